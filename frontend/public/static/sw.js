@@ -0,0 +1,42 @@
+// Web Push service worker. Shows task notifications delivered by the server
+// (internal/webpush) while the board tab is in the background, and focuses or
+// opens the board when one is clicked. When a Wallfacer tab is already
+// focused the page shows an in-page toast instead, so the notification is
+// skipped to avoid doubling up.
+
+self.addEventListener('install', () => self.skipWaiting());
+self.addEventListener('activate', (event) => event.waitUntil(self.clients.claim()));
+
+self.addEventListener('push', (event) => {
+  let msg = {};
+  try {
+    msg = event.data ? event.data.json() : {};
+  } catch {
+    msg = { title: 'Wallfacer', body: event.data ? event.data.text() : '' };
+  }
+  event.waitUntil((async () => {
+    const windows = await self.clients.matchAll({ type: 'window', includeUncontrolled: true });
+    if (msg.kind !== 'test' && windows.some((c) => c.focused)) return;
+    await self.registration.showNotification(msg.title || 'Wallfacer', {
+      body: msg.body || '',
+      tag: msg.tag || undefined,
+      data: { url: msg.url || '/' },
+      icon: '/static/wallfacer-icon.png',
+    });
+  })());
+});
+
+self.addEventListener('notificationclick', (event) => {
+  event.notification.close();
+  const url = new URL((event.notification.data && event.notification.data.url) || '/', self.location.origin);
+  event.waitUntil((async () => {
+    const windows = await self.clients.matchAll({ type: 'window', includeUncontrolled: true });
+    const existing = windows.find((c) => new URL(c.url).origin === url.origin);
+    if (existing) {
+      await existing.focus();
+      if ('navigate' in existing) await existing.navigate(url.href);
+      return;
+    }
+    await self.clients.openWindow(url.href);
+  })());
+});
@@ -1,5 +1,5 @@
 import { describe, it, expect, afterEach, vi } from 'vitest';
-import { api, ApiError, authHeaders, withAuthToken, getServerApiKey } from './client';
+import { api, ApiError, authHeaders, withAuthToken, withBasePath, getServerApiKey, captureUrlToken } from './client';
 
 function setKey(key: string | undefined) {
   if (key === undefined) {
@@ -36,6 +36,32 @@ describe('client auth helpers', () => {
     setKey('a/b c');
     expect(withAuthToken('/api/x')).toBe('/api/x?token=a%2Fb%20c');
   });
+
+  it('captureUrlToken keeps a ?token= key for pages served without one', () => {
+    window.history.replaceState(null, '', '/board?token=remote-key&view=list');
+    setKey('');
+    captureUrlToken();
+    expect(window.location.search).toBe('?view=list');
+    expect(getServerApiKey()).toBe('remote-key');
+    // An injected key still wins.
+    setKey('local-key');
+    expect(getServerApiKey()).toBe('local-key');
+    window.sessionStorage.clear();
+    window.history.replaceState(null, '', '/');
+  });
+});
+
+describe('withBasePath', () => {
+  afterEach(() => setKey(undefined));
+
+  it('prefixes root-relative URLs with the injected base path', () => {
+    expect(withBasePath('/api/x')).toBe('/api/x'); // no boot config → unchanged
+    (window as { __WALLFACER__?: { serverApiKey: string; basePath: string } }).__WALLFACER__ =
+      { serverApiKey: '', basePath: '/wallfacer' };
+    expect(withBasePath('/api/x?a=1')).toBe('/wallfacer/api/x?a=1');
+    expect(withBasePath('//cdn.example/x')).toBe('//cdn.example/x');
+    expect(withBasePath('https://example.com/api/x')).toBe('https://example.com/api/x');
+  });
 });
 
 function mockFetch(status: number, statusText: string, body: string, contentType: string) {
@@ -84,4 +110,15 @@ describe('api error messages', () => {
       JSON.stringify({ error: 'missing' }), 'application/json'));
     await expect(api('GET', '/api/x')).rejects.toBeInstanceOf(ApiError);
   });
+
+  it('exposes the error code from a JSON body', async () => {
+    vi.stubGlobal('fetch', mockFetch(404, 'Not Found',
+      JSON.stringify({ error: 'task not found: abc', code: 'task_not_found' }), 'application/json'));
+    await expect(api('GET', '/api/x')).rejects.toMatchObject({ status: 404, code: 'task_not_found' });
+  });
+
+  it('leaves the code empty for a plain-text body', async () => {
+    vi.stubGlobal('fetch', mockFetch(500, 'Internal Server Error', 'boom', 'text/plain'));
+    await expect(api('GET', '/api/x')).rejects.toMatchObject({ code: '' });
+  });
 });
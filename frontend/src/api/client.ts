@@ -1,23 +1,73 @@
 export class ApiError extends Error {
   status: number;
   body: unknown;
+  // Machine-readable error code from the JSON body (e.g. 'task_not_found',
+  // 'merge_conflict'), or '' when the server sent none.
+  code: string;
   constructor(status: number, body: unknown, message: string) {
     super(message);
     this.status = status;
     this.body = body;
+    const code = body && typeof body === 'object' ? (body as Record<string, unknown>).code : undefined;
+    this.code = typeof code === 'string' ? code : '';
   }
 }
 
-// getServerApiKey reads the local-mode server API key injected into the page.
+// urlTokenKey is the sessionStorage key holding a server API key the user
+// passed as ?token= (see captureUrlToken).
+const urlTokenKey = 'wallfacer-token';
+
+// getServerApiKey reads the local-mode server API key injected into the page,
+// falling back to one captured from ?token= for pages served without it.
 // It is the single source for the key so callers don't each reach into the
 // window global; if auth ever moves off window.__WALLFACER__ only this changes.
 export function getServerApiKey(): string {
+  if (typeof window === 'undefined') return '';
+  const injected = window.__WALLFACER__?.serverApiKey;
+  if (injected) return injected;
+  try {
+    return window.sessionStorage.getItem(urlTokenKey) || '';
+  } catch {
+    return '';
+  }
+}
+
+// captureUrlToken moves a ?token= query parameter into sessionStorage and
+// drops it from the address bar. A board opened through a reverse tunnel
+// (WALLFACER_TUNNEL) gets no key in the page, so the user opens it once as
+// <public url>/?token=<key> and the key lasts for the tab's session.
+export function captureUrlToken(): void {
+  if (typeof window === 'undefined') return;
+  const url = new URL(window.location.href);
+  const token = url.searchParams.get('token');
+  if (!token) return;
+  try {
+    window.sessionStorage.setItem(urlTokenKey, token);
+  } catch {
+    return;
+  }
+  url.searchParams.delete('token');
+  window.history.replaceState(window.history.state, '', url.pathname + url.search + url.hash);
+}
+
+// getBasePath returns the URL prefix the server is mounted under
+// (wallfacer run --base-path, e.g. '/wallfacer' behind a reverse proxy), or
+// '' when it is served at the root.
+export function getBasePath(): string {
   if (typeof window !== 'undefined' && window.__WALLFACER__) {
-    return window.__WALLFACER__.serverApiKey || '';
+    return window.__WALLFACER__.basePath || '';
   }
   return '';
 }
 
+// withBasePath prefixes a root-relative URL ('/api/...') with the base path so
+// fetch, EventSource, and WebSocket requests reach the server through the
+// proxy subpath. Absolute and protocol-relative URLs are returned unchanged.
+export function withBasePath(url: string): string {
+  if (!url.startsWith('/') || url.startsWith('//')) return url;
+  return getBasePath() + url;
+}
+
 // authHeaders returns the Authorization header for fetch when a server API key
 // is configured, or an empty object otherwise.
 export function authHeaders(): Record<string, string> {
@@ -49,7 +99,7 @@ export async function api<T = unknown>(
     headers['Content-Type'] = 'application/json';
     payload = JSON.stringify(body);
   }
-  const res = await fetch(path, {
+  const res = await fetch(withBasePath(path), {
     method,
     credentials: 'same-origin',
     headers,
@@ -67,7 +67,16 @@ export interface DevicePollResponse {
   error?: string;
 }
 
-export type TaskStatus = 'backlog' | 'in_progress' | 'waiting' | 'committing' | 'done' | 'failed' | 'cancelled';
+// GET /api/auth/session: whether API authentication is on, whether this
+// browser passes it, and which sign-in methods the server offers.
+export interface AuthSession {
+  required: boolean;
+  authenticated: boolean;
+  tokens: boolean;
+  oidc: boolean;
+}
+
+export type TaskStatus = 'backlog' | 'in_progress' | 'waiting' | 'committing' | 'review' | 'done' | 'failed' | 'cancelled';
 
 // --- Unified spec+task graph (GET /api/graph) ---
 // Mirrors internal/graph.Graph. The Map renders and drives this.
@@ -139,12 +148,32 @@ export interface Task {
   depends_on: string[];
   failure_category: string;
   fresh_start: boolean;
+  skip_commit?: boolean;
+  // 'pull_request' pushes the branch and opens a pull request instead of
+  // merging it; absent merges into the default branch.
+  merge_mode?: 'pull_request';
+  // Repository path → pull request opened for the branch in pull_request mode.
+  pull_request_urls?: Record<string, string>;
+  // Holds the merge in 'review' until POST /api/tasks/{id}/approve.
+  require_approval?: boolean;
+  approved_at?: string;
+  // Asks the agent to request approval before risky actions.
+  approval_gates?: boolean;
+  // Keeps the agent's stdin open so its prompts can be answered via
+  // POST /api/tasks/{id}/input while a turn runs.
+  interactive_input?: boolean;
+  // Approval requests the agent raised, oldest first; decided via
+  // POST /api/tasks/{id}/approvals/{seq}.
+  approvals?: ApprovalRequest[];
   is_test_run: boolean;
   last_test_result: string;
   session_id: string | null;
   worktree_paths: Record<string, string>;
   usage_breakdown: Record<string, TaskUsage>;
   routine_interval_seconds?: number;
+  // routine_cron, when set, is the cron expression the routine fires on in
+  // place of routine_interval_seconds.
+  routine_cron?: string;
   routine_enabled?: boolean;
   routine_next_run?: string | null;
   routine_last_fired_at?: string | null;
@@ -158,14 +187,68 @@ export interface Task {
   max_input_tokens?: number;
   test_run_start_turn?: number;
   scheduled_at?: string | null;
+  // Set via PATCH on backlog tasks the user marked blocked; absent = not
+  // blocked. until is the optional unblock date that fires a reminder.
+  blocked?: {
+    reason: string;
+    since: string;
+    until?: string;
+    reminded_at?: string;
+  } | null;
+  // Override of the global agent proxy settings; absent = global.
+  proxy?: {
+    http_proxy?: string;
+    https_proxy?: string;
+    no_proxy?: string;
+    direct?: boolean;
+  } | null;
+  // Language tag the title and final summary are translated into;
+  // absent = WALLFACER_SUMMARY_LOCALE.
+  summary_locale?: string;
+  // Latest translation of the title and final summary, kept next to the
+  // originals.
+  localized?: {
+    locale: string;
+    title?: string;
+    summary?: string;
+    generated_at: string;
+  } | null;
+  // Set while the stall watchdog sees no output and no file change from
+  // the running turn; cleared when it resumes or the turn ends.
+  stalled_at?: string | null;
   prompt_history?: string[];
   retry_history?: RetryRecord[];
   parent_task_id?: string | null;
+  // Set on tasks created by POST /api/tasks/{id}/fork to the waiting task
+  // they were forked from.
+  forked_from?: string;
+  // Runs of the workspace's publish command, one per merged repository.
+  publish_runs?: PublishRun[];
+  // Post-merge preview deploys, one per merged repository.
+  previews?: PreviewDeploy[];
+  // External references (tickets, designs, docs, pull requests) set via
+  // PATCH and listed in the agent's prompt.
+  links?: TaskLink[];
+  // Files filed with the task through intake (POST /api/intake or the
+  // intake mailbox); download via GET /api/tasks/{id}/attachments/{name}.
+  attachments?: TaskAttachment[];
+  // The Jira or Linear issue the task is mirrored to (WALLFACER_JIRA_* or
+  // WALLFACER_LINEAR_* settings).
+  tracker_issue?: TrackerIssue;
+  // Workspace files and directories whose contents are injected into the
+  // first prompt as a context pack.
+  context_files?: string[];
+  // Principal subs notified of the task's state changes alongside its
+  // creator; set via PUT /api/tasks/{id}/watch or by an @mention.
+  watchers?: string[];
   spec_source_path?: string;
   environment?: ExecutionEnvironment | null;
   // Review adversarial-verification results. Absent = not yet run.
   review_unresolved?: number;
   review_headline?: string;
+  // Sprint-planning estimates set via PATCH; absent = unpointed / unsized.
+  story_points?: number;
+  size?: 'xs' | 's' | 'm' | 'l' | 'xl';
   // Present (non-empty string) only for tasks run via the agentic flow kind;
   // the opaque JSON of the run's agent-graph lineage. The thin parsed shape is
   // served by GET /api/tasks/{id}/lineage (see AgentLineage).
@@ -236,6 +319,28 @@ export interface ExecutionEnvironment {
   api_base_url?: string;
   sandbox?: string;
   recorded_at?: string;
+  timezone?: string;
+  locale?: string;
+  source_date_epoch?: number;
+  // os/arch the agent ran as (e.g. "darwin/amd64"); emulated when the
+  // workspace forced a non-native architecture.
+  platform?: string;
+  emulated?: boolean;
+  // Host isolation level the agent ran under ("restricted" or "nsjail").
+  // Absent means unconfined.
+  isolation?: string;
+}
+
+export interface ApprovalRequest {
+  seq: number;
+  turn: number;
+  action: string;
+  reason?: string;
+  command?: string;
+  requested_at: string;
+  decision?: 'approved' | 'denied';
+  note?: string;
+  decided_at?: string;
 }
 
 export interface RetryRecord {
@@ -249,6 +354,95 @@ export interface RetryRecord {
   failure_category?: string;
 }
 
+export interface ImpactReference {
+  file: string;
+  line: number;
+  text: string;
+}
+
+// One symbol from GET /api/tasks/{id}/impact: a declaration the diff
+// rewrote or removed, with the references the diff left untouched.
+export interface ImpactSymbol {
+  name: string;
+  file: string;
+  line?: number;
+  status: 'changed' | 'removed';
+  source: 'gopls' | 'tsserver' | 'grep';
+  references: ImpactReference[];
+  truncated?: boolean;
+}
+
+export interface TaskImpact {
+  repos: { repo: string; symbols: ImpactSymbol[] }[];
+  tools: { gopls: boolean; tsserver: boolean };
+}
+
+// One recurring failure from GET /api/failures/signatures (and per task
+// from GET /api/tasks/{id}/failure-hints): error events grouped by
+// normalized message, phase, and repo.
+export interface FailureSignature {
+  id: string;
+  signature: string;
+  phase?: string;
+  repo?: string;
+  category?: string;
+  count: number;
+  task_count: number;
+  task_ids: string[];
+  first_seen: string;
+  last_seen: string;
+  example: string;
+  rule?: string;
+  likely?: 'environmental';
+  hint?: string;
+}
+
+export interface TaskLink {
+  type: 'jira' | 'figma' | 'doc' | 'pr';
+  url: string;
+  title?: string;
+}
+
+export interface TaskAttachment {
+  name: string;
+  content_type?: string;
+  size: number;
+}
+
+export interface TrackerIssue {
+  provider: string;
+  id: string;
+  key: string;
+  url?: string;
+  synced?: string;
+}
+
+export interface PublishRun {
+  repo: string;
+  commit: string;
+  command: string;
+  status: 'running' | 'succeeded' | 'failed';
+  started_at: string;
+  finished_at?: string;
+  artifacts?: string[];
+  log?: string;
+  error?: string;
+}
+
+// A deploy of a merge commit to the workspace's preview provider; url is set
+// once it succeeds.
+export interface PreviewDeploy {
+  repo: string;
+  commit: string;
+  provider: string;
+  status: 'running' | 'succeeded' | 'failed';
+  started_at: string;
+  finished_at?: string;
+  url?: string;
+  log?: string;
+  error?: string;
+}
+
 // --- Workspace registry (GET/POST/PUT/DELETE /api/workspaces) ---
 // A workspace is a first-class object with a stable id, owned by a user/org,
 // holding a mutable set of folder paths. Identity is decoupled from membership:
@@ -266,6 +460,22 @@ export interface Workspace {
   // parallel inputs.
   max_parallel?: number | null;
   max_test_parallel?: number | null;
+  // Shell command run in each new task worktree before the agent's first
+  // turn (e.g. "npm ci"). Absent when none is configured.
+  bootstrap?: string;
+  // Shell command run on each merge commit after a task is done, to build
+  // and upload artifacts. Absent when none is configured.
+  publish?: string;
+  // Deploy-preview provider ("vercel", "netlify") or shell command run on
+  // each merge commit; its preview URL is attached to the task. Absent when
+  // none is configured.
+  preview?: string;
+  // .gitignore-style patterns for untracked paths the commit pipeline
+  // leaves out of task commits. Absent when unset.
+  stage_ignore?: string[];
+  // Architecture the workspace's agents run as ("amd64" or "arm64"). Absent
+  // means the host's native architecture.
+  arch?: string;
 }
 
 export interface WorkspaceGroup {
@@ -300,8 +510,27 @@ export interface ServerConfig {
   default_sandbox: string;
   terminal_enabled: boolean;
   auth_enabled: boolean;
+  // Public URL of the reverse tunnel (WALLFACER_TUNNEL) while it is up.
+  tunnel_url?: string;
   ideation_categories?: string[];
   active_groups?: { key: string; in_progress: number; waiting: number }[];
+  agent_budget?: AgentBudget;
+}
+
+// Budget meter of every open board's spend (GET /api/config agent_budget).
+// Remaining amounts are present only for a configured budget; exhausts_at
+// is when the last-24h burn rate would use one up.
+export interface AgentBudget {
+  today_usd: number;
+  week_usd: number;
+  burn_usd_per_hour: number;
+  total_usd: number;
+  daily_budget_usd: number;
+  weekly_budget_usd: number;
+  daily_remaining_usd?: number;
+  weekly_remaining_usd?: number;
+  exhausts_at?: string;
+  exhausts_budget?: 'daily' | 'weekly';
 }
 
 export interface EnvConfig {
@@ -311,6 +540,7 @@ export interface EnvConfig {
   openai_api_key: string;
   openai_base_url: string;
   cursor_api_key: string;
+  gemini_api_key: string;
   default_model: string;
   title_model: string;
   codex_default_model: string;
@@ -337,6 +567,7 @@ export interface EnvUpdatePayload {
   openai_api_key?: string;
   openai_base_url?: string;
   cursor_api_key?: string;
+  gemini_api_key?: string;
   default_model?: string;
   title_model?: string;
   codex_default_model?: string;
@@ -371,3 +602,16 @@ export interface SandboxTestResponse {
   stop_reason?: string;
   reauth_available?: boolean;
 }
+
+// Payload of a `pipeline_progress` task event: the commit pipeline entered
+// `phase`. `repo` and `attempt` are set for the per-repository phases;
+// `percent` (0-100) only grows within one pipeline run.
+export type PipelinePhase = 'stage' | 'lint' | 'merge_queue' | 'rebase' | 'resolve' | 'merge' | 'pull_request' | 'cleanup' | 'done';
+
+export interface PipelineProgress {
+  phase: PipelinePhase;
+  repo?: string;
+  attempt?: number;
+  percent: number;
+  message: string;
+}
@@ -0,0 +1,62 @@
+// CommitProgress polls pipeline_progress events for a committing task and
+// renders the newest one as a progress bar, asking only for events after the
+// last one it has seen.
+
+import { afterEach, describe, expect, it, vi } from 'vitest';
+import { createApp, h, nextTick, type App } from 'vue';
+
+const { apiMock } = vi.hoisted(() => ({ apiMock: vi.fn() }));
+vi.mock('../api/client', () => ({ api: apiMock }));
+
+import CommitProgress from './CommitProgress.vue';
+
+let app: App | null = null;
+
+async function render(taskId: string): Promise<HTMLElement> {
+  const host = document.createElement('div');
+  document.body.appendChild(host);
+  app = createApp({ render: () => h(CommitProgress, { taskId }) });
+  app.mount(host);
+  await vi.waitFor(() => expect(apiMock).toHaveBeenCalled());
+  await nextTick();
+  return host;
+}
+
+afterEach(() => {
+  app?.unmount();
+  app = null;
+  apiMock.mockReset();
+  vi.useRealTimers();
+  document.body.innerHTML = '';
+});
+
+describe('CommitProgress', () => {
+  it('renders the newest progress event', async () => {
+    apiMock.mockResolvedValue({
+      events: [
+        { id: 3, data: { phase: 'stage', percent: 0, message: 'Staging and committing changes...' } },
+        { id: 7, data: { phase: 'rebase', repo: '/r', attempt: 2, percent: 41, message: 'Rebasing /r onto main (attempt 2/3)...' } },
+      ],
+    });
+    const host = await render('t1');
+    await vi.waitFor(() => expect(host.textContent).toContain('41%'));
+
+    expect(apiMock).toHaveBeenCalledWith('GET', '/api/tasks/t1/events?types=pipeline_progress&after=0');
+    expect(host.textContent).toContain('Rebasing');
+    expect(host.textContent).toContain('attempt 2');
+    expect(host.querySelector('[role="progressbar"]')?.getAttribute('aria-valuenow')).toBe('41');
+  });
+
+  it('polls after the last seen event and stops once done', async () => {
+    vi.useFakeTimers();
+    apiMock
+      .mockResolvedValueOnce({ events: [{ id: 5, data: { phase: 'cleanup', percent: 90, message: 'Cleaning up...' } }] })
+      .mockResolvedValueOnce({ events: [{ id: 6, data: { phase: 'done', percent: 100, message: 'Commit pipeline completed.' } }] });
+    await render('t2');
+    await vi.advanceTimersByTimeAsync(2000);
+
+    expect(apiMock).toHaveBeenLastCalledWith('GET', '/api/tasks/t2/events?types=pipeline_progress&after=5');
+    await vi.advanceTimersByTimeAsync(10000);
+    expect(apiMock).toHaveBeenCalledTimes(2);
+  });
+});
@@ -37,7 +37,7 @@ describe('HarnessLogo', () => {
   });
 
   it('keeps officially-monochrome marks as currentColor even with color', () => {
-    for (const h of ['codex', 'cursor', 'opencode', 'pi']) {
+    for (const h of ['codex', 'cursor', 'gemini', 'opencode', 'pi']) {
       const svg = render({ harness: h, color: true });
       expect(svg.getAttribute('fill')).toBe('currentColor');
       app?.unmount();
@@ -1,5 +1,5 @@
 // WorkspaceEditModal is the single per-workspace settings popup (name, folders,
-// parallel caps, delete). These pin the load-bearing behaviour:
+// parallel caps, bootstrap command, delete). These pin the load-bearing behaviour:
 //  - one Name field (no duplicated label/input box),
 //  - folder add/remove and caps persist via wsStore.update (never the wizard's
 //    activate-on-confirm path),
@@ -132,6 +132,28 @@ describe('WorkspaceEditModal', () => {
     expect((put!.body as { max_parallel: number | null }).max_parallel).toBeNull();
   });
 
+  it('persists a trimmed bootstrap command on blur and clears it when emptied', async () => {
+    seed([{ ...wsA }], 'a');
+    ({ app, host } = await mount());
+    const input = host!.querySelector('#ws-edit-bootstrap') as HTMLInputElement;
+    input.value = '  npm ci ';
+    input.dispatchEvent(new Event('input'));
+    input.dispatchEvent(new Event('blur'));
+    await nextTick();
+    let put = apiCalls.find(c => c.method === 'PUT');
+    expect((put!.body as { bootstrap: string }).bootstrap).toBe('npm ci');
+
+    // Let the first save settle (busy clears) before editing again.
+    await new Promise(r => setTimeout(r, 0));
+    apiCalls.length = 0;
+    input.value = '';
+    input.dispatchEvent(new Event('input'));
+    input.dispatchEvent(new Event('blur'));
+    await nextTick();
+    put = apiCalls.find(c => c.method === 'PUT');
+    expect((put!.body as { bootstrap: string }).bootstrap).toBe('');
+  });
+
   it('adds a browsed folder through update', async () => {
     browseEntries = [{ name: 'gamma', path: '/home/u/gamma', is_git_repo: false }];
     seed([{ ...wsA }], 'a');
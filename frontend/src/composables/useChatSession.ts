@@ -12,7 +12,7 @@
 
 import { ref, computed, onMounted, onUnmounted, nextTick, watch, type Ref, type ComputedRef } from 'vue';
 import { storeToRefs } from 'pinia';
-import { api, authHeaders } from '../api/client';
+import { api, authHeaders, withBasePath } from '../api/client';
 import { renderMarkdown } from '../lib/markdown';
 import { startStreamingFetch, type StreamingFetchHandle } from './useStreamingFetch';
 import { createNdjsonStreamParser } from '../lib/ndjsonStream';
@@ -418,7 +418,7 @@ export function useChatSession(): ChatSession {
     }
 
     try {
-      const res = await fetch('/api/agent/messages', {
+      const res = await fetch(withBasePath('/api/agent/messages'), {
         method: 'POST',
         credentials: 'same-origin',
         headers: {
@@ -464,7 +464,7 @@ export function useChatSession(): ChatSession {
         ? '?thread=' + encodeURIComponent(streamingThreadId.value)
         : '');
     try {
-      await fetch(url, {
+      await fetch(withBasePath(url), {
         method: 'POST',
         credentials: 'same-origin',
         headers: {
@@ -483,7 +483,7 @@ export function useChatSession(): ChatSession {
         ? '?thread=' + encodeURIComponent(activeThreadId.value)
         : '');
     try {
-      await fetch(url, {
+      await fetch(withBasePath(url), {
         method: 'DELETE',
         credentials: 'same-origin',
         headers: {
@@ -698,7 +698,7 @@ export function useChatSession(): ChatSession {
     });
     if (!ok) return;
     try {
-      const res = await fetch('/api/agent/sessions/' + encodeURIComponent(id), {
+      const res = await fetch(withBasePath('/api/agent/sessions/' + encodeURIComponent(id)), {
         method: 'PATCH',
         credentials: 'same-origin',
         headers: {
@@ -744,7 +744,7 @@ export function useChatSession(): ChatSession {
     });
     if (!ok) return;
     try {
-      const res = await fetch('/api/agent/sessions/' + encodeURIComponent(id), {
+      const res = await fetch(withBasePath('/api/agent/sessions/' + encodeURIComponent(id)), {
         method: 'DELETE',
         credentials: 'same-origin',
         headers: { 'Content-Type': 'application/json', ...authHeaders() },
@@ -781,7 +781,7 @@ export function useChatSession(): ChatSession {
         ? '?thread=' + encodeURIComponent(activeThreadId.value)
         : '');
     try {
-      const res = await fetch(url, {
+      const res = await fetch(withBasePath(url), {
         method: 'POST',
         credentials: 'same-origin',
         headers: {
@@ -0,0 +1,68 @@
+import { beforeEach, describe, expect, it, vi } from 'vitest';
+import { ref } from 'vue';
+
+const { apiMock } = vi.hoisted(() => ({ apiMock: vi.fn() }));
+// Keep the real ApiError so the 404 retry's `instanceof ApiError` check works.
+vi.mock('../api/client', async (orig) => {
+  const actual = await orig<typeof import('../api/client')>();
+  return { ...actual, api: apiMock };
+});
+
+import { ApiError } from '../api/client';
+import { useServerDraft } from './useServerDraft';
+
+beforeEach(() => {
+  apiMock.mockReset();
+  localStorage.clear();
+});
+
+describe('useServerDraft', () => {
+  it('saves only when the draft changed, reusing the server id', async () => {
+    apiMock.mockResolvedValue({ id: 'd1', prompt: 'x', updated_at: '' });
+    const prompt = ref('fix the login bug');
+    const d = useServerDraft({ prompt, fields: () => ({ tags: ['auth'] }) });
+
+    await d.flush();
+    await d.flush();
+    expect(apiMock).toHaveBeenCalledTimes(1);
+    expect(apiMock).toHaveBeenLastCalledWith('POST', '/api/drafts', {
+      id: undefined, prompt: 'fix the login bug', fields: { tags: ['auth'] },
+    });
+
+    prompt.value = 'fix the login bug on mobile';
+    await d.flush();
+    expect(apiMock).toHaveBeenCalledTimes(2);
+    expect(apiMock.mock.calls[1][2]).toMatchObject({ id: 'd1' });
+  });
+
+  it('does not save a blank prompt', async () => {
+    const d = useServerDraft({ prompt: ref('   '), fields: () => ({}) });
+    await d.flush();
+    expect(apiMock).not.toHaveBeenCalled();
+  });
+
+  it('starts a new draft when the remembered one is gone', async () => {
+    localStorage.setItem('wallfacer-new-task-draft-id', 'expired');
+    apiMock
+      .mockRejectedValueOnce(new ApiError(404, null, 'draft not found'))
+      .mockResolvedValueOnce({ id: 'd2', prompt: 'p', updated_at: '' });
+    const d = useServerDraft({ prompt: ref('p'), fields: () => ({}) });
+
+    await d.flush();
+    expect(apiMock.mock.calls[1][2]).toMatchObject({ id: undefined, prompt: 'p' });
+    expect(localStorage.getItem('wallfacer-new-task-draft-id')).toBe('d2');
+  });
+
+  it('restores the newest draft and discards it', async () => {
+    apiMock.mockResolvedValueOnce([{ id: 'd3', prompt: 'from laptop', updated_at: '' }]);
+    const d = useServerDraft({ prompt: ref(''), fields: () => ({}) });
+
+    const restored = await d.restore();
+    expect(restored?.prompt).toBe('from laptop');
+
+    apiMock.mockResolvedValueOnce(undefined);
+    await d.discard();
+    expect(apiMock).toHaveBeenLastCalledWith('DELETE', '/api/drafts/d3');
+    expect(localStorage.getItem('wallfacer-new-task-draft-id')).toBeNull();
+  });
+});
@@ -0,0 +1,96 @@
+// Server-side autosave of the task composer's draft (GET/POST /api/drafts).
+// The composer already mirrors its prompt into localStorage; this keeps a
+// copy on the server too, so a draft survives a crashed browser profile or a
+// move to another machine. Saves run on a timer rather than per keystroke:
+// every AUTOSAVE_MS the current draft is posted if it changed since the last
+// save. The server's draft id is remembered in localStorage so reloads keep
+// updating the same draft instead of piling up new ones.
+import { onBeforeUnmount, onMounted, type Ref } from 'vue';
+import { api, ApiError } from '../api/client';
+import { getStored, setStored, removeStored } from '../lib/storage';
+
+export const AUTOSAVE_MS = 5000;
+const DRAFT_ID_KEY = 'wallfacer-new-task-draft-id';
+
+export interface ServerDraft {
+  id: string;
+  prompt: string;
+  fields?: Record<string, unknown>;
+  updated_at: string;
+}
+
+export interface ServerDraftAutosave {
+  // restore returns the newest server draft for the viewed board, or null.
+  restore(): Promise<ServerDraft | null>;
+  // discard deletes the server draft, e.g. once its task is created.
+  discard(): Promise<void>;
+  // flush saves right away if the draft changed since the last save.
+  flush(): Promise<void>;
+}
+
+export function useServerDraft(opts: {
+  prompt: Ref<string>;
+  fields: () => Record<string, unknown>;
+}): ServerDraftAutosave {
+  let lastSaved = '';
+  let saving = false;
+
+  async function flush(): Promise<void> {
+    if (saving) return;
+    const prompt = opts.prompt.value;
+    if (!prompt.trim()) return;
+    const body = { id: getStored(DRAFT_ID_KEY) ?? undefined, prompt, fields: opts.fields() };
+    const snapshot = JSON.stringify({ prompt, fields: body.fields });
+    if (snapshot === lastSaved) return;
+    saving = true;
+    try {
+      let saved: ServerDraft;
+      try {
+        saved = await api<ServerDraft>('POST', '/api/drafts', body);
+      } catch (e) {
+        // The draft expired or was discarded on another machine: start anew.
+        if (!(e instanceof ApiError) || e.status !== 404) throw e;
+        saved = await api<ServerDraft>('POST', '/api/drafts', { ...body, id: undefined });
+      }
+      setStored(DRAFT_ID_KEY, saved.id);
+      lastSaved = snapshot;
+    } catch {
+      // Best effort: the localStorage copy still holds the text, and the
+      // next tick retries.
+    } finally {
+      saving = false;
+    }
+  }
+
+  async function restore(): Promise<ServerDraft | null> {
+    try {
+      const list = await api<ServerDraft[]>('GET', '/api/drafts');
+      const d = list?.[0];
+      if (!d) return null;
+      setStored(DRAFT_ID_KEY, d.id);
+      lastSaved = JSON.stringify({ prompt: d.prompt, fields: d.fields ?? {} });
+      return d;
+    } catch {
+      return null;
+    }
+  }
+
+  async function discard(): Promise<void> {
+    const id = getStored(DRAFT_ID_KEY);
+    removeStored(DRAFT_ID_KEY);
+    lastSaved = '';
+    if (!id) return;
+    try {
+      await api('DELETE', `/api/drafts/${encodeURIComponent(id)}`);
+    } catch {
+      // Already gone (404) or unreachable; it expires on its own.
+    }
+  }
+
+  // Started on mount so the prerender pass never schedules a timer.
+  let timer: ReturnType<typeof setInterval> | undefined;
+  onMounted(() => { timer = setInterval(() => { void flush(); }, AUTOSAVE_MS); });
+  onBeforeUnmount(() => clearInterval(timer));
+
+  return { restore, discard, flush };
+}
@@ -1,6 +1,6 @@
 import { ref, onUnmounted } from 'vue';
 import { isLeader, onLeadershipChange, relay, subscribeAsFollower } from '../lib/tabLeader';
-import { withAuthToken } from '../api/client';
+import { withAuthToken, withBasePath } from '../api/client';
 
 /** Tri-state SSE health, mirroring the legacy status-bar conn dot. */
 export type ConnState = 'ok' | 'reconnecting' | 'closed';
@@ -72,7 +72,7 @@ export function useSse(opts: UseSseOptions) {
   function connectLeader() {
     if (stopped) return;
     connState.value = 'reconnecting';
-    es = new EventSource(addAuthParam(opts.url), {
+    es = new EventSource(addAuthParam(withBasePath(opts.url)), {
       withCredentials: opts.withCredentials ?? true,
     });
 
@@ -15,7 +15,7 @@ export interface StreamingFetchHandle {
   abort: () => void;
 }
 
-import { authHeaders } from '../api/client';
+import { authHeaders, withBasePath } from '../api/client';
 
 export function startStreamingFetch(opts: StreamingFetchOptions): StreamingFetchHandle {
   const ctrl = new AbortController();
@@ -24,7 +24,7 @@ export function startStreamingFetch(opts: StreamingFetchOptions): StreamingFetch
 
   (async () => {
     try {
-      const res = await fetch(opts.url, {
+      const res = await fetch(withBasePath(opts.url), {
         method: 'GET',
         credentials: 'same-origin',
         headers: { Accept: 'text/plain', ...authHeaders() },
@@ -11,7 +11,16 @@ declare const __WALLFACER_VERSION__: string;
 interface WallfacerBootConfig {
   mode: 'local' | 'cloud';
   serverApiKey: string;
+  // Set under WALLFACER_REQUIRE_AUTH: the page carries no API key and the
+  // SPA signs in (GET /api/auth/session) before it loads the board.
+  authRequired?: boolean;
   version: string;
+  // URL prefix the server is mounted under (wallfacer run --base-path), or
+  // '' at the root.
+  basePath?: string;
+  // Primary server a read-only follower mirrors (wallfacer run -follow), or
+  // '' on a primary.
+  followUrl?: string;
 }
 
 interface Window {
@@ -19,6 +19,13 @@ export const en: Record<string, string> = {
   'auth.device.error.denied': 'Sign-in was denied.',
   'auth.device.error.expired': 'The code expired. Try again.',
   'auth.device.error.generic': 'Sign-in failed. Try again.',
+  'auth.gate.title': 'Sign in to this board',
+  'auth.gate.hint': 'This server requires authentication. Paste an API token, or sign in with your account.',
+  'auth.gate.token': 'API token',
+  'auth.gate.submit': 'Sign in',
+  'auth.gate.invalid': 'That token is not valid.',
+  'auth.gate.failed': 'Sign-in failed. Try again.',
+  'auth.gate.oidc': 'Sign in with latere.ai',
 
   // Wallfacer page
   'wf.hero.eyebrow': 'Autonomous engineering platform',
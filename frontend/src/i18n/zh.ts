@@ -19,6 +19,13 @@ export const zh: Record<string, string> = {
   'auth.device.error.denied': '登录被拒绝。',
   'auth.device.error.expired': '代码已过期，请重试。',
   'auth.device.error.generic': '登录失败，请重试。',
+  'auth.gate.title': '登录此看板',
+  'auth.gate.hint': '此服务器需要身份验证。请粘贴 API 令牌，或使用账号登录。',
+  'auth.gate.token': 'API 令牌',
+  'auth.gate.submit': '登录',
+  'auth.gate.invalid': '令牌无效。',
+  'auth.gate.failed': '登录失败，请重试。',
+  'auth.gate.oidc': '通过 latere.ai 登录',
 
   // Wallfacer page
   'wf.hero.eyebrow': '自主工程平台',
@@ -1,5 +1,5 @@
 import { describe, it, expect } from 'vitest';
-import { sortBacklog } from './backlogSort';
+import { sortBacklog, backlogMove } from './backlogSort';
 
 const t = (id: string, impact_score?: number) => ({ id, impact_score });
 
@@ -24,3 +24,25 @@ describe('sortBacklog', () => {
     expect(r.map(x => x.id)).toEqual(['a', 'b', 'c']);
   });
 });
+
+describe('backlogMove', () => {
+  const p = (id: string, position: number) => ({ id, position });
+  const order = [p('a', 0), p('b', 1024), p('c', 2048)];
+
+  it('anchors after the previous neighbour with a midpoint position', () => {
+    expect(backlogMove(order, 'c', 1)).toEqual({ body: { after_id: 'a' }, position: 512 });
+  });
+  it('anchors before the first task when dropped at the top', () => {
+    expect(backlogMove(order, 'c', 0)).toEqual({ body: { before_id: 'a' }, position: -1 });
+  });
+  it('anchors after the last task when dropped at the bottom', () => {
+    expect(backlogMove(order, 'a', 2)).toEqual({ body: { after_id: 'c' }, position: 2049 });
+  });
+  it('ignores the moved task whether or not the list is already reordered', () => {
+    const dropped = [p('a', 0), p('c', 2048), p('b', 1024)];
+    expect(backlogMove(dropped, 'c', 1)).toEqual(backlogMove(order, 'c', 1));
+  });
+  it('falls back to the column for a lone task', () => {
+    expect(backlogMove([p('a', 3)], 'a', 0)).toEqual({ body: { column: 'backlog' }, position: 0 });
+  });
+});
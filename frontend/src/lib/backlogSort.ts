@@ -30,3 +30,22 @@ export function loadBacklogSortMode(): BacklogSortMode {
 export function saveBacklogSortMode(mode: BacklogSortMode): void {
   try { localStorage.setItem(KEY, mode); } catch { /* ignore */ }
 }
+
+export type MoveBody = { after_id: string } | { before_id: string } | { column: 'backlog' };
+
+// backlogMove turns a drag that dropped `id` at `newIndex` of `order` (the
+// list as rendered before the drop) into a POST /api/tasks/{id}/move body,
+// plus an optimistic position between the new neighbours. The server
+// resolves the real position atomically; its SSE delta then overwrites the
+// optimistic one.
+export function backlogMove<T extends { id: string; position: number }>(
+  order: T[], id: string, newIndex: number,
+): { body: MoveBody; position: number } {
+  const rest = order.filter((t) => t.id !== id);
+  const prev = rest[newIndex - 1];
+  const next = rest[newIndex];
+  if (prev && next) return { body: { after_id: prev.id }, position: (prev.position + next.position) / 2 };
+  if (prev) return { body: { after_id: prev.id }, position: prev.position + 1 };
+  if (next) return { body: { before_id: next.id }, position: next.position - 1 };
+  return { body: { column: 'backlog' }, position: 0 };
+}
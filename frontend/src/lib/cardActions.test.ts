@@ -25,6 +25,10 @@ describe('cardActionsFor', () => {
     expect(cardActionsFor(task({ status: 'failed', session_id: null }))).toEqual(['retry']);
   });
 
+  it('review → Approve', () => {
+    expect(cardActionsFor(task({ status: 'review', session_id: 's1' }))).toEqual(['approve']);
+  });
+
   it('done and cancelled → Retry', () => {
     expect(cardActionsFor(task({ status: 'done' }))).toEqual(['retry']);
     expect(cardActionsFor(task({ status: 'cancelled' }))).toEqual(['retry']);
@@ -42,7 +46,7 @@ describe('cardActionsFor', () => {
 
   it('every action id has a render def', () => {
     const ids = new Set<string>();
-    for (const s of ['backlog', 'waiting', 'failed', 'done', 'cancelled'] as const) {
+    for (const s of ['backlog', 'waiting', 'review', 'failed', 'done', 'cancelled'] as const) {
       for (const a of cardActionsFor(task({ status: s, session_id: 's1' }))) ids.add(a);
     }
     for (const id of ids) expect(CARD_ACTION_DEFS[id as keyof typeof CARD_ACTION_DEFS]).toBeTruthy();
@@ -4,7 +4,7 @@
 
 import type { Task } from '../api/types';
 
-export type CardAction = 'plan' | 'start' | 'resume' | 'test' | 'done' | 'retry' | 'sync';
+export type CardAction = 'plan' | 'start' | 'resume' | 'test' | 'done' | 'approve' | 'retry' | 'sync';
 
 export interface CardActionDef {
   id: CardAction;
@@ -20,6 +20,7 @@ export const CARD_ACTION_DEFS: Record<CardAction, CardActionDef> = {
   resume: { id: 'resume', label: 'Resume', icon: '↻', title: 'Resume in existing session', cls: 'card-action-resume' },
   test: { id: 'test', label: 'Test', icon: '▶', title: 'Run test agent', cls: 'card-action-test' },
   done: { id: 'done', label: 'Done', icon: '✓', title: 'Mark done and commit', cls: 'card-action-done' },
+  approve: { id: 'approve', label: 'Approve', icon: '✓', title: 'Approve the reviewed changes and merge', cls: 'card-action-done' },
   retry: { id: 'retry', label: 'Retry', icon: '↩', title: 'Move back to Backlog', cls: 'card-action-retry' },
   sync: { id: 'sync', label: 'Sync with default', icon: '⟳', title: 'Rebase worktrees onto the default branch', cls: 'card-action-sync' },
 };
@@ -34,6 +35,8 @@ export function cardActionsFor(task: Pick<Task, 'status' | 'archived' | 'kind' |
       return ['plan', 'start'];
     case 'waiting':
       return task.session_id ? ['resume', 'test', 'done'] : ['test', 'done'];
+    case 'review':
+      return ['approve'];
     case 'failed':
       return task.session_id ? ['resume', 'retry'] : ['retry'];
     case 'cancelled':
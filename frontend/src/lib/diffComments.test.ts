@@ -1,5 +1,6 @@
 import { describe, it, expect } from 'vitest';
-import { formatBatchFeedback } from './diffComments';
+import { formatBatchFeedback, MAX_REVERT_LINES } from './diffComments';
+import { parseDiffFiles } from './diff';
 import type { DiffComment } from '../stores/diffComments';
 
 function comment(over: Partial<DiffComment> = {}): DiffComment {
@@ -65,4 +66,50 @@ describe('formatBatchFeedback', () => {
     expect(out).toContain('back to z');
     expect(out.endsWith('## General Feedback\n\nwrap up')).toBe(true);
   });
+
+  describe('file decisions', () => {
+    const files = parseDiffFiles([
+      'diff --git a/a.go b/a.go',
+      '--- a/a.go',
+      '+++ b/a.go',
+      '@@ -1,1 +1,2 @@',
+      ' package a',
+      '+var x = 1',
+      'diff --git a/b.go b/b.go',
+      '--- a/b.go',
+      '+++ b/b.go',
+      '@@ -1,1 +1,1 @@',
+      '-old',
+      '+new',
+      'diff --git a/c.go b/c.go',
+      '--- a/c.go',
+      '+++ b/c.go',
+      '@@ -1 +1 @@',
+      '+c',
+    ].join('\n'));
+
+    it('omits the section when nothing is rejected', () => {
+      expect(formatBatchFeedback([comment()], '', files)).not.toContain('## File Decisions');
+    });
+
+    it('reverts rejected files with their hunks, adjusts commented ones, and keeps the rest', () => {
+      const out = formatBatchFeedback([comment({ filename: 'c.go' })], '', files, ['b.go']);
+      expect(out.startsWith('## File Decisions')).toBe(true);
+      expect(out).toContain('#### b.go\n\n```diff\n@@ -1,1 +1,1 @@\n-old\n+new\n```');
+      expect(out).not.toContain('+++ b/b.go');
+      expect(out).toContain('### Adjust\n\nKeep the changes to these files but address the inline comments below:\n\n- `c.go`');
+      expect(out).toContain('### Keep\n\nLeave the changes to these files as they are:\n\n- `a.go`');
+      expect(out.indexOf('## File Decisions')).toBeLessThan(out.indexOf('## Inline Review Comments'));
+    });
+
+    it('caps the quoted hunk lines per file', () => {
+      const big = parseDiffFiles(
+        ['diff --git a/gen.go b/gen.go', '@@ -0,0 +1,200 @@', ...Array.from({ length: 200 }, (_, i) => `+line ${i}`)].join('\n'),
+      );
+      const out = formatBatchFeedback([], '', big, ['gen.go']);
+      expect(out).toContain(`+line ${MAX_REVERT_LINES - 2}`);
+      expect(out).not.toContain(`+line ${MAX_REVERT_LINES - 1}`);
+      expect(out).toContain(`… ${201 - MAX_REVERT_LINES} more lines`);
+    });
+  });
 });
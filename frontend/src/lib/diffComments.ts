@@ -1,17 +1,33 @@
-// Collapse the client-side inline diff-review comments and an optional general
-// message into one structured markdown string for the agent. The result is sent
+// Collapse the client-side inline diff-review comments, per-file rejections,
+// and an optional general message into one structured markdown string for the
+// agent. The result is sent
 // under the `message` key of POST /api/tasks/{id}/feedback — the same channel the
 // Overview feedback textarea uses — so the runner consumes it as the next turn's
 // prompt with enough context to locate each commented line.
+import type { DiffFile } from './diff';
 import type { DiffComment } from '../stores/diffComments';
 
+// MAX_REVERT_LINES caps the hunk lines quoted per rejected file, so rejecting
+// a generated or vendored file does not blow up the prompt.
+export const MAX_REVERT_LINES = 120;
+
 // formatBatchFeedback groups line comments by file (first-seen order) under an
-// "Inline Review Comments" section and appends the general feedback. Either
-// section is omitted when empty; an empty input yields an empty string (the
-// caller disables submit in that case).
-export function formatBatchFeedback(comments: DiffComment[], general: string): string {
+// "Inline Review Comments" section and appends the general feedback. When
+// files are rejected, a leading "File Decisions" section tells the agent which
+// files to revert (quoting their hunks from `files`), which to adjust (those
+// with comments), and which of the other changed files to keep. Each section
+// is omitted when empty; an empty input yields an empty string (the caller
+// disables submit in that case).
+export function formatBatchFeedback(
+  comments: DiffComment[],
+  general: string,
+  files: DiffFile[] = [],
+  rejected: string[] = [],
+): string {
   const sections: string[] = [];
 
+  if (rejected.length > 0) sections.push(formatFileDecisions(comments, files, rejected));
+
   if (comments.length > 0) {
     const order: string[] = [];
     const byFile = new Map<string, DiffComment[]>();
@@ -42,3 +58,35 @@ export function formatBatchFeedback(comments: DiffComment[], general: string): s
 
   return sections.join('\n\n');
 }
+
+function formatFileDecisions(comments: DiffComment[], files: DiffFile[], rejected: string[]): string {
+  const reverted = new Set(rejected);
+  const commented = new Set(comments.map((c) => c.filename));
+  const parts: string[] = [
+    '## File Decisions',
+    '### Revert\n\nRevert every change to these files so they match the default branch again. The rejected changes:',
+  ];
+  for (const filename of rejected) {
+    parts.push(`#### ${filename}`);
+    // Hunks only: file headers are noise, and a real context line is never
+    // empty (it keeps its leading space), so an empty one is a split artifact.
+    const lines = files
+      .find((f) => f.filename === filename)
+      ?.lines.filter((l) => l.kind !== 'header' && l.text !== '') ?? [];
+    if (lines.length === 0) continue;
+    const quoted = lines.slice(0, MAX_REVERT_LINES).map((l) => l.text);
+    if (lines.length > MAX_REVERT_LINES) quoted.push(`… ${lines.length - MAX_REVERT_LINES} more lines`);
+    parts.push('```diff\n' + quoted.join('\n') + '\n```');
+  }
+
+  const bullets = (names: string[]) => names.map((n) => `- \`${n}\``).join('\n');
+  const adjust = [...commented].filter((n) => !reverted.has(n));
+  if (adjust.length > 0) {
+    parts.push('### Adjust\n\nKeep the changes to these files but address the inline comments below:\n\n' + bullets(adjust));
+  }
+  const keep = files.map((f) => f.filename).filter((n) => !reverted.has(n) && !commented.has(n));
+  if (keep.length > 0) {
+    parts.push('### Keep\n\nLeave the changes to these files as they are:\n\n' + bullets(keep));
+  }
+  return parts.join('\n\n');
+}
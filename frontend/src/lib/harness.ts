@@ -5,6 +5,7 @@ export const HARNESS_LABELS: Record<string, string> = {
   claude: 'Claude',
   codex: 'Codex',
   cursor: 'Cursor',
+  gemini: 'Gemini',
   opencode: 'OpenCode',
   pi: 'Pi',
   topos: 'Topos',
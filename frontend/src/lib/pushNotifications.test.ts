@@ -0,0 +1,30 @@
+import { describe, it, expect } from 'vitest';
+import { taskTransitionToast, urlBase64ToUint8Array } from './pushNotifications';
+
+describe('urlBase64ToUint8Array', () => {
+  it('decodes unpadded base64url', () => {
+    // "-_8" is base64url for 0xfb 0xff (would be "+/8=" in standard base64).
+    expect(Array.from(urlBase64ToUint8Array('-_8'))).toEqual([0xfb, 0xff]);
+  });
+  it('handles input that needs no padding', () => {
+    expect(Array.from(urlBase64ToUint8Array('AAEC'))).toEqual([0, 1, 2]);
+  });
+});
+
+describe('taskTransitionToast', () => {
+  const task = { title: 'Fix login', prompt: 'fix the login bug', status: 'done' as const };
+
+  it('announces entering done, waiting and failed', () => {
+    expect(taskTransitionToast('in_progress', task)).toEqual({ message: 'Task done: Fix login', kind: 'success' });
+    expect(taskTransitionToast('in_progress', { ...task, status: 'waiting' })?.kind).toBe('info');
+    expect(taskTransitionToast('in_progress', { ...task, status: 'failed' })?.kind).toBe('error');
+  });
+  it('stays quiet for unchanged, first-seen and uninteresting statuses', () => {
+    expect(taskTransitionToast('done', task)).toBeNull();
+    expect(taskTransitionToast(undefined, task)).toBeNull();
+    expect(taskTransitionToast('backlog', { ...task, status: 'in_progress' })).toBeNull();
+  });
+  it('falls back to the prompt when the title is empty', () => {
+    expect(taskTransitionToast('in_progress', { ...task, title: '' })?.message).toBe('Task done: fix the login bug');
+  });
+});
@@ -0,0 +1,120 @@
+// Browser side of Web Push task notifications. The server pushes a message
+// when a task finishes, fails, or starts waiting for feedback; the service
+// worker at /static/sw.js turns it into a native notification. Where push is
+// unavailable (no service worker, insecure origin, permission denied, or the
+// server has no VAPID keys) the board falls back to in-page toasts via
+// taskTransitionToast.
+import { api } from '../api/client';
+import type { Task } from '../api/types';
+
+export const SERVICE_WORKER_URL = '/static/sw.js';
+
+export interface PushConfig {
+  enabled: boolean;
+  public_key?: string;
+}
+
+export type PushState = 'unsupported' | 'disabled' | 'denied' | 'off' | 'on';
+
+// isPushSupported reports whether this browser context can receive Web Push.
+// Service workers (and therefore push) require a secure context.
+export function isPushSupported(w: Window & typeof globalThis = window): boolean {
+  return !!w.isSecureContext
+    && 'serviceWorker' in w.navigator
+    && 'PushManager' in w
+    && 'Notification' in w;
+}
+
+// urlBase64ToUint8Array decodes the server's unpadded base64url VAPID key into
+// the byte array PushManager.subscribe expects as applicationServerKey.
+export function urlBase64ToUint8Array(b64: string): Uint8Array<ArrayBuffer> {
+  const padded = b64.replace(/-/g, '+').replace(/_/g, '/') + '='.repeat((4 - (b64.length % 4)) % 4);
+  const raw = atob(padded);
+  const out = new Uint8Array(raw.length);
+  for (let i = 0; i < raw.length; i++) out[i] = raw.charCodeAt(i);
+  return out;
+}
+
+// taskTransitionToast returns the in-page toast for a task status change, or
+// null when the change does not warrant one. It mirrors the server's
+// pushMessageForTransition so both channels announce the same events.
+export function taskTransitionToast(
+  prev: Task['status'] | undefined,
+  task: Pick<Task, 'status' | 'title' | 'prompt'>,
+): { message: string; kind: 'info' | 'success' | 'error' } | null {
+  if (!prev || prev === task.status) return null;
+  const label = task.title || (task.prompt || '').slice(0, 80);
+  switch (task.status) {
+    case 'done': return { message: `Task done: ${label}`, kind: 'success' };
+    case 'waiting': return { message: `Task needs feedback: ${label}`, kind: 'info' };
+    case 'review': return { message: `Task awaiting merge approval: ${label}`, kind: 'info' };
+    case 'failed': return { message: `Task failed: ${label}`, kind: 'error' };
+    default: return null;
+  }
+}
+
+async function currentSubscription(): Promise<PushSubscription | null> {
+  const reg = await navigator.serviceWorker.getRegistration(SERVICE_WORKER_URL);
+  return reg ? reg.pushManager.getSubscription() : null;
+}
+
+// pushState resolves the current notification state for the settings UI.
+export async function pushState(): Promise<PushState> {
+  if (!isPushSupported()) return 'unsupported';
+  const cfg = await api<PushConfig>('GET', '/api/push/config');
+  if (!cfg.enabled) return 'disabled';
+  if (Notification.permission === 'denied') return 'denied';
+  return (await currentSubscription()) ? 'on' : 'off';
+}
+
+// enablePush asks for notification permission, registers the service worker,
+// subscribes with the server's VAPID key, and registers the subscription.
+export async function enablePush(): Promise<PushState> {
+  if (!isPushSupported()) return 'unsupported';
+  const cfg = await api<PushConfig>('GET', '/api/push/config');
+  if (!cfg.enabled || !cfg.public_key) return 'disabled';
+  const permission = await Notification.requestPermission();
+  if (permission !== 'granted') return permission === 'denied' ? 'denied' : 'off';
+  const reg = await navigator.serviceWorker.register(SERVICE_WORKER_URL);
+  await navigator.serviceWorker.ready;
+  const sub = await reg.pushManager.subscribe({
+    userVisibleOnly: true,
+    applicationServerKey: urlBase64ToUint8Array(cfg.public_key),
+  });
+  await api('POST', '/api/push/subscriptions', sub.toJSON());
+  return 'on';
+}
+
+// disablePush unsubscribes this browser and removes it from the server.
+export async function disablePush(): Promise<PushState> {
+  const sub = await currentSubscription();
+  if (sub) {
+    await api('DELETE', '/api/push/subscriptions', { endpoint: sub.endpoint }).catch(() => {});
+    await sub.unsubscribe();
+  }
+  return 'off';
+}
+
+// sendTestPush asks the server to push a sample notification to every
+// subscription the caller owns and returns how many were delivered.
+export async function sendTestPush(): Promise<number> {
+  const res = await api<{ delivered: number }>('POST', '/api/push/test');
+  return res.delivered;
+}
+
+// pushActive caches whether this browser holds a live subscription so the
+// task store can skip in-page toasts for a hidden tab that push already
+// covers. Refreshed by refreshPushActive (settings changes and app start).
+let active = false;
+
+export function isPushActive(): boolean {
+  return active;
+}
+
+export async function refreshPushActive(): Promise<void> {
+  try {
+    active = isPushSupported() && Notification.permission === 'granted' && !!(await currentSubscription());
+  } catch {
+    active = false;
+  }
+}
@@ -41,8 +41,14 @@ import './styles/brand-override.css';
 // win; wallfacer keeps its terracotta --accent, which glass.css never sets.
 import 'latere-ui/glass';
 import { vScrollFade } from './directives/scrollFade';
+import { captureUrlToken, getBasePath } from './api/client';
 
-export const createApp = ViteSSG(App, { routes }, ({ app, router, isClient }) => {
+// Take a ?token= server key out of the URL before the router reads it.
+captureUrlToken();
+
+// Mount the router under the server's --base-path so client-side routes
+// resolve behind a reverse-proxy subpath.
+export const createApp = ViteSSG(App, { routes, base: getBasePath() || undefined }, ({ app, router, isClient }) => {
   app.use(createPinia());
   app.directive('scrollfade', vScrollFade);
 
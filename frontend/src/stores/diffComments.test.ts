@@ -61,4 +61,20 @@ describe('diffComments store', () => {
     expect(s.forLine('t1', 'a.go', 9)).toBeUndefined();
     expect(s.forLine('t9', 'a.go', 3)).toBeUndefined();
   });
+
+  it('toggles file rejections per task and clears them with the comments', () => {
+    const s = useDiffCommentsStore();
+    s.toggleRejected('t1', 'b.go');
+    s.toggleRejected('t1', 'a.go');
+    s.toggleRejected('t2', 'a.go');
+    expect(s.rejectedFor('t1')).toEqual(['b.go', 'a.go']);
+    expect(s.isRejected('t1', 'a.go')).toBe(true);
+
+    s.toggleRejected('t1', 'b.go');
+    expect(s.rejectedFor('t1')).toEqual(['a.go']);
+
+    s.clear('t1');
+    expect(s.rejectedFor('t1')).toEqual([]);
+    expect(s.isRejected('t2', 'a.go')).toBe(true);
+  });
 });
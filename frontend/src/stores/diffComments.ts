@@ -1,8 +1,10 @@
-// Client-side store for inline diff-review comments. Comments anchor to a diff
-// line by (taskId, filename, lineIndex) and live only in the browser until the
-// user batches them into one feedback message (see lib/diffComments.ts) and
-// submits through the existing feedback endpoint. Nothing is persisted across
-// reloads — the workflow is open diff → comment → submit.
+// Client-side store for inline diff-review comments and per-file rejections.
+// Comments anchor to a diff line by (taskId, filename, lineIndex); a rejection
+// marks a whole file whose changes should be reverted. Both live only in the
+// browser until the user batches them into one feedback message (see
+// lib/diffComments.ts) and submits through the existing feedback endpoint.
+// Nothing is persisted across reloads — the workflow is open diff → comment or
+// reject → submit.
 import { defineStore } from 'pinia';
 import { ref } from 'vue';
 import type { DiffLineKind } from '../lib/diff';
@@ -26,6 +28,8 @@ export const useDiffCommentsStore = defineStore('diffComments', () => {
   // A flat reactive list; the getters slice it by task. Slicing on read keeps
   // the per-task and per-line lookups reactive without a nested Map.
   const comments = ref<DiffComment[]>([]);
+  // Rejected files, kept flat for the same reason.
+  const rejections = ref<{ taskId: string; filename: string }[]>([]);
 
   function add(c: NewDiffComment): DiffComment {
     const created: DiffComment = { id: crypto.randomUUID(), ...c };
@@ -45,6 +49,23 @@ export const useDiffCommentsStore = defineStore('diffComments', () => {
 
   function clear(taskId: string): void {
     comments.value = comments.value.filter((c) => c.taskId !== taskId);
+    rejections.value = rejections.value.filter((r) => r.taskId !== taskId);
+  }
+
+  // toggleRejected marks a file's changes for reverting, or unmarks it.
+  function toggleRejected(taskId: string, filename: string): void {
+    const i = rejections.value.findIndex((r) => r.taskId === taskId && r.filename === filename);
+    if (i === -1) rejections.value.push({ taskId, filename });
+    else rejections.value.splice(i, 1);
+  }
+
+  function isRejected(taskId: string, filename: string): boolean {
+    return rejections.value.some((r) => r.taskId === taskId && r.filename === filename);
+  }
+
+  // rejectedFor lists the task's rejected files in the order they were marked.
+  function rejectedFor(taskId: string): string[] {
+    return rejections.value.filter((r) => r.taskId === taskId).map((r) => r.filename);
   }
 
   function forTask(taskId: string): DiffComment[] {
@@ -57,5 +78,8 @@ export const useDiffCommentsStore = defineStore('diffComments', () => {
     );
   }
 
-  return { comments, add, update, remove, clear, forTask, forLine };
+  return {
+    comments, rejections, add, update, remove, clear, forTask, forLine,
+    toggleRejected, isRejected, rejectedFor,
+  };
 });
@@ -2,6 +2,8 @@ import { defineStore } from 'pinia';
 import { ref, computed } from 'vue';
 import { api } from '../api/client';
 import { useUiStore } from './ui';
+import { useToastStore } from './toast';
+import { taskTransitionToast, isPushActive } from '../lib/pushNotifications';
 import { matchesTaskFilter } from '../lib/taskFilter';
 import { isSystemRoutineCard } from '../lib/boardVisibility';
 import type { Task, ServerConfig } from '../api/types';
@@ -43,7 +45,7 @@ export const useTaskStore = defineStore('tasks', () => {
   );
   const waiting = computed(() =>
     tasks.value.filter(t =>
-      (t.status === 'waiting' || t.status === 'failed') && !t.archived && matchesFilter(t),
+      (t.status === 'waiting' || t.status === 'review' || t.status === 'failed') && !t.archived && matchesFilter(t),
     ),
   );
   const done = computed(() =>
@@ -67,11 +69,23 @@ export const useTaskStore = defineStore('tasks', () => {
 
   function updateTask(updated: Task) {
     const idx = tasks.value.findIndex(t => t.id === updated.id);
+    const prev = idx >= 0 ? tasks.value[idx].status : undefined;
     if (idx >= 0) {
       tasks.value[idx] = updated;
     } else {
       tasks.value.push(updated);
     }
+    announceTransition(prev, updated);
+  }
+
+  /** In-page fallback for Web Push: toast when a task finishes, fails or
+   *  needs feedback. A hidden tab with a live push subscription is skipped
+   *  because the service worker already shows a native notification. */
+  function announceTransition(prev: Task['status'] | undefined, task: Task) {
+    const t = taskTransitionToast(prev, task);
+    if (!t) return;
+    if (isPushActive() && typeof document !== 'undefined' && document.visibilityState === 'hidden') return;
+    useToastStore().push(t.message, { kind: t.kind });
   }
 
   function removeTask(id: string) {
@@ -76,6 +76,11 @@ export const useWorkspacesStore = defineStore('workspaces', () => {
       folders?: string[];
       max_parallel?: number | null;
       max_test_parallel?: number | null;
+      bootstrap?: string;
+      publish?: string;
+      preview?: string;
+      stage_ignore?: string[];
+      arch?: string;
     },
   ): Promise<Workspace> {
     error.value = null;
@@ -26,3 +26,23 @@ var CommitMessage = Role{
 	Description:        "Produces a descriptive git commit message from the task prompt and diff.",
 	PromptTemplateName: "commit_message",
 }
+
+// Localize is the descriptor for the sub-agent that translates a task's
+// title and final summary into the board's summary language. It runs on the
+// title model, so like Probe it is not listed in BuiltinAgents.
+var Localize = Role{
+	Slug:               "localize",
+	Title:              "Localize",
+	Description:        "Translates a task's title and final summary into another language.",
+	PromptTemplateName: "localize",
+}
+
+// Probe is the descriptor for the capability probe: a one-line prompt run
+// at startup to check that the configured harness and model answer. It is
+// an internal health check rather than a catalog role, so it is not listed
+// in BuiltinAgents.
+var Probe = Role{
+	Slug:        "probe",
+	Title:       "Capability probe",
+	Description: "Checks that the configured agent CLI and model are usable.",
+}
@@ -19,3 +19,14 @@ var Testing = Role{
 	Capabilities: []string{CapWorkspaceWrite, CapBoardContext},
 	Multiturn:    true,
 }
+
+// ConflictResolver settles rebase conflicts in a task's worktree when the
+// task's branch no longer rebases cleanly. It is configured through the
+// env file rather than the catalog, so it is not listed in BuiltinAgents.
+var ConflictResolver = Role{
+	Slug:               "conflict-resolver",
+	Title:              "Conflict resolver",
+	Description:        "Rebases the task branch and resolves the conflicts it hits.",
+	PromptTemplateName: "conflict_resolution",
+	Capabilities:       []string{CapWorkspaceWrite},
+}
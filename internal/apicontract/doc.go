@@ -7,6 +7,13 @@
 // JSDoc types, and by the contract generator to emit machine-readable API documentation.
 // Centralizing routes here prevents drift between backend handlers and frontend callers.
 //
+// # Versioning
+//
+// Every route is also served under [VersionPrefix] (/api/v1/...). A route
+// scheduled for removal sets [Route.Deprecated]; the server then adds
+// deprecation headers to its responses and the contract JSON records the
+// schedule.
+//
 // # Connected packages
 //
 // Consumed by [latere.ai/x/wallfacer/internal/cli] (server startup registers
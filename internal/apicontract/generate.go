@@ -4,6 +4,13 @@ import (
 	"encoding/json"
 )
 
+// deprecationJSON is the JSON representation of a route's Deprecation.
+type deprecationJSON struct {
+	Since     string `json:"since"`
+	Sunset    string `json:"sunset,omitempty"`
+	Successor string `json:"successor,omitempty"`
+}
+
 // routeJSON is the JSON representation emitted to docs/internals/api-contract.json.
 type routeJSON struct {
 	Method      string   `json:"method"`
@@ -11,6 +18,8 @@ type routeJSON struct {
 	Name        string   `json:"name"`
 	Description string   `json:"description"`
 	Tags        []string `json:"tags"`
+
+	Deprecated *deprecationJSON `json:"deprecated,omitempty"`
 }
 
 // GenerateContractJSON returns the pretty-printed JSON content for
@@ -26,14 +35,25 @@ func GenerateContractJSON() ([]byte, error) {
 			Description: r.Description,
 			Tags:        r.Tags,
 		}
+		if d := r.Deprecated; d != nil {
+			dj := &deprecationJSON{Since: d.Since.UTC().Format("2006-01-02"), Successor: d.Successor}
+			if !d.Sunset.IsZero() {
+				dj.Sunset = d.Sunset.UTC().Format("2006-01-02")
+			}
+			rs[i].Deprecated = dj
+		}
 	}
 	type contract struct {
 		GeneratedFrom string      `json:"generated_from"`
+		Version       string      `json:"version"`
+		BasePath      string      `json:"base_path"`
 		RouteCount    int         `json:"route_count"`
 		Routes        []routeJSON `json:"routes"`
 	}
 	c := contract{
 		GeneratedFrom: "internal/apicontract/routes.go",
+		Version:       Version,
+		BasePath:      VersionPrefix,
 		RouteCount:    len(Routes),
 		Routes:        rs,
 	}
@@ -1,10 +1,12 @@
 package apicontract
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 )
 
 // repoRoot returns the repository root directory by walking up from this
@@ -98,3 +100,59 @@ func TestRoute_FullPattern_AllRoutes(t *testing.T) {
 		}
 	}
 }
+
+func TestVersionedPath(t *testing.T) {
+	cases := map[string]string{
+		"/api/tasks":           "/api/v1/tasks",
+		"/api/tasks/{id}/move": "/api/v1/tasks/{id}/move",
+		"/metrics":             "/metrics",
+	}
+	for in, want := range cases {
+		if got := VersionedPath(in); got != want {
+			t.Errorf("VersionedPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestGenerateContractJSON_Deprecation verifies a deprecated route carries
+// its schedule in the contract and undeprecated routes omit the field.
+func TestGenerateContractJSON_Deprecation(t *testing.T) {
+	saved := Routes
+	t.Cleanup(func() { Routes = saved })
+	Routes = []Route{
+		{Method: "GET", Pattern: "/api/old", Name: "Old", Description: "old", Tags: []string{"x"},
+			Deprecated: &Deprecation{
+				Since:     time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+				Sunset:    time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+				Successor: "/api/new",
+			}},
+		{Method: "GET", Pattern: "/api/new", Name: "New", Description: "new", Tags: []string{"x"}},
+	}
+
+	b, err := GenerateContractJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c struct {
+		Version  string `json:"version"`
+		BasePath string `json:"base_path"`
+		Routes   []struct {
+			Deprecated *struct {
+				Since, Sunset, Successor string
+			} `json:"deprecated"`
+		} `json:"routes"`
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Version != Version || c.BasePath != "/api/v1" {
+		t.Errorf("version = %q, base_path = %q", c.Version, c.BasePath)
+	}
+	d := c.Routes[0].Deprecated
+	if d == nil || d.Since != "2026-01-02" || d.Sunset != "2026-07-01" || d.Successor != "/api/new" {
+		t.Errorf("deprecated = %+v", d)
+	}
+	if c.Routes[1].Deprecated != nil {
+		t.Errorf("undeprecated route has deprecated = %+v", c.Routes[1].Deprecated)
+	}
+}
@@ -12,7 +12,33 @@
 // registered in the mux, and that the generated artifacts are not stale.
 package apicontract
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Version is the current HTTP API version. Every route in Routes is served
+// both at its Pattern and under VersionPrefix (e.g. GET /api/v1/tasks); the
+// unversioned /api/... paths are a compatibility alias for the current
+// version, kept so existing scripts and the bundled UI keep working while
+// clients move to the versioned paths.
+const Version = "v1"
+
+// VersionPrefix is the path prefix of the versioned API.
+const VersionPrefix = "/api/" + Version
+
+// Deprecation schedules a route for removal. Responses from a deprecated
+// route carry Deprecation, Sunset, and Link headers (RFC 9745, RFC 8594) so
+// clients can migrate before the route disappears.
+type Deprecation struct {
+	// Since is when the route was deprecated.
+	Since time.Time
+	// Sunset is when the route may be removed; zero when not yet scheduled.
+	Sunset time.Time
+	// Successor is the unversioned pattern of the replacement route, if any.
+	Successor string
+}
 
 // Route describes a single HTTP API endpoint.
 type Route struct {
@@ -31,6 +57,8 @@ type Route struct {
 	Description string
 	// Tags are logical group labels used for documentation and filtering.
 	Tags []string
+	// Deprecated, when set, marks the route as scheduled for removal.
+	Deprecated *Deprecation
 }
 
 // FullPattern returns the combined "METHOD /pattern" string expected by
@@ -39,6 +67,21 @@ func (r Route) FullPattern() string {
 	return r.Method + " " + r.Pattern
 }
 
+// VersionedPattern returns Pattern under VersionPrefix, e.g. "/api/tasks"
+// becomes "/api/v1/tasks".
+func (r Route) VersionedPattern() string {
+	return VersionedPath(r.Pattern)
+}
+
+// VersionedPath maps an unversioned /api/... path onto VersionPrefix. Paths
+// outside /api/ are returned unchanged.
+func VersionedPath(p string) string {
+	if rest, ok := strings.CutPrefix(p, "/api/"); ok {
+		return VersionPrefix + "/" + rest
+	}
+	return p
+}
+
 // Routes is the single source of truth for all HTTP API endpoints.
 // The order here determines the order in generated artifacts.
 var Routes = []Route{
@@ -150,6 +193,39 @@ var Routes = []Route{
 		Description: "Activate a workspace by id and switch the scoped task board.",
 		Tags:        []string{"workspaces"},
 	},
+	{
+		Method: http.MethodGet, Pattern: "/api/project-templates", Name: "ListProjectTemplates",
+		JSName:      "list",
+		Description: "List the starter project templates a workspace can be scaffolded from.",
+		Tags:        []string{"project-templates"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/project-templates/{id}/scaffold", Name: "ScaffoldProject",
+		JSName:      "scaffold",
+		Description: "Scaffold a project from a template, register and activate it as a workspace, and add a setup task.",
+		Tags:        []string{"project-templates"},
+	},
+
+	// --- Managed caches ---
+
+	{
+		Method: http.MethodGet, Pattern: "/api/caches", Name: "ListCaches",
+		JSName:      "list",
+		Description: "List the per-workspace package caches (Go, npm, pip) with their disk usage.",
+		Tags:        []string{"caches"},
+	},
+	{
+		Method: http.MethodDelete, Pattern: "/api/caches/{workspace}", Name: "ClearWorkspaceCaches",
+		JSName:      "clearWorkspace",
+		Description: "Clear every managed package cache of one workspace.",
+		Tags:        []string{"caches"},
+	},
+	{
+		Method: http.MethodDelete, Pattern: "/api/caches/{workspace}/{name}", Name: "ClearCache",
+		JSName:      "clear",
+		Description: "Clear one managed package cache of one workspace.",
+		Tags:        []string{"caches"},
+	},
 
 	// --- Routines ---
 
@@ -429,6 +505,16 @@ var Routes = []Route{
 		Description: "Test sandbox configuration by running a lightweight probe task.",
 		Tags:        []string{"env"},
 	},
+	{
+		Method: http.MethodGet, Pattern: "/api/env/probe", Name: "GetAgentProbe",
+		Description: "Latest capability probe of the implementation agent and model.",
+		Tags:        []string{"env"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/env/probe", Name: "ProbeAgent",
+		Description: "Re-run the agent capability probe and return its result.",
+		Tags:        []string{"env"},
+	},
 	// --- System prompt templates (user-overridable built-in prompts) ---
 
 	{
@@ -456,6 +542,42 @@ var Routes = []Route{
 		Tags:        []string{"system-prompts"},
 	},
 
+	// --- Prompt preamble ---
+
+	{
+		Method: http.MethodGet, Pattern: "/api/preamble", Name: "GetPreamble",
+		JSName:      "get",
+		Description: "Board prompt preamble: the current version and every saved version, newest first.",
+		Tags:        []string{"preamble"},
+	},
+	{
+		Method: http.MethodPut, Pattern: "/api/preamble", Name: "UpdatePreamble",
+		JSName:      "update",
+		Description: "Save a new board prompt preamble version, prefixed to every fresh agent prompt; an empty text turns it off.",
+		Tags:        []string{"preamble"},
+	},
+
+	// --- Prompt drafts ---
+
+	{
+		Method: http.MethodGet, Pattern: "/api/drafts", Name: "ListDrafts",
+		JSName:      "list",
+		Description: "The caller's unexpired composer drafts on the viewed board, most recently saved first.",
+		Tags:        []string{"drafts"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/drafts", Name: "SaveDraft",
+		JSName:      "save",
+		Description: "Autosave a composer draft: create one (no id) or replace the caller's draft with that id; renews its expiry.",
+		Tags:        []string{"drafts"},
+	},
+	{
+		Method: http.MethodDelete, Pattern: "/api/drafts/{id}", Name: "DeleteDraft",
+		JSName:      "delete",
+		Description: "Discard one of the caller's composer drafts.",
+		Tags:        []string{"drafts"},
+	},
+
 	// --- Whiteboard ---
 
 	{
@@ -533,13 +655,91 @@ var Routes = []Route{
 		Description: "Task status and workspace cost statistics. Optional ?workspace=<repo-root-path> restricts aggregation to tasks for that workspace (400 if no tasks match).",
 		Tags:        []string{"stats"},
 	},
+	{
+		Method: http.MethodGet, Pattern: "/api/summary", Name: "GetSummary",
+		JSName:      "summary",
+		Description: "Compact board overview for mobile triage: task counts per status and the waiting/failed tasks needing attention, newest first. ?limit= bounds the list (default 20).",
+		Tags:        []string{"stats"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/dashboard", Name: "GetDashboard",
+		Description: "Cross-board dashboard: per visible workspace, task counts, tasks needing attention, running tasks, and spend, plus totals across workspaces. ?limit= bounds each board's attention list (default 20).",
+		Tags:        []string{"stats"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/failures/signatures", Name: "ListFailureSignatures",
+		Description: "Recurring failure signatures: error events of the board's tasks grouped by normalized message, phase, and repo, with distinct task counts and a hint when a rule judges the failure likely environmental. ?days= sets the window (default 7), ?min_tasks= the minimum distinct tasks (default 2).",
+		Tags:        []string{"stats"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/search", Name: "SearchAllBoards",
+		Description: "Search the tasks of every visible workspace by keyword; each match carries its workspace_id and workspace_name.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/queue", Name: "GetQueue",
+		JSName:      "queue",
+		Description: "Auto-promotion queue: backlog tasks in start order with wait time, effective priority (critical-path score plus aging), and the reason each has not started.",
+		Tags:        []string{"stats"},
+	},
+
+	// --- Web Push notifications ---
+
+	{
+		Method: http.MethodGet, Pattern: "/api/push/config", Name: "GetPushConfig",
+		JSName:      "config",
+		Description: "Whether Web Push is enabled, plus the VAPID public key for PushManager.subscribe.",
+		Tags:        []string{"push"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/push/subscriptions", Name: "ListPushSubscriptions",
+		JSName:      "list",
+		Description: "List the caller's browser push subscriptions.",
+		Tags:        []string{"push"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/push/subscriptions", Name: "CreatePushSubscription",
+		JSName:      "subscribe",
+		Description: "Register or refresh a browser push subscription (PushSubscription.toJSON()) for the caller.",
+		Tags:        []string{"push"},
+	},
+	{
+		Method: http.MethodDelete, Pattern: "/api/push/subscriptions", Name: "DeletePushSubscription",
+		JSName:      "unsubscribe",
+		Description: "Remove one of the caller's push subscriptions by endpoint.",
+		Tags:        []string{"push"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/push/test", Name: "TestPushNotification",
+		JSName:      "test",
+		Description: "Send a sample notification to every subscription the caller owns.",
+		Tags:        []string{"push"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/push/preferences", Name: "GetPushPreferences",
+		JSName:      "preferences",
+		Description: "The caller's notification preferences: stored entries, the viewed board's key, and the preferences in effect on it.",
+		Tags:        []string{"push"},
+	},
+	{
+		Method: http.MethodPut, Pattern: "/api/push/preferences", Name: "UpdatePushPreferences",
+		JSName:      "updatePreferences",
+		Description: "Set which transitions notify, quiet hours, per-tag routing, and the digest time for one board or all boards.",
+		Tags:        []string{"push"},
+	},
+	{
+		Method: http.MethodDelete, Pattern: "/api/push/preferences", Name: "DeletePushPreferences",
+		JSName:      "deletePreferences",
+		Description: "Remove the caller's preferences for one board so the all-boards entry applies again.",
+		Tags:        []string{"push"},
+	},
 
 	// --- Task collection (no {id}) ---
 
 	{
 		Method: http.MethodGet, Pattern: "/api/tasks", Name: "ListTasks",
 		JSName:      "list",
-		Description: "List all tasks (optionally including archived).",
+		Description: "List all tasks (optionally including archived). Any of ?status= (comma-separated), ?limit= or ?cursor= switches to a paginated {tasks, total, next_cursor} response scoped to those columns. ?blocked=true|false filters on the user-set block.",
 		Tags:        []string{"tasks"},
 	},
 	{
@@ -559,6 +759,12 @@ var Routes = []Route{
 		Description: "Create multiple tasks atomically with symbolic dependency wiring.",
 		Tags:        []string{"tasks"},
 	},
+	{
+		Method: http.MethodPost, Pattern: "/api/intake", Name: "IntakeTask",
+		JSName:      "intake",
+		Description: "File a backlog task from an email, form, or chat webhook; authenticated by WALLFACER_INTAKE_TOKEN.",
+		Tags:        []string{"tasks"},
+	},
 	{
 		Method: http.MethodPost, Pattern: "/api/tasks/generate-titles", Name: "GenerateMissingTitles",
 		Description: "Bulk-generate titles for tasks that lack one.",
@@ -591,13 +797,25 @@ var Routes = []Route{
 		Description: "List soft-deleted (tombstoned) tasks that are within the retention window.",
 		Tags:        []string{"tasks"},
 	},
+	{
+		Method: http.MethodGet, Pattern: "/api/tasks/claude-sessions", Name: "ListClaudeSessions",
+		JSName:      "claudeSessions",
+		Description: "List recent Claude Code sessions started outside wallfacer in the current workspaces (?all=true for every directory, ?days=N, default 14).",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/claude-sessions/import", Name: "ImportClaudeSession",
+		JSName:      "importClaudeSession",
+		Description: "Adopt a Claude Code session as a backlog task that resumes the session when started.",
+		Tags:        []string{"tasks"},
+	},
 
 	// --- Task instance operations (require {id}) ---
 
 	{
 		Method: http.MethodPatch, Pattern: "/api/tasks/{id}", Name: "UpdateTask",
 		JSName:      "update",
-		Description: "Update task fields: status (incl. status=cancelled, which kills the worker and cleans worktrees), prompt, timeout, sandbox, dependencies, fresh_start, archived (true/false), deleted=false (restore).",
+		Description: "Update task fields: status (incl. status=cancelled, which kills the worker and cleans worktrees), title, prompt, timeout, sandbox, dependencies, fresh_start, archived (true/false), deleted=false (restore). Metadata (title, tags, links, sizing) is editable in any status; execution fields are rejected while the task is in_progress or committing.",
 		Tags:        []string{"tasks"},
 	},
 	{
@@ -606,6 +824,12 @@ var Routes = []Route{
 		Description: "Soft-delete a task (tombstone); data retained within retention window.",
 		Tags:        []string{"tasks"},
 	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/move", Name: "MoveTask",
+		JSName:      "move",
+		Description: "Reorder a task within its board column: body {after_id} or {before_id} places it next to another task in the same column; {column} alone moves it to the end. Resolved atomically by the store.",
+		Tags:        []string{"tasks"},
+	},
 	{
 		Method: http.MethodGet, Pattern: "/api/tasks/{id}/events", Name: "GetEvents",
 		Description: "Task event timeline (state changes, outputs, feedback, errors).",
@@ -616,11 +840,41 @@ var Routes = []Route{
 		Description: "Submit a feedback message to a waiting task.",
 		Tags:        []string{"tasks"},
 	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/quick-feedback", Name: "QuickFeedback",
+		Description: "Apply a canned triage response: {response: continue} resumes a waiting task with a fixed message; {response: stop} cancels the task.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/approvals/{n}", Name: "DecideApproval",
+		Description: "Approve or deny the agent's pending approval request n ({decision: approve|deny, note?}) and resume the waiting task with the decision.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/input", Name: "SendTaskInput",
+		Description: "Answer a prompt of the running interactive turn on the agent's stdin ({action: approve|deny|text, text?}).",
+		Tags:        []string{"tasks"},
+	},
 	{
 		Method: http.MethodPost, Pattern: "/api/tasks/{id}/done", Name: "CompleteTask",
 		Description: "Mark a waiting task as done and trigger commit-and-push.",
 		Tags:        []string{"tasks"},
 	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/commit", Name: "CommitTask",
+		Description: "Run the commit pipeline for a done task whose changes were left in its worktree (skip_commit).",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/approve", Name: "ApproveTask",
+		Description: "Approve the held merge of a task in review (require_approval) and finish the commit pipeline.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/reject", Name: "RejectTask",
+		Description: "Reject the held merge of a task in review, returning it to waiting or resuming it with feedback.",
+		Tags:        []string{"tasks"},
+	},
 	{
 		Method: http.MethodPost, Pattern: "/api/tasks/{id}/resume", Name: "ResumeTask",
 		Description: "Resume a failed or waiting task using its existing session.",
@@ -631,6 +885,56 @@ var Routes = []Route{
 		Description: "Rebase task worktrees onto the latest default branch.",
 		Tags:        []string{"tasks"},
 	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/rebase", Name: "RebaseTask",
+		Description: "Rebase task worktrees onto the default branch in small upstream steps, resolving conflicts per step.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/tasks/{id}/behind", Name: "TaskBehind",
+		Description: "Commits each task worktree is behind its default branch (cached, no diff rendered).",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/tasks/{id}/state", Name: "TaskState",
+		Description: "Snapshot of a task's store status next to its agent process, worktrees (presence, uncommitted changes, commits ahead and behind), and session, with any divergences spelled out.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/estimate", Name: "EstimateTask",
+		Description: "Predict effort (turns, tokens, minutes) and risk for a backlog task and store the estimate.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/fork", Name: "ForkTask",
+		Description: "Fork a waiting task into a sibling that starts from its worktrees and explores different feedback in a fresh session.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPut, Pattern: "/api/tasks/{id}/watch", Name: "WatchTask",
+		Description: "Add the signed-in caller to the task's watchers so they are notified of its state changes.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodDelete, Pattern: "/api/tasks/{id}/watch", Name: "UnwatchTask",
+		Description: "Remove the caller from the task's watchers.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/comments", Name: "CreateTaskComment",
+		Description: "Add a comment to the task's timeline; users @mentioned in it become watchers and are notified.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/experiment", Name: "CreateExperiment",
+		Description: "Create a shadow copy of a backlog task with a different sandbox, model, or instructions; it runs alongside the task and is never merged.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/tasks/{id}/experiment", Name: "GetExperiment",
+		Description: "Compare cost, turns, diff size, and verdict of both arms of the task's experiment.",
+		Tags:        []string{"tasks"},
+	},
 	{
 		Method: http.MethodPost, Pattern: "/api/tasks/{id}/test", Name: "TestTask",
 		Description: "Trigger the test agent for a task.",
@@ -657,6 +961,26 @@ var Routes = []Route{
 		Description: "Git diff of task worktrees versus the default branch.",
 		Tags:        []string{"tasks"},
 	},
+	{
+		Method: http.MethodGet, Pattern: "/api/tasks/{id}/impact", Name: "TaskImpact",
+		Description: "References to symbols the task's diff changed or removed that the diff did not update (gopls/tsserver, else git grep).",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/tasks/{id}/apply", Name: "ApplyTaskPatch",
+		Description: "Apply a task's diff as a commit on a branch of a configured workspace (cherry-pick style).",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/tasks/{id}/attempts", Name: "TaskAttempts",
+		Description: "Compare a task's attempts side by side: prompt, outcome, cost, and archived diff of each retry.",
+		Tags:        []string{"tasks"},
+	},
+	{
+		Method: http.MethodGet, Pattern: "/api/tasks/{id}/failure-hints", Name: "TaskFailureHints",
+		Description: "The failure signatures a task's errors belong to, with how many tasks share each and an environmental hint when a rule matched. ?days= sets the window (default 7).",
+		Tags:        []string{"tasks"},
+	},
 	{
 		Method: http.MethodGet, Pattern: "/api/tasks/{id}/pr", Name: "TaskPRStatus",
 		Description: "The GitHub pull request for the task's branch, or null.",
@@ -682,6 +1006,11 @@ var Routes = []Route{
 		Description: "Raw Claude Code output file for a single agent turn.",
 		Tags:        []string{"tasks"},
 	},
+	{
+		Method: http.MethodGet, Pattern: "/api/tasks/{id}/attachments/{name}", Name: "ServeTaskAttachment",
+		Description: "Download a file filed with the task through intake.",
+		Tags:        []string{"tasks"},
+	},
 	{
 		Method: http.MethodGet, Pattern: "/api/tasks/{id}/turn-usage", Name: "GetTurnUsage",
 		Description: "Per-turn token usage breakdown for a task.",
@@ -705,6 +1034,16 @@ var Routes = []Route{
 		Description: "Rebuild the in-memory search index from disk; returns the number of repaired entries.",
 		Tags:        []string{"admin"},
 	},
+	{
+		Method: http.MethodGet, Pattern: "/api/admin/audit-log", Name: "ExportAuditLog",
+		Description: "Export every event of every task, with actor attribution, as a JSON Lines audit log in time order. ?since= and ?until= (RFC 3339) bound the window; ?types= filters event types.",
+		Tags:        []string{"admin"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/admin/reload", Name: "ReloadConfig",
+		Description: "Re-read the env file, workspace group settings, and agent and flow catalogs without restarting or interrupting running tasks (same as SIGHUP).",
+		Tags:        []string{"admin"},
+	},
 
 	// --- File explorer ---
 
@@ -807,6 +1146,24 @@ var Routes = []Route{
 		Description: "Switch the active organization (latere-ui session convention). Validates membership, clears the session, and returns {redirect} to /login?org_id=<target>.",
 		Tags:        []string{"login"},
 	},
+	{
+		Method: http.MethodGet, Pattern: "/api/auth/session", Name: "AuthSession",
+		JSName:      "authSession",
+		Description: "Report whether API authentication is required, whether the caller passes it, and which sign-in methods are available.",
+		Tags:        []string{"login"},
+	},
+	{
+		Method: http.MethodPost, Pattern: "/api/auth/session", Name: "CreateAuthSession",
+		JSName:      "createAuthSession",
+		Description: "Exchange an API token for a signed UI session cookie.",
+		Tags:        []string{"login"},
+	},
+	{
+		Method: http.MethodDelete, Pattern: "/api/auth/session", Name: "DeleteAuthSession",
+		JSName:      "deleteAuthSession",
+		Description: "Clear the UI session cookie.",
+		Tags:        []string{"login"},
+	},
 	{
 		Method: http.MethodPost, Pattern: "/api/auth/device/start", Name: "AuthDeviceStart",
 		JSName:      "authDeviceStart",
@@ -0,0 +1,99 @@
+package auditlog
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// SyslogTarget is the WALLFACER_AUDIT_LOG value that sends the log to the
+// local syslog daemon instead of a file.
+const SyslogTarget = "syslog"
+
+// Record is one line of the audit log.
+type Record struct {
+	// Workspace is the key of the workspace group whose store recorded
+	// the event.
+	Workspace string `json:"workspace,omitempty"`
+	store.TaskEvent
+}
+
+// Log appends records to a file or the system log. It is safe for
+// concurrent use.
+type Log struct {
+	mu sync.Mutex
+	w  io.WriteCloser
+	// lines is set for writers that take one message per Write (syslog),
+	// which then carries no trailing newline.
+	lines bool
+}
+
+// Open opens the audit log named by target: "" disables it and returns a
+// nil *Log, SyslogTarget sends records to the local syslog daemon, and any
+// other value is a file that records are appended to, created with mode
+// 0600 when missing.
+func Open(target string) (*Log, error) {
+	switch target {
+	case "":
+		return nil, nil
+	case SyslogTarget:
+		w, err := openSyslog()
+		if err != nil {
+			return nil, err
+		}
+		return &Log{w: w, lines: true}, nil
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{w: f}, nil
+}
+
+// Append writes rec as one line. A failed write is logged and dropped so
+// auditing never blocks the event it records. Append on a nil *Log is a
+// no-op.
+func (l *Log) Append(rec Record) {
+	if l == nil {
+		return
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		logger.Store.Warn("audit log: encode event", "task", rec.TaskID, "error", err)
+		return
+	}
+	if !l.lines {
+		line = append(line, '\n')
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(line); err != nil {
+		logger.Store.Warn("audit log: write event", "task", rec.TaskID, "error", err)
+	}
+}
+
+// Sink returns an event sink for the store of workspace group key, for
+// [store.Store.SetEventSink]. It returns nil on a nil *Log.
+func (l *Log) Sink(key string) func(store.TaskEvent) {
+	if l == nil {
+		return nil
+	}
+	return func(ev store.TaskEvent) {
+		l.Append(Record{Workspace: key, TaskEvent: ev})
+	}
+}
+
+// Close closes the underlying file or syslog connection. Close on a nil
+// *Log is a no-op.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Close()
+}
@@ -0,0 +1,96 @@
+package auditlog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func readRecords(t *testing.T, path string) []Record {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recs []Record
+	for line := range strings.SplitSeq(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestOpen_Disabled(t *testing.T) {
+	l, err := Open("")
+	if err != nil || l != nil {
+		t.Fatalf("Open(\"\") = %v, %v; want nil, nil", l, err)
+	}
+	// A nil log is safe to use.
+	l.Append(Record{})
+	if l.Sink("ws") != nil {
+		t.Error("nil log returned a sink")
+	}
+	if err := l.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLog_AppendsAcrossOpens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	taskID := uuid.New()
+	ev := store.TaskEvent{
+		ID:        1,
+		TaskID:    taskID,
+		EventType: store.EventTypeFeedback,
+		Data:      json.RawMessage(`{"message":"ship it"}`),
+		CreatedAt: time.Now().UTC(),
+		ActorSub:  "user-1",
+		ActorType: string(store.ActorUser),
+	}
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Sink("ws-a")(ev)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev.ID = 2
+	l.Sink("ws-b")(ev)
+	_ = l.Close()
+
+	recs := readRecords(t, path)
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2", len(recs))
+	}
+	if recs[0].Workspace != "ws-a" || recs[1].Workspace != "ws-b" || recs[1].ID != 2 {
+		t.Errorf("records = %+v", recs)
+	}
+	if recs[0].TaskID != taskID || recs[0].ActorSub != "user-1" || string(recs[0].Data) != `{"message":"ship it"}` {
+		t.Errorf("event not preserved: %+v", recs[0])
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestOpen_BadPath(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing", "audit.jsonl")); err == nil {
+		t.Error("expected an error for a file in a missing directory")
+	}
+}
@@ -0,0 +1,21 @@
+// Package auditlog writes task events as an append-only JSON Lines audit log:
+// what each agent did, and who started, reviewed, and approved the work.
+//
+// Every line is a [Record]: one [store.TaskEvent] with its actor attribution,
+// tagged with the key of the workspace group whose store recorded it. The
+// same format serves the one-off export behind GET /api/admin/audit-log and
+// the continuous log a server keeps when WALLFACER_AUDIT_LOG names a file or
+// "syslog".
+//
+// # Connected packages
+//
+// Depends on [store] for the event model. Consumed by [handler] (the export
+// endpoint) and [cli] (opens the continuous log at server start and attaches
+// it to each workspace store with [store.Store.SetEventSink]).
+//
+// # Usage
+//
+//	log, err := auditlog.Open(os.Getenv("WALLFACER_AUDIT_LOG"))
+//	s.SetEventSink(log.Sink(workspaceKey))
+//	defer log.Close()
+package auditlog
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package auditlog
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// openSyslog connects to the local syslog daemon. Records are logged at
+// notice level under the "wallfacer" tag.
+func openSyslog() (io.WriteCloser, error) {
+	return syslog.New(syslog.LOG_NOTICE|syslog.LOG_USER, "wallfacer")
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package auditlog
+
+import (
+	"errors"
+	"io"
+)
+
+// openSyslog fails: there is no syslog daemon to send records to. Use a
+// file target instead.
+func openSyslog() (io.WriteCloser, error) {
+	return nil, errors.New("syslog audit log is not supported on this platform; set WALLFACER_AUDIT_LOG to a file path")
+}
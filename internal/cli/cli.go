@@ -36,8 +36,13 @@ func PrintUsage() {
 	fmt.Fprintf(os.Stderr, "Commands:\n")
 	fmt.Fprintf(os.Stderr, "  run          start the task board server\n")
 	fmt.Fprintf(os.Stderr, "  status       print running board state to terminal\n")
+	fmt.Fprintf(os.Stderr, "  new          scaffold a project from a starter template onto the board\n")
 	fmt.Fprintf(os.Stderr, "  spec         spec document tools (new, validate)\n")
 	fmt.Fprintf(os.Stderr, "  auth         sign in to latere.ai (login, logout, whoami)\n")
+	fmt.Fprintf(os.Stderr, "  service      run the board as a login service (install, uninstall, status)\n")
+	fmt.Fprintf(os.Stderr, "  store        encrypt the task store at rest (keygen, encrypt, decrypt)\n")
+	fmt.Fprintf(os.Stderr, "  replay       re-run a task's recorded agent launch for debugging\n")
+	fmt.Fprintf(os.Stderr, "  update       replace this binary with the newest verified release\n")
 	fmt.Fprintf(os.Stderr, "  web          start the cloud web server (wallfacerd)\n")
 	fmt.Fprintf(os.Stderr, "  doctor       check prerequisites and configuration\n")
 	fmt.Fprintf(os.Stderr, "\nRun 'wallfacer <command> -help' for more information on a command.\n")
@@ -73,7 +78,11 @@ func initConfigDir(configDir, envFile string) {
 			"# Optional: default model for Codex tasks.\n" +
 			"# CODEX_DEFAULT_MODEL=codex-mini-latest\n\n" +
 			"# Optional: model for auto-generating task titles with Codex (falls back to CODEX_DEFAULT_MODEL).\n" +
-			"# CODEX_TITLE_MODEL=codex-mini-latest\n"
+			"# CODEX_TITLE_MODEL=codex-mini-latest\n\n" +
+			"# =============================================================================\n" +
+			"# Gemini CLI (set GEMINI_API_KEY, or sign in with gemini, to enable Gemini-typed tasks).\n" +
+			"# =============================================================================\n\n" +
+			"# GEMINI_API_KEY=...\n"
 		if err := os.WriteFile(envFile, []byte(content), 0600); err != nil {
 			logger.Fatal("create env file", "error", err)
 		}
@@ -107,6 +107,15 @@ func RunDoctor(configDir string, args []string) {
 	printOptionalVar(vals, "CODEX_DEFAULT_MODEL", "using Codex default")
 	printOptionalVar(vals, "CODEX_TITLE_MODEL", "falls back to CODEX_DEFAULT_MODEL")
 
+	// --- Gemini CLI sandbox credentials ---
+	fmt.Println()
+	fmt.Println("Gemini CLI sandbox:")
+	if geminiKey := vals["GEMINI_API_KEY"]; geminiKey != "" {
+		fmt.Printf("[ok] GEMINI_API_KEY is set (%s)\n", envconfig.MaskToken(geminiKey))
+	} else {
+		fmt.Printf("[ ] GEMINI_API_KEY not set (not needed after signing in with gemini)\n")
+	}
+
 	fmt.Println()
 	issues += checkHostBackend(vals)
 
@@ -189,6 +198,19 @@ func checkHostBackend(vals map[string]string) int {
 		}
 	}
 
+	gemini, geminiErr := resolveHostBinary(vals["WALLFACER_HOST_GEMINI_BINARY"], "gemini")
+	if geminiErr != nil {
+		fmt.Printf("[ ] gemini binary not found (optional; gemini-typed tasks will fail)\n")
+		fmt.Printf("    Install with: npm i -g @google/gemini-cli\n")
+	} else {
+		fmt.Printf("[ok] Gemini binary: %s\n", gemini)
+		if ver, err := cliVersion(gemini); err == nil {
+			fmt.Printf("     %s\n", strings.TrimSpace(ver))
+		} else {
+			fmt.Printf("[ ] gemini --version failed: %v\n", err)
+		}
+	}
+
 	return issues
 }
 
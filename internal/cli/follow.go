@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os/signal"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/apicontract"
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/handler"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/metrics"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+)
+
+// FollowConfig holds the settings of a follower: a `wallfacer run -follow`
+// instance that serves a read-only board mirrored from a primary server
+// instead of running agents itself.
+type FollowConfig struct {
+	LogFormat string
+	Addr      string
+	EnvFile   string
+	BasePath  string
+
+	// Primary is the base URL of the primary server, including its
+	// --base-path if it has one.
+	Primary string
+	// PrimaryToken is the primary's WALLFACER_SERVER_API_KEY, sent on every
+	// proxied request. Empty when the primary does not require one.
+	PrimaryToken string
+}
+
+// followerRoutes names the read routes a follower proxies: the board, its
+// tasks and their timelines, and the catalogs the board renders them with.
+// Every other route stays on the primary, so a read route added later is
+// not exposed until it is listed here. The host's filesystem, env file,
+// drafts, push subscriptions, terminal sessions, and audit log are left out
+// on purpose.
+var followerRoutes = map[string]bool{
+	"GetConfig":           true,
+	"ListWorkspaces":      true,
+	"ListAgents":          true,
+	"GetAgent":            true,
+	"ListFlows":           true,
+	"GetFlow":             true,
+	"GetQueue":            true,
+	"GetStats":            true,
+	"GetUsageStats":       true,
+	"GetDashboard":        true,
+	"ListTasks":           true,
+	"StreamTasks":         true,
+	"SearchTasks":         true,
+	"ListSummaries":       true,
+	"GetEvents":           true,
+	"TaskState":           true,
+	"TaskBehind":          true,
+	"TaskLineage":         true,
+	"TaskDiff":            true,
+	"TaskImpact":          true,
+	"TaskAttempts":        true,
+	"TaskFailureHints":    true,
+	"TaskPRStatus":        true,
+	"GetExperiment":       true,
+	"ReviewTranscript":    true,
+	"StreamLogs":          true,
+	"ServeOutput":         true,
+	"ServeTaskAttachment": true,
+	"GetTurnUsage":        true,
+	"GetTaskSpans":        true,
+	"GetOversight":        true,
+}
+
+// parsePrimaryURL validates the -follow value: an absolute http or https URL.
+func parsePrimaryURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimRight(strings.TrimSpace(raw), "/"))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("-follow must be an absolute http or https URL (got %q)", raw)
+	}
+	return u, nil
+}
+
+// newFollowerProxy returns a reverse proxy to the primary at target. The
+// browser's credentials never reach the primary: the proxy drops its
+// cookies and tokens and authenticates with token instead, as a header
+// for regular routes and as the ?token= query SSE streams read.
+// Responses are flushed as they arrive so SSE streams stay live.
+func newFollowerProxy(target *url.URL, token string) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.Header.Del("Cookie")
+			pr.Out.Header.Del("Origin")
+			pr.Out.Header.Del("Referer")
+			pr.Out.Header.Del("Authorization")
+			q := pr.Out.URL.Query()
+			q.Del("token")
+			if token != "" {
+				pr.Out.Header.Set("Authorization", "Bearer "+token)
+				q.Set("token", token)
+			}
+			pr.Out.URL.RawQuery = q.Encode()
+		},
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Handler.Warn("follow: primary request failed", "path", r.URL.Path, "error", err)
+			httpjson.Write(w, http.StatusBadGateway, map[string]string{
+				"error": "primary server unreachable: " + err.Error(),
+			})
+		},
+	}
+}
+
+// buildFollowerMux routes a follower's requests. The read routes listed in
+// followerRoutes go to proxy; every other /api/ request is refused, writes with
+// a 403 that points at the primary. The terminal WebSocket is not in the
+// contract, so it is refused too.
+func buildFollowerMux(proxy http.Handler, primary string) *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, route := range apicontract.Routes {
+		if route.Method != http.MethodGet || !followerRoutes[route.Name] {
+			continue
+		}
+		mux.Handle(route.FullPattern(), proxy)
+	}
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			httpjson.Write(w, http.StatusNotFound, map[string]string{
+				"error": "not available on a read-only follower",
+			})
+			return
+		}
+		httpjson.Write(w, http.StatusForbidden, map[string]string{
+			"error":   "read-only follower; make changes on the primary at " + primary,
+			"primary": primary,
+		})
+	})
+	return mux
+}
+
+// RunFollower serves a read-only board mirrored from cfg.Primary until it
+// receives a shutdown signal. It runs no agents and opens no workspaces,
+// so it needs neither the claude CLI nor a data directory.
+func RunFollower(cfg FollowConfig, vueDist fs.FS) {
+	logger.Init(cfg.LogFormat)
+	primary, err := parsePrimaryURL(cfg.Primary)
+	if err != nil {
+		logger.Fatal("follow", "error", err)
+	}
+
+	// The follower guards its own board with its own key, if it has one.
+	var apiKey string
+	if parsed, err := envconfig.Parse(cfg.EnvFile); err == nil {
+		apiKey = parsed.ServerAPIKey
+	}
+
+	basePath := handler.NormalizeBasePath(cfg.BasePath)
+	mux := buildFollowerMux(newFollowerProxy(primary, cfg.PrimaryToken), primary.String())
+	mountVueSPA(mux, vueDist, IndexViewData{
+		ServerAPIKey: apiKey,
+		BasePath:     basePath,
+		FollowURL:    primary.String(),
+	}, false)
+
+	var srvHandler http.Handler = mux
+	srvHandler = handler.BearerAuthMiddleware(apiKey)(srvHandler)
+	srvHandler = handler.APIVersionMiddleware(loggingMiddleware(srvHandler, metrics.NewRegistry()))
+	srvHandler = handler.BasePathMiddleware(basePath)(srvHandler)
+
+	ctx, stop := signal.NotifyContext(context.Background(), shutdownSignals...)
+	defer stop()
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		logger.Fatal("listen", "error", err)
+	}
+	srv := &http.Server{
+		Handler:           srvHandler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	srvErr := make(chan error, 1)
+	go func() {
+		srvErr <- srv.Serve(ln)
+	}()
+	logger.Main.Info("following", "primary", primary.String(), "addr", ln.Addr().String(), "base_path", basePath)
+
+	select {
+	case <-ctx.Done():
+		logger.Main.Info("received shutdown signal, shutting down gracefully")
+	case err := <-srvErr:
+		if err != nil && err != http.ErrServerClosed {
+			logger.Fatal("server", "error", err)
+		}
+		return
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Main.Error("http server shutdown", "error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/apicontract"
+)
+
+// newTestFollower returns a follower mux in front of a primary that records
+// the last request it saw.
+func newTestFollower(t *testing.T, token string) (http.Handler, **http.Request) {
+	t.Helper()
+	var seen *http.Request
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `[]`)
+	}))
+	t.Cleanup(primary.Close)
+	target, err := parsePrimaryURL(primary.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buildFollowerMux(newFollowerProxy(target, token), target.String()), &seen
+}
+
+func TestFollower_ProxiesReadsWithPrimaryToken(t *testing.T) {
+	mux, seen := newTestFollower(t, "primary-key")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/stream?token=follower-key", nil)
+	req.Header.Set("Authorization", "Bearer follower-key")
+	req.Header.Set("Cookie", "session=x")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "[]" {
+		t.Fatalf("GET proxied = %d %q, want the primary's response", rec.Code, rec.Body.String())
+	}
+	got := *seen
+	if got == nil {
+		t.Fatal("request never reached the primary")
+	}
+	if got.URL.Path != "/api/tasks/stream" {
+		t.Errorf("primary path = %q", got.URL.Path)
+	}
+	if a := got.Header.Get("Authorization"); a != "Bearer primary-key" {
+		t.Errorf("Authorization = %q, want the primary token", a)
+	}
+	if q := got.URL.Query().Get("token"); q != "primary-key" {
+		t.Errorf("token query = %q, want the primary token", q)
+	}
+	if c := got.Header.Get("Cookie"); c != "" {
+		t.Errorf("browser cookie forwarded to the primary: %q", c)
+	}
+}
+
+func TestFollower_RefusesWritesAndUnlistedRoutes(t *testing.T) {
+	mux, seen := newTestFollower(t, "")
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/tasks", http.StatusForbidden},
+		{http.MethodPatch, "/api/tasks/00000000-0000-0000-0000-000000000000", http.StatusForbidden},
+		{http.MethodGet, "/api/env", http.StatusNotFound},
+		{http.MethodGet, "/api/terminal/ws", http.StatusNotFound},
+		// Read routes not on the allowlist stay on the primary.
+		{http.MethodGet, "/api/push/subscriptions", http.StatusNotFound},
+		{http.MethodGet, "/api/tasks/claude-sessions", http.StatusNotFound},
+		{http.MethodGet, "/api/drafts", http.StatusNotFound},
+		{http.MethodGet, "/api/workspaces/browse", http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, strings.NewReader("{}")))
+		if rec.Code != tc.want {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.path, rec.Code, tc.want)
+		}
+	}
+	if *seen != nil {
+		t.Errorf("refused request reached the primary: %s %s", (*seen).Method, (*seen).URL)
+	}
+}
+
+// TestFollowerRoutes_InContract guards the allowlist against renamed or
+// removed routes, which would silently drop a board read from followers.
+func TestFollowerRoutes_InContract(t *testing.T) {
+	gets := map[string]bool{}
+	for _, route := range apicontract.Routes {
+		if route.Method == http.MethodGet {
+			gets[route.Name] = true
+		}
+	}
+	for name := range followerRoutes {
+		if !gets[name] {
+			t.Errorf("followerRoutes lists %q, which is not a GET route of the API contract", name)
+		}
+	}
+}
+
+func TestParsePrimaryURL(t *testing.T) {
+	u, err := parsePrimaryURL(" https://board.example.com/wallfacer/ ")
+	if err != nil || u.String() != (&url.URL{Scheme: "https", Host: "board.example.com", Path: "/wallfacer"}).String() {
+		t.Errorf("parsePrimaryURL = %v, %v", u, err)
+	}
+	for _, bad := range []string{"board.example.com", "ftp://board.example.com", "/wallfacer"} {
+		if _, err := parsePrimaryURL(bad); err == nil {
+			t.Errorf("parsePrimaryURL(%q) accepted", bad)
+		}
+	}
+}
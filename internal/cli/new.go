@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/scaffold"
+)
+
+// RunNew implements `wallfacer new`: it scaffolds a project from a starter
+// template and, unless -no-board is given, asks the running server to
+// register it as a workspace with a first setup task.
+func RunNew(configDir string, args []string) {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	addr := fs.String("addr", envOrDefault("ADDR", "http://localhost:8080"), "wallfacer server address (or ADDR env var)")
+	name := fs.String("name", "", "project name (default: the directory's base name)")
+	module := fs.String("module", "", "Go module path for Go templates (default: the project name)")
+	list := fs.Bool("list", false, "list the available templates and exit")
+	noBoard := fs.Bool("no-board", false, "only scaffold the directory; do not register it with the server")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: wallfacer new [flags] <template> [dir]\n\n")
+		fmt.Fprintf(os.Stderr, "Scaffold a project from a starter template into dir (default: ./<template>),\n")
+		fmt.Fprintf(os.Stderr, "commit it to a new git repository, add it to the board as a workspace,\n")
+		fmt.Fprintf(os.Stderr, "and create a first \"Set up the project\" task.\n\n")
+		fmt.Fprintf(os.Stderr, "Templates:\n")
+		for _, t := range scaffold.Templates() {
+			fmt.Fprintf(os.Stderr, "  %-12s %s\n", t.ID, t.Description)
+		}
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if *list {
+		for _, t := range scaffold.Templates() {
+			fmt.Printf("%-12s %s\n", t.ID, t.Description)
+		}
+		return
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	id := fs.Arg(0)
+	dir, err := filepath.Abs(cmp.Or(fs.Arg(1), id))
+	if err == nil {
+		err = runNew(configDir, strings.TrimRight(*addr, "/"), id, dir, *name, *module, *noBoard)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wallfacer new: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runNew(configDir, addr, id, dir, name, module string, noBoard bool) error {
+	if _, ok := scaffold.Lookup(id); !ok {
+		return fmt.Errorf("%w %q (see 'wallfacer new -list')", scaffold.ErrUnknownTemplate, id)
+	}
+	opts := scaffold.Options{Template: id, Dir: dir, Name: name, Module: module}
+	if noBoard {
+		res, err := scaffold.Create(opts)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s from the %s template (%d files).\n", res.Dir, id, len(res.Files))
+		return nil
+	}
+
+	body, _ := json.Marshal(map[string]string{"dir": dir, "name": name, "module": module})
+	req, err := http.NewRequest(http.MethodPost, addr+"/api/project-templates/"+id+"/scaffold", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key := serverAPIKey(configDir); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := (&http.Client{Timeout: time.Minute}).Do(req)
+	if err != nil {
+		return fmt.Errorf("server not reachable at %s (start it with 'wallfacer run', or pass -no-board): %w", addr, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	var out struct {
+		Project   scaffold.Result `json:"project"`
+		Workspace struct {
+			Name string `json:"name"`
+		} `json:"workspace"`
+		Task struct {
+			ID string `json:"id"`
+		} `json:"task"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	fmt.Printf("Created %s from the %s template (%d files).\n", out.Project.Dir, id, len(out.Project.Files))
+	fmt.Printf("Workspace %q is now active; its setup task %s is in the backlog at %s.\n",
+		out.Workspace.Name, out.Task.ID, addr)
+	return nil
+}
+
+// serverAPIKey returns the key the server requires of API clients, from the
+// environment or the config directory's .env file.
+func serverAPIKey(configDir string) string {
+	if key := os.Getenv("WALLFACER_SERVER_API_KEY"); key != "" {
+		return key
+	}
+	cfg, err := envconfig.Parse(envOrDefault("ENV_FILE", filepath.Join(configDir, ".env")))
+	if err != nil {
+		return ""
+	}
+	return cfg.ServerAPIKey
+}
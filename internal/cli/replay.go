@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/executor"
+	"latere.ai/x/wallfacer/internal/pkg/envutil"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// RunReplay implements `wallfacer replay`: it runs an agent launch recorded
+// in a task's invocation log again, with the same argv, environment, and
+// working directory, so a turn from a support ticket can be reproduced.
+func RunReplay(configDir string, args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	dataDir := fs.String("data", envOrDefault("DATA_DIR", filepath.Join(configDir, "data")), "data directory")
+	envFile := fs.String("env-file", envOrDefault("ENV_FILE", filepath.Join(configDir, ".env")), "env file to use when the recorded one is gone")
+	workDir := fs.String("workdir", "", "run in this directory instead of the recorded one (e.g. a copy of the worktree)")
+	role := fs.String("role", "", "replay this agent role's launch (default: the turn's last launch)")
+	dryRun := fs.Bool("dry-run", false, "print the invocation without running it")
+	list := fs.Bool("list", false, "list the task's recorded invocations and exit")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: wallfacer replay [flags] <task> <turn>\n")
+		fmt.Fprintf(os.Stderr, "       wallfacer replay -list <task>\n\n")
+		fmt.Fprintf(os.Stderr, "Run an agent launch recorded for a task again: the same CLI arguments,\n")
+		fmt.Fprintf(os.Stderr, "environment, and working directory, with secrets taken from the current\n")
+		fmt.Fprintf(os.Stderr, "environment. <task> is a task ID or a unique prefix of one; turn 0 selects\n")
+		fmt.Fprintf(os.Stderr, "launches outside the turn loop (title, oversight, commit message).\n")
+		fmt.Fprintf(os.Stderr, "The agent's output goes to stdout and stderr. It works in the recorded\n")
+		fmt.Fprintf(os.Stderr, "directory, which is usually the task's worktree, unless -workdir is given.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	want := 2
+	if *list {
+		want = 1
+	}
+	if fs.NArg() != want {
+		fs.Usage()
+		os.Exit(2)
+	}
+	dir, taskID, err := findTaskDataDir(*dataDir, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wallfacer replay: %v\n", err)
+		os.Exit(1)
+	}
+	invs, err := store.LoadInvocations(dir, taskID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wallfacer replay: %v\n", err)
+		os.Exit(1)
+	}
+	if *list {
+		printInvocations(os.Stdout, invs)
+		return
+	}
+	turn, err := strconv.Atoi(fs.Arg(1))
+	if err != nil || turn < 0 {
+		fmt.Fprintf(os.Stderr, "wallfacer replay: invalid turn %q\n", fs.Arg(1))
+		os.Exit(2)
+	}
+	inv, err := selectInvocation(invs, turn, *role)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wallfacer replay: %v\n", err)
+		os.Exit(1)
+	}
+	printInvocation(os.Stderr, taskID, inv)
+	if *dryRun {
+		return
+	}
+	code, err := replayInvocation(inv, *envFile, *workDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wallfacer replay: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+// findTaskDataDir locates the task ref (an ID or a unique ID prefix) under
+// dataDir, which holds one directory per workspace, and returns the
+// workspace directory holding it along with the full task ID.
+func findTaskDataDir(dataDir, ref string) (string, uuid.UUID, error) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if ref == "" {
+		return "", uuid.Nil, errors.New("empty task ID")
+	}
+	scopes, err := os.ReadDir(dataDir)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	var dir string
+	var found []uuid.UUID
+	for _, scope := range scopes {
+		if !scope.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(dataDir, scope.Name()))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			id, err := uuid.Parse(e.Name())
+			if err != nil || !e.IsDir() || !strings.HasPrefix(id.String(), ref) {
+				continue
+			}
+			dir = filepath.Join(dataDir, scope.Name())
+			found = append(found, id)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", uuid.Nil, fmt.Errorf("no task %s under %s", ref, dataDir)
+	case 1:
+		return dir, found[0], nil
+	}
+	return "", uuid.Nil, fmt.Errorf("task ID %s is ambiguous (%d tasks match)", ref, len(found))
+}
+
+// selectInvocation returns the last launch of turn, of role when one is
+// given. A turn runs more than once when the stall watchdog restarts it or
+// it falls back to another harness; the last launch is the one whose
+// output the task kept.
+func selectInvocation(invs []store.Invocation, turn int, role string) (store.Invocation, error) {
+	for _, inv := range slices.Backward(invs) {
+		if inv.Turn == turn && (role == "" || inv.Role == role) {
+			return inv, nil
+		}
+	}
+	if role != "" {
+		return store.Invocation{}, fmt.Errorf("no %s launch recorded for turn %d (see -list)", role, turn)
+	}
+	return store.Invocation{}, fmt.Errorf("no launch recorded for turn %d (see -list)", turn)
+}
+
+// printInvocations writes one line per recorded launch.
+func printInvocations(w io.Writer, invs []store.Invocation) {
+	if len(invs) == 0 {
+		fmt.Fprintln(w, "No invocations recorded.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TURN\tROLE\tHARNESS\tSTARTED\tEXIT")
+	for _, inv := range invs {
+		exit := "-"
+		switch {
+		case inv.Error != "":
+			exit = "launch failed"
+		case inv.ExitCode != nil:
+			exit = strconv.Itoa(*inv.ExitCode)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", inv.Turn, inv.Role, inv.Harness,
+			inv.StartedAt.Local().Format("2006-01-02 15:04:05"), exit)
+	}
+	_ = tw.Flush()
+}
+
+// printInvocation describes the launch about to be replayed.
+func printInvocation(w io.Writer, taskID uuid.UUID, inv store.Invocation) {
+	fmt.Fprintf(w, "Task %s, turn %d, %s on %s, launched %s\n", taskID, inv.Turn, inv.Role, inv.Harness,
+		inv.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	if inv.Binary != "" {
+		fmt.Fprintf(w, "  agent:   %s (%s)\n", inv.Binary, inv.BinaryDigest)
+	}
+	fmt.Fprintf(w, "  workdir: %s\n", inv.WorkDir)
+	for _, ws := range slices.Sorted(maps.Keys(inv.Worktrees)) {
+		fmt.Fprintf(w, "  worktree: %s -> %s\n", ws, inv.Worktrees[ws])
+	}
+	fmt.Fprintf(w, "  args:    %q\n", inv.Cmd)
+	for _, k := range slices.Sorted(maps.Keys(inv.Env)) {
+		fmt.Fprintf(w, "  env:     %s=%s\n", k, inv.Env[k])
+	}
+	if inv.Interactive {
+		fmt.Fprintln(w, "  The turn was interactive; the replay sends only the prompt.")
+	}
+}
+
+// replaySpec rebuilds the launch spec of inv. Redacted variables are left
+// out, so the child inherits them from the env file and the environment
+// like every launch does; missing lists those found in neither.
+func replaySpec(inv store.Invocation, envFile, workDir string) (spec executor.ContainerSpec, missing []string) {
+	spec = executor.ContainerSpec{
+		Name:    "wallfacer-replay-" + uuid.NewString()[:8],
+		EnvFile: inv.EnvFile,
+		Env:     make(map[string]string, len(inv.Env)),
+		WorkDir: inv.WorkDir,
+		Cmd:     slices.Clone(inv.Cmd),
+		Arch:    inv.Arch,
+	}
+	if _, err := os.Stat(spec.EnvFile); spec.EnvFile == "" || err != nil {
+		spec.EnvFile = envFile
+	}
+	if workDir != "" {
+		spec.WorkDir = workDir
+	}
+	fromFile, _ := envconfig.ReadRaw(spec.EnvFile)
+	for k, v := range inv.Env {
+		if v != store.RedactedValue {
+			spec.Env[k] = v
+			continue
+		}
+		if _, ok := fromFile[k]; !ok && os.Getenv(k) == "" {
+			missing = append(missing, k)
+		}
+	}
+	slices.Sort(missing)
+	return spec, missing
+}
+
+// replayInvocation runs inv again and returns the agent's exit code.
+func replayInvocation(inv store.Invocation, envFile, workDir string) (int, error) {
+	spec, missing := replaySpec(inv, envFile, workDir)
+	if fi, err := os.Stat(spec.WorkDir); err != nil || !fi.IsDir() {
+		return 0, fmt.Errorf("working directory %s is gone (the worktree may have been cleaned up); pass -workdir", spec.WorkDir)
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %s were redacted and are not set now; the agent runs without them\n",
+			strings.Join(missing, ", "))
+	}
+	cfg, err := envconfig.Parse(spec.EnvFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	backend, err := executor.NewHostBackend(executor.HostBackendConfig{
+		ClaudeBinary:   cfg.HostClaudeBinary,
+		CodexBinary:    cfg.HostCodexBinary,
+		CursorBinary:   cfg.HostCursorBinary,
+		GeminiBinary:   cfg.HostGeminiBinary,
+		OpenCodeBinary: cfg.HostOpenCodeBinary,
+		PiBinary:       cfg.HostPiBinary,
+		AgentNice:      cfg.AgentNice,
+		MaxPromptBytes: envutil.Int("WALLFACER_MAX_PROMPT_BYTES", constants.DefaultMaxPromptBytes),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), shutdownSignals...)
+	defer stop()
+	handle, err := backend.Launch(ctx, spec)
+	if err != nil {
+		return 0, err
+	}
+	var wg sync.WaitGroup
+	wg.Go(func() { _, _ = io.Copy(os.Stdout, handle.Stdout()) })
+	wg.Go(func() { _, _ = io.Copy(os.Stderr, handle.Stderr()) })
+	wg.Wait()
+	code, err := handle.Wait()
+	if rep, ok := handle.(executor.ExitReporter); ok {
+		if info, ok := rep.ExitInfo(); ok && inv.BinaryDigest != "" && info.BinaryDigest != inv.BinaryDigest {
+			fmt.Fprintf(os.Stderr, "warning: the agent binary changed since the recorded run (%s, recorded %s)\n",
+				info.BinaryDigest, inv.BinaryDigest)
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	return code, nil
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestFindTaskDataDir(t *testing.T) {
+	data := t.TempDir()
+	a, b := uuid.MustParse("1a2b3c4d-0000-4000-8000-000000000001"), uuid.MustParse("1a2b9999-0000-4000-8000-000000000002")
+	for _, p := range []string{filepath.Join(data, "ws1", a.String()), filepath.Join(data, "ws2", b.String())} {
+		if err := os.MkdirAll(p, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, id, err := findTaskDataDir(data, "1A2B3C")
+	if err != nil || id != a || dir != filepath.Join(data, "ws1") {
+		t.Errorf("prefix lookup = %s, %s, %v", dir, id, err)
+	}
+	if _, id, err := findTaskDataDir(data, b.String()); err != nil || id != b {
+		t.Errorf("full ID lookup = %s, %v", id, err)
+	}
+	if _, _, err := findTaskDataDir(data, "1a2b"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("ambiguous prefix err = %v", err)
+	}
+	if _, _, err := findTaskDataDir(data, "ffff"); err == nil {
+		t.Error("unknown task found")
+	}
+}
+
+func TestSelectInvocation(t *testing.T) {
+	invs := []store.Invocation{
+		{Turn: 0, Role: "title"},
+		{Turn: 1, Role: "impl", Harness: "claude"},
+		{Turn: 1, Role: "impl", Harness: "codex"},
+		{Turn: 0, Role: "oversight"},
+	}
+	if inv, err := selectInvocation(invs, 1, ""); err != nil || inv.Harness != "codex" {
+		t.Errorf("turn 1 = %+v, %v; want the last launch", inv, err)
+	}
+	if inv, err := selectInvocation(invs, 0, "title"); err != nil || inv.Role != "title" {
+		t.Errorf("turn 0 title = %+v, %v", inv, err)
+	}
+	if _, err := selectInvocation(invs, 2, ""); err == nil {
+		t.Error("unrecorded turn selected")
+	}
+}
+
+func TestReplaySpec(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("ANTHROPIC_API_KEY=sk-test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	inv := store.Invocation{
+		Cmd:     []string{"-p", "fix it"},
+		EnvFile: "/gone/.env",
+		WorkDir: "/recorded/worktree",
+		Env: map[string]string{
+			"WALLFACER_AGENT":   "claude",
+			"ANTHROPIC_API_KEY": store.RedactedValue,
+			"GITHUB_TOKEN":      store.RedactedValue,
+		},
+	}
+	t.Setenv("GITHUB_TOKEN", "")
+	spec, missing := replaySpec(inv, envFile, "/tmp/copy")
+	if spec.EnvFile != envFile || spec.WorkDir != "/tmp/copy" || !slices.Equal(spec.Cmd, inv.Cmd) {
+		t.Errorf("spec = %+v", spec)
+	}
+	if spec.Env["WALLFACER_AGENT"] != "claude" {
+		t.Error("plain variable dropped")
+	}
+	if _, ok := spec.Env["ANTHROPIC_API_KEY"]; ok {
+		t.Error("redacted variable passed through")
+	}
+	if !slices.Equal(missing, []string{"GITHUB_TOKEN"}) {
+		t.Errorf("missing = %v, want GITHUB_TOKEN only", missing)
+	}
+}
+
+func TestReplayInvocation_RunsRecordedCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the agent")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "args")
+	agent := filepath.Join(dir, "claude")
+	script := "#!/bin/sh\necho \"$@\" > " + out + "\ncat >> " + out + "\nexit 3\n"
+	if err := os.WriteFile(agent, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("WALLFACER_HOST_CLAUDE_BINARY="+agent+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	inv := store.Invocation{
+		Harness:   "claude",
+		Cmd:       []string{"-p", "reproduce the bug"},
+		Env:       map[string]string{"WALLFACER_AGENT": "claude"},
+		EnvFile:   envFile,
+		WorkDir:   dir,
+		StartedAt: time.Now(),
+	}
+	code, err := replayInvocation(inv, envFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 3 {
+		t.Errorf("exit code = %d, want the agent's 3", code)
+	}
+	got, _ := os.ReadFile(out)
+	if !strings.Contains(string(got), "reproduce the bug") {
+		t.Errorf("agent saw %q, want the recorded prompt", got)
+	}
+
+	inv.WorkDir = filepath.Join(dir, "cleaned-up")
+	if _, err := replayInvocation(inv, envFile, ""); err == nil || !strings.Contains(err.Error(), "-workdir") {
+		t.Errorf("missing workdir err = %v", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"context"
 	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
 	"flag"
@@ -14,6 +16,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +24,7 @@ import (
 	"github.com/google/uuid"
 	"latere.ai/x/wallfacer/internal/agentsession"
 	"latere.ai/x/wallfacer/internal/apicontract"
+	"latere.ai/x/wallfacer/internal/auditlog"
 	"latere.ai/x/wallfacer/internal/auth"
 	"latere.ai/x/wallfacer/internal/constants"
 	"latere.ai/x/wallfacer/internal/envconfig"
@@ -29,10 +33,14 @@ import (
 	"latere.ai/x/wallfacer/internal/handler"
 	"latere.ai/x/wallfacer/internal/logger"
 	"latere.ai/x/wallfacer/internal/metrics"
+	"latere.ai/x/wallfacer/internal/pkg/devcache"
 	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/projectconfig"
 	"latere.ai/x/wallfacer/internal/prompts"
 	"latere.ai/x/wallfacer/internal/runner"
 	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/webhook"
+	"latere.ai/x/wallfacer/internal/webpush"
 	"latere.ai/x/wallfacer/internal/workspace"
 )
 
@@ -40,6 +48,19 @@ import (
 // index.html (delivered via the window.__WALLFACER__ script tag).
 type IndexViewData struct {
 	ServerAPIKey string
+	// AuthRequired is set under WALLFACER_REQUIRE_AUTH. The page then never
+	// embeds ServerAPIKey, and the SPA signs in before it loads the board.
+	AuthRequired bool
+	// BasePath is the normalized --base-path prefix ("" at the root). The
+	// SPA prefixes it to API, SSE, and WebSocket URLs and router paths.
+	BasePath string
+	// FollowURL is the primary server a read-only follower mirrors (see
+	// RunFollower), or "" on a primary. The SPA shows a read-only banner.
+	FollowURL string
+	// ServesKey, when set, reports whether the page served for a request
+	// may embed ServerAPIKey; pages it refuses carry an empty key. A
+	// reverse tunnel sets it so the key never reaches the public URL.
+	ServesKey func(*http.Request) bool
 }
 
 // ServerConfig holds the parsed flag values for RunServer.
@@ -49,6 +70,17 @@ type ServerConfig struct {
 	Addr      string
 	DataDir   string
 	EnvFile   string
+	BasePath  string
+
+	// TLS. At most one certificate source may be set: a key pair on disk,
+	// a generated self-signed certificate, or ACME for the listed domains.
+	TLSCert       string
+	TLSKey        string
+	TLSSelfSigned bool
+	ACMEDomains   string // comma-separated
+	ACMEEmail     string
+	ACMEHTTPAddr  string // listener for HTTP-01 challenges; "" disables it
+	ACMEDirectory string // ACME directory URL; "" means Let's Encrypt
 }
 
 // ServerComponents holds the initialized server components returned by initServer.
@@ -62,6 +94,16 @@ type ServerComponents struct {
 
 	// ActualPort is the TCP port the listener is bound to.
 	ActualPort int
+	// BasePath is the normalized --base-path prefix ("" at the root).
+	BasePath string
+	// TLS reports whether Ln serves HTTPS.
+	TLS bool
+	// ACMESrv answers ACME HTTP-01 challenges on ACMELn and redirects other
+	// plain-HTTP requests to HTTPS. Both are nil unless ACME is enabled.
+	ACMESrv *http.Server
+	ACMELn  net.Listener
+	// Tunnel is the reverse tunnel WALLFACER_TUNNEL configures, or nil.
+	Tunnel *publicTunnel
 }
 
 // Shutdown performs a graceful shutdown: drains HTTP connections and waits
@@ -75,6 +117,13 @@ func (sc *ServerComponents) Shutdown() {
 	if err := sc.Srv.Shutdown(shutdownCtx); err != nil {
 		logger.Main.Error("http server shutdown", "error", err)
 	}
+	if sc.ACMESrv != nil {
+		if err := sc.ACMESrv.Shutdown(shutdownCtx); err != nil {
+			logger.Main.Error("acme http server shutdown", "error", err)
+		}
+	}
+
+	sc.Tunnel.close()
 
 	if sc.AgentSession != nil && sc.AgentSession.IsRunning() {
 		logger.Main.Info("stopping agent session")
@@ -152,6 +201,37 @@ func initServer(configDir string, cfg ServerConfig, vueDist, docsFS fs.FS) *Serv
 		envCfg = parsed
 	}
 
+	// The wallfacer.yaml files are read whenever they are used; check the
+	// active workspace's up front so a broken one is reported at start
+	// rather than on the first task.
+	for _, folder := range snapshot.Workspaces {
+		if _, err := projectconfig.Load(folder); err != nil {
+			logger.Main.Warn("load "+projectconfig.Filename, "error", err)
+		} else if _, err := os.Stat(filepath.Join(folder, projectconfig.Filename)); err == nil {
+			logger.Main.Info("project config loaded", "path", filepath.Join(folder, projectconfig.Filename))
+		}
+	}
+
+	// Append every task event, from every workspace store the manager
+	// opens, to the audit log named by WALLFACER_AUDIT_LOG, and post the
+	// lifecycle events to WALLFACER_WEBHOOK_URLS.
+	auditLog, err := auditlog.Open(envCfg.AuditLog)
+	if err != nil {
+		logger.Fatal("open audit log", "target", envCfg.AuditLog, "error", err)
+	}
+	if auditLog != nil {
+		logger.Main.Info("audit log enabled", "target", envCfg.AuditLog)
+	}
+	hooks := webhook.New(envCfg.WebhookURLs, envCfg.WebhookSecret)
+	if hooks != nil {
+		logger.Main.Info("webhooks enabled", "urls", len(envCfg.WebhookURLs), "signed", envCfg.WebhookSecret != "")
+	}
+	if auditLog != nil || hooks != nil {
+		wsMgr.OnStoreOpen(func(key string, s *store.Store) {
+			s.SetEventSink(fanOutEvents(auditLog.Sink(key), hooks.Sink(key)))
+		})
+	}
+
 	reg := metrics.NewRegistry()
 
 	promptsDir := filepath.Join(configDir, "prompts")
@@ -161,10 +241,12 @@ func initServer(configDir string, cfg ServerConfig, vueDist, docsFS fs.FS) *Serv
 		Workspaces:         workspaces,
 		WorktreesDir:       worktreesDir,
 		TmpDir:             tmpDir,
+		CachesDir:          devcache.Root(configDir),
 		CodexAuthPath:      codexAuthPath,
 		HostClaudeBinary:   envCfg.HostClaudeBinary,
 		HostCodexBinary:    envCfg.HostCodexBinary,
 		HostCursorBinary:   envCfg.HostCursorBinary,
+		HostGeminiBinary:   envCfg.HostGeminiBinary,
 		HostOpenCodeBinary: envCfg.HostOpenCodeBinary,
 		HostPiBinary:       envCfg.HostPiBinary,
 		AgentNice:          envCfg.AgentNice,
@@ -188,6 +270,8 @@ func initServer(configDir string, cfg ServerConfig, vueDist, docsFS fs.FS) *Serv
 	// worktrees from completed/cancelled tasks; health watcher detects and
 	// repairs corrupted worktree checkouts.
 	go r.StartWorktreeGC(ctx)
+	go r.StartAgentPrewarm(ctx)
+	go r.StartAgentProbe(ctx)
 	go r.StartWorktreeHealthWatcher(ctx)
 
 	h := handler.NewHandler(s, r, configDir, workspaces, reg)
@@ -337,15 +421,28 @@ func initServer(configDir string, cfg ServerConfig, vueDist, docsFS fs.FS) *Serv
 	h.SetAgentSession(p)
 	r.SetAgentSession(p)
 
+	// Web Push: VAPID keys and browser subscriptions live under the config
+	// dir. A failure only disables push; the UI falls back to in-page toasts.
+	if push, err := webpush.Open(configDir); err != nil {
+		logger.Main.Warn("web push disabled", "error", err)
+	} else {
+		h.SetWebPush(push)
+	}
+	h.StartPushNotifier(ctx)
+
 	h.StartAutoPromoter(ctx)
 	h.StartAutoRetrier(ctx)
 	// Ideation is now one instance of the routine primitive — its timer
 	// lives inside the scheduler engine via a system:ideation routine.
 	h.StartRoutineEngine(ctx)
 	h.StartWaitingSyncWatcher(ctx)
+	h.StartUnblockReminders(ctx)
+	h.StartIntakePoller(ctx)
+	h.StartTrackerSync(ctx)
 	h.StartAutoTester(ctx)
 	h.StartAutoSubmitter(ctx)
 	h.StartAutoReview(ctx)
+	startReloadOnSignal(ctx, h.Reload)
 
 	reg.Gauge(
 		"wallfacer_tasks_total",
@@ -451,6 +548,11 @@ func initServer(configDir string, cfg ServerConfig, vueDist, docsFS fs.FS) *Serv
 		"Total number of autonomous actions taken by autoimplement watchers, by watcher and outcome.",
 	)
 
+	tlsCfg, acmeHandler, err := serverTLS(cfg, configDir)
+	if err != nil {
+		logger.Fatal("tls", "error", err)
+	}
+
 	// Bind the listening socket. If the requested port is taken (e.g. another
 	// wallfacer instance), fall back to an OS-assigned free port so the server
 	// still starts rather than failing outright.
@@ -466,17 +568,51 @@ func initServer(configDir string, cfg ServerConfig, vueDist, docsFS fs.FS) *Serv
 
 	actualHostPort := normalizeBrowserVisibleHostPort(cfg.Addr, ln.Addr())
 	actualPort := ln.Addr().(*net.TCPAddr).Port
+	if tlsCfg != nil {
+		ln = tls.NewListener(ln, tlsCfg)
+	}
 
-	mux := BuildMux(h, reg, IndexViewData{ServerAPIKey: envCfg.ServerAPIKey}, docsFS, vueDist, cloudMode)
+	// ACME's HTTP-01 challenge needs plain HTTP, normally on port 80. The
+	// TLS-ALPN-01 challenge works on the TLS listener alone, so a failure to
+	// bind here is logged rather than fatal.
+	var acmeSrv *http.Server
+	var acmeLn net.Listener
+	if acmeHandler != nil && cfg.ACMEHTTPAddr != "" {
+		if acmeLn, err = net.Listen("tcp", cfg.ACMEHTTPAddr); err != nil {
+			logger.Main.Warn("acme: http challenge listener unavailable; relying on TLS-ALPN", "addr", cfg.ACMEHTTPAddr, "error", err)
+			acmeLn = nil
+		} else {
+			acmeSrv = &http.Server{Handler: acmeHandler, ReadHeaderTimeout: 10 * time.Second}
+		}
+	}
 
-	// Middleware stack (outermost first): logging → CSRF → CookieAuth
-	//   → JWT OptionalAuth → bearer auth → mux.
+	basePath := handler.NormalizeBasePath(cfg.BasePath)
+	pubTunnel := newPublicTunnel(envCfg, configDir)
+	indexData := IndexViewData{ServerAPIKey: envCfg.ServerAPIKey, AuthRequired: envCfg.RequireAuth, BasePath: basePath}
+	if envCfg.RequireAuth {
+		indexData.ServerAPIKey = ""
+	}
+	apiAuth := handler.NewAPIAuth(append([]string{envCfg.ServerAPIKey}, envCfg.APITokens...), uiSessionKey(configDir), envCfg.RequireAuth)
+	h.SetAPIAuth(apiAuth)
+	h.SetTrustedOrigins(envCfg.CORSOrigins)
+	if pubTunnel != nil {
+		indexData.ServesKey = pubTunnel.servesKey
+	}
+	mux := BuildMux(h, reg, indexData, docsFS, vueDist, cloudMode)
+
+	// Middleware stack (outermost first): trusted proxy → base path
+	//   → API version → logging → CORS → CSRF → CookieAuth
+	//   → JWT OptionalAuth → API auth → mux.
+	// The trusted-proxy layer runs first so every later layer sees the
+	// client's address and host; CORS answers preflights before CSRF and
+	// auth, which would reject them.
 	// Both identity paths converge on the same *Identity context key: JWT wins
 	// when a Bearer header is present (OptionalAuth runs first, downstream
 	// from the cookie path), CookieAuth fills in when no Bearer was sent.
-	// BearerAuth downstream bypasses its static-key check once an identity is
+	// API auth downstream bypasses its token check once an identity is
 	// populated so a cookie-only browser request succeeds even in a deployment
-	// that also sets WALLFACER_SERVER_API_KEY for scripts.
+	// that also sets WALLFACER_SERVER_API_KEY or WALLFACER_API_TOKENS for
+	// scripts; it also accepts the UI session cookie minted from a token.
 	// Sign-in is always available (the login button), but only *forced* in
 	// cloud/hosted mode. A local `wallfacer run` leaves the board reachable
 	// anonymously; the user signs in only if they choose to.
@@ -484,17 +620,22 @@ func initServer(configDir string, cfg ServerConfig, vueDist, docsFS fs.FS) *Serv
 	if cloudMode {
 		srvHandler = h.ForceLogin(mux)
 	}
-	srvHandler = handler.BearerAuthMiddleware(envCfg.ServerAPIKey)(srvHandler)
+	srvHandler = apiAuth.Middleware(srvHandler)
 	srvHandler = auth.OptionalAuth(jwtValidator, srvHandler)
 	srvHandler = auth.CookieAuth(authClient, srvHandler)
 	// Mirror the UI cookie login's token into the connector's store so signing in
 	// via the board enables the outbound coordination connection automatically.
 	srvHandler = coordBridge.wrap(srvHandler)
-	srvHandler = handler.CSRFMiddleware(actualHostPort)(srvHandler)
+	srvHandler = handler.CSRFMiddleware(actualHostPort, envCfg.CORSOrigins...)(srvHandler)
+	srvHandler = handler.CORSMiddleware(envCfg.CORSOrigins)(srvHandler)
+	srvHandler = handler.APIVersionMiddleware(loggingMiddleware(srvHandler, reg))
+	srvHandler = handler.BasePathMiddleware(basePath)(srvHandler)
+	srvHandler = handler.TrustedProxyMiddleware(envCfg.TrustedProxies)(srvHandler)
 	srv := &http.Server{
-		Handler:     loggingMiddleware(srvHandler, reg),
+		Handler:     srvHandler,
 		BaseContext: func(_ net.Listener) context.Context { return ctx },
 	}
+	go pubTunnel.start(ctx, envCfg, h, actualPort, tlsCfg != nil, basePath)
 
 	return &ServerComponents{
 		Srv:          srv,
@@ -504,6 +645,11 @@ func initServer(configDir string, cfg ServerConfig, vueDist, docsFS fs.FS) *Serv
 		Ctx:          ctx,
 		Stop:         stop,
 		ActualPort:   actualPort,
+		BasePath:     basePath,
+		TLS:          tlsCfg != nil,
+		ACMESrv:      acmeSrv,
+		ACMELn:       acmeLn,
+		Tunnel:       pubTunnel,
 	}
 }
 
@@ -581,6 +727,19 @@ func loadOrCreateCookieKey(configDir string) (string, error) {
 	return key, nil
 }
 
+// uiSessionKey derives the key that signs UI session cookies from the
+// persisted cookie key, so token sign-ins survive a restart. It returns nil,
+// disabling token sign-in from the browser, when the key is unavailable.
+func uiSessionKey(configDir string) []byte {
+	key, err := loadOrCreateCookieKey(configDir)
+	if err != nil {
+		logger.Main.Warn("auth: UI session key unavailable; token sign-in disabled", "error", err)
+		return nil
+	}
+	sum := sha256.Sum256([]byte("wallfacer-ui-session:" + key))
+	return sum[:]
+}
+
 // requireClaudeOrExit fails fast when the claude CLI cannot be resolved, so
 // the user-facing `run`/`desktop` commands surface an actionable message at
 // startup instead of on the first task. The runner itself is built
@@ -607,6 +766,16 @@ func RunServer(configDir string, args []string, vueDist, docsFS fs.FS) {
 	dataDir := fs.String("data", envOrDefault("DATA_DIR", filepath.Join(configDir, "data")), "data directory")
 	envFile := fs.String("env-file", envOrDefault("ENV_FILE", filepath.Join(configDir, ".env")), "env file with credentials and runtime settings")
 	noBrowser := fs.Bool("no-browser", false, "do not open browser on start")
+	basePath := fs.String("base-path", envOrDefault("BASE_PATH", ""), "URL prefix to serve under, e.g. /wallfacer behind a reverse proxy")
+	tlsCert := fs.String("tls-cert", envOrDefault("TLS_CERT", ""), "PEM certificate chain to serve HTTPS with (requires -tls-key)")
+	tlsKey := fs.String("tls-key", envOrDefault("TLS_KEY", ""), "PEM private key for -tls-cert")
+	tlsSelfSigned := fs.Bool("tls-self-signed", false, "serve HTTPS with a generated self-signed certificate")
+	acmeDomain := fs.String("acme-domain", envOrDefault("ACME_DOMAIN", ""), "comma-separated domains to obtain ACME (Let's Encrypt) certificates for")
+	acmeEmail := fs.String("acme-email", envOrDefault("ACME_EMAIL", ""), "contact email for the ACME account")
+	acmeHTTPAddr := fs.String("acme-http-addr", envOrDefault("ACME_HTTP_ADDR", ":80"), `listen address for ACME HTTP-01 challenges and the HTTPS redirect ("" disables)`)
+	acmeDirectory := fs.String("acme-directory", envOrDefault("ACME_DIRECTORY", ""), "ACME directory URL (default Let's Encrypt production)")
+	follow := fs.String("follow", envOrDefault("FOLLOW", ""), "primary server URL to mirror as a read-only board; no agents run on this instance")
+	followToken := fs.String("follow-token", envOrDefault("FOLLOW_TOKEN", ""), "the primary's WALLFACER_SERVER_API_KEY, when it sets one")
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: wallfacer run [flags]\n\n")
 		fmt.Fprintf(os.Stderr, "Start the task board server and open the web UI.\n\n")
@@ -615,13 +784,35 @@ func RunServer(configDir string, args []string, vueDist, docsFS fs.FS) {
 	}
 	_ = fs.Parse(args)
 
+	if *follow != "" {
+		RunFollower(FollowConfig{
+			LogFormat:    *logFormat,
+			Addr:         *addr,
+			EnvFile:      *envFile,
+			BasePath:     *basePath,
+			Primary:      *follow,
+			PrimaryToken: *followToken,
+		}, vueDist)
+		return
+	}
+
 	requireClaudeOrExit(*envFile)
+	go printUpdateBanner(configDir)
 
 	sc := initServer(configDir, ServerConfig{
 		LogFormat: *logFormat,
 		Addr:      *addr,
 		DataDir:   *dataDir,
 		EnvFile:   *envFile,
+		BasePath:  *basePath,
+
+		TLSCert:       *tlsCert,
+		TLSKey:        *tlsKey,
+		TLSSelfSigned: *tlsSelfSigned,
+		ACMEDomains:   *acmeDomain,
+		ACMEEmail:     *acmeEmail,
+		ACMEHTTPAddr:  *acmeHTTPAddr,
+		ACMEDirectory: *acmeDirectory,
 	}, vueDist, docsFS)
 	defer sc.Stop()
 
@@ -631,15 +822,26 @@ func RunServer(configDir string, args []string, vueDist, docsFS fs.FS) {
 		if browserHost == "" || browserHost == "0.0.0.0" || browserHost == "::" || browserHost == "[::]" {
 			browserHost = "localhost"
 		}
-		go openBrowser(fmt.Sprintf("http://%s:%d", browserHost, sc.ActualPort))
+		scheme := "http"
+		if sc.TLS {
+			scheme = "https"
+		}
+		go openBrowser(fmt.Sprintf("%s://%s:%d%s/", scheme, browserHost, sc.ActualPort, sc.BasePath))
 	}
 
 	srvErr := make(chan error, 1)
 	go func() {
 		srvErr <- sc.Srv.Serve(sc.Ln)
 	}()
+	if sc.ACMESrv != nil {
+		go func() {
+			if err := sc.ACMESrv.Serve(sc.ACMELn); err != nil && err != http.ErrServerClosed {
+				logger.Main.Error("acme http server", "error", err)
+			}
+		}()
+	}
 
-	logger.Main.Info("listening", "addr", sc.Ln.Addr().String())
+	logger.Main.Info("listening", "addr", sc.Ln.Addr().String(), "tls", sc.TLS, "base_path", sc.BasePath)
 
 	select {
 	case <-sc.Ctx.Done():
@@ -672,7 +874,7 @@ func stripSSGContent(html string) string {
 // the legacy Go-templated UI for the root path and static assets. The
 // API routes registered by BuildMux are preserved because the SPA handler
 // only claims GET / and the /assets/ prefix, not /api/*.
-func mountVueSPA(mux *http.ServeMux, vueDist fs.FS, serverAPIKey string, cloudMode bool) {
+func mountVueSPA(mux *http.ServeMux, vueDist fs.FS, indexData IndexViewData, cloudMode bool) {
 	dist, err := fs.Sub(vueDist, "frontend/dist")
 	if err != nil {
 		logger.Main.Warn("vue-ui: no frontend/dist embedded", "error", err)
@@ -687,7 +889,7 @@ func mountVueSPA(mux *http.ServeMux, vueDist fs.FS, serverAPIKey string, cloudMo
 	if cloudMode {
 		mode = "cloud"
 	}
-	apiKey := serverAPIKey
+	apiKey := indexData.ServerAPIKey
 	version := Version
 
 	rawHTML, err := fs.ReadFile(dist, "index.html")
@@ -695,11 +897,14 @@ func mountVueSPA(mux *http.ServeMux, vueDist fs.FS, serverAPIKey string, cloudMo
 		logger.Main.Warn("vue-ui: failed to read index.html", "error", err)
 		return
 	}
-	inject := fmt.Sprintf(
-		`<script>window.__WALLFACER__={mode:%q,serverApiKey:%q,version:%q};</script>`,
-		mode, apiKey, version,
-	)
-	indexHTML := strings.Replace(string(rawHTML), "</head>", inject+"</head>", 1)
+	renderIndex := func(apiKey string) string {
+		inject := fmt.Sprintf(
+			`<script>window.__WALLFACER__={mode:%q,serverApiKey:%q,authRequired:%t,version:%q,basePath:%q,followUrl:%q};</script>`,
+			mode, apiKey, indexData.AuthRequired, version, indexData.BasePath, indexData.FollowURL,
+		)
+		return strings.Replace(rebaseIndexHTML(string(rawHTML), indexData.BasePath), "</head>", inject+"</head>", 1)
+	}
+	indexHTML := renderIndex(apiKey)
 	// The SSG-prerendered index.html bakes in the "/" route (ProductPage in
 	// cloud). Serving it verbatim for any other path flashes the landing page
 	// before Vue swaps in the real route, so we strip the stale markup there.
@@ -707,6 +912,7 @@ func mountVueSPA(mux *http.ServeMux, vueDist fs.FS, serverAPIKey string, cloudMo
 	// legitimately matches; everything else (local routes, cloud deep links
 	// like /dashboard) mounts from a blank #app.
 	strippedHTML := stripSSGContent(indexHTML)
+	keylessHTML := stripSSGContent(renderIndex(""))
 
 	serveVueIndex := func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -715,6 +921,10 @@ func mountVueSPA(mux *http.ServeMux, vueDist fs.FS, serverAPIKey string, cloudMo
 			_, _ = w.Write([]byte(indexHTML))
 			return
 		}
+		if indexData.ServesKey != nil && !indexData.ServesKey(r) {
+			_, _ = w.Write([]byte(keylessHTML))
+			return
+		}
 		_, _ = w.Write([]byte(strippedHTML))
 	}
 
@@ -735,6 +945,21 @@ func mountVueSPA(mux *http.ServeMux, vueDist fs.FS, serverAPIKey string, cloudMo
 	logger.Main.Info("ui: serving Vue SPA", "mode", mode)
 }
 
+// rootURLAttr matches a src or href attribute holding a root-relative URL
+// ("/x", not the protocol-relative "//host/x").
+var rootURLAttr = regexp.MustCompile(`\b(src|href)="/([^/"]|")`)
+
+// rebaseIndexHTML prefixes the root-relative asset URLs of the built
+// index.html with basePath, so the browser fetches them through the same
+// reverse-proxy subpath the page came from. An empty basePath returns html
+// unchanged.
+func rebaseIndexHTML(html, basePath string) string {
+	if basePath == "" {
+		return html
+	}
+	return rootURLAttr.ReplaceAllString(html, `$1="`+basePath+`/$2`)
+}
+
 const (
 	immutableAssetCache = "public, max-age=31536000, immutable"
 	staticAssetCache    = "public, max-age=604800, stale-while-revalidate=86400"
@@ -797,7 +1022,7 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 	mux := http.NewServeMux()
 
 	if vueDist != nil {
-		mountVueSPA(mux, vueDist, indexData.ServerAPIKey, cloudMode)
+		mountVueSPA(mux, vueDist, indexData, cloudMode)
 	}
 
 	// Docs API — list and serve embedded documentation.
@@ -1055,7 +1280,9 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 
 	handlers := map[string]http.HandlerFunc{
 		// Admin operations.
-		"RebuildIndex": adminOnly(h.RebuildIndex),
+		"RebuildIndex":   adminOnly(h.RebuildIndex),
+		"ReloadConfig":   adminOnly(h.ReloadConfig),
+		"ExportAuditLog": adminOnly(h.ExportAuditLog),
 
 		// Debug & monitoring.
 		"Health":            h.Health,
@@ -1080,6 +1307,15 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 		"DeleteWorkspace":   h.DeleteWorkspace,
 		"ActivateWorkspace": h.ActivateWorkspace,
 
+		// Starter project templates.
+		"ListProjectTemplates": h.ListProjectTemplates,
+		"ScaffoldProject":      h.ScaffoldProject,
+
+		// Managed package caches.
+		"ListCaches":           h.ListCaches,
+		"ClearWorkspaceCaches": h.ClearWorkspaceCaches,
+		"ClearCache":           h.ClearCache,
+
 		// Spec tree.
 		"GetSpecTree":               h.GetSpecTree,
 		"SpecTreeStream":            h.SpecTreeStream,
@@ -1137,6 +1373,8 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 		"GetEnvConfig":    h.GetEnvConfig,
 		"UpdateEnvConfig": h.UpdateEnvConfig,
 		"TestSandbox":     h.TestSandbox,
+		"GetAgentProbe":   h.GetAgentProbe,
+		"ProbeAgent":      h.ProbeAgent,
 
 		// System prompt templates.
 		"ListSystemPrompts":  h.ListSystemPrompts,
@@ -1144,6 +1382,15 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 		"UpdateSystemPrompt": h.UpdateSystemPrompt,
 		"DeleteSystemPrompt": h.DeleteSystemPrompt,
 
+		// Prompt preamble.
+		"GetPreamble":    h.GetPreamble,
+		"UpdatePreamble": h.UpdatePreamble,
+
+		// Prompt drafts.
+		"ListDrafts":  h.ListDrafts,
+		"SaveDraft":   h.SaveDraft,
+		"DeleteDraft": h.DeleteDraft,
+
 		// Whiteboard.
 		"GetWhiteboard": http.HandlerFunc(h.GetWhiteboard),
 		"PutWhiteboard": http.HandlerFunc(h.PutWhiteboard),
@@ -1168,42 +1415,82 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 		"OpenFolder":       h.OpenFolder,
 
 		// Usage & statistics.
-		"GetUsageStats": h.GetUsageStats,
-		"GetStats":      h.GetStats,
+		"GetUsageStats":         h.GetUsageStats,
+		"GetStats":              h.GetStats,
+		"GetSummary":            h.GetSummary,
+		"GetDashboard":          h.GetDashboard,
+		"GetQueue":              h.GetQueue,
+		"ListFailureSignatures": h.ListFailureSignatures,
+
+		// Web Push notifications.
+		"GetPushConfig":          h.GetPushConfig,
+		"ListPushSubscriptions":  h.ListPushSubscriptions,
+		"CreatePushSubscription": h.CreatePushSubscription,
+		"DeletePushSubscription": h.DeletePushSubscription,
+		"TestPushNotification":   h.TestPushNotification,
+		"GetPushPreferences":     h.GetPushPreferences,
+		"UpdatePushPreferences":  h.UpdatePushPreferences,
+		"DeletePushPreferences":  h.DeletePushPreferences,
 
 		// Task collection (no {id}).
 		"ListTasks":                h.ListTasks,
 		"StreamTasks":              h.StreamTasks,
 		"CreateTask":               h.CreateTask,
+		"IntakeTask":               h.IntakeTask,
 		"BatchCreateTasks":         h.BatchCreateTasks,
 		"GenerateMissingTitles":    h.GenerateMissingTitles,
 		"GenerateMissingOversight": h.GenerateMissingOversight,
 		"SearchTasks":              h.SearchTasks,
+		"SearchAllBoards":          h.SearchAllBoards,
 		"ArchiveAllDone":           h.ArchiveAllDone,
 		"ListSummaries":            h.ListSummaries,
 		"ListDeletedTasks":         h.ListDeletedTasks,
+		"ListClaudeSessions":       h.ListClaudeSessions,
+		"ImportClaudeSession":      h.ImportClaudeSession,
 
 		// Task instance operations (UUID extracted via withID).
-		"UpdateTask":       withID(h.UpdateTask),
-		"DeleteTask":       withID(h.DeleteTask),
-		"GetEvents":        withID(h.GetEvents),
-		"SubmitFeedback":   withID(h.SubmitFeedback),
-		"CompleteTask":     withID(h.CompleteTask),
-		"ResumeTask":       withID(h.ResumeTask),
-		"SyncTask":         withID(h.SyncTask),
-		"TestTask":         withID(h.TestTask),
-		"ReviewTask":       withID(h.ReviewTask),
-		"ReviewTranscript": withID(h.ReviewTranscript),
-		"TaskLineage":      withID(h.TaskLineage),
-
-		"TaskDiff":      withID(h.TaskDiff),
-		"TaskPRStatus":  withID(h.TaskPRStatus),
-		"CreateTaskPR":  withID(h.CreateTaskPR),
-		"TaskPRComment": withID(h.TaskPRComment),
-		"StreamLogs":    withID(h.StreamLogs),
-		"GetTurnUsage":  withID(h.GetTurnUsage),
-
-		// ServeOutput needs both {id} (UUID) and {filename} path values.
+		"UpdateTask":        withID(h.UpdateTask),
+		"MoveTask":          withID(h.MoveTask),
+		"DeleteTask":        withID(h.DeleteTask),
+		"GetEvents":         withID(h.GetEvents),
+		"SubmitFeedback":    withID(h.SubmitFeedback),
+		"QuickFeedback":     withID(h.QuickFeedback),
+		"DecideApproval":    withID(h.DecideApproval),
+		"SendTaskInput":     withID(h.SendTaskInput),
+		"CompleteTask":      withID(h.CompleteTask),
+		"CommitTask":        withID(h.CommitTask),
+		"ApproveTask":       withID(h.ApproveTask),
+		"RejectTask":        withID(h.RejectTask),
+		"ResumeTask":        withID(h.ResumeTask),
+		"SyncTask":          withID(h.SyncTask),
+		"RebaseTask":        withID(h.RebaseTask),
+		"TaskBehind":        withID(h.TaskBehind),
+		"TaskState":         withID(h.TaskState),
+		"EstimateTask":      withID(h.EstimateTask),
+		"ForkTask":          withID(h.ForkTask),
+		"WatchTask":         withID(h.WatchTask),
+		"UnwatchTask":       withID(h.UnwatchTask),
+		"CreateTaskComment": withID(h.CreateTaskComment),
+		"CreateExperiment":  withID(h.CreateExperiment),
+		"GetExperiment":     withID(h.GetExperiment),
+		"TestTask":          withID(h.TestTask),
+		"ReviewTask":        withID(h.ReviewTask),
+		"ReviewTranscript":  withID(h.ReviewTranscript),
+		"TaskLineage":       withID(h.TaskLineage),
+
+		"TaskDiff":         withID(h.TaskDiff),
+		"ApplyTaskPatch":   withID(h.ApplyTaskPatch),
+		"TaskImpact":       withID(h.TaskImpact),
+		"TaskAttempts":     withID(h.TaskAttempts),
+		"TaskFailureHints": withID(h.TaskFailureHints),
+		"TaskPRStatus":     withID(h.TaskPRStatus),
+		"CreateTaskPR":     withID(h.CreateTaskPR),
+		"TaskPRComment":    withID(h.TaskPRComment),
+		"StreamLogs":       withID(h.StreamLogs),
+		"GetTurnUsage":     withID(h.GetTurnUsage),
+
+		// ServeOutput and ServeTaskAttachment need both {id} (UUID) and a
+		// file name path value.
 		"ServeOutput": func(w http.ResponseWriter, r *http.Request) {
 			id, err := uuid.Parse(r.PathValue("id"))
 			if err != nil {
@@ -1212,6 +1499,14 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 			}
 			h.ServeOutput(w, r, id, r.PathValue("filename"))
 		},
+		"ServeTaskAttachment": func(w http.ResponseWriter, r *http.Request) {
+			id, err := uuid.Parse(r.PathValue("id"))
+			if err != nil {
+				http.Error(w, "invalid task id", http.StatusBadRequest)
+				return
+			}
+			h.ServeTaskAttachment(w, r, id, r.PathValue("name"))
+		},
 
 		// Task span / oversight analytics.
 		"GetTaskSpans": withID(h.GetTaskSpans),
@@ -1242,6 +1537,12 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 		"AuthDevicePoll":   http.HandlerFunc(h.AuthDevicePoll),
 		"AuthDeviceCancel": http.HandlerFunc(h.AuthDeviceCancel),
 
+		// Token sign-in for browsers: an API token is exchanged once for a
+		// signed session cookie, so the page never has to embed the token.
+		"AuthSession":       http.HandlerFunc(h.AuthSession),
+		"CreateAuthSession": http.HandlerFunc(h.CreateAuthSession),
+		"DeleteAuthSession": http.HandlerFunc(h.DeleteAuthSession),
+
 		// GitHub integration auth surface (spec: github-integration #1).
 		"GitHubAuthStatus":     http.HandlerFunc(h.GitHubAuthStatus),
 		"GitHubAuthConnect":    http.HandlerFunc(h.GitHubAuthConnect),
@@ -1284,11 +1585,18 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 		// System prompt templates.
 		"UpdateSystemPrompt": handler.BodyLimitDefault,
 
+		// Prompt preamble.
+		"UpdatePreamble": handler.BodyLimitDefault,
+
+		// Prompt drafts.
+		"SaveDraft": handler.BodyLimitDefault,
+
 		// Whiteboard scene (allows embedded images, so larger than default).
 		"PutWhiteboard": handler.BodyLimitWhiteboard,
 
 		// Workspace browser.
 		"MkdirWorkspace":  handler.BodyLimitDefault,
+		"ScaffoldProject": handler.BodyLimitDefault,
 		"RenameWorkspace": handler.BodyLimitDefault,
 
 		// Git workspace operations.
@@ -1301,19 +1609,33 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 
 		// Task collection.
 		"CreateTask":               handler.BodyLimitDefault,
+		"IntakeTask":               handler.BodyLimitIntake,
 		"BatchCreateTasks":         handler.BodyLimitDefault,
 		"GenerateMissingTitles":    handler.BodyLimitDefault,
 		"GenerateMissingOversight": handler.BodyLimitDefault,
 		"ArchiveAllDone":           handler.BodyLimitDefault,
+		"ImportClaudeSession":      handler.BodyLimitDefault,
 
 		// Task instance operations.
-		"UpdateTask":     handler.BodyLimitDefault,
-		"DeleteTask":     handler.BodyLimitDefault,
-		"SubmitFeedback": handler.BodyLimitFeedback,
-		"CompleteTask":   handler.BodyLimitDefault,
-		"ResumeTask":     handler.BodyLimitDefault,
-		"TestTask":       handler.BodyLimitDefault,
-		"ReviewTask":     handler.BodyLimitDefault,
+		"UpdateTask":        handler.BodyLimitDefault,
+		"MoveTask":          handler.BodyLimitDefault,
+		"DeleteTask":        handler.BodyLimitDefault,
+		"SubmitFeedback":    handler.BodyLimitFeedback,
+		"ForkTask":          handler.BodyLimitFeedback,
+		"QuickFeedback":     handler.BodyLimitDefault,
+		"DecideApproval":    handler.BodyLimitFeedback,
+		"SendTaskInput":     handler.BodyLimitFeedback,
+		"CreateTaskComment": handler.BodyLimitDefault,
+		"CompleteTask":      handler.BodyLimitDefault,
+		"CommitTask":        handler.BodyLimitDefault,
+		"ApproveTask":       handler.BodyLimitDefault,
+		"RejectTask":        handler.BodyLimitFeedback,
+		"ApplyTaskPatch":    handler.BodyLimitDefault,
+		"ResumeTask":        handler.BodyLimitDefault,
+		"TestTask":          handler.BodyLimitDefault,
+		"ReviewTask":        handler.BodyLimitDefault,
+		// Carries the shadow arm's instructions, sized like feedback.
+		"CreateExperiment": handler.BodyLimitFeedback,
 	}
 
 	// Register all routes from the contract. A missing handler entry panics at
@@ -1329,6 +1651,9 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 		if limit, ok := bodyLimits[route.Name]; ok {
 			registered = handler.MaxBytesMiddleware(limit)(registered)
 		}
+		if route.Deprecated != nil {
+			registered = handler.DeprecationMiddleware(*route.Deprecated)(registered)
+		}
 		if requiresStore(route.Name) {
 			registered = h.RequireStoreMiddleware(registered)
 		}
@@ -1342,6 +1667,10 @@ func BuildMux(h *handler.Handler, reg *metrics.Registry, indexData IndexViewData
 	// WebSocket upgrades don't follow REST request/response semantics.
 	mux.HandleFunc("GET /api/terminal/ws", h.HandleTerminalWS)
 
+	// WebSocket endpoint: typed live updates (task deltas, appended events,
+	// container state). Not in apicontract for the same reason.
+	mux.HandleFunc("GET /api/ws", h.HandleUpdatesWS)
+
 	// Sandbox trust-plane proxy. Not in apicontract because these
 	// are server-to-server calls the sandbox credential sidecar
 	// makes, not part of the browser client contract. Handlers 503
@@ -1468,9 +1797,9 @@ func loggingMiddleware(next http.Handler, reg *metrics.Registry) http.Handler {
 		}, dur.Seconds())
 
 		if strings.HasPrefix(r.URL.Path, "/api/") {
-			logger.Handler.Info(r.Method+" "+r.URL.Path, "status", sw.status, "dur", dur.Round(time.Millisecond))
+			logger.Handler.Info(r.Method+" "+r.URL.Path, "status", sw.status, "dur", dur.Round(time.Millisecond), "client", handler.ClientIP(r))
 		} else {
-			logger.Handler.Debug(r.Method+" "+r.URL.Path, "status", sw.status, "dur", dur.Round(time.Millisecond))
+			logger.Handler.Debug(r.Method+" "+r.URL.Path, "status", sw.status, "dur", dur.Round(time.Millisecond), "client", handler.ClientIP(r))
 		}
 	})
 }
@@ -1481,10 +1810,14 @@ func loggingMiddleware(next http.Handler, reg *metrics.Registry) http.Handler {
 // is not applied and requests succeed even before workspaces are configured.
 func requiresStore(name string) bool {
 	switch name {
-	case "GetConfig", "UpdateConfig", "BrowseWorkspaces", "PickFolder", "MkdirWorkspace", "RenameWorkspace", "GetEnvConfig", "UpdateEnvConfig", "TestSandbox", "GitStatus", "GitStatusStream",
+	case "GetConfig", "UpdateConfig", "BrowseWorkspaces", "PickFolder", "MkdirWorkspace", "RenameWorkspace", "GetEnvConfig", "UpdateEnvConfig", "TestSandbox", "GetAgentProbe", "ProbeAgent", "GitStatus", "GitStatusStream",
 		// Workspace management works before any workspace is open (the picker
 		// needs to list/create/activate without an active store).
-		"ListWorkspaces", "CreateWorkspace", "UpdateWorkspace", "DeleteWorkspace", "ActivateWorkspace":
+		"ListWorkspaces", "CreateWorkspace", "UpdateWorkspace", "DeleteWorkspace", "ActivateWorkspace",
+		// Scaffolding creates and activates its own workspace.
+		"ListProjectTemplates", "ScaffoldProject",
+		// Caches are keyed by workspace and live outside any store.
+		"ListCaches", "ClearWorkspaceCaches", "ClearCache":
 		return false
 	default:
 		return true
@@ -1499,13 +1832,63 @@ func requiresStore(name string) bool {
 // diff-review surface (gutter comments batched into one feedback message) is
 // restricted to signed-in users, gated server-side the same way — and since
 // feedback is a single message string whether composed inline or in the Overview
-// textarea, gating the one route covers both paths. Local mode (HasAuth false) is
-// a no-op, preserving permissive single-user runs. See RequirePrincipalMiddleware.
+// textarea, gating the one route covers both paths. QuickFeedback sends canned
+// feedback (or cancels), ForkTask sends feedback to a new fork, and
+// DecideApproval sends an approval decision as feedback, so all three are gated
+// alongside, as is SendTaskInput, which answers the agent's prompts directly.
+// Watching and commenting are attributed to the caller, so they are gated too. Local mode (HasAuth false) is a no-op, preserving
+// permissive single-user runs. See RequirePrincipalMiddleware.
 func requiresPrincipal(name string) bool {
 	switch name {
-	case "ListSpecComments", "SubmitSpecComment", "StreamSpecComments", "SubmitFeedback":
+	case "ListSpecComments", "SubmitSpecComment", "StreamSpecComments", "SubmitFeedback", "QuickFeedback", "ForkTask", "DecideApproval",
+		"SendTaskInput", "WatchTask", "UnwatchTask", "CreateTaskComment":
 		return true
 	default:
 		return false
 	}
 }
+
+// startReloadOnSignal calls reload each time one of reloadSignals arrives,
+// until ctx is done. The signals are subscribed before it returns, so they
+// no longer terminate the process.
+func startReloadOnSignal(ctx context.Context, reload func()) {
+	if len(reloadSignals) == 0 {
+		return
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, reloadSignals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ch:
+				logger.Main.Info("reload signal received")
+				reload()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// fanOutEvents combines event sinks into one for [store.Store.SetEventSink],
+// skipping nil sinks. It returns nil when every sink is nil.
+func fanOutEvents(sinks ...func(store.TaskEvent)) func(store.TaskEvent) {
+	var live []func(store.TaskEvent)
+	for _, fn := range sinks {
+		if fn != nil {
+			live = append(live, fn)
+		}
+	}
+	switch len(live) {
+	case 0:
+		return nil
+	case 1:
+		return live[0]
+	}
+	return func(ev store.TaskEvent) {
+		for _, fn := range live {
+			fn(ev)
+		}
+	}
+}
@@ -77,6 +77,50 @@ func TestContractRoutes_AllRegisteredInMux(t *testing.T) {
 	}
 }
 
+// TestContractRoutes_ServedUnderVersionPrefix verifies that, behind
+// APIVersionMiddleware, every contract route is reachable at its
+// /api/v1/... path and resolves to the same mux pattern as the unversioned
+// path.
+func TestContractRoutes_ServedUnderVersionPrefix(t *testing.T) {
+	workdir := t.TempDir()
+	s, err := storetest.NewFileStore(t, filepath.Join(workdir, "data"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+	r := runner.NewRunner(s, runner.RunnerConfig{
+		Command:      "true",
+		EnvFile:      filepath.Join(workdir, ".env"),
+		WorktreesDir: filepath.Join(workdir, "worktrees"),
+		Workspaces:   []string{workdir},
+	})
+	h := handler.NewHandler(s, r, workdir, []string{workdir}, nil)
+	mux := BuildMux(h, metrics.NewRegistry(), IndexViewData{}, testFS(t), nil, false)
+
+	// Capture the pattern the mux would match for the rewritten request.
+	var matched string
+	probe := handler.APIVersionMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		_, matched = mux.Handler(req)
+	}))
+
+	dummyID := uuid.New().String()
+	for _, route := range apicontract.Routes {
+		path := route.VersionedPattern()
+		path = strings.ReplaceAll(path, "{id}", dummyID)
+		path = strings.ReplaceAll(path, "{filename}", "turn-0001.json")
+
+		matched = ""
+		w := httptest.NewRecorder()
+		probe.ServeHTTP(w, httptest.NewRequest(route.Method, path, nil))
+		if matched != route.FullPattern() {
+			t.Errorf("%s %s: matched %q, want %q", route.Method, path, matched, route.FullPattern())
+		}
+		if got := w.Header().Get(handler.APIVersionHeader); got != apicontract.Version {
+			t.Errorf("%s %s: %s = %q", route.Method, path, handler.APIVersionHeader, got)
+		}
+	}
+}
+
 // TestIdeateRoutesRemoved guards against reintroduction of the retired
 // idea-agent / brainstorm HTTP surface. The /api/ideate status, trigger, and
 // cancel endpoints were removed with the idea-agent subsystem; any of them
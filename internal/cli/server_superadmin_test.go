@@ -95,3 +95,17 @@ func TestAdminRebuildIndex_LocalMode200(t *testing.T) {
 		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
 	}
 }
+
+// TestAdminReload_CloudRegular403 confirms the config reload sits behind the
+// same superadmin gate as the other admin operations.
+func TestAdminReload_CloudRegular403(t *testing.T) {
+	mux := newSuperadminMuxHandler(t, true)
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/reload", nil)
+	req = req.WithContext(auth.WithIdentity(req.Context(), &auth.Identity{Sub: "alice", IsSuperadmin: false}))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", w.Code)
+	}
+}
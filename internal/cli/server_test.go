@@ -703,7 +703,7 @@ func TestRequiresStore(t *testing.T) {
 	storeIndependent := []string{
 		"GetConfig", "UpdateConfig", "BrowseWorkspaces", "MkdirWorkspace",
 		"RenameWorkspace", "GetEnvConfig",
-		"UpdateEnvConfig", "TestSandbox", "GitStatus", "GitStatusStream",
+		"UpdateEnvConfig", "TestSandbox", "GetAgentProbe", "ProbeAgent", "GitStatus", "GitStatusStream",
 	}
 	for _, name := range storeIndependent {
 		if requiresStore(name) {
@@ -727,7 +727,8 @@ func TestRequiresStore(t *testing.T) {
 // task routes do not.
 func TestRequiresPrincipal(t *testing.T) {
 	gated := []string{
-		"ListSpecComments", "SubmitSpecComment", "StreamSpecComments", "SubmitFeedback",
+		"ListSpecComments", "SubmitSpecComment", "StreamSpecComments", "SubmitFeedback", "QuickFeedback", "ForkTask",
+		"WatchTask", "UnwatchTask", "CreateTaskComment",
 	}
 	for _, name := range gated {
 		if !requiresPrincipal(name) {
@@ -1107,7 +1108,7 @@ func TestMountVueSPA_NoLandingFlashOnDeepRoutes(t *testing.T) {
 
 	get := func(cloudMode bool, path string) string {
 		mux := http.NewServeMux()
-		mountVueSPA(mux, dist, "k", cloudMode)
+		mountVueSPA(mux, dist, IndexViewData{ServerAPIKey: "k"}, cloudMode)
 		rr := httptest.NewRecorder()
 		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
 		if rr.Code != http.StatusOK {
@@ -1138,3 +1139,32 @@ func TestMountVueSPA_NoLandingFlashOnDeepRoutes(t *testing.T) {
 		t.Errorf("local /: want stripped shell, got %q", body)
 	}
 }
+
+// TestMountVueSPA_BasePath verifies that a --base-path prefix reaches the
+// boot config and the built index.html's root-relative asset URLs, leaving
+// protocol-relative and absolute URLs alone.
+func TestMountVueSPA_BasePath(t *testing.T) {
+	const indexHTML = `<html><head><link rel="icon" href="/favicon.ico">` +
+		`<link rel="preconnect" href="//fonts.example"><link href="https://cdn.example/x.css">` +
+		`</head><body><div id="app"></div><script type="module" src="/assets/app.js"></script></body></html>`
+	dist := fstest.MapFS{
+		"frontend/dist/index.html": {Data: []byte(indexHTML)},
+	}
+	mux := http.NewServeMux()
+	mountVueSPA(mux, dist, IndexViewData{BasePath: "/wallfacer"}, false)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rr.Body.String()
+
+	for _, want := range []string{
+		`basePath:"/wallfacer"`,
+		`href="/wallfacer/favicon.ico"`,
+		`src="/wallfacer/assets/app.js"`,
+		`href="//fonts.example"`,
+		`href="https://cdn.example/x.css"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("index.html missing %s:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package cli
+
+import (
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+)
+
+// Service identifiers. The systemd unit and the launchd agent both run a
+// single per-user `wallfacer run`.
+const (
+	systemdUnitName = "wallfacer.service"
+	launchdLabel    = "ai.latere.wallfacer"
+)
+
+// RunService dispatches the `wallfacer service` subcommand:
+//
+//	wallfacer service install    — install and start a per-user service
+//	wallfacer service uninstall  — stop and remove it
+//	wallfacer service status     — print the service manager's view of it
+//
+// The service is a user-level systemd unit on Linux and a launchd agent on
+// macOS. It runs `wallfacer run -no-browser` from the current binary, so the
+// board comes back after a reboot or crash without a terminal session.
+func RunService(configDir string, args []string) {
+	if len(args) == 0 {
+		printServiceUsage()
+		os.Exit(2)
+	}
+	var err error
+	switch args[0] {
+	case "install":
+		err = runServiceInstall(configDir, args[1:])
+	case "uninstall":
+		err = runServiceUninstall(configDir)
+	case "status":
+		err = runServiceStatus(configDir)
+	case "-help", "--help", "-h":
+		printServiceUsage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "wallfacer service: unknown command %q\n\n", args[0])
+		printServiceUsage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wallfacer service %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+}
+
+func printServiceUsage() {
+	fmt.Fprint(os.Stderr, `Run the wallfacer board as a per-user background service that starts
+at login and restarts on failure (systemd on Linux, launchd on macOS).
+
+Usage:
+  wallfacer service install [flags]   Install and start the service
+  wallfacer service uninstall         Stop and remove the service
+  wallfacer service status            Show the service state
+
+Flags (install):
+  -addr <addr>       listen address passed to 'wallfacer run'
+  -data <dir>        data directory passed to 'wallfacer run'
+  -env-file <path>   env file passed to 'wallfacer run'
+  -dry-run           print the service definition without installing it
+
+Workspaces are read from WALLFACER_WORKSPACES in the env file, as for
+'wallfacer run'.
+`)
+}
+
+// serviceConfig describes the `wallfacer run` invocation a service starts.
+type serviceConfig struct {
+	Executable string   // absolute path of the wallfacer binary
+	Args       []string // arguments after the executable, starting with "run"
+	Path       string   // PATH for the service, so agent CLIs resolve as in the shell
+	WorkDir    string   // working directory (the user's home)
+	LogFile    string   // stdout/stderr destination (launchd only; systemd uses the journal)
+}
+
+// servicePlatform is the per-OS service manager integration.
+type servicePlatform struct {
+	name      string // "systemd" or "launchd"
+	file      string // unit or plist path
+	render    func(serviceConfig) string
+	install   [][]string // commands run after writing file
+	uninstall [][]string // commands run before removing file; failures are ignored
+	status    []string
+}
+
+// servicePlatformFor returns the integration for goos. home is the user's
+// home directory, configHome $XDG_CONFIG_HOME (may be empty), and uid the
+// user's numeric ID for launchd's gui/<uid> domain.
+func servicePlatformFor(goos, home, configHome string, uid int) (*servicePlatform, error) {
+	switch goos {
+	case "linux":
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return &servicePlatform{
+			name:   "systemd",
+			file:   filepath.Join(configHome, "systemd", "user", systemdUnitName),
+			render: renderSystemdUnit,
+			install: [][]string{
+				{"systemctl", "--user", "daemon-reload"},
+				{"systemctl", "--user", "enable", "--now", systemdUnitName},
+			},
+			uninstall: [][]string{
+				{"systemctl", "--user", "disable", "--now", systemdUnitName},
+			},
+			status: []string{"systemctl", "--user", "status", "--no-pager", systemdUnitName},
+		}, nil
+	case "darwin":
+		domain := "gui/" + strconv.Itoa(uid)
+		file := filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist")
+		return &servicePlatform{
+			name:   "launchd",
+			file:   file,
+			render: renderLaunchdPlist,
+			install: [][]string{
+				{"launchctl", "bootstrap", domain, file},
+			},
+			uninstall: [][]string{
+				{"launchctl", "bootout", domain + "/" + launchdLabel},
+			},
+			status: []string{"launchctl", "print", domain + "/" + launchdLabel},
+		}, nil
+	}
+	return nil, fmt.Errorf("service install is not supported on %s (systemd or launchd required)", goos)
+}
+
+// currentServicePlatform returns the integration for the running system.
+func currentServicePlatform() (*servicePlatform, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return servicePlatformFor(runtime.GOOS, home, os.Getenv("XDG_CONFIG_HOME"), os.Getuid())
+}
+
+func runServiceInstall(configDir string, args []string) error {
+	fs := flag.NewFlagSet("service install", flag.ExitOnError)
+	addr := fs.String("addr", "", "listen address passed to 'wallfacer run'")
+	dataDir := fs.String("data", "", "data directory passed to 'wallfacer run'")
+	envFile := fs.String("env-file", envOrDefault("ENV_FILE", filepath.Join(configDir, ".env")), "env file passed to 'wallfacer run'")
+	dryRun := fs.Bool("dry-run", false, "print the service definition without installing it")
+	_ = fs.Parse(args)
+
+	p, err := currentServicePlatform()
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate wallfacer binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	home, _ := os.UserHomeDir()
+
+	// Flags left unset are omitted so 'wallfacer run' applies its own
+	// defaults (including ADDR / DATA_DIR from the service environment).
+	runArgs := []string{"run", "-no-browser"}
+	if *addr != "" {
+		runArgs = append(runArgs, "-addr", *addr)
+	}
+	if *dataDir != "" {
+		runArgs = append(runArgs, "-data", absPath(*dataDir))
+	}
+	runArgs = append(runArgs, "-env-file", absPath(*envFile))
+
+	cfg := serviceConfig{
+		Executable: exe,
+		Args:       runArgs,
+		Path:       os.Getenv("PATH"),
+		WorkDir:    home,
+		LogFile:    filepath.Join(configDir, "logs", "service.log"),
+	}
+	content := p.render(cfg)
+
+	if *dryRun {
+		fmt.Printf("# %s\n%s", p.file, content)
+		for _, c := range p.install {
+			fmt.Println("$", strings.Join(c, " "))
+		}
+		return nil
+	}
+
+	if envCfg, err := envconfig.Parse(absPath(*envFile)); err == nil && len(envCfg.Workspaces) > 0 {
+		fmt.Printf("Workspaces: %s\n", strings.Join(envCfg.Workspaces, ", "))
+	} else {
+		fmt.Println("No workspaces configured in the env file; the board starts with none open.")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.file), 0o755); err != nil {
+		return err
+	}
+	if p.name == "launchd" {
+		if err := os.MkdirAll(filepath.Dir(cfg.LogFile), 0o755); err != nil {
+			return err
+		}
+		// Reinstalling over a loaded agent: bootstrap fails while the old
+		// definition is still loaded.
+		_ = runServiceCommands(p.uninstall, true)
+	}
+	if err := os.WriteFile(p.file, []byte(content), 0o644); err != nil {
+		return err
+	}
+	if err := runServiceCommands(p.install, false); err != nil {
+		return err
+	}
+	fmt.Printf("Installed %s service: %s\n", p.name, p.file)
+	if p.name == "systemd" {
+		fmt.Println("To start it at boot without logging in, run: loginctl enable-linger " + os.Getenv("USER"))
+	} else {
+		fmt.Printf("Logs: %s\n", cfg.LogFile)
+	}
+	return nil
+}
+
+func runServiceUninstall(_ string) error {
+	p, err := currentServicePlatform()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(p.file); errors.Is(err, os.ErrNotExist) {
+		fmt.Println("Service is not installed.")
+		return nil
+	}
+	_ = runServiceCommands(p.uninstall, true)
+	if err := os.Remove(p.file); err != nil {
+		return err
+	}
+	if p.name == "systemd" {
+		_ = runServiceCommands([][]string{{"systemctl", "--user", "daemon-reload"}}, true)
+	}
+	fmt.Printf("Removed %s service: %s\n", p.name, p.file)
+	return nil
+}
+
+func runServiceStatus(_ string) error {
+	p, err := currentServicePlatform()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(p.file); errors.Is(err, os.ErrNotExist) {
+		fmt.Println("Service is not installed. Run 'wallfacer service install'.")
+		return nil
+	}
+	fmt.Printf("Service file: %s\n\n", p.file)
+	// Both managers exit non-zero for a stopped service; the output
+	// already says so, so only a missing binary is an error.
+	out, err := cmdexec.New(p.status[0], p.status[1:]...).Combined()
+	fmt.Print(out)
+	if err != nil && out == "" {
+		return err
+	}
+	return nil
+}
+
+// runServiceCommands runs each command in order. With ignoreErrors it keeps
+// going past failures (used for teardown of a possibly-unloaded service);
+// otherwise it stops at the first failure and returns its output.
+func runServiceCommands(cmds [][]string, ignoreErrors bool) error {
+	for _, c := range cmds {
+		out, err := cmdexec.New(c[0], c[1:]...).Combined()
+		if err != nil && !ignoreErrors {
+			return fmt.Errorf("%s: %w\n%s", strings.Join(c, " "), err, strings.TrimSpace(out))
+		}
+	}
+	return nil
+}
+
+// absPath returns p made absolute, or p unchanged if that fails. Service
+// managers do not start in the shell's working directory.
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// renderSystemdUnit returns a user-level systemd unit for cfg.
+func renderSystemdUnit(cfg serviceConfig) string {
+	argv := make([]string, 0, len(cfg.Args)+1)
+	for _, a := range append([]string{cfg.Executable}, cfg.Args...) {
+		argv = append(argv, systemdQuote(a))
+	}
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=Wallfacer task board\n")
+	b.WriteString("After=network-online.target\n")
+	b.WriteString("Wants=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(argv, " "))
+	if cfg.WorkDir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(cfg.WorkDir))
+	}
+	if cfg.Path != "" {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote("PATH="+cfg.Path))
+	}
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote escapes s for a systemd unit value: specifiers (%) and
+// variable expansion ($) are doubled, and values with whitespace, quotes,
+// or backslashes are double-quoted with C-style escapes.
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	s = strings.ReplaceAll(s, "$", "$$")
+	if !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// renderLaunchdPlist returns a launchd agent plist for cfg.
+func renderLaunchdPlist(cfg serviceConfig) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	plistKeyString(&b, "Label", launchdLabel)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, a := range append([]string{cfg.Executable}, cfg.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(a))
+	}
+	b.WriteString("\t</array>\n")
+	if cfg.Path != "" {
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		fmt.Fprintf(&b, "\t\t<key>PATH</key>\n\t\t<string>%s</string>\n", xmlEscape(cfg.Path))
+		b.WriteString("\t</dict>\n")
+	}
+	if cfg.WorkDir != "" {
+		plistKeyString(&b, "WorkingDirectory", cfg.WorkDir)
+	}
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	// Restart after crashes but not after a clean exit, mirroring
+	// systemd's Restart=on-failure.
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	if cfg.LogFile != "" {
+		plistKeyString(&b, "StandardOutPath", cfg.LogFile)
+		plistKeyString(&b, "StandardErrorPath", cfg.LogFile)
+	}
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+func plistKeyString(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, "\t<key>%s</key>\n\t<string>%s</string>\n", key, xmlEscape(value))
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testServiceConfig() serviceConfig {
+	return serviceConfig{
+		Executable: "/opt/wall facer/bin/wallfacer",
+		Args:       []string{"run", "-no-browser", "-addr", ":9090", "-env-file", "/home/u/.wallfacer/.env"},
+		Path:       "/usr/local/bin:/usr/bin",
+		WorkDir:    "/home/u",
+		LogFile:    "/home/u/.wallfacer/logs/service.log",
+	}
+}
+
+func TestServicePlatformFor(t *testing.T) {
+	linux, err := servicePlatformFor("linux", "/home/u", "", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linux.name != "systemd" || linux.file != filepath.Join("/home/u", ".config", "systemd", "user", "wallfacer.service") {
+		t.Errorf("linux = %s %s", linux.name, linux.file)
+	}
+	xdg, _ := servicePlatformFor("linux", "/home/u", "/cfg", 1000)
+	if xdg.file != filepath.Join("/cfg", "systemd", "user", "wallfacer.service") {
+		t.Errorf("XDG_CONFIG_HOME not honoured: %s", xdg.file)
+	}
+
+	mac, err := servicePlatformFor("darwin", "/Users/u", "", 501)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mac.name != "launchd" || mac.file != filepath.Join("/Users/u", "Library", "LaunchAgents", "ai.latere.wallfacer.plist") {
+		t.Errorf("darwin = %s %s", mac.name, mac.file)
+	}
+	if got := strings.Join(mac.status, " "); got != "launchctl print gui/501/ai.latere.wallfacer" {
+		t.Errorf("darwin status = %q", got)
+	}
+
+	if _, err := servicePlatformFor("windows", `C:\Users\u`, "", -1); err == nil {
+		t.Error("windows: expected unsupported error")
+	}
+}
+
+func TestRenderSystemdUnit(t *testing.T) {
+	unit := renderSystemdUnit(testServiceConfig())
+	for _, want := range []string{
+		`ExecStart="/opt/wall facer/bin/wallfacer" run -no-browser -addr :9090 -env-file /home/u/.wallfacer/.env`,
+		"Environment=PATH=/usr/local/bin:/usr/bin\n",
+		"WorkingDirectory=/home/u\n",
+		"Restart=on-failure\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit missing %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	cases := map[string]string{
+		"plain":       "plain",
+		"with space":  `"with space"`,
+		`a"b`:         `"a\"b"`,
+		"100%":        "100%%",
+		"$HOME/x":     "$$HOME/x",
+		`C:\dir name`: `"C:\\dir name"`,
+	}
+	for in, want := range cases {
+		if got := systemdQuote(in); got != want {
+			t.Errorf("systemdQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRenderLaunchdPlist(t *testing.T) {
+	cfg := testServiceConfig()
+	cfg.Args = append(cfg.Args, "-data", "/data/<a&b>")
+	plist := renderLaunchdPlist(cfg)
+
+	// The plist must be well-formed XML even with markup characters in paths.
+	dec := xml.NewDecoder(strings.NewReader(plist))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("plist is not well-formed: %v\n%s", err, plist)
+			}
+			break
+		}
+	}
+	for _, want := range []string{
+		"<string>ai.latere.wallfacer</string>",
+		"<string>/opt/wall facer/bin/wallfacer</string>",
+		"<string>/data/&lt;a&amp;b&gt;</string>",
+		"<key>PATH</key>\n\t\t<string>/usr/local/bin:/usr/bin</string>",
+		"<key>RunAtLoad</key>\n\t<true/>",
+		"<key>StandardErrorPath</key>\n\t<string>/home/u/.wallfacer/logs/service.log</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %q:\n%s", want, plist)
+		}
+	}
+}
@@ -10,3 +10,7 @@ import (
 // shutdownSignals lists the OS signals that trigger a graceful server shutdown.
 // On Unix, both SIGTERM (sent by container runtimes) and SIGINT (Ctrl-C) are handled.
 var shutdownSignals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+
+// reloadSignals lists the OS signals that reload the configuration without a
+// restart. SIGHUP is the conventional reload signal for daemons.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
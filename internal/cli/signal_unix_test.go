@@ -0,0 +1,28 @@
+//go:build !windows
+
+package cli
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestStartReloadOnSignal verifies SIGHUP triggers a reload instead of the
+// default terminate action.
+func TestStartReloadOnSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan struct{}, 1)
+	startReloadOnSignal(ctx, func() { reloaded <- struct{}{} })
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGHUP did not trigger a reload")
+	}
+}
@@ -7,3 +7,7 @@ import "os"
 // shutdownSignals lists the OS signals that trigger a graceful server shutdown.
 // On Windows, only SIGINT (Ctrl-C) is supported; SIGTERM is not available.
 var shutdownSignals = []os.Signal{os.Interrupt}
+
+// reloadSignals lists the OS signals that reload the configuration. Windows
+// has no SIGHUP; use POST /api/admin/reload instead.
+var reloadSignals []os.Signal
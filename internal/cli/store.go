@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// RunStore dispatches the `wallfacer store` subcommand:
+//
+//	wallfacer store keygen    — print a new encryption key
+//	wallfacer store encrypt   — encrypt an existing plaintext data directory
+//	wallfacer store decrypt   — decrypt it back to plaintext
+//
+// encrypt and decrypt use the key from WALLFACER_STORE_KEY, the same one the
+// server reads, and must run while the server is stopped.
+func RunStore(configDir string, args []string) {
+	if len(args) == 0 {
+		printStoreUsage()
+		os.Exit(2)
+	}
+	var err error
+	switch args[0] {
+	case "keygen":
+		err = runStoreKeygen()
+	case "encrypt":
+		err = runStoreMigrate(configDir, args[1:], true)
+	case "decrypt":
+		err = runStoreMigrate(configDir, args[1:], false)
+	case "-help", "--help", "-h":
+		printStoreUsage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "wallfacer store: unknown command %q\n\n", args[0])
+		printStoreUsage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wallfacer store %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+}
+
+func printStoreUsage() {
+	fmt.Fprint(os.Stderr, `Manage encryption at rest of the task store. With `+atrest.KeyEnv+` set,
+the server encrypts task.json, traces, and outputs; these commands convert
+a data directory written before the key was set, or remove encryption.
+
+Usage:
+  wallfacer store keygen            Print a new random key
+  wallfacer store encrypt [flags]   Encrypt plaintext task files in place
+  wallfacer store decrypt [flags]   Decrypt task files back to plaintext
+
+Flags (encrypt, decrypt):
+  -data <dir>   data directory (default: $DATA_DIR or ~/.wallfacer/data)
+
+The key is read from `+atrest.KeyEnv+` as base64 or hex, file:<path>, or
+keychain:<service>. Stop the server before running encrypt or decrypt.
+`)
+}
+
+func runStoreKeygen() error {
+	key, err := atrest.GenerateKey()
+	if err != nil {
+		return err
+	}
+	fmt.Println(key)
+	return nil
+}
+
+func runStoreMigrate(configDir string, args []string, encrypt bool) error {
+	name := "store decrypt"
+	if encrypt {
+		name = "store encrypt"
+	}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	dataDir := fs.String("data", envOrDefault("DATA_DIR", filepath.Join(configDir, "data")), "data directory")
+	_ = fs.Parse(args)
+
+	c, err := atrest.FromEnv()
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return errors.New(atrest.KeyEnv + " is not set")
+	}
+	if _, err := os.Stat(*dataDir); err != nil {
+		return err
+	}
+	n, err := store.MigrateEncryption(*dataDir, c, encrypt)
+	if err != nil {
+		return fmt.Errorf("%w (%d files converted before the error; rerun to resume)", err, n)
+	}
+	verb := "Decrypted"
+	if encrypt {
+		verb = "Encrypted"
+	}
+	fmt.Printf("%s %d files under %s\n", verb, n, *dataDir)
+	return nil
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/tlscert"
+)
+
+// serverTLS resolves the TLS settings of `wallfacer run`. It returns a nil
+// config when TLS is off. For ACME it also returns the handler to serve on
+// cfg.ACMEHTTPAddr (HTTP-01 challenges and an HTTPS redirect); the other
+// modes return a nil handler. At most one certificate source may be set.
+func serverTLS(cfg ServerConfig, configDir string) (*tls.Config, http.Handler, error) {
+	fromFiles := cfg.TLSCert != "" || cfg.TLSKey != ""
+	acmeDomains := splitList(cfg.ACMEDomains)
+	sources := 0
+	for _, on := range []bool{fromFiles, cfg.TLSSelfSigned, len(acmeDomains) > 0} {
+		if on {
+			sources++
+		}
+	}
+	switch {
+	case sources > 1:
+		return nil, nil, errors.New("choose one of -tls-cert/-tls-key, -tls-self-signed, and -acme-domain")
+	case fromFiles:
+		if cfg.TLSCert == "" || cfg.TLSKey == "" {
+			return nil, nil, errors.New("-tls-cert and -tls-key must be set together")
+		}
+		tlsCfg, err := tlscert.FromFiles(cfg.TLSCert, cfg.TLSKey)
+		return tlsCfg, nil, err
+	case cfg.TLSSelfSigned:
+		tlsCfg, fingerprint, err := tlscert.SelfSigned(filepath.Join(configDir, "tls"), selfSignedHosts(cfg.Addr))
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Main.Info("tls: serving a self-signed certificate; browsers will warn until it is trusted",
+			"sha256", fingerprint, "dir", filepath.Join(configDir, "tls"))
+		return tlsCfg, nil, nil
+	case len(acmeDomains) > 0:
+		return tlscert.ACME(tlscert.ACMEConfig{
+			Domains:      acmeDomains,
+			Email:        cfg.ACMEEmail,
+			CacheDir:     filepath.Join(configDir, "acme"),
+			DirectoryURL: cfg.ACMEDirectory,
+		})
+	}
+	return nil, nil, nil
+}
+
+// selfSignedHosts returns the names a self-signed certificate should cover
+// besides localhost: the host of the listen address and the machine's host
+// name, so the board can be reached from other machines on the network.
+func selfSignedHosts(addr string) []string {
+	var hosts []string
+	if host, _, err := net.SplitHostPort(addr); err == nil && host != "" {
+		hosts = append(hosts, host)
+	}
+	if name, err := os.Hostname(); err == nil && name != "" {
+		hosts = append(hosts, name)
+	}
+	return hosts
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for part := range strings.SplitSeq(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestServerTLS_Sources verifies that TLS stays off by default, that
+// conflicting or incomplete flags are rejected, and that each source yields
+// a config.
+func TestServerTLS_Sources(t *testing.T) {
+	dir := t.TempDir()
+	if cfg, h, err := serverTLS(ServerConfig{Addr: ":8080"}, dir); cfg != nil || h != nil || err != nil {
+		t.Fatalf("default = %v, %v, %v; want TLS off", cfg, h, err)
+	}
+
+	for name, bad := range map[string]ServerConfig{
+		"cert without key":       {TLSCert: "tls.crt"},
+		"files and self-signed":  {TLSCert: "tls.crt", TLSKey: "tls.key", TLSSelfSigned: true},
+		"self-signed and acme":   {TLSSelfSigned: true, ACMEDomains: "board.example"},
+		"missing key pair files": {TLSCert: filepath.Join(dir, "no.crt"), TLSKey: filepath.Join(dir, "no.key")},
+	} {
+		if _, _, err := serverTLS(bad, dir); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+
+	cfg, h, err := serverTLS(ServerConfig{Addr: "127.0.0.1:8443", TLSSelfSigned: true}, dir)
+	if err != nil || cfg == nil || h != nil {
+		t.Fatalf("self-signed = %v, %v, %v", cfg, h, err)
+	}
+	for _, f := range []string{"self-signed.crt", "self-signed.key"} {
+		if _, err := os.Stat(filepath.Join(dir, "tls", f)); err != nil {
+			t.Errorf("self-signed %s not persisted: %v", f, err)
+		}
+	}
+
+	cfg, h, err = serverTLS(ServerConfig{ACMEDomains: "board.example, ", ACMEHTTPAddr: ":80"}, dir)
+	if err != nil || cfg == nil || h == nil {
+		t.Fatalf("acme = %v, %v, %v; want a config and a challenge handler", cfg, h, err)
+	}
+}
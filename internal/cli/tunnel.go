@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/handler"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/tunnel"
+)
+
+// tunnelStartTimeout bounds how long a tunnel provider has to report its
+// public URL.
+const tunnelStartTimeout = 60 * time.Second
+
+// tunnelURLFile is the file under the config dir that holds the public URL
+// of the running tunnel, for scripts that want to find the board.
+const tunnelURLFile = "tunnel-url"
+
+// publicTunnel is the reverse tunnel WALLFACER_TUNNEL configures. It starts
+// in the background once the server listens, so a slow provider does not
+// hold up startup, and it stops with the server.
+type publicTunnel struct {
+	configured bool
+	urlPath    string
+
+	mu  sync.Mutex
+	tun *tunnel.Tunnel
+}
+
+// newPublicTunnel returns the tunnel envCfg configures, or nil when none is.
+func newPublicTunnel(envCfg envconfig.Config, configDir string) *publicTunnel {
+	if envCfg.Tunnel == "" {
+		return nil
+	}
+	return &publicTunnel{configured: true, urlPath: filepath.Join(configDir, tunnelURLFile)}
+}
+
+// tunnelProvider resolves the provider envCfg selects.
+func tunnelProvider(envCfg envconfig.Config) (tunnel.Provider, error) {
+	if envCfg.Tunnel == tunnel.CommandProvider {
+		return tunnel.Command(envCfg.TunnelCommand)
+	}
+	p, ok := tunnel.Lookup(envCfg.Tunnel)
+	if !ok {
+		return tunnel.Provider{}, errors.New("unknown WALLFACER_TUNNEL " + envCfg.Tunnel +
+			" (want " + strings.Join(tunnel.Names(), ", ") + ", or " + tunnel.CommandProvider + ")")
+	}
+	return p, nil
+}
+
+// start opens the tunnel to the server listening on port and publishes its
+// URL to h, the log, and the tunnel-url file. A board without
+// WALLFACER_SERVER_API_KEY is never exposed: anyone with the public URL
+// could run agents on this machine.
+func (p *publicTunnel) start(ctx context.Context, envCfg envconfig.Config, h *handler.Handler, port int, tls bool, basePath string) {
+	if p == nil {
+		return
+	}
+	if strings.TrimSpace(envCfg.ServerAPIKey) == "" {
+		logger.Main.Error("tunnel: not started; set WALLFACER_SERVER_API_KEY before exposing the board", "provider", envCfg.Tunnel)
+		return
+	}
+	provider, err := tunnelProvider(envCfg)
+	if err != nil {
+		logger.Main.Error("tunnel: not started", "error", err)
+		return
+	}
+	tun, err := tunnel.Start(ctx, provider, tunnel.Target(port, tls), tunnelStartTimeout)
+	if err != nil {
+		logger.Main.Error("tunnel: start failed", "error", err)
+		return
+	}
+	public := strings.TrimRight(tun.URL.String(), "/") + basePath + "/"
+	p.mu.Lock()
+	p.tun = tun
+	p.mu.Unlock()
+	h.SetTunnelURL(public)
+	if err := os.WriteFile(p.urlPath, []byte(public+"\n"), 0o600); err != nil {
+		logger.Main.Warn("tunnel: write url file", "path", p.urlPath, "error", err)
+	}
+	logger.Main.Info("tunnel: board reachable; open it once with ?token=<WALLFACER_SERVER_API_KEY>",
+		"provider", provider.Name, "url", public)
+
+	<-tun.Done()
+	h.SetTunnelURL("")
+	_ = os.Remove(p.urlPath)
+	if ctx.Err() == nil {
+		logger.Main.Warn("tunnel: provider exited; the board is no longer reachable remotely",
+			"provider", provider.Name, "error", tun.Err())
+	}
+}
+
+// close stops the tunnel process, if one is running.
+func (p *publicTunnel) close() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	tun := p.tun
+	p.mu.Unlock()
+	if tun != nil {
+		tun.Close()
+	}
+	_ = os.Remove(p.urlPath)
+}
+
+// servesKey reports whether the SPA page for r may embed the server API
+// key. With a tunnel configured it never does: the Host and forwarding
+// headers that would tell a local request from a tunnelled one are set by
+// the client, and a raw TCP forwarder relays them untouched. Every browser,
+// local ones included, passes the key once as ?token= instead.
+func (p *publicTunnel) servesKey(*http.Request) bool {
+	return p == nil || !p.configured
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+)
+
+func TestPublicTunnel_ServesKey(t *testing.T) {
+	pt := newPublicTunnel(envconfig.Config{Tunnel: "command"}, t.TempDir())
+	// A raw TCP forwarder relays whatever Host the remote client sends, so
+	// not even a request that looks local may get the key.
+	for _, host := range []string{"localhost:8080", "127.0.0.1:8080", "[::1]:8080", "ab12.ngrok-free.app"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = host
+		if pt.servesKey(r) {
+			t.Errorf("servesKey(host %s) = true with a tunnel configured", host)
+		}
+	}
+
+	none := newPublicTunnel(envconfig.Config{}, t.TempDir())
+	if none != nil {
+		t.Error("tunnel configured without WALLFACER_TUNNEL")
+	}
+	if !none.servesKey(httptest.NewRequest(http.MethodGet, "/", nil)) {
+		t.Error("servesKey = false without a tunnel")
+	}
+}
+
+func TestMountVueSPA_WithholdsKeyWhenTunnelled(t *testing.T) {
+	pt := newPublicTunnel(envconfig.Config{Tunnel: "cloudflared"}, t.TempDir())
+	mux := http.NewServeMux()
+	mountVueSPA(mux, stubVueFS(t), IndexViewData{ServerAPIKey: "test-key", ServesKey: pt.servesKey}, false)
+
+	for _, target := range []string{"http://localhost:8080/", "http://quiet-river.trycloudflare.com/"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if strings.Contains(w.Body.String(), "test-key") || !strings.Contains(w.Body.String(), `serverApiKey:""`) {
+			t.Errorf("page for %s leaks the key: %q", target, w.Body.String())
+		}
+	}
+}
+
+func TestTunnelProvider(t *testing.T) {
+	if p, err := tunnelProvider(envconfig.Config{Tunnel: "tailscale"}); err != nil || p.Name != "tailscale" {
+		t.Errorf("tailscale = %v, %v", p.Name, err)
+	}
+	if _, err := tunnelProvider(envconfig.Config{Tunnel: "carrier-pigeon"}); err == nil || !strings.Contains(err.Error(), "ngrok") {
+		t.Errorf("unknown provider err = %v, want the known names listed", err)
+	}
+	if _, err := tunnelProvider(envconfig.Config{Tunnel: "command"}); err == nil {
+		t.Error("command provider without WALLFACER_TUNNEL_COMMAND accepted")
+	}
+	p, err := tunnelProvider(envconfig.Config{Tunnel: "command", TunnelCommand: "bore local {port} --to bore.pub"})
+	if err != nil || p.Name != "command" {
+		t.Errorf("command = %v, %v", p.Name, err)
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/selfupdate"
+)
+
+// updateCheckTimeout bounds the release lookup behind the startup banner so
+// an unreachable GitHub never delays anything.
+const updateCheckTimeout = 5 * time.Second
+
+// RunUpdate implements `wallfacer update`: it replaces the running binary
+// with the newest release after verifying it against the release's signed
+// checksum manifest.
+func RunUpdate(configDir string, args []string) {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	checkOnly := fs.Bool("check", false, "only report whether a newer release exists")
+	prerelease := fs.Bool("prerelease", selfupdate.IsPrerelease(Version), "include prereleases (default on for a prerelease build)")
+	requireSig := fs.Bool("require-signature", false, "fail unless the checksum manifest's cosign signature verifies")
+	force := fs.Bool("force", false, "reinstall even when the running version is current")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: wallfacer update [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Replace this binary with the newest GitHub release. The download is\n")
+		fmt.Fprintf(os.Stderr, "checked against the release's SHA256SUMS; when cosign is installed,\n")
+		fmt.Fprintf(os.Stderr, "the manifest's keyless signature is verified as well.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if err := runUpdate(configDir, *checkOnly, *prerelease, *requireSig, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "wallfacer update: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runUpdate(configDir string, checkOnly, prerelease, requireSig, force bool) error {
+	ctx := context.Background()
+	c := &selfupdate.Client{}
+	rel, err := c.Latest(ctx, prerelease)
+	if err != nil {
+		return err
+	}
+	current := Version
+	if current == "" {
+		current = "dev"
+	}
+	newer := selfupdate.Compare(rel.Version(), current) > 0
+	if checkOnly && newer {
+		fmt.Printf("wallfacer %s is available (running %s). Run 'wallfacer update' to install it.\n", rel.Version(), current)
+		return nil
+	}
+	if checkOnly || (!newer && !force) {
+		fmt.Printf("wallfacer %s is up to date (newest release: %s).\n", current, rel.Version())
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate running binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	fmt.Printf("Updating %s from %s to %s...\n", exe, current, rel.Version())
+	res, err := c.Install(ctx, rel, exe, selfupdate.VerifyOptions{RequireSignature: requireSig})
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("%w (re-run with permission to write %s)", err, exe)
+		}
+		return err
+	}
+	// The cached check predates this version; drop it so the banner does
+	// not report the release just installed.
+	_ = os.Remove(filepath.Join(configDir, selfupdate.CheckFile))
+
+	verified := "checksum verified; install cosign to also verify its signature"
+	if res.Signed {
+		verified = "checksum and signature verified"
+	}
+	fmt.Printf("Installed wallfacer %s (sha256 %s, %s).\n", res.Version, res.SHA256[:12], verified)
+	fmt.Println("Restart running servers to use it.")
+	return nil
+}
+
+// printUpdateBanner tells the user on startup when the running version is
+// significantly behind the newest release (see selfupdate.Outdated). Dev
+// builds and WALLFACER_NO_UPDATE_CHECK=1 skip the check; the release lookup
+// is cached for a day under the config directory, and any failure is silent.
+func printUpdateBanner(configDir string) {
+	if Version == "" || os.Getenv("WALLFACER_NO_UPDATE_CHECK") != "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+	c := &selfupdate.Client{}
+	latest, err := c.CheckCached(ctx, filepath.Join(configDir, selfupdate.CheckFile), selfupdate.IsPrerelease(Version), time.Now())
+	if err != nil || !selfupdate.Outdated(Version, latest) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\n  wallfacer %s is available (running %s).\n  Run 'wallfacer update' to upgrade.\n\n", latest, Version)
+}
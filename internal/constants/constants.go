@@ -16,6 +16,14 @@ const DefaultTaskTimeout = 60 * time.Minute
 // because the agent is headless and only needs to emit a 2–5 word summary.
 const TitleAgentTimeout = 60 * time.Second
 
+// DefaultTitleConcurrency is the number of title-generation workers that may
+// run at once. Title requests beyond it wait in the runner's title queue.
+const DefaultTitleConcurrency = 2
+
+// DefaultTitleBatchSize is the most queued tasks a single title-generation
+// agent call names at once.
+const DefaultTitleBatchSize = 5
+
 // OversightAgentTimeout bounds the oversight-summary agent. Generous
 // because oversight reads the full task event timeline before summarizing.
 const OversightAgentTimeout = 3 * time.Minute
@@ -24,6 +32,14 @@ const OversightAgentTimeout = 3 * time.Minute
 // oversight because the input (diff stat + recent log) is small.
 const CommitMessageAgentTimeout = 90 * time.Second
 
+// LocalizeAgentTimeout bounds the agent that translates a task's title and
+// final summary. Between title and oversight: the summary can run long.
+const LocalizeAgentTimeout = 90 * time.Second
+
+// AgentProbeTimeout bounds the capability probe, which only has to answer a
+// one-line prompt.
+const AgentProbeTimeout = 2 * time.Minute
+
 // ---------------------------------------------------------------------------
 // Polling / watcher intervals
 // ---------------------------------------------------------------------------
@@ -37,6 +53,18 @@ const AutoPromoteInterval = 60 * time.Second
 // WaitingSyncInterval is the polling interval for syncing waiting tasks.
 const WaitingSyncInterval = 30 * time.Second
 
+// UnblockReminderInterval is how often blocked tasks are checked for an
+// unblock date that has passed.
+const UnblockReminderInterval = time.Minute
+
+// IntakePollInterval is how often the intake mailbox
+// (WALLFACER_INTAKE_IMAP_URL) is checked for unseen emails.
+const IntakePollInterval = time.Minute
+
+// TrackerSyncInterval is how often tasks are mirrored to the configured
+// Jira or Linear project.
+const TrackerSyncInterval = 30 * time.Second
+
 // AutoTestInterval is the polling interval for the auto-test watcher.
 const AutoTestInterval = 30 * time.Second
 
@@ -52,11 +80,30 @@ const DefaultWorktreeHealthInterval = 2 * time.Minute
 // DefaultWorktreeGCInterval is the interval between worktree garbage collection runs.
 const DefaultWorktreeGCInterval = 24 * time.Hour
 
+// DefaultAgentPrewarmInterval is the interval between agent CLI warm-up probes.
+const DefaultAgentPrewarmInterval = 6 * time.Hour
+
+// AgentPrewarmStartDelay is how long after startup the first agent CLI
+// warm-up runs, so it does not compete with server initialization.
+const AgentPrewarmStartDelay = 30 * time.Second
+
+// AgentPrewarmBusyRetry is how long a warm-up is postponed when agents are
+// running, so probes only run while the host is idle.
+const AgentPrewarmBusyRetry = 5 * time.Minute
+
+// DefaultAgentProbeRetry is how long after a failed capability probe the
+// next one runs.
+const DefaultAgentProbeRetry = 5 * time.Minute
+
 // SSEKeepaliveInterval controls how often SSE streams send keepalive comments
 // to prevent proxy and OS-level TCP idle timeouts from silently closing the
 // connection. Tests can lower this for faster verification.
 var SSEKeepaliveInterval = 15 * time.Second
 
+// WSContainerPollInterval controls how often the /api/ws WebSocket checks
+// the container list for state changes to push.
+const WSContainerPollInterval = 5 * time.Second
+
 // WatcherSettleDelay is the pause after receiving a wake signal before
 // calling the action. Tests can lower this for faster verification.
 var WatcherSettleDelay = 1500 * time.Millisecond
@@ -78,6 +125,14 @@ const DiffCacheTTL = 10 * time.Second
 // CommitsBehindCacheTTL is the time-to-live for cached CommitsBehind results.
 const CommitsBehindCacheTTL = 20 * time.Second
 
+// CommitStyleTTL is how long a repository's cached commit-style profile is
+// used before its history is sampled again.
+const CommitStyleTTL = 6 * time.Hour
+
+// UISessionTTL is how long a browser stays signed in after exchanging an
+// API token for a UI session cookie.
+const UISessionTTL = 7 * 24 * time.Hour
+
 // ---------------------------------------------------------------------------
 // Retry / concurrency limits
 // ---------------------------------------------------------------------------
@@ -89,6 +144,11 @@ const MaxAutoRetries = 3
 // MaxRebaseRetries is the maximum number of rebase attempts before giving up.
 const MaxRebaseRetries = 3
 
+// MaxIncrementalRebaseSteps caps how many upstream checkpoints a step-wise
+// rebase visits per repository. Larger values make each conflict smaller at
+// the cost of more rebase (and possibly resolver) runs.
+const MaxIncrementalRebaseSteps = 8
+
 // MaxTestFailRetries is the maximum number of consecutive test failures before
 // the auto-resume cycle is halted.
 const MaxTestFailRetries = 3
@@ -99,6 +159,26 @@ const DefaultMaxConcurrentTasks = 5
 // DefaultMaxTestConcurrentTasks is the default parallel test-run limit.
 const DefaultMaxTestConcurrentTasks = 2
 
+// DefaultQueueAgingMinutes is how long a backlog task waits in the
+// auto-promotion queue before it gains a point of effective priority, and
+// every further such interval adds another. It keeps low-priority tasks from
+// starving behind a steady stream of critical-path work.
+const DefaultQueueAgingMinutes = 30
+
+// DefaultStallMinutes is how long a running agent turn may go without
+// printing output or changing a file before the stall watchdog flags it.
+// It sits well below the task timeout so a CLI hung on the network is
+// noticed long before the timeout reaps it.
+const DefaultStallMinutes = 15
+
+// DefaultTimeoutActiveMinutes is how recently a task must have printed
+// output or changed a file for an adaptive timeout to extend its deadline.
+const DefaultTimeoutActiveMinutes = 10
+
+// DefaultTimeoutExtendMaxMinutes caps the total time an adaptive timeout
+// adds to a task's deadline.
+const DefaultTimeoutExtendMaxMinutes = 60
+
 // DefaultCBThreshold is the number of consecutive container launch failures
 // required to open the circuit breaker.
 const DefaultCBThreshold = 5
@@ -117,6 +197,9 @@ const (
 	// megabytes. The whiteboard is a local single-user feature, so a generous
 	// cap is acceptable.
 	BodyLimitWhiteboard int64 = 32 << 20 // 32 MiB
+	// BodyLimitIntake caps POST /api/intake, whose emails and forms carry
+	// attachments of up to store.MaxAttachmentBytes each.
+	BodyLimitIntake int64 = 32 << 20 // 32 MiB
 )
 
 // ExplorerMaxFileSize is the maximum file size the explorer will read (2 MiB).
@@ -126,10 +209,23 @@ const ExplorerMaxFileSize int64 = 2 * 1024 * 1024
 // budget. Outputs exceeding this limit are truncated server-side.
 const DefaultMaxTurnOutputBytes = 8 * 1024 * 1024 // 8 MB
 
+// DefaultMaxPromptBytes is the default prompt size budget. Longer prompts are
+// truncated at launch with a warning rather than failing the agent exec.
+const DefaultMaxPromptBytes = 1024 * 1024 // 1 MB
+
 // MaxDiffBytes is the maximum number of bytes to include from the git diff in
 // the test prompt.
 const MaxDiffBytes = 16000
 
+// ContextPackTokenBudget is the approximate token budget for the files of a
+// task's context pack injected into its first prompt. Files that do not fit
+// are summarized as an outline.
+const ContextPackTokenBudget = 20000
+
+// ContextPackBytesPerToken is the bytes-per-token estimate used to turn
+// ContextPackTokenBudget into a byte budget.
+const ContextPackBytesPerToken = 4
+
 // MaxOversightLogBytes caps the total log size to avoid exceeding prompt limits.
 const MaxOversightLogBytes = 40000
 
@@ -185,3 +281,7 @@ const (
 	DefaultRefineSessionsLimit = 5
 	DefaultPromptHistoryLimit  = 20
 )
+
+// DefaultEventCacheTasks is how many tasks' event lists the store keeps in
+// memory before dropping the least recently used; 0 disables the bound.
+const DefaultEventCacheTasks = 256
@@ -0,0 +1,22 @@
+// Package drafts keeps the partially written prompts of the task composer on
+// the server, so a draft survives a browser crash, a cleared local storage,
+// or a switch to another machine. The composer autosaves on a timer while
+// the user types; each user has their own list of drafts per board, and a
+// draft not saved for TTL expires.
+//
+// Drafts live in one file under the config directory, encrypted with the
+// task store's at-rest key when WALLFACER_STORE_KEY is set. The composer's
+// advanced fields travel as opaque JSON the server stores but never parses.
+//
+// # Connected packages
+//
+// Depends on [latere.ai/x/wallfacer/internal/pkg/atomicfile] for crash-safe
+// persistence and [latere.ai/x/wallfacer/internal/pkg/atrest] for
+// encryption at rest. Consumed by [handler] (the /api/drafts endpoints).
+//
+// # Usage
+//
+//	ds, err := drafts.Open(filepath.Join(configDir, drafts.File), cipher)
+//	d, err := ds.Save(drafts.Draft{User: sub, Board: key, Prompt: text}, time.Now())
+//	list := ds.List(sub, key, time.Now())
+package drafts
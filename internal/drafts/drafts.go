@@ -0,0 +1,169 @@
+package drafts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
+)
+
+// File is the name of the drafts file in the config directory.
+const File = "drafts.json"
+
+// TTL is how long a draft is kept after it was last saved.
+const TTL = 14 * 24 * time.Hour
+
+// MaxPerUser caps one user's drafts on one board; saving a new draft beyond
+// it drops the least recently saved.
+const MaxPerUser = 20
+
+// ErrNotFound is returned when a draft ID does not name one of the caller's
+// drafts.
+var ErrNotFound = errors.New("draft not found")
+
+// Draft is one partially written task prompt.
+type Draft struct {
+	ID string `json:"id"`
+	// User is the owner's principal subject; empty for the anonymous local
+	// user.
+	User string `json:"user,omitempty"`
+	// Board is the workspace group key the draft was written on.
+	Board  string `json:"board,omitempty"`
+	Prompt string `json:"prompt"`
+	// Fields holds the composer's other inputs (tags, flow, timeout, ...)
+	// as the client sent them.
+	Fields    json.RawMessage `json:"fields,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// Store is the file-backed set of drafts. It is safe for concurrent use;
+// every mutation rewrites the file atomically.
+type Store struct {
+	path   string
+	cipher *atrest.Cipher // seals the file at rest; nil writes plaintext
+
+	mu     sync.Mutex
+	drafts []Draft
+}
+
+// Open loads the drafts stored at path, decrypting them with c, the task
+// store's at-rest cipher (nil when encryption is off). A plaintext file is
+// read either way and sealed on the next save. A missing file yields an
+// empty store.
+func Open(path string, c *atrest.Cipher) (*Store, error) {
+	ds := &Store{path: path, cipher: c}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ds, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data, err = c.Open(data); err != nil {
+		return nil, fmt.Errorf("open drafts: %w", err)
+	}
+	if err := json.Unmarshal(data, &ds.drafts); err != nil {
+		return nil, fmt.Errorf("parse drafts: %w", err)
+	}
+	return ds, nil
+}
+
+// List returns user's unexpired drafts on board, most recently saved first.
+func (ds *Store) List(user, board string, now time.Time) []Draft {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	out := []Draft{}
+	for _, d := range ds.drafts {
+		if d.User == user && d.Board == board && now.Before(d.ExpiresAt) {
+			out = append(out, d)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Draft) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	return out
+}
+
+// Save stores d at now and returns it as stored. An empty d.ID creates a
+// draft with a new ID; otherwise d replaces the draft with that ID, which
+// must be one of d.User's unexpired drafts on d.Board (ErrNotFound if not).
+// Expired drafts are dropped on every save.
+func (ds *Store) Save(d Draft, now time.Time) (Draft, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(ds.drafts), func(e Draft) bool { return !now.Before(e.ExpiresAt) })
+	d.CreatedAt = now
+	if d.ID == "" {
+		d.ID = uuid.NewString()
+	} else {
+		i := slices.IndexFunc(next, func(e Draft) bool { return e.ID == d.ID })
+		if i < 0 || next[i].User != d.User || next[i].Board != d.Board {
+			return Draft{}, ErrNotFound
+		}
+		d.CreatedAt = next[i].CreatedAt
+		next = slices.Delete(next, i, i+1)
+	}
+	d.UpdatedAt = now
+	d.ExpiresAt = now.Add(TTL)
+
+	// Drop the owner's oldest drafts beyond the cap. next is in save order,
+	// so the first ones found are the oldest.
+	excess := 1 - MaxPerUser
+	for _, e := range next {
+		if e.User == d.User && e.Board == d.Board {
+			excess++
+		}
+	}
+	next = slices.DeleteFunc(next, func(e Draft) bool {
+		if excess > 0 && e.User == d.User && e.Board == d.Board {
+			excess--
+			return true
+		}
+		return false
+	})
+	next = append(next, d)
+	if err := ds.save(next); err != nil {
+		return Draft{}, err
+	}
+	ds.drafts = next
+	return d, nil
+}
+
+// Delete removes user's draft id and reports whether one existed.
+func (ds *Store) Delete(user, id string) (bool, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(ds.drafts), func(d Draft) bool {
+		return d.ID == id && d.User == user
+	})
+	if len(next) == len(ds.drafts) {
+		return false, nil
+	}
+	if err := ds.save(next); err != nil {
+		return false, err
+	}
+	ds.drafts = next
+	return true, nil
+}
+
+func (ds *Store) save(drafts []Draft) error {
+	if drafts == nil {
+		drafts = []Draft{}
+	}
+	data, err := json.Marshal(drafts)
+	if err != nil {
+		return err
+	}
+	if data, err = ds.cipher.Seal(data); err != nil {
+		return err
+	}
+	return atomicfile.Write(ds.path, data, 0o600)
+}
@@ -0,0 +1,158 @@
+package drafts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
+)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), File)
+	ds, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return ds, path
+}
+
+func TestSave_CreateUpdateAndReload(t *testing.T) {
+	ds, path := openTestStore(t)
+	t0 := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	d, err := ds.Save(Draft{User: "alice", Board: "b1", Prompt: "fix the", Fields: json.RawMessage(`{"tags":["x"]}`)}, t0)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if d.ID == "" || !d.CreatedAt.Equal(t0) || !d.ExpiresAt.Equal(t0.Add(TTL)) {
+		t.Fatalf("created draft = %+v", d)
+	}
+
+	t1 := t0.Add(5 * time.Second)
+	d.Prompt = "fix the login bug"
+	updated, err := ds.Save(d, t1)
+	if err != nil {
+		t.Fatalf("Save update: %v", err)
+	}
+	if !updated.CreatedAt.Equal(t0) || !updated.UpdatedAt.Equal(t1) {
+		t.Errorf("update times = created %v, updated %v", updated.CreatedAt, updated.UpdatedAt)
+	}
+
+	reopened, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	list := reopened.List("alice", "b1", t1)
+	if len(list) != 1 || list[0].Prompt != "fix the login bug" || string(list[0].Fields) != `{"tags":["x"]}` {
+		t.Errorf("reloaded drafts = %+v", list)
+	}
+}
+
+func TestList_ScopedToUserAndBoard(t *testing.T) {
+	ds, _ := openTestStore(t)
+	now := time.Now()
+	for _, d := range []Draft{
+		{User: "alice", Board: "b1", Prompt: "a1"},
+		{User: "alice", Board: "b2", Prompt: "a2"},
+		{User: "bob", Board: "b1", Prompt: "b1"},
+	} {
+		if _, err := ds.Save(d, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if list := ds.List("alice", "b1", now); len(list) != 1 || list[0].Prompt != "a1" {
+		t.Errorf("alice/b1 = %+v", list)
+	}
+	if list := ds.List("carol", "b1", now); list == nil || len(list) != 0 {
+		t.Errorf("carol = %#v, want an empty list", list)
+	}
+}
+
+func TestSave_RejectsOtherUsersDraft(t *testing.T) {
+	ds, _ := openTestStore(t)
+	now := time.Now()
+	d, _ := ds.Save(Draft{User: "alice", Prompt: "mine"}, now)
+
+	if _, err := ds.Save(Draft{ID: d.ID, User: "bob", Prompt: "stolen"}, now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("bob overwriting alice's draft: err = %v, want ErrNotFound", err)
+	}
+	if _, err := ds.Save(Draft{ID: "missing", User: "alice", Prompt: "x"}, now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown ID: err = %v, want ErrNotFound", err)
+	}
+	if ok, _ := ds.Delete("bob", d.ID); ok {
+		t.Error("bob deleted alice's draft")
+	}
+	if ok, err := ds.Delete("alice", d.ID); !ok || err != nil {
+		t.Errorf("Delete = %v, %v", ok, err)
+	}
+}
+
+func TestExpiryAndCap(t *testing.T) {
+	ds, _ := openTestStore(t)
+	t0 := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	old, _ := ds.Save(Draft{User: "alice", Prompt: "old"}, t0)
+
+	later := t0.Add(TTL)
+	if list := ds.List("alice", "", later); len(list) != 0 {
+		t.Errorf("expired draft still listed: %+v", list)
+	}
+	if _, err := ds.Save(old, later); !errors.Is(err, ErrNotFound) {
+		t.Errorf("saving an expired draft: err = %v, want ErrNotFound", err)
+	}
+
+	for i := range MaxPerUser + 3 {
+		if _, err := ds.Save(Draft{User: "alice", Prompt: "p"}, later.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := ds.List("alice", "", later.Add(time.Hour))
+	if len(list) != MaxPerUser {
+		t.Fatalf("drafts = %d, want the cap %d", len(list), MaxPerUser)
+	}
+	if newest := later.Add(time.Duration(MaxPerUser+2) * time.Second); !list[0].UpdatedAt.Equal(newest) {
+		t.Errorf("newest draft saved at %v, want %v", list[0].UpdatedAt, newest)
+	}
+	if oldest := later.Add(3 * time.Second); !list[len(list)-1].UpdatedAt.Equal(oldest) {
+		t.Errorf("oldest kept draft saved at %v, want %v", list[len(list)-1].UpdatedAt, oldest)
+	}
+}
+
+func TestSave_EncryptsWithCipher(t *testing.T) {
+	c, err := atrest.New(bytes.Repeat([]byte{7}, atrest.KeySize))
+	if err != nil {
+		t.Fatalf("atrest.New: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), File)
+	ds, err := Open(path, c)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	if _, err := ds.Save(Draft{User: "alice", Board: "b1", Prompt: "rotate the signing key"}, now); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read drafts file: %v", err)
+	}
+	if !atrest.IsSealed(data) || bytes.Contains(data, []byte("rotate the signing key")) {
+		t.Fatalf("drafts file is not sealed: %q", data)
+	}
+	if _, err := Open(path, nil); !errors.Is(err, atrest.ErrNoKey) {
+		t.Errorf("Open without key: err = %v, want ErrNoKey", err)
+	}
+	reopened, err := Open(path, c)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if list := reopened.List("alice", "b1", now); len(list) != 1 || list[0].Prompt != "rotate the signing key" {
+		t.Errorf("reloaded drafts = %+v", list)
+	}
+}
@@ -3,9 +3,11 @@ package envconfig_test
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
+	"latere.ai/x/wallfacer/internal/constants"
 	"latere.ai/x/wallfacer/internal/envconfig"
 )
 
@@ -160,6 +162,19 @@ func TestParseHostPiBinaryOverride(t *testing.T) {
 	}
 }
 
+// TestParseHostIsolation verifies WALLFACER_HOST_ISOLATION is read
+// case-insensitively into HostIsolation.
+func TestParseHostIsolation(t *testing.T) {
+	path := writeEnvFile(t, "WALLFACER_HOST_ISOLATION= Nsjail\n")
+	cfg, err := envconfig.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.HostIsolation != "nsjail" {
+		t.Errorf("HostIsolation = %q, want nsjail", cfg.HostIsolation)
+	}
+}
+
 // TestParseHostBinaryOverrides_Empty verifies that absent keys yield zero-value fields.
 func TestParseHostBinaryOverrides_Empty(t *testing.T) {
 	path := writeEnvFile(t, "# nothing here\n")
@@ -430,6 +445,66 @@ func TestParseOversightInterval(t *testing.T) {
 	}
 }
 
+// TestParseAgentEnvironment verifies the agent locale, timezone, and
+// SOURCE_DATE_EPOCH keys are read, and that a non-positive epoch is ignored.
+func TestParseAgentEnvironment(t *testing.T) {
+	content := `WALLFACER_AGENT_TZ=Europe/Berlin
+WALLFACER_AGENT_LANG=de_DE.UTF-8
+WALLFACER_SOURCE_DATE_EPOCH=1700000000
+`
+	cfg, err := envconfig.Parse(writeEnvFile(t, content))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.AgentTZ != "Europe/Berlin" || cfg.AgentLang != "de_DE.UTF-8" || cfg.SourceDateEpoch != 1700000000 {
+		t.Errorf("got TZ=%q LANG=%q epoch=%d", cfg.AgentTZ, cfg.AgentLang, cfg.SourceDateEpoch)
+	}
+
+	for _, bad := range []string{"-5", "0", "yesterday"} {
+		cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_SOURCE_DATE_EPOCH="+bad+"\n"))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if cfg.SourceDateEpoch != 0 {
+			t.Errorf("SourceDateEpoch = %d for %q; want 0", cfg.SourceDateEpoch, bad)
+		}
+	}
+}
+
+// TestParseAgentProxy verifies the agent proxy keys are read verbatim.
+func TestParseAgentProxy(t *testing.T) {
+	content := `WALLFACER_AGENT_HTTP_PROXY=http://host.containers.internal:7890
+WALLFACER_AGENT_HTTPS_PROXY=http://127.0.0.1:7891
+WALLFACER_AGENT_NO_PROXY=.corp.example.com,10.0.0.0/8
+`
+	cfg, err := envconfig.Parse(writeEnvFile(t, content))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.AgentHTTPProxy != "http://host.containers.internal:7890" ||
+		cfg.AgentHTTPSProxy != "http://127.0.0.1:7891" ||
+		cfg.AgentNoProxy != ".corp.example.com,10.0.0.0/8" {
+		t.Errorf("got HTTP=%q HTTPS=%q NO=%q", cfg.AgentHTTPProxy, cfg.AgentHTTPSProxy, cfg.AgentNoProxy)
+	}
+}
+
+// TestParseSummaryLocale verifies that a language tag is kept and anything
+// else is ignored.
+func TestParseSummaryLocale(t *testing.T) {
+	for content, want := range map[string]string{
+		"WALLFACER_SUMMARY_LOCALE=zh-CN\n":   "zh-CN",
+		"WALLFACER_SUMMARY_LOCALE=Chinese\n": "",
+	} {
+		cfg, err := envconfig.Parse(writeEnvFile(t, content))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if cfg.SummaryLocale != want {
+			t.Errorf("%q: SummaryLocale = %q, want %q", content, cfg.SummaryLocale, want)
+		}
+	}
+}
+
 // TestParseOversightIntervalZero verifies that an explicit "0" is accepted (disables periodic oversight).
 func TestParseOversightIntervalZero(t *testing.T) {
 	content := "WALLFACER_OVERSIGHT_INTERVAL=0\n"
@@ -509,6 +584,25 @@ func TestParseMaxTestParallelTasks(t *testing.T) {
 	}
 }
 
+// TestParseMaxParallelPerRepo verifies the per-repo cap parses and that a
+// negative value is ignored.
+func TestParseMaxParallelPerRepo(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_MAX_PARALLEL_PER_REPO=2\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.MaxParallelPerRepo != 2 {
+		t.Errorf("MaxParallelPerRepo = %d; want 2", cfg.MaxParallelPerRepo)
+	}
+	cfg, err = envconfig.Parse(writeEnvFile(t, "WALLFACER_MAX_PARALLEL_PER_REPO=-1\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.MaxParallelPerRepo != 0 {
+		t.Errorf("MaxParallelPerRepo = %d; want 0 for a negative value", cfg.MaxParallelPerRepo)
+	}
+}
+
 // TestParseMaxTestParallelTasksAbsent verifies that a missing key defaults to 0.
 func TestParseMaxTestParallelTasksAbsent(t *testing.T) {
 	content := "CLAUDE_CODE_OAUTH_TOKEN=tok\n"
@@ -579,6 +673,116 @@ func TestUpdateArchivedTasksPerPage(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Pre-merge lint
+// ---------------------------------------------------------------------------
+
+// TestParsePreMergeCommands verifies that the pre-merge formatter and linter
+// lists are split on ';' with blanks dropped.
+func TestParsePreMergeCommands(t *testing.T) {
+	content := "WALLFACER_PRE_MERGE_FIX=\"gofmt -w . ; npx prettier --write .;\"\nWALLFACER_PRE_MERGE_LINT=go vet ./...\n"
+	path := writeEnvFile(t, content)
+	cfg, err := envconfig.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"gofmt -w .", "npx prettier --write ."}; !slices.Equal(cfg.PreMergeFixCommands, want) {
+		t.Errorf("PreMergeFixCommands = %q; want %q", cfg.PreMergeFixCommands, want)
+	}
+	if want := []string{"go vet ./..."}; !slices.Equal(cfg.PreMergeLintCommands, want) {
+		t.Errorf("PreMergeLintCommands = %q; want %q", cfg.PreMergeLintCommands, want)
+	}
+}
+
+// TestParseCommandList_Empty verifies that a blank list parses as nil.
+func TestParseCommandList_Empty(t *testing.T) {
+	if got := envconfig.ParseCommandList(" ; ;"); got != nil {
+		t.Errorf("ParseCommandList = %q; want nil", got)
+	}
+}
+
+// TestParseSnapshotIgnore verifies that the snapshot ignore list is split on
+// ',' and that an explicitly empty value differs from an unset key.
+func TestParseSnapshotIgnore(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_SNAPSHOT_IGNORE=.DS_Store, dist/* ,\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{".DS_Store", "dist/*"}; !slices.Equal(cfg.SnapshotIgnore, want) {
+		t.Errorf("SnapshotIgnore = %q; want %q", cfg.SnapshotIgnore, want)
+	}
+
+	cfg, err = envconfig.Parse(writeEnvFile(t, "WALLFACER_SNAPSHOT_IGNORE=\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.SnapshotIgnore == nil || len(cfg.SnapshotIgnore) != 0 {
+		t.Errorf("empty SnapshotIgnore = %#v; want an empty non-nil slice", cfg.SnapshotIgnore)
+	}
+
+	cfg, err = envconfig.Parse(writeEnvFile(t, "WALLFACER_PRE_MERGE_LINT=go vet ./...\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.SnapshotIgnore != nil {
+		t.Errorf("unset SnapshotIgnore = %q; want nil", cfg.SnapshotIgnore)
+	}
+}
+
+// TestParseReverseProxySettings verifies that the CORS origin and trusted
+// proxy lists are split on ','.
+func TestParseReverseProxySettings(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t,
+		"WALLFACER_CORS_ORIGINS=https://a.example, https://b.example\n"+
+			"WALLFACER_TRUSTED_PROXIES=127.0.0.1,10.0.0.0/8\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"https://a.example", "https://b.example"}; !slices.Equal(cfg.CORSOrigins, want) {
+		t.Errorf("CORSOrigins = %q; want %q", cfg.CORSOrigins, want)
+	}
+	if want := []string{"127.0.0.1", "10.0.0.0/8"}; !slices.Equal(cfg.TrustedProxies, want) {
+		t.Errorf("TrustedProxies = %q; want %q", cfg.TrustedProxies, want)
+	}
+}
+
+func TestParseAuditLog(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_AUDIT_LOG=/var/log/wallfacer/audit.jsonl\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.AuditLog != "/var/log/wallfacer/audit.jsonl" {
+		t.Errorf("AuditLog = %q", cfg.AuditLog)
+	}
+}
+
+func TestParseWebhooks(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_WEBHOOK_URLS= https://a.example/hook, ,https://b.example/hook\nWALLFACER_WEBHOOK_SECRET=s3cret\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []string{"https://a.example/hook", "https://b.example/hook"}
+	if !slices.Equal(cfg.WebhookURLs, want) {
+		t.Errorf("WebhookURLs = %q; want %q", cfg.WebhookURLs, want)
+	}
+	if cfg.WebhookSecret != "s3cret" {
+		t.Errorf("WebhookSecret = %q", cfg.WebhookSecret)
+	}
+}
+
+func TestParseTunnel(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_TUNNEL= Command \nWALLFACER_TUNNEL_COMMAND=bore local {port} --to bore.pub\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Tunnel != "command" {
+		t.Errorf("Tunnel = %q, want command", cfg.Tunnel)
+	}
+	if cfg.TunnelCommand != "bore local {port} --to bore.pub" {
+		t.Errorf("TunnelCommand = %q", cfg.TunnelCommand)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // AutoPush
 // ---------------------------------------------------------------------------
@@ -826,6 +1030,184 @@ func TestParseTerminalEnabledFalse(t *testing.T) {
 	}
 }
 
+// --- QueueAgingMinutes ---
+
+func TestParse_QueueAgingMinutes(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		raw  string
+		want int
+	}{
+		{"unset keeps the default", "", constants.DefaultQueueAgingMinutes},
+		{"positive", "WALLFACER_QUEUE_AGING_MINUTES=10", 10},
+		{"zero disables aging", "WALLFACER_QUEUE_AGING_MINUTES=0", 0},
+		{"negative is ignored", "WALLFACER_QUEUE_AGING_MINUTES=-5", constants.DefaultQueueAgingMinutes},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := envconfig.Parse(writeEnvFile(t, tc.raw))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if cfg.QueueAgingMinutes != tc.want {
+				t.Errorf("QueueAgingMinutes = %d; want %d", cfg.QueueAgingMinutes, tc.want)
+			}
+		})
+	}
+}
+
+// --- StallMinutes ---
+
+func TestParse_StallWatchdog(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		raw         string
+		wantMinutes int
+		wantRestart bool
+	}{
+		{"unset keeps the default", "", constants.DefaultStallMinutes, false},
+		{"positive", "WALLFACER_STALL_MINUTES=5", 5, false},
+		{"zero disables the watchdog", "WALLFACER_STALL_MINUTES=0", 0, false},
+		{"negative is ignored", "WALLFACER_STALL_MINUTES=-1", constants.DefaultStallMinutes, false},
+		{"restart enabled", "WALLFACER_STALL_RESTART=true", constants.DefaultStallMinutes, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := envconfig.Parse(writeEnvFile(t, tc.raw))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if cfg.StallMinutes != tc.wantMinutes || cfg.StallRestart != tc.wantRestart {
+				t.Errorf("StallMinutes, StallRestart = %d, %v; want %d, %v",
+					cfg.StallMinutes, cfg.StallRestart, tc.wantMinutes, tc.wantRestart)
+			}
+		})
+	}
+}
+
+// --- Adaptive timeout ---
+
+func TestParse_AdaptiveTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		raw                 string
+		extend, max, active int
+	}{
+		{"unset is disabled", "", 0, constants.DefaultTimeoutExtendMaxMinutes, constants.DefaultTimeoutActiveMinutes},
+		{"enabled", "WALLFACER_TIMEOUT_EXTEND_MINUTES=15", 15, constants.DefaultTimeoutExtendMaxMinutes, constants.DefaultTimeoutActiveMinutes},
+		{"all set", "WALLFACER_TIMEOUT_EXTEND_MINUTES=10\nWALLFACER_TIMEOUT_EXTEND_MAX_MINUTES=30\nWALLFACER_TIMEOUT_ACTIVE_MINUTES=5", 10, 30, 5},
+		{"zero window is ignored", "WALLFACER_TIMEOUT_ACTIVE_MINUTES=0", 0, constants.DefaultTimeoutExtendMaxMinutes, constants.DefaultTimeoutActiveMinutes},
+		{"negative is ignored", "WALLFACER_TIMEOUT_EXTEND_MINUTES=-5", 0, constants.DefaultTimeoutExtendMaxMinutes, constants.DefaultTimeoutActiveMinutes},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := envconfig.Parse(writeEnvFile(t, tc.raw))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if cfg.TimeoutExtendMinutes != tc.extend || cfg.TimeoutExtendMaxMinutes != tc.max || cfg.TimeoutActiveMinutes != tc.active {
+				t.Errorf("extend, max, active = %d, %d, %d; want %d, %d, %d",
+					cfg.TimeoutExtendMinutes, cfg.TimeoutExtendMaxMinutes, cfg.TimeoutActiveMinutes, tc.extend, tc.max, tc.active)
+			}
+		})
+	}
+}
+
+func TestParse_ConflictResolverProfile(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, `WALLFACER_SANDBOX_CONFLICT_RESOLVER=Codex
+CLAUDE_CONFLICT_RESOLVER_MODEL=claude-haiku-4-5
+CODEX_CONFLICT_RESOLVER_MODEL=gpt-5-mini
+WALLFACER_CONFLICT_RESOLVER_MAX_COST_USD=0.75
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.ConflictResolverSandbox != "codex" {
+		t.Errorf("ConflictResolverSandbox = %q, want codex", cfg.ConflictResolverSandbox)
+	}
+	if cfg.ConflictResolverModel != "claude-haiku-4-5" || cfg.CodexConflictResolverModel != "gpt-5-mini" {
+		t.Errorf("models = %q, %q", cfg.ConflictResolverModel, cfg.CodexConflictResolverModel)
+	}
+	if cfg.ConflictResolverMaxCostUSD != 0.75 {
+		t.Errorf("ConflictResolverMaxCostUSD = %v, want 0.75", cfg.ConflictResolverMaxCostUSD)
+	}
+
+	cfg, err = envconfig.Parse(writeEnvFile(t, "WALLFACER_CONFLICT_RESOLVER_MAX_COST_USD=-1\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.ConflictResolverMaxCostUSD != 0 {
+		t.Errorf("negative budget parsed as %v, want 0 (unlimited)", cfg.ConflictResolverMaxCostUSD)
+	}
+}
+
+func TestParse_CostBudgets(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_DAILY_BUDGET_USD=5\nWALLFACER_BOARD_BUDGET_USD=120.5\nWALLFACER_WEEKLY_BUDGET_USD=25\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.DailyBudgetUSD != 5 || cfg.BoardBudgetUSD != 120.5 || cfg.WeeklyBudgetUSD != 25 {
+		t.Errorf("budgets = %v, %v, %v, want 5, 120.5, 25", cfg.DailyBudgetUSD, cfg.BoardBudgetUSD, cfg.WeeklyBudgetUSD)
+	}
+
+	cfg, err = envconfig.Parse(writeEnvFile(t, "WALLFACER_DAILY_BUDGET_USD=-1\nWALLFACER_BOARD_BUDGET_USD=lots\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.DailyBudgetUSD != 0 || cfg.BoardBudgetUSD != 0 {
+		t.Errorf("invalid budgets parsed as %v, %v, want 0 (unlimited)", cfg.DailyBudgetUSD, cfg.BoardBudgetUSD)
+	}
+}
+
+func TestParse_GitHostingTokens(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_GITHUB_TOKEN=ghp_abc\nWALLFACER_GITLAB_TOKEN=glpat-xyz\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.GitHubToken != "ghp_abc" || cfg.GitLabToken != "glpat-xyz" {
+		t.Errorf("tokens = %q, %q", cfg.GitHubToken, cfg.GitLabToken)
+	}
+}
+
+func TestParse_Intake(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_INTAKE_TOKEN=s3cret\nWALLFACER_INTAKE_IMAP_URL=imaps://u:p@mail.example.com/Tasks\nWALLFACER_INTAKE_ALLOWED_SENDERS=lead@example.com, @acme.com\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.IntakeToken != "s3cret" || cfg.IntakeIMAPURL != "imaps://u:p@mail.example.com/Tasks" {
+		t.Errorf("intake = %q, %q", cfg.IntakeToken, cfg.IntakeIMAPURL)
+	}
+	if want := []string{"lead@example.com", "@acme.com"}; !slices.Equal(cfg.IntakeAllowedSenders, want) {
+		t.Errorf("IntakeAllowedSenders = %q, want %q", cfg.IntakeAllowedSenders, want)
+	}
+}
+
+func TestParse_Tracker(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_JIRA_URL=https://acme.atlassian.net\nWALLFACER_JIRA_PROJECT=ENG\nWALLFACER_JIRA_EMAIL=me@acme.com\nWALLFACER_JIRA_TOKEN=tok\nWALLFACER_JIRA_ISSUE_TYPE=Story\nWALLFACER_LINEAR_API_KEY=lin_api\nWALLFACER_LINEAR_TEAM=ENG\nWALLFACER_PUBLIC_URL=https://wallfacer.example.com/\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.JiraURL != "https://acme.atlassian.net" || cfg.JiraProject != "ENG" || cfg.JiraEmail != "me@acme.com" || cfg.JiraToken != "tok" || cfg.JiraIssueType != "Story" {
+		t.Errorf("jira = %q, %q, %q, %q, %q", cfg.JiraURL, cfg.JiraProject, cfg.JiraEmail, cfg.JiraToken, cfg.JiraIssueType)
+	}
+	if cfg.LinearAPIKey != "lin_api" || cfg.LinearTeam != "ENG" {
+		t.Errorf("linear = %q, %q", cfg.LinearAPIKey, cfg.LinearTeam)
+	}
+	if cfg.PublicURL != "https://wallfacer.example.com" {
+		t.Errorf("PublicURL = %q, want trailing slash trimmed", cfg.PublicURL)
+	}
+}
+
+func TestParse_APIAuth(t *testing.T) {
+	cfg, err := envconfig.Parse(writeEnvFile(t, "WALLFACER_API_TOKENS= ci-token, ,ops-token\nWALLFACER_REQUIRE_AUTH=yes\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(cfg.APITokens) != 2 || cfg.APITokens[0] != "ci-token" || cfg.APITokens[1] != "ops-token" {
+		t.Errorf("APITokens = %q", cfg.APITokens)
+	}
+	if !cfg.RequireAuth {
+		t.Error("RequireAuth = false, want true")
+	}
+}
+
 // --- AgentSessionWindowDays ---
 
 func TestParse_AgentSessionWindowDaysDefault(t *testing.T) {
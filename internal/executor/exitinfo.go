@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"time"
+)
+
+// ExitInfo describes how an agent process ended. It is the host-process
+// counterpart of the exit state a container runtime reports from inspect:
+// enough to tell an agent that gave up (non-zero exit, clean output) from
+// infrastructure that took it down (signal, OOM kill).
+type ExitInfo struct {
+	ExitCode int // -1 when the process was terminated by a signal
+	// Signal names the signal that terminated the process ("killed",
+	// "terminated", ...), or is empty for a normal exit.
+	Signal string
+	// OOMKilled reports a SIGKILL that wallfacer did not send. Neither Kill
+	// nor context cancellation was involved, which on Linux is the kernel
+	// OOM killer's signature.
+	OOMKilled bool
+	StartedAt time.Time
+	EndedAt   time.Time
+	// MaxRSSBytes is the peak resident set size of the process, or 0 when
+	// the platform does not report it.
+	MaxRSSBytes int64
+	// Binary is the agent executable and BinaryDigest its "sha256:<hex>"
+	// content digest, identifying the exact CLI build that ran the turn.
+	Binary       string
+	BinaryDigest string
+}
+
+// Duration returns how long the process ran.
+func (e ExitInfo) Duration() time.Duration {
+	if e.StartedAt.IsZero() || e.EndedAt.IsZero() {
+		return 0
+	}
+	return e.EndedAt.Sub(e.StartedAt)
+}
+
+// ExitReporter is implemented by handles that can describe how their process
+// ended. It is optional so test doubles need not implement it; callers
+// type-assert a Handle and skip recording when it is absent.
+type ExitReporter interface {
+	// ExitInfo returns the exit metadata once Wait has returned; ok is
+	// false while the process is still running or when it never started.
+	ExitInfo() (info ExitInfo, ok bool)
+}
+
+// binaryDigestEntry caches a binary's digest against the file identity it
+// was computed from, so an in-place CLI upgrade is picked up on the next turn.
+type binaryDigestEntry struct {
+	size    int64
+	modTime time.Time
+	digest  string
+}
+
+// binaryDigest returns the sha256 content digest of path, reusing the cached
+// value while the file's size and mtime are unchanged. Agent CLIs can be
+// hundreds of megabytes, so hashing once per build rather than once per turn
+// matters. Returns "" when the file cannot be read.
+func (b *HostBackend) binaryDigest(path string) string {
+	if path == "" {
+		return ""
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	b.digestMu.Lock()
+	defer b.digestMu.Unlock()
+	if e, ok := b.digests[path]; ok && e.size == fi.Size() && e.modTime.Equal(fi.ModTime()) {
+		return e.digest
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return ""
+	}
+	digest := "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	if b.digests == nil {
+		b.digests = make(map[string]binaryDigestEntry)
+	}
+	b.digests[path] = binaryDigestEntry{size: fi.Size(), modTime: fi.ModTime(), digest: digest}
+	return digest
+}
+
+// ExitInfo implements ExitReporter.
+func (h *hostHandle) ExitInfo() (ExitInfo, bool) {
+	select {
+	case <-h.done:
+	default:
+		return ExitInfo{}, false
+	}
+	ps := h.cmd.ProcessState
+	if ps == nil {
+		return ExitInfo{}, false
+	}
+	info := ExitInfo{
+		ExitCode:    ps.ExitCode(),
+		StartedAt:   h.startedAt,
+		EndedAt:     h.endedAt,
+		MaxRSSBytes: maxRSSBytes(ps),
+		Binary:      h.cmd.Path,
+	}
+	sig, killed := exitSignal(ps)
+	info.Signal = sig
+	info.OOMKilled = killed && !h.killRequested.Load() && !h.cancelled
+	if h.backend != nil {
+		info.BinaryDigest = h.backend.binaryDigest(h.cmd.Path)
+	}
+	return info, true
+}
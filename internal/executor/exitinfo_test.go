@@ -0,0 +1,145 @@
+//go:build !windows
+
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// launchSleeper starts the fake agent sleeping so the test can end it, and
+// drains its output in the background.
+func launchSleeper(t *testing.T, b *HostBackend, name string) (*hostHandle, *sync.WaitGroup) {
+	t.Helper()
+	h, err := b.Launch(context.Background(), ContainerSpec{
+		Name:    name,
+		Env:     map[string]string{"WALLFACER_AGENT": "claude", "FAKEAGENT_SLEEP": "30"},
+		Cmd:     []string{"-p", "wait"},
+		WorkDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("launch: %v", err)
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); _, _ = io.ReadAll(h.Stdout()) }()
+	go func() { defer wg.Done(); _, _ = io.ReadAll(h.Stderr()) }()
+	time.Sleep(100 * time.Millisecond)
+	return h.(*hostHandle), &wg
+}
+
+func TestHostHandle_ExitInfo_NormalExit(t *testing.T) {
+	bin := buildFakeAgent(t, "fakeagent")
+	b, _ := NewHostBackend(HostBackendConfig{ClaudeBinary: bin, CodexBinary: bin})
+
+	h, err := b.Launch(context.Background(), ContainerSpec{
+		Name:    "wallfacer-test-exitinfo",
+		Env:     map[string]string{"WALLFACER_AGENT": "claude"},
+		Cmd:     []string{"-p", "hi"},
+		WorkDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("launch: %v", err)
+	}
+	rep := h.(ExitReporter)
+	if _, ok := rep.ExitInfo(); ok {
+		t.Error("ExitInfo reported before Wait")
+	}
+	_, _ = io.ReadAll(h.Stdout())
+	_, _ = io.ReadAll(h.Stderr())
+	if _, err := h.Wait(); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+
+	info, ok := rep.ExitInfo()
+	if !ok {
+		t.Fatal("ExitInfo not available after Wait")
+	}
+	if info.ExitCode != 0 || info.Signal != "" || info.OOMKilled {
+		t.Errorf("normal exit reported as %+v", info)
+	}
+	if info.Duration() <= 0 {
+		t.Errorf("Duration = %v, want > 0", info.Duration())
+	}
+	if info.Binary != bin || !strings.HasPrefix(info.BinaryDigest, "sha256:") {
+		t.Errorf("binary = %q digest = %q", info.Binary, info.BinaryDigest)
+	}
+}
+
+// TestHostHandle_ExitInfo_ExternalSIGKILL simulates the kernel OOM killer: a
+// SIGKILL wallfacer did not send.
+func TestHostHandle_ExitInfo_ExternalSIGKILL(t *testing.T) {
+	bin := buildFakeAgent(t, "fakeagent")
+	b, _ := NewHostBackend(HostBackendConfig{ClaudeBinary: bin, CodexBinary: bin})
+	h, wg := launchSleeper(t, b, "wallfacer-test-oom")
+
+	if err := syscall.Kill(h.cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+	code, err := h.Wait()
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+
+	info, _ := h.ExitInfo()
+	if code != -1 || info.ExitCode != -1 {
+		t.Errorf("exit code = %d / %d, want -1", code, info.ExitCode)
+	}
+	if info.Signal != syscall.SIGKILL.String() || !info.OOMKilled {
+		t.Errorf("external SIGKILL reported as signal=%q oom=%v", info.Signal, info.OOMKilled)
+	}
+}
+
+func TestHostHandle_ExitInfo_RequestedKillIsNotOOM(t *testing.T) {
+	bin := buildFakeAgent(t, "fakeagent")
+	b, _ := NewHostBackend(HostBackendConfig{ClaudeBinary: bin, CodexBinary: bin})
+	h, wg := launchSleeper(t, b, "wallfacer-test-requested-kill")
+
+	_ = h.Kill()
+	_, _ = h.Wait()
+	wg.Wait()
+
+	info, _ := h.ExitInfo()
+	if info.OOMKilled {
+		t.Errorf("Kill-initiated exit flagged as OOM: %+v", info)
+	}
+	if info.Signal == "" {
+		t.Errorf("signal not recorded for killed process: %+v", info)
+	}
+}
+
+func TestHostBackend_BinaryDigest_TracksFileChanges(t *testing.T) {
+	b := &HostBackend{}
+	path := filepath.Join(t.TempDir(), "agent")
+	sum := func(data string) string {
+		h := sha256.Sum256([]byte(data))
+		return "sha256:" + hex.EncodeToString(h[:])
+	}
+
+	if err := os.WriteFile(path, []byte("v1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.binaryDigest(path); got != sum("v1") {
+		t.Errorf("digest = %q, want %q", got, sum("v1"))
+	}
+	// A rewrite with a new size invalidates the cached digest.
+	if err := os.WriteFile(path, []byte("v2-longer"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.binaryDigest(path); got != sum("v2-longer") {
+		t.Errorf("digest after upgrade = %q, want %q", got, sum("v2-longer"))
+	}
+	if got := b.binaryDigest(filepath.Join(t.TempDir(), "missing")); got != "" {
+		t.Errorf("missing binary digest = %q, want empty", got)
+	}
+}
@@ -54,6 +54,7 @@ type HostBackendConfig struct {
 	ClaudeBinary   string // path to `claude` CLI; empty ⇒ exec.LookPath
 	CodexBinary    string // path to `codex` CLI;  empty ⇒ exec.LookPath
 	CursorBinary   string // path to `cursor-agent` CLI; empty ⇒ exec.LookPath
+	GeminiBinary   string // path to `gemini` CLI; empty ⇒ exec.LookPath
 	OpenCodeBinary string // path to `opencode` CLI; empty ⇒ exec.LookPath
 	PiBinary       string // path to `pi` CLI; empty ⇒ exec.LookPath
 	// AgentNice is the niceness applied to launched agent processes so they and
@@ -65,6 +66,10 @@ type HostBackendConfig struct {
 	// the hard ceiling beneath the per-kind admission gates: Launch blocks until a
 	// slot frees (honoring the caller's context).
 	MaxAgents int
+	// MaxPromptBytes is the prompt size budget; longer prompts are truncated
+	// at launch with a warning. 0 ⇒ unlimited. Harnesses that take the prompt
+	// as an argument are additionally capped at harness.MaxArgPromptBytes.
+	MaxPromptBytes int
 }
 
 // DefaultAgentNice is the niceness used when HostBackendConfig.AgentNice is unset
@@ -91,20 +96,37 @@ type HostBackend struct {
 	claudeBinary   string
 	codexBinary    string
 	cursorBinary   string
+	geminiBinary   string
 	openCodeBinary string
 	piBinary       string
 
-	agentNice int           // resolved niceness passed to applyAgentPriority (0 ⇒ disabled)
-	agentSem  chan struct{} // global concurrency budget; nil ⇒ unlimited
+	// explicit holds the configured binary overrides so Prewarm can
+	// re-resolve a CLI installed or moved after startup. Guarded by binaryMu.
+	explicit map[harness.ID]string
+
+	prewarmMu sync.Mutex
+	versions  map[harness.ID]string // last --version output per harness, for upgrade detection
+
+	agentNice      atomic.Int64 // resolved niceness passed to applyAgentPriority (0 ⇒ disabled)
+	budget         *agentBudget // global concurrency budget
+	maxPromptBytes int          // prompt budget passed to harness.PromptLimit (0 ⇒ unlimited)
 
 	procMu sync.Mutex
 	procs  map[string]*hostHandle // keyed by container name
+
+	digestMu sync.Mutex
+	digests  map[string]binaryDigestEntry // agent binary path → cached content digest
 }
 
 // SetBinaryForTest overrides the resolved binary path for the given agent
 // type. Used by tests that need to swap in a fake-cmd script after the
 // backend is constructed.
 func (b *HostBackend) SetBinaryForTest(t harness.ID, path string) {
+	b.setBinary(t, path)
+}
+
+// setBinary records the resolved binary path for the given agent type.
+func (b *HostBackend) setBinary(t harness.ID, path string) {
 	b.binaryMu.Lock()
 	defer b.binaryMu.Unlock()
 	switch t {
@@ -114,6 +136,8 @@ func (b *HostBackend) SetBinaryForTest(t harness.ID, path string) {
 		b.codexBinary = path
 	case harness.Cursor:
 		b.cursorBinary = path
+	case harness.Gemini:
+		b.geminiBinary = path
 	case harness.OpenCode:
 		b.openCodeBinary = path
 	case harness.Pi:
@@ -131,47 +155,91 @@ func NewHostBackend(cfg HostBackendConfig) (*HostBackend, error) {
 	claude, _ := resolveBinary(cfg.ClaudeBinary, "claude")
 	codex, _ := resolveBinary(cfg.CodexBinary, "codex")
 	cursor, _ := resolveBinary(cfg.CursorBinary, "cursor-agent")
+	gemini, _ := resolveBinary(cfg.GeminiBinary, "gemini")
 	opencode, _ := resolveBinary(cfg.OpenCodeBinary, "opencode")
 	pi, _ := resolveBinary(cfg.PiBinary, "pi")
-	// Resolve niceness: 0 ⇒ default, negative ⇒ disabled (applyAgentPriority
-	// treats 0 as "no change").
-	nice := cfg.AgentNice
-	switch {
-	case nice == 0:
-		nice = DefaultAgentNice
-	case nice < 0:
-		nice = 0
-	}
-	var sem chan struct{}
-	if cfg.MaxAgents > 0 {
-		sem = make(chan struct{}, cfg.MaxAgents)
-	}
-	return &HostBackend{
+	b := &HostBackend{
 		claudeBinary:   claude,
 		codexBinary:    codex,
 		cursorBinary:   cursor,
+		geminiBinary:   gemini,
 		openCodeBinary: opencode,
 		piBinary:       pi,
-		agentNice:      nice,
-		agentSem:       sem,
+		explicit: map[harness.ID]string{
+			harness.Claude:   cfg.ClaudeBinary,
+			harness.Codex:    cfg.CodexBinary,
+			harness.Cursor:   cfg.CursorBinary,
+			harness.Gemini:   cfg.GeminiBinary,
+			harness.OpenCode: cfg.OpenCodeBinary,
+			harness.Pi:       cfg.PiBinary,
+		},
+		versions:       make(map[harness.ID]string),
+		budget:         newAgentBudget(cfg.MaxAgents),
+		maxPromptBytes: cfg.MaxPromptBytes,
 		procs:          make(map[string]*hostHandle),
-	}, nil
+	}
+	b.agentNice.Store(int64(resolveAgentNice(cfg.AgentNice)))
+	return b, nil
+}
+
+// resolveAgentNice maps a configured niceness to the one applied: 0 ⇒
+// default, negative ⇒ disabled (applyAgentPriority treats 0 as "no change").
+func resolveAgentNice(nice int) int {
+	switch {
+	case nice == 0:
+		return DefaultAgentNice
+	case nice < 0:
+		return 0
+	}
+	return nice
+}
+
+// Reconfigure applies cfg to a running backend without interrupting
+// launched agents: binaries are resolved again, and the niceness and agent
+// budget apply to the next launch. A lowered budget lets running agents
+// finish and holds new ones until enough of them exit. A binary that no
+// longer resolves keeps its previous path; an explicit path that does not
+// exist is reported in the returned error. MaxPromptBytes is not reloaded.
+func (b *HostBackend) Reconfigure(cfg HostBackendConfig) error {
+	explicit := map[harness.ID]string{
+		harness.Claude:   cfg.ClaudeBinary,
+		harness.Codex:    cfg.CodexBinary,
+		harness.Cursor:   cfg.CursorBinary,
+		harness.Gemini:   cfg.GeminiBinary,
+		harness.OpenCode: cfg.OpenCodeBinary,
+		harness.Pi:       cfg.PiBinary,
+	}
+	var errs []error
+	for id, path := range explicit {
+		resolved, err := resolveBinary(path, binaryNames[id])
+		if err != nil {
+			if path != "" {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		b.setBinary(id, resolved)
+	}
+	b.binaryMu.Lock()
+	b.explicit = explicit
+	b.binaryMu.Unlock()
+	b.agentNice.Store(int64(resolveAgentNice(cfg.AgentNice)))
+	b.budget.setLimit(cfg.MaxAgents)
+	return errors.Join(errs...)
+}
+
+// explicitBinary returns the configured binary override for t, if any.
+func (b *HostBackend) explicitBinary(t harness.ID) string {
+	b.binaryMu.RLock()
+	defer b.binaryMu.RUnlock()
+	return b.explicit[t]
 }
 
 // acquireSlot reserves a global agent-budget slot, blocking until one frees or
 // ctx is cancelled. The returned release frees the slot exactly once; when the
-// budget is unlimited (agentSem nil) it reserves nothing and release is a no-op.
+// budget is unlimited it still counts the agent so a later limit sees it.
 func (b *HostBackend) acquireSlot(ctx context.Context) (func(), error) {
-	if b.agentSem == nil {
-		return func() {}, nil
-	}
-	select {
-	case b.agentSem <- struct{}{}:
-		var once sync.Once
-		return func() { once.Do(func() { <-b.agentSem }) }, nil
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
+	return b.budget.acquire(ctx)
 }
 
 // RequireClaude verifies the claude binary can be resolved, returning the
@@ -223,6 +291,11 @@ func (b *HostBackend) binaryFor(t harness.ID) (string, error) {
 			return "", fmt.Errorf("cursor-agent binary not resolved")
 		}
 		return b.cursorBinary, nil
+	case harness.Gemini:
+		if b.geminiBinary == "" {
+			return "", fmt.Errorf("gemini binary not resolved")
+		}
+		return b.geminiBinary, nil
 	case harness.OpenCode:
 		if b.openCodeBinary == "" {
 			return "", fmt.Errorf("opencode binary not resolved")
@@ -261,6 +334,11 @@ func (b *HostBackend) Launch(ctx context.Context, spec ContainerSpec) (Handle, e
 	if err != nil {
 		return nil, err
 	}
+	spec, cleanup, err := isolate(spec, b.buildChildEnv(spec))
+	if err != nil {
+		release()
+		return nil, err
+	}
 
 	var h Handle
 	switch agent {
@@ -270,6 +348,8 @@ func (b *HostBackend) Launch(ctx context.Context, spec ContainerSpec) (Handle, e
 		h, err = b.launchCodex(ctx, spec)
 	case harness.Cursor:
 		h, err = b.launchCursor(ctx, spec)
+	case harness.Gemini:
+		h, err = b.launchGemini(ctx, spec)
 	case harness.OpenCode:
 		h, err = b.launchOpenCode(ctx, spec)
 	case harness.Pi:
@@ -279,10 +359,11 @@ func (b *HostBackend) Launch(ctx context.Context, spec ContainerSpec) (Handle, e
 	}
 	if err != nil {
 		release()
+		cleanup()
 		return nil, err
 	}
 	if hh, ok := h.(*hostHandle); ok {
-		hh.release = release
+		hh.release = func() { release(); cleanup() }
 	} else {
 		release() // unknown handle type cannot free the slot on exit
 	}
@@ -328,13 +409,29 @@ func (b *HostBackend) launchPlainHostAgent(ctx context.Context, spec ContainerSp
 	}
 
 	agentH, _ := harness.Lookup(p.id)
-	argv, _, argvErr := agentH.BuildArgv(req)
+	req = b.limitPrompt(agentH, req, spec)
+	req.Interactive = spec.Interactive && agentH.Capabilities().InteractiveInput
+	argv, stdin, argvErr := agentH.BuildArgv(req)
 	if argvErr != nil {
 		return nil, fmt.Errorf("host backend: %s argv: %w", p.id, argvErr)
 	}
 
+	bin, argv, err = wrapCommand(spec, bin, argv)
+	if err != nil {
+		return nil, err
+	}
 	cmd := exec.CommandContext(ctx, bin, argv...)
 	cmd.Env = env
+	var input *hostInput
+	if req.Interactive {
+		w, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, fmt.Errorf("stdin pipe: %w", err)
+		}
+		input = &hostInput{w: w}
+	} else {
+		cmd.Stdin = stdin
+	}
 	if spec.WorkDir != "" {
 		cmd.Dir = spec.WorkDir
 	}
@@ -356,8 +453,12 @@ func (b *HostBackend) launchPlainHostAgent(ctx context.Context, spec ContainerSp
 		transition(&h.state, StateFailed)
 		return nil, fmt.Errorf("start host agent: %w", err)
 	}
-	applyAgentPriority(cmd.Process.Pid, b.agentNice)
+	applyAgentPriority(cmd.Process.Pid, int(b.agentNice.Load()))
 	transition(&h.state, StateRunning)
+	if input != nil {
+		input.start(stdin)
+		h.input = input
+	}
 
 	b.procMu.Lock()
 	b.procs[spec.Name] = h
@@ -366,6 +467,25 @@ func (b *HostBackend) launchPlainHostAgent(ctx context.Context, spec ContainerSp
 	return h, nil
 }
 
+// limitPrompt truncates req.Prompt to what h can be launched with (see
+// harness.PromptLimit), logging a warning and calling spec.OnPromptTruncated
+// when anything is dropped so an oversized prompt degrades visibly instead
+// of failing the exec with E2BIG.
+func (b *HostBackend) limitPrompt(h harness.Harness, req harness.Request, spec ContainerSpec) harness.Request {
+	limit := harness.PromptLimit(h, req, b.maxPromptBytes)
+	prompt, truncated := harness.TruncatePrompt(req.Prompt, limit)
+	if truncated {
+		logger.Runner.Warn("host backend: prompt truncated",
+			"task", spec.Labels["wallfacer.task.id"], "agent", h.ID(),
+			"bytes", len(req.Prompt), "limit", limit)
+		if spec.OnPromptTruncated != nil {
+			spec.OnPromptTruncated(len(req.Prompt), len(prompt))
+		}
+		req.Prompt = prompt
+	}
+	return req
+}
+
 // buildChildEnv returns os.Environ() with spec.EnvFile values merged in
 // and spec.Env overlaid on top. spec.Env wins on collision.
 func (b *HostBackend) buildChildEnv(spec ContainerSpec) []string {
@@ -444,20 +564,30 @@ type hostHandle struct {
 
 	killOnce sync.Once     // ensures SIGTERM→SIGKILL escalation runs at most once
 	done     chan struct{} // closed after cmd.Wait() returns
-	release  func()        // frees the global budget slot; set by Launch, nil ⇒ no-op
+	release  func()        // frees the budget slot and isolated HOME; set by Launch, nil ⇒ no-op
+	input    *hostInput    // open stdin of an interactive launch; nil otherwise
+
+	// Exit metadata reported by ExitInfo. startedAt is set at construction
+	// (immediately before Start); endedAt and cancelled are written before
+	// done is closed, so reads after <-done need no lock.
+	startedAt     time.Time
+	endedAt       time.Time
+	cancelled     bool        // Wait returned a non-exit error (context teardown)
+	killRequested atomic.Bool // Kill was called
 }
 
 // newHostHandle constructs a hostHandle with state initialised to Creating.
 // All construction goes through this so the initial state is never ambiguous.
 func newHostHandle(name string, cmd *exec.Cmd, stdout, stderr io.ReadCloser, taskID string, backend *HostBackend) *hostHandle {
 	h := &hostHandle{
-		name:    name,
-		cmd:     cmd,
-		done:    make(chan struct{}),
-		stdout:  stdout,
-		stderr:  stderr,
-		taskID:  taskID,
-		backend: backend,
+		name:      name,
+		cmd:       cmd,
+		done:      make(chan struct{}),
+		stdout:    stdout,
+		stderr:    stderr,
+		taskID:    taskID,
+		backend:   backend,
+		startedAt: time.Now(),
 	}
 	h.state.Store(int32(StateCreating))
 	return h
@@ -473,6 +603,9 @@ func (h *hostHandle) Name() string          { return h.name }
 // LocalBackend's convention — only unexpected errors surface as non-nil.
 func (h *hostHandle) Wait() (int, error) {
 	err := h.cmd.Wait()
+	h.endedAt = time.Now()
+	var exitErr *exec.ExitError
+	h.cancelled = err != nil && !errors.As(err, &exitErr)
 	close(h.done)
 	defer h.removeFromBackend()
 	if h.release != nil {
@@ -484,8 +617,7 @@ func (h *hostHandle) Wait() (int, error) {
 		return s == StateStopped || s == StateFailed
 	}
 	if err != nil {
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
+		if exitErr != nil {
 			if !terminal() {
 				transition(&h.state, StateStopped)
 			}
@@ -510,6 +642,7 @@ func (h *hostHandle) Kill() error {
 	if s := BackendState(h.state.Load()); s == StateStopped || s == StateFailed {
 		return nil
 	}
+	h.killRequested.Store(true)
 	transition(&h.state, StateStopping)
 	h.killOnce.Do(h.signalAndEscalate)
 	return nil
@@ -558,6 +691,7 @@ func (h *hostHandle) removeFromBackend() {
 
 // Compile-time interface checks.
 var (
-	_ Backend = (*HostBackend)(nil)
-	_ Handle  = (*hostHandle)(nil)
+	_ Backend      = (*HostBackend)(nil)
+	_ Handle       = (*hostHandle)(nil)
+	_ ExitReporter = (*hostHandle)(nil)
 )
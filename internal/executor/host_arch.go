@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Platform returns the os/arch pair a launch with the given Arch runs as,
+// e.g. "darwin/amd64", and whether the host emulates it. An empty arch is the
+// host's native architecture.
+func Platform(arch string) (platform string, emulated bool) {
+	return platformFor(runtime.GOOS, runtime.GOARCH, arch)
+}
+
+func platformFor(goos, goarch, arch string) (string, bool) {
+	if arch == "" {
+		arch = goarch
+	}
+	return goos + "/" + arch, arch != goarch
+}
+
+// archCommand returns the binary and argv that run bin under spec.Arch.
+// The native architecture launches bin unchanged. amd64 on an arm64 Mac runs
+// it under Rosetta 2 through arch(1); other cross-architecture launches have
+// no host emulator and fail with an error naming the platform.
+func archCommand(spec ContainerSpec, bin string, argv []string) (string, []string, error) {
+	return archCommandFor(runtime.GOOS, runtime.GOARCH, spec.Arch, bin, argv)
+}
+
+func archCommandFor(goos, goarch, arch, bin string, argv []string) (string, []string, error) {
+	if arch == "" || arch == goarch {
+		return bin, argv, nil
+	}
+	if goos == "darwin" && goarch == "arm64" && arch == "amd64" {
+		return "arch", append([]string{"-x86_64", bin}, argv...), nil
+	}
+	return "", nil, fmt.Errorf("host backend: cannot run %s/%s agents on a %s/%s host", goos, arch, goos, goarch)
+}
@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestArchCommandFor(t *testing.T) {
+	argv := []string{"-p", "hi"}
+	tests := []struct {
+		name, goos, goarch, arch string
+		wantBin                  string
+		wantArgv                 []string
+		wantErr                  string
+	}{
+		{name: "native default", goos: "linux", goarch: "amd64", arch: "", wantBin: "/bin/claude", wantArgv: argv},
+		{name: "native explicit", goos: "darwin", goarch: "arm64", arch: "arm64", wantBin: "/bin/claude", wantArgv: argv},
+		{name: "rosetta", goos: "darwin", goarch: "arm64", arch: "amd64", wantBin: "arch", wantArgv: []string{"-x86_64", "/bin/claude", "-p", "hi"}},
+		{name: "no emulator on linux", goos: "linux", goarch: "arm64", arch: "amd64", wantErr: "linux/amd64"},
+		{name: "no arm64 on intel mac", goos: "darwin", goarch: "amd64", arch: "arm64", wantErr: "darwin/arm64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin, got, err := archCommandFor(tt.goos, tt.goarch, tt.arch, "/bin/claude", argv)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want mention of %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("archCommandFor: %v", err)
+			}
+			if bin != tt.wantBin || !slices.Equal(got, tt.wantArgv) {
+				t.Fatalf("got %s %v, want %s %v", bin, got, tt.wantBin, tt.wantArgv)
+			}
+		})
+	}
+}
+
+func TestPlatformFor(t *testing.T) {
+	if p, emu := platformFor("darwin", "arm64", ""); p != "darwin/arm64" || emu {
+		t.Fatalf("native: got %q emulated=%v", p, emu)
+	}
+	if p, emu := platformFor("darwin", "arm64", "amd64"); p != "darwin/amd64" || !emu {
+		t.Fatalf("forced amd64: got %q emulated=%v", p, emu)
+	}
+}
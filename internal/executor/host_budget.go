@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"sync"
+)
+
+// agentBudget caps the number of concurrently running agent processes. Unlike
+// a buffered channel its limit can change while agents hold slots: lowering
+// it lets running agents finish and blocks new ones until the count drops
+// below the new limit, and raising it wakes waiters right away.
+type agentBudget struct {
+	mu      sync.Mutex
+	limit   int           // 0 ⇒ unlimited
+	used    int           // slots held, counted even while unlimited
+	changed chan struct{} // closed and replaced whenever used or limit changes
+}
+
+func newAgentBudget(limit int) *agentBudget {
+	return &agentBudget{limit: max(limit, 0), changed: make(chan struct{})}
+}
+
+// acquire reserves a slot, blocking until one frees or ctx is cancelled. The
+// returned release frees the slot exactly once.
+func (a *agentBudget) acquire(ctx context.Context) (func(), error) {
+	for {
+		a.mu.Lock()
+		if a.limit == 0 || a.used < a.limit {
+			a.used++
+			a.mu.Unlock()
+			var once sync.Once
+			return func() { once.Do(a.release) }, nil
+		}
+		changed := a.changed
+		a.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (a *agentBudget) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.used--
+	a.broadcastLocked()
+}
+
+// setLimit changes the cap; 0 or less means unlimited.
+func (a *agentBudget) setLimit(limit int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.limit = max(limit, 0)
+	a.broadcastLocked()
+}
+
+func (a *agentBudget) broadcastLocked() {
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
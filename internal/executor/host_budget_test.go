@@ -54,3 +54,44 @@ func TestAcquireSlot_Unlimited(t *testing.T) {
 		}
 	}
 }
+
+// TestAcquireSlot_LimitChangesWhileHeld proves the budget can be resized
+// under running agents: lowering it below the held count blocks new agents
+// until enough exit, and raising it wakes a waiting acquirer.
+func TestAcquireSlot_LimitChangesWhileHeld(t *testing.T) {
+	b, err := NewHostBackend(HostBackendConfig{MaxAgents: 0})
+	if err != nil {
+		t.Fatalf("NewHostBackend: %v", err)
+	}
+	rel1, _ := b.acquireSlot(context.Background())
+	rel2, _ := b.acquireSlot(context.Background())
+
+	b.budget.setLimit(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := b.acquireSlot(ctx); err == nil {
+		t.Fatal("acquire should block while two agents hold a budget of one")
+	}
+	rel1()
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel2()
+	if _, err := b.acquireSlot(ctx2); err == nil {
+		t.Fatal("acquire should block while one agent holds a budget of one")
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := b.acquireSlot(context.Background())
+		acquired <- err
+	}()
+	b.budget.setLimit(2)
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("acquire after raising the limit: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("raising the limit did not wake the waiting acquirer")
+	}
+	rel2()
+}
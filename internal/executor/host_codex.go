@@ -62,7 +62,8 @@ func (b *HostBackend) launchCodex(ctx context.Context, spec ContainerSpec) (Hand
 	}
 
 	codexH, _ := harness.Lookup(harness.Codex)
-	argv, _, argvErr := codexH.BuildArgv(req)
+	req = b.limitPrompt(codexH, req, spec)
+	argv, stdin, argvErr := codexH.BuildArgv(req)
 	if argvErr != nil {
 		return nil, fmt.Errorf("host backend: codex argv: %w", argvErr)
 	}
@@ -76,12 +77,19 @@ func (b *HostBackend) launchCodex(ctx context.Context, spec ContainerSpec) (Hand
 		return nil, fmt.Errorf("host backend: codex tmp dir: %w", err)
 	}
 	lastMsgFile := filepath.Join(tmpDir, "last-message.txt")
-	// argv ends with the prompt; insert --output-last-message before it.
+	// argv ends with the prompt placeholder ("-", read from stdin); insert
+	// --output-last-message before it.
 	prompt := argv[len(argv)-1]
 	argv = append(argv[:len(argv)-1:len(argv)-1], "--output-last-message", lastMsgFile, prompt)
 
+	bin, argv, err = wrapCommand(spec, bin, argv)
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, err
+	}
 	cmd := exec.CommandContext(ctx, bin, argv...)
 	cmd.Env = env
+	cmd.Stdin = stdin
 	if spec.WorkDir != "" {
 		cmd.Dir = spec.WorkDir
 	}
@@ -110,7 +118,7 @@ func (b *HostBackend) launchCodex(ctx context.Context, spec ContainerSpec) (Hand
 		transition(&h.state, StateFailed)
 		return nil, fmt.Errorf("start host agent: %w", err)
 	}
-	applyAgentPriority(cmd.Process.Pid, b.agentNice)
+	applyAgentPriority(cmd.Process.Pid, int(b.agentNice.Load()))
 	transition(&h.state, StateRunning)
 
 	b.procMu.Lock()
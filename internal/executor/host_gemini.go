@@ -0,0 +1,179 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/logger"
+)
+
+// launchGemini runs the gemini CLI in host mode. gemini's
+// `--output-format stream-json` ends with a native result event carrying
+// the status and token stats, but the assistant's reply only arrives as
+// streamed message deltas before it. The runner derives the agent output
+// from the terminal event, so we tee gemini's stdout, join the deltas of
+// the last assistant message, and forward the result line with that text
+// added as "response", which harness.Gemini.ParseEvent reads as the result
+// text.
+//
+// One gemini-specific adjustment to the shared Request:
+//
+//   - Permission is forced to Full so gemini runs with --approval-mode yolo;
+//     headless gemini drops every tool that would need an approval, so a
+//     lower mode could never write files or run commands.
+func (b *HostBackend) launchGemini(ctx context.Context, spec ContainerSpec) (Handle, error) {
+	bin, err := b.binaryFor(harness.Gemini)
+	if err != nil {
+		return nil, err
+	}
+
+	env := b.buildChildEnv(spec)
+	req := requestFromClaudeSpec(spec)
+	if req.Prompt == "" {
+		return nil, fmt.Errorf("host backend: gemini launch requires a -p <prompt> argument in spec.Cmd")
+	}
+	req.Permission = harness.PermissionFull
+
+	geminiH, _ := harness.Lookup(harness.Gemini)
+	req = b.limitPrompt(geminiH, req, spec)
+	argv, stdin, argvErr := geminiH.BuildArgv(req)
+	if argvErr != nil {
+		return nil, fmt.Errorf("host backend: gemini argv: %w", argvErr)
+	}
+
+	bin, argv, err = wrapCommand(spec, bin, argv)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, bin, argv...)
+	cmd.Env = env
+	cmd.Stdin = stdin
+	if spec.WorkDir != "" {
+		cmd.Dir = spec.WorkDir
+	}
+
+	// gemini's real stdout is consumed internally; a pipe exposes the tee'd
+	// stream (events with the completed result) to the runner.
+	geminiStdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdout pipe: %w", err)
+	}
+	geminiStderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stderr pipe: %w", err)
+	}
+
+	pipeR, pipeW := io.Pipe()
+
+	taskID := spec.Labels["wallfacer.task.id"]
+	h := newHostHandle(spec.Name, cmd, pipeR, geminiStderr, taskID, b)
+
+	configureProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		transition(&h.state, StateFailed)
+		return nil, fmt.Errorf("start host agent: %w", err)
+	}
+	applyAgentPriority(cmd.Process.Pid, int(b.agentNice.Load()))
+	transition(&h.state, StateRunning)
+
+	b.procMu.Lock()
+	b.procs[spec.Name] = h
+	b.procMu.Unlock()
+
+	go teeGeminiAndCompleteResult(geminiStdout, pipeW)
+
+	return h, nil
+}
+
+// geminiSniff captures the fields the tee needs from each gemini event.
+// Unknown fields are ignored, so this is forward-compatible.
+type geminiSniff struct {
+	Type    string `json:"type"`
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	Delta   bool   `json:"delta"`
+}
+
+// teeGeminiAndCompleteResult forwards each gemini stdout line to out while
+// joining the assistant message deltas. Tool calls start a new assistant
+// message, so only the text after the last tool call is kept: that is the
+// final answer. The result line is forwarded with the joined text added as
+// "response". A stream that ends without a result line (gemini crashed or
+// was killed) gets a synthesized error result so the runner still sees a
+// terminal event.
+func teeGeminiAndCompleteResult(geminiStdout io.Reader, out *io.PipeWriter) {
+	var text []byte
+	sawResult := false
+
+	scanner := bufio.NewScanner(geminiStdout)
+	// Tool results can carry whole files; lift the default 64 KiB line cap.
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var evt geminiSniff
+		if err := json.Unmarshal(line, &evt); err == nil {
+			switch evt.Type {
+			case "message":
+				if evt.Role == "assistant" {
+					if !evt.Delta {
+						text = text[:0]
+					}
+					text = append(text, evt.Content...)
+				}
+			case "tool_use":
+				text = text[:0]
+			case "result":
+				sawResult = true
+				line = withGeminiResponse(line, string(text))
+			}
+		}
+
+		if _, err := out.Write(append(append([]byte{}, line...), '\n')); err != nil {
+			_ = out.CloseWithError(err)
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Runner.Warn("host backend: scan gemini stdout", "error", err)
+	}
+
+	if !sawResult {
+		final, _ := json.Marshal(map[string]any{
+			"type":     "result",
+			"status":   "error",
+			"response": string(text),
+			"error":    map[string]string{"type": "missing_result", "message": "gemini exited without a result event"},
+		})
+		_, _ = out.Write(append(final, '\n'))
+	}
+	_ = out.Close()
+}
+
+// withGeminiResponse returns the result line with response set to text,
+// leaving the line untouched when it already carries one or is not an
+// object.
+func withGeminiResponse(line []byte, text string) []byte {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(line, &obj); err != nil {
+		return line
+	}
+	if _, ok := obj["response"]; ok {
+		return line
+	}
+	resp, err := json.Marshal(text)
+	if err != nil {
+		return line
+	}
+	obj["response"] = resp
+	completed, err := json.Marshal(obj)
+	if err != nil {
+		return line
+	}
+	return completed
+}
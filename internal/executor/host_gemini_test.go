@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/harness"
+)
+
+// teeGemini runs teeGeminiAndCompleteResult over stream and returns the
+// forwarded lines.
+func teeGemini(t *testing.T, stream string) []string {
+	t.Helper()
+	pr, pw := io.Pipe()
+	go teeGeminiAndCompleteResult(strings.NewReader(stream), pw)
+	out, err := io.ReadAll(pr)
+	if err != nil {
+		t.Fatalf("read tee: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+func TestTeeGemini_AddsFinalMessageToResult(t *testing.T) {
+	lines := teeGemini(t, strings.Join([]string{
+		`{"type":"init","session_id":"s1","model":"gemini-2.5-pro"}`,
+		`{"type":"message","role":"assistant","content":"Let me look.","delta":true}`,
+		`{"type":"tool_use","tool_name":"read_file","tool_id":"t1","parameters":{}}`,
+		`{"type":"tool_result","tool_id":"t1","status":"success","output":"x"}`,
+		`{"type":"message","role":"assistant","content":"All ","delta":true}`,
+		`{"type":"message","role":"assistant","content":"done.","delta":true}`,
+		`{"type":"result","status":"success","stats":{"input_tokens":10,"output_tokens":5}}`,
+	}, "\n"))
+	if len(lines) != 7 {
+		t.Fatalf("got %d lines, want every line forwarded: %v", len(lines), lines)
+	}
+
+	geminiH, _ := harness.Lookup(harness.Gemini)
+	res, err := geminiH.ParseEvent([]byte(lines[6]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Kind != harness.KindResult || res.Text != "All done." || res.Usage == nil || res.Usage.InputTokens != 10 {
+		t.Errorf("result = %+v, want the text after the last tool call", res)
+	}
+}
+
+func TestTeeGemini_MissingResultIsError(t *testing.T) {
+	lines := teeGemini(t, `{"type":"message","role":"assistant","content":"partial","delta":true}`)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want a synthesized result appended: %v", len(lines), lines)
+	}
+	var res map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res["type"] != "result" || res["status"] != "error" || res["response"] != "partial" {
+		t.Errorf("synthesized result = %v", res)
+	}
+}
+
+func TestHostBackend_LaunchGemini_MissingPromptFails(t *testing.T) {
+	b, _ := NewHostBackend(HostBackendConfig{GeminiBinary: "/bin/sh"})
+	spec := ContainerSpec{
+		Name:    "wallfacer-gemini-noprompt",
+		Env:     map[string]string{"WALLFACER_AGENT": "gemini"},
+		Cmd:     []string{"--verbose"},
+		WorkDir: t.TempDir(),
+	}
+	_, err := b.Launch(t.Context(), spec)
+	if err == nil || !strings.Contains(err.Error(), "-p") {
+		t.Errorf("Launch without -p: err = %v, want missing prompt error", err)
+	}
+}
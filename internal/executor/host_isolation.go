@@ -0,0 +1,299 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/devcache"
+)
+
+// Isolation is how far the host backend confines an agent process. Every
+// level still runs the agent as the server's user with its inherited
+// environment; none of them isolates as strongly as a container would.
+type Isolation string
+
+// Isolation constants.
+const (
+	// IsolationNone runs the agent unconfined, as the server's user with the
+	// user's home directory. The default.
+	IsolationNone Isolation = "none"
+	// IsolationRestricted gives each launch a temporary HOME, TMPDIR, and
+	// XDG directories, disables core dumps, and caps open files and the size
+	// of written files. The filesystem stays writable as the server's user.
+	IsolationRestricted Isolation = "restricted"
+	// IsolationNsjail applies the restricted settings and runs the agent in
+	// nsjail (Linux only) with a read-only root filesystem, a private /tmp,
+	// and its own PID, mount, IPC, and UTS namespaces. Only the working
+	// directory, its git metadata, the temporary HOME, the agent CLI's state
+	// directories, and the managed caches are writable. The network is shared.
+	IsolationNsjail Isolation = "nsjail"
+)
+
+// IsolationEnv is the variable, read per launch from the agent's merged
+// environment (server environment, env file, spec.Env), that selects the
+// isolation level.
+const IsolationEnv = "WALLFACER_HOST_ISOLATION"
+
+// Resource limits applied under restricted and nsjail isolation.
+const (
+	isolationMaxOpenFiles = 4096
+	isolationMaxFileMiB   = 4096 // largest file the agent may write
+)
+
+// ParseIsolation parses an isolation level; "" means IsolationNone.
+func ParseIsolation(s string) (Isolation, error) {
+	switch iso := Isolation(strings.ToLower(strings.TrimSpace(s))); iso {
+	case "", IsolationNone:
+		return IsolationNone, nil
+	case IsolationRestricted, IsolationNsjail:
+		return iso, nil
+	}
+	return "", fmt.Errorf("unknown %s %q (want none, restricted, or nsjail)", IsolationEnv, s)
+}
+
+// agentStateDirs maps the variables that locate an agent CLI's credentials
+// and session history to their default under the real home directory. A
+// temporary HOME would otherwise log the agent out and break --resume, so
+// isolation pins them to where they already are.
+var agentStateDirs = map[string]string{
+	"CLAUDE_CONFIG_DIR": ".claude",
+	"CODEX_HOME":        ".codex",
+}
+
+// agentHomeDirs are agent CLI state directories that no variable can move,
+// such as Gemini's login and sessions in ~/.gemini. Isolation links them
+// from the real home directory into the temporary one.
+var agentHomeDirs = []string{".gemini"}
+
+// isolate prepares spec for the isolation level selected in env, the merged
+// child environment: it creates the launch's temporary HOME and points the
+// home-derived variables at it. cleanup removes the temporary HOME; it is a
+// no-op when nothing was created.
+func isolate(spec ContainerSpec, env []string) (out ContainerSpec, cleanup func(), err error) {
+	cleanup = func() {}
+	iso, err := ParseIsolation(envValue(env, IsolationEnv))
+	if err != nil {
+		return spec, cleanup, fmt.Errorf("host backend: %w", err)
+	}
+	spec.isolation = iso
+	if iso == IsolationNone {
+		return spec, cleanup, nil
+	}
+	if runtime.GOOS == "windows" {
+		return spec, cleanup, fmt.Errorf("host backend: %s isolation is not supported on windows", iso)
+	}
+	if iso == IsolationNsjail && runtime.GOOS != "linux" {
+		return spec, cleanup, fmt.Errorf("host backend: nsjail isolation requires linux")
+	}
+
+	home, err := os.MkdirTemp("", "wallfacer-home-")
+	if err != nil {
+		return spec, cleanup, fmt.Errorf("host backend: isolated home: %w", err)
+	}
+	cleanup = func() { _ = os.RemoveAll(home) }
+	if err := os.Mkdir(filepath.Join(home, "tmp"), 0o700); err != nil {
+		cleanup()
+		return spec, func() {}, fmt.Errorf("host backend: isolated home: %w", err)
+	}
+
+	realHome := envValue(env, "HOME")
+	if realHome == "" {
+		realHome, _ = os.UserHomeDir()
+	}
+	overlay := make(map[string]string, len(spec.Env)+12)
+	for k, v := range spec.Env {
+		overlay[k] = v
+	}
+	for key, dir := range agentStateDirs {
+		if v := envValue(env, key); v != "" {
+			overlay[key] = v
+		} else if realHome != "" {
+			overlay[key] = filepath.Join(realHome, dir)
+		}
+	}
+	for _, dir := range agentHomeDirs {
+		if realHome == "" {
+			break
+		}
+		src := filepath.Join(realHome, dir)
+		if info, err := os.Stat(src); err != nil || !info.IsDir() {
+			continue
+		}
+		if err := os.Symlink(src, filepath.Join(home, dir)); err != nil {
+			cleanup()
+			return spec, func() {}, fmt.Errorf("host backend: isolated home: %w", err)
+		}
+	}
+	if envValue(env, "GIT_CONFIG_GLOBAL") == "" && realHome != "" {
+		if gitconfig := filepath.Join(realHome, ".gitconfig"); fileExists(gitconfig) {
+			overlay["GIT_CONFIG_GLOBAL"] = gitconfig // keep the commit identity
+		}
+	}
+	overlay["HOME"] = home
+	overlay["TMPDIR"] = filepath.Join(home, "tmp")
+	overlay["XDG_CONFIG_HOME"] = filepath.Join(home, ".config")
+	overlay["XDG_CACHE_HOME"] = filepath.Join(home, ".cache")
+	overlay["XDG_DATA_HOME"] = filepath.Join(home, ".local", "share")
+	overlay["XDG_STATE_HOME"] = filepath.Join(home, ".local", "state")
+	spec.Env = overlay
+	return spec, cleanup, nil
+}
+
+// wrapCommand returns the binary and argv that launch bin as spec asks:
+// under spec.Arch (see archCommand) and inside spec's isolation.
+func wrapCommand(spec ContainerSpec, bin string, argv []string) (string, []string, error) {
+	bin, argv, err := archCommand(spec, bin, argv)
+	if err != nil {
+		return "", nil, err
+	}
+	switch spec.isolation {
+	case IsolationRestricted:
+		return restrictedCommand(bin, argv)
+	case IsolationNsjail:
+		nsjail, err := exec.LookPath("nsjail")
+		if err != nil {
+			return "", nil, fmt.Errorf("host backend: nsjail isolation: nsjail not found in PATH")
+		}
+		cwd := spec.WorkDir
+		if cwd == "" {
+			cwd, _ = os.Getwd()
+		}
+		return nsjail, nsjailArgs(cwd, isolationWritable(spec), bin, argv), nil
+	}
+	return bin, argv, nil
+}
+
+// restrictedCommand runs bin through sh with the restricted resource limits.
+// A limit the hard limit does not allow is skipped rather than failing the
+// launch.
+func restrictedCommand(bin string, argv []string) (string, []string, error) {
+	script := fmt.Sprintf(`ulimit -c 0; ulimit -n %d 2>/dev/null; ulimit -f %d 2>/dev/null; exec "$@"`,
+		isolationMaxOpenFiles, isolationMaxFileMiB*2048) // ulimit -f counts 512-byte blocks
+	return "/bin/sh", append([]string{"-c", script, "wallfacer-isolation", bin}, argv...), nil
+}
+
+// nsjailArgs builds the nsjail argv that runs bin in cwd with the host root
+// mounted read-only, a fresh /tmp, and writable bind mounts of writable.
+// nsjail's own defaults (a 600 s time limit, a 1 MiB file size cap, an
+// emptied environment) are overridden to suit a long-running agent.
+func nsjailArgs(cwd string, writable []string, bin string, argv []string) []string {
+	args := []string{
+		"--mode", "o", "--quiet", "--keep_env",
+		"--disable_clone_newnet", "--time_limit", "0",
+		"--rlimit_as", "inf", "--rlimit_cpu", "inf", "--rlimit_core", "0",
+		"--rlimit_fsize", strconv.Itoa(isolationMaxFileMiB),
+		"--rlimit_nofile", strconv.Itoa(isolationMaxOpenFiles),
+		"--rlimit_nproc", "soft", "--rlimit_stack", "soft",
+		"--bindmount_ro", "/", "--tmpfsmount", "/tmp",
+	}
+	for _, dir := range writable {
+		args = append(args, "--bindmount", dir)
+	}
+	args = append(args, "--cwd", cwd, "--", bin)
+	return append(args, argv...)
+}
+
+// isolationWritable lists the existing directories an nsjail-isolated agent
+// must be able to write: the working directory and the git metadata of the
+// worktrees in it, the temporary HOME, the agent CLI state directories
+// (including those linked into HOME), and the managed caches.
+func isolationWritable(spec ContainerSpec) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if dir == "" || seen[dir] {
+			return
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	if spec.WorkDir != "" {
+		add(spec.WorkDir)
+		for _, dir := range gitCommonDirs(spec.WorkDir) {
+			add(dir)
+		}
+	}
+	add(spec.Env["HOME"])
+	for key := range agentStateDirs {
+		add(spec.Env[key])
+	}
+	for _, dir := range agentHomeDirs {
+		if home := spec.Env["HOME"]; home != "" {
+			if target, err := os.Readlink(filepath.Join(home, dir)); err == nil {
+				add(target)
+			}
+		}
+	}
+	for _, kind := range devcache.Kinds {
+		for _, key := range kind.Env {
+			add(spec.Env[key])
+		}
+	}
+	return dirs
+}
+
+// gitCommonDirs returns the shared git directories of the linked worktrees
+// at dir and directly below it (a multi-repository task's worktree root).
+// A worktree's .git is a file naming its per-worktree git directory, whose
+// commondir file names the repository's .git; commits write to both, and
+// both lie outside the worktree.
+func gitCommonDirs(dir string) []string {
+	var out []string
+	candidates := []string{dir}
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() && e.Name() != ".git" {
+				candidates = append(candidates, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	for _, c := range candidates {
+		data, err := os.ReadFile(filepath.Join(c, ".git"))
+		if err != nil {
+			continue // not a linked worktree (a directory, or nothing)
+		}
+		gitdir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !ok {
+			continue
+		}
+		gitdir = strings.TrimSpace(gitdir)
+		if !filepath.IsAbs(gitdir) {
+			gitdir = filepath.Join(c, gitdir)
+		}
+		common := gitdir
+		if rel, err := os.ReadFile(filepath.Join(gitdir, "commondir")); err == nil {
+			common = strings.TrimSpace(string(rel))
+			if !filepath.IsAbs(common) {
+				common = filepath.Join(gitdir, common)
+			}
+		}
+		out = append(out, filepath.Clean(common))
+	}
+	return out
+}
+
+// envValue returns the value of key in env, a KEY=VALUE list; the last
+// entry wins, as in exec.Cmd.
+func envValue(env []string, key string) string {
+	prefix := key + "="
+	v := ""
+	for _, kv := range env {
+		if rest, ok := strings.CutPrefix(kv, prefix); ok {
+			v = rest
+		}
+	}
+	return v
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,171 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseIsolation(t *testing.T) {
+	for in, want := range map[string]Isolation{
+		"":           IsolationNone,
+		"none":       IsolationNone,
+		"Restricted": IsolationRestricted,
+		" nsjail ":   IsolationNsjail,
+	} {
+		got, err := ParseIsolation(in)
+		if err != nil || got != want {
+			t.Errorf("ParseIsolation(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseIsolation("chroot"); err == nil || !strings.Contains(err.Error(), IsolationEnv) {
+		t.Fatalf("unknown level: err = %v, want mention of %s", err, IsolationEnv)
+	}
+}
+
+func TestIsolate_None(t *testing.T) {
+	spec := ContainerSpec{Env: map[string]string{"A": "1"}}
+	got, cleanup, err := isolate(spec, []string{"HOME=/home/u"})
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("isolate: %v", err)
+	}
+	if got.isolation != IsolationNone || len(got.Env) != 1 {
+		t.Fatalf("got isolation %q env %v; want none and spec.Env untouched", got.isolation, got.Env)
+	}
+}
+
+func TestIsolate_Restricted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("isolation is not supported on windows")
+	}
+	realHome := t.TempDir()
+	if err := os.WriteFile(filepath.Join(realHome, ".gitconfig"), []byte("[user]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(realHome, ".gemini"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	spec := ContainerSpec{Env: map[string]string{"WALLFACER_AGENT": "claude"}}
+	env := []string{"HOME=" + realHome, "CODEX_HOME=/srv/codex", IsolationEnv + "=restricted"}
+
+	got, cleanup, err := isolate(spec, env)
+	if err != nil {
+		t.Fatalf("isolate: %v", err)
+	}
+	home := got.Env["HOME"]
+	if got.isolation != IsolationRestricted || home == "" || home == realHome {
+		t.Fatalf("isolation %q HOME %q; want restricted with a temporary HOME", got.isolation, home)
+	}
+	if info, err := os.Stat(got.Env["TMPDIR"]); err != nil || !info.IsDir() || !strings.HasPrefix(got.Env["TMPDIR"], home) {
+		t.Errorf("TMPDIR = %q (%v); want an existing directory under HOME", got.Env["TMPDIR"], err)
+	}
+	if want := filepath.Join(realHome, ".claude"); got.Env["CLAUDE_CONFIG_DIR"] != want {
+		t.Errorf("CLAUDE_CONFIG_DIR = %q; want %q", got.Env["CLAUDE_CONFIG_DIR"], want)
+	}
+	if got.Env["CODEX_HOME"] != "/srv/codex" {
+		t.Errorf("CODEX_HOME = %q; want the inherited /srv/codex", got.Env["CODEX_HOME"])
+	}
+	if want := filepath.Join(realHome, ".gitconfig"); got.Env["GIT_CONFIG_GLOBAL"] != want {
+		t.Errorf("GIT_CONFIG_GLOBAL = %q; want %q", got.Env["GIT_CONFIG_GLOBAL"], want)
+	}
+	gemini := filepath.Join(realHome, ".gemini")
+	if target, err := os.Readlink(filepath.Join(home, ".gemini")); err != nil || target != gemini {
+		t.Errorf("HOME/.gemini links to %q (%v); want %q", target, err, gemini)
+	}
+	if !slices.Contains(isolationWritable(got), gemini) {
+		t.Errorf("isolationWritable(%v) lacks the linked %s", isolationWritable(got), gemini)
+	}
+	if _, ok := spec.Env["HOME"]; ok {
+		t.Error("isolate modified the caller's spec.Env")
+	}
+
+	cleanup()
+	if _, err := os.Stat(home); !os.IsNotExist(err) {
+		t.Errorf("temporary HOME survived cleanup: %v", err)
+	}
+}
+
+func TestIsolate_UnknownLevel(t *testing.T) {
+	_, cleanup, err := isolate(ContainerSpec{}, []string{IsolationEnv + "=jail"})
+	defer cleanup()
+	if err == nil {
+		t.Fatal("expected an error for an unknown isolation level")
+	}
+}
+
+func TestRestrictedCommand(t *testing.T) {
+	bin, argv, err := restrictedCommand("/bin/claude", []string{"-p", "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bin != "/bin/sh" || argv[0] != "-c" || !strings.Contains(argv[1], "ulimit -c 0") {
+		t.Fatalf("got %s %v; want a /bin/sh -c ulimit wrapper", bin, argv)
+	}
+	if want := []string{"wallfacer-isolation", "/bin/claude", "-p", "hi"}; !slices.Equal(argv[2:], want) {
+		t.Fatalf("trailing argv = %v; want %v", argv[2:], want)
+	}
+}
+
+func TestNsjailArgs(t *testing.T) {
+	args := nsjailArgs("/work", []string{"/work", "/repo/.git"}, "/bin/claude", []string{"-p", "hi"})
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"--bindmount_ro / ",
+		"--bindmount /work ",
+		"--bindmount /repo/.git ",
+		"--time_limit 0",
+		"--keep_env",
+		"--disable_clone_newnet",
+		"--cwd /work -- /bin/claude -p hi",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("nsjail args %q missing %q", joined, want)
+		}
+	}
+	if !strings.HasSuffix(joined, "-- /bin/claude -p hi") {
+		t.Errorf("nsjail args %q do not end with the agent command", joined)
+	}
+}
+
+func TestGitCommonDirs(t *testing.T) {
+	root := t.TempDir()
+	common := filepath.Join(root, "repo", ".git")
+	gitdir := filepath.Join(common, "worktrees", "task")
+	if err := os.MkdirAll(gitdir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(gitdir, "commondir"), []byte("../..\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A multi-repository worktree root: one linked worktree below it, and a
+	// plain directory that is not a worktree.
+	wt := filepath.Join(root, "wt")
+	if err := os.MkdirAll(filepath.Join(wt, "repo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(wt, "notes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "repo", ".git"), []byte("gitdir: "+gitdir+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := gitCommonDirs(wt)
+	if want := []string{common}; !slices.Equal(got, want) {
+		t.Fatalf("gitCommonDirs = %v; want %v", got, want)
+	}
+}
+
+func TestEnvValue(t *testing.T) {
+	env := []string{"A=1", "AB=2", "A=3"}
+	if got := envValue(env, "A"); got != "3" {
+		t.Fatalf("envValue(A) = %q; want the last entry 3", got)
+	}
+	if got := envValue(env, "B"); got != "" {
+		t.Fatalf("envValue(B) = %q; want empty", got)
+	}
+}
@@ -41,11 +41,16 @@ func (b *HostBackend) launchOpenCode(ctx context.Context, spec ContainerSpec) (H
 	req.Cwd = spec.WorkDir
 
 	openCodeH, _ := harness.Lookup(harness.OpenCode)
+	req = b.limitPrompt(openCodeH, req, spec)
 	argv, _, argvErr := openCodeH.BuildArgv(req)
 	if argvErr != nil {
 		return nil, fmt.Errorf("host backend: opencode argv: %w", argvErr)
 	}
 
+	bin, argv, err = wrapCommand(spec, bin, argv)
+	if err != nil {
+		return nil, err
+	}
 	cmd := exec.CommandContext(ctx, bin, argv...)
 	cmd.Env = env
 	if spec.WorkDir != "" {
@@ -73,7 +78,7 @@ func (b *HostBackend) launchOpenCode(ctx context.Context, spec ContainerSpec) (H
 		transition(&h.state, StateFailed)
 		return nil, fmt.Errorf("start host agent: %w", err)
 	}
-	applyAgentPriority(cmd.Process.Pid, b.agentNice)
+	applyAgentPriority(cmd.Process.Pid, int(b.agentNice.Load()))
 	transition(&h.state, StateRunning)
 
 	b.procMu.Lock()
@@ -5,6 +5,7 @@ package executor
 import (
 	"os"
 	"os/exec"
+	"runtime"
 	"syscall"
 )
 
@@ -44,3 +45,26 @@ func terminateGroupKill(cmd *exec.Cmd) error {
 	}
 	return cmd.Process.Kill()
 }
+
+// exitSignal returns the name of the signal that terminated the process and
+// whether it was SIGKILL. A normal exit returns ("", false).
+func exitSignal(ps *os.ProcessState) (string, bool) {
+	ws, ok := ps.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return "", false
+	}
+	return ws.Signal().String(), ws.Signal() == syscall.SIGKILL
+}
+
+// maxRSSBytes returns the peak resident set size of the exited process.
+// getrusage reports ru_maxrss in kilobytes on Linux and bytes on macOS.
+func maxRSSBytes(ps *os.ProcessState) int64 {
+	ru, ok := ps.SysUsage().(*syscall.Rusage)
+	if !ok || ru == nil {
+		return 0
+	}
+	if runtime.GOOS == "darwin" {
+		return int64(ru.Maxrss)
+	}
+	return int64(ru.Maxrss) * 1024
+}
@@ -28,3 +28,10 @@ func terminateGroupKill(cmd *exec.Cmd) error {
 	}
 	return cmd.Process.Kill()
 }
+
+// exitSignal always reports a normal exit: Windows processes end with an exit
+// code, never a signal.
+func exitSignal(_ *os.ProcessState) (string, bool) { return "", false }
+
+// maxRSSBytes is not reported on Windows.
+func maxRSSBytes(_ *os.ProcessState) int64 { return 0 }
@@ -14,6 +14,8 @@ import (
 	"syscall"
 	"testing"
 	"time"
+
+	"latere.ai/x/wallfacer/internal/harness"
 )
 
 // buildFakeAgent compiles testdata/fakeagent into a temp binary named `name`
@@ -95,6 +97,51 @@ func TestNewHostBackend_UsesLookupWhenEmpty(t *testing.T) {
 	}
 }
 
+func TestHostBackend_Reconfigure(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir) // keep $PATH lookups from finding real CLIs
+	first := filepath.Join(dir, "claude-1")
+	second := filepath.Join(dir, "claude-2")
+	for _, p := range []string{first, second} {
+		if err := os.WriteFile(p, []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := NewHostBackend(HostBackendConfig{ClaudeBinary: first, MaxAgents: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Reconfigure(HostBackendConfig{ClaudeBinary: second, AgentNice: 5, MaxAgents: 3}); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	if bin, _ := b.binaryFor(harness.Claude); bin != second {
+		t.Errorf("claude binary = %q, want %q", bin, second)
+	}
+	if nice := b.agentNice.Load(); nice != 5 {
+		t.Errorf("agent nice = %d, want 5", nice)
+	}
+	for i := range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		if _, err := b.acquireSlot(ctx); err != nil {
+			t.Fatalf("slot %d under the raised budget: %v", i, err)
+		}
+		cancel()
+	}
+
+	// A missing explicit path is reported and keeps the working binary.
+	err = b.Reconfigure(HostBackendConfig{ClaudeBinary: filepath.Join(dir, "gone")})
+	if err == nil || !strings.Contains(err.Error(), "gone") {
+		t.Errorf("Reconfigure with a missing binary: err = %v", err)
+	}
+	if bin, _ := b.binaryFor(harness.Claude); bin != second {
+		t.Errorf("claude binary after failed reload = %q, want %q", bin, second)
+	}
+	if nice := b.agentNice.Load(); nice != DefaultAgentNice {
+		t.Errorf("agent nice = %d, want the default %d", nice, DefaultAgentNice)
+	}
+}
+
 // launchAndDrain runs Launch with a minimal spec and returns the parsed NDJSON
 // init record the fakeagent emits. Useful for asserting argv / env wiring.
 func launchAndDrain(t *testing.T, b *HostBackend, spec ContainerSpec) map[string]any {
@@ -147,6 +194,36 @@ func TestHostBackend_Launch_Argv(t *testing.T) {
 	}
 }
 
+// TestHostBackend_Launch_LargePromptViaStdin verifies that a prompt far above
+// the per-argument exec limit reaches claude intact, and that the backend's
+// prompt budget truncates it with a marker and reports the cut.
+func TestHostBackend_Launch_LargePromptViaStdin(t *testing.T) {
+	bin := buildFakeAgent(t, "fakeagent")
+	prompt := strings.Repeat("p", 3*harness.MaxArgPromptBytes)
+	spec := ContainerSpec{
+		Name:    "wallfacer-test-large-prompt",
+		Env:     map[string]string{"WALLFACER_AGENT": "claude"},
+		Cmd:     []string{"-p", prompt},
+		WorkDir: t.TempDir(),
+	}
+
+	b, _ := NewHostBackend(HostBackendConfig{ClaudeBinary: bin})
+	if got, _ := launchAndDrain(t, b, spec)["prompt"].(string); got != prompt {
+		t.Errorf("prompt arrived with %d bytes, want %d", len(got), len(prompt))
+	}
+
+	var original, truncated int
+	spec.OnPromptTruncated = func(o, n int) { original, truncated = o, n }
+	b, _ = NewHostBackend(HostBackendConfig{ClaudeBinary: bin, MaxPromptBytes: 4096})
+	got, _ := launchAndDrain(t, b, spec)["prompt"].(string)
+	if len(got) > 4096 || !strings.Contains(got, "[prompt truncated") {
+		t.Errorf("budgeted prompt = %d bytes, tail %q", len(got), got[max(len(got)-80, 0):])
+	}
+	if original != len(prompt) || truncated != len(got) {
+		t.Errorf("OnPromptTruncated(%d, %d), want (%d, %d)", original, truncated, len(prompt), len(got))
+	}
+}
+
 // TestHostBackend_Launch_NoFastPrompt locks the WALLFACER_SANDBOX_FAST removal
 // at the executor altitude: a plain claude launch (no instructions path) must
 // pass no --append-system-prompt at all, so the retired /fast hint cannot leak
@@ -499,3 +576,44 @@ func TestSetEnv(t *testing.T) {
 		t.Errorf("D append failed: %v", env)
 	}
 }
+
+// TestHostBackend_Launch_RestrictedIsolation verifies that an env file
+// selecting restricted isolation runs the agent with a temporary HOME that
+// is removed once the handle is reaped, while the agent's state directory
+// still points at the real home.
+func TestHostBackend_Launch_RestrictedIsolation(t *testing.T) {
+	bin := buildFakeAgent(t, "fakeagent")
+	b, _ := NewHostBackend(HostBackendConfig{ClaudeBinary: bin, CodexBinary: bin})
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte(IsolationEnv+"=restricted\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	realHome := t.TempDir()
+	t.Setenv("HOME", realHome)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+
+	spec := ContainerSpec{
+		Name:    "wallfacer-test-isolation",
+		EnvFile: envFile,
+		Env:     map[string]string{"WALLFACER_AGENT": "claude", "FAKEAGENT_A": "1"},
+		Cmd:     []string{"-p", "hi"},
+		WorkDir: t.TempDir(),
+	}
+	got := launchAndDrain(t, b, spec)
+
+	echo, _ := got["env_echo"].(map[string]any)
+	home, _ := echo["HOME"].(string)
+	if home == "" || home == realHome {
+		t.Fatalf("HOME = %q; want a temporary home, not %q", home, realHome)
+	}
+	if echo["FAKEAGENT_A"] != "1" {
+		t.Errorf("A = %v; want 1 (spec.Env must survive isolation)", echo["FAKEAGENT_A"])
+	}
+	if want := filepath.Join(realHome, ".claude"); echo["CLAUDE_CONFIG_DIR"] != want {
+		t.Errorf("CLAUDE_CONFIG_DIR = %v; want %q", echo["CLAUDE_CONFIG_DIR"], want)
+	}
+	if _, err := os.Stat(home); !os.IsNotExist(err) {
+		t.Errorf("temporary HOME %s survived Wait: %v", home, err)
+	}
+}
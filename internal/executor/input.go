@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrInputClosed is returned by InputWriter.WriteInput once the agent's
+// stdin was closed, by CloseInput or because the process exited.
+var ErrInputClosed = errors.New("agent input is closed")
+
+// InputWriter is the open stdin of an agent launched with
+// ContainerSpec.Interactive. Writes are serialised and never interleave
+// with the initial stdin payload.
+type InputWriter interface {
+	// WriteInput writes p, already framed for the agent (see
+	// harness.InputEncoder), to the agent's stdin.
+	WriteInput(p []byte) error
+	// CloseInput closes stdin, which tells an interactive agent that no
+	// more input follows. Closing twice is a no-op.
+	CloseInput() error
+}
+
+// InputProvider is implemented by handles that can be launched
+// interactively. Input returns nil when the process was not, either
+// because the spec did not ask for it or because its harness lacks the
+// InteractiveInput capability. Like ExitReporter it is optional, so test
+// doubles need not implement it.
+type InputProvider interface {
+	Input() InputWriter
+}
+
+// hostInput is the stdin pipe of an interactive host agent.
+type hostInput struct {
+	mu     sync.Mutex
+	w      io.WriteCloser
+	closed bool
+}
+
+// start writes the initial payload in the background, holding the lock
+// until it is through so input sent meanwhile queues behind it.
+func (in *hostInput) start(payload io.Reader) {
+	in.mu.Lock()
+	go func() {
+		defer in.mu.Unlock()
+		if payload != nil {
+			_, _ = io.Copy(in.w, payload)
+		}
+	}()
+}
+
+// WriteInput implements InputWriter.
+func (in *hostInput) WriteInput(p []byte) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.closed {
+		return ErrInputClosed
+	}
+	if _, err := in.w.Write(p); err != nil {
+		return errors.Join(ErrInputClosed, err)
+	}
+	return nil
+}
+
+// CloseInput implements InputWriter.
+func (in *hostInput) CloseInput() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.closed {
+		return nil
+	}
+	in.closed = true
+	return in.w.Close()
+}
+
+// Input implements InputProvider.
+func (h *hostHandle) Input() InputWriter {
+	if h.input == nil {
+		return nil
+	}
+	return h.input
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHostBackend_Launch_Interactive sends input to a running claude
+// launched with stream-json input and checks that closing stdin ends it.
+func TestHostBackend_Launch_Interactive(t *testing.T) {
+	bin := buildFakeAgent(t, "fakeagent")
+	b, _ := NewHostBackend(HostBackendConfig{ClaudeBinary: bin})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	h, err := b.Launch(ctx, ContainerSpec{
+		Name:        "wallfacer-test-interactive",
+		Env:         map[string]string{"WALLFACER_AGENT": "claude"},
+		Cmd:         []string{"-p", "first"},
+		WorkDir:     t.TempDir(),
+		Interactive: true,
+	})
+	if err != nil {
+		t.Fatalf("launch: %v", err)
+	}
+	go func() { _, _ = io.Copy(io.Discard, h.Stderr()) }()
+	in := h.(InputProvider).Input()
+	if in == nil {
+		t.Fatal("interactive launch has no input")
+	}
+	out := bufio.NewScanner(h.Stdout())
+	next := func() string {
+		if !out.Scan() {
+			t.Fatalf("stdout ended early: %v", out.Err())
+		}
+		return out.Text()
+	}
+	if line := next(); !strings.Contains(line, `"echo":"first"`) {
+		t.Fatalf("first line = %s, want the prompt echoed", line)
+	}
+	if err := in.WriteInput([]byte(`{"type":"user","message":{"role":"user","content":"y"}}` + "\n")); err != nil {
+		t.Fatalf("WriteInput: %v", err)
+	}
+	if line := next(); !strings.Contains(line, `"echo":"y"`) {
+		t.Fatalf("second line = %s, want the input echoed", line)
+	}
+	if err := in.CloseInput(); err != nil {
+		t.Fatalf("CloseInput: %v", err)
+	}
+	if line := next(); !strings.Contains(line, `"type":"result"`) {
+		t.Fatalf("last line = %s, want the result", line)
+	}
+	if _, err := h.Wait(); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if err := in.WriteInput([]byte("late\n")); !errors.Is(err, ErrInputClosed) {
+		t.Errorf("WriteInput after close = %v, want ErrInputClosed", err)
+	}
+}
+
+func TestHostBackend_Launch_NotInteractiveHasNoInput(t *testing.T) {
+	bin := buildFakeAgent(t, "fakeagent")
+	b, _ := NewHostBackend(HostBackendConfig{ClaudeBinary: bin})
+	h, err := b.Launch(context.Background(), ContainerSpec{
+		Name:    "wallfacer-test-batch",
+		Env:     map[string]string{"WALLFACER_AGENT": "claude"},
+		Cmd:     []string{"-p", "hi"},
+		WorkDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("launch: %v", err)
+	}
+	_, _ = io.ReadAll(h.Stdout())
+	_, _ = io.ReadAll(h.Stderr())
+	_, _ = h.Wait()
+	if in := h.(InputProvider).Input(); in != nil {
+		t.Error("batch launch must not expose input")
+	}
+}
@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+)
+
+// prewarmProbeTimeout bounds a single `--version` probe. A cold node-based CLI
+// can take several seconds to load its modules from disk; anything slower is
+// treated as hung.
+const prewarmProbeTimeout = 30 * time.Second
+
+// binaryNames maps each harness to the executable name looked up on $PATH.
+var binaryNames = map[harness.ID]string{
+	harness.Claude:   "claude",
+	harness.Codex:    "codex",
+	harness.Cursor:   "cursor-agent",
+	harness.Gemini:   "gemini",
+	harness.OpenCode: "opencode",
+	harness.Pi:       "pi",
+}
+
+// PrewarmResult reports one agent CLI warm-up probe.
+type PrewarmResult struct {
+	Harness harness.ID `json:"harness"`
+	Binary  string     `json:"binary"`
+	Version string     `json:"version,omitempty"`
+	// Previous is the version seen by the prior Prewarm when it differs from
+	// Version, i.e. the CLI was upgraded in between.
+	Previous string        `json:"previous,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Upgraded reports whether the CLI version changed since the previous probe.
+func (p PrewarmResult) Upgraded() bool { return p.Previous != "" }
+
+// Prewarm runs `<cli> --version` for every resolvable agent CLI so the first
+// real task does not pay the cold-start cost of loading the CLI (and, for the
+// node-based CLIs, its module tree) from disk. Binaries that were not
+// resolvable at construction time are looked up again, which picks up a CLI
+// installed after the server started; a changed version string is reported
+// as an upgrade. Harnesses whose binary still cannot be found are skipped.
+func (b *HostBackend) Prewarm(ctx context.Context) []PrewarmResult {
+	var results []PrewarmResult
+	for _, id := range harness.All() {
+		if bin, ok := b.resolveForProbe(id); ok {
+			results = append(results, b.probe(ctx, id, bin))
+		}
+	}
+	return results
+}
+
+// Version runs `<cli> --version` for one harness and returns the trimmed
+// output. Like Prewarm, it looks up a binary that was missing at
+// construction time again.
+func (b *HostBackend) Version(ctx context.Context, id harness.ID) (string, error) {
+	bin, ok := b.resolveForProbe(id)
+	if !ok {
+		return "", fmt.Errorf("%s CLI not found on PATH", id)
+	}
+	res := b.probe(ctx, id, bin)
+	if res.Error != "" {
+		return "", fmt.Errorf("%s --version: %s", bin, res.Error)
+	}
+	return res.Version, nil
+}
+
+// resolveForProbe returns the binary for id, resolving and caching it when
+// it was not found before. ok is false when the harness has no CLI or the
+// CLI still cannot be found.
+func (b *HostBackend) resolveForProbe(id harness.ID) (string, bool) {
+	name, ok := binaryNames[id]
+	if !ok {
+		return "", false
+	}
+	bin, err := b.binaryFor(id)
+	if err == nil {
+		return bin, true
+	}
+	resolved, err := resolveBinary(b.explicitBinary(id), name)
+	if err != nil {
+		return "", false
+	}
+	b.setBinary(id, resolved)
+	return resolved, true
+}
+
+func (b *HostBackend) probe(ctx context.Context, id harness.ID, bin string) PrewarmResult {
+	res := PrewarmResult{Harness: id, Binary: bin}
+	probeCtx, cancel := context.WithTimeout(ctx, prewarmProbeTimeout)
+	defer cancel()
+	start := time.Now()
+	out, err := cmdexec.New(bin, "--version").WithContext(probeCtx).Output()
+	res.Duration = time.Since(start)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Version = strings.TrimSpace(out)
+
+	b.prewarmMu.Lock()
+	defer b.prewarmMu.Unlock()
+	if b.versions == nil {
+		b.versions = make(map[harness.ID]string)
+	}
+	if prev, seen := b.versions[id]; seen && prev != res.Version {
+		res.Previous = prev
+	}
+	b.versions[id] = res.Version
+	return res
+}
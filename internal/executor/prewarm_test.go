@@ -0,0 +1,125 @@
+//go:build !windows
+
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/harness"
+)
+
+// writeVersionScript writes an executable that prints the contents of
+// versionFile, so a test can "upgrade" the CLI by rewriting that file.
+func writeVersionScript(t *testing.T, dir, name, versionFile string) string {
+	t.Helper()
+	bin := filepath.Join(dir, name)
+	// Shell builtins only: the tests point $PATH at the temp dir.
+	script := "#!/bin/sh\nIFS= read -r v < " + versionFile + "\necho \"$v\"\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin
+}
+
+func findPrewarm(results []PrewarmResult, id harness.ID) (PrewarmResult, bool) {
+	for _, r := range results {
+		if r.Harness == id {
+			return r, true
+		}
+	}
+	return PrewarmResult{}, false
+}
+
+func TestPrewarm_ProbesVersionAndDetectsUpgrade(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir) // keep $PATH lookups from finding real CLIs
+	versionFile := filepath.Join(dir, "version")
+	if err := os.WriteFile(versionFile, []byte("1.0.0 (Claude Code)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	claude := writeVersionScript(t, dir, "claude-bin", versionFile)
+
+	b, err := NewHostBackend(HostBackendConfig{ClaudeBinary: claude})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, ok := findPrewarm(b.Prewarm(context.Background()), harness.Claude)
+	if !ok {
+		t.Fatal("claude was not probed")
+	}
+	if first.Version != "1.0.0 (Claude Code)" || first.Error != "" || first.Upgraded() {
+		t.Fatalf("first probe = %+v", first)
+	}
+
+	if err := os.WriteFile(versionFile, []byte("1.1.0 (Claude Code)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := findPrewarm(b.Prewarm(context.Background()), harness.Claude)
+	if !second.Upgraded() || second.Previous != "1.0.0 (Claude Code)" || second.Version != "1.1.0 (Claude Code)" {
+		t.Errorf("second probe = %+v, want upgrade from 1.0.0", second)
+	}
+}
+
+func TestPrewarm_ResolvesCLIInstalledAfterStartup(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	b, err := NewHostBackend(HostBackendConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := findPrewarm(b.Prewarm(context.Background()), harness.Codex); ok {
+		t.Fatal("codex probed before it was installed")
+	}
+
+	versionFile := filepath.Join(dir, "version")
+	if err := os.WriteFile(versionFile, []byte("codex-cli 0.9.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	installed := writeVersionScript(t, dir, "codex", versionFile)
+
+	res, ok := findPrewarm(b.Prewarm(context.Background()), harness.Codex)
+	if !ok || res.Version != "codex-cli 0.9.0" {
+		t.Fatalf("codex probe = %+v (found %v)", res, ok)
+	}
+	if bin, err := b.binaryFor(harness.Codex); err != nil || bin != installed {
+		t.Errorf("binaryFor(codex) = %q, %v; want %q", bin, err, installed)
+	}
+}
+
+func TestPrewarm_ReportsFailingProbe(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	bad := filepath.Join(dir, "claude-bad")
+	if err := os.WriteFile(bad, []byte("#!/bin/sh\nexit 3\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewHostBackend(HostBackendConfig{ClaudeBinary: bad})
+	res, ok := findPrewarm(b.Prewarm(context.Background()), harness.Claude)
+	if !ok || res.Error == "" || res.Version != "" {
+		t.Errorf("probe = %+v, want an error and no version", res)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	versionFile := filepath.Join(dir, "version")
+	if err := os.WriteFile(versionFile, []byte("2.1.0 (Claude Code)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	claude := writeVersionScript(t, dir, "claude-bin", versionFile)
+	b, err := NewHostBackend(HostBackendConfig{ClaudeBinary: claude})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := b.Version(context.Background(), harness.Claude); err != nil || v != "2.1.0 (Claude Code)" {
+		t.Errorf("Version(claude) = %q, %v", v, err)
+	}
+	if _, err := b.Version(context.Background(), harness.Codex); err == nil {
+		t.Error("Version(codex) should fail when the CLI is not installed")
+	}
+}
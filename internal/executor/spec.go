@@ -14,4 +14,15 @@ type ContainerSpec struct {
 	Env     map[string]string // env vars overlaid on top (wins on collision)
 	WorkDir string            // child process working directory (host path)
 	Cmd     []string          // agent argv (after harness BuildArgv)
+	Arch    string            // GOARCH to run the agent as; "" = host native (see Platform)
+	// Interactive keeps the agent's stdin open after the initial payload so
+	// input can be sent while it runs (see InputProvider). Honoured only for
+	// harnesses with the InteractiveInput capability.
+	Interactive bool
+	// OnPromptTruncated, when set, is called during Launch with the prompt's
+	// original and launched sizes in bytes if it had to be cut to fit (see
+	// HostBackendConfig.MaxPromptBytes), so the caller can tell the user.
+	OnPromptTruncated func(original, truncated int)
+
+	isolation Isolation // set by HostBackend.Launch from the child environment
 }
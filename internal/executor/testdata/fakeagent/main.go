@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -18,7 +19,7 @@ import (
 func main() {
 	// Support --help probing for the --append-system-prompt flag.
 	if len(os.Args) >= 2 && os.Args[1] == "--help" {
-		helpText := "fakeagent\n  -p string   prompt\n  --model string\n  --resume string\n"
+		helpText := "fakeagent\n  -p   print mode (prompt from stdin)\n  --model string\n  --resume string\n"
 		if os.Getenv("FAKEAGENT_NO_APPEND") != "1" {
 			helpText += "  --append-system-prompt string   path to a file whose content is appended to the system prompt\n"
 		}
@@ -36,12 +37,15 @@ func main() {
 	}
 
 	fs := flag.NewFlagSet("fakeagent", flag.ContinueOnError)
-	prompt := fs.String("p", "", "prompt")
+	// Like claude, -p is the print-mode switch; the prompt is a positional
+	// argument or, when absent, stdin.
+	_ = fs.Bool("p", false, "print mode")
 	model := fs.String("model", "", "model")
 	resume := fs.String("resume", "", "resume session id")
 	appendSys := fs.String("append-system-prompt", "", "append system prompt file")
 	verbose := fs.Bool("verbose", false, "verbose")
 	outputFormat := fs.String("output-format", "", "output format")
+	inputFormat := fs.String("input-format", "", "input format")
 	// Accept and ignore the claude-agent.sh wrapper's stability flag.
 	_ = fs.Bool("dangerously-skip-permissions", false, "")
 	// Ignore unknown flags quietly so real CLI args pass through without fuss.
@@ -52,6 +56,15 @@ func main() {
 	}
 	_ = verbose
 	_ = outputFormat
+	if *inputFormat == "stream-json" {
+		runFakeStreamInput()
+		return
+	}
+	prompt := strings.Join(fs.Args(), " ")
+	if prompt == "" {
+		in, _ := io.ReadAll(os.Stdin)
+		prompt = string(in)
+	}
 
 	// Optional sleep so Kill tests can catch a running process.
 	if s := os.Getenv("FAKEAGENT_SLEEP"); s != "" {
@@ -71,7 +84,7 @@ func main() {
 		"resume":   *resume,
 		"model":    *model,
 		"append":   *appendSys,
-		"prompt":   *prompt,
+		"prompt":   prompt,
 		"env_echo": envEcho(),
 	}
 	enc := json.NewEncoder(os.Stdout)
@@ -99,10 +112,33 @@ func main() {
 	}
 }
 
+// runFakeStreamInput mimics claude's --input-format stream-json: every
+// user message read from stdin is echoed back as one NDJSON line, and the
+// result line follows once stdin is closed.
+func runFakeStreamInput() {
+	enc := json.NewEncoder(os.Stdout)
+	dec := json.NewDecoder(os.Stdin)
+	for {
+		var msg struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			break
+		}
+		_ = enc.Encode(map[string]any{"type": "user", "echo": msg.Message.Content})
+	}
+	_ = enc.Encode(map[string]any{
+		"type": "result", "subtype": "success", "result": "fake",
+		"session_id": "fake-session", "stop_reason": "end_turn",
+	})
+}
+
 // envEcho returns a subset of env vars the tests care about, so they can
 // assert env-file merge / spec.Env overlay without dumping the full parent env.
 func envEcho() map[string]string {
-	keys := []string{"FAKEAGENT_A", "FAKEAGENT_B", "FAKEAGENT_C", "WALLFACER_AGENT"}
+	keys := []string{"FAKEAGENT_A", "FAKEAGENT_B", "FAKEAGENT_C", "WALLFACER_AGENT", "HOME", "TMPDIR", "CLAUDE_CONFIG_DIR"}
 	out := make(map[string]string, len(keys))
 	for _, k := range keys {
 		if v := os.Getenv(k); v != "" {
@@ -112,9 +148,6 @@ func envEcho() map[string]string {
 	return out
 }
 
-// Silence unused-import complaints when stripping flags in future edits.
-var _ = strings.TrimSpace
-
 // runFakeCodex mimics enough of `codex exec --json` for HostBackend tests:
 // parses --output-last-message / --model / --config / the trailing prompt,
 // writes the prompt back as the "last message", and emits two NDJSON
@@ -141,13 +174,17 @@ func runFakeCodex() {
 		case a == "--color" && i+1 < len(args):
 			i++
 		default:
-			if !strings.HasPrefix(a, "--") && !strings.HasPrefix(a, "-") {
+			if a == "-" || !strings.HasPrefix(a, "-") {
 				// Positional: the prompt (takes the last positional so a
 				// preamble + "---\n\n" + task is captured as a single arg).
 				prompt = a
 			}
 		}
 	}
+	if prompt == "-" {
+		in, _ := io.ReadAll(os.Stdin)
+		prompt = string(in)
+	}
 
 	if s := os.Getenv("FAKEAGENT_SLEEP"); s != "" {
 		if secs, err := strconv.Atoi(s); err == nil && secs > 0 {
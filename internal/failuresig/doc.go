@@ -0,0 +1,25 @@
+// Package failuresig correlates task failures across tasks and time. A
+// failure that keeps coming back in unrelated tasks (the same network
+// error, the same full disk) is usually the environment's fault rather than
+// the agent's, and a reviewer should fix the host instead of retrying.
+//
+// [Normalize] reduces an error message to a signature by masking the parts
+// that differ between occurrences: task IDs, hashes, numbers, temporary
+// paths. [Group] buckets failures by signature, phase, and repository within
+// a time window, and a small rules engine ([Rule], [DefaultRules]) marks the
+// signatures that look environmental and phrases a hint such as "This
+// failure has occurred in 7 tasks this week, likely environmental".
+//
+// # Connected packages
+//
+// Depends on [store] for the failure category type. Consumed by [handler]
+// (GET /api/failures/signatures and GET /api/tasks/{id}/failure-hints), which
+// reads the failures from the error events of the store's tasks.
+//
+// # Usage
+//
+//	sigs := failuresig.Group(failures, failuresig.DefaultRules, 7*24*time.Hour, time.Now())
+//	for _, s := range sigs {
+//		fmt.Println(s.TaskCount, s.Hint)
+//	}
+package failuresig
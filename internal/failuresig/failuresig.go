@@ -0,0 +1,175 @@
+package failuresig
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// maxSignatureLen caps a normalized message, so a long stderr dump still
+// yields a short, comparable signature.
+const maxSignatureLen = 240
+
+// maxTaskIDs caps how many task IDs one Signature lists.
+const maxTaskIDs = 20
+
+// Failure is one recorded failure of one task.
+type Failure struct {
+	TaskID   uuid.UUID
+	Phase    string // pipeline phase the error was raised in; "" for the agent run
+	Repo     string // repository the error concerns; "" when not repository-specific
+	Category store.FailureCategory
+	Message  string
+	At       time.Time
+}
+
+// Signature is a group of failures sharing a normalized message, phase, and
+// repository.
+type Signature struct {
+	ID        string                `json:"id"`
+	Signature string                `json:"signature"`
+	Phase     string                `json:"phase,omitempty"`
+	Repo      string                `json:"repo,omitempty"`
+	Category  store.FailureCategory `json:"category,omitempty"` // category of the latest occurrence
+	Count     int                   `json:"count"`              // occurrences, counting repeats within one task
+	TaskCount int                   `json:"task_count"`         // distinct tasks
+	TaskIDs   []uuid.UUID           `json:"task_ids"`           // newest first, at most 20
+	FirstSeen time.Time             `json:"first_seen"`
+	LastSeen  time.Time             `json:"last_seen"`
+	Example   string                `json:"example"`          // the latest raw message
+	Rule      string                `json:"rule,omitempty"`   // name of the rule that matched
+	Likely    string                `json:"likely,omitempty"` // "environmental" when a rule matched
+	Hint      string                `json:"hint,omitempty"`   // human-readable hint for failed tasks
+
+	tasks map[uuid.UUID]time.Time // every task with the signature and its latest occurrence
+}
+
+var (
+	ansiRe     = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	uuidRe     = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	hexRe      = regexp.MustCompile(`\b[0-9a-f]{7,64}\b`)
+	tmpPathRe  = regexp.MustCompile(`(/tmp|/var/folders|/private/var)/\S+`)
+	numberRe   = regexp.MustCompile(`\d+(\.\d+)?`)
+	spaceRe    = regexp.MustCompile(`\s+`)
+	wrapPrefix = regexp.MustCompile(`^(error|fatal|panic):\s*`)
+)
+
+// Normalize reduces an error message to its signature: lower-cased, with
+// ANSI escapes, IDs, hashes, temporary paths, and numbers masked and
+// whitespace collapsed, so two occurrences of the same failure compare
+// equal. Returns "" for a message with no text.
+func Normalize(msg string) string {
+	s := ansiRe.ReplaceAllString(msg, "")
+	s = uuidRe.ReplaceAllString(s, "<id>")
+	s = strings.ToLower(s)
+	s = hexRe.ReplaceAllString(s, "<hex>")
+	s = tmpPathRe.ReplaceAllString(s, "<tmp>")
+	s = numberRe.ReplaceAllString(s, "<n>")
+	s = strings.TrimSpace(spaceRe.ReplaceAllString(s, " "))
+	s = wrapPrefix.ReplaceAllString(s, "")
+	if len(s) > maxSignatureLen {
+		s = s[:maxSignatureLen]
+	}
+	return s
+}
+
+// Group buckets the failures that happened within window before now by
+// signature, phase, and repository, applies rules to each group, and
+// returns the groups most widespread first: by distinct tasks, then by most
+// recent occurrence.
+func Group(failures []Failure, rules []Rule, window time.Duration, now time.Time) []Signature {
+	since := now.Add(-window)
+	byID := make(map[string]*Signature)
+	for _, f := range failures {
+		if f.At.Before(since) || f.At.After(now) {
+			continue
+		}
+		norm := Normalize(f.Message)
+		if norm == "" {
+			continue
+		}
+		id := signatureID(norm, f.Phase, f.Repo)
+		sig, ok := byID[id]
+		if !ok {
+			sig = &Signature{ID: id, Signature: norm, Phase: f.Phase, Repo: f.Repo, FirstSeen: f.At, LastSeen: f.At,
+				tasks: make(map[uuid.UUID]time.Time)}
+			byID[id] = sig
+		}
+		sig.Count++
+		if f.At.Before(sig.FirstSeen) {
+			sig.FirstSeen = f.At
+		}
+		if !f.At.Before(sig.LastSeen) {
+			sig.LastSeen, sig.Example, sig.Category = f.At, f.Message, f.Category
+		}
+		last, ok := sig.tasks[f.TaskID]
+		if !ok {
+			sig.TaskCount++
+			sig.TaskIDs = append(sig.TaskIDs, f.TaskID)
+		}
+		if !ok || f.At.After(last) {
+			sig.tasks[f.TaskID] = f.At
+		}
+	}
+
+	out := make([]Signature, 0, len(byID))
+	for _, sig := range byID {
+		applyRules(sig, rules, window)
+		out = append(out, *sig)
+	}
+	// Order each signature's tasks newest first by their latest occurrence.
+	for i := range out {
+		latest := out[i].tasks
+		slices.SortFunc(out[i].TaskIDs, func(a, b uuid.UUID) int {
+			return cmp.Or(latest[b].Compare(latest[a]), strings.Compare(a.String(), b.String()))
+		})
+		if len(out[i].TaskIDs) > maxTaskIDs {
+			out[i].TaskIDs = out[i].TaskIDs[:maxTaskIDs]
+		}
+	}
+	slices.SortFunc(out, func(a, b Signature) int {
+		return cmp.Or(cmp.Compare(b.TaskCount, a.TaskCount), b.LastSeen.Compare(a.LastSeen), strings.Compare(a.ID, b.ID))
+	})
+	return out
+}
+
+// ForTask returns the signatures that include taskID.
+func ForTask(sigs []Signature, taskID uuid.UUID) []Signature {
+	var out []Signature
+	for _, s := range sigs {
+		if _, ok := s.tasks[taskID]; ok || slices.Contains(s.TaskIDs, taskID) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// signatureID is a short stable identifier of a signature within its phase
+// and repository.
+func signatureID(norm, phase, repo string) string {
+	sum := sha256.Sum256([]byte(phase + "\x00" + repo + "\x00" + norm))
+	return hex.EncodeToString(sum[:6])
+}
+
+// windowPhrase renders a window for a hint: "this week" or "in the last N
+// days" (hours below a day).
+func windowPhrase(window time.Duration) string {
+	switch days := int(window / (24 * time.Hour)); {
+	case days == 7:
+		return "this week"
+	case days == 1:
+		return "in the last day"
+	case days > 1:
+		return fmt.Sprintf("in the last %d days", days)
+	}
+	return fmt.Sprintf("in the last %d hours", max(1, int(window/time.Hour)))
+}
@@ -0,0 +1,113 @@
+package failuresig
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestNormalize(t *testing.T) {
+	a := Normalize("Error: container exited with code 137: stderr=worktree /tmp/wf-123/x for task 3f2a1b4c-1d2e-4f50-8a9b-0c1d2e3f4a5b")
+	b := Normalize("error: container exited with code 1:  stderr=worktree /tmp/wf-999/y for task 00000000-1111-4222-8333-444444444444")
+	if a != b {
+		t.Fatalf("signatures differ:\n%q\n%q", a, b)
+	}
+	if want := "container exited with code <n>: stderr=worktree <tmp> for task <id>"; a != want {
+		t.Errorf("Normalize = %q, want %q", a, want)
+	}
+	if got := Normalize("fatal: bad object 1a2b3c4d5e6f"); got != "bad object <hex>" {
+		t.Errorf("Normalize hash = %q", got)
+	}
+	if got := Normalize("  \x1b[31m \x1b[0m "); got != "" {
+		t.Errorf("Normalize blank = %q, want empty", got)
+	}
+	if got := Normalize(strings.Repeat("x", 1000)); len(got) != maxSignatureLen {
+		t.Errorf("Normalize long = %d bytes, want %d", len(got), maxSignatureLen)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tasks := make([]uuid.UUID, 4)
+	for i := range tasks {
+		tasks[i] = uuid.New()
+	}
+	netErr := func(task uuid.UUID, ago time.Duration) Failure {
+		return Failure{
+			TaskID: task, Phase: "push", Repo: "/src/app", Category: store.FailureCategoryUnknown,
+			Message: "fatal: unable to access 'https://github.com/x/y.git/': Could not resolve host: github.com",
+			At:      now.Add(-ago),
+		}
+	}
+	failures := []Failure{
+		netErr(tasks[0], 5*time.Hour),
+		netErr(tasks[0], 4*time.Hour), // repeat within one task
+		netErr(tasks[1], 3*time.Hour),
+		netErr(tasks[2], 2*time.Hour),
+		netErr(tasks[3], 10*24*time.Hour), // outside the window
+		{TaskID: tasks[3], Message: "budget exceeded: $5.00", Category: store.FailureCategoryBudget, At: now.Add(-time.Hour)},
+		{TaskID: tasks[1], Message: "   ", At: now.Add(-time.Hour)},
+	}
+	sigs := Group(failures, DefaultRules, 7*24*time.Hour, now)
+	if len(sigs) != 2 {
+		t.Fatalf("Group = %d signatures, want 2: %+v", len(sigs), sigs)
+	}
+	net := sigs[0]
+	if net.TaskCount != 3 || net.Count != 4 || net.Rule != "network" || net.Likely != LikelyEnvironmental {
+		t.Fatalf("network signature = %+v", net)
+	}
+	if want := "This failure has occurred in 3 tasks this week, likely environmental: network errors."; net.Hint != want {
+		t.Errorf("hint = %q, want %q", net.Hint, want)
+	}
+	if net.TaskIDs[0] != tasks[2] || !net.FirstSeen.Equal(now.Add(-5*time.Hour)) || !net.LastSeen.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("ordering: task_ids = %v, first %v, last %v", net.TaskIDs, net.FirstSeen, net.LastSeen)
+	}
+	if budget := sigs[1]; budget.Likely != "" || budget.Hint != "" {
+		t.Errorf("single budget failure should get no hint: %+v", budget)
+	}
+
+	if got := ForTask(sigs, tasks[1]); len(got) != 1 || got[0].ID != net.ID {
+		t.Errorf("ForTask = %+v", got)
+	}
+	if got := ForTask(sigs, tasks[3]); len(got) != 1 || got[0].ID != sigs[1].ID {
+		t.Errorf("ForTask outside the window = %+v", got)
+	}
+}
+
+func TestGroup_RecurringRule(t *testing.T) {
+	now := time.Now()
+	var failures []Failure
+	for i := range 3 {
+		failures = append(failures, Failure{
+			TaskID: uuid.New(), Category: store.FailureCategoryContainerCrash,
+			Message: "container exited with code 2: stderr=make: *** [build] Error 2", At: now.Add(-time.Duration(i) * time.Hour),
+		})
+	}
+	sigs := Group(failures, DefaultRules, 24*time.Hour, now)
+	if len(sigs) != 1 || sigs[0].Rule != "recurring" {
+		t.Fatalf("Group = %+v, want one recurring signature", sigs)
+	}
+	if want := "This failure has occurred in 3 tasks in the last day, likely environmental."; sigs[0].Hint != want {
+		t.Errorf("hint = %q, want %q", sigs[0].Hint, want)
+	}
+
+	// Recurring timeouts are a configuration matter, not the environment.
+	for i := range failures {
+		failures[i].Category = store.FailureCategoryTimeout
+	}
+	if sigs := Group(failures, DefaultRules, 24*time.Hour, now); sigs[0].Hint != "" {
+		t.Errorf("timeouts got hint %q", sigs[0].Hint)
+	}
+}
+
+func TestGroup_SingleTaskKeywordRule(t *testing.T) {
+	now := time.Now()
+	sigs := Group([]Failure{{TaskID: uuid.New(), Message: "write /work/out: no space left on device", At: now}}, DefaultRules, time.Hour, now)
+	if len(sigs) != 1 || sigs[0].Hint != "Likely environmental: the host's disk is full or read-only." {
+		t.Fatalf("Group = %+v", sigs)
+	}
+}
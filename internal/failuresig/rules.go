@@ -0,0 +1,97 @@
+package failuresig
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// LikelyEnvironmental is Signature.Likely for a signature a rule matched.
+const LikelyEnvironmental = "environmental"
+
+// Rule marks the signatures it matches as likely environmental. All of its
+// set conditions must hold; the first matching rule in a list wins.
+type Rule struct {
+	Name string
+	// Match, when set, must match the normalized signature.
+	Match *regexp.Regexp
+	// MinTasks is how many distinct tasks the signature must span within
+	// the window; 0 means one.
+	MinTasks int
+	// ExcludeCategories lists failure categories the rule never matches,
+	// such as budget_exceeded, which recurs because of configuration.
+	ExcludeCategories []store.FailureCategory
+	// Reason names the likely cause in the hint. Empty for a rule that
+	// judges by recurrence alone.
+	Reason string
+}
+
+// DefaultRules recognise common host and network trouble by its message,
+// and otherwise call a failure environmental once it recurs in three or
+// more tasks.
+var DefaultRules = []Rule{
+	{
+		Name:   "network",
+		Match:  regexp.MustCompile(`could not resolve host|no such host|temporary failure in name resolution|connection (refused|reset|timed out)|network is unreachable|i/o timeout|tls handshake timeout|eof while reading|proxy error`),
+		Reason: "network errors",
+	},
+	{
+		Name:   "disk",
+		Match:  regexp.MustCompile(`no space left on device|disk quota exceeded|read-only file system`),
+		Reason: "the host's disk is full or read-only",
+	},
+	{
+		Name:   "rate_limit",
+		Match:  regexp.MustCompile(`rate.?limit|too many requests|overloaded|quota exceeded`),
+		Reason: "the model API is rate limiting or overloaded",
+	},
+	{
+		Name:   "credentials",
+		Match:  regexp.MustCompile(`invalid api key|unauthorized|authentication failed|token (has )?expired|permission denied \(publickey\)|could not read username`),
+		Reason: "missing or expired credentials",
+	},
+	{
+		Name:   "memory",
+		Match:  regexp.MustCompile(`out of memory|signal: killed|cannot allocate memory|\boom\b|oom-kill`),
+		Reason: "the process ran out of memory",
+	},
+	{
+		Name:              "recurring",
+		MinTasks:          3,
+		ExcludeCategories: []store.FailureCategory{store.FailureCategoryBudget, store.FailureCategoryTimeout},
+	},
+}
+
+// matches reports whether r applies to sig.
+func (r Rule) matches(sig *Signature) bool {
+	if r.Match != nil && !r.Match.MatchString(sig.Signature) {
+		return false
+	}
+	if sig.TaskCount < max(1, r.MinTasks) {
+		return false
+	}
+	return !slices.Contains(r.ExcludeCategories, sig.Category)
+}
+
+// applyRules sets sig's rule, verdict, and hint from the first rule that
+// matches it.
+func applyRules(sig *Signature, rules []Rule, window time.Duration) {
+	for _, r := range rules {
+		if !r.matches(sig) {
+			continue
+		}
+		sig.Rule, sig.Likely = r.Name, LikelyEnvironmental
+		switch {
+		case sig.TaskCount > 1 && r.Reason != "":
+			sig.Hint = fmt.Sprintf("This failure has occurred in %d tasks %s, likely environmental: %s.", sig.TaskCount, windowPhrase(window), r.Reason)
+		case sig.TaskCount > 1:
+			sig.Hint = fmt.Sprintf("This failure has occurred in %d tasks %s, likely environmental.", sig.TaskCount, windowPhrase(window))
+		default:
+			sig.Hint = fmt.Sprintf("Likely environmental: %s.", r.Reason)
+		}
+		return
+	}
+}
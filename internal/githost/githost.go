@@ -0,0 +1,125 @@
+// Package githost opens pull requests on the git hosting service behind a
+// repository's origin remote. The commit pipeline uses it for tasks in
+// pull-request merge mode: instead of fast-forwarding the default branch,
+// the runner pushes the task branch and asks the [Provider] for the
+// remote's host to open a pull request from it.
+//
+// GitHub (github.com) and GitLab (gitlab.com and self-hosted instances
+// whose host name contains "gitlab") are supported, each authenticated by
+// a token from the env file rather than the brokered GitHub App
+// credential in [latere.ai/x/wallfacer/internal/github], so the pipeline
+// works without a signed-in user.
+package githost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"latere.ai/x/pkg/otel"
+)
+
+// ErrNoToken is returned by [ForRepo] when the repository's service has
+// no token configured.
+var ErrNoToken = errors.New("githost: no token configured")
+
+// Repo identifies a repository on a hosting service.
+type Repo struct {
+	// Host is the service's host name, with a port only when the remote
+	// is an http(s) URL that names one.
+	Host string
+	// Scheme is "http" for a plain-http remote and "https" otherwise,
+	// which is how a self-hosted instance's API is reached.
+	Scheme string
+	// Path is the repository path on the host: "owner/name" on GitHub,
+	// possibly "group/subgroup/name" on GitLab.
+	Path string
+}
+
+func (r Repo) String() string { return r.Host + "/" + r.Path }
+
+// ParseRemote parses a git remote URL (https, ssh, or scp-like
+// git@host:path) into a [Repo]. ok is false for a local path or a URL
+// without a repository path.
+func ParseRemote(remoteURL string) (repo Repo, ok bool) {
+	s := strings.TrimSpace(remoteURL)
+	repo.Scheme = "https"
+	var hostPort, path string
+	if scheme, rest, hasScheme := strings.Cut(s, "://"); hasScheme {
+		if at := strings.LastIndex(rest, "@"); at >= 0 {
+			rest = rest[at+1:]
+		}
+		if hostPort, path, ok = strings.Cut(rest, "/"); !ok {
+			return Repo{}, false
+		}
+		// An ssh port says nothing about where the API is served.
+		if scheme != "http" && scheme != "https" {
+			hostPort, _, _ = strings.Cut(hostPort, ":")
+		}
+		if scheme == "http" {
+			repo.Scheme = "http"
+		}
+	} else {
+		if at := strings.LastIndex(s, "@"); at >= 0 {
+			s = s[at+1:]
+		}
+		if hostPort, path, ok = strings.Cut(s, ":"); !ok || strings.Contains(hostPort, "/") {
+			return Repo{}, false
+		}
+	}
+	repo.Host = strings.ToLower(hostPort)
+	repo.Path = strings.Trim(strings.TrimSuffix(strings.Trim(path, "/"), ".git"), "/")
+	if repo.Host == "" || !strings.Contains(repo.Path, "/") {
+		return Repo{}, false
+	}
+	return repo, true
+}
+
+// PullRequest is the input for opening a pull request.
+type PullRequest struct {
+	Title string
+	Body  string
+	Head  string // source branch, already pushed
+	Base  string // target branch
+}
+
+// Provider opens pull requests on one hosting service.
+type Provider interface {
+	// Name is the service's display name, such as "GitHub".
+	Name() string
+	// OpenPullRequest opens a pull request on repo and returns its web
+	// URL. When one is already open for the same head branch, it returns
+	// that one's URL instead.
+	OpenPullRequest(ctx context.Context, repo Repo, pr PullRequest) (string, error)
+}
+
+// Tokens holds the API token for each supported service.
+type Tokens struct {
+	GitHub string // WALLFACER_GITHUB_TOKEN
+	GitLab string // WALLFACER_GITLAB_TOKEN
+}
+
+// ForRepo returns the provider for repo's host, authenticated with its
+// token from tokens.
+func ForRepo(repo Repo, tokens Tokens) (Provider, error) {
+	switch {
+	case repo.Host == "github.com":
+		if tokens.GitHub == "" {
+			return nil, fmt.Errorf("%w for GitHub (set WALLFACER_GITHUB_TOKEN)", ErrNoToken)
+		}
+		return &GitHub{Token: tokens.GitHub}, nil
+	case strings.Contains(repo.Host, "gitlab"):
+		if tokens.GitLab == "" {
+			return nil, fmt.Errorf("%w for GitLab (set WALLFACER_GITLAB_TOKEN)", ErrNoToken)
+		}
+		return &GitLab{Token: tokens.GitLab}, nil
+	}
+	return nil, fmt.Errorf("githost: no pull request support for %s", repo.Host)
+}
+
+func defaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second, Transport: otel.Transport(nil)}
+}
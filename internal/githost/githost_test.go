@@ -0,0 +1,119 @@
+package githost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	cases := []struct {
+		remote string
+		want   Repo
+		ok     bool
+	}{
+		{"https://github.com/o/r.git", Repo{Host: "github.com", Scheme: "https", Path: "o/r"}, true},
+		{"git@github.com:o/r.git", Repo{Host: "github.com", Scheme: "https", Path: "o/r"}, true},
+		{"ssh://git@GitLab.example.com:2222/group/sub/r.git", Repo{Host: "gitlab.example.com", Scheme: "https", Path: "group/sub/r"}, true},
+		{"https://user:pw@gitlab.example.com:8443/g/r/", Repo{Host: "gitlab.example.com:8443", Scheme: "https", Path: "g/r"}, true},
+		{"http://gitlab.local/g/r", Repo{Host: "gitlab.local", Scheme: "http", Path: "g/r"}, true},
+		{"/srv/git/r.git", Repo{}, false},
+		{"https://github.com/r", Repo{}, false},
+		{"", Repo{}, false},
+	}
+	for _, tc := range cases {
+		got, ok := ParseRemote(tc.remote)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("ParseRemote(%q) = %+v, %v; want %+v, %v", tc.remote, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestForRepo(t *testing.T) {
+	tokens := Tokens{GitHub: "gh", GitLab: "gl"}
+	if p, err := ForRepo(Repo{Host: "github.com", Path: "o/r"}, tokens); err != nil || p.Name() != "GitHub" {
+		t.Errorf("github.com: %v, %v", p, err)
+	}
+	if p, err := ForRepo(Repo{Host: "gitlab.example.com", Path: "g/r"}, tokens); err != nil || p.Name() != "GitLab" {
+		t.Errorf("self-hosted GitLab: %v, %v", p, err)
+	}
+	if _, err := ForRepo(Repo{Host: "github.com", Path: "o/r"}, Tokens{}); !errors.Is(err, ErrNoToken) {
+		t.Errorf("missing token err = %v", err)
+	}
+	if _, err := ForRepo(Repo{Host: "bitbucket.org", Path: "o/r"}, tokens); err == nil {
+		t.Error("unsupported host accepted")
+	}
+}
+
+func TestGitHub_OpenPullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/r/pulls" || r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["head"] != "task/abc" || body["base"] != "main" {
+			t.Errorf("body = %v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number":3,"html_url":"https://github.com/o/r/pull/3"}`))
+	}))
+	defer srv.Close()
+
+	g := &GitHub{Token: "tok", BaseURL: srv.URL, HTTP: srv.Client()}
+	got, err := g.OpenPullRequest(context.Background(), Repo{Host: "github.com", Path: "o/r"}, PullRequest{Title: "T", Head: "task/abc", Base: "main"})
+	if err != nil || got != "https://github.com/o/r/pull/3" {
+		t.Errorf("OpenPullRequest = %q, %v", got, err)
+	}
+}
+
+func TestGitLab_OpenPullRequest(t *testing.T) {
+	var exists bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsub%2Fr/merge_requests" || r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.EscapedPath())
+		}
+		switch {
+		case r.Method == http.MethodGet:
+			if r.URL.Query().Get("source_branch") != "task/abc" {
+				t.Errorf("lookup query = %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"web_url":"https://gitlab.example.com/group/sub/r/-/merge_requests/9"}]`))
+		case exists:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":["Another open merge request already exists for this source branch: !9"]}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"web_url":"https://gitlab.example.com/group/sub/r/-/merge_requests/8"}`))
+		}
+	}))
+	defer srv.Close()
+
+	g := &GitLab{Token: "tok", BaseURL: srv.URL, HTTP: srv.Client()}
+	repo := Repo{Host: "gitlab.example.com", Scheme: "https", Path: "group/sub/r"}
+	pr := PullRequest{Title: "T", Head: "task/abc", Base: "main"}
+	if got, err := g.OpenPullRequest(context.Background(), repo, pr); err != nil || got != "https://gitlab.example.com/group/sub/r/-/merge_requests/8" {
+		t.Errorf("created = %q, %v", got, err)
+	}
+	exists = true
+	if got, err := g.OpenPullRequest(context.Background(), repo, pr); err != nil || got != "https://gitlab.example.com/group/sub/r/-/merge_requests/9" {
+		t.Errorf("existing = %q, %v", got, err)
+	}
+}
+
+func TestGitLab_OpenPullRequestError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"401 Unauthorized"}`))
+	}))
+	defer srv.Close()
+
+	g := &GitLab{Token: "bad", BaseURL: srv.URL, HTTP: srv.Client()}
+	_, err := g.OpenPullRequest(context.Background(), Repo{Host: "gitlab.com", Path: "g/r"}, PullRequest{Head: "h", Base: "main"})
+	if err == nil || err.Error() != "githost: create merge request: api status 401: 401 Unauthorized" {
+		t.Errorf("err = %v", err)
+	}
+}
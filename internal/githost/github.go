@@ -0,0 +1,42 @@
+package githost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/github"
+)
+
+// GitHub opens pull requests through the GitHub REST API with a personal
+// access or fine-grained token.
+type GitHub struct {
+	Token string
+	// BaseURL is the API root; empty means [github.DefaultBaseURL].
+	BaseURL string
+	// HTTP is the client used; nil means a 30s-timeout default.
+	HTTP *http.Client
+}
+
+// Name implements [Provider].
+func (g *GitHub) Name() string { return "GitHub" }
+
+// OpenPullRequest implements [Provider].
+func (g *GitHub) OpenPullRequest(ctx context.Context, repo Repo, pr PullRequest) (string, error) {
+	owner, name, ok := strings.Cut(repo.Path, "/")
+	if !ok || strings.Contains(name, "/") {
+		return "", fmt.Errorf("githost: %s is not an owner/name GitHub repository", repo)
+	}
+	client := &github.Client{BaseURL: g.BaseURL, HTTP: g.HTTP}
+	if client.HTTP == nil {
+		client.HTTP = defaultHTTPClient()
+	}
+	created, err := github.CreatePull(ctx, client, &github.Token{AccessToken: g.Token}, owner, name, github.CreatePullParams{
+		Title: pr.Title, Body: pr.Body, Head: pr.Head, Base: pr.Base,
+	})
+	if err != nil {
+		return "", err
+	}
+	return created.HTMLURL, nil
+}
@@ -0,0 +1,122 @@
+package githost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/sanitize"
+)
+
+// GitLab opens merge requests through the GitLab REST API (v4) with a
+// personal, project, or group access token.
+type GitLab struct {
+	Token string
+	// BaseURL is the instance root, such as https://gitlab.example.com;
+	// empty means the repository's own scheme and host.
+	BaseURL string
+	// HTTP is the client used; nil means a 30s-timeout default.
+	HTTP *http.Client
+}
+
+// Name implements [Provider].
+func (g *GitLab) Name() string { return "GitLab" }
+
+// OpenPullRequest implements [Provider]. GitLab answers 409 Conflict when
+// a merge request from the same source branch is already open; the open
+// one is then looked up and returned.
+func (g *GitLab) OpenPullRequest(ctx context.Context, repo Repo, pr PullRequest) (string, error) {
+	project := g.apiRoot(repo) + "/projects/" + url.PathEscape(repo.Path) + "/merge_requests"
+	body, err := json.Marshal(map[string]any{
+		"source_branch":        pr.Head,
+		"target_branch":        pr.Base,
+		"title":                pr.Title,
+		"description":          pr.Body,
+		"remove_source_branch": true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("githost: encode merge request: %w", err)
+	}
+	var created struct {
+		WebURL string `json:"web_url"`
+	}
+	status, err := g.do(ctx, http.MethodPost, project, body, &created)
+	if status == http.StatusConflict {
+		var open []struct {
+			WebURL string `json:"web_url"`
+		}
+		query := url.Values{"state": {"opened"}, "source_branch": {pr.Head}, "target_branch": {pr.Base}}
+		if _, lookupErr := g.do(ctx, http.MethodGet, project+"?"+query.Encode(), nil, &open); lookupErr == nil && len(open) > 0 {
+			return open[0].WebURL, nil
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("githost: create merge request: %w", err)
+	}
+	return created.WebURL, nil
+}
+
+func (g *GitLab) apiRoot(repo Repo) string {
+	base := g.BaseURL
+	if base == "" {
+		base = repo.Scheme + "://" + repo.Host
+	}
+	return strings.TrimRight(base, "/") + "/api/v4"
+}
+
+// do sends an authenticated request and decodes a 2xx JSON response into
+// out. It returns the response status, zero when no response arrived.
+func (g *GitLab) do(ctx context.Context, method, rawURL string, body []byte, out any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", g.Token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := g.HTTP
+	if client == nil {
+		client = defaultHTTPClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("api status %d: %s", resp.StatusCode, gitlabMessage(data))
+	}
+	return resp.StatusCode, json.Unmarshal(data, out)
+}
+
+// gitlabMessage extracts GitLab's {"message": ...} or {"error": ...} text,
+// whose message may be a string, a list, or a map of field errors.
+func gitlabMessage(data []byte) string {
+	var payload struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) == nil {
+		if payload.Message != nil {
+			return fmt.Sprint(payload.Message)
+		}
+		if payload.Error != "" {
+			return payload.Error
+		}
+	}
+	if s := sanitize.Truncate(strings.TrimSpace(string(data)), 200); s != "" {
+		return s
+	}
+	return "(no body)"
+}
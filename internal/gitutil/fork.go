@@ -0,0 +1,70 @@
+package gitutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/pkg/dircp"
+)
+
+// ForkWorktree creates a new worktree of repoPath at dstPath on branchName
+// that reproduces the current state of the worktree at srcPath: its HEAD
+// commit, its uncommitted changes to tracked files, and its untracked files
+// (ignored files are left behind). srcPath is not modified. The uncommitted
+// changes are captured with `git stash create`, which writes a stash commit
+// without touching the source's index, working tree, or stash list.
+func ForkWorktree(repoPath, srcPath, dstPath, branchName string) error {
+	head, err := ResolveHead(srcPath)
+	if err != nil {
+		return err
+	}
+	stash, err := cmdexec.Git(srcPath, "stash", "create").Output()
+	if err != nil {
+		return fmt.Errorf("git stash create in %s: %w", srcPath, err)
+	}
+	untracked, err := cmdexec.Git(srcPath, "ls-files", "--others", "--exclude-standard", "-z").OutputBytes()
+	if err != nil {
+		return fmt.Errorf("git ls-files in %s: %w", srcPath, err)
+	}
+
+	if err := CreateWorktreeAt(repoPath, dstPath, branchName, head); err != nil {
+		return err
+	}
+	if stash != "" {
+		if out, err := cmdexec.Git(dstPath, "stash", "apply", stash).Combined(); err != nil {
+			return fmt.Errorf("git stash apply in %s: %w\n%s", dstPath, err, out)
+		}
+	}
+	for rel := range strings.SplitSeq(string(untracked), "\x00") {
+		if rel == "" {
+			continue
+		}
+		if err := copyUntracked(filepath.Join(srcPath, rel), filepath.Join(dstPath, rel)); err != nil {
+			return fmt.Errorf("copy untracked %s: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+// copyUntracked copies one untracked file or symlink, creating its parent
+// directories.
+func copyUntracked(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, dst)
+	}
+	return dircp.CopyFile(src, dst, info.Mode().Perm())
+}
@@ -0,0 +1,51 @@
+package gitutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestForkWorktree verifies that a fork reproduces the source worktree's
+// commits, uncommitted edits, and untracked files on its own branch while
+// leaving the source untouched.
+func TestForkWorktree(t *testing.T) {
+	repo := setupRepo(t)
+	src := filepath.Join(t.TempDir(), "src")
+	if err := CreateWorktree(repo, src, "task/src"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(src, "committed.txt"), "committed\n")
+	gitRun(t, src, "add", ".")
+	gitRun(t, src, "commit", "-m", "work")
+	writeFile(t, filepath.Join(src, "file.txt"), "edited\n")
+	if err := os.MkdirAll(filepath.Join(src, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(src, "dir", "new.txt"), "untracked\n")
+	srcStatus := gitRun(t, src, "status", "--porcelain")
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	if err := ForkWorktree(repo, src, dst, "task/dst"); err != nil {
+		t.Fatalf("ForkWorktree: %v", err)
+	}
+	for name, want := range map[string]string{
+		"committed.txt": "committed\n",
+		"file.txt":      "edited\n",
+		"dir/new.txt":   "untracked\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil || string(got) != want {
+			t.Errorf("fork %s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if got := gitRun(t, dst, "rev-parse", "--abbrev-ref", "HEAD"); got != "task/dst" {
+		t.Errorf("fork branch = %q, want task/dst", got)
+	}
+	if got := gitRun(t, src, "status", "--porcelain"); got != srcStatus {
+		t.Errorf("source status changed:\n%s\nwant:\n%s", got, srcStatus)
+	}
+	if got := gitRun(t, repo, "stash", "list"); got != "" {
+		t.Errorf("stash list = %q, want empty", got)
+	}
+}
@@ -33,13 +33,21 @@ func GlobalIdentityOverrides(ctx context.Context) []string {
 
 // RebaseOntoDefault rebases the task branch (currently checked out in worktreePath)
 // onto the default branch of repoPath. On conflict it aborts the rebase and returns
-// ErrConflict so the caller can invoke conflict resolution and retry.
+// ErrMergeConflict so the caller can invoke conflict resolution and retry.
 func RebaseOntoDefault(repoPath, worktreePath string) error {
 	defBranch, err := DefaultBranch(repoPath)
 	if err != nil {
 		return err
 	}
+	return RebaseOnto(worktreePath, defBranch)
+}
 
+// RebaseOnto rebases the branch checked out in worktreePath onto target, which
+// may be any commit-ish (branch name or hash). It shares RebaseOntoDefault's
+// contract: stale rebase state is cleared first, and a conflicting rebase is
+// aborted and reported as a *ConflictError. A rebase blocked by another git
+// process's index lock returns an error wrapping ErrWorktreeBusy.
+func RebaseOnto(worktreePath, target string) error {
 	if conflictErr := recoverRebaseState(worktreePath); conflictErr != nil {
 		return conflictErr
 	}
@@ -48,7 +56,7 @@ func RebaseOntoDefault(repoPath, worktreePath string) error {
 	// automatically abort it so the worktree is left in a clean state.
 	tx := cmdexec.NewTx()
 	tx.AddWithRollback(
-		cmdexec.Git(worktreePath, "rebase", defBranch),
+		cmdexec.Git(worktreePath, "rebase", target),
 		cmdexec.Git(worktreePath, "rebase", "--abort"),
 	)
 	if txErr := tx.Run(); txErr != nil {
@@ -60,6 +68,9 @@ func RebaseOntoDefault(repoPath, worktreePath string) error {
 		if len(te.RollbackErrors) > 0 {
 			slog.Default().With("component", "git").Debug("rebase abort after failure", "path", worktreePath, "error", te.RollbackErrors)
 		}
+		if IsIndexLockOutput(out) {
+			return fmt.Errorf("git rebase in %s: %w\n%s", worktreePath, ErrWorktreeBusy, out)
+		}
 		if IsConflictOutput(out) || IsRebaseNeedsMergeOutput(out) {
 			return &ConflictError{
 				WorktreePath:    worktreePath,
@@ -200,6 +211,9 @@ func FFMerge(repoPath, branchName string) error {
 			return nil
 		}
 		out := te.Step.Output
+		if IsIndexLockOutput(out) {
+			return fmt.Errorf("git merge --ff-only %s in %s: %w\n%s", branchName, repoPath, ErrWorktreeBusy, out)
+		}
 		if te.Step.Index == 0 {
 			return fmt.Errorf("git checkout %s in %s: %w\n%s", defBranch, repoPath, te.Step.Err, out)
 		}
@@ -230,6 +244,81 @@ func CommitsBehind(repoPath, worktreePath string) (int, error) {
 	return n, nil
 }
 
+// CommitsAhead returns the number of commits on the worktree's HEAD that
+// the default branch does not have (i.e. the task branch's own commits not
+// yet merged).
+func CommitsAhead(repoPath, worktreePath string) (int, error) {
+	defBranch, err := DefaultBranch(repoPath)
+	if err != nil {
+		return 0, err
+	}
+	defHash, err := defaultBranchCommitHash(repoPath, defBranch)
+	if err != nil {
+		// Without a default branch every commit is unmerged.
+		out, err := cmdexec.Git(worktreePath, "rev-list", "--count", "HEAD").Output()
+		if err != nil {
+			return 0, fmt.Errorf("git rev-list in %s: %w", worktreePath, err)
+		}
+		n, _ := strconv.Atoi(out)
+		return n, nil
+	}
+	out, err := cmdexec.Git(worktreePath, "rev-list", "--count", defHash+"..HEAD").Output()
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list in %s: %w", worktreePath, err)
+	}
+	n, _ := strconv.Atoi(out)
+	return n, nil
+}
+
+// RebaseCheckpoints returns the upstream commits a step-wise rebase of the
+// worktree should visit, oldest first, ending with the current default branch
+// tip. The candidates are the first-parent commits in HEAD..<default>, so
+// merges landed on the default branch count as one step. When more than
+// maxSteps candidates exist, evenly spaced commits are kept so each step
+// covers a similar slice of upstream history. Returns nil when the worktree
+// is not behind, and a single-element slice when maxSteps <= 1.
+func RebaseCheckpoints(repoPath, worktreePath string, maxSteps int) ([]string, error) {
+	defBranch, err := DefaultBranch(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	defHash, err := defaultBranchCommitHash(repoPath, defBranch)
+	if err != nil {
+		return nil, nil
+	}
+	out, err := cmdexec.Git(worktreePath, "rev-list", "--reverse", "--first-parent", "HEAD.."+defHash).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list in %s: %w", worktreePath, err)
+	}
+	var commits []string
+	for line := range strings.SplitSeq(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return sampleCheckpoints(commits, maxSteps), nil
+}
+
+// sampleCheckpoints keeps at most maxSteps entries of commits, always
+// including the last one. Entry i of the result is the commit that ends the
+// i-th of maxSteps equally sized slices of the input.
+func sampleCheckpoints(commits []string, maxSteps int) []string {
+	if len(commits) == 0 {
+		return nil
+	}
+	if maxSteps < 1 {
+		maxSteps = 1
+	}
+	if len(commits) <= maxSteps {
+		return commits
+	}
+	out := make([]string, 0, maxSteps)
+	for i := 1; i <= maxSteps; i++ {
+		out = append(out, commits[i*len(commits)/maxSteps-1])
+	}
+	return out
+}
+
 // defaultBranchCommitHash resolves the commit hash of the default branch,
 // trying multiple ref forms in order: bare name, refs/heads/, origin/ remote,
 // and refs/remotes/origin/. This handles detached-HEAD repos where the local
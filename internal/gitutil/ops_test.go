@@ -2,9 +2,11 @@ package gitutil
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 )
@@ -185,6 +187,32 @@ func TestCommitsBehind(t *testing.T) {
 	})
 }
 
+func TestCommitsAhead(t *testing.T) {
+	repo := setupRepo(t)
+	wtDir := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-b", "task", wtDir, "HEAD")
+	t.Cleanup(func() { _ = RemoveWorktree(repo, wtDir, "task") })
+
+	if n, err := CommitsAhead(repo, wtDir); err != nil || n != 0 {
+		t.Errorf("fresh worktree: CommitsAhead = %d, %v; want 0, nil", n, err)
+	}
+	for _, f := range []string{"t1.txt", "t2.txt"} {
+		writeFile(t, filepath.Join(wtDir, f), f+"\n")
+		gitRun(t, wtDir, "add", ".")
+		gitRun(t, wtDir, "commit", "-m", f)
+	}
+	// Commits landing on main do not change the task branch's own count.
+	writeFile(t, filepath.Join(repo, "m1.txt"), "main\n")
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-m", "main advance")
+	if n, err := CommitsAhead(repo, wtDir); err != nil || n != 2 {
+		t.Errorf("CommitsAhead = %d, %v; want 2, nil", n, err)
+	}
+	if _, err := CommitsAhead(repo, t.TempDir()); err == nil {
+		t.Error("non-git worktree: expected error, got nil")
+	}
+}
+
 // TestHasCommitsAheadOf validates ahead-of detection for same commit, diverged
 // branches, and non-git paths.
 func TestHasCommitsAheadOf(t *testing.T) {
@@ -241,7 +269,7 @@ func TestRebaseOntoDefault(t *testing.T) {
 		}
 	})
 
-	t.Run("conflicting changes return ErrConflict", func(t *testing.T) {
+	t.Run("conflicting changes return ErrMergeConflict", func(t *testing.T) {
 		repo := setupRepo(t)
 		wtDir := filepath.Join(t.TempDir(), "wt")
 		gitRun(t, repo, "worktree", "add", "-b", "task", wtDir, "HEAD")
@@ -256,8 +284,8 @@ func TestRebaseOntoDefault(t *testing.T) {
 		gitRun(t, wtDir, "commit", "-m", "task: change file.txt")
 
 		err := RebaseOntoDefault(repo, wtDir)
-		if !errors.Is(err, ErrConflict) {
-			t.Errorf("expected ErrConflict, got %v", err)
+		if !errors.Is(err, ErrMergeConflict) {
+			t.Errorf("expected ErrMergeConflict, got %v", err)
 		}
 		var conflictErr *ConflictError
 		if !errors.As(err, &conflictErr) {
@@ -267,6 +295,103 @@ func TestRebaseOntoDefault(t *testing.T) {
 			t.Error("expected at least one conflicted file, got none")
 		}
 	})
+
+	t.Run("held index lock returns ErrWorktreeBusy", func(t *testing.T) {
+		repo := setupRepo(t)
+		wtDir := filepath.Join(t.TempDir(), "wt")
+		gitRun(t, repo, "worktree", "add", "-b", "task", wtDir, "HEAD")
+		t.Cleanup(func() { _ = RemoveWorktree(repo, wtDir, "task") })
+
+		writeFile(t, filepath.Join(repo, "main-only.txt"), "main\n")
+		gitRun(t, repo, "add", ".")
+		gitRun(t, repo, "commit", "-m", "main change")
+		writeFile(t, filepath.Join(wtDir, "task-only.txt"), "task\n")
+		gitRun(t, wtDir, "add", ".")
+		gitRun(t, wtDir, "commit", "-m", "task change")
+
+		lock := filepath.Join(gitRun(t, wtDir, "rev-parse", "--absolute-git-dir"), "index.lock")
+		writeFile(t, lock, "")
+		defer os.Remove(lock)
+
+		err := RebaseOntoDefault(repo, wtDir)
+		if !errors.Is(err, ErrWorktreeBusy) {
+			t.Fatalf("expected ErrWorktreeBusy, got %v", err)
+		}
+		if errors.Is(err, ErrMergeConflict) {
+			t.Error("a held lock must not be reported as a conflict")
+		}
+	})
+}
+
+// TestRebaseCheckpoints validates that checkpoints walk upstream history
+// oldest-first, end at the default branch tip, and are capped at maxSteps.
+func TestRebaseCheckpoints(t *testing.T) {
+	repo := setupRepo(t)
+	wtDir := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-b", "task", wtDir, "HEAD")
+	t.Cleanup(func() { _ = RemoveWorktree(repo, wtDir, "task") })
+
+	if got, err := RebaseCheckpoints(repo, wtDir, 4); err != nil || got != nil {
+		t.Fatalf("up-to-date worktree: got %v, %v; want nil, nil", got, err)
+	}
+
+	var hashes []string
+	for i := range 6 {
+		name := fmt.Sprintf("m%d.txt", i)
+		writeFile(t, filepath.Join(repo, name), name+"\n")
+		gitRun(t, repo, "add", ".")
+		gitRun(t, repo, "commit", "-m", name)
+		hashes = append(hashes, gitRun(t, repo, "rev-parse", "HEAD"))
+	}
+
+	all, err := RebaseCheckpoints(repo, wtDir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(all, hashes) {
+		t.Errorf("uncapped checkpoints = %v, want %v", all, hashes)
+	}
+
+	capped, err := RebaseCheckpoints(repo, wtDir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{hashes[1], hashes[3], hashes[5]}
+	if !slices.Equal(capped, want) {
+		t.Errorf("capped checkpoints = %v, want %v", capped, want)
+	}
+
+	for _, cp := range capped {
+		if err := RebaseOnto(wtDir, cp); err != nil {
+			t.Fatalf("RebaseOnto(%s): %v", cp, err)
+		}
+	}
+	if n, err := CommitsBehind(repo, wtDir); err != nil || n != 0 {
+		t.Errorf("after step-wise rebase CommitsBehind = %d, %v; want 0, nil", n, err)
+	}
+}
+
+// TestSampleCheckpoints covers the even-spacing selection edge cases.
+func TestSampleCheckpoints(t *testing.T) {
+	in := []string{"a", "b", "c", "d", "e"}
+	cases := []struct {
+		max  int
+		want []string
+	}{
+		{0, []string{"e"}},
+		{1, []string{"e"}},
+		{2, []string{"b", "e"}},
+		{5, in},
+		{9, in},
+	}
+	for _, tc := range cases {
+		if got := sampleCheckpoints(in, tc.max); !slices.Equal(got, tc.want) {
+			t.Errorf("sampleCheckpoints(max=%d) = %v, want %v", tc.max, got, tc.want)
+		}
+	}
+	if got := sampleCheckpoints(nil, 3); got != nil {
+		t.Errorf("sampleCheckpoints(nil) = %v, want nil", got)
+	}
 }
 
 // TestHasRebaseOrMergeState_ExecStop covers a rebase stopped by a failed
@@ -768,8 +893,8 @@ func TestRebaseOntoDefault_StaleRebaseState(t *testing.T) {
 
 	// First rebase will conflict.
 	err := RebaseOntoDefault(repo, wtDir)
-	if !errors.Is(err, ErrConflict) {
-		t.Fatalf("expected ErrConflict from first rebase, got %v", err)
+	if !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("expected ErrMergeConflict from first rebase, got %v", err)
 	}
 
 	// Second attempt should recover and still return conflict.
@@ -0,0 +1,149 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+)
+
+// ErrPatchConflict is returned by ApplyPatch when the patch does not apply
+// to the target branch, even with a three-way merge.
+var ErrPatchConflict = errors.New("patch does not apply")
+
+// ErrBranchDirty is returned by ApplyPatch when the target branch is checked
+// out in a worktree with uncommitted changes, which advancing it would
+// clobber.
+var ErrBranchDirty = errors.New("branch checkout has uncommitted changes")
+
+// ApplyPatch commits patch (a `git diff` of text or --binary output) onto
+// branch in repoPath, cherry-pick style, and returns the new commit hash.
+//
+// The patch is applied with a three-way fallback in a temporary detached
+// worktree at the branch tip, so no checkout is touched while it applies.
+// The branch is then advanced to the new commit: by a fast-forward merge in
+// the worktree that has it checked out, which must be clean, or by moving
+// the ref when no worktree has it checked out. extraArgs are passed to git
+// before "commit", e.g. [GlobalIdentityOverrides].
+func ApplyPatch(ctx context.Context, repoPath, branch, patch, message string, extraArgs ...string) (string, error) {
+	if strings.TrimSpace(patch) == "" {
+		return "", errors.New("empty patch")
+	}
+	tip, err := cmdexec.Git(repoPath, "rev-parse", "--verify", "refs/heads/"+branch+"^{commit}").WithContext(ctx).Output()
+	if err != nil {
+		return "", fmt.Errorf("branch %q not found in %s", branch, repoPath)
+	}
+	checkout := BranchCheckout(ctx, repoPath, branch)
+	if checkout != "" {
+		dirty, err := HasChanges(ctx, checkout)
+		if err != nil {
+			return "", fmt.Errorf("status of %s: %w", checkout, err)
+		}
+		if dirty {
+			return "", fmt.Errorf("%w: %s is checked out at %s", ErrBranchDirty, branch, checkout)
+		}
+	}
+
+	tmp, err := os.MkdirTemp("", "wallfacer-apply-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	patchFile := filepath.Join(tmp, "task.patch")
+	if !strings.HasSuffix(patch, "\n") {
+		patch += "\n"
+	}
+	if err := os.WriteFile(patchFile, []byte(patch), 0o600); err != nil {
+		return "", err
+	}
+	wt := filepath.Join(tmp, "worktree")
+	if err := CreateDetachedWorktree(repoPath, wt, tip); err != nil {
+		return "", err
+	}
+	defer func() { _ = RemoveDetachedWorktree(repoPath, wt) }()
+
+	if out, err := cmdexec.Git(wt, "apply", "--3way", "--whitespace=nowarn", patchFile).WithContext(ctx).Combined(); err != nil {
+		return "", &PatchConflictError{Branch: branch, ConflictedFiles: parsePatchConflicts(out), RawOutput: out}
+	}
+	if out, err := cmdexec.Git(wt, "add", "-A").WithContext(ctx).Combined(); err != nil {
+		return "", fmt.Errorf("git add: %w\n%s", err, out)
+	}
+	args := slices.Concat([]string{"-C", wt}, extraArgs, []string{"commit", "--no-verify", "-m", message})
+	if out, err := cmdexec.New("git", args...).WithContext(ctx).Combined(); err != nil {
+		return "", fmt.Errorf("git commit: %w\n%s", err, out)
+	}
+	commit, err := ResolveHead(wt)
+	if err != nil {
+		return "", err
+	}
+
+	if checkout != "" {
+		if out, err := cmdexec.Git(checkout, "merge", "--ff-only", commit).WithContext(ctx).Combined(); err != nil {
+			return "", fmt.Errorf("fast-forward %s: %w\n%s", branch, err, out)
+		}
+	} else if out, err := cmdexec.Git(repoPath, "update-ref", "refs/heads/"+branch, commit, tip).WithContext(ctx).Combined(); err != nil {
+		return "", fmt.Errorf("advance %s: %w\n%s", branch, err, out)
+	}
+	return commit, nil
+}
+
+// PatchConflictError is returned by ApplyPatch when the patch does not apply.
+// It wraps ErrPatchConflict and carries the files that failed.
+type PatchConflictError struct {
+	Branch          string
+	ConflictedFiles []string
+	RawOutput       string
+}
+
+func (e *PatchConflictError) Error() string {
+	if len(e.ConflictedFiles) == 0 {
+		return fmt.Sprintf("patch does not apply to %s: %s", e.Branch, e.RawOutput)
+	}
+	return fmt.Sprintf("patch does not apply to %s: conflicts in %s", e.Branch, strings.Join(e.ConflictedFiles, ", "))
+}
+
+func (e *PatchConflictError) Unwrap() error { return ErrPatchConflict }
+
+// parsePatchConflicts extracts the paths from `git apply --3way` output:
+// "U <path>" for a three-way conflict and "error: patch failed: <path>:<line>"
+// for a hunk that could not be placed.
+func parsePatchConflicts(out string) []string {
+	var files []string
+	for line := range strings.SplitSeq(out, "\n") {
+		var path string
+		if p, ok := strings.CutPrefix(line, "U "); ok {
+			path = p
+		} else if p, ok := strings.CutPrefix(line, "error: patch failed: "); ok {
+			if i := strings.LastIndexByte(p, ':'); i > 0 {
+				path = p[:i]
+			}
+		}
+		if path != "" && !slices.Contains(files, path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// BranchCheckout returns the path of the worktree of repoPath that has
+// branch checked out, or "" when none does.
+func BranchCheckout(ctx context.Context, repoPath, branch string) string {
+	out, err := cmdexec.Git(repoPath, "worktree", "list", "--porcelain").WithContext(ctx).Output()
+	if err != nil {
+		return ""
+	}
+	var path string
+	for line := range strings.SplitSeq(out, "\n") {
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			path = p
+		} else if line == "branch refs/heads/"+branch {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// taskPatch commits a change to file.txt on a side branch of repo and
+// returns its diff against main, as TaskDiff would render it.
+func taskPatch(t *testing.T, repo, content string) string {
+	t.Helper()
+	gitRun(t, repo, "checkout", "-q", "-b", "task")
+	writeFile(t, filepath.Join(repo, "file.txt"), content)
+	writeFile(t, filepath.Join(repo, "added.txt"), "new\n")
+	gitRun(t, repo, "add", "-A")
+	gitRun(t, repo, "commit", "-q", "-m", "task change")
+	patch := gitRun(t, repo, "diff", "main", "task")
+	gitRun(t, repo, "checkout", "-q", "main")
+	return patch
+}
+
+func TestApplyPatch_OtherBranch(t *testing.T) {
+	repo := setupRepo(t)
+	patch := taskPatch(t, repo, "fixed\n")
+	gitRun(t, repo, "branch", "release")
+
+	commit, err := ApplyPatch(context.Background(), repo, "release", patch, "backport fix")
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if got := gitRun(t, repo, "rev-parse", "release"); got != commit {
+		t.Errorf("release = %s, want %s", got, commit)
+	}
+	if got := gitRun(t, repo, "show", "release:file.txt"); got != "fixed" {
+		t.Errorf("release:file.txt = %q", got)
+	}
+	if got := gitRun(t, repo, "log", "-1", "--format=%s", "release"); got != "backport fix" {
+		t.Errorf("subject = %q", got)
+	}
+	// The checkout on main is untouched.
+	if got := gitRun(t, repo, "status", "--porcelain"); got != "" {
+		t.Errorf("checkout changed: %q", got)
+	}
+	if got := gitRun(t, repo, "worktree", "list"); strings.Contains(got, "\n") {
+		t.Errorf("temporary worktree left behind: %q", got)
+	}
+}
+
+func TestApplyPatch_CheckedOutBranch(t *testing.T) {
+	repo := setupRepo(t)
+	patch := taskPatch(t, repo, "fixed\n")
+	other := setupRepo(t)
+
+	commit, err := ApplyPatch(context.Background(), other, "main", patch, "apply task")
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if got := gitRun(t, other, "rev-parse", "HEAD"); got != commit {
+		t.Errorf("HEAD = %s, want %s", got, commit)
+	}
+	data, err := os.ReadFile(filepath.Join(other, "added.txt"))
+	if err != nil || string(data) != "new\n" {
+		t.Errorf("checkout not fast-forwarded: added.txt = %q, %v", data, err)
+	}
+}
+
+func TestApplyPatch_DirtyCheckout(t *testing.T) {
+	repo := setupRepo(t)
+	patch := taskPatch(t, repo, "fixed\n")
+	writeFile(t, filepath.Join(repo, "file.txt"), "local edit\n")
+	head := gitRun(t, repo, "rev-parse", "main")
+
+	_, err := ApplyPatch(context.Background(), repo, "main", patch, "apply")
+	if !errors.Is(err, ErrBranchDirty) {
+		t.Fatalf("err = %v, want ErrBranchDirty", err)
+	}
+	if got := gitRun(t, repo, "rev-parse", "main"); got != head {
+		t.Error("branch moved despite the dirty checkout")
+	}
+}
+
+func TestApplyPatch_Conflict(t *testing.T) {
+	repo := setupRepo(t)
+	patch := taskPatch(t, repo, "fixed\n")
+	gitRun(t, repo, "checkout", "-q", "-b", "release")
+	writeFile(t, filepath.Join(repo, "file.txt"), "diverged\n")
+	gitRun(t, repo, "commit", "-q", "-am", "diverge")
+	gitRun(t, repo, "checkout", "-q", "main")
+	tip := gitRun(t, repo, "rev-parse", "release")
+
+	_, err := ApplyPatch(context.Background(), repo, "release", patch, "backport")
+	var pce *PatchConflictError
+	if !errors.As(err, &pce) || !errors.Is(err, ErrPatchConflict) {
+		t.Fatalf("err = %v, want a PatchConflictError", err)
+	}
+	if !slices.Contains(pce.ConflictedFiles, "file.txt") {
+		t.Errorf("ConflictedFiles = %q, want file.txt", pce.ConflictedFiles)
+	}
+	if got := gitRun(t, repo, "rev-parse", "release"); got != tip {
+		t.Error("branch moved despite the conflict")
+	}
+}
+
+func TestApplyPatch_UnknownBranch(t *testing.T) {
+	repo := setupRepo(t)
+	if _, err := ApplyPatch(context.Background(), repo, "nope", "diff --git a/x b/x\n", "m"); err == nil {
+		t.Error("expected an error for an unknown branch")
+	}
+}
+
+func TestBranchCheckout(t *testing.T) {
+	repo := setupRepo(t)
+	gitRun(t, repo, "branch", "side")
+	wt := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-q", wt, "side")
+
+	if got := BranchCheckout(context.Background(), repo, "side"); got != wt {
+		t.Errorf("BranchCheckout(side) = %q, want %q", got, wt)
+	}
+	if got := BranchCheckout(context.Background(), repo, "none"); got != "" {
+		t.Errorf("BranchCheckout(none) = %q, want empty", got)
+	}
+}
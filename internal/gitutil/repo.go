@@ -9,11 +9,22 @@ import (
 	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
 )
 
-// ErrConflict is returned by RebaseOntoDefault when a merge conflict is detected.
-var ErrConflict = errors.New("rebase conflict")
+// ErrMergeConflict is returned by RebaseOntoDefault when a merge conflict is detected.
+var ErrMergeConflict = errors.New("rebase conflict")
+
+// ErrWorktreeBusy is returned when git refuses to touch a worktree because
+// another git process holds its index lock. The operation can be retried
+// once that process finishes.
+var ErrWorktreeBusy = errors.New("worktree busy")
+
+// IsIndexLockOutput reports whether git output says the index lock is held
+// by another process ("Unable to create '.../index.lock': File exists").
+func IsIndexLockOutput(s string) bool {
+	return strings.Contains(s, "index.lock': File exists")
+}
 
 // ConflictError is returned by RebaseOntoDefault when a merge conflict is detected.
-// It wraps ErrConflict and carries the list of conflicted file paths.
+// It wraps ErrMergeConflict and carries the list of conflicted file paths.
 type ConflictError struct {
 	WorktreePath    string
 	ConflictedFiles []string
@@ -24,7 +35,7 @@ func (e *ConflictError) Error() string {
 	return fmt.Sprintf("rebase conflict in %s: %d file(s) conflicted", e.WorktreePath, len(e.ConflictedFiles))
 }
 
-func (e *ConflictError) Unwrap() error { return ErrConflict }
+func (e *ConflictError) Unwrap() error { return ErrMergeConflict }
 
 // conflictFileRe matches git's "CONFLICT (...): Merge conflict in <path>" or
 // "CONFLICT (...): content conflict in <path>" lines to extract file paths.
@@ -164,6 +175,32 @@ func InitLocalRepo(path, email, name, initialCommitMsg string) error {
 	return nil
 }
 
+// InitRepo initialises a git repository at path with branch checked out,
+// stages all files, and creates an initial commit under the user's git
+// identity. When no identity is configured the commit falls back to the one
+// InitLocalRepo uses, so a machine without git setup can still create it.
+// Used to put a freshly scaffolded project under version control.
+func InitRepo(path, branch, initialCommitMsg string) error {
+	if out, err := cmdexec.Git(path, "init", "-b", branch).Combined(); err != nil {
+		return fmt.Errorf("git init %s: %w\n%s", path, err, out)
+	}
+	if err := cmdexec.Git(path, "add", "-A").Run(); err != nil {
+		return fmt.Errorf("git add in %s: %w", path, err)
+	}
+	var identity []string
+	if email, err := cmdexec.Git(path, "config", "user.email").Output(); err != nil || email == "" {
+		identity = append(identity, "-c", "user.email=wallfacer@local")
+	}
+	if name, err := cmdexec.Git(path, "config", "user.name").Output(); err != nil || name == "" {
+		identity = append(identity, "-c", "user.name=Wallfacer")
+	}
+	args := append(identity, "commit", "--allow-empty", "-m", initialCommitMsg)
+	if out, err := cmdexec.Git(path, args...).Combined(); err != nil {
+		return fmt.Errorf("git commit in %s: %w\n%s", path, err, out)
+	}
+	return nil
+}
+
 // GetCommitHash returns the current HEAD commit hash in repoPath.
 func GetCommitHash(repoPath string) (string, error) {
 	out, err := cmdexec.Git(repoPath, "rev-parse", "HEAD").Output()
@@ -1,7 +1,9 @@
 package gitutil
 
 import (
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -297,3 +299,25 @@ func contains(s, sub string) bool {
 			return false
 		}())
 }
+
+func TestInitRepo(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n")
+
+	if err := InitRepo(dir, "main", "Scaffold project"); err != nil {
+		t.Fatalf("InitRepo: %v", err)
+	}
+	if got := gitRun(t, dir, "rev-parse", "--abbrev-ref", "HEAD"); got != "main" {
+		t.Errorf("branch = %q, want main", got)
+	}
+	if got := gitRun(t, dir, "log", "-1", "--format=%s"); got != "Scaffold project" {
+		t.Errorf("commit subject = %q", got)
+	}
+	if got := gitRun(t, dir, "ls-files"); got != "main.go" {
+		t.Errorf("ls-files = %q, want main.go", got)
+	}
+	// Unlike InitLocalRepo, no repo-local identity is written.
+	if out, err := exec.Command("git", "-C", dir, "config", "--local", "user.email").Output(); err == nil {
+		t.Errorf("local user.email set to %q", strings.TrimSpace(string(out)))
+	}
+}
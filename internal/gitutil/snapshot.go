@@ -2,27 +2,26 @@ package gitutil
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
 )
 
 // SnapshotDiff computes a unified diff of all changes in a snapshot repository
-// relative to its initial commit (HEAD~1). If HEAD~1 does not exist (only the
-// initial snapshot commit has been made), it falls back to `git diff HEAD`,
-// capturing the uncommitted changes only. Untracked files are appended as
-// new-file diffs so the output reflects the full set of agent-made changes.
+// relative to its initial commit: agent commits on top of it and uncommitted
+// edits alike. Untracked files are appended as new-file diffs so the output
+// reflects the full set of agent-made changes. An empty string means nothing
+// changed, or the root commit could not be resolved.
 //
 // Intended for repos created by [InitLocalRepo], where the initial commit
 // captures the original workspace and any later commits capture agent changes.
 func SnapshotDiff(ctx context.Context, snapshotPath string) string {
-	out, err := cmdexec.Git(snapshotPath, "diff", "HEAD~1").WithContext(ctx).Output()
+	root, err := SnapshotRoot(ctx, snapshotPath)
 	if err != nil {
-		// HEAD~1 doesn't exist (only the initial snapshot commit has been made,
-		// no agent-changes commit on top). Nothing to diff against — return
-		// uncommitted changes only, matching the semantic of "agent-made changes".
-		out, _ = cmdexec.Git(snapshotPath, "diff", "HEAD").WithContext(ctx).Output()
+		return ""
 	}
+	out, _ := cmdexec.Git(snapshotPath, "diff", root).WithContext(ctx).Output()
 
 	if untrackedRaw, err := cmdexec.Git(snapshotPath,
 		"ls-files", "--others", "--exclude-standard").WithContext(ctx).Output(); err == nil {
@@ -32,8 +31,127 @@ func SnapshotDiff(ctx context.Context, snapshotPath string) string {
 			}
 			fd, _ := cmdexec.Git(snapshotPath,
 				"diff", "--no-index", "/dev/null", file).WithContext(ctx).Output()
+			if fd == "" {
+				continue
+			}
+			// Output is trimmed, so each diff needs its own line break.
+			if out != "" {
+				out += "\n"
+			}
 			out += fd
 		}
 	}
 	return out
 }
+
+// SnapshotRoot returns the hash of a snapshot repository's initial commit,
+// the baseline every agent change is measured against.
+func SnapshotRoot(ctx context.Context, snapshotPath string) (string, error) {
+	roots, err := cmdexec.Git(snapshotPath, "rev-list", "--max-parents=0", "HEAD").WithContext(ctx).Output()
+	if err != nil {
+		return "", err
+	}
+	root, _, _ := strings.Cut(strings.TrimSpace(roots), "\n")
+	if root == "" {
+		return "", fmt.Errorf("no root commit in %s", snapshotPath)
+	}
+	return root, nil
+}
+
+// SnapshotBaseline returns the slash-separated paths of the files recorded in
+// a snapshot repository's initial commit, i.e. the workspace files the
+// snapshot started from. Intended for repos created by [InitLocalRepo].
+func SnapshotBaseline(ctx context.Context, snapshotPath string) ([]string, error) {
+	root, err := SnapshotRoot(ctx, snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmdexec.Git(snapshotPath, "ls-tree", "-r", "-z", "--name-only", root).WithContext(ctx).OutputBytes()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for p := range strings.SplitSeq(string(out), "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// FileChangeStatus classifies how a file differs from its baseline.
+type FileChangeStatus string
+
+// FileChangeStatus constants.
+const (
+	FileAdded    FileChangeStatus = "added"
+	FileModified FileChangeStatus = "modified"
+	FileDeleted  FileChangeStatus = "deleted"
+	FileRenamed  FileChangeStatus = "renamed"
+)
+
+// FileChange is one file's entry in a file-level summary of a diff.
+type FileChange struct {
+	Path    string           `json:"path"`
+	OldPath string           `json:"old_path,omitempty"` // set for renames
+	Status  FileChangeStatus `json:"status"`
+	Binary  bool             `json:"binary,omitempty"` // no content diff was rendered
+}
+
+// DiffFiles summarises a unified git diff (such as [SnapshotDiff] output)
+// file by file, in diff order. Paths git had to quote are returned as they
+// appear in the diff.
+func DiffFiles(diff string) []FileChange {
+	var files []FileChange
+	var cur *FileChange
+	for line := range strings.SplitSeq(diff, "\n") {
+		if rest, ok := strings.CutPrefix(line, "diff --git "); ok {
+			files = append(files, FileChange{Path: headerPath(rest), Status: FileModified})
+			cur = &files[len(files)-1]
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "new file mode"):
+			cur.Status = FileAdded
+		case strings.HasPrefix(line, "deleted file mode"):
+			cur.Status = FileDeleted
+		case strings.HasPrefix(line, "rename from "):
+			cur.Status, cur.OldPath = FileRenamed, strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			cur.Path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+			cur.Binary = true
+		case strings.HasPrefix(line, "+++ b/"):
+			cur.Path = lineEndPath(strings.TrimPrefix(line, "+++ b/"))
+		case strings.HasPrefix(line, "--- a/") && cur.Status == FileDeleted:
+			cur.Path = lineEndPath(strings.TrimPrefix(line, "--- a/"))
+		}
+	}
+	return files
+}
+
+// lineEndPath strips the tab git appends to a "---"/"+++" path that
+// contains spaces.
+func lineEndPath(p string) string {
+	return strings.TrimSuffix(p, "\t")
+}
+
+// headerPath extracts the path from the "a/<path> b/<path>" part of a
+// "diff --git" header. Outside renames both halves name the same path, so
+// the split point follows from the length even when the path has spaces.
+func headerPath(rest string) string {
+	if n := len(rest); n >= 5 && (n-1)%2 == 0 {
+		half := (n - 1) / 2
+		a, b := rest[:half], rest[half+1:]
+		if strings.HasPrefix(a, "a/") && strings.HasPrefix(b, "b/") && a[2:] == b[2:] {
+			return a[2:]
+		}
+	}
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -91,6 +92,37 @@ func TestSnapshotDiff_SingleCommit_UncommittedOnly(t *testing.T) {
 	}
 }
 
+func TestSnapshotBaseline_InitialCommitOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.txt"), "a\n")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "sub", "b c.txt"), "b\n")
+	if err := InitLocalRepo(dir, "a@b", "A", "init"); err != nil {
+		t.Fatalf("InitLocalRepo: %v", err)
+	}
+	// Files added and removed after the initial commit are not baseline.
+	writeFile(t, filepath.Join(dir, "later.txt"), "later\n")
+	gitRun(t, dir, "rm", "-q", "a.txt")
+	gitRun(t, dir, "add", "-A")
+	gitRun(t, dir, "commit", "-m", "changes")
+
+	got, err := SnapshotBaseline(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("SnapshotBaseline: %v", err)
+	}
+	if want := []string{"a.txt", "sub/b c.txt"}; !slices.Equal(got, want) {
+		t.Errorf("SnapshotBaseline = %q, want %q", got, want)
+	}
+}
+
+func TestSnapshotBaseline_NotARepo(t *testing.T) {
+	if _, err := SnapshotBaseline(context.Background(), t.TempDir()); err == nil {
+		t.Error("expected an error outside a git repo")
+	}
+}
+
 func TestHasChanges(t *testing.T) {
 	dir := setupRepo(t)
 	ctx := context.Background()
@@ -158,3 +190,89 @@ func TestGlobalIdentityOverrides_Missing(t *testing.T) {
 		t.Errorf("expected no overrides when global identity unset, got %v", got)
 	}
 }
+
+func TestSnapshotDiff_CommittedAndUncommitted(t *testing.T) {
+	// Agent commits on top of the snapshot and uncommitted edits after them
+	// are both measured against the initial commit.
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.txt"), "a0\n")
+	writeFile(t, filepath.Join(dir, "b.txt"), "b0\n")
+	if err := InitLocalRepo(dir, "a@b", "A", "init"); err != nil {
+		t.Fatalf("InitLocalRepo: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "a.txt"), "a1\n")
+	gitRun(t, dir, "commit", "-qam", "first")
+	writeFile(t, filepath.Join(dir, "a.txt"), "a2\n")
+	gitRun(t, dir, "commit", "-qam", "second")
+	writeFile(t, filepath.Join(dir, "b.txt"), "b1\n")
+
+	diff := SnapshotDiff(context.Background(), dir)
+	for _, want := range []string{"-a0", "+a2", "-b0", "+b1"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q: %s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "a1") {
+		t.Errorf("diff shows intermediate commit content: %s", diff)
+	}
+}
+
+func TestSnapshotRoot(t *testing.T) {
+	dir := t.TempDir()
+	if err := InitLocalRepo(dir, "a@b", "A", "init"); err != nil {
+		t.Fatalf("InitLocalRepo: %v", err)
+	}
+	want := gitRun(t, dir, "rev-parse", "HEAD")
+	gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", "later")
+
+	got, err := SnapshotRoot(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("SnapshotRoot: %v", err)
+	}
+	if got != want {
+		t.Errorf("SnapshotRoot = %q, want %q", got, want)
+	}
+	if _, err := SnapshotRoot(context.Background(), t.TempDir()); err == nil {
+		t.Error("expected an error outside a git repo")
+	}
+}
+
+func TestDiffFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "keep.txt"), "one\n")
+	writeFile(t, filepath.Join(dir, "gone.txt"), "bye\n")
+	writeFile(t, filepath.Join(dir, "old name.txt"), "moved content that is long enough\n")
+	writeFile(t, filepath.Join(dir, "img.bin"), "\x00\x01")
+	if err := InitLocalRepo(dir, "a@b", "A", "init"); err != nil {
+		t.Fatalf("InitLocalRepo: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "keep.txt"), "two\n")
+	if err := os.Remove(filepath.Join(dir, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, dir, "mv", "old name.txt", "new name.txt")
+	writeFile(t, filepath.Join(dir, "img.bin"), "\x00\x02")
+	gitRun(t, dir, "commit", "-qam", "changes")
+	if err := os.Mkdir(filepath.Join(dir, "sub dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "sub dir", "added.txt"), "hi\n")
+
+	got := DiffFiles(SnapshotDiff(context.Background(), dir))
+	want := []FileChange{
+		{Path: "gone.txt", Status: FileDeleted},
+		{Path: "img.bin", Status: FileModified, Binary: true},
+		{Path: "keep.txt", Status: FileModified},
+		{Path: "new name.txt", OldPath: "old name.txt", Status: FileRenamed},
+		{Path: "sub dir/added.txt", Status: FileAdded},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("DiffFiles =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiffFiles_Empty(t *testing.T) {
+	if got := DiffFiles(""); got != nil {
+		t.Errorf("DiffFiles(\"\") = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,83 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+)
+
+// StageAll stages every change in worktreePath like `git add -A`, except
+// untracked paths matching one of the ignore patterns, and returns the
+// untracked paths it left unstaged.
+//
+// Patterns follow .gitignore conventions: a pattern without a slash matches
+// at any depth, a leading slash anchors it at the worktree root, a trailing
+// slash matches everything under a directory, and `*`, `?`, and `**` glob.
+// Negation is not supported. Changes to tracked files are always staged,
+// since a tracked file left modified would block the later rebase; the
+// patterns hold back generated files (dist/, coverage output) the agent
+// created. Files ignored by .gitignore are neither staged nor returned.
+func StageAll(ctx context.Context, worktreePath string, ignore []string) ([]string, error) {
+	specs := stageIgnorePathspecs(ignore)
+	if len(specs) == 0 {
+		if out, err := cmdexec.Git(worktreePath, "add", "-A").WithContext(ctx).Combined(); err != nil {
+			return nil, fmt.Errorf("git add -A: %w: %s", err, out)
+		}
+		return nil, nil
+	}
+	if out, err := cmdexec.Git(worktreePath, "add", "-u").WithContext(ctx).Combined(); err != nil {
+		return nil, fmt.Errorf("git add -u: %w: %s", err, out)
+	}
+	args := append([]string{"add", "-A", "--", "."}, specs...)
+	if out, err := cmdexec.Git(worktreePath, args...).WithContext(ctx).Combined(); err != nil {
+		return nil, fmt.Errorf("git add -A: %w: %s", err, out)
+	}
+	out, err := cmdexec.Git(worktreePath, "ls-files", "-z", "--others", "--exclude-standard").WithContext(ctx).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", err)
+	}
+	var skipped []string
+	for p := range strings.SplitSeq(out, "\x00") {
+		if p != "" {
+			skipped = append(skipped, p)
+		}
+	}
+	return skipped, nil
+}
+
+// HasStagedChanges reports whether worktreePath's index differs from HEAD.
+// Unlike HasChanges it ignores unstaged and untracked files, such as those
+// StageAll held back.
+func HasStagedChanges(ctx context.Context, worktreePath string) (bool, error) {
+	out, err := cmdexec.Git(worktreePath, "diff", "--cached", "--name-only").WithContext(ctx).Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// stageIgnorePathspecs turns .gitignore-style patterns into git exclude
+// pathspecs. Each pattern excludes the path itself and, as it may name a
+// directory, everything under it; a trailing slash keeps only the latter.
+func stageIgnorePathspecs(patterns []string) []string {
+	var specs []string
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		dir := strings.HasSuffix(p, "/")
+		anchored := strings.HasPrefix(p, "/")
+		p = strings.Trim(p, "/")
+		if p == "" || strings.HasPrefix(p, "#") || strings.HasPrefix(p, "!") {
+			continue
+		}
+		if !anchored && !strings.Contains(p, "/") {
+			p = "**/" + p
+		}
+		if !dir {
+			specs = append(specs, ":(exclude,glob)"+p)
+		}
+		specs = append(specs, ":(exclude,glob)"+p+"/**")
+	}
+	return specs
+}
@@ -0,0 +1,72 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStageAll_Ignore(t *testing.T) {
+	repo := setupRepo(t)
+	ctx := context.Background()
+	for _, d := range []string{"dist", "web/dist", "src", "build"} {
+		if err := os.MkdirAll(filepath.Join(repo, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(repo, "file.txt"), "changed\n")
+	writeFile(t, filepath.Join(repo, "dist", "app.js"), "bundle")
+	writeFile(t, filepath.Join(repo, "web", "dist", "app.js"), "bundle")
+	writeFile(t, filepath.Join(repo, "src", "main.go"), "package main\n")
+	writeFile(t, filepath.Join(repo, "src", "coverage.out"), "mode: set\n")
+	writeFile(t, filepath.Join(repo, "build", "out.bin"), "bin")
+	writeFile(t, filepath.Join(repo, "build.go"), "package main\n")
+
+	skipped, err := StageAll(ctx, repo, []string{"dist/", "coverage.out", "/build", "  ", "!src/main.go"})
+	if err != nil {
+		t.Fatalf("StageAll: %v", err)
+	}
+	want := []string{"build/out.bin", "dist/app.js", "src/coverage.out", "web/dist/app.js"}
+	slices.Sort(skipped)
+	if !slices.Equal(skipped, want) {
+		t.Errorf("skipped = %q, want %q", skipped, want)
+	}
+	staged := strings.Fields(gitRun(t, repo, "diff", "--cached", "--name-only"))
+	if wantStaged := []string{"build.go", "file.txt", "src/main.go"}; !slices.Equal(staged, wantStaged) {
+		t.Errorf("staged = %q, want %q", staged, wantStaged)
+	}
+}
+
+// TestStageAll_IgnoreKeepsTrackedChanges verifies that a pattern never holds
+// back a change to a tracked file.
+func TestStageAll_IgnoreKeepsTrackedChanges(t *testing.T) {
+	repo := setupRepo(t)
+	ctx := context.Background()
+	writeFile(t, filepath.Join(repo, "file.txt"), "changed\n")
+	skipped, err := StageAll(ctx, repo, []string{"*.txt"})
+	if err != nil {
+		t.Fatalf("StageAll: %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("skipped = %q, want none", skipped)
+	}
+	if staged, _ := HasStagedChanges(ctx, repo); !staged {
+		t.Error("tracked change was not staged")
+	}
+}
+
+func TestHasStagedChanges(t *testing.T) {
+	repo := setupRepo(t)
+	ctx := context.Background()
+	writeFile(t, filepath.Join(repo, "untracked.txt"), "x")
+	if staged, err := HasStagedChanges(ctx, repo); err != nil || staged {
+		t.Fatalf("HasStagedChanges with only untracked files = %v, %v", staged, err)
+	}
+	gitRun(t, repo, "add", "untracked.txt")
+	if staged, err := HasStagedChanges(ctx, repo); err != nil || !staged {
+		t.Fatalf("HasStagedChanges after add = %v, %v", staged, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"strings"
 
 	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
@@ -124,3 +125,27 @@ func RemoveWorktree(repoPath, worktreePath, branchName string) error {
 	}
 	return nil
 }
+
+// CreateDetachedWorktree checks out commit as a detached worktree at
+// worktreePath, without creating a branch. Used for throwaway checkouts of
+// an exact commit; remove it with RemoveDetachedWorktree.
+func CreateDetachedWorktree(repoPath, worktreePath, commit string) error {
+	out, err := cmdexec.Git(repoPath, "worktree", "add", "--detach", worktreePath, commit).Combined()
+	if err != nil {
+		return fmt.Errorf("git worktree add --detach in %s: %w\n%s", repoPath, err, out)
+	}
+	return nil
+}
+
+// RemoveDetachedWorktree removes a worktree created by CreateDetachedWorktree
+// and prunes its registration even when the directory is already gone.
+func RemoveDetachedWorktree(repoPath, worktreePath string) error {
+	out, err := cmdexec.Git(repoPath, "worktree", "remove", "--force", worktreePath).Combined()
+	if err != nil {
+		_ = os.RemoveAll(worktreePath)
+		if pruneErr := cmdexec.Git(repoPath, "worktree", "prune").Run(); pruneErr != nil {
+			return fmt.Errorf("git worktree remove %s: %w\n%s", worktreePath, err, out)
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -326,3 +327,40 @@ func TestRemoveWorktree_NonWorktreeError(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+// TestDetachedWorktree checks out an exact commit without a branch and
+// removes it again, also after the directory was deleted externally.
+func TestDetachedWorktree(t *testing.T) {
+	repo := setupRepo(t)
+	head, err := ResolveHead(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wtDir := filepath.Join(t.TempDir(), "detached")
+	if err := CreateDetachedWorktree(repo, wtDir, head); err != nil {
+		t.Fatalf("CreateDetachedWorktree: %v", err)
+	}
+	if got, err := ResolveHead(wtDir); err != nil || got != head {
+		t.Fatalf("worktree HEAD = %q, %v; want %q", got, err, head)
+	}
+	if branches := strings.TrimSpace(gitRun(t, repo, "branch", "--list")); branches != "* main" {
+		t.Errorf("detached worktree created a branch: %q", branches)
+	}
+	if err := RemoveDetachedWorktree(repo, wtDir); err != nil {
+		t.Fatalf("RemoveDetachedWorktree: %v", err)
+	}
+	if _, err := os.Stat(wtDir); !os.IsNotExist(err) {
+		t.Error("worktree directory still exists after removal")
+	}
+
+	if err := CreateDetachedWorktree(repo, wtDir, head); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.RemoveAll(wtDir)
+	if err := RemoveDetachedWorktree(repo, wtDir); err != nil {
+		t.Errorf("remove after external delete: %v", err)
+	}
+	if list := gitRun(t, repo, "worktree", "list"); strings.Contains(list, wtDir) {
+		t.Errorf("stale worktree still registered: %s", list)
+	}
+}
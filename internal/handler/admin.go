@@ -3,6 +3,8 @@ package handler
 import (
 	"net/http"
 
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/harness"
 	"latere.ai/x/wallfacer/internal/logger"
 	"latere.ai/x/wallfacer/internal/pkg/httpjson"
 )
@@ -27,3 +29,72 @@ func (h *Handler) RebuildIndex(w http.ResponseWriter, r *http.Request) {
 	logger.Handler.Info("search index rebuild complete", "repaired", repaired)
 	httpjson.Write(w, http.StatusOK, rebuildIndexResponse{Repaired: repaired})
 }
+
+// reloadConfigResponse is the JSON shape returned by POST /api/admin/reload.
+type reloadConfigResponse struct {
+	// Reloaded lists the configuration sources that were re-read.
+	Reloaded []string `json:"reloaded"`
+	// Errors lists the sources that failed to reload; they keep their
+	// previous values.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ReloadConfig re-reads the env file, the workspace group settings, and the
+// agent and flow catalogs without restarting the server. In-flight tasks keep
+// running; the reloaded values apply to the next launch. The same reload runs
+// on SIGHUP.
+func (h *Handler) ReloadConfig(w http.ResponseWriter, _ *http.Request) {
+	httpjson.Write(w, http.StatusOK, h.reload())
+}
+
+// Reload performs the same reload as ReloadConfig; the server calls it on
+// SIGHUP.
+func (h *Handler) Reload() { h.reload() }
+
+// reload applies configuration changes made on disk since startup and
+// reports what it reloaded. A source that fails to reload keeps its
+// previous values and is listed in the errors; the others still apply.
+func (h *Handler) reload() reloadConfigResponse {
+	var resp reloadConfigResponse
+	maxParallel, maxTestParallel := h.maxConcurrentTasks(), h.maxTestConcurrentTasks()
+	step := func(name string, fn func() error) {
+		if err := fn(); err != nil {
+			resp.Errors = append(resp.Errors, name+": "+err.Error())
+			return
+		}
+		resp.Reloaded = append(resp.Reloaded, name)
+	}
+
+	step("env", func() error {
+		cfg, err := envconfig.Parse(h.envFile)
+		if err != nil {
+			return err
+		}
+		h.autopush.Store(cfg.AutoPushEnabled)
+		h.cachedMaxParallel.Invalidate()
+		h.cachedMaxTestParallel.Invalidate()
+		// Credentials may have been rotated: require a fresh sandbox test
+		// for API-key codex, as after an edit on the Settings page.
+		h.setSandboxTestPassed(harness.Codex, false)
+		h.refreshCodexBootstrapAuthState()
+		return nil
+	})
+	step("agent backend", h.runner.ReloadConfig)
+	step("workspace groups", func() error {
+		h.reloadGroupLimits()
+		return nil
+	})
+	step("agents", h.runner.ReloadAgents)
+	step("flows", h.runner.ReloadFlows)
+
+	logger.Handler.Info("configuration reloaded", "reloaded", resp.Reloaded, "errors", resp.Errors)
+	// A raised parallel limit is filled right away rather than on the next
+	// store event.
+	if h.maxConcurrentTasks() > maxParallel {
+		go h.tryAutoPromote(h.runner.ShutdownCtx())
+	}
+	if h.maxTestConcurrentTasks() > maxTestParallel {
+		go h.tryAutoTest(h.runner.ShutdownCtx())
+	}
+	return resp
+}
@@ -3,11 +3,18 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"latere.ai/x/wallfacer/internal/runner"
 	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/store/storetest"
 )
 
 func TestRebuildIndex_EmptyStore_Returns200(t *testing.T) {
@@ -71,3 +78,65 @@ func TestRebuildIndex_ResponseIsJSON(t *testing.T) {
 		t.Error("expected Content-Type header")
 	}
 }
+
+func callReloadConfig(t *testing.T, h *Handler) reloadConfigResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ReloadConfig(w, httptest.NewRequest(http.MethodPost, "/api/admin/reload", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp reloadConfigResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+func TestReloadConfig_AppliesEnvFileEdits(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("WALLFACER_MAX_PARALLEL=2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := storetest.NewFileStore(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := &runner.MockRunner{EnvFilePath: envFile}
+	h := NewHandler(s, mock, t.TempDir(), nil, nil)
+	if got := h.maxConcurrentTasks(); got != 2 {
+		t.Fatalf("max parallel = %d, want 2", got)
+	}
+
+	if err := os.WriteFile(envFile, []byte("WALLFACER_MAX_PARALLEL=1\nWALLFACER_AUTO_PUSH=true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.maxConcurrentTasks(); got != 2 {
+		t.Fatalf("max parallel before reload = %d, want the cached 2", got)
+	}
+	resp := callReloadConfig(t, h)
+	for _, want := range []string{"env", "agent backend", "workspace groups", "agents", "flows"} {
+		if !slices.Contains(resp.Reloaded, want) {
+			t.Errorf("reloaded = %v, missing %q", resp.Reloaded, want)
+		}
+	}
+	if len(resp.Errors) != 0 {
+		t.Errorf("errors = %v", resp.Errors)
+	}
+	if got := h.maxConcurrentTasks(); got != 1 {
+		t.Errorf("max parallel after reload = %d, want 1", got)
+	}
+	if !h.AutopushEnabled() {
+		t.Error("auto push not reloaded from the env file")
+	}
+	if mock.ReloadConfigCalls != 1 {
+		t.Errorf("runner ReloadConfig calls = %d, want 1", mock.ReloadConfigCalls)
+	}
+
+	// A failing source is reported without blocking the others.
+	mock.ReloadConfigErr = errors.New("claude binary not found")
+	resp = callReloadConfig(t, h)
+	if len(resp.Errors) != 1 || !strings.HasPrefix(resp.Errors[0], "agent backend: ") || !slices.Contains(resp.Reloaded, "env") {
+		t.Errorf("reload with a failing backend = %+v", resp)
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+)
+
+// GetAgentProbe returns the latest capability probe of the implementation
+// agent, or 204 when none has run yet.
+func (h *Handler) GetAgentProbe(w http.ResponseWriter, _ *http.Request) {
+	p, ok := h.runner.AgentProbeStatus()
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, p)
+}
+
+// ProbeAgent re-runs the capability probe and returns its result. A passing
+// probe lifts the start gate at once instead of waiting for the next
+// scheduled retry.
+func (h *Handler) ProbeAgent(w http.ResponseWriter, r *http.Request) {
+	httpjson.Write(w, http.StatusOK, h.runner.ProbeAgent(r.Context()))
+}
+
+// reprobeFailedAgent re-runs a failed capability probe in the background,
+// since the failure may have come from settings that just changed.
+func (h *Handler) reprobeFailedAgent() {
+	if p, ok := h.runner.AgentProbeStatus(); ok && !p.OK {
+		go h.runner.ProbeAgent(h.runner.ShutdownCtx())
+	}
+}
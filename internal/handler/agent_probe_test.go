@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestGetAgentProbe(t *testing.T) {
+	mock := &runner.MockRunner{}
+	h, _ := newTestHandlerWithMockRunner(t, mock)
+
+	w := httptest.NewRecorder()
+	h.GetAgentProbe(w, httptest.NewRequest(http.MethodGet, "/api/env/probe", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("before any probe: status = %d, want 204", w.Code)
+	}
+
+	mock.Probe = &runner.AgentProbe{Harness: harness.Claude, Model: "m", Error: "model not found"}
+	w = httptest.NewRecorder()
+	h.GetAgentProbe(w, httptest.NewRequest(http.MethodGet, "/api/env/probe", nil))
+	var got runner.AgentProbe
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || got.OK || got.Error != "model not found" {
+		t.Errorf("status = %d, probe = %+v", w.Code, got)
+	}
+}
+
+func TestUpdateTask_StartBlockedByFailedProbe(t *testing.T) {
+	mock := &runner.MockRunner{Probe: &runner.AgentProbe{Harness: harness.Claude, Error: "claude CLI not found on PATH"}}
+	h, s := newTestHandlerWithMockRunner(t, mock)
+	task, err := s.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := patchTask(h, task.ID, `{"status":"in_progress"}`)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503: %s", w.Code, w.Body.String())
+	}
+	if code := decodeErrorCode(t, w); code != codeAgentUnavailable {
+		t.Errorf("code = %q, want %q", code, codeAgentUnavailable)
+	}
+	if got, _ := s.GetTask(context.Background(), task.ID); got.Status != store.TaskStatusBacklog {
+		t.Errorf("task status = %s, want backlog", got.Status)
+	}
+	if calls := mock.RunCalls(); len(calls) != 0 {
+		t.Errorf("RunBackground called %d times", len(calls))
+	}
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/auth"
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+)
+
+// uiSessionCookie names the cookie that keeps a browser signed in once it
+// has exchanged an API token at POST /api/auth/session.
+const uiSessionCookie = "wallfacer-session"
+
+// APIAuth authenticates API requests with static bearer tokens
+// (WALLFACER_SERVER_API_KEY and WALLFACER_API_TOKENS) and with the UI
+// session cookie a browser gets in exchange for one of them. Requests that
+// already carry an OIDC identity, resolved upstream from the sign-in cookie
+// or a JWT, pass without either.
+type APIAuth struct {
+	tokens       [][]byte
+	fingerprints map[string]bool
+	sessionKey   []byte // signs UI session cookies; nil disables them
+	required     bool
+	now          func() time.Time
+}
+
+// NewAPIAuth returns the authentication for tokens, dropping empty
+// entries. sessionKey signs UI session cookies; nil disables token
+// sign-in from the browser. required enforces authentication even when no
+// token is configured, which leaves OIDC sign-in as the only way in.
+func NewAPIAuth(tokens []string, sessionKey []byte, required bool) *APIAuth {
+	a := &APIAuth{fingerprints: map[string]bool{}, sessionKey: sessionKey, required: required, now: time.Now}
+	for _, t := range tokens {
+		if t = strings.TrimSpace(t); t != "" {
+			a.tokens = append(a.tokens, []byte(t))
+			a.fingerprints[tokenFingerprint(t)] = true
+		}
+	}
+	return a
+}
+
+// Enabled reports whether requests are checked at all.
+func (a *APIAuth) Enabled() bool {
+	return a != nil && (len(a.tokens) > 0 || a.required)
+}
+
+// Middleware rejects unauthenticated requests with 401. The SPA shell and
+// its assets, the OIDC sign-in round trip, the UI session endpoints, and
+// POST /api/intake (which checks WALLFACER_INTAKE_TOKEN itself) stay
+// public. SSE and WebSocket paths take the token as ?token= because
+// EventSource and WebSocket cannot set an Authorization header. With
+// authentication disabled the middleware is a no-op.
+func (a *APIAuth) Middleware(next http.Handler) http.Handler {
+	if !a.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublicPath(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// A request already authenticated by the upstream cookie or JWT
+		// middleware bypasses the token check, so signed-in browsers and
+		// token-carrying scripts work side by side.
+		if _, ok := auth.PrincipalFromContext(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !a.authenticated(r) {
+			httpjson.Write(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isPublicPath reports whether r is served without authentication.
+func isPublicPath(r *http.Request) bool {
+	p := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && (p == "/" || p == "/favicon.ico"):
+	case r.Method == http.MethodGet && (strings.HasPrefix(p, "/assets/") || strings.HasPrefix(p, "/fonts/") || strings.HasPrefix(p, "/static/")):
+	case p == "/login" || p == "/callback" || p == "/logout" || p == "/logout/notify":
+	case p == "/api/auth/session":
+	case r.Method == http.MethodPost && p == "/api/intake":
+	default:
+		return false
+	}
+	return true
+}
+
+// isStreamPath reports whether path is an SSE or WebSocket endpoint, which
+// authenticates with ?token= instead of an Authorization header.
+func isStreamPath(path string) bool {
+	switch path {
+	case "/api/tasks/stream", "/api/git/stream", "/api/terminal/ws", "/api/ws",
+		"/api/explorer/stream", "/api/explorer/file/stream", "/api/specs/stream":
+		return true
+	}
+	return strings.HasPrefix(path, "/api/tasks/") && strings.HasSuffix(path, "/logs")
+}
+
+// authenticated reports whether r carries a configured token or a valid UI
+// session cookie.
+func (a *APIAuth) authenticated(r *http.Request) bool {
+	if a.validSession(r) {
+		return true
+	}
+	if isStreamPath(r.URL.Path) {
+		return a.matchToken(r.URL.Query().Get("token"))
+	}
+	token, ok := strings.CutPrefix(strings.TrimSpace(r.Header.Get("Authorization")), "Bearer ")
+	return ok && a.matchToken(token)
+}
+
+// matchToken compares token against every configured token in constant time.
+func (a *APIAuth) matchToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	match := 0
+	for _, t := range a.tokens {
+		match |= subtle.ConstantTimeCompare([]byte(token), t)
+	}
+	return match == 1
+}
+
+// tokenFingerprint identifies a token inside a session cookie without
+// revealing it.
+func tokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// newSession returns a session cookie value for token: its expiry and the
+// token's fingerprint, signed with the session key.
+func (a *APIAuth) newSession(token string) string {
+	payload := strconv.FormatInt(a.now().Add(constants.UISessionTTL).Unix(), 10) + "." + tokenFingerprint(token)
+	return payload + "." + a.sign(payload)
+}
+
+// validSession reports whether r carries an unexpired session cookie whose
+// token is still configured, so removing a token ends its sessions.
+func (a *APIAuth) validSession(r *http.Request) bool {
+	if a.sessionKey == nil {
+		return false
+	}
+	c, err := r.Cookie(uiSessionCookie)
+	if err != nil {
+		return false
+	}
+	payload, sig, ok := cutLast(c.Value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(a.sign(payload))) {
+		return false
+	}
+	exp, fingerprint, ok := strings.Cut(payload, ".")
+	if !ok || !a.fingerprints[fingerprint] {
+		return false
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	return err == nil && a.now().Unix() < unix
+}
+
+func (a *APIAuth) sign(payload string) string {
+	mac := hmac.New(sha256.New, a.sessionKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// SetAPIAuth installs the API authentication that backs the
+// /api/auth/session endpoints. Pass nil (the default) to leave them
+// reporting authentication as disabled.
+func (h *Handler) SetAPIAuth(a *APIAuth) { h.apiAuth = a }
+
+// authSessionResponse is the body of GET /api/auth/session.
+type authSessionResponse struct {
+	Required      bool `json:"required"`      // API routes need authentication
+	Authenticated bool `json:"authenticated"` // this request passes authentication
+	Tokens        bool `json:"tokens"`        // an API token can be exchanged for a UI session
+	OIDC          bool `json:"oidc"`          // /login signs in
+}
+
+// AuthSession reports whether API authentication is on and whether the
+// caller passes it, so the UI can decide to show its sign-in screen:
+//
+//	GET /api/auth/session
+func (h *Handler) AuthSession(w http.ResponseWriter, r *http.Request) {
+	a := h.apiAuth
+	resp := authSessionResponse{
+		Required: a.Enabled(),
+		Tokens:   a.Enabled() && len(a.tokens) > 0 && a.sessionKey != nil,
+		OIDC:     h.auth != nil,
+	}
+	_, signedIn := auth.PrincipalFromContext(r.Context())
+	resp.Authenticated = !resp.Required || signedIn || a.authenticated(r)
+	w.Header().Set("Cache-Control", "no-store")
+	httpjson.Write(w, http.StatusOK, resp)
+}
+
+// CreateAuthSession exchanges an API token for a UI session cookie, so a
+// browser can use the board without the token being embedded in the page:
+//
+//	POST /api/auth/session  {"token": "..."}
+//
+// The cookie lasts constants.UISessionTTL and ends early when the token is
+// removed from the configuration. 503 when token sign-in is unavailable.
+func (h *Handler) CreateAuthSession(w http.ResponseWriter, r *http.Request) {
+	a := h.apiAuth
+	if a == nil || len(a.tokens) == 0 || a.sessionKey == nil {
+		http.Error(w, "token sign-in is not configured; set WALLFACER_SERVER_API_KEY or WALLFACER_API_TOKENS", http.StatusServiceUnavailable)
+		return
+	}
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	token := strings.TrimSpace(req.Token)
+	if !a.matchToken(token) {
+		httpjson.Write(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     uiSessionCookie,
+		Value:    a.newSession(token),
+		Path:     "/",
+		MaxAge:   int(constants.UISessionTTL / time.Second),
+		HttpOnly: true,
+		Secure:   RequestScheme(r) == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteAuthSession signs the browser out of its UI session:
+//
+//	DELETE /api/auth/session
+func (h *Handler) DeleteAuthSession(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     uiSessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   RequestScheme(r) == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
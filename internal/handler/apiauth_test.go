@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/auth"
+)
+
+func serveAPIAuth(a *APIAuth, r *http.Request) int {
+	w := httptest.NewRecorder()
+	a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})).ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestAPIAuth_MultipleTokens(t *testing.T) {
+	a := NewAPIAuth([]string{"", "server-key", " ci-token "}, nil, false)
+	for _, tc := range []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"server key", "Bearer server-key", http.StatusNoContent},
+		{"extra token", "Bearer ci-token", http.StatusNoContent},
+		{"unknown token", "Bearer nope", http.StatusUnauthorized},
+		{"empty token", "Bearer ", http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+			r.Header.Set("Authorization", tc.header)
+			if got := serveAPIAuth(a, r); got != tc.want {
+				t.Errorf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAPIAuth_RequiredWithoutTokens(t *testing.T) {
+	a := NewAPIAuth(nil, []byte("key"), true)
+	for _, tc := range []struct {
+		method, target string
+		want           int
+	}{
+		{http.MethodGet, "/", http.StatusNoContent},
+		{http.MethodGet, "/assets/index.js", http.StatusNoContent},
+		{http.MethodGet, "/login", http.StatusNoContent},
+		{http.MethodGet, "/api/auth/session", http.StatusNoContent},
+		{http.MethodGet, "/api/tasks", http.StatusUnauthorized},
+		{http.MethodGet, "/api/tasks/stream?token=", http.StatusUnauthorized},
+		{http.MethodGet, "/artifact/report.html", http.StatusUnauthorized},
+	} {
+		r := httptest.NewRequest(tc.method, tc.target, nil)
+		if got := serveAPIAuth(a, r); got != tc.want {
+			t.Errorf("%s %s: status = %d, want %d", tc.method, tc.target, got, tc.want)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	r = r.WithContext(auth.WithIdentity(r.Context(), &auth.Identity{Sub: "user-1"}))
+	if got := serveAPIAuth(a, r); got != http.StatusNoContent {
+		t.Errorf("signed-in request: status = %d, want 204", got)
+	}
+}
+
+func TestAuthSession_ExchangeTokenForCookie(t *testing.T) {
+	h := newTestHandler(t)
+	a := NewAPIAuth([]string{"server-key"}, []byte("session-key"), true)
+	h.SetAPIAuth(a)
+
+	w := httptest.NewRecorder()
+	h.CreateAuthSession(w, httptest.NewRequest(http.MethodPost, "/api/auth/session", strings.NewReader(`{"token":"wrong"}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: status = %d, want 401", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.CreateAuthSession(w, httptest.NewRequest(http.MethodPost, "/api/auth/session", strings.NewReader(`{"token":"server-key"}`)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204; body=%s", w.Code, w.Body.String())
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != uiSessionCookie || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %+v", cookies)
+	}
+	session := cookies[0]
+	if strings.Contains(session.Value, "server-key") {
+		t.Fatal("session cookie embeds the token")
+	}
+
+	// The cookie alone authenticates API and stream requests.
+	for _, target := range []string{"/api/tasks", "/api/tasks/stream"} {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.AddCookie(session)
+		if got := serveAPIAuth(a, r); got != http.StatusNoContent {
+			t.Errorf("%s with session: status = %d, want 204", target, got)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/auth/session", nil)
+	r.AddCookie(session)
+	w = httptest.NewRecorder()
+	h.AuthSession(w, r)
+	var resp authSessionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Required || !resp.Authenticated || !resp.Tokens {
+		t.Errorf("session = %+v", resp)
+	}
+
+	// A tampered cookie, an expired one, and one whose token was removed
+	// from the configuration are all rejected.
+	tampered := *session
+	tampered.Value = strings.Replace(session.Value, ".", "9.", 1)
+	expired := NewAPIAuth([]string{"server-key"}, []byte("session-key"), true)
+	expired.now = func() time.Time { return time.Now().Add(30 * 24 * time.Hour) }
+	rotated := NewAPIAuth([]string{"new-key"}, []byte("session-key"), true)
+	for name, tc := range map[string]struct {
+		a *APIAuth
+		c *http.Cookie
+	}{
+		"tampered": {a, &tampered},
+		"expired":  {expired, session},
+		"rotated":  {rotated, session},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+		r.AddCookie(tc.c)
+		if got := serveAPIAuth(tc.a, r); got != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, got)
+		}
+	}
+}
+
+func TestAuthSession_Disabled(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.AuthSession(w, httptest.NewRequest(http.MethodGet, "/api/auth/session", nil))
+	var resp authSessionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Required || !resp.Authenticated || resp.Tokens {
+		t.Errorf("session = %+v, want authentication off", resp)
+	}
+
+	w = httptest.NewRecorder()
+	h.CreateAuthSession(w, httptest.NewRequest(http.MethodPost, "/api/auth/session", strings.NewReader(`{"token":"x"}`)))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// ApplyTaskPatch exports a task's diff in one of its repositories as a patch
+// and commits it onto a branch of a configured workspace, cherry-pick
+// style, e.g. to backport a fix the agent made on main to a release branch.
+//
+// The request names the target branch and optionally the target workspace
+// (default: the source repository itself), the source repository (required
+// when the task touched several git repositories), and the commit message
+// (default: the task title). The branch must not be checked out with
+// uncommitted changes; a patch that does not apply is a 409
+// patch_conflict.
+func (h *Handler) ApplyTaskPatch(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	req, ok := httpjson.DecodeBody[struct {
+		Source    string `json:"source"`
+		Workspace string `json:"workspace"`
+		Branch    string `json:"branch"`
+		Message   string `json:"message"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	branch := strings.TrimSpace(req.Branch)
+	if !isValidBranchName(branch) {
+		writeFieldError(w, "branch", "invalid branch name %q", req.Branch)
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	source, err := patchSource(task, req.Source)
+	if err != nil {
+		writeFieldError(w, "source", "%s", err)
+		return
+	}
+	target := cmp.Or(req.Workspace, source)
+	if !h.isAllowedWorkspace(r.Context(), target) {
+		http.Error(w, "workspace not configured", http.StatusBadRequest)
+		return
+	}
+	if !requireGitRepo(w, target) {
+		return
+	}
+	if _, err := gitutil.GetCommitHashForRef(target, "refs/heads/"+branch); err != nil {
+		writeFieldError(w, "branch", "branch %s not found in %s", branch, target)
+		return
+	}
+
+	patch := taskRepoPatch(r.Context(), s, task, source)
+	if strings.TrimSpace(patch) == "" {
+		http.Error(w, "task has no changes in "+source, http.StatusBadRequest)
+		return
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		message = cmp.Or(strings.TrimSpace(task.Title), "Apply task "+task.ID.String()[:8])
+	}
+
+	commit, err := gitutil.ApplyPatch(r.Context(), target, branch, patch, message, gitutil.GlobalIdentityOverrides(r.Context())...)
+	if err != nil {
+		logger.Git.Warn("apply task patch failed", "task", id, "workspace", target, "branch", branch, "error", err)
+		writeError(w, err)
+		return
+	}
+	logger.Git.Info("applied task patch", "task", id, "workspace", target, "branch", branch, "commit", commit)
+	h.insertEventOrLog(r.Context(), id, store.EventTypeSystem, map[string]string{
+		"result": fmt.Sprintf("Applied changes to %s in %s as %s", branch, target, commit[:min(len(commit), 8)]),
+	})
+	httpjson.Write(w, http.StatusOK, map[string]string{
+		"commit":    commit,
+		"workspace": target,
+		"branch":    branch,
+	})
+}
+
+// patchSource picks the git repository of task whose changes are exported:
+// the requested one, which the task must have touched, or its only git
+// repository when none is requested.
+func patchSource(task *store.Task, requested string) (string, error) {
+	var repos []string
+	for repo := range task.WorktreePaths {
+		if gitutil.IsGitRepo(repo) {
+			repos = append(repos, repo)
+		}
+	}
+	slices.Sort(repos)
+	switch {
+	case requested != "":
+		if !slices.Contains(repos, requested) {
+			return "", fmt.Errorf("task has no changes in git repository %s", requested)
+		}
+		return requested, nil
+	case len(repos) == 0:
+		return "", errors.New("task has no git repository to export")
+	case len(repos) > 1:
+		return "", fmt.Errorf("task touched %d repositories; choose one of %s", len(repos), strings.Join(repos, ", "))
+	}
+	return repos[0], nil
+}
+
+// taskRepoPatch returns the task's diff in repoPath as TaskDiff renders it:
+// the live worktree against its merge base with the default branch, or the
+// stored commit range once the worktree is gone.
+func taskRepoPatch(ctx context.Context, s *store.Store, task *store.Task, repoPath string) string {
+	worktreePath := task.WorktreePaths[repoPath]
+	if _, err := os.Stat(worktreePath); err != nil {
+		return diffFromStoredRefs(ctx, s, repoPath, task)
+	}
+	defBranch, err := gitutil.DefaultBranch(repoPath)
+	if err != nil {
+		return ""
+	}
+	base, err := gitutil.MergeBase(worktreePath, "HEAD", defBranch)
+	if err != nil {
+		base = defBranch
+	}
+	return diffWithUntracked(ctx, worktreePath, base,
+		":!"+prompts.ClaudeInstructionsFilename, ":!"+prompts.CodexInstructionsFilename)
+}
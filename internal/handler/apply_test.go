@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// applyTaskWithChange creates a task whose worktree of repo on branch "task"
+// rewrites file.txt with content, leaving the change uncommitted.
+func applyTaskWithChange(t *testing.T, h *Handler, repo, content string) *store.Task {
+	t.Helper()
+	ctx := context.Background()
+	wt := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-q", "-b", "task", wt, "HEAD")
+	if err := os.WriteFile(filepath.Join(wt, "file.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "fix", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.store.UpdateTaskTitle(ctx, task.ID, "Fix the file"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: wt}, "task"); err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func callApplyTaskPatch(h *Handler, id uuid.UUID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+id.String()+"/apply", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ApplyTaskPatch(w, req, id)
+	return w
+}
+
+func TestApplyTaskPatch_BackportsToBranch(t *testing.T) {
+	repo := setupRepo(t)
+	gitRun(t, repo, "branch", "release")
+	h, _ := newTestHandlerWithWorkspacesFromRepo(t, repo)
+	task := applyTaskWithChange(t, h, repo, "fixed\n")
+
+	w := callApplyTaskPatch(h, task.ID, `{"branch":"release"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["workspace"] != repo || resp["branch"] != "release" {
+		t.Errorf("response = %v", resp)
+	}
+	if got := gitRun(t, repo, "rev-parse", "release"); got != resp["commit"] {
+		t.Errorf("release = %s, want %s", got, resp["commit"])
+	}
+	if got := gitRun(t, repo, "show", "release:file.txt"); got != "fixed" {
+		t.Errorf("release:file.txt = %q", got)
+	}
+	if got := gitRun(t, repo, "log", "-1", "--format=%s", "release"); got != "Fix the file" {
+		t.Errorf("subject = %q, want the task title", got)
+	}
+	if got := gitRun(t, repo, "show", "main:file.txt"); got != "initial" {
+		t.Errorf("main changed: file.txt = %q", got)
+	}
+}
+
+func TestApplyTaskPatch_OtherWorkspace(t *testing.T) {
+	repo := setupRepo(t)
+	other := setupRepo(t)
+	h := newStaticWorkspaceHandler(t, []string{repo, other})
+	task := applyTaskWithChange(t, h, repo, "fixed\n")
+
+	body, _ := json.Marshal(map[string]string{"workspace": other, "branch": "main", "message": "port fix"})
+	w := callApplyTaskPatch(h, task.ID, string(body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	data, err := os.ReadFile(filepath.Join(other, "file.txt"))
+	if err != nil || string(data) != "fixed\n" {
+		t.Errorf("other checkout not advanced: file.txt = %q, %v", data, err)
+	}
+}
+
+func TestApplyTaskPatch_Conflict(t *testing.T) {
+	repo := setupRepo(t)
+	gitRun(t, repo, "checkout", "-q", "-b", "release")
+	if err := os.WriteFile(filepath.Join(repo, "file.txt"), []byte("diverged\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, repo, "commit", "-q", "-am", "diverge")
+	gitRun(t, repo, "checkout", "-q", "main")
+	h, _ := newTestHandlerWithWorkspacesFromRepo(t, repo)
+	task := applyTaskWithChange(t, h, repo, "fixed\n")
+
+	w := callApplyTaskPatch(h, task.ID, `{"branch":"release"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409; body %s", w.Code, w.Body.String())
+	}
+	if code := decodeErrorCode(t, w); code != codePatchConflict {
+		t.Errorf("code = %q, want %q", code, codePatchConflict)
+	}
+}
+
+func TestApplyTaskPatch_Validation(t *testing.T) {
+	repo := setupRepo(t)
+	h, _ := newTestHandlerWithWorkspacesFromRepo(t, repo)
+	task := applyTaskWithChange(t, h, repo, "fixed\n")
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"missing branch", `{}`, http.StatusUnprocessableEntity},
+		{"flag-like branch", `{"branch":"-f"}`, http.StatusUnprocessableEntity},
+		{"unknown branch", `{"branch":"nope"}`, http.StatusUnprocessableEntity},
+		{"unknown source", `{"branch":"main","source":"/elsewhere"}`, http.StatusUnprocessableEntity},
+		{"unconfigured workspace", `{"branch":"main","workspace":"/elsewhere"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := callApplyTaskPatch(h, task.ID, tt.body); w.Code != tt.want {
+				t.Errorf("status = %d, want %d; body %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestApplyTaskPatch_NoChanges(t *testing.T) {
+	repo := setupRepo(t)
+	h, _ := newTestHandlerWithWorkspacesFromRepo(t, repo)
+	task := applyTaskWithChange(t, h, repo, "initial\n")
+
+	if w := callApplyTaskPatch(h, task.ID, `{"branch":"main"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
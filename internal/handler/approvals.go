@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// Decisions accepted by DecideApproval.
+const (
+	approvalApprove = "approve"
+	approvalDeny    = "deny"
+)
+
+// DecideApproval approves or denies the approval request n the agent ended
+// its last turn with, then resumes the waiting task's session with the
+// decision and the optional note:
+//
+//	POST /api/tasks/{id}/approvals/{n}  {"decision": "approve"|"deny", "note"?}
+//
+// Only the pending request of a waiting task can be decided; one the user
+// passed over with plain feedback can not.
+func (h *Handler) DecideApproval(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	seq, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil || seq < 1 {
+		http.Error(w, "invalid approval request number", http.StatusBadRequest)
+		return
+	}
+	req, ok := httpjson.DecodeBody[struct {
+		Decision string `json:"decision"`
+		Note     string `json:"note"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	var decision store.ApprovalDecision
+	switch req.Decision {
+	case approvalApprove:
+		decision = store.ApprovalApproved
+	case approvalDeny:
+		decision = store.ApprovalDenied
+	default:
+		writeFieldError(w, "decision", "must be approve or deny (got %q)", req.Decision)
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+
+	// Hold promoteMu across the check, the decision, and the resume, as
+	// submitFeedback does, so auto-submit cannot move the task meanwhile.
+	promoteMu.Lock()
+	defer promoteMu.Unlock()
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if task.Status != store.TaskStatusWaiting {
+		http.Error(w, "task is not in waiting status", http.StatusBadRequest)
+		return
+	}
+	if seq > len(task.Approvals) {
+		writeError(w, store.ErrApprovalNotFound)
+		return
+	}
+	if p := task.PendingApproval(); p == nil || p.Seq != seq {
+		http.Error(w, fmt.Sprintf("approval request %d is not pending", seq), http.StatusConflict)
+		return
+	}
+	decided, err := s.DecideApproval(r.Context(), id, seq, decision, strings.TrimSpace(req.Note))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	message := prompts.ApprovalDecision(prompts.ApprovalDecisionData{
+		Approved: decided.Decision == store.ApprovalApproved,
+		Action:   decided.Action,
+		Command:  decided.Command,
+		Note:     decided.Note,
+	})
+	systemMessage := fmt.Sprintf("Approval request #%d %s.", decided.Seq, decided.Decision)
+	if err := h.resumeWaitingTaskWithFeedbackLocked(r.Context(), task, message, store.TriggerFeedback, systemMessage); err != nil {
+		writeError(w, err)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, decided)
+}
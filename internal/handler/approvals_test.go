@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// waitingApprovalTask creates a waiting task whose agent ended its turn with
+// approval request #1.
+func waitingApprovalTask(t *testing.T, h *Handler) *store.Task {
+	t.Helper()
+	ctx := context.Background()
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15, ApprovalGates: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.store.AddApprovalRequest(ctx, task.ID, store.ApprovalRequest{Turn: 1, Action: "drop the users table", Command: "psql -c 'drop table users'"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = h.store.UpdateTaskTurns(ctx, task.ID, 1)
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+	return task
+}
+
+func decideApproval(h *Handler, id uuid.UUID, n, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+id.String()+"/approvals/"+n, strings.NewReader(body))
+	req.SetPathValue("n", n)
+	w := httptest.NewRecorder()
+	h.DecideApproval(w, req, id)
+	return w
+}
+
+func TestDecideApproval_Approve(t *testing.T) {
+	h := newTestHandler(t)
+	task := waitingApprovalTask(t, h)
+
+	w := decideApproval(h, task.ID, "1", `{"decision":"approve","note":" staging only "}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got store.ApprovalRequest
+	_ = json.NewDecoder(w.Body).Decode(&got)
+	if got.Seq != 1 || got.Decision != store.ApprovalApproved || got.Note != "staging only" {
+		t.Errorf("decided = %+v", got)
+	}
+
+	ctx := context.Background()
+	updated, _ := h.store.GetTask(ctx, task.ID)
+	if updated.PendingApproval() != nil {
+		t.Error("request still pending after the decision")
+	}
+	if updated.Status != store.TaskStatusInProgress && updated.Status != store.TaskStatusFailed {
+		t.Errorf("expected in_progress or failed, got %s", updated.Status)
+	}
+	events, _ := h.store.GetEvents(ctx, task.ID)
+	var feedback string
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeFeedback {
+			feedback = string(ev.Data)
+		}
+	}
+	if !strings.Contains(feedback, "Approved:") || !strings.Contains(feedback, "staging only") {
+		t.Errorf("feedback event = %s, want the decision and note", feedback)
+	}
+}
+
+func TestDecideApproval_DecidedTwice(t *testing.T) {
+	h := newTestHandler(t)
+	task := waitingApprovalTask(t, h)
+	ctx := context.Background()
+	if _, err := h.store.DecideApproval(ctx, task.ID, 1, store.ApprovalDenied, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	w := decideApproval(h, task.ID, "1", `{"decision":"approve"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDecideApproval_Rejects(t *testing.T) {
+	h := newTestHandler(t)
+	task := waitingApprovalTask(t, h)
+	backlog, _ := h.store.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+
+	tests := []struct {
+		name string
+		id   uuid.UUID
+		n    string
+		body string
+		want int
+	}{
+		{"bad number", task.ID, "x", `{"decision":"approve"}`, http.StatusBadRequest},
+		{"bad decision", task.ID, "1", `{"decision":"maybe"}`, http.StatusBadRequest},
+		{"unknown task", uuid.New(), "1", `{"decision":"approve"}`, http.StatusNotFound},
+		{"not waiting", backlog.ID, "1", `{"decision":"approve"}`, http.StatusBadRequest},
+		{"unknown request", task.ID, "2", `{"decision":"deny"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := decideApproval(h, tt.id, tt.n, tt.body); w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDecideApproval_PassedOverRequest(t *testing.T) {
+	h := newTestHandler(t)
+	task := waitingApprovalTask(t, h)
+	// Plain feedback resumed the session and the agent stopped again later.
+	_ = h.store.UpdateTaskTurns(context.Background(), task.ID, 2)
+
+	if w := decideApproval(h, task.ID, "1", `{"decision":"approve"}`); w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateTask_ApprovalGatesOnlyInBacklog(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+
+	patch := func() int {
+		req := httptest.NewRequest(http.MethodPatch, "/api/tasks/"+task.ID.String(), strings.NewReader(`{"approval_gates":true}`))
+		w := httptest.NewRecorder()
+		h.UpdateTask(w, req, task.ID)
+		return w.Code
+	}
+	if code := patch(); code != http.StatusOK {
+		t.Fatalf("backlog: expected 200, got %d", code)
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); !got.ApprovalGates {
+		t.Error("approval_gates not set")
+	}
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+	if code := patch(); code != http.StatusBadRequest {
+		t.Errorf("waiting: expected 400, got %d", code)
+	}
+}
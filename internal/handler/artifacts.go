@@ -67,7 +67,7 @@ func (h *Handler) artifactsRoot(ctx context.Context) string {
 type ArtifactInfo struct {
 	Name     string    `json:"name"`     // base file name
 	Path     string    `json:"path"`     // slash path relative to the artifacts dir
-	URL      string    `json:"url"`      // ready-to-open URL: /artifact/<path>
+	URL      string    `json:"url"`      // ready-to-open URL: <base path>/artifact/<path>
 	Size     int64     `json:"size"`     // bytes
 	Modified time.Time `json:"modified"` // last modification time
 }
@@ -105,7 +105,7 @@ func (h *Handler) ListArtifacts(w http.ResponseWriter, r *http.Request) {
 			out = append(out, ArtifactInfo{
 				Name:     d.Name(),
 				Path:     rel,
-				URL:      (&url.URL{Path: "/artifact/" + rel}).String(),
+				URL:      (&url.URL{Path: BasePath(r) + "/artifact/" + rel}).String(),
 				Size:     size,
 				Modified: mod,
 			})
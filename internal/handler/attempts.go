@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// diffStat summarises a unified diff.
+type diffStat struct {
+	Files     int `json:"files"`
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// parseDiffStat counts files and changed lines in a combined task diff. File
+// headers ("+++"/"---") and workspace separators are not counted as changes.
+func parseDiffStat(diff string) diffStat {
+	var st diffStat
+	for line := range strings.SplitSeq(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			st.Files++
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+		case strings.HasPrefix(line, "+"):
+			st.Additions++
+		case strings.HasPrefix(line, "-"):
+			st.Deletions++
+		}
+	}
+	return st
+}
+
+// attemptView is one column of the attempt comparison: the prompt an attempt
+// ran with, what it produced, and what it cost.
+type attemptView struct {
+	Attempt         int                   `json:"attempt"`
+	Current         bool                  `json:"current,omitempty"`
+	RetiredAt       *time.Time            `json:"retired_at,omitempty"`
+	Prompt          string                `json:"prompt"`
+	Status          store.TaskStatus      `json:"status"`
+	Result          string                `json:"result,omitempty"`
+	FailureCategory store.FailureCategory `json:"failure_category,omitempty"`
+	SessionID       string                `json:"session_id,omitempty"`
+	Turns           int                   `json:"turns"`
+	CostUSD         float64               `json:"cost_usd"`
+	// DiffAvailable is false for attempts retired before diffs were archived;
+	// Diff and DiffStat are empty for them.
+	DiffAvailable bool     `json:"diff_available"`
+	Diff          string   `json:"diff"`
+	DiffStat      diffStat `json:"diff_stat"`
+}
+
+// archiveAttemptDiff stores diff as the archived diff of the attempt the most
+// recent ResetTaskForRetry retired. Failures are logged rather than returned:
+// the retry itself has already succeeded.
+func archiveAttemptDiff(ctx context.Context, s *store.Store, id uuid.UUID, diff string) {
+	task, err := s.GetTask(ctx, id)
+	if err != nil || len(task.RetryHistory) == 0 {
+		return
+	}
+	attempt := task.RetryHistory[len(task.RetryHistory)-1].Attempt
+	if err := s.SaveAttemptDiff(id, attempt, diff); err != nil {
+		logger.Handler.Warn("archive attempt diff failed", "task", id, "attempt", attempt, "error", err)
+	}
+}
+
+// TaskAttempts returns every recorded attempt of a task side by side, oldest
+// first, ending with the current attempt. Retired attempts carry the diff
+// archived when they were retried; the current attempt's diff is computed
+// live, so the columns can be compared to see whether a retry improved on
+// its predecessors.
+func (h *Handler) TaskAttempts(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	attempts := make([]attemptView, 0, len(task.RetryHistory)+1)
+	for i, rec := range task.RetryHistory {
+		num := rec.Attempt
+		if num == 0 {
+			// Unnumbered records predate attempt numbering; nothing was
+			// archived for them, so their position is only a label.
+			num = i + 1
+		}
+		v := attemptView{
+			Attempt:         num,
+			RetiredAt:       &rec.RetiredAt,
+			Prompt:          rec.Prompt,
+			Status:          rec.Status,
+			Result:          rec.Result,
+			FailureCategory: rec.FailureCategory,
+			SessionID:       rec.SessionID,
+			Turns:           rec.Turns,
+			CostUSD:         rec.CostUSD,
+		}
+		if rec.Attempt > 0 {
+			diff, found, err := s.LoadAttemptDiff(id, rec.Attempt)
+			if err != nil {
+				logger.Handler.Warn("load attempt diff failed", "task", id, "attempt", rec.Attempt, "error", err)
+			}
+			v.DiffAvailable = found
+			v.Diff = diff
+			v.DiffStat = parseDiffStat(diff)
+		}
+		attempts = append(attempts, v)
+	}
+
+	current := attemptView{
+		Attempt:         store.NextAttempt(task.RetryHistory),
+		Current:         true,
+		Prompt:          task.Prompt,
+		Status:          task.Status,
+		FailureCategory: task.FailureCategory,
+		Turns:           task.Turns,
+		CostUSD:         task.Usage.CostUSD,
+		DiffAvailable:   true,
+	}
+	if task.Result != nil {
+		current.Result = *task.Result
+	}
+	if task.SessionID != nil {
+		current.SessionID = *task.SessionID
+	}
+	current.Diff, _, _ = collectTaskDiff(r.Context(), s, task, false)
+	current.DiffStat = parseDiffStat(current.Diff)
+	attempts = append(attempts, current)
+
+	httpjson.Write(w, http.StatusOK, map[string]any{"attempts": attempts})
+}
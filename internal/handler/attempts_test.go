@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func callTaskAttempts(t *testing.T, h *Handler, id uuid.UUID) []attemptView {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.TaskAttempts(w, httptest.NewRequest(http.MethodGet, "/api/tasks/"+id.String()+"/attempts", nil), id)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Attempts []attemptView `json:"attempts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp.Attempts
+}
+
+func TestParseDiffStat(t *testing.T) {
+	diff := "=== repo ===\n" +
+		"diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n-old\n+new\n+more\n ctx\n" +
+		"diff --git a/y b/y\n--- /dev/null\n+++ b/y\n@@ -0,0 +1 @@\n+y\n"
+	got := parseDiffStat(diff)
+	if want := (diffStat{Files: 2, Additions: 3, Deletions: 1}); got != want {
+		t.Errorf("parseDiffStat = %+v, want %+v", got, want)
+	}
+	if got := parseDiffStat(""); got != (diffStat{}) {
+		t.Errorf("empty diff = %+v", got)
+	}
+}
+
+func TestTaskAttempts_NotFound(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.TaskAttempts(w, httptest.NewRequest(http.MethodGet, "/", nil), uuid.New())
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+// TestTaskAttempts_ArchivesDiffOnRetry verifies that retrying a task archives
+// the retired attempt's diff, so it can still be compared after the retry has
+// rewritten the worktree.
+func TestTaskAttempts_ArchivesDiffOnRetry(t *testing.T) {
+	repo := setupRepo(t)
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	wt := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-b", "task", wt, "HEAD")
+	_ = os.WriteFile(filepath.Join(wt, "file.txt"), []byte("first attempt\n"), 0644)
+
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "try one", Timeout: 5})
+	_ = h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: wt}, "task")
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusFailed)
+
+	w := patchTaskAction(t, h, task.ID, `{"status": "backlog", "prompt": "try two"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("retry: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	// The second attempt overwrites the same file in the reused worktree.
+	_ = os.WriteFile(filepath.Join(wt, "file.txt"), []byte("second attempt\n"), 0644)
+
+	attempts := callTaskAttempts(t, h, task.ID)
+	if len(attempts) != 2 {
+		t.Fatalf("got %d attempts, want 2", len(attempts))
+	}
+	first, second := attempts[0], attempts[1]
+	if first.Attempt != 1 || first.Current || first.Prompt != "try one" || first.Status != store.TaskStatusFailed {
+		t.Errorf("first attempt = %+v", first)
+	}
+	if !first.DiffAvailable || !strings.Contains(first.Diff, "+first attempt") || strings.Contains(first.Diff, "second attempt") {
+		t.Errorf("first attempt diff not archived correctly: %q", first.Diff)
+	}
+	if first.DiffStat.Files != 1 || first.DiffStat.Additions != 1 || first.DiffStat.Deletions != 1 {
+		t.Errorf("first attempt diff_stat = %+v", first.DiffStat)
+	}
+	if second.Attempt != 2 || !second.Current || second.Prompt != "try two" {
+		t.Errorf("current attempt = %+v", second)
+	}
+	if !strings.Contains(second.Diff, "+second attempt") {
+		t.Errorf("current attempt diff = %q", second.Diff)
+	}
+}
+
+func TestTaskAttempts_UnarchivedAttemptHasNoDiff(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 5})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusFailed)
+	_ = h.store.ResetTaskForRetry(ctx, task.ID, "p2", false)
+
+	attempts := callTaskAttempts(t, h, task.ID)
+	if len(attempts) != 2 {
+		t.Fatalf("got %d attempts, want 2", len(attempts))
+	}
+	if attempts[0].DiffAvailable {
+		t.Error("retired attempt without an archived diff reported diff_available")
+	}
+	if attempts[0].RetiredAt == nil || attempts[1].RetiredAt != nil {
+		t.Errorf("retired_at: first=%v current=%v", attempts[0].RetiredAt, attempts[1].RetiredAt)
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/auditlog"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// ExportAuditLog streams every event of every task in the active workspace
+// group, deleted tasks included, as a JSON Lines audit log in time order.
+// Each line is an auditlog.Record carrying the event's actor attribution.
+//
+// Query params:
+//   - since – RFC 3339 time; only events at or after it are exported
+//   - until – RFC 3339 time; only events before it are exported
+//   - types – comma-separated event types to include (default: all types)
+func (h *Handler) ExportAuditLog(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var since, until time.Time
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &since}, {"until", &until}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, p.name+" must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		*p.dst = t
+	}
+	var typeSet map[store.EventType]bool
+	if v := q.Get("types"); v != "" {
+		typeSet = make(map[store.EventType]bool)
+		for raw := range strings.SplitSeq(v, ",") {
+			t := strings.TrimSpace(raw)
+			if t == "" {
+				continue
+			}
+			et, ok := validEventTypes[t]
+			if !ok {
+				http.Error(w, "unknown event type: "+t, http.StatusBadRequest)
+				return
+			}
+			typeSet[et] = true
+		}
+	}
+
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	tasks, err := s.ListTasks(r.Context(), true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	deleted, err := s.ListDeletedTasks(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var events []store.TaskEvent
+	for _, t := range slices.Concat(tasks, deleted) {
+		taskEvents, err := s.GetEvents(r.Context(), t.ID)
+		if err != nil {
+			logger.Handler.Warn("audit export: read events", "task", t.ID, "error", err)
+			continue
+		}
+		for _, ev := range taskEvents {
+			if !since.IsZero() && ev.CreatedAt.Before(since) ||
+				!until.IsZero() && !ev.CreatedAt.Before(until) ||
+				typeSet != nil && !typeSet[ev.EventType] {
+				continue
+			}
+			events = append(events, ev)
+		}
+	}
+	slices.SortStableFunc(events, func(a, b store.TaskEvent) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.TaskID.String(), b.TaskID.String()), cmp.Compare(a.ID, b.ID))
+	})
+
+	key := h.activeDataKey()
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="wallfacer-audit-`+time.Now().UTC().Format("20060102")+`.jsonl"`)
+	enc := json.NewEncoder(w)
+	for _, ev := range events {
+		if err := enc.Encode(auditlog.Record{Workspace: key, TaskEvent: ev}); err != nil {
+			logger.Handler.Debug("audit export write failed", "error", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/auditlog"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func callExportAuditLog(h *Handler, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/audit-log"+query, nil)
+	w := httptest.NewRecorder()
+	h.ExportAuditLog(w, req)
+	return w
+}
+
+func decodeAuditRecords(t *testing.T, w *httptest.ResponseRecorder) []auditlog.Record {
+	t.Helper()
+	var recs []auditlog.Record
+	for line := range strings.SplitSeq(strings.TrimSpace(w.Body.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec auditlog.Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestExportAuditLog_AllTasksInTimeOrder(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	a, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "a", Timeout: 5})
+	b, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "b", Timeout: 5})
+	userCtx := store.WithActorPrincipal(ctx, "user-1", store.ActorUser)
+	_ = h.store.InsertEvent(ctx, a.ID, store.EventTypeSystem, map[string]string{"result": "first"})
+	_ = h.store.InsertEvent(userCtx, b.ID, store.EventTypeFeedback, map[string]string{"message": "second"})
+	_ = h.store.InsertEvent(ctx, a.ID, store.EventTypeSystem, map[string]string{"result": "third"})
+	if err := h.store.DeleteTask(ctx, b.ID, "cleanup"); err != nil {
+		t.Fatal(err)
+	}
+
+	w := callExportAuditLog(h, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var got []string
+	for _, rec := range decodeAuditRecords(t, w) {
+		var data map[string]string
+		_ = json.Unmarshal(rec.Data, &data)
+		if msg := data["result"] + data["message"]; msg != "" {
+			got = append(got, msg)
+		}
+		if rec.EventType == store.EventTypeFeedback && (rec.TaskID != b.ID || rec.ActorSub != "user-1") {
+			t.Errorf("feedback record = %+v, want task %s by user-1", rec, b.ID)
+		}
+	}
+	// The deleted task's events are exported too.
+	if want := "first,second,third"; strings.Join(got, ",") != want {
+		t.Errorf("exported %q, want %q", got, want)
+	}
+}
+
+func TestExportAuditLog_Filters(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "a", Timeout: 5})
+	_ = h.store.InsertEvent(ctx, task.ID, store.EventTypeSystem, map[string]string{"result": "x"})
+	_ = h.store.InsertEvent(ctx, task.ID, store.EventTypeFeedback, map[string]string{"message": "y"})
+
+	w := callExportAuditLog(h, "?types=feedback")
+	if recs := decodeAuditRecords(t, w); len(recs) != 1 || recs[0].EventType != store.EventTypeFeedback {
+		t.Errorf("types=feedback exported %+v", recs)
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if recs := decodeAuditRecords(t, callExportAuditLog(h, "?since="+future)); len(recs) != 0 {
+		t.Errorf("since=future exported %d records", len(recs))
+	}
+	if recs := decodeAuditRecords(t, callExportAuditLog(h, "?until="+future)); len(recs) != 2 {
+		t.Errorf("until=future exported %d records, want 2", len(recs))
+	}
+}
+
+func TestExportAuditLog_BadParams(t *testing.T) {
+	h := newTestHandler(t)
+	for _, q := range []string{"?since=yesterday", "?until=1", "?types=bogus"} {
+		if w := callExportAuditLog(h, q); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, w.Code)
+		}
+	}
+}
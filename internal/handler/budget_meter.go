@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// agentBudget is the budget meter reported as agent_budget by GET
+// /api/config: the spend of every open board against the daily and weekly
+// budgets, the recent burn rate, and when that rate runs out a budget.
+type agentBudget struct {
+	store.Spend
+	DailyBudgetUSD     float64    `json:"daily_budget_usd"`
+	WeeklyBudgetUSD    float64    `json:"weekly_budget_usd"`
+	DailyRemainingUSD  *float64   `json:"daily_remaining_usd,omitempty"`
+	WeeklyRemainingUSD *float64   `json:"weekly_remaining_usd,omitempty"`
+	ExhaustsAt         *time.Time `json:"exhausts_at,omitempty"`
+	ExhaustsBudget     string     `json:"exhausts_budget,omitempty"` // "daily" or "weekly"
+}
+
+// agentBudgetMeter totals the spend of every open board, so the meter
+// reflects the account rather than the board being viewed.
+func (h *Handler) agentBudgetMeter(ctx context.Context, daily, weekly float64) agentBudget {
+	now := time.Now()
+	stores := map[*store.Store]bool{}
+	if s, ok := h.currentStore(); ok && s != nil {
+		stores[s] = true
+	}
+	if h.workspace != nil {
+		for _, snap := range h.workspace.AllActiveSnapshots() {
+			if snap.Store != nil {
+				stores[snap.Store] = true
+			}
+		}
+	}
+	var spend store.Spend
+	for s := range stores {
+		if sp, err := s.Spend(ctx, now); err == nil {
+			spend.Add(sp)
+		}
+	}
+	return projectAgentBudget(spend, daily, weekly, now)
+}
+
+// projectAgentBudget fills in the remaining daily and weekly budgets and,
+// when spend continues at the burn rate, the earliest time one of them is
+// used up. The daily budget only counts when it runs out before midnight.
+func projectAgentBudget(spend store.Spend, daily, weekly float64, now time.Time) agentBudget {
+	b := agentBudget{Spend: spend, DailyBudgetUSD: daily, WeeklyBudgetUSD: weekly}
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	project := func(name string, budget, spent float64, limit time.Time) *float64 {
+		if budget <= 0 {
+			return nil
+		}
+		remaining := max(budget-spent, 0)
+		var at time.Time
+		switch {
+		case remaining == 0:
+			at = now
+		case spend.BurnUSDPerHour > 0:
+			at = now.Add(time.Duration(remaining / spend.BurnUSDPerHour * float64(time.Hour)))
+		default:
+			return &remaining
+		}
+		if (limit.IsZero() || at.Before(limit)) && (b.ExhaustsAt == nil || at.Before(*b.ExhaustsAt)) {
+			b.ExhaustsAt, b.ExhaustsBudget = &at, name
+		}
+		return &remaining
+	}
+	b.DailyRemainingUSD = project("daily", daily, spend.TodayUSD, midnight)
+	b.WeeklyRemainingUSD = project("weekly", weekly, spend.WeekUSD, time.Time{})
+	return b
+}
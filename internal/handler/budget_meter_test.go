@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestProjectAgentBudget(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	t.Run("no budgets", func(t *testing.T) {
+		b := projectAgentBudget(store.Spend{TodayUSD: 3, BurnUSDPerHour: 1}, 0, 0, now)
+		if b.DailyRemainingUSD != nil || b.WeeklyRemainingUSD != nil || b.ExhaustsAt != nil {
+			t.Errorf("budget = %+v, want no remaining or projection", b)
+		}
+	})
+
+	t.Run("weekly runs out first", func(t *testing.T) {
+		// At $2/h the $28 left of the daily budget lasts until midnight,
+		// while the $8 left of the weekly one runs out in 4h.
+		b := projectAgentBudget(store.Spend{TodayUSD: 2, WeekUSD: 42, BurnUSDPerHour: 2}, 30, 50, now)
+		if b.DailyRemainingUSD == nil || *b.DailyRemainingUSD != 28 {
+			t.Errorf("daily remaining = %v, want 28", b.DailyRemainingUSD)
+		}
+		if b.WeeklyRemainingUSD == nil || *b.WeeklyRemainingUSD != 8 {
+			t.Errorf("weekly remaining = %v, want 8", b.WeeklyRemainingUSD)
+		}
+		if b.ExhaustsAt == nil || !b.ExhaustsAt.Equal(now.Add(4*time.Hour)) || b.ExhaustsBudget != "weekly" {
+			t.Errorf("exhausts = %v %q, want weekly in 4h", b.ExhaustsAt, b.ExhaustsBudget)
+		}
+	})
+
+	t.Run("daily resets before running out", func(t *testing.T) {
+		b := projectAgentBudget(store.Spend{TodayUSD: 1, BurnUSDPerHour: 0.5}, 20, 0, now)
+		if b.ExhaustsAt != nil {
+			t.Errorf("exhausts at %v, want none: $19 at $0.5/h outlasts the day", b.ExhaustsAt)
+		}
+	})
+
+	t.Run("already exhausted", func(t *testing.T) {
+		b := projectAgentBudget(store.Spend{TodayUSD: 12}, 10, 0, now)
+		if *b.DailyRemainingUSD != 0 || b.ExhaustsAt == nil || !b.ExhaustsAt.Equal(now) || b.ExhaustsBudget != "daily" {
+			t.Errorf("budget = %+v, want daily exhausted now", b)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"latere.ai/x/wallfacer/internal/pkg/devcache"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/workspace"
+)
+
+// cacheEntry is one managed cache in the GET /api/caches response, annotated
+// with the workspace it belongs to. WorkspaceID and WorkspaceName are empty
+// for caches left behind by a workspace that no longer exists.
+type cacheEntry struct {
+	devcache.Usage
+	WorkspaceID   string `json:"workspace_id,omitempty"`
+	WorkspaceName string `json:"workspace_name,omitempty"`
+}
+
+// cacheKind describes one managed cache kind.
+type cacheKind struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Env         []string `json:"env"`
+}
+
+// cachesResponse is the JSON shape returned by GET /api/caches.
+type cachesResponse struct {
+	Kinds      []cacheKind  `json:"kinds"`
+	Caches     []cacheEntry `json:"caches"`
+	TotalBytes int64        `json:"total_bytes"`
+}
+
+// cacheWorkspace resolves the workspace owning the caches under key and
+// reports whether the request's principal may see them. Orphaned caches are
+// visible only to anonymous callers, who see everything.
+func (h *Handler) cacheWorkspace(r *http.Request, key string) (workspace.Workspace, bool) {
+	var ws workspace.Workspace
+	found := false
+	if h.workspace != nil {
+		ws, found, _ = h.workspace.WorkspaceByKey(key)
+	}
+	p := h.visibilityPrincipal(r)
+	if p == nil {
+		return ws, true
+	}
+	return ws, found && len(workspace.WorkspacesForPrincipal([]workspace.Workspace{ws}, p)) == 1
+}
+
+// ListCaches returns the disk usage of every managed package cache, one entry
+// per workspace and cache kind, with the total across them.
+func (h *Handler) ListCaches(w http.ResponseWriter, r *http.Request) {
+	usage, err := devcache.List(devcache.Root(h.configDir))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := cachesResponse{Kinds: make([]cacheKind, 0, len(devcache.Kinds)), Caches: []cacheEntry{}}
+	for _, k := range devcache.Kinds {
+		resp.Kinds = append(resp.Kinds, cacheKind(k))
+	}
+	for _, u := range usage {
+		ws, visible := h.cacheWorkspace(r, u.Key)
+		if !visible {
+			continue
+		}
+		resp.Caches = append(resp.Caches, cacheEntry{Usage: u, WorkspaceID: ws.ID, WorkspaceName: ws.Name})
+		resp.TotalBytes += u.Bytes
+	}
+	httpjson.Write(w, http.StatusOK, resp)
+}
+
+// ClearWorkspaceCaches removes every managed cache of one workspace. The
+// caches are recreated empty by the next task that runs in it.
+func (h *Handler) ClearWorkspaceCaches(w http.ResponseWriter, r *http.Request) {
+	h.clearCache(w, r, "")
+}
+
+// ClearCache removes one managed cache of one workspace.
+func (h *Handler) ClearCache(w http.ResponseWriter, r *http.Request) {
+	h.clearCache(w, r, r.PathValue("name"))
+}
+
+func (h *Handler) clearCache(w http.ResponseWriter, r *http.Request, name string) {
+	key := r.PathValue("workspace")
+	if _, visible := h.cacheWorkspace(r, key); !visible {
+		http.Error(w, "workspace not found", http.StatusNotFound)
+		return
+	}
+	if err := devcache.Clear(devcache.Root(h.configDir), key, name); err != nil {
+		switch {
+		case errors.Is(err, devcache.ErrUnknownKind):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, devcache.ErrInvalidKey):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/pkg/devcache"
+)
+
+// seedCaches creates the managed caches of the manager's active workspace
+// with one file in its npm cache, returning the workspace key.
+func seedCaches(t *testing.T, h *Handler) string {
+	t.Helper()
+	key := h.workspace.Snapshot().Key
+	if _, err := devcache.Env(devcache.Root(h.configDir), key); err != nil {
+		t.Fatal(err)
+	}
+	npm := devcache.Dir(devcache.Root(h.configDir), key, "npm")
+	if err := os.WriteFile(filepath.Join(npm, "pkg.tgz"), make([]byte, 64), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestListCaches(t *testing.T) {
+	h, _, _ := newTestHandlerWithRealWorkspaceManager(t)
+	key := seedCaches(t, h)
+
+	w := httptest.NewRecorder()
+	h.ListCaches(w, httptest.NewRequest(http.MethodGet, "/api/caches", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp cachesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kinds) != len(devcache.Kinds) {
+		t.Errorf("kinds = %d, want %d", len(resp.Kinds), len(devcache.Kinds))
+	}
+	if len(resp.Caches) != len(devcache.Kinds) {
+		t.Fatalf("caches = %d, want %d", len(resp.Caches), len(devcache.Kinds))
+	}
+	wsID := h.activeWorkspaceID()
+	for _, c := range resp.Caches {
+		if c.Key != key || c.WorkspaceID != wsID {
+			t.Errorf("cache %s belongs to %s/%s, want %s/%s", c.Name, c.Key, c.WorkspaceID, key, wsID)
+		}
+	}
+	if resp.TotalBytes != 64 {
+		t.Errorf("total_bytes = %d, want 64", resp.TotalBytes)
+	}
+}
+
+func TestListCaches_Empty(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.ListCaches(w, httptest.NewRequest(http.MethodGet, "/api/caches", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var resp cachesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Caches == nil || len(resp.Caches) != 0 || resp.TotalBytes != 0 {
+		t.Fatalf("response = %+v, want an empty cache list", resp)
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	h, _, _ := newTestHandlerWithRealWorkspaceManager(t)
+	key := seedCaches(t, h)
+	root := devcache.Root(h.configDir)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/caches/"+key+"/npm", nil)
+	req.SetPathValue("workspace", key)
+	req.SetPathValue("name", "npm")
+	w := httptest.NewRecorder()
+	h.ClearCache(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204: %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(devcache.Dir(root, key, "npm")); !os.IsNotExist(err) {
+		t.Errorf("npm cache not cleared: %v", err)
+	}
+	if _, err := os.Stat(devcache.Dir(root, key, "pip")); err != nil {
+		t.Errorf("pip cache removed with npm: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/caches/"+key, nil)
+	req.SetPathValue("workspace", key)
+	w = httptest.NewRecorder()
+	h.ClearWorkspaceCaches(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204: %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(root, key)); !os.IsNotExist(err) {
+		t.Errorf("workspace caches not cleared: %v", err)
+	}
+}
+
+func TestClearCache_BadInput(t *testing.T) {
+	h, _, _ := newTestHandlerWithRealWorkspaceManager(t)
+	key := seedCaches(t, h)
+
+	for _, tc := range []struct {
+		workspace, name string
+		want            int
+	}{
+		{key, "maven", http.StatusNotFound},
+		{"..", "npm", http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/caches/x/y", nil)
+		req.SetPathValue("workspace", tc.workspace)
+		req.SetPathValue("name", tc.name)
+		w := httptest.NewRecorder()
+		h.ClearCache(w, req)
+		if w.Code != tc.want {
+			t.Errorf("clear %s/%s: status = %d, want %d", tc.workspace, tc.name, w.Code, tc.want)
+		}
+	}
+}
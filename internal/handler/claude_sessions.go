@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"cmp"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/pkg/sanitize"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// claudeSessionDays is how far back ListClaudeSessions looks by default.
+const claudeSessionDays = 14
+
+// claudeSessionTitleLimit caps, in runes, a title derived from a session's
+// first prompt.
+const claudeSessionTitleLimit = 80
+
+// claudeSessionView is a Claude Code session as listed for import.
+type claudeSessionView struct {
+	harness.ClaudeSession
+	// Workspace is the current workspace the session was started in; empty
+	// when it was started outside all of them.
+	Workspace string `json:"workspace,omitempty"`
+	// TaskID is the task that already adopted the session, if any.
+	TaskID *uuid.UUID `json:"task_id,omitempty"`
+}
+
+// ListClaudeSessions lists Claude Code sessions started outside wallfacer,
+// read from the claude CLI's state directory ($CLAUDE_CONFIG_DIR or
+// ~/.claude), most recent first. Only sessions started in one of the
+// current workspaces are listed unless all=true.
+// Query params: days=N (default 14), all=true.
+func (h *Handler) ListClaudeSessions(w http.ResponseWriter, r *http.Request) {
+	days := claudeSessionDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			days = n
+		}
+	}
+	all := r.URL.Query().Get("all") == "true"
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	configDir := harness.ClaudeConfigDir()
+	if configDir == "" {
+		httpjson.Write(w, http.StatusOK, []claudeSessionView{})
+		return
+	}
+	sessions, err := harness.ListClaudeSessions(configDir, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	adopted, err := h.adoptedClaudeSessions(r, s)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	workspaces := h.currentWorkspaces()
+	out := make([]claudeSessionView, 0, len(sessions))
+	for _, cs := range sessions {
+		v := claudeSessionView{ClaudeSession: cs, Workspace: sessionWorkspace(cs.Cwd, workspaces)}
+		if v.Workspace == "" && !all {
+			continue
+		}
+		if id, ok := adopted[cs.ID]; ok {
+			v.TaskID = &id
+		}
+		out = append(out, v)
+	}
+	httpjson.Write(w, http.StatusOK, out)
+}
+
+// ImportClaudeSession adopts a Claude Code session started outside
+// wallfacer as a backlog task. The task keeps the session ID, so starting it
+// resumes the conversation with claude in a fresh task worktree; the prompt
+// tells the agent about the move and carries the optional instructions.
+// The title is the session's summary, else its first prompt.
+func (h *Handler) ImportClaudeSession(w http.ResponseWriter, r *http.Request) {
+	req, ok := httpjson.DecodeBody[struct {
+		SessionID    string `json:"session_id"`
+		Title        string `json:"title"`
+		Instructions string `json:"instructions"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	id := strings.TrimSpace(req.SessionID)
+	if id == "" {
+		http.Error(w, "session_id is required", http.StatusBadRequest)
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	cs, err := harness.FindClaudeSession(harness.ClaudeConfigDir(), id)
+	if errors.Is(err, harness.ErrClaudeSessionNotFound) {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sessionWorkspace(cs.Cwd, h.currentWorkspaces()) == "" {
+		http.Error(w, "session was started outside the current workspaces: "+cs.Cwd, http.StatusBadRequest)
+		return
+	}
+	adopted, err := h.adoptedClaudeSessions(r, s)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if taskID, ok := adopted[cs.ID]; ok {
+		http.Error(w, "session already imported as task "+taskID.String(), http.StatusConflict)
+		return
+	}
+
+	opts := store.TaskCreateOptions{
+		Prompt: prompts.SessionImport(prompts.SessionImportData{
+			Cwd:          cs.Cwd,
+			Instructions: strings.TrimSpace(req.Instructions),
+		}),
+		Sandbox: harness.Claude,
+	}
+	if p := principalFromRequest(r); p != nil {
+		opts.CreatedBy = p.Sub
+		opts.OrgID = p.OrgID
+	}
+	task, err := s.CreateTaskWithOptions(r.Context(), opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := s.UpdateTaskResult(r.Context(), task.ID, cs.LastMessage, cs.ID, "", cs.Turns); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	title := cmp.Or(strings.TrimSpace(req.Title), cs.Summary, sanitize.Truncate(firstLine(cs.Prompt), claudeSessionTitleLimit))
+	_ = s.UpdateTaskTitle(r.Context(), task.ID, title)
+
+	h.insertEventOrLog(r.Context(), task.ID, store.EventTypeStateChange,
+		store.NewStateChangeData("", store.TaskStatusBacklog, store.TriggerUser, nil))
+	h.insertEventOrLog(r.Context(), task.ID, store.EventTypeSystem, map[string]string{
+		"result": "Imported Claude Code session " + cs.ID + " started in " + cs.Cwd,
+	})
+
+	imported, err := s.GetTask(r.Context(), task.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	httpjson.Write(w, http.StatusCreated, imported)
+}
+
+// adoptedClaudeSessions maps the session IDs held by tasks, archived ones
+// included, to their task.
+func (h *Handler) adoptedClaudeSessions(r *http.Request, s *store.Store) (map[string]uuid.UUID, error) {
+	tasks, err := s.ListTasks(r.Context(), true)
+	if err != nil {
+		return nil, err
+	}
+	adopted := make(map[string]uuid.UUID)
+	for _, t := range tasks {
+		if t.SessionID != nil && *t.SessionID != "" {
+			adopted[*t.SessionID] = t.ID
+		}
+	}
+	return adopted, nil
+}
+
+// sessionWorkspace returns the workspace cwd lies in, or "" when none.
+func sessionWorkspace(cwd string, workspaces []string) string {
+	if cwd == "" {
+		return ""
+	}
+	for _, ws := range workspaces {
+		if _, err := isWithinWorkspace(cwd, ws); err == nil {
+			return ws
+		}
+	}
+	return ""
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	for line := range strings.SplitSeq(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// writeClaudeSession writes a two-turn session transcript started in cwd to
+// the Claude config dir the handler reads.
+func writeClaudeSession(t *testing.T, configDir, cwd, id string) {
+	t.Helper()
+	dir := harness.ClaudeProjectDir(configDir, cwd)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	lines := []string{
+		`{"type":"user","cwd":"` + cwd + `","message":{"content":"fix the flaky login test\nit times out on CI"}}`,
+		`{"type":"assistant","cwd":"` + cwd + `","message":{"content":[{"type":"text","text":"The wait is too short."}]}}`,
+		`{"type":"user","cwd":"` + cwd + `","message":{"content":"raise it"}}`,
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".jsonl"), []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func listClaudeSessions(t *testing.T, h *Handler, query string) []claudeSessionView {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ListClaudeSessions(w, httptest.NewRequest(http.MethodGet, "/api/tasks/claude-sessions"+query, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var out []claudeSessionView
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func callImportClaudeSession(h *Handler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ImportClaudeSession(w, httptest.NewRequest(http.MethodPost, "/api/tasks/claude-sessions/import", strings.NewReader(body)))
+	return w
+}
+
+func TestClaudeSessions_ListAndImport(t *testing.T) {
+	cfg := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", cfg)
+	h, ws := newTestHandlerWithWorkspaces(t)
+	sub := filepath.Join(ws, "pkg")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeClaudeSession(t, cfg, sub, "sess-in")
+	writeClaudeSession(t, cfg, t.TempDir(), "sess-out")
+
+	listed := listClaudeSessions(t, h, "")
+	if len(listed) != 1 || listed[0].ID != "sess-in" || listed[0].Workspace != ws || listed[0].TaskID != nil {
+		t.Fatalf("listed = %+v, want only sess-in in %s", listed, ws)
+	}
+	if all := listClaudeSessions(t, h, "?all=true"); len(all) != 2 {
+		t.Errorf("all=true listed %d sessions, want 2", len(all))
+	}
+
+	w := callImportClaudeSession(h, `{"session_id":"sess-in","instructions":"also fix the signup test"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("import: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var task store.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != store.TaskStatusBacklog || task.Sandbox != harness.Claude || task.FreshStart {
+		t.Errorf("task status = %s, sandbox = %s, fresh start = %v", task.Status, task.Sandbox, task.FreshStart)
+	}
+	if task.SessionID == nil || *task.SessionID != "sess-in" || task.Turns != 2 {
+		t.Errorf("task session = %v, turns = %d", task.SessionID, task.Turns)
+	}
+	if task.Title != "fix the flaky login test" {
+		t.Errorf("title = %q", task.Title)
+	}
+	if !strings.Contains(task.Prompt, sub) || !strings.Contains(task.Prompt, "also fix the signup test") {
+		t.Errorf("prompt = %q", task.Prompt)
+	}
+
+	if listed := listClaudeSessions(t, h, ""); len(listed) != 1 || listed[0].TaskID == nil || *listed[0].TaskID != task.ID {
+		t.Errorf("listed after import = %+v, want task_id %s", listed, task.ID)
+	}
+}
+
+func TestImportClaudeSession_Rejects(t *testing.T) {
+	cfg := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", cfg)
+	h, ws := newTestHandlerWithWorkspaces(t)
+	writeClaudeSession(t, cfg, ws, "sess-in")
+	writeClaudeSession(t, cfg, t.TempDir(), "sess-out")
+
+	cases := []struct {
+		body string
+		want int
+	}{
+		{`{"session_id":""}`, http.StatusBadRequest},
+		{`{"session_id":"missing"}`, http.StatusNotFound},
+		{`{"session_id":"../sess-in"}`, http.StatusBadRequest},
+		{`{"session_id":"sess-out"}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		if w := callImportClaudeSession(h, c.body); w.Code != c.want {
+			t.Errorf("%s: expected %d, got %d", c.body, c.want, w.Code)
+		}
+	}
+	if w := callImportClaudeSession(h, `{"session_id":"sess-in"}`); w.Code != http.StatusCreated {
+		t.Fatalf("first import: expected 201, got %d", w.Code)
+	}
+	if w := callImportClaudeSession(h, `{"session_id":"sess-in"}`); w.Code != http.StatusConflict {
+		t.Errorf("second import: expected 409, got %d", w.Code)
+	}
+}
@@ -147,7 +147,7 @@ func (h *Handler) activeGroupInfos(ctx context.Context) []activeGroupInfo {
 					switch t.Status {
 					case store.TaskStatusInProgress, store.TaskStatusCommitting:
 						info.InProgress++
-					case store.TaskStatusWaiting:
+					case store.TaskStatusWaiting, store.TaskStatusReview:
 						info.Waiting++
 					}
 				}
@@ -286,9 +286,17 @@ func (h *Handler) buildConfigResponse(ctx context.Context, cfg *envconfig.Config
 		"auth_enabled":              h.auth != nil,
 		"github":                    h.githubStatus(ctx),
 	}
+	if cfg != nil {
+		resp["agent_budget"] = h.agentBudgetMeter(ctx, cfg.DailyBudgetUSD, cfg.WeeklyBudgetUSD)
+	} else {
+		resp["agent_budget"] = h.agentBudgetMeter(ctx, 0, 0)
+	}
 	if h.authURL != "" {
 		resp["auth_url"] = h.authURL
 	}
+	if u := h.TunnelURL(); u != "" {
+		resp["tunnel_url"] = u
+	}
 	if cfg == nil {
 		return resp
 	}
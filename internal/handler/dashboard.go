@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// boardDashboard is one workspace's entry in the GET /api/dashboard response.
+type boardDashboard struct {
+	WorkspaceID string `json:"workspace_id"`
+	Name        string `json:"name"`
+	Viewed      bool   `json:"viewed"` // the workspace the board shows
+	boardSummary
+	// Running counts in-progress and committing tasks, each of which has an
+	// agent process (and any containers it launched) alive.
+	Running int `json:"running"`
+	// SpendUSD sums the cost of every task, archived ones included.
+	SpendUSD float64 `json:"spend_usd"`
+}
+
+// dashboardTotals aggregates the boards of a dashboard.
+type dashboardTotals struct {
+	Counts         map[store.TaskStatus]int `json:"counts"`
+	Running        int                      `json:"running"`
+	SpendUSD       float64                  `json:"spend_usd"`
+	AttentionTotal int                      `json:"attention_total"`
+	Stalled        int                      `json:"stalled"`
+}
+
+// board is a workspace whose task store can be read for the cross-board
+// views.
+type board struct {
+	id, name string
+	viewed   bool
+	store    *store.Store // nil for a workspace that has no tasks yet
+	release  func()
+}
+
+// visibleBoards returns the workspaces the caller may see, each with its
+// task store opened for reading; callers must release every board. Without
+// a workspace manager the viewed store is the only board. A workspace whose
+// store fails to load is logged and left out.
+func (h *Handler) visibleBoards(r *http.Request) ([]board, error) {
+	if h.workspace == nil {
+		s, ok := h.currentStore()
+		if !ok {
+			return nil, nil
+		}
+		return []board{{viewed: true, store: s, release: func() {}}}, nil
+	}
+	list, err := h.workspace.ListWorkspaces(h.visibilityPrincipal(r))
+	if err != nil {
+		return nil, err
+	}
+	viewed := h.activeWorkspaceID()
+	boards := make([]board, 0, len(list))
+	for _, ws := range list {
+		s, release, err := h.workspace.ReadStore(ws)
+		if err != nil {
+			logger.Handler.Warn("dashboard: read workspace store", "workspace", ws.ID, "error", err)
+			continue
+		}
+		boards = append(boards, board{id: ws.ID, name: ws.Name, viewed: ws.ID == viewed, store: s, release: release})
+	}
+	return boards, nil
+}
+
+// releaseBoards releases the stores visibleBoards opened.
+func releaseBoards(boards []board) {
+	for _, b := range boards {
+		b.release()
+	}
+}
+
+// GetDashboard returns one pane over every workspace the caller can see:
+// per board, the task counts, the tasks waiting on a human (as GET
+// /api/summary reports them for the viewed board), the running tasks, and
+// the spend, plus the totals across boards. ?limit= bounds each board's
+// attention list (default 20).
+//
+// Boards other than the viewed one and those with running tasks are loaded
+// from disk for the request, so the call costs more with many boards.
+func (h *Handler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	limit := defaultSummaryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	boards, err := h.visibleBoards(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer releaseBoards(boards)
+
+	out := make([]boardDashboard, 0, len(boards))
+	totals := dashboardTotals{Counts: make(map[store.TaskStatus]int)}
+	for _, b := range boards {
+		var tasks []store.Task
+		if b.store != nil {
+			tasks = b.store.TasksForPrincipal(r.Context(), principalFromRequest(r), true)
+		}
+		d := dashboardBoard(b, tasks, limit)
+		for status, n := range d.Counts {
+			totals.Counts[status] += n
+		}
+		totals.Running += d.Running
+		totals.SpendUSD += d.SpendUSD
+		totals.AttentionTotal += d.AttentionTotal
+		totals.Stalled += d.Stalled
+		out = append(out, d)
+	}
+	httpjson.Write(w, http.StatusOK, map[string]any{"boards": out, "totals": totals})
+}
+
+// dashboardBoard summarizes one board's tasks, archived ones included.
+func dashboardBoard(b board, tasks []store.Task, limit int) boardDashboard {
+	d := boardDashboard{WorkspaceID: b.id, Name: b.name, Viewed: b.viewed, boardSummary: summarizeBoard(tasks, limit)}
+	for i := range tasks {
+		t := &tasks[i]
+		d.SpendUSD += t.Usage.CostUSD
+		if t.Status == store.TaskStatusInProgress || t.Status == store.TaskStatusCommitting {
+			d.Running++
+		}
+	}
+	return d
+}
+
+// boardSearchResult is a task search match tagged with its workspace.
+type boardSearchResult struct {
+	WorkspaceID   string `json:"workspace_id"`
+	WorkspaceName string `json:"workspace_name"`
+	store.TaskSearchResult
+}
+
+// SearchAllBoards searches the tasks of every workspace the caller can see,
+// as SearchTasks does for the viewed board, and tags each match with its
+// workspace. Matches are ordered by workspace, viewed board first, and
+// capped at constants.MaxSearchResults overall.
+func (h *Handler) SearchAllBoards(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len([]rune(q)) < 2 {
+		http.Error(w, "q must be at least 2 characters", http.StatusBadRequest)
+		return
+	}
+	boards, err := h.visibleBoards(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer releaseBoards(boards)
+	slices.SortStableFunc(boards, func(a, b board) int {
+		switch {
+		case a.viewed == b.viewed:
+			return 0
+		case a.viewed:
+			return -1
+		}
+		return 1
+	})
+
+	results := []boardSearchResult{}
+	for _, b := range boards {
+		if b.store == nil {
+			continue
+		}
+		matches, err := searchVisible(r.Context(), b.store, principalFromRequest(r), q)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for _, m := range matches {
+			if len(results) == constants.MaxSearchResults {
+				httpjson.Write(w, http.StatusOK, results)
+				return
+			}
+			results = append(results, boardSearchResult{WorkspaceID: b.id, WorkspaceName: b.name, TaskSearchResult: m})
+		}
+	}
+	httpjson.Write(w, http.StatusOK, results)
+}
+
+// searchVisible runs s.SearchTasks and keeps the matches p may see.
+func searchVisible(ctx context.Context, s *store.Store, p *store.Principal, q string) ([]store.TaskSearchResult, error) {
+	matches, err := s.SearchTasks(ctx, q)
+	if err != nil || p == nil {
+		return matches, err
+	}
+	visible := make(map[uuid.UUID]bool)
+	for _, t := range s.TasksForPrincipal(ctx, p, true) {
+		visible[t.ID] = true
+	}
+	return slices.DeleteFunc(matches, func(m store.TaskSearchResult) bool { return !visible[m.ID] }), nil
+}
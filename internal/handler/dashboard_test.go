@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/workspace"
+)
+
+// twoBoards creates workspaces "alpha" and "beta", each with tasks, and
+// leaves beta viewed so alpha's store is loaded from disk.
+func twoBoards(t *testing.T) (h *Handler, alpha, beta workspace.Workspace) {
+	t.Helper()
+	h, wsMgr, _ := newTestHandlerWithRealWorkspaceManager(t)
+	h.workspace = wsMgr
+	ctx := context.Background()
+	board := func(name string) (workspace.Workspace, *store.Store) {
+		ws, err := wsMgr.Create(name, []string{t.TempDir()}, nil)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		snap, err := wsMgr.SwitchByID(ws.ID)
+		if err != nil {
+			t.Fatalf("SwitchByID: %v", err)
+		}
+		return ws, snap.Store
+	}
+
+	alpha, s := board("alpha")
+	failed, _ := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "fix the flaky migration test", Timeout: 15})
+	_ = s.ForceUpdateTaskStatus(ctx, failed.ID, store.TaskStatusFailed)
+	_ = s.AccumulateSubAgentUsage(ctx, failed.ID, store.SandboxActivityImplementation, store.TaskUsage{CostUSD: 1.5})
+
+	beta, s = board("beta")
+	waiting, _ := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "migrate the billing tables", Timeout: 15})
+	_ = s.ForceUpdateTaskStatus(ctx, waiting.ID, store.TaskStatusWaiting)
+	_ = s.AccumulateSubAgentUsage(ctx, waiting.ID, store.SandboxActivityImplementation, store.TaskUsage{CostUSD: 0.25})
+	running, _ := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "write docs", Timeout: 15})
+	_ = s.ForceUpdateTaskStatus(ctx, running.ID, store.TaskStatusInProgress)
+	return h, alpha, beta
+}
+
+func TestGetDashboard(t *testing.T) {
+	h, alpha, beta := twoBoards(t)
+
+	w := httptest.NewRecorder()
+	h.GetDashboard(w, httptest.NewRequest(http.MethodGet, "/api/dashboard", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Boards []boardDashboard `json:"boards"`
+		Totals dashboardTotals  `json:"totals"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	byID := map[string]boardDashboard{}
+	for _, b := range resp.Boards {
+		byID[b.WorkspaceID] = b
+	}
+	a, b := byID[alpha.ID], byID[beta.ID]
+	if a.Name != "alpha" || a.Viewed || a.AttentionTotal != 1 || a.NeedsAttention[0].Reason != "failed" || a.SpendUSD != 1.5 {
+		t.Errorf("alpha = %+v", a)
+	}
+	if !b.Viewed || b.Running != 1 || b.AttentionTotal != 1 || b.NeedsAttention[0].Reason != "awaiting_feedback" {
+		t.Errorf("beta = %+v", b)
+	}
+	if resp.Totals.AttentionTotal != 2 || resp.Totals.Running != 1 || resp.Totals.SpendUSD != 1.75 ||
+		resp.Totals.Counts[store.TaskStatusFailed] != 1 || resp.Totals.Counts[store.TaskStatusWaiting] != 1 {
+		t.Errorf("totals = %+v", resp.Totals)
+	}
+}
+
+func TestGetDashboard_RejectsBadLimit(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.GetDashboard(w, httptest.NewRequest(http.MethodGet, "/api/dashboard?limit=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestSearchAllBoards(t *testing.T) {
+	h, alpha, beta := twoBoards(t)
+
+	w := httptest.NewRecorder()
+	h.SearchAllBoards(w, httptest.NewRequest(http.MethodGet, "/api/search?q=migrat", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var results []boardSearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want one per board: %s", len(results), w.Body.String())
+	}
+	// The viewed board's matches come first.
+	if results[0].WorkspaceID != beta.ID || results[0].WorkspaceName != "beta" || results[1].WorkspaceID != alpha.ID {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestSearchAllBoards_RejectsShortQuery(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.SearchAllBoards(w, httptest.NewRequest(http.MethodGet, "/api/search?q=a", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
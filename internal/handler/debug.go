@@ -132,7 +132,7 @@ func (h *Handler) TaskBoardManifest(w http.ResponseWriter, r *http.Request, id u
 	}
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil || task == nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 	manifest, err := h.runner.GenerateBoardManifest(r.Context(), id, task.MountWorktrees)
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// diffBackend names the tool that produced a task diff. The unified `git
+// diff` output is always returned; a non-git backend adds a second,
+// structural rendering alongside it.
+type diffBackend string
+
+const (
+	diffBackendGit        diffBackend = "git"
+	diffBackendDifftastic diffBackend = "difftastic"
+)
+
+// difftasticBinary is the difftastic executable looked up on $PATH.
+// Overridden in tests.
+var difftasticBinary = "difft"
+
+// parseDiffBackend validates the ?backend= query parameter. An empty value
+// selects the git backend.
+func parseDiffBackend(s string) (diffBackend, bool) {
+	switch diffBackend(strings.TrimSpace(s)) {
+	case "", diffBackendGit:
+		return diffBackendGit, true
+	case diffBackendDifftastic:
+		return diffBackendDifftastic, true
+	}
+	return "", false
+}
+
+// difftasticAvailable reports whether the difftastic binary is on $PATH.
+func difftasticAvailable() bool {
+	_, err := exec.LookPath(difftasticBinary)
+	return err == nil
+}
+
+// difftasticExtDiff is the git external-diff command used for structural
+// diffs. Color is disabled so the output embeds cleanly in JSON, and inline
+// display keeps it readable in a single column.
+func difftasticExtDiff() string {
+	return difftasticBinary + " --color=never --display=inline"
+}
+
+// structuralDiff renders the same change set as diffWithUntracked through
+// difftastic, using git's external-diff hook so path filtering, rename
+// detection and untracked files behave identically to the git backend.
+func structuralDiff(ctx context.Context, worktreePath, baseRef string, excludes ...string) string {
+	return diffWithUntrackedExt(ctx, difftasticExtDiff(), worktreePath, baseRef, excludes...)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestParseDiffBackend(t *testing.T) {
+	tests := []struct {
+		in   string
+		want diffBackend
+		ok   bool
+	}{
+		{"", diffBackendGit, true},
+		{"git", diffBackendGit, true},
+		{"difftastic", diffBackendDifftastic, true},
+		{" difftastic ", diffBackendDifftastic, true},
+		{"semantic", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseDiffBackend(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseDiffBackend(%q) = (%q, %v), want (%q, %v)", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// withDifftastic points difftasticBinary at path for the duration of the test.
+func withDifftastic(t *testing.T, path string) {
+	t.Helper()
+	orig := difftasticBinary
+	difftasticBinary = path
+	t.Cleanup(func() { difftasticBinary = orig })
+}
+
+// fakeDifftastic writes a stand-in difftastic that prints a marker and the
+// new-file argument git passes to external diff commands (the fifth
+// positional argument, after path, old-file, old-hex and old-mode).
+func fakeDifftastic(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "difft")
+	script := "#!/bin/sh\nwhile [ \"${1#--}\" != \"$1\" ]; do shift; done\necho \"STRUCTURAL $5\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// setupDiffTask creates a task whose worktree has one committed and one
+// untracked change relative to main.
+func setupDiffTask(t *testing.T, h *Handler) uuid.UUID {
+	t.Helper()
+	repo := setupRepo(t)
+	wt := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-b", "task", wt, "HEAD")
+	_ = os.WriteFile(filepath.Join(wt, "committed.txt"), []byte("committed\n"), 0644)
+	gitRun(t, wt, "add", ".")
+	gitRun(t, wt, "commit", "-m", "task change")
+	_ = os.WriteFile(filepath.Join(wt, "untracked.txt"), []byte("untracked\n"), 0644)
+
+	task, _ := h.store.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "diff", Timeout: 5})
+	_ = h.store.UpdateTaskWorktrees(context.Background(), task.ID, map[string]string{repo: wt}, "task")
+	return task.ID
+}
+
+func callTaskDiffBackend(t *testing.T, h *Handler, id uuid.UUID, backend string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+id.String()+"/diff?backend="+backend, nil)
+	w := httptest.NewRecorder()
+	h.TaskDiff(w, req, id)
+	var resp map[string]any
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal diff response: %v", err)
+		}
+	}
+	return w.Code, resp
+}
+
+func TestTaskDiff_DifftasticBackend(t *testing.T) {
+	withDifftastic(t, fakeDifftastic(t))
+	h := newTestHandler(t)
+	id := setupDiffTask(t, h)
+
+	code, resp := callTaskDiffBackend(t, h, id, "difftastic")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if resp["backend"] != string(diffBackendDifftastic) {
+		t.Errorf("backend = %v, want difftastic", resp["backend"])
+	}
+	structural, _ := resp["structural_diff"].(string)
+	for _, name := range []string{"committed.txt", "untracked.txt"} {
+		if !strings.Contains(structural, "STRUCTURAL "+name) {
+			t.Errorf("structural_diff missing %s: %q", name, structural)
+		}
+	}
+	// The unified diff is still returned for clients that only render git output.
+	if diff, _ := resp["diff"].(string); !strings.Contains(diff, "diff --git a/committed.txt") {
+		t.Errorf("expected unified diff alongside structural diff, got %q", diff)
+	}
+}
+
+func TestTaskDiff_DifftasticUnavailableFallsBackToGit(t *testing.T) {
+	withDifftastic(t, filepath.Join(t.TempDir(), "missing-difft"))
+	h := newTestHandler(t)
+	id := setupDiffTask(t, h)
+
+	code, resp := callTaskDiffBackend(t, h, id, "difftastic")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if resp["backend"] != string(diffBackendGit) {
+		t.Errorf("backend = %v, want git fallback", resp["backend"])
+	}
+	if _, ok := resp["structural_diff"]; ok {
+		t.Error("structural_diff should be absent when falling back to git")
+	}
+}
+
+func TestTaskDiff_StructuralNotCached(t *testing.T) {
+	withDifftastic(t, fakeDifftastic(t))
+	h := newTestHandler(t)
+	id := setupDiffTask(t, h)
+
+	// A cached git response must not be served for a difftastic request.
+	if code, _ := callTaskDiffBackend(t, h, id, "git"); code != http.StatusOK {
+		t.Fatalf("git diff returned %d", code)
+	}
+	_, resp := callTaskDiffBackend(t, h, id, "difftastic")
+	if resp["backend"] != string(diffBackendDifftastic) {
+		t.Errorf("backend = %v, want difftastic after a cached git response", resp["backend"])
+	}
+	// And the structural response must not replace the cached git one.
+	_, resp = callTaskDiffBackend(t, h, id, "")
+	if resp["backend"] != string(diffBackendGit) {
+		t.Errorf("backend = %v, want git", resp["backend"])
+	}
+}
+
+func TestTaskDiff_UnknownBackend(t *testing.T) {
+	h := newTestHandler(t)
+	id := setupDiffTask(t, h)
+	if code, _ := callTaskDiffBackend(t, h, id, "bogus"); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown backend, got %d", code)
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/drafts"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+)
+
+// requireDrafts returns the draft store or writes 503 when the server has
+// no config directory to keep drafts in.
+func (h *Handler) requireDrafts(w http.ResponseWriter) (*drafts.Store, bool) {
+	if h.drafts == nil {
+		http.Error(w, "drafts are not available", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	return h.drafts, true
+}
+
+// ListDrafts returns the caller's unexpired prompt drafts on the viewed
+// board, most recently saved first.
+func (h *Handler) ListDrafts(w http.ResponseWriter, r *http.Request) {
+	ds, ok := h.requireDrafts(w)
+	if !ok {
+		return
+	}
+	httpjson.Write(w, http.StatusOK, ds.List(pushUser(r), h.currentBoardKey(), time.Now()))
+}
+
+// SaveDraft autosaves a prompt draft for the caller on the viewed board. A
+// body without id creates a draft (201); one with id replaces that draft
+// (200), or returns 404 when the caller has no such unexpired draft. Each
+// save renews the draft's expiry.
+func (h *Handler) SaveDraft(w http.ResponseWriter, r *http.Request) {
+	ds, ok := h.requireDrafts(w)
+	if !ok {
+		return
+	}
+	req, ok := httpjson.DecodeBody[struct {
+		ID     string          `json:"id"`
+		Prompt string          `json:"prompt"`
+		Fields json.RawMessage `json:"fields"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		http.Error(w, "prompt is required", http.StatusBadRequest)
+		return
+	}
+	d, err := ds.Save(drafts.Draft{
+		ID:     req.ID,
+		User:   pushUser(r),
+		Board:  h.currentBoardKey(),
+		Prompt: req.Prompt,
+		Fields: req.Fields,
+	}, time.Now())
+	if errors.Is(err, drafts.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "save draft: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	status := http.StatusOK
+	if req.ID == "" {
+		status = http.StatusCreated
+	}
+	httpjson.Write(w, status, d)
+}
+
+// DeleteDraft discards one of the caller's drafts, e.g. once its task is
+// created. Returns 404 when the caller owns no draft with that id.
+func (h *Handler) DeleteDraft(w http.ResponseWriter, r *http.Request) {
+	ds, ok := h.requireDrafts(w)
+	if !ok {
+		return
+	}
+	removed, err := ds.Delete(pushUser(r), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "delete draft: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "draft not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/drafts"
+)
+
+func TestDrafts_Lifecycle(t *testing.T) {
+	h := newTestHandler(t)
+
+	save := func(body string) (int, drafts.Draft) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.SaveDraft(w, httptest.NewRequest(http.MethodPost, "/api/drafts", strings.NewReader(body)))
+		var d drafts.Draft
+		_ = json.Unmarshal(w.Body.Bytes(), &d)
+		return w.Code, d
+	}
+	code, d := save(`{"prompt":"add a retry","fields":{"tags":["backend"]}}`)
+	if code != http.StatusCreated || d.ID == "" {
+		t.Fatalf("create: %d %+v", code, d)
+	}
+	if code, _ := save(`{"id":"` + d.ID + `","prompt":"add a retry to the uploader"}`); code != http.StatusOK {
+		t.Errorf("update: expected 200, got %d", code)
+	}
+	if code, _ := save(`{"id":"nope","prompt":"x"}`); code != http.StatusNotFound {
+		t.Errorf("unknown id: expected 404, got %d", code)
+	}
+	if code, _ := save(`{"prompt":"   "}`); code != http.StatusBadRequest {
+		t.Errorf("blank prompt: expected 400, got %d", code)
+	}
+
+	w := httptest.NewRecorder()
+	h.ListDrafts(w, httptest.NewRequest(http.MethodGet, "/api/drafts", nil))
+	var list []drafts.Draft
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Prompt != "add a retry to the uploader" || list[0].ExpiresAt.IsZero() {
+		t.Fatalf("list = %+v", list)
+	}
+
+	del := func() int {
+		req := httptest.NewRequest(http.MethodDelete, "/api/drafts/"+d.ID, nil)
+		req.SetPathValue("id", d.ID)
+		w := httptest.NewRecorder()
+		h.DeleteDraft(w, req)
+		return w.Code
+	}
+	if code := del(); code != http.StatusNoContent {
+		t.Errorf("delete: expected 204, got %d", code)
+	}
+	if code := del(); code != http.StatusNotFound {
+		t.Errorf("second delete: expected 404, got %d", code)
+	}
+}
+
+func TestDrafts_Unavailable(t *testing.T) {
+	h := newTestHandler(t)
+	h.drafts = nil
+	w := httptest.NewRecorder()
+	h.ListDrafts(w, httptest.NewRequest(http.MethodGet, "/api/drafts", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+}
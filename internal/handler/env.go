@@ -103,6 +103,7 @@ type envConfigResponse struct {
 	OpenAIAPIKey         string                               `json:"openai_api_key"` // masked
 	OpenAIBaseURL        string                               `json:"openai_base_url"`
 	CursorAPIKey         string                               `json:"cursor_api_key"` // masked
+	GeminiAPIKey         string                               `json:"gemini_api_key"` // masked
 	DefaultModel         string                               `json:"default_model"`
 	TitleModel           string                               `json:"title_model"`
 	CodexDefaultModel    string                               `json:"codex_default_model"`
@@ -146,6 +147,7 @@ type sandboxTestRequest struct {
 	OpenAIAPIKey      *string                              `json:"openai_api_key"`
 	OpenAIBaseURL     *string                              `json:"openai_base_url"`
 	CursorAPIKey      *string                              `json:"cursor_api_key"`
+	GeminiAPIKey      *string                              `json:"gemini_api_key"`
 	DefaultModel      *string                              `json:"default_model"`
 	TitleModel        *string                              `json:"title_model"`
 	CodexDefaultModel *string                              `json:"codex_default_model"`
@@ -203,6 +205,7 @@ func (h *Handler) GetEnvConfig(w http.ResponseWriter, _ *http.Request) {
 		OpenAIAPIKey:         envconfig.MaskToken(cfg.OpenAIAPIKey),
 		OpenAIBaseURL:        cfg.OpenAIBaseURL,
 		CursorAPIKey:         envconfig.MaskToken(cfg.CursorAPIKey),
+		GeminiAPIKey:         envconfig.MaskToken(cfg.GeminiAPIKey),
 		DefaultModel:         cfg.DefaultModel,
 		TitleModel:           cfg.TitleModel,
 		CodexDefaultModel:    cfg.CodexDefaultModel,
@@ -255,6 +258,9 @@ func (h *Handler) TestSandbox(w http.ResponseWriter, r *http.Request) {
 	if req.CursorAPIKey != nil && *req.CursorAPIKey == "" {
 		req.CursorAPIKey = nil
 	}
+	if req.GeminiAPIKey != nil && *req.GeminiAPIKey == "" {
+		req.GeminiAPIKey = nil
+	}
 
 	// Validate base URLs (same checks as regular env updates).
 	if req.BaseURL != nil && *req.BaseURL != "" {
@@ -405,6 +411,7 @@ func (h *Handler) buildTestEnvFile(req *sandboxTestRequest) (string, error) {
 		OpenAIAPIKey:      req.OpenAIAPIKey,
 		OpenAIBaseURL:     req.OpenAIBaseURL,
 		CursorAPIKey:      req.CursorAPIKey,
+		GeminiAPIKey:      req.GeminiAPIKey,
 		DefaultModel:      req.DefaultModel,
 		TitleModel:        req.TitleModel,
 		CodexDefaultModel: req.CodexDefaultModel,
@@ -440,6 +447,7 @@ func (h *Handler) UpdateEnvConfig(w http.ResponseWriter, r *http.Request) {
 		OpenAIAPIKey         *string                              `json:"openai_api_key"`
 		OpenAIBaseURL        *string                              `json:"openai_base_url"`
 		CursorAPIKey         *string                              `json:"cursor_api_key"`
+		GeminiAPIKey         *string                              `json:"gemini_api_key"`
 		DefaultModel         *string                              `json:"default_model"`
 		TitleModel           *string                              `json:"title_model"`
 		CodexDefaultModel    *string                              `json:"codex_default_model"`
@@ -476,6 +484,9 @@ func (h *Handler) UpdateEnvConfig(w http.ResponseWriter, r *http.Request) {
 	if req.CursorAPIKey != nil && *req.CursorAPIKey == "" {
 		req.CursorAPIKey = nil
 	}
+	if req.GeminiAPIKey != nil && *req.GeminiAPIKey == "" {
+		req.GeminiAPIKey = nil
+	}
 	// Convert max_parallel_tasks int to string for the env file.
 	var maxParallel *string
 	if req.MaxParallelTasks != nil {
@@ -633,6 +644,7 @@ func (h *Handler) UpdateEnvConfig(w http.ResponseWriter, r *http.Request) {
 		OpenAIAPIKey:         req.OpenAIAPIKey,
 		OpenAIBaseURL:        req.OpenAIBaseURL,
 		CursorAPIKey:         req.CursorAPIKey,
+		GeminiAPIKey:         req.GeminiAPIKey,
 		DefaultModel:         req.DefaultModel,
 		TitleModel:           req.TitleModel,
 		CodexDefaultModel:    req.CodexDefaultModel,
@@ -668,6 +680,8 @@ func (h *Handler) UpdateEnvConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.reprobeFailedAgent()
+
 	// Invalidate the cached parallel-limit values so the next call to
 	// maxConcurrentTasks / maxTestConcurrentTasks re-reads from the env file.
 	h.cachedMaxParallel.Invalidate()
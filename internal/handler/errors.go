@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// Error codes carried in the "code" field of JSON error bodies. Messages are
+// for people and may change; clients branch on the code.
+const (
+	codeTaskNotFound      = "task_not_found"
+	codeInvalidTransition = "invalid_transition"
+	codeStatusChanged     = "status_changed"
+	codeWorktreeBusy      = "worktree_busy"
+	codeMergeConflict     = "merge_conflict"
+	codePatchConflict     = "patch_conflict"
+	codeBranchDirty       = "branch_dirty"
+	codeValidationFailed  = "validation_failed"
+	codeAgentUnavailable  = "agent_unavailable"
+	codeApprovalNotFound  = "approval_not_found"
+	codeApprovalDecided   = "approval_decided"
+	codeNoInteractiveTurn = "no_interactive_turn"
+	codeDependenciesUnmet = "dependencies_unmet"
+)
+
+// errorCodes maps the sentinel errors of the store, gitutil, and runner
+// packages to an HTTP status and error code. Errors from the runner wrap these, so they
+// map the same way. The first match wins.
+var errorCodes = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{store.ErrTaskNotFound, http.StatusNotFound, codeTaskNotFound},
+	{store.ErrInvalidTransition, http.StatusConflict, codeInvalidTransition},
+	{store.ErrPatchStatusChanged, http.StatusConflict, codeStatusChanged},
+	{store.ErrApprovalNotFound, http.StatusNotFound, codeApprovalNotFound},
+	{store.ErrApprovalDecided, http.StatusConflict, codeApprovalDecided},
+	{store.ErrDependenciesUnmet, http.StatusConflict, codeDependenciesUnmet},
+	{gitutil.ErrWorktreeBusy, http.StatusConflict, codeWorktreeBusy},
+	{gitutil.ErrMergeConflict, http.StatusConflict, codeMergeConflict},
+	{gitutil.ErrPatchConflict, http.StatusConflict, codePatchConflict},
+	{gitutil.ErrBranchDirty, http.StatusConflict, codeBranchDirty},
+	{runner.ErrAgentUnavailable, http.StatusServiceUnavailable, codeAgentUnavailable},
+	{runner.ErrNoInteractiveTurn, http.StatusConflict, codeNoInteractiveTurn},
+}
+
+// errorResponse is the JSON error body. Code is empty for errors that have
+// none.
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+// statusError is an error that carries an HTTP status code. Used by
+// validateTaskWorktreesForCommit and similar helpers to propagate
+// structured errors that map directly to HTTP responses.
+type statusError struct {
+	code int
+	err  error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+
+func (e *statusError) Unwrap() error { return e.err }
+
+// httpErrorf creates a statusError with the given HTTP status code and
+// formatted message. A %w verb keeps the wrapped error's code.
+func httpErrorf(code int, format string, args ...any) error {
+	return &statusError{
+		code: code,
+		err:  fmt.Errorf(format, args...),
+	}
+}
+
+// errorStatus returns the HTTP status and error code for err. A statusError
+// keeps its own status; otherwise the status comes from errorCodes, and an
+// unrecognised error is a 500 with no code.
+func errorStatus(err error) (int, string) {
+	status, code := http.StatusInternalServerError, ""
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			status, code = c.status, c.code
+			break
+		}
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		status = se.code
+	}
+	return status, code
+}
+
+// writeError responds with err's message under the status and code
+// errorStatus assigns to it.
+func writeError(w http.ResponseWriter, err error) {
+	status, code := errorStatus(err)
+	httpjson.Write(w, status, errorResponse{Error: err.Error(), Code: code})
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// decodeErrorCode decodes a JSON error body and returns its code.
+func decodeErrorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp errorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode error body %q: %v", w.Body.String(), err)
+	}
+	if resp.Error == "" {
+		t.Error("error message is empty")
+	}
+	return resp.Code
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"task not found", fmt.Errorf("%w: %s", store.ErrTaskNotFound, uuid.New()), http.StatusNotFound, codeTaskNotFound},
+		{"invalid transition", fmt.Errorf("update: %w", store.ErrInvalidTransition), http.StatusConflict, codeInvalidTransition},
+		{"status changed", store.ErrPatchStatusChanged, http.StatusConflict, codeStatusChanged},
+		{"worktree busy", fmt.Errorf("rebase/merge: %w", gitutil.ErrWorktreeBusy), http.StatusConflict, codeWorktreeBusy},
+		{"merge conflict", &gitutil.ConflictError{WorktreePath: "/wt"}, http.StatusConflict, codeMergeConflict},
+		{"patch conflict", &gitutil.PatchConflictError{Branch: "release"}, http.StatusConflict, codePatchConflict},
+		{"branch dirty", fmt.Errorf("%w: main", gitutil.ErrBranchDirty), http.StatusConflict, codeBranchDirty},
+		{"status error", httpErrorf(http.StatusBadRequest, "bad"), http.StatusBadRequest, ""},
+		{"status error keeps code", httpErrorf(http.StatusGone, "%w", store.ErrTaskNotFound), http.StatusGone, codeTaskNotFound},
+		{"unknown", errors.New("disk full"), http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := errorStatus(tt.err)
+			if status != tt.status || code != tt.code {
+				t.Errorf("errorStatus = (%d, %q), want (%d, %q)", status, code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeError(w, fmt.Errorf("%w: abc", store.ErrTaskNotFound))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var resp errorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != "task not found: abc" || resp.Code != codeTaskNotFound {
+		t.Errorf("body = %+v", resp)
+	}
+}
+
+// TestGetTaskEndpoints_UnknownTaskIsCoded checks that a task endpoint
+// reports an unknown ID as a coded 404 rather than a bare string.
+func TestGetTaskEndpoints_UnknownTaskIsCoded(t *testing.T) {
+	h := newTestHandler(t)
+	id := uuid.New()
+	w := httptest.NewRecorder()
+	h.GetTaskSpans(w, httptest.NewRequest(http.MethodGet, "/api/tasks/"+id.String()+"/spans", nil), id)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+	if code := decodeErrorCode(t, w); code != codeTaskNotFound {
+		t.Errorf("code = %q, want %q", code, codeTaskNotFound)
+	}
+}
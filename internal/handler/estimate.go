@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// EstimateTask runs the effort-estimation agent for a backlog task and
+// returns the stored estimate. Estimates are only meaningful before a run,
+// so other statuses are rejected; re-estimating a backlog task replaces the
+// previous estimate. The call blocks until the agent finishes (bounded by
+// the runner's 120-second deadline).
+func (h *Handler) EstimateTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if task.Status != store.TaskStatusBacklog {
+		http.Error(w, "only backlog tasks can be estimated", http.StatusBadRequest)
+		return
+	}
+	if task.IsRoutine() {
+		http.Error(w, "routine tasks cannot be estimated", http.StatusBadRequest)
+		return
+	}
+
+	est, err := h.runner.EstimateTask(r.Context(), id)
+	if err != nil {
+		logger.Handler.Warn("estimate task failed", "task", id, "error", err)
+		http.Error(w, "estimate failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, est)
+}
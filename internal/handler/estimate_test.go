@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func callEstimateTask(h *Handler, id uuid.UUID) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+id.String()+"/estimate", nil)
+	w := httptest.NewRecorder()
+	h.EstimateTask(w, req, id)
+	return w
+}
+
+func TestEstimateTask_ReturnsEstimate(t *testing.T) {
+	var gotID uuid.UUID
+	m := &runner.MockRunner{
+		EstimateTaskFn: func(_ context.Context, id uuid.UUID) (store.TaskEstimate, error) {
+			gotID = id
+			return store.TaskEstimate{Turns: 5, Tokens: 40000, Minutes: 8, Risk: store.EstimateRiskLow}, nil
+		},
+	}
+	h, s := newTestHandlerWithMockRunner(t, m)
+	task, _ := s.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "estimate", Timeout: 15})
+
+	w := callEstimateTask(h, task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotID != task.ID {
+		t.Errorf("runner called with %s, want %s", gotID, task.ID)
+	}
+	var est store.TaskEstimate
+	if err := json.Unmarshal(w.Body.Bytes(), &est); err != nil {
+		t.Fatal(err)
+	}
+	if est.Turns != 5 || est.Risk != store.EstimateRiskLow {
+		t.Errorf("estimate = %+v, want turns=5 risk=low", est)
+	}
+}
+
+func TestEstimateTask_RejectsNonBacklog(t *testing.T) {
+	called := false
+	m := &runner.MockRunner{
+		EstimateTaskFn: func(context.Context, uuid.UUID) (store.TaskEstimate, error) {
+			called = true
+			return store.TaskEstimate{}, nil
+		},
+	}
+	h, s := newTestHandlerWithMockRunner(t, m)
+	ctx := context.Background()
+	task, _ := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "running", Timeout: 15})
+	_ = s.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress)
+
+	if w := callEstimateTask(h, task.ID); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for in_progress task, got %d", w.Code)
+	}
+	if called {
+		t.Error("runner should not be called for a non-backlog task")
+	}
+}
+
+func TestEstimateTask_NotFound(t *testing.T) {
+	h, _ := newTestHandlerWithMockRunner(t, &runner.MockRunner{})
+	if w := callEstimateTask(h, uuid.New()); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestEstimateTask_RunnerError(t *testing.T) {
+	m := &runner.MockRunner{
+		EstimateTaskFn: func(context.Context, uuid.UUID) (store.TaskEstimate, error) {
+			return store.TaskEstimate{}, errors.New("agent unavailable")
+		},
+	}
+	h, s := newTestHandlerWithMockRunner(t, m)
+	task, _ := s.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "estimate", Timeout: 15})
+
+	if w := callEstimateTask(h, task.ID); w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 on runner error, got %d", w.Code)
+	}
+}
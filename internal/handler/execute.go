@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -97,25 +98,6 @@ func (h *Handler) restoreTaskWorktreesForCommit(ctx context.Context, s *store.St
 	return updated, nil
 }
 
-// statusError is an error that carries an HTTP status code. Used by
-// validateTaskWorktreesForCommit and similar helpers to propagate
-// structured errors that map directly to HTTP responses.
-type statusError struct {
-	code int
-	msg  string
-}
-
-func (e *statusError) Error() string { return e.msg }
-
-// httpErrorf creates a statusError with the given HTTP status code and
-// formatted message.
-func httpErrorf(code int, format string, args ...any) error {
-	return &statusError{
-		code: code,
-		msg:  fmt.Sprintf(format, args...),
-	}
-}
-
 // SubmitFeedback resumes a waiting task with user-provided feedback.
 func (h *Handler) SubmitFeedback(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
 	req, ok := httpjson.DecodeBody[struct {
@@ -133,47 +115,44 @@ func (h *Handler) SubmitFeedback(w http.ResponseWriter, r *http.Request, id uuid
 	if !ok {
 		return
 	}
+	if err := h.submitFeedback(r.Context(), s, id, req.Message); err != nil {
+		writeError(w, err)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, map[string]string{"status": "resumed"})
+}
 
+// submitFeedback resumes the waiting task id with message. An unknown task
+// wraps store.ErrTaskNotFound; a task not waiting is a statusError.
+func (h *Handler) submitFeedback(ctx context.Context, s *store.Store, id uuid.UUID, message string) error {
 	// Acquire promoteMu BEFORE reading/modifying the task to prevent races
 	// with tryAutoSubmit (which also holds promoteMu in Phase 2). Without
 	// this, auto-submit can transition the task to committing between our
 	// status check and the UpdateTaskStatus call, causing the feedback to
 	// fail while the commit pipeline cleans up the worktree.
 	promoteMu.Lock()
+	defer promoteMu.Unlock()
 
-	task, err := s.GetTask(r.Context(), id)
+	task, err := s.GetTask(ctx, id)
 	if err != nil {
-		promoteMu.Unlock()
-		http.Error(w, "task not found", http.StatusNotFound)
-		return
+		return err
 	}
 	if task.Status != store.TaskStatusWaiting {
-		promoteMu.Unlock()
-		http.Error(w, "task is not in waiting status", http.StatusBadRequest)
-		return
+		return httpErrorf(http.StatusBadRequest, "task is not in waiting status")
 	}
 
 	// Any further implementation work invalidates prior test verification.
 	// This must happen AFTER the status check to avoid clearing test state
 	// when the transition will fail (e.g. task already moved to committing).
-	if err := s.UpdateTaskTestRun(r.Context(), id, false, ""); err != nil {
-		promoteMu.Unlock()
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if err := s.UpdateTaskTestRun(ctx, id, false, ""); err != nil {
+		return err
 	}
 
 	// Submitting feedback to a waiting task is always allowed even when max
 	// concurrent tasks is reached. The task was previously in_progress and
 	// paused for user input — blocking it would leave it stuck when autoimplement
 	// fills all slots.
-	if err := h.resumeWaitingTaskWithFeedbackLocked(r.Context(), task, req.Message, store.TriggerFeedback, ""); err != nil {
-		promoteMu.Unlock()
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	promoteMu.Unlock()
-
-	httpjson.Write(w, http.StatusOK, map[string]string{"status": "resumed"})
+	return h.resumeWaitingTaskWithFeedbackLocked(ctx, task, message, store.TriggerFeedback, "")
 }
 
 // resumeWaitingTaskWithFeedbackLocked transitions a waiting task back to
@@ -261,6 +240,16 @@ func (h *Handler) runCommitTransition(s *store.Store, taskID uuid.UUID, sessionI
 			}
 		}
 		if err := h.runner.Commit(taskID, sessionID); err != nil {
+			if errors.Is(err, runnerpkg.ErrAwaitingApproval) {
+				// Not a failure: the changes are committed in the
+				// worktree and the merge waits for ApproveTask.
+				if statusErr := s.UpdateTaskStatus(bgCtx, taskID, store.TaskStatusReview); statusErr != nil {
+					logger.Handler.Error("update task status to review after commit", "task", taskID, "error", statusErr)
+				}
+				h.insertEventOrLogTo(bgCtx, s, taskID, store.EventTypeStateChange,
+					store.NewStateChangeData(store.TaskStatusCommitting, store.TaskStatusReview, trigger, nil))
+				return
+			}
 			if runnerpkg.IsCommitMessageGenerationError(err) {
 				if waitErr := s.ForceUpdateTaskStatus(bgCtx, taskID, store.TaskStatusWaiting); waitErr == nil {
 					h.insertEventOrLogTo(bgCtx, s, taskID, store.EventTypeStateChange,
@@ -315,7 +304,7 @@ func (h *Handler) CompleteTask(w http.ResponseWriter, r *http.Request, id uuid.U
 
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 	if task.Status != store.TaskStatusWaiting {
@@ -328,22 +317,18 @@ func (h *Handler) CompleteTask(w http.ResponseWriter, r *http.Request, id uuid.U
 	if task.SessionID != nil && *task.SessionID != "" {
 		task, err = h.restoreTaskWorktreesForCommit(r.Context(), s, task)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		if err := validateTaskWorktreesForCommit(task); err != nil {
-			if se, ok := err.(*statusError); ok {
-				http.Error(w, se.msg, se.code)
-				return
-			}
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		// Transition to "committing" while auto-commit runs in the background.
 		// Use ForceUpdateTaskStatus since waiting → committing is a legitimate
 		// user-initiated flow not in the automated state machine.
 		if err := s.ForceUpdateTaskStatus(r.Context(), id, store.TaskStatusCommitting); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		h.insertEventOrLogTo(r.Context(), s, id, store.EventTypeStateChange,
@@ -353,7 +338,7 @@ func (h *Handler) CompleteTask(w http.ResponseWriter, r *http.Request, id uuid.U
 		// No session to commit — go directly to done (bypasses state machine
 		// since waiting→done is deliberately blocked to protect the commit pipeline).
 		if err := s.ForceUpdateTaskStatus(r.Context(), id, store.TaskStatusDone); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		h.insertEventOrLogTo(r.Context(), s, id, store.EventTypeStateChange,
@@ -363,11 +348,173 @@ func (h *Handler) CompleteTask(w http.ResponseWriter, r *http.Request, id uuid.U
 	httpjson.Write(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
+// CommitTask runs the commit pipeline for a done task that skipped it (see
+// store.Task.SkipCommit), merging the changes left in its worktree exactly
+// as completing the task would have. The flag is cleared first, so the
+// worktree is cleaned up with the rest of the pipeline.
+func (h *Handler) CommitTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+
+	// Hold promoteMu across the read-check-write, as CompleteTask does, so
+	// two requests cannot both start a pipeline.
+	promoteMu.Lock()
+	defer promoteMu.Unlock()
+
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !task.RetainsWorktree() {
+		http.Error(w, "only done tasks that skipped auto-commit can be committed", http.StatusBadRequest)
+		return
+	}
+	if err := validateTaskWorktreesForCommit(task); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// done → committing is not in the state machine: it only happens here.
+	if err := s.ForceUpdateTaskStatus(r.Context(), id, store.TaskStatusCommitting); err != nil {
+		writeError(w, err)
+		return
+	}
+	off := false
+	if err := s.PatchTask(r.Context(), id, store.TaskPatch{SkipCommit: &off}); err != nil {
+		if revertErr := s.ForceUpdateTaskStatus(r.Context(), id, store.TaskStatusDone); revertErr != nil {
+			logger.Handler.Error("commit task: revert to done failed", "task", id, "error", revertErr)
+		}
+		writeError(w, err)
+		return
+	}
+	h.diffCache.invalidate(id)
+	h.insertEventOrLogTo(r.Context(), s, id, store.EventTypeStateChange,
+		store.NewStateChangeData(store.TaskStatusDone, store.TaskStatusCommitting, store.TriggerUser, nil))
+
+	sessionID := ""
+	if task.SessionID != nil {
+		sessionID = *task.SessionID
+	}
+	h.runCommitTransition(s, id, sessionID, store.TriggerUser, "commit failed: ")
+	httpjson.Write(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// ApproveTask merges a task held in review (see store.Task.RequireApproval).
+// The approval is recorded and the commit pipeline resumes at rebase with
+// the changes that were committed before the hold.
+func (h *Handler) ApproveTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+
+	// Hold promoteMu across the read-check-write, as CompleteTask does, so
+	// two approvals cannot both start a pipeline.
+	promoteMu.Lock()
+	defer promoteMu.Unlock()
+
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if task.Status != store.TaskStatusReview {
+		writeError(w, fmt.Errorf("%w: only tasks in review can be approved, not %s tasks", store.ErrInvalidTransition, task.Status))
+		return
+	}
+	task, err = h.restoreTaskWorktreesForCommit(r.Context(), s, task)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := validateTaskWorktreesForCommit(task); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.ApproveTaskMerge(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.UpdateTaskStatus(r.Context(), id, store.TaskStatusCommitting); err != nil {
+		writeError(w, err)
+		return
+	}
+	h.diffCache.invalidate(id)
+	h.insertEventOrLogTo(r.Context(), s, id, store.EventTypeStateChange,
+		store.NewStateChangeData(store.TaskStatusReview, store.TaskStatusCommitting, store.TriggerUser, nil))
+
+	sessionID := ""
+	if task.SessionID != nil {
+		sessionID = *task.SessionID
+	}
+	h.runCommitTransition(s, id, sessionID, store.TriggerUser, "commit failed: ")
+	httpjson.Write(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// RejectTask aborts the merge of a task held in review and returns it to
+// waiting. Its changes stay committed on the task branch. Feedback, when
+// given, resumes the agent's session with it so the next completion is
+// reviewed again.
+func (h *Handler) RejectTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	req, ok := httpjson.DecodeOptionalBody[struct {
+		Feedback string `json:"feedback"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+
+	promoteMu.Lock()
+	defer promoteMu.Unlock()
+
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if task.Status != store.TaskStatusReview {
+		writeError(w, fmt.Errorf("%w: only tasks in review can be rejected, not %s tasks", store.ErrInvalidTransition, task.Status))
+		return
+	}
+	if err := s.UpdateTaskStatus(r.Context(), id, store.TaskStatusWaiting); err != nil {
+		writeError(w, err)
+		return
+	}
+	h.insertEventOrLogTo(r.Context(), s, id, store.EventTypeStateChange,
+		store.NewStateChangeData(store.TaskStatusReview, store.TaskStatusWaiting, store.TriggerUser, nil))
+
+	feedback := strings.TrimSpace(req.Feedback)
+	if feedback == "" || task.SessionID == nil || *task.SessionID == "" {
+		result := "Merge rejected in review."
+		if feedback != "" {
+			result += " Feedback: " + feedback
+		}
+		h.insertEventOrLogTo(r.Context(), s, id, store.EventTypeSystem, map[string]string{
+			"result": result,
+		})
+		httpjson.Write(w, http.StatusOK, map[string]string{"status": "waiting"})
+		return
+	}
+	task.Status = store.TaskStatusWaiting
+	if err := h.resumeWaitingTaskWithFeedbackLocked(r.Context(), task, feedback, store.TriggerUser, "Merge rejected in review."); err != nil {
+		writeError(w, err)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, map[string]string{"status": "resumed"})
+}
+
 // cancellableStatuses lists the statuses a task may be cancelled from.
 var cancellableStatuses = map[store.TaskStatus]bool{
 	store.TaskStatusBacklog:    true,
 	store.TaskStatusInProgress: true,
 	store.TaskStatusWaiting:    true,
+	store.TaskStatusReview:     true,
 	store.TaskStatusFailed:     true,
 }
 
@@ -566,7 +713,7 @@ func (h *Handler) ResumeTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 	if task.Status != store.TaskStatusFailed && task.Status != store.TaskStatusWaiting {
@@ -587,7 +734,7 @@ func (h *Handler) ResumeTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 	promoteMu.Lock()
 	if err := s.ResumeTask(r.Context(), id, req.Timeout); err != nil {
 		promoteMu.Unlock()
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	promoteMu.Unlock()
@@ -614,7 +761,7 @@ func (h *Handler) ArchiveAllDone(w http.ResponseWriter, r *http.Request) {
 	}
 	archived, err := s.ArchiveAllDone(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	for _, id := range archived {
@@ -699,7 +846,7 @@ func (h *Handler) TestTask(w http.ResponseWriter, r *http.Request, id uuid.UUID)
 
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 	if task.Status != store.TaskStatusWaiting {
@@ -730,13 +877,13 @@ func (h *Handler) TestTask(w http.ResponseWriter, r *http.Request, id uuid.UUID)
 
 	// Mark task as a test run and clear any previous verdict.
 	if err := s.UpdateTaskTestRun(r.Context(), id, true, ""); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	// Transition waiting → in_progress.
 	if err := s.UpdateTaskStatus(r.Context(), id, store.TaskStatusInProgress); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	h.insertEventOrLog(r.Context(), id, store.EventTypeStateChange,
@@ -764,7 +911,7 @@ func (h *Handler) ReviewTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 	if task.Status != store.TaskStatusWaiting {
@@ -851,13 +998,30 @@ func generateWorktreeDiff(worktreePaths map[string]string) string {
 
 // SyncTask rebases task worktrees onto the latest default branch without merging.
 func (h *Handler) SyncTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	h.startWorktreeRebase(w, r, id, "syncing", h.runner.SyncWorktreesBackground)
+}
+
+// RebaseTask rebases task worktrees onto the default branch step by step
+// (see runner.IncrementalRebase), so a task that fell far behind while it ran
+// resolves upstream conflicts in small increments instead of all at once in
+// the commit pipeline. Preconditions and the response shape match SyncTask.
+func (h *Handler) RebaseTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	h.startWorktreeRebase(w, r, id, "rebasing", h.runner.IncrementalRebaseBackground)
+}
+
+// startWorktreeRebase validates that the task is a waiting or failed task
+// with worktrees, moves it to in_progress, and launches the given runner
+// rebase in the background. statusLabel is echoed in the response body.
+func (h *Handler) startWorktreeRebase(w http.ResponseWriter, r *http.Request, id uuid.UUID, statusLabel string,
+	launch func(taskID uuid.UUID, sessionID string, prevStatus store.TaskStatus, onDone ...func()),
+) {
 	s, ok := h.requireStore(w)
 	if !ok {
 		return
 	}
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 	if task.Status == store.TaskStatusInProgress {
@@ -883,7 +1047,7 @@ func (h *Handler) SyncTask(w http.ResponseWriter, r *http.Request, id uuid.UUID)
 	promoteMu.Lock()
 	if err := s.ForceUpdateTaskStatus(r.Context(), id, store.TaskStatusInProgress); err != nil {
 		promoteMu.Unlock()
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	promoteMu.Unlock()
@@ -899,11 +1063,11 @@ func (h *Handler) SyncTask(w http.ResponseWriter, r *http.Request, id uuid.UUID)
 		h.commitsBehindCache.invalidate(repoPath, worktreePath)
 	}
 	worktreePaths := task.WorktreePaths
-	h.runner.SyncWorktreesBackground(id, sessionID, oldStatus, func() {
+	launch(id, sessionID, oldStatus, func() {
 		h.diffCache.invalidate(id)
 		for repoPath, worktreePath := range worktreePaths {
 			h.commitsBehindCache.invalidate(repoPath, worktreePath)
 		}
 	})
-	httpjson.Write(w, http.StatusOK, map[string]string{"status": "syncing"})
+	httpjson.Write(w, http.StatusOK, map[string]string{"status": statusLabel})
 }
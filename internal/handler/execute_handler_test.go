@@ -324,6 +324,171 @@ func TestCompleteTask_WithSession_GoesToCommitting(t *testing.T) {
 	}
 }
 
+func TestCommitTask_CommitsRetainedWorktree(t *testing.T) {
+	m := &runner.MockRunner{}
+	h, _ := newTestHandlerWithMockRunner(t, m)
+	ctx := context.Background()
+	repo := setupRepo(t)
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "explore", Timeout: 15, SkipCommit: true})
+	_ = h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: repo}, "main")
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusDone)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/commit", nil)
+	w := httptest.NewRecorder()
+	h.CommitTask(w, req, task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	waitForCond(t, 2*time.Second, "task back to done", func() bool {
+		got, _ := h.store.GetTask(ctx, task.ID)
+		return got.Status == store.TaskStatusDone && len(m.CommitCallsSnapshot()) == 1
+	})
+	got, _ := h.store.GetTask(ctx, task.ID)
+	if got.SkipCommit {
+		t.Error("skip_commit should be cleared once the task is committed")
+	}
+
+	// The worktree is no longer retained, so a second commit is rejected.
+	w = httptest.NewRecorder()
+	h.CommitTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/commit", nil), task.ID)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("second commit: expected 400, got %d", w.Code)
+	}
+}
+
+func TestCommitTask_RejectsTaskWithoutRetainedWorktree(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15, SkipCommit: true})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/commit", nil)
+	w := httptest.NewRecorder()
+	h.CommitTask(w, req, task.ID)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCompleteTask_RequireApprovalHoldsInReview(t *testing.T) {
+	m := &runner.MockRunner{CommitErr: runner.ErrAwaitingApproval}
+	h, _ := newTestHandlerWithMockRunner(t, m)
+	ctx := context.Background()
+	repo := setupRepo(t)
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15, RequireApproval: true})
+	_ = h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: repo}, "main")
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+	setTaskSessionID(t, h, task.ID, "sess-123")
+
+	w := httptest.NewRecorder()
+	h.CompleteTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/done", nil), task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("done: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	waitForCond(t, 2*time.Second, "task held in review", func() bool {
+		got, _ := h.store.GetTask(ctx, task.ID)
+		return got.Status == store.TaskStatusReview
+	})
+
+	// Approval finishes the pipeline.
+	m.CommitErr = nil
+	w = httptest.NewRecorder()
+	h.ApproveTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/approve", nil), task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("approve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	waitForCond(t, 2*time.Second, "approved task done", func() bool {
+		got, _ := h.store.GetTask(ctx, task.ID)
+		return got.Status == store.TaskStatusDone && len(m.CommitCallsSnapshot()) == 2
+	})
+	if got, _ := h.store.GetTask(ctx, task.ID); got.ApprovedAt == nil {
+		t.Error("approved_at not recorded")
+	}
+}
+
+func TestApproveTask_RejectsTaskNotInReview(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15, RequireApproval: true})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+
+	for action, call := range map[string]func(http.ResponseWriter, *http.Request, uuid.UUID){
+		"approve": h.ApproveTask,
+		"reject":  h.RejectTask,
+	} {
+		w := httptest.NewRecorder()
+		call(w, httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/"+action, nil), task.ID)
+		var resp errorResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusConflict || resp.Code != codeInvalidTransition {
+			t.Errorf("%s: got %d %+v, want 409 with code %s", action, w.Code, resp, codeInvalidTransition)
+		}
+	}
+}
+
+func TestRejectTask_ReturnsToWaiting(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15, RequireApproval: true})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusReview)
+
+	if w := patchTask(h, task.ID, `{"status":"done"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("PATCH out of review: expected 400, got %d", w.Code)
+	}
+
+	body := strings.NewReader(`{"feedback":"split the migration into its own commit"}`)
+	w := httptest.NewRecorder()
+	h.RejectTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/reject", body), task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reject: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	got, _ := h.store.GetTask(ctx, task.ID)
+	if got.Status != store.TaskStatusWaiting || got.ApprovedAt != nil {
+		t.Errorf("after reject: status %s, approved_at %v", got.Status, got.ApprovedAt)
+	}
+}
+
+func TestUpdateTask_SkipCommit(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+
+	if w := patchTask(h, task.ID, `{"skip_commit":true}`); w.Code != http.StatusOK {
+		t.Fatalf("waiting: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); !got.SkipCommit {
+		t.Fatal("skip_commit not set")
+	}
+
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusDone)
+	if w := patchTask(h, task.ID, `{"skip_commit":false}`); w.Code != http.StatusBadRequest {
+		t.Errorf("done: expected 400, got %d", w.Code)
+	}
+}
+
+func TestUpdateTask_MergeMode(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+
+	if w := patchTask(h, task.ID, `{"merge_mode":"squash"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown mode: expected 400, got %d", w.Code)
+	}
+	if w := patchTask(h, task.ID, `{"merge_mode":"pull_request"}`); w.Code != http.StatusOK {
+		t.Fatalf("backlog: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.MergeMode != store.MergeModePullRequest {
+		t.Fatalf("merge_mode = %q", got.MergeMode)
+	}
+
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusDone)
+	if w := patchTask(h, task.ID, `{"merge_mode":"merge"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("done: expected 400, got %d", w.Code)
+	}
+}
+
 func TestCompleteTask_WithSessionRejectsMissingWorktrees(t *testing.T) {
 	h := newTestHandler(t)
 	ctx := context.Background()
@@ -401,8 +566,8 @@ func TestWaitingToDone_PATCHBlocked(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.UpdateTask(w, req, task.ID)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for waiting→done via PATCH, got %d: %s", w.Code, w.Body.String())
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for waiting→done via PATCH, got %d: %s", w.Code, w.Body.String())
 	}
 
 	// Task must still be waiting.
@@ -556,8 +721,8 @@ func TestCancelTask_RejectsDone(t *testing.T) {
 
 	w := patchTaskAction(t, h, task.ID, `{"status":"cancelled"}`)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for done task, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for done task, got %d", w.Code)
 	}
 }
 
@@ -931,8 +1096,8 @@ func TestArchiveTask_RejectsNonDone(t *testing.T) {
 
 	w := patchTaskAction(t, h, task.ID, `{"archived":true}`)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for backlog task, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for backlog task, got %d", w.Code)
 	}
 }
 
@@ -1178,6 +1343,57 @@ func TestSyncTask_SucceedsAtFullCapacity(t *testing.T) {
 	}
 }
 
+// --- RebaseTask ---
+
+func TestRebaseTask_NotFound(t *testing.T) {
+	h := newTestHandler(t)
+	id := uuid.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+id.String()+"/rebase", nil)
+	w := httptest.NewRecorder()
+	h.RebaseTask(w, req, id)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestRebaseTask_RejectsBacklog(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/rebase", nil)
+	w := httptest.NewRecorder()
+	h.RebaseTask(w, req, task.ID)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for backlog task, got %d", w.Code)
+	}
+}
+
+func TestRebaseTask_WaitingWithWorktrees(t *testing.T) {
+	repo := setupRepo(t)
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+	_ = h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: repo}, "main")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/rebase", nil)
+	w := httptest.NewRecorder()
+	h.RebaseTask(w, req, task.ID)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]string
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp["status"] != "rebasing" {
+		t.Errorf("expected status=rebasing, got %q", resp["status"])
+	}
+}
+
 // --- runCommitTransition ---
 
 // TestRunCommitTransition_SuccessWithMock verifies that runCommitTransition
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"cmp"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// experimentArm is one column of the experiment comparison.
+type experimentArm struct {
+	TaskID       uuid.UUID            `json:"task_id"`
+	Role         store.ExperimentRole `json:"role"`
+	Label        string               `json:"label,omitempty"`
+	Status       store.TaskStatus     `json:"status"`
+	Sandbox      harness.ID           `json:"sandbox,omitempty"`
+	Model        string               `json:"model,omitempty"`
+	Instructions string               `json:"instructions,omitempty"`
+	Turns        int                  `json:"turns"`
+	CostUSD      float64              `json:"cost_usd"`
+	InputTokens  int                  `json:"input_tokens"`
+	OutputTokens int                  `json:"output_tokens"`
+	Diff         store.ExperimentDiff `json:"diff,omitzero"`
+	Verdict      string               `json:"verdict"`
+}
+
+// experimentResponse is the JSON shape of GET and POST
+// /api/tasks/{id}/experiment.
+type experimentResponse struct {
+	Primary experimentArm `json:"primary"`
+	Shadow  experimentArm `json:"shadow"`
+}
+
+// experimentVerdict condenses an arm's outcome into one word: its test
+// verdict ("pass" or "fail") once tested, "failed" or "cancelled" when it
+// did not finish, "finished" once its changes were measured, and "pending"
+// before that.
+func experimentVerdict(t *store.Task) string {
+	switch {
+	case t.Status == store.TaskStatusFailed, t.Status == store.TaskStatusCancelled:
+		return string(t.Status)
+	case t.LastTestResult != "":
+		return t.LastTestResult
+	case t.Experiment != nil && !t.Experiment.Diff.MeasuredAt.IsZero():
+		return "finished"
+	default:
+		return "pending"
+	}
+}
+
+func newExperimentArm(t *store.Task) experimentArm {
+	arm := experimentArm{
+		TaskID:       t.ID,
+		Status:       t.Status,
+		Sandbox:      t.Sandbox,
+		Turns:        t.Turns,
+		CostUSD:      t.Usage.CostUSD,
+		InputTokens:  t.Usage.InputTokens,
+		OutputTokens: t.Usage.OutputTokens,
+		Verdict:      experimentVerdict(t),
+	}
+	if t.ModelOverride != nil {
+		arm.Model = *t.ModelOverride
+	}
+	if env := t.Environment; env != nil {
+		if env.ModelName != "" {
+			arm.Model = env.ModelName
+		}
+		if env.Sandbox != "" {
+			arm.Sandbox = env.Sandbox
+		}
+	}
+	if e := t.Experiment; e != nil {
+		arm.Role, arm.Label, arm.Instructions, arm.Diff = e.Role, e.Label, e.Instructions, e.Diff
+	}
+	return arm
+}
+
+// CreateExperiment turns a backlog task into the primary arm of a dark-launch
+// experiment: it creates a shadow copy that differs in sandbox, model, or
+// extra instructions. The shadow starts together with the primary, and when
+// its agent finishes its changes are measured and discarded; only the
+// primary is ever merged.
+func (h *Handler) CreateExperiment(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	req, ok := httpjson.DecodeBody[struct {
+		Label        string      `json:"label"`
+		ShadowLabel  string      `json:"shadow_label"`
+		Sandbox      *harness.ID `json:"sandbox,omitempty"`
+		Model        string      `json:"model"`
+		Instructions string      `json:"instructions"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	primary, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	switch {
+	case primary.Status != store.TaskStatusBacklog:
+		http.Error(w, "only backlog tasks can start an experiment", http.StatusBadRequest)
+		return
+	case primary.IsRoutine():
+		http.Error(w, "routine tasks cannot run experiments", http.StatusBadRequest)
+		return
+	case primary.Experiment != nil:
+		http.Error(w, "task is already part of an experiment", http.StatusConflict)
+		return
+	}
+
+	sandbox := primary.Sandbox
+	if req.Sandbox != nil {
+		if !req.Sandbox.IsValid() {
+			writeFieldError(w, "sandbox", "unknown sandbox %q", *req.Sandbox)
+			return
+		}
+		sandbox = *req.Sandbox
+	}
+	if err := h.validateRequestedSandboxes(sandbox, nil); err != nil {
+		writeFieldError(w, "sandbox", "%v", err)
+		return
+	}
+	primaryModel := ""
+	if primary.ModelOverride != nil {
+		primaryModel = *primary.ModelOverride
+	}
+	model := cmp.Or(strings.TrimSpace(req.Model), primaryModel)
+	instructions := strings.TrimSpace(req.Instructions)
+	if sandbox == primary.Sandbox && model == primaryModel && instructions == "" {
+		http.Error(w, "the shadow must differ from the primary in sandbox, model, or instructions", http.StatusBadRequest)
+		return
+	}
+
+	shadow, err := s.CreateTaskWithOptions(r.Context(), store.TaskCreateOptions{
+		Prompt:             primary.Prompt,
+		Criteria:           primary.Criteria,
+		Timeout:            primary.Timeout,
+		MountWorktrees:     primary.MountWorktrees,
+		Kind:               primary.Kind,
+		FlowID:             primary.FlowID,
+		Tags:               primary.Tags,
+		Sandbox:            sandbox,
+		SandboxByActivity:  primary.SandboxByActivity,
+		MaxCostUSD:         primary.MaxCostUSD,
+		MaxInputTokens:     primary.MaxInputTokens,
+		ModelOverride:      model,
+		CustomPassPatterns: primary.CustomPassPatterns,
+		CustomFailPatterns: primary.CustomFailPatterns,
+		ResearchDomains:    primary.ResearchDomains,
+		CreatedBy:          primary.CreatedBy,
+		OrgID:              primary.OrgID,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if primary.ExecutionPrompt != "" {
+		_ = s.UpdateTaskExecutionPrompt(r.Context(), shadow.ID, primary.ExecutionPrompt)
+	}
+	shadowLabel := strings.TrimSpace(req.ShadowLabel)
+	if shadowLabel == "" {
+		shadowLabel = "shadow"
+	}
+	if primary.Title != "" {
+		_ = s.UpdateTaskTitle(r.Context(), shadow.ID, primary.Title+" ("+shadowLabel+")")
+	}
+	if err := s.SetTaskExperiment(r.Context(), shadow.ID, store.Experiment{
+		Role:         store.ExperimentRoleShadow,
+		PeerID:       primary.ID,
+		Label:        shadowLabel,
+		Instructions: instructions,
+	}); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.SetTaskExperiment(r.Context(), primary.ID, store.Experiment{
+		Role:   store.ExperimentRolePrimary,
+		PeerID: shadow.ID,
+		Label:  strings.TrimSpace(req.Label),
+	}); err != nil {
+		writeError(w, err)
+		return
+	}
+	h.insertEventOrLog(r.Context(), shadow.ID, store.EventTypeStateChange,
+		store.NewStateChangeData("", store.TaskStatusBacklog, store.TriggerUser, nil))
+	h.writeExperiment(w, r, s, primary.ID, http.StatusCreated)
+}
+
+// GetExperiment returns the side-by-side metrics of both arms of the
+// experiment the task belongs to; either arm's ID may be given.
+func (h *Handler) GetExperiment(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	h.writeExperiment(w, r, s, id, http.StatusOK)
+}
+
+func (h *Handler) writeExperiment(w http.ResponseWriter, r *http.Request, s *store.Store, id uuid.UUID, status int) {
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if task.Experiment == nil {
+		http.Error(w, "task is not part of an experiment", http.StatusNotFound)
+		return
+	}
+	peer, err := s.GetTask(r.Context(), task.Experiment.PeerID)
+	if err != nil {
+		http.Error(w, "experiment peer task not found", http.StatusNotFound)
+		return
+	}
+	primary, shadow := task, peer
+	if task.IsShadow() {
+		primary, shadow = peer, task
+	}
+	httpjson.Write(w, status, experimentResponse{
+		Primary: newExperimentArm(primary),
+		Shadow:  newExperimentArm(shadow),
+	})
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func callCreateExperiment(h *Handler, id uuid.UUID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+id.String()+"/experiment", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.CreateExperiment(w, req, id)
+	return w
+}
+
+func TestCreateExperiment_CreatesLinkedShadow(t *testing.T) {
+	h, s := newTestHandlerWithMockRunner(t, &runner.MockRunner{})
+	ctx := context.Background()
+	primary, _ := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "add caching", Timeout: 15, Tags: []string{"perf"}})
+
+	w := callCreateExperiment(h, primary.ID, `{"label":"baseline","shadow_label":"tdd","model":"other-model","instructions":"Write the test first."}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp experimentResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Primary.TaskID != primary.ID || resp.Primary.Label != "baseline" || resp.Primary.Role != store.ExperimentRolePrimary {
+		t.Errorf("primary arm = %+v", resp.Primary)
+	}
+	if resp.Shadow.Label != "tdd" || resp.Shadow.Model != "other-model" || resp.Shadow.Instructions != "Write the test first." {
+		t.Errorf("shadow arm = %+v", resp.Shadow)
+	}
+	if resp.Shadow.Verdict != "pending" {
+		t.Errorf("shadow verdict = %q, want pending", resp.Shadow.Verdict)
+	}
+
+	shadow, err := s.GetTask(ctx, resp.Shadow.TaskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !shadow.IsShadow() || shadow.Experiment.PeerID != primary.ID || shadow.Prompt != "add caching" || !shadow.HasTag("perf") {
+		t.Errorf("shadow task = %+v", shadow)
+	}
+	if shadow.Status != store.TaskStatusBacklog {
+		t.Errorf("shadow status = %s, want backlog", shadow.Status)
+	}
+
+	// Either arm's ID resolves the same comparison.
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+shadow.ID.String()+"/experiment", nil)
+	gw := httptest.NewRecorder()
+	h.GetExperiment(gw, req, shadow.ID)
+	var got experimentResponse
+	if err := json.Unmarshal(gw.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Primary.TaskID != primary.ID || got.Shadow.TaskID != shadow.ID {
+		t.Errorf("GetExperiment from shadow = %+v", got)
+	}
+}
+
+func TestCreateExperiment_Rejects(t *testing.T) {
+	h, s := newTestHandlerWithMockRunner(t, &runner.MockRunner{})
+	ctx := context.Background()
+	backlog, _ := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "a", Timeout: 15})
+	running, _ := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "b", Timeout: 15})
+	_ = s.UpdateTaskStatus(ctx, running.ID, store.TaskStatusInProgress)
+
+	if w := callCreateExperiment(h, backlog.ID, `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("identical shadow: expected 400, got %d", w.Code)
+	}
+	if w := callCreateExperiment(h, running.ID, `{"instructions":"x"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("non-backlog task: expected 400, got %d", w.Code)
+	}
+	if w := callCreateExperiment(h, backlog.ID, `{"instructions":"x"}`); w.Code != http.StatusCreated {
+		t.Fatalf("first experiment: expected 201, got %d", w.Code)
+	}
+	if w := callCreateExperiment(h, backlog.ID, `{"instructions":"y"}`); w.Code != http.StatusConflict {
+		t.Errorf("second experiment: expected 409, got %d", w.Code)
+	}
+}
+
+func TestGetExperiment_NotAnExperiment(t *testing.T) {
+	h, s := newTestHandlerWithMockRunner(t, &runner.MockRunner{})
+	task, _ := s.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "plain", Timeout: 15})
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+task.ID.String()+"/experiment", nil)
+	w := httptest.NewRecorder()
+	h.GetExperiment(w, req, task.ID)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
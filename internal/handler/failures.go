@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/failuresig"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// defaultFailureWindowDays is the correlation window when ?days= is absent.
+const defaultFailureWindowDays = 7
+
+// errorPhasePrefixes infers the pipeline phase of an error event that does
+// not record one from the prefix the commit pipeline gives its message.
+var errorPhasePrefixes = []struct{ prefix, phase string }{
+	{"stage/commit failed", "commit"},
+	{"rebase/merge failed", "merge"},
+	{"commit message generation", "commit"},
+}
+
+// failureSignaturesResponse is the body of GET /api/failures/signatures.
+type failureSignaturesResponse struct {
+	WindowDays int                    `json:"window_days"`
+	Signatures []failuresig.Signature `json:"signatures"`
+}
+
+// ListFailureSignatures groups the error events of every task in the active
+// workspace group, deleted tasks included, into recurring failure
+// signatures and marks the ones that look environmental.
+//
+// Query params:
+//   - days – correlation window in days (default 7)
+//   - min_tasks – only list signatures seen in at least this many distinct
+//     tasks (default 2; 1 lists every signature)
+func (h *Handler) ListFailureSignatures(w http.ResponseWriter, r *http.Request) {
+	days, ok := failureWindowDays(w, r)
+	if !ok {
+		return
+	}
+	minTasks := 2
+	if v := r.URL.Query().Get("min_tasks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "min_tasks must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		minTasks = n
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	sigs, err := failureSignatures(r.Context(), s, days)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sigs = slices.DeleteFunc(sigs, func(sig failuresig.Signature) bool { return sig.TaskCount < minTasks })
+	httpjson.Write(w, http.StatusOK, failureSignaturesResponse{WindowDays: days, Signatures: sigs})
+}
+
+// TaskFailureHints returns the failure signatures a task's errors belong
+// to, each with how many other tasks share it and, when a rule matched, a
+// hint that the failure is likely environmental. Accepts ?days= like
+// ListFailureSignatures.
+func (h *Handler) TaskFailureHints(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	days, ok := failureWindowDays(w, r)
+	if !ok {
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	if _, err := s.GetTask(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	sigs, err := failureSignatures(r.Context(), s, days)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	hints := failuresig.ForTask(sigs, id)
+	if hints == nil {
+		hints = []failuresig.Signature{}
+	}
+	httpjson.Write(w, http.StatusOK, hints)
+}
+
+// failureWindowDays parses ?days=, writing a 400 on a bad value.
+func failureWindowDays(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("days")
+	if v == "" {
+		return defaultFailureWindowDays, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		http.Error(w, "days must be a positive integer", http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
+
+// failureSignatures reads the error events of the last days across the
+// store's tasks and groups them with the default rules.
+func failureSignatures(ctx context.Context, s *store.Store, days int) ([]failuresig.Signature, error) {
+	tasks, err := s.ListTasks(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := s.ListDeletedTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	window := time.Duration(days) * 24 * time.Hour
+	since := now.Add(-window)
+
+	var failures []failuresig.Failure
+	for _, t := range slices.Concat(tasks, deleted) {
+		events, err := s.GetEvents(ctx, t.ID)
+		if err != nil {
+			logger.Handler.Warn("failure signatures: read events", "task", t.ID, "error", err)
+			continue
+		}
+		for _, ev := range events {
+			if ev.EventType != store.EventTypeError || ev.CreatedAt.Before(since) {
+				continue
+			}
+			f, ok := failureFromEvent(ev)
+			if !ok {
+				continue
+			}
+			f.TaskID, f.Category = t.ID, t.FailureCategory
+			failures = append(failures, f)
+		}
+	}
+	return failuresig.Group(failures, failuresig.DefaultRules, window, now), nil
+}
+
+// failureFromEvent extracts the message, phase, and repository of an error
+// event. Reports false for an event with no message.
+func failureFromEvent(ev store.TaskEvent) (failuresig.Failure, bool) {
+	var data map[string]any
+	if err := json.Unmarshal(ev.Data, &data); err != nil {
+		return failuresig.Failure{}, false
+	}
+	str := func(key string) string {
+		v, _ := data[key].(string)
+		return v
+	}
+	msg := cmp.Or(str("error"), str("message"), str("result"))
+	if strings.TrimSpace(msg) == "" {
+		return failuresig.Failure{}, false
+	}
+	phase := str("phase")
+	if phase == "" {
+		for _, p := range errorPhasePrefixes {
+			if strings.HasPrefix(msg, p.prefix) {
+				phase = p.phase
+				break
+			}
+		}
+	}
+	return failuresig.Failure{Phase: phase, Repo: str("repo"), Message: msg, At: ev.CreatedAt}, true
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/failuresig"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestListFailureSignatures_GroupsAcrossTasks(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	var ids []uuid.UUID
+	for i := range 3 {
+		task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, task.ID)
+		_ = h.store.InsertEvent(ctx, task.ID, store.EventTypeError, map[string]string{
+			"error": "rebase/merge failed: fatal: unable to access 'https://example.com/r.git/': Could not resolve host: example.com",
+		})
+		if i == 0 {
+			_ = h.store.InsertEvent(ctx, task.ID, store.EventTypeError, map[string]string{"error": "container exited with code 2"})
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ListFailureSignatures(w, httptest.NewRequest(http.MethodGet, "/api/failures/signatures", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var resp failureSignaturesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.WindowDays != 7 || len(resp.Signatures) != 1 {
+		t.Fatalf("response = %+v, want one signature over 7 days", resp)
+	}
+	sig := resp.Signatures[0]
+	if sig.TaskCount != 3 || sig.Phase != "merge" || sig.Rule != "network" || sig.Hint == "" {
+		t.Errorf("signature = %+v", sig)
+	}
+
+	// min_tasks=1 also lists the one-off failure.
+	w = httptest.NewRecorder()
+	h.ListFailureSignatures(w, httptest.NewRequest(http.MethodGet, "/api/failures/signatures?min_tasks=1", nil))
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Signatures) != 2 {
+		t.Errorf("min_tasks=1: %d signatures, want 2", len(resp.Signatures))
+	}
+
+	w = httptest.NewRecorder()
+	h.TaskFailureHints(w, httptest.NewRequest(http.MethodGet, "/api/tasks/x/failure-hints", nil), ids[1])
+	var hints []failuresig.Signature
+	if err := json.NewDecoder(w.Body).Decode(&hints); err != nil {
+		t.Fatal(err)
+	}
+	if len(hints) != 1 || hints[0].ID != sig.ID {
+		t.Errorf("hints = %+v", hints)
+	}
+}
+
+func TestListFailureSignatures_BadQuery(t *testing.T) {
+	h := newTestHandler(t)
+	for _, q := range []string{"?days=0", "?days=x", "?min_tasks=0"} {
+		w := httptest.NewRecorder()
+		h.ListFailureSignatures(w, httptest.NewRequest(http.MethodGet, "/api/failures/signatures"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, w.Code)
+		}
+	}
+}
+
+func TestTaskFailureHints_UnknownTask(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.TaskFailureHints(w, httptest.NewRequest(http.MethodGet, "/api/tasks/x/failure-hints", nil), uuid.New())
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
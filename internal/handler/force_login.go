@@ -105,19 +105,21 @@ func shouldForceLogin(r *http.Request) bool {
 // loginRedirectURL constructs the /login?next=<path> target. `next`
 // is validated to be path-only (no scheme/host), defeating the
 // open-redirect class of bug. An invalid `next` is dropped; the
-// caller just lands on /login with no return target.
+// caller just lands on /login with no return target. Both paths carry
+// the --base-path prefix so they resolve through a reverse proxy.
 func loginRedirectURL(r *http.Request) string {
+	login := BasePath(r) + "/login"
 	next := r.URL.RequestURI()
 	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
-		return "/login"
+		return login
 	}
 	// A URL like /login?next=http://evil/ must not parse as an
 	// off-site redirect. url.Parse treats it as a path-only URL, but
 	// belt-and-suspenders: drop anything that parses with a host set.
 	u, err := url.Parse(next)
 	if err != nil || u.Host != "" || u.Scheme != "" {
-		return "/login"
+		return login
 	}
-	q := url.Values{"next": {next}}.Encode()
-	return "/login?" + q
+	q := url.Values{"next": {BasePath(r) + next}}.Encode()
+	return login + "?" + q
 }
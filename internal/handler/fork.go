@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/pkg/sanitize"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// forkResultLimit caps, in runes, how much of the parent agent's last
+// message is carried into a fork's first prompt.
+const forkResultLimit = 4000
+
+// ForkTask branches a waiting task into a sibling that explores a different
+// direction. The fork copies the parent's settings, starts from a copy of
+// its worktrees (uncommitted changes included), and runs in a fresh agent
+// session seeded with a summary of the parent's session and the given
+// feedback. Both directions can then proceed in parallel and the better one
+// is kept. The parent stays waiting and is not modified.
+func (h *Handler) ForkTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	req, ok := httpjson.DecodeBody[struct {
+		Message string `json:"message"`
+		Title   string `json:"title"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		http.Error(w, "message is required", http.StatusBadRequest)
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+
+	// Holding promoteMu keeps auto-submit from committing the parent, and
+	// removing its worktrees, while they are copied, and makes the
+	// concurrency check race-free.
+	promoteMu.Lock()
+	defer promoteMu.Unlock()
+
+	parent, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	switch {
+	case parent.Status != store.TaskStatusWaiting:
+		http.Error(w, "only waiting tasks can be forked", http.StatusBadRequest)
+		return
+	case parent.IsShadow():
+		http.Error(w, "shadow experiment tasks cannot be forked", http.StatusBadRequest)
+		return
+	case len(parent.WorktreePaths) == 0:
+		http.Error(w, "task has no worktrees to fork", http.StatusBadRequest)
+		return
+	}
+	if h.countGlobalInProgress() >= h.maxConcurrentTasks() {
+		http.Error(w, fmt.Sprintf("max concurrent tasks (%d) reached", h.maxConcurrentTasks()), http.StatusConflict)
+		return
+	}
+
+	model := ""
+	if parent.ModelOverride != nil {
+		model = *parent.ModelOverride
+	}
+	fork, err := s.CreateTaskWithOptions(r.Context(), store.TaskCreateOptions{
+		Prompt:             parent.Prompt,
+		Criteria:           parent.Criteria,
+		Timeout:            parent.Timeout,
+		MountWorktrees:     parent.MountWorktrees,
+		SkipCommit:         parent.SkipCommit,
+		MergeMode:          parent.MergeMode,
+		RequireApproval:    parent.RequireApproval,
+		ApprovalGates:      parent.ApprovalGates,
+		InteractiveInput:   parent.InteractiveInput,
+		Kind:               parent.Kind,
+		FlowID:             parent.FlowID,
+		Tags:               parent.Tags,
+		Sandbox:            parent.Sandbox,
+		SandboxByActivity:  parent.SandboxByActivity,
+		MaxCostUSD:         parent.MaxCostUSD,
+		MaxInputTokens:     parent.MaxInputTokens,
+		ModelOverride:      model,
+		CustomPassPatterns: parent.CustomPassPatterns,
+		CustomFailPatterns: parent.CustomFailPatterns,
+		ResearchDomains:    parent.ResearchDomains,
+		Proxy:              parent.Proxy,
+		SummaryLocale:      parent.SummaryLocale,
+		CreatedBy:          parent.CreatedBy,
+		OrgID:              parent.OrgID,
+		ForkedFrom:         parent.ID,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" && parent.Title != "" {
+		title = parent.Title + " (fork)"
+	}
+	if title != "" {
+		_ = s.UpdateTaskTitle(r.Context(), fork.ID, title)
+	}
+
+	worktrees, branch, err := h.runner.ForkTaskWorktrees(fork.ID, parent.WorktreePaths)
+	if err != nil {
+		if delErr := s.DeleteTask(r.Context(), fork.ID, "worktree fork failed"); delErr != nil {
+			logger.Handler.Warn("fork: delete fork after worktree failure", "task", fork.ID, "error", delErr)
+		}
+		writeError(w, fmt.Errorf("fork worktrees: %w", err))
+		return
+	}
+	if err := s.UpdateTaskWorktrees(r.Context(), fork.ID, worktrees, branch); err != nil {
+		h.runner.CleanupWorktrees(fork.ID, worktrees, branch)
+		writeError(w, err)
+		return
+	}
+	if err := s.UpdateTaskStatus(r.Context(), fork.ID, store.TaskStatusInProgress); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.insertEventOrLog(r.Context(), fork.ID, store.EventTypeSystem, map[string]string{
+		"result": "Forked from task " + parent.ID.String(),
+	})
+	h.insertEventOrLog(r.Context(), fork.ID, store.EventTypeFeedback, map[string]string{
+		"message": message,
+	})
+	h.insertEventOrLog(r.Context(), fork.ID, store.EventTypeStateChange,
+		store.NewStateChangeData(store.TaskStatusBacklog, store.TaskStatusInProgress, store.TriggerUser, nil))
+	h.insertEventOrLog(r.Context(), parent.ID, store.EventTypeSystem, map[string]string{
+		"result": "Forked into task " + fork.ID.String(),
+	})
+
+	oversight, err := s.GetOversight(parent.ID)
+	if err != nil {
+		logger.Handler.Warn("fork: read parent oversight", "task", parent.ID, "error", err)
+	}
+	h.runner.RunBackground(fork.ID, buildForkPrompt(parent, oversight, message), "", false)
+
+	started, err := s.GetTask(r.Context(), fork.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpjson.Write(w, http.StatusCreated, started)
+}
+
+// buildForkPrompt renders the first prompt of a fork of parent: the task,
+// the phases of the parent's session from its oversight summary when one is
+// ready, the parent agent's last message, and the fork's feedback. The
+// summary stands in for the parent's conversation, which is not shared, so
+// forking works the same for every sandbox harness.
+func buildForkPrompt(parent *store.Task, oversight *store.TaskOversight, feedback string) string {
+	var progress strings.Builder
+	if oversight != nil && oversight.Status == store.OversightStatusReady {
+		for i, phase := range oversight.Phases {
+			fmt.Fprintf(&progress, "%d. %s: %s\n", i+1, phase.Title, phase.Summary)
+		}
+	}
+	result := ""
+	if parent.Result != nil {
+		result = sanitize.Truncate(strings.TrimSpace(*parent.Result), forkResultLimit)
+	}
+	return prompts.Fork(prompts.ForkData{
+		Prompt:   cmp.Or(parent.ExecutionPrompt, parent.Prompt),
+		Progress: strings.TrimSpace(progress.String()),
+		Result:   result,
+		Feedback: feedback,
+	})
+}
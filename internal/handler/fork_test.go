@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func callForkTask(h *Handler, id uuid.UUID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+id.String()+"/fork", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ForkTask(w, req, id)
+	return w
+}
+
+// newWaitingTask creates a task that has run once and paused for feedback
+// with a worktree for one repository.
+func newWaitingTask(t *testing.T, s *store.Store, opts store.TaskCreateOptions) *store.Task {
+	t.Helper()
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.UpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress)
+	_ = s.UpdateTaskWorktrees(ctx, task.ID, map[string]string{"/repo": "/worktrees/" + task.ID.String() + "/repo"}, "task/"+task.ID.String()[:8])
+	_ = s.UpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+	task, _ = s.GetTask(ctx, task.ID)
+	return task
+}
+
+func TestForkTask_StartsLinkedSibling(t *testing.T) {
+	mock := &runner.MockRunner{}
+	h, s := newTestHandlerWithMockRunner(t, mock)
+	ctx := context.Background()
+	parent := newWaitingTask(t, s, store.TaskCreateOptions{Prompt: "add caching", Timeout: 15, Tags: []string{"perf"}, ModelOverride: "m1"})
+	_ = s.UpdateTaskTitle(ctx, parent.ID, "Caching")
+
+	w := callForkTask(h, parent.ID, `{"message":"use an LRU instead"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var fork store.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &fork); err != nil {
+		t.Fatal(err)
+	}
+	if fork.ForkedFrom != parent.ID || fork.Status != store.TaskStatusInProgress {
+		t.Errorf("fork forked_from = %s, status = %s", fork.ForkedFrom, fork.Status)
+	}
+	if fork.Title != "Caching (fork)" || fork.Prompt != "add caching" || !fork.HasTag("perf") || fork.ModelOverride == nil || *fork.ModelOverride != "m1" {
+		t.Errorf("fork did not copy the parent's settings: %+v", fork)
+	}
+	if fork.BranchName != "task/"+fork.ID.String()[:8] || fork.WorktreePaths["/repo"] == "" {
+		t.Errorf("fork worktrees = %v on %q", fork.WorktreePaths, fork.BranchName)
+	}
+	if calls := mock.RunBackgroundCalls; len(calls) != 1 || calls[0] != fork.ID {
+		t.Errorf("RunBackground calls = %v, want the fork", calls)
+	}
+	if p, _ := s.GetTask(ctx, parent.ID); p.Status != store.TaskStatusWaiting {
+		t.Errorf("parent status = %s, want waiting", p.Status)
+	}
+
+	events, _ := s.GetEvents(ctx, fork.ID)
+	var feedback bool
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeFeedback && strings.Contains(string(ev.Data), "use an LRU instead") {
+			feedback = true
+		}
+	}
+	if !feedback {
+		t.Error("fork has no feedback event with the message")
+	}
+}
+
+func TestForkTask_Rejects(t *testing.T) {
+	h, s := newTestHandlerWithMockRunner(t, &runner.MockRunner{})
+	ctx := context.Background()
+	backlog, _ := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "a", Timeout: 15})
+	waiting := newWaitingTask(t, s, store.TaskCreateOptions{Prompt: "b", Timeout: 15})
+
+	if w := callForkTask(h, waiting.ID, `{"message":"  "}`); w.Code != http.StatusBadRequest {
+		t.Errorf("empty message: expected 400, got %d", w.Code)
+	}
+	if w := callForkTask(h, backlog.ID, `{"message":"x"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("backlog task: expected 400, got %d", w.Code)
+	}
+	if w := callForkTask(h, uuid.New(), `{"message":"x"}`); w.Code != http.StatusNotFound {
+		t.Errorf("unknown task: expected 404, got %d", w.Code)
+	}
+	tasks, _ := s.ListTasks(ctx, false)
+	if len(tasks) != 2 {
+		t.Errorf("rejected forks created tasks: %d tasks, want 2", len(tasks))
+	}
+}
+
+func TestBuildForkPrompt(t *testing.T) {
+	result := "Should the cache be per request?"
+	parent := &store.Task{Prompt: "short title", ExecutionPrompt: "add caching to the API", Result: &result}
+	oversight := &store.TaskOversight{
+		Status: store.OversightStatusReady,
+		Phases: []store.OversightPhase{{Title: "Explore", Summary: "read the handlers"}},
+	}
+	got := buildForkPrompt(parent, oversight, "cache per user instead")
+	for _, want := range []string{"add caching to the API", "1. Explore: read the handlers", result, "cache per user instead"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("fork prompt missing %q:\n%s", want, got)
+		}
+	}
+
+	oversight.Status = store.OversightStatusGenerating
+	if got := buildForkPrompt(parent, oversight, "x"); strings.Contains(got, "read the handlers") {
+		t.Error("fork prompt used oversight that is not ready")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -65,6 +66,7 @@ func workspaceMutationGuardStatuses() []store.TaskStatus {
 		store.TaskStatusInProgress,
 		store.TaskStatusWaiting,
 		store.TaskStatusCommitting,
+		store.TaskStatusReview,
 		store.TaskStatusFailed,
 	}
 }
@@ -273,7 +275,7 @@ func (h *Handler) GitSyncWorkspace(w http.ResponseWriter, r *http.Request) {
 		}
 		logger.Git.Error("sync rebase failed", "workspace", req.Workspace, "error", err)
 		if conflicted {
-			http.Error(w, "rebase conflict: resolve manually in "+req.Workspace, http.StatusConflict)
+			writeError(w, fmt.Errorf("%w: resolve manually in %s", gitutil.ErrMergeConflict, req.Workspace))
 			return
 		}
 		http.Error(w, "rebase failed: "+out, http.StatusInternalServerError)
@@ -338,7 +340,7 @@ func (h *Handler) GitRebaseOnMain(w http.ResponseWriter, r *http.Request) {
 		}
 		logger.Git.Error("rebase-on-main failed", "workspace", req.Workspace, "error", err)
 		if conflicted {
-			http.Error(w, "rebase conflict: resolve manually in "+req.Workspace, http.StatusConflict)
+			writeError(w, fmt.Errorf("%w: resolve manually in %s", gitutil.ErrMergeConflict, req.Workspace))
 			return
 		}
 		http.Error(w, "rebase failed: "+out, http.StatusInternalServerError)
@@ -353,7 +355,26 @@ func (h *Handler) GitRebaseOnMain(w http.ResponseWriter, r *http.Request) {
 // both git-diff and ls-files (e.g. ":!AGENTS.md"). Output is newline-separated
 // so each file's "diff --git" header starts on its own line.
 func diffWithUntracked(ctx context.Context, worktreePath, baseRef string, excludes ...string) string {
-	args := append([]string{"diff", baseRef, "--", "."}, excludes...)
+	return diffWithUntrackedExt(ctx, "", worktreePath, baseRef, excludes...)
+}
+
+// diffWithUntrackedExt is diffWithUntracked with an optional git external
+// diff command (see diff.external in git-config(1)). An empty extDiff uses
+// git's built-in unified diff.
+func diffWithUntrackedExt(ctx context.Context, extDiff, worktreePath, baseRef string, excludes ...string) string {
+	var prefix []string
+	if extDiff != "" {
+		prefix = []string{"-c", "diff.external=" + extDiff}
+	}
+	diffCmd := func(args ...string) []string {
+		full := append(slices.Clone(prefix), "diff")
+		if extDiff != "" {
+			full = append(full, "--ext-diff")
+		}
+		return append(full, args...)
+	}
+
+	args := append(diffCmd(baseRef, "--", "."), excludes...)
 	out, _ := cmdexec.Git(worktreePath, args...).WithContext(ctx).Output()
 
 	lsArgs := append([]string{"ls-files", "--others", "--exclude-standard", "--", "."}, excludes...)
@@ -364,7 +385,7 @@ func diffWithUntracked(ctx context.Context, worktreePath, baseRef string, exclud
 				continue
 			}
 			fd, _ := cmdexec.Git(worktreePath,
-				"diff", "--no-index", "/dev/null", file).WithContext(ctx).Output()
+				diffCmd("--no-index", "/dev/null", file)...).WithContext(ctx).Output()
 			if fd != "" {
 				if out != "" {
 					out += "\n"
@@ -379,15 +400,12 @@ func diffWithUntracked(ctx context.Context, worktreePath, baseRef string, exclud
 // diffFromStoredRefs reconstructs a diff from stored commit hashes or branch
 // names when the worktree directory no longer exists (cleaned up after done/cancel).
 // Priority: base..commit hash > git show commit > merge-base..branch > default..branch.
-func diffFromStoredRefs(ctx context.Context, repoPath string, task *store.Task) string {
+// The base..commit diff comes from the task's diff snapshot when available.
+func diffFromStoredRefs(ctx context.Context, s *store.Store, repoPath string, task *store.Task) string {
 	commitHash := task.CommitHashes[repoPath]
 	if commitHash != "" {
 		if baseHash := task.BaseCommitHashes[repoPath]; baseHash != "" {
-			out, gitErr := cmdexec.Git(repoPath, "diff", baseHash, commitHash).WithContext(ctx).Output()
-			if gitErr != nil {
-				logger.Git.Debug("git diff base..commit failed", "repo", repoPath, "error", gitErr)
-			}
-			return out
+			return snapshotRangeDiff(ctx, s, task.ID, repoPath, baseHash, commitHash)
 		}
 		out, gitErr := cmdexec.Git(repoPath, "show", commitHash).WithContext(ctx).Output()
 		if gitErr != nil {
@@ -418,6 +436,29 @@ func diffFromStoredRefs(ctx context.Context, repoPath string, task *store.Task)
 	return out
 }
 
+// snapshotRangeDiff returns `git diff base head` for repoPath, serving it from
+// the task's diff snapshot when one was rendered for the same commit pair
+// (normally precomputed at merge) and saving a fresh rendering otherwise.
+// A nil store always runs git.
+func snapshotRangeDiff(ctx context.Context, s *store.Store, taskID uuid.UUID, repoPath, base, head string) string {
+	if s != nil {
+		if diff, ok := s.DiffSnapshot(taskID, repoPath, base, head); ok {
+			return diff
+		}
+	}
+	out, gitErr := cmdexec.Git(repoPath, "diff", base, head).WithContext(ctx).Output()
+	if gitErr != nil {
+		logger.Git.Debug("git diff base..commit failed", "repo", repoPath, "error", gitErr)
+		return out
+	}
+	if s != nil {
+		if err := s.SaveDiffSnapshot(taskID, repoPath, store.DiffSnapshot{Base: base, Head: head, Diff: out}); err != nil {
+			logger.Git.Debug("save diff snapshot failed", "task", taskID, "repo", repoPath, "error", err)
+		}
+	}
+	return out
+}
+
 // appendWorkspaceDiff appends a diff section to the combined builder, prepending
 // a workspace separator line when there are multiple workspaces.
 func appendWorkspaceDiff(combined *strings.Builder, multiWS bool, repoPath, diff string) {
@@ -430,59 +471,60 @@ func appendWorkspaceDiff(combined *strings.Builder, multiWS bool, repoPath, diff
 	combined.WriteString(diff)
 }
 
-// TaskDiff returns the git diff for a task's worktrees versus the default branch.
-// Responses are cached: terminal tasks (done/cancelled/archived) are cached
-// indefinitely; active tasks are cached for constants.DiffCacheTTL (10 s). ETag and
-// Cache-Control headers are set so browsers can issue conditional requests.
-func (h *Handler) TaskDiff(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
-	s, ok := h.requireStore(w)
-	if !ok {
-		return
+// liveSnapshotRoot returns the initial commit of a non-git workspace's
+// snapshot repository while the task's worktree still exists.
+func liveSnapshotRoot(ctx context.Context, worktreePath string) (string, bool) {
+	if _, err := os.Stat(worktreePath); err != nil || !gitutil.IsGitRepo(worktreePath) {
+		return "", false
 	}
-	task, err := s.GetTask(r.Context(), id)
-	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
-		return
-	}
-	if len(task.WorktreePaths) == 0 {
-		httpjson.Write(w, http.StatusOK, map[string]any{"diff": "", "behind_counts": map[string]int{}})
-		return
+	root, err := gitutil.SnapshotRoot(ctx, worktreePath)
+	return root, err == nil
+}
+
+// snapshotWorkspaceDiff returns a non-git workspace's changes: the live
+// snapshot diffed against its initial commit, covering agent commits,
+// uncommitted edits, and new files, or once the worktree is gone the diff
+// stored when the task committed.
+func snapshotWorkspaceDiff(ctx context.Context, task *store.Task, repoPath, worktreePath string) string {
+	if root, ok := liveSnapshotRoot(ctx, worktreePath); ok {
+		return diffWithUntracked(ctx, worktreePath, root)
 	}
+	return task.SnapshotDiffs[repoPath]
+}
 
-	// Serve from cache when available.
-	if entry, ok := h.diffCache.get(id); ok {
-		cacheControl := "no-cache"
-		if entry.immutable {
-			cacheControl = "immutable"
-		}
-		w.Header().Set("ETag", `"`+entry.etag+`"`)
-		w.Header().Set("Cache-Control", cacheControl)
-		if r.Header.Get("If-None-Match") == `"`+entry.etag+`"` {
-			w.WriteHeader(http.StatusNotModified)
-			return
+// snapshotFiles summarises file by file the changes to each of the task's
+// non-git workspaces, keyed by workspace base name. It returns nil when the
+// task has no non-git workspace.
+func snapshotFiles(ctx context.Context, task *store.Task) map[string][]gitutil.FileChange {
+	var files map[string][]gitutil.FileChange
+	for repoPath, worktreePath := range task.WorktreePaths {
+		if gitutil.IsGitRepo(repoPath) {
+			continue
 		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		if _, err := w.Write(entry.payload); err != nil {
-			logger.Handler.Debug("diff response write failed", "task", id, "error", err)
+		if files == nil {
+			files = make(map[string][]gitutil.FileChange)
 		}
-		return
+		files[filepath.Base(repoPath)] = gitutil.DiffFiles(snapshotWorkspaceDiff(ctx, task, repoPath, worktreePath))
 	}
+	return files
+}
 
+// collectTaskDiff computes the combined diff of every workspace the task
+// touched, plus the difftastic rendering when structural is set and the
+// per-repository behind counts. It is the uncached core of TaskDiff, shared
+// with the retry path so each attempt's diff can be archived before the
+// worktree is reused or discarded.
+func collectTaskDiff(ctx context.Context, s *store.Store, task *store.Task, structural bool) (string, string, map[string]int) {
 	multiWS := len(task.WorktreePaths) > 1
-	var combined strings.Builder
+	var combined, combinedStructural strings.Builder
 	behindCounts := make(map[string]int)
 
 	for repoPath, worktreePath := range task.WorktreePaths {
 		if !gitutil.IsGitRepo(repoPath) {
-			// Non-git workspace: try live snapshot first, then stored diff.
-			if _, statErr := os.Stat(worktreePath); statErr == nil && gitutil.IsGitRepo(worktreePath) {
-				// Active task: compute diff from snapshot (initial commit → HEAD).
-				out := diffWithUntracked(r.Context(), worktreePath, "HEAD~1")
-				appendWorkspaceDiff(&combined, multiWS, repoPath, out)
-			} else if task.SnapshotDiffs[repoPath] != "" {
-				// Terminal task: use stored diff captured at commit time.
-				appendWorkspaceDiff(&combined, multiWS, repoPath, task.SnapshotDiffs[repoPath])
+			// Non-git workspace: diff the live snapshot, else the stored diff.
+			appendWorkspaceDiff(&combined, multiWS, repoPath, snapshotWorkspaceDiff(ctx, task, repoPath, worktreePath))
+			if root, ok := liveSnapshotRoot(ctx, worktreePath); ok && structural {
+				appendWorkspaceDiff(&combinedStructural, multiWS, repoPath, structuralDiff(ctx, worktreePath, root))
 			}
 			continue
 		}
@@ -490,7 +532,7 @@ func (h *Handler) TaskDiff(w http.ResponseWriter, r *http.Request, id uuid.UUID)
 		// fall back to stored commit hashes or branch names to reconstruct the diff.
 		// Priority: base..commit hash > git show commit > merge-base..branch > default..branch.
 		if _, statErr := os.Stat(worktreePath); statErr != nil {
-			out := diffFromStoredRefs(r.Context(), repoPath, task)
+			out := diffFromStoredRefs(ctx, s, repoPath, task)
 			appendWorkspaceDiff(&combined, multiWS, repoPath, out)
 			continue
 		}
@@ -510,30 +552,104 @@ func (h *Handler) TaskDiff(w http.ResponseWriter, r *http.Request, id uuid.UUID)
 		// Podman leaves empty mount-point files in the worktree when a file
 		// is bind-mounted into a directory that is itself a bind mount; these
 		// are not real changes and should not appear in task diffs.
-		out := diffWithUntracked(r.Context(), worktreePath, base,
-			":!"+prompts.ClaudeInstructionsFilename, ":!"+prompts.CodexInstructionsFilename)
+		excludes := []string{":!" + prompts.ClaudeInstructionsFilename, ":!" + prompts.CodexInstructionsFilename}
+		out := diffWithUntracked(ctx, worktreePath, base, excludes...)
 		appendWorkspaceDiff(&combined, multiWS, repoPath, out)
+		if structural {
+			appendWorkspaceDiff(&combinedStructural, multiWS, repoPath, structuralDiff(ctx, worktreePath, base, excludes...))
+		}
 		if n, err := gitutil.CommitsBehind(repoPath, worktreePath); err == nil && n > 0 {
 			behindCounts[filepath.Base(repoPath)] = n
 		}
 	}
+	return combined.String(), combinedStructural.String(), behindCounts
+}
+
+// TaskDiff returns the git diff for a task's worktrees versus the default branch.
+// Responses are cached: terminal tasks (done/cancelled/archived) are cached
+// indefinitely; active tasks are cached for constants.DiffCacheTTL (10 s). ETag and
+// Cache-Control headers are set so browsers can issue conditional requests.
+//
+// ?backend=difftastic additionally renders live worktree changes through
+// difftastic into structural_diff when the binary is installed; otherwise the
+// git backend is used. The backend field reports which one produced the
+// response. Structural responses are not cached.
+func (h *Handler) TaskDiff(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	backend, ok := parseDiffBackend(r.URL.Query().Get("backend"))
+	if !ok {
+		http.Error(w, "unknown diff backend", http.StatusBadRequest)
+		return
+	}
+	// Fall back to git when difftastic is requested but not installed; the
+	// response's backend field tells the client which one actually ran.
+	if backend == diffBackendDifftastic && !difftasticAvailable() {
+		backend = diffBackendGit
+	}
+	structural := backend != diffBackendGit
+
+	if len(task.WorktreePaths) == 0 {
+		httpjson.Write(w, http.StatusOK, map[string]any{"diff": "", "behind_counts": map[string]int{}, "backend": backend})
+		return
+	}
+
+	// Serve from cache when available. Structural diffs bypass the cache,
+	// which only holds git-backend responses.
+	if entry, ok := h.diffCache.get(id); ok && !structural {
+		cacheControl := "no-cache"
+		if entry.immutable {
+			cacheControl = "immutable"
+		}
+		w.Header().Set("ETag", `"`+entry.etag+`"`)
+		w.Header().Set("Cache-Control", cacheControl)
+		if r.Header.Get("If-None-Match") == `"`+entry.etag+`"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(entry.payload); err != nil {
+			logger.Handler.Debug("diff response write failed", "task", id, "error", err)
+		}
+		return
+	}
+
+	diff, structDiff, behindCounts := collectTaskDiff(r.Context(), s, task, structural)
 
 	// Serialize, cache, and write the response.
-	payload, err := json.Marshal(map[string]any{
-		"diff":          combined.String(),
+	resp := map[string]any{
+		"diff":          diff,
 		"behind_counts": behindCounts,
-	})
+		"backend":       backend,
+	}
+	if files := snapshotFiles(r.Context(), task); files != nil {
+		resp["snapshot_files"] = files
+	}
+	if structural {
+		resp["structural_diff"] = structDiff
+	}
+	payload, err := json.Marshal(resp)
 	if err != nil {
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
 
 	etag := diffETag(payload)
-	immutable := (task.Status == store.TaskStatusDone || task.Status == store.TaskStatusCancelled) || task.Archived
+	// A done task that left its changes in the worktree can still be
+	// committed, which changes its diff.
+	immutable := (task.Status == store.TaskStatusDone || task.Status == store.TaskStatusCancelled) && !task.RetainsWorktree() || task.Archived
 	// Don't cache diff results for in_progress tasks: their worktrees are
 	// actively being modified (sync, execution) so the computed diff/behind
 	// counts are ephemeral and would become stale when the operation finishes.
-	if task.Status != store.TaskStatusInProgress {
+	if task.Status != store.TaskStatusInProgress && !structural {
 		entry := diffCacheEntry{
 			payload:   payload,
 			etag:      etag,
@@ -560,6 +676,44 @@ func (h *Handler) TaskDiff(w http.ResponseWriter, r *http.Request, id uuid.UUID)
 	}
 }
 
+// TaskBehind reports how many commits each of the task's repositories is
+// behind its default branch, keyed by repository base name. It is the cheap
+// counterpart of the behind_counts field in TaskDiff: no diff is rendered and
+// results come from the shared commitsBehind cache, so the board can poll it
+// for every visible task to surface "behind by N" badges and offer a rebase.
+func (h *Handler) TaskBehind(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	behind := make(map[string]int)
+	total := 0
+	for repoPath, worktreePath := range task.WorktreePaths {
+		if !gitutil.IsGitRepo(repoPath) {
+			continue
+		}
+		if _, statErr := os.Stat(worktreePath); statErr != nil {
+			continue
+		}
+		n, err := h.commitsBehindCache.cachedCommitsBehind(repoPath, worktreePath)
+		if err != nil {
+			logger.Handler.Debug("task behind: CommitsBehind failed", "task", id, "repo", repoPath, "error", err)
+			continue
+		}
+		behind[filepath.Base(repoPath)] = n
+		total += n
+	}
+	httpjson.Write(w, http.StatusOK, map[string]any{
+		"behind_counts": behind,
+		"total":         total,
+	})
+}
+
 // GitBranches returns the list of local branches for a workspace.
 func (h *Handler) GitBranches(w http.ResponseWriter, r *http.Request) {
 	ws := r.URL.Query().Get("workspace")
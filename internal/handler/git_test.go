@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/google/uuid"
 	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/gitutil"
 	"latere.ai/x/wallfacer/internal/pkg/cache"
 	"latere.ai/x/wallfacer/internal/runner"
 	"latere.ai/x/wallfacer/internal/store"
@@ -149,8 +151,9 @@ func newStaticWorkspaceHandler(t *testing.T, workspaces []string) *Handler {
 
 // diffResponse is the JSON shape returned by TaskDiff.
 type diffResponse struct {
-	Diff         string         `json:"diff"`
-	BehindCounts map[string]int `json:"behind_counts"`
+	Diff          string                          `json:"diff"`
+	BehindCounts  map[string]int                  `json:"behind_counts"`
+	SnapshotFiles map[string][]gitutil.FileChange `json:"snapshot_files"`
 }
 
 type gitWorkspaceConflictResponse struct {
@@ -178,6 +181,55 @@ func callTaskDiff(t *testing.T, h *Handler, taskID uuid.UUID) diffResponse {
 	return resp
 }
 
+func TestTaskBehindCountsUpstreamCommits(t *testing.T) {
+	repo := setupRepo(t)
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	wt := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-b", "task", wt, "HEAD")
+
+	for _, name := range []string{"one.txt", "two.txt"} {
+		_ = os.WriteFile(filepath.Join(repo, name), []byte(name+"\n"), 0644)
+		gitRun(t, repo, "add", ".")
+		gitRun(t, repo, "commit", "-m", "add "+name)
+	}
+
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "behind", Timeout: 5})
+	_ = h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: wt}, "task")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+task.ID.String()+"/behind", nil)
+	w := httptest.NewRecorder()
+	h.TaskBehind(w, req, task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("TaskBehind returned %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		BehindCounts map[string]int `json:"behind_counts"`
+		Total        int            `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal behind response: %v", err)
+	}
+	if got := resp.BehindCounts[filepath.Base(repo)]; got != 2 {
+		t.Errorf("behind_counts[%s] = %d, want 2", filepath.Base(repo), got)
+	}
+	if resp.Total != 2 {
+		t.Errorf("total = %d, want 2", resp.Total)
+	}
+}
+
+func TestTaskBehindNotFound(t *testing.T) {
+	h := newTestHandler(t)
+	id := uuid.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+id.String()+"/behind", nil)
+	w := httptest.NewRecorder()
+	h.TaskBehind(w, req, id)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
 func TestTaskDiffShowsOnlyTaskChanges(t *testing.T) {
 	repo := setupRepo(t)
 	h := newTestHandler(t)
@@ -351,6 +403,47 @@ func TestTaskDiffFallbackToCommitHashes(t *testing.T) {
 	}
 }
 
+// TestTaskDiffServesDiffSnapshot verifies that a merged task's diff is read
+// from the snapshot rendered for its commit pair, and that a diff rendered
+// from git is saved as the snapshot for the next request.
+func TestTaskDiffServesDiffSnapshot(t *testing.T) {
+	repo := setupRepo(t)
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	baseHash := gitRun(t, repo, "rev-parse", "HEAD")
+	_ = os.WriteFile(filepath.Join(repo, "task-work.txt"), []byte("task\n"), 0644)
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-m", "task work")
+	commitHash := gitRun(t, repo, "rev-parse", "HEAD")
+
+	newMergedTask := func() uuid.UUID {
+		task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 5})
+		_ = h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: filepath.Join(t.TempDir(), "gone")}, "task")
+		_ = h.store.UpdateTaskCommitHashes(ctx, task.ID, map[string]string{repo: commitHash})
+		_ = h.store.UpdateTaskBaseCommitHashes(ctx, task.ID, map[string]string{repo: baseHash})
+		return task.ID
+	}
+
+	cached := newMergedTask()
+	const snapshot = "diff --git a/precomputed.txt b/precomputed.txt\n"
+	if err := h.store.SaveDiffSnapshot(cached, repo, store.DiffSnapshot{Base: baseHash, Head: commitHash, Diff: snapshot}); err != nil {
+		t.Fatal(err)
+	}
+	if resp := callTaskDiff(t, h, cached); resp.Diff != snapshot {
+		t.Errorf("diff = %q, want the snapshot", resp.Diff)
+	}
+
+	fresh := newMergedTask()
+	resp := callTaskDiff(t, h, fresh)
+	if !strings.Contains(resp.Diff, "task-work.txt") {
+		t.Fatalf("diff = %q, want task-work.txt", resp.Diff)
+	}
+	if diff, ok := h.store.DiffSnapshot(fresh, repo, baseHash, commitHash); !ok || diff != resp.Diff {
+		t.Errorf("snapshot after render = %q, %v; want the served diff", diff, ok)
+	}
+}
+
 func TestTaskDiffFallbackBranchUseMergeBase(t *testing.T) {
 	repo := setupRepo(t)
 	h := newTestHandler(t)
@@ -503,6 +596,61 @@ func TestTaskDiffNonGitWorkspaceLiveSnapshot(t *testing.T) {
 	}
 }
 
+// TestTaskDiffNonGitWorkspaceUncommitted verifies that a live snapshot with
+// only its initial commit still shows the agent's uncommitted edits, new
+// files, and deletions, with a file-level summary per workspace.
+func TestTaskDiffNonGitWorkspaceUncommitted(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	ws := t.TempDir()
+
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot")
+	_ = os.MkdirAll(snapshotPath, 0755)
+	_ = os.WriteFile(filepath.Join(snapshotPath, "file.txt"), []byte("original\n"), 0644)
+	_ = os.WriteFile(filepath.Join(snapshotPath, "gone.txt"), []byte("bye\n"), 0644)
+	if err := gitutil.InitLocalRepo(snapshotPath, "test@example.com", "Test", "wallfacer: initial snapshot"); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(snapshotPath, "file.txt"), []byte("modified\n"), 0644)
+	_ = os.WriteFile(filepath.Join(snapshotPath, "new.txt"), []byte("new\n"), 0644)
+	_ = os.Remove(filepath.Join(snapshotPath, "gone.txt"))
+
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "non-git uncommitted", Timeout: 5})
+	_ = h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{ws: snapshotPath}, "task-branch")
+
+	resp := callTaskDiff(t, h, task.ID)
+	for _, want := range []string{"+modified", "+new", "-bye"} {
+		if !strings.Contains(resp.Diff, want) {
+			t.Errorf("diff missing %q: %s", want, resp.Diff)
+		}
+	}
+	want := []gitutil.FileChange{
+		{Path: "file.txt", Status: gitutil.FileModified},
+		{Path: "gone.txt", Status: gitutil.FileDeleted},
+		{Path: "new.txt", Status: gitutil.FileAdded},
+	}
+	if got := resp.SnapshotFiles[filepath.Base(ws)]; !slices.Equal(got, want) {
+		t.Errorf("snapshot_files = %+v, want %+v", got, want)
+	}
+}
+
+// TestTaskDiffGitWorkspaceNoSnapshotFiles verifies that snapshot_files is
+// omitted for tasks on git workspaces only.
+func TestTaskDiffGitWorkspaceNoSnapshotFiles(t *testing.T) {
+	repo := setupRepo(t)
+	h := newTestHandler(t)
+	ctx := context.Background()
+	wt := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-b", "task", wt, "HEAD")
+
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "git", Timeout: 5})
+	_ = h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: wt}, "task")
+
+	if resp := callTaskDiff(t, h, task.ID); resp.SnapshotFiles != nil {
+		t.Errorf("snapshot_files = %+v, want omitted", resp.SnapshotFiles)
+	}
+}
+
 // TestGitPush_Success verifies that push succeeds when a bare remote is configured.
 func TestGitPush_Success(t *testing.T) {
 	repo := setupRepo(t)
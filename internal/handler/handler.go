@@ -15,12 +15,14 @@ import (
 	wadversarial "latere.ai/x/wallfacer/internal/adversarial"
 	"latere.ai/x/wallfacer/internal/agentsession"
 	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/drafts"
 	"latere.ai/x/wallfacer/internal/envconfig"
 	"latere.ai/x/wallfacer/internal/github"
 	"latere.ai/x/wallfacer/internal/harness"
 	"latere.ai/x/wallfacer/internal/logger"
 	"latere.ai/x/wallfacer/internal/metrics"
 	"latere.ai/x/wallfacer/internal/oauth"
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
 	"latere.ai/x/wallfacer/internal/pkg/circuitbreaker"
 	"latere.ai/x/wallfacer/internal/pkg/httpjson"
 	"latere.ai/x/wallfacer/internal/pkg/lazyval"
@@ -28,6 +30,7 @@ import (
 	"latere.ai/x/wallfacer/internal/runner"
 	"latere.ai/x/wallfacer/internal/store"
 	"latere.ai/x/wallfacer/internal/toposadv"
+	"latere.ai/x/wallfacer/internal/webpush"
 	"latere.ai/x/wallfacer/internal/workspace"
 )
 
@@ -180,11 +183,23 @@ type Handler struct {
 	// authURL caches the auth service base URL for /api/config responses so
 	// handlers don't call back into AuthProvider for every config request.
 	authURL string
+	// tunnelURL is the public URL of the reverse tunnel (WALLFACER_TUNNEL)
+	// while it is up, reported in /api/config. Wired via SetTunnelURL.
+	tunnelURL atomic.Pointer[string]
 	// deviceAuth, when non-nil, drives the local-mode RFC 8628 device-code
 	// sign-in via /api/auth/device/{start,poll,cancel}. Wired via
 	// SetDeviceAuth in local-mode wiring; nil for cloud-mode deployments
 	// (cloud mode uses /login + the OAuth code flow instead).
 	deviceAuth *DeviceAuth
+	// apiAuth is the token and UI-session authentication of the API
+	// (WALLFACER_SERVER_API_KEY, WALLFACER_API_TOKENS), reported and
+	// exchanged by /api/auth/session. Nil leaves authentication reported
+	// as off. Wired via SetAPIAuth.
+	apiAuth *APIAuth
+	// wsOrigins are the cross-origin pages allowed to open /api/ws and
+	// the terminal WebSocket, as coder/websocket origin patterns. The board's own origin is always
+	// allowed. Wired via SetTrustedOrigins.
+	wsOrigins []string
 
 	// github backs the /api/github/* surface with a principal-scoped GitHub
 	// App token provider. Nil until SetGitHub; endpoints then report the
@@ -193,6 +208,16 @@ type Handler struct {
 	// the token store alone.
 	github *github.Provider
 
+	// push delivers Web Push notifications for task events and backs the
+	// /api/push/* subscription endpoints. Nil until SetWebPush; the endpoints
+	// then report push disabled and the UI falls back to in-page toasts.
+	push *webpush.Service
+
+	// drafts keeps the composer's autosaved prompt drafts in the config
+	// directory. Nil without a config directory or when the drafts file
+	// cannot be read; the /api/drafts endpoints then return 503.
+	drafts *drafts.Store
+
 	diffCache          *diffCache
 	commitsBehindCache *commitsBehindCache
 	fileIndex          *fileIndex
@@ -310,6 +335,16 @@ func NewHandler(s *store.Store, r runner.Interface, configDir string, workspaces
 	}
 	// Populate the per-group concurrency override cache from disk.
 	h.reloadGroupLimits()
+	if configDir != "" {
+		// Drafts hold prompt text, so they share the task store's at-rest key.
+		cipher, err := atrest.FromEnv()
+		if err == nil {
+			h.drafts, err = drafts.Open(filepath.Join(configDir, drafts.File), cipher)
+		}
+		if err != nil {
+			logger.Handler.Warn("drafts unavailable", "error", err)
+		}
+	}
 	// Initialise handler state from the current workspace snapshot.
 	h.applySnapshot(wsMgr.Snapshot())
 	if wsMgr != nil {
@@ -757,6 +792,18 @@ func (h *Handler) AutopushEnabled() bool { return h.autopush.Load() }
 // SetAutopush enables or disables auto-push mode.
 func (h *Handler) SetAutopush(enabled bool) { h.autopush.Store(enabled) }
 
+// TunnelURL returns the public URL of the reverse tunnel, or "" when none
+// is up.
+func (h *Handler) TunnelURL() string {
+	if p := h.tunnelURL.Load(); p != nil {
+		return *p
+	}
+	return ""
+}
+
+// SetTunnelURL records the public URL of the reverse tunnel; "" clears it.
+func (h *Handler) SetTunnelURL(url string) { h.tunnelURL.Store(&url) }
+
 // openWatcherBreaker opens the circuit breaker for a specific watcher.
 // It does NOT disable other watchers. Returns true if the breaker was
 // previously closed (i.e., this is a new failure).
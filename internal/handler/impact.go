@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/impact"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+)
+
+// impactTimeout bounds one impact analysis; language servers load the
+// whole project on their first request.
+const impactTimeout = 2 * time.Minute
+
+// impactTools resolves the language servers an impact analysis may run.
+// Overridden in tests.
+var impactTools = impact.LookupTools
+
+// repoImpact is the impact analysis of one repository of a task.
+type repoImpact struct {
+	Repo    string                `json:"repo"`
+	Symbols []impact.SymbolImpact `json:"symbols"`
+}
+
+// TaskImpact lists, for each symbol whose declaration the task's diff
+// rewrote or removed, the references elsewhere in the repository the diff
+// did not update, so reviewers can spot call sites the agent missed.
+// References come from gopls or tsserver when installed on the host and
+// from a whole-word git grep otherwise; the response's tools field says
+// which servers were available.
+//
+// Only git repositories whose task worktree still exists are analyzed.
+func (h *Handler) TaskImpact(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), impactTimeout)
+	defer cancel()
+
+	tools := impactTools()
+	repos := []repoImpact{}
+	for _, repo := range slices.Sorted(maps.Keys(task.WorktreePaths)) {
+		worktree := task.WorktreePaths[repo]
+		if !gitutil.IsGitRepo(repo) {
+			continue
+		}
+		if _, err := os.Stat(worktree); err != nil {
+			continue
+		}
+		patch := taskRepoPatch(ctx, s, task, repo)
+		if strings.TrimSpace(patch) == "" {
+			continue
+		}
+		repos = append(repos, repoImpact{Repo: repo, Symbols: impact.Analyze(ctx, worktree, patch, tools)})
+	}
+	httpjson.Write(w, http.StatusOK, map[string]any{
+		"repos": repos,
+		"tools": map[string]bool{"gopls": tools.Gopls != "", "tsserver": tools.Tsserver != ""},
+	})
+}
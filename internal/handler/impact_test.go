@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/impact"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestTaskImpact_ListsMissedCallSites(t *testing.T) {
+	orig := impactTools
+	impactTools = func() impact.Tools { return impact.Tools{} }
+	t.Cleanup(func() { impactTools = orig })
+
+	repo := setupRepo(t)
+	write := func(dir, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(repo, "greet.go", "package greet\n\nfunc Greet(name string) string { return name }\n")
+	write(repo, "a.go", "package greet\n\nvar a = Greet(\"a\")\n")
+	write(repo, "b.go", "package greet\n\nvar b = Greet(\"b\")\n")
+	gitRun(t, repo, "add", "-A")
+	gitRun(t, repo, "commit", "-q", "-m", "greet")
+
+	h, _ := newTestHandlerWithWorkspacesFromRepo(t, repo)
+	ctx := context.Background()
+	wt := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-q", "-b", "task", wt, "HEAD")
+	write(wt, "greet.go", "package greet\n\nfunc Greet(name, greeting string) string { return greeting + name }\n")
+	write(wt, "a.go", "package greet\n\nvar a = Greet(\"a\", \"hi \")\n")
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "greet", Timeout: 5})
+	if err := h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: wt}, "task"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+task.ID.String()+"/impact", nil)
+	w := httptest.NewRecorder()
+	h.TaskImpact(w, req, task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Repos []struct {
+			Repo    string                `json:"repo"`
+			Symbols []impact.SymbolImpact `json:"symbols"`
+		} `json:"repos"`
+		Tools map[string]bool `json:"tools"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Tools["gopls"] || resp.Tools["tsserver"] {
+		t.Errorf("tools = %v, want none", resp.Tools)
+	}
+	if len(resp.Repos) != 1 || resp.Repos[0].Repo != repo || len(resp.Repos[0].Symbols) != 1 {
+		t.Fatalf("repos = %+v", resp.Repos)
+	}
+	greet := resp.Repos[0].Symbols[0]
+	if greet.Name != "Greet" || greet.Source != impact.SourceGrep || len(greet.References) != 1 || greet.References[0].File != "b.go" {
+		t.Errorf("Greet = %+v", greet)
+	}
+}
+
+func TestTaskImpact_NoWorktree(t *testing.T) {
+	h := newTestHandler(t)
+	task, _ := h.store.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "x", Timeout: 5})
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+task.ID.String()+"/impact", nil)
+	w := httptest.NewRecorder()
+	h.TaskImpact(w, req, task.ID)
+	if w.Code != http.StatusOK || !json.Valid(w.Body.Bytes()) {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp map[string]json.RawMessage
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if string(resp["repos"]) != "[]" {
+		t.Errorf("repos = %s, want []", resp["repos"])
+	}
+}
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/intake"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/pkg/watcher"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// intakeTokenHeader carries WALLFACER_INTAKE_TOKEN on POST /api/intake.
+const intakeTokenHeader = "X-Wallfacer-Intake-Token"
+
+// intakeTag is added to every task filed through intake.
+const intakeTag = "intake"
+
+// intakeJSON is the JSON body of POST /api/intake. Unknown fields are
+// ignored so a webhook's own payload fields do not fail the request.
+type intakeJSON struct {
+	Prompt      string   `json:"prompt"`
+	Text        string   `json:"text"` // alias of prompt, as sent by chat webhooks
+	Title       string   `json:"title"`
+	From        string   `json:"from"`
+	Tags        []string `json:"tags"`
+	Token       string   `json:"token"`
+	Attachments []struct {
+		Name        string `json:"name"`
+		ContentType string `json:"content_type"`
+		Data        []byte `json:"data"` // base64
+	} `json:"attachments"`
+}
+
+// intakeResponse is the body of a successful POST /api/intake. Text is a
+// one-line confirmation that chat integrations such as Slack show to the
+// sender.
+type intakeResponse struct {
+	Task *store.Task `json:"task"`
+	Text string      `json:"text"`
+}
+
+// IntakeTask files a backlog task from an inbound message, so people who
+// do not use the board can send work to it:
+//
+//	POST /api/intake
+//
+// The body is JSON ({"prompt" or "text", "title", "from", "tags",
+// "attachments": [{"name", "content_type", "data" (base64)}]}), a form
+// (application/x-www-form-urlencoded, such as a Slack slash command's
+// "text" and "user_name", or multipart/form-data whose file parts become
+// attachments), or a raw email (message/rfc822). The request must carry
+// WALLFACER_INTAKE_TOKEN in the X-Wallfacer-Intake-Token header, a "token"
+// query parameter, or a "token" field; the endpoint answers 404 while no
+// token is configured. A header or query token is checked before the body
+// is read, so a request with a wrong one never gets its uploads buffered.
+func (h *Handler) IntakeTask(w http.ResponseWriter, r *http.Request) {
+	secret := h.intakeConfig().IntakeToken
+	if secret == "" {
+		http.Error(w, "intake is disabled; set WALLFACER_INTAKE_TOKEN", http.StatusNotFound)
+		return
+	}
+	unauthorized := func(token string) bool {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+			return false
+		}
+		httpjson.Write(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return true
+	}
+	token := cmp.Or(r.Header.Get(intakeTokenHeader), r.URL.Query().Get("token"))
+	if token != "" && unauthorized(token) {
+		return
+	}
+	msg, bodyToken, err := parseIntakeRequest(r)
+	if token == "" && unauthorized(bodyToken) {
+		return
+	}
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			httpjson.Write(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "request body too large"})
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := h.fileIntakeTask(r.Context(), s, msg, "webhook")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// Slack only accepts a 200 answer to a slash command.
+	status := http.StatusCreated
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		status = http.StatusOK
+	}
+	httpjson.Write(w, status, intakeResponse{Task: task, Text: "Filed on the board: " + cmp.Or(task.Title, truncateRunes(task.Prompt, 80))})
+}
+
+// parseIntakeRequest maps an intake request body onto a Message and
+// returns the token it carried in its fields, if any.
+func parseIntakeRequest(r *http.Request) (*intake.Message, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "message/rfc822":
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, "", err
+		}
+		msg, err := intake.ParseEmail(raw)
+		return msg, "", err
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		if err := r.ParseMultipartForm(int64(store.MaxAttachmentBytes)); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return nil, r.PostFormValue("token"), fmt.Errorf("invalid form: %w", err)
+		}
+		msg := &intake.Message{
+			Title: strings.TrimSpace(r.PostFormValue("title")),
+			Body:  strings.TrimSpace(cmp.Or(r.PostFormValue("prompt"), r.PostFormValue("text"))),
+			From:  cmp.Or(r.PostFormValue("from"), r.PostFormValue("user_name")),
+		}
+		if r.MultipartForm != nil {
+			for _, headers := range r.MultipartForm.File {
+				for _, fh := range headers {
+					f, err := fh.Open()
+					if err != nil {
+						return nil, r.PostFormValue("token"), err
+					}
+					data, err := io.ReadAll(f)
+					_ = f.Close()
+					if err != nil {
+						return nil, r.PostFormValue("token"), err
+					}
+					msg.Attachments = append(msg.Attachments, intake.File{Name: fh.Filename, ContentType: fh.Header.Get("Content-Type"), Data: data})
+				}
+			}
+		}
+		if tags := r.PostFormValue("tags"); tags != "" {
+			msg.Tags = strings.Split(tags, ",")
+		}
+		return validIntakeMessage(msg, r.PostFormValue("token"))
+	default:
+		var req intakeJSON
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, "", fmt.Errorf("invalid JSON: %w", err)
+		}
+		msg := &intake.Message{
+			Title: strings.TrimSpace(req.Title),
+			Body:  strings.TrimSpace(cmp.Or(req.Prompt, req.Text)),
+			From:  req.From,
+			Tags:  req.Tags,
+		}
+		for _, a := range req.Attachments {
+			msg.Attachments = append(msg.Attachments, intake.File{Name: a.Name, ContentType: a.ContentType, Data: a.Data})
+		}
+		return validIntakeMessage(msg, req.Token)
+	}
+}
+
+// validIntakeMessage falls back to the title when a message has no body
+// and rejects one with neither.
+func validIntakeMessage(msg *intake.Message, token string) (*intake.Message, string, error) {
+	if msg.Body == "" {
+		msg.Body = msg.Title
+	}
+	if msg.Body == "" {
+		return nil, token, errors.New("prompt (or text) is required")
+	}
+	return msg, token, nil
+}
+
+// fileIntakeTask creates a backlog task from msg: the body is the prompt,
+// led by the title when there is one, and the files are stored as the
+// task's attachments. A file the store refuses is reported on the task
+// rather than failing the whole message. via names the channel ("webhook"
+// or "email") in the task's event log.
+func (h *Handler) fileIntakeTask(ctx context.Context, s *store.Store, msg *intake.Message, via string) (*store.Task, error) {
+	prompt := msg.Body
+	if msg.Title != "" && msg.Title != msg.Body {
+		prompt = msg.Title + "\n\n" + msg.Body
+	}
+	tags := []string{intakeTag}
+	for _, t := range msg.Tags {
+		if t = strings.TrimSpace(t); t != "" && t != intakeTag {
+			tags = append(tags, t)
+		}
+	}
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: prompt, Tags: tags})
+	if err != nil {
+		return nil, err
+	}
+	h.insertEventOrLogTo(ctx, s, task.ID, store.EventTypeStateChange,
+		store.NewStateChangeData("", store.TaskStatusBacklog, store.TriggerSystem, map[string]string{"via": via}))
+	filed := "Filed via " + via
+	if msg.From != "" {
+		filed += " by " + msg.From
+	}
+	h.insertEventOrLogTo(ctx, s, task.ID, store.EventTypeSystem, map[string]string{"result": filed + "."})
+	for _, f := range msg.Attachments {
+		if _, err := s.AddTaskAttachment(ctx, task.ID, f.Name, f.ContentType, f.Data); err != nil {
+			h.insertEventOrLogTo(ctx, s, task.ID, store.EventTypeSystem, map[string]string{
+				"result": fmt.Sprintf("Attachment %q was not kept: %v", f.Name, err),
+			})
+		}
+	}
+	if msg.Title != "" {
+		if err := s.UpdateTaskTitle(ctx, task.ID, truncateRunes(msg.Title, 120)); err != nil {
+			logger.Handler.Warn("intake: set title", "task", task.ID, "error", err)
+		}
+	} else {
+		h.runner.GenerateTitleBackground(task.ID, task.Prompt)
+	}
+	return s.GetTask(ctx, task.ID)
+}
+
+// intakeConfig reads the intake settings from the env file; a missing or
+// unreadable file leaves intake disabled.
+func (h *Handler) intakeConfig() envconfig.Config {
+	if h.envFile == "" {
+		return envconfig.Config{}
+	}
+	cfg, err := envconfig.Parse(h.envFile)
+	if err != nil {
+		return envconfig.Config{}
+	}
+	return cfg
+}
+
+// StartIntakePoller files the unseen emails of the mailbox named by
+// WALLFACER_INTAKE_IMAP_URL from the senders WALLFACER_INTAKE_ALLOWED_SENDERS
+// lists as backlog tasks, checking it every
+// constants.IntakePollInterval. The setting is re-read on every poll, so
+// the mailbox can be configured or changed without a restart.
+func (h *Handler) StartIntakePoller(ctx context.Context) {
+	watcher.Start(ctx, watcher.Config{
+		Interval: constants.IntakePollInterval,
+		Action:   h.pollIntakeMailbox,
+	})
+}
+
+// pollIntakeMailbox runs one mailbox poll. A message that cannot be parsed
+// or comes from a sender not on the allowlist is logged and marked seen so
+// it is not retried forever; one that could not be filed stays unseen for
+// the next poll. Without an allowlist the mailbox is not read at all.
+func (h *Handler) pollIntakeMailbox(ctx context.Context) {
+	cfg := h.intakeConfig()
+	raw := cfg.IntakeIMAPURL
+	if raw == "" {
+		return
+	}
+	if len(cfg.IntakeAllowedSenders) == 0 {
+		logger.Handler.Warn("intake: mailbox not polled; set WALLFACER_INTAKE_ALLOWED_SENDERS")
+		return
+	}
+	mailbox, err := intake.ParseMailboxURL(raw)
+	if err != nil {
+		logger.Handler.Warn("intake: mailbox", "error", err)
+		return
+	}
+	err = mailbox.FetchUnseen(ctx, func(raw []byte) error {
+		msg, err := intake.ParseEmail(raw)
+		if err != nil {
+			logger.Handler.Warn("intake: skipping unreadable email", "error", err)
+			return nil
+		}
+		if !intake.AllowedSender(msg.From, cfg.IntakeAllowedSenders) {
+			logger.Handler.Warn("intake: skipping email from a sender not on the allowlist", "from", msg.From)
+			return nil
+		}
+		s, ok := h.currentStore()
+		if !ok || s == nil {
+			return errors.New("no workspaces configured")
+		}
+		task, err := h.fileIntakeTask(ctx, s, msg, "email")
+		if err != nil {
+			return err
+		}
+		logger.Handler.Info("intake: filed email", "task", task.ID, "from", msg.From)
+		return nil
+	})
+	if err != nil {
+		logger.Handler.Warn("intake: poll mailbox", "mailbox", mailbox.Addr, "error", err)
+	}
+}
+
+// ServeTaskAttachment downloads one of a task's attachments:
+//
+//	GET /api/tasks/{id}/attachments/{name}
+//
+// Attachments come from outside the board, so they are always served as
+// downloads and never rendered inline.
+func (h *Handler) ServeTaskAttachment(w http.ResponseWriter, _ *http.Request, id uuid.UUID, name string) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	data, err := s.ReadTaskAttachment(id, name)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func newIntakeHandler(t *testing.T) *Handler {
+	t.Helper()
+	h := newStaticWorkspaceHandler(t, []string{t.TempDir()})
+	if err := os.WriteFile(h.envFile, []byte("WALLFACER_INTAKE_TOKEN=s3cret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestIntakeTask_DisabledWithoutToken(t *testing.T) {
+	h := newStaticWorkspaceHandler(t, []string{t.TempDir()})
+	w := httptest.NewRecorder()
+	h.IntakeTask(w, httptest.NewRequest(http.MethodPost, "/api/intake", strings.NewReader(`{"prompt":"p"}`)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestIntakeTask_RejectsWrongToken(t *testing.T) {
+	h := newIntakeHandler(t)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/intake", strings.NewReader(`{"prompt":"p","token":"guess"}`))
+	h.IntakeTask(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+	if tasks, _ := h.store.ListTasks(req.Context(), false); len(tasks) != 0 {
+		t.Errorf("created %d tasks", len(tasks))
+	}
+}
+
+func TestIntakeTask_JSONWithAttachment(t *testing.T) {
+	h := newIntakeHandler(t)
+	body := `{"title":"Broken export","prompt":"CSV export drops the last row.","from":"ops@example.com","tags":["billing"],
+		"attachments":[{"name":"report.csv","content_type":"text/csv","data":"YSxiCjEsMgo="}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/intake", strings.NewReader(body))
+	req.Header.Set(intakeTokenHeader, "s3cret")
+	w := httptest.NewRecorder()
+	h.IntakeTask(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp intakeResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	task := resp.Task
+	if task.Status != store.TaskStatusBacklog || task.Title != "Broken export" || task.Prompt != "Broken export\n\nCSV export drops the last row." {
+		t.Errorf("task = %q %q %q", task.Status, task.Title, task.Prompt)
+	}
+	if !slices.Equal(task.Tags, []string{"intake", "billing"}) {
+		t.Errorf("Tags = %v", task.Tags)
+	}
+	if len(task.Attachments) != 1 || task.Attachments[0].Name != "report.csv" || task.Attachments[0].Size != 8 {
+		t.Fatalf("Attachments = %+v", task.Attachments)
+	}
+	if resp.Text != "Filed on the board: Broken export" {
+		t.Errorf("Text = %q", resp.Text)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeTaskAttachment(w, httptest.NewRequest(http.MethodGet, "/", nil), task.ID, "report.csv")
+	if w.Code != http.StatusOK || w.Body.String() != "a,b\n1,2\n" {
+		t.Errorf("attachment = %d %q", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != "attachment; filename=report.csv" {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	w = httptest.NewRecorder()
+	h.ServeTaskAttachment(w, httptest.NewRequest(http.MethodGet, "/", nil), task.ID, "../task.json")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("traversal status = %d", w.Code)
+	}
+}
+
+func TestIntakeTask_SlackSlashCommand(t *testing.T) {
+	h := newIntakeHandler(t)
+	form := url.Values{"token": {"s3cret"}, "command": {"/wallfacer"}, "text": {"Add a dark mode toggle"}, "user_name": {"ada"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/intake", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.IntakeTask(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp intakeResponse
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Task == nil || resp.Task.Prompt != "Add a dark mode toggle" || !strings.HasPrefix(resp.Text, "Filed on the board: ") {
+		t.Errorf("response = %+v", resp)
+	}
+	events, _ := h.store.GetEvents(req.Context(), resp.Task.ID)
+	if !slices.ContainsFunc(events, func(e store.TaskEvent) bool { return strings.Contains(string(e.Data), "Filed via webhook by ada.") }) {
+		t.Error("no event records who filed the task")
+	}
+}
+
+func TestIntakeTask_RawEmail(t *testing.T) {
+	h := newIntakeHandler(t)
+	email := "From: ada@example.com\r\nSubject: Flaky login test\r\n\r\nThe login test fails one run in ten.\r\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/intake?token=s3cret", strings.NewReader(email))
+	req.Header.Set("Content-Type", "message/rfc822")
+	w := httptest.NewRecorder()
+	h.IntakeTask(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp intakeResponse
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Task.Title != "Flaky login test" || resp.Task.Prompt != "Flaky login test\n\nThe login test fails one run in ten." {
+		t.Errorf("task = %q %q", resp.Task.Title, resp.Task.Prompt)
+	}
+}
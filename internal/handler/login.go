@@ -78,8 +78,8 @@ func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
 }
 
 // Logout clears the session and redirects to the auth service logout.
-// Falls back to a bare cookie clear + redirect to "/" when auth is not
-// configured, so the endpoint remains safe to link to unconditionally.
+// Falls back to a bare cookie clear + redirect to the board root when auth
+// is not configured, so the endpoint remains safe to link to unconditionally.
 func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	// Clear the coordination token first so the connector stops dialing and
 	// drops its live connection: signing out must also stop pulling comments.
@@ -88,7 +88,7 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	}
 	if h.auth == nil {
 		auth.ClearSession(w)
-		http.Redirect(w, r, "/", http.StatusFound)
+		http.Redirect(w, r, BasePath(r)+"/", http.StatusFound)
 		return
 	}
 	h.auth.HandleLogout(w, r)
@@ -3,9 +3,11 @@ package handler
 import (
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 
-	"latere.ai/x/wallfacer/internal/auth"
+	"latere.ai/x/wallfacer/internal/apicontract"
 	"latere.ai/x/wallfacer/internal/constants"
 	"latere.ai/x/wallfacer/internal/pkg/httpjson"
 )
@@ -17,6 +19,7 @@ const (
 	BodyLimitDefault    = constants.BodyLimitDefault
 	BodyLimitFeedback   = constants.BodyLimitFeedback
 	BodyLimitWhiteboard = constants.BodyLimitWhiteboard
+	BodyLimitIntake     = constants.BodyLimitIntake
 )
 
 // MaxBytesMiddleware limits the size of the request body for downstream handlers.
@@ -29,6 +32,87 @@ func MaxBytesMiddleware(limit int64) func(http.Handler) http.Handler {
 	}
 }
 
+// APIVersionHeader is the response header reporting the API version that
+// served an /api/ request.
+const APIVersionHeader = "Wallfacer-API-Version"
+
+// versionedAPIPath matches any /api/v<N> prefix, supported or not.
+var versionedAPIPath = regexp.MustCompile(`^/api/v[0-9]+(/|$)`)
+
+// APIVersionMiddleware serves the versioned API. Requests under
+// apicontract.VersionPrefix are rewritten to the matching unversioned /api/
+// path, so the mux, path-based auth checks, and metrics see a single route
+// table; other /api/v<N> prefixes get a 404 naming the supported version.
+// Every /api/ response carries APIVersionHeader. It must wrap the rest of
+// the chain so path checks downstream see the rewritten path.
+func APIVersionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set(APIVersionHeader, apicontract.Version)
+		if !versionedAPIPath.MatchString(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		p, ok := stripAPIVersion(r.URL.Path)
+		if !ok {
+			httpjson.Write(w, http.StatusNotFound, map[string]string{
+				"error": "unsupported API version; supported: " + apicontract.Version,
+			})
+			return
+		}
+		// Shallow-copy the request the way http.StripPrefix does.
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = p
+		if r.URL.RawPath != "" {
+			r2.URL.RawPath, _ = stripAPIVersion(r.URL.RawPath)
+		}
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// stripAPIVersion maps a path under apicontract.VersionPrefix to its
+// unversioned /api/ form. It reports false for paths outside that prefix.
+func stripAPIVersion(p string) (string, bool) {
+	rest, ok := strings.CutPrefix(p, apicontract.VersionPrefix)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return "", false
+	}
+	return "/api" + rest, true
+}
+
+// DeprecationMiddleware adds the headers announcing a deprecated route:
+// Deprecation (RFC 9745), Sunset (RFC 8594) when a removal date is set, and
+// a successor-version Link when the route has a replacement.
+func DeprecationMiddleware(d apicontract.Deprecation) func(http.Handler) http.Handler {
+	deprecation := "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	var sunset, link string
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if d.Successor != "" {
+		link = "<" + apicontract.VersionedPath(d.Successor) + `>; rel="successor-version"`
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("Deprecation", deprecation)
+			if sunset != "" {
+				h.Set("Sunset", sunset)
+			}
+			if link != "" {
+				h.Add("Link", link)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // CSRFMiddleware validates the Origin/Referer header against the expected host.
 // Safe methods (GET, HEAD, OPTIONS) are always allowed. State-changing methods
 // require the Origin or Referer header to match either the server's known
@@ -36,9 +120,12 @@ func MaxBytesMiddleware(limit int64) func(http.Handler) http.Handler {
 // where the browser-visible address differs from the bind address (e.g. IP,
 // custom hostname, port-forwarding). When neither Origin nor Referer is present
 // the request is allowed through — this covers API clients and tools that
-// don't send browser-style origin headers.
-func CSRFMiddleware(serverHostPort string) func(http.Handler) http.Handler {
+// don't send browser-style origin headers. trustedOrigins lists further
+// origins (scheme://host[:port]) allowed to make state-changing requests,
+// normally the WALLFACER_CORS_ORIGINS that CORSMiddleware answers.
+func CSRFMiddleware(serverHostPort string, trustedOrigins ...string) func(http.Handler) http.Handler {
 	allowedHost := strings.TrimSpace(serverHostPort)
+	trusted := originSet(trustedOrigins)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Safe methods never need CSRF protection.
@@ -71,7 +158,7 @@ func CSRFMiddleware(serverHostPort string) func(http.Handler) http.Handler {
 			// or the Host header of this request (same-origin check). The Host
 			// header reflects the address the user actually typed, so this
 			// covers remote/IP access without weakening CSRF protection.
-			if parsed.Host == allowedHost || (r.Host != "" && parsed.Host == r.Host) {
+			if parsed.Host == allowedHost || (r.Host != "" && parsed.Host == r.Host) || trusted[originOf(parsed)] {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -80,52 +167,78 @@ func CSRFMiddleware(serverHostPort string) func(http.Handler) http.Handler {
 	}
 }
 
-// BearerAuthMiddleware enforces bearer-token authentication on non-SSE routes.
-// SSE and WebSocket paths use a ?token= query parameter instead of the
-// Authorization header because EventSource and WebSocket APIs do not support
-// custom request headers. The root path (GET /) is always public so the
-// browser can load the UI shell.
-func BearerAuthMiddleware(apiKey string) func(http.Handler) http.Handler {
-	key := strings.TrimSpace(apiKey)
-	if key == "" {
-		// No API key configured — authentication is disabled.
+// corsAllowMethods is the Access-Control-Allow-Methods value of a preflight
+// response: every method the API routes use.
+const corsAllowMethods = "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS"
+
+// CORSMiddleware lets browser apps on the configured origins call the API.
+// Each entry is an origin (scheme://host[:port]) or "*". A request whose
+// Origin matches an entry gets Access-Control-Allow-Origin echoing it, with
+// credentials allowed so cookie and bearer auth keep working; "*" matches any
+// origin but, as browsers require, without credentials. Preflight OPTIONS
+// requests from a matching origin are answered directly with 204. Requests
+// from other origins pass through without CORS headers, so the browser keeps
+// the response from the calling page. With no origins configured the
+// middleware is a no-op.
+func CORSMiddleware(origins []string) func(http.Handler) http.Handler {
+	allowed := originSet(origins)
+	if len(allowed) == 0 {
 		return func(next http.Handler) http.Handler { return next }
 	}
-	isSSEPath := func(path string) bool {
-		if path == "/api/tasks/stream" || path == "/api/git/stream" || path == "/api/terminal/ws" ||
-			path == "/api/explorer/stream" || path == "/api/explorer/file/stream" || path == "/api/specs/stream" {
-			return true
-		}
-		return strings.HasPrefix(path, "/api/tasks/") && strings.HasSuffix(path, "/logs")
-	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method == http.MethodGet && r.URL.Path == "/" {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
 				next.ServeHTTP(w, r)
 				return
 			}
-			// A request already authenticated by the upstream JWT
-			// middleware (cloud mode) bypasses the static-key check.
-			// Keeps cookie-only and JWT-bearer clients working even
-			// when WALLFACER_SERVER_API_KEY is set for script access.
-			if _, ok := auth.PrincipalFromContext(r.Context()); ok {
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			switch {
+			case allowed[strings.ToLower(origin)]:
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Set("Access-Control-Allow-Credentials", "true")
+			case allowed["*"]:
+				h.Set("Access-Control-Allow-Origin", "*")
+			default:
 				next.ServeHTTP(w, r)
 				return
 			}
-			if isSSEPath(r.URL.Path) {
-				if r.URL.Query().Get("token") != key {
-					httpjson.Write(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
-					return
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Set("Access-Control-Allow-Methods", corsAllowMethods)
+				if reqHeaders := r.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+					h.Set("Access-Control-Allow-Headers", reqHeaders)
 				}
-				next.ServeHTTP(w, r)
-				return
-			}
-			got := strings.TrimSpace(r.Header.Get("Authorization"))
-			if got != "Bearer "+key {
-				httpjson.Write(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				h.Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
 				return
 			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// originSet normalizes a list of origins into a lookup set: lower-cased,
+// without a trailing slash, empty entries dropped.
+func originSet(origins []string) map[string]bool {
+	set := make(map[string]bool, len(origins))
+	for _, o := range origins {
+		if o = strings.ToLower(strings.TrimRight(strings.TrimSpace(o), "/")); o != "" {
+			set[o] = true
+		}
+	}
+	return set
+}
+
+// originOf returns the lower-cased scheme://host origin of u.
+func originOf(u *url.URL) string {
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// BearerAuthMiddleware enforces bearer-token authentication with a single
+// static key and no UI sessions. See APIAuth.Middleware for the public
+// routes and the ?token= rule of SSE and WebSocket paths; an empty key
+// disables the check.
+func BearerAuthMiddleware(apiKey string) func(http.Handler) http.Handler {
+	return NewAPIAuth([]string{apiKey}, nil, false).Middleware
+}
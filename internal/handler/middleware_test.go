@@ -8,7 +8,9 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"latere.ai/x/wallfacer/internal/apicontract"
 	"latere.ai/x/wallfacer/internal/auth"
 	"latere.ai/x/wallfacer/internal/pkg/httpjson"
 )
@@ -129,6 +131,88 @@ func TestCSRFMiddlewareRemoteAccess(t *testing.T) {
 	}
 }
 
+// TestCSRFMiddlewareTrustedOrigins verifies that a configured CORS origin may
+// make state-changing requests, matched on scheme as well as host.
+func TestCSRFMiddlewareTrustedOrigins(t *testing.T) {
+	next := CSRFMiddleware("localhost:8080", "https://app.example/")(ok204())
+	for origin, want := range map[string]int{
+		"https://app.example":  http.StatusNoContent,
+		"HTTPS://APP.EXAMPLE":  http.StatusNoContent,
+		"http://app.example":   http.StatusForbidden,
+		"https://evil.example": http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		next.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("Origin %s: status = %d, want %d", origin, w.Code, want)
+		}
+	}
+}
+
+// TestCORSMiddleware verifies CORS headers for allowed and unknown origins
+// and that preflights are answered without reaching the handler.
+func TestCORSMiddleware(t *testing.T) {
+	reached := false
+	next := CORSMiddleware([]string{"https://app.example"})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	req.Header.Set("Origin", "https://app.example")
+	w := httptest.NewRecorder()
+	next.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+		t.Errorf("Allow-Origin = %q, want the request origin", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Allow-Credentials = %q, want true", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	w = httptest.NewRecorder()
+	next.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("unknown origin: Allow-Origin = %q, want none", got)
+	}
+
+	reached = false
+	req = httptest.NewRequest(http.MethodOptions, "/api/tasks", nil)
+	req.Header.Set("Origin", "https://app.example")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+	w = httptest.NewRecorder()
+	next.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent || reached {
+		t.Fatalf("preflight: status = %d, reached handler = %v; want 204 answered by the middleware", w.Code, reached)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Headers"); got != "Authorization, Content-Type" {
+		t.Errorf("Allow-Headers = %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, http.MethodPost) {
+		t.Errorf("Allow-Methods = %q, want POST listed", got)
+	}
+}
+
+// TestCORSMiddlewareWildcard verifies that "*" allows any origin but never
+// with credentials.
+func TestCORSMiddlewareWildcard(t *testing.T) {
+	next := CORSMiddleware([]string{"*"})(ok204())
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	req.Header.Set("Origin", "https://anything.example")
+	w := httptest.NewRecorder()
+	next.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Allow-Origin = %q, want *", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Allow-Credentials = %q, want none", got)
+	}
+}
+
 // TestBearerAuthMiddleware validates bearer-token auth across public routes,
 // SSE query-token paths, and standard Authorization header paths.
 func TestBearerAuthMiddleware(t *testing.T) {
@@ -146,6 +230,8 @@ func TestBearerAuthMiddleware(t *testing.T) {
 		{name: "sse query token", method: http.MethodGet, target: "/api/tasks/stream?token=secret", want: http.StatusNoContent},
 		{name: "sse wrong header only", method: http.MethodGet, target: "/api/tasks/stream", headers: map[string]string{"Authorization": "Bearer secret"}, want: http.StatusUnauthorized, wantErr: "unauthorized"},
 		{name: "logs sse query token", method: http.MethodGet, target: "/api/tasks/123/logs?token=secret", want: http.StatusNoContent},
+		{name: "updates ws query token", method: http.MethodGet, target: "/api/ws?token=secret", want: http.StatusNoContent},
+		{name: "intake checks its own token", method: http.MethodPost, target: "/api/intake", want: http.StatusNoContent},
 	}
 
 	mw := BearerAuthMiddleware("secret")
@@ -257,3 +343,63 @@ func TestMaxBytesMiddleware_AllowsSmallBody(t *testing.T) {
 		t.Errorf("expected 200 for small body, got %d", w.Code)
 	}
 }
+
+func TestAPIVersionMiddleware(t *testing.T) {
+	var gotPath string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mw := APIVersionMiddleware(next)
+
+	cases := []struct {
+		path, wantPath, wantVersion string
+		wantCode                    int
+	}{
+		{"/api/v1/tasks", "/api/tasks", "v1", http.StatusNoContent},
+		{"/api/v1/tasks/abc/events", "/api/tasks/abc/events", "v1", http.StatusNoContent},
+		{"/api/tasks", "/api/tasks", "v1", http.StatusNoContent},
+		{"/api/v2/tasks", "", "v1", http.StatusNotFound},
+		{"/api/v1x/tasks", "/api/v1x/tasks", "v1", http.StatusNoContent}, // not a version prefix
+		{"/", "/", "", http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		gotPath = ""
+		w := httptest.NewRecorder()
+		mw.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.wantCode || gotPath != tc.wantPath {
+			t.Errorf("%s: code=%d path=%q, want %d %q", tc.path, w.Code, gotPath, tc.wantCode, tc.wantPath)
+		}
+		if got := w.Header().Get(APIVersionHeader); got != tc.wantVersion {
+			t.Errorf("%s: %s = %q, want %q", tc.path, APIVersionHeader, got, tc.wantVersion)
+		}
+	}
+}
+
+func TestDeprecationMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
+	d := apicontract.Deprecation{
+		Since:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:    time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+		Successor: "/api/tasks/{id}/move",
+	}
+	w := httptest.NewRecorder()
+	DeprecationMiddleware(d)(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/old", nil))
+
+	if got, want := w.Header().Get("Deprecation"), "@1767225600"; got != want {
+		t.Errorf("Deprecation = %q, want %q", got, want)
+	}
+	if got, want := w.Header().Get("Sunset"), "Tue, 30 Jun 2026 00:00:00 GMT"; got != want {
+		t.Errorf("Sunset = %q, want %q", got, want)
+	}
+	if got, want := w.Header().Get("Link"), `</api/v1/tasks/{id}/move>; rel="successor-version"`; got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+
+	// No sunset or successor: only the Deprecation header.
+	w = httptest.NewRecorder()
+	DeprecationMiddleware(apicontract.Deprecation{Since: d.Since})(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/old", nil))
+	if w.Header().Get("Sunset") != "" || w.Header().Get("Link") != "" {
+		t.Errorf("unexpected headers: %v", w.Header())
+	}
+}
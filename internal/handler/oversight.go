@@ -26,7 +26,7 @@ func (h *Handler) GetOversight(w http.ResponseWriter, r *http.Request, id uuid.U
 		return
 	}
 	if _, err := s.GetTask(r.Context(), id); err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -61,6 +61,7 @@ func (h *Handler) GenerateMissingOversight(w http.ResponseWriter, r *http.Reques
 	terminal := map[store.TaskStatus]bool{
 		store.TaskStatusDone:      true,
 		store.TaskStatusWaiting:   true,
+		store.TaskStatusReview:    true,
 		store.TaskStatusFailed:    true,
 		store.TaskStatusCancelled: true,
 	}
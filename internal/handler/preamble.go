@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// preambleResponse is the JSON shape of GET /api/preamble. Current is the
+// zero Preamble (version 0) when none has been saved.
+type preambleResponse struct {
+	Current  store.Preamble   `json:"current"`
+	Versions []store.Preamble `json:"versions"`
+}
+
+// GetPreamble handles GET /api/preamble. It returns the board's current
+// prompt preamble and every saved version, newest first, so a task's
+// preamble_version can be resolved to the text it ran with.
+func (h *Handler) GetPreamble(w http.ResponseWriter, _ *http.Request) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	cur, _ := s.CurrentPreamble()
+	versions := s.PreambleHistory()
+	if versions == nil {
+		versions = []store.Preamble{}
+	}
+	httpjson.Write(w, http.StatusOK, preambleResponse{Current: cur, Versions: versions})
+}
+
+// UpdatePreamble handles PUT /api/preamble. Body {"text": "..."} saves a new
+// preamble version, prefixed to every fresh agent prompt on the board from
+// then on; an empty text turns the preamble off. Saving the current text
+// again returns the current version unchanged.
+func (h *Handler) UpdatePreamble(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	req, ok := httpjson.DecodeBody[struct {
+		Text *string `json:"text"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	if req.Text == nil {
+		writeFieldError(w, "text", "must be set (send an empty string to turn the preamble off)")
+		return
+	}
+	if len(*req.Text) > store.MaxPreambleBytes {
+		writeFieldError(w, "text", "must be at most %d bytes (got %d)", store.MaxPreambleBytes, len(*req.Text))
+		return
+	}
+	p, err := s.SetPreamble(*req.Text)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, p)
+}
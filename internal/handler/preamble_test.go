@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func callUpdatePreamble(h *Handler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.UpdatePreamble(w, httptest.NewRequest(http.MethodPut, "/api/preamble", strings.NewReader(body)))
+	return w
+}
+
+func TestPreamble_SaveAndList(t *testing.T) {
+	h := newTestHandler(t)
+
+	w := httptest.NewRecorder()
+	h.GetPreamble(w, httptest.NewRequest(http.MethodGet, "/api/preamble", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"versions":[]`) {
+		t.Fatalf("empty GET = %d %s", w.Code, w.Body.String())
+	}
+
+	for _, text := range []string{"Always add tests.", "Use gofmt."} {
+		if w := callUpdatePreamble(h, `{"text":"`+text+`"}`); w.Code != http.StatusOK {
+			t.Fatalf("PUT %q = %d %s", text, w.Code, w.Body.String())
+		}
+	}
+	w = callUpdatePreamble(h, `{"text":"Use gofmt."}`)
+	var saved store.Preamble
+	if err := json.Unmarshal(w.Body.Bytes(), &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != 2 {
+		t.Errorf("repeated save = %+v, want version 2", saved)
+	}
+
+	w = httptest.NewRecorder()
+	h.GetPreamble(w, httptest.NewRequest(http.MethodGet, "/api/preamble", nil))
+	var resp preambleResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Current.Version != 2 || resp.Current.Text != "Use gofmt." {
+		t.Errorf("current = %+v", resp.Current)
+	}
+	if len(resp.Versions) != 2 || resp.Versions[1].Text != "Always add tests." {
+		t.Errorf("versions = %+v", resp.Versions)
+	}
+}
+
+func TestUpdatePreamble_Validation(t *testing.T) {
+	h := newTestHandler(t)
+	if fields := decodeValidation(t, callUpdatePreamble(h, `{}`)); !slices.Equal(fields, []string{"text"}) {
+		t.Errorf("missing text fields = %v", fields)
+	}
+	long, _ := json.Marshal(map[string]string{"text": strings.Repeat("x", store.MaxPreambleBytes+1)})
+	if fields := decodeValidation(t, callUpdatePreamble(h, string(long))); !slices.Equal(fields, []string{"text"}) {
+		t.Errorf("oversized text fields = %v", fields)
+	}
+}
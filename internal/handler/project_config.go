@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/projectconfig"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// withProjectDefaults fills in the fields a new task left unset from the
+// wallfacer.yaml files of the active workspace folders: the agent's harness
+// and model and the merge mode. The files' labels are added to its tags.
+func (h *Handler) withProjectDefaults(opts store.TaskCreateOptions) store.TaskCreateOptions {
+	cfg, err := projectconfig.LoadAll(h.currentWorkspaces())
+	if err != nil {
+		logger.Handler.Warn("load "+projectconfig.Filename, "error", err)
+	}
+	if opts.Sandbox == "" {
+		opts.Sandbox = cfg.Agent.Sandbox
+	}
+	if opts.ModelOverride == "" {
+		opts.ModelOverride = cfg.Agent.Model
+	}
+	if opts.MergeMode == store.MergeModeMerge {
+		opts.MergeMode = cfg.MergeMode
+	}
+	opts.Tags = cfg.AddLabels(opts.Tags)
+	return opts
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/projectconfig"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestCreateTask_ProjectDefaults(t *testing.T) {
+	h, ws := newTestHandlerWithWorkspaces(t)
+	cfg := "agent:\n  sandbox: codex\n  model: gpt-5-codex\nmerge_mode: pull_request\nlabels: [backend]\n"
+	if err := os.WriteFile(filepath.Join(ws, projectconfig.Filename), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	create := func(body string) store.Task {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.CreateTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks", strings.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var task store.Task
+		if err := json.Unmarshal(w.Body.Bytes(), &task); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return task
+	}
+
+	task := create(`{"prompt": "add an endpoint", "timeout": 15, "tags": ["api"]}`)
+	if task.Sandbox != harness.Codex || task.ModelOverride == nil || *task.ModelOverride != "gpt-5-codex" {
+		t.Errorf("sandbox = %q, model = %v, want the project's agent", task.Sandbox, task.ModelOverride)
+	}
+	if task.MergeMode != store.MergeModePullRequest {
+		t.Errorf("merge_mode = %q, want pull_request", task.MergeMode)
+	}
+	if !slices.Equal(task.Tags, []string{"api", "backend"}) {
+		t.Errorf("tags = %q", task.Tags)
+	}
+
+	task = create(`{"prompt": "fix a typo", "timeout": 15, "merge_mode": "merge", "model": "opus"}`)
+	if task.MergeMode != store.MergeModeMerge || task.ModelOverride == nil || *task.ModelOverride != "opus" {
+		t.Errorf("merge_mode = %q, model = %v, want the request's", task.MergeMode, task.ModelOverride)
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/scaffold"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// setupTaskTitle is the title of the first task on a scaffolded board.
+const setupTaskTitle = "Set up the project"
+
+// ListProjectTemplates returns the starter templates `wallfacer new` and
+// the scaffold endpoint accept.
+func (h *Handler) ListProjectTemplates(w http.ResponseWriter, _ *http.Request) {
+	httpjson.Write(w, http.StatusOK, map[string]any{"templates": scaffold.Templates()})
+}
+
+// ScaffoldProject creates a project from a starter template in a new or
+// empty directory, commits it to a fresh git repository, registers it as a
+// workspace, activates that workspace, and adds a backlog task carrying the
+// template's setup instructions.
+func (h *Handler) ScaffoldProject(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := scaffold.Lookup(id); !ok {
+		http.Error(w, "unknown project template", http.StatusNotFound)
+		return
+	}
+	req, ok := httpjson.DecodeBody[struct {
+		Dir    string `json:"dir"`
+		Name   string `json:"name"`
+		Module string `json:"module"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	if !filepath.IsAbs(req.Dir) {
+		http.Error(w, "dir must be absolute", http.StatusBadRequest)
+		return
+	}
+	res, err := scaffold.Create(scaffold.Options{Template: id, Dir: req.Dir, Name: req.Name, Module: req.Module})
+	switch {
+	case errors.Is(err, scaffold.ErrNotEmpty):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, scaffold.ErrInvalidName):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ws, err := h.workspace.Create(res.Name, []string{res.Dir}, h.ownerPrincipal(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	snap, err := h.workspace.SwitchByID(ws.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if snap.Store == nil {
+		http.Error(w, "scaffolded workspace has no task store", http.StatusInternalServerError)
+		return
+	}
+	h.runner.PruneUnknownWorktrees()
+
+	task, err := snap.Store.CreateTaskWithOptions(r.Context(), store.TaskCreateOptions{
+		Prompt: res.SetupPrompt,
+		Tags:   []string{"setup", res.Template.ID},
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := snap.Store.UpdateTaskTitle(r.Context(), task.ID, setupTaskTitle); err == nil {
+		task.Title = setupTaskTitle
+	}
+	httpjson.Write(w, http.StatusCreated, map[string]any{
+		"project":   res,
+		"workspace": h.workspaceDTO(ws),
+		"task":      task,
+	})
+}
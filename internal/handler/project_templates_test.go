@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestListProjectTemplates(t *testing.T) {
+	h := newTestHandler(t)
+	rec := httptest.NewRecorder()
+	h.ListProjectTemplates(rec, httptest.NewRequest(http.MethodGet, "/api/project-templates", nil))
+	var resp struct {
+		Templates []struct {
+			ID string `json:"id"`
+		} `json:"templates"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	ids := map[string]bool{}
+	for _, tmpl := range resp.Templates {
+		ids[tmpl.ID] = true
+	}
+	if !ids["go-service"] || !ids["nextjs-app"] {
+		t.Fatalf("templates = %+v, want go-service and nextjs-app", resp.Templates)
+	}
+}
+
+func scaffoldRequest(id string, body map[string]any) *http.Request {
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/api/project-templates/"+id+"/scaffold", bytes.NewReader(b))
+	req.SetPathValue("id", id)
+	return req
+}
+
+// TestScaffoldProject checks the whole flow: files and git repository on
+// disk, a new active workspace over the directory, and a backlog setup task
+// on its board.
+func TestScaffoldProject(t *testing.T) {
+	h, _, _ := newTestHandlerWithRealWorkspaceManager(t)
+	dir := filepath.Join(t.TempDir(), "billing")
+
+	rec := httptest.NewRecorder()
+	h.ScaffoldProject(rec, scaffoldRequest("go-service", map[string]any{"dir": dir}))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("scaffold: got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Workspace workspaceDTO `json:"workspace"`
+		Task      store.Task   `json:"task"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		t.Errorf("no git repository in %s: %v", dir, err)
+	}
+	if resp.Workspace.Name != "billing" || len(resp.Workspace.Folders) != 1 || resp.Workspace.Folders[0] != dir {
+		t.Errorf("workspace = %+v, want billing over %s", resp.Workspace, dir)
+	}
+	if h.activeWorkspaceID() != resp.Workspace.ID {
+		t.Errorf("active workspace = %q, want %q", h.activeWorkspaceID(), resp.Workspace.ID)
+	}
+	s, ok := h.currentStore()
+	if !ok {
+		t.Fatal("no active store after scaffold")
+	}
+	task, err := s.GetTask(t.Context(), resp.Task.ID)
+	if err != nil {
+		t.Fatalf("setup task not on the new board: %v", err)
+	}
+	if task.Status != store.TaskStatusBacklog || task.Title != setupTaskTitle || task.Prompt == "" {
+		t.Errorf("setup task = status %s, title %q, prompt %q", task.Status, task.Title, task.Prompt)
+	}
+}
+
+func TestScaffoldProject_Errors(t *testing.T) {
+	h, _, _ := newTestHandlerWithRealWorkspaceManager(t)
+	nonEmpty := t.TempDir()
+	if err := os.WriteFile(filepath.Join(nonEmpty, "main.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		id   string
+		body map[string]any
+		want int
+	}{
+		{"unknown template", "rails", map[string]any{"dir": filepath.Join(t.TempDir(), "p")}, http.StatusNotFound},
+		{"relative dir", "go-service", map[string]any{"dir": "p"}, http.StatusBadRequest},
+		{"not empty", "go-service", map[string]any{"dir": nonEmpty, "name": "p"}, http.StatusConflict},
+		{"bad name", "go-service", map[string]any{"dir": filepath.Join(t.TempDir(), "p"), "name": "My App"}, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ScaffoldProject(rec, scaffoldRequest(tc.id, tc.body))
+			if rec.Code != tc.want {
+				t.Errorf("got %d, want %d: %s", rec.Code, tc.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/pkg/watcher"
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/webpush"
+	"latere.ai/x/wallfacer/internal/workspace"
+)
+
+// SetWebPush wires the Web Push service. Call before StartPushNotifier.
+func (h *Handler) SetWebPush(svc *webpush.Service) { h.push = svc }
+
+// pushUser returns the registry owner key for the caller: the principal's
+// subject when signed in, "" for the anonymous local user.
+func pushUser(r *http.Request) string {
+	if p := principalFromRequest(r); p != nil {
+		return p.Sub
+	}
+	return ""
+}
+
+// GetPushConfig returns whether Web Push is available and, when it is, the
+// VAPID public key the browser must pass to PushManager.subscribe.
+func (h *Handler) GetPushConfig(w http.ResponseWriter, _ *http.Request) {
+	if h.push == nil {
+		httpjson.Write(w, http.StatusOK, map[string]any{"enabled": false})
+		return
+	}
+	httpjson.Write(w, http.StatusOK, map[string]any{
+		"enabled":    true,
+		"public_key": h.push.Keys.PublicKey,
+	})
+}
+
+// requirePush returns the push service or writes 503 when it is not wired.
+func (h *Handler) requirePush(w http.ResponseWriter) (*webpush.Service, bool) {
+	if h.push == nil {
+		http.Error(w, "web push is not enabled", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	return h.push, true
+}
+
+// ListPushSubscriptions returns the caller's push subscriptions.
+func (h *Handler) ListPushSubscriptions(w http.ResponseWriter, r *http.Request) {
+	svc, ok := h.requirePush(w)
+	if !ok {
+		return
+	}
+	subs := svc.Registry.List(pushUser(r))
+	if subs == nil {
+		subs = []webpush.Subscription{}
+	}
+	httpjson.Write(w, http.StatusOK, subs)
+}
+
+// CreatePushSubscription registers (or refreshes) a browser subscription
+// for the caller. The body is PushSubscription.toJSON().
+func (h *Handler) CreatePushSubscription(w http.ResponseWriter, r *http.Request) {
+	svc, ok := h.requirePush(w)
+	if !ok {
+		return
+	}
+	req, ok := httpjson.DecodeBody[webpush.Subscription](w, r)
+	if !ok {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sub := webpush.Subscription{
+		Endpoint:  req.Endpoint,
+		Keys:      req.Keys,
+		User:      pushUser(r),
+		UserAgent: r.UserAgent(),
+		CreatedAt: time.Now(),
+	}
+	if err := svc.Registry.Add(sub); err != nil {
+		http.Error(w, "save subscription: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	httpjson.Write(w, http.StatusCreated, sub)
+}
+
+// DeletePushSubscription removes one of the caller's subscriptions by
+// endpoint. Returns 404 when the caller owns no subscription for it.
+func (h *Handler) DeletePushSubscription(w http.ResponseWriter, r *http.Request) {
+	svc, ok := h.requirePush(w)
+	if !ok {
+		return
+	}
+	req, ok := httpjson.DecodeBody[struct {
+		Endpoint string `json:"endpoint"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	removed, err := svc.Registry.Remove(req.Endpoint, pushUser(r))
+	if err != nil {
+		http.Error(w, "remove subscription: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "subscription not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestPushNotification sends a sample notification to every subscription
+// the caller owns so the settings UI can confirm delivery end to end.
+func (h *Handler) TestPushNotification(w http.ResponseWriter, r *http.Request) {
+	svc, ok := h.requirePush(w)
+	if !ok {
+		return
+	}
+	n := svc.Notify(r.Context(), pushUser(r), webpush.Message{
+		Title: "Wallfacer notifications are on",
+		Body:  "Task updates will appear here while the board is in the background.",
+		Tag:   "wallfacer-test",
+		URL:   "/",
+		Kind:  "test",
+	})
+	httpjson.Write(w, http.StatusOK, map[string]int{"delivered": n})
+}
+
+// pushPreferencesResponse is the JSON shape of GET /api/push/preferences.
+type pushPreferencesResponse struct {
+	// CurrentBoard is the key of the viewed board, for per-board entries.
+	CurrentBoard string `json:"current_board"`
+	// Effective is what applies to the caller on the viewed board.
+	Effective webpush.Preferences `json:"effective"`
+	// Entries are the caller's stored preferences; an empty board applies
+	// to every board without its own entry.
+	Entries []webpush.PreferenceEntry `json:"entries"`
+	Events  []string                  `json:"events"`
+}
+
+// currentBoardKey identifies the viewed board in notification preferences.
+func (h *Handler) currentBoardKey() string {
+	return workspace.GroupKey(h.currentWorkspaces())
+}
+
+// GetPushPreferences returns the caller's notification preferences.
+func (h *Handler) GetPushPreferences(w http.ResponseWriter, r *http.Request) {
+	svc, ok := h.requirePush(w)
+	if !ok {
+		return
+	}
+	user, board := pushUser(r), h.currentBoardKey()
+	entries := svc.Preferences.List(user)
+	if entries == nil {
+		entries = []webpush.PreferenceEntry{}
+	}
+	httpjson.Write(w, http.StatusOK, pushPreferencesResponse{
+		CurrentBoard: board,
+		Effective:    svc.Preferences.Get(user, board),
+		Entries:      entries,
+		Events:       webpush.Events,
+	})
+}
+
+// UpdatePushPreferences stores the caller's preferences for one board, or
+// for every board when board is empty.
+func (h *Handler) UpdatePushPreferences(w http.ResponseWriter, r *http.Request) {
+	svc, ok := h.requirePush(w)
+	if !ok {
+		return
+	}
+	req, ok := httpjson.DecodeBody[webpush.PreferenceEntry](w, r)
+	if !ok {
+		return
+	}
+	if err := req.Preferences.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	user := pushUser(r)
+	if err := svc.Preferences.Set(user, req.Board, req.Preferences); err != nil {
+		http.Error(w, "save preferences: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, webpush.PreferenceEntry{User: user, Board: req.Board, Preferences: req.Preferences})
+}
+
+// DeletePushPreferences removes the caller's preferences for one board so
+// the all-boards entry (or the default) applies again. Returns 404 when the
+// caller has no entry for it.
+func (h *Handler) DeletePushPreferences(w http.ResponseWriter, r *http.Request) {
+	svc, ok := h.requirePush(w)
+	if !ok {
+		return
+	}
+	req, ok := httpjson.DecodeBody[struct {
+		Board string `json:"board"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	removed, err := svc.Preferences.Delete(pushUser(r), req.Board)
+	if err != nil {
+		http.Error(w, "remove preferences: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "preferences not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pushMessageForTransition returns the notification for a task entering
+// status next, or false when the transition is not worth a notification.
+// Only the states that need the user (or tell them work is over) notify.
+func pushMessageForTransition(t *store.Task, next store.TaskStatus) (webpush.Message, bool) {
+	var title string
+	switch next {
+	case store.TaskStatusDone:
+		title = "Task done"
+	case store.TaskStatusWaiting:
+		title = "Task needs feedback"
+	case store.TaskStatusReview:
+		title = "Task awaiting merge approval"
+	case store.TaskStatusFailed:
+		title = "Task failed"
+	default:
+		return webpush.Message{}, false
+	}
+	return webpush.Message{
+		Title: title,
+		Body:  cmp.Or(t.Title, truncateRunes(t.Prompt, 120)),
+		Tag:   "task-" + t.ID.String(),
+		URL:   "/?task=" + t.ID.String(),
+		Kind:  string(next),
+	}, true
+}
+
+// pushTransitions diffs tasks against the statuses seen on the previous
+// scan and returns the notifications to send, one for each of a task's
+// recipients (its creator and watchers).
+// Tasks not present in seen are recorded without notifying, so startup and
+// workspace switches do not replay every finished task. seen is replaced in
+// place with the current statuses.
+func pushTransitions(tasks []store.Task, seen map[uuid.UUID]store.TaskStatus) []pushDelivery {
+	var out []pushDelivery
+	current := make(map[uuid.UUID]store.TaskStatus, len(tasks))
+	for i := range tasks {
+		t := &tasks[i]
+		current[t.ID] = t.Status
+		prev, known := seen[t.ID]
+		if !known || prev == t.Status || t.IsRoutine() {
+			continue
+		}
+		if msg, ok := pushMessageForTransition(t, t.Status); ok {
+			for _, user := range t.Recipients() {
+				out = append(out, pushDelivery{user: user, tags: t.Tags, msg: msg})
+			}
+		}
+	}
+	clear(seen)
+	for id, st := range current {
+		seen[id] = st
+	}
+	return out
+}
+
+// pushDelivery is one notification addressed to a registry owner. tags are
+// the task's tags, matched against the owner's preference routes.
+type pushDelivery struct {
+	user string
+	tags []string
+	msg  webpush.Message
+}
+
+// deliverPush sends d in the background, holds it for later, or drops it,
+// as the owner's notification preferences for board say. It is a no-op when
+// push is not wired or the owner has no subscription.
+func (h *Handler) deliverPush(ctx context.Context, d pushDelivery, board string, now time.Time) {
+	if h.push == nil || len(h.push.Registry.List(d.user)) == 0 {
+		return
+	}
+	send, at := h.push.Preferences.Get(d.user, board).Plan(d.msg.Kind, d.tags, now)
+	if !send {
+		return
+	}
+	if !at.IsZero() {
+		if err := h.push.Outbox.Hold(d.user, d.msg, at); err != nil {
+			logger.Handler.Warn("push notifier: hold notification", "error", err)
+		}
+		return
+	}
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+		defer cancel()
+		h.push.Notify(sendCtx, d.user, d.msg)
+	}()
+}
+
+// pushFlushInterval is how often held notifications (digests and those
+// deferred by quiet hours) are checked for their send time.
+const pushFlushInterval = time.Minute
+
+// StartPushNotifier watches task state changes and sends a Web Push
+// notification when a task finishes, fails, or starts waiting for feedback,
+// as filtered and scheduled by the owner's notification preferences. Held
+// notifications are flushed by a second, ticker-driven loop. It is a no-op
+// when push is not wired.
+func (h *Handler) StartPushNotifier(ctx context.Context) {
+	if h.push == nil {
+		return
+	}
+	seen := make(map[uuid.UUID]store.TaskStatus)
+	scan := func(ctx context.Context) {
+		s, ok := h.currentStore()
+		if !ok || s == nil {
+			return
+		}
+		tasks, err := s.ListTasks(ctx, false)
+		if err != nil {
+			logger.Handler.Warn("push notifier: list tasks", "error", err)
+			return
+		}
+		board, now := h.currentBoardKey(), time.Now()
+		for _, d := range pushTransitions(tasks, seen) {
+			h.deliverPush(ctx, d, board, now)
+		}
+	}
+	watcher.Start(ctx, watcher.Config{
+		Wake:   h.newResubscribingWakeSource(),
+		Init:   scan,
+		Action: scan,
+	})
+	watcher.Start(ctx, watcher.Config{
+		Interval: pushFlushInterval,
+		Action: func(ctx context.Context) {
+			if _, err := h.push.FlushDue(ctx, time.Now()); err != nil {
+				logger.Handler.Warn("push notifier: flush held notifications", "error", err)
+			}
+		},
+	})
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/webpush"
+)
+
+func newTestHandlerWithPush(t *testing.T) *Handler {
+	t.Helper()
+	h := newTestHandler(t)
+	svc, err := webpush.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SetWebPush(svc)
+	return h
+}
+
+func testPushSubscriptionJSON(t *testing.T, endpoint string) []byte {
+	t.Helper()
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	_, _ = rand.Read(auth)
+	body, _ := json.Marshal(map[string]any{
+		"endpoint": endpoint,
+		"keys": map[string]string{
+			"p256dh": base64.RawURLEncoding.EncodeToString(priv.PublicKey().Bytes()),
+			"auth":   base64.RawURLEncoding.EncodeToString(auth),
+		},
+	})
+	return body
+}
+
+func TestGetPushConfig(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.GetPushConfig(w, httptest.NewRequest(http.MethodGet, "/api/push/config", nil))
+	var off map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &off)
+	if off["enabled"] != false || off["public_key"] != nil {
+		t.Errorf("without push: %v", off)
+	}
+
+	h = newTestHandlerWithPush(t)
+	w = httptest.NewRecorder()
+	h.GetPushConfig(w, httptest.NewRequest(http.MethodGet, "/api/push/config", nil))
+	var on map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &on)
+	if on["enabled"] != true || on["public_key"] != h.push.Keys.PublicKey {
+		t.Errorf("with push: %v", on)
+	}
+}
+
+func TestPushSubscriptions_Unavailable(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.ListPushSubscriptions(w, httptest.NewRequest(http.MethodGet, "/api/push/subscriptions", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+}
+
+func TestPushSubscriptions_Lifecycle(t *testing.T) {
+	h := newTestHandlerWithPush(t)
+	const endpoint = "https://push.example.com/send/abc"
+
+	w := httptest.NewRecorder()
+	h.CreatePushSubscription(w, httptest.NewRequest(http.MethodPost, "/api/push/subscriptions",
+		bytes.NewReader(testPushSubscriptionJSON(t, endpoint))))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ListPushSubscriptions(w, httptest.NewRequest(http.MethodGet, "/api/push/subscriptions", nil))
+	var subs []webpush.Subscription
+	if err := json.Unmarshal(w.Body.Bytes(), &subs); err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Endpoint != endpoint {
+		t.Fatalf("list = %+v", subs)
+	}
+
+	del := func() int {
+		w := httptest.NewRecorder()
+		h.DeletePushSubscription(w, httptest.NewRequest(http.MethodDelete, "/api/push/subscriptions",
+			bytes.NewReader([]byte(`{"endpoint":"`+endpoint+`"}`))))
+		return w.Code
+	}
+	if code := del(); code != http.StatusNoContent {
+		t.Errorf("delete: expected 204, got %d", code)
+	}
+	if code := del(); code != http.StatusNotFound {
+		t.Errorf("second delete: expected 404, got %d", code)
+	}
+}
+
+func TestCreatePushSubscription_RejectsInvalid(t *testing.T) {
+	h := newTestHandlerWithPush(t)
+	w := httptest.NewRecorder()
+	h.CreatePushSubscription(w, httptest.NewRequest(http.MethodPost, "/api/push/subscriptions",
+		bytes.NewReader(testPushSubscriptionJSON(t, "http://insecure.example.com/x"))))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+	if len(h.push.Registry.All()) != 0 {
+		t.Error("invalid subscription was stored")
+	}
+}
+
+func TestPushTransitions(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	seen := map[uuid.UUID]store.TaskStatus{}
+
+	// First scan only records statuses.
+	first := []store.Task{
+		{ID: a, Status: store.TaskStatusInProgress, Title: "A", CreatedBy: "alice"},
+		{ID: b, Status: store.TaskStatusDone, Title: "B"},
+	}
+	if got := pushTransitions(first, seen); len(got) != 0 {
+		t.Fatalf("first scan notified: %+v", got)
+	}
+
+	second := []store.Task{
+		{ID: a, Status: store.TaskStatusWaiting, Title: "A", CreatedBy: "alice", Tags: []string{"urgent"}},
+		{ID: b, Status: store.TaskStatusDone, Title: "B"},          // unchanged
+		{ID: c, Status: store.TaskStatusFailed, Prompt: "new one"}, // first seen
+	}
+	got := pushTransitions(second, seen)
+	if len(got) != 1 {
+		t.Fatalf("expected one notification, got %+v", got)
+	}
+	if got[0].user != "alice" || len(got[0].tags) != 1 || got[0].msg.Kind != string(store.TaskStatusWaiting) ||
+		got[0].msg.Title != "Task needs feedback" || got[0].msg.URL != "/?task="+a.String() {
+		t.Errorf("notification = %+v", got[0])
+	}
+	if _, ok := seen[c]; !ok {
+		t.Error("newly seen task not recorded")
+	}
+
+	// Deleted tasks drop out of seen.
+	pushTransitions([]store.Task{{ID: c, Status: store.TaskStatusFailed}}, seen)
+	if _, ok := seen[a]; ok || len(seen) != 1 {
+		t.Errorf("seen = %v, want only %s", seen, c)
+	}
+}
+
+func TestPushPreferences_Lifecycle(t *testing.T) {
+	h := newTestHandlerWithPush(t)
+	get := func() pushPreferencesResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.GetPushPreferences(w, httptest.NewRequest(http.MethodGet, "/api/push/preferences", nil))
+		var resp pushPreferencesResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	put := func(body string) int {
+		w := httptest.NewRecorder()
+		h.UpdatePushPreferences(w, httptest.NewRequest(http.MethodPut, "/api/push/preferences", bytes.NewReader([]byte(body))))
+		return w.Code
+	}
+
+	if resp := get(); len(resp.Entries) != 0 || resp.Effective.Delivery != "" || len(resp.Events) == 0 {
+		t.Fatalf("initial preferences = %+v", resp)
+	}
+	if code := put(`{"preferences":{"delivery":"digest","routes":[{"tag":"urgent","delivery":"push"}]}}`); code != http.StatusOK {
+		t.Fatalf("put all boards: %d", code)
+	}
+	board := get().CurrentBoard
+	body, _ := json.Marshal(webpush.PreferenceEntry{Board: board, Preferences: webpush.Preferences{Delivery: webpush.DeliveryOff}})
+	if code := put(string(body)); code != http.StatusOK {
+		t.Fatalf("put board: %d", code)
+	}
+	if resp := get(); len(resp.Entries) != 2 || resp.Effective.Delivery != webpush.DeliveryOff {
+		t.Errorf("after board put = %+v", resp)
+	}
+	if code := put(`{"preferences":{"quiet_hours":{"start":"late","end":"07:00"}}}`); code != http.StatusBadRequest {
+		t.Errorf("invalid quiet hours: expected 400, got %d", code)
+	}
+
+	del := func() int {
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"board": board})
+		h.DeletePushPreferences(w, httptest.NewRequest(http.MethodDelete, "/api/push/preferences", bytes.NewReader(body)))
+		return w.Code
+	}
+	if code := del(); code != http.StatusNoContent {
+		t.Errorf("delete: expected 204, got %d", code)
+	}
+	if resp := get(); resp.Effective.Delivery != webpush.DeliveryDigest {
+		t.Errorf("after delete effective = %+v, want the all-boards entry", resp.Effective)
+	}
+	if code := del(); code != http.StatusNotFound {
+		t.Errorf("second delete: expected 404, got %d", code)
+	}
+}
@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// queueReason explains where a backlog task stands in the auto-promotion
+// queue. The first four apply to eligible tasks; the rest block a task
+// regardless of capacity.
+type queueReason string
+
+const (
+	queueReasonReady        queueReason = "ready"         // starts on the next promotion pass
+	queueReasonCapacity     queueReason = "capacity"      // eligible, but no slot is left for it
+	queueReasonRepoCapacity queueReason = "repo_capacity" // eligible, but a repository is at its per-repo limit
+	queueReasonAutopilotOff queueReason = "autopilot_off" // eligible, but autopilot is off
+	queueReasonPaused       queueReason = "paused"        // auto-promotion is halted by a circuit breaker
+	queueReasonBlocked      queueReason = "blocked"       // the user marked the task as blocked
+	queueReasonTriage       queueReason = "triage"        // filed through intake and not yet triaged
+	queueReasonScheduled    queueReason = "scheduled"     // ScheduledAt is still in the future
+	queueReasonDependencies queueReason = "dependencies"  // a dependency is not done yet
+	queueReasonLocked       queueReason = "locked"        // pinned by a planning thread
+	queueReasonShadow       queueReason = "shadow"        // an experiment shadow starts with its primary
+)
+
+// queueEntry is one backlog task as the auto-promoter sees it.
+type queueEntry struct {
+	task    store.Task
+	store   *store.Store
+	score   int         // critical-path score
+	boost   int         // aging points earned by waiting
+	since   time.Time   // when the task joined the queue
+	blocked queueReason // empty when the task is eligible for promotion
+	thread  string      // planning thread holding the lock, for queueReasonLocked
+}
+
+// priority is the entry's effective priority: its critical-path score plus
+// the aging points it has earned.
+func (e queueEntry) priority() int { return e.score + e.boost }
+
+// queueAgingInterval returns how long a backlog task waits per point of
+// aging priority (WALLFACER_QUEUE_AGING_MINUTES); zero disables aging.
+func (h *Handler) queueAgingInterval() time.Duration {
+	minutes := constants.DefaultQueueAgingMinutes
+	if cfg, err := envconfig.Parse(h.envFile); err == nil {
+		minutes = cfg.QueueAgingMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// maxParallelPerRepo returns WALLFACER_MAX_PARALLEL_PER_REPO; 0 means no
+// per-repository cap.
+func (h *Handler) maxParallelPerRepo() int {
+	if cfg, err := envconfig.Parse(h.envFile); err == nil {
+		return cfg.MaxParallelPerRepo
+	}
+	return 0
+}
+
+// repoInProgress counts regular in-progress tasks per repository across
+// every active workspace group. A task counts against each folder of its
+// workspace, since it runs in a worktree of every one of them.
+func (h *Handler) repoInProgress() map[string]int {
+	counts := make(map[string]int)
+	h.forEachActiveStore(func(s *store.Store, ws []string) {
+		n := s.CountRegularInProgress()
+		if n == 0 {
+			return
+		}
+		for _, repo := range ws {
+			counts[repo] += n
+		}
+	})
+	return counts
+}
+
+// repoSlots returns how many more tasks of a workspace over repos may start
+// before one of the repositories reaches WALLFACER_MAX_PARALLEL_PER_REPO,
+// and the repository with the fewest slots left. slots is -1 when no
+// per-repository cap is set.
+func (h *Handler) repoSlots(repos []string) (slots int, repo string) {
+	limit := h.maxParallelPerRepo()
+	if limit <= 0 || len(repos) == 0 {
+		return -1, ""
+	}
+	counts := h.repoInProgress()
+	slots = -1
+	for _, r := range slices.Sorted(slices.Values(repos)) {
+		if free := max(limit-counts[r], 0); slots < 0 || free < slots {
+			slots, repo = free, r
+		}
+	}
+	return slots, repo
+}
+
+// rankBacklog returns the backlog tasks of s that the auto-promoter
+// considers, in promotion order: eligible tasks first, by effective priority,
+// then board position, then age; blocked tasks follow in the same order.
+// Routine cards are left out: the routine engine, not the queue, starts them.
+func (h *Handler) rankBacklog(ctx context.Context, s *store.Store, now time.Time) []queueEntry {
+	backlog, err := s.ListTasksByStatus(ctx, store.TaskStatusBacklog)
+	if err != nil {
+		return nil
+	}
+	interval := h.queueAgingInterval()
+	entries := make([]queueEntry, 0, len(backlog))
+	for i := range backlog {
+		t := &backlog[i]
+		if t.IsRoutine() {
+			continue
+		}
+		e := queueEntry{task: *t, store: s, since: store.QueuedSince(t)}
+		e.boost = store.AgingBoost(now.Sub(e.since), interval)
+		switch {
+		case t.IsShadow():
+			e.blocked = queueReasonShadow
+		case t.IsBlocked():
+			e.blocked = queueReasonBlocked
+		case slices.Contains(t.Tags, intakeTag):
+			// Anyone who can reach intake can file a task; a person must
+			// review it before it may run on the host.
+			e.blocked = queueReasonTriage
+		case t.ScheduledAt != nil && now.Before(*t.ScheduledAt):
+			e.blocked = queueReasonScheduled
+		default:
+			if satisfied, err := s.AreDependenciesSatisfied(ctx, t.ID); err != nil || !satisfied {
+				e.blocked = queueReasonDependencies
+			} else if locked, threadID := h.isTaskLockedByAgent(t.ID.String()); locked {
+				e.blocked, e.thread = queueReasonLocked, threadID
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	// Score all entries in one batch: CriticalPathScores builds the
+	// reverse-dependency graph once rather than once per task.
+	ids := make([]uuid.UUID, len(entries))
+	for i := range entries {
+		ids[i] = entries[i].task.ID
+	}
+	scores := s.CriticalPathScores(ids)
+	for i := range entries {
+		entries[i].score = scores[entries[i].task.ID]
+	}
+
+	slices.SortFunc(entries, func(a, b queueEntry) int {
+		if c := cmpBool(a.blocked != "", b.blocked != ""); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.priority(), a.priority()); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.task.Position, b.task.Position); c != 0 {
+			return c
+		}
+		return a.task.CreatedAt.Compare(b.task.CreatedAt)
+	})
+	return entries
+}
+
+// cmpBool orders false before true.
+func cmpBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// queueTask is one task in the GET /api/queue response.
+type queueTask struct {
+	ID                uuid.UUID   `json:"id"`
+	Title             string      `json:"title"`
+	Rank              int         `json:"rank"` // 1-based promotion order among eligible tasks; 0 when blocked
+	CriticalPathScore int         `json:"critical_path_score"`
+	AgingBoost        int         `json:"aging_boost"`
+	EffectivePriority int         `json:"effective_priority"`
+	QueuedSince       time.Time   `json:"queued_since"`
+	WaitSeconds       int64       `json:"wait_seconds"`
+	Reason            queueReason `json:"reason"`
+	Detail            string      `json:"detail"`
+}
+
+// repoQueue is one repository of the viewed workspace in the GET /api/queue
+// response: its running tasks and its merge queue.
+type repoQueue struct {
+	Repo         string               `json:"repo"`
+	InProgress   int                  `json:"in_progress"` // across every workspace that includes the repo
+	Merging      *runner.MergeTicket  `json:"merging,omitempty"`
+	MergeWaiting []runner.MergeTicket `json:"merge_waiting"`
+}
+
+// queueResponse is the JSON shape of GET /api/queue.
+type queueResponse struct {
+	Autopilot          bool        `json:"autopilot"`
+	MaxParallel        int         `json:"max_parallel"`
+	MaxParallelPerRepo int         `json:"max_parallel_per_repo"` // 0 means unlimited
+	InProgress         int         `json:"in_progress"`
+	AgingMinutes       int         `json:"aging_minutes"`
+	Tasks              []queueTask `json:"tasks"`
+	Repos              []repoQueue `json:"repos"`
+}
+
+// GetQueue handles GET /api/queue. It reports the backlog in the order the
+// auto-promoter would start it, each task's wait time and effective priority,
+// and the reason it has not started yet, plus each of the viewed workspace's
+// repositories with its running tasks and merge queue.
+func (h *Handler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	entries := h.rankBacklog(r.Context(), s, now)
+
+	repos := h.currentWorkspaces()
+	resp := queueResponse{
+		Autopilot:          h.AutoimplementEnabled(),
+		MaxParallel:        h.maxConcurrentTasks(),
+		MaxParallelPerRepo: h.maxParallelPerRepo(),
+		InProgress:         h.countGlobalInProgress(),
+		AgingMinutes:       int(h.queueAgingInterval() / time.Minute),
+		Tasks:              make([]queueTask, 0, len(entries)),
+		Repos:              h.repoQueues(repos),
+	}
+	free := resp.MaxParallel - resp.InProgress
+	repoFree, fullRepo := h.repoSlots(repos)
+	paused := h.breakers["auto-promote"].isOpen() || !h.runner.ContainerCircuitAllow()
+	rank := 0
+	for _, e := range entries {
+		qt := queueTask{
+			ID:                e.task.ID,
+			Title:             e.task.Title,
+			CriticalPathScore: e.score,
+			AgingBoost:        e.boost,
+			EffectivePriority: e.priority(),
+			QueuedSince:       e.since,
+			Reason:            e.blocked,
+		}
+		if now.After(e.since) {
+			qt.WaitSeconds = int64(now.Sub(e.since) / time.Second)
+		}
+		switch e.blocked {
+		case "":
+			rank++
+			qt.Rank = rank
+			switch {
+			case !resp.Autopilot:
+				qt.Reason, qt.Detail = queueReasonAutopilotOff, "autopilot is off; start the task manually"
+			case paused:
+				qt.Reason, qt.Detail = queueReasonPaused, "auto-promotion is paused after repeated launch failures"
+			case rank <= free && (repoFree < 0 || rank <= repoFree):
+				qt.Reason, qt.Detail = queueReasonReady, "starts on the next promotion pass"
+			case rank <= free:
+				qt.Reason = queueReasonRepoCapacity
+				qt.Detail = fmt.Sprintf("%s has %d of %d per-repository slots free; %d eligible tasks ahead", fullRepo, repoFree, resp.MaxParallelPerRepo, rank-1)
+			default:
+				qt.Reason = queueReasonCapacity
+				qt.Detail = fmt.Sprintf("%d of %d slots in use; %d eligible tasks ahead", resp.InProgress, resp.MaxParallel, rank-1)
+			}
+		case queueReasonBlocked:
+			qt.Detail = "blocked: " + e.task.Blocked.Reason
+			if until := e.task.Blocked.Until; until != nil {
+				qt.Detail += " (until " + until.Format(time.RFC3339) + ")"
+			}
+		case queueReasonTriage:
+			qt.Detail = "filed through intake; remove the intake tag or start it manually once reviewed"
+		case queueReasonScheduled:
+			qt.Detail = "scheduled for " + e.task.ScheduledAt.Format(time.RFC3339)
+		case queueReasonDependencies:
+			qt.Detail = "waiting for dependencies to finish"
+		case queueReasonLocked:
+			qt.Detail = "pinned by planning thread " + e.thread
+		case queueReasonShadow:
+			qt.Detail = "starts with its experiment primary"
+		}
+		resp.Tasks = append(resp.Tasks, qt)
+	}
+	httpjson.Write(w, http.StatusOK, resp)
+}
+
+// repoQueues reports each of repos with its running tasks and merge queue.
+func (h *Handler) repoQueues(repos []string) []repoQueue {
+	counts := h.repoInProgress()
+	merges := make(map[string]runner.RepoMergeQueue)
+	if h.runner != nil {
+		for _, mq := range h.runner.MergeQueue() {
+			merges[mq.Repo] = mq
+		}
+	}
+	out := make([]repoQueue, 0, len(repos))
+	for _, repo := range slices.Sorted(slices.Values(repos)) {
+		rq := repoQueue{Repo: repo, InProgress: counts[repo], MergeWaiting: []runner.MergeTicket{}}
+		if mq, ok := merges[repo]; ok {
+			rq.Merging = mq.Holder
+			if mq.Waiting != nil {
+				rq.MergeWaiting = mq.Waiting
+			}
+		}
+		out = append(out, rq)
+	}
+	return out
+}
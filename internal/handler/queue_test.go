@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// agingFixture creates three backlog tasks: old (no dependents), hot (one
+// dependent, so a higher critical-path score, but scheduled an hour out),
+// and blocked (depends on hot).
+func agingFixture(t *testing.T, s *store.Store) (old, hot, blocked *store.Task) {
+	t.Helper()
+	ctx := context.Background()
+	old, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "old", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hot, err = s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "hot", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocked, err = s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "blocked", Timeout: 5, DependsOn: []string{hot.ID.String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := time.Now().Add(time.Hour)
+	if err := s.UpdateTaskScheduledAt(ctx, hot.ID, &due); err != nil {
+		t.Fatal(err)
+	}
+	return old, hot, blocked
+}
+
+func rankedIDs(entries []queueEntry) []uuid.UUID {
+	ids := make([]uuid.UUID, len(entries))
+	for i, e := range entries {
+		ids[i] = e.task.ID
+	}
+	return ids
+}
+
+func TestRankBacklog_AgingOvertakesCriticalPath(t *testing.T) {
+	h := newTestHandler(t)
+	old, hot, blocked := agingFixture(t, h.store)
+
+	// Ninety minutes on, old has waited three aging intervals and hot,
+	// queued since its scheduled time, one: 1+3 beats 2+1.
+	entries := h.rankBacklog(context.Background(), h.store, time.Now().Add(90*time.Minute))
+	want := []uuid.UUID{old.ID, hot.ID, blocked.ID}
+	if got := rankedIDs(entries); len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	if entries[0].boost != 3 || entries[1].boost != 1 || entries[1].score != 2 {
+		t.Errorf("entries = %+v", entries[:2])
+	}
+	if entries[2].blocked != queueReasonDependencies {
+		t.Errorf("blocked reason = %q, want dependencies", entries[2].blocked)
+	}
+}
+
+func TestRankBacklog_AgingDisabled(t *testing.T) {
+	h, envPath := newTestHandlerWithEnv(t)
+	if err := os.WriteFile(envPath, []byte("WALLFACER_QUEUE_AGING_MINUTES=0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old, hot, _ := agingFixture(t, h.store)
+
+	entries := h.rankBacklog(context.Background(), h.store, time.Now().Add(90*time.Minute))
+	if got := rankedIDs(entries); got[0] != hot.ID || got[1] != old.ID {
+		t.Fatalf("order = %v, want hot before old without aging", got)
+	}
+}
+
+func TestGetQueue_ReportsReasons(t *testing.T) {
+	h := newTestHandler(t)
+	h.SetAutoimplement(false)
+	old, hot, blocked := agingFixture(t, h.store)
+
+	w := httptest.NewRecorder()
+	h.GetQueue(w, httptest.NewRequest(http.MethodGet, "/api/queue", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp queueResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Autopilot || len(resp.Tasks) != 3 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	want := map[uuid.UUID]queueReason{
+		old.ID:     queueReasonAutopilotOff,
+		hot.ID:     queueReasonScheduled,
+		blocked.ID: queueReasonDependencies,
+	}
+	for _, qt := range resp.Tasks {
+		if qt.Reason != want[qt.ID] || qt.Detail == "" {
+			t.Errorf("task %s: reason %q (%q), want %q", qt.ID, qt.Reason, qt.Detail, want[qt.ID])
+		}
+	}
+	if resp.Tasks[0].ID != old.ID || resp.Tasks[0].Rank != 1 || resp.Tasks[1].Rank != 0 {
+		t.Errorf("ranks = %+v", resp.Tasks)
+	}
+}
+
+func TestGetQueue_RepoCapacity(t *testing.T) {
+	repo := t.TempDir()
+	h := newStaticWorkspaceHandler(t, []string{repo})
+	h.SetAutoimplement(true)
+	if err := os.WriteFile(h.envFile, []byte("WALLFACER_MAX_PARALLEL=5\nWALLFACER_MAX_PARALLEL_PER_REPO=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	running, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "running", Timeout: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.store.UpdateTaskStatus(ctx, running.ID, store.TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	waiting, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "waiting", Timeout: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.GetQueue(w, httptest.NewRequest(http.MethodGet, "/api/queue", nil))
+	var resp queueResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.MaxParallelPerRepo != 1 || len(resp.Repos) != 1 || resp.Repos[0].InProgress != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if len(resp.Tasks) != 1 || resp.Tasks[0].ID != waiting.ID || resp.Tasks[0].Reason != queueReasonRepoCapacity {
+		t.Fatalf("tasks = %+v, want waiting blocked by repo_capacity", resp.Tasks)
+	}
+
+	w = httptest.NewRecorder()
+	if h.checkConcurrencyAndUpdateStatus(ctx, w, waiting.ID, store.TaskStatusInProgress) {
+		t.Fatal("start allowed past the per-repository cap")
+	}
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+}
+
+func TestGetQueue_ReportsMergeQueue(t *testing.T) {
+	holder, queued := uuid.New(), uuid.New()
+	mock := &runner.MockRunner{MergeQueueState: []runner.RepoMergeQueue{{
+		Repo:    "/repo/a",
+		Holder:  &runner.MergeTicket{TaskID: holder},
+		Waiting: []runner.MergeTicket{{TaskID: queued}},
+	}}}
+	h, _ := newTestHandlerWithMockRunner(t, mock)
+	h.workspaces = []string{"/repo/b", "/repo/a"}
+
+	w := httptest.NewRecorder()
+	h.GetQueue(w, httptest.NewRequest(http.MethodGet, "/api/queue", nil))
+	var resp queueResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Repos) != 2 || resp.Repos[0].Repo != "/repo/a" || resp.Repos[1].Repo != "/repo/b" {
+		t.Fatalf("repos = %+v", resp.Repos)
+	}
+	a, b := resp.Repos[0], resp.Repos[1]
+	if a.Merging == nil || a.Merging.TaskID != holder || len(a.MergeWaiting) != 1 || a.MergeWaiting[0].TaskID != queued {
+		t.Errorf("repo a = %+v", a)
+	}
+	if b.Merging != nil || b.MergeWaiting == nil || len(b.MergeWaiting) != 0 {
+		t.Errorf("repo b = %+v, want idle with an empty queue", b)
+	}
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/logger"
+)
+
+// forwardedSchemeKey is the context key under which TrustedProxyMiddleware
+// records the scheme a trusted proxy reported in X-Forwarded-Proto.
+type forwardedSchemeKey struct{}
+
+// basePathKey is the context key under which BasePathMiddleware records the
+// URL prefix the request arrived under.
+type basePathKey struct{}
+
+// TrustedProxyMiddleware honours X-Forwarded-For, X-Forwarded-Proto and
+// X-Forwarded-Host on requests whose immediate peer is one of proxies (IP
+// addresses or CIDR ranges), so request logs, generated URLs and the CSRF
+// same-origin check see the client's address, scheme and host instead of the
+// proxy's. The client address is the rightmost X-Forwarded-For entry that is
+// not itself a trusted proxy; it replaces r.RemoteAddr. The headers are
+// ignored on requests from any other peer, since a client can set them to
+// anything. Invalid entries are logged and skipped; with no valid entry the
+// middleware is a no-op.
+func TrustedProxyMiddleware(proxies []string) func(http.Handler) http.Handler {
+	trusted := parseTrustedProxies(proxies)
+	if len(trusted) == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	isTrusted := func(addr netip.Addr) bool {
+		addr = addr.Unmap()
+		for _, p := range trusted {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer, ok := remoteAddrIP(r.RemoteAddr)
+			if !ok || !isTrusted(peer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := r.Context()
+			if proto := strings.ToLower(firstForwarded(r.Header.Get("X-Forwarded-Proto"))); proto == "http" || proto == "https" {
+				ctx = context.WithValue(ctx, forwardedSchemeKey{}, proto)
+			}
+			r = r.WithContext(ctx)
+			if host := firstForwarded(r.Header.Get("X-Forwarded-Host")); host != "" {
+				r.Host = host
+			}
+			if client, ok := forwardedClient(r.Header.Values("X-Forwarded-For"), isTrusted); ok {
+				r.RemoteAddr = net.JoinHostPort(client.String(), "0")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequestScheme returns the scheme the client used to reach the server:
+// the X-Forwarded-Proto of a trusted proxy when TrustedProxyMiddleware
+// recorded one, otherwise "https" for TLS connections and "http" for the rest.
+func RequestScheme(r *http.Request) string {
+	if s, ok := r.Context().Value(forwardedSchemeKey{}).(string); ok {
+		return s
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// ClientIP returns the client's IP address from r.RemoteAddr, which
+// TrustedProxyMiddleware has already resolved for proxied requests.
+func ClientIP(r *http.Request) string {
+	if addr, ok := remoteAddrIP(r.RemoteAddr); ok {
+		return addr.String()
+	}
+	return r.RemoteAddr
+}
+
+// parseTrustedProxies parses IP addresses and CIDR ranges, logging and
+// skipping invalid entries.
+func parseTrustedProxies(entries []string) []netip.Prefix {
+	var out []netip.Prefix
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if strings.Contains(e, "/") {
+			p, err := netip.ParsePrefix(e)
+			if err != nil {
+				logger.Handler.Warn("ignoring invalid trusted proxy", "entry", e, "error", err)
+				continue
+			}
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(e)
+		if err != nil {
+			logger.Handler.Warn("ignoring invalid trusted proxy", "entry", e, "error", err)
+			continue
+		}
+		addr = addr.Unmap()
+		out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return out
+}
+
+// remoteAddrIP parses the IP of an "ip:port" or bare-IP remote address.
+func remoteAddrIP(remoteAddr string) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// forwardedClient walks the X-Forwarded-For chain from the nearest hop back
+// and returns the first address that is not a trusted proxy. When every hop
+// is trusted the farthest one is the client. Unparsable entries end the walk,
+// as nothing beyond them can be trusted.
+func forwardedClient(values []string, isTrusted func(netip.Addr) bool) (netip.Addr, bool) {
+	var hops []string
+	for _, v := range values {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	var client netip.Addr
+	found := false
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := remoteAddrIP(strings.TrimSpace(hops[i]))
+		if !ok {
+			break
+		}
+		client, found = addr, true
+		if !isTrusted(addr) {
+			break
+		}
+	}
+	return client, found
+}
+
+// firstForwarded returns the first entry of a comma-separated forwarding
+// header, which proxies append to.
+func firstForwarded(v string) string {
+	first, _, _ := strings.Cut(v, ",")
+	return strings.TrimSpace(first)
+}
+
+// NormalizeBasePath cleans a --base-path value into the "/prefix" form
+// BasePathMiddleware expects: a leading slash and no trailing one. An empty
+// value or "/" yields "", meaning the server is mounted at the root.
+func NormalizeBasePath(p string) string {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+// BasePathMiddleware mounts the server under prefix (see NormalizeBasePath),
+// for reverse proxies that forward a subpath such as /wallfacer without
+// stripping it. The prefix is removed before routing, so routes, SSE streams
+// and WebSocket upgrades match as if served at the root, and recorded on the
+// request context for BasePath. The bare prefix redirects to prefix+"/";
+// paths outside it get 404. An empty prefix disables the middleware.
+func BasePathMiddleware(prefix string) func(http.Handler) http.Handler {
+	prefix = NormalizeBasePath(prefix)
+	if prefix == "" {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == prefix {
+				target := prefix + "/"
+				if r.URL.RawQuery != "" {
+					target += "?" + r.URL.RawQuery
+				}
+				http.Redirect(w, r, target, http.StatusMovedPermanently)
+				return
+			}
+			rest, ok := strings.CutPrefix(r.URL.Path, prefix+"/")
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			r2 := r.WithContext(context.WithValue(r.Context(), basePathKey{}, prefix))
+			u := *r.URL
+			u.Path = "/" + rest
+			if raw, ok := strings.CutPrefix(u.RawPath, prefix+"/"); ok {
+				u.RawPath = "/" + raw
+			} else {
+				u.RawPath = ""
+			}
+			r2.URL = &u
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
+
+// BasePath returns the prefix BasePathMiddleware stripped from r, or "" when
+// the server is mounted at the root. Prefix it to server-relative URLs sent
+// back to the browser (redirects, links) so they resolve through the proxy.
+func BasePath(r *http.Request) string {
+	p, _ := r.Context().Value(basePathKey{}).(string)
+	return p
+}
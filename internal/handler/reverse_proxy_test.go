@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// TestTrustedProxyMiddleware verifies that X-Forwarded-* headers are applied
+// only for trusted peers and that the client is the nearest untrusted hop.
+func TestTrustedProxyMiddleware(t *testing.T) {
+	var gotAddr, gotHost, gotScheme string
+	next := TrustedProxyMiddleware([]string{"10.0.0.0/8", "192.168.1.5", "bogus"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAddr, gotHost, gotScheme = ClientIP(r), r.Host, RequestScheme(r)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		wantAddr   string
+		wantHost   string
+		wantScheme string
+	}{
+		{
+			name:       "trusted proxy",
+			remoteAddr: "10.1.2.3:4567",
+			forwarded:  []string{"203.0.113.9"},
+			wantAddr:   "203.0.113.9",
+			wantHost:   "board.example",
+			wantScheme: "https",
+		},
+		{
+			name:       "spoofed leftmost hop is skipped",
+			remoteAddr: "192.168.1.5:80",
+			forwarded:  []string{"1.1.1.1, 203.0.113.9", "10.9.9.9"},
+			wantAddr:   "203.0.113.9",
+			wantHost:   "board.example",
+			wantScheme: "https",
+		},
+		{
+			name:       "untrusted peer is ignored",
+			remoteAddr: "198.51.100.7:4567",
+			forwarded:  []string{"203.0.113.9"},
+			wantAddr:   "198.51.100.7",
+			wantHost:   "wallfacer.internal:8080",
+			wantScheme: "http",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://wallfacer.internal:8080/api/tasks", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, v := range tc.forwarded {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "board.example")
+			next.ServeHTTP(httptest.NewRecorder(), req)
+			if gotAddr != tc.wantAddr || gotHost != tc.wantHost || gotScheme != tc.wantScheme {
+				t.Errorf("client, host, scheme = %q, %q, %q; want %q, %q, %q",
+					gotAddr, gotHost, gotScheme, tc.wantAddr, tc.wantHost, tc.wantScheme)
+			}
+		})
+	}
+}
+
+// TestTrustedProxyMiddleware_NoneConfigured verifies that the headers are
+// ignored entirely when no proxy is trusted.
+func TestTrustedProxyMiddleware_NoneConfigured(t *testing.T) {
+	next := TrustedProxyMiddleware(nil)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if ClientIP(r) != "127.0.0.1" || RequestScheme(r) != "http" {
+			t.Errorf("client, scheme = %q, %q; want the peer's", ClientIP(r), RequestScheme(r))
+		}
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	next.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestNormalizeBasePath(t *testing.T) {
+	for in, want := range map[string]string{
+		"":             "",
+		"/":            "",
+		"wallfacer":    "/wallfacer",
+		"/wallfacer/":  "/wallfacer",
+		" /a/b/ ":      "/a/b",
+		"/tools/board": "/tools/board",
+	} {
+		if got := NormalizeBasePath(in); got != want {
+			t.Errorf("NormalizeBasePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestBasePathMiddleware verifies that requests under the prefix are routed
+// as if served at the root, the bare prefix redirects, and other paths 404.
+func TestBasePathMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/tasks/{id}/logs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(BasePath(r) + " " + r.PathValue("id")))
+	})
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("index"))
+	})
+	srv := BasePathMiddleware("/wallfacer/")(mux)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	if w := get("/wallfacer/api/tasks/abc/logs"); w.Code != http.StatusOK || w.Body.String() != "/wallfacer abc" {
+		t.Errorf("prefixed route = %d %q, want 200 %q", w.Code, w.Body.String(), "/wallfacer abc")
+	}
+	if w := get("/wallfacer/"); w.Body.String() != "index" {
+		t.Errorf("prefixed root = %d %q, want index", w.Code, w.Body.String())
+	}
+	if w := get("/wallfacer?x=1"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/wallfacer/?x=1" {
+		t.Errorf("bare prefix = %d Location %q, want 301 to /wallfacer/?x=1", w.Code, w.Header().Get("Location"))
+	}
+	for _, p := range []string{"/api/tasks/abc/logs", "/wallfacerx/"} {
+		if w := get(p); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", p, w.Code)
+		}
+	}
+}
+
+// TestBasePathMiddleware_LoginRedirect verifies that the cloud-mode login
+// redirect keeps the prefix on both the login path and the return target.
+func TestBasePathMiddleware_LoginRedirect(t *testing.T) {
+	h := newAuthHandler(t)
+	srv := BasePathMiddleware("/wallfacer")(h.ForceLogin(neverReached(t)))
+
+	req := httptest.NewRequest(http.MethodGet, "/wallfacer/tasks/1", nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+
+	want := "/wallfacer/login?" + url.Values{"next": {"/wallfacer/tasks/1"}}.Encode()
+	if loc := w.Header().Get("Location"); loc != want {
+		t.Errorf("Location = %q, want %q", loc, want)
+	}
+}
@@ -82,7 +82,7 @@ func (h *Handler) ReviewTranscript(w http.ResponseWriter, r *http.Request, id uu
 	}
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
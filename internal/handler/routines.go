@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"latere.ai/x/wallfacer/internal/logger"
 	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/routine"
 	"latere.ai/x/wallfacer/internal/store"
 )
 
@@ -26,6 +28,20 @@ var allowedRoutineSpawnKinds = []store.TaskKind{
 // but the minimum keeps the instance-task churn reasonable.
 const minRoutineIntervalMinutes = 1
 
+// validateRoutineCron parses a routine's cron expression and rejects one
+// that never fires, such as February 30th, which would leave the routine
+// armed with nothing to wait for.
+func validateRoutineCron(spec string) error {
+	c, err := routine.ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	if c.Next(time.Now()).IsZero() {
+		return fmt.Errorf("cron %q never fires", spec)
+	}
+	return nil
+}
+
 // RoutineResponse is the JSON shape returned by the routines API. It is
 // a deliberate subset of store.Task: only the fields the UI needs to
 // render a routine card.
@@ -35,9 +51,12 @@ type RoutineResponse struct {
 	Tags                   []string       `json:"tags,omitempty"`
 	Kind                   store.TaskKind `json:"kind"`
 	RoutineIntervalSeconds int            `json:"routine_interval_seconds"`
-	RoutineEnabled         bool           `json:"routine_enabled"`
-	RoutineNextRun         *time.Time     `json:"routine_next_run,omitempty"`
-	RoutineLastFiredAt     *time.Time     `json:"routine_last_fired_at,omitempty"`
+	// RoutineCron is the cron expression the routine fires on; empty when
+	// it fires on RoutineIntervalSeconds.
+	RoutineCron        string     `json:"routine_cron,omitempty"`
+	RoutineEnabled     bool       `json:"routine_enabled"`
+	RoutineNextRun     *time.Time `json:"routine_next_run,omitempty"`
+	RoutineLastFiredAt *time.Time `json:"routine_last_fired_at,omitempty"`
 	// RoutineSpawnKind is the legacy Kind the routine spawns. Kept in
 	// the response for older UIs that still read it; new clients should
 	// read RoutineSpawnFlow instead.
@@ -58,6 +77,7 @@ func toRoutineResponse(t store.Task) RoutineResponse {
 		Tags:                   slices.Clone(t.Tags),
 		Kind:                   t.Kind,
 		RoutineIntervalSeconds: t.RoutineIntervalSeconds,
+		RoutineCron:            t.RoutineCron,
 		RoutineEnabled:         t.RoutineEnabled,
 		RoutineNextRun:         t.RoutineNextRun,
 		RoutineLastFiredAt:     t.RoutineLastFiredAt,
@@ -95,11 +115,13 @@ func (h *Handler) ListRoutines(w http.ResponseWriter, r *http.Request) {
 
 // CreateRoutine handles POST /api/routines. It wraps the generic store
 // creation with routine-specific validation (whitelisted spawn kind,
-// minimum interval), then persists a card with Kind=TaskKindRoutine.
+// minimum interval or a valid cron expression), then persists a card with
+// Kind=TaskKindRoutine.
 func (h *Handler) CreateRoutine(w http.ResponseWriter, r *http.Request) {
 	req, ok := httpjson.DecodeBody[struct {
 		Prompt          string   `json:"prompt"`
 		IntervalMinutes int      `json:"interval_minutes"`
+		Cron            string   `json:"cron"`
 		SpawnKind       string   `json:"spawn_kind"`
 		SpawnFlow       string   `json:"spawn_flow"`
 		Enabled         *bool    `json:"enabled"`
@@ -114,7 +136,14 @@ func (h *Handler) CreateRoutine(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "prompt is required", http.StatusBadRequest)
 		return
 	}
-	if req.IntervalMinutes < minRoutineIntervalMinutes {
+	// A cron expression replaces the interval, which may then be omitted.
+	req.Cron = strings.TrimSpace(req.Cron)
+	if req.Cron != "" {
+		if err := validateRoutineCron(req.Cron); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	} else if req.IntervalMinutes < minRoutineIntervalMinutes {
 		http.Error(w, fmt.Sprintf("interval_minutes must be >= %d", minRoutineIntervalMinutes), http.StatusUnprocessableEntity)
 		return
 	}
@@ -151,7 +180,8 @@ func (h *Handler) CreateRoutine(w http.ResponseWriter, r *http.Request) {
 		Timeout:                req.Timeout,
 		Kind:                   store.TaskKindRoutine,
 		Tags:                   req.Tags,
-		RoutineIntervalSeconds: req.IntervalMinutes * 60,
+		RoutineIntervalSeconds: max(req.IntervalMinutes, 0) * 60,
+		RoutineCron:            req.Cron,
 		RoutineEnabled:         enabled,
 		RoutineSpawnKind:       spawnKind,
 		RoutineSpawnFlow:       spawnFlow,
@@ -163,6 +193,7 @@ func (h *Handler) CreateRoutine(w http.ResponseWriter, r *http.Request) {
 	h.insertEventOrLog(r.Context(), task.ID, store.EventTypeSystem, map[string]any{
 		"kind":             "routine:created",
 		"interval_seconds": task.RoutineIntervalSeconds,
+		"cron":             task.RoutineCron,
 		"enabled":          task.RoutineEnabled,
 		"spawn_kind":       string(task.RoutineSpawnKind),
 		"spawn_flow":       task.RoutineSpawnFlow,
@@ -172,7 +203,8 @@ func (h *Handler) CreateRoutine(w http.ResponseWriter, r *http.Request) {
 
 // UpdateRoutineSchedule handles PATCH /api/routines/{id}/schedule. Fields
 // omitted from the body are left unchanged so the UI can apply partial
-// updates (e.g. toggle enabled without re-sending the interval).
+// updates (e.g. toggle enabled without re-sending the interval). An empty
+// cron returns the routine to its interval, which must then be set.
 func (h *Handler) UpdateRoutineSchedule(w http.ResponseWriter, r *http.Request) {
 	id, ok := httpjson.PathUUID(w, r, "id")
 	if !ok {
@@ -180,8 +212,9 @@ func (h *Handler) UpdateRoutineSchedule(w http.ResponseWriter, r *http.Request)
 	}
 
 	req, ok := httpjson.DecodeBody[struct {
-		IntervalMinutes *int  `json:"interval_minutes"`
-		Enabled         *bool `json:"enabled"`
+		IntervalMinutes *int    `json:"interval_minutes"`
+		Cron            *string `json:"cron"`
+		Enabled         *bool   `json:"enabled"`
 	}](w, r)
 	if !ok {
 		return
@@ -202,13 +235,28 @@ func (h *Handler) UpdateRoutineSchedule(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if req.IntervalMinutes != nil {
-		mins := *req.IntervalMinutes
-		if mins < minRoutineIntervalMinutes {
-			http.Error(w, fmt.Sprintf("interval_minutes must be >= %d", minRoutineIntervalMinutes), http.StatusUnprocessableEntity)
+	if req.IntervalMinutes != nil && *req.IntervalMinutes < minRoutineIntervalMinutes {
+		http.Error(w, fmt.Sprintf("interval_minutes must be >= %d", minRoutineIntervalMinutes), http.StatusUnprocessableEntity)
+		return
+	}
+	if req.Cron != nil {
+		spec := strings.TrimSpace(*req.Cron)
+		if spec != "" {
+			if err := validateRoutineCron(spec); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		} else if req.IntervalMinutes == nil && task.RoutineIntervalSeconds <= 0 {
+			http.Error(w, "interval_minutes is required to clear cron from a routine without an interval", http.StatusUnprocessableEntity)
+			return
+		}
+		if err := s.UpdateRoutineCron(r.Context(), id, spec); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		if err := s.UpdateRoutineSchedule(r.Context(), id, mins*60); err != nil {
+	}
+	if req.IntervalMinutes != nil {
+		if err := s.UpdateRoutineSchedule(r.Context(), id, *req.IntervalMinutes*60); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -228,6 +276,7 @@ func (h *Handler) UpdateRoutineSchedule(w http.ResponseWriter, r *http.Request)
 	h.insertEventOrLog(r.Context(), id, store.EventTypeSystem, map[string]any{
 		"kind":             "routine:schedule_updated",
 		"interval_seconds": updated.RoutineIntervalSeconds,
+		"cron":             updated.RoutineCron,
 		"enabled":          updated.RoutineEnabled,
 	})
 	httpjson.Write(w, http.StatusOK, toRoutineResponse(*updated))
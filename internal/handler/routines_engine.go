@@ -42,15 +42,28 @@ func (h *Handler) StartRoutineEngine(ctx context.Context) {
 }
 
 // scheduleForTask turns a routine card's persisted schedule fields into
-// a concrete routine.Schedule. Routines that are not in an active state
-// (cancelled, done, failed), disabled by the user, or have a non-positive
-// interval become routine.Disabled() so the engine still tracks the entry
+// a concrete routine.Schedule: its cron expression when it has one,
+// otherwise its fixed interval. Routines that are not in an active state
+// (cancelled, done, failed), disabled by the user, with an unparseable
+// cron expression, or with a non-positive interval and no cron expression
+// become routine.Disabled() so the engine still tracks the entry
 // (its next-run is reported as zero in the UI) but never fires it. In
 // practice the reconcile loop skips these entries entirely so the engine
 // drops them on the next pass — Disabled() is the belt to that
 // suspenders.
 func scheduleForTask(t store.Task) routine.Schedule {
-	if isRoutineStoppedStatus(t.Status) || !t.RoutineEnabled || t.RoutineIntervalSeconds <= 0 {
+	if isRoutineStoppedStatus(t.Status) || !t.RoutineEnabled {
+		return routine.Disabled()
+	}
+	if t.RoutineCron != "" {
+		c, err := routine.ParseCron(t.RoutineCron)
+		if err != nil {
+			logger.Handler.Warn("routine: invalid cron", "routine", t.ID, "cron", t.RoutineCron, "error", err)
+			return routine.Disabled()
+		}
+		return c
+	}
+	if t.RoutineIntervalSeconds <= 0 {
 		return routine.Disabled()
 	}
 	return routine.FixedInterval{D: time.Duration(t.RoutineIntervalSeconds) * time.Second}
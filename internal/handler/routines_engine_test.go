@@ -106,6 +106,27 @@ func TestReconcileRoutines_DisabledHasZeroNextRun(t *testing.T) {
 	}
 }
 
+func TestReconcileRoutines_CronSchedulesNextMatch(t *testing.T) {
+	mock := &runner.MockRunner{}
+	h, s := newTestHandlerWithMockRunner(t, mock)
+	installRoutineEngine(h, nil, h.fireRoutine)
+
+	ctx := context.Background()
+	routineTask, _ := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{
+		Prompt: "hourly", Timeout: 10, Kind: store.TaskKindRoutine,
+		RoutineCron: "CRON_TZ=UTC 0 * * * *", RoutineEnabled: true,
+	})
+	h.reconcileRoutines(ctx)
+
+	next := h.routineEngine.NextRuns()[routineTask.ID]
+	if next.IsZero() || next.Minute() != 0 || next.Second() != 0 {
+		t.Fatalf("cron routine next run = %v, want the top of an hour", next)
+	}
+	if time.Until(next) > time.Hour {
+		t.Fatalf("cron routine next run = %v, want within the hour", next)
+	}
+}
+
 func TestFireRoutine_CreatesAndRunsInstanceTask(t *testing.T) {
 	mock := &runner.MockRunner{}
 	h, s := newTestHandlerWithMockRunner(t, mock)
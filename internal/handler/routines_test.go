@@ -309,3 +309,87 @@ func TestTriggerRoutine_UnknownID(t *testing.T) {
 		t.Fatalf("status = %d, want 404; body=%s", rec.Code, rec.Body.String())
 	}
 }
+
+func TestCreateRoutine_AcceptsCronWithoutInterval(t *testing.T) {
+	h := newTestHandler(t)
+	rec := postRoutine(t, h, map[string]any{
+		"prompt": "nightly dependency audit",
+		"cron":   " 0 2 * * * ",
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body=%s", rec.Code, rec.Body.String())
+	}
+	var resp RoutineResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.RoutineCron != "0 2 * * *" {
+		t.Fatalf("cron = %q, want the trimmed spec", resp.RoutineCron)
+	}
+	got, _ := h.store.GetTask(context.Background(), resp.ID)
+	if got.RoutineCron != "0 2 * * *" {
+		t.Fatalf("stored RoutineCron = %q", got.RoutineCron)
+	}
+}
+
+func TestCreateRoutine_RejectsInvalidCron(t *testing.T) {
+	h := newTestHandler(t)
+	for _, spec := range []string{"every night", "0 2 * *", "0 0 30 2 *"} {
+		rec := postRoutine(t, h, map[string]any{"prompt": "p", "cron": spec})
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("cron %q: status = %d, want 422; body=%s", spec, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestUpdateRoutineSchedule_SetsAndClearsCron(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	routine, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{
+		Prompt: "r", Timeout: 10, Kind: store.TaskKindRoutine,
+		RoutineIntervalSeconds: 600, RoutineEnabled: true,
+	})
+
+	rec := patchRoutine(t, h, routine.ID, map[string]any{"cron": "30 9 * * MON-FRI"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	got, _ := h.store.GetTask(ctx, routine.ID)
+	if got.RoutineCron != "30 9 * * MON-FRI" {
+		t.Fatalf("cron = %q, want it set", got.RoutineCron)
+	}
+
+	if rec := patchRoutine(t, h, routine.ID, map[string]any{"cron": "61 * * * *"}); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid cron: status = %d, want 422", rec.Code)
+	}
+
+	rec = patchRoutine(t, h, routine.ID, map[string]any{"cron": ""})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	got, _ = h.store.GetTask(ctx, routine.ID)
+	if got.RoutineCron != "" || got.RoutineIntervalSeconds != 600 {
+		t.Fatalf("after clearing cron = %q, interval %d; want the interval back", got.RoutineCron, got.RoutineIntervalSeconds)
+	}
+}
+
+func TestUpdateRoutineSchedule_ClearingCronNeedsInterval(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	routine, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{
+		Prompt: "r", Timeout: 10, Kind: store.TaskKindRoutine,
+		RoutineCron: "@daily", RoutineEnabled: true,
+	})
+
+	if rec := patchRoutine(t, h, routine.ID, map[string]any{"cron": ""}); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422; body=%s", rec.Code, rec.Body.String())
+	}
+	rec := patchRoutine(t, h, routine.ID, map[string]any{"cron": "", "interval_minutes": 60})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	got, _ := h.store.GetTask(ctx, routine.ID)
+	if got.RoutineCron != "" || got.RoutineIntervalSeconds != 3600 {
+		t.Fatalf("cron = %q, interval = %d", got.RoutineCron, got.RoutineIntervalSeconds)
+	}
+}
@@ -57,6 +57,7 @@ func (h *Handler) GetRuntimeStatus(w http.ResponseWriter, r *http.Request) {
 		store.TaskStatusFailed:     0,
 		store.TaskStatusCancelled:  0,
 		store.TaskStatusCommitting: 0,
+		store.TaskStatusReview:     0,
 	}
 	for _, t := range tasks {
 		taskStates[t.Status]++
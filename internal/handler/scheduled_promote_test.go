@@ -144,3 +144,34 @@ func TestEnsureScheduledPromoteTrigger_ReplacesLaterTimer(t *testing.T) {
 	}
 	h.scheduledPromoteMu.Unlock()
 }
+
+// TestScheduledTaskTimerArmedWhenSaturated verifies that a promotion pass on
+// a board with no free slot still arms the timer for a scheduled task, so it
+// is not left waiting for the next ticker once a slot frees up.
+func TestScheduledTaskTimerArmedWhenSaturated(t *testing.T) {
+	h, _ := newTestHandlerWithEnv(t)
+	h.autoimplement.Store(true)
+	ctx := context.Background()
+
+	for range h.maxConcurrentTasks() {
+		task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "running", Timeout: 15})
+		h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress) //nolint:errcheck
+	}
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "scheduled later", Timeout: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := time.Now().Add(10 * time.Second)
+	if err := h.store.UpdateTaskScheduledAt(ctx, task.ID, &due); err != nil {
+		t.Fatalf("UpdateTaskScheduledAt: %v", err)
+	}
+
+	h.tryAutoPromote(ctx)
+
+	h.scheduledPromoteMu.Lock()
+	defer h.scheduledPromoteMu.Unlock()
+	if h.scheduledPromoteTimer == nil {
+		t.Fatal("expected the scheduled-promotion timer to be armed on a saturated board")
+	}
+	h.scheduledPromoteTimer.Stop()
+}
@@ -17,7 +17,7 @@ func (h *Handler) GetTaskSpans(w http.ResponseWriter, r *http.Request, id uuid.U
 		return
 	}
 	if _, err := s.GetTask(r.Context(), id); err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -350,14 +350,14 @@ func (h *Handler) DispatchSpecs(w http.ResponseWriter, r *http.Request) {
 			tags = append(tags, rs.spec.Track)
 		}
 
-		task, err := s.CreateTaskWithOptions(r.Context(), store.TaskCreateOptions{
+		task, err := s.CreateTaskWithOptions(r.Context(), h.withProjectDefaults(store.TaskCreateOptions{
 			ID:             preAssignedIDs[i],
 			Prompt:         rs.spec.Body,
 			Timeout:        60,
 			Tags:           tags,
 			SpecSourcePath: rs.relPath,
 			DependsOn:      rs.taskDeps,
-		})
+		}))
 		if err != nil {
 			// Rollback: delete any tasks created so far.
 			for j := 0; j < i; j++ {
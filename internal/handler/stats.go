@@ -28,6 +28,59 @@ type StatsResponse struct {
 	TopTasks          []TaskCostEntry                     `json:"top_tasks"`
 	DailyUsage        []DayStat                           `json:"daily_usage"`
 	AgentSessions     map[string]AgentSessionGroupStat    `json:"agent_sessions"`
+	Estimates         EstimateCalibration                 `json:"estimates"`
+	Velocity          VelocityStats                       `json:"velocity"`
+}
+
+// velocityWeeks is how many calendar weeks, the current one included, the
+// velocity section of GET /api/stats covers.
+const velocityWeeks = 12
+
+// VelocityStats is the story-point burndown and velocity of the board, per
+// calendar week (Monday 00:00 UTC), for teams planning sprints on it.
+// AverageVelocity is the mean completed points over the full weeks of the
+// window, leaving out the current partial one.
+type VelocityStats struct {
+	Weeks           []WeekPoints `json:"weeks"`
+	AverageVelocity float64      `json:"average_velocity"`
+}
+
+// WeekPoints holds one week of story-point flow. PlannedPoints is the work
+// open when the week began and CompletedPlannedPoints the part of it done
+// within the week, so their ratio is the week's planning accuracy.
+// RemainingPoints is the open work when the week ended (the burndown line).
+// UnpointedCompleted counts finished tasks without points, which velocity
+// cannot see.
+type WeekPoints struct {
+	WeekStart              string  `json:"week_start"` // "2006-01-02"
+	AddedPoints            float64 `json:"added_points"`
+	PlannedPoints          float64 `json:"planned_points"`
+	CompletedPoints        float64 `json:"completed_points"`
+	CompletedPlannedPoints float64 `json:"completed_planned_points"`
+	RemainingPoints        float64 `json:"remaining_points"`
+	CompletedTasks         int     `json:"completed_tasks"`
+	UnpointedCompleted     int     `json:"unpointed_completed"`
+}
+
+// EstimateCalibration compares pre-run effort estimates with actuals so the
+// estimator's bias is visible. Ratios are the mean of actual/estimated over
+// done tasks whose estimate for that dimension is non-zero: 1.0 is perfectly
+// calibrated, above 1 means the estimator under-predicts. Zero when there
+// are no samples.
+type EstimateCalibration struct {
+	Count        int                                `json:"count"`
+	TurnsRatio   float64                            `json:"turns_ratio"`
+	TokensRatio  float64                            `json:"tokens_ratio"`
+	MinutesRatio float64                            `json:"minutes_ratio"`
+	ByRisk       map[store.EstimateRisk]RiskOutcome `json:"by_risk"`
+}
+
+// RiskOutcome counts finished estimated tasks in one risk bucket and how many
+// of them failed at least once, so predicted risk can be checked against
+// observed failure rates.
+type RiskOutcome struct {
+	Count  int `json:"count"`
+	Failed int `json:"failed"`
 }
 
 // AgentSessionGroupStat aggregates agent-session round usage for one workspace group.
@@ -177,6 +230,9 @@ func aggregateStats(tasks []store.Task, loadSummary func(id uuid.UUID) (*store.T
 		dailyMap[day].OutputTokens += u.OutputTokens
 	}
 
+	resp.Estimates = aggregateEstimates(tasks, loadSummary)
+	resp.Velocity = aggregateVelocity(tasks, loadSummary, time.Now())
+
 	// TopTasks: sort all tasks by cost descending, take top 10.
 	sorted := slices.Clone(tasks)
 	slices.SortFunc(sorted, func(a, b store.Task) int {
@@ -217,6 +273,144 @@ func aggregateStats(tasks []store.Task, loadSummary func(id uuid.UUID) (*store.T
 	return resp
 }
 
+// aggregateEstimates builds the estimate-vs-actual calibration from tasks
+// carrying an Estimate. Ratios use done tasks only; risk outcomes also count
+// failed and cancelled tasks, since those are the outcomes risk predicts.
+func aggregateEstimates(tasks []store.Task, loadSummary func(id uuid.UUID) (*store.TaskSummary, error)) EstimateCalibration {
+	cal := EstimateCalibration{ByRisk: make(map[store.EstimateRisk]RiskOutcome)}
+	var turnsSum, tokensSum, minutesSum float64
+	var turnsN, tokensN, minutesN int
+	for _, t := range tasks {
+		est := t.Estimate
+		if est == nil {
+			continue
+		}
+		switch t.Status {
+		case store.TaskStatusDone, store.TaskStatusFailed, store.TaskStatusCancelled:
+		default:
+			continue
+		}
+		ro := cal.ByRisk[est.Risk]
+		ro.Count++
+		if t.Status == store.TaskStatusFailed || len(t.RetryHistory) > 0 || t.FailureCategory != "" {
+			ro.Failed++
+		}
+		cal.ByRisk[est.Risk] = ro
+
+		if t.Status != store.TaskStatusDone {
+			continue
+		}
+		cal.Count++
+		var summary *store.TaskSummary
+		if loadSummary != nil {
+			summary, _ = loadSummary(t.ID)
+		}
+		turns, tokens, minutes := t.EffortActuals(summary)
+		if est.Turns > 0 {
+			turnsSum += float64(turns) / float64(est.Turns)
+			turnsN++
+		}
+		if est.Tokens > 0 {
+			tokensSum += float64(tokens) / float64(est.Tokens)
+			tokensN++
+		}
+		if est.Minutes > 0 {
+			minutesSum += minutes / est.Minutes
+			minutesN++
+		}
+	}
+	if turnsN > 0 {
+		cal.TurnsRatio = turnsSum / float64(turnsN)
+	}
+	if tokensN > 0 {
+		cal.TokensRatio = tokensSum / float64(tokensN)
+	}
+	if minutesN > 0 {
+		cal.MinutesRatio = minutesSum / float64(minutesN)
+	}
+	return cal
+}
+
+// aggregateVelocity builds the weekly story-point flow for the velocityWeeks
+// weeks ending with the one containing now. A task is open from its creation
+// until it is done or cancelled; a done task's completion time comes from its
+// summary when present, falling back to its last update. Routine cards are
+// templates, not work, and are skipped.
+func aggregateVelocity(tasks []store.Task, loadSummary func(id uuid.UUID) (*store.TaskSummary, error), now time.Time) VelocityStats {
+	current := weekStart(now)
+	first := current.AddDate(0, 0, -7*(velocityWeeks-1))
+	weeks := make([]WeekPoints, velocityWeeks)
+	for i := range weeks {
+		weeks[i].WeekStart = first.AddDate(0, 0, 7*i).Format("2006-01-02")
+	}
+
+	for _, t := range tasks {
+		if t.IsRoutine() {
+			continue
+		}
+		var closed time.Time
+		switch t.Status {
+		case store.TaskStatusDone:
+			closed = t.UpdatedAt
+			if loadSummary != nil {
+				if summary, err := loadSummary(t.ID); err == nil && summary != nil && !summary.CompletedAt.IsZero() {
+					closed = summary.CompletedAt
+				}
+			}
+		case store.TaskStatusCancelled:
+			closed = t.UpdatedAt
+		}
+		done := t.Status == store.TaskStatusDone
+		openAt := func(at time.Time) bool {
+			return t.CreatedAt.Before(at) && (closed.IsZero() || !closed.Before(at))
+		}
+		points := t.Points()
+		for i := range weeks {
+			start := first.AddDate(0, 0, 7*i)
+			end := start.AddDate(0, 0, 7)
+			completed := done && !closed.Before(start) && closed.Before(end)
+			w := &weeks[i]
+			if completed {
+				w.CompletedTasks++
+				if points == 0 {
+					w.UnpointedCompleted++
+				}
+			}
+			if points == 0 {
+				continue
+			}
+			if !t.CreatedAt.Before(start) && t.CreatedAt.Before(end) {
+				w.AddedPoints += points
+			}
+			if openAt(start) {
+				w.PlannedPoints += points
+				if completed {
+					w.CompletedPlannedPoints += points
+				}
+			}
+			if completed {
+				w.CompletedPoints += points
+			}
+			if openAt(end) {
+				w.RemainingPoints += points
+			}
+		}
+	}
+
+	var total float64
+	for _, w := range weeks[:velocityWeeks-1] {
+		total += w.CompletedPoints
+	}
+	return VelocityStats{Weeks: weeks, AverageVelocity: total / float64(velocityWeeks-1)}
+}
+
+// weekStart returns the Monday 00:00 UTC that begins t's calendar week.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
 // filterTasksByWorkspace returns the subset of tasks whose WorktreePaths map
 // contains ws as a key. When ws is empty the full slice is returned unchanged.
 // The second return value is false only when ws is non-empty but no tasks match,
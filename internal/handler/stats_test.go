@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -827,3 +828,133 @@ func TestGetStats_AgentSessionEndpoint(t *testing.T) {
 		t.Errorf("agent-session entry wrong: %+v", stat)
 	}
 }
+
+func TestAggregateEstimates(t *testing.T) {
+	started := time.Now().UTC().Add(-time.Hour)
+	tasks := []store.Task{
+		{
+			// Done, took twice the estimated turns and tokens, as many minutes.
+			ID:        uuid.New(),
+			Status:    store.TaskStatusDone,
+			Turns:     8,
+			Usage:     store.TaskUsage{InputTokens: 150, OutputTokens: 50},
+			StartedAt: &started,
+			UpdatedAt: started.Add(20 * time.Minute),
+			Estimate:  &store.TaskEstimate{Turns: 4, Tokens: 100, Minutes: 20, Risk: store.EstimateRiskLow},
+		},
+		{
+			// Done after a retry, zero minutes estimate excluded from that ratio.
+			ID:           uuid.New(),
+			Status:       store.TaskStatusDone,
+			Turns:        2,
+			Usage:        store.TaskUsage{InputTokens: 100},
+			RetryHistory: []store.RetryRecord{{}},
+			Estimate:     &store.TaskEstimate{Turns: 2, Tokens: 100, Risk: store.EstimateRiskHigh},
+		},
+		{
+			// Failed: counted in risk outcomes only.
+			ID:       uuid.New(),
+			Status:   store.TaskStatusFailed,
+			Estimate: &store.TaskEstimate{Turns: 1, Risk: store.EstimateRiskHigh},
+		},
+		// Backlog and unestimated tasks are ignored.
+		{ID: uuid.New(), Status: store.TaskStatusBacklog, Estimate: &store.TaskEstimate{Turns: 1, Risk: store.EstimateRiskLow}},
+		{ID: uuid.New(), Status: store.TaskStatusDone, Turns: 3},
+	}
+
+	cal := aggregateEstimates(tasks, noSummary)
+
+	if cal.Count != 2 {
+		t.Errorf("Count = %d, want 2", cal.Count)
+	}
+	if cal.TurnsRatio != 1.5 {
+		t.Errorf("TurnsRatio = %v, want 1.5 (mean of 2.0 and 1.0)", cal.TurnsRatio)
+	}
+	if cal.TokensRatio != 1.5 {
+		t.Errorf("TokensRatio = %v, want 1.5 (mean of 2.0 and 1.0)", cal.TokensRatio)
+	}
+	if cal.MinutesRatio != 1 {
+		t.Errorf("MinutesRatio = %v, want 1", cal.MinutesRatio)
+	}
+	if got := cal.ByRisk[store.EstimateRiskLow]; got != (RiskOutcome{Count: 1, Failed: 0}) {
+		t.Errorf("ByRisk[low] = %+v, want {1 0}", got)
+	}
+	if got := cal.ByRisk[store.EstimateRiskHigh]; got != (RiskOutcome{Count: 2, Failed: 2}) {
+		t.Errorf("ByRisk[high] = %+v, want {2 2}", got)
+	}
+}
+
+func TestAggregateEstimates_NoSamples(t *testing.T) {
+	cal := aggregateEstimates([]store.Task{{ID: uuid.New(), Status: store.TaskStatusDone}}, noSummary)
+	if cal.Count != 0 || cal.TurnsRatio != 0 || len(cal.ByRisk) != 0 {
+		t.Errorf("expected empty calibration, got %+v", cal)
+	}
+}
+
+// TestAggregateVelocity verifies the weekly burndown: points enter when a
+// task is created, leave when it is done or cancelled, and count towards
+// planning accuracy only when the task was open as the week began.
+func TestAggregateVelocity(t *testing.T) {
+	now := time.Date(2026, 3, 18, 12, 0, 0, 0, time.UTC) // Wednesday
+	thisWeek := time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)
+	lastWeek := thisWeek.AddDate(0, 0, -7)
+	at := func(week time.Time, days int) time.Time { return week.AddDate(0, 0, days).Add(time.Hour) }
+
+	carried := store.Task{ // open since before last week, done last week
+		ID: uuid.New(), Status: store.TaskStatusDone, StoryPoints: 3,
+		CreatedAt: lastWeek.AddDate(0, 0, -10), UpdatedAt: at(lastWeek, 2),
+	}
+	added := store.Task{ // sized, added last week, still open
+		ID: uuid.New(), Status: store.TaskStatusBacklog, Size: store.TaskSizeL,
+		CreatedAt: at(lastWeek, 1),
+	}
+	cancelled := store.Task{ // added and cancelled last week
+		ID: uuid.New(), Status: store.TaskStatusCancelled, StoryPoints: 2,
+		CreatedAt: at(lastWeek, 1), UpdatedAt: at(lastWeek, 3),
+	}
+	summarised := store.Task{ // completion time comes from the summary
+		ID: uuid.New(), Status: store.TaskStatusDone, StoryPoints: 1,
+		CreatedAt: at(lastWeek, 0), UpdatedAt: at(thisWeek, 1),
+	}
+	unpointed := store.Task{
+		ID: uuid.New(), Status: store.TaskStatusDone,
+		CreatedAt: at(thisWeek, 0), UpdatedAt: at(thisWeek, 1),
+	}
+	routine := store.Task{
+		ID: uuid.New(), Kind: store.TaskKindRoutine, StoryPoints: 8, CreatedAt: at(lastWeek, 0),
+	}
+	loadSummary := func(id uuid.UUID) (*store.TaskSummary, error) {
+		if id == summarised.ID {
+			return &store.TaskSummary{CompletedAt: at(lastWeek, 4)}, nil
+		}
+		return nil, errors.New("no summary")
+	}
+
+	v := aggregateVelocity([]store.Task{carried, added, cancelled, summarised, unpointed, routine}, loadSummary, now)
+
+	if len(v.Weeks) != velocityWeeks {
+		t.Fatalf("len(Weeks) = %d, want %d", len(v.Weeks), velocityWeeks)
+	}
+	last, current := v.Weeks[velocityWeeks-2], v.Weeks[velocityWeeks-1]
+	if current.WeekStart != "2026-03-16" || last.WeekStart != "2026-03-09" {
+		t.Errorf("week starts = %s, %s", last.WeekStart, current.WeekStart)
+	}
+	want := WeekPoints{
+		WeekStart:              "2026-03-09",
+		AddedPoints:            5 + 2 + 1,
+		PlannedPoints:          3,
+		CompletedPoints:        3 + 1,
+		CompletedPlannedPoints: 3,
+		RemainingPoints:        5,
+		CompletedTasks:         2,
+	}
+	if last != want {
+		t.Errorf("last week = %+v\nwant %+v", last, want)
+	}
+	if current.PlannedPoints != 5 || current.RemainingPoints != 5 || current.CompletedTasks != 1 || current.UnpointedCompleted != 1 {
+		t.Errorf("current week = %+v", current)
+	}
+	if got, wantAvg := v.AverageVelocity, 4.0/float64(velocityWeeks-1); got != wantAvg {
+		t.Errorf("AverageVelocity = %v, want %v", got, wantAvg)
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// SendTaskInput answers a prompt of the task's running interactive turn by
+// writing to the agent's stdin:
+//
+//	POST /api/tasks/{id}/input  {"action": "approve"|"deny"|"text", "text"?}
+//
+// approve and deny send "y" and "n"; text sends one line of free text. Only
+// an in-progress task created with interactive_input has a turn to answer.
+func (h *Handler) SendTaskInput(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	req, ok := httpjson.DecodeBody[struct {
+		Action string `json:"action"`
+		Text   string `json:"text"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	action := store.InputAction(req.Action)
+	switch action {
+	case store.InputApprove, store.InputDeny, store.InputText:
+	default:
+		writeFieldError(w, "action", "must be approve, deny, or text (got %q)", req.Action)
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !task.InteractiveInput {
+		http.Error(w, "task does not accept interactive input", http.StatusBadRequest)
+		return
+	}
+	if task.Status != store.TaskStatusInProgress {
+		writeError(w, runner.ErrNoInteractiveTurn)
+		return
+	}
+	if err := h.runner.SendInput(r.Context(), id, action, req.Text); err != nil {
+		if errors.Is(err, runner.ErrInvalidInput) {
+			writeFieldError(w, "text", "%s", err.Error())
+			return
+		}
+		writeError(w, err)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, map[string]string{"action": string(action)})
+}
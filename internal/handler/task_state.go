@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// Session states reported by GET /api/tasks/{id}/state.
+const (
+	sessionStateNone    = "none"    // the task has no agent session
+	sessionStatePresent = "present" // the session transcript is on disk, so the session can be resumed
+	sessionStateMissing = "missing" // the transcript is gone; the next turn starts a fresh session
+	sessionStateUnknown = "unknown" // the harness keeps no transcript wallfacer can check
+)
+
+// taskStateResponse is the body of GET /api/tasks/{id}/state.
+type taskStateResponse struct {
+	ID        uuid.UUID           `json:"id"`
+	Status    store.TaskStatus    `json:"status"`
+	Agent     taskAgentState      `json:"agent"`
+	Worktrees []taskWorktreeState `json:"worktrees"`
+	Session   taskSessionState    `json:"session"`
+	// Divergences lists, in plain words, every way the task record and
+	// what is on the host disagree. Empty when they agree.
+	Divergences []string `json:"divergences"`
+}
+
+// taskAgentState reports the task's agent process.
+type taskAgentState struct {
+	Running bool   `json:"running"`
+	Name    string `json:"name,omitempty"`
+	State   string `json:"state,omitempty"`
+}
+
+// taskWorktreeState reports one of the task's worktrees. Dirty, Ahead, and
+// Behind are only filled for an existing worktree of a git repository.
+type taskWorktreeState struct {
+	Repo   string `json:"repo"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+	Dirty  bool   `json:"dirty"`
+	Ahead  int    `json:"ahead"`  // task branch commits not on the default branch
+	Behind int    `json:"behind"` // default branch commits not on the task branch
+	Error  string `json:"error,omitempty"`
+}
+
+// taskSessionState reports whether the task's agent session can still be
+// resumed.
+type taskSessionState struct {
+	ID      string     `json:"id,omitempty"`
+	Harness harness.ID `json:"harness,omitempty"`
+	State   string     `json:"state"`
+}
+
+// TaskState returns one snapshot of everything that decides what a task
+// can do next, read from the host rather than the task record: the store
+// status, whether an agent process is running, each worktree's presence,
+// uncommitted changes, and commits ahead of and behind the default branch,
+// and whether the agent session is still resumable. Mismatches between
+// the record and the host are spelled out in divergences:
+//
+//	GET /api/tasks/{id}/state
+func (h *Handler) TaskState(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := taskStateResponse{
+		ID:        task.ID,
+		Status:    task.Status,
+		Agent:     h.taskAgentState(id),
+		Worktrees: h.taskWorktreeStates(r.Context(), task),
+		Session:   taskSession(task),
+	}
+	resp.Divergences = taskStateDivergences(resp)
+	w.Header().Set("Cache-Control", "no-store")
+	httpjson.Write(w, http.StatusOK, resp)
+}
+
+func (h *Handler) taskAgentState(id uuid.UUID) taskAgentState {
+	// Errors are treated as an empty list, as in Health.
+	processes, _ := h.runner.ListContainers()
+	for _, p := range processes {
+		if p.TaskID == id.String() {
+			return taskAgentState{Running: p.State == "running", Name: p.Name, State: p.State}
+		}
+	}
+	return taskAgentState{}
+}
+
+func (h *Handler) taskWorktreeStates(ctx context.Context, task *store.Task) []taskWorktreeState {
+	repos := make([]string, 0, len(task.WorktreePaths))
+	for repo := range task.WorktreePaths {
+		repos = append(repos, repo)
+	}
+	slices.Sort(repos)
+	states := make([]taskWorktreeState, 0, len(repos))
+	for _, repo := range repos {
+		st := taskWorktreeState{Repo: repo, Path: task.WorktreePaths[repo]}
+		if _, err := os.Stat(st.Path); err == nil {
+			st.Exists = true
+		}
+		if st.Exists && gitutil.IsGitRepo(repo) {
+			var errs []error
+			var err error
+			st.Dirty, err = gitutil.HasChanges(ctx, st.Path)
+			errs = append(errs, err)
+			st.Ahead, err = gitutil.CommitsAhead(repo, st.Path)
+			errs = append(errs, err)
+			st.Behind, err = h.commitsBehindCache.cachedCommitsBehind(repo, st.Path)
+			errs = append(errs, err)
+			if err := errors.Join(errs...); err != nil {
+				st.Error = err.Error()
+			}
+		}
+		states = append(states, st)
+	}
+	return states
+}
+
+// taskSession checks the task's agent session against the transcripts of
+// the harness it was started with. Only Claude sessions can be checked.
+func taskSession(task *store.Task) taskSessionState {
+	if task.SessionID == nil || *task.SessionID == "" {
+		return taskSessionState{State: sessionStateNone}
+	}
+	st := taskSessionState{ID: *task.SessionID, Harness: task.Sandbox, State: sessionStateUnknown}
+	if task.Environment != nil {
+		st.Harness = cmp.Or(task.Environment.Sandbox, st.Harness)
+	}
+	if st.Harness == harness.Claude {
+		_, err := harness.FindClaudeSession(harness.ClaudeConfigDir(), st.ID)
+		switch {
+		case err == nil:
+			st.State = sessionStatePresent
+		case errors.Is(err, harness.ErrClaudeSessionNotFound):
+			st.State = sessionStateMissing
+		}
+	}
+	return st
+}
+
+// taskStateDivergences compares the task's status with what was found on
+// the host.
+func taskStateDivergences(st taskStateResponse) []string {
+	out := []string{}
+	switch {
+	case st.Status == store.TaskStatusInProgress && !st.Agent.Running:
+		out = append(out, "The task is in progress but no agent process is running.")
+	case st.Agent.Running && st.Status != store.TaskStatusInProgress:
+		out = append(out, fmt.Sprintf("An agent process is running but the task is %s.", st.Status))
+	}
+	needsWorktree := false
+	switch st.Status {
+	case store.TaskStatusInProgress, store.TaskStatusWaiting, store.TaskStatusCommitting, store.TaskStatusReview:
+		needsWorktree = true
+	}
+	for _, wt := range st.Worktrees {
+		switch {
+		case needsWorktree && !wt.Exists:
+			out = append(out, fmt.Sprintf("The worktree for %s is missing (%s).", wt.Repo, wt.Path))
+		case wt.Exists && (st.Status == store.TaskStatusDone || st.Status == store.TaskStatusCancelled):
+			out = append(out, fmt.Sprintf("The worktree for %s is still on disk although the task is %s.", wt.Repo, st.Status))
+		}
+	}
+	if st.Status == store.TaskStatusWaiting && st.Session.State == sessionStateMissing {
+		out = append(out, "The agent session can no longer be resumed; feedback will start a fresh session.")
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestTaskState_Worktrees(t *testing.T) {
+	repo := setupRepo(t)
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	wt := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-b", "task", wt, "HEAD")
+	_ = os.WriteFile(filepath.Join(wt, "task.txt"), []byte("task\n"), 0644)
+	gitRun(t, wt, "add", ".")
+	gitRun(t, wt, "commit", "-m", "task change")
+	_ = os.WriteFile(filepath.Join(wt, "wip.txt"), []byte("wip\n"), 0644)
+	_ = os.WriteFile(filepath.Join(repo, "main.txt"), []byte("main\n"), 0644)
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-m", "main change")
+
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "state", Timeout: 5})
+	missing := filepath.Join(t.TempDir(), "gone")
+	other := setupRepo(t)
+	_ = h.store.UpdateTaskWorktrees(ctx, task.ID, map[string]string{repo: wt, other: missing}, "task")
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+
+	w := httptest.NewRecorder()
+	h.TaskState(w, httptest.NewRequest(http.MethodGet, "/api/tasks/"+task.ID.String()+"/state", nil), task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("TaskState returned %d: %s", w.Code, w.Body.String())
+	}
+	var resp taskStateResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != store.TaskStatusWaiting || resp.Agent.Running || resp.Session.State != sessionStateNone {
+		t.Errorf("status %s, agent %+v, session %+v", resp.Status, resp.Agent, resp.Session)
+	}
+	i := slices.IndexFunc(resp.Worktrees, func(s taskWorktreeState) bool { return s.Repo == repo })
+	if i < 0 {
+		t.Fatalf("worktrees = %+v", resp.Worktrees)
+	}
+	if got := resp.Worktrees[i]; !got.Exists || !got.Dirty || got.Ahead != 1 || got.Behind != 1 || got.Error != "" {
+		t.Errorf("worktree = %+v, want existing, dirty, 1 ahead, 1 behind", got)
+	}
+	if len(resp.Divergences) != 1 {
+		t.Errorf("divergences = %q, want the missing worktree only", resp.Divergences)
+	}
+}
+
+func TestTaskStateDivergences(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		st   taskStateResponse
+		want int
+	}{
+		{"agreeing backlog task", taskStateResponse{Status: store.TaskStatusBacklog}, 0},
+		{"in progress without agent", taskStateResponse{Status: store.TaskStatusInProgress}, 1},
+		{"agent on a cancelled task", taskStateResponse{Status: store.TaskStatusCancelled, Agent: taskAgentState{Running: true}}, 1},
+		{"leftover worktree", taskStateResponse{Status: store.TaskStatusDone, Worktrees: []taskWorktreeState{{Repo: "r", Exists: true}}}, 1},
+		{"waiting on a lost session", taskStateResponse{Status: store.TaskStatusWaiting, Session: taskSessionState{State: sessionStateMissing}}, 1},
+	} {
+		if got := taskStateDivergences(tc.st); len(got) != tc.want {
+			t.Errorf("%s: divergences = %q, want %d", tc.name, got, tc.want)
+		}
+	}
+}
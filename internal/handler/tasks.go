@@ -6,16 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"latere.ai/x/wallfacer/internal/harness"
 	"latere.ai/x/wallfacer/internal/logger"
 	"latere.ai/x/wallfacer/internal/pkg/httpjson"
-	"latere.ai/x/wallfacer/internal/pkg/statemachine"
 	"latere.ai/x/wallfacer/internal/store"
 )
 
@@ -34,7 +34,7 @@ func (h *Handler) SearchTasks(w http.ResponseWriter, r *http.Request) {
 	}
 	results, err := s.SearchTasks(r.Context(), q)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	if results == nil {
@@ -54,7 +54,7 @@ func (h *Handler) ListSummaries(w http.ResponseWriter, _ *http.Request) {
 	}
 	summaries, err := s.ListSummaries()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	if summaries == nil {
@@ -134,6 +134,11 @@ func (h *Handler) ListTasks(w http.ResponseWriter, r *http.Request) {
 		httpjson.Write(w, http.StatusOK, resp)
 		return
 	}
+	q := r.URL.Query()
+	if q.Has("status") || q.Has("limit") || q.Has("cursor") {
+		h.listTasksPage(w, r, s, includeArchived)
+		return
+	}
 	// GET /api/tasks applies org-scoped filtering in cloud mode when the
 	// request carried JWT claims; local-mode callers (principal == nil)
 	// get the unfiltered list identical to today's behavior.
@@ -149,9 +154,73 @@ func (h *Handler) ListTasks(w http.ResponseWriter, r *http.Request) {
 		}
 		tasks = filterByFailureCategory(tasks, category)
 	}
+	blocked, err := parseBlockedFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if blocked != nil {
+		tasks = filterByBlocked(tasks, *blocked)
+	}
 	httpjson.Write(w, http.StatusOK, tasks)
 }
 
+// maxTaskPageSize caps ?limit= on GET /api/tasks.
+const maxTaskPageSize = 500
+
+// listTasksPage serves the column-scoped, paginated form of GET /api/tasks,
+// selected by any of ?status= (comma-separated or repeated), ?limit= and
+// ?cursor=. The response wraps the page in store.TaskPage so callers can
+// follow next_cursor; the unparameterized form keeps returning a bare array.
+func (h *Handler) listTasksPage(w http.ResponseWriter, r *http.Request, s *store.Store, includeArchived bool) {
+	q := r.URL.Query()
+	opts := store.TaskPageOptions{
+		IncludeArchived: includeArchived,
+		Cursor:          strings.TrimSpace(q.Get("cursor")),
+	}
+	for _, raw := range q["status"] {
+		for part := range strings.SplitSeq(raw, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			status, ok := store.ParseTaskStatus(part)
+			if !ok {
+				http.Error(w, "invalid status: "+part, http.StatusBadRequest)
+				return
+			}
+			opts.Statuses = append(opts.Statuses, status)
+		}
+	}
+	if raw := strings.TrimSpace(q.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		opts.Limit = min(limit, maxTaskPageSize)
+	}
+	if raw := q.Get("failure_category"); raw != "" {
+		category, ok := store.ParseFailureCategory(raw)
+		if !ok {
+			http.Error(w, "invalid failure_category", http.StatusBadRequest)
+			return
+		}
+		opts.FailureCategory = category
+	}
+	blocked, err := parseBlockedFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts.Blocked = blocked
+	page, err := s.TasksPage(r.Context(), principalFromRequest(r), opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, page)
+}
+
 // filterByFailureCategory returns only those tasks whose FailureCategory
 // matches cat. The input slice is not modified; a new slice is returned.
 func filterByFailureCategory(tasks []store.Task, cat store.FailureCategory) []store.Task {
@@ -164,6 +233,31 @@ func filterByFailureCategory(tasks []store.Task, cat store.FailureCategory) []st
 	return filtered
 }
 
+// parseBlockedFilter reads ?blocked=true|false; nil means no filter.
+func parseBlockedFilter(r *http.Request) (*bool, error) {
+	raw := r.URL.Query().Get("blocked")
+	if raw == "" {
+		return nil, nil
+	}
+	blocked, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blocked: %q", raw)
+	}
+	return &blocked, nil
+}
+
+// filterByBlocked returns only the tasks whose blocked state matches
+// blocked. The input slice is not modified; a new slice is returned.
+func filterByBlocked(tasks []store.Task, blocked bool) []store.Task {
+	filtered := make([]store.Task, 0, len(tasks))
+	for i := range tasks {
+		if tasks[i].IsBlocked() == blocked {
+			filtered = append(filtered, tasks[i])
+		}
+	}
+	return filtered
+}
+
 // CreateTask creates a new task in backlog status.
 //
 // The deprecated `sandbox` and `sandbox_by_activity` fields are no
@@ -179,6 +273,11 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		Criteria           string                               `json:"criteria"`
 		Timeout            int                                  `json:"timeout"`
 		MountWorktrees     bool                                 `json:"mount_worktrees"`
+		SkipCommit         bool                                 `json:"skip_commit"`
+		MergeMode          string                               `json:"merge_mode"`
+		RequireApproval    bool                                 `json:"require_approval"`
+		ApprovalGates      bool                                 `json:"approval_gates"`
+		InteractiveInput   bool                                 `json:"interactive_input"`
 		Sandbox            *harness.ID                          `json:"sandbox,omitempty"`
 		SandboxByActivity  map[store.SandboxActivity]harness.ID `json:"sandbox_by_activity,omitempty"`
 		Kind               store.TaskKind                       `json:"kind"`
@@ -190,6 +289,9 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		ScheduledAt        *time.Time                           `json:"scheduled_at,omitempty"`
 		CustomPassPatterns []string                             `json:"custom_pass_patterns,omitempty"`
 		CustomFailPatterns []string                             `json:"custom_fail_patterns,omitempty"`
+		ResearchDomains    []string                             `json:"research_domains,omitempty"`
+		Proxy              *store.TaskProxy                     `json:"proxy,omitempty"`
+		SummaryLocale      string                               `json:"summary_locale,omitempty"`
 	}](w, r)
 	if !ok {
 		return
@@ -206,12 +308,29 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 			http.StatusBadRequest)
 		return
 	}
-	if strings.TrimSpace(req.Prompt) == "" {
-		http.Error(w, "prompt is required", http.StatusBadRequest)
-		return
+	errs := taskFields{
+		Prompt:             &req.Prompt,
+		Timeout:            &req.Timeout,
+		MaxCostUSD:         &req.MaxCostUSD,
+		MaxInputTokens:     &req.MaxInputTokens,
+		CustomPassPatterns: req.CustomPassPatterns,
+		CustomFailPatterns: req.CustomFailPatterns,
+	}.validate()
+	domains := researchDomains(req.Kind, req.ResearchDomains, req.Timeout, &errs)
+	proxy, err := store.NormalizeTaskProxy(req.Proxy)
+	if err != nil {
+		errs.Add("proxy", "%v", err)
 	}
-	if err := validateCustomPatterns(req.CustomPassPatterns, req.CustomFailPatterns); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	locale, err := store.NormalizeLocale(req.SummaryLocale)
+	if err != nil {
+		errs.Add("summary_locale", "%v", err)
+	}
+	mergeMode, err := store.NormalizeMergeMode(req.MergeMode)
+	if err != nil {
+		errs.Add("merge_mode", "%v", err)
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
 		return
 	}
 	s, ok2 := h.requireStore(w)
@@ -225,6 +344,11 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		Timeout:            req.Timeout,
 		Tags:               req.Tags,
 		MountWorktrees:     req.MountWorktrees,
+		SkipCommit:         req.SkipCommit,
+		MergeMode:          mergeMode,
+		RequireApproval:    req.RequireApproval,
+		ApprovalGates:      req.ApprovalGates,
+		InteractiveInput:   req.InteractiveInput,
 		Kind:               req.Kind,
 		FlowID:             req.Flow,
 		MaxCostUSD:         req.MaxCostUSD,
@@ -233,14 +357,22 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		ScheduledAt:        req.ScheduledAt,
 		CustomPassPatterns: req.CustomPassPatterns,
 		CustomFailPatterns: req.CustomFailPatterns,
+		ResearchDomains:    domains,
+		Proxy:              proxy,
+		SummaryLocale:      locale,
 	}
 	if p := principalFromRequest(r); p != nil {
 		opts.CreatedBy = p.Sub
 		opts.OrgID = p.OrgID
 	}
+	opts = h.withProjectDefaults(opts)
+	if strings.TrimSpace(req.MergeMode) != "" {
+		// An explicit "merge" is the zero mode; keep it over the project's.
+		opts.MergeMode = mergeMode
+	}
 	task, err := s.CreateTaskWithOptions(r.Context(), opts)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -269,6 +401,9 @@ type batchTaskInput struct {
 	Flow              string                                `json:"flow"`
 	Kind              store.TaskKind                        `json:"kind"`
 	MountWorktrees    bool                                  `json:"mount_worktrees"`
+	SkipCommit        bool                                  `json:"skip_commit"`
+	ApprovalGates     bool                                  `json:"approval_gates"`
+	InteractiveInput  bool                                  `json:"interactive_input"`
 	DependsOnRefs     []string                              `json:"depends_on_refs"`
 	SpecSourcePath    string                                `json:"spec_source_path"`
 }
@@ -525,24 +660,27 @@ func (h *Handler) BatchCreateTasks(w http.ResponseWriter, r *http.Request) {
 		}
 
 		batchOpts := store.TaskCreateOptions{
-			ID:             preAssignedIDs[idx],
-			Prompt:         t.Prompt,
-			Criteria:       t.Criteria,
-			Timeout:        t.Timeout,
-			Tags:           t.Tags,
-			MountWorktrees: t.MountWorktrees,
-			Kind:           t.Kind,
-			FlowID:         t.Flow,
-			DependsOn:      depStrs,
-			SpecSourcePath: t.SpecSourcePath,
+			ID:               preAssignedIDs[idx],
+			Prompt:           t.Prompt,
+			Criteria:         t.Criteria,
+			Timeout:          t.Timeout,
+			Tags:             t.Tags,
+			MountWorktrees:   t.MountWorktrees,
+			SkipCommit:       t.SkipCommit,
+			ApprovalGates:    t.ApprovalGates,
+			InteractiveInput: t.InteractiveInput,
+			Kind:             t.Kind,
+			FlowID:           t.Flow,
+			DependsOn:        depStrs,
+			SpecSourcePath:   t.SpecSourcePath,
 		}
 		if p := principalFromRequest(r); p != nil {
 			batchOpts.CreatedBy = p.Sub
 			batchOpts.OrgID = p.OrgID
 		}
-		task, err := s.CreateTaskWithOptions(r.Context(), batchOpts)
+		task, err := s.CreateTaskWithOptions(r.Context(), h.withProjectDefaults(batchOpts))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 
@@ -561,7 +699,7 @@ func (h *Handler) BatchCreateTasks(w http.ResponseWriter, r *http.Request) {
 	for i := range req.Tasks {
 		updated, err := s.GetTask(r.Context(), preAssignedIDs[i])
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		finalTasks[i] = *updated
@@ -583,15 +721,27 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 	req, ok := httpjson.DecodeBody[struct {
 		Status            *store.TaskStatus                     `json:"status"`
 		Position          *int                                  `json:"position"`
+		Title             *string                               `json:"title"`
 		Prompt            *string                               `json:"prompt"`
 		Criteria          *string                               `json:"criteria"`
 		Timeout           *int                                  `json:"timeout"`
 		FreshStart        *bool                                 `json:"fresh_start"`
 		MountWorktrees    *bool                                 `json:"mount_worktrees"`
+		SkipCommit        *bool                                 `json:"skip_commit"`
+		MergeMode         *string                               `json:"merge_mode"`
+		RequireApproval   *bool                                 `json:"require_approval"`
+		ApprovalGates     *bool                                 `json:"approval_gates"`
+		InteractiveInput  *bool                                 `json:"interactive_input"`
 		Sandbox           *harness.ID                           `json:"sandbox"`
 		SandboxByActivity *map[store.SandboxActivity]harness.ID `json:"sandbox_by_activity"`
 		DependsOn         *[]string                             `json:"depends_on"`
 		Tags              *[]string                             `json:"tags"`
+		Links             *[]store.TaskLink                     `json:"links"`
+		ContextFiles      *[]string                             `json:"context_files"`
+		// StoryPoints and Size are sprint-planning estimates, editable in
+		// any status; 0 and "" clear them.
+		StoryPoints *float64 `json:"story_points"`
+		Size        *string  `json:"size"`
 		// Archived flips the archived flag (replaces POST /archive,/unarchive).
 		Archived *bool `json:"archived"`
 		// Deleted=false restores a soft-deleted task (replaces POST /restore).
@@ -603,14 +753,101 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 		Model *string `json:"model"`
 		// ScheduledAt uses json.RawMessage so we can distinguish "absent" (nil)
 		// from explicitly-sent "null" (clear the schedule) or a valid time (set it).
-		ScheduledAt        json.RawMessage `json:"scheduled_at"`
-		CustomPassPatterns []string        `json:"custom_pass_patterns,omitempty"`
-		CustomFailPatterns []string        `json:"custom_fail_patterns,omitempty"`
+		ScheduledAt json.RawMessage `json:"scheduled_at"`
+		// Blocked is null (unblock) or {"reason", "until"} (block a
+		// backlog task); absent leaves the block unchanged.
+		Blocked json.RawMessage `json:"blocked"`
+		// Proxy is null (use the global proxy settings) or the task's
+		// override; absent leaves it unchanged.
+		Proxy json.RawMessage `json:"proxy"`
+		// SummaryLocale sets the language the title and summary are
+		// translated into; empty string falls back to the global setting.
+		SummaryLocale      *string  `json:"summary_locale"`
+		CustomPassPatterns []string `json:"custom_pass_patterns,omitempty"`
+		CustomFailPatterns []string `json:"custom_fail_patterns,omitempty"`
 	}](w, r)
 	if !ok {
 		return
 	}
 
+	// Validate every sent field before applying any of them, so a rejected
+	// request leaves the task untouched.
+	errs := taskFields{
+		Prompt:             req.Prompt,
+		Timeout:            req.Timeout,
+		MaxCostUSD:         req.MaxCostUSD,
+		MaxInputTokens:     req.MaxInputTokens,
+		CustomPassPatterns: req.CustomPassPatterns,
+		CustomFailPatterns: req.CustomFailPatterns,
+		StoryPoints:        req.StoryPoints,
+		Size:               req.Size,
+	}.validate()
+	if req.Status != nil {
+		if _, valid := store.ParseTaskStatus(string(*req.Status)); !valid {
+			errs.Add("status", "unknown status %q", *req.Status)
+		}
+	}
+	if req.Deleted != nil && *req.Deleted {
+		errs.Add("deleted", "soft-delete uses DELETE /api/tasks/{id}, not PATCH")
+	}
+	var proxy *store.TaskProxy
+	if len(req.Proxy) > 0 && string(req.Proxy) != "null" {
+		if err := json.Unmarshal(req.Proxy, &proxy); err != nil {
+			errs.Add("proxy", "%v", err)
+		} else if proxy, err = store.NormalizeTaskProxy(proxy); err != nil {
+			errs.Add("proxy", "%v", err)
+		}
+	}
+	if req.SummaryLocale != nil {
+		locale, err := store.NormalizeLocale(*req.SummaryLocale)
+		if err != nil {
+			errs.Add("summary_locale", "%v", err)
+		}
+		req.SummaryLocale = &locale
+	}
+	var mergeMode *store.MergeMode
+	if req.MergeMode != nil {
+		m, err := store.NormalizeMergeMode(*req.MergeMode)
+		if err != nil {
+			errs.Add("merge_mode", "%v", err)
+		}
+		mergeMode = &m
+	}
+	if req.Title != nil {
+		if title := strings.TrimSpace(*req.Title); title == "" {
+			errs.Add("title", "must not be empty")
+		} else if n := utf8.RuneCountInString(title); n > store.MaxTaskTitleLength {
+			errs.Add("title", "must be at most %d characters (got %d)", store.MaxTaskTitleLength, n)
+		}
+	}
+	if req.Links != nil {
+		links, err := store.NormalizeTaskLinks(*req.Links)
+		if err != nil {
+			errs.Add("links", "%v", err)
+		}
+		req.Links = &links
+	}
+	if req.ContextFiles != nil {
+		paths, err := store.NormalizeContextFiles(*req.ContextFiles)
+		if err != nil {
+			errs.Add("context_files", "%v", err)
+		}
+		workspaces := h.visibleWorkspaces(r.Context())
+		for _, p := range paths {
+			if !slices.ContainsFunc(workspaces, func(ws string) bool {
+				_, err := isWithinWorkspace(p, ws)
+				return err == nil
+			}) {
+				errs.Add("context_files", "%s is not an existing path inside a configured workspace", p)
+			}
+		}
+		req.ContextFiles = &paths
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
 	s, ok2 := h.requireStore(w)
 	if !ok2 {
 		return
@@ -620,17 +857,13 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 	// GetTask lookup below would not find. Handle it up front. Only
 	// deleted=false is accepted; soft-delete uses DELETE /api/tasks/{id}.
 	if req.Deleted != nil {
-		if *req.Deleted {
-			http.Error(w, "soft-delete uses DELETE /api/tasks/{id}, not PATCH", http.StatusBadRequest)
-			return
-		}
 		if err := h.applyRestore(r.Context(), id); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		restored, err := s.GetTask(r.Context(), id)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		httpjson.Write(w, http.StatusOK, restored)
@@ -639,7 +872,7 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -647,22 +880,65 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 	// standalone mutation, independent of status, so it short-circuits.
 	if req.Archived != nil {
 		if *req.Archived && task.Status != store.TaskStatusDone && task.Status != store.TaskStatusCancelled {
-			http.Error(w, "only done or cancelled tasks can be archived", http.StatusBadRequest)
+			writeFieldError(w, "archived", "only done or cancelled tasks can be archived")
 			return
 		}
 		if err := h.applyArchive(r.Context(), *task, *req.Archived); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		updated, err := s.GetTask(r.Context(), id)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		httpjson.Write(w, http.StatusOK, updated)
 		return
 	}
 
+	// Fields that shape the agent's run are locked while a turn or the
+	// commit pipeline is in flight; metadata (title, tags, links, estimates)
+	// stays editable in every status.
+	if task.Status == store.TaskStatusInProgress || task.Status == store.TaskStatusCommitting {
+		var locked ValidationErrors
+		for _, f := range []struct {
+			name string
+			sent bool
+		}{
+			{"prompt", req.Prompt != nil},
+			{"criteria", req.Criteria != nil},
+			{"timeout", req.Timeout != nil},
+			{"fresh_start", req.FreshStart != nil},
+			{"mount_worktrees", req.MountWorktrees != nil},
+			{"sandbox", req.Sandbox != nil},
+			{"sandbox_by_activity", req.SandboxByActivity != nil},
+			{"model", req.Model != nil},
+			{"max_cost_usd", req.MaxCostUSD != nil},
+			{"max_input_tokens", req.MaxInputTokens != nil},
+			{"custom_pass_patterns", req.CustomPassPatterns != nil},
+			{"custom_fail_patterns", req.CustomFailPatterns != nil},
+			{"proxy", len(req.Proxy) > 0},
+		} {
+			if f.sent {
+				locked.Add(f.name, "cannot change while the task is %s", task.Status)
+			}
+		}
+		if len(locked) > 0 {
+			writeValidationErrors(w, locked)
+			return
+		}
+	}
+
+	// Every field change is collected into one store patch, validated in
+	// full, and applied atomically below, so a rejected or failed request
+	// leaves the task untouched. IfStatus guards the status-dependent edit
+	// rules against a transition racing the request.
+	patch := store.TaskPatch{IfStatus: task.Status, Position: req.Position, Title: req.Title, Tags: req.Tags, Links: req.Links, ContextFiles: req.ContextFiles, StoryPoints: req.StoryPoints, SummaryLocale: req.SummaryLocale}
+	if req.Size != nil {
+		size, _ := store.ParseTaskSize(*req.Size)
+		patch.Size = &size
+	}
+
 	// Allow editing prompt, criteria, timeout, fresh_start, mount_worktrees, sandbox, model, budget, and custom patterns for backlog tasks.
 	if task.Status == store.TaskStatusBacklog && (req.Prompt != nil || req.Criteria != nil || req.Timeout != nil || req.FreshStart != nil || req.MountWorktrees != nil || req.Sandbox != nil || req.SandboxByActivity != nil || req.MaxCostUSD != nil || req.MaxInputTokens != nil || req.Model != nil || req.CustomPassPatterns != nil || req.CustomFailPatterns != nil) {
 		sandbox := task.Sandbox
@@ -677,45 +953,22 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		if err := validateCustomPatterns(req.CustomPassPatterns, req.CustomFailPatterns); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
+		patch.Prompt = req.Prompt
+		patch.Criteria = req.Criteria
+		patch.Timeout = req.Timeout
+		patch.FreshStart = req.FreshStart
+		patch.MountWorktrees = req.MountWorktrees
+		patch.Sandbox = req.Sandbox
+		patch.SandboxByActivity = req.SandboxByActivity
+		patch.MaxCostUSD = req.MaxCostUSD
+		patch.MaxInputTokens = req.MaxInputTokens
+		patch.Model = req.Model
+		// A pattern list that was not sent keeps its current value.
+		if req.CustomPassPatterns != nil {
+			patch.CustomPassPatterns = &req.CustomPassPatterns
 		}
-		if err := s.UpdateTaskBacklog(r.Context(), id, req.Prompt, req.Timeout, req.FreshStart, req.MountWorktrees, req.SandboxByActivity, req.MaxCostUSD, req.MaxInputTokens); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		if req.Criteria != nil {
-			if err := s.UpdateTaskCriteria(r.Context(), id, *req.Criteria); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-		}
-		if req.Sandbox != nil {
-			if err := s.UpdateTaskSandbox(r.Context(), id, *req.Sandbox); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-		}
-		if req.Model != nil {
-			if err := s.UpdateTaskModelOverride(r.Context(), id, *req.Model); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-		}
-		if req.CustomPassPatterns != nil || req.CustomFailPatterns != nil {
-			passP := req.CustomPassPatterns
-			failP := req.CustomFailPatterns
-			if passP == nil {
-				passP = task.CustomPassPatterns
-			}
-			if failP == nil {
-				failP = task.CustomFailPatterns
-			}
-			if err := s.UpdateTaskCustomPatterns(r.Context(), id, passP, failP); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
+		if req.CustomFailPatterns != nil {
+			patch.CustomFailPatterns = &req.CustomFailPatterns
 		}
 	}
 
@@ -723,62 +976,122 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 	// req.ScheduledAt is nil when the field was absent from the JSON body (no-op).
 	// When present it is either "null" (clear) or an ISO 8601 timestamp (set).
 	if task.Status == store.TaskStatusBacklog && len(req.ScheduledAt) > 0 {
-		var scheduledAt *time.Time
 		// "null" clears the schedule; any other value is parsed as a time.
 		if string(req.ScheduledAt) != "null" {
 			var t time.Time
 			if err := json.Unmarshal(req.ScheduledAt, &t); err != nil {
-				http.Error(w, "invalid scheduled_at: "+err.Error(), http.StatusBadRequest)
+				writeFieldError(w, "scheduled_at", "invalid time: %v", err)
 				return
 			}
 			if !t.IsZero() {
-				scheduledAt = &t
+				patch.ScheduledAt = &t
 			}
 		}
-		if err := s.UpdateTaskScheduledAt(r.Context(), id, scheduledAt); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		patch.ClearScheduledAt = patch.ScheduledAt == nil
+	}
+
+	if len(req.Blocked) > 0 {
+		block, clearBlock, err := parseTaskBlock(task, req.Blocked, time.Now())
+		if err != nil {
+			writeFieldError(w, "blocked", "%v", err)
 			return
 		}
+		patch.Blocked, patch.ClearBlocked = block, clearBlock && task.IsBlocked()
 	}
 
-	// Allow raising budget limits for waiting tasks (so users can continue a paused task).
-	if task.Status == store.TaskStatusWaiting && (req.MaxCostUSD != nil || req.MaxInputTokens != nil) {
-		if err := s.UpdateTaskBudget(r.Context(), id, req.MaxCostUSD, req.MaxInputTokens); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	// The proxy applies from the next launch; an empty override clears it.
+	if len(req.Proxy) > 0 {
+		patch.Proxy, patch.ClearProxy = proxy, proxy == nil
+	}
+
+	// skip_commit can change until the task is completed.
+	if req.SkipCommit != nil {
+		switch task.Status {
+		case store.TaskStatusBacklog, store.TaskStatusInProgress, store.TaskStatusWaiting:
+			patch.SkipCommit = req.SkipCommit
+		default:
+			writeFieldError(w, "skip_commit", "skip_commit cannot change on a %s task", task.Status)
 			return
 		}
 	}
 
-	if req.Position != nil {
-		if err := s.UpdateTaskPosition(r.Context(), id, *req.Position); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	// merge_mode is read by the commit pipeline, so it can change until then.
+	if mergeMode != nil {
+		switch task.Status {
+		case store.TaskStatusBacklog, store.TaskStatusInProgress, store.TaskStatusWaiting:
+			patch.MergeMode = mergeMode
+		default:
+			writeFieldError(w, "merge_mode", "merge_mode cannot change on a %s task", task.Status)
 			return
 		}
 	}
 
+	// require_approval is read when the commit pipeline starts.
+	if req.RequireApproval != nil {
+		switch task.Status {
+		case store.TaskStatusBacklog, store.TaskStatusInProgress, store.TaskStatusWaiting:
+			patch.RequireApproval = req.RequireApproval
+		default:
+			writeFieldError(w, "require_approval", "require_approval cannot change on a %s task", task.Status)
+			return
+		}
+	}
+
+	// approval_gates shapes the first prompt, so it is set before the task runs.
+	if req.ApprovalGates != nil {
+		if task.Status != store.TaskStatusBacklog {
+			writeFieldError(w, "approval_gates", "approval_gates can only change on a backlog task")
+			return
+		}
+		patch.ApprovalGates = req.ApprovalGates
+	}
+
+	// interactive_input decides how the next turn launches, so it cannot
+	// change while a turn is running.
+	if req.InteractiveInput != nil {
+		if task.Status == store.TaskStatusInProgress {
+			writeFieldError(w, "interactive_input", "interactive_input cannot change while the task is running")
+			return
+		}
+		patch.InteractiveInput = req.InteractiveInput
+	}
+
+	// Allow raising budget limits for waiting tasks (so users can continue a paused task).
+	if task.Status == store.TaskStatusWaiting {
+		patch.MaxCostUSD = req.MaxCostUSD
+		patch.MaxInputTokens = req.MaxInputTokens
+	}
+
+	// unmetDeps lists the sent dependencies that are not done, for the
+	// start check below.
+	var unmetDeps []string
 	if req.DependsOn != nil {
 		parsedDeps := make([]uuid.UUID, 0, len(*req.DependsOn))
 		for _, depStr := range *req.DependsOn {
 			depID, err := uuid.Parse(depStr)
 			if err != nil {
-				http.Error(w, fmt.Sprintf("invalid dependency UUID %q: %v", depStr, err), http.StatusBadRequest)
+				writeFieldError(w, "depends_on", "invalid dependency UUID %q: %v", depStr, err)
 				return
 			}
 			if depID == id {
-				http.Error(w, "task cannot depend on itself", http.StatusBadRequest)
+				writeFieldError(w, "depends_on", "task cannot depend on itself")
 				return
 			}
-			if _, err := s.GetTask(r.Context(), depID); err != nil {
-				http.Error(w, fmt.Sprintf("dependency task not found: %s", depStr), http.StatusBadRequest)
+			dep, err := s.GetTask(r.Context(), depID)
+			if err != nil {
+				writeFieldError(w, "depends_on", "dependency task not found: %s", depStr)
 				return
 			}
+			if dep.Status != store.TaskStatusDone {
+				unmetDeps = append(unmetDeps, depID.String())
+			}
 			parsedDeps = append(parsedDeps, depID)
 		}
 		// Cycle detection using full graph including archived tasks.
 		allTasks, _ := s.ListTasks(r.Context(), true)
 		for _, depID := range parsedDeps {
 			if taskReachable(allTasks, depID, id) {
-				http.Error(w, fmt.Sprintf("dependency on %s would create a cycle", depID), http.StatusBadRequest)
+				writeFieldError(w, "depends_on", "dependency on %s would create a cycle", depID)
 				return
 			}
 		}
@@ -786,166 +1099,214 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 		for i, d := range parsedDeps {
 			strs[i] = d.String()
 		}
-		if err := s.UpdateTaskDependsOn(r.Context(), id, strs); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		patch.DependsOn = &strs
+	}
+
+	if req.Status == nil {
+		if err := s.PatchTask(r.Context(), id, patch); err != nil {
+			writePatchError(w, err)
 			return
 		}
+		h.recordBlockChange(r.Context(), task, patch)
+		// A new language dropped the old translation; make the next one.
+		if req.SummaryLocale != nil && *req.SummaryLocale != task.SummaryLocale {
+			h.runner.LocalizeSummaryBackground(id)
+		}
+		h.writeTask(w, r, s, id)
+		return
 	}
 
-	if req.Tags != nil {
-		if err := s.UpdateTaskTags(r.Context(), id, *req.Tags); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	oldStatus := task.Status
+	newStatus := *req.Status
+
+	// Cancel is a side-effecting transition (kill container, clean
+	// worktrees, cascade to routine children) that bypasses the state
+	// machine. Intercept it before the routine guard and agent-session-lock
+	// check below so cancelling a routine card still runs its cascade,
+	// matching the old POST /api/tasks/{id}/cancel behaviour. The field
+	// changes are committed first; the cancel itself cannot be rolled back.
+	if newStatus == store.TaskStatusCancelled {
+		if !cancellableStatuses[oldStatus] {
+			writeFieldError(w, "status", "a %s task cannot be cancelled", oldStatus)
+			return
+		}
+		if err := s.PatchTask(r.Context(), id, patch); err != nil {
+			writePatchError(w, err)
+			return
+		}
+		if err := h.applyCancel(r.Context(), *task); err != nil {
+			writeError(w, err)
 			return
 		}
+		h.writeTask(w, r, s, id)
+		return
 	}
 
-	if req.Status != nil {
-		oldStatus := task.Status
-		newStatus := *req.Status
-
-		// Cancel is a side-effecting transition (kill container, clean
-		// worktrees, cascade to routine children) that bypasses the state
-		// machine. Intercept it before the routine guard and agent-session-lock
-		// check below so cancelling a routine card still runs its cascade,
-		// matching the old POST /api/tasks/{id}/cancel behaviour.
-		if newStatus == store.TaskStatusCancelled {
-			if !cancellableStatuses[oldStatus] {
-				http.Error(w, "task cannot be cancelled in its current status", http.StatusBadRequest)
-				return
-			}
-			if err := h.applyCancel(r.Context(), *task); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			updated, err := s.GetTask(r.Context(), id)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			httpjson.Write(w, http.StatusOK, updated)
+	// Routine cards are schedule templates, not executable work. They
+	// live outside the normal lifecycle and must stay in backlog; any
+	// attempt to move them via the generic PATCH is a programmer
+	// error on the client.
+	if task.IsRoutine() {
+		writeFieldError(w, "status", "routine tasks cannot change status; use /api/routines endpoints")
+		return
+	}
+
+	// A task leaves review through approve or reject, which pair the status
+	// change with the held merge or the feedback; cancel is handled above.
+	if oldStatus == store.TaskStatusReview && newStatus != oldStatus {
+		writeFieldError(w, "status", "a task in review moves on via approve or reject")
+		return
+	}
+
+	// Reject status changes on tasks that have an in-flight plan turn.
+	if oldStatus != newStatus {
+		if locked, threadID := h.isTaskLockedByAgent(id.String()); locked {
+			httpjson.Write(w, http.StatusConflict, map[string]string{
+				"error":     "task is locked by an in-flight plan turn",
+				"thread_id": threadID,
+			})
 			return
 		}
+	}
 
-		// Routine cards are schedule templates, not executable work. They
-		// live outside the normal lifecycle and must stay in backlog; any
-		// attempt to move them via the generic PATCH is a programmer
-		// error on the client.
-		if task.IsRoutine() {
-			http.Error(w, "routine tasks cannot change status; use /api/routines endpoints", http.StatusUnprocessableEntity)
+	// Handle retry: done/failed/waiting/cancelled → backlog
+	if newStatus == store.TaskStatusBacklog && (oldStatus == store.TaskStatusDone || oldStatus == store.TaskStatusFailed || oldStatus == store.TaskStatusCancelled || oldStatus == store.TaskStatusWaiting) {
+		newPrompt := task.Prompt
+		if req.Prompt != nil {
+			newPrompt = *req.Prompt
+		}
+		// Default to resuming the previous session; the client can opt out by sending fresh_start=true.
+		freshStart := false
+		if req.FreshStart != nil {
+			freshStart = *req.FreshStart
+		}
+		if err := s.PatchTask(r.Context(), id, patch); err != nil {
+			writePatchError(w, err)
 			return
 		}
-
-		// Reject status changes on tasks that have an in-flight plan turn.
-		if oldStatus != newStatus {
-			if locked, threadID := h.isTaskLockedByAgent(id.String()); locked {
-				httpjson.Write(w, http.StatusConflict, map[string]string{
-					"error":     "task is locked by an in-flight plan turn",
-					"thread_id": threadID,
-				})
-				return
-			}
+		// Capture the retiring attempt's diff before the worktree is
+		// discarded or reused so it stays comparable with later attempts.
+		attemptDiff, _, _ := collectTaskDiff(r.Context(), s, task, false)
+		// Only delete the worktree directory and branch on fresh_start.
+		// For normal retries the branch holds Claude's committed work;
+		// ensureTaskWorktrees will reattach it on the next run.
+		if freshStart && len(task.WorktreePaths) > 0 {
+			h.runner.CleanupWorktrees(id, task.WorktreePaths, task.BranchName)
 		}
+		if err := s.ResetTaskForRetry(r.Context(), id, newPrompt, freshStart); err != nil {
+			writeError(w, err)
+			return
+		}
+		archiveAttemptDiff(r.Context(), s, id, attemptDiff)
+		h.insertEventOrLog(r.Context(), id, store.EventTypeStateChange,
+			store.NewStateChangeData(oldStatus, store.TaskStatusBacklog, store.TriggerUser, nil))
+		h.diffCache.invalidate(id)
+		h.writeTask(w, r, s, id)
+		return
+	}
 
-		// Handle retry: done/failed/waiting/cancelled → backlog
-		if newStatus == store.TaskStatusBacklog && (oldStatus == store.TaskStatusDone || oldStatus == store.TaskStatusFailed || oldStatus == store.TaskStatusCancelled || oldStatus == store.TaskStatusWaiting) {
-			newPrompt := task.Prompt
-			if req.Prompt != nil {
-				newPrompt = *req.Prompt
-			}
-			// Default to resuming the previous session; the client can opt out by sending fresh_start=true.
-			freshStart := false
-			if req.FreshStart != nil {
-				freshStart = *req.FreshStart
-			}
-			// Only delete the worktree directory and branch on fresh_start.
-			// For normal retries the branch holds Claude's committed work;
-			// ensureTaskWorktrees will reattach it on the next run.
-			if freshStart && len(task.WorktreePaths) > 0 {
-				h.runner.CleanupWorktrees(id, task.WorktreePaths, task.BranchName)
-			}
-			if err := s.ResetTaskForRetry(r.Context(), id, newPrompt, freshStart); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			h.insertEventOrLog(r.Context(), id, store.EventTypeStateChange,
-				store.NewStateChangeData(oldStatus, store.TaskStatusBacklog, store.TriggerUser, nil))
-			h.diffCache.invalidate(id)
+	// Reject any direct PATCH to in_progress other than from backlog (i.e.
+	// from waiting or failed). These transitions only flip the status and
+	// consume a concurrency slot — they do NOT launch an agent worker,
+	// leaving the task stuck in_progress with no worker. Resuming a
+	// waiting/failed task must go through ResumeTask/TestTask/SubmitFeedback,
+	// which pair the status flip with a RunBackground launch.
+	if newStatus == store.TaskStatusInProgress && oldStatus != store.TaskStatusBacklog && !task.IsTestRun {
+		writeFieldError(w, "status", "cannot move a %s task to in_progress via PATCH; use resume, test, or feedback to start a worker", oldStatus)
+		return
+	}
 
-			updated, err := s.GetTask(r.Context(), id)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			httpjson.Write(w, http.StatusOK, updated)
+	// Refuse to start a task on an agent whose capability probe failed: the
+	// run would fail on its first turn.
+	if newStatus == store.TaskStatusInProgress && !task.IsTestRun {
+		if err := h.runner.CheckAgentAvailable(task); err != nil {
+			writeError(w, err)
 			return
 		}
-		// Enforce concurrency limit for manual backlog → in_progress transitions.
-		if newStatus == store.TaskStatusInProgress && oldStatus == store.TaskStatusBacklog && !task.IsTestRun {
-			if !h.checkConcurrencyAndUpdateStatus(r.Context(), w, id, newStatus) {
-				return
-			}
-			h.insertEventOrLog(r.Context(), id, store.EventTypeStateChange,
-				store.NewStateChangeData(oldStatus, newStatus, store.TriggerUser, nil))
-			h.diffCache.invalidate(id)
-			h.cascadeArchiveThreadsForTask(id.String())
-			sessionID := ""
-			if !task.FreshStart && task.SessionID != nil {
-				sessionID = *task.SessionID
-			}
-			h.runner.RunBackground(id, task.Prompt, sessionID, false)
-			updated, err := s.GetTask(r.Context(), id)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	// A task waits for every task it depends on. Automation already holds
+	// it back; a manual start is refused the same way, judged by the
+	// dependencies sent with the request when there are any.
+	if newStatus == store.TaskStatusInProgress && oldStatus == store.TaskStatusBacklog && !task.IsTestRun {
+		if req.DependsOn == nil {
+			if unmetDeps, err = s.UnmetDependencies(r.Context(), id); err != nil {
+				writeError(w, err)
 				return
 			}
-			httpjson.Write(w, http.StatusOK, updated)
+		}
+		if len(unmetDeps) > 0 {
+			writeError(w, fmt.Errorf("%w: waiting on %s", store.ErrDependenciesUnmet, strings.Join(unmetDeps, ", ")))
 			return
 		}
+	}
 
-		// Reject any other direct PATCH to in_progress (i.e. from waiting or
-		// failed; backlog is handled above). These transitions only flip the
-		// status and consume a concurrency slot — they do NOT launch an agent
-		// worker, leaving the task stuck in_progress with no worker. Resuming a
-		// waiting/failed task must go through ResumeTask/TestTask/SubmitFeedback,
-		// which pair the status flip with a RunBackground launch.
-		if newStatus == store.TaskStatusInProgress && !task.IsTestRun {
-			http.Error(w, "cannot move a task to in_progress via PATCH; use resume, test, or feedback to start a worker", http.StatusBadRequest)
+	// The transition joins the field changes in the same patch. Manual
+	// backlog → in_progress transitions also enforce the concurrency limit.
+	patch.Status = &newStatus
+	if newStatus == store.TaskStatusInProgress && !task.IsTestRun {
+		if !h.checkConcurrencyAndPatch(r.Context(), w, id, patch) {
 			return
 		}
+	} else if err := s.PatchTask(r.Context(), id, patch); err != nil {
+		writePatchError(w, err)
+		return
+	}
+	h.insertEventOrLog(r.Context(), id, store.EventTypeStateChange,
+		store.NewStateChangeData(oldStatus, newStatus, store.TriggerUser, nil))
+	h.diffCache.invalidate(id)
+	if oldStatus == store.TaskStatusBacklog || oldStatus == store.TaskStatusWaiting {
+		h.cascadeArchiveThreadsForTask(id.String())
+	}
 
-		if err := s.UpdateTaskStatus(r.Context(), id, newStatus); err != nil {
-			if errors.Is(err, statemachine.ErrInvalidTransition) {
-				http.Error(w, err.Error(), http.StatusBadRequest)
-			} else {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-			}
+	if newStatus == store.TaskStatusInProgress && oldStatus == store.TaskStatusBacklog {
+		// Start from the patched task so prompt and fresh_start edits sent
+		// with the transition take effect.
+		started, err := s.GetTask(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
-		h.insertEventOrLog(r.Context(), id, store.EventTypeStateChange,
-			store.NewStateChangeData(oldStatus, newStatus, store.TriggerUser, nil))
-		h.diffCache.invalidate(id)
-		if oldStatus == store.TaskStatusBacklog || oldStatus == store.TaskStatusWaiting {
-			h.cascadeArchiveThreadsForTask(id.String())
+		sessionID := ""
+		if !started.FreshStart && started.SessionID != nil {
+			sessionID = *started.SessionID
 		}
-
-		if newStatus == store.TaskStatusInProgress && oldStatus == store.TaskStatusBacklog {
-			sessionID := ""
-			if !task.FreshStart && task.SessionID != nil {
-				sessionID = *task.SessionID
-			}
-			h.runner.RunBackground(id, task.Prompt, sessionID, false)
-		}
-
+		h.runner.RunBackground(id, started.Prompt, sessionID, false)
 	}
 
+	h.writeTask(w, r, s, id)
+}
+
+// writeTask responds with the current state of the task.
+func (h *Handler) writeTask(w http.ResponseWriter, r *http.Request, s *store.Store, id uuid.UUID) {
 	updated, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	httpjson.Write(w, http.StatusOK, updated)
 }
 
+// writePatchError maps a store.PatchTask failure to an HTTP response: a
+// rejected transition gets the status and code writeError gives it, with
+// the message also reported as a field error on status; anything else goes
+// through writeError (a task whose status changed since the request read
+// it is a 409).
+func writePatchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrInvalidTransition) {
+		var errs ValidationErrors
+		errs.Add("status", "%v", err)
+		status, _ := errorStatus(err)
+		httpjson.Write(w, status, validationResponse{
+			Error:  errs.Error(),
+			Code:   codeInvalidTransition,
+			Fields: errs,
+		})
+		return
+	}
+	writeError(w, err)
+}
+
 // DeleteTask soft-deletes a task by writing a tombstone. The task data is
 // retained on disk for the configured retention period so it can be restored.
 func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
@@ -966,7 +1327,7 @@ func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request, id uuid.UUI
 		}
 	}
 	if err := s.DeleteTask(r.Context(), id, req.Reason); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	h.cascadeArchiveThreadsForTask(id.String())
@@ -981,7 +1342,7 @@ func (h *Handler) ListDeletedTasks(w http.ResponseWriter, r *http.Request) {
 	}
 	tasks, err := s.ListDeletedTasks(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	if tasks == nil {
@@ -1004,19 +1365,3 @@ func (h *Handler) applyRestore(ctx context.Context, id uuid.UUID) error {
 	h.cascadeUnarchiveThreadsForTask(id.String())
 	return nil
 }
-
-// validateCustomPatterns compiles each pattern to ensure it is valid RE2 syntax.
-// Returns a 400-friendly error message that includes the offending pattern.
-func validateCustomPatterns(passPatterns, failPatterns []string) error {
-	for _, p := range passPatterns {
-		if _, err := regexp.Compile(p); err != nil {
-			return fmt.Errorf("invalid custom_pass_pattern %q: %v", p, err)
-		}
-	}
-	for _, p := range failPatterns {
-		if _, err := regexp.Compile(p); err != nil {
-			return fmt.Errorf("invalid custom_fail_pattern %q: %v", p, err)
-		}
-	}
-	return nil
-}
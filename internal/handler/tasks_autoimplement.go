@@ -1,10 +1,8 @@
 package handler
 
 import (
-	"cmp"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -19,7 +17,6 @@ import (
 	"latere.ai/x/wallfacer/internal/gitutil"
 	"latere.ai/x/wallfacer/internal/harness"
 	"latere.ai/x/wallfacer/internal/logger"
-	"latere.ai/x/wallfacer/internal/pkg/statemachine"
 	"latere.ai/x/wallfacer/internal/pkg/watcher"
 	"latere.ai/x/wallfacer/internal/store"
 	"latere.ai/x/wallfacer/internal/toposadv"
@@ -57,10 +54,17 @@ func countRegularInProgress(tasks []store.Task) int {
 }
 
 // checkConcurrencyAndUpdateStatus acquires promoteMu, enforces the regular
-// in-progress concurrency limit, and calls store.UpdateTaskStatus. It writes
+// in-progress concurrency limit, and applies the status transition. It writes
 // the appropriate HTTP error response and returns false on any failure;
 // on success it returns true with the mutex already released.
 func (h *Handler) checkConcurrencyAndUpdateStatus(ctx context.Context, w http.ResponseWriter, id uuid.UUID, newStatus store.TaskStatus) bool {
+	return h.checkConcurrencyAndPatch(ctx, w, id, store.TaskPatch{Status: &newStatus})
+}
+
+// checkConcurrencyAndPatch is checkConcurrencyAndUpdateStatus for a patch
+// that carries field changes along with the transition; the patch is
+// applied with store.PatchTask, so either all of it lands or none of it.
+func (h *Handler) checkConcurrencyAndPatch(ctx context.Context, w http.ResponseWriter, id uuid.UUID, patch store.TaskPatch) bool {
 	promoteMu.Lock()
 	defer promoteMu.Unlock()
 
@@ -69,16 +73,16 @@ func (h *Handler) checkConcurrencyAndUpdateStatus(ctx context.Context, w http.Re
 		http.Error(w, fmt.Sprintf("max concurrent tasks (%d) reached", h.maxConcurrentTasks()), http.StatusConflict)
 		return false
 	}
+	if slots, repo := h.repoSlots(h.currentWorkspaces()); slots == 0 {
+		http.Error(w, fmt.Sprintf("max concurrent tasks per repository (%d) reached for %s", h.maxParallelPerRepo(), repo), http.StatusConflict)
+		return false
+	}
 	s, ok := h.requireStore(w)
 	if !ok {
 		return false
 	}
-	if err := s.UpdateTaskStatus(ctx, id, newStatus); err != nil {
-		if errors.Is(err, statemachine.ErrInvalidTransition) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+	if err := s.PatchTask(ctx, id, patch); err != nil {
+		writePatchError(w, err)
 		return false
 	}
 	return true
@@ -237,6 +241,11 @@ func (h *Handler) tryAutoPromote(ctx context.Context) {
 	if h.breakers["auto-promote"].isOpen() {
 		return
 	}
+	// Promoted tasks would only pause again at their first turn.
+	if h.boardBudgetExceeded(ctx) != "" {
+		h.incAutoimplementAction("auto_promoter", "skipped_budget")
+		return
+	}
 
 	// candidates is populated by Phase1 and consumed by Phase2 via closure.
 	var candidates []autoPromoteCandidate
@@ -280,90 +289,72 @@ func (h *Handler) tryAutoPromote(ctx context.Context) {
 			})
 
 			// Check available capacity for backlog promotion (global count).
+			// The backlog is scanned even when no slot is free, so the timer
+			// for the soonest scheduled task is armed on a saturated board too.
 			regularInProgress := h.countGlobalInProgress()
 			availableSlots := h.maxConcurrentTasks() - regularInProgress
 			if availableSlots <= 0 && len(candidates) == 0 {
 				h.incAutoimplementAction("auto_promoter", "skipped_capacity")
-				return nil, nil
 			}
 
-			if availableSlots > 0 {
-				type cpCandidate struct {
-					task  store.Task
-					store *store.Store
-					score int
-				}
-				var cpCandidates []cpCandidate
-				var nextScheduled *time.Time
-				h.forCurrentStore(func(s *store.Store, _ []string) {
-					backlogTasks, err := s.ListTasksByStatus(ctx, store.TaskStatusBacklog)
-					if err != nil {
-						return
-					}
-					for i := range backlogTasks {
-						t := &backlogTasks[i]
-						// Skip task-kinds that the auto-promoter must not touch:
-						// routine cards are schedule templates driven by the
-						// routine engine rather than the board lifecycle.
-						if t.IsRoutine() {
-							continue
+			var nextScheduled *time.Time
+			h.forCurrentStore(func(s *store.Store, ws []string) {
+				// Every task of this store runs in all of ws, so the
+				// per-repository cap bounds them together.
+				repoFree, _ := h.repoSlots(ws)
+				repoSkipped := false
+				// rankBacklog orders eligible tasks by effective priority
+				// (critical-path score plus aging), so long-waiting tasks
+				// are not starved by newer high-priority work.
+				for _, e := range h.rankBacklog(ctx, s, time.Now()) {
+					switch e.blocked {
+					case queueReasonScheduled:
+						h.incAutoimplementAction("auto_promoter", "skipped_scheduled")
+						if nextScheduled == nil || e.task.ScheduledAt.Before(*nextScheduled) {
+							nextScheduled = e.task.ScheduledAt
 						}
-						if t.ScheduledAt != nil && time.Now().Before(*t.ScheduledAt) {
-							h.incAutoimplementAction("auto_promoter", "skipped_scheduled")
-							if nextScheduled == nil || t.ScheduledAt.Before(*nextScheduled) {
-								nextScheduled = t.ScheduledAt
-							}
-							continue
-						}
-						satisfied, err := s.AreDependenciesSatisfied(ctx, t.ID)
-						if err != nil || !satisfied {
-							h.incAutoimplementAction("auto_promoter", "skipped_dependency")
-							continue
-						}
-						if locked, threadID := h.isTaskLockedByAgent(t.ID.String()); locked {
-							h.incAutoimplementAction("auto_promoter", "skipped_locked")
-							logger.Handler.Debug("auto-promote: skipping locked task",
-								"task", t.ID, "thread", threadID)
-							continue
-						}
-						cpCandidates = append(cpCandidates, cpCandidate{task: *t, store: s})
-					}
-					// Score all candidates in one batch: CriticalPathScores
-					// builds the reverse-dependency graph once rather than once
-					// per candidate.
-					ids := make([]uuid.UUID, len(cpCandidates))
-					for i := range cpCandidates {
-						ids[i] = cpCandidates[i].task.ID
+						continue
+					case queueReasonBlocked:
+						h.incAutoimplementAction("auto_promoter", "skipped_blocked")
+						continue
+					case queueReasonTriage:
+						h.incAutoimplementAction("auto_promoter", "skipped_triage")
+						continue
+					case queueReasonDependencies:
+						h.incAutoimplementAction("auto_promoter", "skipped_dependency")
+						continue
+					case queueReasonLocked:
+						h.incAutoimplementAction("auto_promoter", "skipped_locked")
+						logger.Handler.Debug("auto-promote: skipping locked task",
+							"task", e.task.ID, "thread", e.thread)
+						continue
+					case queueReasonShadow:
+						continue
 					}
-					scores := s.CriticalPathScores(ids)
-					for i := range cpCandidates {
-						cpCandidates[i].score = scores[cpCandidates[i].task.ID]
+					// Keep scanning without promoting once the slots run out:
+					// a scheduled task further down still sets nextScheduled.
+					if availableSlots <= 0 {
+						continue
 					}
-				})
-				// Arm a precise timer for the soonest scheduled task so it is
-				// promoted within milliseconds of its due time rather than waiting
-				// for the next 60-second ticker tick.
-				if nextScheduled != nil {
-					h.ensureScheduledPromoteTrigger(ctx, *nextScheduled)
-				}
-				if len(cpCandidates) > 0 {
-					slices.SortFunc(cpCandidates, func(a, b cpCandidate) int {
-						if c := cmp.Compare(b.score, a.score); c != 0 {
-							return c
-						}
-						if c := cmp.Compare(a.task.Position, b.task.Position); c != 0 {
-							return c
-						}
-						return a.task.CreatedAt.Compare(b.task.CreatedAt)
-					})
-					for _, cp := range cpCandidates {
-						if availableSlots <= 0 {
-							break
+					if repoFree == 0 {
+						if !repoSkipped {
+							h.incAutoimplementAction("auto_promoter", "skipped_repo_capacity")
+							repoSkipped = true
 						}
-						candidates = append(candidates, autoPromoteCandidate{task: cp.task, store: cp.store})
-						availableSlots--
+						continue
+					}
+					candidates = append(candidates, autoPromoteCandidate{task: e.task, store: e.store})
+					availableSlots--
+					if repoFree > 0 {
+						repoFree--
 					}
 				}
+			})
+			// Arm a precise timer for the soonest scheduled task so it is
+			// promoted within milliseconds of its due time rather than waiting
+			// for the next 60-second ticker tick.
+			if nextScheduled != nil {
+				h.ensureScheduledPromoteTrigger(ctx, *nextScheduled)
 			}
 
 			if len(candidates) == 0 {
@@ -423,6 +414,11 @@ func (h *Handler) tryAutoPromote(ctx context.Context) {
 					h.incAutoimplementAction("auto_promoter", "skipped_capacity")
 					break
 				}
+				if slots, repo := h.repoSlots(h.currentWorkspaces()); slots == 0 {
+					h.incAutoimplementAction("auto_promoter", "skipped_repo_capacity")
+					logger.Handler.Debug("auto-promote: repository at its per-repo limit", "repo", repo)
+					break
+				}
 
 				// Abort promotion when the container runtime is known-unavailable.
 				// Without this guard, slot openings caused by failures would trigger
@@ -442,6 +438,20 @@ func (h *Handler) tryAutoPromote(ctx context.Context) {
 					continue
 				}
 
+				// The user may have blocked the task since Phase 1.
+				if fresh, err := c.store.GetTask(ctx, c.task.ID); err == nil && fresh.IsBlocked() {
+					h.incAutoimplementAction("auto_promoter", "skipped_blocked")
+					continue
+				}
+
+				// Leave the task in the backlog while its agent fails the
+				// capability probe; it is promoted once a probe passes.
+				if err := h.runner.CheckAgentAvailable(&c.task); err != nil {
+					h.incAutoimplementAction("auto_promoter", "skipped_agent_unavailable")
+					logger.Handler.Debug("auto-promote: agent unavailable", "task", c.task.ID, "error", err)
+					continue
+				}
+
 				logger.Handler.Info("auto-promoting backlog task",
 					"task", c.task.ID, "position", c.task.Position,
 					"in_progress", freshInProgress)
@@ -512,11 +522,13 @@ func (h *Handler) tryAutoRetry(ctx context.Context, s *store.Store, task store.T
 	logger.Handler.Info("auto-retrying failed task",
 		"task", task.ID, "category", task.FailureCategory,
 		"retry_attempt", task.AutoRetryCount+1)
+	attemptDiff, _, _ := collectTaskDiff(ctx, s, &task, false)
 	if err := s.ResetTaskForRetry(ctx, task.ID, task.Prompt, false); err != nil {
 		logger.Handler.Error("auto-retry reset failed", "task", task.ID, "error", err)
 		h.breakers["auto-retry"].recordFailure(&task.ID, err.Error())
 		return
 	}
+	archiveAttemptDiff(ctx, s, task.ID, attemptDiff)
 	h.incAutoimplementAction("auto_retrier", "retried")
 	h.breakers["auto-retry"].recordSuccess()
 	h.insertEventOrLog(ctx, task.ID, store.EventTypeStateChange,
@@ -739,6 +751,10 @@ func (h *Handler) tryAutoTest(ctx context.Context) {
 					if t.LastTestResult != "" || t.IsTestRun {
 						continue
 					}
+					// The agent is waiting on an approval, not for verification.
+					if t.PendingApproval() != nil {
+						continue
+					}
 					// When review supersedes the test agent for this task, skip it
 					// here so the two don't both verify.
 					if h.reviewSupersedesTest(t) {
@@ -955,6 +971,10 @@ func (h *Handler) tryAutoSubmit(ctx context.Context) {
 				if t.IsTestRun {
 					continue
 				}
+				// Never merge work the agent paused to ask approval for.
+				if t.PendingApproval() != nil {
+					continue
+				}
 				if len(t.WorktreePaths) == 0 || len(missingTaskWorktrees(t)) > 0 {
 					continue
 				}
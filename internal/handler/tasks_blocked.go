@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/watcher"
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/webpush"
+)
+
+// parseTaskBlock reads the blocked field of a PATCH body for task: "null"
+// clears the block; an object {"reason", "until"} sets it. Re-blocking an
+// already blocked task keeps its Since, and its reminder state unless the
+// unblock date changed.
+func parseTaskBlock(task *store.Task, raw json.RawMessage, now time.Time) (block *store.TaskBlock, clear bool, err error) {
+	if string(raw) == "null" {
+		return nil, true, nil
+	}
+	if task.Status != store.TaskStatusBacklog || task.IsRoutine() {
+		return nil, false, errors.New("only backlog tasks can be blocked")
+	}
+	var req struct {
+		Reason string     `json:"reason"`
+		Until  *time.Time `json:"until"`
+	}
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, false, err
+	}
+	block = &store.TaskBlock{Reason: strings.TrimSpace(req.Reason), Since: now, Until: req.Until}
+	if block.Reason == "" {
+		return nil, false, errors.New("reason is required")
+	}
+	if block.Until != nil && block.Until.IsZero() {
+		block.Until = nil
+	}
+	if prev := task.Blocked; prev != nil {
+		block.Since = prev.Since
+		if sameTime(prev.Until, block.Until) {
+			block.RemindedAt = prev.RemindedAt
+		}
+	}
+	return block, false, nil
+}
+
+// sameTime reports whether two optional times are both nil or equal.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// recordBlockChange logs a block being set or cleared on the task's timeline.
+func (h *Handler) recordBlockChange(ctx context.Context, task *store.Task, patch store.TaskPatch) {
+	switch {
+	case patch.Blocked != nil:
+		h.insertEventOrLog(ctx, task.ID, store.EventTypeSystem, map[string]string{
+			"result": "Blocked: " + patch.Blocked.Reason,
+		})
+	case patch.ClearBlocked && task.IsBlocked():
+		h.insertEventOrLog(ctx, task.ID, store.EventTypeSystem, map[string]string{
+			"result": "Unblocked",
+		})
+	}
+}
+
+// StartUnblockReminders periodically checks blocked backlog tasks and, once
+// a task's unblock date passes, records a reminder on its timeline and sends
+// its creator an "unblock" notification. Each unblock date reminds once; the
+// task stays blocked until the user clears the block.
+func (h *Handler) StartUnblockReminders(ctx context.Context) {
+	watcher.Start(ctx, watcher.Config{
+		Interval: constants.UnblockReminderInterval,
+		Action: func(ctx context.Context) {
+			h.remindUnblocks(ctx, time.Now())
+		},
+	})
+}
+
+// remindUnblocks fires the reminders due at now on the current board.
+func (h *Handler) remindUnblocks(ctx context.Context, now time.Time) {
+	s, ok := h.currentStore()
+	if !ok || s == nil {
+		return
+	}
+	backlog, err := s.ListTasksByStatus(ctx, store.TaskStatusBacklog)
+	if err != nil {
+		logger.Handler.Warn("unblock reminders: list backlog", "error", err)
+		return
+	}
+	board := h.currentBoardKey()
+	for i := range backlog {
+		t := &backlog[i]
+		if !t.Blocked.ReminderDue(now) {
+			continue
+		}
+		if err := s.MarkUnblockReminded(ctx, t.ID, now); err != nil {
+			logger.Handler.Warn("unblock reminders: mark reminded", "task", t.ID, "error", err)
+			continue
+		}
+		h.insertEventOrLogTo(ctx, s, t.ID, store.EventTypeSystem, map[string]string{
+			"result": "Unblock date passed; still blocked: " + t.Blocked.Reason,
+		})
+		h.deliverPush(ctx, pushDelivery{user: t.CreatedBy, tags: t.Tags, msg: webpush.Message{
+			Title: "Blocked task may be unblocked",
+			Body:  cmp.Or(t.Title, truncateRunes(t.Prompt, 120)) + ": " + t.Blocked.Reason,
+			Tag:   "task-" + t.ID.String(),
+			URL:   "/?task=" + t.ID.String(),
+			Kind:  "unblock",
+		}}, board, now)
+	}
+}
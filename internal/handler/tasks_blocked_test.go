@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func patchTask(h *Handler, id uuid.UUID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/api/tasks/"+id.String(), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.UpdateTask(w, req, id)
+	return w
+}
+
+func listTaskIDs(t *testing.T, h *Handler, query string) []uuid.UUID {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ListTasks(w, httptest.NewRequest(http.MethodGet, "/api/tasks"+query, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list %s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+	}
+	var tasks []store.Task
+	if strings.Contains(query, "status=") {
+		var page store.TaskPage
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		tasks = page.Tasks
+	} else if err := json.Unmarshal(w.Body.Bytes(), &tasks); err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]uuid.UUID, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+	}
+	return ids
+}
+
+func TestUpdateTask_Blocked(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "ship the export", Timeout: 15})
+	free, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "free", Timeout: 15})
+
+	until := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	body := `{"blocked":{"reason":"  waiting on legal sign-off ","until":"` + until.Format(time.RFC3339) + `"}}`
+	if w := patchTask(h, task.ID, body); w.Code != http.StatusOK {
+		t.Fatalf("block: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	got, _ := h.store.GetTask(ctx, task.ID)
+	if !got.IsBlocked() || got.Blocked.Reason != "waiting on legal sign-off" || !got.Blocked.Until.Equal(until) {
+		t.Fatalf("block = %+v", got.Blocked)
+	}
+
+	for query, want := range map[string]uuid.UUID{
+		"?blocked=true":                 task.ID,
+		"?blocked=false":                free.ID,
+		"?status=backlog&blocked=true":  task.ID,
+		"?status=backlog&blocked=false": free.ID,
+	} {
+		if ids := listTaskIDs(t, h, query); len(ids) != 1 || ids[0] != want {
+			t.Errorf("%s listed %v, want [%s]", query, ids, want)
+		}
+	}
+	w := httptest.NewRecorder()
+	h.ListTasks(w, httptest.NewRequest(http.MethodGet, "/api/tasks?blocked=maybe", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("blocked=maybe: expected 400, got %d", w.Code)
+	}
+
+	entries := h.rankBacklog(ctx, h.store, time.Now())
+	for _, e := range entries {
+		if e.task.ID == task.ID && e.blocked != queueReasonBlocked {
+			t.Errorf("queue reason = %q, want blocked", e.blocked)
+		}
+	}
+
+	if w := patchTask(h, task.ID, `{"blocked":null}`); w.Code != http.StatusOK {
+		t.Fatalf("unblock: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.IsBlocked() {
+		t.Errorf("block = %+v after unblocking, want nil", got.Blocked)
+	}
+	events, _ := h.store.GetEvents(ctx, task.ID)
+	var results []string
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeSystem {
+			var data map[string]string
+			_ = json.Unmarshal(ev.Data, &data)
+			results = append(results, data["result"])
+		}
+	}
+	if len(results) != 2 || results[0] != "Blocked: waiting on legal sign-off" || results[1] != "Unblocked" {
+		t.Errorf("timeline = %q", results)
+	}
+}
+
+func TestUpdateTask_BlockedRejects(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 15})
+
+	if w := patchTask(h, task.ID, `{"blocked":{"reason":"  "}}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("empty reason: expected 422, got %d", w.Code)
+	}
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+	if w := patchTask(h, task.ID, `{"blocked":{"reason":"later"}}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("waiting task: expected 422, got %d", w.Code)
+	}
+}
+
+func TestRemindUnblocks(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 15})
+	until := time.Now().Add(time.Hour)
+	if err := h.store.PatchTask(ctx, task.ID, store.TaskPatch{Blocked: &store.TaskBlock{Reason: "vendor fix", Since: time.Now(), Until: &until}}); err != nil {
+		t.Fatal(err)
+	}
+
+	countReminders := func() int {
+		events, _ := h.store.GetEvents(ctx, task.ID)
+		n := 0
+		for _, ev := range events {
+			if ev.EventType == store.EventTypeSystem && strings.Contains(string(ev.Data), "Unblock date passed") {
+				n++
+			}
+		}
+		return n
+	}
+
+	h.remindUnblocks(ctx, time.Now())
+	if n := countReminders(); n != 0 {
+		t.Fatalf("reminded %d times before the unblock date", n)
+	}
+	later := until.Add(time.Minute)
+	h.remindUnblocks(ctx, later)
+	h.remindUnblocks(ctx, later.Add(time.Minute))
+	if n := countReminders(); n != 1 {
+		t.Errorf("reminded %d times after the unblock date, want 1", n)
+	}
+	got, _ := h.store.GetTask(ctx, task.ID)
+	if !got.IsBlocked() || got.Blocked.RemindedAt == nil {
+		t.Errorf("block = %+v, want still blocked with the reminder recorded", got.Blocked)
+	}
+}
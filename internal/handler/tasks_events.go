@@ -3,15 +3,20 @@ package handler
 import (
 	"bytes"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
 	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/pkg/sortedkeys"
 	"latere.ai/x/wallfacer/internal/store"
 )
 
-// GetTurnUsage returns token usage for a specific task turn.
+// GetTurnUsage returns token usage for a specific task turn. Each
+// implementation or test turn carries the exit metadata of its agent process
+// in exit; turns that failed before reporting usage appear as exit-only
+// records so infrastructure failures stay visible.
 func (h *Handler) GetTurnUsage(w http.ResponseWriter, _ *http.Request, id uuid.UUID) {
 	s, ok := h.requireStore(w)
 	if !ok {
@@ -19,10 +24,48 @@ func (h *Handler) GetTurnUsage(w http.ResponseWriter, _ *http.Request, id uuid.U
 	}
 	records, err := s.GetTurnUsages(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
-	httpjson.Write(w, http.StatusOK, records)
+	exits, err := s.GetTurnExits(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, attachTurnExits(records, exits))
+}
+
+// attachTurnExits pairs turn-loop usage records with the exit metadata saved
+// under the same turn number. Sub-agent records (title, oversight, ...) number
+// their own turns and are left alone. Exits with no matching record are
+// appended as exit-only records, and the result is kept in time order.
+func attachTurnExits(records []store.TurnUsageRecord, exits map[int]store.TurnExit) []store.TurnUsageRecord {
+	if len(exits) == 0 {
+		return records
+	}
+	matched := make(map[int]bool, len(exits))
+	for i := range records {
+		switch records[i].SubAgent {
+		case "", store.SandboxActivityImplementation, store.SandboxActivityTest:
+		default:
+			continue
+		}
+		if exit, ok := exits[records[i].Turn]; ok && !matched[exit.Turn] {
+			records[i].Exit = &exit
+			matched[exit.Turn] = true
+		}
+	}
+	for turn := range sortedkeys.Of(exits) {
+		if matched[turn] {
+			continue
+		}
+		exit := exits[turn]
+		records = append(records, store.TurnUsageRecord{Turn: turn, Timestamp: exit.EndedAt, Exit: &exit})
+	}
+	slices.SortStableFunc(records, func(a, b store.TurnUsageRecord) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return records
 }
 
 // eventsPageResponse is the JSON envelope returned when pagination params are present.
@@ -35,13 +78,20 @@ type eventsPageResponse struct {
 
 // validEventTypes is the set of known event type strings for param validation.
 var validEventTypes = map[string]store.EventType{
-	string(store.EventTypeStateChange): store.EventTypeStateChange,
-	string(store.EventTypeOutput):      store.EventTypeOutput,
-	string(store.EventTypeFeedback):    store.EventTypeFeedback,
-	string(store.EventTypeError):       store.EventTypeError,
-	string(store.EventTypeSystem):      store.EventTypeSystem,
-	string(store.EventTypeSpanStart):   store.EventTypeSpanStart,
-	string(store.EventTypeSpanEnd):     store.EventTypeSpanEnd,
+	string(store.EventTypeStateChange):      store.EventTypeStateChange,
+	string(store.EventTypeOutput):           store.EventTypeOutput,
+	string(store.EventTypeFeedback):         store.EventTypeFeedback,
+	string(store.EventTypeError):            store.EventTypeError,
+	string(store.EventTypeSystem):           store.EventTypeSystem,
+	string(store.EventTypeSpanStart):        store.EventTypeSpanStart,
+	string(store.EventTypeSpanEnd):          store.EventTypeSpanEnd,
+	string(store.EventTypePipelineProgress): store.EventTypePipelineProgress,
+	string(store.EventTypeInputRequest):     store.EventTypeInputRequest,
+	string(store.EventTypeInput):            store.EventTypeInput,
+	string(store.EventTypeStalled):          store.EventTypeStalled,
+	string(store.EventTypeActivity):         store.EventTypeActivity,
+	string(store.EventTypeTimeoutExtended):  store.EventTypeTimeoutExtended,
+	string(store.EventTypeConflictResolved): store.EventTypeConflictResolved,
 }
 
 // GetEvents returns the event timeline for a task.
@@ -57,9 +107,13 @@ var validEventTypes = map[string]store.EventType{
 //   - after  – exclusive event ID cursor; only events with ID > after are returned (default 0)
 //   - limit  – max events per page, 1–1000 (default 200)
 //   - types  – comma-separated event types to include (default: all types)
+//   - raw    – "true" returns every stored event; by default runs of
+//     identical output, system, and error events are coalesced into one
+//     (see store.CoalesceEvents)
 func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
 	q := r.URL.Query()
 	isPaged := q.Has("after") || q.Has("limit") || q.Has("types")
+	coalesce := q.Get("raw") != "true"
 
 	s, ok := h.requireStore(w)
 	if !ok {
@@ -70,12 +124,15 @@ func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request, id uuid.UUID
 		// Backward-compatible: return the full list as a plain JSON array.
 		events, err := s.GetEvents(r.Context(), id)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		if events == nil {
 			events = []store.TaskEvent{}
 		}
+		if coalesce {
+			events = store.CoalesceEvents(events, store.EventCoalesceWindow)
+		}
 		httpjson.Write(w, http.StatusOK, events)
 		return
 	}
@@ -124,9 +181,9 @@ func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request, id uuid.UUID
 		}
 	}
 
-	page, err := s.GetEventsPage(r.Context(), id, afterID, limit, typeSet)
+	page, err := s.GetEventsPage(r.Context(), id, afterID, limit, typeSet, coalesce)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -249,6 +249,44 @@ func TestGetEvents_Paged_TypeFilter(t *testing.T) {
 	}
 }
 
+func TestGetEvents_Paged_PipelineProgressFilter(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 30, Kind: store.TaskKindTask})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if err := h.store.InsertEvent(ctx, task.ID, store.EventTypeSystem, map[string]string{"result": "system"}); err != nil {
+		t.Fatalf("InsertEvent system: %v", err)
+	}
+	progress := store.PipelineProgressData{Phase: store.PipelinePhaseRebase, Repo: "/repo", Attempt: 2, Percent: 40, Message: "Rebasing..."}
+	if err := h.store.InsertEvent(ctx, task.ID, store.EventTypePipelineProgress, progress); err != nil {
+		t.Fatalf("InsertEvent pipeline_progress: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+task.ID.String()+"/events?types=pipeline_progress", nil)
+	w := httptest.NewRecorder()
+	h.GetEvents(w, req, task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp eventsPageResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Events) != 1 {
+		t.Fatalf("expected 1 pipeline_progress event, got %d", len(resp.Events))
+	}
+	var got store.PipelineProgressData
+	if err := json.Unmarshal(resp.Events[0].Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != progress {
+		t.Errorf("data = %+v, want %+v", got, progress)
+	}
+}
+
 func TestGetEvents_Paged_InvalidAfter(t *testing.T) {
 	h := newTestHandler(t)
 	ctx := context.Background()
@@ -321,3 +359,84 @@ func TestGetEvents_Paged_LimitCappedAt1000(t *testing.T) {
 		t.Errorf("expected 200 for limit=9999 (capped), got %d: %s", w.Code, w.Body.String())
 	}
 }
+
+func TestGetTurnUsage_AttachesExitMetadata(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 30, Kind: store.TaskKindTask})
+
+	now := time.Now().UTC()
+	_ = h.store.AppendTurnUsage(task.ID, store.TurnUsageRecord{Turn: 1, Timestamp: now, InputTokens: 10, SubAgent: store.SandboxActivityImplementation})
+	_ = h.store.AppendTurnUsage(task.ID, store.TurnUsageRecord{Turn: 1, Timestamp: now.Add(time.Second), SubAgent: store.SandboxActivityTitle})
+	_ = h.store.SaveTurnExit(task.ID, store.TurnExit{Turn: 1, ExitCode: 0, EndedAt: now})
+	// Turn 2 crashed before reporting usage: only its exit exists.
+	_ = h.store.SaveTurnExit(task.ID, store.TurnExit{Turn: 2, ExitCode: -1, Signal: "killed", OOMKilled: true, EndedAt: now.Add(time.Minute)})
+
+	w := httptest.NewRecorder()
+	h.GetTurnUsage(w, httptest.NewRequest(http.MethodGet, "/api/tasks/"+task.ID.String()+"/turn-usage", nil), task.ID)
+	var records []store.TurnUsageRecord
+	if err := json.NewDecoder(w.Body).Decode(&records); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if records[0].Exit == nil || records[0].Exit.ExitCode != 0 {
+		t.Errorf("implementation turn 1 missing exit: %+v", records[0])
+	}
+	if records[1].SubAgent != store.SandboxActivityTitle || records[1].Exit != nil {
+		t.Errorf("sub-agent record got an exit attached: %+v", records[1])
+	}
+	last := records[2]
+	if last.Turn != 2 || last.Exit == nil || !last.Exit.OOMKilled || last.Exit.Signal != "killed" {
+		t.Errorf("exit-only record for turn 2 = %+v", last)
+	}
+}
+
+// TestGetEvents_CoalescesUnlessRaw verifies that repeated output events are
+// folded into one by default, in both response modes, and returned one by
+// one with raw=true.
+func TestGetEvents_CoalescesUnlessRaw(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 30, Kind: store.TaskKindTask})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	for range 3 {
+		if err := h.store.InsertEvent(ctx, task.ID, store.EventTypeOutput, map[string]string{"result": "same"}); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+
+	get := func(query string) []store.TaskEvent {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+task.ID.String()+"/events"+query, nil)
+		w := httptest.NewRecorder()
+		h.GetEvents(w, req, task.ID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", query, w.Code)
+		}
+		var resp eventsPageResponse
+		if query == "" || query == "?raw=true" {
+			if err := json.NewDecoder(w.Body).Decode(&resp.Events); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		} else if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp.Events
+	}
+
+	for _, q := range []string{"", "?types=output"} {
+		if events := get(q); len(events) != 1 || events[0].Count != 3 {
+			t.Errorf("%q: got %+v, want one event with count 3", q, events)
+		}
+	}
+	for _, q := range []string{"?raw=true", "?types=output&raw=true"} {
+		if events := get(q); len(events) != 3 {
+			t.Errorf("%q: got %d events, want 3", q, len(events))
+		}
+	}
+}
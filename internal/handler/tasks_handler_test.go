@@ -122,13 +122,16 @@ func TestListDeletedTasks_AfterSoftDelete(t *testing.T) {
 	}
 }
 
-// TestRestoreTask_NotFound verifies that PATCH {deleted:false} returns 500
+// TestRestoreTask_NotFound verifies that PATCH {deleted:false} returns 404
 // when the task ID does not correspond to a deleted task.
 func TestRestoreTask_NotFound(t *testing.T) {
 	h := newTestHandler(t)
 	w := patchTaskAction(t, h, uuid.New(), `{"deleted":false}`)
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("expected 500 for unknown task ID, got %d", w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown task ID, got %d", w.Code)
+	}
+	if code := decodeErrorCode(t, w); code != codeTaskNotFound {
+		t.Errorf("code = %q, want %q", code, codeTaskNotFound)
 	}
 }
 
@@ -54,7 +54,7 @@ func (h *Handler) TaskLineage(w http.ResponseWriter, r *http.Request, id uuid.UU
 	}
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestCreateTask_SummaryLocale(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.CreateTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks",
+		strings.NewReader(`{"prompt": "fix login", "timeout": 20, "summary_locale": " zh-CN "}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var task store.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &task); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if task.SummaryLocale != "zh-CN" {
+		t.Errorf("summary_locale = %q, want zh-CN", task.SummaryLocale)
+	}
+
+	w = httptest.NewRecorder()
+	h.CreateTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks",
+		strings.NewReader(`{"prompt": "fix login", "summary_locale": "Chinese"}`)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "summary_locale") {
+		t.Errorf("bad locale: expected 400 naming summary_locale, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateTask_SummaryLocaleRetranslates(t *testing.T) {
+	h := newTestHandler(t)
+	mock := &runner.MockRunner{}
+	h.runner = mock
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "fix login", Timeout: 15})
+
+	if w := patchTask(h, task.ID, `{"summary_locale":"ja"}`); w.Code != http.StatusOK {
+		t.Fatalf("set: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.SummaryLocale != "ja" {
+		t.Fatalf("summary_locale = %q, want ja", got.SummaryLocale)
+	}
+	if len(mock.LocalizeSummaryCalls) != 1 {
+		t.Errorf("translations queued = %d, want 1", len(mock.LocalizeSummaryCalls))
+	}
+
+	// Resending the same locale keeps the translation it already has.
+	if w := patchTask(h, task.ID, `{"summary_locale":"ja"}`); w.Code != http.StatusOK {
+		t.Fatalf("resend: expected 200, got %d", w.Code)
+	}
+	if len(mock.LocalizeSummaryCalls) != 1 {
+		t.Errorf("translations queued = %d after resending the locale, want 1", len(mock.LocalizeSummaryCalls))
+	}
+
+	if w := patchTask(h, task.ID, `{"summary_locale":"zh_CN"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bad locale: expected 400, got %d", w.Code)
+	}
+}
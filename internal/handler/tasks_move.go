@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// MoveTask reorders a task within its board column. Unlike PATCH position,
+// which writes whatever integer the client computed, the store resolves the
+// neighbours and the new position under its lock, so two concurrent drags
+// cannot produce duplicate positions.
+func (h *Handler) MoveTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	req, ok := httpjson.DecodeBody[struct {
+		AfterID  string           `json:"after_id"`
+		BeforeID string           `json:"before_id"`
+		Column   store.TaskStatus `json:"column"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+
+	var target store.MoveTarget
+	target.Column = req.Column
+	for _, ref := range []struct {
+		raw  string
+		name string
+		dst  **uuid.UUID
+	}{
+		{req.AfterID, "after_id", &target.AfterID},
+		{req.BeforeID, "before_id", &target.BeforeID},
+	} {
+		if strings.TrimSpace(ref.raw) == "" {
+			continue
+		}
+		anchor, err := uuid.Parse(ref.raw)
+		if err != nil {
+			http.Error(w, "invalid "+ref.name+": "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		*ref.dst = &anchor
+	}
+	if target.AfterID == nil && target.BeforeID == nil && target.Column == "" {
+		http.Error(w, "one of after_id, before_id or column is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.GetTask(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	moved, err := s.MoveTask(r.Context(), id, target)
+	switch {
+	case errors.Is(err, store.ErrMoveCrossColumn):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, moved)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func moveTaskReq(h *Handler, id uuid.UUID, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+id.String()+"/move", bytes.NewBufferString(body))
+	h.MoveTask(w, req, id)
+	return w
+}
+
+func TestMoveTask_Reorders(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	var ids []uuid.UUID
+	for _, p := range []string{"a", "b", "c"} {
+		task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: p, Timeout: 15})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, task.ID)
+	}
+	// New backlog tasks go to the top, so the board reads c b a.
+	w := moveTaskReq(h, ids[2], `{"after_id":"`+ids[0].String()+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	backlog, _ := h.store.ListTasksByStatus(ctx, store.TaskStatusBacklog)
+	var order string
+	for _, task := range backlog {
+		order += task.Prompt
+	}
+	if order != "bac" {
+		t.Errorf("backlog order = %q, want \"bac\"", order)
+	}
+}
+
+func TestMoveTask_Errors(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	a, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "a", Timeout: 15})
+	b, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "b", Timeout: 15})
+	if err := h.store.ForceUpdateTaskStatus(ctx, b.ID, store.TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		id   uuid.UUID
+		body string
+		code int
+	}{
+		{"empty body", a.ID, `{}`, http.StatusBadRequest},
+		{"bad uuid", a.ID, `{"after_id":"nope"}`, http.StatusBadRequest},
+		{"unknown task", uuid.New(), `{"column":"backlog"}`, http.StatusNotFound},
+		{"anchor in other column", a.ID, `{"before_id":"` + b.ID.String() + `"}`, http.StatusConflict},
+		{"wrong column", a.ID, `{"column":"in_progress"}`, http.StatusConflict},
+	}
+	for _, tc := range cases {
+		if w := moveTaskReq(h, tc.id, tc.body); w.Code != tc.code {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.code, w.Code, w.Body.String())
+		}
+	}
+}
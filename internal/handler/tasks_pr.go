@@ -71,7 +71,7 @@ func (h *Handler) taskForPR(w http.ResponseWriter, r *http.Request, id uuid.UUID
 	}
 	task, err := s.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+		writeError(w, err)
 		return nil, "", "", "", "", false
 	}
 	owner, name, base, head, ok := taskRepoRef(task)
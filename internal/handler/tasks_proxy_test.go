@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestCreateTask_Proxy(t *testing.T) {
+	h := newTestHandler(t)
+	body := `{"prompt": "fetch deps", "timeout": 20, "proxy": {"http_proxy": " http://127.0.0.1:7890 "}}`
+	w := httptest.NewRecorder()
+	h.CreateTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var task store.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &task); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if task.Proxy == nil || task.Proxy.HTTPProxy != "http://127.0.0.1:7890" {
+		t.Errorf("proxy = %+v", task.Proxy)
+	}
+
+	body = `{"prompt": "fetch deps", "proxy": {"http_proxy": "ftp://proxy:21"}}`
+	w = httptest.NewRecorder()
+	h.CreateTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "proxy") {
+		t.Errorf("bad proxy: expected 400 naming proxy, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateTask_Proxy(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "fetch deps", Timeout: 15})
+
+	if w := patchTask(h, task.ID, `{"proxy":{"direct":true}}`); w.Code != http.StatusOK {
+		t.Fatalf("set: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.Proxy == nil || !got.Proxy.Direct {
+		t.Fatalf("proxy = %+v, want direct", got.Proxy)
+	}
+
+	if w := patchTask(h, task.ID, `{"proxy":{"direct":true,"https_proxy":"http://proxy:3128"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("direct with url: expected 400, got %d", w.Code)
+	}
+
+	if w := patchTask(h, task.ID, `{"proxy":null}`); w.Code != http.StatusOK {
+		t.Fatalf("clear: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.Proxy != nil {
+		t.Errorf("proxy = %+v after clearing, want nil", got.Proxy)
+	}
+
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress)
+	if w := patchTask(h, task.ID, `{"proxy":{"http_proxy":"http://proxy:3128"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("running task: expected 400, got %d", w.Code)
+	}
+}
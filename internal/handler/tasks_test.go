@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -76,6 +78,57 @@ func TestListTasks_IncludesCreated(t *testing.T) {
 	}
 }
 
+// TestListTasks_Paginated verifies the ?status=/?limit=/?cursor= form: it
+// returns a TaskPage scoped to the requested columns and pages can be
+// followed through next_cursor.
+func TestListTasks_Paginated(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	for i := range 3 {
+		if _, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: fmt.Sprintf("task %d", i), Timeout: 15}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	done, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "done", Timeout: 15})
+	if err := h.store.ForceUpdateTaskStatus(ctx, done.ID, store.TaskStatusDone); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(query string) store.TaskPage {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ListTasks(w, httptest.NewRequest(http.MethodGet, "/api/tasks?"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+		}
+		var page store.TaskPage
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return page
+	}
+
+	first := get("status=backlog&limit=2")
+	if len(first.Tasks) != 2 || first.Total != 3 || first.NextCursor == "" {
+		t.Fatalf("first page = %d tasks, total %d, cursor %q", len(first.Tasks), first.Total, first.NextCursor)
+	}
+	second := get("status=backlog&limit=2&cursor=" + first.NextCursor)
+	if len(second.Tasks) != 1 || second.NextCursor != "" {
+		t.Errorf("second page = %d tasks, cursor %q", len(second.Tasks), second.NextCursor)
+	}
+	if page := get("status=done,backlog"); page.Total != 4 {
+		t.Errorf("multi-status total = %d, want 4", page.Total)
+	}
+
+	for _, bad := range []string{"status=nope", "limit=0", "limit=x", "cursor=!!"} {
+		w := httptest.NewRecorder()
+		h.ListTasks(w, httptest.NewRequest(http.MethodGet, "/api/tasks?"+bad, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", bad, w.Code)
+		}
+	}
+}
+
 func TestListTasks_InvalidFailureCategory(t *testing.T) {
 	h := newTestHandler(t)
 	req := httptest.NewRequest(http.MethodGet, "/api/tasks?failure_category=not-a-category", nil)
@@ -232,8 +285,8 @@ func TestCreateTask_RejectsEmptyPrompt(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.CreateTask(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", w.Code)
 	}
 }
 
@@ -429,8 +482,8 @@ func TestPatchTask_RejectsWaitingToInProgress(t *testing.T) {
 	rec := httptest.NewRecorder()
 	h.UpdateTask(rec, patch, task.ID)
 
-	if rec.Code != http.StatusBadRequest {
-		t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", rec.Code, rec.Body.String())
 	}
 	updated, err := h.store.GetTask(ctx, task.ID)
 	if err != nil {
@@ -492,6 +545,163 @@ func TestUpdateTask_UpdatesPosition(t *testing.T) {
 	}
 }
 
+// TestUpdateTask_MetadataWhileRunning verifies that title, tags, and links
+// can change while the task runs, and that execution fields are rejected
+// without applying anything else from the request.
+func TestUpdateTask_MetadataWhileRunning(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress)
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/tasks/"+task.ID.String(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.UpdateTask(w, req, task.ID)
+		return w
+	}
+
+	w := patch(`{"title": " Login fix ", "tags": ["priority:1", "auth"], "links": [{"type": "doc", "url": "https://example.com/spec"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var updated store.Task
+	_ = json.NewDecoder(w.Body).Decode(&updated)
+	if updated.Title != "Login fix" || len(updated.Tags) != 2 || len(updated.Links) != 1 {
+		t.Errorf("title %q tags %v links %v", updated.Title, updated.Tags, updated.Links)
+	}
+
+	w = patch(`{"title": "Other", "prompt": "new", "timeout": 30, "fresh_start": true}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp validationResponse
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Fields) != 3 || resp.Fields[0].Field != "prompt" {
+		t.Errorf("fields = %+v, want prompt, timeout, fresh_start", resp.Fields)
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.Title != "Login fix" || got.Prompt != "test" {
+		t.Errorf("rejected patch changed the task: title %q prompt %q", got.Title, got.Prompt)
+	}
+
+	for _, body := range []string{`{"title": "  "}`, `{"title": "` + strings.Repeat("x", store.MaxTaskTitleLength+1) + `"}`} {
+		if w := patch(body); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("bad title: expected 422, got %d", w.Code)
+		}
+	}
+}
+
+// TestUpdateTask_Sizing verifies that story points and size can be set on a
+// task in any status, and that out-of-range values are rejected.
+func TestUpdateTask_Sizing(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress)
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/tasks/"+task.ID.String(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.UpdateTask(w, req, task.ID)
+		return w
+	}
+
+	w := patch(`{"story_points": 5, "size": "L"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var updated store.Task
+	_ = json.NewDecoder(w.Body).Decode(&updated)
+	if updated.StoryPoints != 5 || updated.Size != store.TaskSizeL {
+		t.Errorf("points %v size %q, want 5 and l", updated.StoryPoints, updated.Size)
+	}
+
+	for _, body := range []string{`{"story_points": -1}`, `{"story_points": 1000}`, `{"size": "huge"}`} {
+		if w := patch(body); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected 422, got %d", body, w.Code)
+		}
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.StoryPoints != 5 || got.Size != store.TaskSizeL {
+		t.Errorf("rejected patch changed the task: points %v size %q", got.StoryPoints, got.Size)
+	}
+}
+
+// TestUpdateTask_Links verifies that links are normalized and replaced in
+// any status, cleared by an empty list, and rejected when malformed.
+func TestUpdateTask_Links(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/tasks/"+task.ID.String(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.UpdateTask(w, req, task.ID)
+		return w
+	}
+
+	w := patch(`{"links": [{"type": "Jira", "url": "https://example.atlassian.net/browse/APP-1", "title": "Flaky login"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var updated store.Task
+	_ = json.NewDecoder(w.Body).Decode(&updated)
+	if len(updated.Links) != 1 || updated.Links[0].Type != store.TaskLinkJira || updated.Links[0].Title != "Flaky login" {
+		t.Fatalf("links = %+v", updated.Links)
+	}
+
+	for _, body := range []string{`{"links": [{"type": "slack", "url": "https://example.com"}]}`, `{"links": [{"type": "doc", "url": "ftp://example.com/spec"}]}`} {
+		if w := patch(body); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected 422, got %d", body, w.Code)
+		}
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); len(got.Links) != 1 {
+		t.Errorf("rejected patch changed the links: %+v", got.Links)
+	}
+
+	if w := patch(`{"links": []}`); w.Code != http.StatusOK {
+		t.Fatalf("clear: expected 200, got %d", w.Code)
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.Links != nil {
+		t.Errorf("links = %+v, want none after clearing", got.Links)
+	}
+}
+
+// TestUpdateTask_ContextFiles verifies that context-pack paths are cleaned
+// and stored, and rejected unless they exist inside a configured workspace.
+func TestUpdateTask_ContextFiles(t *testing.T) {
+	repo := setupRepo(t)
+	h, _ := newTestHandlerWithWorkspacesFromRepo(t, repo)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+
+	patch := func(paths ...string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string][]string{"context_files": paths})
+		req := httptest.NewRequest(http.MethodPatch, "/api/tasks/"+task.ID.String(), bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		h.UpdateTask(w, req, task.ID)
+		return w
+	}
+
+	file := filepath.Join(repo, "file.txt")
+	if w := patch(file+" ", repo+"/./file.txt", repo); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); !slices.Equal(got.ContextFiles, []string{file, repo}) {
+		t.Fatalf("context files = %q", got.ContextFiles)
+	}
+
+	for _, paths := range [][]string{{"file.txt"}, {filepath.Join(repo, "missing.go")}, {t.TempDir()}} {
+		if w := patch(paths...); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%q: expected 422, got %d", paths, w.Code)
+		}
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); len(got.ContextFiles) != 2 {
+		t.Errorf("rejected patch changed the context files: %q", got.ContextFiles)
+	}
+}
+
 // TestUpdateTask_UpdatesBacklogFields verifies that prompt/timeout can be updated for backlog tasks.
 func TestUpdateTask_UpdatesBacklogFields(t *testing.T) {
 	h := newTestHandler(t)
@@ -586,7 +796,8 @@ func TestUpdateTask_RetryWithNewPrompt(t *testing.T) {
 	}
 }
 
-// TestDeleteTask_NotFound verifies that deleting a non-existent task returns 500.
+// TestDeleteTask_NotFound verifies that deleting a non-existent task returns
+// 404 with the task_not_found code.
 func TestDeleteTask_NotFound(t *testing.T) {
 	h := newTestHandler(t)
 	id := uuid.New()
@@ -594,8 +805,11 @@ func TestDeleteTask_NotFound(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.DeleteTask(w, req, id)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("expected 500 for non-existent task, got %d", w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for non-existent task, got %d", w.Code)
+	}
+	if code := decodeErrorCode(t, w); code != codeTaskNotFound {
+		t.Errorf("code = %q, want %q", code, codeTaskNotFound)
 	}
 }
 
@@ -1274,8 +1488,8 @@ func TestUpdateTask_SetDependsOn_SelfDependency(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.UpdateTask(w, req, a.ID)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for self-dependency, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for self-dependency, got %d", w.Code)
 	}
 }
 
@@ -1291,8 +1505,8 @@ func TestUpdateTask_SetDependsOn_UnknownUUID(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.UpdateTask(w, req, a.ID)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for unknown UUID, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for unknown UUID, got %d", w.Code)
 	}
 }
 
@@ -1307,8 +1521,8 @@ func TestUpdateTask_SetDependsOn_InvalidUUID(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.UpdateTask(w, req, a.ID)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for invalid UUID, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for invalid UUID, got %d", w.Code)
 	}
 }
 
@@ -1327,8 +1541,8 @@ func TestUpdateTask_SetDependsOn_DirectCycle(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.UpdateTask(w, req, b.ID)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for direct cycle, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for direct cycle, got %d", w.Code)
 	}
 }
 
@@ -1350,8 +1564,8 @@ func TestUpdateTask_SetDependsOn_TransitiveCycle(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.UpdateTask(w, req, c.ID)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for transitive cycle, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for transitive cycle, got %d", w.Code)
 	}
 }
 
@@ -1381,6 +1595,31 @@ func TestUpdateTask_SetDependsOn_AbsentFieldNoOp(t *testing.T) {
 	}
 }
 
+// TestUpdateTask_StartRefusedWithUnmetDependencies verifies that a manual
+// backlog → in_progress move is refused while a dependency is not done.
+func TestUpdateTask_StartRefusedWithUnmetDependencies(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	a, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "dep", Timeout: 15})
+	b, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "task", Timeout: 15, DependsOn: []string{a.ID.String()}})
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/tasks/"+b.ID.String(), strings.NewReader(`{"status": "in_progress"}`))
+	w := httptest.NewRecorder()
+	h.UpdateTask(w, req, b.ID)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp errorResponse
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Code != codeDependenciesUnmet || !strings.Contains(resp.Error, a.ID.String()) {
+		t.Errorf("response = %+v, want %s naming %s", resp, codeDependenciesUnmet, a.ID)
+	}
+	if got, _ := h.store.GetTask(ctx, b.ID); got.Status != store.TaskStatusBacklog {
+		t.Errorf("status = %s, want backlog", got.Status)
+	}
+}
+
 // --- Auto-promoter dependency tests ---
 
 // TestTryAutoPromote_SkipsBlockedTask verifies that the auto-promoter skips the
@@ -4323,8 +4562,9 @@ func TestUpdateTask_RetryFromWaiting(t *testing.T) {
 	}
 }
 
-// TestUpdateTask_InvalidStatusTransition verifies that an invalid status transition
-// returns 400.
+// TestUpdateTask_InvalidStatusTransition verifies that an invalid status
+// transition returns 409 with the invalid_transition code, as on every
+// other endpoint.
 func TestUpdateTask_InvalidStatusTransition(t *testing.T) {
 	h := newTestHandler(t)
 	ctx := context.Background()
@@ -4335,8 +4575,15 @@ func TestUpdateTask_InvalidStatusTransition(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.UpdateTask(w, req, task.ID)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for invalid transition backlog->done, got %d: %s", w.Code, w.Body.String())
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for invalid transition backlog->done, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp validationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != codeInvalidTransition || len(resp.Fields) != 1 || resp.Fields[0].Field != "status" {
+		t.Errorf("body = %+v, want an invalid_transition error on status", resp)
 	}
 }
 
@@ -4376,8 +4623,8 @@ func TestUpdateTask_InvalidCustomPassPattern(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.UpdateTask(w, req, task.ID)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for invalid regex, got %d: %s", w.Code, w.Body.String())
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for invalid regex, got %d: %s", w.Code, w.Body.String())
 	}
 }
 
@@ -4394,8 +4641,8 @@ func TestUpdateTask_InvalidCustomFailPattern(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.UpdateTask(w, req, task.ID)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for invalid fail regex, got %d: %s", w.Code, w.Body.String())
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for invalid fail regex, got %d: %s", w.Code, w.Body.String())
 	}
 }
 
@@ -4548,8 +4795,8 @@ func TestCreateTask_EmptyPromptRejected(t *testing.T) {
 	w := httptest.NewRecorder()
 	h.CreateTask(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Fatalf("expected 400 for empty prompt, got %d: %s", w.Code, w.Body.String())
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for empty prompt, got %d: %s", w.Code, w.Body.String())
 	}
 }
 
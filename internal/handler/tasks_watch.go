@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"cmp"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/webpush"
+)
+
+// WatchTask adds the caller to the task's watchers, so they are notified of
+// its state changes like its creator. Watching requires a signed-in
+// principal; the anonymous local user already receives every notification.
+func (h *Handler) WatchTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	sub := pushUser(r)
+	if sub == "" {
+		http.Error(w, "watching a task requires sign-in", http.StatusBadRequest)
+		return
+	}
+	if err := s.WatchTask(r.Context(), id, sub); err != nil {
+		writeError(w, err)
+		return
+	}
+	h.writeTask(w, r, s, id)
+}
+
+// UnwatchTask removes the caller from the task's watchers.
+func (h *Handler) UnwatchTask(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	if err := s.UnwatchTask(r.Context(), id, pushUser(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	h.writeTask(w, r, s, id)
+}
+
+// CreateTaskComment records a comment on the task's timeline as a comment
+// event attributed to the caller. Everyone @mentioned in it other than the
+// author becomes a watcher and receives a "mention" notification.
+func (h *Handler) CreateTaskComment(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	req, ok := httpjson.DecodeBody[struct {
+		Body string `json:"body"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		writeFieldError(w, "body", "must not be empty")
+		return
+	}
+	if n := utf8.RuneCountInString(body); n > store.MaxCommentLength {
+		writeFieldError(w, "body", "must be at most %d characters (got %d)", store.MaxCommentLength, n)
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	task, err := s.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	comment := store.CommentData{Body: body, Mentions: store.ParseMentions(body)}
+	if err := s.InsertEvent(stampEventActor(r.Context()), id, store.EventTypeComment, comment); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	author := pushUser(r)
+	var mentioned []string
+	for _, sub := range comment.Mentions {
+		if sub != author {
+			mentioned = append(mentioned, sub)
+		}
+	}
+	if len(mentioned) > 0 {
+		if err := s.WatchTask(r.Context(), id, mentioned...); err != nil {
+			logger.Handler.Warn("comment: add mentioned watchers", "task", id, "error", err)
+		}
+		board, now := h.currentBoardKey(), time.Now()
+		msg := mentionMessage(task, body)
+		for _, sub := range mentioned {
+			h.deliverPush(r.Context(), pushDelivery{user: sub, tags: task.Tags, msg: msg}, board, now)
+		}
+	}
+	httpjson.Write(w, http.StatusCreated, comment)
+}
+
+// mentionMessage is the notification sent to a user @mentioned in a comment
+// on t.
+func mentionMessage(t *store.Task, body string) webpush.Message {
+	return webpush.Message{
+		Title: "Mentioned on " + cmp.Or(t.Title, truncateRunes(t.Prompt, 60)),
+		Body:  truncateRunes(body, 120),
+		Tag:   "task-" + t.ID.String(),
+		URL:   "/?task=" + t.ID.String(),
+		Kind:  "mention",
+	}
+}
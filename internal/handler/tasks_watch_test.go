@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/auth"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// asUser returns req carrying a signed-in principal with the given subject.
+func asUser(req *http.Request, sub string) *http.Request {
+	return req.WithContext(auth.WithIdentity(req.Context(), &auth.Identity{Sub: sub}))
+}
+
+func watchersOf(t *testing.T, h *Handler, id uuid.UUID) []string {
+	t.Helper()
+	task, err := h.store.GetTask(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task.Watchers
+}
+
+func TestWatchTask(t *testing.T) {
+	h := newTestHandler(t)
+	task, _ := h.store.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "p", Timeout: 15, CreatedBy: "alice"})
+	path := "/api/tasks/" + task.ID.String() + "/watch"
+
+	w := httptest.NewRecorder()
+	h.WatchTask(w, asUser(httptest.NewRequest(http.MethodPut, path, nil), "bob"), task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("watch: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got store.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Watchers, []string{"bob"}) {
+		t.Errorf("watchers = %v, want [bob]", got.Watchers)
+	}
+
+	w = httptest.NewRecorder()
+	h.WatchTask(w, httptest.NewRequest(http.MethodPut, path, nil), task.ID)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("anonymous watch: expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.UnwatchTask(w, asUser(httptest.NewRequest(http.MethodDelete, path, nil), "bob"), task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unwatch: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ws := watchersOf(t, h, task.ID); len(ws) != 0 {
+		t.Errorf("watchers after unwatch = %v", ws)
+	}
+
+	missing := uuid.New()
+	w = httptest.NewRecorder()
+	h.WatchTask(w, asUser(httptest.NewRequest(http.MethodPut, "/api/tasks/"+missing.String()+"/watch", nil), "bob"), missing)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown task: expected 404, got %d", w.Code)
+	}
+}
+
+func TestCreateTaskComment_Mentions(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 15, CreatedBy: "alice"})
+
+	post := func(sub, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/comments", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.CreateTaskComment(w, asUser(req, sub), task.ID)
+		return w
+	}
+
+	w := post("alice", `{"body": "@bob can you check the migration? cc @carol and me @alice"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var comment store.CommentData
+	if err := json.Unmarshal(w.Body.Bytes(), &comment); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(comment.Mentions, []string{"bob", "carol", "alice"}) {
+		t.Errorf("mentions = %v", comment.Mentions)
+	}
+	// The author mentioning themself does not make them a watcher.
+	if ws := watchersOf(t, h, task.ID); !slices.Equal(ws, []string{"bob", "carol"}) {
+		t.Errorf("watchers = %v, want [bob carol]", ws)
+	}
+
+	events, _ := h.store.GetEvents(ctx, task.ID)
+	var found bool
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeComment {
+			found = true
+			if ev.ActorSub != "alice" {
+				t.Errorf("comment author = %q, want alice", ev.ActorSub)
+			}
+		}
+	}
+	if !found {
+		t.Error("no comment event recorded")
+	}
+
+	if w := post("alice", `{"body": "   "}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("blank comment: expected 422, got %d", w.Code)
+	}
+}
+
+func TestPushTransitions_NotifiesWatchers(t *testing.T) {
+	id := uuid.New()
+	seen := map[uuid.UUID]store.TaskStatus{id: store.TaskStatusInProgress}
+	tasks := []store.Task{{ID: id, Status: store.TaskStatusDone, CreatedBy: "alice", Watchers: []string{"bob", "alice"}}}
+
+	got := pushTransitions(tasks, seen)
+	var users []string
+	for _, d := range got {
+		users = append(users, d.user)
+	}
+	if !slices.Equal(users, []string{"alice", "bob"}) {
+		t.Errorf("notified %v, want [alice bob]", users)
+	}
+}
+
+func TestMentionMessage(t *testing.T) {
+	task := &store.Task{ID: uuid.New(), Title: "Fix login"}
+	msg := mentionMessage(task, "@bob please review")
+	if msg.Kind != "mention" || msg.Title != "Mentioned on Fix login" || msg.URL != "/?task="+task.ID.String() {
+		t.Errorf("message = %+v", msg)
+	}
+}
@@ -238,8 +238,10 @@ func (h *Handler) HandleTerminalWS(w http.ResponseWriter, r *http.Request) {
 	cwd := r.URL.Query().Get("cwd")
 	cwd = h.resolveTerminalCwd(r.Context(), cwd)
 
+	// Same origin check as /api/ws: a same-site page on another port
+	// would otherwise get a shell with the board's session cookie.
 	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
-		InsecureSkipVerify: true, // Origin check not needed; same-host dev tool.
+		OriginPatterns: h.wsOrigins,
 	})
 	if err != nil {
 		logger.Handler.Error("terminal: websocket accept failed", "error", err)
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/watcher"
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/tracker"
+)
+
+// trackerSync holds the provider built from the last configuration seen,
+// so per-provider caches (such as Linear's team states) survive between
+// passes and are dropped when the settings change.
+type trackerSync struct {
+	cfg      tracker.Config
+	provider tracker.Provider
+}
+
+// StartTrackerSync mirrors the current board's tasks into the Jira or
+// Linear project configured in the env file, every
+// constants.TrackerSyncInterval. The settings are re-read on every pass,
+// so mirroring can be turned on or repointed without a restart.
+func (h *Handler) StartTrackerSync(ctx context.Context) {
+	ts := &trackerSync{}
+	watcher.Start(ctx, watcher.Config{
+		Interval: constants.TrackerSyncInterval,
+		Action: func(ctx context.Context) {
+			h.syncTracker(ctx, ts)
+		},
+	})
+}
+
+// trackerConfig reads the tracker settings and WALLFACER_PUBLIC_URL from
+// the env file; a missing or unreadable file leaves mirroring disabled.
+func (h *Handler) trackerConfig() (tracker.Config, string) {
+	if h.envFile == "" {
+		return tracker.Config{}, ""
+	}
+	cfg, err := envconfig.Parse(h.envFile)
+	if err != nil {
+		return tracker.Config{}, ""
+	}
+	return tracker.Config{
+		JiraURL:       cfg.JiraURL,
+		JiraProject:   cfg.JiraProject,
+		JiraEmail:     cfg.JiraEmail,
+		JiraToken:     cfg.JiraToken,
+		JiraIssueType: cfg.JiraIssueType,
+		LinearAPIKey:  cfg.LinearAPIKey,
+		LinearTeam:    cfg.LinearTeam,
+	}, cfg.PublicURL
+}
+
+// syncTracker runs one mirroring pass. A task gets an issue the first
+// time it is seen while still open; from then on its issue is updated
+// whenever the title, prompt, or status changes, and it is closed once
+// the task is deleted. A task that fails to mirror is logged and retried
+// on the next pass, so it does not hold up the others.
+func (h *Handler) syncTracker(ctx context.Context, ts *trackerSync) {
+	tcfg, publicURL := h.trackerConfig()
+	if ts.provider == nil || tcfg != ts.cfg {
+		p, err := tracker.FromConfig(tcfg)
+		ts.cfg, ts.provider = tcfg, p
+		if err != nil {
+			return // tracker.ErrNotConfigured
+		}
+	}
+	s, ok := h.currentStore()
+	if !ok || s == nil {
+		return
+	}
+	tasks, err := s.ListTasks(ctx, false)
+	if err != nil {
+		logger.Handler.Warn("tracker sync: list tasks", "error", err)
+		return
+	}
+	baseURL := cmp.Or(publicURL, strings.TrimRight(h.TunnelURL(), "/"))
+	for i := range tasks {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := h.mirrorTask(ctx, s, ts.provider, &tasks[i], baseURL); err != nil {
+			logger.Handler.Warn("tracker sync: mirror task", "task", tasks[i].ID, "tracker", ts.provider.Name(), "error", err)
+		}
+	}
+	deleted, err := s.ListDeletedTasks(ctx)
+	if err != nil {
+		logger.Handler.Warn("tracker sync: list deleted tasks", "error", err)
+		return
+	}
+	for i := range deleted {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := h.closeTrackerIssue(ctx, s, ts.provider, &deleted[i]); err != nil {
+			logger.Handler.Warn("tracker sync: close issue", "task", deleted[i].ID, "tracker", ts.provider.Name(), "error", err)
+		}
+	}
+}
+
+// mirrorTask creates or updates t's issue. Routine cards, which only
+// spawn other tasks, are not mirrored, nor are tasks that finished before
+// they were first seen.
+func (h *Handler) mirrorTask(ctx context.Context, s *store.Store, p tracker.Provider, t *store.Task, baseURL string) error {
+	if t.IsRoutine() {
+		return nil
+	}
+	issue := trackerIssue(t, trackerState(t.Status))
+	synced := trackerFingerprint(issue)
+	if ref := t.TrackerIssue; ref != nil && ref.Provider == p.Name() {
+		return updateTrackerIssue(ctx, s, p, t.ID, *ref, issue)
+	}
+	if issue.State == tracker.StateDone || issue.State == tracker.StateCanceled {
+		return nil
+	}
+	if baseURL != "" {
+		issue.TaskURL = baseURL + "/?task=" + t.ID.String()
+	}
+	created, err := p.CreateIssue(ctx, issue)
+	if created.ID == "" {
+		return err
+	}
+	// An issue that was created but not fully linked or moved is still
+	// recorded, so it is not duplicated; an empty fingerprint makes the
+	// next pass update it again.
+	if err != nil {
+		synced = ""
+	}
+	if recErr := s.SetTaskTrackerIssue(ctx, t.ID, store.TrackerIssue{
+		Provider: p.Name(), ID: created.ID, Key: created.Key, URL: created.URL, Synced: synced,
+	}); recErr != nil {
+		return recErr
+	}
+	h.insertEventOrLogTo(ctx, s, t.ID, store.EventTypeSystem, map[string]string{
+		"result": fmt.Sprintf("Mirrored to %s as %s.", p.Name(), created.Key),
+	})
+	return err
+}
+
+// closeTrackerIssue moves a deleted task's issue to canceled, or leaves
+// it done if the task had finished. Issues from another tracker are left
+// alone.
+func (h *Handler) closeTrackerIssue(ctx context.Context, s *store.Store, p tracker.Provider, t *store.Task) error {
+	ref := t.TrackerIssue
+	if ref == nil || ref.Provider != p.Name() {
+		return nil
+	}
+	state := tracker.StateCanceled
+	if t.Status == store.TaskStatusDone {
+		state = tracker.StateDone
+	}
+	return updateTrackerIssue(ctx, s, p, t.ID, *ref, trackerIssue(t, state))
+}
+
+// updateTrackerIssue pushes issue to the existing issue ref, unless it
+// was already pushed, and records the new fingerprint.
+func updateTrackerIssue(ctx context.Context, s *store.Store, p tracker.Provider, id uuid.UUID, ref store.TrackerIssue, issue tracker.Issue) error {
+	synced := trackerFingerprint(issue)
+	if ref.Synced == synced {
+		return nil
+	}
+	if err := p.UpdateIssue(ctx, tracker.Ref{ID: ref.ID, Key: ref.Key, URL: ref.URL}, issue); err != nil {
+		return err
+	}
+	ref.Synced = synced
+	return s.SetTaskTrackerIssue(ctx, id, ref)
+}
+
+// trackerIssue is the issue content t is mirrored as, in the given state.
+func trackerIssue(t *store.Task, state tracker.State) tracker.Issue {
+	return tracker.Issue{
+		Title:       cmp.Or(t.Title, truncateRunes(t.Prompt, 120)),
+		Description: t.Prompt,
+		State:       state,
+	}
+}
+
+// trackerState maps a task status to the issue status it is shown as.
+// Waiting, review, and failed tasks still need work, so their issues
+// stay in progress.
+func trackerState(status store.TaskStatus) tracker.State {
+	switch status {
+	case store.TaskStatusBacklog:
+		return tracker.StateBacklog
+	case store.TaskStatusDone:
+		return tracker.StateDone
+	case store.TaskStatusCancelled:
+		return tracker.StateCanceled
+	default:
+		return tracker.StateInProgress
+	}
+}
+
+// trackerFingerprint identifies the content pushed to an issue, so an
+// unchanged task is not sent again.
+func trackerFingerprint(issue tracker.Issue) string {
+	sum := sha256.Sum256([]byte(issue.Title + "\x00" + issue.Description + "\x00" + string(issue.State)))
+	return hex.EncodeToString(sum[:8])
+}
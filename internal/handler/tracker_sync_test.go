@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/tracker"
+)
+
+// fakeTracker records the issues it is asked to create and update, and
+// refuses to create those whose title is in reject.
+type fakeTracker struct {
+	created []tracker.Issue
+	updated []tracker.Issue
+	reject  map[string]bool
+}
+
+func (f *fakeTracker) Name() string { return "Jira" }
+
+func (f *fakeTracker) CreateIssue(_ context.Context, issue tracker.Issue) (tracker.Ref, error) {
+	if f.reject[issue.Title] {
+		return tracker.Ref{}, errors.New("400 Bad Request")
+	}
+	f.created = append(f.created, issue)
+	return tracker.Ref{ID: "10001", Key: "ENG-7", URL: "https://acme.atlassian.net/browse/ENG-7"}, nil
+}
+
+func (f *fakeTracker) UpdateIssue(_ context.Context, ref tracker.Ref, issue tracker.Issue) error {
+	f.updated = append(f.updated, issue)
+	return nil
+}
+
+func TestMirrorTask(t *testing.T) {
+	h := newStaticWorkspaceHandler(t, []string{t.TempDir()})
+	ctx := context.Background()
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "Fix the login redirect", Timeout: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTracker{}
+	mirror := func() {
+		t.Helper()
+		got, err := h.store.GetTask(ctx, task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.mirrorTask(ctx, h.store, fake, got, "https://wf.example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mirror()
+	if len(fake.created) != 1 {
+		t.Fatalf("created %d issues, want 1", len(fake.created))
+	}
+	if c := fake.created[0]; c.State != tracker.StateBacklog || c.Title != "Fix the login redirect" || c.TaskURL != "https://wf.example.com/?task="+task.ID.String() {
+		t.Errorf("created issue = %+v", c)
+	}
+	got, _ := h.store.GetTask(ctx, task.ID)
+	if got.TrackerIssue == nil || got.TrackerIssue.Key != "ENG-7" || got.TrackerIssue.Provider != "Jira" {
+		t.Fatalf("TrackerIssue = %+v", got.TrackerIssue)
+	}
+
+	// An unchanged task is not sent again; a status change is.
+	mirror()
+	if len(fake.created) != 1 || len(fake.updated) != 0 {
+		t.Fatalf("unchanged task: created %d, updated %d", len(fake.created), len(fake.updated))
+	}
+	if err := h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusDone); err != nil {
+		t.Fatal(err)
+	}
+	mirror()
+	if len(fake.updated) != 1 || fake.updated[0].State != tracker.StateDone {
+		t.Errorf("updates = %+v, want one to done", fake.updated)
+	}
+}
+
+func TestMirrorTask_SkipsFinishedTasks(t *testing.T) {
+	h := newStaticWorkspaceHandler(t, []string{t.TempDir()})
+	ctx := context.Background()
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	task, _ = h.store.GetTask(ctx, task.ID)
+	fake := &fakeTracker{}
+	if err := h.mirrorTask(ctx, h.store, fake, task, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.created) != 0 {
+		t.Errorf("created %d issues for a cancelled task", len(fake.created))
+	}
+}
+
+func TestSyncTracker_ContinuesPastFailedTask(t *testing.T) {
+	h := newStaticWorkspaceHandler(t, []string{t.TempDir()})
+	ctx := context.Background()
+	for _, prompt := range []string{"rejected", "accepted"} {
+		if _, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: prompt, Timeout: 15}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fake := &fakeTracker{reject: map[string]bool{"rejected": true}}
+	h.syncTracker(ctx, &trackerSync{provider: fake})
+	if len(fake.created) != 1 || fake.created[0].Title != "accepted" {
+		t.Errorf("created = %+v, want only the accepted task", fake.created)
+	}
+}
+
+func TestSyncTracker_ClosesDeletedTasks(t *testing.T) {
+	h := newStaticWorkspaceHandler(t, []string{t.TempDir()})
+	ctx := context.Background()
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTracker{}
+	ts := &trackerSync{provider: fake}
+	h.syncTracker(ctx, ts)
+	if len(fake.created) != 1 {
+		t.Fatalf("created %d issues, want 1", len(fake.created))
+	}
+	if err := h.store.DeleteTask(ctx, task.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	h.syncTracker(ctx, ts)
+	if len(fake.updated) != 1 || fake.updated[0].State != tracker.StateCanceled {
+		t.Fatalf("updates = %+v, want one to canceled", fake.updated)
+	}
+	// The close is recorded, so the next pass does not send it again.
+	h.syncTracker(ctx, ts)
+	if len(fake.updated) != 1 {
+		t.Errorf("closed issue was updated %d times, want 1", len(fake.updated))
+	}
+}
+
+func TestTrackerState(t *testing.T) {
+	for status, want := range map[store.TaskStatus]tracker.State{
+		store.TaskStatusBacklog:    tracker.StateBacklog,
+		store.TaskStatusInProgress: tracker.StateInProgress,
+		store.TaskStatusWaiting:    tracker.StateInProgress,
+		store.TaskStatusReview:     tracker.StateInProgress,
+		store.TaskStatusFailed:     tracker.StateInProgress,
+		store.TaskStatusDone:       tracker.StateDone,
+		store.TaskStatusCancelled:  tracker.StateCanceled,
+	} {
+		if got := trackerState(status); got != want {
+			t.Errorf("trackerState(%s) = %s, want %s", status, got, want)
+		}
+	}
+}
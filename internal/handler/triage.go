@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// defaultSummaryLimit caps the attention list of GET /api/summary unless the
+// caller asks for more with ?limit=.
+const defaultSummaryLimit = 20
+
+// attentionItem is one task in the summary's needs-attention list: just
+// enough to decide on a quick action without loading the full task.
+type attentionItem struct {
+	ID              uuid.UUID             `json:"id"`
+	Title           string                `json:"title"`
+	Status          store.TaskStatus      `json:"status"`
+	Reason          string                `json:"reason"`
+	FailureCategory store.FailureCategory `json:"failure_category,omitempty"`
+	Result          string                `json:"result,omitempty"`
+	CostUSD         float64               `json:"cost_usd"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+	StalledAt       *time.Time            `json:"stalled_at,omitempty"`
+}
+
+// boardSummary is the GET /api/summary response.
+type boardSummary struct {
+	Counts         map[store.TaskStatus]int `json:"counts"`
+	NeedsAttention []attentionItem          `json:"needs_attention"`
+	// AttentionTotal counts every task needing attention; NeedsAttention
+	// holds at most the requested limit of them.
+	AttentionTotal int `json:"attention_total"`
+	// Stalled counts the running tasks the stall watchdog has flagged.
+	Stalled int `json:"stalled"`
+}
+
+// attentionReason reports why a task needs a human, or "" when it does not.
+// Waiting tasks need feedback or review; tasks in review need their merge
+// approved or rejected; failed tasks need a retry or cancel; running tasks
+// the stall watchdog flagged may need a cancel.
+func attentionReason(t *store.Task) string {
+	switch t.Status {
+	case store.TaskStatusInProgress:
+		if t.StalledAt != nil {
+			return "stalled"
+		}
+	case store.TaskStatusWaiting:
+		if t.FailureCategory == store.FailureCategoryBudget {
+			return "budget_exceeded"
+		}
+		return "awaiting_feedback"
+	case store.TaskStatusReview:
+		return "awaiting_approval"
+	case store.TaskStatusFailed:
+		return "failed"
+	}
+	return ""
+}
+
+// summarizeBoard counts the non-archived tasks by status and lists those
+// needing attention, stalled tasks first and then most recently updated
+// first, up to limit. Routine cards are schedule templates and are left out
+// of both.
+func summarizeBoard(tasks []store.Task, limit int) boardSummary {
+	sum := boardSummary{Counts: make(map[store.TaskStatus]int), NeedsAttention: []attentionItem{}}
+	for i := range tasks {
+		t := &tasks[i]
+		if t.Archived || t.IsRoutine() {
+			continue
+		}
+		sum.Counts[t.Status]++
+		reason := attentionReason(t)
+		if reason == "" {
+			continue
+		}
+		item := attentionItem{
+			ID:              t.ID,
+			Title:           t.Title,
+			Status:          t.Status,
+			Reason:          reason,
+			FailureCategory: t.FailureCategory,
+			CostUSD:         t.Usage.CostUSD,
+			UpdatedAt:       t.UpdatedAt,
+			StalledAt:       t.StalledAt,
+		}
+		if reason == "stalled" {
+			sum.Stalled++
+		}
+		if item.Title == "" {
+			item.Title = truncateRunes(t.Prompt, 80)
+		}
+		if t.Result != nil {
+			item.Result = truncateRunes(*t.Result, 280)
+		}
+		sum.NeedsAttention = append(sum.NeedsAttention, item)
+	}
+	slices.SortStableFunc(sum.NeedsAttention, func(a, b attentionItem) int {
+		if as, bs := a.Reason == "stalled", b.Reason == "stalled"; as != bs {
+			if as {
+				return -1
+			}
+			return 1
+		}
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	sum.AttentionTotal = len(sum.NeedsAttention)
+	if len(sum.NeedsAttention) > limit {
+		sum.NeedsAttention = sum.NeedsAttention[:limit]
+	}
+	return sum
+}
+
+// GetSummary returns a compact board overview for mobile clients and
+// shortcut automations: task counts per status and the tasks waiting on a
+// human, stalled tasks first and then newest first. ?limit= bounds the attention list (default 20).
+func (h *Handler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+	limit := defaultSummaryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	tasks, err := s.ListTasks(r.Context(), false)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, summarizeBoard(tasks, limit))
+}
+
+// quickFeedbackMessages maps the canned "continue"-style responses of
+// QuickFeedback to the feedback message the agent receives.
+var quickFeedbackMessages = map[string]string{
+	"continue": "Looks good, continue.",
+}
+
+// quickFeedbackStop is the canned response that cancels the task.
+const quickFeedbackStop = "stop"
+
+// QuickFeedback applies a canned triage response to a task, so it can be
+// answered with one tap from a phone. "continue" resumes a waiting task with
+// a fixed feedback message; "stop" cancels the task.
+func (h *Handler) QuickFeedback(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	req, ok := httpjson.DecodeBody[struct {
+		Response string `json:"response"`
+	}](w, r)
+	if !ok {
+		return
+	}
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+
+	if req.Response == quickFeedbackStop {
+		task, err := s.GetTask(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if !cancellableStatuses[task.Status] {
+			http.Error(w, "a "+string(task.Status)+" task cannot be stopped", http.StatusBadRequest)
+			return
+		}
+		if err := h.applyCancel(r.Context(), *task); err != nil {
+			writeError(w, err)
+			return
+		}
+		httpjson.Write(w, http.StatusOK, map[string]string{"status": "cancelled"})
+		return
+	}
+
+	message, ok := quickFeedbackMessages[req.Response]
+	if !ok {
+		writeFieldError(w, "response", "must be continue or stop (got %q)", req.Response)
+		return
+	}
+	if err := h.submitFeedback(r.Context(), s, id, message); err != nil {
+		writeError(w, err)
+		return
+	}
+	httpjson.Write(w, http.StatusOK, map[string]string{"status": "resumed"})
+}
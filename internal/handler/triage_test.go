@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestSummarizeBoard(t *testing.T) {
+	now := time.Now()
+	result := "stuck on a flaky test"
+	tasks := []store.Task{
+		{ID: uuid.New(), Title: "older waiting", Status: store.TaskStatusWaiting, UpdatedAt: now.Add(-time.Hour)},
+		{ID: uuid.New(), Prompt: "a failed task without a title", Status: store.TaskStatusFailed, Result: &result, UpdatedAt: now},
+		{ID: uuid.New(), Title: "over budget", Status: store.TaskStatusWaiting, FailureCategory: store.FailureCategoryBudget, UpdatedAt: now.Add(-2 * time.Hour)},
+		{ID: uuid.New(), Title: "running", Status: store.TaskStatusInProgress, UpdatedAt: now},
+		{ID: uuid.New(), Title: "archived failure", Status: store.TaskStatusFailed, Archived: true, UpdatedAt: now},
+		{ID: uuid.New(), Title: "routine", Status: store.TaskStatusBacklog, Kind: store.TaskKindRoutine, UpdatedAt: now},
+	}
+
+	sum := summarizeBoard(tasks, 2)
+	if sum.Counts[store.TaskStatusWaiting] != 2 || sum.Counts[store.TaskStatusFailed] != 1 || sum.Counts[store.TaskStatusInProgress] != 1 {
+		t.Errorf("counts = %v", sum.Counts)
+	}
+	if sum.Counts[store.TaskStatusBacklog] != 0 {
+		t.Errorf("routine card counted: %v", sum.Counts)
+	}
+	if sum.AttentionTotal != 3 {
+		t.Errorf("attention_total = %d, want 3", sum.AttentionTotal)
+	}
+	if len(sum.NeedsAttention) != 2 {
+		t.Fatalf("needs_attention has %d items, want the limit of 2", len(sum.NeedsAttention))
+	}
+	first, second := sum.NeedsAttention[0], sum.NeedsAttention[1]
+	if first.Reason != "failed" || first.Title != "a failed task without a title" || first.Result != result {
+		t.Errorf("first item = %+v, want the newest (failed) task with its prompt as title", first)
+	}
+	if second.Title != "older waiting" || second.Reason != "awaiting_feedback" {
+		t.Errorf("second item = %+v, want the older waiting task", second)
+	}
+	if got := summarizeBoard(tasks, 10).NeedsAttention[2].Reason; got != "budget_exceeded" {
+		t.Errorf("budget-paused task reason = %q, want budget_exceeded", got)
+	}
+}
+
+func TestSummarizeBoard_StalledFirst(t *testing.T) {
+	now := time.Now()
+	stalledAt := now.Add(-time.Hour)
+	tasks := []store.Task{
+		{ID: uuid.New(), Title: "failed", Status: store.TaskStatusFailed, UpdatedAt: now},
+		{ID: uuid.New(), Title: "hung", Status: store.TaskStatusInProgress, StalledAt: &stalledAt, UpdatedAt: now.Add(-2 * time.Hour)},
+		{ID: uuid.New(), Title: "running", Status: store.TaskStatusInProgress, UpdatedAt: now},
+	}
+
+	sum := summarizeBoard(tasks, 10)
+	if sum.Stalled != 1 || sum.AttentionTotal != 2 {
+		t.Fatalf("stalled = %d, attention_total = %d; want 1 and 2", sum.Stalled, sum.AttentionTotal)
+	}
+	first := sum.NeedsAttention[0]
+	if first.Title != "hung" || first.Reason != "stalled" || first.StalledAt == nil {
+		t.Errorf("first item = %+v, want the stalled task ahead of newer ones", first)
+	}
+}
+
+func TestGetSummary(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	waiting, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "needs review", Timeout: 15})
+	_ = h.store.ForceUpdateTaskStatus(ctx, waiting.ID, store.TaskStatusWaiting)
+	_, _ = h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "queued", Timeout: 15})
+
+	w := httptest.NewRecorder()
+	h.GetSummary(w, httptest.NewRequest(http.MethodGet, "/api/summary", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var sum boardSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &sum); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if sum.Counts[store.TaskStatusWaiting] != 1 || sum.Counts[store.TaskStatusBacklog] != 1 {
+		t.Errorf("counts = %v", sum.Counts)
+	}
+	if len(sum.NeedsAttention) != 1 || sum.NeedsAttention[0].ID != waiting.ID {
+		t.Errorf("needs_attention = %+v, want the waiting task", sum.NeedsAttention)
+	}
+}
+
+func TestGetSummary_RejectsBadLimit(t *testing.T) {
+	h := newTestHandler(t)
+	w := httptest.NewRecorder()
+	h.GetSummary(w, httptest.NewRequest(http.MethodGet, "/api/summary?limit=0", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestQuickFeedback_Continue(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+
+	w := httptest.NewRecorder()
+	h.QuickFeedback(w, httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/quick-feedback",
+		strings.NewReader(`{"response": "continue"}`)), task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	events, _ := h.store.GetEvents(ctx, task.ID)
+	found := false
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeFeedback && strings.Contains(string(ev.Data), quickFeedbackMessages["continue"]) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a feedback event carrying the canned message")
+	}
+}
+
+func TestQuickFeedback_Stop(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+
+	w := httptest.NewRecorder()
+	h.QuickFeedback(w, httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/quick-feedback",
+		strings.NewReader(`{"response": "stop"}`)), task.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.Status != store.TaskStatusCancelled {
+		t.Errorf("status = %s, want cancelled", got.Status)
+	}
+}
+
+func TestQuickFeedback_RejectsUnknownResponse(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+	_ = h.store.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting)
+
+	w := httptest.NewRecorder()
+	h.QuickFeedback(w, httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/quick-feedback",
+		strings.NewReader(`{"response": "maybe"}`)), task.ID)
+	if fields := decodeValidation(t, w); len(fields) != 1 || fields[0] != "response" {
+		t.Errorf("fields = %v, want [response]", fields)
+	}
+	if got, _ := h.store.GetTask(ctx, task.ID); got.Status != store.TaskStatusWaiting {
+		t.Errorf("status = %s, want waiting", got.Status)
+	}
+}
+
+func TestQuickFeedback_ContinueRejectsNonWaiting(t *testing.T) {
+	h := newTestHandler(t)
+	task, _ := h.store.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "test", Timeout: 15})
+
+	w := httptest.NewRecorder()
+	h.QuickFeedback(w, httptest.NewRequest(http.MethodPost, "/api/tasks/"+task.ID.String()+"/quick-feedback",
+		strings.NewReader(`{"response": "continue"}`)), task.ID)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a backlog task, got %d", w.Code)
+	}
+}
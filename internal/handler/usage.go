@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"strconv"
 	"time"
 
+	"latere.ai/x/wallfacer/internal/envconfig"
 	"latere.ai/x/wallfacer/internal/pkg/httpjson"
 	"latere.ai/x/wallfacer/internal/store"
 )
@@ -17,6 +19,43 @@ type usageResponse struct {
 	BySubAgent map[store.SandboxActivity]store.TaskUsage `json:"by_sub_agent"`
 	TaskCount  int                                       `json:"task_count"`
 	PeriodDays int                                       `json:"period_days"`
+
+	// Spend is the board's all-time and today's cost regardless of days,
+	// reported next to the budgets it is measured against (0 = unlimited).
+	Spend          store.Spend `json:"spend"`
+	DailyBudgetUSD float64     `json:"daily_budget_usd"`
+	BoardBudgetUSD float64     `json:"board_budget_usd"`
+}
+
+// costBudgets returns WALLFACER_DAILY_BUDGET_USD and WALLFACER_BOARD_BUDGET_USD,
+// both 0 (unlimited) when unset or the env file is absent.
+func (h *Handler) costBudgets() (daily, board float64) {
+	if h.envFile == "" {
+		return 0, 0
+	}
+	cfg, err := envconfig.Parse(h.envFile)
+	if err != nil {
+		return 0, 0
+	}
+	return cfg.DailyBudgetUSD, cfg.BoardBudgetUSD
+}
+
+// boardBudgetExceeded reports why the current board's daily or total budget
+// blocks new work, or "" when no budget is set or reached.
+func (h *Handler) boardBudgetExceeded(ctx context.Context) string {
+	daily, board := h.costBudgets()
+	if daily <= 0 && board <= 0 {
+		return ""
+	}
+	s, ok := h.currentStore()
+	if !ok {
+		return ""
+	}
+	spend, err := s.Spend(ctx, time.Now())
+	if err != nil {
+		return ""
+	}
+	return spend.Exceeded(daily, board)
 }
 
 // agentSessionRecordAsUsage projects a TurnUsageRecord into the TaskUsage shape
@@ -116,5 +155,10 @@ func (h *Handler) GetUsageStats(w http.ResponseWriter, r *http.Request) {
 
 	mergeAgentSessionUsage(&resp, h.configDir, cutoff)
 
+	if spend, err := s.Spend(r.Context(), time.Now()); err == nil {
+		resp.Spend = spend
+	}
+	resp.DailyBudgetUSD, resp.BoardBudgetUSD = h.costBudgets()
+
 	httpjson.Write(w, http.StatusOK, resp)
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
@@ -362,3 +363,34 @@ func TestUsage_TaskCountUnchangedByAgentSession(t *testing.T) {
 		t.Errorf("TaskCount = %d, want 0 (agent-session rounds must not count as tasks)", resp.TaskCount)
 	}
 }
+
+func TestGetUsageStats_SpendAndBudgets(t *testing.T) {
+	h, _ := newTestHandlerWithWorkspaces(t)
+	if err := os.WriteFile(h.envFile, []byte("WALLFACER_DAILY_BUDGET_USD=5\nWALLFACER_BOARD_BUDGET_USD=0.02\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "spend", Timeout: 30, Kind: store.TaskKindTask})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if err := h.store.AccumulateSubAgentUsage(ctx, task.ID, "implementation", store.TaskUsage{CostUSD: 0.03}); err != nil {
+		t.Fatalf("AccumulateSubAgentUsage: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.GetUsageStats(w, httptest.NewRequest(http.MethodGet, "/api/usage", nil))
+	var resp usageResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Spend.TotalUSD != 0.03 {
+		t.Errorf("spend total = %v, want 0.03", resp.Spend.TotalUSD)
+	}
+	if resp.DailyBudgetUSD != 5 || resp.BoardBudgetUSD != 0.02 {
+		t.Errorf("budgets = %v, %v, want 5, 0.02", resp.DailyBudgetUSD, resp.BoardBudgetUSD)
+	}
+	if h.boardBudgetExceeded(ctx) == "" {
+		t.Error("board budget of $0.02 should be exceeded by $0.03 of spend")
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/egress"
+	"latere.ai/x/wallfacer/internal/pkg/httpjson"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// FieldError names one request field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects the field-level problems found in a request body.
+// Handlers write it with writeValidationErrors, which responds 422 so clients
+// can tell a rejected value apart from a malformed body (400).
+type ValidationErrors []FieldError
+
+// Add records a problem with field.
+func (v *ValidationErrors) Add(field, format string, args ...any) {
+	*v = append(*v, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Error joins the problems as "field: message; ...".
+func (v ValidationErrors) Error() string {
+	parts := make([]string, len(v))
+	for i, fe := range v {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// validationResponse is the 422 body. Error repeats the field messages as one
+// line so clients that only read "error" still show something useful.
+type validationResponse struct {
+	Error  string       `json:"error"`
+	Code   string       `json:"code"`
+	Fields []FieldError `json:"fields"`
+}
+
+// writeValidationErrors responds 422 with the field-level problems in errs.
+func writeValidationErrors(w http.ResponseWriter, errs ValidationErrors) {
+	httpjson.Write(w, http.StatusUnprocessableEntity, validationResponse{
+		Error:  errs.Error(),
+		Code:   codeValidationFailed,
+		Fields: errs,
+	})
+}
+
+// writeFieldError responds 422 for a single invalid field.
+func writeFieldError(w http.ResponseWriter, field, format string, args ...any) {
+	var errs ValidationErrors
+	errs.Add(field, format, args...)
+	writeValidationErrors(w, errs)
+}
+
+// taskFields holds the task fields CreateTask and UpdateTask share. A nil
+// pointer means the field was not sent and is not checked.
+type taskFields struct {
+	Prompt             *string
+	Timeout            *int
+	MaxCostUSD         *float64
+	MaxInputTokens     *int
+	CustomPassPatterns []string
+	CustomFailPatterns []string
+	StoryPoints        *float64
+	Size               *string
+}
+
+// validate checks the shared task fields and returns every problem found,
+// so a client can fix them all in one round trip.
+func (f taskFields) validate() ValidationErrors {
+	var errs ValidationErrors
+	if f.Prompt != nil && strings.TrimSpace(*f.Prompt) == "" {
+		errs.Add("prompt", "must not be empty")
+	}
+	if f.Timeout != nil && (*f.Timeout < 0 || *f.Timeout > store.MaxTaskTimeoutMinutes) {
+		errs.Add("timeout", "must be between 1 and %d minutes, or 0 for the default (got %d)", store.MaxTaskTimeoutMinutes, *f.Timeout)
+	}
+	if f.MaxCostUSD != nil && *f.MaxCostUSD < 0 {
+		errs.Add("max_cost_usd", "must not be negative")
+	}
+	if f.MaxInputTokens != nil && *f.MaxInputTokens < 0 {
+		errs.Add("max_input_tokens", "must not be negative")
+	}
+	if f.StoryPoints != nil && (*f.StoryPoints < 0 || *f.StoryPoints > store.MaxStoryPoints) {
+		errs.Add("story_points", "must be between 0 and %d (got %v)", store.MaxStoryPoints, *f.StoryPoints)
+	}
+	if f.Size != nil {
+		if _, ok := store.ParseTaskSize(*f.Size); !ok {
+			errs.Add("size", "must be one of xs, s, m, l, xl, or empty (got %q)", *f.Size)
+		}
+	}
+	for _, p := range f.CustomPassPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			errs.Add("custom_pass_patterns", "invalid pattern %q: %v", p, err)
+		}
+	}
+	for _, p := range f.CustomFailPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			errs.Add("custom_fail_patterns", "invalid pattern %q: %v", p, err)
+		}
+	}
+	return errs
+}
+
+// researchDomains validates the research fields of a new task and returns
+// its allowlist normalized and deduplicated. A research task needs at least
+// one domain and a timeout within the research time box; other kinds must
+// not send domains.
+func researchDomains(kind store.TaskKind, raw []string, timeout int, errs *ValidationErrors) []string {
+	if kind != store.TaskKindResearch {
+		if len(raw) > 0 {
+			errs.Add("research_domains", "only allowed when kind is %q", store.TaskKindResearch)
+		}
+		return nil
+	}
+	var domains []string
+	for _, d := range raw {
+		n := egress.NormalizeDomain(d)
+		if n == "" || !strings.Contains(n, ".") {
+			errs.Add("research_domains", "invalid domain %q", d)
+			continue
+		}
+		if !slices.Contains(domains, n) {
+			domains = append(domains, n)
+		}
+	}
+	if len(raw) == 0 {
+		errs.Add("research_domains", "a research task needs at least one domain")
+	}
+	if timeout > store.MaxResearchTimeoutMinutes {
+		errs.Add("timeout", "must be at most %d minutes for a research task (got %d)", store.MaxResearchTimeoutMinutes, timeout)
+	}
+	return domains
+}
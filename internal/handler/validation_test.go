@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// decodeValidation decodes a 422 body and returns its field names in order.
+func decodeValidation(t *testing.T, w *httptest.ResponseRecorder) []string {
+	t.Helper()
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp validationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Error == "" {
+		t.Error("error summary is empty")
+	}
+	if resp.Code == "" {
+		t.Error("error code is empty")
+	}
+	fields := make([]string, len(resp.Fields))
+	for i, fe := range resp.Fields {
+		if fe.Message == "" {
+			t.Errorf("field %q has no message", fe.Field)
+		}
+		fields[i] = fe.Field
+	}
+	return fields
+}
+
+func TestTaskFields_Validate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	cost := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name   string
+		fields taskFields
+		want   []string
+	}{
+		{"unset fields pass", taskFields{}, nil},
+		{"valid", taskFields{Prompt: str("do it"), Timeout: num(60), MaxCostUSD: cost(1.5)}, nil},
+		{"zero timeout means default", taskFields{Timeout: num(0)}, nil},
+		{"blank prompt", taskFields{Prompt: str("  \n")}, []string{"prompt"}},
+		{"negative timeout", taskFields{Timeout: num(-1)}, []string{"timeout"}},
+		{"timeout over max", taskFields{Timeout: num(store.MaxTaskTimeoutMinutes + 1)}, []string{"timeout"}},
+		{"negative budgets", taskFields{MaxCostUSD: cost(-1), MaxInputTokens: num(-5)}, []string{"max_cost_usd", "max_input_tokens"}},
+		{"bad patterns", taskFields{CustomPassPatterns: []string{"ok", "("}, CustomFailPatterns: []string{"["}}, []string{"custom_pass_patterns", "custom_fail_patterns"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.fields.validate()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got %v, want fields %v", errs, tt.want)
+			}
+			for i, fe := range errs {
+				if fe.Field != tt.want[i] {
+					t.Errorf("errs[%d].Field = %q, want %q", i, fe.Field, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateTask_ReportsAllInvalidFields(t *testing.T) {
+	h := newTestHandler(t)
+	body := `{"prompt": "", "timeout": 100000}`
+	w := httptest.NewRecorder()
+	h.CreateTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks", strings.NewReader(body)))
+
+	fields := decodeValidation(t, w)
+	if strings.Join(fields, ",") != "prompt,timeout" {
+		t.Errorf("fields = %v, want [prompt timeout]", fields)
+	}
+	tasks, _ := h.store.ListTasks(context.Background(), false)
+	if len(tasks) != 0 {
+		t.Errorf("task created despite validation errors: %d", len(tasks))
+	}
+}
+
+func TestUpdateTask_ValidationLeavesTaskUnchanged(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "original", Timeout: 15})
+
+	// The prompt is valid but the timeout is not; neither may be applied.
+	body := `{"prompt": "rewritten", "timeout": -3}`
+	w := httptest.NewRecorder()
+	h.UpdateTask(w, httptest.NewRequest(http.MethodPatch, "/api/tasks/"+task.ID.String(), strings.NewReader(body)), task.ID)
+
+	if fields := decodeValidation(t, w); len(fields) != 1 || fields[0] != "timeout" {
+		t.Errorf("fields = %v, want [timeout]", fields)
+	}
+	got, _ := h.store.GetTask(ctx, task.ID)
+	if got.Prompt != "original" || got.Timeout != 15 {
+		t.Errorf("task changed: prompt=%q timeout=%d", got.Prompt, got.Timeout)
+	}
+}
+
+func TestUpdateTask_RejectedTransitionLeavesTaskUnchanged(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "original", Timeout: 15})
+
+	// Every field is valid on its own, but backlog → done is not a legal
+	// transition, so the prompt, position, and tags must not land either.
+	body := `{"prompt": "rewritten", "position": 9, "tags": ["x"], "status": "done"}`
+	w := httptest.NewRecorder()
+	h.UpdateTask(w, httptest.NewRequest(http.MethodPatch, "/api/tasks/"+task.ID.String(), strings.NewReader(body)), task.ID)
+
+	if fields := decodeValidation(t, w); len(fields) != 1 || fields[0] != "status" {
+		t.Errorf("fields = %v, want [status]", fields)
+	}
+	got, _ := h.store.GetTask(ctx, task.ID)
+	if got.Prompt != "original" || got.Position != task.Position || len(got.Tags) != 0 || got.Status != store.TaskStatusBacklog {
+		t.Errorf("task changed: prompt=%q position=%d tags=%v status=%s", got.Prompt, got.Position, got.Tags, got.Status)
+	}
+}
+
+func TestUpdateTask_InvalidStatusNamesField(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	task, _ := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 15})
+
+	for _, body := range []string{`{"status": "done"}`, `{"status": "bogus"}`} {
+		w := httptest.NewRecorder()
+		h.UpdateTask(w, httptest.NewRequest(http.MethodPatch, "/api/tasks/"+task.ID.String(), strings.NewReader(body)), task.ID)
+		if fields := decodeValidation(t, w); len(fields) != 1 || fields[0] != "status" {
+			t.Errorf("%s: fields = %v, want [status]", body, fields)
+		}
+	}
+}
+
+func TestResearchDomains(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    store.TaskKind
+		raw     []string
+		timeout int
+		want    []string
+		fields  []string
+	}{
+		{"non-research without domains", store.TaskKindTask, nil, 60, nil, nil},
+		{"non-research with domains", store.TaskKindTask, []string{"go.dev"}, 0, nil, []string{"research_domains"}},
+		{"normalized and deduplicated", store.TaskKindResearch, []string{"https://Go.dev/doc", "*.go.dev", "pkg.go.dev"}, 20, []string{"go.dev", "pkg.go.dev"}, nil},
+		{"missing domains", store.TaskKindResearch, nil, 0, nil, []string{"research_domains"}},
+		{"invalid domain", store.TaskKindResearch, []string{"localhost", "go.dev"}, 0, []string{"go.dev"}, []string{"research_domains"}},
+		{"timeout over time box", store.TaskKindResearch, []string{"go.dev"}, store.MaxResearchTimeoutMinutes + 1, []string{"go.dev"}, []string{"timeout"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs ValidationErrors
+			got := researchDomains(tt.kind, tt.raw, tt.timeout, &errs)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("domains = %v, want %v", got, tt.want)
+			}
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("errs = %v, want fields %v", errs, tt.fields)
+			}
+			for i, fe := range errs {
+				if fe.Field != tt.fields[i] {
+					t.Errorf("errs[%d].Field = %q, want %q", i, fe.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateTask_ResearchKindStoresDomains(t *testing.T) {
+	h := newTestHandler(t)
+	body := `{"prompt": "compare Go schedulers", "kind": "research", "timeout": 20, "research_domains": ["https://go.dev/doc", "github.com"]}`
+	w := httptest.NewRecorder()
+	h.CreateTask(w, httptest.NewRequest(http.MethodPost, "/api/tasks", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var task store.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &task); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if task.Kind != store.TaskKindResearch || strings.Join(task.ResearchDomains, ",") != "go.dev,github.com" {
+		t.Errorf("kind=%q domains=%v, want research [go.dev github.com]", task.Kind, task.ResearchDomains)
+	}
+}
@@ -34,6 +34,11 @@ type workspaceDTO struct {
 	Active          bool     `json:"active"`
 	MaxParallel     *int     `json:"max_parallel,omitempty"`
 	MaxTestParallel *int     `json:"max_test_parallel,omitempty"`
+	Bootstrap       string   `json:"bootstrap,omitempty"`
+	Publish         string   `json:"publish,omitempty"`
+	Preview         string   `json:"preview,omitempty"`
+	StageIgnore     []string `json:"stage_ignore,omitempty"`
+	Arch            string   `json:"arch,omitempty"`
 }
 
 func (h *Handler) workspaceDTO(ws workspace.Workspace) workspaceDTO {
@@ -51,6 +56,11 @@ func (h *Handler) workspaceDTO(ws workspace.Workspace) workspaceDTO {
 		Active:          ws.ID != "" && ws.ID == h.activeWorkspaceID(),
 		MaxParallel:     ws.MaxParallel,
 		MaxTestParallel: ws.MaxTestParallel,
+		Bootstrap:       ws.Bootstrap,
+		Publish:         ws.Publish,
+		Preview:         ws.Preview,
+		StageIgnore:     ws.StageIgnore,
+		Arch:            ws.Arch,
 	}
 }
 
@@ -150,6 +160,18 @@ func (h *Handler) UpdateWorkspace(w http.ResponseWriter, r *http.Request) {
 		// distinguishable from an absent key (leave the override unchanged).
 		MaxParallel     json.RawMessage `json:"max_parallel"`
 		MaxTestParallel json.RawMessage `json:"max_test_parallel"`
+		// Bootstrap replaces the bootstrap command; "" removes it.
+		Bootstrap *string `json:"bootstrap"`
+		// Publish replaces the post-merge publish command; "" removes it.
+		Publish *string `json:"publish"`
+		// Preview replaces the deploy-preview provider ("vercel",
+		// "netlify") or command; "" removes it.
+		Preview *string `json:"preview"`
+		// StageIgnore replaces the stage-ignore patterns; [] removes them.
+		StageIgnore *[]string `json:"stage_ignore"`
+		// Arch forces the agents' architecture ("amd64" or "arm64"); ""
+		// restores the host's native one.
+		Arch *string `json:"arch"`
 	}](w, r)
 	if !ok {
 		return
@@ -194,6 +216,41 @@ func (h *Handler) UpdateWorkspace(w http.ResponseWriter, r *http.Request) {
 		}
 		updated = true
 	}
+	if req.Bootstrap != nil {
+		if ws, err = h.workspace.SetBootstrap(id, *req.Bootstrap); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updated = true
+	}
+	if req.Publish != nil {
+		if ws, err = h.workspace.SetPublish(id, *req.Publish); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updated = true
+	}
+	if req.Preview != nil {
+		if ws, err = h.workspace.SetPreview(id, *req.Preview); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updated = true
+	}
+	if req.StageIgnore != nil {
+		if ws, err = h.workspace.SetStageIgnore(id, *req.StageIgnore); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updated = true
+	}
+	if req.Arch != nil {
+		if ws, err = h.workspace.SetArch(id, *req.Arch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updated = true
+	}
 	if !updated {
 		var found bool
 		if ws, found, err = h.workspace.WorkspaceByID(id); err != nil || !found {
@@ -146,6 +146,83 @@ func TestWorkspaceUpdate_Limits(t *testing.T) {
 	}
 }
 
+// TestWorkspaceUpdate_Bootstrap verifies the bootstrap command is set,
+// survives an update that omits it, and is removed by an empty string.
+func TestWorkspaceUpdate_Bootstrap(t *testing.T) {
+	h, _, ws := newTestHandlerWithRealWorkspaceManager(t)
+	body, _ := json.Marshal(map[string]any{"name": "A", "folders": []string{ws}})
+	rec := httptest.NewRecorder()
+	h.CreateWorkspace(rec, httptest.NewRequest(http.MethodPost, "/api/workspaces", bytes.NewReader(body)))
+	var created workspaceDTO
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+
+	put := func(payload string) workspaceDTO {
+		r := httptest.NewRequest(http.MethodPut, "/api/workspaces/"+created.ID, bytes.NewReader([]byte(payload)))
+		r.SetPathValue("id", created.ID)
+		w := httptest.NewRecorder()
+		h.UpdateWorkspace(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("update %s: %d %s", payload, w.Code, w.Body.String())
+		}
+		var dto workspaceDTO
+		_ = json.Unmarshal(w.Body.Bytes(), &dto)
+		return dto
+	}
+
+	if d := put(`{"bootstrap":" go mod download "}`); d.Bootstrap != "go mod download" {
+		t.Fatalf("after set: bootstrap = %q", d.Bootstrap)
+	}
+	if d := put(`{"name":"B"}`); d.Bootstrap != "go mod download" {
+		t.Fatalf("absent field should be preserved: bootstrap = %q", d.Bootstrap)
+	}
+	if d := put(`{"bootstrap":""}`); d.Bootstrap != "" {
+		t.Fatalf("empty string should clear: bootstrap = %q", d.Bootstrap)
+	}
+	if d := put(`{"publish":"make release"}`); d.Publish != "make release" || d.Bootstrap != "" {
+		t.Fatalf("after set: publish = %q, bootstrap = %q", d.Publish, d.Bootstrap)
+	}
+	if d := put(`{"preview":"netlify"}`); d.Preview != "netlify" || d.Publish != "make release" {
+		t.Fatalf("after set: preview = %q, publish = %q", d.Preview, d.Publish)
+	}
+	if d := put(`{"stage_ignore":["dist/"," coverage.out "]}`); len(d.StageIgnore) != 2 || d.StageIgnore[1] != "coverage.out" {
+		t.Fatalf("after set: stage_ignore = %q", d.StageIgnore)
+	}
+	if d := put(`{"stage_ignore":[]}`); len(d.StageIgnore) != 0 || d.Preview != "netlify" {
+		t.Fatalf("empty list should clear: stage_ignore = %q, preview = %q", d.StageIgnore, d.Preview)
+	}
+}
+
+// TestWorkspaceUpdate_Arch verifies the architecture override is normalized,
+// rejected when unsupported, and cleared by an empty string.
+func TestWorkspaceUpdate_Arch(t *testing.T) {
+	h, _, ws := newTestHandlerWithRealWorkspaceManager(t)
+	body, _ := json.Marshal(map[string]any{"name": "A", "folders": []string{ws}})
+	rec := httptest.NewRecorder()
+	h.CreateWorkspace(rec, httptest.NewRequest(http.MethodPost, "/api/workspaces", bytes.NewReader(body)))
+	var created workspaceDTO
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+
+	put := func(payload string) (int, workspaceDTO) {
+		r := httptest.NewRequest(http.MethodPut, "/api/workspaces/"+created.ID, bytes.NewReader([]byte(payload)))
+		r.SetPathValue("id", created.ID)
+		w := httptest.NewRecorder()
+		h.UpdateWorkspace(w, r)
+		var dto workspaceDTO
+		_ = json.Unmarshal(w.Body.Bytes(), &dto)
+		return w.Code, dto
+	}
+
+	if code, d := put(`{"arch":"x86_64"}`); code != http.StatusOK || d.Arch != "amd64" {
+		t.Fatalf("after set: %d arch = %q", code, d.Arch)
+	}
+	if code, _ := put(`{"arch":"mips"}`); code != http.StatusBadRequest {
+		t.Fatalf("unsupported arch: got %d, want 400", code)
+	}
+	if code, d := put(`{"arch":""}`); code != http.StatusOK || d.Arch != "" {
+		t.Fatalf("empty string should clear: %d arch = %q", code, d.Arch)
+	}
+}
+
 // TestWorkspaceUpdate_VisibilityIsolation verifies that in cloud mode a caller
 // who cannot see an org-stamped workspace gets 404 (not found, no leak) on a
 // mutation, while the owning org caller passes the guard.
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/coder/websocket"
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/executor"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// wsWriteTimeout bounds a single frame write so a stalled client cannot
+// hold the updates stream open forever.
+const wsWriteTimeout = 10 * time.Second
+
+// containerRemovedState is the state pushed for a container that has left
+// the container list.
+const containerRemovedState = "removed"
+
+// wsMessage is one server → client frame of the /api/ws stream. Seq is the
+// task delta sequence number on task frames, usable as ?last_event_id= on
+// reconnect; it is omitted on event and container frames.
+type wsMessage struct {
+	Type string          `json:"type"`
+	Seq  int64           `json:"seq,omitempty"`
+	Data json.RawMessage `json:"data"`
+}
+
+// SetTrustedOrigins lists the cross-origin pages (scheme://host[:port],
+// normally WALLFACER_CORS_ORIGINS) allowed to open /api/ws and the
+// terminal WebSocket. The "*"
+// wildcard is ignored here: it stands for credential-less requests, and a
+// WebSocket handshake always carries the session cookie.
+func (h *Handler) SetTrustedOrigins(origins []string) {
+	var patterns []string
+	for o := range originSet(origins) {
+		if o != "*" {
+			patterns = append(patterns, o)
+		}
+	}
+	slices.Sort(patterns)
+	h.wsOrigins = patterns
+}
+
+// HandleUpdatesWS pushes typed live updates over a WebSocket, so a client
+// can patch its local state instead of re-fetching the task list on every
+// store change. It carries the same task deltas as StreamTasks plus the
+// events appended to any task and container state changes:
+//
+//	snapshot         — full task list (data: []Task), unless the replay covers the gap
+//	task-created     — a task was created or restored (data: Task)
+//	task-updated     — a task was mutated (data: Task)
+//	task-deleted     — a task was deleted (data: {"id":"<uuid>"})
+//	event-appended   — an event was added to a task's trail (data: TaskEvent)
+//	container-state  — a container appeared or changed state, or left the
+//	                   list with state "removed" (data: ContainerInfo)
+//
+// ?include_archived and ?last_event_id behave as on StreamTasks. The stream
+// is server → client only; a data frame from the client closes it.
+func (h *Handler) HandleUpdatesWS(w http.ResponseWriter, r *http.Request) {
+	// Capture the store once, as StreamTasks does, so a workspace switch
+	// mid-connection cannot mix two workspaces' tasks on one stream.
+	s, ok := h.requireStore(w)
+	if !ok {
+		return
+	}
+
+	// Subscribe BEFORE reading any state so nothing is missed between the
+	// snapshot and the live loop.
+	subID, deltas := s.Subscribe()
+	defer s.Unsubscribe(subID)
+	eventSubID, events := s.SubscribeEvents()
+	defer s.UnsubscribeEvents(eventSubID)
+
+	// Only the board's own origin and the trusted origins may connect: a
+	// page on another port of the same host is same-site, so the browser
+	// would send it the SameSite session cookie.
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		OriginPatterns: h.wsOrigins,
+	})
+	if err != nil {
+		logger.Handler.Error("ws: websocket accept failed", "error", err)
+		return
+	}
+	defer func() { _ = conn.CloseNow() }()
+
+	// CloseRead keeps reading control frames (pongs, close) and cancels ctx
+	// once the client goes away.
+	ctx := conn.CloseRead(r.Context())
+	send := func(typ string, seq int64, data []byte) bool {
+		payload, err := json.Marshal(wsMessage{Type: typ, Seq: seq, Data: data})
+		if err != nil {
+			return true
+		}
+		writeCtx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+		defer cancel()
+		return conn.Write(writeCtx, websocket.MessageText, payload) == nil
+	}
+
+	replayUpTo, ok := h.sendWSTaskState(r, s, send)
+	if !ok {
+		return
+	}
+
+	containers := map[string]executor.ContainerInfo{}
+	if !h.sendContainerChanges(containers, send) {
+		return
+	}
+
+	keepalive := time.NewTicker(constants.SSEKeepaliveInterval)
+	defer keepalive.Stop()
+	containerPoll := time.NewTicker(constants.WSContainerPollInterval)
+	defer containerPoll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close(websocket.StatusNormalClosure, "")
+			return
+		case delta, ok := <-deltas:
+			if !ok {
+				_ = conn.Close(websocket.StatusTryAgainLater, "subscriber overflow")
+				return
+			}
+			if delta.Seq <= replayUpTo {
+				continue
+			}
+			payload, err := marshalDeltaPayload(delta.Value)
+			if err != nil {
+				continue
+			}
+			if !send(wsDeltaType(delta.Value), delta.Seq, payload) {
+				return
+			}
+		case ev, ok := <-events:
+			if !ok {
+				_ = conn.Close(websocket.StatusTryAgainLater, "subscriber overflow")
+				return
+			}
+			payload, err := json.Marshal(ev.Value)
+			if err != nil {
+				continue
+			}
+			if !send("event-appended", 0, payload) {
+				return
+			}
+		case <-containerPoll.C:
+			if !h.sendContainerChanges(containers, send) {
+				return
+			}
+		case <-keepalive.C:
+			pingCtx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+			err := conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sendWSTaskState sends the missed task deltas when ?last_event_id is
+// covered by the replay buffer, and the full snapshot otherwise. It returns
+// the highest sequence number sent, so the live loop can skip duplicates.
+func (h *Handler) sendWSTaskState(r *http.Request, s *store.Store, send func(string, int64, []byte) bool) (int64, bool) {
+	if seq, err := strconv.ParseInt(r.URL.Query().Get("last_event_id"), 10, 64); err == nil {
+		if deltas, tooOld := s.DeltasSince(seq); !tooOld {
+			replayUpTo := seq
+			for _, d := range deltas {
+				payload, err := marshalDeltaPayload(d.Value)
+				if err != nil {
+					continue
+				}
+				if !send(wsDeltaType(d.Value), d.Seq, payload) {
+					return 0, false
+				}
+				replayUpTo = d.Seq
+			}
+			return replayUpTo, true
+		}
+	}
+
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+	tasks, currentSeq, err := s.ListTasksAndSeq(r.Context(), includeArchived)
+	if err != nil {
+		return 0, false
+	}
+	if tasks == nil {
+		tasks = []store.Task{}
+	}
+	snapshot, err := json.Marshal(tasks)
+	if err != nil {
+		return 0, false
+	}
+	return currentSeq, send("snapshot", currentSeq, snapshot)
+}
+
+// sendContainerChanges lists the containers and sends a container-state
+// frame for each one that is new, changed state, or is gone since the
+// previous call, updating known in place. Listing errors send nothing.
+func (h *Handler) sendContainerChanges(known map[string]executor.ContainerInfo, send func(string, int64, []byte) bool) bool {
+	list, err := h.runner.ListContainers()
+	if err != nil {
+		return true
+	}
+	var changed []executor.ContainerInfo
+	seen := make(map[string]bool, len(list))
+	for _, c := range list {
+		seen[c.ID] = true
+		if prev, ok := known[c.ID]; !ok || prev.State != c.State {
+			changed = append(changed, c)
+		}
+		known[c.ID] = c
+	}
+	for id, c := range known {
+		if !seen[id] {
+			c.State = containerRemovedState
+			changed = append(changed, c)
+			delete(known, id)
+		}
+	}
+	for _, c := range changed {
+		payload, err := json.Marshal(c)
+		if err != nil {
+			continue
+		}
+		if !send("container-state", 0, payload) {
+			return false
+		}
+	}
+	return true
+}
+
+// wsDeltaType returns the /api/ws frame type for a TaskDelta.
+func wsDeltaType(d store.TaskDelta) string {
+	switch {
+	case d.Deleted:
+		return "task-deleted"
+	case d.Created:
+		return "task-created"
+	}
+	return "task-updated"
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"latere.ai/x/wallfacer/internal/executor"
+	"latere.ai/x/wallfacer/internal/runner"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// containerListRunner is a MockRunner whose container list the test sets.
+type containerListRunner struct {
+	runner.MockRunner
+	mu         sync.Mutex
+	containers []executor.ContainerInfo
+}
+
+func (m *containerListRunner) ListContainers() ([]executor.ContainerInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]executor.ContainerInfo(nil), m.containers...), nil
+}
+
+func (m *containerListRunner) setContainers(c ...executor.ContainerInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.containers = c
+}
+
+// dialUpdatesWS serves h.HandleUpdatesWS and connects to it.
+func dialUpdatesWS(t *testing.T, ctx context.Context, h *Handler, query string) *websocket.Conn {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(h.HandleUpdatesWS))
+	t.Cleanup(srv.Close)
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws"+query, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.CloseNow() })
+	return conn
+}
+
+// readWSMessage reads frames until one of type typ arrives.
+func readWSMessage(t *testing.T, ctx context.Context, conn *websocket.Conn, typ string) wsMessage {
+	t.Helper()
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("waiting for %s: %v", typ, err)
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decode %s: %v", data, err)
+		}
+		if msg.Type == typ {
+			return msg
+		}
+	}
+}
+
+func TestUpdatesWS_PushesTypedTaskAndEventFrames(t *testing.T) {
+	h := newTestHandler(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn := dialUpdatesWS(t, ctx, h, "")
+	if msg := readWSMessage(t, ctx, conn, "snapshot"); string(msg.Data) != "[]" {
+		t.Fatalf("snapshot = %s, want an empty task list", msg.Data)
+	}
+
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := readWSMessage(t, ctx, conn, "task-created")
+	var got store.Task
+	if err := json.Unmarshal(created.Data, &got); err != nil || got.ID != task.ID || created.Seq == 0 {
+		t.Fatalf("task-created = %s (seq %d), want task %s", created.Data, created.Seq, task.ID)
+	}
+
+	if err := h.store.UpdateTaskTitle(ctx, task.ID, "renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readWSMessage(t, ctx, conn, "task-updated"); !strings.Contains(string(msg.Data), `"renamed"`) {
+		t.Errorf("task-updated = %s, want the new title", msg.Data)
+	}
+
+	if err := h.store.InsertEvent(ctx, task.ID, store.EventTypeSystem, map[string]string{"result": "ok"}); err != nil {
+		t.Fatal(err)
+	}
+	var ev store.TaskEvent
+	if err := json.Unmarshal(readWSMessage(t, ctx, conn, "event-appended").Data, &ev); err != nil ||
+		ev.TaskID != task.ID || ev.EventType != store.EventTypeSystem {
+		t.Errorf("event-appended = %+v, want the system event of task %s", ev, task.ID)
+	}
+
+	if err := h.store.DeleteTask(ctx, task.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readWSMessage(t, ctx, conn, "task-deleted"); string(msg.Data) != `{"id":"`+task.ID.String()+`"}` {
+		t.Errorf("task-deleted = %s", msg.Data)
+	}
+}
+
+func TestUpdatesWS_ChecksOrigin(t *testing.T) {
+	h := newTestHandler(t)
+	h.SetTrustedOrigins([]string{"https://app.example.com", "*"})
+	srv := httptest.NewServer(http.HandlerFunc(h.HandleUpdatesWS))
+	t.Cleanup(srv.Close)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for origin, wantOK := range map[string]bool{
+		srv.URL:                   true,  // the board's own page
+		"https://app.example.com": true,  // a trusted origin
+		"http://127.0.0.1:3000":   false, // another port on the same host
+		"https://evil.example":    false, // "*" does not open the stream
+	} {
+		conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), &websocket.DialOptions{
+			HTTPHeader: http.Header{"Origin": {origin}},
+		})
+		if (err == nil) != wantOK {
+			t.Errorf("origin %s: dial error = %v, want allowed %v", origin, err, wantOK)
+		}
+		if conn != nil {
+			_ = conn.CloseNow()
+		}
+	}
+}
+
+func TestUpdatesWS_ReplaysFromLastEventID(t *testing.T) {
+	h := newTestHandler(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	seq := h.store.LatestDeltaSeq()
+	task, err := h.store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := dialUpdatesWS(t, ctx, h, "?last_event_id="+strconv.FormatInt(seq, 10))
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg wsMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "task-created" || !strings.Contains(string(msg.Data), task.ID.String()) {
+		t.Errorf("first frame = %s, want the missed task-created instead of a snapshot", data)
+	}
+}
+
+func TestUpdatesWS_PushesContainerStateChanges(t *testing.T) {
+	h := newTestHandler(t)
+	mr := &containerListRunner{}
+	mr.setContainers(executor.ContainerInfo{ID: "c1", State: "running"})
+	h.runner = mr
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn := dialUpdatesWS(t, ctx, h, "")
+	var c executor.ContainerInfo
+	if err := json.Unmarshal(readWSMessage(t, ctx, conn, "container-state").Data, &c); err != nil || c.ID != "c1" || c.State != "running" {
+		t.Fatalf("initial container-state = %+v, want c1 running", c)
+	}
+}
+
+func TestSendContainerChanges(t *testing.T) {
+	h := newTestHandler(t)
+	mr := &containerListRunner{}
+	h.runner = mr
+
+	known := map[string]executor.ContainerInfo{}
+	var sent []string
+	send := func(typ string, _ int64, data []byte) bool {
+		var c executor.ContainerInfo
+		_ = json.Unmarshal(data, &c)
+		sent = append(sent, typ+" "+c.ID+" "+c.State)
+		return true
+	}
+	poll := func() []string {
+		sent = nil
+		h.sendContainerChanges(known, send)
+		return sent
+	}
+
+	mr.setContainers(executor.ContainerInfo{ID: "c1", State: "running", Status: "Up 1 second"})
+	if got := poll(); len(got) != 1 || got[0] != "container-state c1 running" {
+		t.Errorf("new container sent %v", got)
+	}
+	mr.setContainers(executor.ContainerInfo{ID: "c1", State: "running", Status: "Up 5 seconds"})
+	if got := poll(); len(got) != 0 {
+		t.Errorf("unchanged state sent %v", got)
+	}
+	mr.setContainers(executor.ContainerInfo{ID: "c1", State: "exited"})
+	if got := poll(); len(got) != 1 || got[0] != "container-state c1 exited" {
+		t.Errorf("state change sent %v", got)
+	}
+	mr.setContainers()
+	if got := poll(); len(got) != 1 || got[0] != "container-state c1 "+containerRemovedState {
+		t.Errorf("removal sent %v", got)
+	}
+}
@@ -16,6 +16,9 @@ type AuthConfig struct {
 	// Cursor.
 	CursorAPIKey string
 
+	// Google — used by Gemini.
+	GeminiAPIKey string
+
 	// OpenCode — server-mode for warm-start; provider auth is managed
 	// by the opencode CLI itself.
 	OpenCodeServerURL      string
@@ -21,4 +21,19 @@ type Capabilities struct {
 	EmitsUsage           bool
 	EmitsCost            bool
 	NeedsTTY             bool
+	// PromptViaStdin reports that BuildArgv hands the prompt over on stdin
+	// rather than in argv, so it is neither bounded by ARG_MAX nor visible
+	// to other users through ps or /proc/<pid>/cmdline.
+	PromptViaStdin bool
+	// InteractiveInput reports that the harness can run with stdin kept
+	// open (Request.Interactive) and implements InputEncoder for the
+	// input sent while it runs.
+	InteractiveInput bool
+}
+
+// InputEncoder is implemented by harnesses with the InteractiveInput
+// capability. EncodeInput frames one line of user input in the form the
+// CLI reads on its open stdin.
+type InputEncoder interface {
+	EncodeInput(text string) []byte
 }
@@ -1,8 +1,10 @@
 package harness
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
+	"strings"
 )
 
 func init() {
@@ -18,16 +20,26 @@ func (claudeHarness) ID() ID { return Claude }
 // BuildArgv assembles the claude argv for a Request. The argv shape is:
 //
 //	claude --dangerously-skip-permissions
-//	       -p <prompt> --verbose --output-format stream-json
+//	       -p --verbose --output-format stream-json
 //	       [--model <model>] [--resume <session>]
 //	       [--append-system-prompt <system-prompt>]
+//	       [--input-format stream-json]
+//	       < <prompt>
+//
+// The prompt is returned as the stdin payload: `-p` is claude's print-mode
+// switch and reads the prompt from stdin when no positional argument is
+// given, which keeps large prompts clear of ARG_MAX and out of ps output.
 //
 // The `--dangerously-skip-permissions` flag is required when claude runs in a
 // piped non-TTY context: without it claude waits for interactive permission
 // prompts and buffers all stream-json output until the task ends.
-func (claudeHarness) BuildArgv(req Request) ([]string, io.Reader, error) {
+//
+// An interactive request switches stdin to stream-json input: the prompt
+// becomes the first user message and claude keeps reading further messages
+// (see EncodeInput) until stdin is closed.
+func (c claudeHarness) BuildArgv(req Request) ([]string, io.Reader, error) {
 	argv := []string{"--dangerously-skip-permissions"}
-	argv = append(argv, "-p", req.Prompt, "--verbose", "--output-format", "stream-json")
+	argv = append(argv, "-p", "--verbose", "--output-format", "stream-json")
 	if req.Model != "" {
 		argv = append(argv, "--model", req.Model)
 	}
@@ -37,7 +49,28 @@ func (claudeHarness) BuildArgv(req Request) ([]string, io.Reader, error) {
 	if req.SystemPrompt != "" {
 		argv = append(argv, "--append-system-prompt", req.SystemPrompt)
 	}
-	return argv, nil, nil
+	if req.Interactive {
+		argv = append(argv, "--input-format", "stream-json")
+		return argv, bytes.NewReader(c.EncodeInput(req.Prompt)), nil
+	}
+	return argv, strings.NewReader(req.Prompt), nil
+}
+
+// claudeUserMessage is one stream-json input line.
+type claudeUserMessage struct {
+	Type    string `json:"type"`
+	Message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"message"`
+}
+
+// EncodeInput frames text as a stream-json user message line.
+func (claudeHarness) EncodeInput(text string) []byte {
+	msg := claudeUserMessage{Type: "user"}
+	msg.Message.Role, msg.Message.Content = "user", text
+	line, _ := json.Marshal(msg)
+	return append(line, '\n')
 }
 
 // claudeResultLine is the subset of claude's terminal stream-json object
@@ -65,16 +98,28 @@ type claudeUsage struct {
 // reported in two shapes: the system/init line carries it top-level
 // (`model`, including a context-window variant suffix such as "[1m]"),
 // while each assistant line carries the per-turn model nested under
-// `message.model`.
+// `message.model`. Assistant lines carry their text and tool calls as
+// content blocks.
 type claudeStreamLine struct {
 	Type    string `json:"type"`
 	Subtype string `json:"subtype"`
 	Model   string `json:"model"`
 	Message *struct {
-		Model string `json:"model"`
+		Model   string          `json:"model"`
+		Content json.RawMessage `json:"content"`
 	} `json:"message"`
 }
 
+// claudeContentBlock is one block of an assistant message: "text" carries
+// Text, "tool_use" carries ID, Name, and Input.
+type claudeContentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
 // ParseEvent maps one NDJSON line of claude output to a canonical Event.
 // Lines that do not match a known schema yield Event{Kind: KindUnknown}
 // with Raw populated, so callers can record but not crash on schema drift.
@@ -95,6 +140,25 @@ func (claudeHarness) ParseEvent(raw []byte) (Event, error) {
 		evt.Kind = KindAssistantText
 		if line.Message != nil {
 			evt.Model = line.Message.Model
+			// Content that is not a block list leaves the line plain
+			// assistant text.
+			var blocks []claudeContentBlock
+			_ = json.Unmarshal(line.Message.Content, &blocks)
+			var text []string
+			for _, b := range blocks {
+				switch b.Type {
+				case "text":
+					text = append(text, b.Text)
+				case "tool_use":
+					// A message that starts a tool call reports the call;
+					// claude streams one content block per line in practice.
+					if evt.Tool == nil {
+						evt.Kind = KindToolCallStart
+						evt.Tool = &ToolCall{ID: b.ID, Name: b.Name, Input: b.Input}
+					}
+				}
+			}
+			evt.Text = strings.Join(text, "\n")
 		}
 		return evt, nil
 	case "user":
@@ -165,5 +229,7 @@ func (claudeHarness) Capabilities() Capabilities {
 		SupportsSystemPrompt: true,
 		EmitsUsage:           true,
 		EmitsCost:            true,
+		PromptViaStdin:       true,
+		InteractiveInput:     true,
 	}
 }
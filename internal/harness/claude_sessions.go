@@ -0,0 +1,225 @@
+package harness
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/pkg/dircp"
+	"latere.ai/x/wallfacer/internal/pkg/ndjson"
+)
+
+// ErrClaudeSessionNotFound is returned when no transcript with the requested
+// session ID exists under the Claude config directory.
+var ErrClaudeSessionNotFound = errors.New("claude session not found")
+
+// claudeTranscriptMaxLine bounds a single transcript line. Tool results
+// embed whole files, so lines far exceed the scanner's 64 KB default.
+const claudeTranscriptMaxLine = 16 << 20
+
+// ClaudeSession describes a Claude Code session transcript found on disk.
+// The claude CLI writes one transcript per session to
+// <config>/projects/<slug>/<session-id>.jsonl, where slug is derived from
+// the directory the session was started in.
+type ClaudeSession struct {
+	ID string `json:"id"`
+	// Cwd is the directory the session was started in.
+	Cwd string `json:"cwd"`
+	// Summary is the title claude generated for the session, when present.
+	Summary string `json:"summary,omitempty"`
+	// Prompt is the first message the user typed.
+	Prompt string `json:"prompt"`
+	// LastMessage is the last text the assistant produced.
+	LastMessage string `json:"last_message,omitempty"`
+	// Turns counts the user messages in the session.
+	Turns      int       `json:"turns"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Path       string    `json:"-"`
+}
+
+// ClaudeConfigDir returns the directory the claude CLI keeps its state in:
+// $CLAUDE_CONFIG_DIR when set, else ~/.claude. It returns "" when neither
+// is available.
+func ClaudeConfigDir() string {
+	if v := strings.TrimSpace(os.Getenv("CLAUDE_CONFIG_DIR")); v != "" {
+		return v
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".claude")
+}
+
+// ClaudeProjectDir returns the directory holding the transcripts of sessions
+// started in cwd. claude resolves --resume only against this directory, so a
+// session is resumable only from the directory it was started in.
+func ClaudeProjectDir(configDir, cwd string) string {
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, filepath.Clean(cwd))
+	return filepath.Join(configDir, "projects", slug)
+}
+
+// ListClaudeSessions returns the sessions under configDir modified after
+// since, most recent first. A missing projects directory yields no sessions.
+func ListClaudeSessions(configDir string, since time.Time) ([]ClaudeSession, error) {
+	paths, err := filepath.Glob(filepath.Join(configDir, "projects", "*", "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var sessions []ClaudeSession
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.ModTime().Before(since) {
+			continue
+		}
+		s, err := readClaudeSession(path, info.ModTime())
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		// Transcripts with no user message are sub-agent sidechains or
+		// sessions that were opened and closed without a prompt.
+		if s.Prompt == "" {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	slices.SortFunc(sessions, func(a, b ClaudeSession) int {
+		return cmp.Or(b.ModifiedAt.Compare(a.ModifiedAt), strings.Compare(a.ID, b.ID))
+	})
+	return sessions, nil
+}
+
+// FindClaudeSession returns the session with id, searching every project
+// directory under configDir.
+func FindClaudeSession(configDir, id string) (ClaudeSession, error) {
+	if !validClaudeSessionID(id) {
+		return ClaudeSession{}, fmt.Errorf("invalid session id %q", id)
+	}
+	paths, err := filepath.Glob(filepath.Join(configDir, "projects", "*", id+".jsonl"))
+	if err != nil {
+		return ClaudeSession{}, err
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		return readClaudeSession(path, info.ModTime())
+	}
+	return ClaudeSession{}, ErrClaudeSessionNotFound
+}
+
+// LinkClaudeSession makes session id resumable from cwd by copying its
+// transcript into cwd's project directory when it is not already there.
+// Sessions adopted from a terminal were started in another directory than
+// the task worktree the agent later runs in.
+func LinkClaudeSession(configDir, id, cwd string) error {
+	if !validClaudeSessionID(id) {
+		return fmt.Errorf("invalid session id %q", id)
+	}
+	dst := filepath.Join(ClaudeProjectDir(configDir, cwd), id+".jsonl")
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+	s, err := FindClaudeSession(configDir, id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return err
+	}
+	return dircp.CopyFile(s.Path, dst, 0o600)
+}
+
+// validClaudeSessionID rejects IDs that could escape the projects directory
+// or act as glob patterns.
+func validClaudeSessionID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\*?[]`) && id != "." && id != ".."
+}
+
+// claudeTranscriptLine is the subset of a transcript line the importer reads.
+type claudeTranscriptLine struct {
+	Type        string `json:"type"`
+	Summary     string `json:"summary"`
+	Cwd         string `json:"cwd"`
+	IsMeta      bool   `json:"isMeta"`
+	IsSidechain bool   `json:"isSidechain"`
+	Message     *struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+func readClaudeSession(path string, modTime time.Time) (ClaudeSession, error) {
+	s := ClaudeSession{
+		ID:         strings.TrimSuffix(filepath.Base(path), ".jsonl"),
+		ModifiedAt: modTime,
+		Path:       path,
+	}
+	err := ndjson.ReadFileFunc(path, func(line claudeTranscriptLine) bool {
+		if line.Type == "summary" {
+			if line.Summary != "" {
+				s.Summary = line.Summary
+			}
+			return true
+		}
+		if line.IsSidechain || line.Message == nil {
+			return true
+		}
+		if s.Cwd == "" {
+			s.Cwd = line.Cwd
+		}
+		text := claudeMessageText(line.Message.Content)
+		switch line.Type {
+		case "user":
+			// Meta lines, slash-command echoes and tool results are not
+			// typed by the user.
+			if line.IsMeta || text == "" || strings.HasPrefix(text, "<") {
+				return true
+			}
+			s.Turns++
+			if s.Prompt == "" {
+				s.Prompt = text
+			}
+		case "assistant":
+			if text != "" {
+				s.LastMessage = text
+			}
+		}
+		return true
+	}, ndjson.WithBufferSize(64<<10, claudeTranscriptMaxLine))
+	return s, err
+}
+
+// claudeMessageText returns the text of a message's content, which is
+// either a plain string or a list of blocks of which only text blocks count.
+func claudeMessageText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" && strings.TrimSpace(b.Text) != "" {
+			parts = append(parts, strings.TrimSpace(b.Text))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,105 @@
+package harness
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTranscript writes a session transcript for cwd under configDir and
+// returns its path.
+func writeTranscript(t *testing.T, configDir, cwd, id string, lines ...string) string {
+	t.Helper()
+	dir := ClaudeProjectDir(configDir, cwd)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, id+".jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestClaudeProjectDir(t *testing.T) {
+	got := ClaudeProjectDir("/cfg", "/home/me/my.repo_x")
+	if want := "/cfg/projects/-home-me-my-repo-x"; got != want {
+		t.Errorf("ClaudeProjectDir = %q, want %q", got, want)
+	}
+}
+
+func TestListClaudeSessions(t *testing.T) {
+	cfg := t.TempDir()
+	writeTranscript(t, cfg, "/src/app", "s1",
+		`{"type":"summary","summary":"Fix login redirect"}`,
+		`{"type":"user","cwd":"/src/app","isMeta":true,"message":{"role":"user","content":"<local-command-caveat>"}}`,
+		`{"type":"user","cwd":"/src/app","message":{"role":"user","content":"fix the login redirect"}}`,
+		`{"type":"assistant","cwd":"/src/app","message":{"content":[{"type":"text","text":"Looking."},{"type":"tool_use","name":"Read"}]}}`,
+		`{"type":"user","cwd":"/src/app","message":{"content":[{"type":"tool_result","content":"file"}]}}`,
+		`{"type":"user","cwd":"/src/app","isSidechain":true,"message":{"content":"sub-agent task"}}`,
+		`{"type":"user","cwd":"/src/app","message":{"content":[{"type":"text","text":"also add a test"}]}}`,
+		`{"type":"assistant","cwd":"/src/app","message":{"content":[{"type":"text","text":"Done, test added."}]}}`,
+	)
+	writeTranscript(t, cfg, "/src/app", "empty", `{"type":"summary","summary":"nothing"}`)
+	old := writeTranscript(t, cfg, "/src/lib", "s0",
+		`{"type":"user","cwd":"/src/lib","message":{"content":"old work"}}`)
+	past := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := ListClaudeSessions(cfg, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "s1" || sessions[1].ID != "s0" {
+		t.Fatalf("sessions = %+v, want s1 then s0", sessions)
+	}
+	s := sessions[0]
+	if s.Cwd != "/src/app" || s.Summary != "Fix login redirect" || s.Prompt != "fix the login redirect" ||
+		s.LastMessage != "Done, test added." || s.Turns != 2 {
+		t.Errorf("session s1 = %+v", s)
+	}
+
+	recent, err := ListClaudeSessions(cfg, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0].ID != "s1" {
+		t.Errorf("recent sessions = %+v, want only s1", recent)
+	}
+
+	if missing, err := ListClaudeSessions(filepath.Join(cfg, "absent"), time.Time{}); err != nil || len(missing) != 0 {
+		t.Errorf("missing config dir: %v, %v", missing, err)
+	}
+}
+
+func TestLinkClaudeSession(t *testing.T) {
+	cfg := t.TempDir()
+	writeTranscript(t, cfg, "/src/app", "s1", `{"type":"user","cwd":"/src/app","message":{"content":"hi"}}`)
+
+	if err := LinkClaudeSession(cfg, "s1", "/worktrees/t1/app"); err != nil {
+		t.Fatal(err)
+	}
+	linked := filepath.Join(ClaudeProjectDir(cfg, "/worktrees/t1/app"), "s1.jsonl")
+	if data, err := os.ReadFile(linked); err != nil || !strings.Contains(string(data), `"hi"`) {
+		t.Fatalf("linked transcript: %q, %v", data, err)
+	}
+	// Linking again, or from the original directory, is a no-op.
+	if err := LinkClaudeSession(cfg, "s1", "/worktrees/t1/app"); err != nil {
+		t.Error(err)
+	}
+	if err := LinkClaudeSession(cfg, "s1", "/src/app"); err != nil {
+		t.Error(err)
+	}
+
+	if err := LinkClaudeSession(cfg, "nope", "/x"); !errors.Is(err, ErrClaudeSessionNotFound) {
+		t.Errorf("unknown session: err = %v, want ErrClaudeSessionNotFound", err)
+	}
+	if err := LinkClaudeSession(cfg, "../s1", "/x"); err == nil {
+		t.Error("path-like session id accepted")
+	}
+}
@@ -11,15 +11,17 @@ func TestClaude_BuildArgv_Basic(t *testing.T) {
 	if err != nil {
 		t.Fatalf("BuildArgv: %v", err)
 	}
-	if stdin != nil {
-		t.Errorf("stdin = %v, want nil", stdin)
+	if got := readStdin(t, stdin); got != "do the thing" {
+		t.Errorf("stdin = %q, want the prompt", got)
 	}
 
 	joined := strings.Join(argv, " ")
+	if strings.Contains(joined, "do the thing") {
+		t.Errorf("prompt leaked into argv: %v", argv)
+	}
 	mustContain := []string{
 		"--dangerously-skip-permissions",
-		"-p do the thing",
-		"--verbose --output-format stream-json",
+		"-p --verbose --output-format stream-json",
 	}
 	for _, want := range mustContain {
 		if !strings.Contains(joined, want) {
@@ -112,6 +114,20 @@ func TestClaude_ParseEvent_Assistant(t *testing.T) {
 	if evt.Model != "claude-opus-4-8" {
 		t.Errorf("Model = %q, want %q", evt.Model, "claude-opus-4-8")
 	}
+	if evt.Text != "hello" {
+		t.Errorf("Text = %q, want %q", evt.Text, "hello")
+	}
+}
+
+func TestClaude_ParseEvent_ToolUse(t *testing.T) {
+	raw := []byte(`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"go test ./..."}}]}}`)
+	evt, _ := claudeHarness{}.ParseEvent(raw)
+	if evt.Kind != KindToolCallStart {
+		t.Fatalf("Kind = %v, want KindToolCallStart", evt.Kind)
+	}
+	if evt.Tool == nil || evt.Tool.ID != "toolu_1" || evt.Tool.Name != "Bash" || string(evt.Tool.Input) != `{"command":"go test ./..."}` {
+		t.Errorf("Tool = %+v", evt.Tool)
+	}
 }
 
 func TestClaude_ParseEvent_NoModel(t *testing.T) {
@@ -169,3 +185,22 @@ func TestClaude_RegisteredAtInit(t *testing.T) {
 		t.Errorf("Lookup(Claude).ID() = %q, want %q", h.ID(), Claude)
 	}
 }
+
+func TestClaude_BuildArgv_Interactive(t *testing.T) {
+	h := claudeHarness{}
+	argv, stdin, err := h.BuildArgv(Request{Prompt: "do the thing", Interactive: true})
+	if err != nil {
+		t.Fatalf("BuildArgv: %v", err)
+	}
+	if !strings.Contains(strings.Join(argv, " "), "--input-format stream-json") {
+		t.Errorf("argv missing stream-json input: %v", argv)
+	}
+	want := `{"type":"user","message":{"role":"user","content":"do the thing"}}` + "\n"
+	if got := readStdin(t, stdin); got != want {
+		t.Errorf("stdin = %q, want %q", got, want)
+	}
+	if !h.Capabilities().InteractiveInput {
+		t.Error("claude should report the InteractiveInput capability")
+	}
+	var _ InputEncoder = h
+}
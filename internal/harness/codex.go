@@ -22,7 +22,11 @@ func (codexHarness) ID() ID { return Codex }
 //	codex exec --full-auto --sandbox workspace-write --skip-git-repo-check
 //	           --json --color never
 //	           [--model <model>]
-//	           <prompt>
+//	           - < <prompt>
+//
+// The trailing "-" tells codex exec to read the prompt from stdin, which is
+// returned as the stdin payload so large prompts stay clear of ARG_MAX and
+// out of ps output.
 //
 // SystemPrompt is prepended into the prompt since codex's exec subcommand
 // has no append-system-prompt equivalent. SessionID is ignored because
@@ -42,8 +46,8 @@ func (codexHarness) BuildArgv(req Request) ([]string, io.Reader, error) {
 
 	prompt := req.Prompt
 	prompt = prependSystemPrompt(prompt, req.SystemPrompt)
-	argv = append(argv, prompt)
-	return argv, nil, nil
+	argv = append(argv, "-")
+	return argv, strings.NewReader(prompt), nil
 }
 
 type codexUsageLine struct {
@@ -253,5 +257,6 @@ func (codexHarness) Capabilities() Capabilities {
 		SupportsSystemPrompt: false, // prepended into prompt instead
 		EmitsUsage:           true,
 		EmitsCost:            false, // surfaced via Anthropic-style total_cost_usd only when present
+		PromptViaStdin:       true,
 	}
 }
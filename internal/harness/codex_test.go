@@ -11,8 +11,8 @@ func TestCodex_BuildArgv_Basic(t *testing.T) {
 	if err != nil {
 		t.Fatalf("BuildArgv: %v", err)
 	}
-	if stdin != nil {
-		t.Errorf("stdin = %v, want nil", stdin)
+	if got := readStdin(t, stdin); got != "do it" {
+		t.Errorf("stdin = %q, want the prompt", got)
 	}
 
 	if argv[0] != "exec" {
@@ -32,13 +32,13 @@ func TestCodex_BuildArgv_Basic(t *testing.T) {
 			t.Errorf("argv missing %q: %v", want, argv)
 		}
 	}
-	if argv[len(argv)-1] != "do it" {
-		t.Errorf("prompt should be last arg, got %q", argv[len(argv)-1])
+	if argv[len(argv)-1] != "-" {
+		t.Errorf("last arg = %q, want - (prompt on stdin)", argv[len(argv)-1])
 	}
 }
 
 func TestCodex_BuildArgv_ModelAndSystemPrompt(t *testing.T) {
-	argv, _, _ := codexHarness{}.BuildArgv(Request{
+	argv, stdin, _ := codexHarness{}.BuildArgv(Request{
 		Prompt:       "task",
 		Model:        "gpt-5",
 		SystemPrompt: "be careful",
@@ -48,12 +48,13 @@ func TestCodex_BuildArgv_ModelAndSystemPrompt(t *testing.T) {
 	if !strings.Contains(joined, "--model gpt-5") {
 		t.Errorf("argv missing model: %v", argv)
 	}
-	// SystemPrompt prepended into prompt; the last arg holds the full thing.
-	if !strings.Contains(argv[len(argv)-1], "be careful") {
-		t.Errorf("system prompt should be prepended into prompt; got %q", argv[len(argv)-1])
+	// SystemPrompt prepended into prompt; stdin holds the full thing.
+	prompt := readStdin(t, stdin)
+	if !strings.Contains(prompt, "be careful") {
+		t.Errorf("system prompt should be prepended into prompt; got %q", prompt)
 	}
-	if !strings.Contains(argv[len(argv)-1], "task") {
-		t.Errorf("prompt body should be present; got %q", argv[len(argv)-1])
+	if !strings.Contains(prompt, "task") {
+		t.Errorf("prompt body should be present; got %q", prompt)
 	}
 }
 
@@ -1,5 +1,5 @@
 // Package harness defines the abstraction over coding-agent CLIs
-// (Claude Code, Codex, Cursor, OpenCode, Pi, …) that wallfacer drives.
+// (Claude Code, Codex, Cursor, Gemini CLI, OpenCode, Pi, …) that wallfacer drives.
 //
 // A [Harness] implementation owns three things: how to build the CLI's
 // argv from a canonical [Request], how to parse one line of its
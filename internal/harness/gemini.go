@@ -0,0 +1,200 @@
+package harness
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+func init() {
+	Register(&geminiHarness{})
+}
+
+// geminiHarness adapts the `gemini` CLI (Gemini CLI) to the canonical
+// Harness contract. Under --output-format stream-json gemini emits NDJSON
+// shaped {type, timestamp, ...}: init, message, tool_use, tool_result,
+// error, and a terminal result carrying status and token stats. Assistant
+// messages stream as deltas and the result line does not repeat the final
+// text, so the host launcher accumulates the deltas and adds the text to
+// the result line as "response" (the field gemini's one-shot json output
+// uses) before forwarding it.
+type geminiHarness struct{}
+
+// ID returns harness.Gemini.
+func (geminiHarness) ID() ID { return Gemini }
+
+// BuildArgv assembles the gemini argv for a Request:
+//
+//	gemini --output-format stream-json
+//	       [--model <model>] [--resume <id>]
+//	       --approval-mode default|auto_edit|yolo
+//	       < <prompt>
+//
+// gemini runs non-interactively when stdin is not a terminal and reads the
+// prompt from it, so the prompt is returned as the stdin payload. The
+// approval mode maps Permission: ReadOnly keeps the default mode (tools that
+// need approval are unavailable headless), Edit auto-approves edits, and
+// Full auto-approves every tool. SystemPrompt is prepended into the prompt:
+// gemini only takes a system prompt as a whole-file override through
+// GEMINI_SYSTEM_MD, which would replace rather than extend its own.
+func (geminiHarness) BuildArgv(req Request) ([]string, io.Reader, error) {
+	argv := []string{"--output-format", "stream-json"}
+	if req.Model != "" {
+		argv = append(argv, "--model", req.Model)
+	}
+	if req.SessionID != "" {
+		argv = append(argv, "--resume", req.SessionID)
+	}
+	switch req.Permission {
+	case PermissionReadOnly:
+		argv = append(argv, "--approval-mode", "default")
+	case PermissionEdit:
+		argv = append(argv, "--approval-mode", "auto_edit")
+	case PermissionFull:
+		argv = append(argv, "--approval-mode", "yolo")
+	}
+	prompt := prependSystemPrompt(req.Prompt, req.SystemPrompt)
+	return argv, strings.NewReader(prompt), nil
+}
+
+// geminiStats is the token accounting on gemini's terminal result line.
+type geminiStats struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	Cached       int `json:"cached"`
+}
+
+// geminiError is the error object on result and tool_result lines.
+type geminiError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// geminiLine captures the fields harness.Gemini sniffs from gemini's
+// stream-json output. Response is not native to the stream: the host
+// launcher sets it on the result line. Unknown fields are ignored.
+type geminiLine struct {
+	Type       string          `json:"type"`
+	SessionID  string          `json:"session_id"`
+	Model      string          `json:"model"`
+	Role       string          `json:"role"`
+	Content    string          `json:"content"`
+	ToolName   string          `json:"tool_name"`
+	ToolID     string          `json:"tool_id"`
+	Parameters json.RawMessage `json:"parameters"`
+	Status     string          `json:"status"`
+	Output     string          `json:"output"`
+	Severity   string          `json:"severity"`
+	Message    string          `json:"message"`
+	Error      *geminiError    `json:"error"`
+	Stats      *geminiStats    `json:"stats"`
+	Response   string          `json:"response"`
+}
+
+// geminiStopReasons maps gemini's terminal error types to the canonical
+// stop reasons the runner routes on. A turn cut off by the output token
+// limit auto-continues like Claude's max_tokens, and one that hit the
+// session turn limit waits for feedback; every other error fails the turn.
+var geminiStopReasons = map[string]string{
+	"MAX_TOKENS":            "max_tokens",
+	"FatalTurnLimitedError": "max_turns",
+}
+
+// ParseEvent maps one NDJSON line of gemini output to a canonical Event:
+// init → KindSystemInit, assistant message → KindAssistantText, tool_use →
+// KindToolCallStart, tool_result → KindToolCallEnd, error-severity error →
+// KindError, and result → KindResult (KindError when its status is not
+// success). User-message echoes, warnings, and future types yield
+// KindUnknown with Raw preserved.
+func (geminiHarness) ParseEvent(raw []byte) (Event, error) {
+	evt := Event{Raw: append([]byte(nil), raw...)}
+
+	var line geminiLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return evt, nil
+	}
+	evt.SessionID = line.SessionID
+
+	switch line.Type {
+	case "init":
+		evt.Kind = KindSystemInit
+		evt.Model = line.Model
+	case "message":
+		if line.Role == "assistant" {
+			evt.Kind = KindAssistantText
+			evt.Text = line.Content
+		}
+	case "tool_use":
+		evt.Kind = KindToolCallStart
+		evt.Tool = &ToolCall{ID: line.ToolID, Name: line.ToolName, Input: line.Parameters}
+	case "tool_result":
+		evt.Kind = KindToolCallEnd
+		tc := &ToolCall{ID: line.ToolID}
+		if line.Output != "" {
+			if out, err := json.Marshal(line.Output); err == nil {
+				tc.Output = out
+			}
+		}
+		if line.Status == "error" {
+			tc.Error = "tool call failed"
+			if line.Error != nil && line.Error.Message != "" {
+				tc.Error = line.Error.Message
+			}
+		}
+		evt.Tool = tc
+	case "error":
+		if line.Severity != "warning" {
+			evt.Kind = KindError
+			evt.Text = line.Message
+		}
+	case "result":
+		evt.Kind = KindResult
+		evt.Text = line.Response
+		evt.StopReason = "end_turn"
+		if line.Stats != nil {
+			evt.Usage = &Usage{
+				InputTokens:     line.Stats.InputTokens,
+				OutputTokens:    line.Stats.OutputTokens,
+				CacheReadTokens: line.Stats.Cached,
+			}
+		}
+		if line.Status != "" && line.Status != "success" {
+			evt.StopReason = "error_during_execution"
+			if line.Error != nil {
+				evt.Subtype = line.Error.Type
+				if reason, ok := geminiStopReasons[line.Error.Type]; ok {
+					evt.StopReason = reason
+					return evt, nil
+				}
+				if evt.Text == "" {
+					evt.Text = line.Error.Message
+				}
+			}
+			evt.Kind = KindError
+		}
+	}
+	return evt, nil
+}
+
+// AuthEnv populates the env vars gemini reads at startup. GEMINI_API_KEY is
+// the Gemini API credential; Vertex AI and Google-account sign-in are
+// configured by the gemini CLI itself and need nothing here.
+func (geminiHarness) AuthEnv(cfg AuthConfig) (map[string]string, error) {
+	env := map[string]string{}
+	if cfg.GeminiAPIKey != "" {
+		env["GEMINI_API_KEY"] = cfg.GeminiAPIKey
+	}
+	return env, nil
+}
+
+// Capabilities reports gemini's optional-feature matrix.
+func (geminiHarness) Capabilities() Capabilities {
+	return Capabilities{
+		SupportsResume:       true,
+		SupportsMCP:          false, // MCP servers live in gemini's settings.json, not argv
+		SupportsSystemPrompt: false, // prepended into prompt instead
+		EmitsUsage:           true,
+		EmitsCost:            false, // stats carry tokens only
+		PromptViaStdin:       true,
+	}
+}
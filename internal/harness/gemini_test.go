@@ -0,0 +1,127 @@
+package harness
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGemini_BuildArgv(t *testing.T) {
+	argv, stdin, err := geminiHarness{}.BuildArgv(Request{
+		Prompt:       "task",
+		Model:        "gemini-2.5-pro",
+		SessionID:    "sess-1",
+		SystemPrompt: "be careful",
+		Permission:   PermissionFull,
+	})
+	if err != nil {
+		t.Fatalf("BuildArgv: %v", err)
+	}
+	joined := strings.Join(argv, " ")
+	for _, want := range []string{
+		"--output-format stream-json",
+		"--model gemini-2.5-pro",
+		"--resume sess-1",
+		"--approval-mode yolo",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("argv missing %q: %v", want, argv)
+		}
+	}
+	if slices.Contains(argv, "task") {
+		t.Errorf("prompt should travel on stdin, not argv: %v", argv)
+	}
+	if got := readStdin(t, stdin); got != "be careful"+systemPromptSeparator+"task" {
+		t.Errorf("stdin = %q, want the system prompt prepended", got)
+	}
+}
+
+func TestGemini_BuildArgv_PermissionModes(t *testing.T) {
+	for perm, want := range map[Permission]string{
+		PermissionReadOnly: "--approval-mode default",
+		PermissionEdit:     "--approval-mode auto_edit",
+		PermissionFull:     "--approval-mode yolo",
+	} {
+		argv, _, _ := geminiHarness{}.BuildArgv(Request{Prompt: "x", Permission: perm})
+		if !strings.Contains(strings.Join(argv, " "), want) {
+			t.Errorf("permission %d: argv %v missing %q", perm, argv, want)
+		}
+	}
+}
+
+func TestGemini_ParseEvent_Stream(t *testing.T) {
+	cases := []struct {
+		raw  string
+		kind EventKind
+	}{
+		{`{"type":"init","session_id":"s1","model":"gemini-2.5-pro"}`, KindSystemInit},
+		{`{"type":"message","role":"user","content":"task"}`, KindUnknown},
+		{`{"type":"message","role":"assistant","content":"Hel","delta":true}`, KindAssistantText},
+		{`{"type":"tool_use","tool_name":"read_file","tool_id":"t1","parameters":{"path":"a.go"}}`, KindToolCallStart},
+		{`{"type":"tool_result","tool_id":"t1","status":"success","output":"package a"}`, KindToolCallEnd},
+		{`{"type":"error","severity":"warning","message":"loop detected"}`, KindUnknown},
+		{`{"type":"error","severity":"error","message":"quota exceeded"}`, KindError},
+		{`{"type":"future_event"}`, KindUnknown},
+	}
+	for _, c := range cases {
+		evt, err := geminiHarness{}.ParseEvent([]byte(c.raw))
+		if err != nil {
+			t.Fatalf("ParseEvent(%s): %v", c.raw, err)
+		}
+		if evt.Kind != c.kind {
+			t.Errorf("ParseEvent(%s).Kind = %v, want %v", c.raw, evt.Kind, c.kind)
+		}
+	}
+
+	evt, _ := geminiHarness{}.ParseEvent([]byte(`{"type":"init","session_id":"s1","model":"gemini-2.5-pro"}`))
+	if evt.SessionID != "s1" || evt.Model != "gemini-2.5-pro" {
+		t.Errorf("init = %+v", evt)
+	}
+	evt, _ = geminiHarness{}.ParseEvent([]byte(`{"type":"tool_result","tool_id":"t2","status":"error","error":{"type":"invalid_params","message":"no such file"}}`))
+	if evt.Tool == nil || evt.Tool.ID != "t2" || evt.Tool.Error != "no such file" {
+		t.Errorf("failed tool_result Tool = %+v", evt.Tool)
+	}
+}
+
+func TestGemini_ParseEvent_Result(t *testing.T) {
+	raw := []byte(`{"type":"result","status":"success","response":"done","stats":{"total_tokens":160,"input_tokens":100,"output_tokens":50,"cached":10}}`)
+	evt, _ := geminiHarness{}.ParseEvent(raw)
+	if evt.Kind != KindResult || evt.StopReason != "end_turn" || evt.Text != "done" {
+		t.Errorf("result = %+v", evt)
+	}
+	if evt.Usage == nil || evt.Usage.InputTokens != 100 || evt.Usage.OutputTokens != 50 || evt.Usage.CacheReadTokens != 10 {
+		t.Errorf("Usage = %+v", evt.Usage)
+	}
+
+	// A turn cut off by the token limit stays a result so the runner
+	// auto-continues it.
+	evt, _ = geminiHarness{}.ParseEvent([]byte(`{"type":"result","status":"error","error":{"type":"MAX_TOKENS","message":"output truncated"}}`))
+	if evt.Kind != KindResult || evt.StopReason != "max_tokens" {
+		t.Errorf("max tokens result = %+v", evt)
+	}
+
+	evt, _ = geminiHarness{}.ParseEvent([]byte(`{"type":"result","status":"error","error":{"type":"FatalAuthenticationError","message":"bad key"}}`))
+	if evt.Kind != KindError || evt.Subtype != "FatalAuthenticationError" || evt.Text != "bad key" {
+		t.Errorf("error result = %+v", evt)
+	}
+}
+
+func TestGemini_AuthEnv(t *testing.T) {
+	env, err := geminiHarness{}.AuthEnv(AuthConfig{GeminiAPIKey: "g-test"})
+	if err != nil {
+		t.Fatalf("AuthEnv: %v", err)
+	}
+	if env["GEMINI_API_KEY"] != "g-test" {
+		t.Errorf("AuthEnv = %v", env)
+	}
+}
+
+func TestGemini_RegisteredAtInit(t *testing.T) {
+	h, ok := Lookup(Gemini)
+	if !ok {
+		t.Fatal("Gemini not registered")
+	}
+	if h.ID() != Gemini || !h.Capabilities().PromptViaStdin {
+		t.Errorf("Lookup(Gemini) = %q %+v", h.ID(), h.Capabilities())
+	}
+}
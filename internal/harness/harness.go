@@ -14,6 +14,7 @@ const (
 	Claude   ID = "claude"
 	Codex    ID = "codex"
 	Cursor   ID = "cursor"
+	Gemini   ID = "gemini"
 	OpenCode ID = "opencode"
 	Pi       ID = "pi"
 
@@ -63,6 +64,7 @@ type Request struct {
 	MCPServers   []MCPServer
 	MaxTurns     int     // 0 ⇒ no cap
 	MaxCostUSD   float64 // 0 ⇒ no cap
+	Interactive  bool    // keep stdin open for input while running; only honoured with Capabilities.InteractiveInput
 }
 
 // Event is one canonical update from a harness's output stream.
@@ -0,0 +1,51 @@
+package harness
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// MaxArgPromptBytes bounds a prompt that travels as a single argv element.
+// Linux rejects any one argument longer than MAX_ARG_STRLEN (32 pages,
+// 128 KiB) with E2BIG, which surfaces as an opaque exec failure; the margin
+// below that leaves room for the truncation marker.
+const MaxArgPromptBytes = 120 * 1024
+
+// PromptLimit returns the largest Request.Prompt, in bytes, that h can be
+// launched with. maxBytes is the operator's overall budget (<= 0 means no
+// budget). Harnesses that report PromptViaStdin are bounded only by the
+// budget; the others pass the prompt as an argument, so the limit also
+// accounts for MaxArgPromptBytes and any system prompt prepended into it.
+func PromptLimit(h Harness, req Request, maxBytes int) int {
+	caps := h.Capabilities()
+	if caps.PromptViaStdin {
+		return maxBytes
+	}
+	limit := MaxArgPromptBytes
+	if !caps.SupportsSystemPrompt && req.SystemPrompt != "" {
+		limit -= len(req.SystemPrompt) + len(systemPromptSeparator)
+	}
+	if maxBytes > 0 && maxBytes < limit {
+		limit = maxBytes
+	}
+	return max(limit, 0)
+}
+
+// TruncatePrompt cuts prompt to at most limit bytes, ending it with a marker
+// that tells the agent how much was dropped. The cut never splits a UTF-8
+// sequence. It reports whether anything was removed; limit <= 0 disables
+// truncation.
+func TruncatePrompt(prompt string, limit int) (string, bool) {
+	if limit <= 0 || len(prompt) <= limit {
+		return prompt, false
+	}
+	marker := fmt.Sprintf("\n\n[prompt truncated to fit the %d-byte limit; the original was %d bytes]", limit, len(prompt))
+	if len(marker) > limit {
+		marker = ""
+	}
+	cut := limit - len(marker)
+	for cut > 0 && !utf8.RuneStart(prompt[cut]) {
+		cut--
+	}
+	return prompt[:cut] + marker, true
+}
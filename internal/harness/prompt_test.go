@@ -0,0 +1,85 @@
+package harness
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// readStdin drains a BuildArgv stdin payload; a nil reader reads as "".
+func readStdin(t *testing.T, r io.Reader) string {
+	t.Helper()
+	if r == nil {
+		return ""
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read stdin: %v", err)
+	}
+	return string(b)
+}
+
+func TestTruncatePrompt(t *testing.T) {
+	if got, cut := TruncatePrompt("short", 100); cut || got != "short" {
+		t.Errorf("under limit: got %q, %v", got, cut)
+	}
+	if got, cut := TruncatePrompt(strings.Repeat("x", 500), 0); cut || len(got) != 500 {
+		t.Errorf("limit 0 should disable truncation, got %d bytes", len(got))
+	}
+
+	long := strings.Repeat("é", 300) // 600 bytes of two-byte runes
+	got, cut := TruncatePrompt(long, 201)
+	if !cut {
+		t.Fatal("expected truncation")
+	}
+	if len(got) > 201 {
+		t.Errorf("len = %d, want <= 201", len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Error("truncation split a UTF-8 sequence")
+	}
+	if !strings.Contains(got, "[prompt truncated to fit the 201-byte limit; the original was 600 bytes]") {
+		t.Errorf("missing truncation marker: %q", got)
+	}
+}
+
+func TestPromptLimit(t *testing.T) {
+	claude, _ := Lookup(Claude)
+	cursor, _ := Lookup(Cursor)
+
+	if got := PromptLimit(claude, Request{}, 0); got != 0 {
+		t.Errorf("stdin harness without budget = %d, want 0 (unlimited)", got)
+	}
+	if got := PromptLimit(claude, Request{}, 1<<20); got != 1<<20 {
+		t.Errorf("stdin harness = %d, want the budget", got)
+	}
+	if got := PromptLimit(cursor, Request{}, 0); got != MaxArgPromptBytes {
+		t.Errorf("argv harness = %d, want MaxArgPromptBytes", got)
+	}
+	if got := PromptLimit(cursor, Request{}, 1000); got != 1000 {
+		t.Errorf("argv harness with small budget = %d, want 1000", got)
+	}
+	// Cursor prepends the system prompt into the same argument.
+	sys := strings.Repeat("s", 1000)
+	want := MaxArgPromptBytes - len(sys) - len(systemPromptSeparator)
+	if got := PromptLimit(cursor, Request{SystemPrompt: sys}, 0); got != want {
+		t.Errorf("argv harness with system prompt = %d, want %d", got, want)
+	}
+}
+
+func TestClaude_BuildArgv_LargePromptStaysOffArgv(t *testing.T) {
+	prompt := strings.Repeat("a", 2*MaxArgPromptBytes)
+	argv, stdin, err := claudeHarness{}.BuildArgv(Request{Prompt: prompt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range argv {
+		if len(a) > 1024 {
+			t.Fatalf("argv carries a %d-byte argument", len(a))
+		}
+	}
+	if got := readStdin(t, stdin); got != prompt {
+		t.Errorf("stdin carried %d bytes, want %d", len(got), len(prompt))
+	}
+}
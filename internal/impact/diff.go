@@ -0,0 +1,172 @@
+package impact
+
+import (
+	"cmp"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SymbolStatus says how a change touched a symbol's declaration.
+type SymbolStatus string
+
+// SymbolStatus constants.
+const (
+	// SymbolChanged marks a declaration line that was rewritten, e.g. a
+	// function whose parameters changed.
+	SymbolChanged SymbolStatus = "changed"
+	// SymbolRemoved marks a declaration that was deleted or renamed away.
+	SymbolRemoved SymbolStatus = "removed"
+)
+
+// Symbol is a declaration a diff touched.
+type Symbol struct {
+	Name   string       `json:"name"`
+	File   string       `json:"file"`           // repository-relative path
+	Line   int          `json:"line,omitempty"` // new-side declaration line; 0 when removed
+	Status SymbolStatus `json:"status"`
+}
+
+// declPatterns extract declared names by file extension. Each pattern's
+// last submatch is the name.
+var declPatterns = map[string][]*regexp.Regexp{
+	".go": {
+		regexp.MustCompile(`^func (?:\([^)]*\) )?([A-Za-z_]\w*)`),
+		regexp.MustCompile(`^type ([A-Za-z_]\w*)`),
+	},
+	".ts":  tsDeclPatterns,
+	".tsx": tsDeclPatterns,
+	".js":  tsDeclPatterns,
+	".jsx": tsDeclPatterns,
+	".mjs": tsDeclPatterns,
+	".py": {
+		regexp.MustCompile(`^\s*(?:async )?def ([A-Za-z_]\w*)`),
+		regexp.MustCompile(`^class ([A-Za-z_]\w*)`),
+	},
+}
+
+var tsDeclPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(?:export )?(?:default )?(?:async )?function\*? ?([A-Za-z_$][\w$]*)`),
+	regexp.MustCompile(`^(?:export )?(?:default )?(?:abstract )?(?:class|interface|type|enum) ([A-Za-z_$][\w$]*)`),
+	regexp.MustCompile(`^export (?:const|let|var) ([A-Za-z_$][\w$]*)`),
+}
+
+// hunkRe matches a unified diff hunk header and captures the new-side start.
+var hunkRe = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// patchChanges is what a unified diff says about the new side of each file.
+type patchChanges struct {
+	symbols []Symbol
+	// added holds the new-side line numbers the diff added, by file.
+	added map[string]map[int]bool
+}
+
+// ChangedSymbols returns the declarations a unified diff rewrote or removed
+// in Go, TypeScript, JavaScript, and Python files, in diff order. Symbols
+// that were only added are left out: nothing outside the change can refer
+// to them yet.
+func ChangedSymbols(patch string) []Symbol {
+	return parsePatch(patch).symbols
+}
+
+// parsePatch reads the symbols and added lines of a unified diff.
+func parsePatch(patch string) patchChanges {
+	pc := patchChanges{added: make(map[string]map[int]bool)}
+	type decl struct {
+		removed, added bool
+		line           int // new-side line of the first added declaration
+	}
+	var (
+		file, oldFile string
+		newLine       int
+		inHunk        bool
+		decls         = map[string]*decl{}
+		order         []string
+	)
+	flush := func() {
+		for _, name := range order {
+			switch d := decls[name]; {
+			case d.removed && d.added:
+				pc.symbols = append(pc.symbols, Symbol{Name: name, File: file, Line: d.line, Status: SymbolChanged})
+			case d.removed:
+				pc.symbols = append(pc.symbols, Symbol{Name: name, File: cmp.Or(file, oldFile), Status: SymbolRemoved})
+			}
+		}
+		decls, order = map[string]*decl{}, nil
+	}
+	note := func(text string, line int) {
+		name := declName(cmp.Or(file, oldFile), text)
+		if name == "" {
+			return
+		}
+		d := decls[name]
+		if d == nil {
+			d = &decl{}
+			decls[name] = d
+			order = append(order, name)
+		}
+		if line == 0 {
+			d.removed = true
+		} else if !d.added {
+			d.added, d.line = true, line
+		}
+	}
+	for line := range strings.SplitSeq(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			file, oldFile, inHunk = "", "", false
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			oldFile = patchPath(line[4:])
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			file = patchPath(line[4:])
+		case strings.HasPrefix(line, "@@"):
+			m := hunkRe.FindStringSubmatch(line)
+			inHunk = m != nil
+			if inHunk {
+				newLine, _ = strconv.Atoi(m[1])
+			}
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			if pc.added[file] == nil {
+				pc.added[file] = make(map[int]bool)
+			}
+			pc.added[file][newLine] = true
+			note(line[1:], newLine)
+			newLine++
+		case strings.HasPrefix(line, "-"):
+			note(line[1:], 0)
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		default:
+			newLine++
+		}
+	}
+	flush()
+	return pc
+}
+
+// patchPath strips the a/ or b/ prefix from a diff header path and returns
+// "" for /dev/null.
+func patchPath(p string) string {
+	p, _, _ = strings.Cut(p, "\t")
+	if p == "/dev/null" {
+		return ""
+	}
+	if len(p) > 2 && (p[:2] == "a/" || p[:2] == "b/") {
+		return p[2:]
+	}
+	return p
+}
+
+// declName returns the name declared on line, or "" when line declares
+// nothing in a language ChangedSymbols reads.
+func declName(file, line string) string {
+	for _, re := range declPatterns[path.Ext(file)] {
+		if m := re.FindStringSubmatch(line); m != nil {
+			return m[len(m)-1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package impact
+
+import (
+	"reflect"
+	"testing"
+)
+
+const samplePatch = `diff --git a/greet.go b/greet.go
+index 1111111..2222222 100644
+--- a/greet.go
++++ b/greet.go
+@@ -1,7 +1,8 @@
+ package greet
+ 
+-func Greet(name string) string {
++func Greet(name, greeting string) string {
+ 	return "hi " + name
+ }
+ 
+-type Old struct{}
++// Fresh is new.
++func Fresh() {}
+diff --git a/web/app.ts b/web/app.ts
+index 3333333..4444444 100644
+--- a/web/app.ts
++++ b/web/app.ts
+@@ -10,3 +10,3 @@ import { x } from './x';
+ 
+-export function render(el: Element) {
++export function render(el: Element, opts: Opts) {
+ }
+diff --git a/notes.txt b/notes.txt
+--- a/notes.txt
++++ b/notes.txt
+@@ -1 +1 @@
+-func Ignored() {}
++func Ignored(x int) {}
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+--- a/gone.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package greet
+-func Gone() {}
+`
+
+func TestChangedSymbols(t *testing.T) {
+	want := []Symbol{
+		{Name: "Greet", File: "greet.go", Line: 3, Status: SymbolChanged},
+		{Name: "Old", File: "greet.go", Status: SymbolRemoved},
+		{Name: "render", File: "web/app.ts", Line: 11, Status: SymbolChanged},
+		{Name: "Gone", File: "gone.go", Status: SymbolRemoved},
+	}
+	if got := ChangedSymbols(samplePatch); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedSymbols =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParsePatch_AddedLines(t *testing.T) {
+	added := parsePatch(samplePatch).added
+	for file, lines := range map[string][]int{"greet.go": {3, 7, 8}, "web/app.ts": {11}} {
+		if len(added[file]) != len(lines) {
+			t.Errorf("%s: added = %v, want %v", file, added[file], lines)
+		}
+		for _, n := range lines {
+			if !added[file][n] {
+				t.Errorf("%s: line %d not marked added", file, n)
+			}
+		}
+	}
+}
+
+func TestDeclName(t *testing.T) {
+	tests := []struct{ file, line, want string }{
+		{"a.go", "func (s *Server) Start(ctx context.Context) error {", "Start"},
+		{"a.go", "type Config struct {", "Config"},
+		{"a.go", "\treturn Start()", ""},
+		{"a.ts", "export default async function load() {", "load"},
+		{"a.tsx", "export interface Props {", "Props"},
+		{"a.js", "export const API_URL = '/api';", "API_URL"},
+		{"a.py", "    async def fetch(self):", "fetch"},
+		{"a.rb", "def fetch", ""},
+	}
+	for _, tt := range tests {
+		if got := declName(tt.file, tt.line); got != tt.want {
+			t.Errorf("declName(%q, %q) = %q, want %q", tt.file, tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Package impact finds the references to symbols a change touched that the
+// change itself did not update: call sites of a function whose signature
+// changed, uses of a type that was renamed or removed. Reviewers read the
+// result next to a task's diff to spot the places the agent forgot.
+//
+// [ChangedSymbols] reads the declarations added or removed in a unified
+// diff. [Analyze] looks up each symbol's references in the task's checkout
+// with a host-side language server when one is installed (gopls for Go,
+// tsserver for TypeScript and JavaScript) and with a whole-word git grep
+// otherwise, then drops the references on lines the diff added.
+//
+// # Connected packages
+//
+// Uses [cmdexec] to run git and gopls. Consumed by [handler] (GET
+// /api/tasks/{id}/impact).
+//
+// # Usage
+//
+//	symbols := impact.ChangedSymbols(patch)
+//	report := impact.Analyze(ctx, worktree, patch, impact.LookupTools())
+package impact
@@ -0,0 +1,243 @@
+package impact
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+)
+
+// MaxSymbols caps the symbols Analyze looks up per diff.
+const MaxSymbols = 50
+
+// MaxReferences caps the references reported per symbol.
+const MaxReferences = 50
+
+// Source names the tool that found a symbol's references.
+type Source string
+
+// Source constants.
+const (
+	SourceGopls    Source = "gopls"
+	SourceTsserver Source = "tsserver"
+	SourceGrep     Source = "grep" // whole-word git grep; may include same-named symbols
+)
+
+// Tools holds the language servers Analyze may run. An empty path means the
+// server is not installed and references fall back to git grep.
+type Tools struct {
+	Gopls    string
+	Tsserver string
+}
+
+// LookupTools finds gopls and tsserver on $PATH.
+func LookupTools() Tools {
+	var t Tools
+	if p, err := exec.LookPath("gopls"); err == nil {
+		t.Gopls = p
+	}
+	if p, err := exec.LookPath("tsserver"); err == nil {
+		t.Tsserver = p
+	}
+	return t
+}
+
+// Reference is a use of a symbol outside the lines the diff added.
+type Reference struct {
+	File string `json:"file"` // repository-relative path
+	Line int    `json:"line"`
+	Text string `json:"text"` // the referencing line, trimmed
+}
+
+// SymbolImpact is a changed symbol with the references the change did not
+// touch.
+type SymbolImpact struct {
+	Symbol
+	Source     Source      `json:"source"`
+	References []Reference `json:"references"`
+	Truncated  bool        `json:"truncated,omitempty"` // more than MaxReferences were found
+}
+
+// Analyze finds, in the checkout at dir, the references to each symbol
+// patch rewrote or removed that lie outside the lines patch added. patch
+// must be a diff of dir's working tree, so its new-side line numbers match
+// the files on disk. Changed Go symbols are looked up with gopls and
+// changed TypeScript and JavaScript symbols with tsserver when tools names
+// them; removed symbols, other languages, and language-server failures use
+// a whole-word git grep. At most MaxSymbols symbols are analyzed.
+func Analyze(ctx context.Context, dir, patch string, tools Tools) []SymbolImpact {
+	pc := parsePatch(patch)
+	symbols := pc.symbols[:min(len(pc.symbols), MaxSymbols)]
+	var ts *tsserver
+	defer func() {
+		if ts != nil {
+			ts.close()
+		}
+	}()
+
+	out := make([]SymbolImpact, 0, len(symbols))
+	lines := fileLines{dir: dir}
+	for _, sym := range symbols {
+		var (
+			refs   []Reference
+			source Source
+			err    error = errNoServer
+		)
+		col := lines.column(sym.File, sym.Line, sym.Name)
+		switch ext := path.Ext(sym.File); {
+		case col == 0:
+		case ext == ".go" && tools.Gopls != "":
+			source = SourceGopls
+			refs, err = goplsReferences(ctx, tools.Gopls, filepath.Join(dir, sym.File), sym.Line, col)
+		case isTypeScript(ext) && tools.Tsserver != "":
+			if ts == nil {
+				if ts, err = startTsserver(ctx, tools.Tsserver, dir); err != nil {
+					tools.Tsserver = "" // do not retry for every symbol
+				}
+			}
+			if ts != nil {
+				source = SourceTsserver
+				refs, err = ts.references(filepath.Join(dir, sym.File), sym.Line, col)
+			}
+		}
+		if err != nil {
+			source = SourceGrep
+			refs = grepReferences(ctx, dir, sym.Name)
+		}
+
+		si := SymbolImpact{Symbol: sym, Source: source, References: []Reference{}}
+		for _, ref := range refs {
+			if rel, ok := relPath(dir, ref.File); ok {
+				ref.File = rel
+			} else {
+				continue // outside the checkout, e.g. the module cache
+			}
+			if pc.added[ref.File][ref.Line] {
+				continue
+			}
+			if ref.Text == "" {
+				ref.Text = lines.line(ref.File, ref.Line)
+			}
+			ref.Text = strings.TrimSpace(ref.Text)
+			si.References = append(si.References, ref)
+		}
+		slices.SortFunc(si.References, func(a, b Reference) int {
+			return cmp.Or(strings.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+		})
+		si.References = slices.CompactFunc(si.References, func(a, b Reference) bool { return a.File == b.File && a.Line == b.Line })
+		if len(si.References) > MaxReferences {
+			si.References, si.Truncated = si.References[:MaxReferences], true
+		}
+		out = append(out, si)
+	}
+	return out
+}
+
+// grepReferences lists the whole-word occurrences of name in dir's tracked
+// and untracked files.
+func grepReferences(ctx context.Context, dir, name string) []Reference {
+	out, err := cmdexec.Git(dir, "grep", "-z", "-n", "-w", "-F", "-I", "--untracked", "-e", name).WithContext(ctx).OutputBytes()
+	if err != nil {
+		return nil // exit status 1: no matches
+	}
+	var refs []Reference
+	for line := range strings.SplitSeq(string(out), "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		refs = append(refs, Reference{File: parts[0], Line: n, Text: parts[2]})
+	}
+	return refs
+}
+
+// relPath returns file relative to dir. Absolute paths come from language
+// servers, which report resolved paths, so dir's resolved form is tried
+// too. It reports false for files outside dir.
+func relPath(dir, file string) (string, bool) {
+	if !filepath.IsAbs(file) {
+		return filepath.ToSlash(file), true
+	}
+	roots := []string{dir}
+	if real, err := filepath.EvalSymlinks(dir); err == nil && real != dir {
+		roots = append(roots, real)
+	}
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, file)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel), true
+		}
+	}
+	return "", false
+}
+
+func isTypeScript(ext string) bool {
+	switch ext {
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs":
+		return true
+	}
+	return false
+}
+
+// fileLines reads and caches the lines of files under dir.
+type fileLines struct {
+	dir   string
+	files map[string][]string
+}
+
+// line returns line n (1-based) of file, or "" when it does not exist.
+func (f *fileLines) line(file string, n int) string {
+	if f.files == nil {
+		f.files = make(map[string][]string)
+	}
+	lines, ok := f.files[file]
+	if !ok {
+		if fh, err := os.Open(filepath.Join(f.dir, file)); err == nil {
+			sc := bufio.NewScanner(fh)
+			sc.Buffer(nil, 1<<20)
+			for sc.Scan() {
+				lines = append(lines, sc.Text())
+			}
+			_ = fh.Close()
+		}
+		f.files[file] = lines
+	}
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	return lines[n-1]
+}
+
+// column returns the 1-based byte column of name's first whole-word
+// occurrence on line n of file, or 0 when it is not there, as for a
+// removed symbol.
+func (f *fileLines) column(file string, n int, name string) int {
+	text := f.line(file, n)
+	for off := 0; ; {
+		i := strings.Index(text[off:], name)
+		if i < 0 {
+			return 0
+		}
+		start, end := off+i, off+i+len(name)
+		if (start == 0 || !isWordByte(text[start-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return start + 1
+		}
+		off = end
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b == '$' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
@@ -0,0 +1,143 @@
+package impact
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// changedRepo commits a small Go package, then changes Greet's signature,
+// updates one of its two callers, and removes the Old type, leaving the
+// changes uncommitted. It returns the checkout and its diff.
+func changedRepo(t *testing.T) (dir, patch string) {
+	t.Helper()
+	dir = t.TempDir()
+	gitRun(t, dir, "init", "-q", "-b", "main")
+	gitRun(t, dir, "config", "user.email", "test@example.com")
+	gitRun(t, dir, "config", "user.name", "Test")
+	writeFile(t, filepath.Join(dir, "greet.go"), "package greet\n\nfunc Greet(name string) string {\n\treturn \"hi \" + name\n}\n\ntype Old struct{}\n")
+	writeFile(t, filepath.Join(dir, "a.go"), "package greet\n\nfunc a() string {\n\treturn Greet(\"a\")\n}\n")
+	writeFile(t, filepath.Join(dir, "b.go"), "package greet\n\nvar _ Old\n\nfunc b() string {\n\treturn Greet(\"b\")\n}\n")
+	gitRun(t, dir, "add", "-A")
+	gitRun(t, dir, "commit", "-q", "-m", "initial")
+
+	writeFile(t, filepath.Join(dir, "greet.go"), "package greet\n\nfunc Greet(name, greeting string) string {\n\treturn greeting + \" \" + name\n}\n")
+	writeFile(t, filepath.Join(dir, "a.go"), "package greet\n\nfunc a() string {\n\treturn Greet(\"a\", \"hello\")\n}\n")
+	return dir, gitRun(t, dir, "diff")
+}
+
+func TestAnalyze_GrepFallback(t *testing.T) {
+	dir, patch := changedRepo(t)
+	got := Analyze(context.Background(), dir, patch, Tools{})
+	if len(got) != 2 {
+		t.Fatalf("got %d symbols, want Greet and Old: %+v", len(got), got)
+	}
+	greet, old := got[0], got[1]
+	if greet.Name != "Greet" || greet.Status != SymbolChanged || greet.Source != SourceGrep {
+		t.Errorf("Greet = %+v", greet)
+	}
+	// a.go's call was updated by the diff; only b.go's is left.
+	if want := []Reference{{File: "b.go", Line: 6, Text: `return Greet("b")`}}; fmt.Sprint(greet.References) != fmt.Sprint(want) {
+		t.Errorf("Greet references = %+v, want %+v", greet.References, want)
+	}
+	if old.Name != "Old" || old.Status != SymbolRemoved || len(old.References) != 1 || old.References[0].File != "b.go" || old.References[0].Line != 3 {
+		t.Errorf("Old = %+v", old)
+	}
+}
+
+func TestAnalyze_Gopls(t *testing.T) {
+	dir, patch := changedRepo(t)
+	// The stand-in reports the updated call site, the forgotten one, and a
+	// location outside the checkout; only the forgotten one survives.
+	gopls := filepath.Join(t.TempDir(), "gopls")
+	writeFile(t, gopls, fmt.Sprintf("#!/bin/sh\n[ \"$1 $2\" = \"references %s:3:6\" ] || exit 2\necho %s:4:9-14\necho %s:6:9-14\necho /go/pkg/mod/x.go:1:1-6\n",
+		filepath.Join(dir, "greet.go"), filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")))
+
+	got := Analyze(context.Background(), dir, patch, Tools{Gopls: gopls})
+	greet := got[0]
+	if greet.Source != SourceGopls {
+		t.Fatalf("Greet source = %q, want gopls", greet.Source)
+	}
+	if len(greet.References) != 1 || greet.References[0].File != "b.go" || greet.References[0].Text != `return Greet("b")` {
+		t.Errorf("Greet references = %+v", greet.References)
+	}
+	// Removed symbols have no declaration left to ask gopls about.
+	if got[1].Source != SourceGrep {
+		t.Errorf("Old source = %q, want grep", got[1].Source)
+	}
+}
+
+func TestAnalyze_GoplsFailureFallsBackToGrep(t *testing.T) {
+	dir, patch := changedRepo(t)
+	gopls := filepath.Join(t.TempDir(), "gopls")
+	writeFile(t, gopls, "#!/bin/sh\necho 'no packages' >&2\nexit 1\n")
+
+	greet := Analyze(context.Background(), dir, patch, Tools{Gopls: gopls})[0]
+	if greet.Source != SourceGrep || len(greet.References) != 1 {
+		t.Errorf("Greet = %+v", greet)
+	}
+}
+
+func TestAnalyze_Tsserver(t *testing.T) {
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(dir, "app.ts"), "export function render(el: Element) {\n}\n")
+	gitRun(t, dir, "add", "-A")
+	gitRun(t, dir, "-c", "user.email=t@example.com", "-c", "user.name=T", "commit", "-q", "-m", "initial")
+	writeFile(t, filepath.Join(dir, "app.ts"), "export function render(el: Element, opts: object) {\n}\n")
+	patch := gitRun(t, dir, "diff")
+
+	// The stand-in answers the references request (seq 2, after the open
+	// notification) after an unrelated event.
+	body := fmt.Sprintf(`{"type":"response","request_seq":2,"success":true,"body":{"refs":[`+
+		`{"file":%[1]q,"start":{"line":1,"offset":17},"lineText":"export function render(","isDefinition":true},`+
+		`{"file":%[2]q,"start":{"line":3,"offset":1},"lineText":"render(document.body);","isDefinition":false}]}}`,
+		filepath.Join(dir, "app.ts"), filepath.Join(dir, "main.ts"))
+	event := `{"type":"event","event":"projectLoadingStart"}`
+	tsserver := filepath.Join(t.TempDir(), "tsserver")
+	writeFile(t, tsserver, fmt.Sprintf("#!/bin/sh\nwhile read -r line; do\n  case \"$line\" in\n    *'\"line\":1,\"offset\":17'*'\"command\":\"references\"'*)\n      printf 'Content-Length: %d\\r\\n\\r\\n%%s\\n' '%s'\n      printf 'Content-Length: %d\\r\\n\\r\\n%%s\\n' '%s';;\n  esac\ndone\n",
+		len(event)+1, event, len(body)+1, body))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	got := Analyze(ctx, dir, patch, Tools{Tsserver: tsserver})
+	if len(got) != 1 || got[0].Source != SourceTsserver {
+		t.Fatalf("got %+v", got)
+	}
+	if refs := got[0].References; len(refs) != 1 || refs[0].File != "main.ts" || refs[0].Line != 3 || refs[0].Text != "render(document.body);" {
+		t.Errorf("references = %+v", refs)
+	}
+}
+
+func TestAnalyze_TruncatesReferences(t *testing.T) {
+	dir, patch := changedRepo(t)
+	writeFile(t, filepath.Join(dir, "many.go"), "package greet\n\n"+strings.Repeat("var _ = Greet(\"x\")\n", MaxReferences+5))
+	greet := Analyze(context.Background(), dir, patch, Tools{})[0]
+	if len(greet.References) != MaxReferences || !greet.Truncated {
+		t.Errorf("got %d references, truncated %v", len(greet.References), greet.Truncated)
+	}
+}
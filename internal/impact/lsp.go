@@ -0,0 +1,184 @@
+package impact
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+)
+
+// errNoServer marks a symbol no language server was asked about.
+var errNoServer = errors.New("no language server")
+
+// goplsLocRe matches one location printed by `gopls references`:
+// path:line:col or path:line:col-endcol.
+var goplsLocRe = regexp.MustCompile(`^(.+):(\d+):\d+(?:-\d+)?$`)
+
+// goplsReferences runs `gopls references` for the identifier at line:col
+// of file. The declaration itself is not reported.
+func goplsReferences(ctx context.Context, gopls, file string, line, col int) ([]Reference, error) {
+	out, err := cmdexec.New(gopls, "references", fmt.Sprintf("%s:%d:%d", file, line, col)).WithContext(ctx).Output()
+	if err != nil {
+		return nil, fmt.Errorf("gopls references: %w", err)
+	}
+	var refs []Reference
+	for loc := range strings.SplitSeq(out, "\n") {
+		m := goplsLocRe.FindStringSubmatch(strings.TrimSpace(loc))
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[2])
+		refs = append(refs, Reference{File: m[1], Line: n})
+	}
+	return refs, nil
+}
+
+// tsserver is a session with the TypeScript server, speaking its JSON
+// protocol: one request per line on stdin, Content-Length framed messages
+// on stdout.
+type tsserver struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	seq    int
+	opened map[string]bool
+}
+
+// startTsserver starts tsserver in dir, which it uses to find the
+// project's tsconfig.json or jsconfig.json.
+func startTsserver(ctx context.Context, bin, dir string) (*tsserver, error) {
+	cmd := exec.CommandContext(ctx, bin, "--disableAutomaticTypingAcquisition")
+	cmd.Dir = dir
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start tsserver: %w", err)
+	}
+	return &tsserver{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout), opened: make(map[string]bool)}, nil
+}
+
+// tsLocation is a location in a tsserver response.
+type tsLocation struct {
+	Line   int `json:"line"`
+	Offset int `json:"offset"`
+}
+
+// references asks tsserver for the references to the identifier at
+// line:offset of file, opening the file first. The declaration itself is
+// not reported.
+func (ts *tsserver) references(file string, line, offset int) ([]Reference, error) {
+	if !ts.opened[file] {
+		// open is a notification: tsserver sends no response to it.
+		if _, err := ts.send("open", map[string]any{"file": file}); err != nil {
+			return nil, err
+		}
+		ts.opened[file] = true
+	}
+	seq, err := ts.send("references", map[string]any{"file": file, "line": line, "offset": offset})
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Refs []struct {
+			File         string     `json:"file"`
+			Start        tsLocation `json:"start"`
+			LineText     string     `json:"lineText"`
+			IsDefinition bool       `json:"isDefinition"`
+		} `json:"refs"`
+	}
+	if err := ts.await(seq, &body); err != nil {
+		return nil, err
+	}
+	var refs []Reference
+	for _, r := range body.Refs {
+		if !r.IsDefinition {
+			refs = append(refs, Reference{File: r.File, Line: r.Start.Line, Text: r.LineText})
+		}
+	}
+	return refs, nil
+}
+
+// send writes one request and returns its sequence number.
+func (ts *tsserver) send(command string, args any) (int, error) {
+	ts.seq++
+	req, err := json.Marshal(map[string]any{"seq": ts.seq, "type": "request", "command": command, "arguments": args})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := ts.stdin.Write(append(req, '\n')); err != nil {
+		return 0, fmt.Errorf("tsserver %s: %w", command, err)
+	}
+	return ts.seq, nil
+}
+
+// await reads messages until the response to request seq, skipping
+// events, and decodes its body into v.
+func (ts *tsserver) await(seq int, v any) error {
+	for {
+		msg, err := ts.read()
+		if err != nil {
+			return fmt.Errorf("tsserver: %w", err)
+		}
+		var resp struct {
+			Type       string          `json:"type"`
+			RequestSeq int             `json:"request_seq"`
+			Success    bool            `json:"success"`
+			Message    string          `json:"message"`
+			Body       json.RawMessage `json:"body"`
+		}
+		if json.Unmarshal(msg, &resp) != nil || resp.Type != "response" || resp.RequestSeq != seq {
+			continue
+		}
+		if !resp.Success {
+			return fmt.Errorf("tsserver: %s", resp.Message)
+		}
+		return json.Unmarshal(resp.Body, v)
+	}
+}
+
+// read returns the next Content-Length framed message.
+func (ts *tsserver) read() ([]byte, error) {
+	length := -1
+	for {
+		line, err := ts.stdout.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if v, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+			if length, err = strconv.Atoi(strings.TrimSpace(v)); err != nil {
+				return nil, fmt.Errorf("bad header %q", line)
+			}
+			continue
+		}
+		if line == "" && length >= 0 {
+			break
+		}
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(ts.stdout, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// close stops the server.
+func (ts *tsserver) close() {
+	_ = ts.stdin.Close()
+	_ = ts.cmd.Process.Kill()
+	_ = ts.cmd.Wait()
+}
@@ -0,0 +1,197 @@
+// Package intake turns inbound messages into backlog tasks for people who
+// do not use the board directly. An email, read from a mailbox by
+// [Mailbox.FetchUnseen] or posted raw to the intake endpoint, is parsed by
+// [ParseEmail] into a [Message]: its subject becomes the task title, its
+// text body the prompt, and its attached files the task's attachments.
+// Webhook payloads (forms, Slack slash commands) are mapped onto the same
+// Message by the HTTP handler.
+package intake
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+// Message is one inbound request for work.
+type Message struct {
+	Title       string   // email subject or form title; empty lets the board generate one
+	Body        string   // task prompt
+	From        string   // sender address or user name, for the audit trail
+	Tags        []string // extra task tags; set by webhooks, never by ParseEmail
+	Attachments []File
+}
+
+// File is one file attached to a Message.
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// ErrEmptyMessage is returned by [ParseEmail] when a message has neither
+// a text body nor a subject to use as the prompt.
+var ErrEmptyMessage = errors.New("intake: message has no text")
+
+// AllowedSender reports whether an email from the address from may be
+// filed: allowed lists addresses, and "@domain" entries that admit every
+// address of a domain. Matching ignores case. An empty list admits no one.
+func AllowedSender(from string, allowed []string) bool {
+	from = strings.ToLower(strings.TrimSpace(from))
+	at := strings.LastIndexByte(from, '@')
+	if at <= 0 {
+		return false
+	}
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == from || (strings.HasPrefix(a, "@") && a == from[at:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxMIMEDepth bounds the nesting of multipart bodies ParseEmail follows.
+const maxMIMEDepth = 8
+
+// ParseEmail parses a raw RFC 5322 message. The first text/plain part is
+// the body, falling back to the first text/html part with its tags
+// stripped; parts with a file name or an attachment disposition become
+// Attachments. A message with no body uses its subject as the prompt.
+func ParseEmail(raw []byte) (*Message, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("intake: parse email: %w", err)
+	}
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		subject = m.Header.Get("Subject")
+	}
+	msg := &Message{Title: strings.TrimSpace(subject)}
+	if from, err := mail.ParseAddress(m.Header.Get("From")); err == nil {
+		msg.From = from.Address
+	}
+
+	var plain, htmlBody string
+	err = walkPart(textproto.MIMEHeader(m.Header), m.Body, 0, func(h textproto.MIMEHeader, data []byte) {
+		mediaType, params, _ := mime.ParseMediaType(h.Get("Content-Type"))
+		disposition, dparams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+		name := cmp.Or(dparams["filename"], params["name"])
+		switch {
+		case disposition == "attachment" || name != "":
+			if n, err := dec.DecodeHeader(name); err == nil {
+				name = n
+			}
+			msg.Attachments = append(msg.Attachments, File{Name: name, ContentType: mediaType, Data: data})
+		case (mediaType == "text/plain" || mediaType == "") && plain == "":
+			plain = string(data)
+		case mediaType == "text/html" && htmlBody == "":
+			htmlBody = htmlToText(string(data))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	msg.Body = strings.TrimSpace(normalizeNewlines(cmp.Or(plain, htmlBody)))
+	if msg.Body == "" {
+		msg.Body = msg.Title
+	}
+	if msg.Body == "" {
+		return nil, ErrEmptyMessage
+	}
+	return msg, nil
+}
+
+// walkPart calls leaf with the decoded content of every non-multipart part
+// under body, depth first.
+func walkPart(h textproto.MIMEHeader, body io.Reader, depth int, leaf func(textproto.MIMEHeader, []byte)) error {
+	mediaType, params, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMIMEDepth {
+			return fmt.Errorf("intake: MIME nesting deeper than %d", maxMIMEDepth)
+		}
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("intake: read MIME part: %w", err)
+			}
+			if err := walkPart(p.Header, p, depth+1, leaf); err != nil {
+				return err
+			}
+		}
+	}
+	data, err := io.ReadAll(decodeTransfer(h.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("intake: decode %s part: %w", cmp.Or(mediaType, "text/plain"), err)
+	}
+	leaf(h, data)
+	return nil
+}
+
+// decodeTransfer undoes a part's Content-Transfer-Encoding. multipart.Part
+// already decodes quoted-printable and drops the header, so only the
+// top-level body reaches the quoted-printable branch.
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &stripSpace{r: r})
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// stripSpace drops the line breaks and spaces base64 bodies are wrapped
+// with, which base64.NewDecoder does not tolerate in every position.
+type stripSpace struct{ r io.Reader }
+
+func (s *stripSpace) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	out := p[:0]
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+			out = append(out, b)
+		}
+	}
+	return len(out), err
+}
+
+var (
+	htmlBlockRe = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6]|/tr)\s*/?>`)
+	htmlDropRe  = regexp.MustCompile(`(?is)<(style|script|head)\b.*?</(style|script|head)>`)
+	htmlTagRe   = regexp.MustCompile(`<[^>]*>`)
+	blankRunRe  = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText reduces an HTML body to plain text: block ends become line
+// breaks, tags are dropped, and entities are unescaped.
+func htmlToText(s string) string {
+	s = htmlDropRe.ReplaceAllString(s, "")
+	s = htmlBlockRe.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(htmlTagRe.ReplaceAllString(s, ""))
+	lines := strings.Split(normalizeNewlines(s), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return blankRunRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
+
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
@@ -0,0 +1,100 @@
+package intake
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseEmail_MultipartWithAttachment(t *testing.T) {
+	raw := strings.ReplaceAll(`From: Ada Lovelace <ada@example.com>
+To: board@example.com
+Subject: =?UTF-8?Q?Checkout_button_is_misaligned_=E2=80=94_mobile?=
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: multipart/alternative; boundary="inner"
+
+--inner
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: quoted-printable
+
+The checkout button overlaps the total on small screens.=0A=
+See the screenshot.
+
+--inner
+Content-Type: text/html; charset=utf-8
+
+<p>The checkout button overlaps the total.</p>
+--inner--
+
+--outer
+Content-Type: image/png; name="shot.png"
+Content-Disposition: attachment; filename="shot.png"
+Content-Transfer-Encoding: base64
+
+iVBO
+Rw0K
+--outer--
+`, "\n", "\r\n")
+	msg, err := ParseEmail([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Title != "Checkout button is misaligned — mobile" {
+		t.Errorf("Title = %q", msg.Title)
+	}
+	if msg.From != "ada@example.com" {
+		t.Errorf("From = %q", msg.From)
+	}
+	if msg.Body != "The checkout button overlaps the total on small screens.\nSee the screenshot." {
+		t.Errorf("Body = %q", msg.Body)
+	}
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("Attachments = %+v", msg.Attachments)
+	}
+	if a := msg.Attachments[0]; a.Name != "shot.png" || a.ContentType != "image/png" || string(a.Data) != "\x89PNG\r\n" {
+		t.Errorf("attachment = %q %q %q", a.Name, a.ContentType, a.Data)
+	}
+}
+
+func TestParseEmail_HTMLOnlyAndSubjectOnly(t *testing.T) {
+	html := "Subject: Dark mode\r\nContent-Type: text/html\r\n\r\n<html><head><style>p{}</style></head><body><p>Add a dark&nbsp;mode</p><p>to settings</p></body></html>"
+	msg, err := ParseEmail([]byte(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Body != "Add a dark mode\nto settings" {
+		t.Errorf("Body = %q", msg.Body)
+	}
+
+	msg, err = ParseEmail([]byte("Subject: Bump the Go version\r\n\r\n"))
+	if err != nil || msg.Body != "Bump the Go version" {
+		t.Errorf("subject-only = %+v, %v", msg, err)
+	}
+	if _, err := ParseEmail([]byte("From: a@b.c\r\n\r\n  \r\n")); !errors.Is(err, ErrEmptyMessage) {
+		t.Errorf("empty message err = %v", err)
+	}
+}
+
+func TestAllowedSender(t *testing.T) {
+	allowed := []string{"Lead@Example.com", "@acme.com"}
+	for from, want := range map[string]bool{
+		"lead@example.com":      true,
+		"dev@acme.com":          true,
+		"DEV@ACME.COM":          true,
+		"dev@notacme.com":       false,
+		"dev@acme.com.evil.org": false,
+		"other@example.com":     false,
+		"acme.com":              false,
+		"":                      false,
+	} {
+		if got := AllowedSender(from, allowed); got != want {
+			t.Errorf("AllowedSender(%q) = %v, want %v", from, got, want)
+		}
+	}
+	if AllowedSender("lead@example.com", nil) {
+		t.Error("an empty allowlist should admit no one")
+	}
+}
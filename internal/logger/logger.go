@@ -28,6 +28,7 @@ var (
 	Handler  *slog.Logger
 	Recovery *slog.Logger
 	Prompts  *slog.Logger
+	Push     *slog.Logger
 )
 
 // init sets up default text-format loggers so that any package importing logger
@@ -54,6 +55,7 @@ func Init(format string) {
 	Handler = base.With("component", "handler")
 	Recovery = base.With("component", "recovery")
 	Prompts = base.With("component", "prompts")
+	Push = base.With("component", "push")
 }
 
 // Fatal prints a user-friendly error to stderr and exits with code 1.
@@ -26,6 +26,7 @@ func TestInit(t *testing.T) {
 			"Git":      Git,
 			"Handler":  Handler,
 			"Recovery": Recovery,
+			"Push":     Push,
 		}
 		for name, l := range loggers {
 			if l == nil {
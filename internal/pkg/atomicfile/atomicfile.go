@@ -92,3 +92,52 @@ func WriteJSON(path string, v any, perm os.FileMode) error {
 	}
 	return Write(path, raw, perm)
 }
+
+// Pending is a file written incrementally in place of a target path. Like
+// [Write], the data goes to a temporary file in the target's directory, so
+// readers never see a partial file: Commit renames it over the target and
+// Abort discards it.
+type Pending struct {
+	f    *os.File
+	path string
+	perm os.FileMode
+}
+
+// Create starts writing path. The caller must Commit or Abort the result.
+func Create(path string, perm os.FileMode) (*Pending, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	return &Pending{f: f, path: path, perm: perm}, nil
+}
+
+// Write appends data to the temporary file.
+func (p *Pending) Write(data []byte) (int, error) {
+	return writeFile(p.f, data)
+}
+
+// Commit closes the temporary file and renames it over the target path.
+// The temporary file is removed on failure.
+func (p *Pending) Commit() error {
+	tmp := p.f.Name()
+	if err := closeFile(p.f); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := chmodPath(tmp, p.perm); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := renamePath(tmp, p.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Abort closes and removes the temporary file, leaving the target as it was.
+func (p *Pending) Abort() {
+	_ = closeFile(p.f)
+	_ = os.Remove(p.f.Name())
+}
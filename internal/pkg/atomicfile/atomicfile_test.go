@@ -311,3 +311,41 @@ func TestWrite_Concurrent(t *testing.T) {
 		t.Fatalf("expected 1 byte, got %d", len(got))
 	}
 }
+
+// TestCreate_CommitAndAbort verifies that a Pending file is invisible at the
+// target path until Commit, and that Abort leaves the previous content and
+// no temporary file behind.
+func TestCreate_CommitAndAbort(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+
+	p, err := Create(path, 0644)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	_, _ = p.Write([]byte("hello "))
+	_, _ = p.Write([]byte("world"))
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("target must not exist before Commit")
+	}
+	if err := p.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "hello world" {
+		t.Fatalf("got %q, want %q", got, "hello world")
+	}
+
+	p, err = Create(path, 0644)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	_, _ = p.Write([]byte("discarded"))
+	p.Abort()
+	if got, _ := os.ReadFile(path); string(got) != "hello world" {
+		t.Fatalf("after Abort got %q, want the committed content", got)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("dir holds %d entries after Abort, want only the target", len(entries))
+	}
+}
@@ -0,0 +1,189 @@
+// Package atrest encrypts files at rest with AES-256-GCM. Sealed data starts
+// with a fixed magic prefix, so a reader can tell sealed from plaintext and a
+// data directory can be migrated one file at a time: [Cipher.Open] passes
+// plaintext through unchanged.
+package atrest
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+)
+
+// KeyEnv is the environment variable holding the key specification read by
+// [FromEnv]. See [ParseKey] for the accepted forms.
+const KeyEnv = "WALLFACER_STORE_KEY"
+
+// KeySize is the AES-256 key length in bytes.
+const KeySize = 32
+
+// magic prefixes every sealed payload. The version digit leaves room for a
+// future format change.
+var magic = []byte("wfenc1\x00")
+
+// ErrNoKey is returned by Open for sealed data when no key is configured.
+var ErrNoKey = errors.New("atrest: data is encrypted but no key is configured (set " + KeyEnv + ")")
+
+// Cipher seals and opens payloads with one AES-256-GCM key. A nil *Cipher is
+// valid and means encryption is off: Seal returns its input unchanged and
+// Open accepts only plaintext.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New returns a Cipher for a KeySize-byte key.
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("atrest: key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Enabled reports whether c encrypts, i.e. c is non-nil.
+func (c *Cipher) Enabled() bool { return c != nil }
+
+// IsSealed reports whether data was produced by Seal.
+func IsSealed(data []byte) bool { return bytes.HasPrefix(data, magic) }
+
+// Seal encrypts plaintext under a fresh random nonce and returns
+// magic || nonce || ciphertext. With a nil Cipher it returns plaintext.
+func (c *Cipher) Seal(plaintext []byte) ([]byte, error) {
+	if c == nil {
+		return plaintext, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(magic)+len(nonce)+len(plaintext)+c.aead.Overhead())
+	out = append(out, magic...)
+	out = append(out, nonce...)
+	return c.aead.Seal(out, nonce, plaintext, nil), nil
+}
+
+// Open decrypts data produced by Seal. Data without the magic prefix is
+// plaintext and is returned as-is, so a partially migrated directory stays
+// readable. Sealed data with a nil Cipher fails with ErrNoKey.
+func (c *Cipher) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, ErrNoKey
+	}
+	body := data[len(magic):]
+	n := c.aead.NonceSize()
+	if len(body) < n {
+		return nil, errors.New("atrest: sealed data is truncated")
+	}
+	plain, err := c.aead.Open(nil, body[:n], body[n:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("atrest: decrypt: %w (wrong key?)", err)
+	}
+	return plain, nil
+}
+
+// FromEnv returns the Cipher configured by KeyEnv, or nil when it is unset.
+func FromEnv() (*Cipher, error) {
+	spec := strings.TrimSpace(os.Getenv(KeyEnv))
+	if spec == "" {
+		return nil, nil
+	}
+	key, err := ParseKey(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", KeyEnv, err)
+	}
+	return New(key)
+}
+
+// ParseKey resolves a key specification to raw key bytes. Accepted forms:
+//
+//	<base64 or hex>      the key itself (32 bytes once decoded)
+//	file:<path>          a file holding the key in base64 or hex
+//	keychain:<service>   a generic password in the OS keychain (macOS
+//	                     Keychain via security, or the Secret Service via
+//	                     secret-tool on Linux), holding the key in base64 or hex
+func ParseKey(spec string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(spec, "file:"):
+		raw, err := os.ReadFile(strings.TrimPrefix(spec, "file:"))
+		if err != nil {
+			return nil, fmt.Errorf("read key file: %w", err)
+		}
+		return decodeKey(string(raw))
+	case strings.HasPrefix(spec, "keychain:"):
+		raw, err := keychainLookup(strings.TrimPrefix(spec, "keychain:"))
+		if err != nil {
+			return nil, err
+		}
+		return decodeKey(raw)
+	default:
+		return decodeKey(spec)
+	}
+}
+
+// decodeKey accepts a KeySize-byte key encoded as hex or standard base64.
+func decodeKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == hex.EncodedLen(KeySize) {
+		if key, err := hex.DecodeString(s); err == nil {
+			return key, nil
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("key is neither hex nor base64")
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// keychainLookup reads a generic password stored under service.
+func keychainLookup(service string) (string, error) {
+	if service == "" {
+		return "", errors.New("keychain: service name is empty")
+	}
+	var cmd *cmdexec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = cmdexec.New("security", "find-generic-password", "-w", "-s", service)
+	case "linux":
+		cmd = cmdexec.New("secret-tool", "lookup", "service", service)
+	default:
+		return "", fmt.Errorf("keychain: not supported on %s", runtime.GOOS)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("keychain: look up %q: %w", service, err)
+	}
+	return out, nil
+}
+
+// GenerateKey returns a new random key, base64-encoded as ParseKey expects.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
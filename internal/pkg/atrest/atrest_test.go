@@ -0,0 +1,121 @@
+package atrest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testCipher(t *testing.T) *Cipher {
+	t.Helper()
+	c, err := New(bytes.Repeat([]byte{7}, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	c := testCipher(t)
+	plain := []byte(`{"prompt":"secret"}`)
+	sealed, err := c.Seal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("secret")) {
+		t.Fatalf("sealed data is not encrypted: %q", sealed)
+	}
+	again, _ := c.Seal(plain)
+	if bytes.Equal(sealed, again) {
+		t.Error("two seals of the same plaintext are identical; nonce reused")
+	}
+	got, err := c.Open(sealed)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("Open = %q, %v; want %q", got, err, plain)
+	}
+}
+
+func TestOpenPassesPlaintextThrough(t *testing.T) {
+	plain := []byte(`{"id":1}`)
+	for _, c := range []*Cipher{nil, testCipher(t)} {
+		got, err := c.Open(plain)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Errorf("Open(plaintext) = %q, %v", got, err)
+		}
+	}
+}
+
+func TestNilCipher(t *testing.T) {
+	var c *Cipher
+	if c.Enabled() {
+		t.Error("nil cipher reports enabled")
+	}
+	plain := []byte("x")
+	if got, _ := c.Seal(plain); !bytes.Equal(got, plain) {
+		t.Errorf("nil Seal changed data: %q", got)
+	}
+	sealed, _ := testCipher(t).Seal(plain)
+	if _, err := c.Open(sealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("nil Open(sealed) error = %v, want ErrNoKey", err)
+	}
+}
+
+func TestOpenRejectsWrongKeyAndTruncation(t *testing.T) {
+	sealed, _ := testCipher(t).Seal([]byte("payload"))
+	other, _ := New(bytes.Repeat([]byte{9}, KeySize))
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("Open with the wrong key succeeded")
+	}
+	if _, err := testCipher(t).Open(sealed[:len(magic)+3]); err == nil {
+		t.Error("Open of truncated data succeeded")
+	}
+}
+
+func TestNewRejectsShortKey(t *testing.T) {
+	if _, err := New([]byte("short")); err == nil {
+		t.Error("New accepted a short key")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key := bytes.Repeat([]byte{3}, KeySize)
+	b64 := base64.StdEncoding.EncodeToString(key)
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range []string{b64, hex.EncodeToString(key), "file:" + path} {
+		got, err := ParseKey(spec)
+		if err != nil || !bytes.Equal(got, key) {
+			t.Errorf("ParseKey(%q) = %x, %v", spec, got, err)
+		}
+	}
+	for _, spec := range []string{"not a key", base64.StdEncoding.EncodeToString([]byte("short")), "file:" + filepath.Join(t.TempDir(), "missing"), "keychain:"} {
+		if _, err := ParseKey(spec); err == nil {
+			t.Errorf("ParseKey(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(KeyEnv, "")
+	if c, err := FromEnv(); c != nil || err != nil {
+		t.Errorf("FromEnv with no key = %v, %v; want nil, nil", c, err)
+	}
+	k, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(KeyEnv, k)
+	if c, err := FromEnv(); c == nil || err != nil {
+		t.Errorf("FromEnv with a generated key = %v, %v", c, err)
+	}
+	t.Setenv(KeyEnv, "garbage")
+	if _, err := FromEnv(); err == nil {
+		t.Error("FromEnv accepted an invalid key")
+	}
+}
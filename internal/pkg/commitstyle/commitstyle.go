@@ -0,0 +1,177 @@
+// Package commitstyle derives a repository's commit-message conventions
+// from a sample of its recent subject lines: whether it follows
+// Conventional Commits, which types and scopes it uses, whether subjects
+// lead with a path prefix or an emoji, and how descriptions are
+// capitalised. [Profile.Guidance] renders the result as rules for the
+// commit-message prompt, so generated messages stay consistent with the
+// project's history without re-reading it for every commit.
+package commitstyle
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxExamples is the number of recent subjects a profile keeps as style
+// examples for the prompt.
+const MaxExamples = 5
+
+// maxListed bounds the types and scopes a profile records.
+const maxListed = 5
+
+// Profile is the commit style observed in a repository's history.
+type Profile struct {
+	// Sampled is the number of subject lines the profile was built from.
+	Sampled int `json:"sampled"`
+	// Conventional is set when most subjects follow Conventional Commits
+	// (type(scope): description).
+	Conventional bool `json:"conventional"`
+	// Types lists the Conventional Commits types seen, most frequent first.
+	Types []string `json:"types,omitempty"`
+	// PathPrefix is set when most subjects lead with a non-conventional
+	// "<path>: " prefix such as "internal/runner: ".
+	PathPrefix bool `json:"path_prefix"`
+	// Scopes lists the scopes or path prefixes seen, most frequent first.
+	Scopes []string `json:"scopes,omitempty"`
+	// Emoji is set when most subjects start with an emoji or a :shortcode:.
+	Emoji bool `json:"emoji"`
+	// Capitalized is set when most descriptions start with an upper-case
+	// letter.
+	Capitalized bool `json:"capitalized"`
+	// Examples holds the most recent subjects, newest first.
+	Examples []string `json:"examples,omitempty"`
+	// RefreshedAt is when the history was last sampled.
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// conventionalTypes are the types recognised as Conventional Commits.
+var conventionalTypes = map[string]bool{
+	"feat": true, "fix": true, "docs": true, "style": true, "refactor": true,
+	"perf": true, "test": true, "build": true, "ci": true, "chore": true,
+	"revert": true,
+}
+
+var (
+	conventionalRe = regexp.MustCompile(`^([a-z]+)(?:\(([^)]+)\))?!?: (.*)$`)
+	pathPrefixRe   = regexp.MustCompile(`^([\w.][\w./-]*(?:, ?[\w.][\w./-]*)*): (.*)$`)
+	shortcodeRe    = regexp.MustCompile(`^:[a-z0-9_+-]+: ?`)
+)
+
+// Analyze builds a profile from subject lines, newest first. Blank lines
+// and merge commits are ignored; RefreshedAt is left for the caller to set.
+func Analyze(subjects []string) Profile {
+	var p Profile
+	var conventional, prefixed, emoji, capitalized int
+	types := map[string]int{}
+	scopes := map[string]int{}
+	for _, s := range subjects {
+		s = strings.TrimSpace(s)
+		if s == "" || strings.HasPrefix(s, "Merge ") {
+			continue
+		}
+		p.Sampled++
+		if len(p.Examples) < MaxExamples {
+			p.Examples = append(p.Examples, s)
+		}
+
+		rest := s
+		if lead, ok := leadingEmoji(s); ok {
+			emoji++
+			rest = strings.TrimSpace(s[len(lead):])
+		}
+		desc := rest
+		if m := conventionalRe.FindStringSubmatch(rest); m != nil && conventionalTypes[m[1]] {
+			conventional++
+			types[m[1]]++
+			if m[2] != "" {
+				scopes[m[2]]++
+			}
+			desc = m[3]
+		} else if m := pathPrefixRe.FindStringSubmatch(rest); m != nil {
+			prefixed++
+			scopes[m[1]]++
+			desc = m[2]
+		}
+		if r, _ := utf8.DecodeRuneInString(desc); unicode.IsUpper(r) {
+			capitalized++
+		}
+	}
+	if p.Sampled == 0 {
+		return p
+	}
+	majority := func(n int) bool { return 2*n > p.Sampled }
+	p.Conventional = majority(conventional)
+	p.PathPrefix = !p.Conventional && majority(prefixed)
+	p.Emoji = majority(emoji)
+	p.Capitalized = majority(capitalized)
+	p.Types = mostFrequent(types)
+	p.Scopes = mostFrequent(scopes)
+	return p
+}
+
+// leadingEmoji returns the emoji or :shortcode: a subject starts with.
+func leadingEmoji(s string) (string, bool) {
+	if m := shortcodeRe.FindString(s); m != "" {
+		return m, true
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if r >= 0x2190 && (unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r)) {
+		// Swallow a trailing variation selector.
+		if next, n := utf8.DecodeRuneInString(s[size:]); next == 0xFE0F {
+			size += n
+		}
+		return s[:size], true
+	}
+	return "", false
+}
+
+// mostFrequent returns up to maxListed keys of counts, most frequent first
+// and alphabetical among ties.
+func mostFrequent(counts map[string]int) []string {
+	keys := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		return cmp.Or(counts[b]-counts[a], strings.Compare(a, b))
+	})
+	if len(keys) > maxListed {
+		keys = keys[:maxListed]
+	}
+	return keys
+}
+
+// Guidance renders the profile as prompt rules, one per line, or "" when
+// nothing was sampled.
+func (p Profile) Guidance() string {
+	if p.Sampled == 0 {
+		return ""
+	}
+	var b strings.Builder
+	switch {
+	case p.Conventional:
+		b.WriteString("- Subject line format: Conventional Commits, <type>(<scope>): <description>\n")
+		if len(p.Types) > 0 {
+			fmt.Fprintf(&b, "- Types in use: %s\n", strings.Join(p.Types, ", "))
+		}
+	case p.PathPrefix:
+		b.WriteString("- Subject line format: <primary-path>: <description>\n")
+	default:
+		b.WriteString("- Subject line format: a plain description without a type or path prefix\n")
+	}
+	if (p.Conventional || p.PathPrefix) && len(p.Scopes) > 0 {
+		fmt.Fprintf(&b, "- Scopes in use: %s\n", strings.Join(p.Scopes, ", "))
+	}
+	if p.Emoji {
+		b.WriteString("- Start the subject line with an emoji that fits the change\n")
+	}
+	if p.Capitalized {
+		b.WriteString("- Start the description with an upper-case letter\n")
+	} else {
+		b.WriteString("- Start the description with a lower-case letter\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
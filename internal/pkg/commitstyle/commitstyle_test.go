@@ -0,0 +1,67 @@
+package commitstyle
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze_Conventional(t *testing.T) {
+	p := Analyze([]string{
+		"feat(api): add search endpoint",
+		"fix(api): handle empty query",
+		"fix: correct typo in README",
+		"Merge branch 'main' into topic",
+		"",
+		"docs(guide): document search",
+		"update deps",
+	})
+	if p.Sampled != 5 {
+		t.Errorf("Sampled = %d, want 5", p.Sampled)
+	}
+	if !p.Conventional || p.PathPrefix || p.Emoji || p.Capitalized {
+		t.Errorf("profile = %+v, want conventional lower-case", p)
+	}
+	if want := []string{"fix", "docs", "feat"}; !slices.Equal(p.Types, want) {
+		t.Errorf("Types = %v, want %v", p.Types, want)
+	}
+	if want := []string{"api", "guide"}; !slices.Equal(p.Scopes, want) {
+		t.Errorf("Scopes = %v, want %v", p.Scopes, want)
+	}
+	if len(p.Examples) != MaxExamples || p.Examples[0] != "feat(api): add search endpoint" {
+		t.Errorf("Examples = %v", p.Examples)
+	}
+}
+
+func TestAnalyze_PathPrefixAndEmoji(t *testing.T) {
+	p := Analyze([]string{
+		"✨ internal/runner: Add commit style cache",
+		":bug: ui/js: Fix board scroll",
+		"🔥 Makefile: Drop unused target",
+	})
+	if p.Conventional || !p.PathPrefix || !p.Emoji || !p.Capitalized {
+		t.Errorf("profile = %+v, want path-prefix, emoji, capitalised", p)
+	}
+	if len(p.Scopes) != 3 {
+		t.Errorf("Scopes = %v, want three paths", p.Scopes)
+	}
+}
+
+func TestAnalyze_Plain(t *testing.T) {
+	p := Analyze([]string{"Add login page", "Fix crash on startup", "feat: x"})
+	if p.Conventional || p.PathPrefix || p.Emoji || !p.Capitalized {
+		t.Errorf("profile = %+v, want plain capitalised", p)
+	}
+}
+
+func TestGuidance(t *testing.T) {
+	if g := (Profile{}).Guidance(); g != "" {
+		t.Errorf("empty profile guidance = %q", g)
+	}
+	g := Analyze([]string{"feat(api): add x", "fix(api): y"}).Guidance()
+	for _, want := range []string{"Conventional Commits", "Types in use: feat, fix", "Scopes in use: api", "lower-case"} {
+		if !strings.Contains(g, want) {
+			t.Errorf("guidance missing %q:\n%s", want, g)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package devcache manages the per-workspace package-manager caches agent
+// processes run with. Each workspace gets its own directory per cache kind
+// under a common root (root/<workspace-key>/<kind>), and [Env] returns the
+// environment variables that point the tools at it. Keeping caches per
+// workspace lets one be cleared when it goes stale or grows large without
+// touching the others or the user's own caches.
+package devcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Kind is one managed cache and the environment variables that select it.
+type Kind struct {
+	Name        string
+	Description string
+	Env         []string
+}
+
+// Kinds are the managed caches, in listing order.
+var Kinds = []Kind{
+	{Name: "go-build", Description: "Go build cache", Env: []string{"GOCACHE"}},
+	{Name: "go-mod", Description: "Go module download cache", Env: []string{"GOMODCACHE"}},
+	{Name: "npm", Description: "npm package cache (node_modules installs)", Env: []string{"npm_config_cache"}},
+	{Name: "pip", Description: "pip download and wheel cache", Env: []string{"PIP_CACHE_DIR"}},
+}
+
+// Root returns the directory holding every workspace's caches under the
+// wallfacer config directory.
+func Root(configDir string) string {
+	return filepath.Join(configDir, "caches")
+}
+
+// ErrUnknownKind is returned for a cache name not in Kinds.
+var ErrUnknownKind = errors.New("devcache: unknown cache")
+
+// ErrInvalidKey is returned for a workspace key that is not a single path
+// element.
+var ErrInvalidKey = errors.New("devcache: invalid workspace key")
+
+// Lookup returns the Kind named name.
+func Lookup(name string) (Kind, bool) {
+	i := slices.IndexFunc(Kinds, func(k Kind) bool { return k.Name == name })
+	if i < 0 {
+		return Kind{}, false
+	}
+	return Kinds[i], true
+}
+
+// validKey reports whether key is usable as a single path element.
+func validKey(key string) bool {
+	return key != "" && key != "." && key != ".." && filepath.Base(key) == key
+}
+
+// Dir returns the directory of cache kind name for workspace key.
+func Dir(root, key, name string) string {
+	return filepath.Join(root, key, name)
+}
+
+// Env returns the environment variables pointing every managed cache at
+// workspace key's directories, creating them as needed. It returns nil when
+// root or key is empty, leaving the tools on their default caches.
+func Env(root, key string) (map[string]string, error) {
+	if root == "" || !validKey(key) {
+		return nil, nil
+	}
+	env := make(map[string]string)
+	for _, k := range Kinds {
+		dir := Dir(root, key, k.Name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		for _, name := range k.Env {
+			env[name] = dir
+		}
+	}
+	return env, nil
+}
+
+// Usage is the disk usage of one workspace's cache.
+type Usage struct {
+	Key       string    `json:"workspace_key"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Bytes     int64     `json:"size_bytes"`
+	Files     int       `json:"files"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// List returns the usage of every managed cache under root, grouped by
+// workspace key in directory order and by Kinds order within a workspace.
+// Caches that were never created are omitted; a missing root yields none.
+func List(root string) ([]Usage, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Usage
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		for _, k := range Kinds {
+			dir := Dir(root, e.Name(), k.Name)
+			if _, err := os.Stat(dir); err != nil {
+				continue
+			}
+			u, err := measure(dir)
+			if err != nil {
+				return nil, err
+			}
+			u.Key, u.Name = e.Name(), k.Name
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+// measure sums the sizes of the regular files under dir and records the
+// latest modification time. Files that vanish mid-walk are skipped.
+func measure(dir string) (Usage, error) {
+	u := Usage{Path: dir}
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		u.Bytes += info.Size()
+		u.Files++
+		if info.ModTime().After(u.UpdatedAt) {
+			u.UpdatedAt = info.ModTime()
+		}
+		return nil
+	})
+	return u, err
+}
+
+// Clear removes the contents of cache kind name for workspace key, or of
+// every cache of the workspace when name is empty. Go marks its module
+// cache read-only, so permissions are relaxed before removal. Clearing a
+// cache that does not exist is not an error.
+func Clear(root, key, name string) error {
+	if !validKey(key) {
+		return fmt.Errorf("%w %q", ErrInvalidKey, key)
+	}
+	target := filepath.Join(root, key)
+	if name != "" {
+		if _, ok := Lookup(name); !ok {
+			return fmt.Errorf("%w %q", ErrUnknownKind, name)
+		}
+		target = Dir(root, key, name)
+	}
+	if _, err := os.Stat(target); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	_ = filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			_ = os.Chmod(path, 0o755)
+		}
+		return nil
+	})
+	return os.RemoveAll(target)
+}
@@ -0,0 +1,145 @@
+package devcache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnv_CreatesDirsPerWorkspace(t *testing.T) {
+	root := t.TempDir()
+	env, err := Env(root, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range Kinds {
+		dir := Dir(root, "abc", k.Name)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("%s: dir not created: %v", k.Name, err)
+		}
+		for _, name := range k.Env {
+			if env[name] != dir {
+				t.Errorf("%s = %q, want %q", name, env[name], dir)
+			}
+		}
+	}
+}
+
+func TestEnv_DisabledWithoutRootOrKey(t *testing.T) {
+	for _, tc := range []struct{ root, key string }{{"", "abc"}, {t.TempDir(), ""}, {t.TempDir(), ".."}} {
+		env, err := Env(tc.root, tc.key)
+		if err != nil || env != nil {
+			t.Errorf("Env(%q, %q) = %v, %v; want nil, nil", tc.root, tc.key, env, err)
+		}
+	}
+}
+
+func TestList_MeasuresCaches(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Env(root, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	npm := Dir(root, "abc", "npm")
+	if err := os.MkdirAll(filepath.Join(npm, "_cacache"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(npm, "_cacache", "a"), make([]byte, 100), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(npm, "b"), make([]byte, 23), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A stray file at the root is not a workspace.
+	if err := os.WriteFile(filepath.Join(root, "README"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != len(Kinds) {
+		t.Fatalf("List returned %d caches, want %d", len(usage), len(Kinds))
+	}
+	for i, u := range usage {
+		if u.Key != "abc" || u.Name != Kinds[i].Name {
+			t.Errorf("usage[%d] = %s/%s, want abc/%s", i, u.Key, u.Name, Kinds[i].Name)
+		}
+		if u.Name == "npm" && (u.Bytes != 123 || u.Files != 2 || u.UpdatedAt.IsZero()) {
+			t.Errorf("npm usage = %+v, want 123 bytes in 2 files", u)
+		}
+		if u.Name != "npm" && u.Bytes != 0 {
+			t.Errorf("%s usage = %d bytes, want 0", u.Name, u.Bytes)
+		}
+	}
+}
+
+func TestList_MissingRoot(t *testing.T) {
+	usage, err := List(filepath.Join(t.TempDir(), "absent"))
+	if err != nil || usage != nil {
+		t.Fatalf("List = %v, %v; want nil, nil", usage, err)
+	}
+}
+
+func TestClear_OneKindHandlesReadOnlyTree(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Env(root, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	// Mimic Go's read-only module cache.
+	mod := filepath.Join(Dir(root, "abc", "go-mod"), "example.com", "m@v1.0.0")
+	if err := os.MkdirAll(mod, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mod, "go.mod"), []byte("module m\n"), 0o444); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(mod, 0o555); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Clear(root, "abc", "go-mod"); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if _, err := os.Stat(Dir(root, "abc", "go-mod")); !os.IsNotExist(err) {
+		t.Errorf("go-mod cache still present: %v", err)
+	}
+	if _, err := os.Stat(Dir(root, "abc", "npm")); err != nil {
+		t.Errorf("clearing go-mod removed npm: %v", err)
+	}
+}
+
+func TestClear_Workspace(t *testing.T) {
+	root := t.TempDir()
+	for _, key := range []string{"abc", "def"} {
+		if _, err := Env(root, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Clear(root, "abc", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "abc")); !os.IsNotExist(err) {
+		t.Errorf("workspace caches still present: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "def")); err != nil {
+		t.Errorf("other workspace removed: %v", err)
+	}
+	// Clearing again is a no-op.
+	if err := Clear(root, "abc", ""); err != nil {
+		t.Errorf("second Clear: %v", err)
+	}
+}
+
+func TestClear_RejectsBadInput(t *testing.T) {
+	root := t.TempDir()
+	if err := Clear(root, "abc", "maven"); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("unknown kind: err = %v, want ErrUnknownKind", err)
+	}
+	for _, key := range []string{"", "..", "a/b"} {
+		if err := Clear(root, key, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Clear with key %q: err = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
@@ -3,7 +3,8 @@
 // Copy tries a native `cp -a` on Unix for speed and falls back to a pure-Go
 // recursive walk on failure or on Windows. CopyGo is the pure-Go fallback
 // exported for testing or when the native tool is not desired. CopyFile copies
-// a single file preserving its permissions.
+// a single file preserving its permissions. Sync updates an existing tree in
+// place and reports which files it copied, overwrote, and deleted.
 package dircp
 
 import (
@@ -0,0 +1,298 @@
+package dircp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultIgnore lists the operating-system and dependency junk Sync callers
+// skip when no ignore patterns are configured.
+var DefaultIgnore = []string{".DS_Store", "._*", "Thumbs.db", "desktop.ini", "node_modules"}
+
+// SyncOptions controls Sync.
+type SyncOptions struct {
+	// Ignore holds filepath.Match patterns. A pattern without a slash
+	// matches any path component, one with a slash the whole slash-separated
+	// path relative to the root. Ignored entries are neither copied nor
+	// deleted. The .git directory is always ignored.
+	Ignore []string
+	// Baseline lists the slash-separated relative paths of the files src
+	// started with. A baseline file that is missing from src was deleted
+	// there and is deleted from dst; files in dst that were never in the
+	// baseline are left alone.
+	Baseline []string
+}
+
+// Manifest records what Sync changed in dst, as sorted slash-separated
+// paths relative to it.
+type Manifest struct {
+	Copied      []string `json:"copied,omitempty"`
+	Overwritten []string `json:"overwritten,omitempty"`
+	Deleted     []string `json:"deleted,omitempty"`
+}
+
+// Sync makes dst match src for every entry that is not ignored. New files
+// are copied, changed files overwritten, and files deleted from the
+// baseline removed; unchanged files are not touched. Copies keep the
+// source's permission bits, modification time, symlink targets, and, where
+// the platform supports them, extended attributes. On Windows only the
+// read-only permission bit is carried over. Entries other than files,
+// directories, and symlinks are skipped.
+func Sync(src, dst string, opts SyncOptions) (Manifest, error) {
+	var m Manifest
+	ignore := append([]string{".git"}, opts.Ignore...)
+	seen := make(map[string]bool)
+	var dirs []string
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := relPath(src, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		slashRel := filepath.ToSlash(rel)
+		if isIgnored(ignore, slashRel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		seen[slashRel] = true
+		target := filepath.Join(dst, rel)
+		info, err := entryInfo(d)
+		if err != nil {
+			return err
+		}
+
+		var existed, changed bool
+		switch {
+		case d.IsDir():
+			dirs = append(dirs, slashRel)
+			return syncDir(target)
+		case d.Type()&fs.ModeSymlink != 0:
+			existed, changed, err = syncSymlink(p, target)
+		case d.Type().IsRegular():
+			existed, changed, err = syncFile(p, target, info)
+		default:
+			return nil
+		}
+		if err != nil || !changed {
+			return err
+		}
+		if existed {
+			m.Overwritten = append(m.Overwritten, slashRel)
+		} else {
+			m.Copied = append(m.Copied, slashRel)
+		}
+		return nil
+	})
+	if err != nil {
+		return m, err
+	}
+
+	for _, rel := range opts.Baseline {
+		if seen[rel] || isIgnored(ignore, rel) {
+			continue
+		}
+		target := filepath.Join(dst, filepath.FromSlash(rel))
+		if _, err := os.Lstat(target); err != nil {
+			continue
+		}
+		if err := os.Remove(target); err != nil {
+			return m, err
+		}
+		m.Deleted = append(m.Deleted, rel)
+		// Remove directories the deletion emptied, unless src still has them.
+		for dir := path.Dir(rel); dir != "." && !seen[dir]; dir = path.Dir(dir) {
+			if os.Remove(filepath.Join(dst, filepath.FromSlash(dir))) != nil {
+				break
+			}
+		}
+	}
+
+	// Directory permissions are applied last, deepest first, so a read-only
+	// directory does not block writes into it.
+	for _, rel := range slices.Backward(dirs) {
+		if info, err := os.Stat(filepath.Join(src, filepath.FromSlash(rel))); err == nil {
+			_ = os.Chmod(filepath.Join(dst, filepath.FromSlash(rel)), modeBits(info.Mode()))
+		}
+	}
+
+	slices.Sort(m.Copied)
+	slices.Sort(m.Overwritten)
+	slices.Sort(m.Deleted)
+	return m, nil
+}
+
+// isIgnored reports whether the slash-separated relative path rel matches
+// one of patterns (see SyncOptions.Ignore).
+func isIgnored(patterns []string, rel string) bool {
+	parts := strings.Split(rel, "/")
+	for _, pat := range patterns {
+		if strings.Contains(pat, "/") {
+			if ok, _ := path.Match(strings.Trim(pat, "/"), rel); ok {
+				return true
+			}
+			continue
+		}
+		for _, part := range parts {
+			if ok, _ := path.Match(pat, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// modeBits returns the permission and special bits of mode that Sync
+// carries over.
+func modeBits(mode fs.FileMode) fs.FileMode {
+	return mode & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+}
+
+// syncDir ensures target is a directory, replacing a file or symlink that
+// stands in its place.
+func syncDir(target string) error {
+	if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(target, 0755)
+}
+
+// syncSymlink recreates the symlink src at target unless target is already
+// a symlink with the same destination.
+func syncSymlink(src, target string) (existed, changed bool, err error) {
+	link, err := readLink(src)
+	if err != nil {
+		return false, false, err
+	}
+	existing, err := os.Lstat(target)
+	existed = err == nil
+	if existed {
+		if existing.Mode()&fs.ModeSymlink != 0 {
+			if cur, err := os.Readlink(target); err == nil && cur == link {
+				return true, false, nil
+			}
+		}
+		if err := os.RemoveAll(target); err != nil {
+			return true, false, err
+		}
+	}
+	if err := os.Symlink(link, target); err != nil {
+		return existed, false, err
+	}
+	copyXattrs(src, target)
+	return existed, true, nil
+}
+
+// syncFile copies the regular file src to target unless target already has
+// the same content and mode. A target that differs only in mode is
+// chmod-ed in place; any other change is written to a temporary file and
+// renamed over target so a reader never sees a partial file.
+func syncFile(src, target string, info fs.FileInfo) (existed, changed bool, err error) {
+	mode := modeBits(info.Mode())
+	existing, err := os.Lstat(target)
+	existed = err == nil
+	if existed {
+		if existing.Mode().IsRegular() && existing.Size() == info.Size() {
+			same, err := sameContent(src, target)
+			if err != nil {
+				return true, false, err
+			}
+			if same {
+				if modeBits(existing.Mode()) == mode {
+					return true, false, nil
+				}
+				return true, true, os.Chmod(target, mode)
+			}
+		}
+		if !existing.Mode().IsRegular() {
+			if err := os.RemoveAll(target); err != nil {
+				return true, false, err
+			}
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".wallfacer-sync-*")
+	if err != nil {
+		return existed, false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	in, err := os.Open(src)
+	if err != nil {
+		_ = tmp.Close()
+		return existed, false, err
+	}
+	_, err = ioCopy(tmp, in)
+	_ = in.Close()
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return existed, false, err
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return existed, false, err
+	}
+	copyXattrs(src, tmp.Name())
+	if err = os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime()); err != nil {
+		return existed, false, err
+	}
+	if err = os.Rename(tmp.Name(), target); err != nil {
+		return existed, false, err
+	}
+	return existed, true, nil
+}
+
+// sameContent reports whether the files a and b have identical bytes.
+func sameContent(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = fa.Close() }()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = fb.Close() }()
+
+	bufA := make([]byte, 32<<10)
+	bufB := make([]byte, 32<<10)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		endA := errors.Is(errA, io.EOF) || errors.Is(errA, io.ErrUnexpectedEOF)
+		endB := errors.Is(errB, io.EOF) || errors.Is(errB, io.ErrUnexpectedEOF)
+		switch {
+		case endA && endB:
+			return true, nil
+		case endA != endB:
+			return false, nil
+		case errA != nil:
+			return false, errA
+		case errB != nil:
+			return false, errB
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package dircp
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSyncManifest verifies that Sync copies new files, overwrites changed
+// ones, deletes baseline files removed from src, leaves unchanged and
+// unknown files alone, and reports each change in the manifest.
+func TestSyncManifest(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeFile(t, filepath.Join(src, "same.txt"), "same", 0644)
+	writeFile(t, filepath.Join(src, "changed.txt"), "new", 0644)
+	writeFile(t, filepath.Join(src, "sub", "added.txt"), "added", 0644)
+	writeFile(t, filepath.Join(dst, "same.txt"), "same", 0644)
+	writeFile(t, filepath.Join(dst, "changed.txt"), "old", 0644)
+	writeFile(t, filepath.Join(dst, "gone", "removed.txt"), "removed", 0644)
+	writeFile(t, filepath.Join(dst, "untracked.txt"), "local", 0644)
+
+	m, err := Sync(src, dst, SyncOptions{
+		Baseline: []string{"same.txt", "changed.txt", "gone/removed.txt"},
+	})
+	if err != nil {
+		t.Fatal("Sync:", err)
+	}
+	if !slices.Equal(m.Copied, []string{"sub/added.txt"}) {
+		t.Errorf("Copied = %v", m.Copied)
+	}
+	if !slices.Equal(m.Overwritten, []string{"changed.txt"}) {
+		t.Errorf("Overwritten = %v", m.Overwritten)
+	}
+	if !slices.Equal(m.Deleted, []string{"gone/removed.txt"}) {
+		t.Errorf("Deleted = %v", m.Deleted)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "changed.txt")); string(got) != "new" {
+		t.Errorf("changed.txt = %q, want %q", got, "new")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "gone")); !os.IsNotExist(err) {
+		t.Errorf("emptied directory not removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "untracked.txt")); err != nil {
+		t.Errorf("file outside the baseline was removed: %v", err)
+	}
+
+	// A second sync has nothing left to do.
+	m, err = Sync(src, dst, SyncOptions{})
+	if err != nil {
+		t.Fatal("second Sync:", err)
+	}
+	if len(m.Copied)+len(m.Overwritten)+len(m.Deleted) != 0 {
+		t.Errorf("second Sync manifest = %+v, want empty", m)
+	}
+}
+
+// TestSyncIgnore verifies that ignored entries are neither copied nor
+// deleted, and that .git is always skipped.
+func TestSyncIgnore(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeFile(t, filepath.Join(src, "keep.txt"), "keep", 0644)
+	writeFile(t, filepath.Join(src, ".DS_Store"), "junk", 0644)
+	writeFile(t, filepath.Join(src, "web", "node_modules", "dep", "index.js"), "dep", 0644)
+	writeFile(t, filepath.Join(src, "build", "out.bin"), "out", 0644)
+	writeFile(t, filepath.Join(src, ".git", "HEAD"), "ref", 0644)
+	writeFile(t, filepath.Join(dst, "web", "node_modules", "local.js"), "local", 0644)
+
+	m, err := Sync(src, dst, SyncOptions{
+		Ignore:   append(slices.Clone(DefaultIgnore), "/build/*"),
+		Baseline: []string{"web/node_modules/local.js"},
+	})
+	if err != nil {
+		t.Fatal("Sync:", err)
+	}
+	if !slices.Equal(m.Copied, []string{"keep.txt"}) {
+		t.Errorf("Copied = %v, want [keep.txt]", m.Copied)
+	}
+	if len(m.Deleted) != 0 {
+		t.Errorf("Deleted = %v, want none", m.Deleted)
+	}
+	for _, p := range []string{".DS_Store", ".git", "build/out.bin", "web/node_modules/dep"} {
+		if _, err := os.Lstat(filepath.Join(dst, p)); !os.IsNotExist(err) {
+			t.Errorf("ignored %s was copied: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "web", "node_modules", "local.js")); err != nil {
+		t.Errorf("ignored baseline file was deleted: %v", err)
+	}
+}
+
+// TestSyncPreservesModeTimeAndSymlinks verifies that copies keep permission
+// bits, modification time, and symlink targets, and that a mode-only change
+// is reported as an overwrite.
+func TestSyncPreservesModeTimeAndSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits and symlinks are not portable to Windows")
+	}
+	src := t.TempDir()
+	dst := t.TempDir()
+	script := filepath.Join(src, "run.sh")
+	writeFile(t, script, "#!/bin/sh\n", 0750)
+	mtime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(script, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("run.sh", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Sync(src, dst, SyncOptions{}); err != nil {
+		t.Fatal("Sync:", err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("mode = %v, want 0750", info.Mode().Perm())
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("mtime = %v, want %v", info.ModTime(), mtime)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "link")); err != nil || link != "run.sh" {
+		t.Errorf("link = %q, %v; want run.sh", link, err)
+	}
+
+	if err := os.Chmod(script, 0700); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Sync(src, dst, SyncOptions{})
+	if err != nil {
+		t.Fatal("second Sync:", err)
+	}
+	if !slices.Equal(m.Overwritten, []string{"run.sh"}) || len(m.Copied) != 0 {
+		t.Errorf("manifest after chmod = %+v, want run.sh overwritten", m)
+	}
+	if info, _ := os.Stat(filepath.Join(dst, "run.sh")); info.Mode().Perm() != 0700 {
+		t.Errorf("mode after chmod = %v, want 0700", info.Mode().Perm())
+	}
+}
+
+// TestSyncReplacesTypeChanges verifies that a file that became a directory
+// in src (and the reverse) replaces the old entry in dst.
+func TestSyncReplacesTypeChanges(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeFile(t, filepath.Join(src, "was-file", "inner.txt"), "inner", 0644)
+	writeFile(t, filepath.Join(src, "was-dir"), "file", 0644)
+	writeFile(t, filepath.Join(dst, "was-file"), "file", 0644)
+	writeFile(t, filepath.Join(dst, "was-dir", "old.txt"), "old", 0644)
+
+	m, err := Sync(src, dst, SyncOptions{})
+	if err != nil {
+		t.Fatal("Sync:", err)
+	}
+	if !slices.Equal(m.Copied, []string{"was-file/inner.txt"}) || !slices.Equal(m.Overwritten, []string{"was-dir"}) {
+		t.Errorf("manifest = %+v", m)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "was-dir")); string(got) != "file" {
+		t.Errorf("was-dir = %q, want file content", got)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin
+
+package dircp
+
+// copyXattrs is a no-op on platforms without extended-attribute support in
+// golang.org/x/sys/unix.
+func copyXattrs(src, dst string) {}
@@ -0,0 +1,37 @@
+//go:build linux || darwin
+
+package dircp
+
+import (
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyXattrs copies the extended attributes of src onto dst without
+// following symlinks. It is best-effort: filesystems without xattr support
+// and attributes dst's owner may not set are skipped silently.
+func copyXattrs(src, dst string) {
+	size, err := unix.Llistxattr(src, nil)
+	if err != nil || size <= 0 {
+		return
+	}
+	names := make([]byte, size)
+	if size, err = unix.Llistxattr(src, names); err != nil {
+		return
+	}
+	for name := range strings.SplitSeq(string(names[:size]), "\x00") {
+		if name == "" {
+			continue
+		}
+		n, err := unix.Lgetxattr(src, name, nil)
+		if err != nil {
+			continue
+		}
+		val := make([]byte, n)
+		if n, err = unix.Lgetxattr(src, name, val); err != nil {
+			continue
+		}
+		_ = unix.Lsetxattr(dst, name, val[:n], 0)
+	}
+}
@@ -0,0 +1,247 @@
+// Package egress provides a domain-allowlisting HTTP forward proxy. Agent
+// processes run on the host rather than in a container with its own network
+// namespace, so outbound traffic is steered by pointing HTTP_PROXY /
+// HTTPS_PROXY at a [Proxy] that only connects to allowlisted hosts and
+// reports every request it sees. The confinement is advisory: a client that
+// ignores or clears those variables connects directly.
+package egress
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy is a domain allowlist. A host is allowed when it equals one of the
+// domains or is a subdomain of one: "example.com" admits "example.com" and
+// "docs.example.com" but not "badexample.com".
+type Policy struct {
+	Domains []string
+}
+
+// NormalizeDomain lowercases d and strips a URL scheme, path, port, leading
+// "*." wildcard, and surrounding dots, so "https://*.Example.com/docs" and
+// "example.com" name the same allowlist entry. Returns "" for input that
+// holds no host.
+func NormalizeDomain(d string) string {
+	d = strings.ToLower(strings.TrimSpace(d))
+	if _, rest, ok := strings.Cut(d, "://"); ok {
+		d = rest
+	}
+	if i := strings.IndexAny(d, "/?#"); i >= 0 {
+		d = d[:i]
+	}
+	if host, _, err := net.SplitHostPort(d); err == nil {
+		d = host
+	}
+	d = strings.TrimPrefix(d, "*.")
+	return strings.Trim(d, ".")
+}
+
+// Allows reports whether host (with or without a port) is on the allowlist.
+func (p Policy) Allows(host string) bool {
+	host = NormalizeDomain(host)
+	if host == "" {
+		return false
+	}
+	for _, d := range p.Domains {
+		d = NormalizeDomain(d)
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Request describes one request the proxy handled. URL is the full URL for
+// plain HTTP and "https://host" for tunnelled HTTPS, whose path the proxy
+// cannot see.
+type Request struct {
+	Host    string
+	URL     string
+	Allowed bool
+}
+
+// Proxy is a running allowlisting forward proxy bound to a loopback port.
+type Proxy struct {
+	policy    Policy
+	onRequest func(Request)
+	ln        net.Listener
+	srv       *http.Server
+	transport *http.Transport
+
+	mu      sync.Mutex
+	tunnels map[net.Conn]struct{} // hijacked CONNECT connections, closed on Close
+}
+
+// Start listens on a free loopback port and serves the proxy until Close.
+// onRequest, when non-nil, is called for every request, allowed or not; it
+// may be called concurrently.
+func Start(policy Policy, onRequest func(Request)) (*Proxy, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	p := &Proxy{
+		policy:    policy,
+		onRequest: onRequest,
+		ln:        ln,
+		// Never chain through the server's own proxy settings.
+		transport: &http.Transport{Proxy: nil},
+		tunnels:   make(map[net.Conn]struct{}),
+	}
+	p.srv = &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+	go func() { _ = p.srv.Serve(ln) }()
+	return p, nil
+}
+
+// URL returns the proxy address in the form HTTP_PROXY expects.
+func (p *Proxy) URL() string {
+	return "http://" + p.ln.Addr().String()
+}
+
+// Env returns the environment variables that route a child process's
+// traffic through the proxy. Both spellings are set because tools disagree
+// on which one they read, and NO_PROXY is cleared so an inherited value
+// cannot exempt hosts from the allowlist.
+func (p *Proxy) Env() map[string]string {
+	u := p.URL()
+	return map[string]string{
+		"HTTP_PROXY":  u,
+		"HTTPS_PROXY": u,
+		"ALL_PROXY":   u,
+		"http_proxy":  u,
+		"https_proxy": u,
+		"all_proxy":   u,
+		"NO_PROXY":    "",
+		"no_proxy":    "",
+	}
+}
+
+// Close stops the proxy and tears down open tunnels.
+func (p *Proxy) Close() error {
+	err := p.srv.Close()
+	p.mu.Lock()
+	for c := range p.tunnels {
+		_ = c.Close()
+	}
+	p.tunnels = nil
+	p.mu.Unlock()
+	p.transport.CloseIdleConnections()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.serveConnect(w, r)
+		return
+	}
+	if r.URL.Host == "" {
+		http.Error(w, "egress proxy: absolute URL required", http.StatusBadRequest)
+		return
+	}
+	allowed := p.policy.Allows(r.URL.Host)
+	p.report(Request{Host: r.URL.Hostname(), URL: r.URL.String(), Allowed: allowed})
+	if !allowed {
+		http.Error(w, "egress proxy: "+r.URL.Hostname()+" is not on the allowlist", http.StatusForbidden)
+		return
+	}
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	out.Header.Del("Proxy-Connection")
+	out.Header.Del("Proxy-Authorization")
+	resp, err := p.transport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, "egress proxy: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// serveConnect tunnels an HTTPS CONNECT request to an allowlisted host.
+func (p *Proxy) serveConnect(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	allowed := p.policy.Allows(host)
+	p.report(Request{Host: NormalizeDomain(host), URL: "https://" + NormalizeDomain(host), Allowed: allowed})
+	if !allowed {
+		http.Error(w, "egress proxy: "+NormalizeDomain(host)+" is not on the allowlist", http.StatusForbidden)
+		return
+	}
+	upstream, err := net.DialTimeout("tcp", host, 30*time.Second)
+	if err != nil {
+		http.Error(w, "egress proxy: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		_ = upstream.Close()
+		http.Error(w, "egress proxy: hijacking unsupported", http.StatusInternalServerError)
+		return
+	}
+	client, buf, err := hj.Hijack()
+	if err != nil {
+		_ = upstream.Close()
+		return
+	}
+	if !p.track(client, upstream) {
+		return
+	}
+	_, _ = client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+
+	go func() {
+		// Bytes the client sent after the CONNECT line may already sit in
+		// the hijacked reader's buffer.
+		_, _ = io.Copy(upstream, buf)
+		_ = upstream.Close()
+	}()
+	_, _ = io.Copy(client, upstream)
+	_ = client.Close()
+	p.untrack(client, upstream)
+}
+
+// track registers a tunnel's connections so Close can tear them down.
+// Returns false (after closing both) when the proxy is already closed.
+func (p *Proxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tunnels == nil {
+		for _, c := range conns {
+			_ = c.Close()
+		}
+		return false
+	}
+	for _, c := range conns {
+		p.tunnels[c] = struct{}{}
+	}
+	return true
+}
+
+func (p *Proxy) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range conns {
+		delete(p.tunnels, c)
+	}
+}
+
+func (p *Proxy) report(req Request) {
+	if p.onRequest != nil {
+		p.onRequest(req)
+	}
+}
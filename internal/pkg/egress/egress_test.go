@@ -0,0 +1,179 @@
+package egress
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	cases := map[string]string{
+		"Example.com":                 "example.com",
+		"https://*.example.com/docs":  "example.com",
+		"docs.example.com:443":        "docs.example.com",
+		" .example.com. ":             "example.com",
+		"http://[::1]:8080/x":         "::1",
+		"":                            "",
+		"https://":                    "",
+		"go.dev?tab=doc":              "go.dev",
+		"pkg.go.dev/net/http#Handler": "pkg.go.dev",
+	}
+	for in, want := range cases {
+		if got := NormalizeDomain(in); got != want {
+			t.Errorf("NormalizeDomain(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPolicyAllows(t *testing.T) {
+	p := Policy{Domains: []string{"example.com", "https://go.dev/"}}
+	for host, want := range map[string]bool{
+		"example.com":      true,
+		"docs.example.com": true,
+		"EXAMPLE.com:443":  true,
+		"go.dev":           true,
+		"pkg.go.dev":       true,
+		"badexample.com":   false,
+		"example.com.evil": false,
+		"":                 false,
+	} {
+		if got := p.Allows(host); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", host, got, want)
+		}
+	}
+	if (Policy{}).Allows("example.com") {
+		t.Error("empty policy must deny everything")
+	}
+}
+
+// proxiedClient returns an HTTP client that sends every request through p.
+func proxiedClient(t *testing.T, p *Proxy) *http.Client {
+	t.Helper()
+	u, err := url.Parse(p.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(u)}}
+}
+
+func TestProxy_ForwardsAllowedAndBlocksOthers(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello from "+r.URL.Path)
+	}))
+	defer upstream.Close()
+
+	var mu sync.Mutex
+	var seen []Request
+	// httptest listens on 127.0.0.1, so allowlisting that host admits it.
+	p, err := Start(Policy{Domains: []string{"127.0.0.1"}}, func(r Request) {
+		mu.Lock()
+		seen = append(seen, r)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+	client := proxiedClient(t, p)
+
+	resp, err := client.Get(upstream.URL + "/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello from /docs" {
+		t.Fatalf("allowed request: %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = client.Get("http://blocked.invalid/secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("blocked request status = %d, want 403", resp.StatusCode)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 2 {
+		t.Fatalf("reported %d requests, want 2: %+v", len(seen), seen)
+	}
+	if !seen[0].Allowed || !strings.HasSuffix(seen[0].URL, "/docs") {
+		t.Errorf("first report = %+v", seen[0])
+	}
+	if seen[1].Allowed || seen[1].Host != "blocked.invalid" {
+		t.Errorf("second report = %+v", seen[1])
+	}
+}
+
+func TestProxy_TunnelsAllowedHTTPS(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "secure")
+	}))
+	defer upstream.Close()
+
+	var reported []Request
+	var mu sync.Mutex
+	p, err := Start(Policy{Domains: []string{"127.0.0.1"}}, func(r Request) {
+		mu.Lock()
+		reported = append(reported, r)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	u, _ := url.Parse(p.URL())
+	tr := upstream.Client().Transport.(*http.Transport).Clone()
+	tr.Proxy = http.ProxyURL(u)
+	resp, err := (&http.Client{Transport: tr}).Get(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "secure" {
+		t.Fatalf("tunnelled body = %q", body)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || !reported[0].Allowed || reported[0].URL != "https://127.0.0.1" {
+		t.Errorf("reported = %+v", reported)
+	}
+}
+
+func TestProxy_RejectsDisallowedConnect(t *testing.T) {
+	p, err := Start(Policy{Domains: []string{"example.com"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	u, _ := url.Parse(p.URL())
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(u)}}
+	if _, err := client.Get("https://blocked.invalid/"); err == nil {
+		t.Fatal("expected the CONNECT to a disallowed host to fail")
+	}
+}
+
+func TestProxyEnv(t *testing.T) {
+	p, err := Start(Policy{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+	env := p.Env()
+	if env["HTTPS_PROXY"] != p.URL() || env["http_proxy"] != p.URL() {
+		t.Errorf("proxy vars = %v", env)
+	}
+	if v, ok := env["NO_PROXY"]; !ok || v != "" {
+		t.Errorf("NO_PROXY must be present and empty, got %q (present=%v)", v, ok)
+	}
+}
@@ -4,6 +4,7 @@
 package livelog
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"sync"
@@ -17,6 +18,8 @@ import (
 type Log struct {
 	mu     sync.Mutex
 	buf    []byte
+	base   int // stream offset of buf[0]; non-zero once a bounded Log drops data
+	max    int // bytes retained; 0 = unbounded
 	done   bool
 	notify chan struct{} // closed-and-replaced on every mutation to wake readers
 }
@@ -26,6 +29,15 @@ func New() *Log {
 	return &Log{notify: make(chan struct{})}
 }
 
+// NewBounded creates a Log that retains at most max bytes. When the buffer
+// grows past max, the oldest data is dropped, at a line boundary when
+// possible, keeping between half of max and max. A reader that falls behind the retained data
+// skips to the oldest line still held, so a late or slow reader sees the
+// recent output rather than all of it.
+func NewBounded(max int) *Log {
+	return &Log{notify: make(chan struct{}), max: max}
+}
+
 // Write appends p to the buffer and wakes all blocked readers.
 // It is safe to call from multiple goroutines. The wake mechanism uses a
 // close-and-replace pattern: the current notify channel is closed (waking
@@ -40,6 +52,9 @@ func (l *Log) Write(p []byte) (int, error) {
 		return len(p), nil
 	}
 	l.buf = append(l.buf, p...)
+	if l.max > 0 && len(l.buf) > l.max {
+		l.trim()
+	}
 	ch := l.notify
 	l.notify = make(chan struct{})
 	l.mu.Unlock()
@@ -47,6 +62,20 @@ func (l *Log) Write(p []byte) (int, error) {
 	return len(p), nil
 }
 
+// trim drops the oldest data of a bounded Log, keeping at least max/2
+// bytes and starting after a line break when one keeps the buffer within
+// max. Callers hold l.mu.
+func (l *Log) trim() {
+	cut := len(l.buf) - l.max/2
+	if i := bytes.LastIndexByte(l.buf[:cut], '\n'); i >= 0 && len(l.buf)-(i+1) <= l.max {
+		cut = i + 1
+	}
+	// Copy so the dropped prefix can be collected; readers only ever hold
+	// copies of the buffer.
+	l.buf = append([]byte(nil), l.buf[cut:]...)
+	l.base += cut
+}
+
 // Close seals the buffer. Readers that have consumed all data will
 // receive io.EOF on subsequent reads.
 func (l *Log) Close() {
@@ -62,12 +91,14 @@ func (l *Log) Close() {
 }
 
 // snapshot returns the current buffer contents (as a slice into the
-// internal array — caller must copy before releasing), whether the
+// internal array — caller must copy before releasing), the stream offset
+// of its first byte, whether the
 // writer has closed, and a channel that will be closed when the next
 // mutation occurs.
-func (l *Log) snapshot() (buf []byte, done bool, wake <-chan struct{}) {
+func (l *Log) snapshot() (buf []byte, base int, done bool, wake <-chan struct{}) {
 	l.mu.Lock()
 	buf = l.buf
+	base = l.base
 	done = l.done
 	wake = l.notify
 	l.mu.Unlock()
@@ -84,7 +115,7 @@ func (l *Log) NewReader() *Reader {
 // arrives or the Log is closed.
 type Reader struct {
 	log    *Log
-	offset int
+	offset int // stream offset of the next byte to return
 }
 
 // ReadChunk returns new data appended since the last call, blocking
@@ -92,11 +123,12 @@ type Reader struct {
 // context is cancelled.
 func (r *Reader) ReadChunk(ctx context.Context) ([]byte, error) {
 	for {
-		buf, done, wake := r.log.snapshot()
-		if r.offset < len(buf) {
-			data := make([]byte, len(buf)-r.offset)
-			copy(data, buf[r.offset:])
-			r.offset = len(buf)
+		buf, base, done, wake := r.log.snapshot()
+		r.offset = max(r.offset, base) // skip data a bounded Log dropped
+		if end := base + len(buf); r.offset < end {
+			data := make([]byte, end-r.offset)
+			copy(data, buf[r.offset-base:])
+			r.offset = end
 			return data, nil
 		}
 		if done {
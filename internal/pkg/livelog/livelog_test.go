@@ -53,3 +53,44 @@ func TestPostCloseReaderGetsEOF(t *testing.T) {
 		t.Fatalf("second ReadChunk err = %v, want io.EOF", err)
 	}
 }
+
+// TestBoundedDropsOldestLines verifies a bounded Log keeps only recent
+// output, cut at a line boundary, and that a reader that fell behind skips
+// to the retained data instead of reading stale offsets.
+func TestBoundedDropsOldestLines(t *testing.T) {
+	l := NewBounded(16)
+	r := l.NewReader()
+	_, _ = l.Write([]byte("line-1\nline-2\n"))
+	_, _ = l.Write([]byte("line-3\nline-4\n"))
+	l.Close()
+
+	got, err := r.ReadChunk(context.Background())
+	if err != nil {
+		t.Fatalf("ReadChunk err = %v", err)
+	}
+	if want := "line-3\nline-4\n"; string(got) != want {
+		t.Fatalf("ReadChunk = %q, want the retained tail %q", got, want)
+	}
+	if _, err := r.ReadChunk(context.Background()); err != io.EOF {
+		t.Fatalf("second ReadChunk err = %v, want io.EOF", err)
+	}
+}
+
+// TestBoundedReaderKeepsUp verifies that a reader consuming as data arrives
+// loses nothing when the bounded Log trims behind it.
+func TestBoundedReaderKeepsUp(t *testing.T) {
+	l := NewBounded(8)
+	r := l.NewReader()
+	var all []byte
+	for _, chunk := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n"} {
+		_, _ = l.Write([]byte(chunk))
+		got, err := r.ReadChunk(context.Background())
+		if err != nil {
+			t.Fatalf("ReadChunk err = %v", err)
+		}
+		all = append(all, got...)
+	}
+	if want := "aaaa\nbbbb\ncccc\ndddd\n"; string(all) != want {
+		t.Fatalf("read %q, want %q", all, want)
+	}
+}
@@ -61,6 +61,16 @@ func ReadFile[T any](path string, opts ...Option) ([]T, error) {
 	return readAll[T](f, &cfg)
 }
 
+// Read decodes each JSON line of r into T, like ReadFile does for a file.
+// Use it for NDJSON held in memory, e.g. after decrypting a file.
+func Read[T any](r io.Reader, opts ...Option) ([]T, error) {
+	var cfg config
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return readAll[T](io.NopCloser(r), &cfg)
+}
+
 // readAll reads and decodes all JSON lines from rc, then closes it.
 func readAll[T any](rc io.ReadCloser, cfg *config) ([]T, error) {
 	scanner := bufio.NewScanner(rc)
@@ -464,3 +464,18 @@ func writeTempFile(t *testing.T, content string) string {
 	}
 	return path
 }
+
+func TestRead_FromReader(t *testing.T) {
+	var lines []int
+	got, err := Read[record](strings.NewReader("{\"name\":\"a\",\"value\":1}\n\nnot json\n{\"name\":\"b\",\"value\":2}\n"),
+		WithOnError(func(lineNum int, _ error) { lines = append(lines, lineNum) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "a" || got[1].Value != 2 {
+		t.Errorf("Read = %+v", got)
+	}
+	if len(lines) != 1 || lines[0] != 3 {
+		t.Errorf("error lines = %v, want [3]", lines)
+	}
+}
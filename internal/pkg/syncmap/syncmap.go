@@ -32,6 +32,13 @@ func (m *Map[K, V]) Delete(key K) {
 	m.m.Delete(key)
 }
 
+// CompareAndDelete removes the entry for key if its value equals old and
+// reports whether it did. V must be comparable at run time (a pointer, not
+// a func or slice), or it panics.
+func (m *Map[K, V]) CompareAndDelete(key K, old V) bool {
+	return m.m.CompareAndDelete(key, old)
+}
+
 // Range calls fn for each entry. Iteration stops if fn returns false.
 func (m *Map[K, V]) Range(fn func(K, V) bool) {
 	m.m.Range(func(k, v any) bool {
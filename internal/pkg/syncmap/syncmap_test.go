@@ -44,6 +44,25 @@ func TestMap_Delete(t *testing.T) {
 	}
 }
 
+// TestMap_CompareAndDelete verifies that an entry is only removed while it
+// still holds the given value.
+func TestMap_CompareAndDelete(t *testing.T) {
+	var m Map[string, *int]
+	first, second := new(int), new(int)
+
+	m.Store("k", first)
+	m.Store("k", second)
+	if m.CompareAndDelete("k", first) {
+		t.Fatal("CompareAndDelete removed an entry holding another value")
+	}
+	if !m.CompareAndDelete("k", second) {
+		t.Fatal("CompareAndDelete kept an entry holding the given value")
+	}
+	if _, ok := m.Load("k"); ok {
+		t.Fatal("expected entry to be deleted")
+	}
+}
+
 // TestMap_Range verifies that Range visits all stored entries.
 func TestMap_Range(t *testing.T) {
 	var m Map[int, string]
@@ -0,0 +1,297 @@
+// Package tlscert builds the server's TLS configuration from one of three
+// certificate sources: a certificate and key on disk, a self-signed
+// certificate generated and kept under a directory, or ACME (Let's Encrypt).
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+)
+
+// nextProtos is the ALPN list the server offers. HTTP/2 is left out on
+// purpose: the terminal WebSocket needs an HTTP/1.1 upgrade, which a browser
+// cannot make on a connection that negotiated h2.
+var nextProtos = []string{"http/1.1"}
+
+// Self-signed certificate file names inside the directory passed to SelfSigned.
+const (
+	selfSignedCertFile = "self-signed.crt"
+	selfSignedKeyFile  = "self-signed.key"
+)
+
+// selfSignedValidity is the lifetime of a generated certificate, and
+// selfSignedRenewBefore how close to expiry a stored one is replaced.
+const (
+	selfSignedValidity    = 365 * 24 * time.Hour
+	selfSignedRenewBefore = 30 * 24 * time.Hour
+)
+
+// baseConfig returns the settings shared by every source.
+func baseConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: slices.Clone(nextProtos),
+	}
+}
+
+// FromFiles returns a configuration serving the PEM certificate chain and
+// private key at certFile and keyFile. The pair is loaded once up front, so a
+// bad path fails at startup, and reloaded on a handshake after either file's
+// modification time changes, so a renewed certificate is picked up without a
+// restart.
+func FromFiles(certFile, keyFile string) (*tls.Config, error) {
+	kp := &keyPair{certFile: certFile, keyFile: keyFile}
+	if _, err := kp.get(); err != nil {
+		return nil, err
+	}
+	cfg := baseConfig()
+	cfg.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return kp.get() }
+	return cfg, nil
+}
+
+// keyPair caches a certificate loaded from disk and reloads it when the files
+// change.
+type keyPair struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// get returns the cached certificate, reloading it when the newer of the two
+// files' modification times has moved. A failed reload keeps serving the
+// previous certificate.
+func (kp *keyPair) get() (*tls.Certificate, error) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	mod, err := newestModTime(kp.certFile, kp.keyFile)
+	if err != nil {
+		if kp.cert != nil {
+			return kp.cert, nil
+		}
+		return nil, err
+	}
+	if kp.cert != nil && mod.Equal(kp.modTime) {
+		return kp.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(kp.certFile, kp.keyFile)
+	if err != nil {
+		if kp.cert != nil {
+			return kp.cert, nil
+		}
+		return nil, fmt.Errorf("load TLS key pair: %w", err)
+	}
+	kp.cert, kp.modTime = &cert, mod
+	return kp.cert, nil
+}
+
+// newestModTime returns the latest modification time among paths.
+func newestModTime(paths ...string) (time.Time, error) {
+	var newest time.Time
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest, nil
+}
+
+// SelfSigned returns a configuration serving a self-signed certificate kept
+// in dir, along with the certificate's SHA-256 fingerprint so users can check
+// it against the browser's warning. The certificate covers localhost, the
+// loopback addresses, and hosts (names or IPs). A stored certificate is
+// reused while it covers every host and is more than 30 days from expiry;
+// otherwise a new one is generated and saved, with the key readable only by
+// the owner.
+func SelfSigned(dir string, hosts []string) (*tls.Config, string, error) {
+	hosts = selfSignedHosts(hosts)
+	certPath := filepath.Join(dir, selfSignedCertFile)
+	keyPath := filepath.Join(dir, selfSignedKeyFile)
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil || !selfSignedUsable(cert, hosts, time.Now()) {
+		certPEM, keyPEM, err := generateSelfSigned(hosts, time.Now())
+		if err != nil {
+			return nil, "", err
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, "", err
+		}
+		if err := atomicfile.Write(keyPath, keyPEM, 0600); err != nil {
+			return nil, "", err
+		}
+		if err := atomicfile.Write(certPath, certPEM, 0644); err != nil {
+			return nil, "", err
+		}
+		if cert, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+			return nil, "", err
+		}
+	}
+	cfg := baseConfig()
+	cfg.Certificates = []tls.Certificate{cert}
+	return cfg, Fingerprint(cert.Certificate[0]), nil
+}
+
+// Fingerprint formats the SHA-256 digest of a DER certificate as
+// colon-separated upper-case hex, the form browsers display.
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	h := strings.ToUpper(hex.EncodeToString(sum[:]))
+	parts := make([]string, 0, len(sum))
+	for i := 0; i < len(h); i += 2 {
+		parts = append(parts, h[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+// selfSignedHosts returns hosts plus the loopback names, deduplicated, with
+// wildcard bind addresses dropped.
+func selfSignedHosts(hosts []string) []string {
+	out := []string{"localhost", "127.0.0.1", "::1"}
+	for _, h := range hosts {
+		h = strings.TrimSpace(h)
+		switch h {
+		case "", "0.0.0.0", "::", "[::]":
+			continue
+		}
+		if !slices.Contains(out, h) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// selfSignedUsable reports whether a stored certificate covers every host
+// and is not close to expiry.
+func selfSignedUsable(cert tls.Certificate, hosts []string, now time.Time) bool {
+	if len(cert.Certificate) == 0 {
+		return false
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil || now.Add(selfSignedRenewBefore).After(leaf.NotAfter) {
+		return false
+	}
+	for _, h := range hosts {
+		if leaf.VerifyHostname(h) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// generateSelfSigned creates an ECDSA P-256 certificate for hosts valid from
+// now, returning the PEM-encoded certificate and private key.
+func generateSelfSigned(hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"wallfacer"}, CommonName: hosts[0]},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// ACMEConfig configures ACME certificate issuance.
+type ACMEConfig struct {
+	// Domains lists the host names certificates are issued for. Requests
+	// for any other name are refused, so a stray SNI cannot trigger issuance.
+	Domains []string
+	// Email is the optional contact address given to the CA for expiry and
+	// account notices.
+	Email string
+	// CacheDir holds the account key and issued certificates between runs.
+	CacheDir string
+	// DirectoryURL is the CA's ACME directory; empty means Let's Encrypt
+	// production.
+	DirectoryURL string
+}
+
+// ACME returns a configuration that obtains and renews certificates from an
+// ACME CA, plus the handler to serve on port 80. The handler answers HTTP-01
+// challenges and redirects every other request to HTTPS; the TLS-ALPN-01
+// challenge is answered on the TLS listener itself, so port 80 is optional
+// when the TLS listener is reachable on 443. Submitting the first request
+// accepts the CA's terms of service.
+func ACME(cfg ACMEConfig) (*tls.Config, http.Handler, error) {
+	var domains []string
+	for _, d := range cfg.Domains {
+		if d = strings.TrimSpace(d); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	if len(domains) == 0 {
+		return nil, nil, errors.New("acme: at least one domain is required")
+	}
+	if cfg.CacheDir == "" {
+		return nil, nil, errors.New("acme: a cache directory is required")
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		Email:      cfg.Email,
+	}
+	if cfg.DirectoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	}
+	tlsCfg := baseConfig()
+	tlsCfg.GetCertificate = m.GetCertificate
+	tlsCfg.NextProtos = append(tlsCfg.NextProtos, acme.ALPNProto)
+	return tlsCfg, m.HTTPHandler(nil), nil
+}
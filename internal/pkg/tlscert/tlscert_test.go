@@ -0,0 +1,157 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+func leaf(t *testing.T, cert tls.Certificate) *x509.Certificate {
+	t.Helper()
+	c, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// TestSelfSignedReusesStoredCertificate verifies that a generated
+// certificate covers the loopback names and extra hosts, is persisted with a
+// private key, and is reused on the next start.
+func TestSelfSignedReusesStoredCertificate(t *testing.T) {
+	dir := t.TempDir()
+	cfg, fp, err := SelfSigned(dir, []string{"board.lan", "192.168.1.10", "0.0.0.0"})
+	if err != nil {
+		t.Fatal("SelfSigned:", err)
+	}
+	c := leaf(t, cfg.Certificates[0])
+	for _, h := range []string{"localhost", "127.0.0.1", "::1", "board.lan", "192.168.1.10"} {
+		if err := c.VerifyHostname(h); err != nil {
+			t.Errorf("certificate does not cover %s: %v", h, err)
+		}
+	}
+	if fp != Fingerprint(cfg.Certificates[0].Certificate[0]) || len(fp) != 32*3-1 {
+		t.Errorf("fingerprint = %q", fp)
+	}
+	info, err := os.Stat(filepath.Join(dir, selfSignedKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	_, again, err := SelfSigned(dir, []string{"board.lan"})
+	if err != nil {
+		t.Fatal("second SelfSigned:", err)
+	}
+	if again != fp {
+		t.Errorf("stored certificate was not reused: %s != %s", again, fp)
+	}
+
+	// A host the stored certificate does not cover forces a new one.
+	_, other, err := SelfSigned(dir, []string{"other.lan"})
+	if err != nil {
+		t.Fatal("third SelfSigned:", err)
+	}
+	if other == fp {
+		t.Error("certificate not regenerated for a new host")
+	}
+}
+
+// TestSelfSignedUsableNearExpiry verifies that a certificate within the
+// renewal window is replaced.
+func TestSelfSignedUsableNearExpiry(t *testing.T) {
+	hosts := selfSignedHosts(nil)
+	certPEM, keyPEM, err := generateSelfSigned(hosts, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !selfSignedUsable(cert, hosts, time.Now()) {
+		t.Error("fresh certificate reported unusable")
+	}
+	if selfSignedUsable(cert, hosts, time.Now().Add(selfSignedValidity-selfSignedRenewBefore/2)) {
+		t.Error("certificate inside the renewal window reported usable")
+	}
+}
+
+// TestFromFilesReloadsOnChange verifies that a replaced key pair is served
+// after its files change, and that a missing pair fails up front.
+func TestFromFilesReloadsOnChange(t *testing.T) {
+	if _, err := FromFiles(filepath.Join(t.TempDir(), "missing.crt"), "missing.key"); err == nil {
+		t.Fatal("FromFiles with missing files succeeded")
+	}
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	write := func(host string, mod time.Time) {
+		t.Helper()
+		certPEM, keyPEM, err := generateSelfSigned([]string{host}, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for path, data := range map[string][]byte{certPath: certPEM, keyPath: keyPEM} {
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, mod, mod); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	write("first.example", time.Now().Add(-time.Hour))
+	cfg, err := FromFiles(certPath, keyPath)
+	if err != nil {
+		t.Fatal("FromFiles:", err)
+	}
+	get := func() string {
+		t.Helper()
+		c, err := cfg.GetCertificate(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf(t, *c).Subject.CommonName
+	}
+	if got := get(); got != "first.example" {
+		t.Fatalf("served %q, want first.example", got)
+	}
+	write("second.example", time.Now())
+	if got := get(); got != "second.example" {
+		t.Errorf("after renewal served %q, want second.example", got)
+	}
+	if !slices.Equal(cfg.NextProtos, []string{"http/1.1"}) {
+		t.Errorf("NextProtos = %v, want http/1.1 only", cfg.NextProtos)
+	}
+}
+
+func TestACMEValidation(t *testing.T) {
+	if _, _, err := ACME(ACMEConfig{CacheDir: t.TempDir()}); err == nil {
+		t.Error("ACME without domains succeeded")
+	}
+	if _, _, err := ACME(ACMEConfig{Domains: []string{"board.example"}}); err == nil {
+		t.Error("ACME without a cache directory succeeded")
+	}
+	cfg, h, err := ACME(ACMEConfig{Domains: []string{" board.example "}, CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatal("ACME:", err)
+	}
+	if h == nil || cfg.GetCertificate == nil || !slices.Contains(cfg.NextProtos, acme.ALPNProto) {
+		t.Errorf("ACME config = %+v, handler %v; want a certificate getter, the TLS-ALPN protocol, and a handler", cfg, h)
+	}
+	// A name outside the allow list is refused without contacting the CA.
+	if _, err := cfg.GetCertificate(&tls.ClientHelloInfo{ServerName: "evil.example"}); err == nil {
+		t.Error("certificate requested for a domain outside the allow list")
+	}
+}
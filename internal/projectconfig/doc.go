@@ -0,0 +1,25 @@
+// Package projectconfig reads wallfacer.yaml, the board configuration a
+// team checks into a workspace folder so it is versioned with the code.
+//
+// The file declares defaults for the tasks created on a board (the agent's
+// harness and model, the merge mode, and labels added as tags), an
+// instructions snippet appended to every fresh agent prompt, a verify
+// command the commit pipeline runs before merging, and paths it leaves out
+// of commits. [Load] reads one folder's file; [LoadAll] merges the files of
+// a workspace group's folders. Both read the file on every call, so an edit
+// applies to the next task without a restart.
+//
+// # Connected packages
+//
+// Depends on [harness] and [store] to validate the agent and merge mode.
+// Consumed by [handler] (task defaults), [runner] (instructions, verify, and
+// ignore paths), and [cli] (checks the files at server start).
+//
+// # Usage
+//
+//	cfg, err := projectconfig.LoadAll(workspaceFolders)
+//	if err != nil {
+//		logger.Main.Warn("wallfacer.yaml", "error", err)
+//	}
+//	opts.Tags = cfg.AddLabels(opts.Tags)
+package projectconfig
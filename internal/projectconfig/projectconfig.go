@@ -0,0 +1,157 @@
+package projectconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// Filename is the name of the configuration file in a workspace folder.
+const Filename = "wallfacer.yaml"
+
+// Agent is the default agent profile for new tasks. Model applies to
+// Sandbox's harness; empty fields fall back to the env-file defaults.
+type Agent struct {
+	Sandbox harness.ID
+	Model   string
+}
+
+// Config is the parsed content of one or more wallfacer.yaml files.
+type Config struct {
+	Agent Agent
+	// Instructions is appended to every fresh prompt of the task's agent.
+	Instructions string
+	// Verify is a shell command run in the task's worktree before merging,
+	// next to WALLFACER_PRE_MERGE_LINT; a failure gets the agent one
+	// feedback turn.
+	Verify string
+	// MergeMode is the merge mode of new tasks that do not choose one.
+	MergeMode store.MergeMode
+	// Ignore lists .gitignore-style patterns for untracked paths the commit
+	// pipeline leaves unstaged, on top of the workspace's stage-ignore list.
+	Ignore []string
+	// Labels are added to the tags of every new task.
+	Labels []string
+}
+
+// diskConfig is the on-disk YAML shape of wallfacer.yaml.
+type diskConfig struct {
+	Agent struct {
+		Sandbox string `yaml:"sandbox"`
+		Model   string `yaml:"model"`
+	} `yaml:"agent"`
+	Instructions string   `yaml:"instructions"`
+	Verify       string   `yaml:"verify"`
+	MergeMode    string   `yaml:"merge_mode"`
+	Ignore       []string `yaml:"ignore"`
+	Labels       []string `yaml:"labels"`
+}
+
+// Load reads folder's wallfacer.yaml. A missing file is not an error and
+// yields the zero Config. Unknown keys, an unregistered harness, and an
+// unknown merge mode are errors, since silently skipping them masks typos.
+func Load(folder string) (Config, error) {
+	path := filepath.Join(folder, Filename)
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+	var d diskConfig
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&d); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	cfg := Config{
+		Agent:        Agent{Model: strings.TrimSpace(d.Agent.Model)},
+		Instructions: strings.TrimSpace(d.Instructions),
+		Verify:       strings.TrimSpace(d.Verify),
+		Ignore:       cleanList(d.Ignore),
+		Labels:       cleanList(d.Labels),
+	}
+	if d.Agent.Sandbox != "" {
+		sb, ok := harness.ParseID(d.Agent.Sandbox)
+		if !ok {
+			return Config{}, fmt.Errorf("parse %s: unknown agent sandbox %q", path, d.Agent.Sandbox)
+		}
+		cfg.Agent.Sandbox = sb
+	}
+	if cfg.MergeMode, err = store.NormalizeMergeMode(d.MergeMode); err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadAll reads and merges the wallfacer.yaml files of a workspace group's
+// folders, in order. The first folder that sets the agent, the verify
+// command, or the merge mode provides it; instructions are joined, and
+// ignore patterns and labels are combined without duplicates. A file that
+// fails to load is skipped and its error returned alongside the merge of
+// the others.
+func LoadAll(folders []string) (Config, error) {
+	var merged Config
+	var errs []error
+	var instructions []string
+	for _, folder := range folders {
+		cfg, err := Load(folder)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if merged.Agent == (Agent{}) {
+			merged.Agent = cfg.Agent
+		}
+		if merged.Verify == "" {
+			merged.Verify = cfg.Verify
+		}
+		if merged.MergeMode == store.MergeModeMerge {
+			merged.MergeMode = cfg.MergeMode
+		}
+		if cfg.Instructions != "" {
+			instructions = append(instructions, cfg.Instructions)
+		}
+		merged.Ignore = appendNew(merged.Ignore, cfg.Ignore)
+		merged.Labels = appendNew(merged.Labels, cfg.Labels)
+	}
+	merged.Instructions = strings.Join(instructions, "\n\n")
+	return merged, errors.Join(errs...)
+}
+
+// AddLabels returns tags with the configured labels it lacks appended.
+func (c Config) AddLabels(tags []string) []string {
+	return appendNew(tags, c.Labels)
+}
+
+// cleanList trims every entry and drops the empty ones.
+func cleanList(list []string) []string {
+	var out []string
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// appendNew appends the entries of add that dst does not contain yet.
+func appendNew(dst, add []string) []string {
+	for _, s := range add {
+		if !slices.Contains(dst, s) {
+			dst = append(dst, s)
+		}
+	}
+	return dst
+}
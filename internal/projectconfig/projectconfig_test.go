@@ -0,0 +1,103 @@
+package projectconfig
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, Filename), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeConfig(t, `agent:
+  sandbox: Codex
+  model: gpt-5-codex
+instructions: |
+  Run make lint before finishing.
+verify: go test ./...
+merge_mode: pull_request
+ignore: [dist/, " ", coverage.out]
+labels: [backend]
+`)
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Agent != (Agent{Sandbox: harness.Codex, Model: "gpt-5-codex"}) {
+		t.Errorf("Agent = %+v", cfg.Agent)
+	}
+	if cfg.Instructions != "Run make lint before finishing." || cfg.Verify != "go test ./..." {
+		t.Errorf("Instructions = %q, Verify = %q", cfg.Instructions, cfg.Verify)
+	}
+	if cfg.MergeMode != store.MergeModePullRequest {
+		t.Errorf("MergeMode = %q", cfg.MergeMode)
+	}
+	if !slices.Equal(cfg.Ignore, []string{"dist/", "coverage.out"}) || !slices.Equal(cfg.Labels, []string{"backend"}) {
+		t.Errorf("Ignore = %q, Labels = %q", cfg.Ignore, cfg.Labels)
+	}
+}
+
+func TestLoad_MissingAndEmpty(t *testing.T) {
+	for _, dir := range []string{t.TempDir(), writeConfig(t, "")} {
+		cfg, err := Load(dir)
+		if err != nil {
+			t.Fatalf("Load(%s): %v", dir, err)
+		}
+		if cfg.Agent != (Agent{}) || cfg.Verify != "" || cfg.Labels != nil {
+			t.Errorf("Load(%s) = %+v, want zero", dir, cfg)
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, body := range map[string]string{
+		"unknown key":     "verfy: make test\n",
+		"unknown sandbox": "agent:\n  sandbox: nope\n",
+		"unknown merge":   "merge_mode: squash\n",
+		"malformed":       "labels: [a\n",
+	} {
+		if _, err := Load(writeConfig(t, body)); err == nil {
+			t.Errorf("%s: Load succeeded, want error", name)
+		} else if !strings.Contains(err.Error(), Filename) {
+			t.Errorf("%s: error %q does not name the file", name, err)
+		}
+	}
+}
+
+func TestLoadAll_Merges(t *testing.T) {
+	a := writeConfig(t, "instructions: Use tabs.\nverify: make test\nignore: [dist/]\nlabels: [web]\n")
+	b := writeConfig(t, "agent:\n  sandbox: claude\nmerge_mode: pull_request\ninstructions: Keep the API stable.\nverify: go test ./...\nignore: [dist/, out/]\nlabels: [web, api]\n")
+	broken := writeConfig(t, "labels: [x\n")
+
+	cfg, err := LoadAll([]string{a, broken, b})
+	if err == nil {
+		t.Error("LoadAll: want the broken file's error")
+	}
+	if cfg.Agent.Sandbox != harness.Claude || cfg.MergeMode != store.MergeModePullRequest {
+		t.Errorf("Agent = %+v, MergeMode = %q", cfg.Agent, cfg.MergeMode)
+	}
+	if cfg.Verify != "make test" {
+		t.Errorf("Verify = %q, want the first folder's", cfg.Verify)
+	}
+	if cfg.Instructions != "Use tabs.\n\nKeep the API stable." {
+		t.Errorf("Instructions = %q", cfg.Instructions)
+	}
+	if !slices.Equal(cfg.Ignore, []string{"dist/", "out/"}) || !slices.Equal(cfg.Labels, []string{"web", "api"}) {
+		t.Errorf("Ignore = %q, Labels = %q", cfg.Ignore, cfg.Labels)
+	}
+	if got := cfg.AddLabels([]string{"api", "urgent"}); !slices.Equal(got, []string{"api", "urgent", "web"}) {
+		t.Errorf("AddLabels = %q", got)
+	}
+}
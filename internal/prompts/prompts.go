@@ -306,6 +306,9 @@ type CommitData struct {
 	Prompt    string
 	DiffStat  string
 	RecentLog string // optional; rendered only when non-empty
+	// Style holds subject-line rules derived from the repository's commit
+	// history; when set they replace the default path-prefix format.
+	Style string
 }
 
 // ConflictData holds template variables for the conflict resolution prompt.
@@ -314,6 +317,27 @@ type ConflictData struct {
 	DefaultBranch string
 }
 
+// LintFixData holds template variables for the pre-merge lint feedback
+// prompt: the linter failures the agent is asked to fix.
+type LintFixData struct {
+	Failures []LintFailure
+}
+
+// LintFailure is one failing linter command in one repository.
+type LintFailure struct {
+	Repo    string
+	Command string
+	Output  string
+}
+
+// ResearchData holds template variables for the research-task wrapper: the
+// user's question, the egress allowlist, and the time box in minutes.
+type ResearchData struct {
+	Prompt         string
+	Domains        []string
+	TimeBoxMinutes int
+}
+
 // DriftData holds template variables for the drift-assessment prompt: the
 // spec body and the task's actual changes. Affects and ChangedFiles are
 // newline-joined for rendering.
@@ -324,6 +348,117 @@ type DriftData struct {
 	Diff         string
 }
 
+// EstimateData holds template variables for the pre-run effort-estimation
+// prompt. RepoLayout is a pre-formatted file listing; History is an optional
+// pre-formatted table of past estimates versus actuals.
+type EstimateData struct {
+	Prompt     string
+	RepoLayout string
+	History    string // optional; rendered only when non-empty
+}
+
+// LocalizeData holds template variables for the prompt that translates a
+// task's title and final summary. Locale is a BCP 47 language tag.
+type LocalizeData struct {
+	Locale  string
+	Title   string
+	Summary string
+}
+
+// ForkData holds template variables for the first turn of a forked task.
+// Progress is a pre-formatted summary of the parent's oversight phases and
+// Result the parent agent's last message; both are optional.
+type ForkData struct {
+	Prompt   string
+	Progress string // optional; rendered only when non-empty
+	Result   string // optional; rendered only when non-empty
+	Feedback string
+}
+
+// TaskLinksData holds template variables for the external links appended to
+// a task's prompt.
+type TaskLinksData struct {
+	Prompt string
+	Links  []TaskLink
+}
+
+// TaskLink is one external reference listed in a task's prompt.
+type TaskLink struct {
+	Type  string
+	URL   string
+	Title string // optional
+}
+
+// TaskAttachmentsData holds template variables for the list of attached
+// files appended to a task's prompt.
+type TaskAttachmentsData struct {
+	Prompt string
+	Dir    string // directory the files were written to
+	Files  []TaskAttachment
+}
+
+// TaskAttachment is one file listed in a task's prompt.
+type TaskAttachment struct {
+	Name        string
+	ContentType string // optional
+	Size        int64
+}
+
+// ApprovalGatesData holds template variables for the approval protocol
+// appended to a task's prompt.
+type ApprovalGatesData struct {
+	Prompt string
+	Tag    string // info string of the fenced block that carries a request
+}
+
+// ApprovalDecisionData holds template variables for the message that sends
+// the user's decision on an approval request back to the agent.
+type ApprovalDecisionData struct {
+	Approved bool
+	Action   string
+	Command  string
+	Note     string
+}
+
+// ContextPackData holds template variables for the context pack appended to
+// a task's first prompt.
+type ContextPackData struct {
+	Prompt  string
+	Files   []ContextPackFile
+	Omitted []string // paths left out: binary, unreadable, or over budget
+}
+
+// ContextPackFile is one file of a context pack: its full contents, or an
+// outline of numbered head and declaration lines when Summarized.
+type ContextPackFile struct {
+	Path       string
+	Content    string
+	Fence      string // code fence that does not occur in Content
+	Summarized bool
+	Lines      int // total lines in the file
+}
+
+// WorkspacePathsData holds template variables for the note appended to a
+// prompt whose workspace paths were rewritten to the task's worktrees.
+type WorkspacePathsData struct {
+	Prompt   string
+	Rewrites []PathRewrite
+}
+
+// PathRewrite maps a workspace directory named in a prompt to the task
+// worktree it was rewritten to.
+type PathRewrite struct {
+	From string
+	To   string
+}
+
+// SessionImportData holds template variables for the first turn of a task
+// adopted from a Claude Code session started outside wallfacer.
+type SessionImportData struct {
+	Cwd          string
+	Instructions string // optional; rendered only when non-empty
+}
+
 // TestData holds template variables for the test verification prompt.
 type TestData struct {
 	OriginalPrompt string
@@ -351,12 +486,73 @@ func (m *Manager) Title(taskPrompt string) string {
 	return m.render("title.tmpl", struct{ Prompt string }{taskPrompt})
 }
 
+// TitleBatch renders the prompt that titles several tasks in one agent call.
+// The agent answers with a JSON array holding one title per prompt, in order.
+func (m *Manager) TitleBatch(taskPrompts []string) string {
+	return m.render("title_batch.tmpl", struct{ Prompts []string }{taskPrompts})
+}
+
 // CommitMessage renders the commit message generation prompt.
 func (m *Manager) CommitMessage(d CommitData) string { return m.render("commit.tmpl", d) }
 
+// LintFix renders the feedback prompt for the pre-merge lint stage.
+func (m *Manager) LintFix(d LintFixData) string { return m.render("lint_fix.tmpl", d) }
+
+// Research renders the prompt a research task runs with: the user's
+// question framed by the domain allowlist, time box, and citation rules.
+func (m *Manager) Research(d ResearchData) string { return m.render("research.tmpl", d) }
+
 // DriftAssessment renders the task-done drift-assessment prompt.
 func (m *Manager) DriftAssessment(d DriftData) string { return m.render("drift.tmpl", d) }
 
+// Estimate renders the pre-run effort-estimation prompt.
+func (m *Manager) Estimate(d EstimateData) string { return m.render("estimate.tmpl", d) }
+
+// Localize renders the prompt that translates a task's title and summary.
+func (m *Manager) Localize(d LocalizeData) string { return m.render("localize.tmpl", d) }
+
+// Fork renders the prompt that opens a forked task's fresh session with a
+// summary of its parent's session and the feedback the fork explores.
+func (m *Manager) Fork(d ForkData) string { return m.render("fork.tmpl", d) }
+
+// TaskLinks renders a task's prompt followed by its external links, offered
+// as optional context the agent may fetch.
+func (m *Manager) TaskLinks(d TaskLinksData) string { return m.render("task_links.tmpl", d) }
+
+// TaskAttachments renders a task's prompt followed by the files filed with
+// it and the directory they were written to.
+func (m *Manager) TaskAttachments(d TaskAttachmentsData) string {
+	return m.render("task_attachments.tmpl", d)
+}
+
+// ApprovalGates renders a task's prompt followed by the protocol the agent
+// uses to ask for approval before a risky action.
+func (m *Manager) ApprovalGates(d ApprovalGatesData) string {
+	return m.render("approval_gates.tmpl", d)
+}
+
+// ApprovalDecision renders the message that tells the agent whether its
+// approval request was granted.
+func (m *Manager) ApprovalDecision(d ApprovalDecisionData) string {
+	return m.render("approval_decision.tmpl", d)
+}
+
+// ContextPack renders a task's prompt followed by the contents of the files
+// the user attached to it.
+func (m *Manager) ContextPack(d ContextPackData) string { return m.render("context_pack.tmpl", d) }
+
+// WorkspacePaths renders a prompt whose workspace paths were rewritten,
+// followed by the mapping from each workspace to its task worktree.
+func (m *Manager) WorkspacePaths(d WorkspacePathsData) string {
+	return m.render("workspace_paths.tmpl", d)
+}
+
+// SessionImport renders the prompt that resumes an adopted terminal session
+// inside its task worktree.
+func (m *Manager) SessionImport(d SessionImportData) string {
+	return m.render("session_import.tmpl", d)
+}
+
 // ConflictResolution renders the rebase conflict resolution prompt.
 func (m *Manager) ConflictResolution(d ConflictData) string { return m.render("conflict.tmpl", d) }
 
@@ -393,12 +589,37 @@ func Oversight(activityLog string) string { return Default.Oversight(activityLog
 // Title renders the title-generation prompt for the given task prompt.
 func Title(taskPrompt string) string { return Default.Title(taskPrompt) }
 
+// TitleBatch renders the multi-task title-generation prompt.
+func TitleBatch(taskPrompts []string) string { return Default.TitleBatch(taskPrompts) }
+
 // CommitMessage renders the commit message generation prompt.
 func CommitMessage(d CommitData) string { return Default.CommitMessage(d) }
 
+// LintFix renders the pre-merge lint feedback prompt.
+func LintFix(d LintFixData) string { return Default.LintFix(d) }
+
+// Research renders the research-task prompt.
+func Research(d ResearchData) string { return Default.Research(d) }
+
 // DriftAssessment renders the task-done drift-assessment prompt.
 func DriftAssessment(d DriftData) string { return Default.DriftAssessment(d) }
 
+// Estimate renders the pre-run effort-estimation prompt.
+func Estimate(d EstimateData) string { return Default.Estimate(d) }
+
+// Localize renders the title and summary translation prompt.
+func Localize(d LocalizeData) string { return Default.Localize(d) }
+
+// Fork renders the first-turn prompt of a forked task.
+func Fork(d ForkData) string { return Default.Fork(d) }
+
+// ApprovalDecision renders the message carrying a decision on an agent's
+// approval request.
+func ApprovalDecision(d ApprovalDecisionData) string { return Default.ApprovalDecision(d) }
+
+// SessionImport renders the first-turn prompt of an adopted terminal session.
+func SessionImport(d SessionImportData) string { return Default.SessionImport(d) }
+
 // ConflictResolution renders the rebase conflict resolution prompt.
 func ConflictResolution(d ConflictData) string { return Default.ConflictResolution(d) }
 
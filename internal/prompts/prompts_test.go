@@ -303,6 +303,20 @@ func TestCommitMessage_ReturnsNonEmptyRendered(t *testing.T) {
 	}
 }
 
+// TestCommitMessage_StyleReplacesDefaultFormat verifies that a learned
+// commit style takes the place of the default path-prefix subject rule.
+func TestCommitMessage_StyleReplacesDefaultFormat(t *testing.T) {
+	mgr := prompts.NewManager(t.TempDir())
+	plain := mgr.CommitMessage(prompts.CommitData{Prompt: "p", DiffStat: "d"})
+	if !strings.Contains(plain, "<primary-path>") {
+		t.Fatalf("default prompt lacks the path-prefix rule:\n%s", plain)
+	}
+	styled := mgr.CommitMessage(prompts.CommitData{Prompt: "p", DiffStat: "d", Style: "- Subject line format: Conventional Commits"})
+	if strings.Contains(styled, "<primary-path>") || !strings.Contains(styled, "Conventional Commits") {
+		t.Errorf("styled prompt did not replace the default rule:\n%s", styled)
+	}
+}
+
 func TestDriftAssessment_ReturnsNonEmptyRendered(t *testing.T) {
 	mgr := prompts.NewManager(t.TempDir())
 	got := mgr.DriftAssessment(prompts.DriftData{
@@ -324,6 +338,170 @@ func TestDriftAssessment_ReturnsNonEmptyRendered(t *testing.T) {
 	}
 }
 
+func TestEstimate_RendersLayoutAndOptionalHistory(t *testing.T) {
+	mgr := prompts.NewManager(t.TempDir())
+	got := mgr.Estimate(prompts.EstimateData{
+		Prompt:     "Add a retry flag",
+		RepoLayout: "repo/\n  main.go",
+	})
+	if strings.Contains(got, "{{") {
+		t.Errorf("unreplaced template syntax: %q", got)
+	}
+	for _, want := range []string{"Add a retry flag", "main.go", `"turns"`, `"risk"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q", want)
+		}
+	}
+	if strings.Contains(got, "for calibration") {
+		t.Error("history section should be omitted when History is empty")
+	}
+
+	withHistory := mgr.Estimate(prompts.EstimateData{
+		Prompt:     "Add a retry flag",
+		RepoLayout: "repo/",
+		History:    "- fix typo: est 2 turns, actual 3 turns",
+	})
+	if !strings.Contains(withHistory, "est 2 turns, actual 3 turns") {
+		t.Error("history section missing when History is set")
+	}
+}
+
+func TestLocalize_RendersLocaleAndSources(t *testing.T) {
+	mgr := prompts.NewManager(t.TempDir())
+	got := mgr.Localize(prompts.LocalizeData{
+		Locale:  "zh-CN",
+		Title:   "Add retry flag",
+		Summary: "Added a --retry flag to the CLI.",
+	})
+	for _, want := range []string{"zh-CN", "Add retry flag", "Added a --retry flag", `"summary"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q", want)
+		}
+	}
+}
+
+func TestFork_RendersSummaryAndFeedback(t *testing.T) {
+	mgr := prompts.NewManager(t.TempDir())
+	got := mgr.Fork(prompts.ForkData{
+		Prompt:   "Add a retry flag",
+		Progress: "1. Explored: found the CLI parser",
+		Result:   "Should retries back off exponentially?",
+		Feedback: "Use a fixed delay instead",
+	})
+	if strings.Contains(got, "{{") {
+		t.Errorf("unreplaced template syntax: %q", got)
+	}
+	for _, want := range []string{"Add a retry flag", "found the CLI parser", "back off exponentially", "Use a fixed delay instead"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q", want)
+		}
+	}
+
+	bare := mgr.Fork(prompts.ForkData{Prompt: "Add a retry flag", Feedback: "Use a fixed delay"})
+	for _, absent := range []string{"What the earlier session did", "last message"} {
+		if strings.Contains(bare, absent) {
+			t.Errorf("optional section %q rendered without data", absent)
+		}
+	}
+}
+
+func TestSessionImport_RendersInstructions(t *testing.T) {
+	mgr := prompts.NewManager(t.TempDir())
+	got := mgr.SessionImport(prompts.SessionImportData{Cwd: "/src/app", Instructions: "now add a test"})
+	for _, want := range []string{"/src/app", "now add a test"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q:\n%s", want, got)
+		}
+	}
+	bare := mgr.SessionImport(prompts.SessionImportData{Cwd: "/src/app"})
+	if !strings.Contains(bare, "where the conversation left off") || strings.Contains(bare, "instruction:") {
+		t.Errorf("prompt without instructions:\n%s", bare)
+	}
+}
+
+func TestTaskLinks_ListsEveryLink(t *testing.T) {
+	got := prompts.NewManager(t.TempDir()).TaskLinks(prompts.TaskLinksData{
+		Prompt: "Fix the checkout layout",
+		Links: []prompts.TaskLink{
+			{Type: "figma", URL: "https://figma.com/file/abc", Title: "Checkout v2"},
+			{Type: "jira", URL: "https://example.atlassian.net/browse/APP-9"},
+		},
+	})
+	for _, want := range []string{"Fix the checkout layout", "- figma: Checkout v2 <https://figma.com/file/abc>", "- jira: https://example.atlassian.net/browse/APP-9", "network policy"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasPrefix(got, "Fix the checkout layout") {
+		t.Errorf("the task prompt should lead:\n%s", got)
+	}
+}
+
+func TestTaskAttachments_ListsFilesAndDir(t *testing.T) {
+	got := prompts.NewManager(t.TempDir()).TaskAttachments(prompts.TaskAttachmentsData{
+		Prompt: "Fix the checkout layout",
+		Dir:    "/tmp/wallfacer-attachments-1",
+		Files: []prompts.TaskAttachment{
+			{Name: "shot.png", ContentType: "image/png", Size: 2048},
+			{Name: "notes", Size: 12},
+		},
+	})
+	for _, want := range []string{"/tmp/wallfacer-attachments-1", "- shot.png (image/png, 2048 bytes)", "- notes (12 bytes)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasPrefix(got, "Fix the checkout layout") {
+		t.Errorf("the task prompt should lead:\n%s", got)
+	}
+}
+
+func TestContextPack_RendersFilesAndOmissions(t *testing.T) {
+	got := prompts.NewManager(t.TempDir()).ContextPack(prompts.ContextPackData{
+		Prompt: "Fix the parser",
+		Files: []prompts.ContextPackFile{
+			{Path: "/wt/app/parse.go", Content: "package parse", Fence: "```", Lines: 1},
+			{Path: "/wt/app/big.go", Content: "1: package big", Fence: "```", Summarized: true, Lines: 900},
+		},
+		Omitted: []string{"/wt/app/logo.png"},
+	})
+	for _, want := range []string{"### /wt/app/parse.go\n```\npackage parse\n```", "### /wt/app/big.go (summarized, 900 lines)", "- /wt/app/logo.png"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasPrefix(got, "Fix the parser") {
+		t.Errorf("the task prompt should lead:\n%s", got)
+	}
+}
+
+func TestTitleBatch_NumbersEveryTask(t *testing.T) {
+	got := prompts.NewManager(t.TempDir()).TitleBatch([]string{"fix the login bug", "add dark mode"})
+	if strings.Contains(got, "{{") {
+		t.Errorf("unreplaced template syntax: %q", got)
+	}
+	for _, want := range []string{"2 tasks", "Task 1:\nfix the login bug", "Task 2:\nadd dark mode", "JSON array"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestLintFix_ListsEveryFailure(t *testing.T) {
+	got := prompts.NewManager(t.TempDir()).LintFix(prompts.LintFixData{Failures: []prompts.LintFailure{
+		{Repo: "/repo/api", Command: "go vet ./...", Output: "main.go:3: unreachable code"},
+		{Repo: "/repo/web", Command: "npx eslint .", Output: "app.js: 'x' is unused"},
+	}})
+	if strings.Contains(got, "{{") {
+		t.Errorf("unreplaced template syntax: %q", got)
+	}
+	for _, want := range []string{"Repository: /repo/api", "`go vet ./...`", "main.go:3: unreachable code", "Repository: /repo/web", "'x' is unused"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q:\n%s", want, got)
+		}
+	}
+}
+
 // TestConflictResolution_ReturnsNonEmptyRendered verifies that the conflict
 // resolution template renders with container path and default branch populated.
 func TestConflictResolution_ReturnsNonEmptyRendered(t *testing.T) {
@@ -547,3 +725,53 @@ func TestPromptRegistry_SpecOverridable(t *testing.T) {
 		})
 	}
 }
+
+func TestResearch_ListsDomainsAndTimeBox(t *testing.T) {
+	got := prompts.NewManager(t.TempDir()).Research(prompts.ResearchData{
+		Prompt:         "How does the Go scheduler preempt goroutines?",
+		Domains:        []string{"go.dev", "github.com"},
+		TimeBoxMinutes: 30,
+	})
+	if strings.Contains(got, "{{") {
+		t.Errorf("unreplaced template syntax: %q", got)
+	}
+	for _, want := range []string{"- go.dev\n", "- github.com\n", "30 minutes", "## Sources", "preempt goroutines"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered prompt missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWorkspacePaths_ListsRewrites(t *testing.T) {
+	got := prompts.NewManager(t.TempDir()).WorkspacePaths(prompts.WorkspacePathsData{
+		Prompt:   "Fix /wt/t1/app/main.go",
+		Rewrites: []prompts.PathRewrite{{From: "/code/app", To: "/wt/t1/app"}},
+	})
+	if !strings.HasPrefix(got, "Fix /wt/t1/app/main.go") || !strings.Contains(got, "- /code/app → /wt/t1/app") {
+		t.Errorf("rendered prompt:\n%s", got)
+	}
+}
+
+func TestApprovalGates_ShowsFencedRequest(t *testing.T) {
+	got := prompts.NewManager(t.TempDir()).ApprovalGates(prompts.ApprovalGatesData{Prompt: "Add the index", Tag: "wallfacer-approval"})
+	if !strings.HasPrefix(got, "Add the index") {
+		t.Errorf("the task prompt should lead:\n%s", got)
+	}
+	if !strings.Contains(got, "```wallfacer-approval\n{\"action\"") {
+		t.Errorf("rendered prompt missing the request block:\n%s", got)
+	}
+}
+
+func TestApprovalDecision(t *testing.T) {
+	m := prompts.NewManager(t.TempDir())
+	approved := m.ApprovalDecision(prompts.ApprovalDecisionData{Approved: true, Action: "run migration 0042", Command: "make migrate"})
+	if strings.TrimSpace(approved) != "Approved: run migration 0042\nGo ahead with `make migrate`, then continue the task." {
+		t.Errorf("approved = %q", approved)
+	}
+	denied := m.ApprovalDecision(prompts.ApprovalDecisionData{Action: "drop the users table", Note: "copy it first"})
+	for _, want := range []string{"Denied: drop the users table\nDo not take this action.", "\n\nNote from the reviewer: copy it first"} {
+		if !strings.Contains(denied, want) {
+			t.Errorf("denied missing %q:\n%s", want, denied)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package routine
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears bounds how far [Cron.Next] looks for a matching minute.
+// A spec that matches nothing in that span (such as February 30th) never
+// fires.
+const cronSearchYears = 5
+
+// Cron fires at the minutes matching a standard five-field cron
+// expression: minute, hour, day of month, month, and day of week. Fields
+// accept `*`, single values, ranges (`1-5`), steps (`*/15`, `0-30/10`),
+// and comma-separated lists of those; months and weekdays also accept
+// three-letter names (`JAN`, `MON`), and 7 is Sunday like 0. As in cron,
+// when both day fields are restricted a day matching either one fires.
+//
+// The macros @yearly (@annually), @monthly, @weekly, @daily (@midnight),
+// and @hourly stand for their usual expressions. Times are matched in the
+// server's local time zone unless the spec starts with `CRON_TZ=<zone> `
+// (or `TZ=<zone> `).
+//
+// Cron is comparable with reflect.DeepEqual, which [Engine.Register] uses
+// to keep a timer armed across repeated registration of the same spec.
+type Cron struct {
+	spec   string
+	loc    *time.Location
+	minute uint64 // bit n set: minute n matches
+	hour   uint64
+	dom    uint64 // days of month 1-31
+	month  uint64 // months 1-12
+	dow    uint64 // weekdays 0-6, Sunday first
+	// domAny and dowAny record a day field given as `*`, which leaves the
+	// day to the other field.
+	domAny bool
+	dowAny bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	cronDayNames   = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// ParseCron parses a cron expression into a [Cron] schedule.
+func ParseCron(spec string) (Cron, error) {
+	c := Cron{spec: strings.TrimSpace(spec), loc: time.Local}
+	expr := c.spec
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if rest, ok := strings.CutPrefix(expr, prefix); ok {
+			zone, fields, _ := strings.Cut(rest, " ")
+			loc, err := time.LoadLocation(zone)
+			if err != nil {
+				return Cron{}, fmt.Errorf("cron: unknown time zone %q", zone)
+			}
+			c.loc = loc
+			expr = strings.TrimSpace(fields)
+			break
+		}
+	}
+	if strings.HasPrefix(expr, "@") {
+		macro, ok := cronMacros[strings.ToLower(expr)]
+		if !ok {
+			return Cron{}, fmt.Errorf("cron: unknown macro %q", expr)
+		}
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Cron{}, fmt.Errorf("cron: %q has %d fields, want 5 (minute hour day-of-month month day-of-week)", expr, len(fields))
+	}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return Cron{}, fmt.Errorf("cron: minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return Cron{}, fmt.Errorf("cron: hour: %w", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return Cron{}, fmt.Errorf("cron: day of month: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return Cron{}, fmt.Errorf("cron: month: %w", err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return Cron{}, fmt.Errorf("cron: day of week: %w", err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	c.domAny = fields[2] == "*" || fields[2] == "?"
+	c.dowAny = fields[4] == "*" || fields[4] == "?"
+	return c, nil
+}
+
+// parseCronField returns the bit set of the values in [lo, hi] that field
+// selects. names, when set, maps each value (by index) to its name.
+func parseCronField(field string, lo, hi int, names []string) (uint64, error) {
+	var set uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+		var from, to int
+		switch {
+		case rng == "*" || rng == "?":
+			from, to = lo, hi
+		default:
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = cronValue(a, lo, hi, names); err != nil {
+				return 0, err
+			}
+			to = from
+			if isRange {
+				if to, err = cronValue(b, lo, hi, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// `5/15` means from 5 to the end in steps of 15.
+				to = hi
+			}
+			if from > to {
+				return 0, fmt.Errorf("range %q runs backwards", rng)
+			}
+		}
+		for v := from; v <= to; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func cronValue(s string, lo, hi int, names []string) (int, error) {
+	for i, name := range names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if n < lo || n > hi {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, lo, hi)
+	}
+	return n, nil
+}
+
+// String returns the spec the schedule was parsed from.
+func (c Cron) String() string { return c.spec }
+
+// Next implements [Schedule]: it returns the first matching minute after
+// now, or the zero time when none comes within five years.
+func (c Cron) Next(now time.Time) time.Time {
+	if c.minute == 0 || c.hour == 0 || c.month == 0 {
+		return time.Time{}
+	}
+	loc := c.loc
+	if loc == nil {
+		loc = time.Local
+	}
+	t := now.In(loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = nextHour(t, c.hour)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = nextMinute(t, c.minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// nextHour returns the start of the next hour of t's day in set, or the
+// start of the next day when none is left.
+func nextHour(t time.Time, set uint64) time.Time {
+	rest := set >> uint(t.Hour()+1) << uint(t.Hour()+1)
+	if rest == 0 {
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+	next := time.Date(t.Year(), t.Month(), t.Day(), bits.TrailingZeros64(rest), 0, 0, 0, t.Location())
+	if !next.After(t) {
+		// A daylight saving change made the wall-clock hour ambiguous;
+		// move on to the next hour by elapsed time instead.
+		return startOfNextHour(t)
+	}
+	return next
+}
+
+// nextMinute returns the next minute of t's hour in set, or the start of
+// the next hour when none is left.
+func nextMinute(t time.Time, set uint64) time.Time {
+	rest := set >> uint(t.Minute()+1) << uint(t.Minute()+1)
+	if rest == 0 {
+		return startOfNextHour(t)
+	}
+	return t.Add(time.Duration(bits.TrailingZeros64(rest)-t.Minute()) * time.Minute)
+}
+
+// startOfNextHour returns the minute 0 following t, t being on a whole
+// minute. Adding elapsed time keeps it moving forward across daylight
+// saving changes and in zones offset from UTC by a fraction of an hour.
+func startOfNextHour(t time.Time) time.Time {
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
@@ -0,0 +1,131 @@
+package routine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCronNext(t *testing.T) {
+	utc := func(s string) time.Time {
+		ts, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	// 2026-03-04 is a Wednesday.
+	cases := []struct {
+		spec, now, want string
+	}{
+		{"CRON_TZ=UTC 0 2 * * *", "2026-03-04 10:00", "2026-03-05 02:00"},
+		{"CRON_TZ=UTC 0 2 * * *", "2026-03-04 01:59", "2026-03-04 02:00"},
+		{"CRON_TZ=UTC 0 2 * * *", "2026-03-04 02:00", "2026-03-05 02:00"},
+		{"CRON_TZ=UTC */15 * * * *", "2026-03-04 10:07", "2026-03-04 10:15"},
+		{"CRON_TZ=UTC */15 * * * *", "2026-03-04 10:50", "2026-03-04 11:00"},
+		{"CRON_TZ=UTC 30 9 * * MON-FRI", "2026-03-06 12:00", "2026-03-09 09:30"},
+		{"CRON_TZ=UTC 0 0 1 * *", "2026-12-15 00:00", "2027-01-01 00:00"},
+		{"CRON_TZ=UTC 0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"CRON_TZ=UTC 0 12 13 * FRI", "2026-03-04 00:00", "2026-03-06 12:00"}, // either day field
+		{"CRON_TZ=UTC 0 0 * * 7", "2026-03-04 00:00", "2026-03-08 00:00"},
+		{"CRON_TZ=UTC 5,35 1-3/2 * jan,mar *", "2026-03-04 01:40", "2026-03-04 03:05"},
+		{"CRON_TZ=UTC @weekly", "2026-03-04 00:00", "2026-03-08 00:00"},
+		{"CRON_TZ=UTC @hourly", "2026-03-04 23:30", "2026-03-05 00:00"},
+		{"CRON_TZ=Asia/Kolkata 0 9 * * *", "2026-03-04 04:00", "2026-03-05 03:30"},
+	}
+	for _, tc := range cases {
+		c, err := ParseCron(tc.spec)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tc.spec, err)
+		}
+		if got := c.Next(utc(tc.now)); !got.Equal(utc(tc.want)) {
+			t.Errorf("%q after %s = %s, want %s UTC", tc.spec, tc.now, got.UTC().Format("2006-01-02 15:04"), tc.want)
+		}
+	}
+}
+
+func TestCronNext_DaylightSaving(t *testing.T) {
+	// New York skips 02:00-03:00 on 2026-03-08 and repeats 01:00-02:00 on
+	// 2026-11-01; a daily job at 02:30 runs on the next existing day and
+	// Next always moves forward.
+	c, err := ParseCron("CRON_TZ=America/New_York 30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := c.loc
+	got := c.Next(time.Date(2026, 3, 7, 12, 0, 0, 0, loc))
+	if got.Before(time.Date(2026, 3, 8, 0, 0, 0, 0, loc)) || got.After(time.Date(2026, 3, 9, 2, 30, 0, 0, loc)) {
+		t.Errorf("next across spring-forward = %s", got)
+	}
+	hourly, _ := ParseCron("CRON_TZ=America/New_York 0 * * * *")
+	now := time.Date(2026, 11, 1, 0, 30, 0, 0, loc)
+	for range 5 {
+		next := hourly.Next(now)
+		if !next.After(now) || next.Sub(now) > time.Hour {
+			t.Fatalf("hourly after %s = %s", now, next)
+		}
+		now = next
+	}
+}
+
+func TestCronNext_NeverMatches(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Next(time.Now()); !got.IsZero() {
+		t.Errorf("February 30th fires at %s", got)
+	}
+	if got := (Cron{}).Next(time.Now()); !got.IsZero() {
+		t.Errorf("zero Cron fires at %s", got)
+	}
+}
+
+func TestParseCron_Errors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@fortnightly",
+		"CRON_TZ=Mars/Olympus 0 0 * * *",
+		"x * * * *",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) succeeded", spec)
+		} else if !strings.HasPrefix(err.Error(), "cron: ") {
+			t.Errorf("ParseCron(%q) error %q lacks the cron prefix", spec, err)
+		}
+	}
+}
+
+func TestEngine_CronScheduleFiresAndRegistersIdempotently(t *testing.T) {
+	clock := newFakeClock()
+	fire, ch := collectFires(t)
+	eng := NewEngine(context.Background(), clock, fire)
+	id := uuid.New()
+	spec, err := ParseCron("* * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eng.Register(id, spec)
+	first := eng.NextRuns()[id]
+	again, _ := ParseCron("* * * * *")
+	eng.Register(id, again)
+	if got := eng.NextRuns()[id]; !got.Equal(first) {
+		t.Errorf("re-registering the same spec moved the next run from %s to %s", first, got)
+	}
+	clock.Advance(time.Minute)
+	if got := waitFire(t, ch); got != id {
+		t.Errorf("fired %s, want %s", got, id)
+	}
+}
@@ -11,7 +11,8 @@
 // # Connected packages
 //
 // No internal dependencies (stdlib + google/uuid only). Consumed by
-// [handler] to drive user-defined routine tasks on the board. Adding new
-// [Schedule] implementations (cron expressions, time-of-day) does not
-// require changes here.
+// [handler] to drive user-defined routine tasks on the board. Two
+// [Schedule] implementations ship here: [FixedInterval] and [Cron], which
+// parses standard five-field cron expressions. Adding another does not
+// require changes to the engine.
 package routine
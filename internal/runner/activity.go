@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// maxActivityEventsPerTurn bounds the activity events one turn records, so
+// a long turn cannot flood the event log. The live log and the turn output
+// still carry everything past the cap.
+const maxActivityEventsPerTurn = 200
+
+// maxActivityTextRunes is the longest text an activity event carries.
+const maxActivityTextRunes = 300
+
+// activityRecorder appends the steps of a running turn to the task's event
+// log as the agent reports them: every tool call it starts and every text
+// block it writes. The turn's final result is still recorded once, as an
+// output event, when the turn ends.
+type activityRecorder struct {
+	r      *Runner
+	taskID uuid.UUID
+
+	mu    sync.Mutex
+	count int
+}
+
+func (r *Runner) newActivityRecorder(taskID uuid.UUID) *activityRecorder {
+	return &activityRecorder{r: r, taskID: taskID}
+}
+
+// event is the runAgent OnEvent hook. It runs on the stdout drain
+// goroutine, once per parsed line.
+func (a *activityRecorder) event(evt harness.Event) {
+	data, ok := activityData(evt)
+	if !ok {
+		return
+	}
+	a.mu.Lock()
+	if a.count >= maxActivityEventsPerTurn {
+		a.mu.Unlock()
+		return
+	}
+	a.count++
+	a.mu.Unlock()
+	_ = a.r.taskStore(a.taskID).InsertEvent(context.Background(), a.taskID, store.EventTypeActivity, data)
+}
+
+// activityData returns the activity payload evt records, if any. Thinking,
+// results, and tool results are not activity: the result is recorded when
+// the turn ends and the others are in the live log.
+func activityData(evt harness.Event) (store.ActivityData, bool) {
+	switch evt.Kind {
+	case harness.KindToolCallStart:
+		if evt.Tool == nil || evt.Tool.Name == "" {
+			return store.ActivityData{}, false
+		}
+		return store.ActivityData{
+			Kind: store.ActivityTool,
+			Tool: evt.Tool.Name,
+			Text: truncate(extractToolInputGo(evt.Tool.Name, parseRawInput(evt.Tool.Input)), maxActivityTextRunes),
+		}, true
+	case harness.KindAssistantText:
+		text := strings.TrimSpace(evt.Text)
+		if text == "" {
+			return store.ActivityData{}, false
+		}
+		return store.ActivityData{Kind: store.ActivityText, Text: truncate(text, maxActivityTextRunes)}, true
+	}
+	return store.ActivityData{}, false
+}
+
+// chainEventFuncs returns a hook calling each non-nil fn in order, or nil
+// when all are nil.
+func chainEventFuncs(fns ...func(harness.Event)) func(harness.Event) {
+	var set []func(harness.Event)
+	for _, fn := range fns {
+		if fn != nil {
+			set = append(set, fn)
+		}
+	}
+	switch len(set) {
+	case 0:
+		return nil
+	case 1:
+		return set[0]
+	}
+	return func(evt harness.Event) {
+		for _, fn := range set {
+			fn(evt)
+		}
+	}
+}
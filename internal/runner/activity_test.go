@@ -0,0 +1,131 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestActivityData(t *testing.T) {
+	cases := []struct {
+		name string
+		evt  harness.Event
+		want store.ActivityData
+		ok   bool
+	}{
+		{
+			name: "tool call",
+			evt:  harness.Event{Kind: harness.KindToolCallStart, Tool: &harness.ToolCall{Name: "Bash", Input: json.RawMessage(`{"command":"go test ./..."}`)}},
+			want: store.ActivityData{Kind: store.ActivityTool, Tool: "Bash", Text: "go test ./..."},
+			ok:   true,
+		},
+		{
+			name: "text",
+			evt:  harness.Event{Kind: harness.KindAssistantText, Text: "  Looking at the parser.\n"},
+			want: store.ActivityData{Kind: store.ActivityText, Text: "Looking at the parser."},
+			ok:   true,
+		},
+		{name: "blank text", evt: harness.Event{Kind: harness.KindAssistantText, Text: " \n"}},
+		{name: "thinking", evt: harness.Event{Kind: harness.KindThinking, Text: "hmm"}},
+		{name: "result", evt: harness.Event{Kind: harness.KindResult, Text: "done"}},
+		{name: "tool result", evt: harness.Event{Kind: harness.KindToolCallEnd, Tool: &harness.ToolCall{Name: "Bash"}}},
+	}
+	for _, tc := range cases {
+		got, ok := activityData(tc.evt)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("%s: activityData = %+v, %v; want %+v, %v", tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+	long, _ := activityData(harness.Event{Kind: harness.KindAssistantText, Text: strings.Repeat("x", 2*maxActivityTextRunes)})
+	if n := len([]rune(long.Text)); n > maxActivityTextRunes {
+		t.Errorf("text of %d runes kept, want at most %d", n, maxActivityTextRunes)
+	}
+}
+
+func TestActivityRecorder_CapsEventsPerTurn(t *testing.T) {
+	s, r := setupRunnerWithCmd(t, nil, "echo")
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := r.newActivityRecorder(task.ID)
+	for range maxActivityEventsPerTurn + 5 {
+		rec.event(harness.Event{Kind: harness.KindAssistantText, Text: "step"})
+	}
+	events, _ := s.GetEvents(ctx, task.ID)
+	if n := countEvents(events, store.EventTypeActivity); n != maxActivityEventsPerTurn {
+		t.Errorf("activity events = %d, want %d", n, maxActivityEventsPerTurn)
+	}
+}
+
+// TestRunRecordsActivityWhileRunning runs a fake agent that reports a tool
+// call and then waits, and checks the call is in the event log before the
+// turn ends.
+func TestRunRecordsActivityWhileRunning(t *testing.T) {
+	repo := setupTestRepo(t)
+	dir := t.TempDir()
+	release := filepath.Join(dir, "release")
+	script := filepath.Join(dir, "claude")
+	body := `#!/bin/sh
+[ "$1" = "--help" ] && exit 0
+echo '{"type":"system","subtype":"init","session_id":"s1"}'
+echo '{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"make test"}}]}}'
+while [ ! -f ` + release + ` ]; do sleep 0.05; done
+echo '{"type":"result","result":"done","session_id":"s1","stop_reason":"end_turn","is_error":false}'
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s, r := setupRunnerWithCmd(t, []string{repo}, script)
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "test it", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(task.ID, "test it", "", false)
+	}()
+	defer func() {
+		_ = os.WriteFile(release, nil, 0o644)
+		<-done
+	}()
+
+	deadline := time.Now().Add(20 * time.Second)
+	for {
+		events, _ := s.GetEvents(ctx, task.ID)
+		var data store.ActivityData
+		for _, ev := range events {
+			if ev.EventType == store.EventTypeActivity {
+				_ = json.Unmarshal(ev.Data, &data)
+			}
+		}
+		if data.Kind != "" {
+			if data.Tool != "Bash" || data.Text != "make test" {
+				t.Fatalf("activity = %+v, want the Bash call", data)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no activity event while the turn was running")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("turn ended before it was released")
+	default:
+	}
+}
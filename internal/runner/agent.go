@@ -1,9 +1,11 @@
 package runner
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"strings"
 	"sync"
 	"time"
@@ -70,6 +72,20 @@ type runAgentOpts struct {
 	// attach to the in-flight container. Called for every launch
 	// attempt, including the codex fallback.
 	OnLaunch func(containerName string, handle executor.Handle)
+	// Interactive launches the agent with its stdin kept open (see
+	// executor.ContainerSpec.Interactive). The stdin is closed when the
+	// agent reports its result, which ends an agent that would otherwise
+	// wait for more input. OnLaunch registers the input with the caller.
+	Interactive bool
+	// OnEvent, when set, sees every canonical event parsed from stdout
+	// while the agent runs.
+	OnEvent func(evt harness.Event)
+	// StderrTap, when set, receives stderr alongside the other sinks.
+	StderrTap io.Writer
+	// OnExit, when set, receives the process exit metadata after Wait
+	// returns, on success and failure alike. Skipped for handles that
+	// do not implement executor.ExitReporter.
+	OnExit func(info executor.ExitInfo)
 
 	// SessionID, when non-empty, is threaded into buildAgentCmd as
 	// --resume <id>. Used by the multi-turn heavyweight roles so the
@@ -97,6 +113,11 @@ type runAgentOpts struct {
 	// read-only mounts of other in-progress task worktrees, so the
 	// agent can reference sibling work without modifying it.
 	SiblingMounts map[string]map[string]string
+	// Output, when set, receives stdout and stderr as the agent produces
+	// them, and the agentResult carries no raw streams, so a verbose
+	// turn never sits in memory. Heavyweight turns stream into the
+	// task's turn-output files this way.
+	Output *store.TurnOutput
 	// LiveLogWriter, when set, is tee'd alongside stdout and stderr
 	// during the container run so callers can stream output while the
 	// container is still alive. Heavyweight roles wire this to their
@@ -121,9 +142,8 @@ type runAgentCircuitBreaker interface {
 }
 
 // agentResult is runAgent's return envelope. It bundles the parsed
-// output, the role-specific structured result, and the raw streams so
-// callers that need to persist turn output (heavyweight roles) can
-// reach them without re-parsing.
+// output, the role-specific structured result, and the raw streams
+// (absent when runAgentOpts.Output received them instead).
 type agentResult struct {
 	Output      *agentOutput
 	Parsed      any
@@ -194,13 +214,15 @@ func (r *Runner) runAgent(
 	}
 
 	// Derive a per-call context with the role's timeout. Callers that
-	// already own a sub-deadline supply their own context via opts.
+	// already own a sub-deadline supply their own context via opts. An
+	// adaptive task deadline already bounds the turn and may move past the
+	// role's timeout, so it replaces it.
 	runCtx := ctx
 	var cancel context.CancelFunc
 	if runCtx == nil {
 		runCtx = r.shutdownCtx
 	}
-	if binding.Timeout != nil {
+	if binding.Timeout != nil && taskDeadlineFrom(runCtx) == nil {
 		if d := binding.Timeout(task); d > 0 {
 			runCtx, cancel = context.WithTimeout(runCtx, d)
 			defer cancel()
@@ -364,6 +386,31 @@ func (r *Runner) launchOne(
 		}
 	}
 
+	// Pin SOURCE_DATE_EPOCH to the task when the base spec could not, point
+	// package managers at the task workspace's managed caches, and run the
+	// agent as the workspace's architecture.
+	if task != nil {
+		r.agentEnvironment(task).apply(spec.Env)
+		maps.Copy(spec.Env, r.cacheEnv(task.ID))
+		spec.Arch = r.workspaceArch(task.ID)
+		taskID := task.ID
+		spec.OnPromptTruncated = func(original, truncated int) {
+			r.recordPromptTruncated(taskID, role, original, truncated)
+		}
+	}
+
+	// Research tasks are pointed at the allowlisting egress proxy, which
+	// lives exactly as long as this invocation. Fail closed: without the
+	// proxy the agent would not even be steered.
+	if task != nil && task.Kind == store.TaskKindResearch {
+		proxy, err := r.startResearchEgress(task)
+		if err != nil {
+			return nil, fmt.Errorf("%s: start research egress proxy: %w", role.Slug, err)
+		}
+		defer func() { _ = proxy.Close() }()
+		maps.Copy(spec.Env, proxy.Env())
+	}
+
 	// Clone the labels map so a caller that hands us a shared map (the
 	// migrated title/oversight/commit call sites do) cannot be mutated
 	// by the backend or by a later retry.
@@ -375,39 +422,89 @@ func (r *Runner) launchOne(
 		merged[k] = v
 	}
 	spec.Labels = merged
+	spec.Interactive = opts.Interactive
 
+	// Every launch for a task goes into its invocation log, from which
+	// `wallfacer replay` can run it again.
+	var inv store.Invocation
+	if task != nil {
+		inv = newInvocation(role, labels["wallfacer.task.activity"], sb, spec, opts)
+	}
 	handle, launchErr := r.backend.Launch(ctx, spec)
 	if launchErr != nil {
 		if opts.CircuitBreaker != nil {
 			opts.CircuitBreaker.RecordFailure()
 		}
+		if task != nil {
+			r.saveInvocation(task.ID, inv, nil, launchErr)
+		}
 		return nil, fmt.Errorf("launch %s container: %w", role.Slug, launchErr)
 	}
 	if opts.OnLaunch != nil {
 		opts.OnLaunch(containerName, handle)
 	}
 
-	// Stdout / stderr are tee'd into the optional live-log writer so
-	// callers can stream output while the container is still alive.
-	// When no writer is supplied we fall back to a direct ReadAll.
-	var rawStdout, rawStderr []byte
-	if opts.LiveLogWriter != nil {
-		var wg sync.WaitGroup
-		wg.Add(2)
-		go func() {
-			defer wg.Done()
-			rawStdout, _ = io.ReadAll(io.TeeReader(handle.Stdout(), opts.LiveLogWriter))
-		}()
-		go func() {
-			defer wg.Done()
-			rawStderr, _ = io.ReadAll(io.TeeReader(handle.Stderr(), opts.LiveLogWriter))
-		}()
-		wg.Wait()
+	// Stdout and stderr are drained concurrently: into opts.Output when
+	// the caller streams the turn to disk, into memory otherwise, and into
+	// the optional live-log writer either way. Stdout also feeds the
+	// harness parser line by line, so a streamed turn is parsed without
+	// being held in memory.
+	stream := newStreamParser(sb)
+	var input executor.InputWriter
+	if p, ok := handle.(executor.InputProvider); ok {
+		input = p.Input()
+	}
+	if input != nil || opts.OnEvent != nil {
+		stream.onEvent(func(evt harness.Event) {
+			if input != nil && (evt.Kind == harness.KindResult || evt.Kind == harness.KindError) {
+				_ = input.CloseInput()
+			}
+			if opts.OnEvent != nil {
+				opts.OnEvent(evt)
+			}
+		})
+	}
+	if input != nil {
+		defer func() { _ = input.CloseInput() }()
+	}
+	var stdoutBuf, stderrBuf bytes.Buffer
+	stdoutW := []io.Writer{stream}
+	var stderrW []io.Writer
+	if opts.Output != nil {
+		stdoutW = append(stdoutW, opts.Output.Stdout())
+		stderrW = append(stderrW, opts.Output.Stderr(), &headWriter{buf: &stderrBuf, max: stderrHeadBytes})
 	} else {
-		rawStdout, _ = io.ReadAll(handle.Stdout())
-		rawStderr, _ = io.ReadAll(handle.Stderr())
+		stdoutW = append(stdoutW, &stdoutBuf)
+		stderrW = append(stderrW, &stderrBuf)
+	}
+	if opts.LiveLogWriter != nil {
+		stdoutW = append(stdoutW, opts.LiveLogWriter)
+		stderrW = append(stderrW, opts.LiveLogWriter)
+	}
+	if opts.StderrTap != nil {
+		stderrW = append(stderrW, opts.StderrTap)
 	}
+	var wg sync.WaitGroup
+	wg.Go(func() { _, _ = io.Copy(io.MultiWriter(stdoutW...), handle.Stdout()) })
+	wg.Go(func() { _, _ = io.Copy(io.MultiWriter(stderrW...), handle.Stderr()) })
+	wg.Wait()
+	rawStderr := stderrBuf.Bytes()
 	exitCode, waitErr := handle.Wait()
+	var exitInfo *executor.ExitInfo
+	if rep, ok := handle.(executor.ExitReporter); ok {
+		if info, ok := rep.ExitInfo(); ok {
+			exitInfo = &info
+		}
+	}
+	if opts.OnExit != nil && exitInfo != nil {
+		opts.OnExit(*exitInfo)
+	}
+	if task != nil {
+		if exitInfo == nil {
+			exitInfo = &executor.ExitInfo{ExitCode: exitCode}
+		}
+		r.saveInvocation(task.ID, inv, exitInfo, nil)
+	}
 
 	// Exit code 125 is the container runtime's "engine error" signal
 	// (podman / docker). Record it against the circuit breaker even
@@ -423,8 +520,7 @@ func (r *Runner) launchOne(
 		return nil, fmt.Errorf("%s container terminated: %w", role.Slug, ctx.Err())
 	}
 
-	raw := strings.TrimSpace(string(rawStdout))
-	if raw == "" {
+	if stream.empty() {
 		// Surface the wait error when present — classifyFailure keys
 		// on "exit status" or "empty output" to bucket container
 		// crashes into the retry-eligible category, so keep those
@@ -451,15 +547,15 @@ func (r *Runner) launchOne(
 	// paths all implemented it. Parsing is harness-owned: each harness
 	// maps its own event stream to canonical events, and
 	// parseHarnessOutput collapses them into the result fields below.
-	output, err := r.parseAgentStream(sb, raw)
+	output, err := stream.result()
 	if err != nil {
 		if exitCode != 0 {
 			return nil, fmt.Errorf("%s container exited with code %d: stderr=%s stdout=%s",
-				role.Slug, exitCode, truncate(string(rawStderr), 200), truncate(raw, 200))
+				role.Slug, exitCode, truncate(string(rawStderr), 200), truncate(stream.headText(), 200))
 		}
 		// Wording matches the pre-migration message so callers that
 		// grep on "parse output" (some tests do) still match.
-		return nil, fmt.Errorf("%s: parse output: %w (raw: %s)", role.Slug, err, truncate(raw, 200))
+		return nil, fmt.Errorf("%s: parse output: %w (raw: %s)", role.Slug, err, truncate(stream.headText(), 200))
 	}
 	if exitCode != 0 {
 		logger.Runner.Warn(role.Slug+": container exited non-zero but produced valid output",
@@ -472,12 +568,11 @@ func (r *Runner) launchOne(
 		opts.CircuitBreaker.RecordSuccess()
 	}
 	output.ActualSandbox = sb
-	return &agentResult{
-		Output:      output,
-		RawStdout:   rawStdout,
-		RawStderr:   rawStderr,
-		SandboxUsed: sb,
-	}, nil
+	res := &agentResult{Output: output, SandboxUsed: sb}
+	if opts.Output == nil {
+		res.RawStdout, res.RawStderr = stdoutBuf.Bytes(), rawStderr
+	}
+	return res, nil
 }
 
 // buildInspectorSpec produces a ContainerSpec for headless or read-only
@@ -520,6 +615,17 @@ func (r *Runner) recordFallbackEvent(taskID uuid.UUID, activity store.SandboxAct
 	})
 }
 
+// recordPromptTruncated writes a system event noting that the prompt for
+// role was cut to fit the launch limit, so the user learns the agent worked
+// from a shortened prompt instead of finding it only in the server log.
+func (r *Runner) recordPromptTruncated(taskID uuid.UUID, role AgentRole, original, truncated int) {
+	_ = r.taskStore(taskID).InsertEvent(r.shutdownCtx, taskID, store.EventTypeSystem, map[string]any{
+		"result":          fmt.Sprintf("Prompt truncated for %s: %d of %d bytes were sent to the agent (see WALLFACER_MAX_PROMPT_BYTES)", role.Slug, truncated, original),
+		"original_bytes":  original,
+		"truncated_bytes": truncated,
+	})
+}
+
 // accumulateAgentUsage bumps the per-sub-agent usage totals on the task
 // and appends a turn record so the UI's cost dashboard and the per-
 // turn drill-down both see the invocation.
@@ -557,3 +663,21 @@ func (r *Runner) accumulateAgentUsage(
 			"task", taskID, "activity", activity, "error", err)
 	}
 }
+
+// stderrHeadBytes is how much of a streamed turn's stderr runAgent keeps
+// for its error messages.
+const stderrHeadBytes = 4 << 10
+
+// headWriter keeps the first max bytes written to it in buf and discards
+// the rest.
+type headWriter struct {
+	buf *bytes.Buffer
+	max int
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	if room := w.max - w.buf.Len(); room > 0 {
+		w.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
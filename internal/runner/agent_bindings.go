@@ -64,6 +64,20 @@ var agentBindings = map[string]agentBinding{
 		SingleTurn:  true,
 		ParseResult: parseCommitMessageResult,
 	},
+	agents.Localize.Slug: {
+		Activity:    store.SandboxActivityTitle,
+		Timeout:     func(*store.Task) time.Duration { return constants.LocalizeAgentTimeout },
+		MountMode:   mountNone,
+		SingleTurn:  true,
+		ParseResult: parseLocalizeResult,
+	},
+	agents.Probe.Slug: {
+		Activity:    store.SandboxActivityImplementation,
+		Timeout:     func(*store.Task) time.Duration { return constants.AgentProbeTimeout },
+		MountMode:   mountNone,
+		SingleTurn:  true,
+		ParseResult: passthroughParse,
+	},
 	agents.Implementation.Slug: {
 		Activity: store.SandboxActivityImplementation,
 		Timeout: func(t *store.Task) time.Duration {
@@ -90,6 +104,18 @@ var agentBindings = map[string]agentBinding{
 		SingleTurn:  false,
 		ParseResult: passthroughParse,
 	},
+	agents.ConflictResolver.Slug: {
+		Activity: store.SandboxActivityConflictResolver,
+		Timeout: func(t *store.Task) time.Duration {
+			if t == nil {
+				return 0
+			}
+			return time.Duration(t.Timeout) * time.Minute
+		},
+		MountMode:   mountReadWrite,
+		SingleTurn:  true,
+		ParseResult: passthroughParse,
+	},
 }
 
 // bindingFor looks up the runner-side dispatch plumbing for an agent
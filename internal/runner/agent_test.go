@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"latere.ai/x/wallfacer/internal/agents"
+	"latere.ai/x/wallfacer/internal/executor"
 	"latere.ai/x/wallfacer/internal/harness"
 	"latere.ai/x/wallfacer/internal/prompts"
 	"latere.ai/x/wallfacer/internal/store"
@@ -299,6 +300,50 @@ func TestRunAgent_TokenLimitFallback(t *testing.T) {
 	}
 }
 
+// truncatingBackend reports every launch's prompt as truncated, as the host
+// backend does when a prompt exceeds its budget.
+type truncatingBackend struct{ *MockSandboxBackend }
+
+func (b truncatingBackend) Launch(ctx context.Context, spec executor.ContainerSpec) (executor.Handle, error) {
+	if spec.OnPromptTruncated != nil {
+		spec.OnPromptTruncated(5000, 4096)
+	}
+	return b.MockSandboxBackend.Launch(ctx, spec)
+}
+
+// TestRunAgent_PromptTruncatedEvent verifies that a prompt cut at launch is
+// recorded on the task's timeline with both sizes.
+func TestRunAgent_PromptTruncatedEvent(t *testing.T) {
+	r, backend, s := newAgentTestRunner(t)
+	backend.responses = []ContainerResponse{{Stdout: []byte(happyHeadlessStdout)}}
+	r.backend = truncatingBackend{backend}
+
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "probe", Timeout: 10})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	role := makeTestRole(t, "t-trunc", mountNone)
+	if _, err := r.runAgent(ctx, role, task, "p", runAgentOpts{}); err != nil {
+		t.Fatalf("runAgent: %v", err)
+	}
+
+	events, err := s.GetEvents(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	found := false
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeSystem && strings.Contains(string(ev.Data), "Prompt truncated") {
+			found = strings.Contains(string(ev.Data), `"original_bytes":5000`) &&
+				strings.Contains(string(ev.Data), `"truncated_bytes":4096`)
+		}
+	}
+	if !found {
+		t.Fatalf("no prompt-truncation event with both sizes in %d events", len(events))
+	}
+}
+
 func TestRunAgent_LaunchErrorNoFallbackOnNonTokenLimit(t *testing.T) {
 	r, backend, _ := newAgentTestRunner(t)
 	backend.responses = []ContainerResponse{
@@ -0,0 +1,167 @@
+package runner
+
+import (
+	"net"
+	"net/url"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// defaultAgentTZ is the timezone agents run in when WALLFACER_AGENT_TZ is
+// unset, so timestamps an agent writes into code, docs, or test fixtures do
+// not depend on where the server happens to run.
+const defaultAgentTZ = "UTC"
+
+// defaultAgentLang returns the locale agents run in when WALLFACER_AGENT_LANG
+// is unset. C.UTF-8 is the locale-neutral choice on Linux; macOS does not ship
+// it, so en_US.UTF-8 is used there instead.
+func defaultAgentLang() string {
+	if runtime.GOOS == "darwin" {
+		return "en_US.UTF-8"
+	}
+	return "C.UTF-8"
+}
+
+// agentEnv is the locale, timezone, build timestamp, and proxy pinned in
+// every agent process environment.
+type agentEnv struct {
+	tz              string
+	lang            string
+	sourceDateEpoch int64 // 0 ⇒ SOURCE_DATE_EPOCH is left unset
+	proxy           store.TaskProxy
+}
+
+// containerHostAliases are the names a container uses for the machine it
+// runs on. Agents run as host processes, so a proxy configured with one of
+// them (as it would be for a containerised setup) is reached on loopback.
+var containerHostAliases = []string{"host.containers.internal", "host.docker.internal", "gateway.docker.internal"}
+
+// defaultNoProxy keeps loopback traffic, such as the agent's calls back
+// to the server, off a configured proxy.
+const defaultNoProxy = "localhost,127.0.0.1,::1"
+
+// agentEnvironment resolves the pinned agent environment from the env file,
+// with the task's proxy override applied over the global proxy settings.
+// SOURCE_DATE_EPOCH comes from WALLFACER_SOURCE_DATE_EPOCH when set, otherwise
+// from the task's creation time, which stays fixed across retries and
+// machines. With neither (task-less invocations such as agent-session
+// titles) it is left unset.
+func (r *Runner) agentEnvironment(task *store.Task) agentEnv {
+	env := agentEnv{tz: defaultAgentTZ, lang: defaultAgentLang()}
+	if r.envFile != "" {
+		if cfg, err := envconfig.Parse(r.envFile); err == nil {
+			if cfg.AgentTZ != "" {
+				if _, err := time.LoadLocation(cfg.AgentTZ); err != nil {
+					logger.Runner.Warn("ignoring unknown WALLFACER_AGENT_TZ", "value", cfg.AgentTZ, "error", err)
+				} else {
+					env.tz = cfg.AgentTZ
+				}
+			}
+			if cfg.AgentLang != "" {
+				env.lang = cfg.AgentLang
+			}
+			env.sourceDateEpoch = cfg.SourceDateEpoch
+			env.proxy = store.TaskProxy{
+				HTTPProxy:  cfg.AgentHTTPProxy,
+				HTTPSProxy: cfg.AgentHTTPSProxy,
+				NoProxy:    cfg.AgentNoProxy,
+			}
+		}
+	}
+	if task != nil && task.Proxy != nil {
+		env.proxy = mergeProxy(env.proxy, *task.Proxy)
+	}
+	if env.sourceDateEpoch == 0 && task != nil && !task.CreatedAt.IsZero() {
+		env.sourceDateEpoch = task.CreatedAt.Unix()
+	}
+	return env
+}
+
+// apply writes the pinned variables into a launch spec's env overlay. LC_ALL
+// is set alongside LANG so LC_* variables inherited from the server's shell
+// cannot override the pinned locale.
+func (e agentEnv) apply(env map[string]string) {
+	env["TZ"] = e.tz
+	env["LANG"] = e.lang
+	env["LC_ALL"] = e.lang
+	if e.sourceDateEpoch > 0 {
+		env["SOURCE_DATE_EPOCH"] = strconv.FormatInt(e.sourceDateEpoch, 10)
+	}
+	e.applyProxy(env)
+}
+
+// mergeProxy returns global with the fields task sets replacing it. A
+// direct override drops the global proxy entirely.
+func mergeProxy(global, task store.TaskProxy) store.TaskProxy {
+	if task.Direct {
+		return store.TaskProxy{Direct: true}
+	}
+	if task.HTTPProxy != "" {
+		global.HTTPProxy = task.HTTPProxy
+	}
+	if task.HTTPSProxy != "" {
+		global.HTTPSProxy = task.HTTPSProxy
+	}
+	if task.NoProxy != "" {
+		global.NoProxy = task.NoProxy
+	}
+	return global
+}
+
+// applyProxy writes the proxy variables, in both the upper- and lowercase
+// spellings tools disagree on. Without a configured proxy the variables
+// inherited from the server are left alone; a direct proxy blanks them.
+// HTTPS traffic uses the HTTP proxy unless an HTTPS proxy is set, and
+// loopback always bypasses the proxy.
+func (e agentEnv) applyProxy(env map[string]string) {
+	p := e.proxy
+	if p.Direct {
+		for _, k := range []string{"HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY", "NO_PROXY"} {
+			env[k] = ""
+			env[strings.ToLower(k)] = ""
+		}
+		return
+	}
+	httpsProxy := p.HTTPSProxy
+	if httpsProxy == "" {
+		httpsProxy = p.HTTPProxy
+	}
+	if p.HTTPProxy == "" && httpsProxy == "" {
+		return
+	}
+	set := func(k, v string) {
+		env[k] = v
+		env[strings.ToLower(k)] = v
+	}
+	if p.HTTPProxy != "" {
+		set("HTTP_PROXY", hostProxyURL(p.HTTPProxy))
+	}
+	set("HTTPS_PROXY", hostProxyURL(httpsProxy))
+	noProxy := defaultNoProxy
+	if p.NoProxy != "" {
+		noProxy += "," + p.NoProxy
+	}
+	set("NO_PROXY", noProxy)
+}
+
+// hostProxyURL rewrites a proxy URL that names the host by a container
+// alias to use loopback instead. Any other URL is returned unchanged.
+func hostProxyURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || !slices.Contains(containerHostAliases, strings.ToLower(u.Hostname())) {
+		return raw
+	}
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort("127.0.0.1", port)
+	} else {
+		u.Host = "127.0.0.1"
+	}
+	return u.String()
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestAgentEnvironment_Defaults(t *testing.T) {
+	r := NewRunner(nil, RunnerConfig{Command: "echo"})
+	t.Cleanup(func() { r.Shutdown() })
+
+	spec := r.buildBaseContainerSpec("c-test", "", harness.Claude)
+	if spec.Env["TZ"] != "UTC" {
+		t.Errorf("TZ = %q, want UTC", spec.Env["TZ"])
+	}
+	if spec.Env["LANG"] != defaultAgentLang() || spec.Env["LC_ALL"] != defaultAgentLang() {
+		t.Errorf("LANG/LC_ALL = %q/%q, want %q", spec.Env["LANG"], spec.Env["LC_ALL"], defaultAgentLang())
+	}
+	// Without a task or a configured epoch there is nothing fixed to pin.
+	if v, ok := spec.Env["SOURCE_DATE_EPOCH"]; ok {
+		t.Errorf("SOURCE_DATE_EPOCH = %q, want unset", v)
+	}
+
+	created := time.Unix(1760000000, 0)
+	got := r.agentEnvironment(&store.Task{CreatedAt: created})
+	if got.sourceDateEpoch != created.Unix() {
+		t.Errorf("sourceDateEpoch = %d, want %d", got.sourceDateEpoch, created.Unix())
+	}
+}
+
+func TestAgentEnvironment_FromEnvFile(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	content := "WALLFACER_AGENT_TZ=Asia/Tokyo\nWALLFACER_AGENT_LANG=ja_JP.UTF-8\nWALLFACER_SOURCE_DATE_EPOCH=1700000000\n"
+	if err := os.WriteFile(envFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRunner(nil, RunnerConfig{Command: "echo", EnvFile: envFile})
+	t.Cleanup(func() { r.Shutdown() })
+
+	// The configured epoch wins over the task's creation time.
+	spec := r.buildBaseContainerSpec("c-test", "", harness.Claude)
+	r.agentEnvironment(&store.Task{CreatedAt: time.Now()}).apply(spec.Env)
+	want := map[string]string{
+		"TZ":                "Asia/Tokyo",
+		"LANG":              "ja_JP.UTF-8",
+		"LC_ALL":            "ja_JP.UTF-8",
+		"SOURCE_DATE_EPOCH": "1700000000",
+	}
+	for k, v := range want {
+		if spec.Env[k] != v {
+			t.Errorf("%s = %q, want %q", k, spec.Env[k], v)
+		}
+	}
+}
+
+func TestAgentEnvironment_UnknownTZFallsBack(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("WALLFACER_AGENT_TZ=Not/AZone\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRunner(nil, RunnerConfig{Command: "echo", EnvFile: envFile})
+	t.Cleanup(func() { r.Shutdown() })
+
+	if got := r.agentEnvironment(nil).tz; got != defaultAgentTZ {
+		t.Errorf("tz = %q, want fallback %q", got, defaultAgentTZ)
+	}
+}
+
+func TestAgentEnvironment_Proxy(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	content := "WALLFACER_AGENT_HTTP_PROXY=http://host.containers.internal:7890\nWALLFACER_AGENT_NO_PROXY=.corp.example.com\n"
+	if err := os.WriteFile(envFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRunner(nil, RunnerConfig{Command: "echo", EnvFile: envFile})
+	t.Cleanup(func() { r.Shutdown() })
+
+	// The global proxy serves both schemes, with the container alias
+	// rewritten to loopback and loopback kept off the proxy.
+	env := map[string]string{}
+	r.agentEnvironment(&store.Task{}).apply(env)
+	want := map[string]string{
+		"HTTP_PROXY":  "http://127.0.0.1:7890",
+		"https_proxy": "http://127.0.0.1:7890",
+		"NO_PROXY":    defaultNoProxy + ",.corp.example.com",
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("%s = %q, want %q", k, env[k], v)
+		}
+	}
+
+	// A task override replaces only the fields it sets.
+	env = map[string]string{}
+	r.agentEnvironment(&store.Task{Proxy: &store.TaskProxy{HTTPSProxy: "http://squid.internal:3128"}}).apply(env)
+	if env["HTTPS_PROXY"] != "http://squid.internal:3128" || env["HTTP_PROXY"] != "http://127.0.0.1:7890" {
+		t.Errorf("override: HTTP_PROXY=%q HTTPS_PROXY=%q", env["HTTP_PROXY"], env["HTTPS_PROXY"])
+	}
+
+	// Direct blanks every proxy variable the server would pass down.
+	env = map[string]string{}
+	r.agentEnvironment(&store.Task{Proxy: &store.TaskProxy{Direct: true}}).apply(env)
+	for _, k := range []string{"HTTP_PROXY", "https_proxy", "ALL_PROXY"} {
+		if v, ok := env[k]; !ok || v != "" {
+			t.Errorf("direct: %s = %q (set %v), want blank", k, v, ok)
+		}
+	}
+}
+
+func TestAgentEnvironment_NoProxyLeavesInherited(t *testing.T) {
+	r := NewRunner(nil, RunnerConfig{Command: "echo"})
+	t.Cleanup(func() { r.Shutdown() })
+
+	env := map[string]string{}
+	r.agentEnvironment(nil).apply(env)
+	if _, ok := env["HTTP_PROXY"]; ok {
+		t.Error("HTTP_PROXY set without a configured proxy")
+	}
+}
@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/url"
 	"time"
 
@@ -184,6 +185,7 @@ func (r *Runner) driveToposRun(bgCtx context.Context, taskID uuid.UUID, task sto
 	}
 	ctx, cancel := context.WithTimeout(bgCtx, timeout)
 	defer cancel()
+	defer r.trackRun(taskID, cancel)()
 
 	// Forward the topos run's live events onto the task timeline. The topos
 	// observer is called synchronously on the run goroutine(s), so it must not
@@ -264,7 +266,12 @@ func (r *Runner) driveToposRun(bgCtx context.Context, taskID uuid.UUID, task sto
 	// nothing in the repo to commit and it walks straight to done as before.
 	// Threading a worktree through the agentic path is deferred (tracked on #17).
 	if cur, gErr := r.taskStore(taskID).GetTask(bgCtx, taskID); gErr == nil && cur != nil && len(cur.WorktreePaths) > 0 {
-		if err := r.Commit(taskID, ""); err != nil {
+		if err := r.Commit(taskID, ""); errors.Is(err, ErrAwaitingApproval) {
+			_ = r.taskStore(taskID).UpdateTaskStatus(bgCtx, taskID, store.TaskStatusReview)
+			_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeStateChange,
+				store.NewStateChangeData(store.TaskStatusCommitting, store.TaskStatusReview, store.TriggerSystem, nil))
+			return
+		} else if err != nil {
 			logger.Runner.Error("topos run commit", "task", taskID, "error", err)
 			_ = r.taskStore(taskID).SetTaskFailureCategory(bgCtx, taskID, classifyFailure(err, false, ""))
 			_ = r.taskStore(taskID).UpdateTaskStatus(bgCtx, taskID, store.TaskStatusFailed)
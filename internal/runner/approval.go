@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// approvalTag is the info string of the fenced block an agent ends its turn
+// with to ask for approval.
+const approvalTag = "wallfacer-approval"
+
+// approvalGatesPrompt appends the approval protocol to a fresh prompt of a
+// task with ApprovalGates set. Test runs, empty (auto-continue) prompts, and
+// other tasks are returned unchanged.
+func (r *Runner) approvalGatesPrompt(task *store.Task, prompt string) string {
+	if !task.ApprovalGates || task.IsTestRun || prompt == "" {
+		return prompt
+	}
+	return r.promptsMgr.ApprovalGates(prompts.ApprovalGatesData{Prompt: prompt, Tag: approvalTag})
+}
+
+// parseApprovalRequest returns the approval request in the last
+// approvalTag-fenced block of an agent's final message. It reports false
+// when there is no such block or its JSON has no action.
+func parseApprovalRequest(result string) (store.ApprovalRequest, bool) {
+	var body string
+	found := false
+	lines := strings.Split(result, "\n")
+	for i := 0; i < len(lines); i++ {
+		fence, info, ok := openingFence(lines[i])
+		if !ok || info != approvalTag {
+			continue
+		}
+		var b strings.Builder
+		for i++; i < len(lines) && strings.TrimSpace(lines[i]) != fence; i++ {
+			b.WriteString(lines[i] + "\n")
+		}
+		body, found = b.String(), true
+	}
+	if !found {
+		return store.ApprovalRequest{}, false
+	}
+	var req struct {
+		Action  string `json:"action"`
+		Reason  string `json:"reason"`
+		Command string `json:"command"`
+	}
+	if json.Unmarshal([]byte(body), &req) != nil || strings.TrimSpace(req.Action) == "" {
+		return store.ApprovalRequest{}, false
+	}
+	return store.ApprovalRequest{
+		Action:  strings.TrimSpace(req.Action),
+		Reason:  strings.TrimSpace(req.Reason),
+		Command: strings.TrimSpace(req.Command),
+	}, true
+}
+
+// openingFence reports whether line opens a backtick code fence and returns
+// the fence and its info string.
+func openingFence(line string) (fence, info string, ok bool) {
+	line = strings.TrimSpace(line)
+	n := len(line) - len(strings.TrimLeft(line, "`"))
+	if n < 3 {
+		return "", "", false
+	}
+	return line[:n], strings.TrimSpace(line[n:]), true
+}
+
+// requestApproval records the approval request the agent ended turn with and
+// moves the task to waiting until the user decides it. It reports false,
+// leaving the task untouched, when the request cannot be stored.
+func (r *Runner) requestApproval(ctx context.Context, taskID uuid.UUID, turn int, req store.ApprovalRequest) bool {
+	s := r.taskStore(taskID)
+	req.Turn, req.RequestedAt = turn, time.Now()
+	req, err := s.AddApprovalRequest(ctx, taskID, req)
+	if err != nil {
+		logger.Runner.Warn("record approval request", "task", taskID, "error", err)
+		return false
+	}
+	_ = s.UpdateTaskStatus(ctx, taskID, store.TaskStatusWaiting)
+	_ = s.InsertEvent(ctx, taskID, store.EventTypeStateChange,
+		store.NewStateChangeData(store.TaskStatusInProgress, store.TaskStatusWaiting, store.TriggerSystem, nil))
+	_ = s.InsertEvent(ctx, taskID, store.EventTypeNeedsApproval, req)
+	_ = s.InsertEvent(ctx, taskID, store.EventTypeSystem, map[string]string{
+		"result": fmt.Sprintf("Agent requests approval #%d: %s", req.Seq, req.Action),
+	})
+	_ = s.InsertEvent(ctx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "feedback_waiting", Label: "feedback_waiting"})
+	return true
+}
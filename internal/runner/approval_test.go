@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestParseApprovalRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		want   store.ApprovalRequest
+		ok     bool
+	}{
+		{
+			name:   "request",
+			result: "The schema change is ready.\n\n```wallfacer-approval\n{\"action\": \"run migration 0042\", \"reason\": \"adds the index\", \"command\": \"make migrate\"}\n```",
+			want:   store.ApprovalRequest{Action: "run migration 0042", Reason: "adds the index", Command: "make migrate"},
+			ok:     true,
+		},
+		{
+			name:   "last block wins",
+			result: "````wallfacer-approval\n{\"action\": \"first\"}\n````\n```wallfacer-approval\n{\"action\": \"second\"}\n```",
+			want:   store.ApprovalRequest{Action: "second"},
+			ok:     true,
+		},
+		{name: "no block", result: "Done. All tests pass."},
+		{name: "other fence", result: "```json\n{\"action\": \"deploy\"}\n```"},
+		{name: "no action", result: "```wallfacer-approval\n{\"reason\": \"why\"}\n```"},
+		{name: "bad json", result: "```wallfacer-approval\nrun the migration\n```"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseApprovalRequest(tt.result)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("parseApprovalRequest = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestApprovalGatesPrompt(t *testing.T) {
+	_, r := setupTestRunner(t, nil)
+	task := &store.Task{ApprovalGates: true}
+
+	got := r.approvalGatesPrompt(task, "add the index")
+	if !strings.HasPrefix(got, "add the index") || !strings.Contains(got, "```"+approvalTag) {
+		t.Errorf("approvalGatesPrompt = %q", got)
+	}
+	if got := r.approvalGatesPrompt(task, ""); got != "" {
+		t.Errorf("auto-continue prompt changed to %q", got)
+	}
+	if got := r.approvalGatesPrompt(&store.Task{}, "add the index"); got != "add the index" {
+		t.Errorf("prompt of a task without approval gates changed to %q", got)
+	}
+}
+
+// TestRunApprovalRequestWaits verifies that a turn ending with an approval
+// request leaves the task waiting with the request pending and recorded as
+// a needs_approval event.
+func TestRunApprovalRequestWaits(t *testing.T) {
+	repo := setupTestRepo(t)
+	output := `{"result":"Ready to migrate.\n\n` + "```" + `wallfacer-approval\n{\"action\": \"run migration 0042\"}\n` + "```" + `","session_id":"sess1","stop_reason":"end_turn","is_error":false,"total_cost_usd":0.001}`
+	cmd := fakeCmdScript(t, output, 0)
+	s, r := setupRunnerWithCmd(t, []string{repo}, cmd)
+	ctx := context.Background()
+
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "add the index", Timeout: 5, ApprovalGates: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	r.Run(task.ID, "add the index", "", false)
+
+	updated, _ := s.GetTask(ctx, task.ID)
+	if updated.Status != store.TaskStatusWaiting {
+		t.Fatalf("status = %q, want waiting", updated.Status)
+	}
+	pending := updated.PendingApproval()
+	if pending == nil || pending.Seq != 1 || pending.Action != "run migration 0042" || pending.Turn != 1 {
+		t.Fatalf("pending approval = %+v", pending)
+	}
+	events, err := s.GetEvents(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeNeedsApproval {
+			found = strings.Contains(string(ev.Data), "run migration 0042")
+		}
+	}
+	if !found {
+		t.Fatal("no needs_approval event recorded")
+	}
+}
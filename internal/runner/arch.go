@@ -0,0 +1,17 @@
+package runner
+
+import "github.com/google/uuid"
+
+// workspaceArch returns the architecture override configured on the
+// workspace the task was dispatched under, or "" for the host's native
+// architecture.
+func (r *Runner) workspaceArch(taskID uuid.UUID) string {
+	if r.workspaceManager == nil {
+		return ""
+	}
+	ws, found, err := r.workspaceManager.WorkspaceByKey(r.taskWorkspaceKey(taskID))
+	if err != nil || !found {
+		return ""
+	}
+	return ws.Arch
+}
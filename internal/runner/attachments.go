@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// writeAttachments copies the task's attachments out of the store into a
+// directory the agent can read and returns it, or "" when the task has
+// none. The path depends only on the task, so a resumed session finds the
+// files where its first prompt said they were; the caller removes the
+// directory when the run ends.
+func (r *Runner) writeAttachments(task *store.Task) string {
+	if len(task.Attachments) == 0 {
+		return ""
+	}
+	dir := filepath.Join(cmp.Or(r.tmpDir, os.TempDir()), "wallfacer-attachments-"+task.ID.String())
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		logger.Runner.Warn("attachments: create dir", "task", task.ID, "error", err)
+		return ""
+	}
+	for _, a := range task.Attachments {
+		data, err := r.taskStore(task.ID).ReadTaskAttachment(task.ID, a.Name)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, a.Name), data, 0o600)
+		}
+		if err != nil {
+			logger.Runner.Warn("attachments: write file", "task", task.ID, "name", a.Name, "error", err)
+		}
+	}
+	return dir
+}
+
+// attachmentsPrompt appends the list of the task's attachments, written to
+// dir by writeAttachments, to a fresh prompt. Test runs, empty
+// (auto-continue) prompts, and tasks without attachments are returned
+// unchanged.
+func (r *Runner) attachmentsPrompt(task *store.Task, prompt, dir string) string {
+	if dir == "" || task.IsTestRun || prompt == "" {
+		return prompt
+	}
+	files := make([]prompts.TaskAttachment, len(task.Attachments))
+	for i, a := range task.Attachments {
+		files[i] = prompts.TaskAttachment{Name: a.Name, ContentType: a.ContentType, Size: a.Size}
+	}
+	return r.promptsMgr.TaskAttachments(prompts.TaskAttachmentsData{Prompt: prompt, Dir: dir, Files: files})
+}
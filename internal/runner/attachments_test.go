@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestWriteAttachments(t *testing.T) {
+	s, r := setupTestRunner(t, nil)
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "fix the layout", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir := r.writeAttachments(task); dir != "" {
+		t.Errorf("task without attachments wrote %s", dir)
+	}
+	if _, err := s.AddTaskAttachment(ctx, task.ID, "shot.png", "image/png", []byte("png")); err != nil {
+		t.Fatal(err)
+	}
+	task, _ = s.GetTask(ctx, task.ID)
+
+	dir := r.writeAttachments(task)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	if data, err := os.ReadFile(filepath.Join(dir, "shot.png")); err != nil || string(data) != "png" {
+		t.Fatalf("written attachment = %q, %v", data, err)
+	}
+	got := r.attachmentsPrompt(task, "fix the layout", dir)
+	if !strings.HasPrefix(got, "fix the layout") || !strings.Contains(got, dir) || !strings.Contains(got, "- shot.png (image/png, 3 bytes)") {
+		t.Errorf("attachmentsPrompt = %q", got)
+	}
+	if got := r.attachmentsPrompt(task, "", dir); got != "" {
+		t.Errorf("auto-continue prompt changed to %q", got)
+	}
+}
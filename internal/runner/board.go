@@ -65,7 +65,7 @@ func sharesWorkspace(worktreePaths map[string]string, selfWorkspaces set.Set[str
 // for read-only mounting based on its status.
 func canMountWorktree(status store.TaskStatus, worktreePaths map[string]string) bool {
 	switch status {
-	case store.TaskStatusWaiting, store.TaskStatusFailed:
+	case store.TaskStatusWaiting, store.TaskStatusReview, store.TaskStatusFailed:
 		return true
 	case store.TaskStatusDone:
 		// Only if at least one worktree directory still exists on disk.
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// bootstrapTimeout bounds one worktree's bootstrap command, so a hung
+// install cannot consume the whole task timeout before the agent starts.
+const bootstrapTimeout = 15 * time.Minute
+
+// workspaceBootstrap returns the bootstrap command configured on the
+// workspace the task was dispatched under, or "" when there is none.
+func (r *Runner) workspaceBootstrap(taskID uuid.UUID) string {
+	if r.workspaceManager == nil {
+		return ""
+	}
+	ws, found, err := r.workspaceManager.WorkspaceByKey(r.taskWorkspaceKey(taskID))
+	if err != nil || !found {
+		return ""
+	}
+	return ws.Bootstrap
+}
+
+// runBootstrap runs the workspace's bootstrap command in each of the task's
+// worktrees before the agent's first turn. It runs on the host with the
+// workspace's managed caches (see cacheEnv), so installs after the first are
+// mostly cache hits and the agent finds the same caches warm. A failure is
+// recorded as an event and does not stop the task: the agent can still
+// install what it needs itself.
+func (r *Runner) runBootstrap(ctx context.Context, taskID uuid.UUID, worktreePaths map[string]string) {
+	command := r.workspaceBootstrap(taskID)
+	if command == "" {
+		return
+	}
+	repos := lintableWorktrees(worktreePaths)
+	if len(repos) == 0 {
+		return
+	}
+	env := r.cacheEnv(taskID)
+	bgCtx := r.shutdownCtx
+	s := r.taskStore(taskID)
+	_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "bootstrap", Label: "bootstrap"})
+	defer func() {
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSpanEnd, store.SpanData{Phase: "bootstrap", Label: "bootstrap"})
+	}()
+
+	for _, repo := range repos {
+		start := time.Now()
+		runCtx, cancel := context.WithTimeout(ctx, bootstrapTimeout)
+		out, err := runShellCommand(runCtx, worktreePaths[repo], command, env)
+		cancel()
+		elapsed := time.Since(start).Round(time.Second)
+		if err != nil {
+			logger.Runner.Warn("workspace bootstrap failed", "task", taskID, "repo", repo, "command", command, "error", err)
+			_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+				"phase":   "bootstrap",
+				"status":  "failed",
+				"repo":    repo,
+				"command": command,
+				"result":  fmt.Sprintf("Bootstrap `%s` failed in %s after %s: %v\n%s", command, repo, elapsed, err, truncate(out, maxLintOutputBytes)),
+			})
+			continue
+		}
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+			"phase":   "bootstrap",
+			"status":  "done",
+			"repo":    repo,
+			"command": command,
+			"result":  fmt.Sprintf("Bootstrap `%s` finished in %s (%s).", command, repo, elapsed),
+		})
+	}
+}
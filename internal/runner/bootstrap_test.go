@@ -0,0 +1,95 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/workspace"
+)
+
+// setupBootstrap creates a workspace over a fresh repo with the given
+// bootstrap command, a runner viewing it, and a task with worktrees.
+func setupBootstrap(t *testing.T, command string) (*store.Store, *Runner, uuid.UUID, map[string]string) {
+	t.Helper()
+	s, r, taskID, _ := setupWorkspaceTask(t, func(mgr *workspace.Manager, wsID string) error {
+		_, err := mgr.SetBootstrap(wsID, command)
+		return err
+	})
+	worktreePaths, branchName, err := r.setupWorktrees(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.cleanupWorktrees(taskID, worktreePaths, branchName) })
+	return s, r, taskID, worktreePaths
+}
+
+// bootstrapEvents returns the data of every bootstrap system event.
+func bootstrapEvents(t *testing.T, s *store.Store, taskID uuid.UUID) []string {
+	t.Helper()
+	events, err := s.GetEvents(context.Background(), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeSystem && strings.Contains(string(ev.Data), `"phase":"bootstrap"`) {
+			out = append(out, string(ev.Data))
+		}
+	}
+	return out
+}
+
+func TestRunBootstrap_RunsInWorktree(t *testing.T) {
+	s, r, taskID, worktreePaths := setupBootstrap(t, "echo ready > .bootstrapped")
+
+	r.runBootstrap(context.Background(), taskID, worktreePaths)
+
+	for repo, wt := range worktreePaths {
+		if _, err := os.Stat(filepath.Join(wt, ".bootstrapped")); err != nil {
+			t.Errorf("bootstrap did not run in worktree of %s: %v", repo, err)
+		}
+	}
+	events := bootstrapEvents(t, s, taskID)
+	if len(events) != 1 || !strings.Contains(events[0], `"status":"done"`) {
+		t.Fatalf("bootstrap events = %v, want one done event", events)
+	}
+}
+
+func TestRunBootstrap_FailureIsRecorded(t *testing.T) {
+	s, r, taskID, worktreePaths := setupBootstrap(t, "echo missing lockfile; exit 3")
+
+	r.runBootstrap(context.Background(), taskID, worktreePaths)
+
+	events := bootstrapEvents(t, s, taskID)
+	if len(events) != 1 || !strings.Contains(events[0], `"status":"failed"`) {
+		t.Fatalf("bootstrap events = %v, want one failed event", events)
+	}
+	if !strings.Contains(events[0], "missing lockfile") {
+		t.Errorf("failed event should quote the command output: %s", events[0])
+	}
+}
+
+func TestRunBootstrap_NoCommand(t *testing.T) {
+	s, r, taskID, worktreePaths := setupBootstrap(t, "")
+
+	r.runBootstrap(context.Background(), taskID, worktreePaths)
+
+	if events := bootstrapEvents(t, s, taskID); len(events) != 0 {
+		t.Fatalf("bootstrap events = %v, want none without a command", events)
+	}
+}
+
+func TestWorkspaceBootstrap_StaticManager(t *testing.T) {
+	_, r := setupTestRunner(t, nil)
+	if got := r.workspaceBootstrap(uuid.New()); got != "" {
+		t.Fatalf("workspaceBootstrap = %q, want empty without a workspace registry", got)
+	}
+}
@@ -0,0 +1,33 @@
+package runner
+
+import (
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/devcache"
+)
+
+// taskWorkspaceKey returns the storage key of the workspace the task was
+// dispatched under, falling back to the currently viewed workspace.
+func (r *Runner) taskWorkspaceKey(taskID uuid.UUID) string {
+	if k, ok := r.taskWSKey.Load(taskID); ok {
+		return k.(string)
+	}
+	return r.currentWSKey()
+}
+
+// cacheEnv returns the environment variables pointing package managers at
+// the managed caches of the task's workspace (see devcache), or nil when
+// managed caches are off. Each workspace keeps its own caches so a stale or
+// bloated one can be cleared through /api/caches without affecting others.
+func (r *Runner) cacheEnv(taskID uuid.UUID) map[string]string {
+	if r.cachesDir == "" {
+		return nil
+	}
+	env, err := devcache.Env(r.cachesDir, r.taskWorkspaceKey(taskID))
+	if err != nil {
+		logger.Runner.Warn("managed caches unavailable", "task", taskID, "error", err)
+		return nil
+	}
+	return env
+}
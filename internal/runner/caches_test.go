@@ -0,0 +1,34 @@
+package runner
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/workspace"
+)
+
+func TestCacheEnv_UsesTaskWorkspace(t *testing.T) {
+	_, r := setupTestRunner(t, nil)
+	r.cachesDir = t.TempDir()
+	r.applyWorkspaceSnapshot(workspace.Snapshot{Key: "viewed"})
+
+	mapped := uuid.New()
+	r.taskWSKey.Store(mapped, "dispatched")
+	if got, want := r.cacheEnv(mapped)["GOCACHE"], filepath.Join(r.cachesDir, "dispatched", "go-build"); got != want {
+		t.Errorf("mapped task GOCACHE = %q, want %q", got, want)
+	}
+	// A task without a mapping falls back to the viewed workspace.
+	if got, want := r.cacheEnv(uuid.New())["npm_config_cache"], filepath.Join(r.cachesDir, "viewed", "npm"); got != want {
+		t.Errorf("unmapped task npm_config_cache = %q, want %q", got, want)
+	}
+}
+
+func TestCacheEnv_DisabledWithoutCachesDir(t *testing.T) {
+	_, r := setupTestRunner(t, nil)
+	r.applyWorkspaceSnapshot(workspace.Snapshot{Key: "viewed"})
+	if env := r.cacheEnv(uuid.New()); env != nil {
+		t.Fatalf("cacheEnv = %v, want nil without a caches dir", env)
+	}
+}
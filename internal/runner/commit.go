@@ -1,12 +1,15 @@
 package runner
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,6 +22,7 @@ import (
 	"latere.ai/x/wallfacer/internal/harness"
 	"latere.ai/x/wallfacer/internal/logger"
 	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/pkg/commitstyle"
 	"latere.ai/x/wallfacer/internal/prompts"
 	"latere.ai/x/wallfacer/internal/store"
 )
@@ -40,8 +44,16 @@ func newCommitMessageGenerationError(format string, args ...any) error {
 	return fmt.Errorf("%w: %s", ErrCommitMessageGeneration, fmt.Sprintf(format, args...))
 }
 
+// ErrAwaitingApproval is returned by Commit when a RequireApproval task's
+// changes are committed in its worktree and the merge is held for review.
+// It is not a failure: the caller moves the task to review, and approval
+// runs Commit again to finish the pipeline.
+var ErrAwaitingApproval = errors.New("merge awaiting approval")
+
 // Commit creates its own timeout context and runs the full commit pipeline
-// (stage → rebase → merge → cleanup) for a task.
+// (stage → rebase → merge → cleanup) for a task. A RequireApproval task
+// stops after staging with ErrAwaitingApproval until it is approved, and
+// then resumes at rebase.
 // Returns an error if any phase of the pipeline fails.
 func (r *Runner) Commit(taskID uuid.UUID, sessionID string) error {
 	task, err := r.taskStore(taskID).GetTask(r.shutdownCtx, taskID)
@@ -55,9 +67,112 @@ func (r *Runner) Commit(taskID uuid.UUID, sessionID string) error {
 	}
 	ctx, cancel := context.WithTimeout(r.shutdownCtx, timeout)
 	defer cancel()
+	if task.IsShadow() {
+		// The shadow arm of an experiment is measured and discarded, never
+		// merged, however it reaches the commit step.
+		r.discardShadow(ctx, task)
+		return nil
+	}
+	if task.SkipCommit {
+		r.leaveChangesInWorktree(task)
+		return nil
+	}
+	if baseHashes, ok := noChangesProduced(ctx, task.WorktreePaths); ok {
+		r.finishWithoutChanges(task, baseHashes)
+		return nil
+	}
+	if task.RequireApproval {
+		if task.ApprovedAt == nil {
+			if err := r.stageAndLint(ctx, taskID, sessionID, task.WorktreePaths); err != nil {
+				return err
+			}
+			r.holdForApproval(task)
+			return ErrAwaitingApproval
+		}
+		return r.mergeStaged(ctx, taskID, sessionID, task.WorktreePaths, task.BranchName)
+	}
 	return r.commit(ctx, taskID, sessionID, task.Turns, task.WorktreePaths, task.BranchName)
 }
 
+// holdForApproval records on the timeline that a RequireApproval task's
+// changes are committed and its merge waits for POST /api/tasks/{id}/approve.
+func (r *Runner) holdForApproval(task *store.Task) {
+	logger.Runner.Info("merge held for approval", "task", task.ID)
+	_ = r.taskStore(task.ID).InsertEvent(r.shutdownCtx, task.ID, store.EventTypeSystem, map[string]string{
+		"result": "Changes committed in the worktree. Review the diff, then approve to merge or reject with feedback.",
+		"phase":  "review",
+	})
+}
+
+// leaveChangesInWorktree stands in for the commit pipeline on a SkipCommit
+// task: nothing is staged, merged, pushed, or cleaned up, and each
+// worktree holding the changes is recorded on the timeline.
+func (r *Runner) leaveChangesInWorktree(task *store.Task) {
+	logger.Runner.Info("auto-commit skipped", "task", task.ID)
+	for _, repoPath := range slices.Sorted(maps.Keys(task.WorktreePaths)) {
+		_ = r.taskStore(task.ID).InsertEvent(r.shutdownCtx, task.ID, store.EventTypeSystem, map[string]string{
+			"result": fmt.Sprintf("Auto-commit skipped: changes left in worktree %s", task.WorktreePaths[repoPath]),
+			"phase":  "skip_commit",
+		})
+	}
+}
+
+// noChangesProduced reports whether a task's worktrees hold neither
+// uncommitted changes nor commits ahead of their repository's default
+// branch, returning each repository's default-branch HEAD. Snapshot
+// workspaces, missing worktrees, and git errors report false so the full
+// pipeline decides.
+func noChangesProduced(ctx context.Context, worktreePaths map[string]string) (map[string]string, bool) {
+	if len(worktreePaths) == 0 {
+		return nil, false
+	}
+	baseHashes := make(map[string]string, len(worktreePaths))
+	for repoPath, worktreePath := range worktreePaths {
+		if !gitutil.IsGitRepo(repoPath) || !gitutil.HasCommits(repoPath) || !gitutil.IsGitRepo(worktreePath) {
+			return nil, false
+		}
+		removeInjectedInstructions(ctx, worktreePath)
+		if dirty, err := gitutil.HasChanges(ctx, worktreePath); err != nil || dirty {
+			return nil, false
+		}
+		defBranch, err := gitutil.DefaultBranch(repoPath)
+		if err != nil {
+			return nil, false
+		}
+		if ahead, err := gitutil.HasCommitsAheadOf(worktreePath, defBranch); err != nil || ahead {
+			return nil, false
+		}
+		base, err := gitutil.GetCommitHashForRef(repoPath, defBranch)
+		if err != nil {
+			return nil, false
+		}
+		baseHashes[repoPath] = base
+	}
+	return baseHashes, true
+}
+
+// finishWithoutChanges stands in for the commit pipeline on a task whose
+// agent changed nothing: no commit message is generated and nothing is
+// rebased or merged. The base hashes are kept so the task's diff shows
+// genuinely no changes, and the worktrees are cleaned up as after a merge.
+func (r *Runner) finishWithoutChanges(task *store.Task, baseHashes map[string]string) {
+	bgCtx := r.shutdownCtx
+	logger.Runner.Info("commit skipped: no changes produced", "task", task.ID)
+	if err := r.taskStore(task.ID).UpdateTaskBaseCommitHashes(bgCtx, task.ID, baseHashes); err != nil {
+		logger.Runner.Warn("save base commit hashes", "task", task.ID, "error", err)
+	}
+	_ = r.taskStore(task.ID).InsertEvent(bgCtx, task.ID, store.EventTypeSystem, map[string]string{
+		"result": "No changes produced; commit pipeline skipped.",
+		"phase":  "no_changes",
+	})
+	r.cleanupWorktrees(task.ID, task.WorktreePaths, task.BranchName)
+	r.pipelineProgress(task.ID, store.PipelineProgressData{
+		Phase:   store.PipelinePhaseDone,
+		Percent: pipelinePercentDone,
+		Message: "No changes produced.",
+	})
+}
+
 // commit runs Phase 1 (host-side commit in worktree), Phase 2 (host-side
 // rebase+merge), Phase 3 (worktree cleanup).
 // Returns an error if the rebase/merge phase fails.
@@ -69,13 +184,23 @@ func (r *Runner) commit(
 	worktreePaths map[string]string,
 	branchName string,
 ) error {
+	if err := r.stageAndLint(ctx, taskID, sessionID, worktreePaths); err != nil {
+		return err
+	}
+	return r.mergeStaged(ctx, taskID, sessionID, worktreePaths, branchName)
+}
+
+// stageAndLint runs Phase 1, committing all uncommitted changes in each
+// worktree, followed by the optional pre-merge lint stage.
+func (r *Runner) stageAndLint(ctx context.Context, taskID uuid.UUID, sessionID string, worktreePaths map[string]string) error {
 	bgCtx := r.shutdownCtx
 	logger.Runner.Info("auto-commit", "task", taskID, "session", sessionID)
 
 	// Phase 1: stage and commit all uncommitted changes on the host.
-	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
-
-		"result": "Phase 1/3: Staging and committing changes...",
+	r.pipelineProgress(taskID, store.PipelineProgressData{
+		Phase:   store.PipelinePhaseStage,
+		Percent: pipelinePercentStage,
+		Message: "Staging and committing changes...",
 	})
 	task, getErr := r.taskStore(taskID).GetTask(bgCtx, taskID)
 	if getErr != nil {
@@ -103,11 +228,38 @@ func (r *Runner) commit(
 		return fmt.Errorf("stage and commit: %w", stageErr)
 	}
 
-	// Phase 2: host-side rebase and merge for each git worktree.
-	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
-
-		"result": "Phase 2/3: Rebasing and merging into default branch...",
+	// Optional pre-merge lint stage: formatters, linters, and at most one
+	// agent feedback turn. It never blocks the merge.
+	r.pipelineProgress(taskID, store.PipelineProgressData{
+		Phase:   store.PipelinePhaseLint,
+		Percent: pipelinePercentLint,
+		Message: "Running pre-merge checks...",
 	})
+	r.preMergeLint(ctx, taskID, sessionID, worktreePaths)
+	return nil
+}
+
+// mergeStaged runs Phase 2 (host-side rebase+merge, or a pull request in
+// MergeModePullRequest) and Phase 3 (worktree cleanup) over branches whose
+// changes stageAndLint has already committed.
+func (r *Runner) mergeStaged(
+	ctx context.Context,
+	taskID uuid.UUID,
+	sessionID string,
+	worktreePaths map[string]string,
+	branchName string,
+) error {
+	bgCtx := r.shutdownCtx
+	task, getErr := r.taskStore(taskID).GetTask(bgCtx, taskID)
+	if getErr != nil {
+		logger.Runner.Warn("merge: GetTask failed", "task", taskID, "error", getErr)
+	}
+	if task != nil && task.MergeMode == store.MergeModePullRequest {
+		return r.commitAsPullRequests(ctx, taskID, worktreePaths, branchName)
+	}
+
+	// Phase 2: host-side rebase and merge for each git worktree. Progress
+	// is reported per repository by rebaseAndMerge.
 	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "commit", Label: "rebase_merge"})
 
 	commitHashes, baseHashes, snapshotDiffs, mergeErr := r.rebaseAndMerge(ctx, taskID, worktreePaths, branchName, sessionID)
@@ -123,9 +275,10 @@ func (r *Runner) commit(
 	}
 
 	// Phase 3: persist commit hashes and clean up worktrees.
-	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
-
-		"result": "Phase 3/3: Cleaning up...",
+	r.pipelineProgress(taskID, store.PipelineProgressData{
+		Phase:   store.PipelinePhaseCleanup,
+		Percent: pipelinePercentCleanup,
+		Message: "Cleaning up...",
 	})
 	if len(commitHashes) > 0 {
 		if err := r.taskStore(taskID).UpdateTaskCommitHashes(bgCtx, taskID, commitHashes); err != nil {
@@ -137,6 +290,7 @@ func (r *Runner) commit(
 			logger.Runner.Warn("save base commit hashes", "task", taskID, "error", err)
 		}
 	}
+	r.precomputeDiffSnapshots(bgCtx, taskID, baseHashes, commitHashes)
 	if len(snapshotDiffs) > 0 {
 		if err := r.taskStore(taskID).UpdateTaskSnapshotDiffs(bgCtx, taskID, snapshotDiffs); err != nil {
 			logger.Runner.Warn("save snapshot diffs", "task", taskID, "error", err)
@@ -147,9 +301,10 @@ func (r *Runner) commit(
 	r.cleanupWorktrees(taskID, worktreePaths, branchName)
 	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanEnd, store.SpanData{Phase: "commit", Label: "cleanup"})
 
-	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
-
-		"result": "Commit pipeline completed.",
+	r.pipelineProgress(taskID, store.PipelineProgressData{
+		Phase:   store.PipelinePhaseDone,
+		Percent: pipelinePercentDone,
+		Message: "Commit pipeline completed.",
 	})
 	logger.Runner.Info("commit completed", "task", taskID)
 
@@ -157,9 +312,92 @@ func (r *Runner) commit(
 	// least AutoPushThreshold commits ahead of its upstream.
 	r.maybeAutoPush(bgCtx, taskID, worktreePaths)
 
+	// Publish: if the workspace has a publish command, build and upload
+	// artifacts from each merge commit in the background.
+	r.publishMerged(taskID, commitHashes)
+
+	// Preview: if the workspace has a preview provider, deploy each merge
+	// commit in the background and attach the preview URLs to the task.
+	r.previewMerged(taskID, commitHashes)
+
 	return nil
 }
 
+// commitAsPullRequests finishes the commit pipeline for a task in
+// pull-request merge mode: Phase 2 pushes each task branch and opens a pull
+// request for it instead of merging, and Phase 3 records the pull requests
+// and the branches' commit ranges before the worktrees are cleaned up like
+// a merged task's. The branch itself lives on in origin. Auto-push, publish,
+// and preview all act on merge commits, so none of them runs.
+func (r *Runner) commitAsPullRequests(ctx context.Context, taskID uuid.UUID, worktreePaths map[string]string, branchName string) error {
+	bgCtx := r.shutdownCtx
+	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "commit", Label: "pull_request"})
+	commitHashes, baseHashes, urls, prErr := r.openPullRequests(ctx, taskID, worktreePaths, branchName)
+	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanEnd, store.SpanData{Phase: "commit", Label: "pull_request"})
+	if len(urls) > 0 {
+		if err := r.taskStore(taskID).UpdateTaskPullRequestURLs(bgCtx, taskID, urls); err != nil {
+			logger.Runner.Warn("save pull request urls", "task", taskID, "error", err)
+		}
+	}
+	if prErr != nil {
+		logger.Runner.Error("pull request failed", "task", taskID, "error", prErr)
+		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeError, map[string]string{
+			"error": "pull request failed: " + prErr.Error(),
+		})
+		return fmt.Errorf("pull request: %w", prErr)
+	}
+
+	r.pipelineProgress(taskID, store.PipelineProgressData{
+		Phase:   store.PipelinePhaseCleanup,
+		Percent: pipelinePercentCleanup,
+		Message: "Cleaning up...",
+	})
+	if len(commitHashes) > 0 {
+		if err := r.taskStore(taskID).UpdateTaskCommitHashes(bgCtx, taskID, commitHashes); err != nil {
+			logger.Runner.Warn("save commit hashes", "task", taskID, "error", err)
+		}
+	}
+	if len(baseHashes) > 0 {
+		if err := r.taskStore(taskID).UpdateTaskBaseCommitHashes(bgCtx, taskID, baseHashes); err != nil {
+			logger.Runner.Warn("save base commit hashes", "task", taskID, "error", err)
+		}
+	}
+	r.precomputeDiffSnapshots(bgCtx, taskID, baseHashes, commitHashes)
+	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "commit", Label: "cleanup"})
+	r.cleanupWorktrees(taskID, worktreePaths, branchName)
+	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanEnd, store.SpanData{Phase: "commit", Label: "cleanup"})
+
+	r.pipelineProgress(taskID, store.PipelineProgressData{
+		Phase:   store.PipelinePhaseDone,
+		Percent: pipelinePercentDone,
+		Message: "Commit pipeline completed.",
+	})
+	logger.Runner.Info("commit completed with pull requests", "task", taskID, "pull_requests", len(urls))
+	return nil
+}
+
+// precomputeDiffSnapshots renders each merged repository's diff between the
+// pre-merge base and the merge commit and saves it as the task's diff
+// snapshot, so the review view of a done task does not have to run git diff
+// on first open. Failures only cost that speed-up and are logged.
+func (r *Runner) precomputeDiffSnapshots(ctx context.Context, taskID uuid.UUID, baseHashes, commitHashes map[string]string) {
+	s := r.taskStore(taskID)
+	for repoPath, head := range commitHashes {
+		base := baseHashes[repoPath]
+		if base == "" {
+			continue
+		}
+		out, err := cmdexec.Git(repoPath, "diff", base, head).WithContext(ctx).Output()
+		if err != nil {
+			logger.Runner.Warn("precompute diff snapshot", "task", taskID, "repo", repoPath, "error", err)
+			continue
+		}
+		if err := s.SaveDiffSnapshot(taskID, repoPath, store.DiffSnapshot{Base: base, Head: head, Diff: out}); err != nil {
+			logger.Runner.Warn("save diff snapshot", "task", taskID, "repo", repoPath, "error", err)
+		}
+	}
+}
+
 // maybeAutoPush checks the auto-push configuration and, for each repo that
 // qualifies (ahead_count >= threshold), runs `git push`.
 func (r *Runner) maybeAutoPush(ctx context.Context, taskID uuid.UUID, worktreePaths map[string]string) {
@@ -244,7 +482,9 @@ func (r *Runner) MaybeAutoPushWorkspace(ctx context.Context, ws string) {
 }
 
 // hostStageAndCommit stages and commits all uncommitted changes in each
-// worktree directly on the host. Returns true if any new commits were created.
+// worktree directly on the host, except untracked paths matching the
+// workspace's stage-ignore patterns, which are listed in a system event.
+// Returns true if any new commits were created.
 // Returns an error if changes were present but could not be staged or committed.
 // ctx is the task timeout context: all git subprocesses are tied to it so that
 // a task timeout or server shutdown interrupts them promptly.
@@ -259,12 +499,13 @@ func (r *Runner) hostStageAndCommit(ctx context.Context, taskID uuid.UUID, workt
 		repoPath     string
 		worktreePath string
 		diffStat     string
-		recentLog    string
+		style        commitstyle.Profile
 	}
 	var pending []pendingCommit
 	var errs []string
 
 	var missing []string
+	stageIgnore := r.workspaceStageIgnore(taskID)
 	for repoPath, worktreePath := range worktreePaths {
 		if _, err := os.Stat(worktreePath); err != nil {
 			logger.Runner.Warn("host commit: worktree missing, skipping", "repo", repoPath, "path", worktreePath)
@@ -276,42 +517,29 @@ func (r *Runner) hostStageAndCommit(ctx context.Context, taskID uuid.UUID, workt
 			missing = append(missing, repoPath)
 			continue
 		}
-		// Clean up instructions files injected by the container mount.
-		// The workspace CLAUDE.md / AGENTS.md is bind-mounted into the
-		// worktree for the agent to read, but it must not be committed.
-		// Remove the file if it is not already tracked by git (i.e. the
-		// original repo didn't have one). If the repo does track it, leave
-		// it alone so legitimate edits are preserved.
-		for _, instrFile := range []string{prompts.ClaudeInstructionsFilename, prompts.CodexInstructionsFilename} {
-			p := filepath.Join(worktreePath, instrFile)
-			if _, err := os.Stat(p); err != nil {
-				continue // file doesn't exist
-			}
-			// git ls-files exits 0 with output if the file is tracked.
-			out, err := cmdexec.Git(worktreePath, "ls-files", instrFile).WithContext(ctx).Output()
-			if err != nil || strings.TrimSpace(out) == "" {
-				_ = os.Remove(p) // untracked — remove before staging
-			}
-		}
+		removeInjectedInstructions(ctx, worktreePath)
 
-		if out, err := cmdexec.Git(worktreePath, "add", "-A").WithContext(ctx).Combined(); err != nil {
+		skipped, err := gitutil.StageAll(ctx, worktreePath, repoStageIgnore(stageIgnore, repoPath))
+		if err != nil {
 			if ctx.Err() != nil {
 				return false, fmt.Errorf("context canceled during git add: %w", ctx.Err())
 			}
-			logger.Runner.Warn("host commit: git add -A", "repo", repoPath, "worktree", worktreePath, "error", err, "output", out)
-			errs = append(errs, fmt.Sprintf("git add in %s (worktree %s): %v: %s", repoPath, worktreePath, err, out))
+			logger.Runner.Warn("host commit: git add -A", "repo", repoPath, "worktree", worktreePath, "error", err)
+			errs = append(errs, fmt.Sprintf("git add in %s (worktree %s): %v", repoPath, worktreePath, err))
 			continue
 		}
+		r.recordSkippedPaths(r.shutdownCtx, taskID, repoPath, skipped)
 
-		hasChanges, _ := gitutil.HasChanges(ctx, worktreePath)
+		// Only the index counts: paths held back by stage-ignore patterns
+		// stay behind as untracked files.
+		hasChanges, _ := gitutil.HasStagedChanges(ctx, worktreePath)
 		if !hasChanges {
 			logger.Runner.Info("host commit: nothing to commit", "repo", repoPath)
 			continue
 		}
 
 		statOut, _ := cmdexec.Git(worktreePath, "diff", "--cached", "--stat").WithContext(ctx).Output()
-		logOut, _ := cmdexec.Git(worktreePath, "log", "--format=%s", "-5").WithContext(ctx).Output()
-		pending = append(pending, pendingCommit{repoPath, worktreePath, statOut, logOut})
+		pending = append(pending, pendingCommit{repoPath, worktreePath, statOut, r.commitStyle(ctx, taskID, repoPath)})
 	}
 
 	if len(pending) == 0 {
@@ -324,21 +552,27 @@ func (r *Runner) hostStageAndCommit(ctx context.Context, taskID uuid.UUID, workt
 		return false, nil
 	}
 
-	// Build combined diff stat and git log context across all worktrees, then
-	// generate a descriptive commit message via a lightweight Claude container.
+	// Build combined diff stat and commit-style context across all worktrees,
+	// then generate a descriptive commit message via a lightweight Claude
+	// container. The style rules are used only when every repository agrees
+	// on them; the examples are always shown.
 	var allStats strings.Builder
 	var allLogs strings.Builder
+	style := pending[0].style.Guidance()
 	for _, p := range pending {
 		if len(pending) > 1 {
 			allStats.WriteString("Repository: " + p.repoPath + "\n")
 			allLogs.WriteString("Repository: " + p.repoPath + "\n")
 		}
 		allStats.WriteString(p.diffStat + "\n")
-		if p.recentLog != "" {
-			allLogs.WriteString(p.recentLog + "\n")
+		if len(p.style.Examples) > 0 {
+			allLogs.WriteString(strings.Join(p.style.Examples, "\n") + "\n")
+		}
+		if p.style.Guidance() != style {
+			style = ""
 		}
 	}
-	msg, err := r.generateCommitMessage(ctx, taskID, prompt, allStats.String(), allLogs.String())
+	msg, err := r.generateCommitMessage(ctx, taskID, prompt, allStats.String(), allLogs.String(), style)
 	if err != nil {
 		// Do not fabricate a commit message. Surface the failure (already wrapped
 		// with ErrCommitMessageGeneration) so the caller returns the task to
@@ -380,6 +614,26 @@ func (r *Runner) hostStageAndCommit(ctx context.Context, taskID uuid.UUID, workt
 	return committed, nil
 }
 
+// removeInjectedInstructions cleans up instructions files injected by the
+// container mount. The workspace CLAUDE.md / AGENTS.md is bind-mounted into
+// the worktree for the agent to read, but it must not be committed. The file
+// is removed if it is not already tracked by git (i.e. the original repo
+// didn't have one). If the repo does track it, it is left alone so
+// legitimate edits are preserved.
+func removeInjectedInstructions(ctx context.Context, worktreePath string) {
+	for _, instrFile := range []string{prompts.ClaudeInstructionsFilename, prompts.CodexInstructionsFilename} {
+		p := filepath.Join(worktreePath, instrFile)
+		if _, err := os.Stat(p); err != nil {
+			continue // file doesn't exist
+		}
+		// git ls-files exits 0 with output if the file is tracked.
+		out, err := cmdexec.Git(worktreePath, "ls-files", instrFile).WithContext(ctx).Output()
+		if err != nil || strings.TrimSpace(out) == "" {
+			_ = os.Remove(p) // untracked — remove before staging
+		}
+	}
+}
+
 // runCommitContainer launches one commit-message agent container and
 // returns the parsed output. This is the lowest-level primitive shared by
 // both the task-aware commit path and the planning-facing public method;
@@ -584,11 +838,12 @@ func looksLikePreamble(line string) bool {
 }
 
 // generateCommitMessage runs a lightweight container to produce a descriptive
-// git commit message from the task prompt, staged diff stats, and recent git
-// log history (used to match the project's commit style).
+// git commit message from the task prompt, staged diff stats, recent commit
+// subjects, and the subject-line rules of the repository's commit-style
+// profile (used to match the project's commit style; see commitStyle).
 // ctx is the caller-supplied task context; a 90-second sub-deadline is derived
 // from it so that task cancellation or timeout propagates into the container.
-func (r *Runner) generateCommitMessage(ctx context.Context, taskID uuid.UUID, prompt, diffStat, recentLog string) (string, error) {
+func (r *Runner) generateCommitMessage(ctx context.Context, taskID uuid.UUID, prompt, diffStat, recentLog, style string) (string, error) {
 	task, err := r.taskStore(taskID).GetTask(r.shutdownCtx, taskID)
 	if err != nil {
 		logger.Runner.Warn("generate commit message: get task", "task", taskID, "error", err)
@@ -598,6 +853,7 @@ func (r *Runner) generateCommitMessage(ctx context.Context, taskID uuid.UUID, pr
 		Prompt:    prompt,
 		DiffStat:  diffStat,
 		RecentLog: recentLog,
+		Style:     style,
 	})
 
 	// A task on the in-process native harness (topos) cannot run the commit-msg
@@ -659,7 +915,10 @@ func (r *Runner) rebaseAndMerge(
 	snapshotDiffs = make(map[string]string)
 
 	var missing int
-	for repoPath, worktreePath := range worktreePaths {
+	repos := slices.Sorted(maps.Keys(worktreePaths))
+	for i, repoPath := range repos {
+		worktreePath := worktreePaths[repoPath]
+		progress := repoProgress{index: i, count: len(repos)}
 		if _, err := os.Stat(worktreePath); err != nil {
 			logger.Runner.Warn("rebase+merge: worktree missing, skipping", "task", taskID, "repo", repoPath, "path", worktreePath)
 			missing++
@@ -669,13 +928,15 @@ func (r *Runner) rebaseAndMerge(
 
 		// Serialize rebase+merge per repo so concurrent tasks on the same
 		// repo don't race (the second task sees the first task's merge
-		// before rebasing). Tasks on different repos remain fully concurrent.
-		mu := r.repoLock(repoPath)
-		mu.Lock()
-
-		err := r.rebaseAndMergeOne(ctx, taskID, repoPath, worktreePath, branchName, sessionID, bgCtx, commitHashes, baseHashes, snapshotDiffs)
-		mu.Unlock()
-		if err != nil {
+		// before rebasing). Tasks on different repos remain fully concurrent,
+		// and tasks on the same repo merge in the order they got here.
+		r.pipelineProgress(taskID, store.PipelineProgressData{
+			Phase:   store.PipelinePhaseMergeQueue,
+			Repo:    repoPath,
+			Percent: progress.percent(store.PipelinePhaseMergeQueue, 0),
+			Message: fmt.Sprintf("Waiting for the merge lock on %s...", filepath.Base(repoPath)),
+		})
+		if err := r.rebaseAndMergeQueued(ctx, taskID, repoPath, worktreePath, branchName, sessionID, bgCtx, progress, commitHashes, baseHashes, snapshotDiffs); err != nil {
 			return commitHashes, baseHashes, snapshotDiffs, err
 		}
 	}
@@ -687,21 +948,52 @@ func (r *Runner) rebaseAndMerge(
 	return commitHashes, baseHashes, snapshotDiffs, nil
 }
 
+// rebaseAndMergeQueued takes repoPath's merge lock, runs rebaseAndMergeOne
+// under it, and releases it even if the pipeline panics. A task whose ctx
+// ends while it waits leaves the queue.
+func (r *Runner) rebaseAndMergeQueued(
+	ctx context.Context,
+	taskID uuid.UUID,
+	repoPath, worktreePath, branchName, sessionID string,
+	bgCtx context.Context, //nolint:revive // bgCtx is a separate long-lived context, not a replacement for ctx
+	progress repoProgress,
+	commitHashes, baseHashes, snapshotDiffs map[string]string,
+) error {
+	ahead, err := r.mergeQueue.acquire(ctx, repoPath, taskID)
+	if err != nil {
+		return fmt.Errorf("wait for merge lock on %s: %w", filepath.Base(repoPath), err)
+	}
+	defer r.mergeQueue.release(repoPath)
+	if ahead > 0 {
+		logger.Runner.Info("rebase+merge: waited for merge queue", "task", taskID, "repo", repoPath, "ahead", ahead)
+		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+			"phase":  "merge_queue",
+			"repo":   repoPath,
+			"ahead":  ahead,
+			"result": fmt.Sprintf("Merged into %s after %d earlier task(s) in its merge queue.", filepath.Base(repoPath), ahead),
+		})
+	}
+	return r.rebaseAndMergeOne(ctx, taskID, repoPath, worktreePath, branchName, sessionID, bgCtx, progress, commitHashes, baseHashes, snapshotDiffs)
+}
+
 // rebaseAndMergeOne handles the rebase+merge pipeline for a single repo/worktree pair.
-// Extracted so the caller can hold/release the per-repo lock cleanly.
+// Extracted so rebaseAndMergeQueued can hold/release the per-repo lock cleanly.
 func (r *Runner) rebaseAndMergeOne(
 	ctx context.Context,
 	taskID uuid.UUID,
 	repoPath, worktreePath, branchName, sessionID string,
 	bgCtx context.Context, //nolint:revive // bgCtx is a separate long-lived context, not a replacement for ctx
+	progress repoProgress,
 	commitHashes, baseHashes, snapshotDiffs map[string]string,
 ) error {
 	if !gitutil.IsGitRepo(repoPath) || !gitutil.HasCommits(repoPath) {
 		// Non-git workspace or empty git repo (no commits): the worktree was
 		// set up as a snapshot — copy changes back to the original directory.
-		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
-
-			"result": fmt.Sprintf("Extracting changes from sandbox to %s...", filepath.Base(repoPath)),
+		r.pipelineProgress(taskID, store.PipelineProgressData{
+			Phase:   store.PipelinePhaseMerge,
+			Repo:    repoPath,
+			Percent: progress.percent(store.PipelinePhaseMerge, 0),
+			Message: fmt.Sprintf("Extracting changes from sandbox to %s...", filepath.Base(repoPath)),
 		})
 
 		// Capture the diff before extraction so we can show it in the UI.
@@ -710,15 +1002,23 @@ func (r *Runner) rebaseAndMergeOne(
 			snapshotDiffs[repoPath] = diff
 		}
 
-		if err := extractSnapshotToWorkspace(worktreePath, repoPath); err != nil {
+		manifest, err := extractSnapshotToWorkspace(ctx, worktreePath, repoPath, r.snapshotIgnore())
+		if err != nil {
 			return fmt.Errorf("extract snapshot for %s: %w", repoPath, err)
 		}
 		if hash, err := gitutil.GetCommitHash(worktreePath); err == nil {
 			commitHashes[repoPath] = hash
 		}
-		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
-
-			"result": fmt.Sprintf("Changes extracted to %s.", filepath.Base(repoPath)),
+		// The manifest rides on the event so the timeline records exactly
+		// which workspace files the extraction touched.
+		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+			"result": fmt.Sprintf("Changes extracted to %s: %d copied, %d overwritten, %d deleted.",
+				filepath.Base(repoPath), len(manifest.Copied), len(manifest.Overwritten), len(manifest.Deleted)),
+			"phase":       "snapshot_extract",
+			"repo":        repoPath,
+			"copied":      manifest.Copied,
+			"overwritten": manifest.Overwritten,
+			"deleted":     manifest.Deleted,
 		})
 		return nil
 	}
@@ -752,9 +1052,12 @@ func (r *Runner) rebaseAndMergeOne(
 	// Rebase with conflict-resolution retry loop.
 	var rebaseErr error
 	for attempt := 1; attempt <= constants.MaxRebaseRetries; attempt++ {
-		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
-
-			"result": fmt.Sprintf("Rebasing %s onto %s (attempt %d/%d)...", repoPath, defBranch, attempt, constants.MaxRebaseRetries),
+		r.pipelineProgress(taskID, store.PipelineProgressData{
+			Phase:   store.PipelinePhaseRebase,
+			Repo:    repoPath,
+			Attempt: attempt,
+			Percent: progress.percent(store.PipelinePhaseRebase, attempt),
+			Message: fmt.Sprintf("Rebasing %s onto %s (attempt %d/%d)...", repoPath, defBranch, attempt, constants.MaxRebaseRetries),
 		})
 
 		rebaseErr = gitutil.RebaseOntoDefault(repoPath, worktreePath)
@@ -787,19 +1090,24 @@ func (r *Runner) rebaseAndMergeOne(
 
 		logger.Runner.Warn("rebase conflict, invoking resolver",
 			"task", taskID, "repo", repoPath, "attempt", attempt)
-		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
-
-			"result": fmt.Sprintf("Conflict in %s — running resolver (attempt %d)...", repoPath, attempt),
+		r.pipelineProgress(taskID, store.PipelineProgressData{
+			Phase:   store.PipelinePhaseResolve,
+			Repo:    repoPath,
+			Attempt: attempt,
+			Percent: progress.percent(store.PipelinePhaseResolve, attempt),
+			Message: fmt.Sprintf("Conflict in %s — running resolver (attempt %d)...", repoPath, attempt),
 		})
 
-		if resolveErr := r.resolveConflicts(ctx, taskID, repoPath, worktreePath, sessionID, defBranch, ConflictResolverTriggerCommit, attempt, constants.MaxRebaseRetries); resolveErr != nil {
+		if resolveErr := r.resolveConflicts(ctx, taskID, repoPath, worktreePath, sessionID, defBranch, conflictedFiles(rebaseErr), ConflictResolverTriggerCommit, attempt, constants.MaxRebaseRetries); resolveErr != nil {
 			return fmt.Errorf("conflict resolution failed: %w", resolveErr)
 		}
 	}
 
-	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
-
-		"result": fmt.Sprintf("Fast-forward merging %s into %s...", branchName, defBranch),
+	r.pipelineProgress(taskID, store.PipelineProgressData{
+		Phase:   store.PipelinePhaseMerge,
+		Repo:    repoPath,
+		Percent: progress.percent(store.PipelinePhaseMerge, 0),
+		Message: fmt.Sprintf("Fast-forward merging %s into %s...", branchName, defBranch),
 	})
 	if err := gitutil.FFMerge(repoPath, branchName); err != nil {
 		return fmt.Errorf("ff-merge %s: %w", repoPath, err)
@@ -819,21 +1127,30 @@ func (r *Runner) rebaseAndMergeOne(
 	return nil
 }
 
-// isConflictError reports whether err wraps ErrConflict.
+// isConflictError reports whether err wraps ErrMergeConflict.
 func isConflictError(err error) bool {
-	return errors.Is(err, gitutil.ErrConflict)
+	return errors.Is(err, gitutil.ErrMergeConflict)
 }
 
-// resolveConflicts runs a Claude container session to resolve rebase conflicts.
-// The rebase has already been aborted by RebaseOntoDefault, so the worktree is
-// on the task branch in a clean state. The agent must start the rebase itself,
-// resolve any conflicts, and complete the rebase with `git rebase --continue`.
+// resolveConflicts runs an agent session to resolve rebase conflicts. The
+// rebase has already been aborted by RebaseOntoDefault, so the worktree is
+// on the task branch in a clean state. The agent must start the rebase
+// itself, resolve any conflicts, and complete the rebase with
+// `git rebase --continue`. conflicted lists the files git reported
+// conflicted; on success each one's resolution is recorded in a
+// conflict_resolved event.
+//
+// The resolver runs on its own profile when the env file configures one
+// (WALLFACER_SANDBOX_CONFLICT_RESOLVER, CLAUDE_CONFLICT_RESOLVER_MODEL,
+// CODEX_CONFLICT_RESOLVER_MODEL), and refuses to start once its spend on the
+// task reaches WALLFACER_CONFLICT_RESOLVER_MAX_COST_USD.
 func (r *Runner) resolveConflicts(
 	ctx context.Context,
 	taskID uuid.UUID,
 	repoPath, worktreePath string,
 	sessionID string,
 	defBranch string,
+	conflicted []string,
 	trigger ConflictResolverTrigger,
 	attempt int,
 	maxAttempts int,
@@ -842,6 +1159,37 @@ func (r *Runner) resolveConflicts(
 	containerPath := "/workspace/" + basename
 	repoName := filepath.Base(repoPath)
 
+	task, getErr := r.taskStore(taskID).GetTask(r.shutdownCtx, taskID)
+	if getErr != nil {
+		logger.Runner.Warn("resolveConflictWithContainer: GetTask failed", "task", taskID, "error", getErr)
+	}
+	turns := 0
+	if task != nil {
+		turns = task.Turns + 1
+	}
+
+	if limit := r.conflictResolverMaxCostFromEnv(); limit > 0 && task != nil {
+		if spent := task.UsageBreakdown[activityConflictResolver].CostUSD; spent >= limit {
+			_ = r.taskStore(taskID).InsertEvent(r.shutdownCtx, taskID, store.EventTypeError, map[string]any{
+				"phase":        "conflict_resolver",
+				"status":       "budget_exceeded",
+				"trigger":      string(trigger),
+				"repo":         repoName,
+				"attempt":      attempt,
+				"max_attempts": maxAttempts,
+				"error":        fmt.Sprintf("Conflict resolver budget exhausted for %s: $%.2f spent of $%.2f.", repoName, spent, limit),
+			})
+			return fmt.Errorf("conflict resolver budget exhausted: $%.2f spent of $%.2f", spent, limit)
+		}
+	}
+
+	sb := r.sandboxForTaskActivity(task, activityConflictResolver)
+	model := r.conflictResolverModelFromEnvForSandbox(sb)
+	// A session can only be resumed by the harness that started it.
+	if task != nil && sb != r.sandboxForTask(task) {
+		sessionID = ""
+	}
+
 	prompt := r.promptsMgr.ConflictResolution(prompts.ConflictData{
 		ContainerPath: containerPath,
 		DefaultBranch: defBranch,
@@ -855,25 +1203,24 @@ func (r *Runner) resolveConflicts(
 		"repo":         repoName,
 		"attempt":      attempt,
 		"max_attempts": maxAttempts,
+		"harness":      string(sb),
+		"model":        model,
 		"result":       fmt.Sprintf("Conflict resolver started for %s (%s, attempt %d/%d).", repoName, trigger, attempt, maxAttempts),
 	})
 
 	// Mount only the conflicted worktree for this targeted fix.
 	override := map[string]string{repoPath: worktreePath}
+	sides := readConflictSides(worktreePath, defBranch)
 
-	output, rawStdout, rawStderr, err := r.runContainer(ctx, taskID, prompt, sessionID, override, "", nil, "", activityCommitMessage)
-
-	task, getErr := r.taskStore(taskID).GetTask(r.shutdownCtx, taskID)
-	if getErr != nil {
-		logger.Runner.Warn("resolveConflictWithContainer: GetTask failed", "task", taskID, "error", getErr)
-	}
-	turns := 0
-	if task != nil {
-		turns = task.Turns + 1
+	turnOut := r.taskStore(taskID).OpenTurnOutput(taskID, turns)
+	output, _, _, err := r.runContainer(ctx, taskID, prompt, sessionID, override, "", nil, model, activityConflictResolver, turnOut)
+	_ = turnOut.Close()
+	r.saveTurnExit(taskID, turns)
+	if output != nil {
+		r.accumulateAgentUsage(taskID, activityConflictResolver, turns, output)
 	}
-	_ = r.taskStore(taskID).SaveTurnOutput(taskID, turns, rawStdout, rawStderr)
 
-	if len(rawStderr) > 0 {
+	if turnOut.HasStderr() {
 		stderrFile := fmt.Sprintf("turn-%04d.stderr.txt", turns)
 		_ = r.taskStore(taskID).InsertEvent(ctx, taskID, store.EventTypeSystem, map[string]string{
 
@@ -920,5 +1267,27 @@ func (r *Runner) resolveConflicts(
 		"max_attempts": maxAttempts,
 		"result":       "Conflict resolver: " + truncate(output.Result, 500),
 	})
+	_ = r.taskStore(taskID).InsertEvent(r.shutdownCtx, taskID, store.EventTypeConflictResolved, store.ConflictResolvedData{
+		Repo:    repoName,
+		Trigger: string(trigger),
+		Attempt: attempt,
+		Harness: string(cmp.Or(output.ActualSandbox, sb)),
+		Model:   cmp.Or(output.ObservedModel, model),
+		CostUSD: output.TotalCostUSD,
+		Files:   conflictResolutions(worktreePath, sides, conflicted),
+	})
 	return nil
 }
+
+// conflictResolverMaxCostFromEnv reads WALLFACER_CONFLICT_RESOLVER_MAX_COST_USD,
+// returning 0 (unlimited) when it is unset or the env file is absent.
+func (r *Runner) conflictResolverMaxCostFromEnv() float64 {
+	if r.envFile == "" {
+		return 0
+	}
+	cfg, err := envconfig.Parse(r.envFile)
+	if err != nil {
+		return 0
+	}
+	return cfg.ConflictResolverMaxCostUSD
+}
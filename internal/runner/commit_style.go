@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/pkg/commitstyle"
+)
+
+// commitStyleSampleSize is the number of recent subjects a commit-style
+// profile is built from.
+const commitStyleSampleSize = 30
+
+// commitStyle returns the commit-style profile of the repository at
+// repoPath. A profile cached in the task's store is reused until it is
+// older than constants.CommitStyleTTL; after that the repository's recent
+// history is sampled again and the cache refreshed. When sampling fails the
+// stale profile, or the zero profile, is returned.
+func (r *Runner) commitStyle(ctx context.Context, taskID uuid.UUID, repoPath string) commitstyle.Profile {
+	s := r.taskStore(taskID)
+	var cached commitstyle.Profile
+	if s != nil {
+		var ok bool
+		if cached, ok = s.CommitStyle(repoPath); ok && time.Since(cached.RefreshedAt) < constants.CommitStyleTTL {
+			return cached
+		}
+	}
+	out, err := cmdexec.Git(repoPath, "log", "--format=%s", "-n", strconv.Itoa(commitStyleSampleSize)).WithContext(ctx).Output()
+	if err != nil {
+		logger.Runner.Warn("commit style: read history", "task", taskID, "repo", repoPath, "error", err)
+		return cached
+	}
+	p := commitstyle.Analyze(strings.Split(out, "\n"))
+	p.RefreshedAt = time.Now().UTC()
+	if s != nil {
+		if err := s.SaveCommitStyle(repoPath, p); err != nil {
+			logger.Runner.Warn("commit style: save", "task", taskID, "repo", repoPath, "error", err)
+		}
+	}
+	return p
+}
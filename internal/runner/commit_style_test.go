@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCommitStyle_CachesProfile(t *testing.T) {
+	repo := setupTestRepo(t)
+	s, r := setupTestRunner(t, []string{repo})
+	ctx := context.Background()
+	taskID := uuid.New()
+	gitRun(t, repo, "commit", "--allow-empty", "-m", "feat(api): add search")
+	gitRun(t, repo, "commit", "--allow-empty", "-m", "fix(api): handle empty query")
+
+	p := r.commitStyle(ctx, taskID, repo)
+	if !p.Conventional || p.RefreshedAt.IsZero() {
+		t.Fatalf("profile = %+v, want a fresh conventional profile", p)
+	}
+	if cached, ok := s.CommitStyle(repo); !ok || !cached.RefreshedAt.Equal(p.RefreshedAt) {
+		t.Fatalf("profile not cached in the store: %+v, %v", cached, ok)
+	}
+
+	// A fresh profile is reused without reading the history again.
+	gitRun(t, repo, "commit", "--allow-empty", "-m", "Plain subject one")
+	gitRun(t, repo, "commit", "--allow-empty", "-m", "Plain subject two")
+	gitRun(t, repo, "commit", "--allow-empty", "-m", "Plain subject three")
+	if again := r.commitStyle(ctx, taskID, repo); !again.RefreshedAt.Equal(p.RefreshedAt) {
+		t.Errorf("fresh profile was rebuilt")
+	}
+
+	// A stale profile is rebuilt from the current history.
+	p.RefreshedAt = time.Now().Add(-25 * time.Hour)
+	if err := s.SaveCommitStyle(repo, p); err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt := r.commitStyle(ctx, taskID, repo); rebuilt.Conventional || rebuilt.Examples[0] != "Plain subject three" {
+		t.Errorf("stale profile not rebuilt: %+v", rebuilt)
+	}
+}
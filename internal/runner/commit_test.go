@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -67,7 +68,7 @@ func TestGenerateCommitMessageSuccess(t *testing.T) {
 	cmd := fakeCmdScript(t, validStreamJSON, 0)
 	runner := runnerWithCmd(t, cmd)
 
-	msg, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Add authentication", "auth.go | 50 ++++", "")
+	msg, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Add authentication", "auth.go | 50 ++++", "", "")
 	if err != nil {
 		t.Fatalf("generateCommitMessage error: %v", err)
 	}
@@ -81,7 +82,7 @@ func TestGenerateCommitMessageSuccess(t *testing.T) {
 func TestGenerateCommitMessageErrorsOnInvalidOutput(t *testing.T) {
 	runner := runnerWithCmd(t, "echo") // outputs its args, not valid JSON
 
-	_, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Fix the login bug", "login.go | 3 +-", "")
+	_, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Fix the login bug", "login.go | 3 +-", "", "")
 	if err == nil {
 		t.Fatal("expected error for invalid commit message output")
 	}
@@ -94,7 +95,7 @@ func TestGenerateCommitMessageErrorsOnCommandError(t *testing.T) {
 	cmd := fakeCmdScript(t, "", 1) // exits 1 with empty output
 	runner := runnerWithCmd(t, cmd)
 
-	_, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Refactor database layer", "db/*.go | 120 ++--", "")
+	_, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Refactor database layer", "db/*.go | 120 ++--", "", "")
 	if err == nil {
 		t.Fatal("expected error for failed commit message command")
 	}
@@ -108,7 +109,7 @@ func TestGenerateCommitMessageErrorsOnBlankResult(t *testing.T) {
 	cmd := fakeCmdScript(t, blankResult, 0)
 	runner := runnerWithCmd(t, cmd)
 
-	_, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Update configuration", "config.go | 5 +-", "")
+	_, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Update configuration", "config.go | 5 +-", "", "")
 	if err == nil {
 		t.Fatal("expected error for blank commit message result")
 	}
@@ -122,7 +123,7 @@ func TestGenerateCommitMessageMultiline(t *testing.T) {
 	cmd := fakeCmdScript(t, multilineResult, 0)
 	runner := runnerWithCmd(t, cmd)
 
-	msg, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Add auth", "auth.go | 80 ++++", "")
+	msg, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Add auth", "auth.go | 80 ++++", "", "")
 	if err != nil {
 		t.Fatalf("generateCommitMessage error: %v", err)
 	}
@@ -145,7 +146,7 @@ func TestGenerateCommitMessageNDJSON(t *testing.T) {
 	cmd := fakeCmdScript(t, ndjson, 0)
 	runner := runnerWithCmd(t, cmd)
 
-	msg, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Fix crash", "main.go | 2 +-", "")
+	msg, err := runner.generateCommitMessage(context.Background(), uuid.New(), "Fix crash", "main.go | 2 +-", "", "")
 	if err != nil {
 		t.Fatalf("generateCommitMessage error: %v", err)
 	}
@@ -178,7 +179,7 @@ func TestGenerateCommitMessageStripsPreambleAndFence(t *testing.T) {
 	cmd := fakeCmdScript(t, string(payload), 0)
 	runner := runnerWithCmd(t, cmd)
 
-	msg, err := runner.generateCommitMessage(context.Background(), uuid.New(), "add new-chat button", "frontend/x.vue | 10 ++--", "")
+	msg, err := runner.generateCommitMessage(context.Background(), uuid.New(), "add new-chat button", "frontend/x.vue | 10 ++--", "", "")
 	if err != nil {
 		t.Fatalf("generateCommitMessage error: %v", err)
 	}
@@ -578,7 +579,7 @@ func TestGenerateCommitMessageRespectsCallerContext(t *testing.T) {
 	}
 	done := make(chan result, 1)
 	go func() {
-		msg, err := runner.generateCommitMessage(ctx, taskID, "Add feature", "feature.go | 10 ++++", "")
+		msg, err := runner.generateCommitMessage(ctx, taskID, "Add feature", "feature.go | 10 ++++", "", "")
 		done <- result{msg, err}
 	}()
 
@@ -1027,3 +1028,152 @@ func TestMaybeAutoPushWorkspace_BelowThresholdIsNoOp(t *testing.T) {
 		t.Errorf("expected no push below threshold; origin log:\n%s", log)
 	}
 }
+
+// TestCommit_SkipCommitLeavesWorktree verifies that a SkipCommit task's
+// changes are neither committed nor merged, and that its worktree survives.
+func TestCommit_SkipCommitLeavesWorktree(t *testing.T) {
+	repo := setupTestRepo(t)
+	_, r := setupTestRunner(t, []string{repo})
+	s := r.currentStore()
+	ctx := context.Background()
+
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "explore", Timeout: 5, SkipCommit: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktreePaths, branchName, err := r.setupWorktrees(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskWorktrees(ctx, task.ID, worktreePaths, branchName); err != nil {
+		t.Fatal(err)
+	}
+	wt := worktreePaths[repo]
+	if err := os.WriteFile(filepath.Join(wt, "scratch.txt"), []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	head := gitRun(t, repo, "rev-parse", "main")
+	branchHead := gitRun(t, wt, "rev-parse", "HEAD")
+
+	if err := r.Commit(task.ID, ""); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if got := gitRun(t, repo, "rev-parse", "main"); got != head {
+		t.Errorf("changes were merged: main moved from %s to %s", head, got)
+	}
+	if got := gitRun(t, wt, "rev-parse", "HEAD"); got != branchHead {
+		t.Errorf("changes were committed on the task branch: %s → %s", branchHead, got)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "scratch.txt")); err != nil {
+		t.Errorf("change not left in worktree: %v", err)
+	}
+	if !hasEventContaining(t, s, task.ID, "changes left in worktree "+wt) {
+		t.Error("missing changes-left-in-worktree event")
+	}
+}
+
+// TestCommit_RequireApprovalHoldsMerge verifies that a RequireApproval task
+// stops after staging with ErrAwaitingApproval, leaving the default branch
+// alone, and merges once approved.
+func TestCommit_RequireApprovalHoldsMerge(t *testing.T) {
+	repo := setupTestRepo(t)
+	mainBefore := strings.TrimSpace(gitRun(t, repo, "rev-parse", "main"))
+
+	cmd := fakeCmdScript(t, validStreamJSON, 0)
+	s, err := storetest.NewFileStore(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	resolved := resolveTestCmd(cmd)
+	r := NewRunner(s, RunnerConfig{
+		Command:          cmd,
+		Workspaces:       []string{repo},
+		WorktreesDir:     t.TempDir(),
+		HostClaudeBinary: resolved,
+		HostCodexBinary:  resolved,
+	})
+	t.Cleanup(func() { r.Shutdown() })
+
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "add feature", Timeout: 5, RequireApproval: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktreePaths, branchName, err := r.setupWorktrees(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskWorktrees(ctx, task.ID, worktreePaths, branchName); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePaths[repo], "feature.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Commit(task.ID, "sess1"); !errors.Is(err, ErrAwaitingApproval) {
+		t.Fatalf("Commit = %v, want ErrAwaitingApproval", err)
+	}
+	if got := strings.TrimSpace(gitRun(t, repo, "rev-parse", "main")); got != mainBefore {
+		t.Fatalf("main moved to %s before approval", got)
+	}
+	if ahead := strings.TrimSpace(gitRun(t, worktreePaths[repo], "rev-list", "--count", "main..HEAD")); ahead != "1" {
+		t.Fatalf("task branch is %s commits ahead, want the staged commit", ahead)
+	}
+
+	if err := s.ApproveTaskMerge(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit(task.ID, "sess1"); err != nil {
+		t.Fatalf("Commit after approval: %v", err)
+	}
+	if got := strings.TrimSpace(gitRun(t, repo, "rev-parse", "main")); got == mainBefore {
+		t.Error("main was not merged after approval")
+	}
+}
+
+// TestCommit_NoChangesSkipsPipeline verifies that a task whose worktree is
+// clean and not ahead of the default branch finishes without generating a
+// commit message, merging, or waiting for approval, and that its worktree
+// is still cleaned up.
+func TestCommit_NoChangesSkipsPipeline(t *testing.T) {
+	repo := setupTestRepo(t)
+	_, r := setupTestRunner(t, []string{repo})
+	s := r.currentStore()
+	ctx := context.Background()
+
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "explain the code", Timeout: 5, RequireApproval: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktreePaths, branchName, err := r.setupWorktrees(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskWorktrees(ctx, task.ID, worktreePaths, branchName); err != nil {
+		t.Fatal(err)
+	}
+	head := strings.TrimSpace(gitRun(t, repo, "rev-parse", "main"))
+
+	if err := r.Commit(task.ID, ""); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if got := strings.TrimSpace(gitRun(t, repo, "rev-parse", "main")); got != head {
+		t.Errorf("main moved from %s to %s", head, got)
+	}
+	if _, err := os.Stat(worktreePaths[repo]); !os.IsNotExist(err) {
+		t.Errorf("worktree not cleaned up: %v", err)
+	}
+	if !hasEventContaining(t, s, task.ID, "No changes produced") {
+		t.Error("missing no-changes event")
+	}
+	got, err := s.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BaseCommitHashes[repo] != head {
+		t.Errorf("base hash = %q, want %q", got.BaseCommitHashes[repo], head)
+	}
+}
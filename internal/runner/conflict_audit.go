@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// conflictDiffMaxBytes bounds the diff recorded for each file in a
+// conflict_resolved event.
+const conflictDiffMaxBytes = 4 << 10
+
+// conflictedFiles returns the files a rebase conflict error names, or nil.
+func conflictedFiles(err error) []string {
+	var ce *gitutil.ConflictError
+	if errors.As(err, &ce) {
+		return ce.ConflictedFiles
+	}
+	return nil
+}
+
+// conflictSides holds the two commits a rebase conflict is between, read
+// before the resolver rewrites the task branch.
+type conflictSides struct {
+	task     string // the task branch tip
+	upstream string // the commit the branch is rebased onto
+}
+
+// readConflictSides resolves the task branch tip of worktreePath and the
+// commit onto names. Either is empty when git cannot resolve it.
+func readConflictSides(worktreePath, onto string) conflictSides {
+	rev := func(name string) string {
+		out, err := cmdexec.Git(worktreePath, "rev-parse", "--verify", "--quiet", name+"^{commit}").Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(out)
+	}
+	return conflictSides{task: rev("HEAD"), upstream: rev(onto)}
+}
+
+// conflictResolutions classifies how the resolver left each of files,
+// comparing the rebased branch's version of it with the two sides. When
+// files is empty, as when git's output named none, it uses the files both
+// sides changed since they diverged.
+func conflictResolutions(worktreePath string, sides conflictSides, files []string) []store.ConflictFileResolution {
+	if sides.task == "" || sides.upstream == "" {
+		return nil
+	}
+	if len(files) == 0 {
+		files = filesChangedOnBothSides(worktreePath, sides)
+	}
+	blob := func(rev, path string) string {
+		out, err := cmdexec.Git(worktreePath, "rev-parse", "--verify", "--quiet", rev+":"+path).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(out)
+	}
+	out := make([]store.ConflictFileResolution, 0, len(files))
+	for _, path := range files {
+		res := store.ConflictFileResolution{Path: path}
+		resolved := blob("HEAD", path)
+		switch {
+		case resolved == "":
+			res.Resolution = store.ConflictResolutionDeleted
+		case resolved == blob(sides.task, path):
+			res.Resolution = store.ConflictResolutionTask
+		case resolved == blob(sides.upstream, path):
+			res.Resolution = store.ConflictResolutionUpstream
+		default:
+			res.Resolution = store.ConflictResolutionMerged
+		}
+		if res.Resolution != store.ConflictResolutionUpstream {
+			if diff, err := cmdexec.Git(worktreePath, "diff", "--no-color", sides.upstream, "HEAD", "--", path).Output(); err == nil {
+				res.Diff = truncate(diff, conflictDiffMaxBytes)
+			}
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+// filesChangedOnBothSides lists the files the task branch and upstream
+// both changed since their merge base, the candidates for a conflict.
+func filesChangedOnBothSides(worktreePath string, sides conflictSides) []string {
+	base, err := cmdexec.Git(worktreePath, "merge-base", sides.task, sides.upstream).Output()
+	if err != nil {
+		return nil
+	}
+	changed := func(rev string) []string {
+		out, err := cmdexec.Git(worktreePath, "diff", "--name-only", strings.TrimSpace(base), rev).Output()
+		if err != nil || strings.TrimSpace(out) == "" {
+			return nil
+		}
+		return strings.Split(strings.TrimSpace(out), "\n")
+	}
+	upstream := changed(sides.upstream)
+	var both []string
+	for _, f := range changed(sides.task) {
+		if slices.Contains(upstream, f) {
+			both = append(both, f)
+		}
+	}
+	return both
+}
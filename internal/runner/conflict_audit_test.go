@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestConflictResolutions(t *testing.T) {
+	repo := setupTestRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"keep.txt", "take.txt", "mix.txt", "drop.txt"} {
+		write(f, "base\n")
+	}
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-m", "base")
+
+	gitRun(t, repo, "checkout", "-b", "task")
+	for _, f := range []string{"keep.txt", "take.txt", "mix.txt", "drop.txt"} {
+		write(f, "task\n")
+	}
+	gitRun(t, repo, "commit", "-am", "task side")
+	gitRun(t, repo, "checkout", "main")
+	for _, f := range []string{"keep.txt", "take.txt", "mix.txt", "drop.txt"} {
+		write(f, "upstream\n")
+	}
+	gitRun(t, repo, "commit", "-am", "upstream side")
+
+	gitRun(t, repo, "checkout", "task")
+	sides := readConflictSides(repo, "main")
+	if sides.task == "" || sides.upstream == "" {
+		t.Fatalf("sides = %+v", sides)
+	}
+
+	// Stand in for the resolver: rebuild the branch on main with one file
+	// settled each way.
+	gitRun(t, repo, "reset", "--hard", "main")
+	write("keep.txt", "task\n")
+	write("mix.txt", "upstream\ntask\n")
+	gitRun(t, repo, "rm", "-q", "drop.txt")
+	gitRun(t, repo, "commit", "-qam", "task side, resolved")
+
+	got := conflictResolutions(repo, sides, nil)
+	want := map[string]store.ConflictResolution{
+		"keep.txt": store.ConflictResolutionTask,
+		"take.txt": store.ConflictResolutionUpstream,
+		"mix.txt":  store.ConflictResolutionMerged,
+		"drop.txt": store.ConflictResolutionDeleted,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("resolutions = %+v, want one per file changed on both sides", got)
+	}
+	for _, res := range got {
+		if res.Resolution != want[res.Path] {
+			t.Errorf("%s resolved as %q, want %q", res.Path, res.Resolution, want[res.Path])
+		}
+		if hasDiff := res.Diff != ""; hasDiff == (res.Resolution == store.ConflictResolutionUpstream) {
+			t.Errorf("%s diff = %q", res.Path, res.Diff)
+		}
+	}
+
+	if got := conflictResolutions(repo, sides, []string{"mix.txt"}); len(got) != 1 || !strings.Contains(got[0].Diff, "+task") {
+		t.Errorf("named file resolutions = %+v", got)
+	}
+}
+
+func TestResolveConflicts_StopsAtBudget(t *testing.T) {
+	s, r := setupRunnerWithCmd(t, nil, fakeCmdScript(t, endTurnOutput, 0))
+	r.envFile = filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(r.envFile, []byte("WALLFACER_CONFLICT_RESOLVER_MAX_COST_USD=0.5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "budget", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.resolveConflicts(ctx, task.ID, t.TempDir(), t.TempDir(), "", "main", nil, ConflictResolverTriggerCommit, 1, 3); err != nil {
+		t.Fatalf("under budget: %v", err)
+	}
+	got, _ := s.GetTask(ctx, task.ID)
+	if cost := got.UsageBreakdown[store.SandboxActivityConflictResolver].CostUSD; cost != 0.001 {
+		t.Errorf("resolver usage = %v, want the run's 0.001", cost)
+	}
+
+	_ = s.AccumulateSubAgentUsage(ctx, task.ID, store.SandboxActivityConflictResolver, store.TaskUsage{CostUSD: 0.5})
+	err = r.resolveConflicts(ctx, task.ID, t.TempDir(), t.TempDir(), "", "main", nil, ConflictResolverTriggerCommit, 2, 3)
+	if err == nil || !strings.Contains(err.Error(), "budget exhausted") {
+		t.Fatalf("over budget err = %v", err)
+	}
+	if got, _ := s.GetTask(ctx, task.ID); got.UsageBreakdown[store.SandboxActivityConflictResolver].CostUSD != 0.501 {
+		t.Error("the resolver ran after exhausting its budget")
+	}
+}
+
+func TestSandboxForTaskActivity_ConflictResolver(t *testing.T) {
+	_, r := setupRunnerWithCmd(t, nil, "echo")
+	r.envFile = filepath.Join(t.TempDir(), ".env")
+	task := &store.Task{Sandbox: harness.Claude}
+
+	if err := os.WriteFile(r.envFile, []byte("WALLFACER_SANDBOX_IMPLEMENTATION=codex\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.sandboxForTaskActivity(task, activityConflictResolver); got != harness.Claude {
+		t.Errorf("unconfigured resolver runs on %q, want the implementation's claude", got)
+	}
+
+	env := "WALLFACER_SANDBOX_CONFLICT_RESOLVER=codex\nCODEX_CONFLICT_RESOLVER_MODEL=gpt-5-mini\n"
+	if err := os.WriteFile(r.envFile, []byte(env), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.sandboxForTaskActivity(task, activityConflictResolver); got != harness.Codex {
+		t.Errorf("configured resolver runs on %q, want codex", got)
+	}
+	if got := r.conflictResolverModelFromEnvForSandbox(harness.Codex); got != "gpt-5-mini" {
+		t.Errorf("codex resolver model = %q", got)
+	}
+	if got := r.conflictResolverModelFromEnvForSandbox(harness.Claude); got != "" {
+		t.Errorf("claude resolver model = %q, want the default", got)
+	}
+}
@@ -9,4 +9,7 @@ type ConflictResolverTrigger string
 const (
 	ConflictResolverTriggerSync   ConflictResolverTrigger = "sync"
 	ConflictResolverTriggerCommit ConflictResolverTrigger = "commit"
+	// ConflictResolverTriggerIncremental marks resolutions run by the
+	// step-wise rebase (IncrementalRebase), one upstream checkpoint at a time.
+	ConflictResolverTriggerIncremental ConflictResolverTrigger = "incremental_rebase"
 )
@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -52,6 +53,8 @@ const (
 	activityTitle          = store.SandboxActivityTitle
 	activityOversight      = store.SandboxActivityOversight
 	activityCommitMessage  = store.SandboxActivityCommitMessage
+
+	activityConflictResolver = store.SandboxActivityConflictResolver
 )
 
 // buildContainerSpecForSandbox constructs a ContainerSpec for the given sandbox
@@ -96,7 +99,7 @@ func (r *Runner) buildContainerSpecForSandbox(
 func (r *Runner) buildHostSpec(
 	spec executor.ContainerSpec,
 	prompt, model, sessionID string,
-	_ harness.ID,
+	sb harness.ID,
 	worktreeOverrides map[string]string,
 	boardDir string,
 	siblingMounts map[string]map[string]string,
@@ -118,6 +121,15 @@ func (r *Runner) buildHostSpec(
 	}
 	spec.WorkDir = workDir
 
+	// claude resolves --resume only among sessions started in the current
+	// directory, and a session adopted from a terminal was started outside
+	// the task worktree.
+	if sessionID != "" && sb == harness.Claude && workDir != "" {
+		if err := harness.LinkClaudeSession(harness.ClaudeConfigDir(), sessionID, workDir); err != nil && !errors.Is(err, harness.ErrClaudeSessionNotFound) {
+			logger.Runner.Warn("host mode: link claude session", "session", sessionID, "error", err)
+		}
+	}
+
 	// Surface board / siblings via env vars.
 	if boardDir != "" {
 		boardPath := filepath.Join(boardDir, "board.json")
@@ -175,6 +187,7 @@ func buildAgentCmd(prompt, model string) []string {
 //   - WALLFACER_AGENT environment variable (claude or codex) so the image
 //     entrypoint dispatches to the correct CLI
 //   - CLAUDE_CODE_MODEL environment variable (when model is non-empty)
+//   - TZ, LANG/LC_ALL, and a configured SOURCE_DATE_EPOCH (see agentEnvironment)
 //   - claude-config named volume for agent configuration persistence
 //   - Codex auth.json bind-mount (when sandbox=="codex" and the file exists)
 //
@@ -225,6 +238,7 @@ func (r *Runner) buildBaseContainerSpec(containerName, model string, sb harness.
 	if model != "" {
 		spec.Env["CLAUDE_CODE_MODEL"] = model
 	}
+	r.agentEnvironment(nil).apply(spec.Env)
 	return spec
 }
 
@@ -240,11 +254,21 @@ func (r *Runner) sandboxForTask(task *store.Task) harness.ID {
 // (harness.Default(), the final fallback). Keying the fallback on Default() rather
 // than a literal harness.Claude is what lets the native default change without
 // touching this resolver.
+//
+// The conflict resolver is the exception: WALLFACER_SANDBOX_CONFLICT_RESOLVER
+// routes it ahead of every tier, and without it the resolver runs wherever
+// the task's implementation does.
 func (r *Runner) sandboxForTaskActivity(task *store.Task, activity store.SandboxActivity) harness.ID {
 	if task == nil {
 		return harness.Default()
 	}
 	activity = store.SandboxActivity(strings.ToLower(strings.TrimSpace(string(activity))))
+	if activity == activityConflictResolver {
+		if sb := r.conflictResolverSandboxFromEnv(); sb != "" {
+			return sb
+		}
+		activity = activityImplementation
+	}
 	if task.SandboxByActivity != nil {
 		if sb, ok := task.SandboxByActivity[activity]; ok && sb.IsValid() {
 			return sb
@@ -350,6 +374,35 @@ func (r *Runner) titleModelFromEnvForSandbox(sb harness.ID) string {
 	}
 }
 
+// conflictResolverSandboxFromEnv reads WALLFACER_SANDBOX_CONFLICT_RESOLVER,
+// returning "" when it is unset or the env file is absent.
+func (r *Runner) conflictResolverSandboxFromEnv() harness.ID {
+	if r.envFile == "" {
+		return ""
+	}
+	cfg, err := envconfig.Parse(r.envFile)
+	if err != nil {
+		return ""
+	}
+	return cfg.ConflictResolverSandbox
+}
+
+// conflictResolverModelFromEnvForSandbox returns the model of the conflict
+// resolver profile for the given sandbox, or "" to use the default model.
+func (r *Runner) conflictResolverModelFromEnvForSandbox(sb harness.ID) string {
+	if r.envFile == "" {
+		return ""
+	}
+	cfg, err := envconfig.Parse(r.envFile)
+	if err != nil {
+		return ""
+	}
+	if sb == harness.Codex {
+		return cfg.CodexConflictResolverModel
+	}
+	return cfg.ConflictResolverModel
+}
+
 // roleImplementation and roleTesting are the heavyweight descriptors the
 // multi-turn agent turn loop in execute.go calls runAgent through. They
 // carry no timeout (the turn loop owns the deadline via ctx), no
@@ -365,8 +418,20 @@ var (
 	roleTesting        = agents.Testing
 )
 
+// roleConflictResolver is the descriptor resolveConflicts launches through
+// runContainer; its binding routes the sandbox by the resolver's activity.
+var roleConflictResolver = agents.ConflictResolver
+
+// liveLogMaxBytes bounds the live-log buffer of a running turn. A client
+// that attaches mid-turn sees at most this much of the turn's output; the
+// full output is in the turn-output file once the turn ends.
+const liveLogMaxBytes = 4 << 20
+
 // runContainer executes an agent container and parses its NDJSON output.
-// Returns (output, rawStdout, rawStderr, error). Wraps runAgent with the
+// Returns (output, rawStdout, rawStderr, error). When out is non-nil the
+// streams are written to it as the agent runs instead, including those of
+// a codex fallback launch after the first attempt's, and the raw return
+// values are nil. Wraps runAgent with the
 // heavyweight-specific concerns: slugged container name, live-log tee,
 // container-runtime circuit breaker, and the per-activity descriptor
 // dispatch. The outer turn loop in execute.go owns session handling
@@ -380,6 +445,7 @@ func (r *Runner) runContainer(
 	siblingMounts map[string]map[string]string,
 	modelOverride string,
 	activity store.SandboxActivity,
+	out *store.TurnOutput,
 ) (*agentOutput, []byte, []byte, error) {
 	slug := slugifyPrompt(prompt, 30)
 	containerName := "wallfacer-" + slug + "-" + taskID.String()[:8]
@@ -390,14 +456,18 @@ func (r *Runner) runContainer(
 	}
 
 	role := roleImplementation
-	if activity == store.SandboxActivityTesting {
+	switch activity {
+	case store.SandboxActivityTesting:
 		role = roleTesting
+	case activityConflictResolver:
+		role = roleConflictResolver
 	}
 
 	// Set up the live-log buffer that StreamLogs attaches to while the
 	// container is running. The tee is wired via LiveLogWriter so
-	// runAgent drains both streams through it.
-	ll := livelog.New()
+	// runAgent drains both streams through it. It keeps only the recent
+	// output, so a verbose turn cannot grow it without bound.
+	ll := livelog.NewBounded(liveLogMaxBytes)
 	r.liveLogs.Store(taskID, ll)
 	defer func() {
 		ll.Close()
@@ -410,28 +480,72 @@ func (r *Runner) runContainer(
 	r.taskContainers.Set(taskID, containerName)
 	defer r.taskContainers.Delete(taskID)
 
-	res, err := r.runAgent(ctx, role, task, prompt, runAgentOpts{
-		ContainerName:     containerName,
-		SessionID:         sessionID,
-		ModelOverride:     modelOverride,
-		WorktreeOverrides: worktreeOverrides,
-		BoardDir:          boardDir,
-		SiblingMounts:     siblingMounts,
-		LiveLogWriter:     ll,
-		CircuitBreaker:    r.containerCB,
-		EmitSpanEvents:    true,
-		// Upgrade the name-only registration to a handle entry so
-		// KillContainer can actually signal the running agent when the
-		// user cancels the task mid-run.
-		OnLaunch: func(_ string, handle executor.Handle) {
-			r.taskContainers.SetHandle(taskID, handle, nil)
-		},
-		// Heavyweight turn invocations rebind the activity bucket
-		// for each turn's usage ledger — implementation or testing.
-		ActivityOverride: activity,
-		// Usage is accounted in the outer turn-loop; runAgent does not
-		// bill heavyweight turns itself because the loop already does.
-	})
+	// Drop exit metadata left by an earlier launch so a turn that fails
+	// before launching is not attributed someone else's exit.
+	r.turnExits.Delete(taskID)
+
+	// An interactive task keeps the agent's stdin open for SendInput and
+	// watches its output for prompts to surface as input_request events.
+	interactive := task != nil && task.InteractiveInput && prompt != ""
+	var promptWatch *inputPromptWatcher
+	if interactive {
+		promptWatch = r.newInputPromptWatcher(taskID)
+		defer r.taskInputs.Delete(taskID)
+	}
+
+	// The stall watchdog flags a turn that goes quiet without timing out
+	// and, when configured, kills it once so it runs again below. An
+	// interactive turn may be quiet because it waits on the user, so it is
+	// only ever flagged.
+	stall := r.newStallWatch(taskID, worktreeOverrides, !interactive)
+	defer stall.close()
+
+	// Tool calls and text are appended to the event log as the agent
+	// streams them, so the timeline shows a long turn's progress.
+	activityLog := r.newActivityRecorder(taskID)
+
+	launch := func() (*agentResult, error) {
+		return r.runAgent(ctx, role, task, prompt, runAgentOpts{
+			ContainerName:     containerName,
+			SessionID:         sessionID,
+			ModelOverride:     modelOverride,
+			WorktreeOverrides: worktreeOverrides,
+			BoardDir:          boardDir,
+			SiblingMounts:     siblingMounts,
+			LiveLogWriter:     taskDeadlineFrom(ctx).liveLogWriter(stall.liveLogWriter(ll)),
+			Output:            out,
+			CircuitBreaker:    r.containerCB,
+			EmitSpanEvents:    true,
+			// Upgrade the name-only registration to a handle entry so
+			// KillContainer can actually signal the running agent when the
+			// user cancels the task mid-run.
+			OnLaunch: func(_ string, handle executor.Handle) {
+				r.taskContainers.SetHandle(taskID, handle, nil)
+				stall.start(handle)
+				if interactive {
+					r.registerInput(taskID, r.sandboxForTaskActivity(task, activity), handle)
+				}
+			},
+			OnExit:      func(info executor.ExitInfo) { r.turnExits.Store(taskID, info) },
+			Interactive: interactive,
+			OnEvent:     chainEventFuncs(promptWatch.eventFunc(), activityLog.event),
+			StderrTap:   promptWatch.writer(),
+			// Heavyweight turn invocations rebind the activity bucket
+			// for each turn's usage ledger — implementation or testing.
+			ActivityOverride: activity,
+			// Usage is accounted in the outer turn-loop; runAgent does not
+			// bill heavyweight turns itself because the loop already does.
+		})
+	}
+	res, err := launch()
+	if stall.restartRequested() && ctx.Err() == nil {
+		logger.Runner.Warn("restarting stalled turn", "task", taskID)
+		_ = r.taskStore(taskID).InsertEvent(ctx, taskID, store.EventTypeSystem, map[string]string{
+			"result": "Stall watchdog: restarting the stalled turn",
+		})
+		res, err = launch()
+	}
+	stall.close()
 	var output *agentOutput
 	var rawStdout, rawStderr []byte
 	if res != nil {
@@ -453,7 +567,7 @@ func (r *Runner) runContainer(
 				"result": "Sandbox fallback: claude → codex (token/rate limit hit)",
 			})
 			return r.runContainerOnSandbox(ctx, role, taskID, task, containerName, prompt, sessionID,
-				modelOverride, worktreeOverrides, boardDir, siblingMounts, ll, harness.Codex)
+				modelOverride, worktreeOverrides, boardDir, siblingMounts, ll, out, harness.Codex)
 		}
 		return nil, rawStdout, rawStderr, err
 	}
@@ -465,7 +579,7 @@ func (r *Runner) runContainer(
 			"result": "Sandbox fallback: claude → codex (token/rate limit in output)",
 		})
 		return r.runContainerOnSandbox(ctx, role, taskID, task, containerName, prompt, sessionID,
-			modelOverride, worktreeOverrides, boardDir, siblingMounts, ll, harness.Codex)
+			modelOverride, worktreeOverrides, boardDir, siblingMounts, ll, out, harness.Codex)
 	}
 
 	return output, rawStdout, rawStderr, nil
@@ -485,6 +599,7 @@ func (r *Runner) runContainerOnSandbox(
 	boardDir string,
 	siblingMounts map[string]map[string]string,
 	ll *livelog.Log,
+	out *store.TurnOutput,
 	sb harness.ID,
 ) (*agentOutput, []byte, []byte, error) {
 	// Override the per-activity sandbox resolution by temporarily
@@ -505,6 +620,7 @@ func (r *Runner) runContainerOnSandbox(
 		BoardDir:          boardDir,
 		SiblingMounts:     siblingMounts,
 		LiveLogWriter:     ll,
+		Output:            out,
 		CircuitBreaker:    r.containerCB,
 		EmitSpanEvents:    true,
 		// Register the fallback launch's handle too, so a cancel during
@@ -512,6 +628,8 @@ func (r *Runner) runContainerOnSandbox(
 		OnLaunch: func(_ string, handle executor.Handle) {
 			r.taskContainers.SetHandle(taskID, handle, nil)
 		},
+		OnExit:  func(info executor.ExitInfo) { r.turnExits.Store(taskID, info) },
+		OnEvent: r.newActivityRecorder(taskID).event,
 	})
 	if res == nil {
 		return nil, nil, nil, err
@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// maxContextPackFiles caps the files a context pack expands to, so a
+// directory entry naming a large tree does not stall the task start.
+const maxContextPackFiles = 200
+
+// outlineHeadLines is the number of leading lines an outline keeps before
+// it switches to declaration lines only.
+const outlineHeadLines = 15
+
+// maxOutlineLineBytes truncates long lines in an outline.
+const maxOutlineLineBytes = 160
+
+// outlineDeclRe matches the lines an outline keeps past its head: function,
+// type, and top-level variable declarations in common languages, plus
+// Markdown headings.
+var outlineDeclRe = regexp.MustCompile(`^(\s*(func|function|def|async def|class|interface|struct|enum|impl|trait|fn|pub(\([a-z]+\))? (fn|struct|enum|trait|mod|type))\b|(type|const|var|let|export|package|module|#{1,6})\s)`)
+
+// contextPackPrompt appends the contents of the task's context files to a
+// fresh prompt, read from the task's worktrees. Test runs, empty
+// (auto-continue) prompts, and tasks without context files are returned
+// unchanged.
+func (r *Runner) contextPackPrompt(task *store.Task, prompt string, worktreePaths map[string]string) string {
+	if len(task.ContextFiles) == 0 || task.IsTestRun || prompt == "" {
+		return prompt
+	}
+	budget := constants.ContextPackTokenBudget * constants.ContextPackBytesPerToken
+	files, omitted := buildContextPack(task.ContextFiles, worktreePaths, budget)
+	if len(files) == 0 && len(omitted) == 0 {
+		return prompt
+	}
+	return r.promptsMgr.ContextPack(prompts.ContextPackData{Prompt: prompt, Files: files, Omitted: omitted})
+}
+
+// buildContextPack expands paths (files or directories in the workspaces)
+// to the files under them in the task's worktrees and renders them within
+// budget bytes. A file is included whole when it fits both the remaining
+// budget and a quarter of the total, so one large file cannot crowd out
+// the rest; otherwise it is summarized as an outline. Binary and
+// unreadable files, and files whose outline does not fit either, are
+// returned in omitted.
+func buildContextPack(paths []string, worktreePaths map[string]string, budget int) (files []prompts.ContextPackFile, omitted []string) {
+	perFile := budget / 4
+	remaining := budget
+	for _, path := range expandContextPaths(paths, worktreePaths) {
+		data, err := os.ReadFile(path)
+		if err != nil || int64(len(data)) > constants.ExplorerMaxFileSize || isBinary(data) {
+			omitted = append(omitted, path)
+			continue
+		}
+		content := strings.TrimRight(string(data), "\n")
+		f := prompts.ContextPackFile{Path: path, Lines: strings.Count(content, "\n") + 1}
+		limit := min(perFile, remaining)
+		if len(content) <= limit {
+			f.Content = content
+		} else if outline := outlineFile(content, limit); outline != "" {
+			f.Content, f.Summarized = outline, true
+		} else {
+			omitted = append(omitted, path)
+			continue
+		}
+		f.Fence = "```"
+		for strings.Contains(f.Content, f.Fence) {
+			f.Fence += "`"
+		}
+		remaining -= len(f.Content)
+		files = append(files, f)
+	}
+	return files, omitted
+}
+
+// expandContextPaths maps each workspace path to the task's worktree and
+// expands directories to the regular files under them, skipping hidden
+// entries such as .git. The result is deduplicated, in walk order, and
+// capped at maxContextPackFiles. Paths outside every mapped workspace are
+// used as they are.
+func expandContextPaths(paths []string, worktreePaths map[string]string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(p string) bool {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+		return len(out) < maxContextPackFiles
+	}
+	for _, p := range paths {
+		p = worktreePathFor(p, worktreePaths)
+		info, err := os.Stat(p)
+		if err != nil || !info.IsDir() {
+			if !add(p) {
+				return out
+			}
+			continue
+		}
+		full := false
+		_ = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if path != p && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			if !add(path) {
+				full = true
+				return filepath.SkipAll
+			}
+			return nil
+		})
+		if full {
+			return out
+		}
+	}
+	return out
+}
+
+// worktreePathFor rewrites a path inside a workspace to the same path in
+// that workspace's worktree. Nested workspaces map to the innermost one.
+func worktreePathFor(path string, worktreePaths map[string]string) string {
+	workspaces := make([]string, 0, len(worktreePaths))
+	for ws := range worktreePaths {
+		workspaces = append(workspaces, ws)
+	}
+	slices.SortFunc(workspaces, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+	for _, ws := range workspaces {
+		rel, err := filepath.Rel(ws, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.Join(worktreePaths[ws], rel)
+	}
+	return path
+}
+
+// outlineFile summarizes content as numbered lines: the first
+// outlineHeadLines, then the declaration lines, stopping before the
+// outline exceeds limit bytes. It returns "" when not even the first line
+// fits.
+func outlineFile(content string, limit int) string {
+	var b strings.Builder
+	for i, line := range strings.Split(content, "\n") {
+		if i >= outlineHeadLines && !outlineDeclRe.MatchString(line) {
+			continue
+		}
+		if len(line) > maxOutlineLineBytes {
+			line = strings.ToValidUTF8(line[:maxOutlineLineBytes], "") + "…"
+		}
+		entry := fmt.Sprintf("%d: %s\n", i+1, line)
+		if b.Len()+len(entry) > limit {
+			break
+		}
+		b.WriteString(entry)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// isBinary reports whether data looks like a binary file: a NUL byte in
+// its first 8 KiB, the heuristic git uses.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func writeContextFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContextPackPrompt(t *testing.T) {
+	_, r := setupTestRunner(t, nil)
+	ws, wt := t.TempDir(), t.TempDir()
+	writeContextFile(t, filepath.Join(wt, "pkg", "a.go"), "package pkg\n")
+	writeContextFile(t, filepath.Join(wt, "pkg", ".hidden"), "secret\n")
+	writeContextFile(t, filepath.Join(wt, "logo.png"), "\x89PNG\x00\x00")
+	task := &store.Task{ContextFiles: []string{filepath.Join(ws, "pkg"), filepath.Join(ws, "logo.png")}}
+	worktrees := map[string]string{ws: wt}
+
+	got := r.contextPackPrompt(task, "fix the bug", worktrees)
+	for _, want := range []string{"### " + filepath.Join(wt, "pkg", "a.go") + "\n```\npackage pkg\n```", "- " + filepath.Join(wt, "logo.png")} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasPrefix(got, "fix the bug") || strings.Contains(got, "secret") || strings.Contains(got, ws) {
+		t.Errorf("contextPackPrompt = %q", got)
+	}
+	if got := r.contextPackPrompt(task, "", worktrees); got != "" {
+		t.Errorf("auto-continue prompt changed to %q", got)
+	}
+	if got := r.contextPackPrompt(&store.Task{}, "fix the bug", worktrees); got != "fix the bug" {
+		t.Errorf("plain task prompt changed to %q", got)
+	}
+}
+
+func TestBuildContextPack_SummarizesOversizedFiles(t *testing.T) {
+	dir := t.TempDir()
+	var big strings.Builder
+	big.WriteString("package big\n")
+	for i := range 500 {
+		big.WriteString("// filler line to pad the file well past the budget\n")
+		if i == 300 {
+			big.WriteString("func Important() {}\n")
+		}
+	}
+	small := filepath.Join(dir, "small.go")
+	writeContextFile(t, small, "package small\n")
+	writeContextFile(t, filepath.Join(dir, "big.go"), big.String())
+
+	files, omitted := buildContextPack([]string{filepath.Join(dir, "big.go"), small}, nil, 4000)
+	if len(files) != 2 || len(omitted) != 0 {
+		t.Fatalf("files = %+v, omitted = %q", files, omitted)
+	}
+	outline := files[0]
+	if !outline.Summarized || outline.Lines != 502 || len(outline.Content) > 1000 {
+		t.Errorf("big.go: summarized %v, %d lines, %d bytes", outline.Summarized, outline.Lines, len(outline.Content))
+	}
+	if !strings.HasPrefix(outline.Content, "1: package big\n") || !strings.Contains(outline.Content, "303: func Important() {}") {
+		t.Errorf("outline = %q", outline.Content)
+	}
+	if files[1].Summarized || files[1].Content != "package small" {
+		t.Errorf("small.go = %+v", files[1])
+	}
+}
+
+func TestBuildContextPack_FenceAvoidsContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	writeContextFile(t, path, "# Usage\n```sh\nmake\n```\n")
+	files, _ := buildContextPack([]string{path}, nil, 4000)
+	if len(files) != 1 || files[0].Fence != "````" {
+		t.Errorf("files = %+v", files)
+	}
+}
+
+func TestWorktreePathFor(t *testing.T) {
+	worktrees := map[string]string{"/code/app": "/wt/app", "/code/app/sub": "/wt/sub"}
+	for in, want := range map[string]string{
+		"/code/app/main.go":  "/wt/app/main.go",
+		"/code/app/sub/x.go": "/wt/sub/x.go",
+		"/code/app2/main.go": "/code/app2/main.go",
+		"/code/app":          "/wt/app",
+	} {
+		if got := worktreePathFor(in, worktrees); got != want {
+			t.Errorf("worktreePathFor(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// adaptiveTimeout holds the adaptive task timeout settings. The zero value
+// disables it.
+type adaptiveTimeout struct {
+	step   time.Duration // length of one extension
+	max    time.Duration // cap on the total extension
+	active time.Duration // how recent the last progress must be to extend
+}
+
+func (a adaptiveTimeout) enabled() bool { return a.step > 0 && a.max > 0 && a.active > 0 }
+
+// adaptiveTimeoutFromEnv returns the adaptive timeout settings of the env
+// file; WALLFACER_TIMEOUT_EXTEND_MINUTES unset or 0 disables it.
+func (r *Runner) adaptiveTimeoutFromEnv() adaptiveTimeout {
+	if r.envFile == "" {
+		return adaptiveTimeout{}
+	}
+	cfg, err := envconfig.Parse(r.envFile)
+	if err != nil || cfg.TimeoutExtendMinutes <= 0 {
+		return adaptiveTimeout{}
+	}
+	return adaptiveTimeout{
+		step:   time.Duration(cfg.TimeoutExtendMinutes) * time.Minute,
+		max:    time.Duration(cfg.TimeoutExtendMaxMinutes) * time.Minute,
+		active: time.Duration(cfg.TimeoutActiveMinutes) * time.Minute,
+	}
+}
+
+// withTaskTimeout returns the context bounding a task's whole run. With
+// adaptive true and the adaptive timeout configured, its deadline moves
+// back while the task keeps making progress; otherwise it is a plain
+// context.WithTimeout.
+func (r *Runner) withTaskTimeout(parent context.Context, taskID uuid.UUID, timeout time.Duration, adaptive bool) (context.Context, context.CancelFunc) {
+	settings := r.adaptiveTimeoutFromEnv()
+	if !adaptive || !settings.enabled() {
+		return context.WithTimeout(parent, timeout)
+	}
+	return r.newTaskDeadline(parent, taskID, timeout, settings)
+}
+
+// taskDeadline is a task context whose deadline the adaptive timeout can
+// extend. When the deadline arrives it looks for the task's latest
+// progress, meaning output from its agent or a change to the files of its
+// worktrees. Progress within the active window moves the deadline back by
+// one step and records a timeout_extended event, until the extensions
+// reach the cap. Otherwise the context ends with context.DeadlineExceeded,
+// as a plain timeout does, so the task fails as timed out.
+type taskDeadline struct {
+	r        *Runner
+	taskID   uuid.UUID
+	parent   context.Context
+	settings adaptiveTimeout
+	done     chan struct{}
+	now      func() time.Time
+	started  time.Time
+
+	mu         sync.Mutex
+	deadline   time.Time
+	extended   time.Duration
+	lastOutput time.Time
+	timer      *time.Timer
+	err        error
+	stopParent func() bool
+}
+
+// taskDeadlineKey is the context key under which a taskDeadline finds
+// itself, so code running under it can feed it output.
+type taskDeadlineKey struct{}
+
+func (r *Runner) newTaskDeadline(parent context.Context, taskID uuid.UUID, timeout time.Duration, settings adaptiveTimeout) (*taskDeadline, context.CancelFunc) {
+	d := &taskDeadline{
+		r:        r,
+		taskID:   taskID,
+		parent:   parent,
+		settings: settings,
+		done:     make(chan struct{}),
+		now:      time.Now,
+	}
+	d.started = d.now()
+	d.mu.Lock()
+	d.deadline = d.started.Add(timeout)
+	d.timer = time.AfterFunc(timeout, d.expire)
+	d.stopParent = context.AfterFunc(parent, func() { d.finish(parent.Err()) })
+	d.mu.Unlock()
+	return d, func() { d.finish(context.Canceled) }
+}
+
+// taskDeadlineFrom returns the taskDeadline ctx runs under, or nil.
+func taskDeadlineFrom(ctx context.Context) *taskDeadline {
+	d, _ := ctx.Value(taskDeadlineKey{}).(*taskDeadline)
+	return d
+}
+
+// Deadline reports the current deadline, which may still move back.
+func (d *taskDeadline) Deadline() (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.deadline, true
+}
+
+func (d *taskDeadline) Done() <-chan struct{} { return d.done }
+
+func (d *taskDeadline) Err() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+func (d *taskDeadline) Value(key any) any {
+	if key == (taskDeadlineKey{}) {
+		return d
+	}
+	return d.parent.Value(key)
+}
+
+// liveLogWriter returns ll teed into d, so the agent's output counts as
+// progress, or ll alone when d is nil.
+func (d *taskDeadline) liveLogWriter(ll io.Writer) io.Writer {
+	if d == nil {
+		return ll
+	}
+	return io.MultiWriter(ll, d)
+}
+
+// Write records output from the task's agent as progress. It never fails.
+func (d *taskDeadline) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		d.mu.Lock()
+		d.lastOutput = d.now()
+		d.mu.Unlock()
+	}
+	return len(p), nil
+}
+
+// expire runs when the deadline arrives and either extends it or ends the
+// context.
+func (d *taskDeadline) expire() {
+	changed := d.worktreesChangedAt()
+
+	d.mu.Lock()
+	if d.err != nil {
+		d.mu.Unlock()
+		return
+	}
+	now := d.now()
+	if now.Before(d.deadline) {
+		// An extension raced this run of the timer.
+		d.mu.Unlock()
+		return
+	}
+	// Changes older than the run, such as the commit the worktree was
+	// branched from, are not its progress.
+	last := d.lastOutput
+	if changed.After(last) && !changed.Before(d.started) {
+		last = changed
+	}
+	step := min(d.settings.step, d.settings.max-d.extended)
+	if step <= 0 || last.IsZero() || now.Sub(last) > d.settings.active {
+		d.mu.Unlock()
+		d.finish(context.DeadlineExceeded)
+		return
+	}
+	d.extended += step
+	d.deadline = now.Add(step)
+	d.timer.Reset(step)
+	deadline, total := d.deadline, d.extended
+	d.mu.Unlock()
+
+	logger.Runner.Info("task timeout extended", "task", d.taskID,
+		"by", step, "total", total, "last_progress", last)
+	_ = d.r.taskStore(d.taskID).InsertEvent(context.Background(), d.taskID, store.EventTypeTimeoutExtended, store.TimeoutExtendedData{
+		Minutes:        int(step.Round(time.Minute) / time.Minute),
+		TotalMinutes:   int(total.Round(time.Minute) / time.Minute),
+		Deadline:       deadline,
+		LastProgressAt: last,
+	})
+}
+
+// finish ends the context with err unless it has already ended.
+func (d *taskDeadline) finish(err error) {
+	d.mu.Lock()
+	if d.err != nil {
+		d.mu.Unlock()
+		return
+	}
+	d.err = err
+	d.timer.Stop()
+	close(d.done)
+	stopParent := d.stopParent
+	d.mu.Unlock()
+	stopParent()
+}
+
+// worktreesChangedAt returns the latest change to the task's worktrees:
+// the newest commit on their branches and the modification time of every
+// file git reports as changed. It is zero when there is none.
+func (d *taskDeadline) worktreesChangedAt() time.Time {
+	task, err := d.r.taskStore(d.taskID).GetTask(context.Background(), d.taskID)
+	if err != nil {
+		return time.Time{}
+	}
+	var latest time.Time
+	for _, wt := range task.WorktreePaths {
+		if out, err := cmdexec.Git(wt, "log", "-1", "--format=%ct").Output(); err == nil {
+			if sec, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64); err == nil {
+				latest = later(latest, time.Unix(sec, 0))
+			}
+		}
+		// OutputBytes keeps the leading status column that Output would trim.
+		status, err := cmdexec.Git(wt, "status", "--porcelain", "-z", "--untracked-files=all").OutputBytes()
+		if err != nil {
+			continue
+		}
+		for entry := range strings.SplitSeq(string(status), "\x00") {
+			if len(entry) < 4 {
+				continue
+			}
+			if fi, err := os.Stat(filepath.Join(wt, entry[3:])); err == nil {
+				latest = later(latest, fi.ModTime())
+			}
+		}
+	}
+	return latest
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
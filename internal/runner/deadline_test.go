@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// newTestTaskDeadline starts a deadline for a new task, whose worktree is
+// worktree when it is not empty.
+func newTestTaskDeadline(t *testing.T, timeout time.Duration, settings adaptiveTimeout, worktree string) (*store.Store, uuid.UUID, *taskDeadline, context.CancelFunc) {
+	t.Helper()
+	s, r := setupRunnerWithCmd(t, nil, "echo")
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worktree != "" {
+		if err := s.UpdateTaskWorktrees(ctx, task.ID, map[string]string{worktree: worktree}, "main"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, cancel := r.newTaskDeadline(ctx, task.ID, timeout, settings)
+	t.Cleanup(cancel)
+	return s, task.ID, d, cancel
+}
+
+func waitDone(t *testing.T, ctx context.Context, within time.Duration) time.Duration {
+	t.Helper()
+	start := time.Now()
+	select {
+	case <-ctx.Done():
+		return time.Since(start)
+	case <-time.After(within):
+		t.Fatal("context not done")
+		return 0
+	}
+}
+
+func TestTaskDeadline_ExpiresWhenIdle(t *testing.T) {
+	settings := adaptiveTimeout{step: time.Second, max: time.Minute, active: time.Minute}
+	s, taskID, d, _ := newTestTaskDeadline(t, 50*time.Millisecond, settings, setupTestRepo(t))
+	child, stop := context.WithCancel(d)
+	defer stop()
+
+	waitDone(t, child, 5*time.Second)
+	if !errors.Is(d.Err(), context.DeadlineExceeded) || !errors.Is(child.Err(), context.DeadlineExceeded) {
+		t.Errorf("Err = %v, child Err = %v", d.Err(), child.Err())
+	}
+	events, _ := s.GetEvents(context.Background(), taskID)
+	if n := countEvents(events, store.EventTypeTimeoutExtended); n != 0 {
+		t.Errorf("timeout_extended events = %d, want 0 for an idle task", n)
+	}
+}
+
+func TestTaskDeadline_ExtendsWhileProgressingUpToCap(t *testing.T) {
+	settings := adaptiveTimeout{step: 100 * time.Millisecond, max: 200 * time.Millisecond, active: time.Minute}
+	s, taskID, d, _ := newTestTaskDeadline(t, 100*time.Millisecond, settings, "")
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(10 * time.Millisecond):
+				_, _ = d.Write([]byte("working\n"))
+			}
+		}
+	}()
+
+	if elapsed := waitDone(t, d, 5*time.Second); elapsed < 250*time.Millisecond {
+		t.Errorf("ended after %v, want the 100ms timeout plus 200ms of extensions", elapsed)
+	}
+	if !errors.Is(d.Err(), context.DeadlineExceeded) {
+		t.Errorf("Err = %v, want DeadlineExceeded once the cap is reached", d.Err())
+	}
+	events, _ := s.GetEvents(context.Background(), taskID)
+	if n := countEvents(events, store.EventTypeTimeoutExtended); n != 2 {
+		t.Errorf("timeout_extended events = %d, want 2", n)
+	}
+}
+
+func TestTaskDeadline_FileChangeCountsAsProgress(t *testing.T) {
+	settings := adaptiveTimeout{step: 100 * time.Millisecond, max: 100 * time.Millisecond, active: time.Minute}
+	repo := setupTestRepo(t)
+	s, taskID, d, _ := newTestTaskDeadline(t, 50*time.Millisecond, settings, repo)
+	// The file system's coarse clock can date a write a few milliseconds
+	// back, before the deadline started; set the time the check sees.
+	path := filepath.Join(repo, "new.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if now := time.Now(); os.Chtimes(path, now, now) != nil {
+		t.Fatal("set modification time")
+	}
+
+	waitDone(t, d, 5*time.Second)
+	events, _ := s.GetEvents(context.Background(), taskID)
+	if n := countEvents(events, store.EventTypeTimeoutExtended); n != 1 {
+		t.Errorf("timeout_extended events = %d, want 1", n)
+	}
+}
+
+func TestTaskDeadline_Cancel(t *testing.T) {
+	settings := adaptiveTimeout{step: time.Minute, max: time.Hour, active: time.Minute}
+	_, _, d, cancel := newTestTaskDeadline(t, time.Hour, settings, "")
+	if taskDeadlineFrom(d) != d || taskDeadlineFrom(context.Background()) != nil {
+		t.Error("taskDeadlineFrom does not find the deadline")
+	}
+	cancel()
+	waitDone(t, d, time.Second)
+	if !errors.Is(d.Err(), context.Canceled) {
+		t.Errorf("Err = %v, want Canceled", d.Err())
+	}
+
+	_, r := setupRunnerWithCmd(t, nil, "echo")
+	parent, cancelParent := context.WithCancel(context.Background())
+	d2, cancel2 := r.newTaskDeadline(parent, uuid.New(), time.Hour, settings)
+	defer cancel2()
+	cancelParent()
+	waitDone(t, d2, time.Second)
+	if !errors.Is(d2.Err(), context.Canceled) {
+		t.Errorf("Err after parent cancel = %v, want Canceled", d2.Err())
+	}
+}
@@ -63,6 +63,21 @@ func (r *Runner) AssessDrift(ctx context.Context, specBody string, affects, chan
 // tolerating surrounding prose or a code fence the agent may emit despite the
 // instruction not to.
 func parseDriftVerdict(raw string) (spec.DriftVerdict, error) {
+	obj, ok := extractJSONObject(raw)
+	if !ok {
+		return spec.DriftVerdict{}, fmt.Errorf("no JSON object in drift output: %s", truncate(raw, 200))
+	}
+	var v spec.DriftVerdict
+	if err := json.Unmarshal([]byte(obj), &v); err != nil {
+		return spec.DriftVerdict{}, fmt.Errorf("parse drift verdict: %w", err)
+	}
+	return v, nil
+}
+
+// extractJSONObject returns the outermost {...} span of raw, after stripping
+// a surrounding code fence if present. ok is false when raw contains no
+// object.
+func extractJSONObject(raw string) (string, bool) {
 	s := strings.TrimSpace(raw)
 	if i := strings.Index(s, "```"); i >= 0 {
 		s = s[i+3:]
@@ -76,11 +91,7 @@ func parseDriftVerdict(raw string) (spec.DriftVerdict, error) {
 	start := strings.IndexByte(s, '{')
 	end := strings.LastIndexByte(s, '}')
 	if start < 0 || end < start {
-		return spec.DriftVerdict{}, fmt.Errorf("no JSON object in drift output: %s", truncate(raw, 200))
-	}
-	var v spec.DriftVerdict
-	if err := json.Unmarshal([]byte(s[start:end+1]), &v); err != nil {
-		return spec.DriftVerdict{}, fmt.Errorf("parse drift verdict: %w", err)
+		return "", false
 	}
-	return v, nil
+	return s[start : end+1], true
 }
@@ -0,0 +1,218 @@
+package runner
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+const (
+	// estimateLayoutMaxLines caps the per-directory listing fed to the
+	// estimation agent. Directory-level counts are enough to judge scope.
+	estimateLayoutMaxLines = 200
+	// estimateLayoutDepth is how many path segments directories are grouped by.
+	estimateLayoutDepth = 2
+	// estimateHistoryLimit is how many past estimate/actual pairs are shown to
+	// the agent as calibration examples.
+	estimateHistoryLimit = 5
+)
+
+// estimateResult is the JSON shape the estimation agent emits.
+type estimateResult struct {
+	Turns     int     `json:"turns"`
+	Tokens    int     `json:"tokens"`
+	Minutes   float64 `json:"minutes"`
+	Risk      string  `json:"risk"`
+	Rationale string  `json:"rationale"`
+}
+
+// EstimateTask runs a one-shot agent that predicts the effort (turns, tokens,
+// minutes) and risk of a task from its prompt and the workspace layout, then
+// stores the estimate on the task. The prompt includes the most recent
+// finished tasks' estimates next to their actuals so the estimator can
+// correct its own bias. Mirrors AssessDrift: a short-lived container with a
+// 120-second sub-deadline, claude first with a codex fallback on a
+// token-limit hit.
+func (r *Runner) EstimateTask(ctx context.Context, taskID uuid.UUID) (store.TaskEstimate, error) {
+	s := r.taskStore(taskID)
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return store.TaskEstimate{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+
+	taskPrompt := task.Prompt
+	if task.ExecutionPrompt != "" {
+		taskPrompt = task.ExecutionPrompt
+	}
+	var history string
+	if tasks, err := s.ListTasks(ctx, true); err == nil {
+		history = estimateHistory(tasks, taskID, s.LoadSummary)
+	}
+	prompt := r.promptsMgr.Estimate(prompts.EstimateData{
+		Prompt:     taskPrompt,
+		RepoLayout: repoLayout(r.Workspaces()),
+		History:    history,
+	})
+	containerName := "wallfacer-estimate-" + uuid.NewString()[:8]
+	labels := map[string]string{"wallfacer.task.id": taskID.String(), "wallfacer.task.activity": "estimate"}
+
+	output, err := r.runCommitContainer(ctx, containerName, prompt, harness.Claude, labels)
+	if err != nil {
+		if isLikelyTokenLimitError(err.Error()) {
+			output, err = r.runCommitContainer(ctx, containerName, prompt, harness.Codex, labels)
+		}
+		if err != nil {
+			return store.TaskEstimate{}, err
+		}
+	}
+	if output == nil {
+		return store.TaskEstimate{}, fmt.Errorf("estimate agent returned nil output")
+	}
+	if output.IsError {
+		msg := strings.TrimSpace(output.Result)
+		if msg == "" {
+			msg = "agent returned an error result"
+		}
+		return store.TaskEstimate{}, fmt.Errorf("estimate agent: %s", msg)
+	}
+	est, err := parseEstimate(output.Result)
+	if err != nil {
+		return store.TaskEstimate{}, err
+	}
+	est.EstimatedAt = time.Now()
+	if err := s.UpdateTaskEstimate(ctx, taskID, est); err != nil {
+		return store.TaskEstimate{}, err
+	}
+	return est, nil
+}
+
+// parseEstimate extracts a TaskEstimate from agent output. Negative values
+// are clamped to zero and an unrecognised risk is treated as medium, so a
+// sloppy but parseable answer still yields a usable estimate.
+func parseEstimate(raw string) (store.TaskEstimate, error) {
+	obj, ok := extractJSONObject(raw)
+	if !ok {
+		return store.TaskEstimate{}, fmt.Errorf("no JSON object in estimate output: %s", truncate(raw, 200))
+	}
+	var res estimateResult
+	if err := json.Unmarshal([]byte(obj), &res); err != nil {
+		return store.TaskEstimate{}, fmt.Errorf("parse estimate: %w", err)
+	}
+	risk := store.EstimateRisk(strings.ToLower(strings.TrimSpace(res.Risk)))
+	switch risk {
+	case store.EstimateRiskLow, store.EstimateRiskMedium, store.EstimateRiskHigh:
+	default:
+		risk = store.EstimateRiskMedium
+	}
+	return store.TaskEstimate{
+		Turns:     max(res.Turns, 0),
+		Tokens:    max(res.Tokens, 0),
+		Minutes:   max(res.Minutes, 0),
+		Risk:      risk,
+		Rationale: strings.TrimSpace(res.Rationale),
+	}, nil
+}
+
+// estimateHistory formats up to estimateHistoryLimit of the most recently
+// finished tasks that carry an estimate as "estimated vs actual" lines.
+// exclude is the task being estimated. Returns "" when there is no history.
+func estimateHistory(tasks []store.Task, exclude uuid.UUID, loadSummary func(uuid.UUID) (*store.TaskSummary, error)) string {
+	var done []store.Task
+	for _, t := range tasks {
+		if t.ID != exclude && t.Status == store.TaskStatusDone && t.Estimate != nil {
+			done = append(done, t)
+		}
+	}
+	slices.SortFunc(done, func(a, b store.Task) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+
+	var sb strings.Builder
+	for _, t := range done[:min(len(done), estimateHistoryLimit)] {
+		var summary *store.TaskSummary
+		if loadSummary != nil {
+			summary, _ = loadSummary(t.ID)
+		}
+		turns, tokens, minutes := t.EffortActuals(summary)
+		label := cmp.Or(t.Title, truncate(t.Prompt, 60))
+		fmt.Fprintf(&sb, "- %s: estimated %d turns, %d tokens, %.0f min; actual %d turns, %d tokens, %.0f min\n",
+			label, t.Estimate.Turns, t.Estimate.Tokens, t.Estimate.Minutes, turns, tokens, minutes)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// repoLayout summarises each workspace as a list of directories (grouped to
+// estimateLayoutDepth segments) with their tracked file counts. Git
+// workspaces use `git ls-files`; other directories list their top-level
+// entries only.
+func repoLayout(workspaces []string) string {
+	var sb strings.Builder
+	lines := 0
+	for _, ws := range workspaces {
+		files := workspaceFiles(ws)
+		fmt.Fprintf(&sb, "%s/ (%d files)\n", filepath.Base(ws), len(files))
+		counts := make(map[string]int)
+		for _, f := range files {
+			dir := path.Dir(f)
+			if dir == "." {
+				counts[f] = 0 // top-level file: list by name
+				continue
+			}
+			if parts := strings.Split(dir, "/"); len(parts) > estimateLayoutDepth {
+				dir = strings.Join(parts[:estimateLayoutDepth], "/")
+			}
+			counts[dir+"/"]++
+		}
+		for _, name := range slices.Sorted(maps.Keys(counts)) {
+			if lines >= estimateLayoutMaxLines {
+				sb.WriteString("  ...\n")
+				return sb.String()
+			}
+			if n := counts[name]; n > 0 {
+				fmt.Fprintf(&sb, "  %s (%d files)\n", name, n)
+			} else {
+				fmt.Fprintf(&sb, "  %s\n", name)
+			}
+			lines++
+		}
+	}
+	return sb.String()
+}
+
+// workspaceFiles returns slash-separated paths relative to ws.
+func workspaceFiles(ws string) []string {
+	if gitutil.IsGitRepo(ws) {
+		out, err := cmdexec.Git(ws, "ls-files").Output()
+		if err == nil {
+			return strings.FieldsFunc(out, func(r rune) bool { return r == '\n' })
+		}
+	}
+	entries, err := os.ReadDir(ws)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		files = append(files, e.Name())
+	}
+	return files
+}
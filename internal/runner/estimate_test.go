@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestParseEstimate(t *testing.T) {
+	raw := "Here you go:\n```json\n{\"turns\": 6, \"tokens\": 90000, \"minutes\": 12.5, \"risk\": \"HIGH\", \"rationale\": \" wide change \"}\n```"
+	est, err := parseEstimate(raw)
+	if err != nil {
+		t.Fatalf("parseEstimate: %v", err)
+	}
+	if est.Turns != 6 || est.Tokens != 90000 || est.Minutes != 12.5 {
+		t.Errorf("estimate = %+v", est)
+	}
+	if est.Risk != store.EstimateRiskHigh {
+		t.Errorf("Risk = %q, want high", est.Risk)
+	}
+	if est.Rationale != "wide change" {
+		t.Errorf("Rationale = %q, want trimmed", est.Rationale)
+	}
+}
+
+func TestParseEstimate_ClampsAndDefaults(t *testing.T) {
+	est, err := parseEstimate(`{"turns": -3, "tokens": -1, "minutes": -2, "risk": "extreme"}`)
+	if err != nil {
+		t.Fatalf("parseEstimate: %v", err)
+	}
+	if est.Turns != 0 || est.Tokens != 0 || est.Minutes != 0 {
+		t.Errorf("negative values should clamp to zero, got %+v", est)
+	}
+	if est.Risk != store.EstimateRiskMedium {
+		t.Errorf("unknown risk should default to medium, got %q", est.Risk)
+	}
+}
+
+func TestParseEstimate_NoJSON(t *testing.T) {
+	if _, err := parseEstimate("I cannot estimate this."); err == nil {
+		t.Error("expected error for output without a JSON object")
+	}
+}
+
+func TestEstimateHistory(t *testing.T) {
+	now := time.Now()
+	started := now.Add(-time.Hour)
+	self := uuid.New()
+	tasks := []store.Task{
+		{ID: uuid.New(), Title: "older", Status: store.TaskStatusDone, UpdatedAt: now.Add(-2 * time.Hour),
+			Estimate: &store.TaskEstimate{Turns: 1}},
+		{ID: uuid.New(), Title: "newer", Status: store.TaskStatusDone, Turns: 7, StartedAt: &started, UpdatedAt: now,
+			Usage: store.TaskUsage{InputTokens: 900, OutputTokens: 100}, Estimate: &store.TaskEstimate{Turns: 3, Tokens: 500, Minutes: 30}},
+		{ID: uuid.New(), Title: "unestimated", Status: store.TaskStatusDone},
+		{ID: uuid.New(), Title: "failed", Status: store.TaskStatusFailed, Estimate: &store.TaskEstimate{}},
+		{ID: self, Title: "self", Status: store.TaskStatusDone, Estimate: &store.TaskEstimate{}},
+	}
+
+	got := estimateHistory(tasks, self, nil)
+	lines := strings.Split(got, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 history lines, got %d: %q", len(lines), got)
+	}
+	if !strings.HasPrefix(lines[0], "- newer:") || !strings.HasPrefix(lines[1], "- older:") {
+		t.Errorf("expected newest first, got %q", got)
+	}
+	if !strings.Contains(lines[0], "estimated 3 turns, 500 tokens, 30 min; actual 7 turns, 1000 tokens, 60 min") {
+		t.Errorf("unexpected history line: %q", lines[0])
+	}
+	if estimateHistory(nil, self, nil) != "" {
+		t.Error("expected empty history for no tasks")
+	}
+}
+
+func TestRepoLayout_GroupsGitFilesByDirectory(t *testing.T) {
+	repo := setupTestRepo(t)
+	for _, f := range []string{"cmd/app/main.go", "internal/a/b/c/deep.go", "internal/a/x.go"} {
+		p := filepath.Join(repo, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-m", "layout")
+
+	got := repoLayout([]string{repo})
+	for _, want := range []string{
+		filepath.Base(repo) + "/",
+		"  cmd/app/ (1 files)",
+		"  internal/a/ (2 files)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("layout missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "internal/a/b/") {
+		t.Errorf("directories deeper than estimateLayoutDepth should be folded:\n%s", got)
+	}
+}
+
+func TestRepoLayout_NonGitListsTopLevel(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, ".hidden"), []byte("x"), 0644)
+
+	got := repoLayout([]string{dir})
+	if !strings.Contains(got, "  notes.txt\n") {
+		t.Errorf("expected top-level file listed, got:\n%s", got)
+	}
+	if strings.Contains(got, ".hidden") {
+		t.Errorf("hidden entries should be skipped, got:\n%s", got)
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/envconfig"
 	"latere.ai/x/wallfacer/internal/gitutil"
 	"latere.ai/x/wallfacer/internal/harness"
 	"latere.ai/x/wallfacer/internal/logger"
@@ -136,6 +137,45 @@ func (r *Runner) tryAutoRetry(bgCtx context.Context, taskID uuid.UUID, category
 	return true
 }
 
+// pauseForBudget moves an in-progress task to waiting because a cost or
+// token budget was reached, recording reason as a budget_exceeded event.
+// The caller must set statusSet=true and return immediately afterwards.
+func (r *Runner) pauseForBudget(bgCtx context.Context, taskID uuid.UUID, reason string) {
+	_ = r.taskStore(taskID).UpdateTaskStatus(bgCtx, taskID, store.TaskStatusWaiting)
+
+	_ = r.taskStore(taskID).SetTaskFailureCategory(bgCtx, taskID, store.FailureCategoryBudget)
+
+	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeStateChange,
+
+		store.NewStateChangeData(store.TaskStatusInProgress, store.TaskStatusWaiting, store.TriggerSystem, nil))
+	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+
+		"message":         reason,
+		"budget_exceeded": true,
+	})
+	r.GenerateOversightBackground(taskID)
+	_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "feedback_waiting", Label: "feedback_waiting"})
+}
+
+// boardBudgetExceeded reports why the board's daily or total cost budget
+// blocks another turn, or "" when neither WALLFACER_DAILY_BUDGET_USD nor
+// WALLFACER_BOARD_BUDGET_USD is set or reached.
+func (r *Runner) boardBudgetExceeded(bgCtx context.Context, taskID uuid.UUID) string {
+	if r.envFile == "" {
+		return ""
+	}
+	cfg, err := envconfig.Parse(r.envFile)
+	if err != nil || (cfg.DailyBudgetUSD <= 0 && cfg.BoardBudgetUSD <= 0) {
+		return ""
+	}
+	spend, err := r.taskStore(taskID).Spend(bgCtx, time.Now())
+	if err != nil {
+		logger.Runner.Warn("board spend", "task", taskID, "error", err)
+		return ""
+	}
+	return spend.Exceeded(cfg.DailyBudgetUSD, cfg.BoardBudgetUSD)
+}
+
 // Run is the main task execution loop. It sets up worktrees, runs the agent
 // in a container, handles auto-continue turns, and transitions the task to the
 // appropriate terminal state (done/waiting/failed).
@@ -198,6 +238,9 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 		prompt = task.ExecutionPrompt
 	}
 
+	// The shadow arm of a dark-launch experiment starts with its primary.
+	r.startExperimentShadow(task)
+
 	// Resolve the task's flow. Precedence: task.FlowID → legacy Kind
 	// mapping → "implement". The implement path stays on the turn loop
 	// below (multi-turn semantics the linear engine does not express
@@ -245,6 +288,7 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 		}
 		flowCtx, flowCancel := context.WithTimeout(bgCtx, flowTimeout)
 		defer flowCancel()
+		defer r.trackRun(taskID, flowCancel)()
 		if runErr := r.flowEngine.Execute(flowCtx, f, task); runErr != nil {
 			if cur, _ := r.taskStore(taskID).GetTask(bgCtx, taskID); cur != nil && cur.Status == store.TaskStatusCancelled {
 				return
@@ -299,8 +343,15 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 	if timeout <= 0 {
 		timeout = constants.DefaultTaskTimeout
 	}
-	ctx, cancel := context.WithTimeout(bgCtx, timeout)
+	if task.Kind == store.TaskKindResearch {
+		timeout = researchTimeBox(timeout)
+	}
+	// With WALLFACER_TIMEOUT_EXTEND_MINUTES set, a task still making
+	// progress at its deadline gets more time; a research time box is
+	// never extended.
+	ctx, cancel := r.withTaskTimeout(bgCtx, taskID, timeout, task.Kind != store.TaskKindResearch)
 	defer cancel()
+	defer r.trackRun(taskID, cancel)()
 
 	// Launch periodic oversight generation while the turn-loop executes.
 	// The goroutine exits when Run returns (oversightCancel is deferred).
@@ -370,6 +421,9 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 		if err := r.taskStore(taskID).UpdateTaskWorktrees(bgCtx, taskID, worktreePaths, branchName); err != nil {
 			logger.Runner.Error("save worktree paths", "task", taskID, "error", err)
 		}
+		// Fresh worktrees hold no installed dependencies; run the
+		// workspace's bootstrap command before the agent starts.
+		r.runBootstrap(ctx, taskID, worktreePaths)
 	}
 
 	// Native Topos harness dispatch: now that the worktree exists, run the task
@@ -383,6 +437,28 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 
 	turns := task.Turns
 
+	// Paths the user copied from the workspace checkout point at the task's
+	// worktree instead, on fresh prompts and feedback alike.
+	prompt = r.workspacePathsPrompt(prompt, worktreePaths)
+
+	// Files filed with the task through intake are written out for the
+	// agent on every run, so a resumed session still finds them.
+	attachmentsDir := r.writeAttachments(task)
+	if attachmentsDir != "" {
+		defer func() { _ = os.RemoveAll(attachmentsDir) }()
+	}
+
+	// Research tasks run their fresh prompt inside the research framing and
+	// collect the URLs the agent fetches as citations. The task's external
+	// links, its attached files, the approval protocol, and its context pack
+	// follow the prompt, and the board's preamble leads every fresh prompt.
+	if sessionID == "" {
+		prompt = r.linksPrompt(task, r.researchPrompt(task, r.projectPrompt(task, experimentPrompt(task, prompt))))
+		prompt = r.approvalGatesPrompt(task, r.attachmentsPrompt(task, prompt, attachmentsDir))
+		prompt = r.preamblePrompt(bgCtx, task, r.contextPackPrompt(task, prompt, worktreePaths))
+	}
+	var citations citationLog
+
 	// testSessionID tracks the test agent's session across turns so that
 	// multi-turn test runs (max_tokens/pause_turn) can resume their own
 	// session rather than starting a fresh empty-prompt session.
@@ -422,6 +498,14 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 		turns++
 		logger.Runner.Info("turn", "task", taskID, "turn", turns, "session", sessionID, "timeout", timeout)
 
+		// Board-wide budgets are checked before each turn so no new spend
+		// starts once the daily or total cap is reached.
+		if reason := r.boardBudgetExceeded(bgCtx, taskID); reason != "" {
+			statusSet = true
+			r.pauseForBudget(bgCtx, taskID, reason)
+			return
+		}
+
 		// Refresh board.json and sibling mounts before each turn so they reflect latest state.
 		if boardDir != "" {
 			boardRefreshLabel := fmt.Sprintf("board_context_%d", turns)
@@ -446,13 +530,16 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 		}
 		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "agent_turn", Label: turnLabel})
 
-		output, rawStdout, rawStderr, err := r.runContainer(ctx, taskID, prompt, sessionID, worktreePaths, boardDir, siblingMounts, modelOverride, runActivity)
+		turnOut := r.taskStore(taskID).OpenTurnOutput(taskID, turns)
+		output, _, _, err := r.runContainer(ctx, taskID, prompt, sessionID, worktreePaths, boardDir, siblingMounts, modelOverride, runActivity, turnOut)
 		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanEnd, store.SpanData{Phase: "agent_turn", Label: turnLabel})
 
-		if saveErr := r.taskStore(taskID).SaveTurnOutput(taskID, turns, rawStdout, rawStderr); saveErr != nil {
+		if saveErr := turnOut.Close(); saveErr != nil {
 			logger.Runner.Error("save turn output", "task", taskID, "turn", turns, "error", saveErr)
 		}
-		if len(rawStderr) > 0 {
+		r.saveTurnExit(taskID, turns)
+		r.repairTurnOwnership(taskID, turns, worktreePaths)
+		if turnOut.HasStderr() {
 			stderrFile := fmt.Sprintf("turn-%04d.stderr.txt", turns)
 			_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
 
@@ -463,9 +550,11 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 		if err != nil {
 			// Try to salvage session_id from partial output so the task
 			// can be resumed even when the container fails (e.g. timeout).
-			if sessionID == "" && len(rawStdout) > 0 {
-				if sid := extractSessionID(rawStdout); sid != "" {
-					sessionID = sid
+			if sessionID == "" {
+				if rawStdout, _ := r.taskStore(taskID).ReadTurnOutput(taskID, turns); len(rawStdout) > 0 {
+					if sid := extractSessionID(rawStdout); sid != "" {
+						sessionID = sid
+					}
 				}
 			}
 
@@ -483,6 +572,7 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 				} else {
 					prompt = task.Prompt
 				}
+				prompt = r.approvalGatesPrompt(task, r.researchPrompt(task, r.projectPrompt(task, experimentPrompt(task, prompt))))
 				continue
 			}
 
@@ -511,6 +601,14 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 			return
 		}
 
+		if task.Kind == store.TaskKindResearch && !isTestRun {
+			rawStdout, _ := r.taskStore(taskID).ReadTurnOutput(taskID, turns)
+			r.recordCitations(taskID, &citations, rawStdout)
+			if output.StopReason == "end_turn" {
+				output.Result = withSourcesSection(output.Result, citations.urls)
+			}
+		}
+
 		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeOutput, map[string]string{
 
 			"result":      output.Result,
@@ -590,21 +688,7 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 					reason = fmt.Sprintf("token budget exceeded: %d of %d input tokens", totalInputTokens, currentTask.MaxInputTokens)
 				}
 				statusSet = true
-				_ = r.taskStore(taskID).UpdateTaskStatus(bgCtx, taskID, store.TaskStatusWaiting)
-
-				_ = r.taskStore(taskID).SetTaskFailureCategory(bgCtx, taskID, store.FailureCategoryBudget)
-
-				_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeStateChange,
-
-					store.NewStateChangeData(store.TaskStatusInProgress, store.TaskStatusWaiting, store.TriggerSystem, nil))
-				_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
-
-					"message":         reason,
-					"budget_exceeded": true,
-				})
-				r.GenerateOversightBackground(taskID)
-				_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "feedback_waiting", Label: "feedback_waiting"})
-
+				r.pauseForBudget(bgCtx, taskID, reason)
 				return
 			}
 		}
@@ -613,6 +697,7 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 			// If the error is a stale session ("No conversation found"),
 			// drop the session and retry with the original prompt instead
 			// of failing permanently.
+			rawStdout, _ := r.taskStore(taskID).ReadTurnOutput(taskID, turns)
 			combinedErr := output.Result + " " + string(rawStdout)
 			if sessionID != "" && strings.Contains(combinedErr, "No conversation found") {
 				logger.Runner.Warn("session not found, retrying without session",
@@ -627,6 +712,7 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 				} else {
 					prompt = task.Prompt
 				}
+				prompt = r.approvalGatesPrompt(task, r.researchPrompt(task, r.projectPrompt(task, experimentPrompt(task, prompt))))
 				continue
 			}
 			category := classifyFailure(nil, true, output.Result)
@@ -656,9 +742,27 @@ func (r *Runner) Run(taskID uuid.UUID, prompt, sessionID string, resumedFromWait
 				r.finalizeTestRun(bgCtx, taskID, *task, output.Result)
 				return
 			}
+			// An agent that ended its turn asking for approval waits for
+			// the decision, which resumes the session.
+			if req, ok := parseApprovalRequest(output.Result); ok && !task.IsShadow() {
+				if r.requestApproval(bgCtx, taskID, turns, req) {
+					return
+				}
+			}
+			// Experiment arms record the size of their changes for
+			// comparison; a shadow arm is then discarded instead of
+			// waiting for review.
+			if task.Experiment != nil {
+				if task.IsShadow() {
+					r.finishShadow(ctx, taskID)
+					return
+				}
+				r.recordExperimentDiff(ctx, taskID, worktreePaths)
+			}
 			// Move to waiting for human review. Auto-submit (if enabled)
 			// will pick up the task and run the commit pipeline.
 			r.GenerateOversightBackground(taskID)
+			r.LocalizeSummaryBackground(taskID)
 			_ = r.taskStore(taskID).UpdateTaskStatus(bgCtx, taskID, store.TaskStatusWaiting)
 
 			_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeStateChange,
@@ -834,7 +938,7 @@ func (r *Runner) SyncWorktrees(taskID uuid.UUID, sessionID string, prevStatus st
 				"result": fmt.Sprintf("Conflict in %s — running resolver (attempt %d/%d)...",
 					filepath.Base(repoPath), attempt, constants.MaxRebaseRetries),
 			})
-			if resolveErr := r.resolveConflicts(ctx, taskID, repoPath, worktreePath, sessionID, defBranch, ConflictResolverTriggerSync, attempt, constants.MaxRebaseRetries); resolveErr != nil {
+			if resolveErr := r.resolveConflicts(ctx, taskID, repoPath, worktreePath, sessionID, defBranch, conflictedFiles(rebaseErr), ConflictResolverTriggerSync, attempt, constants.MaxRebaseRetries); resolveErr != nil {
 				rebaseErr = fmt.Errorf("conflict resolution failed: %w", resolveErr)
 				break
 			}
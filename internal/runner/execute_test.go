@@ -1643,6 +1643,51 @@ func TestRunBudgetTokensExceededTransitionsToWaiting(t *testing.T) {
 	}
 }
 
+// TestRunBoardBudgetExceededPausesBeforeTurn verifies that once the board's
+// spend reaches WALLFACER_BOARD_BUDGET_USD, the runner moves the task to
+// "waiting" before starting a turn, so the agent is never invoked.
+func TestRunBoardBudgetExceededPausesBeforeTurn(t *testing.T) {
+	repo := setupTestRepo(t)
+	cmd := fakeCmdScript(t, endTurnOutput, 0)
+	s, r := setupRunnerWithCmd(t, []string{repo}, cmd)
+	r.envFile = filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(r.envFile, []byte("WALLFACER_BOARD_BUDGET_USD=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	spent, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "Earlier spend", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AccumulateSubAgentUsage(ctx, spent.ID, store.SandboxActivityImplementation, store.TaskUsage{CostUSD: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "Board budget test", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	r.Run(task.ID, "do the task", "", false)
+
+	updated, err := s.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != store.TaskStatusWaiting {
+		t.Fatalf("expected status=waiting when board budget exceeded, got %q", updated.Status)
+	}
+	if updated.Usage.CostUSD != 0 {
+		t.Errorf("cost = %v, want 0: no turn should run over budget", updated.Usage.CostUSD)
+	}
+	if updated.FailureCategory != store.FailureCategoryBudget {
+		t.Errorf("failure category = %q, want %q", updated.FailureCategory, store.FailureCategoryBudget)
+	}
+}
+
 func TestSyncWorktrees_PreservesTestVerdictAfterCleanSync(t *testing.T) {
 	repo := setupTestRepo(t)
 	cmd := fakeCmdScript(t, "", 0)
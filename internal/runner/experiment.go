@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// experimentPrompt appends an experiment arm's extra instructions to a fresh
+// prompt. Test runs, the empty prompt of an auto-continue turn, and tasks
+// outside an experiment are returned unchanged.
+func experimentPrompt(task *store.Task, prompt string) string {
+	if task.Experiment == nil || task.Experiment.Instructions == "" || task.IsTestRun || prompt == "" {
+		return prompt
+	}
+	return prompt + "\n\n" + task.Experiment.Instructions
+}
+
+// startExperimentShadow launches the shadow arm of a primary task's
+// experiment alongside it, in the primary's workspace. It is a no-op unless
+// the shadow is still in the backlog, so resumed turns and retries of the
+// primary do not start it again.
+func (r *Runner) startExperimentShadow(task *store.Task) {
+	if task.Experiment == nil || task.Experiment.Role != store.ExperimentRolePrimary || task.IsTestRun {
+		return
+	}
+	bgCtx := r.shutdownCtx
+	s := r.taskStore(task.ID)
+	shadowID := task.Experiment.PeerID
+	shadow, err := s.GetTask(bgCtx, shadowID)
+	if err != nil || shadow.Status != store.TaskStatusBacklog {
+		return
+	}
+	if err := s.UpdateTaskStatus(bgCtx, shadowID, store.TaskStatusInProgress); err != nil {
+		logger.Runner.Warn("experiment: start shadow", "task", task.ID, "shadow", shadowID, "error", err)
+		return
+	}
+	_ = s.InsertEvent(bgCtx, shadowID, store.EventTypeStateChange,
+		store.NewStateChangeData(store.TaskStatusBacklog, store.TaskStatusInProgress, store.TriggerSystem, nil))
+	r.runBackgroundIn(r.taskWorkspaceKey(task.ID), shadowID, shadow.Prompt, "", false)
+}
+
+// recordExperimentDiff measures an experiment arm's changes in its worktrees
+// and stores them on the task for comparison.
+func (r *Runner) recordExperimentDiff(ctx context.Context, taskID uuid.UUID, worktreePaths map[string]string) store.ExperimentDiff {
+	diff := measureWorktreeDiff(ctx, worktreePaths)
+	if err := r.taskStore(taskID).UpdateExperimentDiff(r.shutdownCtx, taskID, diff); err != nil {
+		logger.Runner.Warn("experiment: record diff", "task", taskID, "error", err)
+	}
+	return diff
+}
+
+// discardShadow ends the shadow arm of an experiment in place of the commit
+// pipeline: it measures the arm's changes, removes its worktrees and branch
+// without merging anything, and posts the arm's metrics to the primary's
+// event log. The caller moves the task to done.
+func (r *Runner) discardShadow(ctx context.Context, task *store.Task) {
+	bgCtx := r.shutdownCtx
+	s := r.taskStore(task.ID)
+	diff := r.recordExperimentDiff(ctx, task.ID, task.WorktreePaths)
+	r.CleanupWorktrees(task.ID, task.WorktreePaths, task.BranchName)
+	_ = s.InsertEvent(bgCtx, task.ID, store.EventTypeSystem, map[string]string{
+		"result": "Shadow run finished — its changes were measured and discarded, not merged.",
+	})
+
+	cur, err := s.GetTask(bgCtx, task.ID)
+	if err != nil {
+		cur = task
+	}
+	label := cur.Experiment.Label
+	if label == "" {
+		label = string(store.ExperimentRoleShadow)
+	}
+	_ = s.InsertEvent(bgCtx, cur.Experiment.PeerID, store.EventTypeSystem, map[string]any{
+		"phase":     "experiment",
+		"shadow_id": cur.ID.String(),
+		"result": fmt.Sprintf("Experiment arm %q finished: %d turns, $%.4f, %d files changed (+%d/-%d). Compare at /api/tasks/%s/experiment.",
+			label, cur.Turns, cur.Usage.CostUSD, diff.Files, diff.Added, diff.Deleted, cur.Experiment.PeerID),
+	})
+}
+
+// finishShadow completes a shadow arm whose agent has finished: the arm is
+// discarded and walks waiting → committing → done like a flow-engine task,
+// since nothing of it is ever reviewed or merged.
+func (r *Runner) finishShadow(ctx context.Context, taskID uuid.UUID) {
+	bgCtx := r.shutdownCtx
+	s := r.taskStore(taskID)
+	task, err := s.GetTask(bgCtx, taskID)
+	if err != nil {
+		logger.Runner.Error("experiment: finish shadow", "task", taskID, "error", err)
+		return
+	}
+	r.discardShadow(ctx, task)
+	for _, step := range [][2]store.TaskStatus{
+		{store.TaskStatusInProgress, store.TaskStatusWaiting},
+		{store.TaskStatusWaiting, store.TaskStatusCommitting},
+		{store.TaskStatusCommitting, store.TaskStatusDone},
+	} {
+		_ = s.UpdateTaskStatus(bgCtx, taskID, step[1])
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeStateChange,
+			store.NewStateChangeData(step[0], step[1], store.TriggerSystem, nil))
+	}
+}
+
+// measureWorktreeDiff counts the files and lines changed in each git
+// worktree since it branched from the repository's default branch,
+// including uncommitted and untracked files. The agent instruction files
+// the runner manages are excluded, as in the task diff.
+func measureWorktreeDiff(ctx context.Context, worktreePaths map[string]string) store.ExperimentDiff {
+	d := store.ExperimentDiff{MeasuredAt: time.Now().UTC()}
+	excludes := []string{":!" + prompts.ClaudeInstructionsFilename, ":!" + prompts.CodexInstructionsFilename}
+	for repoPath, wt := range worktreePaths {
+		if !gitutil.IsGitRepo(repoPath) {
+			continue
+		}
+		defBranch, err := gitutil.DefaultBranch(repoPath)
+		if err != nil {
+			continue
+		}
+		base, err := cmdexec.Git(wt, "merge-base", "HEAD", defBranch).WithContext(ctx).Output()
+		if err != nil {
+			continue
+		}
+		args := append([]string{"diff", "--numstat", base, "--", "."}, excludes...)
+		if out, err := cmdexec.Git(wt, args...).WithContext(ctx).Output(); err == nil {
+			addNumstat(&d, out)
+		}
+		lsArgs := append([]string{"ls-files", "--others", "--exclude-standard", "--", "."}, excludes...)
+		untracked, err := cmdexec.Git(wt, lsArgs...).WithContext(ctx).Output()
+		if err != nil {
+			continue
+		}
+		for file := range strings.SplitSeq(untracked, "\n") {
+			if file == "" {
+				continue
+			}
+			d.Files++
+			if data, err := os.ReadFile(filepath.Join(wt, file)); err == nil {
+				d.Added += countLines(data)
+			}
+		}
+	}
+	return d
+}
+
+// addNumstat adds the output of git diff --numstat to d. Binary files count
+// as changed files without lines.
+func addNumstat(d *store.ExperimentDiff, out string) {
+	for line := range strings.SplitSeq(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		d.Files++
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		d.Added += added
+		d.Deleted += deleted
+	}
+}
+
+// countLines returns the number of lines in data, counting a final line
+// without a trailing newline.
+func countLines(data []byte) int {
+	n := bytes.Count(data, []byte{'\n'})
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestExperimentPrompt(t *testing.T) {
+	arm := &store.Task{Experiment: &store.Experiment{Role: store.ExperimentRoleShadow, Instructions: "Write tests first."}}
+	if got := experimentPrompt(arm, "fix the bug"); got != "fix the bug\n\nWrite tests first." {
+		t.Errorf("experimentPrompt = %q", got)
+	}
+	if got := experimentPrompt(arm, ""); got != "" {
+		t.Errorf("auto-continue prompt changed to %q", got)
+	}
+	testRun := *arm
+	testRun.IsTestRun = true
+	if got := experimentPrompt(&testRun, "verify"); got != "verify" {
+		t.Errorf("test run prompt changed to %q", got)
+	}
+	if got := experimentPrompt(&store.Task{}, "fix the bug"); got != "fix the bug" {
+		t.Errorf("plain task prompt changed to %q", got)
+	}
+}
+
+func TestMeasureWorktreeDiff(t *testing.T) {
+	repo := setupTestRepo(t)
+	_, r := setupTestRunner(t, []string{repo})
+	s := r.currentStore()
+	task, err := s.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "change", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktreePaths, branchName, err := r.setupWorktrees(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.cleanupWorktrees(task.ID, worktreePaths, branchName) })
+	wt := worktreePaths[repo]
+
+	// One modified tracked file, one committed new file, one untracked file,
+	// and an instruction file that must not count.
+	if err := os.WriteFile(filepath.Join(wt, "README.md"), []byte("# Changed\nmore\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "a.go"), []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, wt, "add", "a.go")
+	gitRun(t, wt, "commit", "-m", "add a")
+	if err := os.WriteFile(filepath.Join(wt, "b.txt"), []byte("one\ntwo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "AGENTS.md"), []byte("rules\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := measureWorktreeDiff(context.Background(), worktreePaths)
+	if d.Files != 3 || d.Added != 5 || d.Deleted != 1 {
+		t.Fatalf("diff = %+v, want 3 files +5/-1", d)
+	}
+	if d.MeasuredAt.IsZero() {
+		t.Error("MeasuredAt not set")
+	}
+}
+
+func TestCommit_ShadowIsDiscarded(t *testing.T) {
+	repo := setupTestRepo(t)
+	_, r := setupTestRunner(t, []string{repo})
+	s := r.currentStore()
+	ctx := context.Background()
+
+	primary, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "change", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shadow, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "change", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTaskExperiment(ctx, shadow.ID, store.Experiment{Role: store.ExperimentRoleShadow, PeerID: primary.ID, Label: "terse"}); err != nil {
+		t.Fatal(err)
+	}
+	worktreePaths, branchName, err := r.setupWorktrees(shadow.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskWorktrees(ctx, shadow.ID, worktreePaths, branchName); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePaths[repo], "new.txt"), []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	head := gitRun(t, repo, "rev-parse", "main")
+
+	if err := r.Commit(shadow.ID, ""); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if got := gitRun(t, repo, "rev-parse", "main"); got != head {
+		t.Errorf("shadow changes were merged: main moved from %s to %s", head, got)
+	}
+	if _, err := os.Stat(worktreePaths[repo]); !os.IsNotExist(err) {
+		t.Errorf("shadow worktree not removed: %v", err)
+	}
+	got, err := s.GetTask(ctx, shadow.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got.Experiment.Diff; d.Files != 1 || d.Added != 1 {
+		t.Errorf("shadow diff = %+v, want 1 file +1", d)
+	}
+	if !hasEventContaining(t, s, primary.ID, `Experiment arm \"terse\" finished`) {
+		t.Error("primary has no experiment comparison event")
+	}
+}
+
+// hasEventContaining reports whether any of the task's events has data
+// containing substr.
+func hasEventContaining(t *testing.T, s *store.Store, id uuid.UUID, substr string) bool {
+	t.Helper()
+	events, err := s.GetEvents(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ev := range events {
+		if strings.Contains(string(ev.Data), substr) {
+			return true
+		}
+	}
+	return false
+}
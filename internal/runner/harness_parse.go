@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 
@@ -36,69 +37,88 @@ func (r *Runner) parseAgentStream(sb harness.ID, raw string) (*agentOutput, erro
 //   - if no line parses into a recognised event, it is an error, matching
 //     parseOutput's "no valid JSON object found".
 func parseHarnessOutput(h harness.Harness, raw string) (*agentOutput, error) {
-	var (
-		terminal      *harness.Event
-		sessionID     string
-		lastText      string
-		observedModel string
-		sawAnyResult  bool
-		sawAnyEvent   bool
-	)
+	p := harnessOutputParser{h: h}
 	for line := range strings.SplitSeq(raw, "\n") {
-		line = strings.TrimSpace(line)
-		if len(line) == 0 || line[0] != '{' {
-			continue
-		}
-		evt, err := h.ParseEvent([]byte(line))
-		if err != nil {
-			continue
-		}
-		if evt.Kind != harness.KindUnknown {
-			sawAnyEvent = true
-		}
-		if evt.SessionID != "" {
-			sessionID = evt.SessionID
-		}
-		// First reported model wins: the init line carries the session
-		// primary, which is the value provenance should record.
-		if observedModel == "" && evt.Model != "" {
-			observedModel = evt.Model
-		}
-		// Only assistant prose is a candidate for the final-answer fallback.
-		// Thinking blocks (KindThinking) also carry Text but are reasoning, not
-		// the conclusion, so they must never be picked as the result.
-		if evt.Kind == harness.KindAssistantText && evt.Text != "" {
-			lastText = evt.Text
-		}
-		if evt.Kind == harness.KindResult || evt.Kind == harness.KindError {
-			e := evt
-			terminal = &e
-			sawAnyResult = true
+		p.line([]byte(line))
+	}
+	return p.result()
+}
+
+// harnessOutputParser is parseHarnessOutput's accumulator, fed one line at
+// a time so a streamed turn can be parsed without holding its output.
+type harnessOutputParser struct {
+	h             harness.Harness
+	terminal      *harness.Event
+	sessionID     string
+	lastText      string
+	observedModel string
+	sawAnyResult  bool
+	sawAnyEvent   bool
+	onEvent       func(harness.Event) // optional; sees every recognised event as it is parsed
+}
+
+// line feeds one line of stdout to the parser.
+func (p *harnessOutputParser) line(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return
+	}
+	// Events keep the line as Raw, so it must outlive the caller's buffer.
+	evt, err := p.h.ParseEvent(bytes.Clone(line))
+	if err != nil {
+		return
+	}
+	if evt.Kind != harness.KindUnknown {
+		p.sawAnyEvent = true
+		if p.onEvent != nil {
+			p.onEvent(evt)
 		}
 	}
+	if evt.SessionID != "" {
+		p.sessionID = evt.SessionID
+	}
+	// First reported model wins: the init line carries the session
+	// primary, which is the value provenance should record.
+	if p.observedModel == "" && evt.Model != "" {
+		p.observedModel = evt.Model
+	}
+	// Only assistant prose is a candidate for the final-answer fallback.
+	// Thinking blocks (KindThinking) also carry Text but are reasoning, not
+	// the conclusion, so they must never be picked as the result.
+	if evt.Kind == harness.KindAssistantText && evt.Text != "" {
+		p.lastText = evt.Text
+	}
+	if evt.Kind == harness.KindResult || evt.Kind == harness.KindError {
+		p.terminal = &evt
+		p.sawAnyResult = true
+	}
+}
 
-	if !sawAnyResult {
-		if !sawAnyEvent {
+// result collapses the lines fed so far into the agent output.
+func (p *harnessOutputParser) result() (*agentOutput, error) {
+	if !p.sawAnyResult {
+		if !p.sawAnyEvent {
 			return nil, fmt.Errorf("no valid JSON object found in output")
 		}
 		// Recognised non-terminal events only (e.g. an init line with a
 		// session id but no result yet). Surface what we have so callers
 		// that tolerate a missing result still see the session id.
-		return &agentOutput{SessionID: sessionID, ObservedModel: observedModel}, nil
+		return &agentOutput{SessionID: p.sessionID, ObservedModel: p.observedModel}, nil
 	}
 
+	terminal := p.terminal
 	out := &agentOutput{
 		SessionID:     terminal.SessionID,
 		StopReason:    terminal.StopReason,
 		IsError:       terminal.Kind == harness.KindError,
 		Result:        terminal.Text,
-		ObservedModel: observedModel,
+		ObservedModel: p.observedModel,
 	}
 	if out.Result == "" {
-		out.Result = lastText
+		out.Result = p.lastText
 	}
 	if out.SessionID == "" {
-		out.SessionID = sessionID
+		out.SessionID = p.sessionID
 	}
 	if terminal.Usage != nil {
 		out.TotalCostUSD = terminal.Usage.CostUSD
@@ -111,3 +131,106 @@ func parseHarnessOutput(h harness.Harness, raw string) (*agentOutput, error) {
 	}
 	return out, nil
 }
+
+// maxStreamLineBytes caps the stdout line a stream parser holds; a longer
+// line is passed through to disk but not parsed.
+const maxStreamLineBytes = 8 << 20
+
+// streamLineKeepBytes is the largest line buffer a stream parser reuses for
+// the next line; a larger one is released.
+const streamLineKeepBytes = 64 << 10
+
+// stdoutHeadBytes is how much of stdout a stream parser keeps for error
+// messages.
+const stdoutHeadBytes = 4 << 10
+
+// streamParser is the io.Writer runAgent drains stdout into. It splits the
+// stream into lines for the harness's parser, so the agent's result is known
+// once the stream ends while memory holds at most one line. Without a
+// registered harness it falls back to buffering everything for parseOutput.
+type streamParser struct {
+	p        *harnessOutputParser // nil: raw holds the whole stream
+	raw      []byte
+	partial  []byte // the line being received
+	overlong bool   // partial exceeded maxStreamLineBytes and is skipped
+	head     []byte // the first stdoutHeadBytes of the stream
+	nonBlank bool   // a non-whitespace byte was seen
+}
+
+func newStreamParser(sb harness.ID) *streamParser {
+	sp := &streamParser{}
+	if h, ok := harness.Lookup(sb); ok {
+		sp.p = &harnessOutputParser{h: h}
+	}
+	return sp
+}
+
+// onEvent registers fn to see each recognised event while the stream is
+// written. A parser without a harness parses nothing until the end and
+// never calls it.
+func (sp *streamParser) onEvent(fn func(harness.Event)) {
+	if sp.p != nil {
+		sp.p.onEvent = fn
+	}
+}
+
+// Write implements io.Writer; it never fails.
+func (sp *streamParser) Write(b []byte) (int, error) {
+	if len(sp.head) < stdoutHeadBytes {
+		sp.head = append(sp.head, b[:min(len(b), stdoutHeadBytes-len(sp.head))]...)
+	}
+	if !sp.nonBlank && len(bytes.TrimSpace(b)) > 0 {
+		sp.nonBlank = true
+	}
+	if sp.p == nil {
+		sp.raw = append(sp.raw, b...)
+		return len(b), nil
+	}
+	rest := b
+	for len(rest) > 0 {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			sp.appendPartial(rest)
+			break
+		}
+		sp.appendPartial(rest[:i])
+		if !sp.overlong {
+			sp.p.line(sp.partial)
+		}
+		sp.partial, sp.overlong = sp.partial[:0], false
+		if cap(sp.partial) > streamLineKeepBytes {
+			sp.partial = nil // do not pin one long line's buffer for the whole turn
+		}
+		rest = rest[i+1:]
+	}
+	return len(b), nil
+}
+
+func (sp *streamParser) appendPartial(b []byte) {
+	if sp.overlong {
+		return
+	}
+	if len(sp.partial)+len(b) > maxStreamLineBytes {
+		sp.partial, sp.overlong = nil, true
+		return
+	}
+	sp.partial = append(sp.partial, b...)
+}
+
+// empty reports whether the stream held nothing but whitespace.
+func (sp *streamParser) empty() bool { return !sp.nonBlank }
+
+// headText returns the start of the stream for error messages.
+func (sp *streamParser) headText() string { return strings.TrimSpace(string(sp.head)) }
+
+// result parses the final, unterminated line and returns the agent output.
+func (sp *streamParser) result() (*agentOutput, error) {
+	if sp.p == nil {
+		return parseOutput(strings.TrimSpace(string(sp.raw)))
+	}
+	if !sp.overlong && len(sp.partial) > 0 {
+		sp.p.line(sp.partial)
+		sp.partial = sp.partial[:0]
+	}
+	return sp.p.result()
+}
@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/harness"
+)
+
+// claudeStreamFixture is a small claude turn: an init line, assistant text,
+// and the terminal result.
+const claudeStreamFixture = `{"type":"system","subtype":"init","session_id":"sess-1","model":"claude-sonnet-4-6"}
+{"type":"assistant","session_id":"sess-1","message":{"content":[{"type":"text","text":"working"}]}}
+{"type":"result","subtype":"success","session_id":"sess-1","result":"done","stop_reason":"end_turn","is_error":false,"total_cost_usd":0.5,"usage":{"input_tokens":10,"output_tokens":20}}`
+
+// TestStreamParser_MatchesParseHarnessOutput verifies that feeding stdout in
+// arbitrary chunks yields the same output as parsing it in one piece, with
+// and without a trailing newline.
+func TestStreamParser_MatchesParseHarnessOutput(t *testing.T) {
+	h, _ := harness.Lookup(harness.Claude)
+	for _, raw := range []string{claudeStreamFixture, claudeStreamFixture + "\n"} {
+		want, err := parseHarnessOutput(h, raw)
+		if err != nil {
+			t.Fatalf("parseHarnessOutput: %v", err)
+		}
+		for _, size := range []int{1, 5, 64, len(raw)} {
+			sp := newStreamParser(harness.Claude)
+			for chunk := range slices.Chunk([]byte(raw), size) {
+				_, _ = sp.Write(chunk)
+			}
+			got, err := sp.result()
+			if err != nil {
+				t.Fatalf("chunk %d: result: %v", size, err)
+			}
+			if *got != *want {
+				t.Fatalf("chunk %d: got %+v, want %+v", size, *got, *want)
+			}
+			if sp.empty() {
+				t.Fatalf("chunk %d: empty() = true for a non-empty stream", size)
+			}
+		}
+	}
+}
+
+// TestStreamParser_SkipsOverlongLine verifies that a line longer than
+// maxStreamLineBytes is dropped instead of held, and that the lines around
+// it still parse.
+func TestStreamParser_SkipsOverlongLine(t *testing.T) {
+	lines := strings.SplitN(claudeStreamFixture, "\n", 2)
+	sp := newStreamParser(harness.Claude)
+	_, _ = sp.Write([]byte(lines[0] + "\n"))
+	huge := `{"type":"assistant","message":{"content":[{"type":"text","text":"` + strings.Repeat("x", maxStreamLineBytes) + `"}]}}` + "\n"
+	for chunk := range slices.Chunk([]byte(huge), 1<<20) {
+		_, _ = sp.Write(chunk)
+	}
+	if cap(sp.partial) > streamLineKeepBytes {
+		t.Fatalf("still holds a %d-byte buffer after an overlong line", cap(sp.partial))
+	}
+	_, _ = sp.Write([]byte(lines[1]))
+	got, err := sp.result()
+	if err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if got.Result != "done" || got.SessionID != "sess-1" {
+		t.Fatalf("got %+v, want the terminal result after the overlong line", *got)
+	}
+}
+
+// TestStreamParser_EmptyAndHead verifies the whitespace-only check and the
+// head kept for error messages.
+func TestStreamParser_EmptyAndHead(t *testing.T) {
+	sp := newStreamParser(harness.Claude)
+	_, _ = sp.Write([]byte("  \n\t\n"))
+	if !sp.empty() {
+		t.Fatal("empty() = false for whitespace-only output")
+	}
+	_, _ = sp.Write([]byte("not json " + strings.Repeat("y", 2*stdoutHeadBytes)))
+	if sp.empty() {
+		t.Fatal("empty() = true after non-whitespace output")
+	}
+	if head := sp.headText(); !strings.HasPrefix(head, "not json") || len(head) > stdoutHeadBytes {
+		t.Fatalf("headText() = %d bytes starting %q", len(head), head[:min(len(head), 10)])
+	}
+	if _, err := sp.result(); err == nil {
+		t.Fatal("result() accepted output with no JSON events")
+	}
+}
@@ -0,0 +1,214 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// IncrementalRebaseBackground launches IncrementalRebase in a background
+// goroutine tracked by backgroundWg. The optional onDone callbacks run after
+// IncrementalRebase returns.
+func (r *Runner) IncrementalRebaseBackground(taskID uuid.UUID, sessionID string, prevStatus store.TaskStatus, onDone ...func()) {
+	r.taskBackground("rebase", taskID, func() {
+		r.IncrementalRebase(taskID, sessionID, prevStatus)
+		for _, fn := range onDone {
+			fn()
+		}
+	})
+}
+
+// IncrementalRebase brings the task's worktrees up to date with the default
+// branch one upstream checkpoint at a time (see gitutil.RebaseCheckpoints)
+// instead of in a single jump. Each step that conflicts runs the conflict
+// resolver against that checkpoint only, so the agent sees a handful of
+// upstream commits per conflict rather than the whole backlog at merge time.
+//
+// Steps that succeed are kept: when a step cannot be resolved automatically
+// the task stays in_progress and Run() hands the remaining conflict to the
+// agent with the checkpoint hash in the prompt, so already-integrated
+// checkpoints are not redone. On success the task returns to prevStatus
+// (failed tasks return to waiting, matching SyncWorktrees).
+func (r *Runner) IncrementalRebase(taskID uuid.UUID, sessionID string, prevStatus store.TaskStatus) {
+	r.StopTaskWorker(taskID)
+
+	bgCtx := r.shutdownCtx
+	s := r.taskStore(taskID)
+	restoreStatus := prevStatus
+	if restoreStatus == store.TaskStatusFailed {
+		restoreStatus = store.TaskStatusWaiting
+	}
+
+	statusSet := false
+	defer func() {
+		if p := recover(); p != nil {
+			logger.Runner.Error("incremental rebase panic", "task", taskID, "panic", p)
+		}
+		if !statusSet {
+			_ = s.ForceUpdateTaskStatus(bgCtx, taskID, restoreStatus)
+			_ = s.InsertEvent(bgCtx, taskID, store.EventTypeStateChange,
+				store.NewStateChangeData(store.TaskStatusInProgress, restoreStatus, store.TriggerSystem, nil))
+		}
+	}()
+
+	task, err := s.GetTask(bgCtx, taskID)
+	if err != nil {
+		logger.Runner.Error("incremental rebase: get task", "task", taskID, "error", err)
+		return
+	}
+
+	rebased := false
+	for repoPath, worktreePath := range task.WorktreePaths {
+		repoName := filepath.Base(repoPath)
+		if _, statErr := os.Stat(worktreePath); statErr != nil || !gitutil.IsGitRepo(repoPath) {
+			continue
+		}
+		if fetchErr := gitutil.FetchOrigin(repoPath); fetchErr != nil {
+			logger.Runner.Warn("incremental rebase: git fetch failed, continuing with local refs",
+				"task", taskID, "repo", repoPath, "error", fetchErr)
+		}
+		checkpoints, err := gitutil.RebaseCheckpoints(repoPath, worktreePath, constants.MaxIncrementalRebaseSteps)
+		if err != nil {
+			statusSet = true
+			r.failSync(bgCtx, taskID, sessionID, task.Turns, fmt.Sprintf("list rebase checkpoints for %s: %v", repoName, err))
+			return
+		}
+		if len(checkpoints) == 0 {
+			_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
+				"result": fmt.Sprintf("%s is already up to date with the default branch.", repoName),
+			})
+			continue
+		}
+
+		stashed := gitutil.StashIfDirty(worktreePath)
+		for step, checkpoint := range checkpoints {
+			short := checkpoint[:min(len(checkpoint), 8)]
+			_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+				"phase":      "incremental_rebase",
+				"repo":       repoName,
+				"step":       step + 1,
+				"steps":      len(checkpoints),
+				"checkpoint": checkpoint,
+				"result":     fmt.Sprintf("Rebasing %s onto %s (step %d/%d)...", repoName, short, step+1, len(checkpoints)),
+			})
+			rebaseErr := r.rebaseOntoCheckpoint(taskID, repoPath, worktreePath, sessionID, checkpoint)
+			if rebaseErr == nil {
+				continue
+			}
+			if !isConflictError(rebaseErr) && !conflictResolutionFailed(rebaseErr) {
+				if stashed {
+					_ = gitutil.StashPop(worktreePath)
+				}
+				statusSet = true
+				r.failSync(bgCtx, taskID, sessionID, task.Turns,
+					fmt.Sprintf("incremental rebase of %s onto %s: %v", repoName, short, rebaseErr))
+				return
+			}
+
+			// The resolver could not settle this checkpoint. Everything before
+			// it is already integrated; hand the single remaining step to the
+			// agent so it resolves a narrow slice of upstream history.
+			statusSet = true
+			_ = s.UpdateTaskTestRun(bgCtx, taskID, false, "")
+			_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+				"phase":      "incremental_rebase",
+				"status":     "handoff",
+				"repo":       repoName,
+				"step":       step + 1,
+				"steps":      len(checkpoints),
+				"checkpoint": checkpoint,
+				"result": fmt.Sprintf("Step %d/%d of the %s rebase could not be resolved automatically — handing off to agent.",
+					step+1, len(checkpoints), repoName),
+			})
+			prompt := fmt.Sprintf(
+				"The worktree %s is being rebased onto the default branch in small steps. "+
+					"%d of %d step(s) are already applied. Rebasing onto upstream commit %s "+
+					"conflicts with your changes, and the rebase was aborted so the worktree "+
+					"is clean on the task branch.\n\n"+
+					"Please integrate this step:\n"+
+					"1. Run `git log HEAD..%s` to see the upstream commits in this step\n"+
+					"2. Run `git rebase %s` and resolve each conflict, keeping both the upstream intent and your changes\n"+
+					"3. Run `git rebase --continue` until the rebase completes\n\n"+
+					"Later upstream commits will be integrated by the next sync.",
+				filepath.Base(worktreePath), step, len(checkpoints), short, checkpoint, checkpoint,
+			)
+			if stashed {
+				prompt += "\n\nYour uncommitted changes from before the rebase are saved in the git stash; " +
+					"restore them with `git stash pop` once the rebase is complete."
+			}
+			r.Run(taskID, prompt, sessionID, false)
+			return
+		}
+		rebased = true
+		if stashed {
+			if popErr := gitutil.StashPop(worktreePath); popErr != nil {
+				logger.Runner.Warn("incremental rebase: stash pop failed", "task", taskID, "repo", repoPath, "error", popErr)
+				_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
+					"result": fmt.Sprintf("Rebase of %s succeeded, but uncommitted changes could not be restored; they remain in `git stash list`.", repoName),
+				})
+			}
+		}
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
+			"result": fmt.Sprintf("Rebased %s onto the default branch in %d step(s).", repoName, len(checkpoints)),
+		})
+	}
+
+	if rebased {
+		// The task branch now contains upstream changes the last test run
+		// did not see; require a fresh verification before auto-submit.
+		_ = s.UpdateTaskTestRun(bgCtx, taskID, false, "")
+	}
+	statusSet = true
+	_ = s.ForceUpdateTaskStatus(bgCtx, taskID, restoreStatus)
+	_ = s.InsertEvent(bgCtx, taskID, store.EventTypeStateChange,
+		store.NewStateChangeData(store.TaskStatusInProgress, restoreStatus, store.TriggerSystem, nil))
+	logger.Runner.Info("incremental rebase completed", "task", taskID)
+}
+
+// errConflictResolutionFailed wraps resolver failures inside
+// rebaseOntoCheckpoint so the caller can distinguish "the resolver gave up"
+// (hand off to the agent) from plain git errors (fail the task).
+type errConflictResolutionFailed struct{ err error }
+
+func (e *errConflictResolutionFailed) Error() string {
+	return "conflict resolution failed: " + e.err.Error()
+}
+
+func (e *errConflictResolutionFailed) Unwrap() error { return e.err }
+
+// conflictResolutionFailed reports whether err came from an exhausted or
+// failing conflict resolver in rebaseOntoCheckpoint.
+func conflictResolutionFailed(err error) bool {
+	var target *errConflictResolutionFailed
+	return errors.As(err, &target)
+}
+
+// rebaseOntoCheckpoint rebases worktreePath onto a single checkpoint,
+// invoking the conflict resolver between attempts exactly like the sync path
+// (up to constants.MaxRebaseRetries). The resolver is told to rebase onto the
+// checkpoint hash rather than the default branch name, which keeps the
+// conflict it sees limited to this step.
+func (r *Runner) rebaseOntoCheckpoint(taskID uuid.UUID, repoPath, worktreePath, sessionID, checkpoint string) error {
+	var rebaseErr error
+	for attempt := 1; attempt <= constants.MaxRebaseRetries; attempt++ {
+		rebaseErr = gitutil.RebaseOnto(worktreePath, checkpoint)
+		if rebaseErr == nil || !isConflictError(rebaseErr) {
+			return rebaseErr
+		}
+		if attempt == constants.MaxRebaseRetries {
+			break
+		}
+		if resolveErr := r.resolveConflicts(r.shutdownCtx, taskID, repoPath, worktreePath, sessionID, checkpoint, conflictedFiles(rebaseErr),
+			ConflictResolverTriggerIncremental, attempt, constants.MaxRebaseRetries); resolveErr != nil {
+			return &errConflictResolutionFailed{err: resolveErr}
+		}
+	}
+	return rebaseErr
+}
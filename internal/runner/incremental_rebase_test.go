@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// TestIncrementalRebaseBehindMain verifies that a worktree several commits
+// behind main is rebased step by step, every upstream file lands in the
+// worktree, and the task returns to its previous status.
+func TestIncrementalRebaseBehindMain(t *testing.T) {
+	repo := setupTestRepo(t)
+	s, runner := setupTestRunner(t, []string{repo})
+	ctx := context.Background()
+
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "incremental rebase test", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, br, err := runner.setupWorktrees(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { runner.cleanupWorktrees(task.ID, wt, br) })
+
+	if err := s.UpdateTaskWorktrees(ctx, task.ID, wt, br); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusWaiting); err != nil {
+		t.Fatal(err)
+	}
+
+	// Advance main by three commits so the rebase takes multiple steps.
+	for i := range 3 {
+		name := fmt.Sprintf("upstream%d.txt", i)
+		if err := os.WriteFile(filepath.Join(repo, name), []byte("upstream\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		gitRun(t, repo, "add", ".")
+		gitRun(t, repo, "commit", "-m", "upstream "+name)
+	}
+
+	runner.IncrementalRebase(task.ID, "", store.TaskStatusWaiting)
+
+	updated, _ := s.GetTask(ctx, task.ID)
+	if updated.Status != store.TaskStatusWaiting {
+		t.Fatalf("expected status=waiting after incremental rebase, got %q", updated.Status)
+	}
+	for i := range 3 {
+		if _, err := os.Stat(filepath.Join(wt[repo], fmt.Sprintf("upstream%d.txt", i))); err != nil {
+			t.Errorf("upstream%d.txt should be in worktree after incremental rebase: %v", i, err)
+		}
+	}
+
+	events, err := s.GetEvents(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := 0
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeSystem && strings.Contains(string(ev.Data), `"phase":"incremental_rebase"`) {
+			steps++
+		}
+	}
+	if steps != 3 {
+		t.Errorf("expected 3 incremental_rebase step events, got %d", steps)
+	}
+}
+
+// TestIncrementalRebaseFailedTaskReturnsToWaiting verifies that a failed task
+// already up to date with main is moved to waiting, matching SyncWorktrees.
+func TestIncrementalRebaseFailedTaskReturnsToWaiting(t *testing.T) {
+	repo := setupTestRepo(t)
+	s, runner := setupTestRunner(t, []string{repo})
+	ctx := context.Background()
+
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "up to date", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, br, err := runner.setupWorktrees(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { runner.cleanupWorktrees(task.ID, wt, br) })
+	if err := s.UpdateTaskWorktrees(ctx, task.ID, wt, br); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+
+	runner.IncrementalRebase(task.ID, "", store.TaskStatusFailed)
+
+	updated, _ := s.GetTask(ctx, task.ID)
+	if updated.Status != store.TaskStatusWaiting {
+		t.Fatalf("expected status=waiting, got %q", updated.Status)
+	}
+}
@@ -0,0 +1,218 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/executor"
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// Errors of SendInput.
+var (
+	ErrNoInteractiveTurn = errors.New("task has no interactive turn running")
+	ErrInvalidInput      = errors.New("invalid input")
+)
+
+// maxInputBytes bounds one line of text input.
+const maxInputBytes = 2000
+
+// maxInputRequestsPerTurn bounds the input_request events one turn records,
+// so a chatty process cannot flood the event log.
+const maxInputRequestsPerTurn = 20
+
+// maxPromptLineBytes is the longest line still considered a prompt.
+const maxPromptLineBytes = 300
+
+// taskInput is the open stdin of a task's running interactive turn.
+type taskInput struct {
+	w   executor.InputWriter
+	enc harness.InputEncoder
+}
+
+// registerInput records the input of an interactive launch so SendInput can
+// reach it. Launches without input (non-interactive, or a harness without
+// the capability) register nothing.
+func (r *Runner) registerInput(taskID uuid.UUID, sb harness.ID, handle executor.Handle) {
+	p, ok := handle.(executor.InputProvider)
+	if !ok {
+		return
+	}
+	w := p.Input()
+	h, _ := harness.Lookup(sb)
+	enc, ok := h.(harness.InputEncoder)
+	if w == nil || !ok {
+		return
+	}
+	r.taskInputs.Store(taskID, taskInput{w: w, enc: enc})
+}
+
+// HasInteractiveTurn reports whether taskID has a running turn that
+// accepts input.
+func (r *Runner) HasInteractiveTurn(taskID uuid.UUID) bool {
+	_, ok := r.taskInputs.Load(taskID)
+	return ok
+}
+
+// SendInput answers the running interactive turn of taskID: approve sends
+// "y", deny sends "n", and text sends one line of free text. The input is
+// recorded as an input event. It returns ErrNoInteractiveTurn when no
+// interactive turn is running and ErrInvalidInput for an unknown action or
+// text that is empty, too long, or spans lines.
+func (r *Runner) SendInput(ctx context.Context, taskID uuid.UUID, action store.InputAction, text string) error {
+	line, err := inputLine(action, text)
+	if err != nil {
+		return err
+	}
+	in, ok := r.taskInputs.Load(taskID)
+	if !ok {
+		return ErrNoInteractiveTurn
+	}
+	if err := in.w.WriteInput(in.enc.EncodeInput(line)); err != nil {
+		if errors.Is(err, executor.ErrInputClosed) {
+			return ErrNoInteractiveTurn
+		}
+		return err
+	}
+	data := store.InputData{Action: action}
+	if action == store.InputText {
+		data.Text = line
+	}
+	_ = r.taskStore(taskID).InsertEvent(ctx, taskID, store.EventTypeInput, data)
+	return nil
+}
+
+// inputLine maps an input action to the line sent to the agent.
+func inputLine(action store.InputAction, text string) (string, error) {
+	switch action {
+	case store.InputApprove:
+		return "y", nil
+	case store.InputDeny:
+		return "n", nil
+	case store.InputText:
+	default:
+		return "", fmt.Errorf("%w: unknown action %q", ErrInvalidInput, action)
+	}
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "":
+		return "", fmt.Errorf("%w: text is empty", ErrInvalidInput)
+	case len(text) > maxInputBytes:
+		return "", fmt.Errorf("%w: text exceeds %d bytes", ErrInvalidInput, maxInputBytes)
+	case strings.IndexFunc(text, unicode.IsControl) >= 0:
+		return "", fmt.Errorf("%w: text must be a single line without control characters", ErrInvalidInput)
+	}
+	return text, nil
+}
+
+// inputPromptRe matches lines that wait for an answer: "press enter"
+// anywhere, and line tails offering a yes/no choice, asking to confirm,
+// or asking for a password.
+var inputPromptRe = regexp.MustCompile(`(?i)(press (enter|return)\b|(\(y(es)?/n(o)?\)|\[y(es)?/n(o)?\]|\b(continue|proceed|overwrite|allow|approve|confirm)\b[^.!]*\?|(password|passphrase)[^:]*:)\s*$)`)
+
+// looksLikeInputPrompt reports whether a line of agent output is a prompt
+// for input.
+func looksLikeInputPrompt(line string) bool {
+	line = strings.TrimSpace(line)
+	return line != "" && len(line) <= maxPromptLineBytes && inputPromptRe.MatchString(line)
+}
+
+// inputPromptWatcher records input_request events for the prompts an
+// interactive turn prints: on stderr (tool and CLI prompts) and as the last
+// line of an assistant message (the agent asking before it acts).
+type inputPromptWatcher struct {
+	r      *Runner
+	taskID uuid.UUID
+
+	mu      sync.Mutex
+	partial []byte
+	last    string
+	count   int
+}
+
+func (r *Runner) newInputPromptWatcher(taskID uuid.UUID) *inputPromptWatcher {
+	return &inputPromptWatcher{r: r, taskID: taskID}
+}
+
+// writer returns w as a stderr tap, or nil for a nil watcher.
+func (w *inputPromptWatcher) writer() io.Writer {
+	if w == nil {
+		return nil
+	}
+	return w
+}
+
+// eventFunc returns w's stdout event hook, or nil for a nil watcher.
+func (w *inputPromptWatcher) eventFunc() func(harness.Event) {
+	if w == nil {
+		return nil
+	}
+	return w.event
+}
+
+// Write implements io.Writer over the stderr stream; it never fails.
+func (w *inputPromptWatcher) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	rest := b
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		w.checkLocked(string(append(w.partial, rest[:i]...)))
+		w.partial = w.partial[:0]
+		rest = rest[i+1:]
+	}
+	if len(w.partial)+len(rest) <= maxPromptLineBytes {
+		w.partial = append(w.partial, rest...)
+	} else {
+		w.partial = w.partial[:0]
+	}
+	// A prompt usually waits without a trailing newline.
+	if len(w.partial) > 0 {
+		w.checkLocked(string(w.partial))
+	}
+	return len(b), nil
+}
+
+// event inspects a parsed stdout event for an assistant message that ends
+// in a prompt.
+func (w *inputPromptWatcher) event(evt harness.Event) {
+	if evt.Kind != harness.KindAssistantText || evt.Text == "" {
+		return
+	}
+	text := strings.TrimRight(evt.Text, "\n")
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		text = text[i+1:]
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.checkLocked(text)
+}
+
+func (w *inputPromptWatcher) checkLocked(line string) {
+	line = strings.TrimSpace(line)
+	if w.count >= maxInputRequestsPerTurn || !looksLikeInputPrompt(line) {
+		return
+	}
+	// A prompt seen while still being written matches again once complete.
+	if w.last != "" && strings.HasPrefix(line, w.last) {
+		w.last = line
+		return
+	}
+	w.last = line
+	w.count++
+	_ = w.r.taskStore(w.taskID).InsertEvent(context.Background(), w.taskID, store.EventTypeInputRequest,
+		store.InputRequestData{Prompt: line})
+}
@@ -0,0 +1,203 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestInputLine(t *testing.T) {
+	cases := []struct {
+		action store.InputAction
+		text   string
+		want   string
+		ok     bool
+	}{
+		{store.InputApprove, "", "y", true},
+		{store.InputDeny, "ignored", "n", true},
+		{store.InputText, "  main  ", "main", true},
+		{store.InputText, "", "", false},
+		{store.InputText, "two\nlines", "", false},
+		{store.InputText, strings.Repeat("x", maxInputBytes+1), "", false},
+		{"shell", "rm -rf /", "", false},
+	}
+	for _, tc := range cases {
+		got, err := inputLine(tc.action, tc.text)
+		if tc.ok != (err == nil) || got != tc.want {
+			t.Errorf("inputLine(%q, %q) = %q, %v", tc.action, tc.text, got, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("inputLine(%q, %q) error %v is not ErrInvalidInput", tc.action, tc.text, err)
+		}
+	}
+}
+
+func TestLooksLikeInputPrompt(t *testing.T) {
+	for _, line := range []string{
+		"Overwrite package.json? (y/N)",
+		"Proceed with installation? [Y/n] ",
+		"Do you want to continue?",
+		"Enter passphrase for key '/root/.ssh/id_ed25519':",
+		"Press ENTER to continue",
+	} {
+		if !looksLikeInputPrompt(line) {
+			t.Errorf("looksLikeInputPrompt(%q) = false", line)
+		}
+	}
+	for _, line := range []string{
+		"",
+		"Installing dependencies...",
+		"Why does the build fail? It is the linker.",
+		strings.Repeat("x", maxPromptLineBytes) + " continue?",
+	} {
+		if looksLikeInputPrompt(line) {
+			t.Errorf("looksLikeInputPrompt(%q) = true", line)
+		}
+	}
+}
+
+// fakeInput records the input written to it.
+type fakeInput struct {
+	written []string
+	closed  bool
+}
+
+func (f *fakeInput) WriteInput(p []byte) error { f.written = append(f.written, string(p)); return nil }
+func (f *fakeInput) CloseInput() error         { f.closed = true; return nil }
+
+func TestSendInput(t *testing.T) {
+	s, r := setupRunnerWithCmd(t, nil, "echo")
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p", InteractiveInput: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SendInput(ctx, task.ID, store.InputApprove, ""); !errors.Is(err, ErrNoInteractiveTurn) {
+		t.Fatalf("SendInput without a turn = %v, want ErrNoInteractiveTurn", err)
+	}
+
+	in := &fakeInput{}
+	h, _ := harness.Lookup(harness.Claude)
+	r.taskInputs.Store(task.ID, taskInput{w: in, enc: h.(harness.InputEncoder)})
+	if err := r.SendInput(ctx, task.ID, store.InputText, "use the main branch"); err != nil {
+		t.Fatalf("SendInput: %v", err)
+	}
+	if len(in.written) != 1 || !strings.Contains(in.written[0], `"content":"use the main branch"`) {
+		t.Fatalf("written = %q, want one stream-json user message", in.written)
+	}
+	if err := r.SendInput(ctx, task.ID, store.InputText, "a\x1b[2Jb"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("control characters = %v, want ErrInvalidInput", err)
+	}
+	events, _ := s.GetEvents(ctx, task.ID)
+	if n := countEvents(events, store.EventTypeInput); n != 1 {
+		t.Errorf("input events = %d, want 1", n)
+	}
+}
+
+func TestInputPromptWatcher(t *testing.T) {
+	s, r := setupRunnerWithCmd(t, nil, "echo")
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := r.newInputPromptWatcher(task.ID)
+	// A prompt waiting without a newline, then completed and repeated,
+	// counts once.
+	for _, chunk := range []string{"npm WARN old lockfile\nOverwrite? (y/N) ", "\nOverwrite? (y/N)\n"} {
+		_, _ = w.Write([]byte(chunk))
+	}
+	w.event(harness.Event{Kind: harness.KindAssistantText, Text: "I will drop the table.\nShall I proceed?"})
+	w.event(harness.Event{Kind: harness.KindThinking, Text: "Continue?"})
+
+	events, _ := s.GetEvents(ctx, task.ID)
+	var prompts []string
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeInputRequest {
+			prompts = append(prompts, string(ev.Data))
+		}
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[0], "Overwrite? (y/N)") || !strings.Contains(prompts[1], "Shall I proceed?") {
+		t.Fatalf("input_request events = %q", prompts)
+	}
+}
+
+func countEvents(events []store.TaskEvent, typ store.EventType) int {
+	n := 0
+	for _, ev := range events {
+		if ev.EventType == typ {
+			n++
+		}
+	}
+	return n
+}
+
+// TestRunInteractiveTurn runs a fake agent that prompts on stderr and waits
+// for an answer on its open stdin, answers it through SendInput, and checks
+// the turn completes with the answer.
+func TestRunInteractiveTurn(t *testing.T) {
+	repo := setupTestRepo(t)
+	script := filepath.Join(t.TempDir(), "claude")
+	body := `#!/bin/sh
+[ "$1" = "--help" ] && exit 0
+read -r first
+echo "Overwrite config? (y/n)" >&2
+read -r answer
+case "$answer" in
+*'"content":"y"'*) result=approved ;;
+*) result=denied ;;
+esac
+printf '{"result":"%s","session_id":"s1","stop_reason":"end_turn","is_error":false,"total_cost_usd":0.001}\n' "$result"
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s, r := setupRunnerWithCmd(t, []string{repo}, script)
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "configure", Timeout: 5, InteractiveInput: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskStatus(ctx, task.ID, store.TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(task.ID, "configure", "", false)
+	}()
+
+	deadline := time.Now().Add(20 * time.Second)
+	for {
+		events, _ := s.GetEvents(ctx, task.ID)
+		if countEvents(events, store.EventTypeInputRequest) > 0 && r.HasInteractiveTurn(task.ID) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no input_request before the deadline")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err := r.SendInput(ctx, task.ID, store.InputApprove, ""); err != nil {
+		t.Fatalf("SendInput: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		t.Fatal("interactive turn did not finish after the answer")
+	}
+	updated, _ := s.GetTask(ctx, task.ID)
+	if updated.Result == nil || *updated.Result != "approved" {
+		t.Fatalf("result = %v, want approved", updated.Result)
+	}
+	if r.HasInteractiveTurn(task.ID) {
+		t.Error("input still registered after the turn")
+	}
+}
@@ -24,17 +24,24 @@ type Interface interface {
 	RunBackground(taskID uuid.UUID, prompt, sessionID string, resumedFromWaiting bool)
 	Commit(taskID uuid.UUID, sessionID string) error
 	SyncWorktreesBackground(taskID uuid.UUID, sessionID string, prevStatus store.TaskStatus, onDone ...func())
+	IncrementalRebaseBackground(taskID uuid.UUID, sessionID string, prevStatus store.TaskStatus, onDone ...func())
 
 	// Worktree management.
 	EnsureTaskWorktrees(taskID uuid.UUID, existing map[string]string, branchName string) (map[string]string, string, error)
+	ForkTaskWorktrees(forkID uuid.UUID, parentWorktrees map[string]string) (map[string]string, string, error)
 	CleanupWorktrees(taskID uuid.UUID, worktreePaths map[string]string, branchName string)
 	PruneUnknownWorktrees()
 
+	// Merge queue: per-repo holders and waiters of the rebase+merge lock.
+	MergeQueue() []RepoMergeQueue
+
 	// Container management.
 	ListContainers() ([]executor.ContainerInfo, error)
 	ContainerName(taskID uuid.UUID) string
 	TaskLogReader(taskID uuid.UUID) *livelog.Reader
 	KillContainer(taskID uuid.UUID)
+	// SendInput answers the prompt of a task's running interactive turn.
+	SendInput(ctx context.Context, taskID uuid.UUID, action store.InputAction, text string) error
 	StopTaskWorker(taskID uuid.UUID)
 	WorkerStats() executor.WorkerStatsInfo
 
@@ -51,6 +58,7 @@ type Interface interface {
 	// Title & oversight generation.
 	GenerateTitleBackground(taskID uuid.UUID, prompt string)
 	GenerateOversight(taskID uuid.UUID)
+	LocalizeSummaryBackground(taskID uuid.UUID)
 	GenerateBoardManifest(ctx context.Context, selfTaskID uuid.UUID, mountWorktrees bool) (*BoardManifest, error)
 
 	// Commit-message generation (task-free flavor). Used by callers that do
@@ -62,6 +70,10 @@ type Interface interface {
 	// verdict. Gated behind WALLFACER_DRIFT_TESTER at the call site.
 	AssessDrift(ctx context.Context, specBody string, affects, changedFiles []string, diff string) (spec.DriftVerdict, error)
 
+	// EstimateTask runs a one-shot agent that predicts a task's effort and
+	// risk before it runs and stores the estimate on the task.
+	EstimateTask(ctx context.Context, taskID uuid.UUID) (store.TaskEstimate, error)
+
 	// RunCriticRound runs a one-shot stateless agent invocation for an review
 	// critic turn in the given working directory (cwd), so the critic can read
 	// the full codebase rather than only the diff patch. cwd may be empty for a
@@ -78,6 +90,12 @@ type Interface interface {
 	// that do not have a task ID in scope, e.g. the plan commit pipeline.
 	MaybeAutoPushWorkspace(ctx context.Context, ws string)
 
+	// Agent capability probe. CheckAgentAvailable gates task starts on the
+	// latest probe result.
+	ProbeAgent(ctx context.Context) AgentProbe
+	AgentProbeStatus() (AgentProbe, bool)
+	CheckAgentAvailable(task *store.Task) error
+
 	// Host Codex auth.
 	HostCodexAuthStatus(now time.Time) (bool, string)
 	CodexAuthPath() string
@@ -91,6 +109,7 @@ type Interface interface {
 	WorktreesDir() string
 	TmpDir() string
 	EnvFile() string
+	ReloadConfig() error
 	Prompts() *prompts.Manager
 	WorkspaceManager() *workspace.Manager
 
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"maps"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/executor"
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// secretEnvMarkers are the substrings of an environment variable name that
+// mark its value as a secret an invocation record must not keep.
+var secretEnvMarkers = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL", "_KEY", "AUTH"}
+
+// redactEnv returns a copy of env with secret values replaced by
+// store.RedactedValue: variables named like a secret, and URLs carrying a
+// password (an authenticated proxy).
+func redactEnv(env map[string]string) map[string]string {
+	if len(env) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		out[k] = v
+		upper := strings.ToUpper(k)
+		for _, m := range secretEnvMarkers {
+			if strings.Contains(upper, m) {
+				out[k] = store.RedactedValue
+				break
+			}
+		}
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				out[k] = store.RedactedValue
+			}
+		}
+	}
+	return out
+}
+
+// newInvocation records the launch of spec for role on sb. The turn is the
+// one opts streams output to, or 0 outside the turn loop.
+func newInvocation(role AgentRole, activity string, sb harness.ID, spec executor.ContainerSpec, opts runAgentOpts) store.Invocation {
+	inv := store.Invocation{
+		Role:        role.Slug,
+		Activity:    store.SandboxActivity(activity),
+		Harness:     string(sb),
+		Name:        spec.Name,
+		Cmd:         append([]string(nil), spec.Cmd...),
+		Env:         redactEnv(spec.Env),
+		EnvFile:     spec.EnvFile,
+		WorkDir:     spec.WorkDir,
+		Arch:        spec.Arch,
+		Interactive: spec.Interactive,
+		Worktrees:   maps.Clone(opts.WorktreeOverrides),
+		BoardDir:    opts.BoardDir,
+		StartedAt:   time.Now(),
+	}
+	if opts.Output != nil {
+		inv.Turn = opts.Output.Turn()
+	}
+	return inv
+}
+
+// saveInvocation completes inv with how the launch ended, from the exit
+// metadata when the process ran or launchErr when it never started, and
+// appends it to the task's invocation log.
+func (r *Runner) saveInvocation(taskID uuid.UUID, inv store.Invocation, info *executor.ExitInfo, launchErr error) {
+	if info != nil {
+		inv.Binary = info.Binary
+		inv.BinaryDigest = info.BinaryDigest
+		code := info.ExitCode
+		inv.ExitCode = &code
+	}
+	if launchErr != nil {
+		inv.Error = launchErr.Error()
+	}
+	if err := r.taskStore(taskID).SaveInvocation(taskID, inv); err != nil {
+		logger.Runner.Warn("save invocation", "task", taskID, "role", inv.Role, "error", err)
+	}
+}
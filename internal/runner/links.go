@@ -0,0 +1,21 @@
+package runner
+
+import (
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// linksPrompt appends the task's external links (tickets, designs, docs,
+// pull requests) to a fresh prompt as optional references the agent may
+// fetch. Test runs, empty (auto-continue) prompts, and tasks without links
+// are returned unchanged.
+func (r *Runner) linksPrompt(task *store.Task, prompt string) string {
+	if len(task.Links) == 0 || task.IsTestRun || prompt == "" {
+		return prompt
+	}
+	links := make([]prompts.TaskLink, len(task.Links))
+	for i, l := range task.Links {
+		links[i] = prompts.TaskLink{Type: string(l.Type), URL: l.URL, Title: l.Title}
+	}
+	return r.promptsMgr.TaskLinks(prompts.TaskLinksData{Prompt: prompt, Links: links})
+}
@@ -0,0 +1,29 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestLinksPrompt(t *testing.T) {
+	_, r := setupTestRunner(t, nil)
+	task := &store.Task{Links: []store.TaskLink{{Type: store.TaskLinkPR, URL: "https://git.example.com/app/pull/3", Title: "Earlier attempt"}}}
+
+	got := r.linksPrompt(task, "fix the bug")
+	if !strings.HasPrefix(got, "fix the bug") || !strings.Contains(got, "- pr: Earlier attempt <https://git.example.com/app/pull/3>") {
+		t.Errorf("linksPrompt = %q", got)
+	}
+	if got := r.linksPrompt(task, ""); got != "" {
+		t.Errorf("auto-continue prompt changed to %q", got)
+	}
+	testRun := *task
+	testRun.IsTestRun = true
+	if got := r.linksPrompt(&testRun, "verify"); got != "verify" {
+		t.Errorf("test run prompt changed to %q", got)
+	}
+	if got := r.linksPrompt(&store.Task{}, "fix the bug"); got != "fix the bug" {
+		t.Errorf("plain task prompt changed to %q", got)
+	}
+}
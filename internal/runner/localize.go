@@ -0,0 +1,131 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/agents"
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// localizeMaxSummaryBytes caps the final summary sent for translation.
+const localizeMaxSummaryBytes = 16 << 10
+
+// localizedText is the JSON shape the localize agent emits.
+type localizedText struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+// parseLocalizeResult extracts the translated title and summary from the
+// agent's answer, which may be wrapped in prose or a code fence.
+func parseLocalizeResult(o *agentOutput) (any, error) {
+	obj, ok := extractJSONObject(o.Result)
+	if !ok {
+		return nil, fmt.Errorf("no JSON object in localize response (raw: %s)", truncate(o.Result, 200))
+	}
+	var v localizedText
+	if err := json.Unmarshal([]byte(obj), &v); err != nil {
+		return nil, fmt.Errorf("parse localize JSON: %w", err)
+	}
+	v.Title = strings.TrimSpace(v.Title)
+	v.Summary = strings.TrimSpace(v.Summary)
+	if v.Title == "" && v.Summary == "" {
+		return nil, errors.New("blank localize result")
+	}
+	return v, nil
+}
+
+// summaryLocale returns the language task's title and summary are
+// translated into: its own SummaryLocale, else WALLFACER_SUMMARY_LOCALE.
+// Empty means no translation.
+func (r *Runner) summaryLocale(task *store.Task) string {
+	if task.SummaryLocale != "" {
+		return task.SummaryLocale
+	}
+	if r.envFile == "" {
+		return ""
+	}
+	cfg, err := envconfig.Parse(r.envFile)
+	if err != nil {
+		return ""
+	}
+	return cfg.SummaryLocale
+}
+
+// LocalizeSummary translates the task's title and final summary into its
+// summary locale on the title model and stores the translation next to
+// the originals. It does nothing when no locale applies or the task has
+// neither a title nor a summary yet.
+func (r *Runner) LocalizeSummary(taskID uuid.UUID) {
+	s := r.taskStore(taskID)
+	task, err := s.GetTask(r.shutdownCtx, taskID)
+	if err != nil || task == nil {
+		logger.Runner.Warn("localize: get task failed", "task", taskID, "error", err)
+		return
+	}
+	locale := r.summaryLocale(task)
+	if locale == "" {
+		return
+	}
+	var summary string
+	if task.Result != nil {
+		summary = strings.TrimSpace(*task.Result)
+	}
+	if task.Title == "" && summary == "" {
+		return
+	}
+	if len(summary) > localizeMaxSummaryBytes {
+		summary = strings.ToValidUTF8(summary[:localizeMaxSummaryBytes], "") + "\n[...truncated...]"
+	}
+
+	prompt := r.promptsMgr.Localize(prompts.LocalizeData{Locale: locale, Title: task.Title, Summary: summary})
+	res, err := r.runAgent(r.shutdownCtx, agents.Localize, task, prompt, runAgentOpts{
+		EmitSpanEvents: true,
+		TrackUsage:     true,
+		Turn:           1,
+		ModelResolver:  func(sb harness.ID) string { return r.titleModelFromEnvForSandbox(sb) },
+	})
+	if err != nil {
+		logger.Runner.Warn("localize failed", "task", taskID, "locale", locale, "error", err)
+		return
+	}
+	text, _ := res.Parsed.(localizedText)
+	// Drop a field whose source was empty rather than keep what the
+	// agent made up for it.
+	if task.Title == "" {
+		text.Title = ""
+	}
+	if summary == "" {
+		text.Summary = ""
+	}
+
+	// The locale may have changed while the agent ran; that change queued
+	// its own translation.
+	if cur, err := s.GetTask(r.shutdownCtx, taskID); err != nil || cur == nil || r.summaryLocale(cur) != locale {
+		return
+	}
+	if err := s.SetTaskLocalized(r.shutdownCtx, taskID, store.LocalizedSummary{
+		Locale:      locale,
+		Title:       text.Title,
+		Summary:     text.Summary,
+		GeneratedAt: time.Now().UTC(),
+	}); err != nil {
+		logger.Runner.Warn("localize: store update failed", "task", taskID, "error", err)
+	}
+}
+
+// LocalizeSummaryBackground runs LocalizeSummary in a background goroutine
+// tracked by backgroundWg so that WaitBackground can drain it before cleanup.
+func (r *Runner) LocalizeSummaryBackground(taskID uuid.UUID) {
+	r.taskBackground("localize", taskID, func() { r.LocalizeSummary(taskID) })
+}
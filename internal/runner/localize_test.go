@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestParseLocalizeResult(t *testing.T) {
+	got, err := parseLocalizeResult(&agentOutput{Result: "```json\n{\"title\": \" 修复登录错误 \", \"summary\": \"已修复。\"}\n```"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := got.(localizedText); text.Title != "修复登录错误" || text.Summary != "已修复。" {
+		t.Errorf("parsed = %+v", text)
+	}
+	for name, in := range map[string]string{
+		"no object": "修复登录错误",
+		"blank":     `{"title": " ", "summary": ""}`,
+		"bad json":  `{"title": 1}`,
+	} {
+		if _, err := parseLocalizeResult(&agentOutput{Result: in}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+const localizeOutput = `{"result":"{\"title\":\"修复登录错误\",\"summary\":\"已修复登录模块中的错误。\"}","session_id":"sess1","stop_reason":"end_turn","is_error":false}`
+
+// TestLocalizeSummary_StoresTranslation verifies that a task with a summary
+// locale gets its title and result translated and stored next to them.
+func TestLocalizeSummary_StoresTranslation(t *testing.T) {
+	s, r := setupRunnerWithCmd(t, nil, fakeCmdScript(t, localizeOutput, 0))
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "Fix the login bug", SummaryLocale: "zh-CN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskTitle(ctx, task.ID, "Fix Login Bug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskResult(ctx, task.ID, "Fixed the bug in the login module.", "sess1", "end_turn", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	r.LocalizeSummary(task.ID)
+
+	got, _ := s.GetTask(ctx, task.ID)
+	if got.Localized == nil {
+		t.Fatal("no translation stored")
+	}
+	if got.Localized.Locale != "zh-CN" || got.Localized.Title != "修复登录错误" || got.Localized.Summary != "已修复登录模块中的错误。" {
+		t.Errorf("Localized = %+v", got.Localized)
+	}
+	if got.Title != "Fix Login Bug" {
+		t.Errorf("original title replaced: %q", got.Title)
+	}
+}
+
+// TestLocalizeSummary_NoLocaleIsNoop verifies that nothing runs when neither
+// the task nor the env file names a locale.
+func TestLocalizeSummary_NoLocaleIsNoop(t *testing.T) {
+	s, r := setupRunnerWithCmd(t, nil, fakeCmdScript(t, localizeOutput, 0))
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "Fix the login bug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskTitle(ctx, task.ID, "Fix Login Bug"); err != nil {
+		t.Fatal(err)
+	}
+
+	r.LocalizeSummary(task.ID)
+
+	if got, _ := s.GetTask(ctx, task.ID); got.Localized != nil {
+		t.Errorf("Localized = %+v, want none without a locale", got.Localized)
+	}
+}
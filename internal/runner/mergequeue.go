@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MergeTicket is one task holding or waiting for a repository's merge lock.
+type MergeTicket struct {
+	TaskID uuid.UUID `json:"task_id"`
+	Since  time.Time `json:"since"` // when the task took the lock or joined the queue
+}
+
+// RepoMergeQueue is the merge lock state of one repository: the task
+// currently rebasing and merging into it, and the tasks queued behind it in
+// the order they will merge.
+type RepoMergeQueue struct {
+	Repo    string        `json:"repo"`
+	Holder  *MergeTicket  `json:"holder,omitempty"`
+	Waiting []MergeTicket `json:"waiting"`
+}
+
+// mergeQueue serializes rebase+merge per repository in arrival order, so
+// concurrent tasks on the same repo cannot interleave in the commit pipeline
+// and a task that queued first also merges first. Unlike a plain mutex it
+// can report who holds each lock and who is waiting. The zero value is ready
+// to use.
+type mergeQueue struct {
+	mu    sync.Mutex
+	repos map[string]*mergeLine
+}
+
+type mergeLine struct {
+	holder  *MergeTicket
+	waiting []mergeWaiter
+}
+
+type mergeWaiter struct {
+	ticket MergeTicket
+	ready  chan struct{}
+}
+
+// acquire blocks until taskID holds repo's merge lock and returns how many
+// tasks were ahead of it when it asked (0 when the lock was free). If ctx
+// ends first, the task leaves the queue and acquire returns ctx's error
+// without the lock.
+func (q *mergeQueue) acquire(ctx context.Context, repo string, taskID uuid.UUID) (int, error) {
+	q.mu.Lock()
+	if q.repos == nil {
+		q.repos = make(map[string]*mergeLine)
+	}
+	line := q.repos[repo]
+	if line == nil {
+		line = &mergeLine{}
+		q.repos[repo] = line
+	}
+	ticket := MergeTicket{TaskID: taskID, Since: time.Now()}
+	if line.holder == nil {
+		line.holder = &ticket
+		q.mu.Unlock()
+		return 0, nil
+	}
+	ahead := 1 + len(line.waiting)
+	w := mergeWaiter{ticket: ticket, ready: make(chan struct{})}
+	line.waiting = append(line.waiting, w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return ahead, nil
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	select {
+	case <-w.ready:
+		// The lock was handed over as ctx ended: pass it on.
+		q.mu.Unlock()
+		q.release(repo)
+	default:
+		line.waiting = slices.DeleteFunc(line.waiting, func(o mergeWaiter) bool { return o.ready == w.ready })
+		q.mu.Unlock()
+	}
+	return 0, ctx.Err()
+}
+
+// release hands repo's merge lock to the longest-waiting task, or frees it.
+func (q *mergeQueue) release(repo string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	line := q.repos[repo]
+	if line == nil {
+		return
+	}
+	if len(line.waiting) == 0 {
+		delete(q.repos, repo)
+		return
+	}
+	next := line.waiting[0]
+	line.waiting = line.waiting[1:]
+	next.ticket.Since = time.Now()
+	line.holder = &next.ticket
+	close(next.ready)
+}
+
+// snapshot returns the state of every repository with a held merge lock,
+// sorted by repository path.
+func (q *mergeQueue) snapshot() []RepoMergeQueue {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]RepoMergeQueue, 0, len(q.repos))
+	for repo, line := range q.repos {
+		rq := RepoMergeQueue{Repo: repo, Waiting: make([]MergeTicket, len(line.waiting))}
+		if line.holder != nil {
+			holder := *line.holder
+			rq.Holder = &holder
+		}
+		for i, w := range line.waiting {
+			rq.Waiting[i] = w.ticket
+		}
+		out = append(out, rq)
+	}
+	slices.SortFunc(out, func(a, b RepoMergeQueue) int { return strings.Compare(a.Repo, b.Repo) })
+	return out
+}
+
+// MergeQueue reports, per repository, the task merging into it and the tasks
+// queued behind it. Repositories nobody is merging into are omitted.
+func (r *Runner) MergeQueue() []RepoMergeQueue {
+	return r.mergeQueue.snapshot()
+}
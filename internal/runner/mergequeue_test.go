@@ -0,0 +1,128 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// waitForWaiters polls until repo has n queued waiters.
+func waitForWaiters(t *testing.T, q *mergeQueue, repo string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, rq := range q.snapshot() {
+			if rq.Repo == repo && len(rq.Waiting) == n {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiters on %s: %+v", n, repo, q.snapshot())
+}
+
+func TestMergeQueue_FIFO(t *testing.T) {
+	var q mergeQueue
+	first := uuid.New()
+	if ahead, _ := q.acquire(context.Background(), "/repo", first); ahead != 0 {
+		t.Fatalf("free lock: ahead = %d, want 0", ahead)
+	}
+
+	var mu sync.Mutex
+	var order []uuid.UUID
+	var wg sync.WaitGroup
+	waiters := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, id := range waiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ahead, _ := q.acquire(context.Background(), "/repo", id); ahead != i+1 {
+				t.Errorf("waiter %d: ahead = %d, want %d", i, ahead, i+1)
+			}
+			mu.Lock()
+			order = append(order, id)
+			mu.Unlock()
+			q.release("/repo")
+		}()
+		// Queue them one at a time so arrival order is deterministic.
+		waitForWaiters(t, &q, "/repo", i+1)
+	}
+
+	snap := q.snapshot()
+	if len(snap) != 1 || snap[0].Holder == nil || snap[0].Holder.TaskID != first || len(snap[0].Waiting) != 3 {
+		t.Fatalf("snapshot = %+v, want first holding with 3 waiting", snap)
+	}
+	if snap[0].Waiting[0].TaskID != waiters[0] || snap[0].Waiting[2].TaskID != waiters[2] {
+		t.Errorf("waiting order = %+v", snap[0].Waiting)
+	}
+
+	q.release("/repo")
+	wg.Wait()
+	for i := range waiters {
+		if order[i] != waiters[i] {
+			t.Fatalf("merge order = %v, want %v", order, waiters)
+		}
+	}
+	if snap := q.snapshot(); len(snap) != 0 {
+		t.Errorf("released queue still reported: %+v", snap)
+	}
+}
+
+func TestMergeQueue_ReposIndependent(t *testing.T) {
+	var q mergeQueue
+	_, _ = q.acquire(context.Background(), "/b", uuid.New())
+	if ahead, _ := q.acquire(context.Background(), "/a", uuid.New()); ahead != 0 {
+		t.Fatalf("other repo's lock blocked: ahead = %d", ahead)
+	}
+	snap := q.snapshot()
+	if len(snap) != 2 || snap[0].Repo != "/a" || snap[1].Repo != "/b" {
+		t.Errorf("snapshot = %+v, want /a and /b sorted", snap)
+	}
+	q.release("/a")
+	q.release("/b")
+	q.release("/never-locked")
+}
+
+// TestMergeQueue_CancelledWaiterLeaves verifies that a waiter whose context
+// ends leaves the queue at once instead of holding up the tasks behind it.
+func TestMergeQueue_CancelledWaiterLeaves(t *testing.T) {
+	var q mergeQueue
+	_, _ = q.acquire(context.Background(), "/repo", uuid.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := q.acquire(ctx, "/repo", uuid.New())
+		cancelled <- err
+	}()
+	waitForWaiters(t, &q, "/repo", 1)
+	next := uuid.New()
+	acquired := make(chan int, 1)
+	go func() {
+		ahead, _ := q.acquire(context.Background(), "/repo", next)
+		acquired <- ahead
+	}()
+	waitForWaiters(t, &q, "/repo", 2)
+
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled waiter: err = %v, want context.Canceled", err)
+	}
+	snap := q.snapshot()
+	if len(snap) != 1 || len(snap[0].Waiting) != 1 || snap[0].Waiting[0].TaskID != next {
+		t.Fatalf("snapshot = %+v, want only the remaining waiter queued", snap)
+	}
+
+	q.release("/repo")
+	if ahead := <-acquired; ahead != 2 {
+		t.Errorf("remaining waiter: ahead = %d, want 2", ahead)
+	}
+	q.release("/repo")
+	if snap := q.snapshot(); len(snap) != 0 {
+		t.Errorf("released queue still reported: %+v", snap)
+	}
+}
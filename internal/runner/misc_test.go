@@ -105,6 +105,51 @@ func TestKillContainer(t *testing.T) {
 	r.KillContainer(uuid.New())
 }
 
+// TestKillContainer_CancelsRunContext verifies that KillContainer ends a
+// task's tracked run context even when no agent process is running, and
+// that a finished run unregisters itself.
+func TestKillContainer_CancelsRunContext(t *testing.T) {
+	_, r := setupRunnerWithCmd(t, nil, "echo")
+	taskID := uuid.New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	untrack := r.trackRun(taskID, cancel)
+	r.KillContainer(taskID)
+	if ctx.Err() == nil {
+		t.Fatal("KillContainer did not cancel the run context")
+	}
+	untrack()
+	if _, ok := r.taskRuns.Load(taskID); ok {
+		t.Error("run still tracked after it unregistered")
+	}
+}
+
+// TestTrackRun_OlderRunKeepsNewerRegistration verifies that an older run of
+// a task finishing after a newer one started does not unregister the newer
+// run, which KillContainer must still be able to cancel.
+func TestTrackRun_OlderRunKeepsNewerRegistration(t *testing.T) {
+	_, r := setupRunnerWithCmd(t, nil, "echo")
+	taskID := uuid.New()
+
+	_, oldCancel := context.WithCancel(context.Background())
+	defer oldCancel()
+	untrackOld := r.trackRun(taskID, oldCancel)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	untrack := r.trackRun(taskID, cancel)
+
+	untrackOld()
+	r.KillContainer(taskID)
+	if ctx.Err() == nil {
+		t.Fatal("the older run's untrack removed the newer run's registration")
+	}
+	untrack()
+	if _, ok := r.taskRuns.Load(taskID); ok {
+		t.Error("run still tracked after it unregistered")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // isConflictError
 // ---------------------------------------------------------------------------
@@ -121,24 +166,24 @@ func TestIsConflictErrorNonConflict(t *testing.T) {
 	}
 }
 
-func TestIsConflictErrorWrappedErrConflict(t *testing.T) {
-	err := fmt.Errorf("rebase failed: %w", gitutil.ErrConflict)
+func TestIsConflictErrorWrappedErrMergeConflict(t *testing.T) {
+	err := fmt.Errorf("rebase failed: %w", gitutil.ErrMergeConflict)
 	if !isConflictError(err) {
-		t.Fatal("wrapped ErrConflict should be detected as a conflict error")
+		t.Fatal("wrapped ErrMergeConflict should be detected as a conflict error")
 	}
 }
 
 func TestIsConflictErrorDirectString(t *testing.T) {
 	// A plain string error that happens to contain "rebase conflict" is NOT
-	// a conflict error unless it actually wraps ErrConflict via %w.
+	// a conflict error unless it actually wraps ErrMergeConflict via %w.
 	err := fmt.Errorf("rebase conflict occurred")
 	if isConflictError(err) {
-		t.Fatal("plain string error should not be detected as a conflict error without wrapping ErrConflict")
+		t.Fatal("plain string error should not be detected as a conflict error without wrapping ErrMergeConflict")
 	}
 }
 
 func TestIsConflictErrorConflictError(t *testing.T) {
-	// *ConflictError wraps ErrConflict via Unwrap() and must be detected.
+	// *ConflictError wraps ErrMergeConflict via Unwrap() and must be detected.
 	err := &gitutil.ConflictError{
 		WorktreePath:    "/tmp/wt",
 		ConflictedFiles: []string{"foo.go"},
@@ -211,7 +256,7 @@ func TestResolveConflictsSuccess(t *testing.T) {
 	repoPath := t.TempDir()
 	worktreePath := t.TempDir()
 
-	if err := r.resolveConflicts(ctx, task.ID, repoPath, worktreePath, "", "main", ConflictResolverTriggerCommit, 1, 3); err != nil {
+	if err := r.resolveConflicts(ctx, task.ID, repoPath, worktreePath, "", "main", nil, ConflictResolverTriggerCommit, 1, 3); err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
 	events, err := s.GetEvents(ctx, task.ID)
@@ -254,7 +299,7 @@ func TestResolveConflictsContainerError(t *testing.T) {
 	repoPath := t.TempDir()
 	worktreePath := t.TempDir()
 
-	err = r.resolveConflicts(ctx, task.ID, repoPath, worktreePath, "", "main", ConflictResolverTriggerCommit, 1, 3)
+	err = r.resolveConflicts(ctx, task.ID, repoPath, worktreePath, "", "main", nil, ConflictResolverTriggerCommit, 1, 3)
 	if err == nil {
 		t.Fatal("expected error from container failure")
 	}
@@ -278,7 +323,7 @@ func TestResolveConflictsIsError(t *testing.T) {
 	repoPath := t.TempDir()
 	worktreePath := t.TempDir()
 
-	err = r.resolveConflicts(ctx, task.ID, repoPath, worktreePath, "", "main", ConflictResolverTriggerCommit, 1, 3)
+	err = r.resolveConflicts(ctx, task.ID, repoPath, worktreePath, "", "main", nil, ConflictResolverTriggerCommit, 1, 3)
 	if err == nil {
 		t.Fatal("expected error when container reports is_error=true")
 	}
@@ -458,7 +503,7 @@ func TestRunContainerSuccess(t *testing.T) {
 	cmd := fakeCmdScript(t, endTurnOutput, 0)
 	r := runnerWithCmd(t, cmd)
 
-	out, stdout, stderr, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "")
+	out, stdout, stderr, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "", nil)
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
@@ -469,13 +514,44 @@ func TestRunContainerSuccess(t *testing.T) {
 	_ = stderr
 }
 
+// TestRunContainerStreamsToTurnOutput verifies that with a TurnOutput the
+// agent's stdout lands in the turn-output file rather than in the returned
+// raw slices, and is still parsed.
+func TestRunContainerStreamsToTurnOutput(t *testing.T) {
+	cmd := fakeCmdScript(t, endTurnOutput, 0)
+	r := runnerWithCmd(t, cmd)
+	taskID := uuid.New()
+
+	out := r.store.OpenTurnOutput(taskID, 1)
+	output, stdout, stderr, err := r.runContainer(context.Background(), taskID, "prompt", "", nil, "", nil, "", "", out)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if output.StopReason != "end_turn" {
+		t.Fatalf("expected stop_reason=end_turn, got %q", output.StopReason)
+	}
+	if stdout != nil || stderr != nil {
+		t.Fatalf("raw streams returned alongside a TurnOutput: %d/%d bytes", len(stdout), len(stderr))
+	}
+	saved, err := r.store.ReadTurnOutput(taskID, 1)
+	if err != nil {
+		t.Fatalf("ReadTurnOutput: %v", err)
+	}
+	if !strings.Contains(string(saved), `"stop_reason":"end_turn"`) {
+		t.Fatalf("turn output = %q, want the agent's stdout", saved)
+	}
+}
+
 // TestRunContainerNonZeroExitWithValidOutput verifies that a non-zero exit is
 // tolerated when the container produced parseable JSON output.
 func TestRunContainerNonZeroExitWithValidOutput(t *testing.T) {
 	cmd := fakeCmdScript(t, endTurnOutput, 1)
 	r := runnerWithCmd(t, cmd)
 
-	out, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "")
+	out, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "", nil)
 	if err != nil {
 		t.Fatalf("expected no error for non-zero exit with valid output, got: %v", err)
 	}
@@ -490,7 +566,7 @@ func TestRunContainerEmptyOutputNonZeroExit(t *testing.T) {
 	cmd := fakeCmdScript(t, "", 1)
 	r := runnerWithCmd(t, cmd)
 
-	_, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "")
+	_, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "", nil)
 	if err == nil {
 		t.Fatal("expected error for empty container output with non-zero exit")
 	}
@@ -502,7 +578,7 @@ func TestRunContainerEmptyOutputZeroExit(t *testing.T) {
 	cmd := fakeCmdScript(t, "", 0)
 	r := runnerWithCmd(t, cmd)
 
-	_, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "")
+	_, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "", nil)
 	if err == nil {
 		t.Fatal("expected error for empty container output with exit 0")
 	}
@@ -518,7 +594,7 @@ func TestRunContainerWithSessionID(t *testing.T) {
 	r := runnerWithCmd(t, cmd)
 
 	// Should succeed; session ID is passed to args (verified via args tests).
-	out, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "sess-xyz", nil, "", nil, "", "")
+	out, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "sess-xyz", nil, "", nil, "", "", nil)
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
@@ -535,7 +611,7 @@ func TestRunContainerFallsBackToCodexOnTokenLimit(t *testing.T) {
 	cmd := fakeStatefulCmd(t, []string{tokenLimit, endTurnOutput})
 	r := runnerWithCmd(t, cmd)
 
-	out, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "")
+	out, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "", nil)
 	if err != nil {
 		t.Fatalf("expected fallback success, got error: %v", err)
 	}
@@ -559,7 +635,7 @@ func TestRunContainerRegistersHandleForKill(t *testing.T) {
 	taskID := uuid.New()
 	done := make(chan error, 1)
 	go func() {
-		_, _, _, err := r.runContainer(context.Background(), taskID, "prompt", "", nil, "", nil, "", "")
+		_, _, _, err := r.runContainer(context.Background(), taskID, "prompt", "", nil, "", nil, "", "", nil)
 		done <- err
 	}()
 
@@ -751,7 +827,7 @@ func TestRunContainerParseErrorExitZero(t *testing.T) {
 	cmd := fakeCmdScript(t, "this is not valid json output at all", 0)
 	r := runnerWithCmd(t, cmd)
 
-	_, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "")
+	_, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "", nil)
 	if err == nil {
 		t.Fatal("expected error for non-JSON output")
 	}
@@ -767,7 +843,7 @@ func TestRunContainerParseErrorWithExitCode(t *testing.T) {
 	cmd := fakeCmdScript(t, "not valid json", 1)
 	r := runnerWithCmd(t, cmd)
 
-	_, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "")
+	_, _, _, err := r.runContainer(context.Background(), uuid.New(), "prompt", "", nil, "", nil, "", "", nil)
 	if err == nil {
 		t.Fatal("expected error for invalid JSON with exit code 1")
 	}
@@ -786,7 +862,7 @@ func TestRunContainerContextCancelled(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
 	defer cancel()
 
-	_, _, _, err := r.runContainer(ctx, uuid.New(), "prompt", "", nil, "", nil, "", "")
+	_, _, _, err := r.runContainer(ctx, uuid.New(), "prompt", "", nil, "", nil, "", "", nil)
 	if err == nil {
 		t.Fatal("expected error when context is cancelled")
 	}
@@ -2,6 +2,8 @@ package runner
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"slices"
 	"sync"
 	"time"
@@ -45,8 +47,23 @@ type MockRunner struct {
 	KillContainerCalls          []uuid.UUID
 	CleanupWorktreesCalls       []uuid.UUID
 	GenerateTitleCalls          []uuid.UUID
+	LocalizeSummaryCalls        []uuid.UUID
 	MaybeAutoPushWorkspaceCalls []string
 	CommitCalls                 []uuid.UUID
+	ReloadConfigCalls           int
+
+	// ReloadConfigErr is returned by ReloadConfig.
+	ReloadConfigErr error
+
+	// CommitErr is returned by Commit.
+	CommitErr error
+
+	// MergeQueueState is returned by MergeQueue.
+	MergeQueueState []RepoMergeQueue
+
+	// SendInputFn lets tests stub SendInput. When nil the method reports
+	// ErrNoInteractiveTurn, as for a task without a running turn.
+	SendInputFn func(ctx context.Context, taskID uuid.UUID, action store.InputAction, text string) error
 
 	// Optional override for ContainerName return value.
 	// When nil the method returns "" (no container active), matching the default
@@ -61,6 +78,9 @@ type MockRunner struct {
 	// AssessDriftFn lets tests stub the drift-assessment agent call.
 	AssessDriftFn func(ctx context.Context, specBody string, affects, changedFiles []string, diff string) (spec.DriftVerdict, error)
 
+	// EstimateTaskFn lets tests stub the effort-estimation agent call.
+	EstimateTaskFn func(ctx context.Context, taskID uuid.UUID) (store.TaskEstimate, error)
+
 	// RunCriticRoundFn lets tests stub review critic invocations and assert the
 	// working directory the critic is run in.
 	RunCriticRoundFn func(ctx context.Context, prompt string, sb harness.ID, cwd string, deadline time.Duration) (CriticRoundResult, error)
@@ -68,6 +88,10 @@ type MockRunner struct {
 	// GenerateAgentSessionTitleFn lets tests stub the task-free agent-session
 	// thread title generation. When nil, the method returns ("", nil).
 	GenerateAgentSessionTitleFn func(ctx context.Context, firstUserMessage string) (string, error)
+
+	// Probe, when set, is the capability probe result the mock reports.
+	// A failed probe makes CheckAgentAvailable block every task.
+	Probe *AgentProbe
 }
 
 // compile-time assertion.
@@ -94,12 +118,12 @@ func (m *MockRunner) RunBackground(taskID uuid.UUID, _, _ string, _ bool) {
 	m.mu.Unlock()
 }
 
-// Commit records the call and returns nil.
+// Commit records the call and returns CommitErr.
 func (m *MockRunner) Commit(taskID uuid.UUID, _ string) error {
 	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.CommitCalls = append(m.CommitCalls, taskID)
-	m.mu.Unlock()
-	return nil
+	return m.CommitErr
 }
 
 // CommitCallsSnapshot returns a race-safe snapshot of the Commit call IDs.
@@ -113,11 +137,21 @@ func (m *MockRunner) CommitCallsSnapshot() []uuid.UUID {
 func (m *MockRunner) SyncWorktreesBackground(_ uuid.UUID, _ string, _ store.TaskStatus, _ ...func()) {
 }
 
+// IncrementalRebaseBackground is a no-op mock.
+func (m *MockRunner) IncrementalRebaseBackground(_ uuid.UUID, _ string, _ store.TaskStatus, _ ...func()) {
+}
+
 // EnsureTaskWorktrees returns the provided worktrees unchanged.
 func (m *MockRunner) EnsureTaskWorktrees(_ uuid.UUID, existing map[string]string, branchName string) (map[string]string, string, error) {
 	return existing, branchName, nil
 }
 
+// ForkTaskWorktrees returns the parent's worktree paths unchanged on the
+// fork's branch name; no files are copied.
+func (m *MockRunner) ForkTaskWorktrees(forkID uuid.UUID, parentWorktrees map[string]string) (map[string]string, string, error) {
+	return maps.Clone(parentWorktrees), "task/" + forkID.String()[:8], nil
+}
+
 // CleanupWorktrees records the call for later assertions.
 func (m *MockRunner) CleanupWorktrees(taskID uuid.UUID, _ map[string]string, _ string) {
 	m.mu.Lock()
@@ -128,6 +162,22 @@ func (m *MockRunner) CleanupWorktrees(taskID uuid.UUID, _ map[string]string, _ s
 // PruneUnknownWorktrees is a no-op mock.
 func (m *MockRunner) PruneUnknownWorktrees() {}
 
+// MergeQueue returns MergeQueueState, which tests may set.
+func (m *MockRunner) MergeQueue() []RepoMergeQueue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.MergeQueueState
+}
+
+// SendInput calls SendInputFn when set and otherwise reports
+// ErrNoInteractiveTurn.
+func (m *MockRunner) SendInput(ctx context.Context, taskID uuid.UUID, action store.InputAction, text string) error {
+	if m.SendInputFn != nil {
+		return m.SendInputFn(ctx, taskID, action, text)
+	}
+	return ErrNoInteractiveTurn
+}
+
 // ListContainers returns an empty list.
 func (m *MockRunner) ListContainers() ([]executor.ContainerInfo, error) { return nil, nil }
 
@@ -183,11 +233,42 @@ func (m *MockRunner) GenerateTitleBackground(taskID uuid.UUID, _ string) {
 // GenerateOversight is a no-op in the mock.
 func (m *MockRunner) GenerateOversight(_ uuid.UUID) {}
 
+// LocalizeSummaryBackground records a translation request.
+func (m *MockRunner) LocalizeSummaryBackground(taskID uuid.UUID) {
+	m.mu.Lock()
+	m.LocalizeSummaryCalls = append(m.LocalizeSummaryCalls, taskID)
+	m.mu.Unlock()
+}
+
 // GenerateBoardManifest returns an empty manifest in the mock.
 func (m *MockRunner) GenerateBoardManifest(_ context.Context, _ uuid.UUID, _ bool) (*BoardManifest, error) {
 	return &BoardManifest{}, nil
 }
 
+// ProbeAgent returns Probe, or a passing probe when it is unset.
+func (m *MockRunner) ProbeAgent(_ context.Context) AgentProbe {
+	if m.Probe != nil {
+		return *m.Probe
+	}
+	return AgentProbe{OK: true}
+}
+
+// AgentProbeStatus returns Probe, or false when it is unset.
+func (m *MockRunner) AgentProbeStatus() (AgentProbe, bool) {
+	if m.Probe == nil {
+		return AgentProbe{}, false
+	}
+	return *m.Probe, true
+}
+
+// CheckAgentAvailable fails when Probe is set and did not pass.
+func (m *MockRunner) CheckAgentAvailable(_ *store.Task) error {
+	if m.Probe != nil && !m.Probe.OK {
+		return fmt.Errorf("%w: %s", ErrAgentUnavailable, m.Probe.Error)
+	}
+	return nil
+}
+
 // HostCodexAuthStatus returns false in the mock.
 func (m *MockRunner) HostCodexAuthStatus(_ time.Time) (bool, string) { return false, "" }
 
@@ -212,6 +293,14 @@ func (m *MockRunner) TmpDir() string { return "" }
 // EnvFile returns the configured env file path.
 func (m *MockRunner) EnvFile() string { return m.EnvFilePath }
 
+// ReloadConfig records the call and returns ReloadConfigErr.
+func (m *MockRunner) ReloadConfig() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ReloadConfigCalls++
+	return m.ReloadConfigErr
+}
+
 // Prompts returns a default prompts manager (empty overrides dir) so tests
 // that construct a Handler with a MockRunner don't panic calling
 // h.runner.Prompts().PromptsDir(). Override via m.PromptsFn if a test needs
@@ -237,6 +326,15 @@ func (m *MockRunner) AssessDrift(ctx context.Context, specBody string, affects,
 	return spec.DriftVerdict{}, nil
 }
 
+// EstimateTask delegates to EstimateTaskFn when set; the default returns a
+// zero estimate and nil error without touching the store.
+func (m *MockRunner) EstimateTask(ctx context.Context, taskID uuid.UUID) (store.TaskEstimate, error) {
+	if m.EstimateTaskFn != nil {
+		return m.EstimateTaskFn(ctx, taskID)
+	}
+	return store.TaskEstimate{}, nil
+}
+
 // RunCriticRound delegates to RunCriticRoundFn when set; otherwise it returns
 // a zero result so callers that do not exercise the critic path are unaffected.
 func (m *MockRunner) RunCriticRound(ctx context.Context, prompt string, sb harness.ID, cwd string, deadline time.Duration) (CriticRoundResult, error) {
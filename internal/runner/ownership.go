@@ -0,0 +1,153 @@
+package runner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// maxOwnershipPaths caps how many repaired or unrepaired paths one
+// ownership event lists.
+const maxOwnershipPaths = 50
+
+// ownershipReport is what one repair pass over a worktree did.
+type ownershipReport struct {
+	Repaired []string // worktree-relative paths now owned by the server user
+	Failed   []string // worktree-relative paths still owned by someone else
+}
+
+// repairTurnOwnership restores the server user's ownership of files in the
+// task's worktrees after an agent turn. Agents run as the server user, so
+// their own writes are never affected, but a container the agent starts with
+// the worktree bind-mounted (a rootful `docker run -v "$PWD":/src`) leaves
+// root-owned files that later host git operations cannot update. Each
+// worktree with foreign-owned entries gets a system event listing what was
+// repaired and what was not.
+func (r *Runner) repairTurnOwnership(taskID uuid.UUID, turn int, worktreePaths map[string]string) {
+	uid, gid, ok := processOwner()
+	if !ok {
+		return
+	}
+	for _, repo := range lintableWorktrees(worktreePaths) {
+		rep := repairOwnership(worktreePaths[repo], uid, gid)
+		if len(rep.Repaired) == 0 && len(rep.Failed) == 0 {
+			continue
+		}
+		status := "done"
+		if len(rep.Failed) > 0 {
+			status = "failed"
+		}
+		logger.Runner.Warn("repaired foreign-owned worktree files", "task", taskID, "turn", turn, "repo", repo,
+			"repaired", len(rep.Repaired), "failed", len(rep.Failed))
+		_ = r.taskStore(taskID).InsertEvent(r.shutdownCtx, taskID, store.EventTypeSystem, map[string]any{
+			"phase":  "ownership",
+			"status": status,
+			"repo":   repo,
+			"turn":   turn,
+			"result": rep.summary(repo),
+		})
+	}
+}
+
+// summary renders the report as an event result.
+func (rep ownershipReport) summary(repo string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d files in %s not owned by the server user after the agent turn.",
+		len(rep.Repaired)+len(rep.Failed), repo)
+	list := func(title string, paths []string) {
+		if len(paths) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d):", title, len(paths))
+		for _, p := range paths[:min(len(paths), maxOwnershipPaths)] {
+			b.WriteString("\n  " + p)
+		}
+		if len(paths) > maxOwnershipPaths {
+			fmt.Fprintf(&b, "\n  … and %d more", len(paths)-maxOwnershipPaths)
+		}
+	}
+	list("Repaired", rep.Repaired)
+	list("Could not repair; fix with sudo chown", rep.Failed)
+	return b.String()
+}
+
+// repairOwnership walks dir and gives every entry not owned by uid back to
+// uid:gid. It chowns when the server may (root or CAP_CHOWN) and otherwise
+// replaces a foreign regular file or symlink with an identical copy of its
+// own, which needs only write access to the parent directory. Foreign
+// directories can only be chowned. The git metadata file is skipped.
+func repairOwnership(dir string, uid, gid int) ownershipReport {
+	var rep ownershipReport
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return nil
+		}
+		if d.Name() == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		owner, ok := fileOwner(info)
+		if !ok || owner == uid {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if os.Lchown(path, uid, gid) == nil || replaceOwned(path, info) == nil {
+			rep.Repaired = append(rep.Repaired, rel)
+		} else {
+			rep.Failed = append(rep.Failed, rel)
+		}
+		return nil
+	})
+	return rep
+}
+
+// replaceOwned swaps the file or symlink at path for a copy created by the
+// current user, preserving its content, target, and permission bits.
+func replaceOwned(path string, info fs.FileInfo) error {
+	tmp := filepath.Join(filepath.Dir(path), ".wallfacer-own-"+uuid.NewString())
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(target, tmp); err != nil {
+			return err
+		}
+	case info.Mode().IsRegular():
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(tmp, data, info.Mode().Perm()); err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+		// WriteFile's mode is filtered by the umask.
+		if err := os.Chmod(tmp, info.Mode().Perm()); err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot replace %s: not a regular file or symlink", path)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestRepairOwnership_AllOwned verifies a worktree the server user owns
+// entirely produces an empty report.
+func TestRepairOwnership_AllOwned(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rep := repairOwnership(dir, os.Getuid(), os.Getgid())
+	if len(rep.Repaired) != 0 || len(rep.Failed) != 0 {
+		t.Fatalf("report = %+v, want empty", rep)
+	}
+}
+
+// TestRepairOwnership_Chown verifies foreign-owned files and directories are
+// given back to the server user. Creating them needs root.
+func TestRepairOwnership_Chown(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("creating foreign-owned files requires root")
+	}
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "build")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(sub, "out.o")
+	if err := os.WriteFile(out, []byte("obj"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{sub, out} {
+		if err := os.Lchown(p, 4242, 4242); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rep := repairOwnership(dir, 0, 0)
+	if !slices.Equal(rep.Repaired, []string{"build", "build/out.o"}) || len(rep.Failed) != 0 {
+		t.Fatalf("report = %+v", rep)
+	}
+	info, err := os.Lstat(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uid, _ := fileOwner(info); uid != 0 {
+		t.Fatalf("owner = %d after repair, want 0", uid)
+	}
+}
+
+// TestReplaceOwned verifies the copy fallback keeps a file's content and
+// permission bits and a symlink's target.
+func TestReplaceOwned(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "latest")
+	if err := os.Symlink("run.sh", link); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{script, link} {
+		info, err := os.Lstat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := replaceOwned(p, info); err != nil {
+			t.Fatalf("replaceOwned(%s): %v", p, err)
+		}
+	}
+	if data, _ := os.ReadFile(script); string(data) != "#!/bin/sh\n" {
+		t.Fatalf("content = %q", data)
+	}
+	if info, _ := os.Stat(script); info.Mode().Perm() != 0o755 {
+		t.Fatalf("mode = %v, want 0755", info.Mode().Perm())
+	}
+	if target, _ := os.Readlink(link); target != "run.sh" {
+		t.Fatalf("symlink target = %q", target)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("temp files left behind: %v", entries)
+	}
+	if err := replaceOwned(dir, mustLstat(t, dir)); err == nil {
+		t.Fatal("replaceOwned on a directory: want error")
+	}
+}
+
+func mustLstat(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+// TestOwnershipReportSummary verifies the event text lists both groups and
+// truncates long lists.
+func TestOwnershipReportSummary(t *testing.T) {
+	var many []string
+	for range maxOwnershipPaths + 3 {
+		many = append(many, "f")
+	}
+	got := ownershipReport{Repaired: []string{"a.txt"}, Failed: many}.summary("/repo")
+	for _, want := range []string{"Found 54 files in /repo", "Repaired (1):\n  a.txt", "Could not repair", "… and 3 more"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+}
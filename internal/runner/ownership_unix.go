@@ -0,0 +1,23 @@
+//go:build !windows
+
+package runner
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// processOwner returns the uid and gid files in task worktrees should have.
+func processOwner() (uid, gid int, ok bool) {
+	return os.Getuid(), os.Getgid(), true
+}
+
+// fileOwner returns the uid that owns info's file.
+func fileOwner(info fs.FileInfo) (int, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(st.Uid), true
+}
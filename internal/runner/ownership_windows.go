@@ -0,0 +1,15 @@
+//go:build windows
+
+package runner
+
+import "io/fs"
+
+// processOwner reports false: Windows files have no uid ownership to repair.
+func processOwner() (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+// fileOwner reports false on Windows.
+func fileOwner(fs.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// Percent anchors of the commit pipeline's pipeline_progress events. The
+// per-repository rebase+merge work shares the range between
+// pipelinePercentRepos and pipelinePercentCleanup evenly.
+const (
+	pipelinePercentStage   = 0
+	pipelinePercentLint    = 15
+	pipelinePercentRepos   = 25
+	pipelinePercentCleanup = 90
+	pipelinePercentDone    = 100
+)
+
+// pipelineProgress records that the commit pipeline entered a phase, so the
+// board can draw a progress bar and tooling can spot a task stuck in one
+// phase without parsing free-text system events.
+func (r *Runner) pipelineProgress(taskID uuid.UUID, p store.PipelineProgressData) {
+	_ = r.taskStore(taskID).InsertEvent(r.shutdownCtx, taskID, store.EventTypePipelineProgress, p)
+}
+
+// repoProgress places the per-repository phases of the index-th of count
+// repositories within the pipeline's percent range.
+type repoProgress struct {
+	index, count int
+}
+
+// percent returns the progress percent at which phase starts for this
+// repository. A rebase retry reports the resolver's step rather than going
+// back to the first rebase's, so the percent never decreases.
+func (p repoProgress) percent(phase store.PipelinePhase, attempt int) int {
+	const steps = 4 // merge_queue, rebase, resolve, merge
+	var step int
+	switch phase {
+	case store.PipelinePhaseRebase:
+		step = 1
+		if attempt > 1 {
+			step = 2
+		}
+	case store.PipelinePhaseResolve:
+		step = 2
+	case store.PipelinePhaseMerge, store.PipelinePhasePullRequest:
+		step = 3
+	}
+	count := max(p.count, 1)
+	span := pipelinePercentCleanup - pipelinePercentRepos
+	return pipelinePercentRepos + (span*(p.index*steps+step))/(count*steps)
+}
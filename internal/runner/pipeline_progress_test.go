@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// TestRepoProgress_Monotonic walks the per-repository phases of a
+// three-repository merge, including a rebase retry, and checks the percent
+// never decreases and stays between the repository and cleanup anchors.
+func TestRepoProgress_Monotonic(t *testing.T) {
+	type step struct {
+		phase   store.PipelinePhase
+		attempt int
+	}
+	steps := []step{
+		{store.PipelinePhaseMergeQueue, 0},
+		{store.PipelinePhaseRebase, 1},
+		{store.PipelinePhaseResolve, 1},
+		{store.PipelinePhaseRebase, 2},
+		{store.PipelinePhaseMerge, 0},
+	}
+	prev := pipelinePercentLint
+	for i := range 3 {
+		p := repoProgress{index: i, count: 3}
+		for _, s := range steps {
+			got := p.percent(s.phase, s.attempt)
+			if got < prev || got < pipelinePercentRepos || got >= pipelinePercentCleanup {
+				t.Fatalf("repo %d %s attempt %d: percent %d after %d", i, s.phase, s.attempt, got, prev)
+			}
+			prev = got
+		}
+	}
+	if got := (repoProgress{index: 1, count: 2}).percent(store.PipelinePhaseMergeQueue, 0); got != 57 {
+		t.Errorf("second of two repos starts at %d, want 57", got)
+	}
+}
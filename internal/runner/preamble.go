@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"context"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// preamblePrompt prefixes the board's current prompt preamble to a fresh
+// agent prompt and records the version used on the task, so a result can be
+// traced back to the standing instructions that produced it. Test runs and
+// empty (auto-continue) prompts are returned unchanged. A board without a
+// preamble, or whose current version is empty, records version 0.
+func (r *Runner) preamblePrompt(ctx context.Context, task *store.Task, prompt string) string {
+	if task.IsTestRun || prompt == "" {
+		return prompt
+	}
+	s := r.taskStore(task.ID)
+	if s == nil {
+		return prompt
+	}
+	p, ok := s.CurrentPreamble()
+	if !ok || p.Text == "" {
+		p = store.Preamble{}
+	}
+	if p.Version != task.PreambleVersion {
+		if err := s.UpdateTaskPreambleVersion(ctx, task.ID, p.Version); err != nil {
+			logger.Runner.Warn("record preamble version", "task", task.ID, "error", err)
+		}
+	}
+	if p.Text == "" {
+		return prompt
+	}
+	return p.Text + "\n\n" + prompt
+}
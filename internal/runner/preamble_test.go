@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestPreamblePrompt(t *testing.T) {
+	repo := setupTestRepo(t)
+	s, r := setupTestRunner(t, []string{repo})
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "fix the bug", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := r.preamblePrompt(ctx, task, "fix the bug"); got != "fix the bug" {
+		t.Errorf("prompt without a preamble = %q", got)
+	}
+
+	if _, err := s.SetPreamble("Always add tests."); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.preamblePrompt(ctx, task, "fix the bug"); got != "Always add tests.\n\nfix the bug" {
+		t.Errorf("prompt = %q", got)
+	}
+	if got, _ := s.GetTask(ctx, task.ID); got.PreambleVersion != 1 {
+		t.Errorf("PreambleVersion = %d, want 1", got.PreambleVersion)
+	}
+
+	// Auto-continue turns and test runs are left alone.
+	if got := r.preamblePrompt(ctx, task, ""); got != "" {
+		t.Errorf("auto-continue prompt changed to %q", got)
+	}
+	testRun := *task
+	testRun.IsTestRun = true
+	if got := r.preamblePrompt(ctx, &testRun, "verify"); got != "verify" {
+		t.Errorf("test run prompt changed to %q", got)
+	}
+
+	// Clearing the preamble records that the next session ran without one.
+	if _, err := s.SetPreamble(""); err != nil {
+		t.Fatal(err)
+	}
+	cur, _ := s.GetTask(ctx, task.ID)
+	if got := r.preamblePrompt(ctx, cur, "fix the bug"); got != "fix the bug" {
+		t.Errorf("prompt after clearing = %q", got)
+	}
+	if got, _ := s.GetTask(ctx, task.ID); got.PreambleVersion != 0 {
+		t.Errorf("PreambleVersion after clearing = %d, want 0", got.PreambleVersion)
+	}
+}
@@ -0,0 +1,254 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/pkg/sortedkeys"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// Commit subjects used for the pre-merge lint stage's own commits.
+const (
+	lintFixCommitMessage      = "Apply pre-merge formatter fixes"
+	lintFeedbackCommitMessage = "Address pre-merge lint findings"
+)
+
+// maxLintOutputBytes caps how much of a failing linter's output is quoted
+// back to the agent and recorded in events.
+const maxLintOutputBytes = 8 << 10
+
+// preMergeLint runs the optional lint stage between committing the task's
+// changes and merging them. Formatter commands (WALLFACER_PRE_MERGE_FIX) run
+// first in every worktree and their edits are committed as-is. Linter
+// commands (WALLFACER_PRE_MERGE_LINT), followed by the verify command of
+// the repository's wallfacer.yaml, then run; when any fails, the agent
+// gets one feedback turn in its session to fix the findings, its edits are
+// committed, and the checks run once more. The merge proceeds either way:
+// remaining failures are recorded as an event rather than blocking it. The
+// stage is a no-op when no formatter, linter, or verify command is set.
+func (r *Runner) preMergeLint(ctx context.Context, taskID uuid.UUID, sessionID string, worktreePaths map[string]string) {
+	var cfg envconfig.Config
+	if r.envFile != "" {
+		cfg, _ = envconfig.Parse(r.envFile)
+	}
+	repos := lintableWorktrees(worktreePaths)
+	checks := make(map[string][]string, len(repos))
+	hasChecks := false
+	for _, repo := range repos {
+		checks[repo] = cfg.PreMergeLintCommands
+		if verify := repoProjectConfig(repo).Verify; verify != "" {
+			checks[repo] = append(slices.Clone(cfg.PreMergeLintCommands), verify)
+		}
+		hasChecks = hasChecks || len(checks[repo]) > 0
+	}
+	if len(repos) == 0 || (len(cfg.PreMergeFixCommands) == 0 && !hasChecks) {
+		return
+	}
+	bgCtx := r.shutdownCtx
+	s := r.taskStore(taskID)
+	env := r.cacheEnv(taskID)
+	stageIgnore := r.workspaceStageIgnore(taskID)
+	_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "commit", Label: "lint"})
+	defer func() {
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSpanEnd, store.SpanData{Phase: "commit", Label: "lint"})
+	}()
+
+	for _, repo := range repos {
+		wt := worktreePaths[repo]
+		for _, command := range cfg.PreMergeFixCommands {
+			if out, err := runShellCommand(ctx, wt, command, env); err != nil {
+				logger.Runner.Warn("pre-merge formatter failed", "task", taskID, "repo", repo, "command", command, "error", err)
+				_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+					"phase":   "pre_merge_lint",
+					"status":  "fix_failed",
+					"repo":    repo,
+					"command": command,
+					"result":  fmt.Sprintf("Pre-merge formatter `%s` failed in %s: %v\n%s", command, repo, err, truncate(out, maxLintOutputBytes)),
+				})
+			}
+		}
+		committed, err := commitWorktree(ctx, wt, lintFixCommitMessage, repoStageIgnore(stageIgnore, repo))
+		if err != nil {
+			logger.Runner.Warn("pre-merge formatter commit failed", "task", taskID, "repo", repo, "error", err)
+			continue
+		}
+		if committed {
+			_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+				"phase":  "pre_merge_lint",
+				"status": "fixed",
+				"repo":   repo,
+				"result": "Pre-merge lint: committed formatter fixes in " + repo + ".",
+			})
+		}
+	}
+
+	failures := runLintChecks(ctx, repos, worktreePaths, checks, env)
+	if len(failures) == 0 {
+		if hasChecks {
+			_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+				"phase":  "pre_merge_lint",
+				"status": "passed",
+				"result": "Pre-merge lint passed.",
+			})
+		}
+		return
+	}
+	_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+		"phase":    "pre_merge_lint",
+		"status":   "feedback",
+		"failures": len(failures),
+		"result":   fmt.Sprintf("Pre-merge lint found %d failing check(s); asking the agent to fix them.", len(failures)),
+	})
+	if err := r.lintFeedbackTurn(ctx, taskID, sessionID, worktreePaths, failures); err != nil {
+		logger.Runner.Warn("pre-merge lint feedback turn failed", "task", taskID, "error", err)
+	}
+	for _, repo := range repos {
+		if _, err := commitWorktree(ctx, worktreePaths[repo], lintFeedbackCommitMessage, repoStageIgnore(stageIgnore, repo)); err != nil {
+			logger.Runner.Warn("pre-merge lint commit failed", "task", taskID, "repo", repo, "error", err)
+		}
+	}
+
+	remaining := runLintChecks(ctx, repos, worktreePaths, checks, env)
+	if len(remaining) == 0 {
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+			"phase":  "pre_merge_lint",
+			"status": "passed",
+			"result": "Pre-merge lint passed after the agent's fixes.",
+		})
+		return
+	}
+	var summary strings.Builder
+	for _, f := range remaining {
+		fmt.Fprintf(&summary, "\n%s: `%s`\n%s", f.Repo, f.Command, truncate(f.Output, 1000))
+	}
+	_ = s.InsertEvent(bgCtx, taskID, store.EventTypeError, map[string]any{
+		"phase":    "pre_merge_lint",
+		"status":   "failed",
+		"failures": len(remaining),
+		"error":    fmt.Sprintf("Pre-merge lint still failing after one feedback turn; merging anyway.%s", summary.String()),
+	})
+}
+
+// lintFeedbackTurn resumes the agent's session once with the linter
+// failures, the same way the conflict resolver does for rebase conflicts.
+func (r *Runner) lintFeedbackTurn(ctx context.Context, taskID uuid.UUID, sessionID string, worktreePaths map[string]string, failures []prompts.LintFailure) error {
+	prompt := r.promptsMgr.LintFix(prompts.LintFixData{Failures: failures})
+	turns := 1
+	if task, getErr := r.taskStore(taskID).GetTask(r.shutdownCtx, taskID); getErr == nil && task != nil {
+		turns = task.Turns + 1
+	}
+	turnOut := r.taskStore(taskID).OpenTurnOutput(taskID, turns)
+	output, _, _, err := r.runContainer(ctx, taskID, prompt, sessionID, worktreePaths, "", nil, "", activityImplementation, turnOut)
+	_ = turnOut.Close()
+	r.saveTurnExit(taskID, turns)
+
+	if err != nil {
+		return fmt.Errorf("lint feedback container: %w", err)
+	}
+	r.accumulateAgentUsage(taskID, activityImplementation, turns, output)
+	if output.IsError {
+		return fmt.Errorf("lint feedback reported error: %s", truncate(output.Result, 300))
+	}
+	_ = r.taskStore(taskID).InsertEvent(r.shutdownCtx, taskID, store.EventTypeSystem, map[string]any{
+		"phase":  "pre_merge_lint",
+		"status": "feedback_done",
+		"result": "Pre-merge lint feedback: " + truncate(output.Result, 500),
+	})
+	return nil
+}
+
+// lintableWorktrees returns the repos whose worktree exists and is a git
+// repository, sorted so the stage runs and reports in a stable order.
+func lintableWorktrees(worktreePaths map[string]string) []string {
+	var repos []string
+	for repo := range sortedkeys.Of(worktreePaths) {
+		wt := worktreePaths[repo]
+		if _, err := os.Stat(wt); err != nil || !gitutil.IsGitRepo(wt) {
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	return repos
+}
+
+// runLintChecks runs each repo's check commands in its worktree and returns
+// the failing ones with their (truncated) combined output.
+func runLintChecks(ctx context.Context, repos []string, worktreePaths map[string]string, checks map[string][]string, env map[string]string) []prompts.LintFailure {
+	var failures []prompts.LintFailure
+	for _, repo := range repos {
+		for _, command := range checks[repo] {
+			out, err := runShellCommand(ctx, worktreePaths[repo], command, env)
+			if err == nil {
+				continue
+			}
+			if out == "" {
+				out = err.Error()
+			}
+			failures = append(failures, prompts.LintFailure{
+				Repo:    repo,
+				Command: command,
+				Output:  truncate(out, maxLintOutputBytes),
+			})
+		}
+	}
+	return failures
+}
+
+// runShellCommand runs a user-configured command (formatter, linter, or
+// workspace bootstrap) through the platform shell in dir, with env added to
+// the server's environment, and returns its combined output.
+func runShellCommand(ctx context.Context, dir, command string, env map[string]string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = os.Environ()
+		for k := range sortedkeys.Of(env) {
+			cmd.Env = append(cmd.Env, k+"="+env[k])
+		}
+	}
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// commitWorktree stages everything in the worktree except untracked paths
+// matching the ignore patterns and commits it with msg under the host
+// user's global identity. Returns false when there was nothing to commit.
+func commitWorktree(ctx context.Context, worktreePath, msg string, ignore []string) (bool, error) {
+	// Instructions files are mounted for the agent and must not be committed;
+	// hostStageAndCommit removes them before staging for the same reason.
+	for _, name := range []string{prompts.ClaudeInstructionsFilename, prompts.CodexInstructionsFilename} {
+		if out, err := cmdexec.Git(worktreePath, "ls-files", name).WithContext(ctx).Output(); err == nil && out == "" {
+			_ = os.Remove(filepath.Join(worktreePath, name))
+		}
+	}
+	if _, err := gitutil.StageAll(ctx, worktreePath, ignore); err != nil {
+		return false, err
+	}
+	if hasChanges, _ := gitutil.HasStagedChanges(ctx, worktreePath); !hasChanges {
+		return false, nil
+	}
+	args := slices.Concat([]string{"-C", worktreePath}, gitutil.GlobalIdentityOverrides(ctx), []string{"commit", "-m", msg})
+	if out, err := cmdexec.New("git", args...).WithContext(ctx).Combined(); err != nil {
+		return false, fmt.Errorf("git commit: %w: %s", err, out)
+	}
+	return true, nil
+}
@@ -0,0 +1,200 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/projectconfig"
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/store/storetest"
+)
+
+// setupPreMergeLint creates a runner whose env file configures the pre-merge
+// lint stage, plus a task worktree on a fresh repo. The agent command is a
+// fake that answers every turn with validStreamJSON.
+func setupPreMergeLint(t *testing.T, envContent string) (*store.Store, *Runner, uuid.UUID, map[string]string) {
+	t.Helper()
+	repo := setupTestRepo(t)
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte(envContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := storetest.NewFileStore(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	worktreesDir := filepath.Join(t.TempDir(), "worktrees")
+	if err := os.MkdirAll(worktreesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := fakeCmdScript(t, validStreamJSON, 0)
+	resolved := resolveTestCmd(cmd)
+	r := NewRunner(s, RunnerConfig{
+		Command:          cmd,
+		EnvFile:          envFile,
+		Workspaces:       []string{repo},
+		WorktreesDir:     worktreesDir,
+		HostClaudeBinary: resolved,
+		HostCodexBinary:  resolved,
+	})
+	t.Cleanup(func() { r.Shutdown() })
+
+	task, err := s.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "lint me", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktreePaths, branchName, err := r.setupWorktrees(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.cleanupWorktrees(task.ID, worktreePaths, branchName) })
+	return s, r, task.ID, worktreePaths
+}
+
+// lintEvents returns the data of every pre-merge lint event of the task.
+func lintEvents(t *testing.T, s *store.Store, taskID uuid.UUID) []string {
+	t.Helper()
+	events, err := s.GetEvents(context.Background(), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, ev := range events {
+		if strings.Contains(string(ev.Data), `"phase":"pre_merge_lint"`) {
+			out = append(out, string(ev.Data))
+		}
+	}
+	return out
+}
+
+func hasEventStatus(events []string, status string) bool {
+	for _, ev := range events {
+		if strings.Contains(ev, `"status":"`+status+`"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestPreMergeLint_NotConfiguredIsNoOp verifies that the stage does nothing
+// without configured commands.
+func TestPreMergeLint_NotConfiguredIsNoOp(t *testing.T) {
+	s, r, taskID, worktreePaths := setupPreMergeLint(t, "WALLFACER_AUTO_PUSH=false\n")
+	r.preMergeLint(context.Background(), taskID, "", worktreePaths)
+	if evs := lintEvents(t, s, taskID); len(evs) != 0 {
+		t.Fatalf("expected no lint events, got %v", evs)
+	}
+}
+
+// TestPreMergeLint_RunsProjectVerify verifies that the verify command of a
+// repository's wallfacer.yaml runs as a check without any env-file lint
+// configuration, and that its failure gets the agent a feedback turn.
+func TestPreMergeLint_RunsProjectVerify(t *testing.T) {
+	s, r, taskID, worktreePaths := setupPreMergeLint(t, "")
+	for repo := range worktreePaths {
+		if err := os.WriteFile(filepath.Join(repo, projectconfig.Filename), []byte("verify: echo 'verify failed' && exit 1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.preMergeLint(context.Background(), taskID, "sess-1", worktreePaths)
+
+	evs := lintEvents(t, s, taskID)
+	if !hasEventStatus(evs, "feedback") || !hasEventStatus(evs, "failed") {
+		t.Fatalf("expected feedback and failed events, got %v", evs)
+	}
+	if !strings.Contains(strings.Join(evs, "\n"), "verify failed") {
+		t.Errorf("events do not quote the verify output: %v", evs)
+	}
+}
+
+// TestPreMergeLint_CommitsFormatterFixes verifies that formatter edits are
+// committed under their own subject and that passing linters do not start a
+// feedback turn.
+func TestPreMergeLint_CommitsFormatterFixes(t *testing.T) {
+	s, r, taskID, worktreePaths := setupPreMergeLint(t,
+		"WALLFACER_PRE_MERGE_FIX=printf '# Formatted\\n' > README.md\nWALLFACER_PRE_MERGE_LINT=true\n")
+	r.preMergeLint(context.Background(), taskID, "", worktreePaths)
+
+	for _, wt := range worktreePaths {
+		if subject := gitRun(t, wt, "log", "--format=%s", "-1"); subject != lintFixCommitMessage {
+			t.Errorf("last commit = %q, want %q", subject, lintFixCommitMessage)
+		}
+		if status := gitRun(t, wt, "status", "--porcelain"); status != "" {
+			t.Errorf("worktree left dirty: %s", status)
+		}
+	}
+	evs := lintEvents(t, s, taskID)
+	if !hasEventStatus(evs, "fixed") || !hasEventStatus(evs, "passed") {
+		t.Errorf("expected fixed and passed events, got %v", evs)
+	}
+	if hasEventStatus(evs, "feedback") {
+		t.Errorf("passing linters must not trigger feedback: %v", evs)
+	}
+}
+
+// TestPreMergeLint_FeedbackTurnThenPass verifies that a failing linter gets
+// one agent feedback turn and is re-checked afterwards.
+func TestPreMergeLint_FeedbackTurnThenPass(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "checked")
+	// The linter fails on its first run and passes on every later one. It is
+	// a script because ';' separates commands in the configured list.
+	check := filepath.Join(dir, "lint.sh")
+	script := "#!/bin/sh\nif [ -f " + marker + " ]; then exit 0; fi\ntouch " + marker + "\necho 'main.go:1: bad style'\nexit 1\n"
+	if err := os.WriteFile(check, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	s, r, taskID, worktreePaths := setupPreMergeLint(t, "WALLFACER_PRE_MERGE_LINT="+check+"\n")
+	r.preMergeLint(context.Background(), taskID, "sess-1", worktreePaths)
+
+	evs := lintEvents(t, s, taskID)
+	for _, status := range []string{"feedback", "feedback_done", "passed"} {
+		if !hasEventStatus(evs, status) {
+			t.Errorf("missing %q event in %v", status, evs)
+		}
+	}
+	if hasEventStatus(evs, "failed") {
+		t.Errorf("unexpected failed event: %v", evs)
+	}
+}
+
+// TestPreMergeLint_PersistentFailureDoesNotBlock verifies that linters still
+// failing after the feedback turn are reported without running a second turn.
+func TestPreMergeLint_PersistentFailureDoesNotBlock(t *testing.T) {
+	s, r, taskID, worktreePaths := setupPreMergeLint(t, "WALLFACER_PRE_MERGE_LINT=echo unfixable && exit 1\n")
+	r.preMergeLint(context.Background(), taskID, "sess-1", worktreePaths)
+
+	evs := lintEvents(t, s, taskID)
+	if !hasEventStatus(evs, "failed") {
+		t.Fatalf("expected a failed event, got %v", evs)
+	}
+	feedback := 0
+	for _, ev := range evs {
+		if strings.Contains(ev, `"status":"feedback"`) {
+			feedback++
+		}
+	}
+	if feedback != 1 {
+		t.Errorf("feedback turns = %d, want exactly 1", feedback)
+	}
+}
+
+func TestRunLintChecks_ReportsOutput(t *testing.T) {
+	dir := t.TempDir()
+	failures := runLintChecks(context.Background(), []string{"/repo"}, map[string]string{"/repo": dir},
+		map[string][]string{"/repo": {"true", "echo 'x.go:1: unused' && exit 3"}}, nil)
+	if len(failures) != 1 {
+		t.Fatalf("failures = %+v, want 1", failures)
+	}
+	if f := failures[0]; f.Repo != "/repo" || f.Output != "x.go:1: unused" {
+		t.Errorf("failure = %+v", f)
+	}
+}
@@ -0,0 +1,155 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/sortedkeys"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// previewTimeout bounds one repository's preview deploy.
+const previewTimeout = 15 * time.Minute
+
+// previewProviders maps the provider names a workspace's Preview setting
+// accepts to the command that deploys with that provider's CLI. Each writes
+// the deployment URL to $WALLFACER_PREVIEW_URL; credentials come from the
+// server's environment (VERCEL_TOKEN, NETLIFY_AUTH_TOKEN and
+// NETLIFY_SITE_ID). Any other value is run as a shell command.
+var previewProviders = map[string]string{
+	"vercel":  `vercel deploy --yes ${VERCEL_TOKEN:+--token "$VERCEL_TOKEN"} > "$WALLFACER_PREVIEW_URL"`,
+	"netlify": `netlify deploy --json | sed -n 's/.*"deploy_url": *"\([^"]*\)".*/\1/p' > "$WALLFACER_PREVIEW_URL"`,
+}
+
+// errNoPreviewURL reports a deploy that exited cleanly without a URL.
+var errNoPreviewURL = errors.New("no preview URL was written to $WALLFACER_PREVIEW_URL")
+
+// workspacePreview returns the preview provider or command configured on the
+// workspace the task was dispatched under, or "" when there is none.
+func (r *Runner) workspacePreview(taskID uuid.UUID) string {
+	if r.workspaceManager == nil {
+		return ""
+	}
+	ws, found, err := r.workspaceManager.WorkspaceByKey(r.taskWorkspaceKey(taskID))
+	if err != nil || !found {
+		return ""
+	}
+	return ws.Preview
+}
+
+// previewMerged deploys each merge commit in commitHashes (repo path →
+// commit) to the workspace's preview provider in the background and attaches
+// the resulting URLs to the task. Like publishing, it runs after the task is
+// done and a failure never reverts the merge.
+func (r *Runner) previewMerged(taskID uuid.UUID, commitHashes map[string]string) {
+	provider := r.workspacePreview(taskID)
+	if provider == "" || len(commitHashes) == 0 {
+		return
+	}
+	hashes := maps.Clone(commitHashes)
+	r.taskBackground("preview", taskID, func() {
+		r.runPreview(r.shutdownCtx, taskID, provider, hashes)
+	})
+}
+
+// runPreview deploys each merged repository in turn and records one
+// PreviewDeploy per repository on the task.
+func (r *Runner) runPreview(ctx context.Context, taskID uuid.UUID, provider string, commitHashes map[string]string) {
+	s := r.taskStore(taskID)
+	bgCtx := r.shutdownCtx
+	_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "preview", Label: "preview"})
+	defer func() {
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSpanEnd, store.SpanData{Phase: "preview", Label: "preview"})
+	}()
+
+	for repo := range sortedkeys.Of(commitHashes) {
+		deploy := r.previewRepo(ctx, taskID, provider, repo, commitHashes[repo])
+		if deploy.Status == store.PublishStatusFailed {
+			logger.Runner.Warn("preview deploy failed", "task", taskID, "repo", repo, "provider", provider, "error", deploy.Error)
+			_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+				"phase":  "preview",
+				"status": "failed",
+				"repo":   repo,
+				"result": fmt.Sprintf("Preview deploy of %s failed: %s\n%s", repo, deploy.Error, truncate(deploy.Log, maxLintOutputBytes)),
+			})
+			continue
+		}
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+			"phase":  "preview",
+			"status": "done",
+			"repo":   repo,
+			"url":    deploy.URL,
+			"result": fmt.Sprintf("Preview of %s deployed: %s", repo, deploy.URL),
+		})
+	}
+}
+
+// previewRepo deploys commit in a detached checkout (see runInCheckout) and
+// reads the preview URL the command wrote to $WALLFACER_PREVIEW_URL.
+func (r *Runner) previewRepo(ctx context.Context, taskID uuid.UUID, provider, repo, commit string) store.PreviewDeploy {
+	s := r.taskStore(taskID)
+	deploy := store.PreviewDeploy{
+		Repo:      repo,
+		Commit:    commit,
+		Provider:  provider,
+		Status:    store.PublishStatusRunning,
+		StartedAt: time.Now(),
+	}
+	_ = s.SetPreviewDeploy(r.shutdownCtx, taskID, deploy)
+	finish := func(log, previewURL string, err error) store.PreviewDeploy {
+		deploy.FinishedAt = time.Now()
+		deploy.Log = tail(log, maxPublishLogBytes)
+		deploy.Status = store.PublishStatusSucceeded
+		deploy.URL = previewURL
+		if err != nil {
+			deploy.Status = store.PublishStatusFailed
+			deploy.Error = err.Error()
+			deploy.URL = ""
+		}
+		if storeErr := s.SetPreviewDeploy(r.shutdownCtx, taskID, deploy); storeErr != nil {
+			logger.Runner.Warn("save preview deploy", "task", taskID, "repo", repo, "error", storeErr)
+		}
+		return deploy
+	}
+
+	command := provider
+	if preset, ok := previewProviders[provider]; ok {
+		command = preset
+	}
+	dir, err := os.MkdirTemp("", "wallfacer-preview-")
+	if err != nil {
+		return finish("", "", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	urlFile := filepath.Join(dir, "url")
+	out, err := r.runInCheckout(ctx, taskID, command, repo, commit,
+		map[string]string{"WALLFACER_PREVIEW_URL": urlFile}, previewTimeout)
+	if err != nil {
+		return finish(out, "", err)
+	}
+	previewURL, err := readPreviewURL(urlFile)
+	return finish(out, previewURL, err)
+}
+
+// readPreviewURL returns the first line of the file the deploy command wrote,
+// which must be an absolute http or https URL so the board can link to it.
+func readPreviewURL(path string) (string, error) {
+	lines := readArtifacts(path)
+	if len(lines) == 0 {
+		return "", errNoPreviewURL
+	}
+	u, err := url.Parse(lines[0])
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("preview URL %q is not an http(s) URL", lines[0])
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,107 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/workspace"
+)
+
+// setupPreview creates a workspace over a fresh repo with the given preview
+// setting, a runner viewing it, and a task. It returns the repo's HEAD as
+// the merge commit to deploy.
+func setupPreview(t *testing.T, preview string) (*store.Store, *Runner, uuid.UUID, string, string) {
+	t.Helper()
+	s, r, taskID, repo := setupWorkspaceTask(t, func(mgr *workspace.Manager, wsID string) error {
+		_, err := mgr.SetPreview(wsID, preview)
+		return err
+	})
+	return s, r, taskID, repo, gitRun(t, repo, "rev-parse", "HEAD")
+}
+
+func previews(t *testing.T, s *store.Store, taskID uuid.UUID) []store.PreviewDeploy {
+	t.Helper()
+	task, err := s.GetTask(context.Background(), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task.Previews
+}
+
+func TestPreviewMerged_AttachesURL(t *testing.T) {
+	command := `test -f README.md && echo "https://preview.example.com/$(git rev-parse --short HEAD)" > "$WALLFACER_PREVIEW_URL"`
+	s, r, taskID, repo, commit := setupPreview(t, command)
+
+	r.previewMerged(taskID, map[string]string{repo: commit})
+	r.WaitBackground()
+
+	got := previews(t, s, taskID)
+	if len(got) != 1 {
+		t.Fatalf("previews = %+v, want one", got)
+	}
+	p := got[0]
+	if p.Status != store.PublishStatusSucceeded || p.Repo != repo || p.Commit != commit || p.Provider != command {
+		t.Fatalf("preview = %+v, want succeeded for %s@%s", p, repo, commit)
+	}
+	if want := "https://preview.example.com/" + commit[:7]; p.URL != want {
+		t.Errorf("url = %q, want %q", p.URL, want)
+	}
+	if out := gitRun(t, repo, "worktree", "list"); strings.Count(out, "\n") != 0 {
+		t.Errorf("preview checkout left registered:\n%s", out)
+	}
+}
+
+func TestPreviewMerged_MissingURLFails(t *testing.T) {
+	s, r, taskID, repo, commit := setupPreview(t, "echo deployed somewhere")
+
+	r.previewMerged(taskID, map[string]string{repo: commit})
+	r.WaitBackground()
+
+	got := previews(t, s, taskID)
+	if len(got) != 1 || got[0].Status != store.PublishStatusFailed || got[0].URL != "" {
+		t.Fatalf("previews = %+v, want one failed deploy without a URL", got)
+	}
+	if !strings.Contains(got[0].Log, "deployed somewhere") || got[0].Error == "" {
+		t.Errorf("failed deploy should keep the output and error: %+v", got[0])
+	}
+}
+
+func TestPreviewMerged_NoProvider(t *testing.T) {
+	s, r, taskID, repo, commit := setupPreview(t, "")
+
+	r.previewMerged(taskID, map[string]string{repo: commit})
+	r.WaitBackground()
+
+	if got := previews(t, s, taskID); len(got) != 0 {
+		t.Fatalf("previews = %+v, want none without a provider", got)
+	}
+}
+
+func TestReadPreviewURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "url")
+	if _, err := readPreviewURL(path); err == nil {
+		t.Error("missing file: want error")
+	}
+	for content, want := range map[string]string{
+		"https://app-git-x.vercel.app\n":      "https://app-git-x.vercel.app",
+		"\n  http://localhost:3000 \nextra\n": "http://localhost:3000",
+		"javascript:alert(1)\n":               "",
+		"app.netlify.app\n":                   "",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readPreviewURL(path)
+		if got != want || (want == "") != (err != nil) {
+			t.Errorf("readPreviewURL(%q) = %q, %v; want %q", content, got, err, want)
+		}
+	}
+}
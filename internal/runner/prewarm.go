@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/executor"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/envutil"
+)
+
+// prewarmer is implemented by backends that can warm agent CLIs ahead of
+// the first task (see executor.HostBackend.Prewarm).
+type prewarmer interface {
+	Prewarm(ctx context.Context) []executor.PrewarmResult
+}
+
+// StartAgentPrewarm periodically warms the agent CLIs while no agents are
+// running, so the first task after an idle period does not pay the CLI's
+// cold-start cost, and logs CLI upgrades picked up since the last run.
+// WALLFACER_AGENT_PREWARM_INTERVAL sets the interval; 0 disables warming.
+func (r *Runner) StartAgentPrewarm(ctx context.Context) {
+	interval := envutil.Duration("WALLFACER_AGENT_PREWARM_INTERVAL", constants.DefaultAgentPrewarmInterval)
+	if interval <= 0 {
+		return
+	}
+	pw, ok := r.backend.(prewarmer)
+	if !ok {
+		return
+	}
+	if !r.backgroundWg.Add("agent-prewarm") {
+		return
+	}
+	defer r.backgroundWg.Done("agent-prewarm")
+
+	timer := time.NewTimer(constants.AgentPrewarmStartDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if running, err := r.backend.List(ctx); err == nil && len(running) > 0 {
+				timer.Reset(constants.AgentPrewarmBusyRetry)
+				continue
+			}
+			logPrewarmResults(pw.Prewarm(ctx))
+			timer.Reset(interval)
+		}
+	}
+}
+
+func logPrewarmResults(results []executor.PrewarmResult) {
+	for _, res := range results {
+		switch {
+		case res.Error != "":
+			logger.Runner.Warn("agent prewarm: probe failed",
+				"harness", res.Harness, "binary", res.Binary, "error", res.Error)
+		case res.Upgraded():
+			logger.Runner.Info("agent prewarm: CLI upgraded",
+				"harness", res.Harness, "previous", res.Previous, "version", res.Version)
+		default:
+			logger.Runner.Debug("agent prewarm: warmed",
+				"harness", res.Harness, "version", res.Version, "duration", res.Duration)
+		}
+	}
+}
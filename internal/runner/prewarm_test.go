@@ -0,0 +1,25 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestStartAgentPrewarm_Disabled verifies that a zero interval turns the
+// warm-up loop off: it must return immediately rather than block on ctx.
+func TestStartAgentPrewarm_Disabled(t *testing.T) {
+	t.Setenv("WALLFACER_AGENT_PREWARM_INTERVAL", "0")
+	r := newTestRunner(t)
+
+	done := make(chan struct{})
+	go func() {
+		r.StartAgentPrewarm(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("StartAgentPrewarm did not return with the interval set to 0")
+	}
+}
@@ -0,0 +1,200 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/agents"
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/envutil"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// ErrAgentUnavailable is returned by CheckAgentAvailable when the latest
+// capability probe of the harness a task would run on failed.
+var ErrAgentUnavailable = errors.New("agent unavailable")
+
+// probePrompt is the whole of the probe's conversation: cheap to answer,
+// yet it exercises authentication, the model, and stream-json output.
+const probePrompt = "This is an availability check. Reply with the single word OK."
+
+// minAgentVersions is the oldest CLI release per harness whose flags and
+// stream-json output the runner supports. Harnesses without an entry are
+// not version-checked.
+var minAgentVersions = map[harness.ID]string{
+	harness.Claude: "1.0.0",
+}
+
+// versionRe matches the first dotted version number in `--version` output,
+// e.g. "2.1.3" in "2.1.3 (Claude Code)".
+var versionRe = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// AgentProbe is the result of one capability probe of the harness and model
+// that implementation turns run on.
+type AgentProbe struct {
+	Harness   harness.ID    `json:"harness"`
+	Model     string        `json:"model,omitempty"`
+	Version   string        `json:"version,omitempty"`
+	OK        bool          `json:"ok"`
+	Error     string        `json:"error,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// versionProber is implemented by backends that can report an agent CLI's
+// version (see executor.HostBackend.Version).
+type versionProber interface {
+	Version(ctx context.Context, id harness.ID) (string, error)
+}
+
+// ProbeAgent checks that the harness and model configured for
+// implementation are usable: the CLI is installed and recent enough, the
+// authenticated account can run the model, and the CLI answers in
+// stream-json. The result replaces the cached probe that
+// CheckAgentAvailable consults.
+func (r *Runner) ProbeAgent(ctx context.Context) AgentProbe {
+	sb := r.sandboxFromEnvForActivity(activityImplementation)
+	if sb == "" {
+		sb = harness.Default()
+	}
+	p := AgentProbe{Harness: sb, Model: r.modelFromEnvForSandbox(sb), CheckedAt: time.Now()}
+	version, err := r.probeAgent(ctx, sb, p.Model)
+	p.Version, p.OK, p.Duration = version, err == nil, time.Since(p.CheckedAt)
+	if err != nil {
+		p.Error = err.Error()
+		logger.Runner.Warn("agent probe failed", "harness", sb, "model", p.Model, "error", err)
+	} else {
+		logger.Runner.Info("agent probe passed", "harness", sb, "model", p.Model, "version", version)
+	}
+	r.agentProbe.Store(&p)
+	return p
+}
+
+func (r *Runner) probeAgent(ctx context.Context, sb harness.ID, model string) (string, error) {
+	var version string
+	if vp, ok := r.backend.(versionProber); ok {
+		v, err := vp.Version(ctx, sb)
+		if err != nil {
+			return "", err
+		}
+		version = v
+		if err := checkAgentVersion(sb, version); err != nil {
+			return version, err
+		}
+	}
+
+	role := agents.Probe
+	role.Harness = string(sb)
+	res, err := r.runAgent(ctx, role, nil, probePrompt, runAgentOpts{ModelOverride: model})
+	if err != nil {
+		return version, fmt.Errorf("run %s: %w", sb, err)
+	}
+	if res.Output != nil && res.Output.IsError {
+		msg := strings.TrimSpace(res.Output.Result)
+		if model != "" && strings.Contains(strings.ToLower(msg), "model") {
+			return version, fmt.Errorf("model %q is not available to the authenticated account: %s", model, msg)
+		}
+		return version, fmt.Errorf("%s reported an error: %s", sb, msg)
+	}
+	return version, nil
+}
+
+// checkAgentVersion rejects a CLI older than minAgentVersions. Output
+// without a recognisable version number is accepted.
+func checkAgentVersion(sb harness.ID, version string) error {
+	minVersion, ok := minAgentVersions[sb]
+	if !ok {
+		return nil
+	}
+	have, ok := parseVersion(version)
+	if !ok {
+		return nil
+	}
+	want, _ := parseVersion(minVersion)
+	for i := range have {
+		if have[i] != want[i] {
+			if have[i] < want[i] {
+				return fmt.Errorf("%s CLI %s is older than the minimum supported %s; upgrade it", sb, strings.TrimSpace(version), minVersion)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func parseVersion(s string) ([3]int, bool) {
+	m := versionRe.FindStringSubmatch(s)
+	if m == nil {
+		return [3]int{}, false
+	}
+	var v [3]int
+	for i := range v {
+		v[i], _ = strconv.Atoi(m[i+1])
+	}
+	return v, true
+}
+
+// AgentProbeStatus returns the latest capability probe, or false when none
+// has run.
+func (r *Runner) AgentProbeStatus() (AgentProbe, bool) {
+	p := r.agentProbe.Load()
+	if p == nil {
+		return AgentProbe{}, false
+	}
+	return *p, true
+}
+
+// CheckAgentAvailable returns an error wrapping ErrAgentUnavailable when the
+// latest probe of the harness and model task would start on failed. A task
+// routed to another harness or pinned to another model is not blocked, nor
+// is any task before the first probe.
+func (r *Runner) CheckAgentAvailable(task *store.Task) error {
+	p, ok := r.AgentProbeStatus()
+	if !ok || p.OK {
+		return nil
+	}
+	if r.sandboxForTaskActivity(task, activityImplementation) != p.Harness {
+		return nil
+	}
+	if task.ModelOverride != nil && *task.ModelOverride != "" && *task.ModelOverride != p.Model {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrAgentUnavailable, p.Error)
+}
+
+// StartAgentProbe probes the agent once at startup and, while the probe
+// fails, again every WALLFACER_AGENT_PROBE_RETRY, so a transient outage or
+// a fixed credential clears on its own. 0 disables probing, and with it the
+// start gate.
+func (r *Runner) StartAgentProbe(ctx context.Context) {
+	retry := envutil.Duration("WALLFACER_AGENT_PROBE_RETRY", constants.DefaultAgentProbeRetry)
+	if retry <= 0 {
+		return
+	}
+	if !r.backgroundWg.Add("agent-probe") {
+		return
+	}
+	defer r.backgroundWg.Done("agent-probe")
+
+	for {
+		if r.ProbeAgent(ctx).OK {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		// An on-demand probe may have passed in the meantime.
+		if p, _ := r.AgentProbeStatus(); p.OK {
+			return
+		}
+	}
+}
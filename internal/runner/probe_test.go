@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestCheckAgentVersion(t *testing.T) {
+	cases := []struct {
+		sb      harness.ID
+		version string
+		ok      bool
+	}{
+		{harness.Claude, "2.1.3 (Claude Code)", true},
+		{harness.Claude, "1.0.0 (Claude Code)", true},
+		{harness.Claude, "0.2.125 (Claude Code)", false},
+		{harness.Claude, "dev build", true}, // no version number: not checked
+		{harness.Codex, "codex-cli 0.0.1", true},
+	}
+	for _, tc := range cases {
+		err := checkAgentVersion(tc.sb, tc.version)
+		if (err == nil) != tc.ok {
+			t.Errorf("checkAgentVersion(%s, %q) = %v, want ok=%v", tc.sb, tc.version, err, tc.ok)
+		}
+	}
+}
+
+func TestProbeAgent_Passes(t *testing.T) {
+	cmd := fakeCmdScript(t, `{"result":"OK","session_id":"s","stop_reason":"end_turn","is_error":false}`, 0)
+	_, r := setupRunnerWithCmd(t, nil, cmd)
+
+	if _, ok := r.AgentProbeStatus(); ok {
+		t.Fatal("probe status reported before any probe ran")
+	}
+	p := r.ProbeAgent(context.Background())
+	if !p.OK || p.Error != "" || p.Harness != harness.Claude {
+		t.Fatalf("probe = %+v, want a passing claude probe", p)
+	}
+	if cached, ok := r.AgentProbeStatus(); !ok || cached != p {
+		t.Errorf("cached probe = %+v, want %+v", cached, p)
+	}
+	if err := r.CheckAgentAvailable(&store.Task{}); err != nil {
+		t.Errorf("CheckAgentAvailable after passing probe: %v", err)
+	}
+}
+
+func TestProbeAgent_ModelUnavailableBlocksStarts(t *testing.T) {
+	cmd := fakeCmdScript(t, `{"result":"There's an issue with the selected model. It may not exist or you may not have access to it.","is_error":true}`, 0)
+	_, r := setupRunnerWithCmd(t, nil, cmd)
+
+	p := r.ProbeAgent(context.Background())
+	if p.OK || !strings.Contains(p.Error, "selected model") {
+		t.Fatalf("probe = %+v, want a failure naming the model error", p)
+	}
+
+	err := r.CheckAgentAvailable(&store.Task{})
+	if !errors.Is(err, ErrAgentUnavailable) || !strings.Contains(err.Error(), "selected model") {
+		t.Errorf("CheckAgentAvailable = %v, want ErrAgentUnavailable with the probe error", err)
+	}
+	if err := r.CheckAgentAvailable(&store.Task{Sandbox: harness.Codex}); err != nil {
+		t.Errorf("task routed to another harness was blocked: %v", err)
+	}
+	other := "another-model"
+	if err := r.CheckAgentAvailable(&store.Task{ModelOverride: &other}); err != nil {
+		t.Errorf("task pinned to another model was blocked: %v", err)
+	}
+}
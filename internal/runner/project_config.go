@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/projectconfig"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// taskProjectConfig returns the merged wallfacer.yaml of the folders of the
+// workspace the task was dispatched under. The files are read on every
+// call, so an edit applies to the task's next step.
+func (r *Runner) taskProjectConfig(taskID uuid.UUID) projectconfig.Config {
+	folders := r.currentWorkspaces()
+	if r.workspaceManager != nil {
+		if ws, found, err := r.workspaceManager.WorkspaceByKey(r.taskWorkspaceKey(taskID)); err == nil && found {
+			folders = ws.Folders
+		}
+	}
+	cfg, err := projectconfig.LoadAll(folders)
+	if err != nil {
+		logger.Runner.Warn("load "+projectconfig.Filename, "task", taskID, "error", err)
+	}
+	return cfg
+}
+
+// repoProjectConfig returns the wallfacer.yaml of one repository, for the
+// settings that apply per repository: the verify command and ignore paths.
+func repoProjectConfig(repo string) projectconfig.Config {
+	cfg, err := projectconfig.Load(repo)
+	if err != nil {
+		logger.Runner.Warn("load "+projectconfig.Filename, "repo", repo, "error", err)
+	}
+	return cfg
+}
+
+// repoStageIgnore returns the workspace's stage-ignore patterns followed by
+// the ignore paths of repo's wallfacer.yaml.
+func repoStageIgnore(workspace []string, repo string) []string {
+	ignore := repoProjectConfig(repo).Ignore
+	if len(ignore) == 0 {
+		return workspace
+	}
+	return slices.Concat(workspace, ignore)
+}
+
+// projectPrompt appends the instructions of the workspace's wallfacer.yaml
+// to a fresh prompt. Test runs and the empty prompt of an auto-continue
+// turn are returned unchanged.
+func (r *Runner) projectPrompt(task *store.Task, prompt string) string {
+	if task.IsTestRun || prompt == "" {
+		return prompt
+	}
+	if instructions := r.taskProjectConfig(task.ID).Instructions; instructions != "" {
+		return prompt + "\n\n" + instructions
+	}
+	return prompt
+}
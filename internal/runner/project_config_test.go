@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/projectconfig"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestRepoStageIgnore(t *testing.T) {
+	repo := t.TempDir()
+	if got := repoStageIgnore([]string{"dist/"}, repo); !slices.Equal(got, []string{"dist/"}) {
+		t.Errorf("without %s: %q", projectconfig.Filename, got)
+	}
+	if err := os.WriteFile(filepath.Join(repo, projectconfig.Filename), []byte("ignore: [coverage.out]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := repoStageIgnore([]string{"dist/"}, repo); !slices.Equal(got, []string{"dist/", "coverage.out"}) {
+		t.Errorf("with %s: %q", projectconfig.Filename, got)
+	}
+}
+
+func TestProjectPrompt(t *testing.T) {
+	repo := setupTestRepo(t)
+	s, r := setupTestRunner(t, []string{repo})
+	task, err := s.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "build", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.projectPrompt(task, "build"); got != "build" {
+		t.Errorf("without %s: %q", projectconfig.Filename, got)
+	}
+	if err := os.WriteFile(filepath.Join(repo, projectconfig.Filename), []byte("instructions: Keep the API stable.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.projectPrompt(task, "build"); got != "build\n\nKeep the API stable." {
+		t.Errorf("fresh prompt = %q", got)
+	}
+	if got := r.projectPrompt(task, ""); got != "" {
+		t.Errorf("auto-continue prompt = %q, want empty", got)
+	}
+}
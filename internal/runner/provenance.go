@@ -1,9 +1,11 @@
 package runner
 
 import (
+	"os"
 	"time"
 
 	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/executor"
 	"latere.ai/x/wallfacer/internal/store"
 )
 
@@ -19,10 +21,19 @@ func (r *Runner) captureExecutionEnvironment(task store.Task) store.ExecutionEnv
 	}
 
 	// Model: read from env config, with per-task override taking precedence.
+	// Isolation: the env file wins over the server environment, as in the
+	// agent's merged environment.
+	isolation := os.Getenv(executor.IsolationEnv)
 	if r.envFile != "" {
 		cfg, _ := envconfig.Parse(r.envFile)
 		env.ModelName = r.modelFromEnvForSandbox(task.Sandbox)
 		env.APIBaseURL = cfg.BaseURL
+		if cfg.HostIsolation != "" {
+			isolation = cfg.HostIsolation
+		}
+	}
+	if iso, err := executor.ParseIsolation(isolation); err == nil && iso != executor.IsolationNone {
+		env.Isolation = string(iso)
 	}
 	// Per-task model override (deprecated field, kept for migration compatibility).
 	if task.Model != "" {
@@ -32,5 +43,15 @@ func (r *Runner) captureExecutionEnvironment(task store.Task) store.ExecutionEnv
 	// Sandbox: record the configured sandbox for this task.
 	env.Sandbox = r.sandboxForTaskActivity(&task, activityImplementation)
 
+	// Pinned locale, timezone, and build timestamp, as launchOne applies them.
+	pinned := r.agentEnvironment(&task)
+	env.Timezone = pinned.tz
+	env.Locale = pinned.lang
+	env.SourceDateEpoch = pinned.sourceDateEpoch
+
+	// Platform: the os/arch the agent runs as, emulated when the workspace
+	// forces a non-native architecture.
+	env.Platform, env.Emulated = executor.Platform(r.workspaceArch(task.ID))
+
 	return env
 }
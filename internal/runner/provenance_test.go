@@ -3,7 +3,9 @@ package runner
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"latere.ai/x/wallfacer/internal/store"
 )
@@ -75,3 +77,54 @@ func TestCaptureExecutionEnvironment_TaskModelOverride(t *testing.T) {
 		t.Errorf("ModelName = %q, want %q", env.ModelName, "override-model")
 	}
 }
+
+// TestCaptureExecutionEnvironment_PinnedLocale verifies the snapshot records
+// the timezone, locale, and SOURCE_DATE_EPOCH the agent is launched with.
+func TestCaptureExecutionEnvironment_PinnedLocale(t *testing.T) {
+	r := NewRunner(nil, RunnerConfig{Command: "echo"})
+	t.Cleanup(func() { r.Shutdown() })
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	env := r.captureExecutionEnvironment(store.Task{CreatedAt: created})
+	if env.Timezone != defaultAgentTZ || env.Locale != defaultAgentLang() {
+		t.Errorf("Timezone/Locale = %q/%q, want defaults", env.Timezone, env.Locale)
+	}
+	if env.SourceDateEpoch != created.Unix() {
+		t.Errorf("SourceDateEpoch = %d, want task creation time %d", env.SourceDateEpoch, created.Unix())
+	}
+}
+
+// TestCaptureExecutionEnvironment_Platform verifies the snapshot records the
+// host's native platform when no workspace forces an architecture.
+func TestCaptureExecutionEnvironment_Platform(t *testing.T) {
+	r := NewRunner(nil, RunnerConfig{Command: "echo"})
+	t.Cleanup(func() { r.Shutdown() })
+
+	env := r.captureExecutionEnvironment(store.Task{})
+	if want := runtime.GOOS + "/" + runtime.GOARCH; env.Platform != want || env.Emulated {
+		t.Errorf("Platform = %q (emulated %v), want native %q", env.Platform, env.Emulated, want)
+	}
+}
+
+// TestCaptureExecutionEnvironment_Isolation verifies the snapshot records the
+// host isolation level from the env file, and nothing when agents are
+// unconfined.
+func TestCaptureExecutionEnvironment_Isolation(t *testing.T) {
+	t.Setenv("WALLFACER_HOST_ISOLATION", "")
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("WALLFACER_HOST_ISOLATION=restricted\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRunner(nil, RunnerConfig{Command: "echo", EnvFile: envFile})
+	t.Cleanup(func() { r.Shutdown() })
+
+	if env := r.captureExecutionEnvironment(store.Task{}); env.Isolation != "restricted" {
+		t.Errorf("Isolation = %q, want restricted", env.Isolation)
+	}
+
+	plain := NewRunner(nil, RunnerConfig{Command: "echo"})
+	t.Cleanup(func() { plain.Shutdown() })
+	if env := plain.captureExecutionEnvironment(store.Task{}); env.Isolation != "" {
+		t.Errorf("Isolation = %q, want empty when unconfined", env.Isolation)
+	}
+}
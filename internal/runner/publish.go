@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/sortedkeys"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// publishTimeout bounds one repository's publish command, so a hung image
+// build or upload cannot hold a background slot indefinitely.
+const publishTimeout = 30 * time.Minute
+
+// maxPublishLogBytes caps the output tail kept on a publish run.
+const maxPublishLogBytes = 16 << 10
+
+// workspacePublish returns the publish command configured on the workspace
+// the task was dispatched under, or "" when there is none.
+func (r *Runner) workspacePublish(taskID uuid.UUID) string {
+	if r.workspaceManager == nil {
+		return ""
+	}
+	ws, found, err := r.workspaceManager.WorkspaceByKey(r.taskWorkspaceKey(taskID))
+	if err != nil || !found {
+		return ""
+	}
+	return ws.Publish
+}
+
+// publishMerged runs the workspace's publish command in the background
+// against each merge commit in commitHashes (repo path → commit). The task
+// is already done; a failed publish is recorded on the task and never
+// reverts the merge.
+func (r *Runner) publishMerged(taskID uuid.UUID, commitHashes map[string]string) {
+	command := r.workspacePublish(taskID)
+	if command == "" || len(commitHashes) == 0 {
+		return
+	}
+	hashes := maps.Clone(commitHashes)
+	r.taskBackground("publish", taskID, func() {
+		r.runPublish(r.shutdownCtx, taskID, command, hashes)
+	})
+}
+
+// runPublish publishes each merged repository in turn and records one
+// PublishRun per repository on the task.
+func (r *Runner) runPublish(ctx context.Context, taskID uuid.UUID, command string, commitHashes map[string]string) {
+	s := r.taskStore(taskID)
+	bgCtx := r.shutdownCtx
+	_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSpanStart, store.SpanData{Phase: "publish", Label: "publish"})
+	defer func() {
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSpanEnd, store.SpanData{Phase: "publish", Label: "publish"})
+	}()
+
+	for repo := range sortedkeys.Of(commitHashes) {
+		run := r.publishRepo(ctx, taskID, command, repo, commitHashes[repo])
+		elapsed := run.FinishedAt.Sub(run.StartedAt).Round(time.Second)
+		if run.Status == store.PublishStatusFailed {
+			logger.Runner.Warn("workspace publish failed", "task", taskID, "repo", repo, "command", command, "error", run.Error)
+			_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+				"phase":   "publish",
+				"status":  "failed",
+				"repo":    repo,
+				"command": command,
+				"result":  fmt.Sprintf("Publish `%s` failed in %s after %s: %s\n%s", command, repo, elapsed, run.Error, truncate(run.Log, maxLintOutputBytes)),
+			})
+			continue
+		}
+		_ = s.InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]any{
+			"phase":     "publish",
+			"status":    "done",
+			"repo":      repo,
+			"command":   command,
+			"artifacts": run.Artifacts,
+			"result":    fmt.Sprintf("Publish `%s` finished in %s (%s), %d artifact(s).", command, repo, elapsed, len(run.Artifacts)),
+		})
+	}
+}
+
+// publishRepo runs command in a detached checkout of commit (see
+// runInCheckout). The command reports artifacts by writing one reference
+// per line to $WALLFACER_ARTIFACTS.
+func (r *Runner) publishRepo(ctx context.Context, taskID uuid.UUID, command, repo, commit string) store.PublishRun {
+	s := r.taskStore(taskID)
+	run := store.PublishRun{
+		Repo:      repo,
+		Commit:    commit,
+		Command:   command,
+		Status:    store.PublishStatusRunning,
+		StartedAt: time.Now(),
+	}
+	_ = s.SetPublishRun(r.shutdownCtx, taskID, run)
+	finish := func(log string, artifacts []string, err error) store.PublishRun {
+		run.FinishedAt = time.Now()
+		run.Log = tail(log, maxPublishLogBytes)
+		run.Artifacts = artifacts
+		run.Status = store.PublishStatusSucceeded
+		if err != nil {
+			run.Status = store.PublishStatusFailed
+			run.Error = err.Error()
+		}
+		if storeErr := s.SetPublishRun(r.shutdownCtx, taskID, run); storeErr != nil {
+			logger.Runner.Warn("save publish run", "task", taskID, "repo", repo, "error", storeErr)
+		}
+		return run
+	}
+
+	dir, err := os.MkdirTemp("", "wallfacer-publish-")
+	if err != nil {
+		return finish("", nil, err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	artifactsFile := filepath.Join(dir, "artifacts")
+	out, err := r.runInCheckout(ctx, taskID, command, repo, commit,
+		map[string]string{"WALLFACER_ARTIFACTS": artifactsFile}, publishTimeout)
+	return finish(out, readArtifacts(artifactsFile), err)
+}
+
+// runInCheckout runs command in a temporary detached checkout of commit,
+// outside the repository's working tree so a concurrent task's merge cannot
+// change what is built, and removes the checkout afterwards. The command
+// runs with the workspace's managed caches, extra, and WALLFACER_TASK_ID,
+// WALLFACER_REPO and WALLFACER_COMMIT set, and is stopped after timeout.
+func (r *Runner) runInCheckout(ctx context.Context, taskID uuid.UUID, command, repo, commit string, extra map[string]string, timeout time.Duration) (string, error) {
+	dir, err := os.MkdirTemp("", "wallfacer-checkout-")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	checkout := filepath.Join(dir, filepath.Base(repo))
+	if err := gitutil.CreateDetachedWorktree(repo, checkout, commit); err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := gitutil.RemoveDetachedWorktree(repo, checkout); err != nil {
+			logger.Runner.Warn("remove detached checkout", "task", taskID, "repo", repo, "error", err)
+		}
+	}()
+
+	env := maps.Clone(r.cacheEnv(taskID))
+	if env == nil {
+		env = make(map[string]string, 3+len(extra))
+	}
+	maps.Copy(env, extra)
+	env["WALLFACER_TASK_ID"] = taskID.String()
+	env["WALLFACER_REPO"] = repo
+	env["WALLFACER_COMMIT"] = commit
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return runShellCommand(runCtx, checkout, command, env)
+}
+
+// readArtifacts returns the non-empty lines of the artifacts file, or nil
+// when the command did not write one.
+func readArtifacts(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// tail returns at most the last n bytes of s.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
@@ -0,0 +1,110 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/workspace"
+)
+
+// setupPublish creates a workspace over a fresh repo with the given publish
+// command, a runner viewing it, and a task. It returns the repo's HEAD as
+// the merge commit to publish.
+func setupPublish(t *testing.T, command string) (*store.Store, *Runner, uuid.UUID, string, string) {
+	t.Helper()
+	s, r, taskID, repo := setupWorkspaceTask(t, func(mgr *workspace.Manager, wsID string) error {
+		_, err := mgr.SetPublish(wsID, command)
+		return err
+	})
+	return s, r, taskID, repo, gitRun(t, repo, "rev-parse", "HEAD")
+}
+
+func publishRuns(t *testing.T, s *store.Store, taskID uuid.UUID) []store.PublishRun {
+	t.Helper()
+	task, err := s.GetTask(context.Background(), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task.PublishRuns
+}
+
+func TestPublishMerged_RecordsArtifacts(t *testing.T) {
+	command := `test -f README.md && echo "img:$(git rev-parse HEAD)" >> "$WALLFACER_ARTIFACTS" && echo "$WALLFACER_TASK_ID" >> "$WALLFACER_ARTIFACTS"`
+	s, r, taskID, repo, commit := setupPublish(t, command)
+
+	r.publishMerged(taskID, map[string]string{repo: commit})
+	r.WaitBackground()
+
+	runs := publishRuns(t, s, taskID)
+	if len(runs) != 1 {
+		t.Fatalf("publish runs = %+v, want one", runs)
+	}
+	run := runs[0]
+	if run.Status != store.PublishStatusSucceeded || run.Repo != repo || run.Commit != commit {
+		t.Fatalf("run = %+v, want succeeded for %s@%s", run, repo, commit)
+	}
+	want := []string{"img:" + commit, taskID.String()}
+	if len(run.Artifacts) != 2 || run.Artifacts[0] != want[0] || run.Artifacts[1] != want[1] {
+		t.Errorf("artifacts = %v, want %v", run.Artifacts, want)
+	}
+	if run.FinishedAt.IsZero() {
+		t.Error("finished_at not set")
+	}
+	if out := gitRun(t, repo, "worktree", "list"); strings.Count(out, "\n") != 0 {
+		t.Errorf("publish checkout left registered:\n%s", out)
+	}
+}
+
+func TestPublishMerged_FailureIsRecorded(t *testing.T) {
+	s, r, taskID, repo, commit := setupPublish(t, "echo registry unreachable; exit 2")
+
+	r.publishMerged(taskID, map[string]string{repo: commit})
+	r.WaitBackground()
+
+	runs := publishRuns(t, s, taskID)
+	if len(runs) != 1 || runs[0].Status != store.PublishStatusFailed {
+		t.Fatalf("publish runs = %+v, want one failed run", runs)
+	}
+	if !strings.Contains(runs[0].Log, "registry unreachable") || runs[0].Error == "" {
+		t.Errorf("failed run should keep the output and error: %+v", runs[0])
+	}
+	task, err := s.GetTask(context.Background(), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != store.TaskStatusBacklog {
+		t.Errorf("status = %s, a failed publish must not change the task", task.Status)
+	}
+}
+
+func TestPublishMerged_NoCommand(t *testing.T) {
+	s, r, taskID, repo, commit := setupPublish(t, "")
+
+	r.publishMerged(taskID, map[string]string{repo: commit})
+	r.WaitBackground()
+
+	if runs := publishRuns(t, s, taskID); len(runs) != 0 {
+		t.Fatalf("publish runs = %+v, want none without a command", runs)
+	}
+}
+
+func TestReadArtifacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "artifacts")
+	if got := readArtifacts(path); got != nil {
+		t.Errorf("missing file: got %v", got)
+	}
+	if err := os.WriteFile(path, []byte("a\n\n  b  \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := readArtifacts(path); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("got %v, want [a b]", got)
+	}
+}
@@ -0,0 +1,142 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/githost"
+	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// openPullRequests stands in for rebase and merge on a task in
+// pull-request merge mode (store.MergeModePullRequest). Each repository's
+// task branch is pushed to origin and a pull request is opened for it
+// against the default branch, which is left untouched. It returns the
+// branch tips and their merge bases with the default branch, so the
+// task's diff survives worktree cleanup, and the pull request URLs.
+func (r *Runner) openPullRequests(
+	ctx context.Context,
+	taskID uuid.UUID,
+	worktreePaths map[string]string,
+	branchName string,
+) (commitHashes, baseHashes, urls map[string]string, err error) {
+	bgCtx := r.shutdownCtx
+	commitHashes = make(map[string]string)
+	baseHashes = make(map[string]string)
+	urls = make(map[string]string)
+
+	task, err := r.taskStore(taskID).GetTask(bgCtx, taskID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("get task: %w", err)
+	}
+	repos := slices.Sorted(maps.Keys(worktreePaths))
+	for i, repoPath := range repos {
+		worktreePath := worktreePaths[repoPath]
+		if _, err := os.Stat(worktreePath); err != nil {
+			logger.Runner.Warn("pull request: worktree missing, skipping", "task", taskID, "repo", repoPath, "path", worktreePath)
+			continue
+		}
+		if !gitutil.IsGitRepo(repoPath) || !gitutil.HasCommits(repoPath) {
+			return commitHashes, baseHashes, urls, fmt.Errorf("%s is not a git repository with commits; pull request mode needs one", repoPath)
+		}
+		defBranch, err := gitutil.DefaultBranch(repoPath)
+		if err != nil {
+			return commitHashes, baseHashes, urls, fmt.Errorf("defaultBranch for %s: %w", repoPath, err)
+		}
+		if ahead, err := gitutil.HasCommitsAheadOf(worktreePath, defBranch); err == nil && !ahead {
+			_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
+				"result": fmt.Sprintf("Skipping %s — no new commits for a pull request.", repoPath),
+			})
+			continue
+		}
+
+		r.pipelineProgress(taskID, store.PipelineProgressData{
+			Phase:   store.PipelinePhasePullRequest,
+			Repo:    repoPath,
+			Percent: repoProgress{index: i, count: len(repos)}.percent(store.PipelinePhasePullRequest, 0),
+			Message: fmt.Sprintf("Pushing %s and opening a pull request into %s...", branchName, defBranch),
+		})
+		url, err := r.openPullRequest(ctx, repoPath, worktreePath, branchName, defBranch, task)
+		if err != nil {
+			return commitHashes, baseHashes, urls, fmt.Errorf("pull request for %s: %w", repoPath, err)
+		}
+		urls[repoPath] = url
+		if tip, err := gitutil.GetCommitHash(worktreePath); err == nil {
+			commitHashes[repoPath] = tip
+		}
+		if base, err := gitutil.MergeBase(worktreePath, defBranch, "HEAD"); err == nil {
+			baseHashes[repoPath] = base
+		}
+		_ = r.taskStore(taskID).InsertEvent(bgCtx, taskID, store.EventTypeSystem, map[string]string{
+			"result": fmt.Sprintf("Opened pull request for %s: %s", filepath.Base(repoPath), url),
+			"phase":  "pull_request",
+			"repo":   repoPath,
+			"url":    url,
+		})
+	}
+	return commitHashes, baseHashes, urls, nil
+}
+
+// openPullRequest pushes branchName from worktreePath to the origin of
+// repoPath and opens a pull request for it into base, returning its URL.
+func (r *Runner) openPullRequest(ctx context.Context, repoPath, worktreePath, branchName, base string, task *store.Task) (string, error) {
+	remote, err := cmdexec.Git(repoPath, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return "", fmt.Errorf("no origin remote: %w", err)
+	}
+	repo, ok := githost.ParseRemote(remote)
+	if !ok {
+		return "", fmt.Errorf("origin %q is not a hosted repository", remote)
+	}
+	provider, err := r.gitHostProvider(repo)
+	if err != nil {
+		return "", err
+	}
+	if out, err := cmdexec.Git(worktreePath, "push", "--set-upstream", "origin", branchName).WithContext(ctx).Combined(); err != nil {
+		return "", fmt.Errorf("push %s: %w\n%s", branchName, err, out)
+	}
+	return provider.OpenPullRequest(ctx, repo, githost.PullRequest{
+		Title: pullRequestTitle(task),
+		Body:  task.CommitMessage,
+		Head:  branchName,
+		Base:  base,
+	})
+}
+
+// gitHostProvider returns the pull request provider for repo, using the
+// gitHost override when one is set and the env file's tokens otherwise.
+func (r *Runner) gitHostProvider(repo githost.Repo) (githost.Provider, error) {
+	if r.gitHost != nil {
+		return r.gitHost(repo)
+	}
+	var tokens githost.Tokens
+	if r.envFile != "" {
+		if cfg, err := envconfig.Parse(r.envFile); err == nil {
+			tokens = githost.Tokens{GitHub: cfg.GitHubToken, GitLab: cfg.GitLabToken}
+		}
+	}
+	return githost.ForRepo(repo, tokens)
+}
+
+// pullRequestTitle prefers the task's title, then the subject of its
+// commit message, then a branch-based fallback.
+func pullRequestTitle(task *store.Task) string {
+	if t := strings.TrimSpace(task.Title); t != "" {
+		return t
+	}
+	if subject, _, _ := strings.Cut(strings.TrimSpace(task.CommitMessage), "\n"); subject != "" {
+		return subject
+	}
+	return "Changes from " + task.BranchName
+}
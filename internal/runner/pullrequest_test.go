@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/githost"
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/store/storetest"
+)
+
+type fakeGitHost struct {
+	repo githost.Repo
+	pr   githost.PullRequest
+}
+
+func (f *fakeGitHost) Name() string { return "fake" }
+
+func (f *fakeGitHost) OpenPullRequest(_ context.Context, repo githost.Repo, pr githost.PullRequest) (string, error) {
+	f.repo, f.pr = repo, pr
+	return "https://github.com/o/r/pull/1", nil
+}
+
+func TestCommit_PullRequestModeLeavesDefaultBranch(t *testing.T) {
+	repo := setupTestRepo(t)
+	origin := t.TempDir()
+	gitRun(t, origin, "init", "--bare", "-b", "main")
+	gitRun(t, repo, "remote", "add", "origin", "https://github.com/o/r.git")
+	gitRun(t, repo, "remote", "set-url", "--push", "origin", origin)
+	mainBefore := strings.TrimSpace(gitRun(t, repo, "rev-parse", "main"))
+
+	cmd := fakeCmdScript(t, validStreamJSON, 0) // for commit message generation
+	s, err := storetest.NewFileStore(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	resolved := resolveTestCmd(cmd)
+	r := NewRunner(s, RunnerConfig{
+		Command:          cmd,
+		Workspaces:       []string{repo},
+		WorktreesDir:     t.TempDir(),
+		HostClaudeBinary: resolved,
+		HostCodexBinary:  resolved,
+	})
+	t.Cleanup(func() { r.Shutdown() })
+	host := &fakeGitHost{}
+	r.gitHost = func(githost.Repo) (githost.Provider, error) { return host, nil }
+
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "add feature", Timeout: 5, MergeMode: store.MergeModePullRequest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktreePaths, branchName, err := r.setupWorktrees(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskWorktrees(ctx, task.ID, worktreePaths, branchName); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePaths[repo], "feature.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Commit(task.ID, "sess1"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if got := strings.TrimSpace(gitRun(t, repo, "rev-parse", "main")); got != mainBefore {
+		t.Errorf("main moved from %s to %s; pull request mode must not merge", mainBefore, got)
+	}
+	pushed := strings.TrimSpace(gitRun(t, origin, "rev-parse", branchName))
+	if host.repo.Path != "o/r" || host.pr.Head != branchName || host.pr.Base != "main" || host.pr.Title == "" {
+		t.Errorf("opened %+v on %+v", host.pr, host.repo)
+	}
+	got, _ := s.GetTask(ctx, task.ID)
+	if got.PullRequestURLs[repo] != "https://github.com/o/r/pull/1" {
+		t.Errorf("PullRequestURLs = %v", got.PullRequestURLs)
+	}
+	if got.CommitHashes[repo] != pushed || got.BaseCommitHashes[repo] != mainBefore {
+		t.Errorf("commit range = %s..%s, want %s..%s", got.BaseCommitHashes[repo], got.CommitHashes[repo], mainBefore, pushed)
+	}
+}
@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/pkg/egress"
+	"latere.ai/x/wallfacer/internal/prompts"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// agentAPIDomains are the model-provider hosts a research agent must reach
+// to run at all. They are allowlisted alongside the task's own domains.
+var agentAPIDomains = []string{"anthropic.com", "claude.ai", "openai.com", "chatgpt.com"}
+
+// researchTimeBox caps a research task's total timeout. The store clamps the
+// timeout at creation; this also bounds tasks whose timeout was edited later.
+func researchTimeBox(timeout time.Duration) time.Duration {
+	return min(timeout, store.MaxResearchTimeoutMinutes*time.Minute)
+}
+
+// researchPrompt wraps a fresh prompt of a research task with the allowlist,
+// time box, and citation instructions. Other tasks, and the empty prompt of
+// an auto-continue turn, are returned unchanged.
+func (r *Runner) researchPrompt(task *store.Task, prompt string) string {
+	if task.Kind != store.TaskKindResearch || prompt == "" {
+		return prompt
+	}
+	timeBox := task.Timeout
+	if timeBox <= 0 || timeBox > store.MaxResearchTimeoutMinutes {
+		timeBox = store.MaxResearchTimeoutMinutes
+	}
+	return r.promptsMgr.Research(prompts.ResearchData{
+		Prompt:         prompt,
+		Domains:        task.ResearchDomains,
+		TimeBoxMinutes: timeBox,
+	})
+}
+
+// researchEgressPolicy returns the allowlist a research task's agent runs
+// under: the task's domains plus the model provider hosts, including any
+// configured base URL.
+func (r *Runner) researchEgressPolicy(task *store.Task) egress.Policy {
+	domains := append(append([]string(nil), task.ResearchDomains...), agentAPIDomains...)
+	if r.envFile != "" {
+		if cfg, err := envconfig.Parse(r.envFile); err == nil {
+			for _, base := range []string{cfg.BaseURL, cfg.OpenAIBaseURL} {
+				if d := egress.NormalizeDomain(base); d != "" {
+					domains = append(domains, d)
+				}
+			}
+		}
+	}
+	return egress.Policy{Domains: domains}
+}
+
+// startResearchEgress starts the allowlisting proxy a research task's agent
+// is pointed at. Each host the proxy refuses is recorded once as a system
+// event so the task log shows what the agent tried to reach.
+func (r *Runner) startResearchEgress(task *store.Task) (*egress.Proxy, error) {
+	taskID := task.ID
+	var mu sync.Mutex
+	blocked := map[string]bool{}
+	return egress.Start(r.researchEgressPolicy(task), func(req egress.Request) {
+		if req.Allowed {
+			return
+		}
+		mu.Lock()
+		seen := blocked[req.Host]
+		blocked[req.Host] = true
+		mu.Unlock()
+		if seen {
+			return
+		}
+		_ = r.taskStore(taskID).InsertEvent(r.shutdownCtx, taskID, store.EventTypeSystem, map[string]any{
+			"phase":  "research",
+			"status": "blocked",
+			"host":   req.Host,
+			"result": "Research egress blocked: " + req.Host + " is not on the allowlist.",
+		})
+	})
+}
+
+// citationLog accumulates the distinct URLs a research task fetched during
+// one Run, in the order they were first fetched.
+type citationLog struct {
+	seen map[string]bool
+	urls []string
+}
+
+// recordCitations adds the URLs fetched in one turn's output to cites and
+// inserts a citation event for each one not seen before.
+func (r *Runner) recordCitations(taskID uuid.UUID, cites *citationLog, rawStdout []byte) {
+	for _, u := range fetchedURLs(rawStdout) {
+		if cites.seen[u] {
+			continue
+		}
+		if cites.seen == nil {
+			cites.seen = make(map[string]bool)
+		}
+		cites.seen[u] = true
+		cites.urls = append(cites.urls, u)
+		_ = r.taskStore(taskID).InsertEvent(r.shutdownCtx, taskID, store.EventTypeSystem, map[string]any{
+			"phase":    "research",
+			"status":   "citation",
+			"citation": u,
+			"result":   "Fetched " + u,
+		})
+	}
+}
+
+// fetchedURLs returns the URLs passed to web-fetch tool calls in a turn's
+// NDJSON output, in order and without duplicates. Both the Claude
+// (assistant tool_use blocks) and Codex (item events) formats are read.
+func fetchedURLs(raw []byte) []string {
+	var urls []string
+	seen := map[string]bool{}
+	add := func(tool, input string) {
+		if tool != "WebFetch" {
+			return
+		}
+		u, err := url.Parse(strings.TrimSpace(input))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return
+		}
+		if s := u.String(); !seen[s] {
+			seen[s] = true
+			urls = append(urls, s)
+		}
+	}
+	for line := range strings.SplitSeq(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var msg ndjsonLine
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			continue
+		}
+		if msg.Item != nil {
+			add(codexToolFromItem(msg.Item))
+			continue
+		}
+		if msg.Type != "assistant" || len(msg.Message) == 0 {
+			continue
+		}
+		var m ndjsonMessage
+		if err := json.Unmarshal(msg.Message, &m); err != nil {
+			continue
+		}
+		for _, block := range parseContentBlocks(m.Content) {
+			if block.Type != "tool_use" && block.Type != "tool_call" {
+				continue
+			}
+			name := canonicalizeToolName(block.Name)
+			if name == "" {
+				name = canonicalizeToolName(block.Tool)
+			}
+			add(name, extractToolInputGo(name, parseRawInput(block.Input)))
+		}
+	}
+	return urls
+}
+
+// withSourcesSection appends a "## Sources" list of the fetched URLs to a
+// research result that does not already have one, so every research
+// result names where its answer came from.
+func withSourcesSection(result string, urls []string) string {
+	if len(urls) == 0 || strings.Contains(result, "## Sources") {
+		return result
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(result, "\n"))
+	b.WriteString("\n\n## Sources\n\n")
+	for i, u := range urls {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, u)
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// TestFetchedURLs verifies that web-fetch URLs are collected from both the
+// Claude and Codex NDJSON formats, in order and without duplicates, and that
+// other tools and non-HTTP inputs are ignored.
+func TestFetchedURLs(t *testing.T) {
+	raw := strings.Join([]string{
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"WebFetch","input":{"url":"https://go.dev/doc/","prompt":"summarize"}}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Read","input":{"file_path":"/workspace/main.go"}}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"WebSearch","input":{"query":"go scheduler"}}]}}`,
+		`{"type":"item.started","item":{"id":"1","type":"web_fetch","input":{"url":"https://pkg.go.dev/sync"}}}`,
+		`{"type":"item.completed","item":{"id":"1","type":"web_fetch","input":{"url":"https://pkg.go.dev/sync"}}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"WebFetch","input":{"url":"https://go.dev/doc/"}}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"WebFetch","input":{"url":"file:///etc/passwd"}}]}}`,
+		`not json`,
+	}, "\n")
+	got := fetchedURLs([]byte(raw))
+	want := []string{"https://go.dev/doc/", "https://pkg.go.dev/sync"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("fetchedURLs = %v, want %v", got, want)
+	}
+}
+
+func TestWithSourcesSection(t *testing.T) {
+	urls := []string{"https://go.dev/doc/", "https://pkg.go.dev/sync"}
+
+	got := withSourcesSection("The answer [1].\n", urls)
+	want := "The answer [1].\n\n## Sources\n\n1. https://go.dev/doc/\n2. https://pkg.go.dev/sync\n"
+	if got != want {
+		t.Fatalf("withSourcesSection = %q, want %q", got, want)
+	}
+
+	existing := "The answer [1].\n\n## Sources\n\n1. https://go.dev/doc/"
+	if got := withSourcesSection(existing, urls); got != existing {
+		t.Fatalf("existing sources section was rewritten: %q", got)
+	}
+	if got := withSourcesSection("No fetches.", nil); got != "No fetches." {
+		t.Fatalf("result without citations was changed: %q", got)
+	}
+}
+
+func TestResearchTimeBox(t *testing.T) {
+	if got := researchTimeBox(2 * time.Hour); got != store.MaxResearchTimeoutMinutes*time.Minute {
+		t.Fatalf("researchTimeBox(2h) = %v, want the research cap", got)
+	}
+	if got := researchTimeBox(5 * time.Minute); got != 5*time.Minute {
+		t.Fatalf("researchTimeBox(5m) = %v, want 5m", got)
+	}
+}
+
+// TestResearchEgressPolicy verifies that the research allowlist admits the
+// task's domains and the model provider APIs and nothing else.
+func TestResearchEgressPolicy(t *testing.T) {
+	r := &Runner{}
+	p := r.researchEgressPolicy(&store.Task{Kind: store.TaskKindResearch, ResearchDomains: []string{"go.dev"}})
+	for _, host := range []string{"go.dev", "pkg.go.dev", "api.anthropic.com", "api.openai.com"} {
+		if !p.Allows(host) {
+			t.Errorf("Allows(%q) = false, want true", host)
+		}
+	}
+	if p.Allows("example.com") {
+		t.Error("Allows(example.com) = true, want false")
+	}
+}
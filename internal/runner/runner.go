@@ -15,8 +15,10 @@ import (
 	"latere.ai/x/wallfacer/internal/agents"
 	"latere.ai/x/wallfacer/internal/agentsession"
 	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/envconfig"
 	"latere.ai/x/wallfacer/internal/executor"
 	"latere.ai/x/wallfacer/internal/flow"
+	"latere.ai/x/wallfacer/internal/githost"
 	"latere.ai/x/wallfacer/internal/logger"
 	"latere.ai/x/wallfacer/internal/metrics"
 	"latere.ai/x/wallfacer/internal/pkg/circuitbreaker"
@@ -90,11 +92,13 @@ type RunnerConfig struct {
 	HostClaudeBinary   string           // optional override for the `claude` binary path
 	HostCodexBinary    string           // optional override for the `codex` binary path
 	HostCursorBinary   string           // optional override for the `cursor-agent` binary path
+	HostGeminiBinary   string           // optional override for the `gemini` binary path
 	HostOpenCodeBinary string           // optional override for the `opencode` binary path
 	HostPiBinary       string           // optional override for the `pi` binary path
 	AgentNice          int              // niceness for agent processes (0 ⇒ default, negative disables)
 	MaxAgents          int              // global concurrent agent-process budget (0 ⇒ unlimited)
 	TmpDir             string           // base dir for ephemeral files bind-mounted into containers (must be Docker-accessible)
+	CachesDir          string           // root of the per-workspace package-manager caches (see devcache); empty leaves agents on their default caches
 	Prompts            *prompts.Manager // prompt template manager; nil = use prompts.Default
 	WorkspaceManager   *workspace.Manager
 	Reg                *metrics.Registry // optional metrics registry; nil disables metric collection
@@ -150,21 +154,31 @@ type Runner struct {
 	workspaces       []string
 	worktreesDir     string
 	tmpDir           string
+	cachesDir        string
 	workspaceManager *workspace.Manager
 	codexAuthPath    string
-	promptsMgr       *prompts.Manager                     // prompt template manager
-	worktreeMu       sync.Mutex                           // serializes all worktree filesystem operations on worktreesDir
-	repoMu           keyedmu.Map[string]                  // per-repo mutex for serializing rebase+merge
-	taskContainers   *containerRegistry                   // taskID → container name
-	liveLogs         syncmap.Map[uuid.UUID, *livelog.Log] // live log buffers for in-progress turns
-	oversightMu      keyedmu.Map[string]                  // per-task mutex for serializing oversight generation
-	containerCB      *circuitbreaker.Breaker              // circuit breaker for container launch operations
-	backend          executor.Backend                     // pluggable sandbox backend (local podman/docker, host, future: k8s)
-	backgroundWg     trackedwg.WaitGroup                  // tracks fire-and-forget background goroutines
+	promptsMgr       *prompts.Manager                          // prompt template manager
+	worktreeMu       sync.Mutex                                // serializes all worktree filesystem operations on worktreesDir
+	mergeQueue       mergeQueue                                // per-repo FIFO lock serializing rebase+merge
+	taskContainers   *containerRegistry                        // taskID → container name
+	liveLogs         syncmap.Map[uuid.UUID, *livelog.Log]      // live log buffers for in-progress turns
+	turnExits        syncmap.Map[uuid.UUID, executor.ExitInfo] // exit metadata of each task's latest runContainer launch
+	taskInputs       syncmap.Map[uuid.UUID, taskInput]         // open stdin of each task's running interactive turn
+	taskRuns         syncmap.Map[uuid.UUID, *trackedRun]       // cancels each running task's run context
+	titles           titleQueue                                // bounded, batching queue behind GenerateTitleBackground
+	oversightMu      keyedmu.Map[string]                       // per-task mutex for serializing oversight generation
+	containerCB      *circuitbreaker.Breaker                   // circuit breaker for container launch operations
+	backend          executor.Backend                          // pluggable sandbox backend (local podman/docker, host, future: k8s)
+	backgroundWg     trackedwg.WaitGroup                       // tracks fire-and-forget background goroutines
 	stopReasonMu     sync.RWMutex
 	onStopReason     func(taskID uuid.UUID, stopReason string)
 	agentSession     *agentsession.Runtime // agent session for chat; may be nil
 
+	// gitHost returns the provider that opens pull requests for a task in
+	// pull-request merge mode. nil means githost.ForRepo with the env
+	// file's tokens; tests substitute a fake.
+	gitHost func(githost.Repo) (githost.Provider, error)
+
 	// Board context cache: avoids redundant store.ListTasks calls on every turn
 	// when no task has changed since the last generation. Keyed by
 	// (boardChangeSeq, selfTaskID): a cache hit means no store mutation
@@ -195,6 +209,9 @@ type Runner struct {
 	flows      *flow.Registry
 	flowsDir   string // ~/.wallfacer/flows by default
 	flowEngine *flow.Engine
+
+	// agentProbe holds the latest capability probe; nil until one has run.
+	agentProbe atomic.Pointer[AgentProbe]
 }
 
 // ShutdownCtx returns the runner's shutdown context. It is cancelled when
@@ -319,6 +336,14 @@ func (r *Runner) Shutdown() {
 // so that WaitBackground can drain all outstanding work — particularly useful
 // in tests to prevent cleanup races with temp-dir removal.
 func (r *Runner) RunBackground(taskID uuid.UUID, prompt, sessionID string, resumedFromWaiting bool) {
+	// Capture the current workspace key at dispatch time so the task uses the
+	// correct store even if the user switches workspaces during execution.
+	r.runBackgroundIn(r.currentWSKey(), taskID, prompt, sessionID, resumedFromWaiting)
+}
+
+// runBackgroundIn is RunBackground for a task of the workspace with key
+// wsKey, which need not be the one currently viewed.
+func (r *Runner) runBackgroundIn(wsKey string, taskID uuid.UUID, prompt, sessionID string, resumedFromWaiting bool) {
 	// Bail out if shutdown has already been initiated. This prevents a race
 	// where backgroundWg.Add (inside Go) runs concurrently with
 	// backgroundWg.Wait in Shutdown — sync.WaitGroup forbids that when the
@@ -327,9 +352,6 @@ func (r *Runner) RunBackground(taskID uuid.UUID, prompt, sessionID string, resum
 		return
 	}
 
-	// Capture the current workspace key at dispatch time so the task uses the
-	// correct store even if the user switches workspaces during execution.
-	wsKey := r.currentWSKey()
 	r.taskWSKey.Store(taskID, wsKey)
 	if r.workspaceManager != nil {
 		r.workspaceManager.IncrementTaskCount(wsKey)
@@ -375,10 +397,15 @@ func (r *Runner) GenerateOversightBackground(taskID uuid.UUID) {
 	r.taskBackground("oversight", taskID, func() { r.GenerateOversight(taskID) })
 }
 
-// GenerateTitleBackground launches GenerateTitle in a background goroutine
-// tracked by backgroundWg so that WaitBackground can drain it before cleanup.
+// GenerateTitleBackground queues title generation for the task. Requests are
+// drained by a bounded pool of workers tracked by backgroundWg (so
+// WaitBackground can drain them before cleanup), which title several queued
+// tasks per agent call; see titleQueue.
 func (r *Runner) GenerateTitleBackground(taskID uuid.UUID, prompt string) {
-	r.taskBackground("title", taskID, func() { r.GenerateTitle(taskID, prompt) })
+	if r.titles.push(titleJob{taskID: taskID, prompt: prompt}) && !r.backgroundWg.Go("title-worker", r.titleWorker) {
+		// Shutting down: the worker never started, so give back its slot.
+		r.titles.retire()
+	}
 }
 
 // NewRunner constructs a Runner from the given store and config. The returned
@@ -398,6 +425,7 @@ func NewRunner(s *store.Store, cfg RunnerConfig) *Runner {
 		workspaces:       cfg.Workspaces,
 		worktreesDir:     cfg.WorktreesDir,
 		tmpDir:           cfg.TmpDir,
+		cachesDir:        cfg.CachesDir,
 		codexAuthPath:    strings.TrimSpace(cfg.CodexAuthPath),
 		promptsMgr:       mgr,
 		workspaceManager: cfg.WorkspaceManager,
@@ -455,6 +483,8 @@ func NewRunner(s *store.Store, cfg RunnerConfig) *Runner {
 	cbThreshold := envutil.IntMin("WALLFACER_CONTAINER_CB_THRESHOLD", constants.DefaultCBThreshold, 1)
 	cbOpenSec := envutil.IntMin("WALLFACER_CONTAINER_CB_OPEN_SECONDS", 30, 1)
 	r.containerCB = circuitbreaker.New(cbThreshold, time.Duration(cbOpenSec)*time.Second)
+	r.titles.concurrency = envutil.IntMin("WALLFACER_TITLE_CONCURRENCY", constants.DefaultTitleConcurrency, 1)
+	r.titles.batchSize = envutil.IntMin("WALLFACER_TITLE_BATCH_SIZE", constants.DefaultTitleBatchSize, 1)
 	// Best-effort construction: an unresolved agent binary yields a backend
 	// whose Launch returns a clear error, rather than crashing the process.
 	// The run command fails fast separately via executor.RequireClaude, so the
@@ -463,10 +493,12 @@ func NewRunner(s *store.Store, cfg RunnerConfig) *Runner {
 		ClaudeBinary:   cfg.HostClaudeBinary,
 		CodexBinary:    cfg.HostCodexBinary,
 		CursorBinary:   cfg.HostCursorBinary,
+		GeminiBinary:   cfg.HostGeminiBinary,
 		OpenCodeBinary: cfg.HostOpenCodeBinary,
 		PiBinary:       cfg.HostPiBinary,
 		AgentNice:      cfg.AgentNice,
 		MaxAgents:      cfg.MaxAgents,
+		MaxPromptBytes: envutil.Int("WALLFACER_MAX_PROMPT_BYTES", constants.DefaultMaxPromptBytes),
 	})
 	r.backend = hb
 	r.reg = cfg.Reg
@@ -541,6 +573,31 @@ func (r *Runner) ReloadFlows() error {
 	return nil
 }
 
+// ReloadConfig re-reads the env file and applies the settings that are
+// otherwise fixed at startup to the agent backend: the host CLI binaries,
+// the agent niceness, and the global agent budget. Running agents are not
+// affected. Every other env-file setting is already read per launch.
+func (r *Runner) ReloadConfig() error {
+	cfg, err := envconfig.Parse(r.resolveEnvFile())
+	if err != nil {
+		return err
+	}
+	hb, ok := r.backend.(*executor.HostBackend)
+	if !ok {
+		return nil
+	}
+	return hb.Reconfigure(executor.HostBackendConfig{
+		ClaudeBinary:   cfg.HostClaudeBinary,
+		CodexBinary:    cfg.HostCodexBinary,
+		CursorBinary:   cfg.HostCursorBinary,
+		GeminiBinary:   cfg.HostGeminiBinary,
+		OpenCodeBinary: cfg.HostOpenCodeBinary,
+		PiBinary:       cfg.HostPiBinary,
+		AgentNice:      cfg.AgentNice,
+		MaxAgents:      cfg.MaxAgents,
+	})
+}
+
 // WorkspaceManager returns the runner's workspace manager.
 func (r *Runner) WorkspaceManager() *workspace.Manager {
 	return r.workspaceManager
@@ -780,23 +837,38 @@ func isJWTExpired(jwt string, now time.Time) bool {
 	return now.Unix() >= claims.Exp
 }
 
-// repoLock returns a per-repo mutex, creating one on first access.
-// Used to serialize rebase+merge operations on the same repository.
-func (r *Runner) repoLock(repoPath string) *sync.Mutex {
-	return r.repoMu.Get(repoPath)
-}
-
 // oversightLock returns the per-task mutex for serialising oversight generation.
 // The mutex is created on first access and stored in oversightMu.
 func (r *Runner) oversightLock(taskID uuid.UUID) *sync.Mutex {
 	return r.oversightMu.Get(taskID.String())
 }
 
+// trackedRun is one registered run of a task; its address tells runs of
+// the same task apart.
+type trackedRun struct {
+	cancel context.CancelFunc
+}
+
+// trackRun registers cancel as the way KillContainer ends taskID's run
+// context and returns the func that unregisters it. A run that finishes
+// after a newer run of the same task started leaves the newer
+// registration in place.
+func (r *Runner) trackRun(taskID uuid.UUID, cancel context.CancelFunc) func() {
+	run := &trackedRun{cancel: cancel}
+	r.taskRuns.Store(taskID, run)
+	return func() { r.taskRuns.CompareAndDelete(taskID, run) }
+}
+
 // KillContainer sends a kill signal to the running container for a task.
 // Kill goes through the SandboxHandle when registered; otherwise it is a no-op
 // (container already exited or was never launched).
+// It also cancels the task's run context, so a kill that lands between
+// turns or flow steps still keeps the next one from launching.
 // Safe to call when no container is running — errors are silently ignored.
 func (r *Runner) KillContainer(taskID uuid.UUID) {
+	if run, ok := r.taskRuns.Load(taskID); ok {
+		run.cancel()
+	}
 	if h := r.taskContainers.GetHandle(taskID); h != nil {
 		_ = h.Kill()
 	}
@@ -100,6 +100,40 @@ func setupTestRunnerWithManager(t *testing.T, workspaces []string, mgr *workspac
 	return s, runner
 }
 
+// setupWorkspaceTask creates a workspace over a fresh repo, lets configure
+// apply one workspace setting to it, and returns a runner viewing it, a
+// task, and the repo.
+func setupWorkspaceTask(t *testing.T, configure func(mgr *workspace.Manager, wsID string) error) (*store.Store, *Runner, uuid.UUID, string) {
+	t.Helper()
+	repo := setupTestRepo(t)
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	mgr, err := workspace.NewManager(t.TempDir(), t.TempDir(), envFile, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := mgr.Create("app", []string{repo}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := configure(mgr, ws.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.SwitchByID(ws.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, r := setupTestRunnerWithManager(t, []string{repo}, mgr)
+	s := r.currentStore()
+
+	task, err := s.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "build it", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, r, task.ID, repo
+}
+
 func enableCommitMessageGeneration(t *testing.T, runner *Runner) {
 	t.Helper()
 	cmd := fakeCmdScript(t, `{"result":"wallfacer: generated commit","session_id":"abc123","stop_reason":"end_turn","is_error":false}`, 0)
@@ -1180,6 +1214,10 @@ func TestCommitPipelineBaseHashUsesDefBranch(t *testing.T) {
 	if base == featureHash {
 		t.Error("BaseCommitHashes incorrectly captured the feature branch HEAD")
 	}
+	// The merged diff is precomputed for the review view.
+	if diff, ok := s.DiffSnapshot(task.ID, repo, base, updated.CommitHashes[repo]); !ok || !strings.Contains(diff, "task.txt") {
+		t.Errorf("diff snapshot = %q, %v; want the task's change", diff, ok)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -1300,3 +1338,20 @@ func TestCommitPipelineNoChangesStoresBaseHash(t *testing.T) {
 		t.Errorf("BaseCommitHashes = %q, want main HEAD %q", base, mainHash)
 	}
 }
+
+// TestReloadConfig_AppliesEnvFile verifies a reload picks up env-file edits
+// made after startup and reports an explicit agent binary that does not exist.
+func TestReloadConfig_AppliesEnvFile(t *testing.T) {
+	r := newRunnerWithEnvFile(t, "WALLFACER_MAX_AGENTS=2\n")
+	if err := r.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+
+	missing := filepath.Join(t.TempDir(), "no-claude")
+	if err := os.WriteFile(r.EnvFile(), []byte("WALLFACER_HOST_CLAUDE_BINARY="+missing+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ReloadConfig(); err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("ReloadConfig with a missing claude binary: err = %v", err)
+	}
+}
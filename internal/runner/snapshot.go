@@ -1,14 +1,13 @@
 package runner
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 
+	"latere.ai/x/wallfacer/internal/envconfig"
 	"latere.ai/x/wallfacer/internal/gitutil"
 	"latere.ai/x/wallfacer/internal/logger"
-	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
 	"latere.ai/x/wallfacer/internal/pkg/dircp"
 )
 
@@ -35,36 +34,32 @@ func setupNonGitSnapshot(ws, snapshotPath string) error {
 	return nil
 }
 
-// extractSnapshotToWorkspace copies all changes from snapshotPath back to
-// the original workspace at targetPath, excluding the .git directory that was
-// added for change tracking. Uses rsync when available (handles deletions);
-// falls back to a Go-native copy which covers new/modified files only.
-func extractSnapshotToWorkspace(snapshotPath, targetPath string) error {
-	// rsync handles new, modified, AND deleted files correctly via --delete.
-	// --checksum is needed because files may have the same size and mtime
-	// but different content (e.g. macOS openrsync skips them otherwise).
-	// The trailing "/" on both paths is critical: it means "copy contents of
-	// snapshotPath into targetPath" rather than creating a subdirectory.
-	if _, err := exec.LookPath("rsync"); err == nil {
-		out, err := cmdexec.New(
-			"rsync", "-a", "--checksum", "--delete", "--exclude=.git",
-			snapshotPath+"/", targetPath+"/",
-		).Combined()
-		if err != nil {
-			return fmt.Errorf("rsync snapshot to workspace: %w\n%s", err, out)
-		}
-		return nil
+// extractSnapshotToWorkspace copies the changes in snapshotPath back to the
+// original workspace at targetPath with [dircp.Sync], skipping the .git
+// directory added for change tracking and any entry matching ignore. Files
+// that were in the snapshot's initial commit but are gone from the snapshot
+// are deleted from the workspace; workspace files the snapshot never tracked
+// are left alone. The returned manifest lists every path that changed.
+func extractSnapshotToWorkspace(ctx context.Context, snapshotPath, targetPath string, ignore []string) (dircp.Manifest, error) {
+	baseline, err := gitutil.SnapshotBaseline(ctx, snapshotPath)
+	if err != nil {
+		logger.Runner.Warn("snapshot baseline unavailable; deletions will not propagate to workspace",
+			"snapshot", snapshotPath, "error", err)
 	}
-	// Fallback: copy covers new/modified files; files deleted inside the sandbox
-	// are not removed from the original workspace.
-	logger.Runner.Warn("rsync not found; falling back to copy (deletions will not propagate to workspace)",
-		"snapshot", snapshotPath, "target", targetPath)
-	if err := dircp.Copy(snapshotPath, targetPath); err != nil {
-		return fmt.Errorf("copy snapshot to workspace: %w", err)
+	m, err := dircp.Sync(snapshotPath, targetPath, dircp.SyncOptions{Ignore: ignore, Baseline: baseline})
+	if err != nil {
+		return m, fmt.Errorf("sync snapshot to workspace: %w", err)
 	}
-	// Remove the .git directory that the copy may have brought over from the snapshot.
-	if err := os.RemoveAll(filepath.Join(targetPath, ".git")); err != nil {
-		logger.Runner.Warn("failed to remove .git from extracted snapshot", "path", targetPath, "error", err)
+	return m, nil
+}
+
+// snapshotIgnore returns the patterns skipped when extracting a snapshot:
+// WALLFACER_SNAPSHOT_IGNORE when it is set, otherwise [dircp.DefaultIgnore].
+func (r *Runner) snapshotIgnore() []string {
+	if r.envFile != "" {
+		if cfg, err := envconfig.Parse(r.envFile); err == nil && cfg.SnapshotIgnore != nil {
+			return cfg.SnapshotIgnore
+		}
 	}
-	return nil
+	return dircp.DefaultIgnore
 }
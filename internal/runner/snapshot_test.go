@@ -5,11 +5,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"latere.ai/x/wallfacer/internal/gitutil"
+	"latere.ai/x/wallfacer/internal/pkg/dircp"
 	"latere.ai/x/wallfacer/internal/store"
 )
 
@@ -107,7 +109,7 @@ func TestExtractSnapshotToWorkspace(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := extractSnapshotToWorkspace(snapshot, target); err != nil {
+	if _, err := extractSnapshotToWorkspace(context.Background(), snapshot, target, nil); err != nil {
 		t.Fatal("extractSnapshotToWorkspace:", err)
 	}
 
@@ -124,8 +126,7 @@ func TestExtractSnapshotToWorkspace(t *testing.T) {
 }
 
 // TestExtractSnapshotDoesNotLeakGitDir verifies that the .git directory from
-// the snapshot is not extracted to the target workspace. rsync excludes it;
-// the cp fallback removes it afterward.
+// the snapshot is not extracted to the target workspace.
 func TestExtractSnapshotDoesNotLeakGitDir(t *testing.T) {
 	snapshot := t.TempDir()
 	target := t.TempDir()
@@ -141,7 +142,7 @@ func TestExtractSnapshotDoesNotLeakGitDir(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := extractSnapshotToWorkspace(snapshot, target); err != nil {
+	if _, err := extractSnapshotToWorkspace(context.Background(), snapshot, target, nil); err != nil {
 		t.Fatal("extractSnapshotToWorkspace:", err)
 	}
 
@@ -149,6 +150,85 @@ func TestExtractSnapshotDoesNotLeakGitDir(t *testing.T) {
 	if _, err := os.Stat(filepath.Join(target, "app.txt")); err != nil {
 		t.Fatal("app.txt should be in target:", err)
 	}
+	if _, err := os.Stat(filepath.Join(target, ".git")); !os.IsNotExist(err) {
+		t.Fatal(".git should not be extracted:", err)
+	}
+}
+
+// TestExtractSnapshotManifest verifies the full round trip: changes made in
+// a snapshot, including a deletion, are applied to the workspace, ignored
+// junk is skipped, untracked workspace files survive, and the manifest
+// reports each change.
+func TestExtractSnapshotManifest(t *testing.T) {
+	ws := t.TempDir()
+	for name, content := range map[string]string{"keep.txt": "keep", "edit.txt": "old", "drop.txt": "drop"} {
+		if err := os.WriteFile(filepath.Join(ws, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshot := filepath.Join(t.TempDir(), "snapshot")
+	if err := setupNonGitSnapshot(ws, snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(snapshot, "edit.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(snapshot, "drop.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(snapshot, "add.txt"), []byte("add"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(snapshot, ".DS_Store"), []byte("junk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Created in the workspace after the snapshot was taken.
+	if err := os.WriteFile(filepath.Join(ws, "local.txt"), []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := extractSnapshotToWorkspace(context.Background(), snapshot, ws, dircp.DefaultIgnore)
+	if err != nil {
+		t.Fatal("extractSnapshotToWorkspace:", err)
+	}
+	if !slices.Equal(m.Copied, []string{"add.txt"}) || !slices.Equal(m.Overwritten, []string{"edit.txt"}) ||
+		!slices.Equal(m.Deleted, []string{"drop.txt"}) {
+		t.Errorf("manifest = %+v", m)
+	}
+	if got, _ := os.ReadFile(filepath.Join(ws, "edit.txt")); string(got) != "new" {
+		t.Errorf("edit.txt = %q, want new", got)
+	}
+	for _, name := range []string{"drop.txt", ".DS_Store"} {
+		if _, err := os.Stat(filepath.Join(ws, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be in the workspace: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(ws, "local.txt")); err != nil {
+		t.Error("untracked workspace file was removed:", err)
+	}
+}
+
+// TestSnapshotIgnore verifies that WALLFACER_SNAPSHOT_IGNORE replaces the
+// default ignore list, including when it is set to nothing.
+func TestSnapshotIgnore(t *testing.T) {
+	r := &Runner{}
+	if got := r.snapshotIgnore(); !slices.Equal(got, dircp.DefaultIgnore) {
+		t.Errorf("no env file: %q, want defaults", got)
+	}
+	for content, want := range map[string][]string{
+		"WALLFACER_SNAPSHOT_IGNORE=dist,*.log\n": {"dist", "*.log"},
+		"WALLFACER_SNAPSHOT_IGNORE=\n":           {},
+		"WALLFACER_AUTO_PUSH=false\n":            dircp.DefaultIgnore,
+	} {
+		r.envFile = filepath.Join(t.TempDir(), ".env")
+		if err := os.WriteFile(r.envFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.snapshotIgnore(); !slices.Equal(got, want) {
+			t.Errorf("%q: snapshotIgnore = %q, want %q", content, got, want)
+		}
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/projectconfig"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// maxSkippedPathsListed caps how many skipped paths one event names; the
+// count always covers all of them.
+const maxSkippedPathsListed = 50
+
+// workspaceStageIgnore returns the stage-ignore patterns configured on the
+// workspace the task was dispatched under, or nil when there are none.
+func (r *Runner) workspaceStageIgnore(taskID uuid.UUID) []string {
+	if r.workspaceManager == nil {
+		return nil
+	}
+	ws, found, err := r.workspaceManager.WorkspaceByKey(r.taskWorkspaceKey(taskID))
+	if err != nil || !found {
+		return nil
+	}
+	return ws.StageIgnore
+}
+
+// recordSkippedPaths records the untracked paths the commit pipeline left
+// unstaged in repoPath because they matched a stage-ignore pattern, so the
+// timeline shows what was held back from the commit.
+func (r *Runner) recordSkippedPaths(ctx context.Context, taskID uuid.UUID, repoPath string, skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	listed := skipped
+	if len(listed) > maxSkippedPathsListed {
+		listed = listed[:maxSkippedPathsListed]
+	}
+	result := fmt.Sprintf("Left %d path(s) matching the workspace's stage-ignore patterns or %s ignore paths out of the commit in %s: %s",
+		len(skipped), projectconfig.Filename, repoPath, strings.Join(listed, ", "))
+	if len(listed) < len(skipped) {
+		result += fmt.Sprintf(", and %d more", len(skipped)-len(listed))
+	}
+	_ = r.taskStore(taskID).InsertEvent(ctx, taskID, store.EventTypeSystem, map[string]any{
+		"phase":   "stage",
+		"status":  "skipped",
+		"repo":    repoPath,
+		"skipped": listed,
+		"count":   len(skipped),
+		"result":  result,
+	})
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"latere.ai/x/wallfacer/internal/store"
+	"latere.ai/x/wallfacer/internal/workspace"
+)
+
+// TestCommitWorktree_StageIgnore verifies that untracked paths matching a
+// stage-ignore pattern stay out of the commit and in the worktree.
+func TestCommitWorktree_StageIgnore(t *testing.T) {
+	repo := setupTestRepo(t)
+	if err := os.MkdirAll(filepath.Join(repo, "dist"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range map[string]string{"main.go": "package main\n", "dist/app.js": "bundle", "cover.out": "mode: set\n"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	committed, err := commitWorktree(context.Background(), repo, "Add main", []string{"dist/", "*.out"})
+	if err != nil || !committed {
+		t.Fatalf("commitWorktree = %v, %v", committed, err)
+	}
+	if files := gitRun(t, repo, "show", "--name-only", "--format=", "HEAD"); files != "main.go" {
+		t.Errorf("committed files = %q, want main.go", files)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "dist", "app.js")); err != nil {
+		t.Errorf("skipped file should stay in the worktree: %v", err)
+	}
+
+	// Only ignored files left: nothing to commit.
+	committed, err = commitWorktree(context.Background(), repo, "Nothing", []string{"dist/", "*.out"})
+	if err != nil || committed {
+		t.Fatalf("commitWorktree with only skipped files = %v, %v", committed, err)
+	}
+}
+
+func TestRecordSkippedPaths(t *testing.T) {
+	repo := setupTestRepo(t)
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mgr, err := workspace.NewManager(t.TempDir(), t.TempDir(), envFile, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := mgr.Create("web", []string{repo}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.SetStageIgnore(ws.ID, []string{"dist/"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.SwitchByID(ws.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, r := setupTestRunnerWithManager(t, []string{repo}, mgr)
+	s := r.currentStore()
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "build", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.workspaceStageIgnore(task.ID); !slices.Equal(got, []string{"dist/"}) {
+		t.Fatalf("workspaceStageIgnore = %q", got)
+	}
+
+	paths := make([]string, maxSkippedPathsListed+2)
+	for i := range paths {
+		paths[i] = filepath.Join("dist", strings.Repeat("a", i+1))
+	}
+	r.recordSkippedPaths(ctx, task.ID, repo, paths)
+	r.recordSkippedPaths(ctx, task.ID, repo, nil)
+
+	events, _ := s.GetEvents(ctx, task.ID)
+	var data []map[string]any
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeSystem {
+			var d map[string]any
+			_ = json.Unmarshal(ev.Data, &d)
+			data = append(data, d)
+		}
+	}
+	if len(data) != 1 {
+		t.Fatalf("system events = %v, want one", data)
+	}
+	d := data[0]
+	if d["phase"] != "stage" || d["count"] != float64(len(paths)) || len(d["skipped"].([]any)) != maxSkippedPathsListed {
+		t.Errorf("event = %v", d)
+	}
+	if result, _ := d["result"].(string); !strings.HasSuffix(result, "and 2 more") {
+		t.Errorf("result = %q", result)
+	}
+}
@@ -0,0 +1,262 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/executor"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/cmdexec"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// stallCheckMaxInterval caps how often the stall watchdog looks at a turn,
+// so a long stall timeout is still checked at least once a minute.
+const stallCheckMaxInterval = time.Minute
+
+// stallSettingsFromEnv returns the stall watchdog's timeout and whether a
+// stalled turn is restarted. A zero timeout disables the watchdog.
+func (r *Runner) stallSettingsFromEnv() (time.Duration, bool) {
+	if r.envFile == "" {
+		return 0, false
+	}
+	cfg, err := envconfig.Parse(r.envFile)
+	if err != nil || cfg.StallMinutes <= 0 {
+		return 0, false
+	}
+	return time.Duration(cfg.StallMinutes) * time.Minute, cfg.StallRestart
+}
+
+// stallWatch is the watchdog of one agent turn. It treats any output on
+// the turn's streams and any change to the files of its worktrees as
+// activity. Once the turn has shown neither for the timeout, it records a
+// stalled event and flags the task; with restart enabled it also kills the
+// agent so runContainer runs the turn again, at most once per turn.
+type stallWatch struct {
+	r         *Runner
+	taskID    uuid.UUID
+	timeout   time.Duration
+	restart   bool
+	worktrees []string
+	now       func() time.Time
+
+	mu           sync.Mutex
+	lastActivity time.Time
+	fingerprint  string
+	stalled      bool
+	restarted    bool
+	killed       bool
+	handle       executor.Handle
+	stop         chan struct{}
+}
+
+// newStallWatch returns the watchdog of a turn of taskID that works in
+// worktrees, or nil when the watchdog is disabled. allowRestart false keeps
+// it from restarting the turn even when configured to. A nil *stallWatch
+// is safe to use and watches nothing.
+func (r *Runner) newStallWatch(taskID uuid.UUID, worktrees map[string]string, allowRestart bool) *stallWatch {
+	timeout, restart := r.stallSettingsFromEnv()
+	if timeout <= 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(worktrees))
+	for _, wt := range worktrees {
+		paths = append(paths, wt)
+	}
+	slices.Sort(paths)
+	return &stallWatch{
+		r:         r,
+		taskID:    taskID,
+		timeout:   timeout,
+		restart:   restart && allowRestart,
+		worktrees: paths,
+		now:       time.Now,
+	}
+}
+
+// liveLogWriter returns ll teed into w, so the output drained into the
+// live log also counts as activity, or ll alone when w is nil.
+func (w *stallWatch) liveLogWriter(ll io.Writer) io.Writer {
+	if w == nil {
+		return ll
+	}
+	return io.MultiWriter(ll, w)
+}
+
+// Write records output from the turn as activity. It never fails, so it
+// can sit in the io.MultiWriter that drains the agent's streams.
+func (w *stallWatch) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return len(p), nil
+	}
+	w.activity()
+	return len(p), nil
+}
+
+// start begins watching the agent behind handle. Each launch of the turn
+// calls it with its own handle; the first call starts the ticker.
+func (w *stallWatch) start(handle executor.Handle) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handle = handle
+	w.killed = false
+	w.lastActivity = w.now()
+	if w.stop != nil {
+		return
+	}
+	w.fingerprint = w.worktreeFingerprint()
+	w.stop = make(chan struct{})
+	go w.loop(w.stop, stallCheckInterval(w.timeout))
+}
+
+// close stops the watchdog and clears the task's stall flag.
+func (w *stallWatch) close() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+	stalled := w.stalled
+	w.stalled = false
+	w.mu.Unlock()
+	if stalled {
+		w.clearFlag()
+	}
+}
+
+// restartRequested reports whether the watchdog killed the last launch of
+// the turn so it can be run again.
+func (w *stallWatch) restartRequested() bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.killed
+}
+
+// stallCheckInterval is how often a turn with the given stall timeout is
+// checked: a fifth of the timeout, between a second and a minute.
+func stallCheckInterval(timeout time.Duration) time.Duration {
+	return min(max(timeout/5, time.Second), stallCheckMaxInterval)
+}
+
+func (w *stallWatch) loop(stop <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check counts a change to the worktrees as activity and flags the turn
+// once it has been idle for the timeout.
+func (w *stallWatch) check() {
+	fp := w.worktreeFingerprint()
+
+	w.mu.Lock()
+	if fp != w.fingerprint {
+		w.fingerprint = fp
+		w.mu.Unlock()
+		w.activity()
+		return
+	}
+	idle := w.now().Sub(w.lastActivity)
+	if w.stalled || w.killed || idle < w.timeout {
+		w.mu.Unlock()
+		return
+	}
+	w.stalled = true
+	restart := w.restart && !w.restarted && w.handle != nil
+	if restart {
+		w.restarted = true
+		w.killed = true
+	}
+	handle := w.handle
+	lastActivity := w.lastActivity
+	w.mu.Unlock()
+
+	logger.Runner.Warn("agent turn stalled", "task", w.taskID,
+		"idle", idle.Round(time.Second), "restart", restart)
+	s := w.r.taskStore(w.taskID)
+	at := w.now()
+	_ = s.InsertEvent(context.Background(), w.taskID, store.EventTypeStalled, store.StalledData{
+		IdleSeconds:    int(idle.Seconds()),
+		LastActivityAt: lastActivity,
+		Restarted:      restart,
+	})
+	if err := s.SetTaskStalled(context.Background(), w.taskID, &at); err != nil {
+		logger.Runner.Warn("stall watchdog: flag task", "task", w.taskID, "error", err)
+	}
+	if restart {
+		if err := handle.Kill(); err != nil {
+			logger.Runner.Warn("stall watchdog: kill agent", "task", w.taskID, "error", err)
+		}
+	}
+}
+
+// activity marks the turn as active now, clearing the stall flag when it
+// was set.
+func (w *stallWatch) activity() {
+	w.mu.Lock()
+	w.lastActivity = w.now()
+	stalled := w.stalled
+	w.stalled = false
+	w.mu.Unlock()
+	if stalled {
+		w.clearFlag()
+	}
+}
+
+func (w *stallWatch) clearFlag() {
+	if err := w.r.taskStore(w.taskID).SetTaskStalled(context.Background(), w.taskID, nil); err != nil {
+		logger.Runner.Warn("stall watchdog: clear flag", "task", w.taskID, "error", err)
+	}
+}
+
+// worktreeFingerprint summarises the uncommitted state of the worktrees:
+// the changed paths git reports and their sizes and modification times.
+// Committing, editing, creating, and deleting files all change it.
+func (w *stallWatch) worktreeFingerprint() string {
+	h := sha256.New()
+	for _, wt := range w.worktrees {
+		head, _ := cmdexec.Git(wt, "rev-parse", "HEAD").Output()
+		// OutputBytes keeps the leading status column that Output would trim.
+		status, err := cmdexec.Git(wt, "status", "--porcelain", "-z", "--untracked-files=all").OutputBytes()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00", wt, head, status)
+		for entry := range strings.SplitSeq(string(status), "\x00") {
+			if len(entry) < 4 {
+				continue
+			}
+			if fi, err := os.Stat(filepath.Join(wt, entry[3:])); err == nil {
+				fmt.Fprintf(h, "%s\x00%d\x00%d\x00", entry[3:], fi.Size(), fi.ModTime().UnixNano())
+			}
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestStallCheckInterval(t *testing.T) {
+	for timeout, want := range map[time.Duration]time.Duration{
+		2 * time.Second:  time.Second,
+		30 * time.Second: 6 * time.Second,
+		15 * time.Minute: time.Minute,
+	} {
+		if got := stallCheckInterval(timeout); got != want {
+			t.Errorf("stallCheckInterval(%v) = %v, want %v", timeout, got, want)
+		}
+	}
+}
+
+// newTestStallWatch returns a watchdog over worktrees with a clock the
+// test advances, started on a stub handle without its ticker.
+func newTestStallWatch(r *Runner, taskID uuid.UUID, worktrees []string, restart bool) (*stallWatch, *time.Time, *stubHandle) {
+	now := time.Now()
+	h := &stubHandle{name: "agent"}
+	w := &stallWatch{
+		r:         r,
+		taskID:    taskID,
+		timeout:   time.Minute,
+		restart:   restart,
+		worktrees: worktrees,
+		now:       func() time.Time { return now },
+	}
+	w.handle = h
+	w.lastActivity = now
+	w.fingerprint = w.worktreeFingerprint()
+	return w, &now, h
+}
+
+func TestStallWatch_FlagsAndRestartsOnce(t *testing.T) {
+	s, r := setupRunnerWithCmd(t, nil, "echo")
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, now, h := newTestStallWatch(r, task.ID, nil, true)
+
+	*now = now.Add(30 * time.Second)
+	w.check()
+	if events, _ := s.GetEvents(ctx, task.ID); countEvents(events, store.EventTypeStalled) != 0 {
+		t.Fatal("stalled event recorded before the timeout")
+	}
+
+	*now = now.Add(time.Minute)
+	w.check()
+	if !h.killed || !w.restartRequested() {
+		t.Fatalf("killed = %v, restartRequested = %v; want the stalled agent killed for a restart", h.killed, w.restartRequested())
+	}
+	if got, _ := s.GetTask(ctx, task.ID); got.StalledAt == nil {
+		t.Fatal("StalledAt not set on a stalled turn")
+	}
+
+	// The restarted launch prints output, which clears the flag.
+	w.start(&stubHandle{name: "agent-2"})
+	_, _ = w.Write([]byte("working\n"))
+	if got, _ := s.GetTask(ctx, task.ID); got.StalledAt != nil {
+		t.Fatal("StalledAt kept after the agent printed output")
+	}
+
+	// A second stall in the same turn is only flagged.
+	*now = now.Add(2 * time.Minute)
+	w.check()
+	if w.restartRequested() {
+		t.Error("second stall requested another restart")
+	}
+	events, _ := s.GetEvents(ctx, task.ID)
+	if n := countEvents(events, store.EventTypeStalled); n != 2 {
+		t.Errorf("stalled events = %d, want 2", n)
+	}
+	w.close()
+	if got, _ := s.GetTask(ctx, task.ID); got.StalledAt != nil {
+		t.Error("StalledAt kept after the turn ended")
+	}
+}
+
+func TestStallWatch_FileChangeIsActivity(t *testing.T) {
+	s, r := setupRunnerWithCmd(t, nil, "echo")
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt := t.TempDir()
+	if out, err := exec.Command("git", "-C", wt, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	w, now, h := newTestStallWatch(r, task.ID, []string{wt}, true)
+
+	*now = now.Add(2 * time.Minute)
+	if err := os.WriteFile(filepath.Join(wt, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w.check()
+	if h.killed {
+		t.Fatal("agent killed although it wrote a file")
+	}
+	if events, _ := s.GetEvents(ctx, task.ID); countEvents(events, store.EventTypeStalled) != 0 {
+		t.Fatal("stalled event recorded although the agent wrote a file")
+	}
+}
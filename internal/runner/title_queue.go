@@ -0,0 +1,201 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/agents"
+	"latere.ai/x/wallfacer/internal/harness"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// titleJob is one queued title-generation request.
+type titleJob struct {
+	taskID uuid.UUID
+	prompt string
+}
+
+// titleQueue bounds background title generation. Requests wait in pending
+// and are drained by at most concurrency workers, each taking up to
+// batchSize jobs per agent call. This keeps a backfill over dozens of
+// untitled tasks from launching one agent per task at once. The zero value
+// is usable and behaves as one worker titling one task at a time.
+type titleQueue struct {
+	mu          sync.Mutex
+	pending     []titleJob
+	queued      map[uuid.UUID]bool // task IDs in pending or in flight, to drop duplicates
+	workers     int                // running workers
+	concurrency int
+	batchSize   int
+}
+
+// push queues job and reports whether the caller must start a new worker.
+// Returns false without queueing when the task is already queued.
+func (q *titleQueue) push(job titleJob) (startWorker bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.queued[job.taskID] {
+		return false
+	}
+	if q.queued == nil {
+		q.queued = make(map[uuid.UUID]bool)
+	}
+	q.queued[job.taskID] = true
+	q.pending = append(q.pending, job)
+	if q.workers >= max(q.concurrency, 1) {
+		return false
+	}
+	q.workers++
+	return true
+}
+
+// next dequeues up to batchSize jobs and the number still waiting after
+// them. An empty batch means the queue is drained and the calling worker
+// has been retired.
+func (q *titleQueue) next() (batch []titleJob, remaining int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		q.workers--
+		return nil, 0
+	}
+	n := min(max(q.batchSize, 1), len(q.pending))
+	batch = append([]titleJob(nil), q.pending[:n]...)
+	q.pending = q.pending[n:]
+	return batch, len(q.pending)
+}
+
+// retire releases a worker slot claimed by push whose worker did not start.
+func (q *titleQueue) retire() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.workers--
+}
+
+// done releases the dedupe entries of a finished batch so the tasks can be
+// queued again (for example, after a failed attempt).
+func (q *titleQueue) done(batch []titleJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range batch {
+		delete(q.queued, job.taskID)
+	}
+}
+
+// titleWorker drains the title queue until it is empty.
+func (r *Runner) titleWorker() {
+	for {
+		batch, remaining := r.titles.next()
+		if len(batch) == 0 {
+			return
+		}
+		r.generateTitleBatch(batch, remaining)
+		r.titles.done(batch)
+	}
+}
+
+// generateTitleBatch titles every still-untitled task in batch. A single
+// task takes the GenerateTitle path; several are named by one agent call,
+// falling back to per-task generation when the batched answer is unusable.
+// remaining is how many requests were still queued behind this batch and is
+// reported in each task's progress event.
+func (r *Runner) generateTitleBatch(batch []titleJob, remaining int) {
+	var tasks []*store.Task
+	var jobs []titleJob
+	for _, job := range batch {
+		task, err := r.taskStore(job.taskID).GetTask(r.shutdownCtx, job.taskID)
+		if err != nil || task == nil || task.Title != "" {
+			continue
+		}
+		tasks = append(tasks, task)
+		jobs = append(jobs, job)
+	}
+	switch len(jobs) {
+	case 0:
+		return
+	case 1:
+		r.GenerateTitle(jobs[0].taskID, jobs[0].prompt)
+		return
+	}
+
+	prompts := make([]string, len(jobs))
+	for i, job := range jobs {
+		prompts[i] = job.prompt
+	}
+	res, err := r.runAgent(r.shutdownCtx, agents.Title, nil, r.promptsMgr.TitleBatch(prompts), runAgentOpts{
+		ModelResolver: func(sb harness.ID) string { return r.titleModelFromEnvForSandbox(sb) },
+	})
+	var titles []string
+	if err == nil {
+		titles, err = parseTitleBatch(res.Output.Result, len(jobs))
+	}
+	if err != nil {
+		logger.Runner.Warn("batched title generation failed; titling tasks one by one",
+			"tasks", len(jobs), "error", err)
+		for _, job := range jobs {
+			r.GenerateTitle(job.taskID, job.prompt)
+		}
+		return
+	}
+
+	// One agent call served the whole batch; bill each task an equal share.
+	share := shareAgentUsage(res.Output, len(jobs))
+	for i, task := range tasks {
+		r.accumulateAgentUsage(task.ID, activityTitle, 1, share)
+		status, result := "done", fmt.Sprintf("Title generated in a batch of %d (%d still queued)", len(jobs), remaining)
+		if err := r.taskStore(task.ID).UpdateTaskTitle(r.shutdownCtx, task.ID, titles[i]); err != nil {
+			logger.Runner.Warn("title generation: store update failed", "task", task.ID, "error", err)
+			status, result = "failed", "Title generation failed: "+err.Error()
+		}
+		_ = r.taskStore(task.ID).InsertEvent(r.shutdownCtx, task.ID, store.EventTypeSystem, map[string]any{
+			"phase":      "title",
+			"status":     status,
+			"batch_size": len(jobs),
+			"remaining":  remaining,
+			"result":     result,
+		})
+	}
+}
+
+// parseTitleBatch extracts want titles from a batched title response: a JSON
+// array of strings, possibly wrapped in prose or a code fence. Each title is
+// cleaned like a single-task result; a wrong count or a blank title is an
+// error so the caller can fall back to per-task generation.
+func parseTitleBatch(result string, want int) ([]string, error) {
+	start, end := strings.Index(result, "["), strings.LastIndex(result, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON array in title batch response")
+	}
+	var titles []string
+	if err := json.Unmarshal([]byte(result[start:end+1]), &titles); err != nil {
+		return nil, fmt.Errorf("decode title batch: %w", err)
+	}
+	if len(titles) != want {
+		return nil, fmt.Errorf("title batch returned %d titles, want %d", len(titles), want)
+	}
+	for i, t := range titles {
+		t, _ := parseTitleResult(&agentOutput{Result: t})
+		titles[i] = t.(string)
+		if titles[i] == "" {
+			return nil, fmt.Errorf("title batch entry %d is blank", i+1)
+		}
+	}
+	return titles, nil
+}
+
+// shareAgentUsage returns a copy of o with its token counts and cost divided
+// evenly across n tasks.
+func shareAgentUsage(o *agentOutput, n int) *agentOutput {
+	share := *o
+	share.TotalCostUSD = o.TotalCostUSD / float64(n)
+	share.Usage.InputTokens = o.Usage.InputTokens / n
+	share.Usage.OutputTokens = o.Usage.OutputTokens / n
+	share.Usage.CacheReadInputTokens = o.Usage.CacheReadInputTokens / n
+	share.Usage.CacheCreationInputTokens = o.Usage.CacheCreationInputTokens / n
+	return &share
+}
@@ -0,0 +1,171 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// TestTitleQueue_BoundsWorkersAndBatches verifies that the queue starts no
+// more than concurrency workers, hands out at most batchSize jobs at a time,
+// drops duplicate requests, and retires a worker once it finds the queue
+// empty.
+func TestTitleQueue_BoundsWorkersAndBatches(t *testing.T) {
+	q := &titleQueue{concurrency: 2, batchSize: 3}
+	ids := make([]uuid.UUID, 5)
+	started := 0
+	for i := range ids {
+		ids[i] = uuid.New()
+		if q.push(titleJob{taskID: ids[i], prompt: "p"}) {
+			started++
+		}
+	}
+	if started != 2 {
+		t.Fatalf("started %d workers, want 2", started)
+	}
+	if q.push(titleJob{taskID: ids[0], prompt: "p"}) || len(q.pending) != 5 {
+		t.Fatalf("duplicate request was queued: pending=%d", len(q.pending))
+	}
+
+	batch, remaining := q.next()
+	if len(batch) != 3 || remaining != 2 || batch[0].taskID != ids[0] {
+		t.Fatalf("first batch = %d jobs, %d remaining", len(batch), remaining)
+	}
+	q.done(batch)
+	if q.queued[ids[0]] {
+		t.Error("finished task still marked queued")
+	}
+	batch, remaining = q.next()
+	if len(batch) != 2 || remaining != 0 {
+		t.Fatalf("second batch = %d jobs, %d remaining", len(batch), remaining)
+	}
+	if batch, _ = q.next(); batch != nil || q.workers != 1 {
+		t.Fatalf("drained queue: batch=%v workers=%d, want nil and 1", batch, q.workers)
+	}
+}
+
+// TestTitleQueue_ZeroValue verifies that an unconfigured queue runs one worker
+// titling one task at a time.
+func TestTitleQueue_ZeroValue(t *testing.T) {
+	var q titleQueue
+	if !q.push(titleJob{taskID: uuid.New()}) {
+		t.Fatal("first push must start a worker")
+	}
+	if q.push(titleJob{taskID: uuid.New()}) {
+		t.Fatal("second push must reuse the running worker")
+	}
+	if batch, _ := q.next(); len(batch) != 1 {
+		t.Fatalf("batch = %d jobs, want 1", len(batch))
+	}
+}
+
+func TestParseTitleBatch(t *testing.T) {
+	got, err := parseTitleBatch("Here you go:\n```json\n[\"Fix Login Bug\", \" 'Add Dark Mode' \"]\n```", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != "Fix Login Bug" || got[1] != "Add Dark Mode" {
+		t.Errorf("titles = %q", got)
+	}
+	for name, in := range map[string]string{
+		"no array":    "Fix Login Bug",
+		"wrong count": `["Only One"]`,
+		"blank entry": `["Fix Login Bug", "  "]`,
+		"not strings": `[1, 2]`,
+	} {
+		if _, err := parseTitleBatch(in, 2); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestShareAgentUsage(t *testing.T) {
+	o := &agentOutput{Result: "r", TotalCostUSD: 0.9}
+	o.Usage.InputTokens = 300
+	o.Usage.OutputTokens = 31
+	share := shareAgentUsage(o, 3)
+	if share.Usage.InputTokens != 100 || share.Usage.OutputTokens != 10 {
+		t.Errorf("tokens = %+v", share.Usage)
+	}
+	if share.TotalCostUSD < 0.299 || share.TotalCostUSD > 0.301 {
+		t.Errorf("cost = %v, want 0.3", share.TotalCostUSD)
+	}
+	if o.Usage.InputTokens != 300 {
+		t.Error("shareAgentUsage modified its input")
+	}
+}
+
+// TestGenerateTitleBatch_TitlesAllTasksInOneCall verifies that a batch of
+// untitled tasks is named from a single agent response, that a task which
+// already has a title is left alone, and that each titled task records a
+// progress event.
+func TestGenerateTitleBatch_TitlesAllTasksInOneCall(t *testing.T) {
+	const out = `{"result":"[\"Fix Login Bug\", \"Add Dark Mode\"]","session_id":"s","stop_reason":"end_turn","is_error":false}`
+	s, r := setupRunnerWithCmd(t, nil, fakeCmdScript(t, out, 0))
+	ctx := context.Background()
+
+	var jobs []titleJob
+	var tasks []*store.Task
+	for _, p := range []string{"fix the login bug", "already titled", "add a dark mode toggle"} {
+		task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: p, Timeout: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+		jobs = append(jobs, titleJob{taskID: task.ID, prompt: p})
+	}
+	if err := s.UpdateTaskTitle(ctx, tasks[1].ID, "Pre-set Title"); err != nil {
+		t.Fatal(err)
+	}
+
+	r.generateTitleBatch(jobs, 4)
+
+	for i, want := range []string{"Fix Login Bug", "Pre-set Title", "Add Dark Mode"} {
+		got, _ := s.GetTask(ctx, tasks[i].ID)
+		if got.Title != want {
+			t.Errorf("task %d title = %q, want %q", i, got.Title, want)
+		}
+	}
+	events, err := s.GetEvents(ctx, tasks[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, ev := range events {
+		if ev.EventType == store.EventTypeSystem && strings.Contains(string(ev.Data), `"batch_size":2`) && strings.Contains(string(ev.Data), `"remaining":4`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("no title progress event recorded")
+	}
+}
+
+// TestGenerateTitleBatch_FallsBackPerTask verifies that an unusable batched
+// response falls back to titling each task on its own.
+func TestGenerateTitleBatch_FallsBackPerTask(t *testing.T) {
+	s, r := setupRunnerWithCmd(t, nil, fakeCmdScript(t, titleOutput, 0))
+	ctx := context.Background()
+
+	var jobs []titleJob
+	for _, p := range []string{"first prompt", "second prompt"} {
+		task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: p, Timeout: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobs = append(jobs, titleJob{taskID: task.ID, prompt: p})
+	}
+
+	r.generateTitleBatch(jobs, 0)
+
+	for _, job := range jobs {
+		got, _ := s.GetTask(ctx, job.taskID)
+		if got.Title != "Fix Login Bug" {
+			t.Errorf("task %s title = %q, want the per-task result", job.taskID, got.Title)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/executor"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// turnExitRecord converts executor exit metadata into its persisted form.
+func turnExitRecord(turn int, info executor.ExitInfo) store.TurnExit {
+	return store.TurnExit{
+		Turn:         turn,
+		ExitCode:     info.ExitCode,
+		Signal:       info.Signal,
+		OOMKilled:    info.OOMKilled,
+		StartedAt:    info.StartedAt,
+		EndedAt:      info.EndedAt,
+		DurationMs:   info.Duration().Milliseconds(),
+		MaxRSS:       info.MaxRSSBytes,
+		Binary:       info.Binary,
+		BinaryDigest: info.BinaryDigest,
+	}
+}
+
+// saveTurnExit persists the exit metadata runContainer captured for the
+// task's latest launch under the given turn number. A no-op when the turn
+// never launched a process (for example, the launch itself failed).
+func (r *Runner) saveTurnExit(taskID uuid.UUID, turn int) {
+	info, ok := r.turnExits.Load(taskID)
+	if !ok {
+		return
+	}
+	r.turnExits.Delete(taskID)
+	if err := r.taskStore(taskID).SaveTurnExit(taskID, turnExitRecord(turn, info)); err != nil {
+		logger.Runner.Warn("save turn exit", "task", taskID, "turn", turn, "error", err)
+	}
+	if info.OOMKilled {
+		logger.Runner.Warn("agent process was killed by an external SIGKILL (likely out of memory)",
+			"task", taskID, "turn", turn, "max_rss_bytes", info.MaxRSSBytes)
+	}
+}
@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/executor"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func TestTurnExitRecord(t *testing.T) {
+	start := time.Unix(1760000000, 0)
+	got := turnExitRecord(3, executor.ExitInfo{
+		ExitCode:     -1,
+		Signal:       "killed",
+		OOMKilled:    true,
+		StartedAt:    start,
+		EndedAt:      start.Add(2500 * time.Millisecond),
+		MaxRSSBytes:  1 << 30,
+		Binary:       "/usr/local/bin/claude",
+		BinaryDigest: "sha256:abc",
+	})
+	if got.Turn != 3 || got.ExitCode != -1 || got.Signal != "killed" || !got.OOMKilled {
+		t.Errorf("exit fields = %+v", got)
+	}
+	if got.DurationMs != 2500 || got.MaxRSS != 1<<30 || got.BinaryDigest != "sha256:abc" {
+		t.Errorf("duration/rss/digest = %d/%d/%q", got.DurationMs, got.MaxRSS, got.BinaryDigest)
+	}
+}
+
+// TestSaveTurnExit_ConsumesLatestLaunch verifies the captured exit is written
+// once under the given turn and is not reused for a later turn.
+func TestSaveTurnExit_ConsumesLatestLaunch(t *testing.T) {
+	s, r := setupTestRunner(t, nil)
+	task, err := s.CreateTaskWithOptions(context.Background(), store.TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.saveTurnExit(task.ID, 1) // nothing captured yet
+	r.turnExits.Store(task.ID, executor.ExitInfo{ExitCode: 2})
+	r.saveTurnExit(task.ID, 2)
+	r.saveTurnExit(task.ID, 3)
+
+	exits, err := s.GetTurnExits(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exits) != 1 || exits[2].ExitCode != 2 {
+		t.Errorf("exits = %+v, want only turn 2 with exit code 2", exits)
+	}
+}
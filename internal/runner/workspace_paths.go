@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"cmp"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/prompts"
+)
+
+// workspacePathsPrompt rewrites absolute paths into a workspace checkout
+// (e.g. /Users/x/code/app/main.go, or ~/code/app/main.go) to the same path
+// in the task's worktree and appends the mapping, so the agent edits its
+// own worktree rather than the shared checkout the user copied the path
+// from. Prompts that name no workspace path are returned unchanged.
+func (r *Runner) workspacePathsPrompt(prompt string, worktreePaths map[string]string) string {
+	out, rewrites := rewriteWorkspacePaths(prompt, worktreePaths, userHomeDir())
+	if len(rewrites) == 0 {
+		return prompt
+	}
+	return r.promptsMgr.WorkspacePaths(prompts.WorkspacePathsData{Prompt: out, Rewrites: rewrites})
+}
+
+// userHomeDir returns the home directory used to expand ~ in prompts, or ""
+// when it cannot be determined.
+func userHomeDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return home
+}
+
+// rewriteWorkspacePaths replaces each occurrence of a workspace directory
+// in text with its worktree and reports the workspaces that were rewritten,
+// in the order given by their paths. A match must be the whole directory:
+// it is followed by a path separator, the end of text, or a character that
+// cannot continue a file name, so /code/app does not match /code/app2. A
+// trailing period ends the match too, as at the end of a sentence.
+// When home is set, the ~-relative form of a workspace under it matches
+// too. Longer workspace paths are tried first so nested workspaces map to
+// the innermost one.
+func rewriteWorkspacePaths(text string, worktreePaths map[string]string, home string) (string, []prompts.PathRewrite) {
+	type mapping struct{ from, to, ws string }
+	var cands []mapping
+	for ws, wt := range worktreePaths {
+		ws, wt = filepath.Clean(ws), filepath.Clean(wt)
+		if ws == wt || ws == "." || ws == string(filepath.Separator) {
+			continue
+		}
+		cands = append(cands, mapping{ws, wt, ws})
+		if home != "" {
+			if rel, err := filepath.Rel(home, ws); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+				cands = append(cands, mapping{"~" + string(filepath.Separator) + rel, wt, ws})
+			}
+		}
+	}
+	slices.SortFunc(cands, func(a, b mapping) int {
+		return cmp.Or(cmp.Compare(len(b.from), len(a.from)), strings.Compare(a.from, b.from))
+	})
+
+	used := make(map[string]string) // workspace → worktree
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		matched := false
+		if i == 0 || !isPathByte(text[i-1]) {
+			for _, m := range cands {
+				end := i + len(m.from)
+				if strings.HasPrefix(text[i:], m.from) && endsName(text, end) {
+					b.WriteString(m.to)
+					used[m.ws] = m.to
+					i = end
+					matched = true
+					break
+				}
+			}
+		}
+		if !matched {
+			b.WriteByte(text[i])
+			i++
+		}
+	}
+	if len(used) == 0 {
+		return text, nil
+	}
+
+	var rewrites []prompts.PathRewrite
+	for _, ws := range slices.Sorted(maps.Keys(used)) {
+		rewrites = append(rewrites, prompts.PathRewrite{From: ws, To: used[ws]})
+	}
+	return b.String(), rewrites
+}
+
+// endsName reports whether a name that stops at text[end] is complete.
+func endsName(text string, end int) bool {
+	if end == len(text) || !isNameByte(text[end]) {
+		return true
+	}
+	return text[end] == '.' && (end+1 == len(text) || !isNameByte(text[end+1]))
+}
+
+// isNameByte reports whether c can continue a file or directory name.
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '_' || c == '.' || c >= 0x80
+}
+
+// isPathByte reports whether c can precede a path inside a longer path, in
+// which case a workspace directory starting after it is not a match.
+func isPathByte(c byte) bool {
+	return isNameByte(c) || c == '/' || c == '\\' || c == '~'
+}
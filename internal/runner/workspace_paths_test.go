@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteWorkspacePaths(t *testing.T) {
+	worktrees := map[string]string{
+		"/home/u/code/app":     "/home/u/.wallfacer/worktrees/t1/app",
+		"/home/u/code/app/lib": "/home/u/.wallfacer/worktrees/t1/lib",
+		"/srv/plain":           "/srv/plain", // non-git workspace: no worktree
+	}
+	cases := []struct {
+		name, in, want string
+		rewritten      []string
+	}{
+		{"file", "fix /home/u/code/app/main.go now", "fix /home/u/.wallfacer/worktrees/t1/app/main.go now", []string{"/home/u/code/app"}},
+		{"sentence end", "look in /home/u/code/app.", "look in /home/u/.wallfacer/worktrees/t1/app.", []string{"/home/u/code/app"}},
+		{"home relative", "see `~/code/app/go.mod`", "see `/home/u/.wallfacer/worktrees/t1/app/go.mod`", []string{"/home/u/code/app"}},
+		{"nested workspace", "/home/u/code/app/lib/x.go", "/home/u/.wallfacer/worktrees/t1/lib/x.go", []string{"/home/u/code/app/lib"}},
+		{"sibling name", "/home/u/code/app2/main.go", "/home/u/code/app2/main.go", nil},
+		{"inside longer path", "/mnt/home/u/code/app/main.go", "/mnt/home/u/code/app/main.go", nil},
+		{"unchanged workspace", "/srv/plain/data.csv", "/srv/plain/data.csv", nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, rewrites := rewriteWorkspacePaths(tc.in, worktrees, "/home/u")
+			if got != tc.want {
+				t.Errorf("text = %q, want %q", got, tc.want)
+			}
+			if len(rewrites) != len(tc.rewritten) {
+				t.Fatalf("rewrites = %+v, want %v", rewrites, tc.rewritten)
+			}
+			for i, rw := range rewrites {
+				if rw.From != tc.rewritten[i] || rw.To != worktrees[rw.From] {
+					t.Errorf("rewrite %d = %+v, want from %s", i, rw, tc.rewritten[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWorkspacePathsPrompt(t *testing.T) {
+	_, r := setupTestRunner(t, nil)
+	worktrees := map[string]string{"/code/app": "/wt/t1/app"}
+
+	got := r.workspacePathsPrompt("fix /code/app/main.go", worktrees)
+	if !strings.HasPrefix(got, "fix /wt/t1/app/main.go") || !strings.Contains(got, "- /code/app → /wt/t1/app") {
+		t.Errorf("workspacePathsPrompt = %q", got)
+	}
+	if got := r.workspacePathsPrompt("fix the bug", worktrees); got != "fix the bug" {
+		t.Errorf("prompt without paths changed to %q", got)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/google/uuid"
 	"latere.ai/x/wallfacer/internal/gitutil"
 	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/dircp"
 	"latere.ai/x/wallfacer/internal/store"
 )
 
@@ -118,6 +119,41 @@ func (r *Runner) EnsureTaskWorktrees(taskID uuid.UUID, existing map[string]strin
 	return r.ensureTaskWorktrees(taskID, existing, branchName)
 }
 
+// ForkTaskWorktrees creates worktrees for forkID that start from the current
+// state of another task's worktrees, uncommitted changes included, so a
+// forked task continues exactly where its parent paused. Git worktrees are
+// forked onto the fork's own branch with [gitutil.ForkWorktree]; non-git
+// snapshots are copied together with their tracking repo so the baseline
+// used when extracting changes stays the same. On failure every worktree
+// created so far is removed.
+func (r *Runner) ForkTaskWorktrees(forkID uuid.UUID, parentWorktrees map[string]string) (map[string]string, string, error) {
+	r.worktreeMu.Lock()
+	defer r.worktreeMu.Unlock()
+
+	branchName := "task/" + forkID.String()[:8]
+	worktreePaths := make(map[string]string, len(parentWorktrees))
+	for repoPath, src := range parentWorktrees {
+		worktreePath := filepath.Join(r.worktreesDir, forkID.String(), filepath.Base(repoPath))
+		if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+			r.cleanupWorktrees(forkID, worktreePaths, branchName)
+			return nil, "", fmt.Errorf("mkdir worktree parent: %w", err)
+		}
+		var err error
+		if gitutil.IsGitRepo(repoPath) && gitutil.HasCommits(repoPath) {
+			err = gitutil.ForkWorktree(repoPath, src, worktreePath, branchName)
+		} else if err = os.MkdirAll(worktreePath, 0755); err == nil {
+			err = dircp.Copy(src, worktreePath)
+		}
+		if err != nil {
+			worktreePaths[repoPath] = worktreePath
+			r.cleanupWorktrees(forkID, worktreePaths, branchName)
+			return nil, "", fmt.Errorf("fork worktree for %s: %w", repoPath, err)
+		}
+		worktreePaths[repoPath] = worktreePath
+	}
+	return worktreePaths, branchName, nil
+}
+
 // CleanupWorktrees is the exported variant of cleanupWorktrees for handler use.
 func (r *Runner) CleanupWorktrees(taskID uuid.UUID, worktreePaths map[string]string, branchName string) {
 	// Stop the per-task worker container before removing worktrees.
@@ -184,6 +220,9 @@ func (r *Runner) PruneUnknownWorktrees() {
 	pruneIDs := map[string]bool{}
 	tasks, _ := s.ListTasks(ctx, true)
 	for _, t := range tasks {
+		if t.RetainsWorktree() {
+			continue
+		}
 		if t.Archived ||
 			t.Status == store.TaskStatusDone ||
 			t.Status == store.TaskStatusCancelled {
@@ -16,7 +16,7 @@ import (
 )
 
 // ScanMissingTaskWorktrees iterates over all tasks in in_progress, waiting,
-// and committing states and returns those where at least one WorktreePaths
+// committing, and review states and returns those where at least one WorktreePaths
 // entry is missing on disk. It does not remove anything.
 func (r *Runner) ScanMissingTaskWorktrees(ctx context.Context) ([]store.Task, error) {
 	s := r.currentStore()
@@ -28,6 +28,7 @@ func (r *Runner) ScanMissingTaskWorktrees(ctx context.Context) ([]store.Task, er
 		store.TaskStatusInProgress,
 		store.TaskStatusWaiting,
 		store.TaskStatusCommitting,
+		store.TaskStatusReview,
 	}
 
 	var missing []store.Task
@@ -138,7 +139,7 @@ func (r *Runner) StartWorktreeHealthWatcher(ctx context.Context) {
 // ScanOrphanedWorktrees inspects r.worktreesDir and returns the task IDs
 // whose worktree directories exist on disk but whose tasks are in a terminal
 // or non-existent state (done, cancelled, archived, or unknown to the store).
-// Tasks in backlog, in_progress, waiting, committing, or failed still need
+// Tasks in backlog, in_progress, waiting, committing, review, or failed still need
 // their worktrees and are skipped.
 func (r *Runner) ScanOrphanedWorktrees(ctx context.Context) ([]uuid.UUID, error) {
 	s := r.currentStore()
@@ -172,13 +173,17 @@ func (r *Runner) ScanOrphanedWorktrees(ctx context.Context) ([]uuid.UUID, error)
 			continue
 		}
 
-		// Terminal states whose worktrees should have been cleaned up.
+		// Terminal states whose worktrees should have been cleaned up,
+		// except a done task that left its changes in the worktree.
+		if task.RetainsWorktree() {
+			continue
+		}
 		if task.Status == store.TaskStatusDone ||
 			task.Status == store.TaskStatusCancelled ||
 			task.Archived {
 			orphans = append(orphans, id)
 		}
-		// backlog, in_progress, waiting, committing, failed: skip.
+		// backlog, in_progress, waiting, committing, review, failed: skip.
 	}
 
 	return orphans, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/google/uuid"
@@ -180,6 +181,44 @@ func TestScanOrphanedWorktrees_SkipsNonTerminalStates(t *testing.T) {
 	}
 }
 
+// TestScanOrphanedWorktrees_SkipsRetainedWorktree verifies that a done task
+// that left its changes in the worktree (SkipCommit) is not an orphan until
+// it is archived.
+func TestScanOrphanedWorktrees_SkipsRetainedWorktree(t *testing.T) {
+	s, r := setupTestRunner(t, nil)
+	ctx := context.Background()
+
+	task, err := s.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "explore", Timeout: 5, SkipCommit: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ForceUpdateTaskStatus(ctx, task.ID, store.TaskStatusDone); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(r.worktreesDir, task.ID.String()), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := r.ScanOrphanedWorktrees(ctx)
+	if err != nil {
+		t.Fatalf("ScanOrphanedWorktrees: %v", err)
+	}
+	if slices.Contains(orphans, task.ID) {
+		t.Error("retained worktree reported as orphan")
+	}
+
+	if err := s.SetTaskArchived(ctx, task.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	orphans, err = r.ScanOrphanedWorktrees(ctx)
+	if err != nil {
+		t.Fatalf("ScanOrphanedWorktrees: %v", err)
+	}
+	if !slices.Contains(orphans, task.ID) {
+		t.Error("archived task's worktree should be an orphan")
+	}
+}
+
 // TestScanOrphanedWorktrees_MissingDir verifies that ScanOrphanedWorktrees
 // returns nil (not an error) when worktreesDir does not exist.
 func TestScanOrphanedWorktrees_MissingDir(t *testing.T) {
@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -104,6 +105,52 @@ func TestWorktreeConcurrencySetupAndPrune(t *testing.T) {
 	wg.Wait()
 }
 
+// TestForkTaskWorktrees verifies that a fork starts from the parent's
+// uncommitted edits in both a git worktree and a non-git snapshot, on its own
+// branch and directories.
+func TestForkTaskWorktrees(t *testing.T) {
+	repo := setupTestRepo(t)
+	plain := t.TempDir()
+	if err := os.WriteFile(filepath.Join(plain, "notes.txt"), []byte("v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, runner := setupTestRunner(t, []string{repo, plain})
+
+	parentID := uuid.New()
+	parentWT, parentBranch, err := runner.setupWorktrees(parentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { runner.cleanupWorktrees(parentID, parentWT, parentBranch) })
+	for _, wt := range parentWT {
+		if err := os.WriteFile(filepath.Join(wt, "draft.txt"), []byte("draft\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	forkID := uuid.New()
+	forkWT, forkBranch, err := runner.ForkTaskWorktrees(forkID, parentWT)
+	if err != nil {
+		t.Fatalf("ForkTaskWorktrees: %v", err)
+	}
+	t.Cleanup(func() { runner.cleanupWorktrees(forkID, forkWT, forkBranch) })
+
+	if forkBranch != "task/"+forkID.String()[:8] {
+		t.Errorf("fork branch = %q", forkBranch)
+	}
+	for ws, wt := range forkWT {
+		if wt == parentWT[ws] {
+			t.Errorf("%s: fork shares the parent's worktree %s", ws, wt)
+		}
+		if got, err := os.ReadFile(filepath.Join(wt, "draft.txt")); err != nil || string(got) != "draft\n" {
+			t.Errorf("%s: fork draft.txt = %q, %v", ws, got, err)
+		}
+	}
+	if got := gitRun(t, forkWT[repo], "rev-parse", "--abbrev-ref", "HEAD"); got != forkBranch {
+		t.Errorf("fork git worktree on %q, want %q", got, forkBranch)
+	}
+}
+
 // TestCwdInDir verifies separator-aware path containment: a process cwd that is
 // the worktree dir or strictly inside it matches, while a sibling that merely
 // shares the dir as a leading string (the prefix bug) must NOT match.
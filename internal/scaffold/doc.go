@@ -0,0 +1,24 @@
+// Package scaffold creates new projects from the curated starter templates
+// behind `wallfacer new`. A template is a small, working project skeleton
+// (a Go HTTP service, a Next.js app) embedded in the binary; scaffolding
+// writes it into an empty directory with the project name substituted,
+// initialises a git repository with an initial commit, and returns the
+// template's setup instructions, which become the prompt of the first task
+// on the new board.
+//
+// Template files carry a ".tmpl" suffix in the embedded tree so an embedded
+// go.mod or package.json is never mistaken for part of this module; the
+// suffix is dropped on write. Placeholders are plain tokens rather than
+// text/template actions so template sources keep their own braces intact.
+//
+// # Connected packages
+//
+// Depends on [latere.ai/x/wallfacer/internal/gitutil] for the initial
+// commit. Consumed by [handler] (the /api/project-templates endpoints) and
+// [cli] (`wallfacer new --no-board`).
+//
+// # Usage
+//
+//	res, err := scaffold.Create(scaffold.Options{Template: "go-service", Dir: dir})
+//	task := res.SetupPrompt
+package scaffold
@@ -0,0 +1,182 @@
+package scaffold
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/gitutil"
+)
+
+//go:embed all:templates
+var templatesFS embed.FS
+
+// Placeholders substituted in every template file.
+const (
+	projectNamePlaceholder = "__PROJECT_NAME__"
+	goModulePlaceholder    = "__GO_MODULE__"
+)
+
+// setupFile holds a template's first-task instructions; it is not written
+// into the project.
+const setupFile = "setup.md"
+
+// Errors of [Create].
+var (
+	ErrUnknownTemplate = errors.New("unknown project template")
+	ErrNotEmpty        = errors.New("target directory is not empty")
+	ErrInvalidName     = errors.New("invalid project name")
+)
+
+// Template describes one starter template.
+type Template struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// templates lists the curated templates in display order. Each ID names a
+// directory under templates/.
+var templates = []Template{
+	{
+		ID:          "go-service",
+		Title:       "Go service",
+		Description: "HTTP service with a health endpoint, tests, and a Makefile.",
+	},
+	{
+		ID:          "nextjs-app",
+		Title:       "Next.js app",
+		Description: "Next.js app router project with TypeScript and a single page.",
+	},
+}
+
+// Templates returns the available templates in display order.
+func Templates() []Template {
+	return append([]Template(nil), templates...)
+}
+
+// Lookup returns the template with the given ID.
+func Lookup(id string) (Template, bool) {
+	for _, t := range templates {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// nameRe restricts project names to what is valid as a directory, a Go
+// module path element, and an npm package name.
+var nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Options configures [Create].
+type Options struct {
+	// Template is the ID of the template to use.
+	Template string
+	// Dir is the absolute project directory. It must not exist yet or be
+	// empty.
+	Dir string
+	// Name is the project name; empty means the base name of Dir,
+	// lowercased.
+	Name string
+	// Module is the Go module path of a Go template; empty means Name.
+	Module string
+}
+
+// Result describes a scaffolded project.
+type Result struct {
+	Dir      string   `json:"dir"`
+	Name     string   `json:"name"`
+	Template Template `json:"template"`
+	// Files lists the written files relative to Dir, in walk order.
+	Files []string `json:"files"`
+	// SetupPrompt is the prompt of the first "set up the project" task:
+	// the template's setup instructions with the project's details.
+	SetupPrompt string `json:"setup_prompt"`
+}
+
+// Create writes the template into opts.Dir, substitutes the project name
+// and module, and commits the result to a new git repository on branch
+// main. A failure after the directory was created leaves the partial
+// project in place for inspection.
+func Create(opts Options) (Result, error) {
+	tmpl, ok := Lookup(opts.Template)
+	if !ok {
+		return Result{}, fmt.Errorf("%w %q", ErrUnknownTemplate, opts.Template)
+	}
+	if !filepath.IsAbs(opts.Dir) {
+		return Result{}, fmt.Errorf("project directory must be absolute: %s", opts.Dir)
+	}
+	dir := filepath.Clean(opts.Dir)
+	name := opts.Name
+	if name == "" {
+		name = strings.ToLower(filepath.Base(dir))
+	}
+	if !nameRe.MatchString(name) {
+		return Result{}, fmt.Errorf("%w %q: use lowercase letters, digits, '.', '_' and '-'", ErrInvalidName, name)
+	}
+	module := opts.Module
+	if module == "" {
+		module = name
+	}
+	if err := ensureEmptyDir(dir); err != nil {
+		return Result{}, err
+	}
+
+	repl := strings.NewReplacer(projectNamePlaceholder, name, goModulePlaceholder, module)
+	root := path.Join("templates", tmpl.ID)
+	res := Result{Dir: dir, Name: name, Template: tmpl}
+	err := fs.WalkDir(templatesFS, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel := strings.TrimPrefix(p, root+"/")
+		data, err := templatesFS.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if rel == setupFile {
+			res.SetupPrompt = repl.Replace(string(data))
+			return nil
+		}
+		rel = strings.TrimSuffix(rel, ".tmpl")
+		dst := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, []byte(repl.Replace(string(data))), 0o644); err != nil {
+			return err
+		}
+		res.Files = append(res.Files, rel)
+		return nil
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("write %s template: %w", tmpl.ID, err)
+	}
+	msg := fmt.Sprintf("Scaffold %s from the %s template", name, tmpl.ID)
+	if err := gitutil.InitRepo(dir, "main", msg); err != nil {
+		return Result{}, err
+	}
+	return res, nil
+}
+
+// ensureEmptyDir creates dir, or accepts it when it already exists and has
+// no entries.
+func ensureEmptyDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return os.MkdirAll(dir, 0o755)
+	case err != nil:
+		return err
+	case len(entries) > 0:
+		return fmt.Errorf("%w: %s", ErrNotEmpty, dir)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package scaffold
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTemplates_HaveEmbeddedFilesAndSetup(t *testing.T) {
+	for _, tmpl := range Templates() {
+		entries, err := templatesFS.ReadDir("templates/" + tmpl.ID)
+		if err != nil || len(entries) == 0 {
+			t.Errorf("template %s has no embedded files: %v", tmpl.ID, err)
+		}
+		if _, err := templatesFS.ReadFile("templates/" + tmpl.ID + "/" + setupFile); err != nil {
+			t.Errorf("template %s has no %s: %v", tmpl.ID, setupFile, err)
+		}
+	}
+}
+
+func TestCreate_GoService(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "billing")
+	res, err := Create(Options{Template: "go-service", Dir: dir, Module: "example.com/acme/billing"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if res.Name != "billing" {
+		t.Errorf("Name = %q, want billing", res.Name)
+	}
+	for _, want := range []string{"go.mod", "cmd/server/main.go", ".gitignore"} {
+		if !slices.Contains(res.Files, want) {
+			t.Errorf("Files = %v, missing %s", res.Files, want)
+		}
+	}
+	if slices.Contains(res.Files, setupFile) {
+		t.Errorf("Files = %v, must not include %s", res.Files, setupFile)
+	}
+	gomod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(gomod), "module example.com/acme/billing") {
+		t.Errorf("go.mod = %q, want module substituted", gomod)
+	}
+	if strings.Contains(res.SetupPrompt, "__") || !strings.Contains(res.SetupPrompt, "billing") {
+		t.Errorf("SetupPrompt not substituted: %q", res.SetupPrompt)
+	}
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatalf("git status: %v", err)
+	}
+	if len(out) != 0 {
+		t.Errorf("working tree not clean after scaffold: %s", out)
+	}
+	branch, _ := exec.Command("git", "-C", dir, "branch", "--show-current").Output()
+	if strings.TrimSpace(string(branch)) != "main" {
+		t.Errorf("branch = %q, want main", branch)
+	}
+}
+
+func TestCreate_NextJSNameDefaultsToDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Storefront")
+	res, err := Create(Options{Template: "nextjs-app", Dir: dir})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	pkg, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Name != "storefront" || !strings.Contains(string(pkg), `"name": "storefront"`) {
+		t.Errorf("name = %q, package.json = %s", res.Name, pkg)
+	}
+}
+
+func TestCreate_Errors(t *testing.T) {
+	nonEmpty := t.TempDir()
+	if err := os.WriteFile(filepath.Join(nonEmpty, "x"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		opts Options
+		want error
+	}{
+		{"unknown template", Options{Template: "rails", Dir: filepath.Join(t.TempDir(), "p")}, ErrUnknownTemplate},
+		{"not empty", Options{Template: "go-service", Dir: nonEmpty, Name: "p"}, ErrNotEmpty},
+		{"bad name", Options{Template: "go-service", Dir: filepath.Join(t.TempDir(), "p"), Name: "My App"}, ErrInvalidName},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Create(tc.opts); !errors.Is(err, tc.want) {
+				t.Errorf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+	if _, err := Create(Options{Template: "go-service", Dir: "relative/p"}); err == nil {
+		t.Error("relative dir: want error")
+	}
+}
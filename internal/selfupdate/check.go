@@ -0,0 +1,45 @@
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+)
+
+// CheckFile is the name of the release-check cache under the config
+// directory.
+const CheckFile = "update-check.json"
+
+// CheckTTL is how long a cached release lookup is reused.
+const CheckTTL = 24 * time.Hour
+
+// checkCache is the on-disk form of the last release lookup.
+type checkCache struct {
+	CheckedAt  time.Time `json:"checked_at"`
+	Prerelease bool      `json:"prerelease"` // whether prereleases were included
+	Latest     string    `json:"latest"`
+}
+
+// CheckCached returns the newest release version, reusing the lookup stored
+// in cachePath when it is younger than CheckTTL and was made with the same
+// prerelease setting. A failed lookup returns the error and leaves the
+// cache alone, so the next start tries again.
+func (c *Client) CheckCached(ctx context.Context, cachePath string, includePrerelease bool, now time.Time) (string, error) {
+	if data, err := os.ReadFile(cachePath); err == nil {
+		var cached checkCache
+		if json.Unmarshal(data, &cached) == nil && cached.Prerelease == includePrerelease &&
+			cached.Latest != "" && now.Sub(cached.CheckedAt) < CheckTTL {
+			return cached.Latest, nil
+		}
+	}
+	rel, err := c.Latest(ctx, includePrerelease)
+	if err != nil {
+		return "", err
+	}
+	data, _ := json.Marshal(checkCache{CheckedAt: now, Prerelease: includePrerelease, Latest: rel.Version()})
+	_ = atomicfile.Write(cachePath, data, 0o600)
+	return rel.Version(), nil
+}
@@ -0,0 +1,25 @@
+// Package selfupdate replaces the running wallfacer binary with a newer
+// GitHub release. It finds the newest release, downloads the binary built
+// for the current platform, checks it against the release's SHA256SUMS
+// manifest, and swaps it in with an atomic rename. When cosign is installed,
+// the manifest's keyless signature is verified first, which binds every
+// checksum in it to the release workflow of the wallfacer repository.
+//
+// The package also decides when a running version is far enough behind the
+// newest release to warrant a startup banner, caching the release lookup so
+// a restart does not hit the GitHub API each time.
+//
+// # Connected packages
+//
+// Depends on [latere.ai/x/wallfacer/internal/pkg/atomicfile] for the binary
+// swap and the check cache. Consumed by [cli] (`wallfacer update` and the
+// banner printed by `wallfacer run`).
+//
+// # Usage
+//
+//	c := &selfupdate.Client{}
+//	rel, err := c.Latest(ctx, selfupdate.IsPrerelease(current))
+//	if selfupdate.Compare(rel.Version(), current) > 0 {
+//		err = c.Install(ctx, rel, exePath, selfupdate.VerifyOptions{})
+//	}
+package selfupdate
@@ -0,0 +1,221 @@
+package selfupdate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+)
+
+// Release asset names of the supply-chain attestation the release workflow
+// publishes next to the binaries.
+const (
+	ChecksumsAsset = "SHA256SUMS"
+	BundleAsset    = "SHA256SUMS.cosign.bundle"
+)
+
+// Identity the cosign bundle's keyless certificate must carry: a GitHub
+// Actions workflow of the wallfacer repository.
+const (
+	signerIdentityRegexp = `^https://github.com/changkun/wallfacer/`
+	signerOIDCIssuer     = "https://token.actions.githubusercontent.com"
+)
+
+// maxBinaryBytes bounds the binary download; maxSumsBytes bounds the
+// checksum manifest and the signature bundle.
+const (
+	maxBinaryBytes = 512 << 20
+	maxSumsBytes   = 1 << 20
+)
+
+// Errors of [Client.Install].
+var (
+	ErrNoAsset          = errors.New("release has no asset")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrCosignMissing    = errors.New("cosign not found on PATH")
+)
+
+// VerifyOptions controls the signature check of [Client.Install].
+type VerifyOptions struct {
+	// RequireSignature fails the update when the checksum manifest's
+	// signature cannot be verified, including when cosign is not installed.
+	// Without it a missing cosign only downgrades verification to checksums.
+	RequireSignature bool
+	// Cosign is the cosign executable; empty means "cosign" on PATH.
+	Cosign string
+}
+
+// InstallResult describes a completed update.
+type InstallResult struct {
+	Version string
+	SHA256  string
+	// Signed is true when the checksum manifest's signature was verified.
+	Signed bool
+}
+
+// Install downloads rel's binary for the running platform, verifies it, and
+// atomically replaces exePath with it. The binary must match its entry in
+// the release's SHA256SUMS; when cosign is available the manifest's
+// signature bundle is verified first (see [VerifyOptions]). exePath is left
+// untouched on any failure.
+func (c *Client) Install(ctx context.Context, rel Release, exePath string, opts VerifyOptions) (InstallResult, error) {
+	name := PlatformAsset()
+	bin, ok := rel.Asset(name)
+	if !ok {
+		return InstallResult{}, fmt.Errorf("%w %s", ErrNoAsset, name)
+	}
+	sumsAsset, ok := rel.Asset(ChecksumsAsset)
+	if !ok {
+		return InstallResult{}, fmt.Errorf("%w %s", ErrNoAsset, ChecksumsAsset)
+	}
+	sums, err := c.download(ctx, sumsAsset.URL, maxSumsBytes)
+	if err != nil {
+		return InstallResult{}, err
+	}
+
+	res := InstallResult{Version: rel.Version()}
+	switch err := c.verifySignature(ctx, rel, sums, opts); {
+	case err == nil:
+		res.Signed = true
+	case errors.Is(err, ErrCosignMissing) && !opts.RequireSignature:
+	default:
+		return InstallResult{}, err
+	}
+
+	want, ok := ParseChecksums(sums)[name]
+	if !ok {
+		return InstallResult{}, fmt.Errorf("%s lists no checksum for %s", ChecksumsAsset, name)
+	}
+	data, err := c.download(ctx, bin.URL, maxBinaryBytes)
+	if err != nil {
+		return InstallResult{}, err
+	}
+	sum := sha256.Sum256(data)
+	res.SHA256 = hex.EncodeToString(sum[:])
+	if res.SHA256 != want {
+		return InstallResult{}, fmt.Errorf("%w for %s: got %s, want %s", ErrChecksumMismatch, name, res.SHA256, want)
+	}
+	if err := Replace(exePath, data); err != nil {
+		return InstallResult{}, err
+	}
+	return res, nil
+}
+
+// verifySignature checks the cosign bundle over the checksum manifest.
+func (c *Client) verifySignature(ctx context.Context, rel Release, sums []byte, opts VerifyOptions) error {
+	cosign := opts.Cosign
+	if cosign == "" {
+		cosign = "cosign"
+	}
+	path, err := exec.LookPath(cosign)
+	if err != nil {
+		return ErrCosignMissing
+	}
+	bundleAsset, ok := rel.Asset(BundleAsset)
+	if !ok {
+		return fmt.Errorf("%w %s", ErrNoAsset, BundleAsset)
+	}
+	bundle, err := c.download(ctx, bundleAsset.URL, maxSumsBytes)
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "wallfacer-update-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	sumsPath, bundlePath := filepath.Join(dir, ChecksumsAsset), filepath.Join(dir, BundleAsset)
+	if err := os.WriteFile(sumsPath, sums, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(bundlePath, bundle, 0o600); err != nil {
+		return err
+	}
+	out, err := exec.CommandContext(ctx, path, "verify-blob",
+		"--bundle", bundlePath,
+		"--certificate-identity-regexp", signerIdentityRegexp,
+		"--certificate-oidc-issuer", signerOIDCIssuer,
+		sumsPath).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("verify %s signature: %w: %s", ChecksumsAsset, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (c *Client) download(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", url, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("download %s: larger than %d bytes", url, limit)
+	}
+	return data, nil
+}
+
+// ParseChecksums parses a sha256sum manifest ("<hex>  <name>" per line,
+// with an optional "*" binary-mode marker) into name → lowercase hex digest.
+func ParseChecksums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		sum, name, ok := strings.Cut(strings.TrimSpace(sc.Text()), " ")
+		if !ok || len(sum) != sha256.Size*2 {
+			continue
+		}
+		name = strings.TrimPrefix(strings.TrimSpace(name), "*")
+		sums[name] = strings.ToLower(sum)
+	}
+	return sums
+}
+
+// Replace atomically swaps the file at exePath for data, keeping it
+// executable. Windows cannot overwrite a running executable but can rename
+// it, so there the old binary moves aside to exePath+".old" first.
+func Replace(exePath string, data []byte) error {
+	info, err := os.Stat(exePath)
+	if err != nil {
+		return err
+	}
+	perm := info.Mode().Perm() | 0o111
+	if runtime.GOOS == "windows" {
+		old := exePath + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(exePath, old); err != nil {
+			return fmt.Errorf("move running binary aside: %w", err)
+		}
+		if err := atomicfile.WriteSync(exePath, data, perm); err != nil {
+			_ = os.Rename(old, exePath)
+			return fmt.Errorf("write %s: %w", exePath, err)
+		}
+		return nil
+	}
+	if err := atomicfile.WriteSync(exePath, data, perm); err != nil {
+		return fmt.Errorf("write %s: %w", exePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package selfupdate
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults for [Client]: the public GitHub API and the wallfacer repository.
+const (
+	DefaultBaseURL = "https://api.github.com"
+	DefaultRepo    = "changkun/wallfacer"
+)
+
+// maxAPIBytes bounds a release list response.
+const maxAPIBytes = 4 << 20
+
+// ErrNoRelease reports that the repository has no release matching the
+// request, e.g. only prereleases when stable ones were asked for.
+var ErrNoRelease = errors.New("no matching release")
+
+// Client looks up and downloads wallfacer releases.
+type Client struct {
+	// BaseURL is the GitHub API root; empty means [DefaultBaseURL].
+	BaseURL string
+	// Repo is the owner/name of the repository; empty means [DefaultRepo].
+	Repo string
+	// HTTP is the underlying client; nil means a client with a 5 minute
+	// timeout, long enough for the binary download.
+	HTTP *http.Client
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL == "" {
+		return DefaultBaseURL
+	}
+	return strings.TrimRight(c.BaseURL, "/")
+}
+
+func (c *Client) repo() string {
+	return cmp.Or(c.Repo, DefaultRepo)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP != nil {
+		return c.HTTP
+	}
+	return &http.Client{Timeout: 5 * time.Minute}
+}
+
+// Asset is one file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a published GitHub release.
+type Release struct {
+	Tag         string    `json:"tag_name"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Version returns the release tag without its leading "v", the form the
+// binary reports as its own version.
+func (r Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Asset returns the asset called name.
+func (r Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Latest returns the newest published release. Prereleases are skipped
+// unless includePrerelease is set.
+func (c *Client) Latest(ctx context.Context, includePrerelease bool) (Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases?per_page=30", c.baseURL(), c.repo())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("list releases: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("list releases: %s", resp.Status)
+	}
+	var releases []Release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAPIBytes)).Decode(&releases); err != nil {
+		return Release{}, fmt.Errorf("decode releases: %w", err)
+	}
+	var best *Release
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || (r.Prerelease && !includePrerelease) {
+			continue
+		}
+		if best == nil || Compare(r.Version(), best.Version()) > 0 {
+			best = r
+		}
+	}
+	if best == nil {
+		return Release{}, ErrNoRelease
+	}
+	return *best, nil
+}
+
+// AssetName returns the name of the release binary for a platform, matching
+// the names the release workflow and install.sh use.
+func AssetName(goos, goarch string) string {
+	name := "wallfacer-" + goos + "-" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// PlatformAsset is [AssetName] for the running platform.
+func PlatformAsset() string {
+	return AssetName(runtime.GOOS, runtime.GOARCH)
+}
+
+// version is a parsed MAJOR.MINOR.PATCH[-PRERELEASE] string.
+type version struct {
+	nums [3]int
+	pre  string
+}
+
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+") // build metadata never affects ordering
+	core, pre, _ := strings.Cut(s, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return version{}, false
+	}
+	var v version
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.nums[i] = n
+	}
+	v.pre = pre
+	return v, true
+}
+
+// Compare orders two versions like semver: -1 when a is older than b, 1
+// when newer, 0 when equal. A prerelease sorts before its release, and
+// prerelease identifiers compare numerically where both are numbers
+// (alpha.9 < alpha.10). Strings that are not versions sort before any
+// version, so a dev build always counts as outdated.
+func Compare(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	for i := range va.nums {
+		if c := cmp.Compare(va.nums[i], vb.nums[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case va.pre == vb.pre:
+		return 0
+	case va.pre == "":
+		return 1
+	case vb.pre == "":
+		return -1
+	}
+	pa, pb := strings.Split(va.pre, "."), strings.Split(vb.pre, ".")
+	for i := range min(len(pa), len(pb)) {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		var c int
+		switch {
+		case errA == nil && errB == nil:
+			c = cmp.Compare(na, nb)
+		case errA == nil:
+			c = -1
+		case errB == nil:
+			c = 1
+		default:
+			c = strings.Compare(pa[i], pb[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(pa), len(pb))
+}
+
+// IsPrerelease reports whether v carries a prerelease suffix. A binary built
+// from a prerelease follows prereleases when it updates.
+func IsPrerelease(v string) bool {
+	pv, ok := parseVersion(v)
+	return ok && pv.pre != ""
+}
+
+// SignificantPatchGap is how many patch releases behind a running version
+// may fall before [Outdated] reports it; a newer major or minor version is
+// always significant.
+const SignificantPatchGap = 3
+
+// Outdated reports whether latest is far enough ahead of current to warrant
+// a startup banner: a newer major or minor version, or at least
+// SignificantPatchGap patch releases. Prerelease steps alone never are.
+func Outdated(current, latest string) bool {
+	vc, okC := parseVersion(current)
+	vl, okL := parseVersion(latest)
+	if !okC || !okL || Compare(latest, current) <= 0 {
+		return false
+	}
+	if vl.nums[0] != vc.nums[0] || vl.nums[1] != vc.nums[1] {
+		return true
+	}
+	return vl.nums[2]-vc.nums[2] >= SignificantPatchGap
+}
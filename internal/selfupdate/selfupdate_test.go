@@ -0,0 +1,198 @@
+package selfupdate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"0.0.19", "0.0.19", 0},
+		{"v0.0.19", "0.0.19", 0},
+		{"0.0.20", "0.0.19", 1},
+		{"0.1.0", "0.0.99", 1},
+		{"1.0.0", "1.0.0-rc.1", 1},
+		{"0.0.7-alpha.10", "0.0.7-alpha.9", 1},
+		{"0.0.7-alpha.2", "0.0.7-beta.1", -1},
+		{"0.0.7-alpha", "0.0.7-alpha.1", -1},
+		{"1.2.3+build.5", "1.2.3", 0},
+		{"", "0.0.1", -1},
+		{"abc1234", "0.0.1", -1},
+	} {
+		if got := Compare(tc.a, tc.b); got != tc.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestOutdated(t *testing.T) {
+	for _, tc := range []struct {
+		current, latest string
+		want            bool
+	}{
+		{"0.0.16", "0.0.19", true},
+		{"0.0.17", "0.0.19", false},
+		{"0.0.19", "0.1.0", true},
+		{"0.9.9", "1.0.0", true},
+		{"0.0.7-alpha.3", "0.0.7-alpha.24", false},
+		{"0.0.19", "0.0.19", false},
+		{"0.0.20", "0.0.19", false},
+		{"dev", "0.0.19", false},
+	} {
+		if got := Outdated(tc.current, tc.latest); got != tc.want {
+			t.Errorf("Outdated(%q, %q) = %v, want %v", tc.current, tc.latest, got, tc.want)
+		}
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	a := sha256.Sum256([]byte("a"))
+	b := sha256.Sum256([]byte("b"))
+	data := fmt.Sprintf("%x  wallfacer-linux-amd64\n%X *wallfacer-windows-amd64.exe\nnot a line\n\n", a, b)
+	sums := ParseChecksums([]byte(data))
+	if len(sums) != 2 || sums["wallfacer-linux-amd64"] != hex.EncodeToString(a[:]) ||
+		sums["wallfacer-windows-amd64.exe"] != hex.EncodeToString(b[:]) {
+		t.Errorf("sums = %v", sums)
+	}
+}
+
+// fakeRelease serves a release list and the assets of one release, with the
+// binary's checksum listed as sum (the real one when sum is empty).
+func fakeRelease(t *testing.T, binary []byte, sum string) (*Client, Release, *atomic.Int32) {
+	t.Helper()
+	if sum == "" {
+		s := sha256.Sum256(binary)
+		sum = hex.EncodeToString(s[:])
+	}
+	var lists atomic.Int32
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	name := PlatformAsset()
+	releases := []Release{
+		{Tag: "v0.0.21-rc.1", Prerelease: true},
+		{Tag: "v0.0.22", Draft: true},
+		{Tag: "v0.0.20", Assets: []Asset{
+			{Name: name, URL: srv.URL + "/dl/" + name},
+			{Name: ChecksumsAsset, URL: srv.URL + "/dl/" + ChecksumsAsset},
+		}},
+		{Tag: "v0.0.19"},
+	}
+	mux.HandleFunc("GET /repos/changkun/wallfacer/releases", func(w http.ResponseWriter, _ *http.Request) {
+		lists.Add(1)
+		_ = json.NewEncoder(w).Encode(releases)
+	})
+	mux.HandleFunc("GET /dl/"+name, func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(binary) })
+	mux.HandleFunc("GET /dl/"+ChecksumsAsset, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s  %s\n", sum, name)
+	})
+	return &Client{BaseURL: srv.URL}, releases[2], &lists
+}
+
+func TestLatest_SkipsDraftsAndPrereleases(t *testing.T) {
+	c, _, _ := fakeRelease(t, nil, "")
+	rel, err := c.Latest(context.Background(), false)
+	if err != nil || rel.Tag != "v0.0.20" {
+		t.Fatalf("stable latest = %q, %v; want v0.0.20", rel.Tag, err)
+	}
+	rel, err = c.Latest(context.Background(), true)
+	if err != nil || rel.Tag != "v0.0.21-rc.1" {
+		t.Fatalf("prerelease latest = %q, %v; want v0.0.21-rc.1", rel.Tag, err)
+	}
+}
+
+func writeExe(t *testing.T) string {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "wallfacer")
+	if err := os.WriteFile(exe, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return exe
+}
+
+// noCosign points the signature check at an executable that does not exist,
+// so the tests behave the same whether or not cosign is installed.
+var noCosign = VerifyOptions{Cosign: "wallfacer-test-no-cosign"}
+
+func TestInstall_ReplacesVerifiedBinary(t *testing.T) {
+	c, rel, _ := fakeRelease(t, []byte("new binary"), "")
+	exe := writeExe(t)
+
+	res, err := c.Install(context.Background(), rel, exe, noCosign)
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if res.Version != "0.0.20" || res.Signed {
+		t.Errorf("result = %+v", res)
+	}
+	data, _ := os.ReadFile(exe)
+	if string(data) != "new binary" {
+		t.Errorf("exe = %q, want the new binary", data)
+	}
+	if info, _ := os.Stat(exe); info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("exe mode = %v, want executable", info.Mode())
+	}
+}
+
+func TestInstall_ChecksumMismatchKeepsBinary(t *testing.T) {
+	other := sha256.Sum256([]byte("something else"))
+	c, rel, _ := fakeRelease(t, []byte("tampered"), hex.EncodeToString(other[:]))
+	exe := writeExe(t)
+
+	if _, err := c.Install(context.Background(), rel, exe, noCosign); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("err = %v, want ErrChecksumMismatch", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old binary" {
+		t.Errorf("exe replaced despite mismatch: %q", data)
+	}
+}
+
+func TestInstall_RequireSignatureWithoutCosign(t *testing.T) {
+	c, rel, _ := fakeRelease(t, []byte("new binary"), "")
+	exe := writeExe(t)
+	opts := noCosign
+	opts.RequireSignature = true
+
+	if _, err := c.Install(context.Background(), rel, exe, opts); !errors.Is(err, ErrCosignMissing) {
+		t.Fatalf("err = %v, want ErrCosignMissing", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old binary" {
+		t.Errorf("exe replaced without a verified signature: %q", data)
+	}
+}
+
+func TestCheckCached(t *testing.T) {
+	c, _, lists := fakeRelease(t, nil, "")
+	cache := filepath.Join(t.TempDir(), CheckFile)
+	now := time.Now()
+
+	for i, at := range []time.Time{now, now.Add(time.Hour)} {
+		v, err := c.CheckCached(context.Background(), cache, false, at)
+		if err != nil || v != "0.0.20" {
+			t.Fatalf("check %d = %q, %v", i, v, err)
+		}
+	}
+	if lists.Load() != 1 {
+		t.Errorf("release list fetched %d times within the TTL, want 1", lists.Load())
+	}
+	if _, err := c.CheckCached(context.Background(), cache, true, now); err != nil || lists.Load() != 2 {
+		t.Errorf("changed prerelease setting reused the cache: %v, %d fetches", err, lists.Load())
+	}
+	if _, err := c.CheckCached(context.Background(), cache, true, now.Add(CheckTTL+time.Minute)); err != nil || lists.Load() != 3 {
+		t.Errorf("stale cache reused: %v, %d fetches", err, lists.Load())
+	}
+}
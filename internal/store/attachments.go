@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// attachmentBlobPrefix is the blob key prefix under which a task's
+// attachment files are stored.
+const attachmentBlobPrefix = "attachments/"
+
+// Attachment limits: a task holds at most MaxAttachments files of at most
+// MaxAttachmentBytes each.
+const (
+	MaxAttachments     = 20
+	MaxAttachmentBytes = 10 << 20
+)
+
+// Attachment describes one file filed with a task. The bytes live in the
+// task blob "attachments/<Name>"; read them with ReadTaskAttachment.
+type Attachment struct {
+	Name        string `json:"name"`                   // file name, unique within the task
+	ContentType string `json:"content_type,omitempty"` // MIME type reported by the sender
+	Size        int64  `json:"size"`                   // bytes
+}
+
+// AddTaskAttachment stores data as an attachment of a task and records it
+// on the task. The name is reduced to a safe base name and suffixed when
+// the task already has a file of that name; the stored Attachment carries
+// the final name.
+func (s *Store) AddTaskAttachment(_ context.Context, id uuid.UUID, name, contentType string, data []byte) (Attachment, error) {
+	if len(data) > MaxAttachmentBytes {
+		return Attachment{}, fmt.Errorf("attachment %q is %d bytes; the limit is %d", name, len(data), MaxAttachmentBytes)
+	}
+	var att Attachment
+	err := s.mutateTask(id, func(t *Task) error {
+		if len(t.Attachments) >= MaxAttachments {
+			return fmt.Errorf("a task holds at most %d attachments", MaxAttachments)
+		}
+		att = Attachment{Name: uniqueAttachmentName(t.Attachments, SanitizeAttachmentName(name)), ContentType: contentType, Size: int64(len(data))}
+		if err := s.backend.SaveBlob(id, attachmentBlobPrefix+att.Name, data); err != nil {
+			return err
+		}
+		t.Attachments = append(t.Attachments, att)
+		return nil
+	})
+	return att, err
+}
+
+// ReadTaskAttachment returns the bytes of a task's attachment. It returns
+// os.ErrNotExist when the task has no attachment of that name.
+func (s *Store) ReadTaskAttachment(id uuid.UUID, name string) ([]byte, error) {
+	if name != SanitizeAttachmentName(name) {
+		return nil, fmt.Errorf("invalid attachment name %q", name)
+	}
+	return s.backend.ReadBlob(id, attachmentBlobPrefix+name)
+}
+
+// SanitizeAttachmentName reduces a sender-supplied file name to a base
+// name safe to use as a blob key: directory parts, control characters,
+// and leading dots are dropped, and an empty result becomes "attachment".
+func SanitizeAttachmentName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '/' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+	if name == "" {
+		return "attachment"
+	}
+	return name
+}
+
+// uniqueAttachmentName returns name, or name with a "-2", "-3", ... suffix
+// before its extension when existing already holds it.
+func uniqueAttachmentName(existing []Attachment, name string) string {
+	taken := func(n string) bool {
+		return slices.ContainsFunc(existing, func(a Attachment) bool { return a.Name == n })
+	}
+	if !taken(name) {
+		return name
+	}
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		if n := stem + "-" + strconv.Itoa(i) + ext; !taken(n) {
+			return n
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestAddTaskAttachment verifies that attachments are stored under safe,
+// unique names, survive a restart, and are bounded in size.
+func TestAddTaskAttachment(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := s.AddTaskAttachment(bg(), task.ID, "../../screenshot.png", "image/png", []byte("png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.AddTaskAttachment(bg(), task.ID, `C:\shots\screenshot.png`, "image/png", []byte("png2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Name != "screenshot.png" || second.Name != "screenshot-2.png" {
+		t.Errorf("names = %q, %q", first.Name, second.Name)
+	}
+	if _, err := s.AddTaskAttachment(bg(), task.ID, "big.bin", "", make([]byte, MaxAttachmentBytes+1)); err == nil {
+		t.Error("oversized attachment accepted")
+	}
+
+	s.Close()
+	reopened, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := reopened.GetTask(bg(), task.ID)
+	if len(got.Attachments) != 2 || got.Attachments[1].Size != 4 {
+		t.Errorf("Attachments = %+v", got.Attachments)
+	}
+	if data, err := reopened.ReadTaskAttachment(task.ID, "screenshot-2.png"); err != nil || string(data) != "png2" {
+		t.Errorf("ReadTaskAttachment = %q, %v", data, err)
+	}
+	if _, err := reopened.ReadTaskAttachment(task.ID, "missing.txt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing attachment err = %v", err)
+	}
+	if _, err := reopened.ReadTaskAttachment(task.ID, "../task.json"); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Errorf("traversal err = %v", err)
+	}
+}
+
+func TestSanitizeAttachmentName(t *testing.T) {
+	for in, want := range map[string]string{
+		"report.pdf":       "report.pdf",
+		"a/b/report.pdf":   "report.pdf",
+		".env":             "env",
+		"..":               "attachment",
+		"":                 "attachment",
+		"line\nbreak.txt":  "linebreak.txt",
+		`dir\file name.md`: "file name.md",
+	} {
+		if got := SanitizeAttachmentName(in); got != want {
+			t.Errorf("SanitizeAttachmentName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package store
 
-import "github.com/google/uuid"
+import (
+	"io"
+
+	"github.com/google/uuid"
+)
 
 // StorageBackend abstracts the three persistence concerns of the store:
 // tasks (structured, indexed), events (ordered, append-heavy), and blobs
@@ -25,3 +29,19 @@ type StorageBackend interface {
 	ListBlobs(taskID uuid.UUID, prefix string) ([]string, error) // List blob keys matching a prefix.
 	ListBlobOwners(key string) ([]uuid.UUID, error)              // Find all tasks that have a given blob key.
 }
+
+// BlobStreamer is implemented by backends that can write a blob as it is
+// produced instead of from one in-memory slice. Store.OpenTurnOutput uses
+// it to keep agent output out of memory; without it the output is buffered
+// and written with SaveBlob.
+type BlobStreamer interface {
+	CreateBlob(taskID uuid.UUID, key string) (BlobWriter, error)
+}
+
+// BlobWriter writes one blob. Nothing is visible under the key until
+// Commit; Abort discards what was written.
+type BlobWriter interface {
+	io.Writer
+	Commit() error
+	Abort()
+}
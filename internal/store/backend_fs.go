@@ -1,6 +1,7 @@
 package store
 
 import (
+	"bytes"
 	"cmp"
 	"encoding/json"
 	"errors"
@@ -12,8 +13,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/constants"
 	"latere.ai/x/wallfacer/internal/logger"
 	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
 	"latere.ai/x/wallfacer/internal/pkg/ndjson"
 )
 
@@ -22,6 +25,10 @@ import (
 // under the root data directory.
 type FilesystemBackend struct {
 	dir string // root data directory, e.g. ~/.wallfacer/data/<workspace-key>/
+	// cipher seals task.json, traces, and blobs on write and opens them on
+	// read. Nil leaves files in plaintext; plaintext files are read either
+	// way, so a directory can be encrypted incrementally.
+	cipher *atrest.Cipher
 }
 
 // NewFilesystemBackend creates a FilesystemBackend rooted at dir.
@@ -33,6 +40,34 @@ func NewFilesystemBackend(dir string) (*FilesystemBackend, error) {
 	return &FilesystemBackend{dir: dir}, nil
 }
 
+// readFile reads path and decrypts it when sealed.
+func (b *FilesystemBackend) readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return b.cipher.Open(data)
+}
+
+// writeFile encrypts data when a cipher is configured and writes it atomically.
+func (b *FilesystemBackend) writeFile(path string, data []byte) error {
+	sealed, err := b.cipher.Seal(data)
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, sealed, 0644)
+}
+
+// writeJSON marshals v as indented JSON, matching atomicfile.WriteJSON, and
+// writes it with writeFile.
+func (b *FilesystemBackend) writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return b.writeFile(path, data)
+}
+
 // Init creates the task directory and traces subdirectory.
 func (b *FilesystemBackend) Init(taskID uuid.UUID) error {
 	tracesDir := filepath.Join(b.dir, taskID.String(), "traces")
@@ -58,7 +93,7 @@ func (b *FilesystemBackend) LoadAll() ([]*Task, error) {
 		}
 
 		taskPath := filepath.Join(b.dir, entry.Name(), "task.json")
-		raw, err := os.ReadFile(taskPath)
+		disk, err := os.ReadFile(taskPath)
 		if err != nil {
 			// A UUID directory without task.json is an incomplete task, not
 			// corruption: Init creates the dir (and traces/) before SaveTask
@@ -72,6 +107,13 @@ func (b *FilesystemBackend) LoadAll() ([]*Task, error) {
 			logger.Store.Warn("skipping task", "name", entry.Name(), "error", err)
 			continue
 		}
+		raw, err := b.cipher.Open(disk)
+		if err != nil {
+			// A missing or wrong key affects every sealed task alike; fail
+			// the load rather than start with an empty-looking board whose
+			// saves could never be reconciled with the encrypted files.
+			return nil, fmt.Errorf("task %s: %w", entry.Name(), err)
+		}
 
 		// Determine file mod time for defaulting missing timestamps.
 		var modTime time.Time
@@ -88,7 +130,16 @@ func (b *FilesystemBackend) LoadAll() ([]*Task, error) {
 		}
 
 		// Persist migrated task back to disk so future loads skip migration.
+		// Schema upgrades keep the pre-migration file alongside as a backup
+		// so a bad migration can be rolled back by hand.
 		if changed {
+			if from := rawSchemaVersion(raw); from < constants.CurrentTaskSchemaVersion {
+				if err := backupTaskJSON(taskPath, disk, from); err != nil {
+					logger.Store.Warn("failed to back up task before migration", "name", entry.Name(), "error", err)
+					tasks = append(tasks, &task)
+					continue
+				}
+			}
 			if err := b.SaveTask(&task); err != nil {
 				logger.Store.Warn("failed to persist migrated task", "name", entry.Name(), "error", err)
 			}
@@ -99,10 +150,21 @@ func (b *FilesystemBackend) LoadAll() ([]*Task, error) {
 	return tasks, nil
 }
 
+// backupTaskJSON writes raw to task.json.v<version>.bak next to taskPath.
+// An existing backup for the same version is left untouched so repeated
+// failed loads never overwrite the original pre-migration file.
+func backupTaskJSON(taskPath string, raw []byte, version int) error {
+	backupPath := fmt.Sprintf("%s.v%d.bak", taskPath, version)
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+	return atomicfile.Write(backupPath, raw, 0644)
+}
+
 // SaveTask atomically writes a task's metadata to its task.json file.
 func (b *FilesystemBackend) SaveTask(t *Task) error {
 	path := filepath.Join(b.dir, t.ID.String(), "task.json")
-	return atomicfile.WriteJSON(path, t, 0644)
+	return b.writeJSON(path, t)
 }
 
 // RemoveTask permanently removes a task's directory and all its data.
@@ -118,7 +180,7 @@ func (b *FilesystemBackend) SaveEvent(taskID uuid.UUID, seq int, event TaskEvent
 		return err
 	}
 	path := filepath.Join(tracesDir, fmt.Sprintf("%04d.json", seq))
-	return atomicfile.WriteJSON(path, event, 0644)
+	return b.writeJSON(path, event)
 }
 
 // LoadEvents reads all events for a task from compact.ndjson and individual
@@ -136,7 +198,11 @@ func (b *FilesystemBackend) LoadEvents(taskID uuid.UUID) ([]TaskEvent, int64, er
 
 	// Phase 1: Read the compact file which contains events from previous sessions.
 	compactPath := filepath.Join(tracesDir, "compact.ndjson")
-	events, err := ndjson.ReadFile[TaskEvent](compactPath,
+	compact, err := b.readFile(compactPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, 0, err
+	}
+	events, err := ndjson.Read[TaskEvent](bytes.NewReader(compact),
 		ndjson.WithBufferSize(64*1024, 1024*1024),
 		ndjson.WithOnError(func(lineNum int, err error) {
 			logger.Store.Warn("skipping compact trace line", "task", dirName, "trace", "compact.ndjson", "line", lineNum, "error", err)
@@ -166,7 +232,7 @@ func (b *FilesystemBackend) LoadEvents(taskID uuid.UUID) ([]TaskEvent, int64, er
 		if !ok || int64(traceFile.seq) <= compactMaxID {
 			continue
 		}
-		raw, err := os.ReadFile(filepath.Join(tracesDir, te.Name()))
+		raw, err := b.readFile(filepath.Join(tracesDir, te.Name()))
 		if err != nil {
 			logger.Store.Warn("skipping trace", "task", dirName, "trace", te.Name(), "error", err)
 			continue
@@ -223,7 +289,7 @@ func (b *FilesystemBackend) CompactEvents(taskID uuid.UUID, events []TaskEvent)
 	}
 
 	compactPath := filepath.Join(tracesDir, "compact.ndjson")
-	if err := atomicfile.Write(compactPath, compact, 0644); err != nil {
+	if err := b.writeFile(compactPath, compact); err != nil {
 		return err
 	}
 
@@ -255,13 +321,28 @@ func (b *FilesystemBackend) SaveBlob(taskID uuid.UUID, key string, data []byte)
 			return err
 		}
 	}
-	return atomicfile.Write(path, data, 0644)
+	return b.writeFile(path, data)
+}
+
+// CreateBlob starts writing a named blob under the task's directory; the
+// data streams to a temporary file that Commit renames into place. With
+// encryption configured the blob is sealed as a whole, so it is buffered
+// and written by SaveBlob on Commit.
+func (b *FilesystemBackend) CreateBlob(taskID uuid.UUID, key string) (BlobWriter, error) {
+	if b.cipher != nil {
+		return &bufferedBlob{save: func(data []byte) error { return b.SaveBlob(taskID, key, data) }}, nil
+	}
+	path := filepath.Join(b.dir, taskID.String(), key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return atomicfile.Create(path, 0644)
 }
 
 // ReadBlob reads named data from the task's directory.
 func (b *FilesystemBackend) ReadBlob(taskID uuid.UUID, key string) ([]byte, error) {
 	path := filepath.Join(b.dir, taskID.String(), key)
-	return os.ReadFile(path)
+	return b.readFile(path)
 }
 
 // DeleteBlob removes named data from the task's directory.
@@ -305,6 +386,9 @@ func (b *FilesystemBackend) ListBlobs(taskID uuid.UUID, prefix string) ([]string
 		if filePrefix != "" && !strings.HasPrefix(name, filePrefix) {
 			continue
 		}
+		if strings.HasPrefix(name, ".tmp-") {
+			continue // a blob still being written (see CreateBlob)
+		}
 		key := filepath.Join(dirPart, name)
 		keys = append(keys, key)
 	}
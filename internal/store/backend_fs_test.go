@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -160,6 +161,82 @@ func TestFilesystemBackend_SaveTaskLoadAll(t *testing.T) {
 	}
 }
 
+func TestFilesystemBackend_LoadAll_BacksUpBeforeSchemaUpgrade(t *testing.T) {
+	b := newTestBackend(t)
+	id := uuid.New()
+	if err := b.Init(id); err != nil {
+		t.Fatal(err)
+	}
+	taskPath := filepath.Join(b.dir, id.String(), "task.json")
+	raw := []byte(`{"id":"` + id.String() + `","schema_version":1,"prompt":"old","status":"backlog","timeout":60}`)
+	if err := os.WriteFile(taskPath, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := b.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].SchemaVersion != constants.CurrentTaskSchemaVersion {
+		t.Fatalf("expected one task at the current schema version, got %+v", tasks)
+	}
+
+	backup, err := os.ReadFile(taskPath + ".v1.bak")
+	if err != nil {
+		t.Fatalf("expected pre-migration backup: %v", err)
+	}
+	if string(backup) != string(raw) {
+		t.Errorf("backup = %s, want original %s", backup, raw)
+	}
+	persisted, err := os.ReadFile(taskPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rawSchemaVersion(persisted) != constants.CurrentTaskSchemaVersion {
+		t.Errorf("persisted schema_version = %d, want %d", rawSchemaVersion(persisted), constants.CurrentTaskSchemaVersion)
+	}
+}
+
+func TestFilesystemBackend_LoadAll_NoBackupForCurrentVersion(t *testing.T) {
+	b := newTestBackend(t)
+	id := uuid.New()
+	if err := b.Init(id); err != nil {
+		t.Fatal(err)
+	}
+	// Current version but missing timeout: rewritten, not backed up.
+	taskPath := filepath.Join(b.dir, id.String(), "task.json")
+	raw := fmt.Appendf(nil, `{"id":%q,"schema_version":%d,"prompt":"p","status":"backlog"}`,
+		id.String(), constants.CurrentTaskSchemaVersion)
+	if err := os.WriteFile(taskPath, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.LoadAll(); err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	matches, _ := filepath.Glob(taskPath + ".v*.bak")
+	if len(matches) != 0 {
+		t.Errorf("expected no backup for a current-version task, got %v", matches)
+	}
+}
+
+func TestBackupTaskJSON_KeepsExistingBackup(t *testing.T) {
+	dir := t.TempDir()
+	taskPath := filepath.Join(dir, "task.json")
+	if err := backupTaskJSON(taskPath, []byte("first"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupTaskJSON(taskPath, []byte("second"), 1); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(taskPath + ".v1.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "first" {
+		t.Errorf("backup = %q, want the original %q", got, "first")
+	}
+}
+
 func TestFilesystemBackend_RemoveTask(t *testing.T) {
 	b := newTestBackend(t)
 	id := uuid.New()
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+	"latere.ai/x/wallfacer/internal/pkg/commitstyle"
+)
+
+// commitStylesFile is the workspace-level file, directly under the store's
+// data directory, that caches the commit-style profile of each repository.
+const commitStylesFile = "commit-styles.json"
+
+// CommitStyle returns the cached commit-style profile of the repository at
+// repoPath and whether one was cached. The caller decides whether it is
+// still fresh from its RefreshedAt.
+func (s *Store) CommitStyle(repoPath string) (commitstyle.Profile, bool) {
+	s.commitStylesMu.Lock()
+	defer s.commitStylesMu.Unlock()
+	s.loadCommitStylesLocked()
+	p, ok := s.commitStyles[repoPath]
+	return p, ok
+}
+
+// SaveCommitStyle caches the commit-style profile of the repository at
+// repoPath. Stores without a data directory keep it in memory only.
+func (s *Store) SaveCommitStyle(repoPath string, p commitstyle.Profile) error {
+	s.commitStylesMu.Lock()
+	defer s.commitStylesMu.Unlock()
+	s.loadCommitStylesLocked()
+	s.commitStyles[repoPath] = p
+	if s.dir == "" {
+		return nil
+	}
+	return atomicfile.WriteJSON(filepath.Join(s.dir, commitStylesFile), s.commitStyles, 0644)
+}
+
+// loadCommitStylesLocked reads the cached profiles on first use. A missing
+// or unreadable file starts an empty cache; profiles are rebuilt from git
+// history on demand. Must be called with s.commitStylesMu held.
+func (s *Store) loadCommitStylesLocked() {
+	if s.commitStyles != nil {
+		return
+	}
+	s.commitStyles = make(map[string]commitstyle.Profile)
+	if s.dir == "" {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, commitStylesFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Store.Warn("read commit styles", "error", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &s.commitStyles); err != nil {
+		logger.Store.Warn("parse commit styles", "error", err)
+		s.commitStyles = make(map[string]commitstyle.Profile)
+	}
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/pkg/commitstyle"
+)
+
+func TestCommitStyle_PersistsAcrossReload(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.CommitStyle("/repo"); ok {
+		t.Fatal("empty store has a cached commit style")
+	}
+	p := commitstyle.Analyze([]string{"feat(api): add x", "fix(api): y"})
+	p.RefreshedAt = time.Now().UTC().Truncate(time.Second)
+	if err := s.SaveCommitStyle("/repo", p); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := reloaded.CommitStyle("/repo")
+	if !ok || !got.Conventional || !got.RefreshedAt.Equal(p.RefreshedAt) || len(got.Examples) != 2 {
+		t.Fatalf("reloaded style = %+v, %v; want %+v", got, ok, p)
+	}
+	if len(reloaded.tasks) != 0 {
+		t.Errorf("commit style file loaded as %d tasks", len(reloaded.tasks))
+	}
+}
+
+func TestCommitStyle_CorruptFileStartsEmpty(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, commitStylesFile), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.CommitStyle("/repo"); ok {
+		t.Fatal("corrupt file produced a cached commit style")
+	}
+	if err := s.SaveCommitStyle("/repo", commitstyle.Profile{Sampled: 1}); err != nil {
+		t.Fatalf("SaveCommitStyle after corrupt file: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// MaxContextFiles bounds the files and directories one task's context pack
+// may name.
+const MaxContextFiles = 50
+
+// NormalizeContextFiles validates the paths of a task's context pack and
+// returns them cleaned, with duplicates dropped. Every path must be
+// absolute; whether it lies inside a configured workspace is checked by
+// the caller.
+func NormalizeContextFiles(paths []string) ([]string, error) {
+	if len(paths) > MaxContextFiles {
+		return nil, fmt.Errorf("at most %d context files (got %d)", MaxContextFiles, len(paths))
+	}
+	out := make([]string, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for i, p := range paths {
+		p = strings.TrimSpace(p)
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("context file %d: path must be absolute (got %q)", i, p)
+		}
+		p = filepath.Clean(p)
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		out = append(out, p)
+	}
+	return out, nil
+}
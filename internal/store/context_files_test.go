@@ -0,0 +1,30 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeContextFiles(t *testing.T) {
+	got, err := NormalizeContextFiles([]string{" /code/app/main.go ", "/code/app/internal/", "/code/app/./main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/code/app/main.go", "/code/app/internal"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	tooMany := make([]string, MaxContextFiles+1)
+	for i := range tooMany {
+		tooMany[i] = "/code/app/main.go"
+	}
+	for name, paths := range map[string][]string{
+		"relative path": {"main.go"},
+		"empty path":    {"  "},
+		"too many":      tooMany,
+	} {
+		if _, err := NormalizeContextFiles(paths); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -1291,7 +1291,7 @@ func TestGetEventsPage_WithTypeFilter(t *testing.T) {
 	s.InsertEvent(bg(), task.ID, EventTypeError, json.RawMessage(`{"error":"something"}`))                       //nolint:errcheck
 
 	typeSet := map[EventType]struct{}{EventTypeOutput: {}}
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 10, typeSet)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 10, typeSet, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -1314,7 +1314,7 @@ func TestGetEventsPage_LazyLoadFromTerminalState(t *testing.T) {
 	s.eventsLoaded[task.ID] = false
 	s.mu.Unlock()
 
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 10, nil)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 10, nil, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -2072,20 +2072,26 @@ func TestSaveTurnOutput_StderrTruncationCoverage(t *testing.T) {
 func TestTruncateTurnData_NoNewlineCoverage(t *testing.T) {
 	s := newTestStore(t)
 	s.maxTurnOutputBytes = 10
-	result, originalLen := s.truncateTurnData([]byte("abcdefghijklmnop"))
-	if originalLen != 16 {
-		t.Errorf("originalLen = %d, want 16", originalLen)
+	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "cut", Timeout: 5})
+	if err := s.SaveTurnOutput(task.ID, 1, []byte("abcdefghijklmnop"), nil); err != nil {
+		t.Fatalf("SaveTurnOutput: %v", err)
 	}
-	if len(result) == 0 {
-		t.Error("expected non-empty result")
+	result, _ := s.backend.ReadBlob(task.ID, "outputs/turn-0001.json")
+	want := "abcdefghij\n" + `{"type":"system","subtype":"truncation_notice","total_bytes":16,"truncated_at":10}` + "\n"
+	if string(result) != want {
+		t.Errorf("result = %q, want %q", result, want)
 	}
 }
 
 func TestTruncateTurnData_DisabledWhenZeroCoverage(t *testing.T) {
 	s := newTestStore(t)
 	s.maxTurnOutputBytes = 0
-	result, originalLen := s.truncateTurnData([]byte("some data"))
-	if originalLen != 0 || string(result) != "some data" {
+	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "keep", Timeout: 5})
+	if err := s.SaveTurnOutput(task.ID, 1, []byte("some data"), nil); err != nil {
+		t.Fatalf("SaveTurnOutput: %v", err)
+	}
+	result, _ := s.backend.ReadBlob(task.ID, "outputs/turn-0001.json")
+	if string(result) != "some data" {
 		t.Error("expected no truncation when disabled")
 	}
 }
@@ -217,3 +217,27 @@ func TestUpdateTaskDependsOn_ClearsToNil(t *testing.T) {
 		t.Error("expected depends_on absent from JSON after clear (omitempty), but key was found")
 	}
 }
+
+// TestUnmetDependencies verifies that only the dependencies not yet done are
+// returned, in DependsOn order, with malformed entries counted as unmet.
+func TestUnmetDependencies(t *testing.T) {
+	s := newTestStore(t)
+	a, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "a", Timeout: 15})
+	b, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "b", Timeout: 15})
+	c, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{
+		Prompt: "c", Timeout: 15,
+		DependsOn: []string{a.ID.String(), b.ID.String(), "not-a-uuid"},
+	})
+	_ = s.ForceUpdateTaskStatus(bg(), a.ID, TaskStatusDone)
+
+	unmet, err := s.UnmetDependencies(bg(), c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unmet) != 2 || unmet[0] != b.ID.String() || unmet[1] != "not-a-uuid" {
+		t.Errorf("UnmetDependencies = %v, want [%s not-a-uuid]", unmet, b.ID)
+	}
+	if unmet, _ := s.UnmetDependencies(bg(), a.ID); len(unmet) != 0 {
+		t.Errorf("task without dependencies: unmet = %v", unmet)
+	}
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+// diffSnapshotsBlob is the per-task blob holding rendered diffs, keyed by
+// repository path.
+const diffSnapshotsBlob = "diff-snapshots.json"
+
+// DiffSnapshot is the rendered `git diff Base Head` of one repository. Once a
+// task is merged its worktrees are gone and the diff is rebuilt from the
+// commit hashes recorded at merge, which is slow on very large repositories;
+// a snapshot lets the review view serve it without running git again.
+type DiffSnapshot struct {
+	Base string `json:"base"`
+	Head string `json:"head"`
+	Diff string `json:"diff"`
+}
+
+// DiffSnapshot returns the cached diff of repoPath between base and head for
+// a task. A snapshot rendered for a different commit pair is treated as a
+// miss, so stale entries never need explicit invalidation when the task's
+// commits change.
+func (s *Store) DiffSnapshot(taskID uuid.UUID, repoPath, base, head string) (string, bool) {
+	s.diffSnapshotsMu.Lock()
+	defer s.diffSnapshotsMu.Unlock()
+	snap, ok := s.readDiffSnapshotsLocked(taskID)[repoPath]
+	if !ok || snap.Base != base || snap.Head != head {
+		return "", false
+	}
+	return snap.Diff, true
+}
+
+// SaveDiffSnapshot records the rendered diff of repoPath for a task,
+// replacing any snapshot of the same repository.
+func (s *Store) SaveDiffSnapshot(taskID uuid.UUID, repoPath string, snap DiffSnapshot) error {
+	s.diffSnapshotsMu.Lock()
+	defer s.diffSnapshotsMu.Unlock()
+	snaps := s.readDiffSnapshotsLocked(taskID)
+	if snaps == nil {
+		snaps = make(map[string]DiffSnapshot)
+	}
+	snaps[repoPath] = snap
+	data, err := json.Marshal(snaps)
+	if err != nil {
+		return err
+	}
+	return s.backend.SaveBlob(taskID, diffSnapshotsBlob, data)
+}
+
+// DeleteDiffSnapshots drops every diff snapshot of a task. It is a no-op when
+// none were saved.
+func (s *Store) DeleteDiffSnapshots(taskID uuid.UUID) error {
+	s.diffSnapshotsMu.Lock()
+	defer s.diffSnapshotsMu.Unlock()
+	if err := s.backend.DeleteBlob(taskID, diffSnapshotsBlob); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// readDiffSnapshotsLocked loads a task's snapshots. A missing or unreadable
+// blob yields nil; the diff is then rendered from git as before. Must be
+// called with s.diffSnapshotsMu held.
+func (s *Store) readDiffSnapshotsLocked(taskID uuid.UUID) map[string]DiffSnapshot {
+	data, err := s.backend.ReadBlob(taskID, diffSnapshotsBlob)
+	if err != nil {
+		return nil
+	}
+	var snaps map[string]DiffSnapshot
+	if json.Unmarshal(data, &snaps) != nil {
+		return nil
+	}
+	return snaps
+}
@@ -0,0 +1,50 @@
+package store
+
+import "testing"
+
+// TestDiffSnapshot verifies that a snapshot is served only for the commit
+// pair it was rendered from, survives a restart, and is dropped on retry.
+func TestDiffSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.DiffSnapshot(task.ID, "/repo/a", "b1", "h1"); ok {
+		t.Fatal("snapshot reported before one was saved")
+	}
+	for repo, diff := range map[string]string{"/repo/a": "diff a", "/repo/b": "diff b"} {
+		if err := s.SaveDiffSnapshot(task.ID, repo, DiffSnapshot{Base: "b1", Head: "h1", Diff: diff}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff, ok := s.DiffSnapshot(task.ID, "/repo/a", "b1", "h1"); !ok || diff != "diff a" {
+		t.Errorf("DiffSnapshot = %q, %v; want diff a", diff, ok)
+	}
+	if _, ok := s.DiffSnapshot(task.ID, "/repo/a", "b1", "h2"); ok {
+		t.Error("snapshot served for a different head")
+	}
+
+	s.Close()
+	reopened, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff, ok := reopened.DiffSnapshot(task.ID, "/repo/b", "b1", "h1"); !ok || diff != "diff b" {
+		t.Errorf("after restart DiffSnapshot = %q, %v; want diff b", diff, ok)
+	}
+
+	if err := reopened.ResetTaskForRetry(bg(), task.ID, "p", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.DiffSnapshot(task.ID, "/repo/a", "b1", "h1"); ok {
+		t.Error("snapshot kept after retry")
+	}
+	if err := reopened.DeleteDiffSnapshots(task.ID); err != nil {
+		t.Errorf("DeleteDiffSnapshots without snapshots: %v", err)
+	}
+}
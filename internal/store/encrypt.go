@@ -0,0 +1,90 @@
+package store
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
+)
+
+// plaintextTaskFiles are task-directory files that stay in plaintext even
+// when the store encrypts: they are appended a line at a time, which a
+// sealed file cannot support, and hold only token counts.
+var plaintextTaskFiles = map[string]bool{
+	"turn-usage.jsonl": true,
+}
+
+// MigrateEncryption rewrites every task directory under dir (a workspace
+// data directory or the root holding several) so its files match the target
+// state: encrypt seals plaintext files with c, and !encrypt opens sealed
+// files with c and writes them back in plaintext. Files already in the
+// target state are left alone, so an interrupted run can be repeated.
+// Returns the number of files rewritten. The server must not be running
+// against dir while it migrates.
+func MigrateEncryption(dir string, c *atrest.Cipher, encrypt bool) (int, error) {
+	if c == nil {
+		return 0, errors.New("migrate encryption: no key configured (set " + atrest.KeyEnv + ")")
+	}
+	var n int
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || !isTaskDir(path) {
+			return nil
+		}
+		changed, err := migrateTaskDir(path, c, encrypt)
+		n += changed
+		if err != nil {
+			return err
+		}
+		return filepath.SkipDir
+	})
+	return n, err
+}
+
+// isTaskDir reports whether path is a task directory: named by a UUID and
+// holding a task.json.
+func isTaskDir(path string) bool {
+	if _, err := uuid.Parse(filepath.Base(path)); err != nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(path, "task.json"))
+	return err == nil
+}
+
+// migrateTaskDir converts the files of one task directory.
+func migrateTaskDir(taskDir string, c *atrest.Cipher, encrypt bool) (int, error) {
+	var n int
+	err := filepath.WalkDir(taskDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !d.Type().IsRegular() || plaintextTaskFiles[d.Name()] {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if atrest.IsSealed(data) == encrypt {
+			return nil
+		}
+		var out []byte
+		if encrypt {
+			out, err = c.Seal(data)
+		} else {
+			out, err = c.Open(data)
+		}
+		if err != nil {
+			return &fs.PathError{Op: "migrate", Path: path, Err: err}
+		}
+		if err := atomicfile.Write(path, out, 0644); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
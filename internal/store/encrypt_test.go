@@ -0,0 +1,220 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/constants"
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
+)
+
+// testCipher returns a Cipher with a fixed all-ones key.
+func testCipher(t *testing.T) *atrest.Cipher {
+	t.Helper()
+	c, err := atrest.New(bytes.Repeat([]byte{1}, atrest.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// seedTask writes a task with the given prompt, three trace events (the
+// first two compacted), a blob, and a turn-usage line through b.
+func seedTask(t *testing.T, b *FilesystemBackend, prompt string) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	if err := b.Init(id); err != nil {
+		t.Fatal(err)
+	}
+	task := &Task{
+		ID:            id,
+		Prompt:        prompt,
+		Status:        TaskStatusBacklog,
+		SchemaVersion: constants.CurrentTaskSchemaVersion,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := b.SaveTask(task); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(map[string]string{"result": prompt})
+	var events []TaskEvent
+	for i := 1; i <= 3; i++ {
+		evt := TaskEvent{ID: int64(i), TaskID: id, EventType: EventTypeOutput, Data: data, CreatedAt: time.Now()}
+		if err := b.SaveEvent(id, i, evt); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, evt)
+	}
+	if err := b.CompactEvents(id, events[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SaveBlob(id, "outputs/turn-0001.json", []byte(prompt)); err != nil {
+		t.Fatal(err)
+	}
+	usage := filepath.Join(b.dir, id.String(), "turn-usage.jsonl")
+	if err := os.WriteFile(usage, []byte(`{"turn":1}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// taskFiles returns the contents of every regular file in a task directory,
+// keyed by path relative to it.
+func taskFiles(t *testing.T, b *FilesystemBackend, id uuid.UUID) map[string][]byte {
+	t.Helper()
+	root := filepath.Join(b.dir, id.String())
+	files := map[string][]byte{}
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		rel, _ := filepath.Rel(root, path)
+		files[rel] = data
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestFilesystemBackend_EncryptedRoundTrip(t *testing.T) {
+	b := newTestBackend(t)
+	b.cipher = testCipher(t)
+	const secret = "fix the payroll export bug"
+	id := seedTask(t, b, secret)
+
+	for name, data := range taskFiles(t, b, id) {
+		if name == "turn-usage.jsonl" {
+			continue
+		}
+		if !atrest.IsSealed(data) {
+			t.Errorf("%s is not sealed", name)
+		}
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("%s contains the plaintext prompt", name)
+		}
+	}
+
+	tasks, err := b.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Prompt != secret {
+		t.Fatalf("LoadAll = %+v, want one task with the prompt", tasks)
+	}
+	events, maxSeq, err := b.LoadEvents(id)
+	if err != nil {
+		t.Fatalf("LoadEvents: %v", err)
+	}
+	if len(events) != 3 || maxSeq != 3 {
+		t.Errorf("LoadEvents = %d events, maxSeq %d; want 3, 3", len(events), maxSeq)
+	}
+	blob, err := b.ReadBlob(id, "outputs/turn-0001.json")
+	if err != nil || string(blob) != secret {
+		t.Errorf("ReadBlob = %q, %v; want %q", blob, err, secret)
+	}
+}
+
+func TestFilesystemBackend_LoadAll_SealedWithoutKey(t *testing.T) {
+	b := newTestBackend(t)
+	b.cipher = testCipher(t)
+	seedTask(t, b, "secret")
+
+	b.cipher = nil
+	if _, err := b.LoadAll(); !errors.Is(err, atrest.ErrNoKey) {
+		t.Fatalf("LoadAll without key: err = %v, want ErrNoKey", err)
+	}
+}
+
+func TestFilesystemBackend_EncryptedReadsPlaintext(t *testing.T) {
+	b := newTestBackend(t)
+	id := seedTask(t, b, "written before encryption")
+
+	b.cipher = testCipher(t)
+	tasks, err := b.LoadAll()
+	if err != nil || len(tasks) != 1 || tasks[0].Prompt != "written before encryption" {
+		t.Fatalf("LoadAll = %+v, %v", tasks, err)
+	}
+	if events, _, err := b.LoadEvents(id); err != nil || len(events) != 3 {
+		t.Errorf("LoadEvents = %d events, %v; want 3", len(events), err)
+	}
+}
+
+func TestMigrateEncryption(t *testing.T) {
+	b := newTestBackend(t)
+	const secret = "migrate me"
+	id := seedTask(t, b, secret)
+	plain := taskFiles(t, b, id)
+	c := testCipher(t)
+
+	n, err := MigrateEncryption(b.dir, c, true)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	// task.json, trace 0003, compact.ndjson, and the blob.
+	if n != 4 {
+		t.Errorf("encrypt rewrote %d files, want 4", n)
+	}
+	for name, data := range taskFiles(t, b, id) {
+		if sealed := atrest.IsSealed(data); sealed != (name != "turn-usage.jsonl") {
+			t.Errorf("%s: sealed = %v after encrypt", name, sealed)
+		}
+	}
+
+	// A second run finds nothing left to do.
+	if n, err := MigrateEncryption(b.dir, c, true); err != nil || n != 0 {
+		t.Errorf("re-encrypt = %d, %v; want 0, nil", n, err)
+	}
+
+	b.cipher = c
+	tasks, err := b.LoadAll()
+	if err != nil || len(tasks) != 1 || tasks[0].Prompt != secret {
+		t.Fatalf("LoadAll after encrypt = %+v, %v", tasks, err)
+	}
+
+	if _, err := MigrateEncryption(b.dir, c, false); err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	for name, data := range taskFiles(t, b, id) {
+		// LoadAll may have rewritten task.json; the rest is untouched.
+		if name == "task.json" {
+			if atrest.IsSealed(data) || !bytes.Contains(data, []byte(secret)) {
+				t.Errorf("task.json not restored to plaintext: %q", data)
+			}
+			continue
+		}
+		if !bytes.Equal(data, plain[name]) {
+			t.Errorf("%s differs after decrypt round trip", name)
+		}
+	}
+}
+
+func TestMigrateEncryption_NoKey(t *testing.T) {
+	if _, err := MigrateEncryption(t.TempDir(), nil, true); err == nil {
+		t.Fatal("expected an error without a key")
+	}
+}
+
+func TestMigrateEncryption_WrongKey(t *testing.T) {
+	b := newTestBackend(t)
+	seedTask(t, b, "secret")
+	if _, err := MigrateEncryption(b.dir, testCipher(t), true); err != nil {
+		t.Fatal(err)
+	}
+	other, err := atrest.New(bytes.Repeat([]byte{2}, atrest.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateEncryption(b.dir, other, false); err == nil {
+		t.Fatal("decrypt with the wrong key succeeded")
+	}
+}
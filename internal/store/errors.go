@@ -0,0 +1,30 @@
+package store
+
+import (
+	"errors"
+
+	"latere.ai/x/wallfacer/internal/pkg/statemachine"
+)
+
+// ErrTaskNotFound is returned, wrapped with the task's ID, by every Store
+// method that looks up a task that does not exist (or, for the trash
+// methods, is not soft-deleted). Callers test for it with errors.Is.
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrInvalidTransition is returned by the status-changing methods when the
+// task state machine does not allow the requested transition. It is
+// statemachine.ErrInvalidTransition, re-exported so callers need not import
+// the state machine package to test for it.
+var ErrInvalidTransition = statemachine.ErrInvalidTransition
+
+// ErrApprovalNotFound is returned by DecideApproval for a request number the
+// task does not have.
+var ErrApprovalNotFound = errors.New("approval request not found")
+
+// ErrApprovalDecided is returned by DecideApproval for a request that was
+// already approved or denied.
+var ErrApprovalDecided = errors.New("approval request already decided")
+
+// ErrDependenciesUnmet marks a refused start of a task whose dependencies
+// are not all done. Callers wrap it with the IDs UnmetDependencies returns.
+var ErrDependenciesUnmet = errors.New("dependencies not done")
@@ -1,12 +1,14 @@
 package store
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,23 +21,31 @@ import (
 // consumers (the task event trace, the audit-log spec) can attribute
 // mutations. An empty context produces an event with both fields
 // empty, which matches pre-Phase-2 records.
+//
+// Inserts into one task are serialized by that task's event lock, which is
+// held across the trace-file write; s.mu is only held to read the next
+// sequence number and to append the saved event, so readers and inserts
+// into other tasks never wait on the disk.
 func (s *Store) InsertEvent(ctx context.Context, taskID uuid.UUID, eventType EventType, data any) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	mu := s.eventLock(taskID)
+	mu.Lock()
+	defer mu.Unlock()
 
+	s.mu.Lock()
 	if _, ok := s.tasks[taskID]; !ok {
-		return fmt.Errorf("task not found: %s", taskID)
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
 	}
-
-	actorSub, actorType := actorFromContext(ctx)
-
 	s.ensureEventsLoadedLocked(taskID)
 	seq := s.nextSeq[taskID]
+	s.mu.Unlock()
+
+	actorSub, actorType := actorFromContext(ctx)
 	event := TaskEvent{
 		ID:        int64(seq),
 		TaskID:    taskID,
@@ -50,27 +60,55 @@ func (s *Store) InsertEvent(ctx context.Context, taskID uuid.UUID, eventType Eve
 		return err
 	}
 
-	s.events[taskID] = append(s.events[taskID], event)
-	s.nextSeq[taskID] = seq + 1
+	s.mu.Lock()
+	// The task's events were purged or reloaded from disk while the file
+	// was written: the in-memory trace no longer expects this sequence
+	// number, and a reload has already picked the event up from its file.
+	// Events evicted meanwhile are left to the next reload as well.
+	if s.nextSeq[taskID] == seq {
+		if s.eventsLoaded[taskID] {
+			s.events[taskID] = append(s.events[taskID], event)
+		}
+		s.nextSeq[taskID] = seq + 1
+	}
+	s.mu.Unlock()
+
+	// The task's event lock is still held, so the sink sees each task's
+	// events in sequence order.
+	if sink := s.eventSink.Load(); sink != nil {
+		(*sink)(event)
+	}
+	s.eventHub.Publish(event)
 	return nil
 }
 
+// SetEventSink registers fn to receive every event after InsertEvent has
+// persisted it, e.g. to stream an audit log. fn runs synchronously on the
+// inserting goroutine, so it must be quick and must not call back into the
+// store. A nil fn removes the sink.
+func (s *Store) SetEventSink(fn func(TaskEvent)) {
+	if fn == nil {
+		s.eventSink.Store(nil)
+		return
+	}
+	s.eventSink.Store(&fn)
+}
+
+// eventLock returns the mutex serializing event inserts into one task.
+func (s *Store) eventLock(taskID uuid.UUID) *sync.Mutex {
+	mu, _ := s.eventLocks.LoadOrStore(taskID, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
 // GetEvents returns a copy of all events for a task in order.
-// If events have not been loaded yet (lazy loading for terminal tasks),
-// this method upgrades from a read lock to a write lock, loads the events,
-// then downgrades back to a read lock. The brief window between RUnlock
-// and Lock where s.mu is unheld is safe because ensureEventsLoadedLocked
-// is idempotent — a concurrent goroutine loading the same events is harmless.
+// If events are not in memory (never loaded, or evicted from the event
+// cache), this method upgrades from a read lock to a write lock, loads the
+// events, then downgrades back to a read lock. The brief window between
+// RUnlock and Lock where s.mu is unheld is safe because
+// ensureEventsLoadedLocked is idempotent — a concurrent goroutine loading
+// the same events is harmless.
 func (s *Store) GetEvents(_ context.Context, taskID uuid.UUID) ([]TaskEvent, error) {
-	s.mu.RLock()
-	if !s.eventsLoaded[taskID] {
-		// Events not yet loaded — release read lock, acquire write lock to load.
-		s.mu.RUnlock()
-		s.mu.Lock()
-		s.ensureEventsLoadedLocked(taskID)
-		s.mu.Unlock()
-		s.mu.RLock()
-	}
+	s.rlockEventsLoaded(taskID)
 	defer s.mu.RUnlock()
 
 	events := s.events[taskID]
@@ -96,17 +134,19 @@ type EventsPage struct {
 //
 // typeSet restricts results to the given event types. A nil or empty map means
 // all event types are included.
-func (s *Store) GetEventsPage(_ context.Context, taskID uuid.UUID, afterID int64, limit int, typeSet map[EventType]struct{}) (EventsPage, error) {
-	s.mu.RLock()
-	if !s.eventsLoaded[taskID] {
-		s.mu.RUnlock()
-		s.mu.Lock()
-		s.ensureEventsLoadedLocked(taskID)
-		s.mu.Unlock()
-		s.mu.RLock()
-	}
+//
+// coalesce folds runs of identical noisy events (see CoalesceEvents) before
+// filtering and paginating, so a run is never split across pages; the cursor
+// is the ID of a run's last event.
+func (s *Store) GetEventsPage(_ context.Context, taskID uuid.UUID, afterID int64, limit int, typeSet map[EventType]struct{}, coalesce bool) (EventsPage, error) {
+	s.rlockEventsLoaded(taskID)
 	defer s.mu.RUnlock()
 
+	events := s.events[taskID]
+	if coalesce {
+		events = CoalesceEvents(events, EventCoalesceWindow)
+	}
+
 	var filter func(TaskEvent) bool
 	if len(typeSet) > 0 {
 		filter = func(ev TaskEvent) bool {
@@ -118,7 +158,7 @@ func (s *Store) GetEventsPage(_ context.Context, taskID uuid.UUID, afterID int64
 	// Paginate using event ID as the cursor key.
 	// Default page size is 200, hard max is 1000.
 	p := pagination.Paginate(
-		s.events[taskID],
+		events,
 		func(ev TaskEvent) int64 { return ev.ID },
 		afterID, limit, 200, 1000,
 		filter,
@@ -132,6 +172,63 @@ func (s *Store) GetEventsPage(_ context.Context, taskID uuid.UUID, afterID int64
 	}, nil
 }
 
+// EventCoalesceWindow is the largest gap between two identical events that
+// CoalesceEvents folds into one.
+const EventCoalesceWindow = 10 * time.Second
+
+// coalescedEventTypes are the event types CoalesceEvents folds. The others
+// (state changes, spans, feedback, comments, approvals) mark distinct steps
+// even when their payloads repeat.
+var coalescedEventTypes = map[EventType]bool{
+	EventTypeOutput: true,
+	EventTypeSystem: true,
+	EventTypeError:  true,
+}
+
+// CoalesceEvents folds each run of consecutive output, system, or error
+// events that share a type, actor, and payload, and that each follow the
+// previous by at most window, into one event: the run's last, with Count and
+// FirstID recording the run. Other events are returned unchanged. events is
+// not modified; the result is events itself when nothing was folded.
+//
+// Folding is a read-time view: InsertEvent stores every repeat and hands it
+// to the event sink and subscribers as inserted.
+func CoalesceEvents(events []TaskEvent, window time.Duration) []TaskEvent {
+	var out []TaskEvent // nil until the first fold
+	for i, ev := range events {
+		if i == 0 || !repeatsEvent(events[i-1], ev, window) {
+			if out != nil {
+				out = append(out, ev)
+			}
+			continue
+		}
+		if out == nil {
+			out = slices.Clone(events[:i])
+		}
+		last := &out[len(out)-1]
+		count, first := max(last.Count, 1), last.FirstID
+		if first == 0 {
+			first = last.ID
+		}
+		*last = ev
+		last.Count, last.FirstID = count+1, first
+	}
+	if out == nil {
+		return events
+	}
+	return out
+}
+
+// repeatsEvent reports whether ev repeats prev closely enough to be folded
+// into it by CoalesceEvents.
+func repeatsEvent(prev, ev TaskEvent, window time.Duration) bool {
+	return coalescedEventTypes[ev.EventType] &&
+		prev.EventType == ev.EventType &&
+		prev.ActorSub == ev.ActorSub && prev.ActorType == ev.ActorType &&
+		ev.CreatedAt.Sub(prev.CreatedAt) <= window &&
+		bytes.Equal(prev.Data, ev.Data)
+}
+
 // SpanResult holds the paired timing data for a single execution span.
 // EndedAt is zero and DurationMS is 0 for unclosed spans (no matching span_end).
 type SpanResult struct {
@@ -231,8 +328,10 @@ func parseNumberedTraceFile(name string) (numberedTraceFile, bool) {
 func (s *Store) compactTaskEvents(taskID uuid.UUID, maxSeq int64) error {
 	// Read events from memory. This is called from a background goroutine
 	// after the lock has been released, so we need to acquire a read lock.
+	// The event cache may have dropped the list since; read the trace files
+	// then.
 	s.mu.RLock()
-	allEvents := s.events[taskID]
+	allEvents, loaded := s.events[taskID], s.eventsLoaded[taskID]
 	var eventsToCompact []TaskEvent
 	for _, evt := range allEvents {
 		if evt.ID <= maxSeq {
@@ -240,6 +339,17 @@ func (s *Store) compactTaskEvents(taskID uuid.UUID, maxSeq int64) error {
 		}
 	}
 	s.mu.RUnlock()
+	if !loaded {
+		events, _, err := s.backend.LoadEvents(taskID)
+		if err != nil {
+			return err
+		}
+		for _, evt := range events {
+			if evt.ID <= maxSeq {
+				eventsToCompact = append(eventsToCompact, evt)
+			}
+		}
+	}
 
 	if len(eventsToCompact) == 0 {
 		return nil
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// eventCache tracks which tasks have their event lists in memory, least
+// recently used first, so the store can drop the oldest once more than limit
+// are loaded. Events are written to their trace file before they are added
+// to memory, so a dropped list is reloaded intact on next access.
+//
+// The cache has its own mutex because reads touch it under s.mu.RLock.
+type eventCache struct {
+	mu    sync.Mutex
+	limit int // 0 ⇒ unlimited
+	order *list.List
+	elems map[uuid.UUID]*list.Element
+}
+
+func newEventCache(limit int) *eventCache {
+	return &eventCache{
+		limit: max(limit, 0),
+		order: list.New(),
+		elems: make(map[uuid.UUID]*list.Element),
+	}
+}
+
+// touch marks id as the most recently used loaded task.
+func (c *eventCache) touch(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.elems[id]; ok {
+		c.order.MoveToBack(e)
+		return
+	}
+	c.elems[id] = c.order.PushBack(id)
+}
+
+// remove forgets id.
+func (c *eventCache) remove(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.elems[id]; ok {
+		c.order.Remove(e)
+		delete(c.elems, id)
+	}
+}
+
+// overflow removes and returns the least recently used tasks beyond the
+// limit, never keep.
+func (c *eventCache) overflow(keep uuid.UUID) []uuid.UUID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.limit == 0 {
+		return nil
+	}
+	var out []uuid.UUID
+	for e := c.order.Front(); e != nil && c.order.Len() > c.limit; {
+		next := e.Next()
+		if id := e.Value.(uuid.UUID); id != keep {
+			c.order.Remove(e)
+			delete(c.elems, id)
+			out = append(out, id)
+		}
+		e = next
+	}
+	return out
+}
+
+// len returns the number of tracked tasks.
+func (c *eventCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// evictEventsLocked drops a task's in-memory events; the next access reloads
+// them from its trace files. nextSeq is kept, so a terminal transition still
+// knows which events to compact, and an InsertEvent writing to the task at
+// the same time advances it but leaves its event to that reload. Must be
+// called while s.mu is held for writing.
+func (s *Store) evictEventsLocked(id uuid.UUID) {
+	delete(s.events, id)
+	s.eventsLoaded[id] = false
+}
@@ -9,6 +9,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"sync"
 	"testing"
@@ -176,6 +177,34 @@ func TestInsertEvent_NotFound(t *testing.T) {
 	}
 }
 
+func TestInsertEvent_EventSink(t *testing.T) {
+	s := newTestStore(t)
+	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	var got []TaskEvent
+	s.SetEventSink(func(ev TaskEvent) { got = append(got, ev) })
+
+	ctx := WithActorPrincipal(bg(), "user-1", ActorUser)
+	if err := s.InsertEvent(ctx, task.ID, EventTypeFeedback, map[string]string{"message": "ship it"}); err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+	if err := s.InsertEvent(bg(), uuid.New(), EventTypeSystem, nil); err == nil {
+		t.Fatal("expected error for unknown task")
+	}
+	events, _ := s.GetEvents(bg(), task.ID)
+	if len(got) != 1 || len(events) != 1 {
+		t.Fatalf("sink got %d events, store has %d; want 1 each", len(got), len(events))
+	}
+	if got[0].ID != events[0].ID || got[0].ActorSub != "user-1" || got[0].EventType != EventTypeFeedback {
+		t.Errorf("sink event = %+v, want %+v", got[0], events[0])
+	}
+
+	s.SetEventSink(nil)
+	_ = s.InsertEvent(bg(), task.ID, EventTypeSystem, nil)
+	if len(got) != 1 {
+		t.Errorf("removed sink still called: %d events", len(got))
+	}
+}
+
 func TestInsertEvent_PersistsAndReloads(t *testing.T) {
 	dir := t.TempDir()
 	s, _ := newTestFileStore(t, dir)
@@ -286,6 +315,81 @@ func TestConcurrentInsertEvent(t *testing.T) {
 	if len(events) != n {
 		t.Errorf("expected %d events, got %d", n, len(events))
 	}
+	for i, ev := range events {
+		if ev.ID != int64(i+1) {
+			t.Fatalf("events[%d].ID = %d, want contiguous IDs in order", i, ev.ID)
+		}
+	}
+}
+
+// slowEventBackend holds SaveEvent open until the test releases it.
+type slowEventBackend struct {
+	StorageBackend
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *slowEventBackend) SaveEvent(taskID uuid.UUID, seq int, event TaskEvent) error {
+	b.started <- struct{}{}
+	<-b.release
+	return b.StorageBackend.SaveEvent(taskID, seq, event)
+}
+
+// TestInsertEvent_TraceWriteDoesNotBlockReaders verifies the trace-file
+// write happens outside the store-wide lock: reads and inserts into other
+// tasks proceed while one task's write is stalled.
+func TestInsertEvent_TraceWriteDoesNotBlockReaders(t *testing.T) {
+	fsb, err := NewFilesystemBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb := &slowEventBackend{StorageBackend: fsb, started: make(chan struct{}, 2), release: make(chan struct{})}
+	s, err := newTestStoreBackend(t, sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Registered after the store's Close so it runs first: a failing run
+	// must not leave Close waiting on the stalled write.
+	var releaseOnce sync.Once
+	release := func() { releaseOnce.Do(func() { close(sb.release) }) }
+	t.Cleanup(release)
+	slow, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "slow", Timeout: 5})
+	other, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "other", Timeout: 5})
+
+	done := make(chan error, 1)
+	go func() { done <- s.InsertEvent(bg(), slow.ID, EventTypeOutput, "stalled") }()
+	<-sb.started
+
+	readDone := make(chan struct{})
+	go func() {
+		_, _ = s.GetTask(bg(), other.ID)
+		_, _ = s.ListTasks(bg(), false)
+		_, _ = s.GetEvents(bg(), slow.ID)
+		close(readDone)
+	}()
+	select {
+	case <-readDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("readers blocked behind a stalled trace write")
+	}
+
+	otherDone := make(chan error, 1)
+	go func() { otherDone <- s.InsertEvent(bg(), other.ID, EventTypeOutput, "free") }()
+	select {
+	case <-sb.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("insert into another task blocked behind a stalled trace write")
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-otherDone; err != nil {
+		t.Fatal(err)
+	}
+	if events, _ := s.GetEvents(bg(), slow.ID); len(events) != 1 || events[0].ID != 1 {
+		t.Errorf("slow task events = %+v, want one event with ID 1", events)
+	}
 }
 
 func TestCompactTaskEvents_Basic(t *testing.T) {
@@ -352,6 +456,30 @@ func TestForceUpdateTaskStatus_CompactsOnTerminal(t *testing.T) {
 	}
 }
 
+// TestForceUpdateTaskStatus_CompactsEvictedTask verifies that a task whose
+// events were dropped from the cache is still compacted when it finishes.
+func TestForceUpdateTaskStatus_CompactsEvictedTask(t *testing.T) {
+	s := newTestStore(t)
+	task, _ := s.CreateTaskWithOptions(context.Background(), TaskCreateOptions{Prompt: "p", Timeout: 5, Kind: TaskKindTask})
+	insertOutputEvents(t, s, task.ID, 3)
+
+	s.mu.Lock()
+	s.evictEventsLocked(task.ID)
+	s.mu.Unlock()
+	if err := s.ForceUpdateTaskStatus(context.Background(), task.ID, TaskStatusDone); err != nil {
+		t.Fatalf("ForceUpdateTaskStatus: %v", err)
+	}
+	s.WaitCompaction()
+
+	if _, err := os.Stat(filepath.Join(s.dir, task.ID.String(), "traces", "compact.ndjson")); err != nil {
+		t.Fatalf("compact.ndjson not written for an evicted task: %v", err)
+	}
+	evts, _ := s.GetEvents(context.Background(), task.ID)
+	if len(evts) != 3 {
+		t.Errorf("events after compaction = %d, want 3", len(evts))
+	}
+}
+
 func TestCompactTaskEvents_LoadEventsAfterCompaction(t *testing.T) {
 	dir := t.TempDir()
 	s, err := newTestFileStore(t, dir)
@@ -472,7 +600,7 @@ func TestGetEventsPage_AllEventsNoFilter(t *testing.T) {
 		_ = s.InsertEvent(bg(), task.ID, EventTypeOutput, i)
 	}
 
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 0, nil)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 0, nil, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -494,7 +622,7 @@ func TestGetEventsPage_OrderedByID(t *testing.T) {
 		_ = s.InsertEvent(bg(), task.ID, EventTypeOutput, i)
 	}
 
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 0, nil)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 0, nil, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -514,7 +642,7 @@ func TestGetEventsPage_CursorAfterExclusive(t *testing.T) {
 	}
 
 	// Get the first 3 events to find the cursor.
-	page1, _ := s.GetEventsPage(bg(), task.ID, 0, 3, nil)
+	page1, _ := s.GetEventsPage(bg(), task.ID, 0, 3, nil, false)
 	if len(page1.Events) != 3 {
 		t.Fatalf("expected 3 events in page1, got %d", len(page1.Events))
 	}
@@ -524,7 +652,7 @@ func TestGetEventsPage_CursorAfterExclusive(t *testing.T) {
 	cursor := page1.NextAfter
 
 	// Use the cursor to get the remaining events.
-	page2, _ := s.GetEventsPage(bg(), task.ID, cursor, 10, nil)
+	page2, _ := s.GetEventsPage(bg(), task.ID, cursor, 10, nil, false)
 	if len(page2.Events) != 2 {
 		t.Errorf("expected 2 events in page2, got %d", len(page2.Events))
 	}
@@ -546,7 +674,7 @@ func TestGetEventsPage_CursorNextAfterIsLastID(t *testing.T) {
 		_ = s.InsertEvent(bg(), task.ID, EventTypeOutput, i)
 	}
 
-	page, _ := s.GetEventsPage(bg(), task.ID, 0, 3, nil)
+	page, _ := s.GetEventsPage(bg(), task.ID, 0, 3, nil, false)
 	want := page.Events[len(page.Events)-1].ID
 	if page.NextAfter != want {
 		t.Errorf("NextAfter = %d, want last event ID %d", page.NextAfter, want)
@@ -557,7 +685,7 @@ func TestGetEventsPage_NextAfterZeroWhenEmpty(t *testing.T) {
 	s := newTestStore(t)
 	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
 
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 10, nil)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 10, nil, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -575,7 +703,7 @@ func TestGetEventsPage_TypeFilter(t *testing.T) {
 	_ = s.InsertEvent(bg(), task.ID, EventTypeOutput, "d")
 
 	typeSet := map[EventType]struct{}{EventTypeOutput: {}}
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 100, typeSet)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 100, typeSet, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -604,7 +732,7 @@ func TestGetEventsPage_MultiTypeFilter(t *testing.T) {
 		EventTypeStateChange: {},
 		EventTypeFeedback:    {},
 	}
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 100, typeSet)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 100, typeSet, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -626,7 +754,7 @@ func TestGetEventsPage_LimitDefault(t *testing.T) {
 	}
 
 	// limit=0 should default to 200, returning all 5.
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 0, nil)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 0, nil, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -643,7 +771,7 @@ func TestGetEventsPage_LimitCappedAt1000(t *testing.T) {
 	}
 
 	// limit=5000 should be capped to 1000, returning all 10 events.
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 5000, nil)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 5000, nil, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -659,7 +787,7 @@ func TestGetEventsPage_LimitTruncatesPage(t *testing.T) {
 		_ = s.InsertEvent(bg(), task.ID, EventTypeOutput, i)
 	}
 
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 4, nil)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 4, nil, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -681,7 +809,7 @@ func TestGetEventsPage_HasMoreFalseWhenExact(t *testing.T) {
 		_ = s.InsertEvent(bg(), task.ID, EventTypeOutput, i)
 	}
 
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 5, nil)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 5, nil, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -700,7 +828,7 @@ func TestGetEventsPage_TypeFilterWithCursor(t *testing.T) {
 
 	// After ID=2, output only → should get IDs 3 and 4.
 	typeSet := map[EventType]struct{}{EventTypeOutput: {}}
-	page, err := s.GetEventsPage(bg(), task.ID, 2, 100, typeSet)
+	page, err := s.GetEventsPage(bg(), task.ID, 2, 100, typeSet, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -721,7 +849,7 @@ func TestGetEventsPage_EmptyTask(t *testing.T) {
 	s := newTestStore(t)
 	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
 
-	page, err := s.GetEventsPage(bg(), task.ID, 0, 10, nil)
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 10, nil, false)
 	if err != nil {
 		t.Fatalf("GetEventsPage: %v", err)
 	}
@@ -978,7 +1106,7 @@ func TestGetEventsPage_FullPaginationWalk(t *testing.T) {
 	var collected []int64
 	var cursor int64
 	for {
-		page, err := s.GetEventsPage(bg(), task.ID, cursor, 3, nil)
+		page, err := s.GetEventsPage(bg(), task.ID, cursor, 3, nil, false)
 		if err != nil {
 			t.Fatalf("GetEventsPage cursor=%d: %v", cursor, err)
 		}
@@ -1002,3 +1130,115 @@ func TestGetEventsPage_FullPaginationWalk(t *testing.T) {
 		}
 	}
 }
+
+// TestCoalesceEvents verifies that runs of identical output events collapse
+// into their last event with the run recorded, while events that differ in
+// payload, type, or timing, and non-noisy types, are kept.
+func TestCoalesceEvents(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev := func(id int64, typ EventType, data string, at time.Duration) TaskEvent {
+		return TaskEvent{ID: id, EventType: typ, Data: json.RawMessage(data), CreatedAt: t0.Add(at)}
+	}
+	events := []TaskEvent{
+		ev(1, EventTypeOutput, `"x"`, 0),
+		ev(2, EventTypeOutput, `"x"`, 2*time.Second),
+		ev(3, EventTypeOutput, `"x"`, 4*time.Second),
+		ev(4, EventTypeOutput, `"y"`, 5*time.Second),
+		ev(5, EventTypeOutput, `"y"`, time.Minute), // outside the window
+		ev(6, EventTypeSpanStart, `"s"`, time.Minute),
+		ev(7, EventTypeSpanStart, `"s"`, time.Minute),
+		ev(8, EventTypeSystem, `"y"`, time.Minute),
+	}
+	before := slices.Clone(events)
+
+	got := CoalesceEvents(events, 10*time.Second)
+	var ids []int64
+	for _, e := range got {
+		ids = append(ids, e.ID)
+	}
+	if want := []int64{3, 4, 5, 6, 7, 8}; !slices.Equal(ids, want) {
+		t.Fatalf("IDs = %v, want %v", ids, want)
+	}
+	if got[0].Count != 3 || got[0].FirstID != 1 || !got[0].CreatedAt.Equal(t0.Add(4*time.Second)) {
+		t.Errorf("run = count %d, first %d, at %v; want 3, 1, +4s", got[0].Count, got[0].FirstID, got[0].CreatedAt)
+	}
+	if got[1].Count != 0 || got[1].FirstID != 0 {
+		t.Errorf("single event carries count %d, first %d", got[1].Count, got[1].FirstID)
+	}
+	if !slices.EqualFunc(events, before, func(a, b TaskEvent) bool { return a.ID == b.ID && a.Count == b.Count }) {
+		t.Error("input slice was modified")
+	}
+	if plain := events[3:5]; &CoalesceEvents(plain, 10*time.Second)[0] != &plain[0] {
+		t.Error("nothing to fold: want the input slice back")
+	}
+}
+
+// TestGetEventsPage_Coalesce verifies the coalesced and raw views of the
+// same trail.
+func TestGetEventsPage_Coalesce(t *testing.T) {
+	s := newTestStore(t)
+	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	for range 5 {
+		_ = s.InsertEvent(bg(), task.ID, EventTypeOutput, map[string]string{"result": "rebasing"})
+	}
+	_ = s.InsertEvent(bg(), task.ID, EventTypeStateChange, "done")
+
+	page, err := s.GetEventsPage(bg(), task.ID, 0, 100, nil, true)
+	if err != nil {
+		t.Fatalf("GetEventsPage: %v", err)
+	}
+	if len(page.Events) != 2 || page.Events[0].Count != 5 || page.Events[0].ID != 5 || page.Events[0].FirstID != 1 {
+		t.Fatalf("coalesced = %+v, want one run of 5 then the state change", page.Events)
+	}
+	if page.TotalFiltered != 2 {
+		t.Errorf("TotalFiltered = %d, want 2", page.TotalFiltered)
+	}
+
+	raw, _ := s.GetEventsPage(bg(), task.ID, 0, 100, nil, false)
+	if len(raw.Events) != 6 {
+		t.Errorf("raw events = %d, want 6", len(raw.Events))
+	}
+	if all, _ := s.GetEvents(bg(), task.ID); len(all) != 6 || all[0].Count != 0 {
+		t.Error("coalescing changed the stored events")
+	}
+}
+
+// TestInsertEvent_RepeatsReachSinkAndSubscribers verifies that coalescing is
+// a read-time view: every repeated event is stored, handed to the event sink
+// (audit log, webhooks), and published to event subscribers (SSE) as it was
+// inserted.
+func TestInsertEvent_RepeatsReachSinkAndSubscribers(t *testing.T) {
+	s := newTestStore(t)
+	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	var sunk []TaskEvent
+	s.SetEventSink(func(ev TaskEvent) { sunk = append(sunk, ev) })
+	defer s.SetEventSink(nil)
+	id, ch := s.SubscribeEvents()
+	defer s.UnsubscribeEvents(id)
+
+	for range 3 {
+		if err := s.InsertEvent(bg(), task.ID, EventTypeOutput, map[string]string{"result": "rebasing"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(sunk) != 3 || sunk[2].ID != 3 || sunk[2].Count != 0 {
+		t.Errorf("sink got %+v, want the 3 events as inserted", sunk)
+	}
+	for i := range 3 {
+		select {
+		case ev := <-ch:
+			if ev.Value.ID != int64(i+1) || ev.Value.Count != 0 {
+				t.Errorf("subscriber event %d = %+v, want ID %d uncoalesced", i, ev.Value, i+1)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("subscriber got %d of 3 events", i)
+		}
+	}
+	if all, _ := s.GetEvents(bg(), task.ID); len(all) != 3 {
+		t.Errorf("stored events = %d, want 3", len(all))
+	}
+	if page, _ := s.GetEventsPage(bg(), task.ID, 0, 100, nil, true); len(page.Events) != 1 || page.Events[0].Count != 3 {
+		t.Errorf("coalesced page = %+v, want one event with count 3", page.Events)
+	}
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSetTaskExperiment_RoundTripAndDiff(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "arm", Timeout: 5})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	peer := uuid.New()
+	if err := s.SetTaskExperiment(bg(), task.ID, Experiment{Role: ExperimentRoleShadow, PeerID: peer, Label: "terse"}); err != nil {
+		t.Fatalf("set experiment: %v", err)
+	}
+	diff := ExperimentDiff{Files: 2, Added: 10, Deleted: 3, MeasuredAt: time.Now().UTC()}
+	if err := s.UpdateExperimentDiff(bg(), task.ID, diff); err != nil {
+		t.Fatalf("update diff: %v", err)
+	}
+
+	got, err := s.GetTask(bg(), task.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !got.IsShadow() || got.Experiment.PeerID != peer || got.Experiment.Label != "terse" {
+		t.Fatalf("experiment = %+v, want shadow of %s labelled terse", got.Experiment, peer)
+	}
+	if got.Experiment.Diff.Files != 2 || got.Experiment.Diff.Added != 10 || got.Experiment.Diff.Deleted != 3 {
+		t.Fatalf("diff = %+v", got.Experiment.Diff)
+	}
+
+	// The returned copy must not alias the stored experiment.
+	got.Experiment.Label = "mutated"
+	again, _ := s.GetTask(bg(), task.ID)
+	if again.Experiment.Label != "terse" {
+		t.Fatal("GetTask returned an aliased Experiment")
+	}
+}
+
+func TestUpdateExperimentDiff_NoExperimentIsNoop(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "plain", Timeout: 5})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := s.UpdateExperimentDiff(bg(), task.ID, ExperimentDiff{Files: 1}); err != nil {
+		t.Fatalf("update diff: %v", err)
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if got.Experiment != nil || got.IsShadow() {
+		t.Fatalf("plain task gained an experiment: %+v", got.Experiment)
+	}
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
+)
+
+// invocationPrefix is the blob key prefix of a task's invocation log. Each
+// launch is its own blob, keyed by its start time so concurrent launches
+// for one task (a title alongside a turn) never contend for a file.
+const invocationPrefix = "invocations/"
+
+// SaveInvocation appends inv to the task's invocation log.
+func (s *Store) SaveInvocation(taskID uuid.UUID, inv Invocation) error {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s%020d.json", invocationPrefix, inv.StartedAt.UnixNano())
+	return s.backend.SaveBlob(taskID, key, data)
+}
+
+// GetInvocations returns the task's invocation log in launch order.
+// Unreadable entries are skipped; a task with none yields an empty slice.
+func (s *Store) GetInvocations(taskID uuid.UUID) ([]Invocation, error) {
+	return readInvocations(s.backend, taskID)
+}
+
+// LoadInvocations reads the invocation log of taskID from the data
+// directory dir (one workspace's directory, holding the task directories)
+// without opening a Store, so the CLI can read it while the server runs.
+// Blobs are decrypted with WALLFACER_STORE_KEY when it is set.
+func LoadInvocations(dir string, taskID uuid.UUID) ([]Invocation, error) {
+	c, err := atrest.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return readInvocations(&FilesystemBackend{dir: dir, cipher: c}, taskID)
+}
+
+func readInvocations(backend StorageBackend, taskID uuid.UUID) ([]Invocation, error) {
+	keys, err := backend.ListBlobs(taskID, invocationPrefix)
+	if err != nil {
+		return nil, err
+	}
+	invs := make([]Invocation, 0, len(keys))
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		data, err := backend.ReadBlob(taskID, key)
+		if err != nil {
+			continue
+		}
+		var inv Invocation
+		if err := json.Unmarshal(data, &inv); err != nil {
+			continue
+		}
+		invs = append(invs, inv)
+	}
+	slices.SortStableFunc(invs, func(a, b Invocation) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return invs, nil
+}
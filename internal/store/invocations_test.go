@@ -0,0 +1,48 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaveAndGetInvocations(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invs, err := s.GetInvocations(task.ID); err != nil || len(invs) != 0 {
+		t.Fatalf("GetInvocations before any launch = %v, %v", invs, err)
+	}
+
+	start := time.Now()
+	code := 0
+	second := Invocation{Turn: 2, Role: "impl", Harness: "claude", Cmd: []string{"-p", "again"}, StartedAt: start.Add(time.Minute), ExitCode: &code}
+	first := Invocation{Turn: 1, Role: "impl", Harness: "claude", Cmd: []string{"-p", "go"},
+		Env: map[string]string{"ANTHROPIC_API_KEY": RedactedValue}, StartedAt: start}
+	for _, inv := range []Invocation{second, first} {
+		if err := s.SaveInvocation(task.ID, inv); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	invs, err := s.GetInvocations(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invs) != 2 || invs[0].Turn != 1 || invs[1].Turn != 2 {
+		t.Fatalf("invocations = %+v, want turns 1 and 2 in launch order", invs)
+	}
+	if invs[0].Env["ANTHROPIC_API_KEY"] != RedactedValue || invs[1].ExitCode == nil || *invs[1].ExitCode != 0 {
+		t.Errorf("round trip lost fields: %+v", invs)
+	}
+
+	loaded, err := LoadInvocations(dir, task.ID)
+	if err != nil || len(loaded) != 2 || loaded[1].Cmd[1] != "again" {
+		t.Errorf("LoadInvocations = %+v, %v", loaded, err)
+	}
+}
@@ -1,8 +1,6 @@
 package store
 
 import (
-	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,7 +8,6 @@ import (
 
 	"github.com/google/uuid"
 	"latere.ai/x/wallfacer/internal/constants"
-	"latere.ai/x/wallfacer/internal/logger"
 	"latere.ai/x/wallfacer/internal/pkg/tail"
 )
 
@@ -38,81 +35,14 @@ func (s *Store) saveTask(id uuid.UUID, task *Task) error {
 	return s.backend.SaveTask(&pruned)
 }
 
-// truncateTurnData applies the per-turn output size budget to data. If
-// s.maxTurnOutputBytes > 0 and len(data) exceeds the limit, it scans backwards
-// from the limit to find the last newline so a JSON line is not split
-// mid-record, then appends a NDJSON truncation_notice sentinel and returns the
-// truncated slice together with the original length. Returns (data, 0) when no
-// truncation is needed.
-func (s *Store) truncateTurnData(data []byte) ([]byte, int) {
-	if s.maxTurnOutputBytes <= 0 || len(data) <= s.maxTurnOutputBytes {
-		return data, 0
-	}
-	originalLen := len(data)
-
-	// Find the last newline at or before the budget boundary so we do not split
-	// a NDJSON record in the middle.
-	cutoff := bytes.LastIndexByte(data[:s.maxTurnOutputBytes], '\n')
-	if cutoff < 0 {
-		// No newline within the budget; hard-cut at the limit.
-		cutoff = s.maxTurnOutputBytes
-	}
-
-	// Append a JSON sentinel line so consumers can detect truncation
-	// without comparing against the expected length.
-	sentinel := fmt.Sprintf(
-		`{"type":"system","subtype":"truncation_notice","total_bytes":%d,"truncated_at":%d}`,
-		originalLen, cutoff,
-	)
-
-	result := make([]byte, 0, cutoff+1+len(sentinel)+1)
-	result = append(result, data[:cutoff]...)
-	result = append(result, '\n')
-	result = append(result, sentinel...)
-	result = append(result, '\n')
-	return result, originalLen
-}
-
-// SaveTurnOutput persists raw stdout/stderr for a given turn via the backend.
+// SaveTurnOutput persists raw stdout/stderr for a given turn via the backend,
+// applying the per-turn size budget. See OpenTurnOutput for output that is
+// streamed while the agent runs.
 func (s *Store) SaveTurnOutput(taskID uuid.UUID, turn int, stdout, stderr []byte) error {
-	truncated := false
-
-	// Apply the server-side per-turn stdout size budget.
-	if truncatedStdout, originalLen := s.truncateTurnData(stdout); originalLen > 0 {
-		logger.Store.Warn("turn output truncated",
-			"task", taskID, "turn", turn, "original_bytes", originalLen)
-		stdout = truncatedStdout
-		truncated = true
-	}
-
-	stdoutKey := fmt.Sprintf("outputs/turn-%04d.json", turn)
-	if err := s.backend.SaveBlob(taskID, stdoutKey, stdout); err != nil {
-		return fmt.Errorf("write stdout: %w", err)
-	}
-
-	if len(stderr) > 0 {
-		// Apply the server-side per-turn stderr size budget.
-		if truncatedStderr, originalLen := s.truncateTurnData(stderr); originalLen > 0 {
-			logger.Store.Warn("turn output truncated",
-				"task", taskID, "turn", turn, "original_bytes", originalLen)
-			stderr = truncatedStderr
-			truncated = true
-		}
-
-		stderrKey := fmt.Sprintf("outputs/turn-%04d.stderr.txt", turn)
-		if err := s.backend.SaveBlob(taskID, stderrKey, stderr); err != nil {
-			return fmt.Errorf("write stderr: %w", err)
-		}
-	}
-
-	if truncated {
-		if err := s.MarkTurnTruncated(context.Background(), taskID, turn); err != nil {
-			logger.Store.Warn("failed to mark turn truncated",
-				"task", taskID, "turn", turn, "error", err)
-		}
-	}
-
-	return nil
+	o := s.OpenTurnOutput(taskID, turn)
+	_, _ = o.Stdout().Write(stdout)
+	_, _ = o.Stderr().Write(stderr)
+	return o.Close()
 }
 
 // SaveSummary atomically writes the immutable task summary for a completed task.
@@ -141,3 +71,31 @@ func (s *Store) LoadSummary(id uuid.UUID) (*TaskSummary, error) {
 	}
 	return &summary, nil
 }
+
+// attemptDiffKey returns the blob key holding the archived diff of a retired
+// attempt.
+func attemptDiffKey(attempt int) string {
+	return fmt.Sprintf("attempts/attempt-%04d.diff", attempt)
+}
+
+// SaveAttemptDiff archives the diff a retired attempt produced, keyed by its
+// RetryRecord.Attempt number. Retries reuse (or, with fresh_start, delete) the
+// task's branch, so the diff must be captured before the reset to remain
+// comparable with later attempts.
+func (s *Store) SaveAttemptDiff(taskID uuid.UUID, attempt int, diff string) error {
+	return s.backend.SaveBlob(taskID, attemptDiffKey(attempt), []byte(diff))
+}
+
+// LoadAttemptDiff reads the archived diff of a retired attempt. The boolean
+// is false when no diff was archived (the attempt predates archiving or the
+// task had no worktrees at retry time).
+func (s *Store) LoadAttemptDiff(taskID uuid.UUID, attempt int) (string, bool, error) {
+	data, err := s.backend.ReadBlob(taskID, attemptDiffKey(attempt))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
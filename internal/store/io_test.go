@@ -227,3 +227,23 @@ func TestSaveTurnOutput_UnlimitedWhenZero(t *testing.T) {
 		t.Errorf("expected %d bytes unchanged, got %d", len(big), len(data))
 	}
 }
+
+func TestAttemptDiff_RoundTrip(t *testing.T) {
+	s, _ := newTestFileStore(t, t.TempDir())
+	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+
+	if _, ok, err := s.LoadAttemptDiff(task.ID, 1); err != nil || ok {
+		t.Fatalf("LoadAttemptDiff before save: ok=%v err=%v, want absent", ok, err)
+	}
+	diff := "diff --git a/x b/x\n+added\n"
+	if err := s.SaveAttemptDiff(task.ID, 1, diff); err != nil {
+		t.Fatalf("SaveAttemptDiff: %v", err)
+	}
+	got, ok, err := s.LoadAttemptDiff(task.ID, 1)
+	if err != nil || !ok || got != diff {
+		t.Errorf("LoadAttemptDiff = %q ok=%v err=%v, want %q", got, ok, err, diff)
+	}
+	if _, ok, _ := s.LoadAttemptDiff(task.ID, 2); ok {
+		t.Error("attempt 2 reported present without a saved diff")
+	}
+}
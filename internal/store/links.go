@@ -0,0 +1,67 @@
+package store
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// TaskLinkType classifies an external resource attached to a task.
+type TaskLinkType string
+
+// TaskLinkType constants.
+const (
+	TaskLinkJira  TaskLinkType = "jira"  // ticket
+	TaskLinkFigma TaskLinkType = "figma" // design
+	TaskLinkDoc   TaskLinkType = "doc"   // design doc, spec, or wiki page
+	TaskLinkPR    TaskLinkType = "pr"    // pull or merge request
+)
+
+var taskLinkTypes = map[TaskLinkType]bool{
+	TaskLinkJira:  true,
+	TaskLinkFigma: true,
+	TaskLinkDoc:   true,
+	TaskLinkPR:    true,
+}
+
+// MaxTaskLinks bounds the links one task may carry.
+const MaxTaskLinks = 20
+
+// maxTaskLinkTitle caps, in runes, a link's display title.
+const maxTaskLinkTitle = 200
+
+// TaskLink is an external reference (ticket, design, doc, pull request)
+// attached to a task. It is shown on the card and listed in the agent's
+// prompt as optional context.
+type TaskLink struct {
+	Type  TaskLinkType `json:"type"`
+	URL   string       `json:"url"`
+	Title string       `json:"title,omitempty"`
+}
+
+// NormalizeTaskLinks validates links and returns them with the type
+// lowercased and the URL and title trimmed. Every URL must be an absolute
+// http or https URL.
+func NormalizeTaskLinks(links []TaskLink) ([]TaskLink, error) {
+	if len(links) > MaxTaskLinks {
+		return nil, fmt.Errorf("at most %d links (got %d)", MaxTaskLinks, len(links))
+	}
+	out := make([]TaskLink, 0, len(links))
+	for i, l := range links {
+		l.Type = TaskLinkType(strings.ToLower(strings.TrimSpace(string(l.Type))))
+		if !taskLinkTypes[l.Type] {
+			return nil, fmt.Errorf("link %d: type must be one of jira, figma, doc, pr (got %q)", i, l.Type)
+		}
+		l.URL = strings.TrimSpace(l.URL)
+		u, err := url.Parse(l.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("link %d: url must be an absolute http or https URL (got %q)", i, l.URL)
+		}
+		l.Title = strings.TrimSpace(l.Title)
+		if n := len([]rune(l.Title)); n > maxTaskLinkTitle {
+			return nil, fmt.Errorf("link %d: title must be at most %d characters (got %d)", i, maxTaskLinkTitle, n)
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTaskLinks(t *testing.T) {
+	got, err := NormalizeTaskLinks([]TaskLink{
+		{Type: " JIRA ", URL: " https://example.atlassian.net/browse/APP-12 ", Title: " Login flake "},
+		{Type: "pr", URL: "http://git.example.com/app/pull/7"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TaskLink{
+		{Type: TaskLinkJira, URL: "https://example.atlassian.net/browse/APP-12", Title: "Login flake"},
+		{Type: TaskLinkPR, URL: "http://git.example.com/app/pull/7"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	tooMany := make([]TaskLink, MaxTaskLinks+1)
+	for i := range tooMany {
+		tooMany[i] = TaskLink{Type: TaskLinkDoc, URL: "https://example.com/"}
+	}
+	for name, links := range map[string][]TaskLink{
+		"unknown type":   {{Type: "slack", URL: "https://example.com/"}},
+		"relative url":   {{Type: TaskLinkDoc, URL: "/docs/spec"}},
+		"file scheme":    {{Type: TaskLinkDoc, URL: "file:///etc/passwd"}},
+		"missing host":   {{Type: TaskLinkDoc, URL: "https://"}},
+		"long title":     {{Type: TaskLinkDoc, URL: "https://example.com/", Title: strings.Repeat("x", maxTaskLinkTitle+1)}},
+		"too many links": tooMany,
+	} {
+		if _, err := NormalizeTaskLinks(links); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPatchTask_Links(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := []TaskLink{{Type: TaskLinkFigma, URL: "https://figma.com/file/abc", Title: "Checkout"}}
+	if err := s.PatchTask(bg(), task.ID, TaskPatch{Links: &links}); err != nil {
+		t.Fatal(err)
+	}
+	links[0].Title = "mutated"
+	got, _ := s.GetTask(bg(), task.ID)
+	if len(got.Links) != 1 || got.Links[0].Title != "Checkout" {
+		t.Fatalf("links = %+v, want the patched link unaffected by caller mutation", got.Links)
+	}
+
+	empty := []TaskLink{}
+	if err := s.PatchTask(bg(), task.ID, TaskPatch{Links: &empty}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetTask(bg(), task.ID); got.Links != nil {
+		t.Errorf("links = %+v, want nil after clearing", got.Links)
+	}
+}
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LocalizedSummary is a task's title and final summary translated into
+// another language, kept alongside the originals so the board can show
+// either. It is regenerated each time the agent finishes a turn.
+type LocalizedSummary struct {
+	Locale      string    `json:"locale"`
+	Title       string    `json:"title,omitempty"`
+	Summary     string    `json:"summary,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// localeTag matches a BCP 47 language tag such as "ja", "zh-CN", or
+// "zh-Hant-TW": a two- or three-letter language and optional subtags.
+var localeTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// NormalizeLocale trims s and checks that it is a BCP 47 language tag. An
+// empty s is valid and means no locale.
+func NormalizeLocale(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || localeTag.MatchString(s) {
+		return s, nil
+	}
+	return "", fmt.Errorf("must be a language tag such as zh-CN (got %q)", s)
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	for in, want := range map[string]string{
+		"":           "",
+		" zh-CN ":    "zh-CN",
+		"ja":         "ja",
+		"zh-Hant-TW": "zh-Hant-TW",
+		"es-419":     "es-419",
+	} {
+		if got, err := NormalizeLocale(in); err != nil || got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"Chinese", "zh_CN", "zh-", "e"} {
+		if _, err := NormalizeLocale(bad); err == nil {
+			t.Errorf("NormalizeLocale(%q) accepted", bad)
+		}
+	}
+}
+
+func TestPatchTask_SummaryLocaleDropsStaleTranslation(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	task, err := s.CreateTaskWithOptions(ctx, TaskCreateOptions{Prompt: "p", SummaryLocale: "zh-CN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTaskLocalized(ctx, task.ID, LocalizedSummary{Locale: "zh-CN", Title: "标题", GeneratedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	same := "zh-CN"
+	if err := s.PatchTask(ctx, task.ID, TaskPatch{SummaryLocale: &same}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetTask(ctx, task.ID); got.Localized == nil {
+		t.Fatal("translation dropped although the locale did not change")
+	}
+
+	other := "ja"
+	if err := s.PatchTask(ctx, task.ID, TaskPatch{SummaryLocale: &other}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.GetTask(ctx, task.ID)
+	if got.SummaryLocale != "ja" || got.Localized != nil {
+		t.Errorf("SummaryLocale = %q, Localized = %+v; want ja and no translation", got.SummaryLocale, got.Localized)
+	}
+}
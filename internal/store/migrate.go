@@ -12,22 +12,71 @@ import (
 	"latere.ai/x/wallfacer/internal/harness"
 )
 
+// taskMigration upgrades a task from schema version To-1 to To. Apply
+// mutates the task in place and reports whether anything changed.
+type taskMigration struct {
+	To    int
+	Name  string
+	Apply func(t *Task) bool
+}
+
+// taskMigrations is the ordered registry of schema upgrades. Entry i moves a
+// task from version i to version i+1, so the registry length always equals
+// constants.CurrentTaskSchemaVersion (enforced by TestTaskMigrations_Registry).
+// To add a field that needs backfilling: append a migration here, bump
+// CurrentTaskSchemaVersion, and add a fixture for the previous version to
+// migrate_test.go.
+var taskMigrations = []taskMigration{
+	{To: 1, Name: "model_override", Apply: migrateModelOverride},
+	{To: 2, Name: "auto_retry_budget", Apply: migrateAutoRetryBudget},
+}
+
+// migrateModelOverride moves the deprecated Model field to ModelOverride
+// (when ModelOverride is unset) and clears Model.
+func migrateModelOverride(t *Task) bool {
+	if t.Model == "" || t.ModelOverride != nil {
+		return false
+	}
+	model := t.Model
+	t.ModelOverride = &model
+	t.Model = ""
+	return true
+}
+
+// migrateAutoRetryBudget backfills AutoRetryBudget for tasks created before
+// schema version 2. Only transient/infrastructure failures get a budget;
+// agent_error and timeout are not retried automatically because they are
+// likely to recur.
+func migrateAutoRetryBudget(t *Task) bool {
+	if t.AutoRetryBudget != nil {
+		return false
+	}
+	t.AutoRetryBudget = map[FailureCategory]int{
+		FailureCategoryContainerCrash: defaultAutoRetryBudget[FailureCategoryContainerCrash],
+		FailureCategorySyncError:      defaultAutoRetryBudget[FailureCategorySyncError],
+		FailureCategoryWorktree:       defaultAutoRetryBudget[FailureCategoryWorktree],
+	}
+	return true
+}
+
 // migrateTaskJSON deserializes raw JSON into a Task and applies any
 // missing-value defaults, canonicalization, and schema-version stamping.
 // It returns the migrated Task, whether any change was made (so the caller
 // can persist the result and avoid redundant writes), and any parse error.
 //
 // Migration steps applied in order:
-//  1. Migrate the deprecated Model field to ModelOverride (when Model is set
-//     and ModelOverride is unset), then clear Model.
+//  1. Run every taskMigrations entry newer than the task's SchemaVersion.
 //  2. Default missing/zero values: Status → "backlog", Timeout via
 //     clampTimeout, missing CreatedAt/UpdatedAt from file mod time.
 //  3. Canonicalize DependsOn: trim whitespace, UUID-validate, deduplicate,
 //     stable-sort.
 //  4. Normalize Sandbox (trim) and SandboxByActivity via
 //     normalizeSandboxByActivity.
-//  5. Backfill AutoRetryBudget for tasks created before schema version 2.
-//  6. Stamp SchemaVersion = constants.CurrentTaskSchemaVersion.
+//  5. Stamp SchemaVersion = constants.CurrentTaskSchemaVersion.
+//
+// Tasks written by a newer binary (SchemaVersion above current) skip steps 1
+// and 5 so their version is not silently lowered; they are still normalized
+// so this binary can operate on the fields it knows about.
 func migrateTaskJSON(raw []byte, fileModTime time.Time) (Task, bool, error) {
 	var task Task
 	if err := json.Unmarshal(raw, &task); err != nil {
@@ -35,15 +84,18 @@ func migrateTaskJSON(raw []byte, fileModTime time.Time) (Task, bool, error) {
 	}
 
 	changed := false
+	fromNewer := task.SchemaVersion > constants.CurrentTaskSchemaVersion
 
-	// Migrate deprecated Model field to ModelOverride.
-	if task.Model != "" && task.ModelOverride == nil {
-		task.ModelOverride = &task.Model
-		task.Model = ""
-		changed = true
+	// (1) Versioned upgrades. A negative version is treated as 0.
+	if !fromNewer {
+		for _, m := range taskMigrations[max(task.SchemaVersion, 0):] {
+			if m.Apply(&task) {
+				changed = true
+			}
+		}
 	}
 
-	// (1) Default missing/zero values.
+	// (2) Default missing/zero values.
 	if task.Status == "" {
 		task.Status = TaskStatusBacklog
 		changed = true
@@ -61,7 +113,7 @@ func migrateTaskJSON(raw []byte, fileModTime time.Time) (Task, bool, error) {
 		changed = true
 	}
 
-	// (2) Canonicalize DependsOn.
+	// (3) Canonicalize DependsOn.
 	if len(task.DependsOn) > 0 {
 		canon := canonicalizeDependsOn(task.DependsOn)
 		if !slices.Equal(canon, task.DependsOn) {
@@ -70,7 +122,7 @@ func migrateTaskJSON(raw []byte, fileModTime time.Time) (Task, bool, error) {
 		}
 	}
 
-	// (3) Normalize Sandbox and SandboxByActivity.
+	// (4) Normalize Sandbox and SandboxByActivity.
 	if normalSandbox := harness.NormalizeID(string(task.Sandbox)); normalSandbox != task.Sandbox {
 		task.Sandbox = normalSandbox
 		changed = true
@@ -80,20 +132,8 @@ func migrateTaskJSON(raw []byte, fileModTime time.Time) (Task, bool, error) {
 		changed = true
 	}
 
-	// (4) Backfill AutoRetryBudget for tasks created before schema version 2.
-	// Only transient/infrastructure failures get a budget; agent_error and
-	// timeout are not retried automatically because they are likely to recur.
-	if task.AutoRetryBudget == nil {
-		task.AutoRetryBudget = map[FailureCategory]int{
-			FailureCategoryContainerCrash: 2,
-			FailureCategorySyncError:      2,
-			FailureCategoryWorktree:       1,
-		}
-		changed = true
-	}
-
 	// (5) Guarantee SchemaVersion is current.
-	if task.SchemaVersion != constants.CurrentTaskSchemaVersion {
+	if !fromNewer && task.SchemaVersion != constants.CurrentTaskSchemaVersion {
 		task.SchemaVersion = constants.CurrentTaskSchemaVersion
 		changed = true
 	}
@@ -101,6 +141,16 @@ func migrateTaskJSON(raw []byte, fileModTime time.Time) (Task, bool, error) {
 	return task, changed, nil
 }
 
+// rawSchemaVersion returns the schema_version recorded in raw task JSON, or
+// 0 when the field is absent or the JSON cannot be parsed.
+func rawSchemaVersion(raw []byte) int {
+	var v struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	_ = json.Unmarshal(raw, &v)
+	return v.SchemaVersion
+}
+
 // canonicalizeDependsOn trims whitespace from each element, validates UUID
 // format (dropping non-UUID values), deduplicates using the 16-byte UUID value
 // (so case and format variants are unified), and sorts the result in ascending
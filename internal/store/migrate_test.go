@@ -2,6 +2,7 @@ package store
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -234,6 +235,164 @@ func TestMigrateTaskJSON_NoChangesWhenAlreadyCurrent(t *testing.T) {
 	_ = changed
 }
 
+// --- taskMigrations registry tests ---
+
+// taskFixturesByVersion holds a representative task.json for every released
+// schema version. Each fixture exercises the fields the following migrations
+// touch. TestMigrateTaskJSON_UpgradesEveryReleasedVersion requires an entry
+// for every version below constants.CurrentTaskSchemaVersion, so bumping the
+// version without adding a fixture fails the build.
+var taskFixturesByVersion = map[int]string{
+	0: `{
+		"id": "11111111-1111-1111-1111-111111111111",
+		"prompt": "v0 task",
+		"status": "waiting",
+		"timeout": 30,
+		"model": "claude-sonnet",
+		"created_at": "2025-01-01T00:00:00Z",
+		"updated_at": "2025-01-01T00:00:00Z"
+	}`,
+	1: `{
+		"id": "22222222-2222-2222-2222-222222222222",
+		"schema_version": 1,
+		"prompt": "v1 task",
+		"status": "done",
+		"timeout": 45,
+		"model_override": "claude-opus",
+		"depends_on": ["11111111-1111-1111-1111-111111111111"],
+		"created_at": "2025-06-01T00:00:00Z",
+		"updated_at": "2025-06-01T00:00:00Z"
+	}`,
+}
+
+func TestTaskMigrations_Registry(t *testing.T) {
+	if len(taskMigrations) != constants.CurrentTaskSchemaVersion {
+		t.Fatalf("len(taskMigrations) = %d, want CurrentTaskSchemaVersion = %d",
+			len(taskMigrations), constants.CurrentTaskSchemaVersion)
+	}
+	for i, m := range taskMigrations {
+		if m.To != i+1 {
+			t.Errorf("taskMigrations[%d].To = %d, want %d", i, m.To, i+1)
+		}
+		if m.Name == "" || m.Apply == nil {
+			t.Errorf("taskMigrations[%d] must have a Name and Apply", i)
+		}
+	}
+}
+
+func TestMigrateTaskJSON_UpgradesEveryReleasedVersion(t *testing.T) {
+	for v := 0; v < constants.CurrentTaskSchemaVersion; v++ {
+		raw, ok := taskFixturesByVersion[v]
+		if !ok {
+			t.Errorf("missing task fixture for schema version %d", v)
+			continue
+		}
+		t.Run(fmt.Sprintf("v%d", v), func(t *testing.T) {
+			task, changed, err := migrateTaskJSON([]byte(raw), time.Now())
+			if err != nil {
+				t.Fatalf("migrateTaskJSON: %v", err)
+			}
+			if !changed {
+				t.Error("expected changed=true when upgrading an old schema version")
+			}
+			if task.SchemaVersion != constants.CurrentTaskSchemaVersion {
+				t.Errorf("SchemaVersion = %d, want %d", task.SchemaVersion, constants.CurrentTaskSchemaVersion)
+			}
+			if task.Model != "" {
+				t.Errorf("deprecated Model should be cleared, got %q", task.Model)
+			}
+			if task.ModelOverride == nil || *task.ModelOverride == "" {
+				t.Error("expected ModelOverride to be preserved or migrated from Model")
+			}
+			if task.AutoRetryBudget == nil {
+				t.Error("expected AutoRetryBudget to be backfilled")
+			}
+
+			// A second pass over the migrated task must be a no-op.
+			out, err := json.Marshal(task)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, changed, err := migrateTaskJSON(out, time.Now())
+			if err != nil {
+				t.Fatalf("second migrateTaskJSON: %v", err)
+			}
+			if changed {
+				t.Error("expected migration to be idempotent on an already-migrated task")
+			}
+			if again.Prompt != task.Prompt || again.Status != task.Status || again.Timeout != task.Timeout {
+				t.Errorf("round trip changed core fields: %+v vs %+v", again, task)
+			}
+		})
+	}
+}
+
+func TestMigrateTaskJSON_PreservesModelOverride(t *testing.T) {
+	raw := buildMinimalTaskJSON(t, map[string]any{
+		"model":          "old",
+		"model_override": "new",
+	})
+	task, _, err := migrateTaskJSON(raw, time.Now())
+	if err != nil {
+		t.Fatalf("migrateTaskJSON: %v", err)
+	}
+	if task.ModelOverride == nil || *task.ModelOverride != "new" {
+		t.Errorf("ModelOverride = %v, want \"new\"", task.ModelOverride)
+	}
+}
+
+func TestMigrateTaskJSON_CurrentVersionSkipsRegistry(t *testing.T) {
+	// A current-version task with no budget is not backfilled: only tasks
+	// created before version 2 predate the field.
+	raw := buildMinimalTaskJSON(t, map[string]any{
+		"schema_version": constants.CurrentTaskSchemaVersion,
+	})
+	task, _, err := migrateTaskJSON(raw, time.Now())
+	if err != nil {
+		t.Fatalf("migrateTaskJSON: %v", err)
+	}
+	if task.AutoRetryBudget != nil {
+		t.Errorf("AutoRetryBudget = %v, want nil for a current-version task", task.AutoRetryBudget)
+	}
+}
+
+func TestMigrateTaskJSON_NewerVersionNotDowngraded(t *testing.T) {
+	newer := constants.CurrentTaskSchemaVersion + 1
+	raw := buildMinimalTaskJSON(t, map[string]any{
+		"schema_version": newer,
+		"status":         string(TaskStatusBacklog),
+		"timeout":        60,
+		"created_at":     time.Now().UTC(),
+		"updated_at":     time.Now().UTC(),
+	})
+	task, changed, err := migrateTaskJSON(raw, time.Now())
+	if err != nil {
+		t.Fatalf("migrateTaskJSON: %v", err)
+	}
+	if task.SchemaVersion != newer {
+		t.Errorf("SchemaVersion = %d, want %d (unchanged)", task.SchemaVersion, newer)
+	}
+	if changed {
+		t.Error("expected changed=false for a task written by a newer version")
+	}
+}
+
+func TestRawSchemaVersion(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want int
+	}{
+		{`{"schema_version": 1}`, 1},
+		{`{"prompt": "x"}`, 0},
+		{`{bad`, 0},
+	}
+	for _, tt := range tests {
+		if got := rawSchemaVersion([]byte(tt.raw)); got != tt.want {
+			t.Errorf("rawSchemaVersion(%s) = %d, want %d", tt.raw, got, tt.want)
+		}
+	}
+}
+
 // --- canonicalizeDependsOn tests ---
 
 func TestCanonicalizeDependsOn_EmptySlice(t *testing.T) {
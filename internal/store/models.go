@@ -2,6 +2,7 @@ package store
 
 import (
 	"encoding/json"
+	"fmt"
 	"slices"
 	"strings"
 	"time"
@@ -45,6 +46,67 @@ type ExecutionEnvironment struct {
 	APIBaseURL      string     `json:"api_base_url"`     // empty string = default Anthropic endpoint
 	Sandbox         harness.ID `json:"sandbox"`          // configured sandbox: "claude", "codex", etc.
 	RecordedAt      time.Time  `json:"recorded_at"`
+
+	// Timezone, Locale, and SourceDateEpoch are the TZ, LANG/LC_ALL, and
+	// SOURCE_DATE_EPOCH values pinned in the agent's environment so generated
+	// timestamps and locale-sensitive output match across machines.
+	Timezone        string `json:"timezone,omitempty"`
+	Locale          string `json:"locale,omitempty"`
+	SourceDateEpoch int64  `json:"source_date_epoch,omitempty"`
+
+	// Platform is the os/arch the agent ran as (e.g. "darwin/amd64");
+	// Emulated reports that it differs from the host's native architecture
+	// because the workspace forced one.
+	Platform string `json:"platform,omitempty"`
+	Emulated bool   `json:"emulated,omitempty"`
+
+	// Isolation is the host isolation level the agent ran under
+	// ("restricted" or "nsjail"); empty means unconfined.
+	Isolation string `json:"isolation,omitempty"`
+}
+
+// EstimateRisk is the estimator's qualitative rating of how likely a task is
+// to need rework, retries, or human intervention.
+type EstimateRisk string
+
+// EstimateRisk values.
+const (
+	EstimateRiskLow    EstimateRisk = "low"
+	EstimateRiskMedium EstimateRisk = "medium"
+	EstimateRiskHigh   EstimateRisk = "high"
+)
+
+// TaskEstimate is a pre-run effort prediction for a task, produced by the
+// estimation agent from the prompt and the workspace layout. Tokens counts
+// input plus output tokens (cache tokens excluded), matching how actuals are
+// compared in GET /api/stats.
+type TaskEstimate struct {
+	Turns       int          `json:"turns"`
+	Tokens      int          `json:"tokens"`
+	Minutes     float64      `json:"minutes"`
+	Risk        EstimateRisk `json:"risk"`
+	Rationale   string       `json:"rationale,omitempty"`
+	EstimatedAt time.Time    `json:"estimated_at"`
+}
+
+// TaskBlock records why a backlog task is blocked on something outside the
+// board (a review, a decision, another team) and when it may be unblocked.
+// A blocked task is never auto-promoted; it stays blocked until the user
+// clears the block or starts the task.
+type TaskBlock struct {
+	Reason string    `json:"reason"`
+	Since  time.Time `json:"since"`
+	// Until is the optional date the block is expected to lift. Passing it
+	// does not unblock the task; it fires a one-time reminder.
+	Until *time.Time `json:"until,omitempty"`
+	// RemindedAt is when the reminder for Until fired; nil until then.
+	RemindedAt *time.Time `json:"reminded_at,omitempty"`
+}
+
+// ReminderDue reports whether the unblock reminder should fire at now: the
+// unblock date has passed and no reminder has fired for it yet.
+func (b *TaskBlock) ReminderDue(now time.Time) bool {
+	return b != nil && b.Until != nil && b.RemindedAt == nil && !now.Before(*b.Until)
 }
 
 // TurnUsageRecord captures token consumption and stop reason for a single agent turn.
@@ -59,6 +121,62 @@ type TurnUsageRecord struct {
 	StopReason           string          `json:"stop_reason,omitempty"`
 	Sandbox              harness.ID      `json:"sandbox,omitempty"`
 	SubAgent             SandboxActivity `json:"sub_agent,omitempty"`
+	// Exit is not stored in the usage log; GET /api/tasks/{id}/turn-usage
+	// attaches the turn's TurnExit when one was recorded.
+	Exit *TurnExit `json:"exit,omitempty"`
+}
+
+// TurnExit records how the agent process for one implementation or test turn
+// ended. It is saved for every turn, including failed ones that produce no
+// TurnUsageRecord, so an infrastructure kill can be told apart from an agent
+// that gave up.
+type TurnExit struct {
+	Turn       int       `json:"turn"`
+	ExitCode   int       `json:"exit_code"`        // -1 when terminated by a signal
+	Signal     string    `json:"signal,omitempty"` // e.g. "killed", "terminated"
+	OOMKilled  bool      `json:"oom_killed,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at"`
+	DurationMs int64     `json:"duration_ms"`
+	MaxRSS     int64     `json:"max_rss_bytes,omitempty"`
+	// Binary and BinaryDigest identify the agent CLI build ("sha256:<hex>").
+	Binary       string `json:"binary,omitempty"`
+	BinaryDigest string `json:"binary_digest,omitempty"`
+}
+
+// RedactedValue replaces the value of a secret environment variable in an
+// Invocation.
+const RedactedValue = "<redacted>"
+
+// Invocation records one agent process launch exactly enough to run it
+// again (see `wallfacer replay`): the launch spec the runner handed the
+// backend, with secret environment values replaced by RedactedValue, and
+// the agent build that ran it. Turn is 0 for launches outside the turn loop
+// (title, oversight, commit message, ...).
+type Invocation struct {
+	Turn        int               `json:"turn,omitempty"`
+	Role        string            `json:"role"`
+	Activity    SandboxActivity   `json:"activity,omitempty"`
+	Harness     string            `json:"harness"`
+	Name        string            `json:"name"`
+	Cmd         []string          `json:"cmd"`
+	Env         map[string]string `json:"env,omitempty"`
+	EnvFile     string            `json:"env_file,omitempty"`
+	WorkDir     string            `json:"workdir,omitempty"`
+	Arch        string            `json:"arch,omitempty"`
+	Interactive bool              `json:"interactive,omitempty"`
+	// Worktrees maps each workspace to the task worktree mounted in its
+	// place, and BoardDir is the board context directory; together they
+	// are what a container launch would have mounted.
+	Worktrees map[string]string `json:"worktrees,omitempty"`
+	BoardDir  string            `json:"board_dir,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+	// Binary, BinaryDigest, and ExitCode come from the process's exit
+	// metadata; Error is set instead when the launch itself failed.
+	Binary       string `json:"binary,omitempty"`
+	BinaryDigest string `json:"binary_digest,omitempty"`
+	ExitCode     *int   `json:"exit_code,omitempty"`
+	Error        string `json:"error,omitempty"`
 }
 
 // RefinementMessage is a single turn in a refinement chat session.
@@ -86,6 +204,7 @@ type RefinementSession struct {
 // RetryRecord captures the execution outcome of one task lifecycle before it
 // is reset for a retry. Appended to Task.RetryHistory by ResetTaskForRetry.
 type RetryRecord struct {
+	Attempt         int             `json:"attempt,omitempty"` // 1-based lifecycle number; stable across history pruning
 	RetiredAt       time.Time       `json:"retired_at"`
 	Prompt          string          `json:"prompt"`
 	Status          TaskStatus      `json:"status"`
@@ -129,8 +248,35 @@ const (
 	TaskKindTask     TaskKind = ""         // default; regular implementation task
 	TaskKindPlanning TaskKind = "planning" // spec planning session task
 	TaskKindRoutine  TaskKind = "routine"  // scheduler template; spawns instance tasks on its interval
+	TaskKindResearch TaskKind = "research" // time-boxed research; web access proxied to ResearchDomains
 )
 
+// MergeMode selects how the commit pipeline delivers a task's branch.
+type MergeMode string
+
+// MergeMode constants.
+const (
+	// MergeModeMerge rebases the branch and fast-forwards the default
+	// branch onto it. It is the zero value.
+	MergeModeMerge MergeMode = ""
+	// MergeModePullRequest pushes the branch to origin and opens a pull
+	// request (a merge request on GitLab) against the default branch,
+	// leaving the default branch untouched.
+	MergeModePullRequest MergeMode = "pull_request"
+)
+
+// NormalizeMergeMode parses a merge mode from the API, where "merge" is
+// accepted as a name for the empty MergeModeMerge.
+func NormalizeMergeMode(s string) (MergeMode, error) {
+	switch m := MergeMode(strings.TrimSpace(s)); m {
+	case MergeModeMerge, "merge":
+		return MergeModeMerge, nil
+	case MergeModePullRequest:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown merge mode %q (want merge or pull_request)", s)
+}
+
 // SandboxActivity identifies which phase of a task a container run belongs to.
 // The routing constants (Implementation through AgentSession) are used for
 // sandbox-per-activity configuration. Test and OversightTest are
@@ -158,6 +304,10 @@ const (
 	SandboxActivityTest SandboxActivity = "test"
 	// SandboxActivityOversightTest is a usage-attribution-only activity for test oversight generation.
 	SandboxActivityOversightTest SandboxActivity = "oversight-test"
+	// SandboxActivityConflictResolver is the rebase conflict resolver. Its
+	// sandbox is routed by WALLFACER_SANDBOX_CONFLICT_RESOLVER, falling back
+	// to the implementation's, rather than by the per-activity settings.
+	SandboxActivityConflictResolver SandboxActivity = "conflict_resolver"
 	// SandboxActivityReview is a usage-attribution-only activity for review
 	// adversarial verification (proposer + critics), so its cost is visible in
 	// the task's usage breakdown rather than untracked.
@@ -184,11 +334,29 @@ const (
 	TaskStatusInProgress TaskStatus = "in_progress" // agent is running in a container
 	TaskStatusWaiting    TaskStatus = "waiting"     // agent stopped, waiting for user feedback
 	TaskStatusCommitting TaskStatus = "committing"  // commit pipeline running (merge, push)
+	TaskStatusReview     TaskStatus = "review"      // changes committed in the worktree, merge held for approval (see RequireApproval)
 	TaskStatusDone       TaskStatus = "done"        // completed successfully
 	TaskStatusFailed     TaskStatus = "failed"      // execution failed; eligible for retry
 	TaskStatusCancelled  TaskStatus = "cancelled"   // user-cancelled; can be retried to backlog
 )
 
+// ParseTaskStatus normalizes a string into a known task status.
+func ParseTaskStatus(raw string) (TaskStatus, bool) {
+	status := TaskStatus(strings.TrimSpace(raw))
+	switch status {
+	case TaskStatusBacklog,
+		TaskStatusInProgress,
+		TaskStatusWaiting,
+		TaskStatusCommitting,
+		TaskStatusReview,
+		TaskStatusDone,
+		TaskStatusFailed,
+		TaskStatusCancelled:
+		return status, true
+	}
+	return "", false
+}
+
 // FailureCategory identifies the root cause of a task failure.
 type FailureCategory string
 
@@ -225,7 +393,8 @@ func ParseFailureCategory(raw string) (FailureCategory, bool) {
 var TaskMachine = statemachine.New(map[TaskStatus][]TaskStatus{
 	TaskStatusBacklog:    {TaskStatusInProgress},
 	TaskStatusInProgress: {TaskStatusBacklog, TaskStatusWaiting, TaskStatusFailed, TaskStatusCancelled},
-	TaskStatusCommitting: {TaskStatusDone, TaskStatusFailed},
+	TaskStatusCommitting: {TaskStatusDone, TaskStatusFailed, TaskStatusReview},
+	TaskStatusReview:     {TaskStatusCommitting, TaskStatusWaiting, TaskStatusCancelled},
 	TaskStatusWaiting:    {TaskStatusInProgress, TaskStatusCommitting, TaskStatusCancelled},
 	TaskStatusFailed:     {TaskStatusBacklog, TaskStatusCancelled},
 	TaskStatusDone:       {TaskStatusCancelled},
@@ -311,6 +480,12 @@ type Task struct {
 	// field via TasksForPrincipal; local queries ignore it.
 	OrgID string `json:"org_id,omitempty"`
 
+	// Watchers are the principal IDs of users other than the creator who
+	// asked to be notified about this task's state changes, either
+	// explicitly or by being @mentioned in a comment. Managed through
+	// WatchTask and UnwatchTask.
+	Watchers []string `json:"watchers,omitempty"`
+
 	// Worktree isolation fields (populated when task moves to in_progress).
 	WorktreePaths    map[string]string `json:"worktree_paths,omitempty"`     // host repoPath → worktree path
 	BranchName       string            `json:"branch_name,omitempty"`        // "task/<uuid8>"
@@ -319,8 +494,15 @@ type Task struct {
 	SnapshotDiffs    map[string]string `json:"snapshot_diffs,omitempty"`     // repoPath → diff text (non-git workspaces only)
 	CommitMessage    string            `json:"commit_message,omitempty"`     // generated commit message from the commit pipeline
 	MountWorktrees   bool              `json:"mount_worktrees,omitempty"`
-	Model            string            `json:"model,omitempty"`          // deprecated: retained for migration compatibility
-	ModelOverride    *string           `json:"model_override,omitempty"` // per-task model override; nil means use global default
+	SkipCommit       bool              `json:"skip_commit,omitempty"`       // exploratory work: completion leaves changes in the worktree (see RetainsWorktree)
+	MergeMode        MergeMode         `json:"merge_mode,omitempty"`        // how the commit pipeline delivers the task branch; empty merges it
+	PullRequestURLs  map[string]string `json:"pull_request_urls,omitempty"` // host repoPath → pull request opened for the branch (MergeModePullRequest)
+	RequireApproval  bool              `json:"require_approval,omitempty"`  // the commit pipeline stops in review before rebase/merge until approved
+	ApprovedAt       *time.Time        `json:"approved_at,omitempty"`       // when the held merge was approved; cleared on retry
+	ApprovalGates    bool              `json:"approval_gates,omitempty"`    // the prompt tells the agent how to request approval before risky actions
+	InteractiveInput bool              `json:"interactive_input,omitempty"` // turns keep the agent's stdin open for approve/deny/text input (see runner.SendInput)
+	Model            string            `json:"model,omitempty"`             // deprecated: retained for migration compatibility
+	ModelOverride    *string           `json:"model_override,omitempty"`    // per-task model override; nil means use global default
 
 	// Test verification fields.
 	IsTestRun           bool   `json:"is_test_run,omitempty"`           // true while the task is running as a test verifier
@@ -338,6 +520,12 @@ type Task struct {
 	// Empty string and "task" are equivalent: a standard implementation task.
 	Kind TaskKind `json:"kind,omitempty"`
 
+	// ResearchDomains is the egress allowlist of a research task
+	// (Kind == TaskKindResearch): the only hosts, with their subdomains, the
+	// agent's proxy connects to. Advisory, since the agent shares the host
+	// network. Empty for every other kind.
+	ResearchDomains []string `json:"research_domains,omitempty"`
+
 	// FlowID is the slug of the flow this task runs against (see
 	// internal/flow). Empty means the runner falls back to the legacy
 	// Kind→Flow resolver so pre-flow-migration task records keep
@@ -360,6 +548,11 @@ type Task struct {
 	// just the task title for idea-tagged cards). Empty means use Prompt.
 	ExecutionPrompt string `json:"execution_prompt,omitempty"`
 
+	// PreambleVersion is the version of the board's prompt preamble that was
+	// prefixed to the task's latest fresh agent session. Zero means the
+	// session ran without a preamble.
+	PreambleVersion int `json:"preamble_version,omitempty"`
+
 	// DependsOn lists UUIDs of tasks that must all reach TaskStatusDone
 	// before this task is eligible for auto-promotion.
 	// Nil/empty means no dependencies (backward-compatible default).
@@ -370,6 +563,23 @@ type Task struct {
 	// capacity" (the existing default behaviour).
 	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
 
+	// Blocked marks a backlog task as blocked by the user, with a reason and
+	// an optional unblock date. Nil means not blocked. Cleared when the task
+	// leaves the backlog.
+	Blocked *TaskBlock `json:"blocked,omitempty"`
+
+	// Proxy overrides the global agent proxy settings for this task's agent
+	// processes. Nil means the global settings apply.
+	Proxy *TaskProxy `json:"proxy,omitempty"`
+
+	// SummaryLocale is the language tag (such as "zh-CN") this task's title
+	// and final summary are translated into, overriding
+	// WALLFACER_SUMMARY_LOCALE. Empty means the global setting applies.
+	SummaryLocale string `json:"summary_locale,omitempty"`
+	// Localized is the latest translation of the title and final summary.
+	// Nil until one has been generated.
+	Localized *LocalizedSummary `json:"localized,omitempty"`
+
 	// FailureCategory records the machine-readable root cause of the last
 	// failure transition. Set automatically by the runner at every
 	// TaskStatusFailed transition. Empty when the task has not failed.
@@ -399,6 +609,12 @@ type Task struct {
 	// LastFetchErrorAt is when the last fetch failure was recorded.
 	LastFetchErrorAt *time.Time `json:"last_fetch_error_at,omitempty"`
 
+	// StalledAt is when the stall watchdog flagged the running turn for
+	// printing no output and changing no file for WALLFACER_STALL_MINUTES.
+	// Cleared when the agent shows activity again or the turn ends. Nil
+	// when the task is not stalled.
+	StalledAt *time.Time `json:"stalled_at,omitempty"`
+
 	// Routine fields — only set when Kind == TaskKindRoutine. A routine card is
 	// a schedule template, not an executable task; the scheduler engine in
 	// internal/routine fires it at RoutineNextRun and spawns a fresh instance
@@ -407,6 +623,9 @@ type Task struct {
 	// RoutineIntervalSeconds is the fixed interval between scheduled fires.
 	// Zero means the routine is paused. Must be >= 60 when set via the API.
 	RoutineIntervalSeconds int `json:"routine_interval_seconds,omitempty"`
+	// RoutineCron is a cron expression (see routine.ParseCron) the routine
+	// fires on instead of its interval. Empty means the interval applies.
+	RoutineCron string `json:"routine_cron,omitempty"`
 	// RoutineEnabled toggles the schedule without discarding the interval, so
 	// users can pause and resume a routine without re-entering its config.
 	RoutineEnabled bool `json:"routine_enabled,omitempty"`
@@ -440,6 +659,197 @@ type Task struct {
 	ReviewUnresolved *int   `json:"review_unresolved,omitempty"`
 	ReviewHeadline   string `json:"review_headline,omitempty"`
 	ReviewSessionDir string `json:"review_session_dir,omitempty"`
+
+	// Estimate is the optional pre-run effort prediction set by
+	// POST /api/tasks/{id}/estimate. Nil when no estimate was requested.
+	Estimate *TaskEstimate `json:"estimate,omitempty"`
+
+	// StoryPoints is the team's size estimate for sprint planning, set via
+	// PATCH /api/tasks/{id}. Zero means unpointed; see (*Task).Points.
+	StoryPoints float64 `json:"story_points,omitempty"`
+	// Size is an optional T-shirt size for teams that size work coarsely
+	// instead of pointing it. Empty means unsized.
+	Size TaskSize `json:"size,omitempty"`
+
+	// Experiment links the task to the other arm of a dark-launch A/B run
+	// created by POST /api/tasks/{id}/experiment. Nil for ordinary tasks.
+	Experiment *Experiment `json:"experiment,omitempty"`
+
+	// ForkedFrom is the waiting task this one was forked from by
+	// POST /api/tasks/{id}/fork. Zero for tasks that were not forked.
+	ForkedFrom uuid.UUID `json:"forked_from,omitzero"`
+
+	// PublishRuns records the workspace's post-merge publish command, one
+	// run per merged repository. Empty when the workspace has no publish
+	// command or the task has not been merged.
+	PublishRuns []PublishRun `json:"publish_runs,omitempty"`
+
+	// Previews records the workspace's post-merge preview deploys, one per
+	// merged repository. Empty when the workspace has no preview provider
+	// or the task has not been merged.
+	Previews []PreviewDeploy `json:"previews,omitempty"`
+
+	// Links are external references (tickets, designs, docs, pull
+	// requests) set via PATCH /api/tasks/{id}; see TaskLink.
+	Links []TaskLink `json:"links,omitempty"`
+
+	// Attachments are files filed with the task through the intake
+	// endpoint or mailbox, stored as task blobs; see Attachment.
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// TrackerIssue is the Jira or Linear issue the task is mirrored to,
+	// set by the tracker sync; see TrackerIssue.
+	TrackerIssue *TrackerIssue `json:"tracker_issue,omitempty"`
+
+	// ContextFiles are workspace files and directories set via
+	// PATCH /api/tasks/{id} whose contents are injected into the first
+	// prompt as a context pack, within a token budget.
+	ContextFiles []string `json:"context_files,omitempty"`
+
+	// Approvals are the approval requests the agent ended a turn with,
+	// oldest first; see ApprovalRequest. The last one is pending until
+	// POST /api/tasks/{id}/approvals/{n} decides it.
+	Approvals []ApprovalRequest `json:"approvals,omitempty"`
+}
+
+// ApprovalDecision is a user's answer to an agent's approval request.
+type ApprovalDecision string
+
+// ApprovalDecision constants. An undecided request has an empty decision.
+const (
+	ApprovalApproved ApprovalDecision = "approved"
+	ApprovalDenied   ApprovalDecision = "denied"
+)
+
+// ApprovalRequest is an action the agent asked the user to approve before
+// taking it, such as running a database migration. The agent ends its turn
+// with the request; the task waits until the request is decided and the
+// decision is sent back into the session.
+type ApprovalRequest struct {
+	// Seq numbers the task's requests from 1.
+	Seq         int              `json:"seq"`
+	Turn        int              `json:"turn"`
+	Action      string           `json:"action"`
+	Reason      string           `json:"reason,omitempty"`
+	Command     string           `json:"command,omitempty"`
+	RequestedAt time.Time        `json:"requested_at"`
+	Decision    ApprovalDecision `json:"decision,omitempty"`
+	// Note is the user's optional comment, passed to the agent with the
+	// decision.
+	Note      string    `json:"note,omitempty"`
+	DecidedAt time.Time `json:"decided_at,omitzero"`
+}
+
+// PublishStatus is the state of a post-merge publish run.
+type PublishStatus string
+
+// PublishStatus constants.
+const (
+	PublishStatusRunning   PublishStatus = "running"
+	PublishStatusSucceeded PublishStatus = "succeeded"
+	PublishStatusFailed    PublishStatus = "failed"
+)
+
+// PublishRun is one run of a workspace's publish command against the merge
+// commit of one repository.
+type PublishRun struct {
+	Repo       string        `json:"repo"`
+	Commit     string        `json:"commit"`
+	Command    string        `json:"command"`
+	Status     PublishStatus `json:"status"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at,omitzero"`
+	// Artifacts are the references the command reported (image tags, URLs,
+	// file paths), one per line written to $WALLFACER_ARTIFACTS.
+	Artifacts []string `json:"artifacts,omitempty"`
+	// Log is the tail of the command's combined output.
+	Log   string `json:"log,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// PreviewDeploy is one deploy of a merge commit to a preview environment.
+// Status reuses the publish run states.
+type PreviewDeploy struct {
+	Repo       string        `json:"repo"`
+	Commit     string        `json:"commit"`
+	Provider   string        `json:"provider"`
+	Status     PublishStatus `json:"status"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at,omitzero"`
+	// URL is the live preview, set once the deploy succeeds.
+	URL string `json:"url,omitempty"`
+	// Log is the tail of the deploy's combined output.
+	Log   string `json:"log,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ExperimentRole identifies which arm of an experiment a task is.
+type ExperimentRole string
+
+// ExperimentRole constants.
+const (
+	// ExperimentRolePrimary is the arm that is reviewed and merged normally.
+	ExperimentRolePrimary ExperimentRole = "primary"
+	// ExperimentRoleShadow runs alongside the primary with a different agent
+	// profile or instructions; its changes are measured and then discarded.
+	ExperimentRoleShadow ExperimentRole = "shadow"
+)
+
+// Experiment records a task's part in a dark-launch experiment.
+type Experiment struct {
+	Role ExperimentRole `json:"role"`
+	// PeerID is the task ID of the other arm.
+	PeerID uuid.UUID `json:"peer_id"`
+	// Label names the arm's variant in comparisons (e.g. "baseline").
+	Label string `json:"label,omitempty"`
+	// Instructions are the extra instructions this arm's agent received on
+	// top of the shared prompt. Empty for an arm that differs only in
+	// agent profile.
+	Instructions string `json:"instructions,omitempty"`
+	// Diff is the size of the arm's changes, measured against the base
+	// branch when its agent finished and before any merge. Zero until then.
+	Diff ExperimentDiff `json:"diff,omitzero"`
+}
+
+// ExperimentDiff summarizes the size of an experiment arm's changes.
+type ExperimentDiff struct {
+	Files      int       `json:"files"`
+	Added      int       `json:"added"`
+	Deleted    int       `json:"deleted"`
+	MeasuredAt time.Time `json:"measured_at"`
+}
+
+// IsShadow reports whether the task is the shadow arm of an experiment,
+// which must never be merged.
+func (t *Task) IsShadow() bool {
+	return t.Experiment != nil && t.Experiment.Role == ExperimentRoleShadow
+}
+
+// RetainsWorktree reports whether the task is done with its changes left
+// uncommitted in its worktree (see SkipCommit), which worktree cleanup must
+// then leave in place.
+func (t *Task) RetainsWorktree() bool {
+	return t.SkipCommit && t.Status == TaskStatusDone && !t.Archived
+}
+
+// PendingApproval returns the approval request the task is waiting on, or
+// nil when there is none: the last request, if the agent raised it on the
+// task's latest turn and it is still undecided. A request passed over by
+// plain feedback is no longer pending once the session moves on.
+// Automation leaves a task with a pending request alone.
+func (t *Task) PendingApproval() *ApprovalRequest {
+	if t.Status != TaskStatusWaiting {
+		return nil
+	}
+	if n := len(t.Approvals); n > 0 && t.Approvals[n-1].Decision == "" && t.Approvals[n-1].Turn == t.Turns {
+		return &t.Approvals[n-1]
+	}
+	return nil
+}
+
+// IsBlocked reports whether the user has marked the task as blocked.
+func (t *Task) IsBlocked() bool {
+	return t.Blocked != nil
 }
 
 // IsAutoRetryEligible reports whether task t is eligible for an automatic retry
@@ -452,6 +862,22 @@ func IsAutoRetryEligible(t Task, category FailureCategory) bool {
 	return t.AutoRetryBudget[category] > 0 && t.AutoRetryCount < constants.MaxAutoRetries
 }
 
+// EffortActuals returns the measured counterparts of a TaskEstimate: turns,
+// input plus output tokens, and execution minutes (StartedAt to the last
+// update). When summary is non-nil its frozen execution duration is used
+// instead, since UpdatedAt keeps moving after completion (archive, tags).
+func (t *Task) EffortActuals(summary *TaskSummary) (turns, tokens int, minutes float64) {
+	turns = t.Turns
+	tokens = t.Usage.InputTokens + t.Usage.OutputTokens
+	switch {
+	case summary != nil:
+		minutes = summary.ExecutionDurationSeconds / 60
+	case t.StartedAt != nil:
+		minutes = t.UpdatedAt.Sub(*t.StartedAt).Minutes()
+	}
+	return turns, tokens, minutes
+}
+
 // HasTag reports whether the task has the given tag.
 func (t *Task) HasTag(tag string) bool {
 	return slices.Contains(t.Tags, tag)
@@ -480,6 +906,22 @@ func cloneRefinementSessionSlice(src []RefinementSession) []RefinementSession {
 	return dst
 }
 
+// clonePublishRunSlice deep-copies a []PublishRun, duplicating each
+// element's Artifacts slice. It is called by the generated deepCloneTask
+// function.
+func clonePublishRunSlice(src []PublishRun) []PublishRun {
+	if src == nil {
+		return nil
+	}
+
+	dst := make([]PublishRun, len(src))
+	for i := range src {
+		dst[i] = src[i]
+		dst[i].Artifacts = slices.Clone(src[i].Artifacts)
+	}
+	return dst
+}
+
 // Tombstone records when and why a task was soft-deleted.
 // A tombstone.json file in the task directory marks the task as deleted but
 // retains all data on disk until the retention period expires.
@@ -559,6 +1001,15 @@ const (
 	EventTypeSpanEnd           EventType = "span_end"
 	EventTypePromptRound       EventType = "prompt_round"
 	EventTypePromptRoundRevert EventType = "prompt_round_revert"
+	EventTypeComment           EventType = "comment"
+	EventTypeNeedsApproval     EventType = "needs_approval"    // data: ApprovalRequest
+	EventTypePipelineProgress  EventType = "pipeline_progress" // data: PipelineProgressData
+	EventTypeInputRequest      EventType = "input_request"     // data: InputRequestData
+	EventTypeInput             EventType = "input"             // data: InputData
+	EventTypeStalled           EventType = "stalled"           // data: StalledData
+	EventTypeActivity          EventType = "activity"          // data: ActivityData
+	EventTypeTimeoutExtended   EventType = "timeout_extended"  // data: TimeoutExtendedData
+	EventTypeConflictResolved  EventType = "conflict_resolved" // data: ConflictResolvedData
 )
 
 // Trigger identifies what caused a state_change event. Used in the Data payload
@@ -631,6 +1082,140 @@ func NewPromptRoundRevertEvent(threadID string, revertedRound int) PromptRoundRe
 	}
 }
 
+// PipelinePhase is one step of the commit pipeline, as reported by
+// pipeline_progress events.
+type PipelinePhase string
+
+// PipelinePhase constants, in pipeline order. The merge_queue, rebase,
+// resolve, and merge phases repeat for each repository; a task in
+// pull-request merge mode reports pull_request for each repository
+// instead.
+const (
+	PipelinePhaseStage       PipelinePhase = "stage"        // host-side git add + commit in each worktree
+	PipelinePhaseLint        PipelinePhase = "lint"         // optional pre-merge formatters and linters
+	PipelinePhaseMergeQueue  PipelinePhase = "merge_queue"  // waiting for the repository's merge lock
+	PipelinePhaseRebase      PipelinePhase = "rebase"       // rebasing onto the default branch
+	PipelinePhaseResolve     PipelinePhase = "resolve"      // conflict resolver agent after a failed rebase
+	PipelinePhaseMerge       PipelinePhase = "merge"        // fast-forward merge, or snapshot extraction
+	PipelinePhasePullRequest PipelinePhase = "pull_request" // pushing the branch and opening a pull request instead of merging
+	PipelinePhaseCleanup     PipelinePhase = "cleanup"      // persisting hashes and removing worktrees
+	PipelinePhaseDone        PipelinePhase = "done"
+)
+
+// PipelineProgressData is the payload for EventTypePipelineProgress events:
+// the commit pipeline entered Phase. Repo and Attempt are set for the
+// per-repository phases; Attempt counts rebase attempts from 1. Percent
+// (0-100) only grows within one pipeline run, so it can drive a progress
+// bar directly.
+type PipelineProgressData struct {
+	Phase   PipelinePhase `json:"phase"`
+	Repo    string        `json:"repo,omitempty"`
+	Attempt int           `json:"attempt,omitempty"`
+	Percent int           `json:"percent"`
+	Message string        `json:"message"`
+}
+
+// InputAction is what the user answers an interactive agent with.
+type InputAction string
+
+// InputAction values. Approve and deny send the conventional "y" and "n";
+// text sends one line of free text.
+const (
+	InputApprove InputAction = "approve"
+	InputDeny    InputAction = "deny"
+	InputText    InputAction = "text"
+)
+
+// InputRequestData is the payload for EventTypeInputRequest events: a line
+// the agent process printed that looks like it waits for an answer on
+// stdin. Only tasks with InteractiveInput record them.
+type InputRequestData struct {
+	Prompt string `json:"prompt"`
+}
+
+// InputData is the payload for EventTypeInput events: input the user sent
+// to a running interactive agent.
+type InputData struct {
+	Action InputAction `json:"action"`
+	Text   string      `json:"text,omitempty"`
+}
+
+// StalledData is the payload for EventTypeStalled events: the stall
+// watchdog saw no output and no file change from a running turn for
+// IdleSeconds. Restarted reports whether the watchdog killed the turn to
+// run it again (WALLFACER_STALL_RESTART).
+type StalledData struct {
+	IdleSeconds    int       `json:"idle_seconds"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	Restarted      bool      `json:"restarted,omitempty"`
+}
+
+// TimeoutExtendedData is the payload for EventTypeTimeoutExtended events:
+// the task reached its deadline while still making progress, so the
+// adaptive timeout (WALLFACER_TIMEOUT_EXTEND_MINUTES) moved the deadline
+// to Deadline. TotalMinutes is the task's extension so far, this one
+// included.
+type TimeoutExtendedData struct {
+	Minutes        int       `json:"minutes"`
+	TotalMinutes   int       `json:"total_minutes"`
+	Deadline       time.Time `json:"deadline"`
+	LastProgressAt time.Time `json:"last_progress_at"`
+}
+
+// ConflictResolvedData is the payload for EventTypeConflictResolved events:
+// how the conflict resolver settled each conflicted file of Repo, so its
+// decisions can be audited after the rebase has rewritten the branch.
+type ConflictResolvedData struct {
+	Repo    string `json:"repo"`
+	Trigger string `json:"trigger"`
+	Attempt int    `json:"attempt"`
+	Harness string `json:"harness,omitempty"`
+	Model   string `json:"model,omitempty"`
+	// CostUSD is what this resolver run cost.
+	CostUSD float64                  `json:"cost_usd"`
+	Files   []ConflictFileResolution `json:"files"`
+}
+
+// ConflictResolution classifies the content the resolver left in a
+// conflicted file.
+type ConflictResolution string
+
+// ConflictResolution values.
+const (
+	ConflictResolutionTask     ConflictResolution = "task"     // the task branch's version, upstream changes dropped
+	ConflictResolutionUpstream ConflictResolution = "upstream" // the upstream version, task changes dropped
+	ConflictResolutionMerged   ConflictResolution = "merged"   // a combination of both sides
+	ConflictResolutionDeleted  ConflictResolution = "deleted"  // the file was removed
+)
+
+// ConflictFileResolution is one file of a ConflictResolvedData. Diff is
+// the resolved file against the upstream version, truncated, so it shows
+// what the resolver kept of the task's changes.
+type ConflictFileResolution struct {
+	Path       string             `json:"path"`
+	Resolution ConflictResolution `json:"resolution"`
+	Diff       string             `json:"diff,omitempty"`
+}
+
+// ActivityKind is the kind of step an activity event records.
+type ActivityKind string
+
+// ActivityKind values.
+const (
+	ActivityTool ActivityKind = "tool" // the agent started a tool call
+	ActivityText ActivityKind = "text" // the agent wrote text
+)
+
+// ActivityData is the payload for EventTypeActivity events: one step of a
+// running turn, recorded as the agent's stream-json output arrives rather
+// than when the turn ends. Tool names the tool of an ActivityTool step;
+// Text is the tool's main argument or the written text, truncated.
+type ActivityData struct {
+	Kind ActivityKind `json:"kind"`
+	Tool string       `json:"tool,omitempty"`
+	Text string       `json:"text,omitempty"`
+}
+
 // SpanData holds metadata for a span_start or span_end event.
 // Phase identifies the execution phase (e.g. "worktree_setup", "agent_turn",
 // "container_run", "commit"). Label allows differentiating multiple spans of
@@ -661,4 +1246,11 @@ type TaskEvent struct {
 	// runner / a background goroutine with no request context), or
 	// empty (legacy / anonymous local deployment).
 	ActorType string `json:"actor_type,omitempty"`
+
+	// Count and FirstID are set only on an event returned by CoalesceEvents
+	// that stands for a run of identical events: Count is the length of the
+	// run and FirstID the ID of its first event. ID and CreatedAt are those
+	// of the last. Stored events never carry them.
+	Count   int   `json:"count,omitempty"`
+	FirstID int64 `json:"first_id,omitempty"`
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"latere.ai/x/wallfacer/internal/pkg/statemachine"
 )
@@ -13,6 +14,7 @@ var allStatuses = []TaskStatus{
 	TaskStatusBacklog,
 	TaskStatusInProgress,
 	TaskStatusCommitting,
+	TaskStatusReview,
 	TaskStatusWaiting,
 	TaskStatusDone,
 	TaskStatusFailed,
@@ -30,6 +32,10 @@ func TestTaskMachine_Validate(t *testing.T) {
 		{TaskStatusInProgress, TaskStatusCancelled},
 		{TaskStatusCommitting, TaskStatusDone},
 		{TaskStatusCommitting, TaskStatusFailed},
+		{TaskStatusCommitting, TaskStatusReview},
+		{TaskStatusReview, TaskStatusCommitting},
+		{TaskStatusReview, TaskStatusWaiting},
+		{TaskStatusReview, TaskStatusCancelled},
 		{TaskStatusWaiting, TaskStatusInProgress},
 		{TaskStatusWaiting, TaskStatusCommitting},
 		{TaskStatusWaiting, TaskStatusCancelled},
@@ -64,6 +70,7 @@ func TestTaskMachine_Validate(t *testing.T) {
 		{TaskStatusInProgress, TaskStatusDone},
 		{TaskStatusWaiting, TaskStatusDone},
 		{TaskStatusCommitting, TaskStatusBacklog},
+		{TaskStatusReview, TaskStatusDone},
 		{TaskStatusDone, TaskStatusBacklog},
 		{TaskStatusCancelled, TaskStatusDone},
 	}
@@ -108,7 +115,8 @@ func TestTaskStatus_AllowedTransitions(t *testing.T) {
 	}{
 		{TaskStatusBacklog, []TaskStatus{TaskStatusInProgress}},
 		{TaskStatusInProgress, []TaskStatus{TaskStatusBacklog, TaskStatusWaiting, TaskStatusFailed, TaskStatusCancelled}},
-		{TaskStatusCommitting, []TaskStatus{TaskStatusDone, TaskStatusFailed}},
+		{TaskStatusCommitting, []TaskStatus{TaskStatusDone, TaskStatusFailed, TaskStatusReview}},
+		{TaskStatusReview, []TaskStatus{TaskStatusCommitting, TaskStatusWaiting, TaskStatusCancelled}},
 		{TaskStatusWaiting, []TaskStatus{TaskStatusInProgress, TaskStatusCommitting, TaskStatusCancelled}},
 		{TaskStatusFailed, []TaskStatus{TaskStatusBacklog, TaskStatusCancelled}},
 		{TaskStatusDone, []TaskStatus{TaskStatusCancelled}},
@@ -156,6 +164,33 @@ func TestTask_HasTag(t *testing.T) {
 // TestTaskBudgetFieldsRoundTrip verifies that MaxCostUSD and MaxInputTokens
 // survive JSON marshal→unmarshal with correct values, and that zero values are
 // omitted (omitempty) for backwards compatibility with existing task files.
+func TestTask_EffortActuals(t *testing.T) {
+	started := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	task := Task{
+		Turns:     4,
+		Usage:     TaskUsage{InputTokens: 1000, OutputTokens: 200, CacheReadInputTokens: 5000},
+		StartedAt: &started,
+		UpdatedAt: started.Add(30 * time.Minute),
+	}
+
+	turns, tokens, minutes := task.EffortActuals(nil)
+	if turns != 4 || tokens != 1200 || minutes != 30 {
+		t.Errorf("EffortActuals(nil) = (%d, %d, %v), want (4, 1200, 30)", turns, tokens, minutes)
+	}
+
+	// The frozen summary duration wins over a later UpdatedAt.
+	_, _, minutes = task.EffortActuals(&TaskSummary{ExecutionDurationSeconds: 600})
+	if minutes != 10 {
+		t.Errorf("minutes with summary = %v, want 10", minutes)
+	}
+
+	// A task that never started has no measured duration.
+	_, _, minutes = (&Task{}).EffortActuals(nil)
+	if minutes != 0 {
+		t.Errorf("minutes without StartedAt = %v, want 0", minutes)
+	}
+}
+
 func TestTaskBudgetFieldsRoundTrip(t *testing.T) {
 	original := Task{
 		MaxCostUSD:     1.5,
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+)
+
+// preambleFile is the workspace-level file, directly under the store's data
+// directory, that holds every saved version of the board's prompt preamble.
+const preambleFile = "preamble.json"
+
+// MaxPreambleBytes caps the size of a prompt preamble. It is prefixed to every
+// fresh agent prompt on the board, so it is kept well below a prompt's budget.
+const MaxPreambleBytes = 16 << 10
+
+// Preamble is one saved version of the board's prompt preamble: standing
+// instructions (coding conventions, testing rules, tone) prefixed to every
+// fresh agent prompt. Versions are numbered from 1 and never rewritten, so a
+// task's PreambleVersion always resolves to the text it ran with. An empty
+// Text is a valid version that turns the preamble off.
+type Preamble struct {
+	Version   int       `json:"version"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CurrentPreamble returns the newest preamble version, or false when none has
+// been saved.
+func (s *Store) CurrentPreamble() (Preamble, bool) {
+	s.preambleMu.Lock()
+	defer s.preambleMu.Unlock()
+	s.loadPreamblesLocked()
+	if len(s.preambles) == 0 {
+		return Preamble{}, false
+	}
+	return s.preambles[len(s.preambles)-1], true
+}
+
+// PreambleVersion returns the preamble with the given version number, or
+// false when no such version exists.
+func (s *Store) PreambleVersion(version int) (Preamble, bool) {
+	s.preambleMu.Lock()
+	defer s.preambleMu.Unlock()
+	s.loadPreamblesLocked()
+	i, ok := slices.BinarySearchFunc(s.preambles, version, func(p Preamble, v int) int { return p.Version - v })
+	if !ok {
+		return Preamble{}, false
+	}
+	return s.preambles[i], true
+}
+
+// PreambleHistory returns every saved preamble version, newest first.
+func (s *Store) PreambleHistory() []Preamble {
+	s.preambleMu.Lock()
+	defer s.preambleMu.Unlock()
+	s.loadPreamblesLocked()
+	out := slices.Clone(s.preambles)
+	slices.Reverse(out)
+	return out
+}
+
+// SetPreamble saves text as a new preamble version and returns it. Leading
+// and trailing whitespace is trimmed; text equal to the current version's
+// returns that version without creating a new one. Stores without a data
+// directory keep the history in memory only.
+func (s *Store) SetPreamble(text string) (Preamble, error) {
+	text = strings.TrimSpace(text)
+	s.preambleMu.Lock()
+	defer s.preambleMu.Unlock()
+	s.loadPreamblesLocked()
+	if n := len(s.preambles); n > 0 && s.preambles[n-1].Text == text {
+		return s.preambles[n-1], nil
+	}
+	p := Preamble{Version: 1, Text: text, CreatedAt: time.Now().UTC()}
+	if n := len(s.preambles); n > 0 {
+		p.Version = s.preambles[n-1].Version + 1
+	}
+	next := append(slices.Clip(s.preambles), p)
+	if s.dir != "" {
+		if err := atomicfile.WriteJSON(filepath.Join(s.dir, preambleFile), next, 0644); err != nil {
+			return Preamble{}, err
+		}
+	}
+	s.preambles = next
+	return p, nil
+}
+
+// loadPreamblesLocked reads the saved versions on first use. A missing file
+// starts an empty history; an unreadable one is logged and also starts empty,
+// so the board runs without a preamble rather than failing. Must be called
+// with s.preambleMu held.
+func (s *Store) loadPreamblesLocked() {
+	if s.preamblesLoaded {
+		return
+	}
+	s.preamblesLoaded = true
+	if s.dir == "" {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, preambleFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Store.Warn("read preamble", "error", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &s.preambles); err != nil {
+		logger.Store.Warn("parse preamble", "error", err)
+		s.preambles = nil
+		return
+	}
+	slices.SortFunc(s.preambles, func(a, b Preamble) int { return a.Version - b.Version })
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreamble_VersionsPersistAcrossReload(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.CurrentPreamble(); ok {
+		t.Fatal("empty store has a preamble")
+	}
+
+	first, err := s.SetPreamble("  Always add tests.\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Version != 1 || first.Text != "Always add tests." || first.CreatedAt.IsZero() {
+		t.Fatalf("first version = %+v", first)
+	}
+	// Saving the same text again does not create a version.
+	if same, err := s.SetPreamble("Always add tests."); err != nil || same.Version != 1 {
+		t.Fatalf("unchanged save = %+v, %v; want version 1", same, err)
+	}
+	if second, err := s.SetPreamble("Use gofmt."); err != nil || second.Version != 2 {
+		t.Fatalf("second save = %+v, %v; want version 2", second, err)
+	}
+
+	reloaded, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur, ok := reloaded.CurrentPreamble(); !ok || cur.Version != 2 || cur.Text != "Use gofmt." {
+		t.Fatalf("reloaded current = %+v, %v", cur, ok)
+	}
+	if old, ok := reloaded.PreambleVersion(1); !ok || old.Text != "Always add tests." {
+		t.Errorf("version 1 = %+v, %v", old, ok)
+	}
+	if _, ok := reloaded.PreambleVersion(3); ok {
+		t.Error("unknown version resolved")
+	}
+	if h := reloaded.PreambleHistory(); len(h) != 2 || h[0].Version != 2 || h[1].Version != 1 {
+		t.Errorf("history = %+v, want versions 2, 1", h)
+	}
+	if len(reloaded.tasks) != 0 {
+		t.Errorf("preamble file loaded as %d tasks", len(reloaded.tasks))
+	}
+}
+
+func TestPreamble_EmptyTextIsAVersion(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.SetPreamble("Be terse."); err != nil {
+		t.Fatal(err)
+	}
+	cleared, err := s.SetPreamble("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cleared.Version != 2 || cleared.Text != "" {
+		t.Fatalf("cleared = %+v, want an empty version 2", cleared)
+	}
+}
+
+func TestPreamble_CorruptFileStartsEmpty(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, preambleFile), []byte("["), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.CurrentPreamble(); ok {
+		t.Fatal("corrupt file produced a preamble")
+	}
+	if p, err := s.SetPreamble("x"); err != nil || p.Version != 1 {
+		t.Fatalf("SetPreamble after corrupt file = %+v, %v", p, err)
+	}
+}
+
+func TestUpdateTaskPreambleVersion(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskPreambleVersion(bg(), task.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetTask(bg(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PreambleVersion != 3 {
+		t.Errorf("PreambleVersion = %d, want 3", got.PreambleVersion)
+	}
+}
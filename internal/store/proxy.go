@@ -0,0 +1,74 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// TaskProxy overrides, for one task, the HTTP proxy its agent processes use
+// (WALLFACER_AGENT_HTTP_PROXY and friends). A set field replaces the global
+// value and an empty one keeps it. Direct drops every proxy, the global one
+// included, for tasks whose agent must reach the network directly.
+type TaskProxy struct {
+	HTTPProxy  string `json:"http_proxy,omitempty"`
+	HTTPSProxy string `json:"https_proxy,omitempty"`
+	NoProxy    string `json:"no_proxy,omitempty"`
+	Direct     bool   `json:"direct,omitempty"`
+}
+
+// IsZero reports whether p overrides nothing.
+func (p TaskProxy) IsZero() bool {
+	return p == TaskProxy{}
+}
+
+// NormalizeTaskProxy validates p and returns it with its fields trimmed, or
+// nil when it overrides nothing. Proxy URLs must be absolute http, https,
+// socks5, or socks5h URLs, and Direct excludes them.
+func NormalizeTaskProxy(p *TaskProxy) (*TaskProxy, error) {
+	if p == nil {
+		return nil, nil
+	}
+	out := TaskProxy{
+		HTTPProxy:  strings.TrimSpace(p.HTTPProxy),
+		HTTPSProxy: strings.TrimSpace(p.HTTPSProxy),
+		NoProxy:    strings.TrimSpace(p.NoProxy),
+		Direct:     p.Direct,
+	}
+	if out.IsZero() {
+		return nil, nil
+	}
+	if out.Direct && (out.HTTPProxy != "" || out.HTTPSProxy != "") {
+		return nil, errors.New("direct cannot be combined with a proxy URL")
+	}
+	for _, f := range []struct{ name, raw string }{
+		{"http_proxy", out.HTTPProxy},
+		{"https_proxy", out.HTTPSProxy},
+	} {
+		if f.raw == "" {
+			continue
+		}
+		if err := ValidateProxyURL(f.raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	if strings.ContainsAny(out.NoProxy, " \t\n") {
+		return nil, errors.New("no_proxy must be a comma-separated list without spaces")
+	}
+	return &out, nil
+}
+
+// ValidateProxyURL reports whether raw is an absolute proxy URL with an
+// http, https, socks5, or socks5h scheme and a host.
+func ValidateProxyURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("must be an absolute proxy URL (got %q)", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return nil
+	}
+	return fmt.Errorf("scheme must be http, https, socks5, or socks5h (got %q)", u.Scheme)
+}
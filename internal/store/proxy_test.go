@@ -0,0 +1,32 @@
+package store
+
+import "testing"
+
+func TestNormalizeTaskProxy(t *testing.T) {
+	got, err := NormalizeTaskProxy(&TaskProxy{HTTPProxy: " http://127.0.0.1:7890 ", NoProxy: "internal.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.HTTPProxy != "http://127.0.0.1:7890" || got.NoProxy != "internal.example.com" {
+		t.Errorf("got %+v", got)
+	}
+
+	if got, err := NormalizeTaskProxy(&TaskProxy{HTTPProxy: "  "}); err != nil || got != nil {
+		t.Errorf("blank override = %+v, %v; want nil", got, err)
+	}
+	if got, err := NormalizeTaskProxy(&TaskProxy{Direct: true}); err != nil || got == nil || !got.Direct {
+		t.Errorf("direct = %+v, %v", got, err)
+	}
+
+	for name, p := range map[string]TaskProxy{
+		"direct with url": {Direct: true, HTTPSProxy: "http://proxy:3128"},
+		"relative url":    {HTTPProxy: "proxy:3128"},
+		"ftp scheme":      {HTTPSProxy: "ftp://proxy:21"},
+		"spaced no_proxy": {NoProxy: "a.example.com, b.example.com"},
+		"missing host":    {HTTPProxy: "http://"},
+	} {
+		if _, err := NormalizeTaskProxy(&p); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package store
+
+import "time"
+
+// QueuedSince returns when t joined the auto-promotion queue: the latest of
+// its creation, its most recent retry (which returns it to the backlog), and
+// its scheduled time once that has passed. A task scheduled for the future is
+// not queued yet and reports its scheduled time.
+func QueuedSince(t *Task) time.Time {
+	since := t.CreatedAt
+	if n := len(t.RetryHistory); n > 0 && t.RetryHistory[n-1].RetiredAt.After(since) {
+		since = t.RetryHistory[n-1].RetiredAt
+	}
+	if t.ScheduledAt != nil && t.ScheduledAt.After(since) {
+		since = *t.ScheduledAt
+	}
+	return since
+}
+
+// AgingBoost returns the effective-priority points a backlog task has earned
+// by waiting: one for every full interval it has been queued. Added to the
+// critical-path score, it lets a long-waiting task eventually overtake newer
+// high-priority work instead of starving. A non-positive interval disables
+// aging.
+func AgingBoost(waited, interval time.Duration) int {
+	if interval <= 0 || waited <= 0 {
+		return 0
+	}
+	return int(waited / interval)
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueuedSince(t *testing.T) {
+	created := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	task := &Task{CreatedAt: created}
+	if got := QueuedSince(task); !got.Equal(created) {
+		t.Errorf("new task queued since %v, want creation %v", got, created)
+	}
+
+	retried := created.Add(2 * time.Hour)
+	task.RetryHistory = []RetryRecord{{Attempt: 1, RetiredAt: retried}}
+	if got := QueuedSince(task); !got.Equal(retried) {
+		t.Errorf("retried task queued since %v, want retry %v", got, retried)
+	}
+
+	due := created.Add(5 * time.Hour)
+	task.ScheduledAt = &due
+	if got := QueuedSince(task); !got.Equal(due) {
+		t.Errorf("scheduled task queued since %v, want scheduled time %v", got, due)
+	}
+
+	// A schedule that predates the last retry does not move the clock back.
+	early := created.Add(time.Hour)
+	task.ScheduledAt = &early
+	if got := QueuedSince(task); !got.Equal(retried) {
+		t.Errorf("queued since %v, want retry %v", got, retried)
+	}
+}
+
+func TestAgingBoost(t *testing.T) {
+	for _, tc := range []struct {
+		waited, interval time.Duration
+		want             int
+	}{
+		{29 * time.Minute, 30 * time.Minute, 0},
+		{30 * time.Minute, 30 * time.Minute, 1},
+		{95 * time.Minute, 30 * time.Minute, 3},
+		{10 * time.Hour, 0, 0},
+		{-time.Minute, 30 * time.Minute, 0},
+	} {
+		if got := AgingBoost(tc.waited, tc.interval); got != tc.want {
+			t.Errorf("AgingBoost(%v, %v) = %d, want %d", tc.waited, tc.interval, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCreateTaskWithOptions_ResearchKind_PersistsDomainsAndClampsTimeout(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{
+		Prompt:          "survey the docs",
+		Timeout:         120,
+		Kind:            TaskKindResearch,
+		ResearchDomains: []string{"go.dev", "pkg.go.dev"},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if task.Timeout != MaxResearchTimeoutMinutes {
+		t.Fatalf("timeout = %d, want %d", task.Timeout, MaxResearchTimeoutMinutes)
+	}
+
+	got, err := s.GetTask(bg(), task.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Kind != TaskKindResearch || !slices.Equal(got.ResearchDomains, []string{"go.dev", "pkg.go.dev"}) {
+		t.Fatalf("round-trip lost research fields: kind=%q domains=%v", got.Kind, got.ResearchDomains)
+	}
+}
+
+func TestCreateTaskWithOptions_ResearchKind_KeepsShorterTimeout(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{
+		Prompt:          "quick lookup",
+		Timeout:         10,
+		Kind:            TaskKindResearch,
+		ResearchDomains: []string{"go.dev"},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if task.Timeout != 10 {
+		t.Fatalf("timeout = %d, want 10", task.Timeout)
+	}
+}
+
+func TestCreateTaskWithOptions_NonResearchIgnoresDomains(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{
+		Prompt:          "normal task",
+		Timeout:         120,
+		ResearchDomains: []string{"go.dev"},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(task.ResearchDomains) != 0 || task.Timeout != 120 {
+		t.Fatalf("non-research task got research fields: domains=%v timeout=%d", task.ResearchDomains, task.Timeout)
+	}
+}
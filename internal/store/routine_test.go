@@ -91,6 +91,34 @@ func TestUpdateRoutineSchedule_PersistsAndClamps(t *testing.T) {
 	}
 }
 
+func TestUpdateRoutineCron(t *testing.T) {
+	s := newTestStore(t)
+	task := mkRoutine(t, s)
+
+	if err := s.UpdateRoutineCron(bg(), task.ID, " 0 2 * * * "); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if got.RoutineCron != "0 2 * * *" || got.RoutineIntervalSeconds != 3600 {
+		t.Fatalf("cron, interval = %q, %d; want the trimmed spec and the kept interval", got.RoutineCron, got.RoutineIntervalSeconds)
+	}
+	if err := s.UpdateRoutineCron(bg(), task.ID, ""); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	got, _ = s.GetTask(bg(), task.ID)
+	if got.RoutineCron != "" {
+		t.Fatalf("cron = %q after clearing", got.RoutineCron)
+	}
+
+	plain, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5, RoutineCron: "@daily"})
+	if plain.RoutineCron != "" {
+		t.Fatalf("non-routine task kept a cron spec")
+	}
+	if err := s.UpdateRoutineCron(bg(), plain.ID, "@daily"); err == nil {
+		t.Fatal("UpdateRoutineCron on a plain task succeeded")
+	}
+}
+
 func TestUpdateRoutineEnabled_TogglesAndClearsNextRun(t *testing.T) {
 	s := newTestStore(t)
 	task := mkRoutine(t, s)
@@ -210,7 +238,7 @@ func TestRoutineFields_OmitEmptyForNonRoutineTask(t *testing.T) {
 		t.Fatalf("marshal: %v", err)
 	}
 	encoded := string(raw)
-	for _, needle := range []string{"routine_interval", "routine_enabled", "routine_next_run", "routine_last_fired", "routine_spawn_kind"} {
+	for _, needle := range []string{"routine_interval", "routine_cron", "routine_enabled", "routine_next_run", "routine_last_fired", "routine_spawn_kind"} {
 		if strings.Contains(encoded, needle) {
 			t.Fatalf("non-routine JSON leaked %q: %s", needle, encoded)
 		}
@@ -0,0 +1,50 @@
+package store
+
+import "strings"
+
+// TaskSize is a T-shirt size estimate of a task.
+type TaskSize string
+
+// TaskSize values, smallest first.
+const (
+	TaskSizeXS TaskSize = "xs"
+	TaskSizeS  TaskSize = "s"
+	TaskSizeM  TaskSize = "m"
+	TaskSizeL  TaskSize = "l"
+	TaskSizeXL TaskSize = "xl"
+)
+
+// sizePoints maps each size to the story points it stands for when a task
+// has a size but no explicit points, following the Fibonacci scale most
+// teams point with.
+var sizePoints = map[TaskSize]float64{
+	TaskSizeXS: 1,
+	TaskSizeS:  2,
+	TaskSizeM:  3,
+	TaskSizeL:  5,
+	TaskSizeXL: 8,
+}
+
+// MaxStoryPoints bounds the points a single task may carry, catching typos
+// like 300 for 3 before they dominate velocity.
+const MaxStoryPoints = 100
+
+// ParseTaskSize normalises s to a TaskSize. The empty string is valid and
+// means unsized.
+func ParseTaskSize(s string) (TaskSize, bool) {
+	size := TaskSize(strings.ToLower(strings.TrimSpace(s)))
+	if size == "" {
+		return "", true
+	}
+	_, ok := sizePoints[size]
+	return size, ok
+}
+
+// Points returns the task's story points: StoryPoints when set, otherwise
+// the points its Size stands for, otherwise zero (unpointed).
+func (t *Task) Points() float64 {
+	if t.StoryPoints > 0 {
+		return t.StoryPoints
+	}
+	return sizePoints[t.Size]
+}
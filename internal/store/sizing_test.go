@@ -0,0 +1,57 @@
+package store
+
+import "testing"
+
+func TestParseTaskSize(t *testing.T) {
+	for in, want := range map[string]TaskSize{"": "", " M ": TaskSizeM, "xl": TaskSizeXL} {
+		if got, ok := ParseTaskSize(in); !ok || got != want {
+			t.Errorf("ParseTaskSize(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := ParseTaskSize("huge"); ok {
+		t.Error("ParseTaskSize accepted an unknown size")
+	}
+}
+
+// TestTaskPoints verifies that explicit points win over the size and that an
+// unsized, unpointed task has none.
+func TestTaskPoints(t *testing.T) {
+	for _, tc := range []struct {
+		task Task
+		want float64
+	}{
+		{Task{}, 0},
+		{Task{Size: TaskSizeL}, 5},
+		{Task{Size: TaskSizeL, StoryPoints: 2}, 2},
+		{Task{StoryPoints: 0.5}, 0.5},
+	} {
+		if got := tc.task.Points(); got != tc.want {
+			t.Errorf("Points(size %q, points %v) = %v, want %v", tc.task.Size, tc.task.StoryPoints, got, tc.want)
+		}
+	}
+}
+
+// TestPatchTaskSizing verifies that points and size are patched together
+// and that negative points are clamped.
+func TestPatchTaskSizing(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	points, size := 3.0, TaskSizeM
+	if err := s.PatchTask(bg(), task.ID, TaskPatch{StoryPoints: &points, Size: &size}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if got.StoryPoints != 3 || got.Size != TaskSizeM {
+		t.Errorf("after patch: points %v size %q", got.StoryPoints, got.Size)
+	}
+	negative := -1.0
+	if err := s.PatchTask(bg(), task.ID, TaskPatch{StoryPoints: &negative}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetTask(bg(), task.ID); got.StoryPoints != 0 || got.Size != TaskSizeM {
+		t.Errorf("after clamp: points %v size %q", got.StoryPoints, got.Size)
+	}
+}
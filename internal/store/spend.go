@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Spend is a board's agent spend, measured against the board budgets
+// (WALLFACER_DAILY_BUDGET_USD and WALLFACER_BOARD_BUDGET_USD).
+type Spend struct {
+	TodayUSD       float64 `json:"today_usd"`         // turns recorded since local midnight
+	WeekUSD        float64 `json:"week_usd"`          // turns recorded in the last 7 days
+	BurnUSDPerHour float64 `json:"burn_usd_per_hour"` // average over the last 24 hours
+	TotalUSD       float64 `json:"total_usd"`         // every task on the board, archived ones included
+}
+
+// SpendWeek is the rolling window of Spend.WeekUSD.
+const SpendWeek = 7 * 24 * time.Hour
+
+// Spend totals the cost of every task on the board and of the turns
+// recorded in the windows ending at now: since midnight of now's day, the
+// last 24 hours, and the last week. Only tasks updated within the week are
+// read for those, since recording a turn updates its task.
+func (s *Store) Spend(ctx context.Context, now time.Time) (Spend, error) {
+	tasks, err := s.ListTasks(ctx, true)
+	if err != nil {
+		return Spend{}, err
+	}
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	burnStart := now.Add(-24 * time.Hour)
+	weekStart := now.Add(-SpendWeek)
+	var sp Spend
+	var burnUSD float64
+	for _, t := range tasks {
+		sp.TotalUSD += t.Usage.CostUSD
+		if t.UpdatedAt.Before(weekStart) {
+			continue
+		}
+		recs, err := s.GetTurnUsages(t.ID)
+		if err != nil {
+			return Spend{}, err
+		}
+		for _, rec := range recs {
+			if rec.Timestamp.Before(weekStart) {
+				continue
+			}
+			sp.WeekUSD += rec.CostUSD
+			if !rec.Timestamp.Before(burnStart) {
+				burnUSD += rec.CostUSD
+			}
+			if !rec.Timestamp.Before(dayStart) {
+				sp.TodayUSD += rec.CostUSD
+			}
+		}
+	}
+	sp.BurnUSDPerHour = burnUSD / 24
+	return sp, nil
+}
+
+// Add accumulates o into sp, for totals across boards.
+func (sp *Spend) Add(o Spend) {
+	sp.TodayUSD += o.TodayUSD
+	sp.WeekUSD += o.WeekUSD
+	sp.BurnUSDPerHour += o.BurnUSDPerHour
+	sp.TotalUSD += o.TotalUSD
+}
+
+// Exceeded returns why sp is over a budget, or "" when it is within both.
+// A budget of 0 is unlimited.
+func (sp Spend) Exceeded(dailyUSD, boardUSD float64) string {
+	switch {
+	case dailyUSD > 0 && sp.TodayUSD >= dailyUSD:
+		return fmt.Sprintf("daily budget exceeded: $%.4f of $%.4f spent today", sp.TodayUSD, dailyUSD)
+	case boardUSD > 0 && sp.TotalUSD >= boardUSD:
+		return fmt.Sprintf("board budget exceeded: $%.4f of $%.4f", sp.TotalUSD, boardUSD)
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpend(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "spend", Timeout: 0, Kind: TaskKindTask})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if err := s.AccumulateSubAgentUsage(bg(), task.ID, SandboxActivityImplementation, TaskUsage{CostUSD: 17}); err != nil {
+		t.Fatalf("AccumulateSubAgentUsage: %v", err)
+	}
+	now := time.Now()
+	for _, rec := range []TurnUsageRecord{
+		{Turn: 1, Timestamp: now.AddDate(0, 0, -10), CostUSD: 4},
+		{Turn: 2, Timestamp: now.AddDate(0, 0, -2), CostUSD: 1},
+		{Turn: 3, Timestamp: now, CostUSD: 12},
+	} {
+		if err := s.AppendTurnUsage(task.ID, rec); err != nil {
+			t.Fatalf("AppendTurnUsage: %v", err)
+		}
+	}
+
+	sp, err := s.Spend(bg(), now)
+	if err != nil {
+		t.Fatalf("Spend: %v", err)
+	}
+	if sp.TodayUSD != 12 || sp.WeekUSD != 13 || sp.BurnUSDPerHour != 0.5 || sp.TotalUSD != 17 {
+		t.Errorf("Spend = %+v, want today 12, week 13, burn 0.5/h, total 17", sp)
+	}
+
+	for _, tc := range []struct {
+		daily, board float64
+		exceeded     bool
+	}{
+		{0, 0, false},
+		{20, 30, false},
+		{12, 0, true},
+		{0, 17, true},
+	} {
+		if got := sp.Exceeded(tc.daily, tc.board); (got != "") != tc.exceeded {
+			t.Errorf("Exceeded(%v, %v) = %q, want exceeded=%v", tc.daily, tc.board, got, tc.exceeded)
+		}
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/google/uuid"
 	"latere.ai/x/wallfacer/internal/constants"
 	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
+	"latere.ai/x/wallfacer/internal/pkg/commitstyle"
 	"latere.ai/x/wallfacer/internal/pkg/envutil"
 	"latere.ai/x/wallfacer/internal/pkg/pubsub"
 )
@@ -51,6 +53,10 @@ type Store struct {
 	events  map[uuid.UUID][]TaskEvent
 	nextSeq map[uuid.UUID]int // next event sequence number to assign per task
 
+	// eventLocks holds one *sync.Mutex per task (uuid.UUID key) serializing
+	// InsertEvent for that task, so the trace-file write happens outside mu.
+	eventLocks sync.Map
+
 	// tasksByStatus is a secondary index from status → set of task IDs.
 	// It enables O(1) CountByStatus and O(k) ListTasksByStatus (where k is the
 	// count for that status) instead of O(n) full-map scans.
@@ -67,6 +73,10 @@ type Store struct {
 	// Every mutation that persists a task also calls hub.Publish via notify().
 	hub *pubsub.Hub[TaskDelta]
 
+	// eventHub fans out each event InsertEvent persists to live subscribers
+	// such as the /api/ws WebSocket.
+	eventHub *pubsub.Hub[TaskEvent]
+
 	// Payload pruning limits. A value of 0 disables pruning for that field.
 	// Configured at startup from environment variables with fallback to the
 	// Default* constants in models.go.
@@ -83,11 +93,32 @@ type Store struct {
 	compactWg sync.WaitGroup
 
 	// eventsLoaded tracks which tasks have had their events loaded into
-	// memory. Tasks in terminal states (done, failed, cancelled) skip
-	// event loading at startup and are loaded lazily on first access.
-	// This avoids reading potentially large trace files for completed
-	// tasks that are unlikely to be queried during normal operation.
+	// memory. No events are read at startup: a task's trace files are
+	// loaded on first access, and eventCache drops the least recently used
+	// lists once more than WALLFACER_EVENT_CACHE_TASKS tasks are loaded, so
+	// startup time and memory do not grow with the size of the history.
 	eventsLoaded map[uuid.UUID]bool
+	eventCache   *eventCache
+
+	// commitStyles caches per-repository commit-style profiles, keyed by
+	// repository path and loaded lazily from commitStylesFile. Guarded by
+	// commitStylesMu rather than mu: it is not task state.
+	commitStylesMu sync.Mutex
+	commitStyles   map[string]commitstyle.Profile
+
+	// preambles holds every saved version of the board's prompt preamble,
+	// oldest first, loaded lazily from preambleFile. Guarded by preambleMu
+	// rather than mu: it is not task state.
+	preambleMu      sync.Mutex
+	preambles       []Preamble
+	preamblesLoaded bool
+
+	// diffSnapshotsMu serializes the read-modify-write of each task's
+	// diff-snapshots.json blob.
+	diffSnapshotsMu sync.Mutex
+
+	// eventSink receives each persisted event; see SetEventSink.
+	eventSink atomic.Pointer[func(TaskEvent)]
 
 	// OnDone is an optional callback invoked after a task transitions to
 	// TaskStatusDone. It runs outside the store lock in a fire-and-forget
@@ -109,10 +140,12 @@ func NewStore(backend StorageBackend) (*Store, error) {
 		tasksByStatus:       make(map[TaskStatus]map[uuid.UUID]struct{}),
 		searchIndex:         make(map[uuid.UUID]indexedTaskText),
 		hub:                 pubsub.NewHub[TaskDelta](pubsub.WithClone(cloneTaskDelta)),
+		eventHub:            pubsub.NewHub[TaskEvent](),
 		retryHistoryLimit:   envutil.Int("WALLFACER_RETRY_HISTORY_LIMIT", constants.DefaultRetryHistoryLimit),
 		refineSessionsLimit: envutil.Int("WALLFACER_REFINE_SESSIONS_LIMIT", constants.DefaultRefineSessionsLimit),
 		promptHistoryLimit:  envutil.Int("WALLFACER_PROMPT_HISTORY_LIMIT", constants.DefaultPromptHistoryLimit),
 		maxTurnOutputBytes:  envutil.Int("WALLFACER_MAX_TURN_OUTPUT_BYTES", constants.DefaultMaxTurnOutputBytes),
+		eventCache:          newEventCache(envutil.Int("WALLFACER_EVENT_CACHE_TASKS", constants.DefaultEventCacheTasks)),
 	}
 
 	if err := s.loadAll(); err != nil {
@@ -128,12 +161,17 @@ func NewStore(backend StorageBackend) (*Store, error) {
 }
 
 // NewFileStore creates a Store backed by a FilesystemBackend rooted at dir.
-// This is the standard constructor for local deployments.
+// This is the standard constructor for local deployments. When
+// WALLFACER_STORE_KEY is set, task.json, traces, and blobs are encrypted at
+// rest with that key (see package atrest).
 func NewFileStore(dir string) (*Store, error) {
 	backend, err := NewFilesystemBackend(dir)
 	if err != nil {
 		return nil, err
 	}
+	if backend.cipher, err = atrest.FromEnv(); err != nil {
+		return nil, err
+	}
 	s, err := NewStore(backend)
 	if err != nil {
 		return nil, err
@@ -250,16 +288,7 @@ func (s *Store) loadAll() error {
 
 		s.tasks[id] = task
 		s.searchIndex[id] = indexEntry
-
-		// Eagerly load events only for tasks that may still be active.
-		if isTerminalStatus(task.Status) || task.Archived {
-			s.eventsLoaded[id] = false
-		} else {
-			if err := s.loadEvents(id); err != nil {
-				return err
-			}
-			s.eventsLoaded[id] = true
-		}
+		s.eventsLoaded[id] = false
 	}
 
 	return nil
@@ -284,7 +313,7 @@ func (s *Store) mutateTask(id uuid.UUID, fn func(t *Task) error) error {
 	defer s.mu.Unlock()
 	t, ok := s.tasks[id]
 	if !ok {
-		return fmt.Errorf("task not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
 	if err := fn(t); err != nil {
 		return err
@@ -301,12 +330,39 @@ func (s *Store) mutateTask(id uuid.UUID, fn func(t *Task) error) error {
 // loaded yet. Must be called while s.mu is held for writing.
 func (s *Store) ensureEventsLoadedLocked(id uuid.UUID) {
 	if s.eventsLoaded[id] {
+		s.eventCache.touch(id)
 		return
 	}
 	if err := s.loadEvents(id); err != nil {
 		logger.Store.Warn("lazy event load failed", "task", id, "error", err)
 	}
+	s.markEventsLoadedLocked(id)
+}
+
+// markEventsLoadedLocked records that id's events are in memory and evicts
+// the least recently used lists beyond the cache limit. Must be called while
+// s.mu is held for writing.
+func (s *Store) markEventsLoadedLocked(id uuid.UUID) {
 	s.eventsLoaded[id] = true
+	s.eventCache.touch(id)
+	for _, victim := range s.eventCache.overflow(id) {
+		s.evictEventsLocked(victim)
+	}
+}
+
+// rlockEventsLoaded read-locks s.mu with id's events in memory, loading them
+// under the write lock first when needed. The caller must RUnlock.
+func (s *Store) rlockEventsLoaded(id uuid.UUID) {
+	s.mu.RLock()
+	// Loop because another load may evict the list between Unlock and RLock.
+	for !s.eventsLoaded[id] {
+		s.mu.RUnlock()
+		s.mu.Lock()
+		s.ensureEventsLoadedLocked(id)
+		s.mu.Unlock()
+		s.mu.RLock()
+	}
+	s.eventCache.touch(id)
 }
 
 // loadEvents delegates to the backend to read all events for a task.
@@ -636,6 +636,45 @@ func TestSetTaskFailureCategory(t *testing.T) {
 	}
 }
 
+func TestSetTaskStalled(t *testing.T) {
+	s := newTestStore(t)
+
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "stalled turn", Timeout: 15, Kind: TaskKindTask})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if err := s.UpdateTaskStatus(bg(), task.ID, TaskStatusInProgress); err != nil {
+		t.Fatalf("UpdateTaskStatus(in_progress): %v", err)
+	}
+
+	at := time.Now().Truncate(time.Second)
+	if err := s.SetTaskStalled(bg(), task.ID, &at); err != nil {
+		t.Fatalf("SetTaskStalled: %v", err)
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if got.StalledAt == nil || !got.StalledAt.Equal(at) {
+		t.Fatalf("StalledAt = %v, want %v", got.StalledAt, at)
+	}
+
+	if err := s.SetTaskStalled(bg(), task.ID, nil); err != nil {
+		t.Fatalf("SetTaskStalled(nil): %v", err)
+	}
+	if got, _ := s.GetTask(bg(), task.ID); got.StalledAt != nil {
+		t.Errorf("StalledAt = %v after clearing, want nil", got.StalledAt)
+	}
+
+	// Leaving in_progress drops a flag the runner did not get to clear.
+	if err := s.SetTaskStalled(bg(), task.ID, &at); err != nil {
+		t.Fatalf("SetTaskStalled: %v", err)
+	}
+	if err := s.UpdateTaskStatus(bg(), task.ID, TaskStatusFailed); err != nil {
+		t.Fatalf("UpdateTaskStatus(failed): %v", err)
+	}
+	if got, _ := s.GetTask(bg(), task.ID); got.StalledAt != nil {
+		t.Errorf("StalledAt = %v after the task failed, want nil", got.StalledAt)
+	}
+}
+
 func TestResetTaskForRetry_ClearsFailureCategory(t *testing.T) {
 	s := newTestStore(t)
 
@@ -1060,9 +1099,10 @@ func TestLazyEventLoading(t *testing.T) {
 	}
 }
 
-// TestLazyEventLoading_ActiveTasksEager verifies that events for active
-// (non-terminal) tasks are loaded eagerly at startup.
-func TestLazyEventLoading_ActiveTasksEager(t *testing.T) {
+// TestLazyEventLoading_ActiveTasks verifies that events of active tasks are
+// not read at startup either, and that the first insert after a restart
+// loads them and continues the sequence.
+func TestLazyEventLoading_ActiveTasks(t *testing.T) {
 	dir := t.TempDir()
 	s, err := newTestFileStore(t, dir)
 	if err != nil {
@@ -1092,15 +1132,84 @@ func TestLazyEventLoading_ActiveTasksEager(t *testing.T) {
 		t.Fatalf("NewStore reload: %v", err)
 	}
 
-	// Events should be loaded eagerly for in_progress task.
 	s2.mu.RLock()
 	loaded := s2.eventsLoaded[task.ID]
-	evts := s2.events[task.ID]
 	s2.mu.RUnlock()
-	if !loaded {
-		t.Error("expected eventsLoaded=true for in_progress task at startup")
+	if loaded {
+		t.Error("expected eventsLoaded=false for in_progress task at startup")
+	}
+
+	if err := s2.InsertEvent(ctx, task.ID, EventTypeOutput, "more"); err != nil {
+		t.Fatalf("InsertEvent after reload: %v", err)
+	}
+	evts, _ := s2.GetEvents(ctx, task.ID)
+	if len(evts) != 2 || evts[1].ID != 2 {
+		t.Errorf("events after reload = %+v, want IDs 1 and 2", evts)
+	}
+}
+
+// TestEventCache_EvictsLeastRecentlyUsed verifies that loading events past
+// the cache limit drops the least recently used list and that an evicted
+// list reloads intact, including the next sequence number.
+func TestEventCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Setenv("WALLFACER_EVENT_CACHE_TASKS", "2")
+	s := newTestStore(t)
+	ctx := bg()
+	var ids []uuid.UUID
+	for i := range 3 {
+		task, err := s.CreateTaskWithOptions(ctx, TaskCreateOptions{Prompt: fmt.Sprintf("t%d", i), Timeout: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.InsertEvent(ctx, task.ID, EventTypeOutput, i); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, task.ID)
+	}
+
+	s.mu.RLock()
+	first, last := s.eventsLoaded[ids[0]], s.eventsLoaded[ids[2]]
+	s.mu.RUnlock()
+	if first || !last {
+		t.Fatalf("eventsLoaded first=%v last=%v, want the oldest evicted", first, last)
+	}
+	if n := s.eventCache.len(); n != 2 {
+		t.Errorf("cache holds %d tasks, want 2", n)
+	}
+
+	if err := s.InsertEvent(ctx, ids[0], EventTypeOutput, "again"); err != nil {
+		t.Fatal(err)
+	}
+	evts, _ := s.GetEvents(ctx, ids[0])
+	if len(evts) != 2 || evts[0].ID != 1 || evts[1].ID != 2 {
+		t.Errorf("reloaded events = %+v, want IDs 1 and 2", evts)
+	}
+	s.mu.RLock()
+	loaded := s.eventsLoaded[ids[1]]
+	s.mu.RUnlock()
+	if loaded {
+		t.Error("reloading the oldest list should evict the next least recently used")
+	}
+}
+
+// TestCompactTaskEvents_AfterEviction verifies compaction reads the trace
+// files when the task's events were evicted before the compactor ran.
+func TestCompactTaskEvents_AfterEviction(t *testing.T) {
+	s := newTestStore(t)
+	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+	insertOutputEvents(t, s, task.ID, 3)
+
+	s.mu.Lock()
+	s.evictEventsLocked(task.ID)
+	s.mu.Unlock()
+	if err := s.compactTaskEvents(task.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+	evts, _ := s.GetEvents(bg(), task.ID)
+	if len(evts) != 3 {
+		t.Fatalf("events after compaction = %d, want 3", len(evts))
 	}
-	if len(evts) != 1 {
-		t.Errorf("expected 1 event loaded eagerly, got %d", len(evts))
+	if _, err := os.Stat(filepath.Join(s.dir, task.ID.String(), "traces", "compact.ndjson")); err != nil {
+		t.Errorf("compact file not written: %v", err)
 	}
 }
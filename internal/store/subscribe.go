@@ -5,9 +5,11 @@ import "latere.ai/x/wallfacer/internal/pkg/pubsub"
 // TaskDelta carries the payload for a single task change notification.
 // Deleted is true when the task was removed; Task.ID holds the affected task's ID.
 // For non-delete events, Task is a standalone clone of the mutated task.
+// Created is true when the task was just created or restored from the trash.
 type TaskDelta struct {
 	Task    *Task
 	Deleted bool
+	Created bool
 }
 
 // Subscribe registers a channel that receives a SequencedDelta whenever task
@@ -51,6 +53,24 @@ func (s *Store) notify(task *Task, deleted bool) {
 	s.hub.Publish(td)
 }
 
+// notifyCreated publishes the delta of a task that was just created or
+// restored. Same locking rules as notify.
+func (s *Store) notifyCreated(task *Task) {
+	s.hub.Publish(TaskDelta{Task: copyTask(task), Created: true})
+}
+
+// SubscribeEvents registers a channel that receives every event after
+// InsertEvent has persisted it. The caller must call UnsubscribeEvents with
+// the returned ID when done.
+func (s *Store) SubscribeEvents() (int, <-chan pubsub.Sequenced[TaskEvent]) {
+	return s.eventHub.Subscribe()
+}
+
+// UnsubscribeEvents removes the event subscriber and drains any buffered events.
+func (s *Store) UnsubscribeEvents(id int) {
+	s.eventHub.Unsubscribe(id)
+}
+
 // LatestDeltaSeq returns the sequence number of the most recently emitted delta.
 func (s *Store) LatestDeltaSeq() int64 {
 	return s.hub.LatestSeq()
@@ -23,6 +23,9 @@ func TestSubscribe_ReceivesNotificationOnCreate(t *testing.T) {
 		if delta.Value.Deleted {
 			t.Error("expected Deleted=false for CreateTask")
 		}
+		if !delta.Value.Created {
+			t.Error("expected Created=true for CreateTask")
+		}
 	case <-time.After(time.Second):
 		t.Error("expected notification after CreateTask, timed out")
 	}
@@ -42,11 +45,35 @@ func TestSubscribe_ReceivesNotificationOnStatusUpdate(t *testing.T) {
 		if delta.Value.Task == nil || delta.Value.Task.ID != task.ID {
 			t.Errorf("expected delta for task %s, got %v", task.ID, delta.Value.Task)
 		}
+		if delta.Value.Created {
+			t.Error("expected Created=false for UpdateTaskStatus")
+		}
 	case <-time.After(time.Second):
 		t.Error("expected notification after UpdateTaskStatus, timed out")
 	}
 }
 
+func TestSubscribeEvents_ReceivesInsertedEvent(t *testing.T) {
+	s := newTestStore(t)
+	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
+
+	id, ch := s.SubscribeEvents()
+	defer s.UnsubscribeEvents(id)
+
+	if err := s.InsertEvent(bg(), task.ID, EventTypeSystem, map[string]string{"result": "ok"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ev := <-ch:
+		if ev.Value.TaskID != task.ID || ev.Value.EventType != EventTypeSystem {
+			t.Errorf("event = %+v, want a system event of task %s", ev.Value, task.ID)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected event after InsertEvent, timed out")
+	}
+}
+
 func TestSubscribe_DeleteSendsDeletedDelta(t *testing.T) {
 	s := newTestStore(t)
 	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5})
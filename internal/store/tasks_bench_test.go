@@ -1,13 +1,16 @@
-// Benchmarks for tasks.go: measure lock hold time reduction for
-// buildIndexEntry-before-lock optimisations.
+// Benchmarks for tasks.go and events.go: measure lock hold time of the hot
+// mutation paths and how much they stall concurrent readers.
 package store
 
 import (
 	"context"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // newBenchStore creates a Store backed by a fresh temporary directory for use
@@ -119,3 +122,150 @@ func BenchmarkCreateTask_LargeOversight(b *testing.B) {
 		b.StartTimer()
 	}
 }
+
+// BenchmarkListTasks measures parallel board snapshots of 200 tasks, the
+// read every SSE (re)connect and most list endpoints pay.
+func BenchmarkListTasks(b *testing.B) {
+	s := seedBenchmarkStore(b, 200)
+	ctx := context.Background()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := s.ListTasks(ctx, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// latencyBackend delays every trace-file write, standing in for a disk
+// slower than the tmpfs benchmarks usually run on.
+type latencyBackend struct {
+	StorageBackend
+	delay time.Duration
+}
+
+func (b *latencyBackend) SaveEvent(taskID uuid.UUID, seq int, event TaskEvent) error {
+	time.Sleep(b.delay)
+	return b.StorageBackend.SaveEvent(taskID, seq, event)
+}
+
+// newLatencyBenchStore creates a benchmark Store whose trace writes take
+// 200µs each.
+func newLatencyBenchStore(b *testing.B) *Store {
+	b.Helper()
+	fsb, err := NewFilesystemBackend(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	s, err := newTestStoreBackend(b, &latencyBackend{StorageBackend: fsb, delay: 200 * time.Microsecond})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return s
+}
+
+// BenchmarkInsertEvent measures parallel event inserts with 200µs trace
+// writes, each goroutine writing to its own task as concurrently running
+// agents do. Inserts into different tasks overlap their writes.
+func BenchmarkInsertEvent(b *testing.B) {
+	s := newLatencyBenchStore(b)
+	ctx := context.Background()
+	var ids []uuid.UUID
+	for range 16 {
+		task, err := s.CreateTaskWithOptions(ctx, TaskCreateOptions{Prompt: "bench", Timeout: 60})
+		if err != nil {
+			b.Fatal(err)
+		}
+		ids = append(ids, task.ID)
+	}
+	var next atomic.Int64
+	data := map[string]string{"result": strings.Repeat("output ", 40)}
+	b.SetParallelism(8) // 8 goroutines per GOMAXPROCS
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		id := ids[int(next.Add(1))%len(ids)]
+		for pb.Next() {
+			if err := s.InsertEvent(ctx, id, EventTypeOutput, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkGetTask_UnderEventLoad measures GetTask latency while background
+// goroutines insert events into other tasks, each trace write taking 200µs.
+// Readers only pay for the write when it happens under the store-wide lock.
+func BenchmarkGetTask_UnderEventLoad(b *testing.B) {
+	s := newLatencyBenchStore(b)
+	ctx := context.Background()
+	target, err := s.CreateTaskWithOptions(ctx, TaskCreateOptions{Prompt: "read me", Timeout: 60})
+	if err != nil {
+		b.Fatal(err)
+	}
+	const writers = 8
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range writers {
+		task, err := s.CreateTaskWithOptions(ctx, TaskCreateOptions{Prompt: "bench", Timeout: 60})
+		if err != nil {
+			b.Fatal(err)
+		}
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					_ = s.InsertEvent(ctx, task.ID, EventTypeOutput, map[string]string{"result": "tick"})
+				}
+			}
+		})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.GetTask(ctx, target.ID); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	close(stop)
+	wg.Wait()
+}
+
+// BenchmarkNewFileStore measures opening a data directory of 200 in-progress
+// tasks with 50 events each. Events are read on first access, not here.
+func BenchmarkNewFileStore(b *testing.B) {
+	dir := b.TempDir()
+	s, err := newTestFileStore(b, dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+	for range 200 {
+		task, err := s.CreateTaskWithOptions(ctx, TaskCreateOptions{Prompt: "bench", Timeout: 60})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := s.UpdateTaskStatus(ctx, task.ID, TaskStatusInProgress); err != nil {
+			b.Fatal(err)
+		}
+		for range 50 {
+			if err := s.InsertEvent(ctx, task.ID, EventTypeOutput, map[string]string{"result": "tick"}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	s.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s, err := NewFileStore(dir) // storetest:allow: closed each iteration
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.Close()
+	}
+}
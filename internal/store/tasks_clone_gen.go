@@ -18,17 +18,26 @@ func deepCloneTask(t *Task) Task {
 	cp.PromptHistory = slices.Clone(t.PromptHistory)
 	cp.RetryHistory = slices.Clone(t.RetryHistory)
 	cp.RefineSessions = cloneRefinementSessionSlice(t.RefineSessions)
+	cp.Watchers = slices.Clone(t.Watchers)
 	cp.CustomPassPatterns = slices.Clone(t.CustomPassPatterns)
 	cp.CustomFailPatterns = slices.Clone(t.CustomFailPatterns)
+	cp.ResearchDomains = slices.Clone(t.ResearchDomains)
 	cp.Tags = slices.Clone(t.Tags)
 	cp.DependsOn = slices.Clone(t.DependsOn)
 	cp.TruncatedTurns = slices.Clone(t.TruncatedTurns)
+	cp.PublishRuns = clonePublishRunSlice(t.PublishRuns)
+	cp.Previews = slices.Clone(t.Previews)
+	cp.Links = slices.Clone(t.Links)
+	cp.Attachments = slices.Clone(t.Attachments)
+	cp.ContextFiles = slices.Clone(t.ContextFiles)
+	cp.Approvals = slices.Clone(t.Approvals)
 	cp.SandboxByActivity = maps.Clone(t.SandboxByActivity)
 	cp.UsageBreakdown = maps.Clone(t.UsageBreakdown)
 	cp.WorktreePaths = maps.Clone(t.WorktreePaths)
 	cp.CommitHashes = maps.Clone(t.CommitHashes)
 	cp.BaseCommitHashes = maps.Clone(t.BaseCommitHashes)
 	cp.SnapshotDiffs = maps.Clone(t.SnapshotDiffs)
+	cp.PullRequestURLs = maps.Clone(t.PullRequestURLs)
 	cp.AutoRetryBudget = maps.Clone(t.AutoRetryBudget)
 
 	if t.CurrentRefinement != nil {
@@ -43,6 +52,10 @@ func deepCloneTask(t *Task) Task {
 		result := *t.Result
 		cp.Result = &result
 	}
+	if t.Lineage != nil {
+		lineage := *t.Lineage
+		cp.Lineage = &lineage
+	}
 	if t.StopReason != nil {
 		stopReason := *t.StopReason
 		cp.StopReason = &stopReason
@@ -55,6 +68,10 @@ func deepCloneTask(t *Task) Task {
 		startedAt := *t.StartedAt
 		cp.StartedAt = &startedAt
 	}
+	if t.ApprovedAt != nil {
+		approvedAt := *t.ApprovedAt
+		cp.ApprovedAt = &approvedAt
+	}
 	if t.ModelOverride != nil {
 		modelOverride := *t.ModelOverride
 		cp.ModelOverride = &modelOverride
@@ -63,10 +80,26 @@ func deepCloneTask(t *Task) Task {
 		scheduledAt := *t.ScheduledAt
 		cp.ScheduledAt = &scheduledAt
 	}
+	if t.Blocked != nil {
+		blocked := *t.Blocked
+		cp.Blocked = &blocked
+	}
+	if t.Proxy != nil {
+		proxy := *t.Proxy
+		cp.Proxy = &proxy
+	}
+	if t.Localized != nil {
+		localized := *t.Localized
+		cp.Localized = &localized
+	}
 	if t.LastFetchErrorAt != nil {
 		lastFetchErrorAt := *t.LastFetchErrorAt
 		cp.LastFetchErrorAt = &lastFetchErrorAt
 	}
+	if t.StalledAt != nil {
+		stalledAt := *t.StalledAt
+		cp.StalledAt = &stalledAt
+	}
 	if t.RoutineNextRun != nil {
 		routineNextRun := *t.RoutineNextRun
 		cp.RoutineNextRun = &routineNextRun
@@ -75,6 +108,22 @@ func deepCloneTask(t *Task) Task {
 		routineLastFiredAt := *t.RoutineLastFiredAt
 		cp.RoutineLastFiredAt = &routineLastFiredAt
 	}
+	if t.ReviewUnresolved != nil {
+		reviewUnresolved := *t.ReviewUnresolved
+		cp.ReviewUnresolved = &reviewUnresolved
+	}
+	if t.Estimate != nil {
+		estimate := *t.Estimate
+		cp.Estimate = &estimate
+	}
+	if t.Experiment != nil {
+		experiment := *t.Experiment
+		cp.Experiment = &experiment
+	}
+	if t.TrackerIssue != nil {
+		trackerIssue := *t.TrackerIssue
+		cp.TrackerIssue = &trackerIssue
+	}
 
 	return cp
 }
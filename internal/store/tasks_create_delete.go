@@ -34,7 +34,15 @@ type TaskCreateOptions struct {
 	Criteria       string
 	Timeout        int
 	MountWorktrees bool
-	Kind           TaskKind
+	SkipCommit     bool
+	MergeMode      MergeMode
+	// RequireApproval holds the merge for review (see Task.RequireApproval).
+	RequireApproval bool
+	ApprovalGates   bool
+	// InteractiveInput keeps the agent's stdin open during turns so the
+	// user can answer its prompts (see Task.InteractiveInput).
+	InteractiveInput bool
+	Kind             TaskKind
 	// FlowID is the slug of the flow this task runs against. Empty means
 	// the runner's legacy Kind→Flow resolver picks the default ("implement").
 	FlowID             string
@@ -49,18 +57,30 @@ type TaskCreateOptions struct {
 	ModelOverride      string
 	CustomPassPatterns []string
 	CustomFailPatterns []string
+	// Proxy overrides the global agent proxy settings (see Task.Proxy).
+	Proxy *TaskProxy
+	// SummaryLocale is the translation language (see Task.SummaryLocale).
+	SummaryLocale string
 
 	// Routine fields — only meaningful when Kind == TaskKindRoutine. Ignored
 	// for any other Kind.
 	RoutineIntervalSeconds int
+	RoutineCron            string // cron expression; wins over the interval
 	RoutineEnabled         bool
 	RoutineSpawnKind       TaskKind // legacy; prefer RoutineSpawnFlow
 	RoutineSpawnFlow       string   // flow slug; wins over SpawnKind
 
+	// ResearchDomains is the egress allowlist — only meaningful when
+	// Kind == TaskKindResearch.
+	ResearchDomains []string
+
 	// Principal / org attribution. Empty on anonymous / local calls;
 	// populated at the handler boundary from auth.PrincipalFromContext.
 	CreatedBy string
 	OrgID     string
+
+	// ForkedFrom links a task created by forking to its parent.
+	ForkedFrom uuid.UUID
 }
 
 // CreateTaskWithOptions creates a new backlog task in a single atomic write.
@@ -80,16 +100,22 @@ func (s *Store) CreateTaskWithOptions(_ context.Context, opts TaskCreateOptions)
 	now := time.Now()
 
 	task := &Task{
-		SchemaVersion:  constants.CurrentTaskSchemaVersion,
-		ID:             id,
-		Prompt:         opts.Prompt,
-		Criteria:       opts.Criteria,
-		Status:         TaskStatusBacklog,
-		Turns:          0,
-		Timeout:        clampTimeout(opts.Timeout),
-		MountWorktrees: opts.MountWorktrees,
-		Kind:           opts.Kind,
-		FlowID:         opts.FlowID,
+		SchemaVersion:    constants.CurrentTaskSchemaVersion,
+		ID:               id,
+		Prompt:           opts.Prompt,
+		Criteria:         opts.Criteria,
+		Status:           TaskStatusBacklog,
+		Turns:            0,
+		Timeout:          clampTimeout(opts.Timeout),
+		MountWorktrees:   opts.MountWorktrees,
+		SkipCommit:       opts.SkipCommit,
+		MergeMode:        opts.MergeMode,
+		RequireApproval:  opts.RequireApproval,
+		ApprovalGates:    opts.ApprovalGates,
+		InteractiveInput: opts.InteractiveInput,
+		Kind:             opts.Kind,
+		FlowID:           opts.FlowID,
+		SummaryLocale:    opts.SummaryLocale,
 		// Position is set under the lock after scanning existing backlog tasks.
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -142,6 +168,12 @@ func (s *Store) CreateTaskWithOptions(_ context.Context, opts TaskCreateOptions)
 		task.ScheduledAt = &ts
 	}
 
+	// Proxy: copy to avoid aliasing the caller's pointer.
+	if opts.Proxy != nil {
+		px := *opts.Proxy
+		task.Proxy = &px
+	}
+
 	// ModelOverride: nil when empty so omitempty keeps JSON clean.
 	if model := strings.TrimSpace(opts.ModelOverride); model != "" {
 		task.ModelOverride = &model
@@ -159,17 +191,26 @@ func (s *Store) CreateTaskWithOptions(_ context.Context, opts TaskCreateOptions)
 	// strings) remain empty and JSON round-trips via omitempty.
 	task.CreatedBy = opts.CreatedBy
 	task.OrgID = opts.OrgID
+	task.ForkedFrom = opts.ForkedFrom
 
 	// Routine fields: only persisted when the kind matches so a misuse of
 	// the options struct for a non-routine task doesn't accidentally set
 	// schedule metadata that the engine would then try to act on.
 	if opts.Kind == TaskKindRoutine {
 		task.RoutineIntervalSeconds = opts.RoutineIntervalSeconds
+		task.RoutineCron = opts.RoutineCron
 		task.RoutineEnabled = opts.RoutineEnabled
 		task.RoutineSpawnKind = opts.RoutineSpawnKind
 		task.RoutineSpawnFlow = opts.RoutineSpawnFlow
 	}
 
+	// Research fields: the allowlist is kept only for research tasks, whose
+	// timeout is also capped to the research time box.
+	if opts.Kind == TaskKindResearch {
+		task.ResearchDomains = append([]string(nil), opts.ResearchDomains...)
+		task.Timeout = min(task.Timeout, MaxResearchTimeoutMinutes)
+	}
+
 	// Build the search index entry before acquiring the lock.  Position is not
 	// a search-indexed field, so the entry is fully accurate even though
 	// task.Position has not been set yet.
@@ -210,9 +251,9 @@ func (s *Store) CreateTaskWithOptions(_ context.Context, opts TaskCreateOptions)
 	s.addToStatusIndex(task.Status, task.ID)
 	s.events[task.ID] = nil
 	s.nextSeq[task.ID] = 1
-	s.eventsLoaded[task.ID] = true
+	s.markEventsLoadedLocked(task.ID)
 	s.searchIndex[task.ID] = entry
-	s.notify(task, false)
+	s.notifyCreated(task)
 
 	ret := deepCloneTask(task)
 	return &ret, nil
@@ -260,7 +301,7 @@ func (s *Store) DeleteTask(ctx context.Context, id uuid.UUID, reason string) err
 	t, ok := s.tasks[id]
 	if !ok {
 		s.mu.Unlock()
-		return fmt.Errorf("task not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
 	tomb := Tombstone{DeletedAt: time.Now(), Reason: reason}
 	tombData, err := json.Marshal(tomb)
@@ -358,7 +399,7 @@ func (s *Store) RestoreTask(_ context.Context, id uuid.UUID) error {
 	t, ok := s.deleted[id]
 	if !ok {
 		s.mu.RUnlock()
-		return fmt.Errorf("deleted task not found: %s", id)
+		return fmt.Errorf("deleted %w: %s", ErrTaskNotFound, id)
 	}
 	s.mu.RUnlock()
 
@@ -375,7 +416,7 @@ func (s *Store) RestoreTask(_ context.Context, id uuid.UUID) error {
 	// Purge that may have removed the task from s.deleted between the read
 	// lock above and now.
 	if _, ok := s.deleted[id]; !ok {
-		return fmt.Errorf("deleted task not found: %s", id)
+		return fmt.Errorf("deleted %w: %s", ErrTaskNotFound, id)
 	}
 	if err := s.backend.DeleteBlob(id, "tombstone.json"); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("remove tombstone: %w", err)
@@ -384,7 +425,7 @@ func (s *Store) RestoreTask(_ context.Context, id uuid.UUID) error {
 	s.tasks[id] = t
 	s.addToStatusIndex(t.Status, id)
 	s.searchIndex[id] = entry
-	s.notify(t, false)
+	s.notifyCreated(t)
 	return nil
 }
 
@@ -410,6 +451,8 @@ func (s *Store) purgeTaskLocked(id uuid.UUID) error {
 	delete(s.events, id)
 	delete(s.nextSeq, id)
 	delete(s.eventsLoaded, id)
+	s.eventLocks.Delete(id)
+	s.eventCache.remove(id)
 	return nil
 }
 
@@ -446,7 +489,7 @@ func (s *Store) PurgeExpiredTombstones(retentionDays int) {
 }
 
 // UpdateTaskStatus sets a task's status field, enforcing the state machine.
-// Returns statemachine.ErrInvalidTransition if the requested transition is not allowed.
+// Returns ErrInvalidTransition if the requested transition is not allowed.
 // When transitioning to TaskStatusDone, a summary.json is written atomically
 // before subscribers are notified, so the file is always present by the time
 // any observer sees the done state.
@@ -214,7 +214,8 @@ func (s *Store) ListWaitingTasksWithSession(_ context.Context) []Task {
 }
 
 // TasksDependingOn returns all non-terminal tasks whose DependsOn contains
-// taskID. Non-terminal statuses are backlog, in_progress, waiting, and failed.
+// taskID. Non-terminal statuses are backlog, in_progress, waiting, review,
+// and failed.
 // The returned slice contains deep copies safe for use outside the lock.
 func (s *Store) TasksDependingOn(_ context.Context, taskID uuid.UUID) ([]*Task, error) {
 	s.mu.RLock()
@@ -224,7 +225,7 @@ func (s *Store) TasksDependingOn(_ context.Context, taskID uuid.UUID) ([]*Task,
 	var result []*Task
 	for _, t := range s.tasks {
 		switch t.Status {
-		case TaskStatusBacklog, TaskStatusInProgress, TaskStatusWaiting, TaskStatusFailed:
+		case TaskStatusBacklog, TaskStatusInProgress, TaskStatusWaiting, TaskStatusReview, TaskStatusFailed:
 		default:
 			continue
 		}
@@ -255,7 +256,7 @@ func (s *Store) GetTask(_ context.Context, id uuid.UUID) (*Task, error) {
 
 	t, ok := s.tasks[id]
 	if !ok {
-		return nil, fmt.Errorf("task not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
 	cp := deepCloneTask(t)
 	return &cp, nil
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// positionGap is the spacing MoveTask leaves between neighbours when it has
+// to renumber a column, so later moves can usually take a midpoint and write
+// only the moved task.
+const positionGap = 1024
+
+// ErrMoveCrossColumn is returned by MoveTask when the target column or an
+// anchor task is in a different column than the task being moved. Column
+// changes are status transitions and go through the status update path.
+var ErrMoveCrossColumn = errors.New("move target is in a different column")
+
+// MoveTarget says where MoveTask places a task within its board column. At
+// most one of AfterID and BeforeID may be set; with neither, the task moves
+// to the end of the column. Column, when set, must name the task's current
+// column and guards against a status change racing the move.
+type MoveTarget struct {
+	AfterID  *uuid.UUID
+	BeforeID *uuid.UUID
+	Column   TaskStatus
+}
+
+// MoveTask reorders a task within its column. Neighbours are resolved and
+// the new position computed under the store lock, so concurrent moves
+// cannot interleave into duplicate positions. The task normally takes the
+// midpoint between its new neighbours; when they are adjacent the column is
+// renumbered with positionGap spacing first. Every write is staged on a copy
+// and the live tasks change only once all of them are saved, so a failed
+// save leaves every position as it was. It returns the moved task.
+func (s *Store) MoveTask(_ context.Context, id uuid.UUID, target MoveTarget) (Task, error) {
+	if target.AfterID != nil && target.BeforeID != nil {
+		return Task{}, fmt.Errorf("only one of after_id and before_id may be set")
+	}
+	anchorID := target.AfterID
+	if anchorID == nil {
+		anchorID = target.BeforeID
+	}
+	if anchorID != nil && *anchorID == id {
+		return Task{}, fmt.Errorf("task cannot be moved relative to itself")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[id]
+	if !ok {
+		return Task{}, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	if target.Column != "" && target.Column != t.Status {
+		return Task{}, fmt.Errorf("%w: task is %s, not %s", ErrMoveCrossColumn, t.Status, target.Column)
+	}
+
+	// Column order without the moved task, as the board renders it.
+	column := make([]*Task, 0, len(s.tasksByStatus[t.Status]))
+	for cid := range s.tasksByStatus[t.Status] {
+		if c := s.tasks[cid]; c != nil && cid != id {
+			column = append(column, c)
+		}
+	}
+	slices.SortFunc(column, func(a, b *Task) int { return cmpTaskPositionCreatedAt(*a, *b) })
+
+	idx := len(column)
+	if anchorID != nil {
+		anchor, ok := s.tasks[*anchorID]
+		if !ok {
+			return Task{}, fmt.Errorf("%w: %s", ErrTaskNotFound, *anchorID)
+		}
+		if anchor.Status != t.Status {
+			return Task{}, fmt.Errorf("%w: anchor is %s, task is %s", ErrMoveCrossColumn, anchor.Status, t.Status)
+		}
+		idx = slices.Index(column, anchor)
+		if target.AfterID != nil {
+			idx++
+		}
+	}
+
+	// Stage the new positions on copies and persist them before any becomes
+	// visible, so a failed save leaves the column as it was in memory.
+	now := time.Now()
+	var staged []*Task // copies to save; the moved task is last
+	pos, ok := positionBetween(column, idx)
+	if !ok {
+		column, staged = renumberColumn(column, now)
+		pos, _ = positionBetween(column, idx)
+	}
+	moved := deepCloneTask(t)
+	moved.Position = pos
+	moved.UpdatedAt = now
+	staged = append(staged, &moved)
+	for i, c := range staged {
+		if err := s.saveTask(c.ID, c); err != nil {
+			// Rewrite the tasks already saved from memory so a partly
+			// renumbered column does not outlive the failed move on disk.
+			for _, done := range staged[:i] {
+				_ = s.saveTask(done.ID, s.tasks[done.ID])
+			}
+			return Task{}, err
+		}
+	}
+
+	// Swap.
+	for _, c := range staged {
+		live := s.tasks[c.ID]
+		*live = *c
+		s.notify(live, false)
+	}
+	return cloneTask(t), nil
+}
+
+// positionBetween returns a position that sorts a task at index idx of
+// column (which excludes the task itself). It reports false when the
+// neighbours at idx-1 and idx have no free integer between them.
+func positionBetween(column []*Task, idx int) (int, bool) {
+	switch {
+	case len(column) == 0:
+		return 0, true
+	case idx == 0:
+		return column[0].Position - positionGap, true
+	case idx == len(column):
+		return column[idx-1].Position + positionGap, true
+	}
+	lo, hi := column[idx-1].Position, column[idx].Position
+	if hi-lo < 2 {
+		return 0, false
+	}
+	return lo + (hi-lo)/2, true
+}
+
+// renumberColumn returns column with positions 0, positionGap,
+// 2*positionGap, … preserving its order, together with the copies of the
+// tasks whose position changes. The tasks in column are not modified.
+func renumberColumn(column []*Task, now time.Time) (renumbered, changed []*Task) {
+	renumbered = make([]*Task, len(column))
+	for i, c := range column {
+		want := i * positionGap
+		if c.Position == want {
+			renumbered[i] = c
+			continue
+		}
+		cp := deepCloneTask(c)
+		cp.Position = want
+		cp.UpdatedAt = now
+		renumbered[i] = &cp
+		changed = append(changed, &cp)
+	}
+	return renumbered, changed
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// backlogOrder returns the backlog task IDs in board order.
+func backlogOrder(t *testing.T, s *Store) []uuid.UUID {
+	t.Helper()
+	tasks, err := s.ListTasksByStatus(bg(), TaskStatusBacklog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+// newBacklog creates n backlog tasks with positions 0..n-1 and returns their
+// IDs in that order.
+func newBacklog(t *testing.T, s *Store, n int) []uuid.UUID {
+	t.Helper()
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: fmt.Sprintf("task %d", i), Timeout: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = s.UpdateTaskPosition(bg(), task.ID, i)
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func assertOrder(t *testing.T, got, want []uuid.UUID) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("order has %d tasks, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order[%d] = %s, want %s\n got: %v\nwant: %v", i, got[i], want[i], got, want)
+		}
+	}
+}
+
+func TestMoveTask_AfterBeforeAndEnd(t *testing.T) {
+	s := newTestStore(t)
+	ids := newBacklog(t, s, 4) // a b c d
+	a, b, c, d := ids[0], ids[1], ids[2], ids[3]
+
+	// Adjacent integer positions force a renumber before the midpoint.
+	if _, err := s.MoveTask(bg(), d, MoveTarget{AfterID: &a}); err != nil {
+		t.Fatal(err)
+	}
+	assertOrder(t, backlogOrder(t, s), []uuid.UUID{a, d, b, c})
+
+	if _, err := s.MoveTask(bg(), c, MoveTarget{BeforeID: &a}); err != nil {
+		t.Fatal(err)
+	}
+	assertOrder(t, backlogOrder(t, s), []uuid.UUID{c, a, d, b})
+
+	moved, err := s.MoveTask(bg(), a, MoveTarget{Column: TaskStatusBacklog})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOrder(t, backlogOrder(t, s), []uuid.UUID{c, d, b, a})
+	if moved.ID != a {
+		t.Errorf("returned task %s, want %s", moved.ID, a)
+	}
+}
+
+func TestMoveTask_MidpointWritesOnlyMovedTask(t *testing.T) {
+	s := newTestStore(t)
+	ids := newBacklog(t, s, 3)
+	for i, id := range ids {
+		_ = s.UpdateTaskPosition(bg(), id, i*positionGap)
+	}
+	before, _ := s.GetTask(bg(), ids[1])
+
+	moved, err := s.MoveTask(bg(), ids[2], MoveTarget{BeforeID: &ids[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Position != positionGap/2 {
+		t.Errorf("position = %d, want midpoint %d", moved.Position, positionGap/2)
+	}
+	after, _ := s.GetTask(bg(), ids[1])
+	if after.Position != before.Position || !after.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Error("neighbour was rewritten although a midpoint was free")
+	}
+}
+
+func TestMoveTask_RepairsDuplicatePositions(t *testing.T) {
+	s := newTestStore(t)
+	ids := newBacklog(t, s, 3)
+	for _, id := range ids {
+		_ = s.UpdateTaskPosition(bg(), id, 7) // the race the endpoint replaces
+	}
+	if _, err := s.MoveTask(bg(), ids[0], MoveTarget{AfterID: &ids[1]}); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := s.ListTasksByStatus(bg(), TaskStatusBacklog)
+	seen := map[int]bool{}
+	for _, task := range tasks {
+		if seen[task.Position] {
+			t.Fatalf("duplicate position %d after move", task.Position)
+		}
+		seen[task.Position] = true
+	}
+	assertOrder(t, backlogOrder(t, s), []uuid.UUID{ids[1], ids[0], ids[2]})
+}
+
+// failNthSaveBackend wraps a real backend and fails the failAt-th SaveTask
+// once armed, so a test can fail a move partway through a renumber.
+type failNthSaveBackend struct {
+	StorageBackend
+	failAt, saves int
+}
+
+func (b *failNthSaveBackend) SaveTask(t *Task) error {
+	if b.failAt > 0 {
+		if b.saves++; b.saves == b.failAt {
+			return errors.New("disk full")
+		}
+	}
+	return b.StorageBackend.SaveTask(t)
+}
+
+func TestMoveTask_FailedSaveMovesNothing(t *testing.T) {
+	fsb, err := NewFilesystemBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &failNthSaveBackend{StorageBackend: fsb}
+	s, err := newTestStoreBackend(t, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := newBacklog(t, s, 4) // adjacent positions force a renumber
+	positions := func(tasks []*Task) map[uuid.UUID]int {
+		out := make(map[uuid.UUID]int, len(tasks))
+		for _, task := range tasks {
+			out[task.ID] = task.Position
+		}
+		return out
+	}
+	want := map[uuid.UUID]int{ids[0]: 0, ids[1]: 1, ids[2]: 2, ids[3]: 3}
+
+	// The renumber saves two neighbours and then the moved task; fail each
+	// of those writes in turn.
+	for failAt := 1; failAt <= 3; failAt++ {
+		b.failAt, b.saves = failAt, 0
+		if _, err := s.MoveTask(bg(), ids[3], MoveTarget{AfterID: &ids[0]}); err == nil {
+			t.Fatalf("failAt %d: MoveTask succeeded with a failing backend", failAt)
+		}
+		b.failAt = 0
+		tasks, _ := s.ListTasksByStatus(bg(), TaskStatusBacklog)
+		onDisk, err := fsb.LoadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := make([]*Task, len(tasks))
+		for i := range tasks {
+			list[i] = &tasks[i]
+		}
+		if got := positions(list); !maps.Equal(got, want) {
+			t.Errorf("failAt %d: positions in memory = %v, want %v", failAt, got, want)
+		}
+		if got := positions(onDisk); !maps.Equal(got, want) {
+			t.Errorf("failAt %d: positions on disk = %v, want %v", failAt, got, want)
+		}
+	}
+}
+
+func TestMoveTask_Rejects(t *testing.T) {
+	s := newTestStore(t)
+	ids := newBacklog(t, s, 2)
+	if err := s.ForceUpdateTaskStatus(bg(), ids[1], TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	missing := uuid.New()
+
+	cases := []struct {
+		name   string
+		target MoveTarget
+		cross  bool
+	}{
+		{"both anchors", MoveTarget{AfterID: &ids[1], BeforeID: &ids[1]}, false},
+		{"self anchor", MoveTarget{AfterID: &ids[0]}, false},
+		{"unknown anchor", MoveTarget{AfterID: &missing}, false},
+		{"anchor in other column", MoveTarget{AfterID: &ids[1]}, true},
+		{"other column", MoveTarget{Column: TaskStatusInProgress}, true},
+	}
+	for _, tc := range cases {
+		_, err := s.MoveTask(bg(), ids[0], tc.target)
+		if err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+			continue
+		}
+		if got := errors.Is(err, ErrMoveCrossColumn); got != tc.cross {
+			t.Errorf("%s: errors.Is(ErrMoveCrossColumn) = %v, err %v", tc.name, got, err)
+		}
+	}
+}
+
+func TestMoveTask_ConcurrentMovesKeepPositionsUnique(t *testing.T) {
+	s := newTestStore(t)
+	ids := newBacklog(t, s, 8)
+
+	var wg sync.WaitGroup
+	for i := 1; i < len(ids); i++ {
+		wg.Go(func() {
+			if _, err := s.MoveTask(bg(), ids[i], MoveTarget{AfterID: &ids[0]}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	tasks, _ := s.ListTasksByStatus(bg(), TaskStatusBacklog)
+	seen := map[int]bool{}
+	for _, task := range tasks {
+		if seen[task.Position] {
+			t.Fatalf("duplicate position %d after concurrent moves", task.Position)
+		}
+		seen[task.Position] = true
+	}
+	if tasks[0].ID != ids[0] {
+		t.Errorf("anchor task is no longer first")
+	}
+}
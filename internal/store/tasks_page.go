@@ -0,0 +1,156 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskPageOptions scopes and pages a task listing (see TasksPage).
+type TaskPageOptions struct {
+	// Statuses restricts the listing to these columns; empty means all.
+	Statuses        []TaskStatus
+	IncludeArchived bool
+	// FailureCategory, when set, keeps only tasks with that category.
+	FailureCategory FailureCategory
+	// Blocked, when set, keeps only blocked (true) or unblocked (false)
+	// tasks.
+	Blocked *bool
+	// Limit caps the page size; <= 0 returns every remaining task.
+	Limit int
+	// Cursor resumes after the last task of a previous page; empty starts
+	// at the top.
+	Cursor string
+}
+
+// TaskPage is one page of a task listing in board order.
+type TaskPage struct {
+	Tasks []Task `json:"tasks"`
+	// Total counts every task matching the options, across all pages.
+	Total int `json:"total"`
+	// NextCursor is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// TasksPage lists the tasks visible to p in board order (position, then
+// creation time, then ID), one page at a time. Status-scoped listings read
+// only the matching columns through the status index, so a board with
+// thousands of done tasks can load its active columns without cloning the
+// rest. The cursor is a keyset over the sort key, so pages stay consistent
+// when tasks are created or deleted between requests.
+func (s *Store) TasksPage(_ context.Context, p *Principal, opts TaskPageOptions) (TaskPage, error) {
+	after, err := decodeTaskCursor(opts.Cursor)
+	if err != nil {
+		return TaskPage{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keep := func(t *Task) bool {
+		return t != nil &&
+			(opts.IncludeArchived || !t.Archived) &&
+			(opts.FailureCategory == "" || t.FailureCategory == opts.FailureCategory) &&
+			(opts.Blocked == nil || t.IsBlocked() == *opts.Blocked) &&
+			principalSeesTask(p, t)
+	}
+	var matched []*Task
+	if len(opts.Statuses) == 0 {
+		for _, t := range s.tasks {
+			if keep(t) {
+				matched = append(matched, t)
+			}
+		}
+	} else {
+		for _, status := range slices.Compact(slices.Sorted(slices.Values(opts.Statuses))) {
+			for id := range s.tasksByStatus[status] {
+				if t := s.tasks[id]; keep(t) {
+					matched = append(matched, t)
+				}
+			}
+		}
+	}
+	slices.SortFunc(matched, func(a, b *Task) int { return cmpTaskPageKey(taskCursorOf(a), taskCursorOf(b)) })
+
+	start := 0
+	if after != nil {
+		start, _ = slices.BinarySearchFunc(matched, *after, func(t *Task, c taskCursor) int {
+			return cmpTaskPageKey(taskCursorOf(t), c)
+		})
+		// Skip the cursor task itself when it still exists.
+		if start < len(matched) && cmpTaskPageKey(taskCursorOf(matched[start]), *after) == 0 {
+			start++
+		}
+	}
+	end := len(matched)
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, end)
+	}
+
+	page := TaskPage{Tasks: make([]Task, 0, end-start), Total: len(matched)}
+	for _, t := range matched[start:end] {
+		page.Tasks = append(page.Tasks, cloneTask(t))
+	}
+	if end < len(matched) && end > start {
+		page.NextCursor = encodeTaskCursor(taskCursorOf(matched[end-1]))
+	}
+	return page, nil
+}
+
+// taskCursor is the board sort key of a task, used as a keyset cursor.
+type taskCursor struct {
+	position  int
+	createdAt time.Time
+	id        uuid.UUID
+}
+
+func taskCursorOf(t *Task) taskCursor {
+	return taskCursor{position: t.Position, createdAt: t.CreatedAt, id: t.ID}
+}
+
+// cmpTaskPageKey extends cmpTaskPositionCreatedAt with an ID tie-break so the
+// order is total and a cursor identifies exactly one slot.
+func cmpTaskPageKey(a, b taskCursor) int {
+	if c := cmp.Compare(a.position, b.position); c != 0 {
+		return c
+	}
+	if c := a.createdAt.Compare(b.createdAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.id.String(), b.id.String())
+}
+
+// encodeTaskCursor renders c as an opaque URL-safe token.
+func encodeTaskCursor(c taskCursor) string {
+	raw := strconv.Itoa(c.position) + ":" + strconv.FormatInt(c.createdAt.UnixNano(), 10) + ":" + c.id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTaskCursor parses a token from encodeTaskCursor; "" yields nil.
+func decodeTaskCursor(token string) (*taskCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	pos, err1 := strconv.Atoi(parts[0])
+	nanos, err2 := strconv.ParseInt(parts[1], 10, 64)
+	id, err3 := uuid.Parse(parts[2])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &taskCursor{position: pos, createdAt: time.Unix(0, nanos), id: id}, nil
+}
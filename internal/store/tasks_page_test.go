@@ -0,0 +1,92 @@
+package store
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTasksPage_CursorWalksBoardOrder(t *testing.T) {
+	s := newTestStore(t)
+	ids := newBacklog(t, s, 5)
+
+	var got []uuid.UUID
+	cursor := ""
+	for range 10 {
+		page, err := s.TasksPage(bg(), nil, TaskPageOptions{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Total != 5 {
+			t.Errorf("total = %d, want 5", page.Total)
+		}
+		for _, task := range page.Tasks {
+			got = append(got, task.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	assertOrder(t, got, ids)
+}
+
+func TestTasksPage_CursorSurvivesDeletedTask(t *testing.T) {
+	s := newTestStore(t)
+	ids := newBacklog(t, s, 4)
+
+	first, _ := s.TasksPage(bg(), nil, TaskPageOptions{Limit: 2})
+	if err := s.DeleteTask(bg(), ids[1], ""); err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.TasksPage(bg(), nil, TaskPageOptions{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOrder(t, []uuid.UUID{second.Tasks[0].ID, second.Tasks[1].ID}, ids[2:])
+	if second.NextCursor != "" {
+		t.Errorf("last page has a next cursor")
+	}
+}
+
+func TestTasksPage_StatusScope(t *testing.T) {
+	s := newTestStore(t)
+	ids := newBacklog(t, s, 4)
+	_ = s.ForceUpdateTaskStatus(bg(), ids[1], TaskStatusInProgress)
+	_ = s.ForceUpdateTaskStatus(bg(), ids[2], TaskStatusDone)
+	_ = s.ForceUpdateTaskStatus(bg(), ids[3], TaskStatusDone)
+	_ = s.SetTaskArchived(bg(), ids[3], true)
+
+	for _, tc := range []struct {
+		statuses []TaskStatus
+		archived bool
+		want     int
+	}{
+		{nil, false, 3},
+		{nil, true, 4},
+		{[]TaskStatus{TaskStatusDone}, false, 1},
+		{[]TaskStatus{TaskStatusDone}, true, 2},
+		{[]TaskStatus{TaskStatusBacklog, TaskStatusInProgress, TaskStatusBacklog}, false, 2},
+	} {
+		page, err := s.TasksPage(bg(), nil, TaskPageOptions{Statuses: tc.statuses, IncludeArchived: tc.archived})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Tasks) != tc.want || page.Total != tc.want {
+			t.Errorf("statuses=%v archived=%v: got %d tasks (total %d), want %d",
+				tc.statuses, tc.archived, len(page.Tasks), page.Total, tc.want)
+		}
+	}
+}
+
+func TestTasksPage_InvalidCursor(t *testing.T) {
+	s := newTestStore(t)
+	b64 := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	for _, c := range []string{"!!", b64("1:2"), b64(fmt.Sprintf("x:1:%s", uuid.New()))} {
+		if _, err := s.TasksPage(bg(), nil, TaskPageOptions{Cursor: c}); err == nil {
+			t.Errorf("cursor %q: expected an error", c)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/harness"
+)
+
+// ErrPatchStatusChanged is returned by PatchTask when the task is no longer
+// in the status the patch was prepared against.
+var ErrPatchStatusChanged = errors.New("task status changed")
+
+// TaskPatch is a set of field changes that PatchTask applies to a task as a
+// single unit. Nil fields are left unchanged. IfStatus, when set, must name
+// the task's current status and guards against a status change racing the
+// patch (the caller decides which fields are editable from the status it
+// read).
+type TaskPatch struct {
+	IfStatus TaskStatus
+
+	Status             *TaskStatus
+	Title              *string
+	Prompt             *string
+	Criteria           *string
+	Timeout            *int
+	FreshStart         *bool
+	MountWorktrees     *bool
+	SkipCommit         *bool
+	MergeMode          *MergeMode
+	RequireApproval    *bool
+	ApprovalGates      *bool
+	InteractiveInput   *bool
+	Sandbox            *harness.ID
+	SandboxByActivity  *map[SandboxActivity]harness.ID
+	MaxCostUSD         *float64
+	MaxInputTokens     *int
+	Model              *string
+	CustomPassPatterns *[]string
+	CustomFailPatterns *[]string
+	// ScheduledAt sets the schedule; ClearScheduledAt removes it.
+	ScheduledAt      *time.Time
+	ClearScheduledAt bool
+	// Blocked sets the block; ClearBlocked removes it.
+	Blocked      *TaskBlock
+	ClearBlocked bool
+	// Proxy sets the task's proxy override; ClearProxy removes it.
+	Proxy      *TaskProxy
+	ClearProxy bool
+	// SummaryLocale sets the translation language; "" clears it.
+	SummaryLocale *string
+	Position      *int
+	DependsOn     *[]string
+	Tags          *[]string
+	Links         *[]TaskLink
+	ContextFiles  *[]string
+	StoryPoints   *float64
+	Size          *TaskSize
+}
+
+// PatchTask applies every change in p to the task identified by id, or none
+// of them. The changes are applied to a staged copy of the task, the copy is
+// validated (including the status transition) and persisted, and only then
+// swapped in for the live task; a rejected transition or a failed write
+// leaves the task, its indexes, and subscribers untouched. An empty patch is
+// a no-op.
+func (s *Store) PatchTask(_ context.Context, id uuid.UUID, p TaskPatch) error {
+	if p == (TaskPatch{IfStatus: p.IfStatus}) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	if p.IfStatus != "" && p.IfStatus != t.Status {
+		return fmt.Errorf("%w: task is %s, not %s", ErrPatchStatusChanged, t.Status, p.IfStatus)
+	}
+
+	// Stage.
+	staged := deepCloneTask(t)
+	p.applyTo(&staged)
+
+	// Validate.
+	transition := p.Status != nil
+	if transition {
+		if err := TaskMachine.Validate(t.Status, staged.Status); err != nil {
+			return err
+		}
+		if staged.Status == TaskStatusInProgress && staged.StartedAt == nil {
+			now := time.Now()
+			staged.StartedAt = &now
+		}
+	}
+
+	// Persist the staged copy before it becomes visible.
+	staged.UpdatedAt = time.Now()
+	if err := s.saveTask(id, &staged); err != nil {
+		return err
+	}
+
+	// Swap.
+	oldStatus := t.Status
+	*t = staged
+	if transition {
+		s.removeFromStatusIndex(oldStatus, id)
+		s.addToStatusIndex(t.Status, id)
+	}
+	if entry, ok := s.searchIndex[id]; ok {
+		entry.title = strings.ToLower(t.Title)
+		entry.prompt = strings.ToLower(t.Prompt)
+		entry.tags = strings.ToLower(strings.Join(t.Tags, " "))
+		s.searchIndex[id] = entry
+	}
+	if transition && t.Status == TaskStatusDone {
+		s.buildAndSaveSummary(*t)
+		if s.OnDone != nil {
+			taskCopy := deepCloneTask(t)
+			go s.OnDone(taskCopy)
+		}
+	}
+	s.notify(t, false)
+	if transition && isTerminalStatus(t.Status) {
+		s.scheduleTerminalCompaction(id)
+	}
+	return nil
+}
+
+// applyTo writes the patch's fields onto t, normalising them the same way
+// the single-field update methods do.
+func (p TaskPatch) applyTo(t *Task) {
+	if p.Status != nil {
+		t.Status = *p.Status
+		clearBlockOutsideBacklog(t)
+		clearStallOutsideInProgress(t)
+	}
+	if p.Title != nil {
+		t.Title = strings.TrimSpace(*p.Title)
+	}
+	if p.Prompt != nil {
+		t.Prompt = *p.Prompt
+	}
+	if p.Criteria != nil {
+		t.Criteria = *p.Criteria
+	}
+	if p.Timeout != nil {
+		t.Timeout = clampTimeout(*p.Timeout)
+	}
+	if p.FreshStart != nil {
+		t.FreshStart = *p.FreshStart
+	}
+	if p.MountWorktrees != nil {
+		t.MountWorktrees = *p.MountWorktrees
+	}
+	if p.SkipCommit != nil {
+		t.SkipCommit = *p.SkipCommit
+	}
+	if p.MergeMode != nil {
+		t.MergeMode = *p.MergeMode
+	}
+	if p.RequireApproval != nil {
+		t.RequireApproval = *p.RequireApproval
+	}
+	if p.ApprovalGates != nil {
+		t.ApprovalGates = *p.ApprovalGates
+	}
+	if p.InteractiveInput != nil {
+		t.InteractiveInput = *p.InteractiveInput
+	}
+	if p.Sandbox != nil {
+		t.Sandbox = harness.NormalizeID(string(*p.Sandbox))
+	}
+	if p.SandboxByActivity != nil {
+		t.SandboxByActivity = normalizeSandboxByActivity(*p.SandboxByActivity)
+	}
+	if p.MaxCostUSD != nil {
+		t.MaxCostUSD = max(*p.MaxCostUSD, 0)
+	}
+	if p.MaxInputTokens != nil {
+		t.MaxInputTokens = max(*p.MaxInputTokens, 0)
+	}
+	if p.Model != nil {
+		if model := strings.TrimSpace(*p.Model); model == "" {
+			t.ModelOverride = nil
+		} else {
+			t.ModelOverride = &model
+		}
+	}
+	if p.CustomPassPatterns != nil {
+		t.CustomPassPatterns = cloneOrNil(*p.CustomPassPatterns)
+	}
+	if p.CustomFailPatterns != nil {
+		t.CustomFailPatterns = cloneOrNil(*p.CustomFailPatterns)
+	}
+	if p.ClearScheduledAt {
+		t.ScheduledAt = nil
+	} else if p.ScheduledAt != nil {
+		ts := *p.ScheduledAt
+		t.ScheduledAt = &ts
+	}
+	if p.ClearBlocked {
+		t.Blocked = nil
+	} else if p.Blocked != nil {
+		b := *p.Blocked
+		t.Blocked = &b
+	}
+	if p.ClearProxy {
+		t.Proxy = nil
+	} else if p.Proxy != nil {
+		px := *p.Proxy
+		t.Proxy = &px
+	}
+	if p.SummaryLocale != nil && *p.SummaryLocale != t.SummaryLocale {
+		t.SummaryLocale = *p.SummaryLocale
+		// A translation into the old language no longer applies.
+		t.Localized = nil
+	}
+	if p.Position != nil {
+		t.Position = *p.Position
+	}
+	if p.DependsOn != nil {
+		t.DependsOn = cloneOrNil(*p.DependsOn)
+	}
+	if p.Tags != nil {
+		t.Tags = cloneOrNil(*p.Tags)
+	}
+	if p.Links != nil {
+		t.Links = cloneOrNil(*p.Links)
+	}
+	if p.ContextFiles != nil {
+		t.ContextFiles = cloneOrNil(*p.ContextFiles)
+	}
+	if p.StoryPoints != nil {
+		t.StoryPoints = max(*p.StoryPoints, 0)
+	}
+	if p.Size != nil {
+		t.Size = *p.Size
+	}
+}
+
+// cloneOrNil copies s, normalising an empty slice to nil so omitempty keeps
+// the JSON clean.
+func cloneOrNil[T any](s []T) []T {
+	if len(s) == 0 {
+		return nil
+	}
+	return append([]T(nil), s...)
+}
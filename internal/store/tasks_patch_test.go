@@ -0,0 +1,171 @@
+package store
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/pkg/statemachine"
+)
+
+// failingSaveBackend wraps a real backend and fails every SaveTask once
+// armed, so a test can observe what a failed write leaves behind.
+type failingSaveBackend struct {
+	StorageBackend
+	fail bool
+}
+
+func (b *failingSaveBackend) SaveTask(t *Task) error {
+	if b.fail {
+		return errors.New("disk full")
+	}
+	return b.StorageBackend.SaveTask(t)
+}
+
+func TestPatchTask_AppliesAllFields(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "old prompt", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	title, prompt, pos, cost := "  Fix Login  ", "New Prompt", 7, -3.0
+	tags := []string{"Backend"}
+	if err := s.PatchTask(bg(), task.ID, TaskPatch{
+		IfStatus:   TaskStatusBacklog,
+		Title:      &title,
+		Prompt:     &prompt,
+		Position:   &pos,
+		MaxCostUSD: &cost,
+		Tags:       &tags,
+	}); err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if got.Prompt != prompt || got.Position != pos || got.MaxCostUSD != 0 || !reflect.DeepEqual(got.Tags, tags) {
+		t.Fatalf("task = prompt %q position %d cost %v tags %v", got.Prompt, got.Position, got.MaxCostUSD, got.Tags)
+	}
+	if got.Title != "Fix Login" {
+		t.Errorf("title = %q, want it trimmed", got.Title)
+	}
+	if entry := s.searchIndex[task.ID]; entry.title != "fix login" || entry.prompt != "new prompt" || entry.tags != "backend" {
+		t.Errorf("search index = %+v, want the patched title, prompt, and tags", entry)
+	}
+}
+
+func TestPatchTask_InvalidTransitionAppliesNothing(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "old prompt", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompt, pos, status := "new prompt", 7, TaskStatusDone
+	err = s.PatchTask(bg(), task.ID, TaskPatch{Prompt: &prompt, Position: &pos, Status: &status})
+	if !errors.Is(err, statemachine.ErrInvalidTransition) {
+		t.Fatalf("err = %v, want ErrInvalidTransition", err)
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if got.Prompt != "old prompt" || got.Position != task.Position || got.Status != TaskStatusBacklog {
+		t.Fatalf("task changed by a rejected patch: prompt %q position %d status %s", got.Prompt, got.Position, got.Status)
+	}
+}
+
+func TestPatchTask_FailedSaveAppliesNothing(t *testing.T) {
+	fsb, err := NewFilesystemBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &failingSaveBackend{StorageBackend: fsb}
+	s, err := newTestStoreBackend(t, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "old prompt", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subID, ch := s.Subscribe()
+	defer s.Unsubscribe(subID)
+
+	b.fail = true
+	prompt, status := "new prompt", TaskStatusInProgress
+	if err := s.PatchTask(bg(), task.ID, TaskPatch{Prompt: &prompt, Status: &status}); err == nil {
+		t.Fatal("PatchTask succeeded with a failing backend")
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if got.Prompt != "old prompt" || got.Status != TaskStatusBacklog || got.StartedAt != nil {
+		t.Fatalf("task changed by a failed save: prompt %q status %s", got.Prompt, got.Status)
+	}
+	if n := s.CountByStatus(TaskStatusInProgress); n != 0 {
+		t.Errorf("in_progress index has %d tasks, want 0", n)
+	}
+	if entry := s.searchIndex[task.ID]; entry.prompt != "old prompt" {
+		t.Errorf("search index prompt = %q, want the old prompt", entry.prompt)
+	}
+	select {
+	case <-ch:
+		t.Error("subscribers notified of a failed patch")
+	default:
+	}
+}
+
+func TestPatchTask_StatusGuard(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "old prompt", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskStatus(bg(), task.ID, TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	prompt := "new prompt"
+	err = s.PatchTask(bg(), task.ID, TaskPatch{IfStatus: TaskStatusBacklog, Prompt: &prompt})
+	if !errors.Is(err, ErrPatchStatusChanged) {
+		t.Fatalf("err = %v, want ErrPatchStatusChanged", err)
+	}
+	if got, _ := s.GetTask(bg(), task.ID); got.Prompt != "old prompt" {
+		t.Errorf("prompt = %q, want it unchanged", got.Prompt)
+	}
+}
+
+func TestPatchTask_Blocked(t *testing.T) {
+	s := newTestStore(t)
+	blocked, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "waits on legal", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "free", Timeout: 5}); err != nil {
+		t.Fatal(err)
+	}
+	until := time.Now().Add(time.Hour)
+	if err := s.PatchTask(bg(), blocked.ID, TaskPatch{Blocked: &TaskBlock{Reason: "legal review", Since: time.Now(), Until: &until}}); err != nil {
+		t.Fatal(err)
+	}
+
+	yes, no := true, false
+	if page, _ := s.TasksPage(bg(), nil, TaskPageOptions{Blocked: &yes}); page.Total != 1 || page.Tasks[0].ID != blocked.ID {
+		t.Errorf("blocked page = %+v, want only the blocked task", page.Tasks)
+	}
+	if page, _ := s.TasksPage(bg(), nil, TaskPageOptions{Blocked: &no}); page.Total != 1 || page.Tasks[0].ID == blocked.ID {
+		t.Errorf("unblocked page = %+v, want only the free task", page.Tasks)
+	}
+
+	got, _ := s.GetTask(bg(), blocked.ID)
+	if got.Blocked.ReminderDue(time.Now()) || !got.Blocked.ReminderDue(until) {
+		t.Error("reminder should be due at the unblock date, not before")
+	}
+	if err := s.MarkUnblockReminded(bg(), blocked.ID, until); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetTask(bg(), blocked.ID); got.Blocked.ReminderDue(until.Add(time.Hour)) || got.Blocked.Reason != "legal review" {
+		t.Errorf("after the reminder: block = %+v", got.Blocked)
+	}
+
+	// Starting the task resolves the block.
+	if err := s.UpdateTaskStatus(bg(), blocked.ID, TaskStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetTask(bg(), blocked.ID); got.IsBlocked() {
+		t.Errorf("block = %+v after leaving the backlog, want nil", got.Blocked)
+	}
+}
@@ -986,6 +986,108 @@ func TestResetTaskForRetry_PreservesMountWorktrees(t *testing.T) {
 	}
 }
 
+// ─────────────────────────────────────────────────────────────────────────────
+// SkipCommit
+// ─────────────────────────────────────────────────────────────────────────────
+
+func TestCreateAndPatchTask_SkipCommit(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "explore", Timeout: 5, SkipCommit: true})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if !got.SkipCommit {
+		t.Fatal("SkipCommit should persist after create")
+	}
+
+	off := false
+	if err := s.PatchTask(bg(), task.ID, TaskPatch{SkipCommit: &off}); err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+	got, _ = s.GetTask(bg(), task.ID)
+	if got.SkipCommit {
+		t.Error("SkipCommit should be cleared by the patch")
+	}
+}
+
+func TestCreateTask_PullRequestMode(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5, MergeMode: MergeModePullRequest})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	urls := map[string]string{"/repo/a": "https://github.com/o/a/pull/7"}
+	if err := s.UpdateTaskPullRequestURLs(bg(), task.ID, urls); err != nil {
+		t.Fatalf("UpdateTaskPullRequestURLs: %v", err)
+	}
+	urls["/repo/a"] = "mutated"
+	got, _ := s.GetTask(bg(), task.ID)
+	if got.MergeMode != MergeModePullRequest {
+		t.Errorf("MergeMode = %q", got.MergeMode)
+	}
+	if got.PullRequestURLs["/repo/a"] != "https://github.com/o/a/pull/7" {
+		t.Errorf("PullRequestURLs = %v", got.PullRequestURLs)
+	}
+
+	merge := MergeModeMerge
+	if err := s.PatchTask(bg(), task.ID, TaskPatch{MergeMode: &merge}); err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+	if got, _ := s.GetTask(bg(), task.ID); got.MergeMode != MergeModeMerge {
+		t.Errorf("MergeMode after patch = %q", got.MergeMode)
+	}
+}
+
+func TestNormalizeMergeMode(t *testing.T) {
+	for in, want := range map[string]MergeMode{"": MergeModeMerge, "merge": MergeModeMerge, " pull_request ": MergeModePullRequest} {
+		if got, err := NormalizeMergeMode(in); err != nil || got != want {
+			t.Errorf("NormalizeMergeMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := NormalizeMergeMode("squash"); err == nil {
+		t.Error("unknown mode accepted")
+	}
+}
+
+func TestApproveTaskMerge_ClearedOnRetry(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 5, RequireApproval: true})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if err := s.ApproveTaskMerge(bg(), task.ID); err != nil {
+		t.Fatalf("ApproveTaskMerge: %v", err)
+	}
+	if got, _ := s.GetTask(bg(), task.ID); !got.RequireApproval || got.ApprovedAt == nil {
+		t.Fatalf("after approval: require_approval %v, approved_at %v", got.RequireApproval, got.ApprovedAt)
+	}
+	if err := s.ResetTaskForRetry(bg(), task.ID, "again", false); err != nil {
+		t.Fatalf("ResetTaskForRetry: %v", err)
+	}
+	if got, _ := s.GetTask(bg(), task.ID); got.ApprovedAt != nil {
+		t.Errorf("approved_at survived retry: %v", got.ApprovedAt)
+	}
+}
+
+func TestTask_RetainsWorktree(t *testing.T) {
+	cases := []struct {
+		name string
+		task Task
+		want bool
+	}{
+		{"done skip-commit", Task{SkipCommit: true, Status: TaskStatusDone}, true},
+		{"done committed", Task{Status: TaskStatusDone}, false},
+		{"waiting skip-commit", Task{SkipCommit: true, Status: TaskStatusWaiting}, false},
+		{"archived skip-commit", Task{SkipCommit: true, Status: TaskStatusDone, Archived: true}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.task.RetainsWorktree(); got != tc.want {
+			t.Errorf("%s: RetainsWorktree = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // ResetTaskForRetry
 // ─────────────────────────────────────────────────────────────────────────────
@@ -1775,6 +1877,68 @@ func TestUpdateTaskEnvironment_NotFound(t *testing.T) {
 	}
 }
 
+func TestUpdateTaskEstimate_PersistsAcrossLoad(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "estimate me", Timeout: 60})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	est := TaskEstimate{
+		Turns:       6,
+		Tokens:      120000,
+		Minutes:     12.5,
+		Risk:        EstimateRiskMedium,
+		Rationale:   "touches two packages",
+		EstimatedAt: time.Now().Truncate(time.Second),
+	}
+	if err := s.UpdateTaskEstimate(bg(), task.ID, est); err != nil {
+		t.Fatalf("UpdateTaskEstimate: %v", err)
+	}
+
+	// The returned clone must not alias the stored estimate.
+	got, _ := s.GetTask(bg(), task.ID)
+	got.Estimate.Turns = 99
+	if again, _ := s.GetTask(bg(), task.ID); again.Estimate.Turns != 6 {
+		t.Errorf("mutating a GetTask result changed the stored estimate: Turns = %d", again.Estimate.Turns)
+	}
+	s.Close()
+
+	s2, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatalf("NewStore (reload): %v", err)
+	}
+	defer s2.Close()
+
+	reloaded, err := s2.GetTask(bg(), task.ID)
+	if err != nil {
+		t.Fatalf("GetTask after reload: %v", err)
+	}
+	if reloaded.Estimate == nil {
+		t.Fatal("Estimate should not be nil after reload")
+	}
+	gotEst := *reloaded.Estimate
+	if !gotEst.EstimatedAt.Equal(est.EstimatedAt) {
+		t.Errorf("EstimatedAt = %v, want %v", gotEst.EstimatedAt, est.EstimatedAt)
+	}
+	gotEst.EstimatedAt = est.EstimatedAt
+	if gotEst != est {
+		t.Errorf("Estimate = %+v, want %+v", gotEst, est)
+	}
+}
+
+func TestUpdateTaskEstimate_NotFound(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.UpdateTaskEstimate(bg(), uuid.New(), TaskEstimate{}); err == nil {
+		t.Error("expected error for unknown task ID")
+	}
+}
+
 func TestTaskSpecSourcePath_Persists(t *testing.T) {
 	dir := t.TempDir()
 	s, _ := newTestFileStore(t, dir)
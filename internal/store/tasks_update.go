@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,7 +20,7 @@ func (s *Store) UpdateTaskStatus(_ context.Context, id uuid.UUID, status TaskSta
 
 	t, ok := s.tasks[id]
 	if !ok {
-		return fmt.Errorf("task not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
 	if err := TaskMachine.Validate(t.Status, status); err != nil {
 		return err
@@ -27,6 +28,8 @@ func (s *Store) UpdateTaskStatus(_ context.Context, id uuid.UUID, status TaskSta
 	s.removeFromStatusIndex(t.Status, id)
 	t.Status = status
 	s.addToStatusIndex(t.Status, id)
+	clearBlockOutsideBacklog(t)
+	clearStallOutsideInProgress(t)
 	if status == TaskStatusInProgress && t.StartedAt == nil {
 		now := time.Now()
 		t.StartedAt = &now
@@ -131,11 +134,13 @@ func (s *Store) ForceUpdateTaskStatus(_ context.Context, id uuid.UUID, status Ta
 
 	t, ok := s.tasks[id]
 	if !ok {
-		return fmt.Errorf("task not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
 	s.removeFromStatusIndex(t.Status, id)
 	t.Status = status
 	s.addToStatusIndex(t.Status, id)
+	clearBlockOutsideBacklog(t)
+	clearStallOutsideInProgress(t)
 	if status == TaskStatusInProgress && t.StartedAt == nil {
 		now := time.Now()
 		t.StartedAt = &now
@@ -160,6 +165,10 @@ func (s *Store) ForceUpdateTaskStatus(_ context.Context, id uuid.UUID, status Ta
 	return nil
 }
 
+// MaxTaskTitleLength caps, in runes, a title set by a user; generated
+// titles are already a few words.
+const MaxTaskTitleLength = 200
+
 // UpdateTaskTitle sets a task's display title.
 func (s *Store) UpdateTaskTitle(_ context.Context, id uuid.UUID, title string) error {
 	// Compute the lowercased title before acquiring the lock so that the
@@ -176,6 +185,14 @@ func (s *Store) UpdateTaskTitle(_ context.Context, id uuid.UUID, title string) e
 	})
 }
 
+// SetTaskLocalized stores the translated title and summary of a task.
+func (s *Store) SetTaskLocalized(_ context.Context, id uuid.UUID, l LocalizedSummary) error {
+	return s.mutateTask(id, func(t *Task) error {
+		t.Localized = &l
+		return nil
+	})
+}
+
 // UpdateTaskExecutionPrompt sets the full execution prompt used at runtime.
 // When non-empty, the runner passes ExecutionPrompt to the sandbox instead of
 // Prompt, so Prompt can be kept as a short human-readable card label.
@@ -186,6 +203,15 @@ func (s *Store) UpdateTaskExecutionPrompt(_ context.Context, id uuid.UUID, execu
 	})
 }
 
+// UpdateTaskPreambleVersion records which preamble version was prefixed to
+// the task's fresh agent session (0 when none was).
+func (s *Store) UpdateTaskPreambleVersion(_ context.Context, id uuid.UUID, version int) error {
+	return s.mutateTask(id, func(t *Task) error {
+		t.PreambleVersion = version
+		return nil
+	})
+}
+
 // UpdateTaskTurns updates only the turn counter for a task, leaving all other
 // fields (Result, SessionID, StopReason) unchanged. Used during test runs so
 // that the implementation agent's output is not overwritten.
@@ -229,6 +255,97 @@ func (s *Store) UpdateTaskReview(_ context.Context, id uuid.UUID, unresolved int
 	})
 }
 
+// UpdateTaskEstimate records a pre-run effort estimate on a task, replacing
+// any earlier one.
+func (s *Store) UpdateTaskEstimate(_ context.Context, id uuid.UUID, est TaskEstimate) error {
+	return s.mutateTask(id, func(t *Task) error {
+		t.Estimate = &est
+		return nil
+	})
+}
+
+// SetTaskExperiment records the task's part in a dark-launch experiment,
+// replacing any earlier one.
+func (s *Store) SetTaskExperiment(_ context.Context, id uuid.UUID, exp Experiment) error {
+	return s.mutateTask(id, func(t *Task) error {
+		t.Experiment = &exp
+		return nil
+	})
+}
+
+// UpdateExperimentDiff records the measured size of an experiment arm's
+// changes. It is a no-op for tasks outside an experiment.
+func (s *Store) UpdateExperimentDiff(_ context.Context, id uuid.UUID, diff ExperimentDiff) error {
+	return s.mutateTask(id, func(t *Task) error {
+		if t.Experiment != nil {
+			t.Experiment.Diff = diff
+		}
+		return nil
+	})
+}
+
+// SetPublishRun records a publish run on the task, replacing the run for the
+// same repository and commit if one exists.
+func (s *Store) SetPublishRun(_ context.Context, id uuid.UUID, run PublishRun) error {
+	run.Artifacts = slices.Clone(run.Artifacts)
+	return s.mutateTask(id, func(t *Task) error {
+		for i := range t.PublishRuns {
+			if t.PublishRuns[i].Repo == run.Repo && t.PublishRuns[i].Commit == run.Commit {
+				t.PublishRuns[i] = run
+				return nil
+			}
+		}
+		t.PublishRuns = append(t.PublishRuns, run)
+		return nil
+	})
+}
+
+// AddApprovalRequest appends an approval request from the agent to the task,
+// numbering it after the existing ones, and returns it as stored.
+func (s *Store) AddApprovalRequest(_ context.Context, id uuid.UUID, req ApprovalRequest) (ApprovalRequest, error) {
+	err := s.mutateTask(id, func(t *Task) error {
+		req.Seq = len(t.Approvals) + 1
+		req.Decision, req.Note, req.DecidedAt = "", "", time.Time{}
+		t.Approvals = append(t.Approvals, req)
+		return nil
+	})
+	return req, err
+}
+
+// DecideApproval records the user's decision on approval request seq. The
+// request must exist and be undecided.
+func (s *Store) DecideApproval(_ context.Context, id uuid.UUID, seq int, decision ApprovalDecision, note string) (ApprovalRequest, error) {
+	var out ApprovalRequest
+	err := s.mutateTask(id, func(t *Task) error {
+		if seq < 1 || seq > len(t.Approvals) {
+			return fmt.Errorf("%w: approval request %d", ErrApprovalNotFound, seq)
+		}
+		a := &t.Approvals[seq-1]
+		if a.Decision != "" {
+			return fmt.Errorf("%w: approval request %d was already %s", ErrApprovalDecided, seq, a.Decision)
+		}
+		a.Decision, a.Note, a.DecidedAt = decision, note, time.Now()
+		out = *a
+		return nil
+	})
+	return out, err
+}
+
+// SetPreviewDeploy records a preview deploy on the task, replacing the
+// deploy for the same repository and commit if one exists.
+func (s *Store) SetPreviewDeploy(_ context.Context, id uuid.UUID, deploy PreviewDeploy) error {
+	return s.mutateTask(id, func(t *Task) error {
+		for i := range t.Previews {
+			if t.Previews[i].Repo == deploy.Repo && t.Previews[i].Commit == deploy.Commit {
+				t.Previews[i] = deploy
+				return nil
+			}
+		}
+		t.Previews = append(t.Previews, deploy)
+		return nil
+	})
+}
+
 // UpdateTaskCriteria sets a task's free-form acceptance Criteria. Callers gate
 // this to backlog status (same constraint as editing the prompt); the store
 // records it unconditionally.
@@ -290,6 +407,37 @@ func (s *Store) UpdateTaskScheduledAt(_ context.Context, id uuid.UUID, scheduled
 	})
 }
 
+// MarkUnblockReminded records that the reminder for the task's unblock date
+// fired at. It is a no-op when the task is no longer blocked.
+func (s *Store) MarkUnblockReminded(_ context.Context, id uuid.UUID, at time.Time) error {
+	return s.mutateTask(id, func(t *Task) error {
+		if t.Blocked == nil {
+			return nil
+		}
+		b := *t.Blocked
+		b.RemindedAt = &at
+		t.Blocked = &b
+		return nil
+	})
+}
+
+// clearBlockOutsideBacklog drops the user's block once the task has left
+// the backlog: starting or cancelling a blocked task resolves the block.
+func clearBlockOutsideBacklog(t *Task) {
+	if t.Status != TaskStatusBacklog {
+		t.Blocked = nil
+	}
+}
+
+// clearStallOutsideInProgress drops the stall watchdog's flag once the task
+// is no longer running, so a turn that ended while stalled does not keep
+// the task listed as stalled.
+func clearStallOutsideInProgress(t *Task) {
+	if t.Status != TaskStatusInProgress {
+		t.StalledAt = nil
+	}
+}
+
 // UpdateTaskDependsOn sets the list of task UUID strings that must all reach
 // TaskStatusDone before this task is auto-promoted. An empty or nil slice clears
 // all dependencies.
@@ -324,18 +472,27 @@ func (s *Store) UpdateTaskTags(_ context.Context, id uuid.UUID, tags []string) e
 // AreDependenciesSatisfied reports whether every task listed in t.DependsOn has
 // status TaskStatusDone. A missing or malformed dependency UUID is treated as
 // unsatisfied to avoid silent unblocking.
-func (s *Store) AreDependenciesSatisfied(_ context.Context, id uuid.UUID) (bool, error) {
+func (s *Store) AreDependenciesSatisfied(ctx context.Context, id uuid.UUID) (bool, error) {
+	unmet, err := s.UnmetDependencies(ctx, id)
+	return err == nil && len(unmet) == 0, err
+}
+
+// UnmetDependencies returns the entries of the task's DependsOn that are not
+// done yet, in order. A missing or malformed dependency counts as unmet.
+func (s *Store) UnmetDependencies(_ context.Context, id uuid.UUID) ([]string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	t, ok := s.tasks[id]
 	if !ok {
-		return false, fmt.Errorf("task not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
+	var unmet []string
 	for _, depStr := range t.DependsOn {
 		depID, err := uuid.Parse(depStr)
 		if err != nil {
-			return false, nil // malformed UUID → unsatisfied
+			unmet = append(unmet, depStr) // malformed UUID → unsatisfied
+			continue
 		}
 		dep, ok := s.tasks[depID]
 		if !ok {
@@ -343,13 +500,14 @@ func (s *Store) AreDependenciesSatisfied(_ context.Context, id uuid.UUID) (bool,
 			// prevent silent unblocking. In normal operation, DeleteTask
 			// cleans up orphaned dependency references, so this path only
 			// fires for race conditions or data corruption.
-			return false, nil
+			unmet = append(unmet, depStr)
+			continue
 		}
 		if dep.Status != TaskStatusDone {
-			return false, nil
+			unmet = append(unmet, depStr)
 		}
 	}
-	return true, nil
+	return unmet, nil
 }
 
 // UpdateTaskBacklog edits prompt, timeout, fresh_start, mount_worktrees, and budget limits for backlog tasks.
@@ -499,7 +657,7 @@ func (s *Store) ResetTaskForRetry(_ context.Context, id uuid.UUID, newPrompt str
 
 	t, ok := s.tasks[id]
 	if !ok {
-		return fmt.Errorf("task not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
 
 	// Snapshot the current run's outcome into a RetryRecord before resetting
@@ -517,6 +675,7 @@ func (s *Store) ResetTaskForRetry(_ context.Context, id uuid.UUID, newPrompt str
 	// the cause of the lifecycle being retired.
 	retiredCategory := t.FailureCategory
 	t.RetryHistory = append(t.RetryHistory, RetryRecord{
+		Attempt:         NextAttempt(t.RetryHistory),
 		RetiredAt:       time.Now(),
 		Prompt:          t.Prompt,
 		Status:          t.Status,
@@ -549,6 +708,8 @@ func (s *Store) ResetTaskForRetry(_ context.Context, id uuid.UUID, newPrompt str
 	}
 	t.CommitHashes = nil
 	t.BaseCommitHashes = nil
+	t.PullRequestURLs = nil
+	t.ApprovedAt = nil
 	t.IsTestRun = false
 	t.LastTestResult = ""
 	t.PendingTestFeedback = ""
@@ -561,6 +722,9 @@ func (s *Store) ResetTaskForRetry(_ context.Context, id uuid.UUID, newPrompt str
 		FailureCategorySyncError:      defaultAutoRetryBudget[FailureCategorySyncError],
 		FailureCategoryWorktree:       defaultAutoRetryBudget[FailureCategoryWorktree],
 	}
+	// Approval requests belong to the retired session; a pending one must
+	// not hold the next attempt back from automation.
+	t.Approvals = nil
 	t.CurrentRefinement = nil
 	if freshStart {
 		t.RefineSessions = nil
@@ -571,10 +735,26 @@ func (s *Store) ResetTaskForRetry(_ context.Context, id uuid.UUID, newPrompt str
 	if err := s.saveTask(id, t); err != nil {
 		return err
 	}
+	// The retired lifecycle's commits are cleared above; drop the diffs
+	// rendered from them too.
+	if err := s.DeleteDiffSnapshots(id); err != nil {
+		logger.Store.Warn("delete diff snapshots", "task", id, "error", err)
+	}
 	s.notify(t, false)
 	return nil
 }
 
+// NextAttempt returns the attempt number of the lifecycle that follows history.
+// Numbers continue from the newest record so they survive pruning of older
+// entries; histories written before attempts were numbered fall back to their
+// length.
+func NextAttempt(history []RetryRecord) int {
+	if n := len(history); n > 0 && history[n-1].Attempt > 0 {
+		return history[n-1].Attempt + 1
+	}
+	return len(history) + 1
+}
+
 // ArchiveAllDone archives all done and cancelled tasks in a single operation.
 // Returns the IDs of tasks that were archived.
 func (s *Store) ArchiveAllDone(_ context.Context) ([]uuid.UUID, error) {
@@ -616,7 +796,7 @@ func (s *Store) ResumeTask(_ context.Context, id uuid.UUID, timeout *int) error
 
 	t, ok := s.tasks[id]
 	if !ok {
-		return fmt.Errorf("task not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
 
 	s.removeFromStatusIndex(t.Status, id)
@@ -654,6 +834,20 @@ func (s *Store) UpdateRoutineSchedule(_ context.Context, id uuid.UUID, intervalS
 	})
 }
 
+// UpdateRoutineCron sets the cron expression the routine fires on. An
+// empty spec returns the routine to its fixed interval. The spec is stored
+// as given; callers validate it. Returns an error if the task is not a
+// routine card.
+func (s *Store) UpdateRoutineCron(_ context.Context, id uuid.UUID, spec string) error {
+	return s.mutateTask(id, func(t *Task) error {
+		if !t.IsRoutine() {
+			return fmt.Errorf("task %s is not a routine", id)
+		}
+		t.RoutineCron = strings.TrimSpace(spec)
+		return nil
+	})
+}
+
 // UpdateRoutineEnabled toggles whether the scheduler engine should arm a
 // timer for this routine. Disabling clears RoutineNextRun so the UI does
 // not show a stale countdown.
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -54,12 +55,45 @@ func TestMutateTask_UpdatedAtPersistedAfterRefactoredMethod(t *testing.T) {
 	}
 }
 
-// TestMutateTask_ErrorOnTaskNotFound verifies that mutateTask propagates a
-// "task not found" error when the supplied ID does not exist.
+// TestMutateTask_ErrorOnTaskNotFound verifies that mutateTask propagates
+// ErrTaskNotFound when the supplied ID does not exist.
 func TestMutateTask_ErrorOnTaskNotFound(t *testing.T) {
 	s := newTestStore(t)
-	if err := s.UpdateTaskPosition(bg(), uuid.New(), 1); err == nil {
-		t.Fatal("expected error for non-existent task, got nil")
+	if err := s.UpdateTaskPosition(bg(), uuid.New(), 1); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound for non-existent task, got %v", err)
+	}
+}
+
+// TestErrTaskNotFound_Lookups checks that reads, patches, status changes, and
+// trash operations on an unknown task all wrap ErrTaskNotFound.
+func TestErrTaskNotFound_Lookups(t *testing.T) {
+	s := newTestStore(t)
+	id := uuid.New()
+	_, getErr := s.GetTask(bg(), id)
+	checks := map[string]error{
+		"GetTask":          getErr,
+		"PatchTask":        s.PatchTask(bg(), id, TaskPatch{Tags: &[]string{"x"}}),
+		"UpdateTaskStatus": s.UpdateTaskStatus(bg(), id, TaskStatusInProgress),
+		"InsertEvent":      s.InsertEvent(bg(), id, EventTypeSystem, map[string]string{"result": "x"}),
+		"RestoreTask":      s.RestoreTask(bg(), id),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, ErrTaskNotFound) {
+			t.Errorf("%s: got %v, want ErrTaskNotFound", name, err)
+		}
+	}
+}
+
+// TestUpdateTaskStatus_InvalidTransitionIsTyped checks that a rejected
+// transition can be told apart from other failures with errors.Is.
+func TestUpdateTaskStatus_InvalidTransitionIsTyped(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateTaskStatus(bg(), task.ID, TaskStatusDone); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("backlog → done: got %v, want ErrInvalidTransition", err)
 	}
 }
 
@@ -529,3 +563,152 @@ func TestResetTaskForRetry_RefreshesSearchIndex(t *testing.T) {
 		t.Fatalf("expected old prompt to be unfindable: got %d hits, want 0", len(oldHits))
 	}
 }
+
+// TestResetTaskForRetry_NumbersAttempts verifies that retired lifecycles are
+// numbered consecutively starting at 1.
+func TestResetTaskForRetry_NumbersAttempts(t *testing.T) {
+	s := newTestStore(t)
+	ctx := bg()
+
+	task, err := s.CreateTaskWithOptions(ctx, TaskCreateOptions{Prompt: "attempt numbering", Timeout: 15})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	for i := range 3 {
+		if err := s.ForceUpdateTaskStatus(ctx, task.ID, TaskStatusFailed); err != nil {
+			t.Fatalf("ForceUpdateTaskStatus[%d]: %v", i, err)
+		}
+		if err := s.ResetTaskForRetry(ctx, task.ID, "attempt numbering", false); err != nil {
+			t.Fatalf("ResetTaskForRetry[%d]: %v", i, err)
+		}
+	}
+	got, _ := s.GetTask(ctx, task.ID)
+	for i, rec := range got.RetryHistory {
+		if rec.Attempt != i+1 {
+			t.Errorf("RetryHistory[%d].Attempt = %d, want %d", i, rec.Attempt, i+1)
+		}
+	}
+}
+
+func TestNextAttempt(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []RetryRecord
+		want    int
+	}{
+		{"empty", nil, 1},
+		{"numbered", []RetryRecord{{Attempt: 4}, {Attempt: 5}}, 6},
+		{"legacy unnumbered", []RetryRecord{{}, {}}, 3},
+	}
+	for _, tt := range tests {
+		if got := NextAttempt(tt.history); got != tt.want {
+			t.Errorf("%s: NextAttempt = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetPublishRun_UpsertsByRepoAndCommit(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "ship", Timeout: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := PublishRun{Repo: "/src/app", Commit: "abc", Command: "make release", Status: PublishStatusRunning, StartedAt: time.Now()}
+	if err := s.SetPublishRun(bg(), task.ID, run); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPublishRun(bg(), task.ID, PublishRun{Repo: "/src/lib", Commit: "def", Status: PublishStatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+	run.Status, run.Artifacts = PublishStatusSucceeded, []string{"registry.local/app:abc"}
+	if err := s.SetPublishRun(bg(), task.ID, run); err != nil {
+		t.Fatal(err)
+	}
+	run.Artifacts[0] = "mutated by the caller"
+
+	got, _ := s.GetTask(bg(), task.ID)
+	if len(got.PublishRuns) != 2 {
+		t.Fatalf("publish runs = %+v, want one per repo", got.PublishRuns)
+	}
+	first := got.PublishRuns[0]
+	if first.Repo != "/src/app" || first.Status != PublishStatusSucceeded || len(first.Artifacts) != 1 || first.Artifacts[0] != "registry.local/app:abc" {
+		t.Errorf("first run = %+v", first)
+	}
+}
+
+func TestApprovalRequests_AddAndDecide(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "migrate", Timeout: 15, ApprovalGates: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := s.AddApprovalRequest(bg(), task.ID, ApprovalRequest{Turn: 2, Action: "run migration 0042", Decision: ApprovalApproved})
+	if err != nil {
+		t.Fatalf("AddApprovalRequest: %v", err)
+	}
+	if req.Seq != 1 || req.Decision != "" {
+		t.Fatalf("stored request = %+v, want seq 1 and undecided", req)
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if got.PendingApproval() != nil {
+		t.Fatal("request pending outside waiting status")
+	}
+	if err := s.UpdateTaskTurns(bg(), task.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ForceUpdateTaskStatus(bg(), task.ID, TaskStatusWaiting); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.GetTask(bg(), task.ID)
+	if !got.ApprovalGates || got.PendingApproval() == nil || got.PendingApproval().Action != "run migration 0042" {
+		t.Fatalf("task = %+v, want the pending request", got.Approvals)
+	}
+	// Feedback that passes over the request moves the session on.
+	if err := s.UpdateTaskTurns(bg(), task.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = s.GetTask(bg(), task.ID); got.PendingApproval() != nil {
+		t.Fatal("request from an earlier turn should not be pending")
+	}
+
+	if _, err := s.DecideApproval(bg(), task.ID, 2, ApprovalApproved, ""); !errors.Is(err, ErrApprovalNotFound) {
+		t.Fatalf("decide unknown request: err = %v, want ErrApprovalNotFound", err)
+	}
+	decided, err := s.DecideApproval(bg(), task.ID, 1, ApprovalDenied, "use a copy of the table")
+	if err != nil {
+		t.Fatalf("DecideApproval: %v", err)
+	}
+	if decided.Decision != ApprovalDenied || decided.Note != "use a copy of the table" || decided.DecidedAt.IsZero() {
+		t.Fatalf("decided = %+v", decided)
+	}
+	if _, err := s.DecideApproval(bg(), task.ID, 1, ApprovalApproved, ""); !errors.Is(err, ErrApprovalDecided) {
+		t.Fatalf("decide twice: err = %v, want ErrApprovalDecided", err)
+	}
+	if next, err := s.AddApprovalRequest(bg(), task.ID, ApprovalRequest{Turn: 3, Action: "deploy"}); err != nil || next.Seq != 2 {
+		t.Fatalf("next request = %+v, err %v; want seq 2", next, err)
+	}
+}
+
+func TestSetPreviewDeploy_UpsertsByRepoAndCommit(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "restyle", Timeout: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploy := PreviewDeploy{Repo: "/src/web", Commit: "abc", Provider: "vercel", Status: PublishStatusRunning, StartedAt: time.Now()}
+	if err := s.SetPreviewDeploy(bg(), task.ID, deploy); err != nil {
+		t.Fatal(err)
+	}
+	deploy.Status, deploy.URL = PublishStatusSucceeded, "https://web-abc.vercel.app"
+	if err := s.SetPreviewDeploy(bg(), task.ID, deploy); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := s.GetTask(bg(), task.ID)
+	if len(got.Previews) != 1 {
+		t.Fatalf("previews = %+v, want one per repo and commit", got.Previews)
+	}
+	if p := got.Previews[0]; p.Status != PublishStatusSucceeded || p.URL != "https://web-abc.vercel.app" {
+		t.Errorf("preview = %+v", p)
+	}
+}
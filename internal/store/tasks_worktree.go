@@ -37,6 +37,24 @@ func (s *Store) UpdateTaskCommitHashes(_ context.Context, id uuid.UUID, hashes m
 	})
 }
 
+// UpdateTaskPullRequestURLs stores the pull request opened per repo path.
+func (s *Store) UpdateTaskPullRequestURLs(_ context.Context, id uuid.UUID, urls map[string]string) error {
+	return s.mutateTask(id, func(t *Task) error {
+		t.PullRequestURLs = maps.Clone(urls)
+		return nil
+	})
+}
+
+// ApproveTaskMerge records that the merge a RequireApproval task is held
+// for in review has been approved, so the commit pipeline runs through.
+func (s *Store) ApproveTaskMerge(_ context.Context, id uuid.UUID) error {
+	return s.mutateTask(id, func(t *Task) error {
+		now := time.Now()
+		t.ApprovedAt = &now
+		return nil
+	})
+}
+
 // UpdateTaskTestRun sets the IsTestRun flag and LastTestResult on a task atomically.
 // Call with isTestRun=true and empty lastTestResult to mark the start of a test run;
 // call with isTestRun=false and a verdict ("pass"/"fail"/"") when the test completes.
@@ -95,6 +113,20 @@ func (s *Store) SetTaskFailureCategory(_ context.Context, id uuid.UUID, cat Fail
 	})
 }
 
+// SetTaskStalled records when the stall watchdog flagged the task's running
+// turn, or clears the flag when at is nil.
+func (s *Store) SetTaskStalled(_ context.Context, id uuid.UUID, at *time.Time) error {
+	return s.mutateTask(id, func(t *Task) error {
+		if at == nil {
+			t.StalledAt = nil
+			return nil
+		}
+		stalledAt := *at
+		t.StalledAt = &stalledAt
+		return nil
+	})
+}
+
 // UpdateTaskCommitMessage persists the generated git commit message from the commit pipeline.
 func (s *Store) UpdateTaskCommitMessage(_ context.Context, id uuid.UUID, msg string) error {
 	return s.mutateTask(id, func(t *Task) error {
@@ -352,13 +384,21 @@ func (s *Store) ClearFetchFailure(_ context.Context, id uuid.UUID) error {
 	})
 }
 
-// clampTimeout ensures timeout stays in [1, 1440] minutes with a default of 60.
+// MaxTaskTimeoutMinutes is the largest per-task timeout, in minutes.
+const MaxTaskTimeoutMinutes = 1440
+
+// MaxResearchTimeoutMinutes is the time box of a research task: its timeout
+// never exceeds this many minutes.
+const MaxResearchTimeoutMinutes = 30
+
+// clampTimeout ensures timeout stays in [1, MaxTaskTimeoutMinutes] minutes
+// with a default of 60.
 func clampTimeout(v int) int {
 	if v <= 0 {
 		return 60
 	}
-	if v > 1440 {
-		return 1440
+	if v > MaxTaskTimeoutMinutes {
+		return MaxTaskTimeoutMinutes
 	}
 	return v
 }
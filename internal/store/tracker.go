@@ -0,0 +1,36 @@
+package store
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// TrackerIssue is the issue in an external tracker (Jira or Linear) that
+// mirrors a task.
+type TrackerIssue struct {
+	Provider string `json:"provider"`      // tracker name, such as "Jira"
+	ID       string `json:"id"`            // the tracker's own issue ID
+	Key      string `json:"key"`           // human-readable identifier, such as ENG-12
+	URL      string `json:"url,omitempty"` // the issue's web page
+	// Synced fingerprints the title, description, and state last pushed
+	// to the issue, so an unchanged task is not sent again.
+	Synced string `json:"synced,omitempty"`
+}
+
+// SetTaskTrackerIssue records the tracker issue that mirrors a task. A
+// soft-deleted task is updated in place, without notifying subscribers,
+// so closing its issue is remembered across passes.
+func (s *Store) SetTaskTrackerIssue(_ context.Context, id uuid.UUID, issue TrackerIssue) error {
+	s.mu.Lock()
+	if t, ok := s.deleted[id]; ok {
+		defer s.mu.Unlock()
+		t.TrackerIssue = &issue
+		return s.saveTask(id, t)
+	}
+	s.mu.Unlock()
+	return s.mutateTask(id, func(t *Task) error {
+		t.TrackerIssue = &issue
+		return nil
+	})
+}
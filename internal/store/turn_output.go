@@ -0,0 +1,182 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/logger"
+)
+
+// TurnOutput streams one turn's stdout and stderr to the task's
+// outputs/turn-NNNN.json and outputs/turn-NNNN.stderr.txt blobs while the
+// agent runs, so the runner never holds a whole turn in memory. The
+// per-turn size budget applies as in SaveTurnOutput, with the same
+// truncation_notice sentinel. Writes never fail: a storage error is
+// reported by Close, which must be called once the agent's streams are
+// drained.
+type TurnOutput struct {
+	s      *Store
+	taskID uuid.UUID
+	turn   int
+	stdout *turnStream
+	stderr *turnStream
+}
+
+// OpenTurnOutput starts streaming the output of turn for taskID.
+func (s *Store) OpenTurnOutput(taskID uuid.UUID, turn int) *TurnOutput {
+	o := &TurnOutput{s: s, taskID: taskID, turn: turn}
+	o.stdout = &turnStream{o: o, key: turnStdoutKey(turn), max: s.maxTurnOutputBytes}
+	o.stderr = &turnStream{o: o, key: fmt.Sprintf("outputs/turn-%04d.stderr.txt", turn), max: s.maxTurnOutputBytes}
+	return o
+}
+
+// ReadTurnOutput returns the stored stdout of turn for taskID, as written by
+// SaveTurnOutput or a TurnOutput.
+func (s *Store) ReadTurnOutput(taskID uuid.UUID, turn int) ([]byte, error) {
+	return s.backend.ReadBlob(taskID, turnStdoutKey(turn))
+}
+
+func turnStdoutKey(turn int) string { return fmt.Sprintf("outputs/turn-%04d.json", turn) }
+
+// Turn returns the turn number the output belongs to.
+func (o *TurnOutput) Turn() int { return o.turn }
+
+// Stdout returns the writer for the agent's stdout.
+func (o *TurnOutput) Stdout() io.Writer { return o.stdout }
+
+// Stderr returns the writer for the agent's stderr.
+func (o *TurnOutput) Stderr() io.Writer { return o.stderr }
+
+// HasStderr reports whether the agent wrote anything to stderr.
+func (o *TurnOutput) HasStderr() bool { return o.stderr.total > 0 }
+
+// Close commits the stdout blob (empty when the agent printed nothing) and
+// the stderr blob when there was any stderr, then marks the turn truncated
+// if either exceeded the budget. Close is idempotent.
+func (o *TurnOutput) Close() error {
+	if o.stdout.closed {
+		return nil
+	}
+	o.stdout.open() // an empty turn still gets its stdout file, as with SaveTurnOutput
+	err := errors.Join(o.stdout.commit(), o.stderr.commit())
+	if err != nil {
+		return fmt.Errorf("write turn output: %w", err)
+	}
+	if o.stdout.truncated() || o.stderr.truncated() {
+		if err := o.s.MarkTurnTruncated(context.Background(), o.taskID, o.turn); err != nil {
+			logger.Store.Warn("failed to mark turn truncated",
+				"task", o.taskID, "turn", o.turn, "error", err)
+		}
+	}
+	return nil
+}
+
+// turnStream writes one stream of a TurnOutput. With a budget it holds back
+// the current partial line so that, once the budget is exceeded, the blob
+// ends at the last line break within the budget, exactly as
+// truncateTurnData cuts a buffered turn.
+type turnStream struct {
+	o       *TurnOutput
+	key     string
+	max     int // per-turn budget; <= 0 = unlimited
+	w       BlobWriter
+	err     error  // first storage error, reported by Close
+	pending []byte // bytes since the last line break, not yet written
+	written int    // bytes written to w
+	total   int    // bytes received
+	closed  bool
+}
+
+// Write implements io.Writer. It always consumes p so that a storage
+// failure cannot stall the agent's pipe.
+func (t *turnStream) Write(p []byte) (int, error) {
+	n := len(p)
+	if n == 0 || t.closed {
+		return n, nil
+	}
+	t.open()
+	if t.max > 0 && t.total < t.max {
+		within := p[:min(len(p), t.max-t.total)]
+		if i := bytes.LastIndexByte(within, '\n'); i >= 0 {
+			t.write(t.pending)
+			t.write(within[:i+1])
+			t.pending = append(t.pending[:0], within[i+1:]...)
+		} else {
+			t.pending = append(t.pending, within...)
+		}
+	} else if t.max <= 0 {
+		t.write(p)
+	}
+	t.total += n
+	return n, nil
+}
+
+func (t *turnStream) truncated() bool { return t.max > 0 && t.total > t.max }
+
+// open creates the blob writer on first use.
+func (t *turnStream) open() {
+	if t.w != nil || t.err != nil {
+		return
+	}
+	if bs, ok := t.o.s.backend.(BlobStreamer); ok {
+		t.w, t.err = bs.CreateBlob(t.o.taskID, t.key)
+		return
+	}
+	t.w = &bufferedBlob{save: func(data []byte) error { return t.o.s.backend.SaveBlob(t.o.taskID, t.key, data) }}
+}
+
+func (t *turnStream) write(p []byte) {
+	if t.err != nil || len(p) == 0 {
+		return
+	}
+	n, err := t.w.Write(p)
+	t.written += n
+	t.err = err
+}
+
+// commit flushes the held-back line, appends the truncation sentinel when
+// the budget was exceeded, and commits the blob.
+func (t *turnStream) commit() error {
+	t.closed = true
+	if t.w == nil {
+		return t.err
+	}
+	if t.truncated() {
+		logger.Store.Warn("turn output truncated",
+			"task", t.o.taskID, "turn", t.o.turn, "original_bytes", t.total)
+		// The blob ends at the last line break within the budget, which
+		// stands in for the sentinel's leading newline; without one it is
+		// hard-cut at the budget.
+		cutoff := t.written - 1
+		if t.written == 0 {
+			t.write(t.pending)
+			t.write([]byte{'\n'})
+			cutoff = t.max
+		}
+		t.write(fmt.Appendf(nil, `{"type":"system","subtype":"truncation_notice","total_bytes":%d,"truncated_at":%d}`+"\n",
+			t.total, cutoff))
+	} else {
+		t.write(t.pending)
+	}
+	t.pending = nil
+	if t.err != nil {
+		t.w.Abort()
+		return t.err
+	}
+	return t.w.Commit()
+}
+
+// bufferedBlob is a BlobWriter for backends that cannot stream: it holds
+// the data and saves it in one piece on Commit.
+type bufferedBlob struct {
+	buf  bytes.Buffer
+	save func([]byte) error
+}
+
+func (b *bufferedBlob) Write(p []byte) (int, error) { return b.buf.Write(p) }
+func (b *bufferedBlob) Commit() error               { return b.save(b.buf.Bytes()) }
+func (b *bufferedBlob) Abort()                      { b.buf.Reset() }
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestOpenTurnOutput_StreamedMatchesSaved verifies that output written in
+// small chunks is stored, and truncated, exactly as SaveTurnOutput stores
+// the same bytes in one piece.
+func TestOpenTurnOutput_StreamedMatchesSaved(t *testing.T) {
+	inputs := map[string]string{
+		"within budget":   "{\"a\":1}\n{\"b\":2}\n",
+		"cut at line":     strings.Repeat("{\"line\":true}\n", 10),
+		"no line break":   strings.Repeat("x", 200),
+		"long last line":  "{\"a\":1}\n" + strings.Repeat("y", 200),
+		"exactly budget":  strings.Repeat("z", 63) + "\n",
+		"break at budget": strings.Repeat("w", 64) + "\nmore\n",
+	}
+	for name, in := range inputs {
+		t.Run(name, func(t *testing.T) {
+			s := newTestStore(t)
+			s.maxTurnOutputBytes = 64
+			task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "stream", Timeout: 5})
+
+			if err := s.SaveTurnOutput(task.ID, 1, []byte(in), nil); err != nil {
+				t.Fatalf("SaveTurnOutput: %v", err)
+			}
+			o := s.OpenTurnOutput(task.ID, 2)
+			for chunk := range slices.Chunk([]byte(in), 7) {
+				if n, err := o.Stdout().Write(chunk); n != len(chunk) || err != nil {
+					t.Fatalf("Write = (%d, %v)", n, err)
+				}
+			}
+			if err := o.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			saved, _ := s.backend.ReadBlob(task.ID, "outputs/turn-0001.json")
+			streamed, _ := s.backend.ReadBlob(task.ID, "outputs/turn-0002.json")
+			if string(streamed) != string(saved) {
+				t.Fatalf("streamed %q\nsaved    %q", streamed, saved)
+			}
+			got, _ := s.GetTask(bg(), task.ID)
+			if want := len(in) > 64; slices.Contains(got.TruncatedTurns, 2) != want {
+				t.Fatalf("turn 2 truncated = %v, want %v", got.TruncatedTurns, want)
+			}
+		})
+	}
+}
+
+// TestOpenTurnOutput_InvisibleUntilClose verifies a turn being streamed is
+// not listed as an output until Close commits it, and that stderr is only
+// stored when the agent wrote some.
+func TestOpenTurnOutput_InvisibleUntilClose(t *testing.T) {
+	s := newTestStore(t)
+	task, _ := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "stream", Timeout: 5})
+
+	o := s.OpenTurnOutput(task.ID, 1)
+	_, _ = o.Stdout().Write([]byte("{\"partial\":true}\n"))
+	if keys, _ := s.backend.ListBlobs(task.ID, "outputs/"); len(keys) != 0 {
+		t.Fatalf("outputs listed before Close: %v", keys)
+	}
+	if o.HasStderr() {
+		t.Fatal("HasStderr before any stderr")
+	}
+	if err := o.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := o.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+	keys, _ := s.backend.ListBlobs(task.ID, "outputs/")
+	if want := []string{"outputs/turn-0001.json"}; !slices.Equal(keys, want) {
+		t.Fatalf("outputs = %v, want %v", keys, want)
+	}
+}
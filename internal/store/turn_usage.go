@@ -1,7 +1,10 @@
 package store
 
 import (
+	"encoding/json"
+	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
 	"latere.ai/x/wallfacer/internal/pkg/ndjson"
@@ -25,3 +28,42 @@ func (s *Store) AppendTurnUsage(taskID uuid.UUID, rec TurnUsageRecord) error {
 func (s *Store) GetTurnUsages(taskID uuid.UUID) ([]TurnUsageRecord, error) {
 	return ndjson.ReadFile[TurnUsageRecord](s.turnUsagePath(taskID))
 }
+
+// turnExitPrefix is the blob key prefix for per-turn exit metadata. It sits
+// beside the turn outputs but deliberately does not share their "turn-"
+// prefix, which log replay and oversight scan for agent output.
+const turnExitPrefix = "outputs/exit-"
+
+// SaveTurnExit persists the exit metadata of one turn's agent process.
+func (s *Store) SaveTurnExit(taskID uuid.UUID, exit TurnExit) error {
+	data, err := json.Marshal(exit)
+	if err != nil {
+		return err
+	}
+	return s.backend.SaveBlob(taskID, fmt.Sprintf("%s%04d.json", turnExitPrefix, exit.Turn), data)
+}
+
+// GetTurnExits returns the recorded exit metadata for a task keyed by turn.
+// Unreadable entries are skipped; a task with none yields an empty map.
+func (s *Store) GetTurnExits(taskID uuid.UUID) (map[int]TurnExit, error) {
+	keys, err := s.backend.ListBlobs(taskID, turnExitPrefix)
+	if err != nil {
+		return nil, err
+	}
+	exits := make(map[int]TurnExit, len(keys))
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		data, err := s.backend.ReadBlob(taskID, key)
+		if err != nil {
+			continue
+		}
+		var exit TurnExit
+		if err := json.Unmarshal(data, &exit); err != nil {
+			continue
+		}
+		exits[exit.Turn] = exit
+	}
+	return exits, nil
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -192,3 +193,45 @@ func TestGetTurnUsages_CorruptedLineSkipped(t *testing.T) {
 		t.Errorf("expected 2 records (corrupted line skipped), got %d", len(got))
 	}
 }
+
+func TestSaveAndGetTurnExits(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "exit metadata", Timeout: 5})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	if exits, err := s.GetTurnExits(task.ID); err != nil || len(exits) != 0 {
+		t.Fatalf("GetTurnExits before save = %v, %v; want empty", exits, err)
+	}
+	want := TurnExit{Turn: 2, ExitCode: -1, Signal: "killed", OOMKilled: true, DurationMs: 1500, BinaryDigest: "sha256:abc"}
+	if err := s.SaveTurnExit(task.ID, TurnExit{Turn: 1}); err != nil {
+		t.Fatalf("SaveTurnExit: %v", err)
+	}
+	if err := s.SaveTurnExit(task.ID, want); err != nil {
+		t.Fatalf("SaveTurnExit: %v", err)
+	}
+	// Turn output blobs beside the exit records must not be mistaken for them.
+	if err := s.SaveTurnOutput(task.ID, 1, []byte(`{"type":"result"}`), nil); err != nil {
+		t.Fatalf("SaveTurnOutput: %v", err)
+	}
+
+	exits, err := s.GetTurnExits(task.ID)
+	if err != nil {
+		t.Fatalf("GetTurnExits: %v", err)
+	}
+	if len(exits) != 2 {
+		t.Fatalf("got %d exits, want 2: %v", len(exits), exits)
+	}
+	if got := exits[2]; got.ExitCode != want.ExitCode || got.Signal != want.Signal || !got.OOMKilled || got.BinaryDigest != want.BinaryDigest {
+		t.Errorf("exits[2] = %+v, want %+v", got, want)
+	}
+	// Exit blobs must stay out of the "outputs/turn-" listing that log replay
+	// and oversight read as agent output.
+	keys, _ := s.ListBlobs(task.ID, "outputs/turn-")
+	for _, k := range keys {
+		if strings.Contains(k, "exit") {
+			t.Errorf("exit blob %q listed as turn output", k)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxCommentLength caps, in runes, the body of a task comment.
+const MaxCommentLength = 10000
+
+// CommentData is the payload for EventTypeComment events: a note a user
+// leaves on a task's timeline. The author is the event's ActorSub.
+// Mentions lists the principal IDs @mentioned in Body, in order of first
+// appearance.
+type CommentData struct {
+	Body     string   `json:"body"`
+	Mentions []string `json:"mentions,omitempty"`
+}
+
+// mentionRe matches an @mention: "@" at the start of the text or after
+// whitespace or an opening bracket, followed by a principal ID. Requiring
+// the boundary keeps e-mail addresses from reading as mentions.
+var mentionRe = regexp.MustCompile(`(?:^|[\s(\[])@([A-Za-z0-9][A-Za-z0-9._:|-]*)`)
+
+// ParseMentions returns the principal IDs @mentioned in body, without
+// duplicates, in order of first appearance. Trailing sentence punctuation
+// is not part of a mention.
+func ParseMentions(body string) []string {
+	var out []string
+	for _, m := range mentionRe.FindAllStringSubmatch(body, -1) {
+		sub := strings.TrimRight(m[1], ".:-")
+		if sub != "" && !slices.Contains(out, sub) {
+			out = append(out, sub)
+		}
+	}
+	return out
+}
+
+// Recipients returns the principals notified about the task: its creator
+// followed by its watchers, without duplicates.
+func (t *Task) Recipients() []string {
+	out := []string{t.CreatedBy}
+	for _, w := range t.Watchers {
+		if !slices.Contains(out, w) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// WatchTask adds the principals in subs to the task's watchers. The creator
+// and existing watchers are skipped, so watching twice is a no-op.
+func (s *Store) WatchTask(_ context.Context, id uuid.UUID, subs ...string) error {
+	return s.mutateTask(id, func(t *Task) error {
+		for _, sub := range subs {
+			if sub == "" {
+				return fmt.Errorf("watcher must be a signed-in principal")
+			}
+			if sub != t.CreatedBy && !slices.Contains(t.Watchers, sub) {
+				t.Watchers = append(t.Watchers, sub)
+			}
+		}
+		return nil
+	})
+}
+
+// UnwatchTask removes sub from the task's watchers. Unwatching a task the
+// principal does not watch is a no-op.
+func (s *Store) UnwatchTask(_ context.Context, id uuid.UUID, sub string) error {
+	return s.mutateTask(id, func(t *Task) error {
+		t.Watchers = slices.DeleteFunc(t.Watchers, func(w string) bool { return w == sub })
+		if len(t.Watchers) == 0 {
+			t.Watchers = nil
+		}
+		return nil
+	})
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"no mentions here", nil},
+		{"@alice please look", []string{"alice"}},
+		{"cc @bob, @carol.", []string{"bob", "carol"}},
+		{"(@auth0|123) and @auth0|123 again", []string{"auth0|123"}},
+		{"mail bob@example.com instead", nil},
+		{"line one\n@dave: done?", []string{"dave"}},
+		{"a lone @ sign", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseMentions(tt.body); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseMentions(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestWatchTask(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.CreateTaskWithOptions(bg(), TaskCreateOptions{Prompt: "p", Timeout: 15, CreatedBy: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.WatchTask(bg(), task.ID, "bob", "alice", "bob", "carol"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.GetTask(bg(), task.ID)
+	if !slices.Equal(got.Watchers, []string{"bob", "carol"}) {
+		t.Errorf("watchers = %v, want [bob carol] (creator and duplicates skipped)", got.Watchers)
+	}
+	if r := got.Recipients(); !slices.Equal(r, []string{"alice", "bob", "carol"}) {
+		t.Errorf("recipients = %v", r)
+	}
+	if err := s.WatchTask(bg(), task.ID, ""); err == nil {
+		t.Error("an anonymous watcher should be rejected")
+	}
+
+	if err := s.UnwatchTask(bg(), task.ID, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UnwatchTask(bg(), task.ID, "nobody"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.GetTask(bg(), task.ID)
+	if !slices.Equal(got.Watchers, []string{"carol"}) {
+		t.Errorf("after unwatch: watchers = %v, want [carol]", got.Watchers)
+	}
+
+	// Watchers survive a reload.
+	s2, err := newTestFileStore(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := s2.GetTask(bg(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(reloaded.Watchers, []string{"carol"}) {
+		t.Errorf("reloaded watchers = %v", reloaded.Watchers)
+	}
+}
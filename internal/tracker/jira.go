@@ -0,0 +1,270 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"latere.ai/x/wallfacer/internal/pkg/sanitize"
+)
+
+// Jira mirrors tasks through the Jira REST API (v2), which both Jira
+// Cloud and Jira Data Center serve.
+type Jira struct {
+	BaseURL string // site root, such as https://acme.atlassian.net
+	Project string // project key
+	// Email and Token authenticate with HTTP basic auth, the scheme for
+	// Jira Cloud API tokens. With no Email, Token is sent as a bearer
+	// personal access token, the Data Center scheme.
+	Email string
+	Token string
+	// IssueType names the type of created issues; empty means "Task".
+	IssueType string
+	// HTTP is the client used; nil means a 30s-timeout default.
+	HTTP *http.Client
+}
+
+// Name implements [Provider].
+func (j *Jira) Name() string { return "Jira" }
+
+// CreateIssue implements [Provider]. The task link is added as a remote
+// link, which Jira lists under the issue's "Web links".
+func (j *Jira) CreateIssue(ctx context.Context, issue Issue) (Ref, error) {
+	issueType := j.IssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+	var created struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	if _, err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": j.Project},
+			"issuetype":   map[string]string{"name": issueType},
+			"summary":     jiraSummary(issue.Title),
+			"description": fitDescription(issue.Description, jiraMaxDescription),
+		},
+	}, &created); err != nil {
+		return Ref{}, fmt.Errorf("tracker: create Jira issue: %w", err)
+	}
+	ref := Ref{ID: created.ID, Key: created.Key, URL: j.BaseURL + "/browse/" + created.Key}
+	if issue.TaskURL != "" {
+		if _, err := j.do(ctx, http.MethodPost, j.issuePath(ref)+"/remotelink", map[string]any{
+			"object": map[string]string{"url": issue.TaskURL, "title": "Wallfacer task"},
+		}, nil); err != nil {
+			return ref, fmt.Errorf("tracker: link Jira issue %s: %w", ref.Key, err)
+		}
+	}
+	if err := j.transition(ctx, ref, issue.State); err != nil {
+		return ref, err
+	}
+	return ref, nil
+}
+
+// UpdateIssue implements [Provider].
+func (j *Jira) UpdateIssue(ctx context.Context, ref Ref, issue Issue) error {
+	if _, err := j.do(ctx, http.MethodPut, j.issuePath(ref), map[string]any{
+		"fields": map[string]any{
+			"summary":     jiraSummary(issue.Title),
+			"description": fitDescription(issue.Description, jiraMaxDescription),
+		},
+	}, nil); err != nil {
+		return fmt.Errorf("tracker: update Jira issue %s: %w", ref.Key, err)
+	}
+	return j.transition(ctx, ref, issue.State)
+}
+
+// jiraStatus is a workflow status with its category: "new" (to do),
+// "indeterminate" (in progress), or "done".
+type jiraStatus struct {
+	Name           string `json:"name"`
+	StatusCategory struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+// matches reports whether the status represents want. Jira has no
+// canceled category, so a canceled task maps to a done-category status,
+// preferably one whose name says so.
+func (s jiraStatus) matches(want State, strict bool) bool {
+	switch want {
+	case StateBacklog:
+		return s.StatusCategory.Key == "new"
+	case StateInProgress:
+		return s.StatusCategory.Key == "indeterminate"
+	case StateDone:
+		return s.StatusCategory.Key == "done" && (!strict || !isCancelName(s.Name))
+	case StateCanceled:
+		return s.StatusCategory.Key == "done" && (!strict || isCancelName(s.Name))
+	}
+	return false
+}
+
+func isCancelName(name string) bool {
+	name = strings.ToLower(name)
+	for _, w := range []string{"cancel", "won't", "wont", "declin", "reject", "abandon"} {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// transition moves the issue to a status matching want through one of
+// the workflow transitions available from its current status. Statuses
+// whose names distinguish done from canceled are preferred; any status in
+// the right category is the fallback. When no transition leads there the
+// issue is left where it is.
+func (j *Jira) transition(ctx context.Context, ref Ref, want State) error {
+	var current struct {
+		Fields struct {
+			Status jiraStatus `json:"status"`
+		} `json:"fields"`
+	}
+	if _, err := j.do(ctx, http.MethodGet, j.issuePath(ref)+"?fields=status", nil, &current); err != nil {
+		return fmt.Errorf("tracker: read Jira issue %s: %w", ref.Key, err)
+	}
+	cur := current.Fields.Status
+	if cur.matches(want, true) {
+		return nil
+	}
+	var available struct {
+		Transitions []struct {
+			ID string     `json:"id"`
+			To jiraStatus `json:"to"`
+		} `json:"transitions"`
+	}
+	if _, err := j.do(ctx, http.MethodGet, j.issuePath(ref)+"/transitions", nil, &available); err != nil {
+		return fmt.Errorf("tracker: list Jira transitions for %s: %w", ref.Key, err)
+	}
+	// A done issue that should read canceled (or the reverse) only moves
+	// to a status named for it; any other issue takes the category's
+	// first status when none is.
+	id := ""
+	for _, strict := range []bool{true, false} {
+		if !strict && cur.matches(want, false) {
+			break
+		}
+		for _, t := range available.Transitions {
+			if t.To.matches(want, strict) {
+				id = t.ID
+				break
+			}
+		}
+		if id != "" {
+			break
+		}
+	}
+	if id == "" {
+		return nil
+	}
+	if _, err := j.do(ctx, http.MethodPost, j.issuePath(ref)+"/transitions", map[string]any{
+		"transition": map[string]string{"id": id},
+	}, nil); err != nil {
+		return fmt.Errorf("tracker: transition Jira issue %s: %w", ref.Key, err)
+	}
+	return nil
+}
+
+func (j *Jira) issuePath(ref Ref) string {
+	key := ref.Key
+	if key == "" {
+		key = ref.ID
+	}
+	return "/rest/api/2/issue/" + url.PathEscape(key)
+}
+
+// jiraMaxDescription is the most characters Jira accepts in a text
+// field such as the description; a longer one fails with a 400.
+const jiraMaxDescription = 32767
+
+// jiraSummary fits a title into the single-line summary field, which
+// holds at most 255 characters.
+func jiraSummary(title string) string {
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		title = "Untitled task"
+	}
+	return sanitize.Truncate(title, 254)
+}
+
+// do sends an authenticated request with a JSON body (nil for none) and
+// decodes a 2xx JSON response into out (nil to discard it). It returns
+// the response status, zero when no response arrived.
+func (j *Jira) do(ctx context.Context, method, path string, in, out any) (int, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, j.BaseURL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	if j.Email != "" {
+		req.SetBasicAuth(j.Email, j.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.Token)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := j.HTTP
+	if client == nil {
+		client = defaultHTTPClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("api status %d: %s", resp.StatusCode, jiraMessage(data))
+	}
+	if out == nil || len(data) == 0 {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, json.Unmarshal(data, out)
+}
+
+// jiraMessage extracts Jira's {"errorMessages": [...], "errors": {...}}
+// text.
+func jiraMessage(data []byte) string {
+	var payload struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(data, &payload) == nil {
+		msgs := payload.ErrorMessages
+		fields := make([]string, 0, len(payload.Errors))
+		for f := range payload.Errors {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		for _, f := range fields {
+			msgs = append(msgs, f+": "+payload.Errors[f])
+		}
+		if len(msgs) > 0 {
+			return strings.Join(msgs, "; ")
+		}
+	}
+	if s := sanitize.Truncate(strings.TrimSpace(string(data)), 200); s != "" {
+		return s
+	}
+	return "(no body)"
+}
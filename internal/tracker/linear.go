@@ -0,0 +1,219 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"latere.ai/x/wallfacer/internal/pkg/sanitize"
+)
+
+// LinearAPIURL is Linear's GraphQL endpoint.
+const LinearAPIURL = "https://api.linear.app/graphql"
+
+// Linear mirrors tasks through the Linear GraphQL API with a personal
+// API key.
+type Linear struct {
+	APIKey string
+	Team   string // team key, such as ENG
+	// BaseURL is the GraphQL endpoint; empty means [LinearAPIURL].
+	BaseURL string
+	// HTTP is the client used; nil means a 30s-timeout default.
+	HTTP *http.Client
+
+	mu     sync.Mutex
+	teamID string
+	states map[State]string // workflow state ID per State
+}
+
+// Name implements [Provider].
+func (l *Linear) Name() string { return "Linear" }
+
+// CreateIssue implements [Provider]. The task link is added as an issue
+// attachment, which Linear shows in the issue's sidebar.
+func (l *Linear) CreateIssue(ctx context.Context, issue Issue) (Ref, error) {
+	if err := l.resolveTeam(ctx); err != nil {
+		return Ref{}, err
+	}
+	input := map[string]any{"teamId": l.teamID, "title": linearTitle(issue.Title), "description": fitDescription(issue.Description, linearMaxDescription)}
+	if id := l.states[issue.State]; id != "" {
+		input["stateId"] = id
+	}
+	var out struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				ID         string `json:"id"`
+				Identifier string `json:"identifier"`
+				URL        string `json:"url"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	if err := l.graphql(ctx, `mutation($input: IssueCreateInput!) {
+  issueCreate(input: $input) { success issue { id identifier url } }
+}`, map[string]any{"input": input}, &out); err != nil {
+		return Ref{}, fmt.Errorf("tracker: create Linear issue: %w", err)
+	}
+	if !out.IssueCreate.Success {
+		return Ref{}, errors.New("tracker: create Linear issue: not created")
+	}
+	created := out.IssueCreate.Issue
+	ref := Ref{ID: created.ID, Key: created.Identifier, URL: created.URL}
+	if issue.TaskURL != "" {
+		if err := l.graphql(ctx, `mutation($input: AttachmentCreateInput!) {
+  attachmentCreate(input: $input) { success }
+}`, map[string]any{"input": map[string]any{
+			"issueId": ref.ID, "url": issue.TaskURL, "title": "Wallfacer task",
+		}}, nil); err != nil {
+			return ref, fmt.Errorf("tracker: link Linear issue %s: %w", ref.Key, err)
+		}
+	}
+	return ref, nil
+}
+
+// UpdateIssue implements [Provider]. Linear moves an issue to any state
+// of its team, so the status always follows the task.
+func (l *Linear) UpdateIssue(ctx context.Context, ref Ref, issue Issue) error {
+	if err := l.resolveTeam(ctx); err != nil {
+		return err
+	}
+	input := map[string]any{"title": linearTitle(issue.Title), "description": fitDescription(issue.Description, linearMaxDescription)}
+	if id := l.states[issue.State]; id != "" {
+		input["stateId"] = id
+	}
+	if err := l.graphql(ctx, `mutation($id: String!, $input: IssueUpdateInput!) {
+  issueUpdate(id: $id, input: $input) { success }
+}`, map[string]any{"id": ref.ID, "input": input}, nil); err != nil {
+		return fmt.Errorf("tracker: update Linear issue %s: %w", ref.Key, err)
+	}
+	return nil
+}
+
+// linearStateTypes lists, per State, the workflow state types that
+// represent it in order of preference. Linear teams may turn the backlog
+// off, leaving "unstarted" (Todo) as the first state.
+var linearStateTypes = map[State][]string{
+	StateBacklog:    {"backlog", "unstarted"},
+	StateInProgress: {"started"},
+	StateDone:       {"completed"},
+	StateCanceled:   {"canceled"},
+}
+
+// resolveTeam looks up the team's ID and picks, for each State, the
+// first workflow state of the preferred type. The result is cached for
+// the life of the provider.
+func (l *Linear) resolveTeam(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.teamID != "" {
+		return nil
+	}
+	var out struct {
+		Teams struct {
+			Nodes []struct {
+				ID     string `json:"id"`
+				States struct {
+					Nodes []struct {
+						ID       string  `json:"id"`
+						Type     string  `json:"type"`
+						Position float64 `json:"position"`
+					} `json:"nodes"`
+				} `json:"states"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	if err := l.graphql(ctx, `query($key: String!) {
+  teams(filter: { key: { eq: $key } }) { nodes { id states { nodes { id type position } } } }
+}`, map[string]any{"key": l.Team}, &out); err != nil {
+		return fmt.Errorf("tracker: look up Linear team %s: %w", l.Team, err)
+	}
+	if len(out.Teams.Nodes) == 0 {
+		return fmt.Errorf("tracker: no Linear team with key %s", l.Team)
+	}
+	team := out.Teams.Nodes[0]
+	states := map[State]string{}
+	for state, types := range linearStateTypes {
+		for _, typ := range types {
+			best := -1
+			for i, s := range team.States.Nodes {
+				if s.Type == typ && (best < 0 || s.Position < team.States.Nodes[best].Position) {
+					best = i
+				}
+			}
+			if best >= 0 {
+				states[state] = team.States.Nodes[best].ID
+				break
+			}
+		}
+	}
+	l.teamID, l.states = team.ID, states
+	return nil
+}
+
+// linearMaxDescription caps issue descriptions sent to Linear. Linear
+// documents no limit of its own; the cap keeps a huge prompt from turning
+// every update into a request of several megabytes.
+const linearMaxDescription = 100000
+
+func linearTitle(title string) string {
+	if title = strings.TrimSpace(title); title == "" {
+		return "Untitled task"
+	}
+	return title
+}
+
+// graphql sends an authenticated GraphQL request and decodes its data
+// into out (nil to discard it). GraphQL errors in a 200 response are
+// returned as errors.
+func (l *Linear) graphql(ctx context.Context, query string, vars map[string]any, out any) error {
+	data, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	if err != nil {
+		return err
+	}
+	endpoint := l.BaseURL
+	if endpoint == "" {
+		endpoint = LinearAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", l.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	client := l.HTTP
+	if client == nil {
+		client = defaultHTTPClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	var payload struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if jsonErr := json.Unmarshal(body, &payload); jsonErr != nil || len(payload.Errors) > 0 || resp.StatusCode != http.StatusOK {
+		msg := sanitize.Truncate(strings.TrimSpace(string(body)), 200)
+		if len(payload.Errors) > 0 {
+			msg = payload.Errors[0].Message
+		}
+		return fmt.Errorf("api status %d: %s", resp.StatusCode, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(payload.Data, out)
+}
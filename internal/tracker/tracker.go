@@ -0,0 +1,112 @@
+// Package tracker mirrors wallfacer tasks into an external issue tracker.
+// The server's tracker sync creates one issue per task, keeps its title,
+// description, and status in step with the task, and links the issue back
+// to the task on the board, so a team that plans in Jira or Linear sees
+// the agents' work where it already looks.
+//
+// Jira (Cloud and Data Center, REST API v2) and Linear (GraphQL API) are
+// supported, each authenticated by a token from the env file.
+package tracker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"latere.ai/x/pkg/otel"
+)
+
+// ErrNotConfigured is returned by [FromConfig] when no tracker is set up.
+var ErrNotConfigured = errors.New("tracker: not configured")
+
+// State is a tracker-neutral issue status. Each provider maps it to the
+// closest status its workflow offers.
+type State string
+
+const (
+	StateBacklog    State = "backlog"
+	StateInProgress State = "in_progress"
+	StateDone       State = "done"
+	StateCanceled   State = "canceled"
+)
+
+// Issue is the content pushed to a tracker issue.
+type Issue struct {
+	Title       string
+	Description string
+	State       State
+	// TaskURL is the task's page on the board, attached to the issue as a
+	// link when it is created; empty skips the link.
+	TaskURL string
+}
+
+// Ref identifies an issue created by a [Provider].
+type Ref struct {
+	ID  string // the tracker's own issue ID
+	Key string // human-readable identifier, such as ENG-12
+	URL string // the issue's web page
+}
+
+// Provider creates and updates issues in one tracker.
+type Provider interface {
+	// Name is the tracker's display name, such as "Jira".
+	Name() string
+	// CreateIssue opens an issue in issue.State, linked to issue.TaskURL.
+	CreateIssue(ctx context.Context, issue Issue) (Ref, error)
+	// UpdateIssue rewrites the issue's title and description and moves it
+	// to issue.State. A status the workflow cannot reach from the current
+	// one is left unchanged rather than reported as an error.
+	UpdateIssue(ctx context.Context, ref Ref, issue Issue) error
+}
+
+// Config selects and authenticates the tracker.
+type Config struct {
+	JiraURL       string // WALLFACER_JIRA_URL site root, such as https://acme.atlassian.net
+	JiraProject   string // WALLFACER_JIRA_PROJECT project key
+	JiraEmail     string // WALLFACER_JIRA_EMAIL account for a Cloud API token; empty sends the token as a bearer PAT
+	JiraToken     string // WALLFACER_JIRA_TOKEN
+	JiraIssueType string // WALLFACER_JIRA_ISSUE_TYPE; empty means "Task"
+	LinearAPIKey  string // WALLFACER_LINEAR_API_KEY
+	LinearTeam    string // WALLFACER_LINEAR_TEAM team key, such as ENG
+}
+
+// FromConfig returns the provider cfg configures: Jira when its URL,
+// project, and token are all set, otherwise Linear when its key and team
+// are set, otherwise [ErrNotConfigured].
+func FromConfig(cfg Config) (Provider, error) {
+	switch {
+	case cfg.JiraURL != "" && cfg.JiraProject != "" && cfg.JiraToken != "":
+		return &Jira{
+			BaseURL:   strings.TrimRight(cfg.JiraURL, "/"),
+			Project:   cfg.JiraProject,
+			Email:     cfg.JiraEmail,
+			Token:     cfg.JiraToken,
+			IssueType: cfg.JiraIssueType,
+		}, nil
+	case cfg.LinearAPIKey != "" && cfg.LinearTeam != "":
+		return &Linear{APIKey: cfg.LinearAPIKey, Team: cfg.LinearTeam}, nil
+	}
+	return nil, ErrNotConfigured
+}
+
+// descriptionCutNote ends a description that was cut to fit a tracker.
+const descriptionCutNote = "\n\n[Truncated; the full prompt is on the board.]"
+
+// fitDescription cuts desc to at most limit characters, ending a cut one
+// with descriptionCutNote, so an oversized prompt is mirrored in part
+// instead of failing every update of its issue.
+func fitDescription(desc string, limit int) string {
+	runes := []rune(desc)
+	if len(runes) <= limit {
+		return desc
+	}
+	keep := max(limit-utf8.RuneCountInString(descriptionCutNote), 0)
+	return string(runes[:keep]) + descriptionCutNote
+}
+
+func defaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second, Transport: otel.Transport(nil)}
+}
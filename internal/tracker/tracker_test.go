@@ -0,0 +1,250 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFromConfig(t *testing.T) {
+	p, err := FromConfig(Config{JiraURL: "https://acme.atlassian.net/", JiraProject: "ENG", JiraToken: "tok", LinearAPIKey: "lin", LinearTeam: "ENG"})
+	if err != nil || p.Name() != "Jira" || p.(*Jira).BaseURL != "https://acme.atlassian.net" {
+		t.Errorf("Jira config: %v, %v", p, err)
+	}
+	if p, err := FromConfig(Config{JiraURL: "https://acme.atlassian.net", LinearAPIKey: "lin", LinearTeam: "ENG"}); err != nil || p.Name() != "Linear" {
+		t.Errorf("incomplete Jira config should fall back to Linear: %v, %v", p, err)
+	}
+	if _, err := FromConfig(Config{LinearAPIKey: "lin"}); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("err = %v, want ErrNotConfigured", err)
+	}
+}
+
+// jiraServer fakes an issue whose status is changed only by transitions.
+type jiraServer struct {
+	t           *testing.T
+	status      jiraStatus
+	transitions map[string]jiraStatus // id -> target status
+	fields      map[string]any
+	links       []string
+}
+
+func newJiraStatus(name, category string) jiraStatus {
+	s := jiraStatus{Name: name}
+	s.StatusCategory.Key = category
+	return s
+}
+
+func (s *jiraServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "me@acme.com" || pass != "tok" {
+		s.t.Errorf("%s %s: missing basic auth", r.Method, r.URL.Path)
+	}
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+		s.fields = body["fields"].(map[string]any)
+		_, _ = w.Write([]byte(`{"id":"10001","key":"ENG-7"}`))
+	case r.Method == http.MethodPut && r.URL.Path == "/rest/api/2/issue/ENG-7":
+		s.fields = body["fields"].(map[string]any)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/ENG-7/remotelink":
+		s.links = append(s.links, body["object"].(map[string]any)["url"].(string))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/ENG-7":
+		_ = json.NewEncoder(w).Encode(map[string]any{"fields": map[string]any{"status": s.status}})
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/ENG-7/transitions":
+		var list []map[string]any
+		for _, id := range []string{"11", "21", "31", "41"} {
+			if to, ok := s.transitions[id]; ok {
+				list = append(list, map[string]any{"id": id, "to": to})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"transitions": list})
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/ENG-7/transitions":
+		id := body["transition"].(map[string]any)["id"].(string)
+		s.status = s.transitions[id]
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestJira_CreateAndUpdate(t *testing.T) {
+	fake := &jiraServer{
+		t:      t,
+		status: newJiraStatus("To Do", "new"),
+		transitions: map[string]jiraStatus{
+			"11": newJiraStatus("To Do", "new"),
+			"21": newJiraStatus("In Progress", "indeterminate"),
+			"31": newJiraStatus("Done", "done"),
+			"41": newJiraStatus("Won't Do", "done"),
+		},
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	j := &Jira{BaseURL: srv.URL, Project: "ENG", Email: "me@acme.com", Token: "tok", HTTP: srv.Client()}
+	ctx := context.Background()
+
+	ref, err := j.CreateIssue(ctx, Issue{Title: "Fix\nlogin", Description: "d", State: StateBacklog, TaskURL: "https://wf.example/?task=abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != (Ref{ID: "10001", Key: "ENG-7", URL: srv.URL + "/browse/ENG-7"}) {
+		t.Errorf("ref = %+v", ref)
+	}
+	if fake.fields["summary"] != "Fix login" || fake.fields["issuetype"].(map[string]any)["name"] != "Task" {
+		t.Errorf("fields = %v", fake.fields)
+	}
+	if len(fake.links) != 1 || fake.links[0] != "https://wf.example/?task=abc" {
+		t.Errorf("links = %v", fake.links)
+	}
+
+	for _, tc := range []struct {
+		state State
+		want  string
+	}{
+		{StateInProgress, "In Progress"},
+		{StateCanceled, "Won't Do"},
+		{StateDone, "Done"},
+		{StateBacklog, "To Do"},
+	} {
+		if err := j.UpdateIssue(ctx, ref, Issue{Title: "Fix login", State: tc.state}); err != nil {
+			t.Fatal(err)
+		}
+		if fake.status.Name != tc.want {
+			t.Errorf("%s: status = %q, want %q", tc.state, fake.status.Name, tc.want)
+		}
+	}
+
+	// Without a cancel-named status a done issue stays done, and an
+	// unreachable status is not an error.
+	delete(fake.transitions, "41")
+	fake.status = newJiraStatus("Done", "done")
+	if err := j.UpdateIssue(ctx, ref, Issue{Title: "Fix login", State: StateCanceled}); err != nil || fake.status.Name != "Done" {
+		t.Errorf("canceled without cancel status: %q, %v", fake.status.Name, err)
+	}
+	fake.transitions = nil
+	if err := j.UpdateIssue(ctx, ref, Issue{Title: "Fix login", State: StateInProgress}); err != nil || fake.status.Name != "Done" {
+		t.Errorf("no transition: %q, %v", fake.status.Name, err)
+	}
+}
+
+func TestJira_ErrorMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errorMessages":[],"errors":{"project":"project is required"}}`))
+	}))
+	defer srv.Close()
+	j := &Jira{BaseURL: srv.URL, Token: "pat", HTTP: srv.Client()}
+	_, err := j.CreateIssue(context.Background(), Issue{Title: "T"})
+	if err == nil || !strings.Contains(err.Error(), "project: project is required") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestLinear_CreateAndUpdate(t *testing.T) {
+	var teamLookups int
+	var created, updated map[string]any
+	var attached string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_key" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case strings.Contains(req.Query, "teams("):
+			teamLookups++
+			if req.Variables["key"] != "ENG" {
+				t.Errorf("team key = %v", req.Variables["key"])
+			}
+			_, _ = w.Write([]byte(`{"data":{"teams":{"nodes":[{"id":"team-1","states":{"nodes":[
+				{"id":"s-todo","type":"unstarted","position":1},
+				{"id":"s-review","type":"started","position":3},
+				{"id":"s-doing","type":"started","position":2},
+				{"id":"s-done","type":"completed","position":4},
+				{"id":"s-canceled","type":"canceled","position":5}]}}]}}}`))
+		case strings.Contains(req.Query, "issueCreate"):
+			created = req.Variables["input"].(map[string]any)
+			_, _ = w.Write([]byte(`{"data":{"issueCreate":{"success":true,"issue":{"id":"uuid-1","identifier":"ENG-3","url":"https://linear.app/acme/issue/ENG-3"}}}}`))
+		case strings.Contains(req.Query, "attachmentCreate"):
+			attached = req.Variables["input"].(map[string]any)["url"].(string)
+			_, _ = w.Write([]byte(`{"data":{"attachmentCreate":{"success":true}}}`))
+		case strings.Contains(req.Query, "issueUpdate"):
+			if req.Variables["id"] != "uuid-1" {
+				t.Errorf("update id = %v", req.Variables["id"])
+			}
+			updated = req.Variables["input"].(map[string]any)
+			_, _ = w.Write([]byte(`{"data":{"issueUpdate":{"success":true}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"errors":[{"message":"unknown query"}]}`))
+		}
+	}))
+	defer srv.Close()
+	l := &Linear{APIKey: "lin_key", Team: "ENG", BaseURL: srv.URL, HTTP: srv.Client()}
+	ctx := context.Background()
+
+	ref, err := l.CreateIssue(ctx, Issue{Title: "Fix login", State: StateBacklog, TaskURL: "https://wf.example/?task=abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Key != "ENG-3" || ref.URL != "https://linear.app/acme/issue/ENG-3" {
+		t.Errorf("ref = %+v", ref)
+	}
+	// With the backlog turned off, backlog tasks land in the first
+	// unstarted state.
+	if created["teamId"] != "team-1" || created["stateId"] != "s-todo" {
+		t.Errorf("create input = %v", created)
+	}
+	if attached != "https://wf.example/?task=abc" {
+		t.Errorf("attachment url = %q", attached)
+	}
+
+	if err := l.UpdateIssue(ctx, ref, Issue{Title: "Fix login", State: StateInProgress}); err != nil {
+		t.Fatal(err)
+	}
+	if updated["stateId"] != "s-doing" {
+		t.Errorf("update input = %v", updated)
+	}
+	if err := l.UpdateIssue(ctx, ref, Issue{Title: "Fix login", State: StateCanceled}); err != nil || updated["stateId"] != "s-canceled" {
+		t.Errorf("canceled: %v, %v", updated, err)
+	}
+	if teamLookups != 1 {
+		t.Errorf("team looked up %d times, want 1", teamLookups)
+	}
+}
+
+func TestLinear_GraphQLError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":[{"message":"Authentication required"}]}`))
+	}))
+	defer srv.Close()
+	l := &Linear{APIKey: "bad", Team: "ENG", BaseURL: srv.URL, HTTP: srv.Client()}
+	if _, err := l.CreateIssue(context.Background(), Issue{Title: "T"}); err == nil || !strings.Contains(err.Error(), "Authentication required") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestFitDescription(t *testing.T) {
+	if got := fitDescription("short", 100); got != "short" {
+		t.Errorf("fitDescription(short) = %q", got)
+	}
+	long := strings.Repeat("é", 200)
+	got := fitDescription(long, 100)
+	if n := utf8.RuneCountInString(got); n != 100 {
+		t.Errorf("fitted to %d runes, want 100", n)
+	}
+	if !strings.HasSuffix(got, descriptionCutNote) {
+		t.Errorf("fitted description %q lacks the truncation note", got)
+	}
+}
@@ -0,0 +1,251 @@
+// Package tunnel exposes the local server to the internet through a
+// bring-your-own reverse tunnel: a provider CLI (Tailscale Funnel, ngrok,
+// cloudflared, or any command) runs alongside the server, and the public
+// URL it reports is read from its output.
+//
+// Providers are pluggable: the built-ins are registered at init, [Register]
+// adds more, and [Command] builds one from a user-supplied command line.
+// The package only runs the process; deciding whether exposing the board
+// is safe (an API key must be set) is the caller's job.
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandProvider is the provider name that runs a user-supplied command
+// (see [Command]) instead of a built-in.
+const CommandProvider = "command"
+
+// stopGrace is how long a tunnel process has to exit after an interrupt
+// before it is killed.
+const stopGrace = 5 * time.Second
+
+// outputTailLines is how many trailing output lines an error carries when
+// the tunnel exits without reporting a URL.
+const outputTailLines = 5
+
+// Provider describes one tunnel CLI.
+type Provider struct {
+	// Name is the value of WALLFACER_TUNNEL that selects the provider.
+	Name string
+	// Args returns the command line that tunnels to target, the local
+	// server's URL (e.g. http://127.0.0.1:8080).
+	Args func(target *url.URL) []string
+	// URL matches the public URL in the process's output. When it has a
+	// capture group, the first group is the URL.
+	URL *regexp.Regexp
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// Register adds p to the providers Lookup finds, replacing any provider of
+// the same name.
+func Register(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[p.Name] = p
+}
+
+// Lookup returns the registered provider called name.
+func Lookup(name string) (Provider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[name]
+	return p, ok
+}
+
+// Names returns the registered provider names in sorted order.
+func Names() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// anyHTTPSURL matches the first https URL in a line of output.
+var anyHTTPSURL = regexp.MustCompile(`https://[^\s"'<>]+`)
+
+func init() {
+	Register(Provider{
+		Name: "tailscale",
+		Args: func(target *url.URL) []string {
+			dest := target.String()
+			if target.Scheme == "https" {
+				// The local server's certificate is not valid for 127.0.0.1.
+				dest = "https+insecure://" + target.Host
+			}
+			return []string{"tailscale", "funnel", dest}
+		},
+		URL: regexp.MustCompile(`https://[A-Za-z0-9.-]+\.ts\.net\b`),
+	})
+	Register(Provider{
+		Name: "ngrok",
+		Args: func(target *url.URL) []string {
+			return []string{"ngrok", "http", target.String(), "--log", "stdout", "--log-format", "logfmt"}
+		},
+		URL: regexp.MustCompile(`url=(https://[^\s"]+)`),
+	})
+	Register(Provider{
+		Name: "cloudflared",
+		Args: func(target *url.URL) []string {
+			args := []string{"cloudflared", "tunnel", "--no-autoupdate", "--url", target.String()}
+			if target.Scheme == "https" {
+				args = append(args, "--no-tls-verify")
+			}
+			return args
+		},
+		URL: regexp.MustCompile(`https://[A-Za-z0-9-]+\.trycloudflare\.com\b`),
+	})
+}
+
+// Command returns a provider that runs cmdline, split on whitespace, with
+// {url} and {port} replaced by the local server's URL and port. The first
+// https URL in its output is taken as the public URL.
+func Command(cmdline string) (Provider, error) {
+	fields := strings.Fields(cmdline)
+	if len(fields) == 0 {
+		return Provider{}, errors.New("tunnel command is empty")
+	}
+	return Provider{
+		Name: CommandProvider,
+		Args: func(target *url.URL) []string {
+			r := strings.NewReplacer("{url}", target.String(), "{port}", target.Port())
+			args := make([]string, len(fields))
+			for i, f := range fields {
+				args[i] = r.Replace(f)
+			}
+			return args
+		},
+		URL: anyHTTPSURL,
+	}, nil
+}
+
+// Tunnel is a running tunnel process.
+type Tunnel struct {
+	// URL is the public URL the provider reported.
+	URL *url.URL
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Start runs p's command to tunnel to target and waits up to timeout for
+// it to report its public URL. The process runs until ctx is cancelled,
+// Close is called, or it exits on its own (see Done).
+func Start(ctx context.Context, p Provider, target *url.URL, timeout time.Duration) (*Tunnel, error) {
+	args := p.Args(target)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("tunnel %s: no command", p.Name)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	// An interrupt lets providers such as tailscale funnel remove their
+	// configuration before exiting; WaitDelay kills those that ignore it.
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = stopGrace
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("tunnel %s: %w", p.Name, err)
+	}
+
+	t := &Tunnel{cancel: cancel, done: make(chan struct{})}
+	found := make(chan string, 1)
+	var tailMu sync.Mutex
+	var tail []string
+	go func() {
+		sc := bufio.NewScanner(pr)
+		for sc.Scan() {
+			line := sc.Text()
+			tailMu.Lock()
+			tail = append(tail, line)
+			if len(tail) > outputTailLines {
+				tail = tail[1:]
+			}
+			tailMu.Unlock()
+			if m := p.URL.FindStringSubmatch(line); m != nil {
+				select {
+				case found <- m[len(m)-1]:
+				default:
+				}
+			}
+		}
+		// Keep draining so a chatty provider never blocks on a full pipe.
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+	go func() {
+		t.err = cmd.Wait()
+		_ = pw.Close()
+		close(t.done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case raw := <-found:
+		u, err := url.Parse(strings.TrimRight(raw, "/.,"))
+		if err != nil || u.Host == "" {
+			t.Close()
+			return nil, fmt.Errorf("tunnel %s: reported an invalid URL %q", p.Name, raw)
+		}
+		t.URL = u
+		return t, nil
+	case <-t.done:
+		tailMu.Lock()
+		defer tailMu.Unlock()
+		return nil, fmt.Errorf("tunnel %s exited before reporting a URL (%v): %s", p.Name, t.err, strings.Join(tail, " | "))
+	case <-timer.C:
+		t.Close()
+		return nil, fmt.Errorf("tunnel %s reported no URL within %s", p.Name, timeout)
+	}
+}
+
+// Done is closed when the tunnel process has exited.
+func (t *Tunnel) Done() <-chan struct{} { return t.done }
+
+// Err returns how the tunnel process exited. It is only meaningful once
+// Done is closed.
+func (t *Tunnel) Err() error {
+	<-t.done
+	return t.err
+}
+
+// Close stops the tunnel process and waits for it to exit.
+func (t *Tunnel) Close() {
+	t.cancel()
+	<-t.done
+}
+
+// Target returns the local URL a tunnel forwards to: the loopback address
+// on port, over https when the server serves TLS.
+func Target(port int, tls bool) *url.URL {
+	scheme := "http"
+	if tls {
+		scheme = "https"
+	}
+	return &url.URL{Scheme: scheme, Host: "127.0.0.1:" + strconv.Itoa(port)}
+}
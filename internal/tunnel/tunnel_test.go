@@ -0,0 +1,109 @@
+//go:build !windows
+
+package tunnel
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeScript writes an executable shell script with body and returns its path.
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tunnel.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStart_ReportsURLAndStops(t *testing.T) {
+	script := writeScript(t, `echo "starting tunnel to $1"
+echo "Available on the internet: https://board.example.ts.net/"
+exec sleep 60
+`)
+	p, err := Command(script + " {url}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tun, err := Start(context.Background(), p, Target(8080, false), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tun.URL.String(); got != "https://board.example.ts.net" {
+		t.Errorf("URL = %q", got)
+	}
+	tun.Close()
+	select {
+	case <-tun.Done():
+	default:
+		t.Error("Close returned with the process still running")
+	}
+}
+
+func TestStart_ExitWithoutURL(t *testing.T) {
+	p, err := Command(writeScript(t, "echo 'auth required' >&2\nexit 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Start(context.Background(), p, Target(8080, false), 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "auth required") {
+		t.Fatalf("err = %v, want the exit reported with the process output", err)
+	}
+}
+
+func TestStart_Timeout(t *testing.T) {
+	p, err := Command(writeScript(t, "exec sleep 60\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Start(context.Background(), p, Target(8080, false), 100*time.Millisecond); err == nil {
+		t.Fatal("Start succeeded without a URL")
+	}
+}
+
+func TestBuiltinProviders(t *testing.T) {
+	for _, name := range []string{"cloudflared", "ngrok", "tailscale"} {
+		if !slices.Contains(Names(), name) {
+			t.Errorf("provider %q not registered", name)
+		}
+	}
+	for _, tc := range []struct {
+		name, line, want string
+	}{
+		{"tailscale", "https://host.tail1234.ts.net/", "https://host.tail1234.ts.net"},
+		{"ngrok", `t=2026 lvl=info msg="started tunnel" url=https://ab12.ngrok-free.app`, "https://ab12.ngrok-free.app"},
+		{"cloudflared", "|  https://quiet-river-1234.trycloudflare.com  |", "https://quiet-river-1234.trycloudflare.com"},
+	} {
+		p, _ := Lookup(tc.name)
+		m := p.URL.FindStringSubmatch(tc.line)
+		if m == nil || m[len(m)-1] != tc.want {
+			t.Errorf("%s URL match on %q = %v, want %q", tc.name, tc.line, m, tc.want)
+		}
+	}
+	p, _ := Lookup("tailscale")
+	if args := p.Args(Target(8443, true)); args[len(args)-1] != "https+insecure://127.0.0.1:8443" {
+		t.Errorf("tailscale args for a TLS server = %v", args)
+	}
+}
+
+func TestCommand_Placeholders(t *testing.T) {
+	p, err := Command("my-tunnel --to {url} --port {port}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := p.Args(&url.URL{Scheme: "http", Host: "127.0.0.1:9000"})
+	want := []string{"my-tunnel", "--to", "http://127.0.0.1:9000", "--port", "9000"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Args = %v, want %v", got, want)
+	}
+	if _, err := Command("  "); err == nil {
+		t.Error("empty command accepted")
+	}
+}
@@ -0,0 +1,21 @@
+// Package webhook posts task lifecycle events to outbound webhooks, so
+// board activity can feed a chat channel or a user's own automation.
+//
+// Each URL in WALLFACER_WEBHOOK_URLS receives a JSON [Payload] for every
+// state change, error, finished commit pipeline, and budget pause. With
+// WALLFACER_WEBHOOK_SECRET set, the body is signed with HMAC-SHA256 in the
+// [SignatureHeader]. Deliveries run on one background goroutine from a
+// bounded queue; a failed delivery is logged and not retried.
+//
+// # Connected packages
+//
+// Depends on [store] for the event model. Consumed by [cli], which creates
+// the dispatcher at server start and attaches it to each workspace store
+// with [store.Store.SetEventSink].
+//
+// # Usage
+//
+//	hooks := webhook.New(cfg.WebhookURLs, cfg.WebhookSecret)
+//	s.SetEventSink(hooks.Sink(workspaceKey))
+//	defer hooks.Close()
+package webhook
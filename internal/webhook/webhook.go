@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/logger"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+// Webhook event names, sent as Payload.Event and the EventHeader.
+const (
+	EventStateChange    = "state_change"    // a task changed status
+	EventError          = "error"           // a task recorded an error
+	EventMergeComplete  = "merge_complete"  // a task's commit pipeline finished
+	EventBudgetExceeded = "budget_exceeded" // a task paused at a cost or token budget
+)
+
+const (
+	// EventHeader carries Payload.Event on each delivery.
+	EventHeader = "X-Wallfacer-Event"
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+	// request body keyed with the webhook secret. It is omitted when no
+	// secret is set.
+	SignatureHeader = "X-Wallfacer-Signature"
+)
+
+// queueSize bounds the deliveries waiting to be sent; events beyond it are
+// dropped so a slow receiver never holds up the board.
+const queueSize = 256
+
+// deliveryTimeout bounds one POST to one URL.
+const deliveryTimeout = 10 * time.Second
+
+// Payload is the JSON body posted for each event.
+type Payload struct {
+	Event string `json:"event"`
+	// Workspace is the key of the workspace group whose store recorded
+	// the event.
+	Workspace string          `json:"workspace,omitempty"`
+	TaskID    uuid.UUID       `json:"task_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data,omitempty"` // the task event's data
+}
+
+// Dispatcher posts payloads to a fixed set of URLs from a background
+// goroutine. It is safe for concurrent use.
+type Dispatcher struct {
+	urls   []string
+	secret string
+	client *http.Client
+	queue  chan Payload
+	done   chan struct{}
+	once   sync.Once
+}
+
+// New returns a Dispatcher posting to urls, signing each body with secret
+// when it is non-empty. It returns nil when urls is empty.
+func New(urls []string, secret string) *Dispatcher {
+	if len(urls) == 0 {
+		return nil
+	}
+	d := &Dispatcher{
+		urls:   urls,
+		secret: secret,
+		client: &http.Client{Timeout: deliveryTimeout},
+		queue:  make(chan Payload, queueSize),
+		done:   make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// Sink returns an event sink for the store of workspace group key, for
+// [store.Store.SetEventSink]. Only lifecycle events (see [Classify]) are
+// queued. It returns nil on a nil *Dispatcher.
+func (d *Dispatcher) Sink(key string) func(store.TaskEvent) {
+	if d == nil {
+		return nil
+	}
+	return func(ev store.TaskEvent) {
+		name, ok := Classify(ev)
+		if !ok {
+			return
+		}
+		d.Enqueue(Payload{Event: name, Workspace: key, TaskID: ev.TaskID, CreatedAt: ev.CreatedAt, Data: ev.Data})
+	}
+}
+
+// Enqueue queues p for delivery without blocking. A full queue drops p.
+func (d *Dispatcher) Enqueue(p Payload) {
+	select {
+	case d.queue <- p:
+	default:
+		logger.Main.Warn("webhook: queue full, dropping event", "event", p.Event, "task", p.TaskID)
+	}
+}
+
+// Close stops accepting payloads and waits for the queued ones to be sent.
+// Close on a nil *Dispatcher is a no-op.
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	d.once.Do(func() { close(d.queue) })
+	<-d.done
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	for p := range d.queue {
+		body, err := json.Marshal(p)
+		if err != nil {
+			logger.Main.Warn("webhook: encode payload", "event", p.Event, "task", p.TaskID, "error", err)
+			continue
+		}
+		for _, u := range d.urls {
+			if err := d.post(u, p.Event, body); err != nil {
+				logger.Main.Warn("webhook: deliver", "url", u, "event", p.Event, "task", p.TaskID, "error", err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) post(url, event string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if d.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(d.secret, body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the SignatureHeader value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Classify names the webhook event a task event is delivered as. Every
+// state_change and error event is delivered, as are a pipeline_progress
+// event for the done phase and a system event flagged budget_exceeded;
+// Classify reports false for the rest.
+func Classify(ev store.TaskEvent) (string, bool) {
+	switch ev.EventType {
+	case store.EventTypeStateChange:
+		return EventStateChange, true
+	case store.EventTypeError:
+		return EventError, true
+	case store.EventTypePipelineProgress:
+		var data store.PipelineProgressData
+		if json.Unmarshal(ev.Data, &data) == nil && data.Phase == store.PipelinePhaseDone {
+			return EventMergeComplete, true
+		}
+	case store.EventTypeSystem:
+		var data struct {
+			BudgetExceeded bool `json:"budget_exceeded"`
+		}
+		if json.Unmarshal(ev.Data, &data) == nil && data.BudgetExceeded {
+			return EventBudgetExceeded, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"latere.ai/x/wallfacer/internal/store"
+)
+
+func event(t *testing.T, typ store.EventType, data any) store.TaskEvent {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store.TaskEvent{TaskID: uuid.New(), EventType: typ, Data: raw, CreatedAt: time.Now()}
+}
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		ev   store.TaskEvent
+		want string
+	}{
+		{event(t, store.EventTypeStateChange, store.NewStateChangeData(store.TaskStatusInProgress, store.TaskStatusWaiting, store.TriggerSystem, nil)), EventStateChange},
+		{event(t, store.EventTypeError, map[string]string{"error": "boom"}), EventError},
+		{event(t, store.EventTypePipelineProgress, store.PipelineProgressData{Phase: store.PipelinePhaseDone}), EventMergeComplete},
+		{event(t, store.EventTypePipelineProgress, store.PipelineProgressData{Phase: store.PipelinePhaseStage}), ""},
+		{event(t, store.EventTypeSystem, map[string]any{"message": "over", "budget_exceeded": true}), EventBudgetExceeded},
+		{event(t, store.EventTypeSystem, map[string]string{"result": "hello"}), ""},
+		{event(t, store.EventTypeOutput, map[string]string{"result": "hi"}), ""},
+	} {
+		got, ok := Classify(tc.ev)
+		if got != tc.want || ok != (tc.want != "") {
+			t.Errorf("Classify(%s %s) = %q, %v, want %q", tc.ev.EventType, tc.ev.Data, got, ok, tc.want)
+		}
+	}
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan delivery, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- delivery{r.Header.Clone(), body}
+	}))
+	defer srv.Close()
+
+	d := New([]string{srv.URL}, "s3cret")
+	sink := d.Sink("ws-key")
+	sink(event(t, store.EventTypeOutput, map[string]string{"result": "ignored"}))
+	ev := event(t, store.EventTypeError, map[string]string{"error": "boom"})
+	sink(ev)
+	d.Close()
+
+	if len(got) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(got))
+	}
+	dl := <-got
+	if sig := dl.header.Get(SignatureHeader); sig != Sign("s3cret", dl.body) {
+		t.Errorf("signature = %q, want %q", sig, Sign("s3cret", dl.body))
+	}
+	if e := dl.header.Get(EventHeader); e != EventError {
+		t.Errorf("event header = %q", e)
+	}
+	var p Payload
+	if err := json.Unmarshal(dl.body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Event != EventError || p.Workspace != "ws-key" || p.TaskID != ev.TaskID {
+		t.Errorf("payload = %+v", p)
+	}
+}
+
+func TestNew_NoURLs(t *testing.T) {
+	d := New(nil, "secret")
+	if d != nil {
+		t.Fatal("New with no URLs should return nil")
+	}
+	if d.Sink("k") != nil {
+		t.Error("nil dispatcher should have no sink")
+	}
+	d.Close()
+}
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256("key", "The quick brown fox jumps over the lazy dog").
+	want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got := Sign("key", []byte("The quick brown fox jumps over the lazy dog")); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,28 @@
+// Package webpush implements the server side of the Web Push protocol so the
+// browser can show native notifications for task events while the Wallfacer
+// tab is in the background.
+//
+// It covers the three pieces a push sender needs, using only the standard
+// library: VAPID application-server keys and JWTs (RFC 8292), aes128gcm
+// payload encryption (RFC 8291 / RFC 8188), and a file-backed registry of
+// browser subscriptions keyed by endpoint and scoped to the subscribing
+// user. Keys and subscriptions live under the config directory so they
+// survive restarts; regenerating the keys invalidates every subscription.
+//
+// Per-user notification preferences decide which task events notify and
+// how: right away, after quiet hours, or in a daily digest, optionally
+// routed by task tag. Held notifications wait in a file-backed outbox until
+// [Service.FlushDue] sends them as one summary per user.
+//
+// # Connected packages
+//
+// Depends on [latere.ai/x/wallfacer/internal/pkg/atomicfile] for crash-safe
+// persistence. Consumed by [handler] (subscription endpoints and the task
+// notifier) and [cli] (opens the service at server start).
+//
+// # Usage
+//
+//	svc, err := webpush.Open(configDir)
+//	svc.Registry.Add(sub)
+//	svc.Notify(ctx, userSub, webpush.Message{Title: "Task done", Body: "..."})
+package webpush
@@ -0,0 +1,91 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// recordSize is the aes128gcm record size advertised in the header. Payloads
+// are always sent as a single record, so it only needs to exceed the
+// ciphertext length; 4096 is what browsers and push services expect.
+const recordSize = 4096
+
+// maxPayload is the largest plaintext that fits in one record once the
+// delimiter byte and the 16-byte GCM tag are added.
+const maxPayload = recordSize - 17
+
+// encrypt seals payload for the subscription per RFC 8291 using the
+// aes128gcm content coding (RFC 8188). entropy supplies the salt and the
+// ephemeral sender key.
+func encrypt(payload []byte, keys SubscriptionKeys, entropy io.Reader) ([]byte, error) {
+	if len(payload) > maxPayload {
+		return nil, fmt.Errorf("push payload too large: %d bytes (max %d)", len(payload), maxPayload)
+	}
+	uaRaw, err := b64.DecodeString(keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("decode p256dh: %w", err)
+	}
+	authSecret, err := b64.DecodeString(keys.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("decode auth: %w", err)
+	}
+	uaPub, err := ecdh.P256().NewPublicKey(uaRaw)
+	if err != nil {
+		return nil, fmt.Errorf("parse p256dh: %w", err)
+	}
+
+	asPriv, err := ecdh.P256().GenerateKey(entropy)
+	if err != nil {
+		return nil, err
+	}
+	asPub := asPriv.PublicKey().Bytes()
+	shared, err := asPriv.ECDH(uaPub)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(entropy, salt); err != nil {
+		return nil, err
+	}
+
+	// RFC 8291 §3.4: mix the auth secret and both public keys into the IKM,
+	// then derive the content-encryption key and nonce from it.
+	keyInfo := "WebPush: info\x00" + string(uaRaw) + string(asPub)
+	ikm, err := hkdf.Key(sha256.New, shared, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last (and only) record; no padding follows.
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+
+	// Header: salt(16) | rs(4) | idlen(1) | keyid(65).
+	out := make([]byte, 0, 16+4+1+len(asPub)+len(plaintext)+gcm.Overhead())
+	out = append(out, salt...)
+	out = binary.BigEndian.AppendUint32(out, recordSize)
+	out = append(out, byte(len(asPub)))
+	out = append(out, asPub...)
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
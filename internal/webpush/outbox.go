@@ -0,0 +1,149 @@
+package webpush
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+)
+
+// digestLines caps how many held notifications a summary lists by name; the
+// rest are counted. It keeps the encrypted payload well under the 4 KB push
+// limit.
+const digestLines = 5
+
+// HeldMessage is a notification waiting for its send time: the daily digest
+// or the end of quiet hours.
+type HeldMessage struct {
+	User    string    `json:"user,omitempty"`
+	Message Message   `json:"message"`
+	Due     time.Time `json:"due"`
+}
+
+// Outbox is the file-backed queue of held notifications, so a digest
+// survives a server restart. It is safe for concurrent use.
+type Outbox struct {
+	path string
+
+	mu   sync.Mutex
+	held []HeldMessage
+}
+
+// OpenOutbox loads the outbox stored at path. A missing file yields an empty
+// outbox.
+func OpenOutbox(path string) (*Outbox, error) {
+	o := &Outbox{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &o.held); err != nil {
+		return nil, fmt.Errorf("parse push outbox: %w", err)
+	}
+	return o, nil
+}
+
+// Hold queues msg for user until due. A held message with the same tag and
+// due time is replaced, so a task that changes state twice before the
+// digest is listed once, with its latest state.
+func (o *Outbox) Hold(user string, msg Message, due time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(o.held), func(h HeldMessage) bool {
+		return h.User == user && msg.Tag != "" && h.Message.Tag == msg.Tag && h.Due.Equal(due)
+	})
+	next = append(next, HeldMessage{User: user, Message: msg, Due: due})
+	if err := o.save(next); err != nil {
+		return err
+	}
+	o.held = next
+	return nil
+}
+
+// Len returns the number of held messages.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.held)
+}
+
+// takeDue removes and returns the messages due at now, grouped by user in
+// the order they were held.
+func (o *Outbox) takeDue(now time.Time) (map[string][]Message, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	due := make(map[string][]Message)
+	var keep []HeldMessage
+	for _, h := range o.held {
+		if h.Due.After(now) {
+			keep = append(keep, h)
+			continue
+		}
+		due[h.User] = append(due[h.User], h.Message)
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	if err := o.save(keep); err != nil {
+		return nil, err
+	}
+	o.held = keep
+	return due, nil
+}
+
+func (o *Outbox) save(held []HeldMessage) error {
+	if held == nil {
+		held = []HeldMessage{}
+	}
+	data, err := json.MarshalIndent(held, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(o.path, data, 0o600)
+}
+
+// summarize folds several held messages into one notification listing them.
+// A single message is sent unchanged.
+func summarize(msgs []Message) Message {
+	if len(msgs) == 1 {
+		return msgs[0]
+	}
+	lines := make([]string, 0, digestLines+1)
+	for _, m := range msgs[:min(len(msgs), digestLines)] {
+		lines = append(lines, m.Title+": "+m.Body)
+	}
+	if extra := len(msgs) - digestLines; extra > 0 {
+		lines = append(lines, fmt.Sprintf("and %d more", extra))
+	}
+	return Message{
+		Title: fmt.Sprintf("%d task updates", len(msgs)),
+		Body:  strings.Join(lines, "\n"),
+		Tag:   "wallfacer-digest",
+		URL:   "/",
+		Kind:  "digest",
+	}
+}
+
+// FlushDue sends every held message that is due at now, one summary per
+// user, and returns the number of successful deliveries.
+func (s *Service) FlushDue(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.Outbox.takeDue(now)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for user, msgs := range due {
+		delivered += s.Notify(ctx, user, summarize(msgs))
+	}
+	return delivered, nil
+}
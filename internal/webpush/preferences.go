@@ -0,0 +1,317 @@
+package webpush
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+)
+
+// Events lists the task events that can notify, as reported in
+// Message.Kind: the transitions a task finishes or stops in, the reminder
+// that a blocked task's unblock date has passed, and an @mention in a task
+// comment.
+var Events = []string{"done", "waiting", "failed", "unblock", "mention"}
+
+// DefaultDigestAt is the local time the daily digest is sent when the
+// preferences do not set one.
+const DefaultDigestAt = "09:00"
+
+// Delivery says how a notification reaches the user.
+type Delivery string
+
+// Delivery constants.
+const (
+	// DeliveryPush sends the notification right away (after quiet hours).
+	DeliveryPush Delivery = "push"
+	// DeliveryDigest collects the notification into one daily summary.
+	DeliveryDigest Delivery = "digest"
+	// DeliveryOff drops the notification.
+	DeliveryOff Delivery = "off"
+)
+
+func (d Delivery) valid() bool {
+	return d == DeliveryPush || d == DeliveryDigest || d == DeliveryOff
+}
+
+// Route overrides the delivery of notifications for tasks carrying Tag.
+type Route struct {
+	Tag      string   `json:"tag"`
+	Delivery Delivery `json:"delivery"`
+}
+
+// QuietHours is a daily window, in "HH:MM" local times, during which
+// notifications that would be pushed are held until the window ends. A
+// window whose start is after its end spans midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Preferences control which task notifications a user receives and how.
+// The zero value pushes every event immediately, matching the behaviour
+// before preferences existed.
+type Preferences struct {
+	// Events lists the transitions that notify; empty means all of Events.
+	Events []string `json:"events,omitempty"`
+	// Delivery applies to tasks no route matches; empty means push.
+	Delivery Delivery `json:"delivery,omitempty"`
+	// Routes are checked in order; the first whose tag the task carries wins.
+	Routes     []Route     `json:"routes,omitempty"`
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+	// DigestAt is the "HH:MM" local time of the daily digest.
+	DigestAt string `json:"digest_at,omitempty"`
+	// Timezone is the IANA zone the times above are in; empty means the
+	// server's local zone.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Validate reports the first invalid field, naming it as the JSON key.
+func (p Preferences) Validate() error {
+	for _, e := range p.Events {
+		if !slices.Contains(Events, e) {
+			return fmt.Errorf("events: unknown event %q; want one of %s", e, strings.Join(Events, ", "))
+		}
+	}
+	if p.Delivery != "" && !p.Delivery.valid() {
+		return fmt.Errorf("delivery: unknown delivery %q", p.Delivery)
+	}
+	for i, r := range p.Routes {
+		if strings.TrimSpace(r.Tag) == "" {
+			return fmt.Errorf("routes[%d].tag: required", i)
+		}
+		if !r.Delivery.valid() {
+			return fmt.Errorf("routes[%d].delivery: unknown delivery %q", i, r.Delivery)
+		}
+	}
+	if q := p.QuietHours; q != nil {
+		if _, err := parseClock(q.Start); err != nil {
+			return fmt.Errorf("quiet_hours.start: %w", err)
+		}
+		if _, err := parseClock(q.End); err != nil {
+			return fmt.Errorf("quiet_hours.end: %w", err)
+		}
+	}
+	if p.DigestAt != "" {
+		if _, err := parseClock(p.DigestAt); err != nil {
+			return fmt.Errorf("digest_at: %w", err)
+		}
+	}
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return fmt.Errorf("timezone: %w", err)
+		}
+	}
+	return nil
+}
+
+// Plan decides what happens to a notification of kind for a task with tags
+// at now. It returns false when the notification is dropped. Otherwise the
+// returned time is when to send it: zero for right away, or a later time
+// when it is held for the digest or until quiet hours end.
+func (p Preferences) Plan(kind string, tags []string, now time.Time) (bool, time.Time) {
+	if len(p.Events) > 0 && !slices.Contains(p.Events, kind) {
+		return false, time.Time{}
+	}
+	loc := p.location()
+	switch p.deliveryFor(tags) {
+	case DeliveryOff:
+		return false, time.Time{}
+	case DeliveryDigest:
+		at, err := parseClock(p.DigestAt)
+		if err != nil {
+			at, _ = parseClock(DefaultDigestAt)
+		}
+		return true, nextClock(now.In(loc), at)
+	}
+	if end, ok := p.quietUntil(now.In(loc)); ok {
+		return true, end
+	}
+	return true, time.Time{}
+}
+
+// location returns the zone of the preferences' clock times.
+func (p Preferences) location() *time.Location {
+	if p.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+func (p Preferences) deliveryFor(tags []string) Delivery {
+	for _, r := range p.Routes {
+		for _, tag := range tags {
+			if strings.EqualFold(strings.TrimSpace(r.Tag), tag) {
+				return r.Delivery
+			}
+		}
+	}
+	if p.Delivery == "" {
+		return DeliveryPush
+	}
+	return p.Delivery
+}
+
+// quietUntil returns the end of the quiet-hours window containing now.
+func (p Preferences) quietUntil(now time.Time) (time.Time, bool) {
+	if p.QuietHours == nil {
+		return time.Time{}, false
+	}
+	start, err1 := parseClock(p.QuietHours.Start)
+	end, err2 := parseClock(p.QuietHours.End)
+	if err1 != nil || err2 != nil || start == end {
+		return time.Time{}, false
+	}
+	m := now.Hour()*60 + now.Minute()
+	var quiet bool
+	if start < end {
+		quiet = m >= start && m < end
+	} else {
+		quiet = m >= start || m < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+	return nextClock(now, end), true
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// nextClock returns the first time after now, in now's zone, whose clock
+// reads minutes after midnight.
+func nextClock(now time.Time, minutes int) time.Time {
+	y, mo, d := now.Date()
+	at := time.Date(y, mo, d, minutes/60, minutes%60, 0, 0, now.Location())
+	if !at.After(now) {
+		at = time.Date(y, mo, d+1, minutes/60, minutes%60, 0, 0, now.Location())
+	}
+	return at
+}
+
+// PreferenceEntry is one user's preferences for one board. Board is the
+// workspace group key; empty applies to every board without its own entry.
+type PreferenceEntry struct {
+	User        string      `json:"user,omitempty"`
+	Board       string      `json:"board,omitempty"`
+	Preferences Preferences `json:"preferences"`
+}
+
+// PreferenceStore is the file-backed set of notification preferences. It is
+// safe for concurrent use; every mutation rewrites the file atomically.
+type PreferenceStore struct {
+	path string
+
+	mu      sync.Mutex
+	entries []PreferenceEntry
+}
+
+// OpenPreferences loads the preferences stored at path. A missing file
+// yields an empty store.
+func OpenPreferences(path string) (*PreferenceStore, error) {
+	ps := &PreferenceStore{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ps, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &ps.entries); err != nil {
+		return nil, fmt.Errorf("parse notification preferences: %w", err)
+	}
+	return ps, nil
+}
+
+// Get returns the preferences that apply to user on board: the board's own
+// entry, else the user's entry for every board, else the zero value.
+func (ps *PreferenceStore) Get(user, board string) Preferences {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	var fallback Preferences
+	for _, e := range ps.entries {
+		if e.User != user {
+			continue
+		}
+		if e.Board == board {
+			return e.Preferences
+		}
+		if e.Board == "" {
+			fallback = e.Preferences
+		}
+	}
+	return fallback
+}
+
+// List returns user's entries, the all-boards entry first.
+func (ps *PreferenceStore) List(user string) []PreferenceEntry {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	var out []PreferenceEntry
+	for _, e := range ps.entries {
+		if e.User == user {
+			out = append(out, e)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b PreferenceEntry) int { return strings.Compare(a.Board, b.Board) })
+	return out
+}
+
+// Set stores user's preferences for board, replacing any existing entry.
+func (ps *PreferenceStore) Set(user, board string, p Preferences) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(ps.entries), func(e PreferenceEntry) bool {
+		return e.User == user && e.Board == board
+	})
+	next = append(next, PreferenceEntry{User: user, Board: board, Preferences: p})
+	if err := ps.save(next); err != nil {
+		return err
+	}
+	ps.entries = next
+	return nil
+}
+
+// Delete removes user's entry for board and reports whether one existed.
+func (ps *PreferenceStore) Delete(user, board string) (bool, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(ps.entries), func(e PreferenceEntry) bool {
+		return e.User == user && e.Board == board
+	})
+	if len(next) == len(ps.entries) {
+		return false, nil
+	}
+	if err := ps.save(next); err != nil {
+		return false, err
+	}
+	ps.entries = next
+	return true, nil
+}
+
+func (ps *PreferenceStore) save(entries []PreferenceEntry) error {
+	if entries == nil {
+		entries = []PreferenceEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(ps.path, data, 0o600)
+}
@@ -0,0 +1,155 @@
+package webpush
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+)
+
+// SubscriptionKeys carries the browser's message-encryption keys exactly as
+// PushSubscription.toJSON() reports them (unpadded base64url).
+type SubscriptionKeys struct {
+	P256dh string `json:"p256dh"`
+	Auth   string `json:"auth"`
+}
+
+// Subscription is one browser's push endpoint. User is the principal that
+// registered it (empty in local mode, where every subscription belongs to
+// the single local user).
+type Subscription struct {
+	Endpoint  string           `json:"endpoint"`
+	Keys      SubscriptionKeys `json:"keys"`
+	User      string           `json:"user,omitempty"`
+	UserAgent string           `json:"user_agent,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// Validate checks that the subscription has an https endpoint and both
+// encryption keys with the lengths RFC 8291 requires.
+func (s Subscription) Validate() error {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || u.Host == "" || !strings.EqualFold(u.Scheme, "https") {
+		return errors.New("endpoint must be an https URL")
+	}
+	if p, err := b64.DecodeString(s.Keys.P256dh); err != nil || len(p) != 65 {
+		return errors.New("keys.p256dh must be a base64url-encoded 65-byte P-256 point")
+	}
+	if a, err := b64.DecodeString(s.Keys.Auth); err != nil || len(a) != 16 {
+		return errors.New("keys.auth must be a base64url-encoded 16-byte secret")
+	}
+	return nil
+}
+
+// Registry is a file-backed set of subscriptions keyed by endpoint. It is
+// safe for concurrent use; every mutation rewrites the file atomically.
+type Registry struct {
+	path string
+
+	mu   sync.Mutex
+	subs []Subscription
+}
+
+// OpenRegistry loads the registry stored at path. A missing file yields an
+// empty registry.
+func OpenRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.subs); err != nil {
+		return nil, fmt.Errorf("parse push subscriptions: %w", err)
+	}
+	return r, nil
+}
+
+// Add stores sub, replacing any existing subscription with the same
+// endpoint. Browsers reuse an endpoint when they re-subscribe, so replacing
+// keeps the keys current and lets a different signed-in user take it over.
+func (r *Registry) Add(sub Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(r.subs), func(s Subscription) bool { return s.Endpoint == sub.Endpoint })
+	next = append(next, sub)
+	if err := r.save(next); err != nil {
+		return err
+	}
+	r.subs = next
+	return nil
+}
+
+// Remove deletes the subscription for endpoint when it belongs to user and
+// reports whether one was removed.
+func (r *Registry) Remove(endpoint, user string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(r.subs), func(s Subscription) bool {
+		return s.Endpoint == endpoint && s.User == user
+	})
+	if len(next) == len(r.subs) {
+		return false, nil
+	}
+	if err := r.save(next); err != nil {
+		return false, err
+	}
+	r.subs = next
+	return true, nil
+}
+
+// removeEndpoint drops an endpoint regardless of owner. Used when the push
+// service reports the subscription as gone.
+func (r *Registry) removeEndpoint(endpoint string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(r.subs), func(s Subscription) bool { return s.Endpoint == endpoint })
+	if len(next) == len(r.subs) {
+		return nil
+	}
+	if err := r.save(next); err != nil {
+		return err
+	}
+	r.subs = next
+	return nil
+}
+
+// List returns user's subscriptions, oldest first.
+func (r *Registry) List(user string) []Subscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Subscription
+	for _, s := range r.subs {
+		if s.User == user {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// All returns every subscription regardless of owner.
+func (r *Registry) All() []Subscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.subs)
+}
+
+func (r *Registry) save(subs []Subscription) error {
+	if subs == nil {
+		subs = []Subscription{}
+	}
+	data, err := json.MarshalIndent(subs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(r.path, data, 0o600)
+}
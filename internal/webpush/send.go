@@ -0,0 +1,156 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/logger"
+)
+
+const (
+	// These files live directly under the config dir.
+	keysFile          = "vapid.json"
+	subscriptionsFile = "push-subscriptions.json"
+	preferencesFile   = "notification-preferences.json"
+	outboxFile        = "push-outbox.json"
+
+	// DefaultSubject is the VAPID contact claim. Push services use it to
+	// reach the operator of a misbehaving sender; the project URL is the
+	// only stable contact a self-hosted install has.
+	DefaultSubject = "https://github.com/changkun/wallfacer"
+
+	// defaultTTL is how long a push service keeps an undelivered message.
+	// Task notifications are stale after an hour.
+	defaultTTL = time.Hour
+)
+
+// ErrGone is returned by Send when the push service reports that the
+// subscription no longer exists (HTTP 404 or 410).
+var ErrGone = errors.New("push subscription expired")
+
+// Message is the JSON payload delivered to the service worker.
+type Message struct {
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+	// Tag collapses repeated notifications for the same task into one.
+	Tag string `json:"tag,omitempty"`
+	// URL is opened (or focused) when the notification is clicked.
+	URL string `json:"url,omitempty"`
+	// Kind is the event that produced the message, e.g. "done" or "waiting".
+	Kind string `json:"kind,omitempty"`
+}
+
+// Service bundles the VAPID keys, the subscription registry, the users'
+// notification preferences, the outbox of held notifications, and the HTTP
+// client used to deliver messages.
+type Service struct {
+	Keys        *Keys
+	Registry    *Registry
+	Preferences *PreferenceStore
+	Outbox      *Outbox
+	Subject     string
+	Client      *http.Client
+}
+
+// Open loads (or creates) the VAPID keys, subscription registry,
+// preferences, and outbox under configDir.
+func Open(configDir string) (*Service, error) {
+	keys, err := LoadOrCreateKeys(filepath.Join(configDir, keysFile))
+	if err != nil {
+		return nil, err
+	}
+	reg, err := OpenRegistry(filepath.Join(configDir, subscriptionsFile))
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := OpenPreferences(filepath.Join(configDir, preferencesFile))
+	if err != nil {
+		return nil, err
+	}
+	outbox, err := OpenOutbox(filepath.Join(configDir, outboxFile))
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		Keys:        keys,
+		Registry:    reg,
+		Preferences: prefs,
+		Outbox:      outbox,
+		Subject:     DefaultSubject,
+		Client:      &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Send encrypts msg for sub and posts it to the subscription endpoint.
+func (s *Service) Send(ctx context.Context, sub Subscription, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	body, err := encrypt(payload, sub.Keys, rand.Reader)
+	if err != nil {
+		return err
+	}
+	auth, err := s.Keys.authorization(sub.Endpoint, s.Subject, time.Now())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(defaultTTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push service returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// Notify delivers msg to every subscription owned by user. Subscriptions the
+// push service reports as gone are pruned. It returns the number of
+// successful deliveries; individual failures are logged, not returned, so
+// one dead browser cannot block the others.
+func (s *Service) Notify(ctx context.Context, user string, msg Message) int {
+	delivered := 0
+	for _, sub := range s.Registry.List(user) {
+		err := s.Send(ctx, sub, msg)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, ErrGone):
+			if rmErr := s.Registry.removeEndpoint(sub.Endpoint); rmErr != nil {
+				logger.Push.Warn("prune expired subscription", "error", rmErr)
+			}
+		default:
+			logger.Push.Warn("send failed", "endpoint", sub.Endpoint, "error", err)
+		}
+	}
+	return delivered
+}
@@ -0,0 +1,123 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+)
+
+// vapidTokenTTL is how long a signed VAPID JWT stays valid. RFC 8292 caps
+// the expiry at 24 hours; half of that leaves room for clock skew.
+const vapidTokenTTL = 12 * time.Hour
+
+// b64 is the unpadded base64url encoding used by every Web Push field.
+var b64 = base64.RawURLEncoding
+
+// Keys is a VAPID application-server key pair. Both halves are stored as
+// unpadded base64url: PublicKey is the 65-byte uncompressed P-256 point the
+// browser passes to PushManager.subscribe as applicationServerKey, and
+// PrivateKey is the 32-byte scalar.
+type Keys struct {
+	PublicKey  string `json:"public_key"`
+	PrivateKey string `json:"private_key"`
+
+	priv *ecdsa.PrivateKey
+}
+
+// GenerateKeys creates a fresh VAPID key pair.
+func GenerateKeys() (*Keys, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return keysFromPrivate(priv)
+}
+
+func keysFromPrivate(priv *ecdsa.PrivateKey) (*Keys, error) {
+	rawPriv, err := priv.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	rawPub, err := priv.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &Keys{
+		PublicKey:  b64.EncodeToString(rawPub),
+		PrivateKey: b64.EncodeToString(rawPriv),
+		priv:       priv,
+	}, nil
+}
+
+// LoadOrCreateKeys reads the key pair stored at path, generating and
+// persisting a new one (mode 0600) when the file does not exist.
+func LoadOrCreateKeys(path string) (*Keys, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		k, err := GenerateKeys()
+		if err != nil {
+			return nil, err
+		}
+		out, err := json.MarshalIndent(k, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := atomicfile.WriteSync(path, out, 0o600); err != nil {
+			return nil, fmt.Errorf("write vapid keys: %w", err)
+		}
+		return k, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored Keys
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("parse vapid keys: %w", err)
+	}
+	rawPriv, err := b64.DecodeString(stored.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode vapid private key: %w", err)
+	}
+	priv, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), rawPriv)
+	if err != nil {
+		return nil, fmt.Errorf("parse vapid private key: %w", err)
+	}
+	return keysFromPrivate(priv)
+}
+
+// authorization returns the RFC 8292 Authorization header value for a push
+// to endpoint: a short-lived ES256 JWT whose audience is the push service
+// origin, plus the public key the subscription was created with.
+func (k *Keys) authorization(endpoint, subject string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid push endpoint %q", endpoint)
+	}
+	header, _ := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	claims, _ := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(vapidTokenTTL).Unix(),
+		"sub": subject,
+	})
+	signingInput := b64.EncodeToString(header) + "." + b64.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, k.priv, digest[:])
+	if err != nil {
+		return "", err
+	}
+	// JWS ES256 signatures are the fixed-width R||S concatenation, not DER.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return fmt.Sprintf("vapid t=%s.%s, k=%s", signingInput, b64.EncodeToString(sig), k.PublicKey), nil
+}
@@ -0,0 +1,480 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testBrowser is the user-agent side of a subscription: it owns the private
+// key and auth secret needed to decrypt a push message.
+type testBrowser struct {
+	priv *ecdh.PrivateKey
+	auth []byte
+}
+
+func newTestBrowser(t *testing.T) *testBrowser {
+	t.Helper()
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	_, _ = rand.Read(auth)
+	return &testBrowser{priv: priv, auth: auth}
+}
+
+func (b *testBrowser) keys() SubscriptionKeys {
+	return SubscriptionKeys{
+		P256dh: b64.EncodeToString(b.priv.PublicKey().Bytes()),
+		Auth:   b64.EncodeToString(b.auth),
+	}
+}
+
+// decrypt reverses encrypt following RFC 8291 from the receiver's side.
+func (b *testBrowser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	salt := body[:16]
+	rs := binary.BigEndian.Uint32(body[16:20])
+	if rs != recordSize {
+		t.Fatalf("record size = %d, want %d", rs, recordSize)
+	}
+	idlen := int(body[20])
+	asRaw := body[21 : 21+idlen]
+	ciphertext := body[21+idlen:]
+
+	asPub, err := ecdh.P256().NewPublicKey(asRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := b.priv.ECDH(asPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := "WebPush: info\x00" + string(b.priv.PublicKey().Bytes()) + string(asRaw)
+	ikm, _ := hkdf.Key(sha256.New, shared, b.auth, info, 32)
+	cek, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	nonce, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if plain[len(plain)-1] != 0x02 {
+		t.Fatalf("missing last-record delimiter: %x", plain[len(plain)-1])
+	}
+	return plain[:len(plain)-1]
+}
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	b := newTestBrowser(t)
+	body, err := encrypt([]byte(`{"title":"hi"}`), b.keys(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b.decrypt(t, body)); got != `{"title":"hi"}` {
+		t.Errorf("payload = %q", got)
+	}
+}
+
+func TestEncrypt_RejectsOversizedPayload(t *testing.T) {
+	b := newTestBrowser(t)
+	if _, err := encrypt(make([]byte, maxPayload+1), b.keys(), rand.Reader); err == nil {
+		t.Fatal("expected error for oversized payload")
+	}
+}
+
+func TestLoadOrCreateKeys_PersistsAndReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vapid.json")
+	k1, err := LoadOrCreateKeys(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+	k2, err := LoadOrCreateKeys(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k1.PublicKey != k2.PublicKey || k1.PrivateKey != k2.PrivateKey {
+		t.Error("reloaded keys differ from generated keys")
+	}
+	if raw, _ := b64.DecodeString(k1.PublicKey); len(raw) != 65 || raw[0] != 0x04 {
+		t.Errorf("public key is not an uncompressed P-256 point (len %d)", len(raw))
+	}
+}
+
+// verifyVAPID checks the Authorization header against the public key and
+// returns the decoded JWT claims.
+func verifyVAPID(t *testing.T, header string, k *Keys) map[string]any {
+	t.Helper()
+	rest, ok := strings.CutPrefix(header, "vapid t=")
+	if !ok {
+		t.Fatalf("authorization = %q", header)
+	}
+	token, pub, ok := strings.Cut(rest, ", k=")
+	if !ok || pub != k.PublicKey {
+		t.Fatalf("authorization key mismatch: %q", header)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("jwt has %d parts", len(parts))
+	}
+	sig, _ := b64.DecodeString(parts[2])
+	rawPub, _ := b64.DecodeString(k.PublicKey)
+	ecPub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), rawPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(ecPub, digest[:], r, s) {
+		t.Fatal("jwt signature does not verify")
+	}
+	claimsJSON, _ := b64.DecodeString(parts[1])
+	var claims map[string]any
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func TestKeys_AuthorizationClaims(t *testing.T) {
+	k, err := GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	h, err := k.authorization("https://push.example.com/send/abc?x=1", "mailto:ops@example.com", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := verifyVAPID(t, h, k)
+	if claims["aud"] != "https://push.example.com" {
+		t.Errorf("aud = %v", claims["aud"])
+	}
+	if claims["sub"] != "mailto:ops@example.com" {
+		t.Errorf("sub = %v", claims["sub"])
+	}
+	if exp := int64(claims["exp"].(float64)); exp != now.Add(vapidTokenTTL).Unix() {
+		t.Errorf("exp = %d", exp)
+	}
+}
+
+func TestRegistry_AddReplaceRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subs.json")
+	reg, err := OpenRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := Subscription{Endpoint: "https://push.example.com/a", User: "alice"}
+	if err := reg.Add(a); err != nil {
+		t.Fatal(err)
+	}
+	// Same endpoint re-registered by another user replaces the entry.
+	if err := reg.Add(Subscription{Endpoint: a.Endpoint, User: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := reg.List("alice"); len(got) != 0 {
+		t.Errorf("alice still owns %d subscriptions", len(got))
+	}
+	if got := reg.List("bob"); len(got) != 1 {
+		t.Fatalf("bob owns %d subscriptions, want 1", len(got))
+	}
+
+	reloaded, err := OpenRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.All(); len(got) != 1 || got[0].User != "bob" {
+		t.Fatalf("reloaded = %+v", got)
+	}
+
+	if ok, _ := reg.Remove(a.Endpoint, "alice"); ok {
+		t.Error("alice removed bob's subscription")
+	}
+	if ok, err := reg.Remove(a.Endpoint, "bob"); !ok || err != nil {
+		t.Errorf("Remove = %v, %v", ok, err)
+	}
+	if len(reg.All()) != 0 {
+		t.Error("registry not empty after remove")
+	}
+}
+
+func TestSubscription_Validate(t *testing.T) {
+	b := newTestBrowser(t)
+	valid := Subscription{Endpoint: "https://push.example.com/x", Keys: b.keys()}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid subscription rejected: %v", err)
+	}
+	cases := map[string]Subscription{
+		"http endpoint": {Endpoint: "http://push.example.com/x", Keys: b.keys()},
+		"no endpoint":   {Keys: b.keys()},
+		"short auth":    {Endpoint: valid.Endpoint, Keys: SubscriptionKeys{P256dh: valid.Keys.P256dh, Auth: "AAAA"}},
+		"bad p256dh":    {Endpoint: valid.Endpoint, Keys: SubscriptionKeys{P256dh: "not-a-key", Auth: valid.Keys.Auth}},
+	}
+	for name, sub := range cases {
+		if err := sub.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	svc, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestService_SendDeliversEncryptedMessage(t *testing.T) {
+	svc := newTestService(t)
+	b := newTestBrowser(t)
+
+	var gotBody []byte
+	var gotHeader http.Header
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	svc.Client = srv.Client()
+
+	sub := Subscription{Endpoint: srv.URL + "/push/1", Keys: b.keys()}
+	msg := Message{Title: "Task done", Body: "fix the bug", Tag: "task-1", Kind: "done"}
+	if err := svc.Send(context.Background(), sub, msg); err != nil {
+		t.Fatal(err)
+	}
+	if gotHeader.Get("Content-Encoding") != "aes128gcm" {
+		t.Errorf("Content-Encoding = %q", gotHeader.Get("Content-Encoding"))
+	}
+	if gotHeader.Get("TTL") == "" {
+		t.Error("missing TTL header")
+	}
+	claims := verifyVAPID(t, gotHeader.Get("Authorization"), svc.Keys)
+	if claims["aud"] != srv.URL {
+		t.Errorf("aud = %v, want %s", claims["aud"], srv.URL)
+	}
+	var got Message
+	if err := json.Unmarshal(b.decrypt(t, gotBody), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != msg {
+		t.Errorf("message = %+v, want %+v", got, msg)
+	}
+}
+
+func TestService_NotifyPrunesGoneSubscriptions(t *testing.T) {
+	svc := newTestService(t)
+	b := newTestBrowser(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	svc.Client = srv.Client()
+
+	for _, p := range []string{"/ok", "/gone"} {
+		if err := svc.Registry.Add(Subscription{Endpoint: srv.URL + p, Keys: b.keys(), User: "u"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Another user's subscription must not receive the message.
+	if err := svc.Registry.Add(Subscription{Endpoint: srv.URL + "/other", Keys: b.keys(), User: "v"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := svc.Notify(context.Background(), "u", Message{Title: "x"}); n != 1 {
+		t.Errorf("delivered = %d, want 1", n)
+	}
+	remaining := svc.Registry.List("u")
+	if len(remaining) != 1 || !strings.HasSuffix(remaining[0].Endpoint, "/ok") {
+		t.Errorf("remaining = %+v, want only /ok", remaining)
+	}
+	if len(svc.Registry.List("v")) != 1 {
+		t.Error("other user's subscription was pruned")
+	}
+}
+
+func TestService_SendServerError(t *testing.T) {
+	svc := newTestService(t)
+	b := newTestBrowser(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "bad jwt", http.StatusForbidden)
+	}))
+	defer srv.Close()
+	svc.Client = srv.Client()
+
+	err := svc.Send(context.Background(), Subscription{Endpoint: srv.URL, Keys: b.keys()}, Message{Title: "x"})
+	if err == nil || errors.Is(err, ErrGone) || !strings.Contains(err.Error(), "bad jwt") {
+		t.Errorf("err = %v, want non-gone error carrying the body", err)
+	}
+}
+
+func TestPreferences_Plan(t *testing.T) {
+	utc := time.UTC
+	noon := time.Date(2026, 3, 10, 12, 0, 0, 0, utc)
+	night := time.Date(2026, 3, 10, 23, 30, 0, 0, utc)
+	p := Preferences{
+		Events:     []string{"done", "failed"},
+		Routes:     []Route{{Tag: "urgent", Delivery: DeliveryPush}, {Tag: "chore", Delivery: DeliveryOff}},
+		Delivery:   DeliveryDigest,
+		QuietHours: &QuietHours{Start: "22:00", End: "07:00"},
+		DigestAt:   "18:00",
+		Timezone:   "UTC",
+	}
+	tests := []struct {
+		name     string
+		kind     string
+		tags     []string
+		now      time.Time
+		wantSend bool
+		wantAt   time.Time
+	}{
+		{"unselected event", "waiting", []string{"urgent"}, noon, false, time.Time{}},
+		{"urgent pushes now", "done", []string{"URGENT"}, noon, true, time.Time{}},
+		{"urgent held in quiet hours", "done", []string{"urgent"}, night, true, time.Date(2026, 3, 11, 7, 0, 0, 0, utc)},
+		{"routed off", "failed", []string{"chore"}, noon, false, time.Time{}},
+		{"default digest today", "done", nil, noon, true, time.Date(2026, 3, 10, 18, 0, 0, 0, utc)},
+		{"default digest tomorrow", "done", nil, night, true, time.Date(2026, 3, 11, 18, 0, 0, 0, utc)},
+	}
+	for _, tc := range tests {
+		send, at := p.Plan(tc.kind, tc.tags, tc.now)
+		if send != tc.wantSend || !at.Equal(tc.wantAt) {
+			t.Errorf("%s: Plan = %v, %v; want %v, %v", tc.name, send, at, tc.wantSend, tc.wantAt)
+		}
+	}
+
+	if send, at := (Preferences{}).Plan("waiting", nil, noon); !send || !at.IsZero() {
+		t.Errorf("zero preferences: Plan = %v, %v; want immediate push", send, at)
+	}
+}
+
+func TestPreferences_Validate(t *testing.T) {
+	if err := (Preferences{DigestAt: "08:30", QuietHours: &QuietHours{Start: "22:00", End: "06:00"}, Timezone: "Europe/Berlin"}).Validate(); err != nil {
+		t.Errorf("valid preferences: %v", err)
+	}
+	for field, bad := range map[string]Preferences{
+		"events":             {Events: []string{"archived"}},
+		"delivery":           {Delivery: "email"},
+		"routes[0].tag":      {Routes: []Route{{Tag: "", Delivery: DeliveryPush}}},
+		"routes[0].delivery": {Routes: []Route{{Tag: "x", Delivery: "sms"}}},
+		"quiet_hours.start":  {QuietHours: &QuietHours{Start: "25:00", End: "06:00"}},
+		"digest_at":          {DigestAt: "9am"},
+		"timezone":           {Timezone: "Mars/Olympus"},
+	} {
+		if err := bad.Validate(); err == nil || !strings.HasPrefix(err.Error(), field+":") {
+			t.Errorf("%s: Validate = %v", field, err)
+		}
+	}
+}
+
+func TestPreferenceStore_BoardFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefs.json")
+	ps, err := OpenPreferences(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := Preferences{Delivery: DeliveryDigest}
+	board := Preferences{Delivery: DeliveryOff}
+	if err := ps.Set("u", "", all); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.Set("u", "repo-a", board); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenPreferences(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Get("u", "repo-a"); got.Delivery != DeliveryOff {
+		t.Errorf("board entry = %+v", got)
+	}
+	if got := reopened.Get("u", "repo-b"); got.Delivery != DeliveryDigest {
+		t.Errorf("fallback entry = %+v", got)
+	}
+	if got := reopened.Get("v", "repo-a"); got.Delivery != "" {
+		t.Errorf("other user got %+v", got)
+	}
+	if entries := reopened.List("u"); len(entries) != 2 || entries[0].Board != "" {
+		t.Errorf("List = %+v", entries)
+	}
+	if ok, err := reopened.Delete("u", "repo-a"); !ok || err != nil {
+		t.Errorf("Delete = %v, %v", ok, err)
+	}
+	if ok, _ := reopened.Delete("u", "repo-a"); ok {
+		t.Error("second Delete reported a removal")
+	}
+}
+
+func TestService_FlushDueSummarizes(t *testing.T) {
+	svc := newTestService(t)
+	b := newTestBrowser(t)
+	var got []Message
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var m Message
+		_ = json.Unmarshal(b.decrypt(t, body), &m)
+		got = append(got, m)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	svc.Client = srv.Client()
+	if err := svc.Registry.Add(Subscription{Endpoint: srv.URL + "/u", Keys: b.keys(), User: "u"}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	hold := func(tag string, due time.Time) {
+		t.Helper()
+		if err := svc.Outbox.Hold("u", Message{Title: "Task done", Body: tag, Tag: tag}, due); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hold("task-1", now.Add(-time.Minute))
+	hold("task-1", now.Add(-time.Minute)) // replaces the first
+	hold("task-2", now.Add(-time.Minute))
+	hold("task-3", now.Add(time.Hour))
+
+	n, err := svc.FlushDue(context.Background(), now)
+	if err != nil || n != 1 {
+		t.Fatalf("FlushDue = %d, %v; want one delivery", n, err)
+	}
+	if len(got) != 1 || got[0].Title != "2 task updates" || !strings.Contains(got[0].Body, "task-2") {
+		t.Errorf("delivered %+v", got)
+	}
+	if svc.Outbox.Len() != 1 {
+		t.Errorf("outbox holds %d, want the one not yet due", svc.Outbox.Len())
+	}
+}
@@ -47,6 +47,36 @@ type Workspace struct {
 	Autosubmit    *bool `json:"autosubmit,omitempty"`
 	Autosync      *bool `json:"autosync,omitempty"`
 
+	// Bootstrap is a shell command (e.g. "npm ci" or "go mod download") the
+	// runner executes in each of a task's freshly created worktrees before
+	// the agent's first turn, so the agent does not spend turns installing
+	// dependencies. Empty means no bootstrap step.
+	Bootstrap string `json:"bootstrap,omitempty"`
+
+	// Publish is a shell command (e.g. "make release-snapshot") the runner
+	// executes after a task's changes are merged, in a clean checkout of each
+	// merge commit, to build and push artifacts. Empty means no publish
+	// stage.
+	Publish string `json:"publish,omitempty"`
+
+	// Preview deploys each merge commit to a preview environment after a
+	// task's changes are merged, so reviewers can open the result. It is
+	// "vercel" or "netlify" to use that provider's CLI, or a shell command
+	// that writes the preview URL to $WALLFACER_PREVIEW_URL. Empty means no
+	// preview deploys.
+	Preview string `json:"preview,omitempty"`
+
+	// StageIgnore lists .gitignore-style patterns for untracked paths the
+	// commit pipeline leaves unstaged, on top of .gitignore, so build
+	// artifacts an agent generated (dist/, coverage output) stay out of the
+	// task's commit. See gitutil.StageAll.
+	StageIgnore []string `json:"stage_ignore,omitempty"`
+
+	// Arch forces the CPU architecture ("amd64" or "arm64") the workspace's
+	// agents run as, for toolchains that only ship one of them. Empty means
+	// the host's native architecture. See executor.Platform.
+	Arch string `json:"arch,omitempty"`
+
 	// CreatedBy records the principal sub of the user who first owned
 	// this workspace in cloud mode. Empty on workspaces created pre-cloud or in
 	// local mode. Mirrors store.Task.CreatedBy semantics.
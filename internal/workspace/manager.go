@@ -1,7 +1,9 @@
 package workspace
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
@@ -35,7 +37,7 @@ type activeGroup struct {
 }
 
 // storeHasActiveTasks reports whether the store has any tasks in a non-terminal
-// state (in_progress, committing, waiting). Used alongside taskCount to decide
+// state (in_progress, committing, waiting, review). Used alongside taskCount to decide
 // whether a store can be safely closed.
 func storeHasActiveTasks(s *store.Store) bool {
 	if s == nil {
@@ -43,7 +45,8 @@ func storeHasActiveTasks(s *store.Store) bool {
 	}
 	return s.CountByStatus(store.TaskStatusInProgress) > 0 ||
 		s.CountByStatus(store.TaskStatusCommitting) > 0 ||
-		s.CountByStatus(store.TaskStatusWaiting) > 0
+		s.CountByStatus(store.TaskStatusWaiting) > 0 ||
+		s.CountByStatus(store.TaskStatusReview) > 0
 }
 
 // pendingSwap carries the before/after snapshots and a cleanup callback
@@ -83,6 +86,10 @@ type Manager struct {
 	// newStore is the factory used to open scoped stores. It defaults to
 	// store.NewFileStore and can be replaced in tests to intercept created stores.
 	newStore func(dir string) (*store.Store, error)
+
+	// storeHook is called with each store the manager opens; see
+	// OnStoreOpen. Guarded by mu.
+	storeHook func(key string, s *store.Store)
 }
 
 // NewManager creates a Manager and switches to the initial workspace set.
@@ -291,6 +298,12 @@ func (m *Manager) activate(ws Workspace) (Snapshot, error) {
 		if err != nil {
 			return Snapshot{}, fmt.Errorf("open scoped store: %w", err)
 		}
+		m.mu.RLock()
+		hook := m.storeHook
+		m.mu.RUnlock()
+		if hook != nil {
+			hook(key, s)
+		}
 		swap.next.Store = s
 	}
 
@@ -377,6 +390,25 @@ func (m *Manager) AllActiveSnapshots() []Snapshot {
 	return out
 }
 
+// OnStoreOpen registers fn to be called with the workspace key and store of
+// each workspace group the manager opens from now on, before the store is
+// used, and calls it right away for the groups that are already open. It
+// replaces any earlier hook.
+func (m *Manager) OnStoreOpen(fn func(key string, s *store.Store)) {
+	m.mu.Lock()
+	m.storeHook = fn
+	open := make([]Snapshot, 0, len(m.activeGroups))
+	for _, ag := range m.activeGroups {
+		open = append(open, ag.snapshot)
+	}
+	m.mu.Unlock()
+	for _, snap := range open {
+		if snap.Store != nil {
+			fn(snap.Key, snap.Store)
+		}
+	}
+}
+
 // StoreForKey returns the store for a workspace key, if it is still active.
 func (m *Manager) StoreForKey(key string) (*store.Store, bool) {
 	m.mu.RLock()
@@ -388,6 +420,38 @@ func (m *Manager) StoreForKey(key string) (*store.Store, bool) {
 	return ag.snapshot.Store, ag.snapshot.Store != nil
 }
 
+// ReadStore returns the task store of ws for reading: the open store when
+// the group is active, otherwise one loaded from the group's data directory
+// for this read alone. release closes a store opened here and does nothing
+// for an active one; call it when done. The store is nil, with no error,
+// when ws has never been activated and so has no tasks. Nothing may be
+// written through a store opened here: the group's own store would not see
+// it.
+func (m *Manager) ReadStore(ws Workspace) (s *store.Store, release func(), err error) {
+	key := ws.DataKey
+	if key == "" {
+		key = prompts.WorkspaceDataKey(ws.Folders)
+	}
+	if s, ok := m.StoreForKey(key); ok && !s.IsClosed() {
+		return s, func() {}, nil
+	}
+	if m.dataDir == "" {
+		return nil, func() {}, nil // static manager: only the fixed store exists
+	}
+	dir := filepath.Join(m.dataDir, key)
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return nil, func() {}, nil
+	}
+	newStoreFn := m.newStore
+	if newStoreFn == nil {
+		newStoreFn = store.NewFileStore
+	}
+	if s, err = newStoreFn(dir); err != nil {
+		return nil, func() {}, fmt.Errorf("open store of workspace %s: %w", ws.Name, err)
+	}
+	return s, s.Close, nil
+}
+
 // ActiveGroupKeys returns the workspace keys for all groups with open stores.
 func (m *Manager) ActiveGroupKeys() []string {
 	m.mu.RLock()
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"latere.ai/x/wallfacer/internal/envconfig"
+	"latere.ai/x/wallfacer/internal/pkg/devcache"
 	"latere.ai/x/wallfacer/internal/prompts"
 	"latere.ai/x/wallfacer/internal/store"
 )
@@ -273,6 +276,132 @@ func (m *Manager) SetLimits(id string, maxParallel, maxTestParallel *int) (Works
 	return out, nil
 }
 
+// SetBootstrap sets a workspace's bootstrap command. Surrounding whitespace is
+// trimmed; an empty command removes the bootstrap step.
+func (m *Manager) SetBootstrap(id, command string) (Workspace, error) {
+	var out Workspace
+	if err := m.mutateGroups(func(groups []Workspace) ([]Workspace, error) {
+		i := findByID(groups, id)
+		if i < 0 {
+			return nil, fmt.Errorf("workspace not found: %s", id)
+		}
+		groups[i].Bootstrap = strings.TrimSpace(command)
+		groups[i].UpdatedAt = nowStamp()
+		out = groups[i]
+		return groups, nil
+	}); err != nil {
+		return Workspace{}, err
+	}
+	return out, nil
+}
+
+// SetPublish sets a workspace's post-merge publish command. Surrounding
+// whitespace is trimmed; an empty command removes the publish stage.
+func (m *Manager) SetPublish(id, command string) (Workspace, error) {
+	var out Workspace
+	if err := m.mutateGroups(func(groups []Workspace) ([]Workspace, error) {
+		i := findByID(groups, id)
+		if i < 0 {
+			return nil, fmt.Errorf("workspace not found: %s", id)
+		}
+		groups[i].Publish = strings.TrimSpace(command)
+		groups[i].UpdatedAt = nowStamp()
+		out = groups[i]
+		return groups, nil
+	}); err != nil {
+		return Workspace{}, err
+	}
+	return out, nil
+}
+
+// SetPreview sets a workspace's deploy-preview provider or command.
+// Surrounding whitespace is trimmed; an empty value turns previews off.
+func (m *Manager) SetPreview(id, preview string) (Workspace, error) {
+	var out Workspace
+	if err := m.mutateGroups(func(groups []Workspace) ([]Workspace, error) {
+		i := findByID(groups, id)
+		if i < 0 {
+			return nil, fmt.Errorf("workspace not found: %s", id)
+		}
+		groups[i].Preview = strings.TrimSpace(preview)
+		groups[i].UpdatedAt = nowStamp()
+		out = groups[i]
+		return groups, nil
+	}); err != nil {
+		return Workspace{}, err
+	}
+	return out, nil
+}
+
+// SetStageIgnore replaces a workspace's stage-ignore patterns. Patterns are
+// trimmed and empty or duplicate ones dropped; negated patterns ("!path")
+// are rejected since staging has nothing to re-include. An empty list
+// removes the setting.
+func (m *Manager) SetStageIgnore(id string, patterns []string) (Workspace, error) {
+	var clean []string
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || slices.Contains(clean, p) {
+			continue
+		}
+		if strings.HasPrefix(p, "!") {
+			return Workspace{}, fmt.Errorf("negated stage-ignore pattern %q is not supported", p)
+		}
+		clean = append(clean, p)
+	}
+	var out Workspace
+	if err := m.mutateGroups(func(groups []Workspace) ([]Workspace, error) {
+		i := findByID(groups, id)
+		if i < 0 {
+			return nil, fmt.Errorf("workspace not found: %s", id)
+		}
+		groups[i].StageIgnore = clean
+		groups[i].UpdatedAt = nowStamp()
+		out = groups[i]
+		return groups, nil
+	}); err != nil {
+		return Workspace{}, err
+	}
+	return out, nil
+}
+
+// SetArch sets the architecture a workspace's agents run as. The GOARCH
+// names amd64 and arm64 are accepted along with their x86_64 and aarch64
+// aliases; an empty arch restores the host's native architecture.
+func (m *Manager) SetArch(id, arch string) (Workspace, error) {
+	arch, ok := normalizeArch(arch)
+	if !ok {
+		return Workspace{}, fmt.Errorf("unsupported architecture %q (want amd64 or arm64)", arch)
+	}
+	var out Workspace
+	if err := m.mutateGroups(func(groups []Workspace) ([]Workspace, error) {
+		i := findByID(groups, id)
+		if i < 0 {
+			return nil, fmt.Errorf("workspace not found: %s", id)
+		}
+		groups[i].Arch = arch
+		groups[i].UpdatedAt = nowStamp()
+		out = groups[i]
+		return groups, nil
+	}); err != nil {
+		return Workspace{}, err
+	}
+	return out, nil
+}
+
+// normalizeArch maps an architecture name to its GOARCH form.
+func normalizeArch(arch string) (string, bool) {
+	switch arch = strings.ToLower(strings.TrimSpace(arch)); arch {
+	case "", "amd64", "arm64":
+		return arch, true
+	case "x86_64":
+		return "amd64", true
+	case "aarch64":
+		return "arm64", true
+	}
+	return arch, false
+}
+
 // Delete removes a workspace and permanently wipes its scoped data — the task
 // store, transcripts, planning state, whiteboard, and agent-session history.
 // The active workspace may be deleted: the board auto-switches to the next
@@ -343,7 +472,8 @@ func firstActivatable(groups []Workspace) string {
 }
 
 // wipeWorkspaceData closes the scoped store (if still open) and removes the
-// workspace's data directory and agent-session directory. A "" key is a no-op
+// workspace's data directory, agent-session directory, and managed package
+// caches. A "" key is a no-op
 // so it can never escalate to removing a parent directory.
 func (m *Manager) wipeWorkspaceData(dataKey string) {
 	if dataKey == "" {
@@ -362,6 +492,7 @@ func (m *Manager) wipeWorkspaceData(dataKey string) {
 	}
 	if m.configDir != "" {
 		_ = os.RemoveAll(store.AgentSessionUsageDir(m.configDir, dataKey))
+		_ = devcache.Clear(devcache.Root(m.configDir), dataKey, "")
 	}
 }
 
@@ -374,6 +505,31 @@ func (m *Manager) ListWorkspaces(p *Principal) ([]Workspace, error) {
 	return WorkspacesForPrincipal(groups, p), nil
 }
 
+// WorkspaceByKey returns the workspace whose storage is addressed by key (its
+// DataKey, or the folder-derived key of a legacy record without one), if
+// present. The runner uses it to resolve a task's workspace from the key it
+// was dispatched under.
+func (m *Manager) WorkspaceByKey(key string) (Workspace, bool, error) {
+	// A static manager (tests, single-store embedding) has no registry.
+	if key == "" || m.configDir == "" {
+		return Workspace{}, false, nil
+	}
+	groups, err := LoadGroups(m.configDir)
+	if err != nil {
+		return Workspace{}, false, err
+	}
+	for _, g := range groups {
+		dataKey := g.DataKey
+		if dataKey == "" {
+			dataKey = prompts.WorkspaceDataKey(g.Folders)
+		}
+		if dataKey == key {
+			return g, true, nil
+		}
+	}
+	return Workspace{}, false, nil
+}
+
 // WorkspaceByID returns the workspace with the given id, if present.
 func (m *Manager) WorkspaceByID(id string) (Workspace, bool, error) {
 	groups, err := LoadGroups(m.configDir)
@@ -3,9 +3,11 @@ package workspace
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
+	"latere.ai/x/wallfacer/internal/pkg/devcache"
 	"latere.ai/x/wallfacer/internal/store"
 	"latere.ai/x/wallfacer/internal/store/storetest"
 )
@@ -191,8 +193,12 @@ func TestDelete_WipesDataAndAutoSwitches(t *testing.T) {
 	if err := store.AppendAgentSessionUsage(configDir, snapA.Key, store.TurnUsageRecord{Turn: 1}); err != nil {
 		t.Fatalf("seed usage: %v", err)
 	}
+	if _, err := devcache.Env(devcache.Root(configDir), snapA.Key); err != nil {
+		t.Fatalf("seed caches: %v", err)
+	}
 	dataA := filepath.Join(m.dataDir, snapA.Key)
 	sessA := store.AgentSessionUsageDir(configDir, snapA.Key)
+	cacheA := filepath.Join(devcache.Root(configDir), snapA.Key)
 	if _, err := os.Stat(dataA); err != nil {
 		t.Fatalf("A data dir should exist before delete: %v", err)
 	}
@@ -217,6 +223,9 @@ func TestDelete_WipesDataAndAutoSwitches(t *testing.T) {
 	if _, err := os.Stat(sessA); !os.IsNotExist(err) {
 		t.Errorf("A session dir not wiped: %v", err)
 	}
+	if _, err := os.Stat(cacheA); !os.IsNotExist(err) {
+		t.Errorf("A caches not wiped: %v", err)
+	}
 
 	// Delete the LAST workspace B (now active) → empty active state.
 	snap2, err := m.Delete(b.ID)
@@ -264,3 +273,146 @@ func TestCreate_StampsOwner(t *testing.T) {
 		t.Fatalf("owner not stamped at creation: %+v", ws)
 	}
 }
+
+func TestSetBootstrap(t *testing.T) {
+	m, _, _ := newCountingManager(t)
+	ws, err := m.Create("app", []string{t.TempDir()}, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := m.SetBootstrap(ws.ID, "  npm ci \n")
+	if err != nil {
+		t.Fatalf("SetBootstrap: %v", err)
+	}
+	if got.Bootstrap != "npm ci" {
+		t.Fatalf("Bootstrap = %q, want trimmed %q", got.Bootstrap, "npm ci")
+	}
+
+	// Persisted and reachable by the storage key the runner holds.
+	byKey, found, err := m.WorkspaceByKey(ws.DataKey)
+	if err != nil || !found {
+		t.Fatalf("WorkspaceByKey(%q) = found %v, err %v", ws.DataKey, found, err)
+	}
+	if byKey.ID != ws.ID || byKey.Bootstrap != "npm ci" {
+		t.Fatalf("WorkspaceByKey = %+v", byKey)
+	}
+
+	cleared, err := m.SetBootstrap(ws.ID, "")
+	if err != nil || cleared.Bootstrap != "" {
+		t.Fatalf("clear: Bootstrap = %q, err %v", cleared.Bootstrap, err)
+	}
+	if _, err := m.SetBootstrap("missing", "npm ci"); err == nil {
+		t.Fatal("SetBootstrap on unknown id: want error")
+	}
+}
+
+func TestSetPublish(t *testing.T) {
+	m, _, _ := newCountingManager(t)
+	ws, err := m.Create("app", []string{t.TempDir()}, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := m.SetPublish(ws.ID, " make release-snapshot\n")
+	if err != nil {
+		t.Fatalf("SetPublish: %v", err)
+	}
+	if got.Publish != "make release-snapshot" || got.Bootstrap != "" {
+		t.Fatalf("Publish = %q, Bootstrap = %q", got.Publish, got.Bootstrap)
+	}
+	if byKey, found, err := m.WorkspaceByKey(ws.DataKey); err != nil || !found || byKey.Publish != "make release-snapshot" {
+		t.Fatalf("WorkspaceByKey = %+v, found %v, err %v", byKey, found, err)
+	}
+	if cleared, err := m.SetPublish(ws.ID, ""); err != nil || cleared.Publish != "" {
+		t.Fatalf("clear: Publish = %q, err %v", cleared.Publish, err)
+	}
+	if _, err := m.SetPublish("missing", "make"); err == nil {
+		t.Fatal("SetPublish on unknown id: want error")
+	}
+}
+
+func TestSetPreview(t *testing.T) {
+	m, _, _ := newCountingManager(t)
+	ws, err := m.Create("app", []string{t.TempDir()}, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := m.SetPreview(ws.ID, " vercel ")
+	if err != nil {
+		t.Fatalf("SetPreview: %v", err)
+	}
+	if got.Preview != "vercel" || got.Publish != "" {
+		t.Fatalf("Preview = %q, Publish = %q", got.Preview, got.Publish)
+	}
+	if byKey, found, err := m.WorkspaceByKey(ws.DataKey); err != nil || !found || byKey.Preview != "vercel" {
+		t.Fatalf("WorkspaceByKey = %+v, found %v, err %v", byKey, found, err)
+	}
+	if cleared, err := m.SetPreview(ws.ID, ""); err != nil || cleared.Preview != "" {
+		t.Fatalf("clear: Preview = %q, err %v", cleared.Preview, err)
+	}
+	if _, err := m.SetPreview("missing", "netlify"); err == nil {
+		t.Fatal("SetPreview on unknown id: want error")
+	}
+}
+
+func TestSetArch(t *testing.T) {
+	m, _, _ := newCountingManager(t)
+	ws, err := m.Create("app", []string{t.TempDir()}, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := m.SetArch(ws.ID, " x86_64 ")
+	if err != nil {
+		t.Fatalf("SetArch: %v", err)
+	}
+	if got.Arch != "amd64" {
+		t.Fatalf("Arch = %q, want alias normalized to amd64", got.Arch)
+	}
+	if byKey, found, err := m.WorkspaceByKey(ws.DataKey); err != nil || !found || byKey.Arch != "amd64" {
+		t.Fatalf("WorkspaceByKey = %+v, found %v, err %v", byKey, found, err)
+	}
+	if _, err := m.SetArch(ws.ID, "riscv64"); err == nil {
+		t.Fatal("SetArch riscv64: want error")
+	}
+	if cleared, err := m.SetArch(ws.ID, ""); err != nil || cleared.Arch != "" {
+		t.Fatalf("clear: Arch = %q, err %v", cleared.Arch, err)
+	}
+	if _, err := m.SetArch("missing", "arm64"); err == nil {
+		t.Fatal("SetArch on unknown id: want error")
+	}
+}
+
+func TestWorkspaceByKey_Unknown(t *testing.T) {
+	m, _, _ := newCountingManager(t)
+	if _, err := m.Create("app", []string{t.TempDir()}, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "0123456789abcdef"} {
+		if _, found, err := m.WorkspaceByKey(key); err != nil || found {
+			t.Errorf("WorkspaceByKey(%q) = found %v, err %v; want not found", key, found, err)
+		}
+	}
+}
+
+func TestSetStageIgnore(t *testing.T) {
+	m, _, _ := newCountingManager(t)
+	ws, err := m.Create("app", []string{t.TempDir()}, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := m.SetStageIgnore(ws.ID, []string{" dist/ ", "", "coverage.out", "dist/"})
+	if err != nil {
+		t.Fatalf("SetStageIgnore: %v", err)
+	}
+	if want := []string{"dist/", "coverage.out"}; !slices.Equal(got.StageIgnore, want) {
+		t.Fatalf("StageIgnore = %q, want %q", got.StageIgnore, want)
+	}
+	if byKey, found, err := m.WorkspaceByKey(ws.DataKey); err != nil || !found || len(byKey.StageIgnore) != 2 {
+		t.Fatalf("WorkspaceByKey = %+v, found %v, err %v", byKey, found, err)
+	}
+	if _, err := m.SetStageIgnore(ws.ID, []string{"!keep.txt"}); err == nil {
+		t.Fatal("negated pattern: want error")
+	}
+	if cleared, err := m.SetStageIgnore(ws.ID, nil); err != nil || cleared.StageIgnore != nil {
+		t.Fatalf("clear: StageIgnore = %q, err %v", cleared.StageIgnore, err)
+	}
+}
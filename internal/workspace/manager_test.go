@@ -733,6 +733,80 @@ func TestStoreForKey(t *testing.T) {
 	}
 }
 
+// TestReadStore verifies that ReadStore hands out an active group's own
+// store, loads an idle group's tasks from disk, and reports no store for a
+// workspace that was never activated.
+func TestReadStore(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+	board, err := m.Create("board", []string{t.TempDir()}, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	snap, err := m.SwitchByID(board.ID)
+	if err != nil {
+		t.Fatalf("SwitchByID: %v", err)
+	}
+	if _, err := snap.Store.CreateTaskWithOptions(ctx, store.TaskCreateOptions{Prompt: "on the board", Timeout: 15}); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	s, release, err := m.ReadStore(board)
+	if err != nil || s != snap.Store {
+		t.Fatalf("active group: store %p, err %v; want the open store", s, err)
+	}
+	release()
+	if s.IsClosed() {
+		t.Fatal("release closed the active group's store")
+	}
+
+	if _, err := m.Switch([]string{t.TempDir()}); err != nil {
+		t.Fatalf("Switch: %v", err)
+	}
+	s, release, err = m.ReadStore(board)
+	if err != nil || s == nil || s == snap.Store {
+		t.Fatalf("idle group: store %p, err %v; want a freshly loaded store", s, err)
+	}
+	tasks, _ := s.ListTasks(ctx, false)
+	if len(tasks) != 1 || tasks[0].Prompt != "on the board" {
+		t.Fatalf("idle group tasks = %+v", tasks)
+	}
+	release()
+	if !s.IsClosed() {
+		t.Fatal("release left the loaded store open")
+	}
+
+	fresh, err := m.Create("fresh", []string{t.TempDir()}, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if s, release, err = m.ReadStore(fresh); err != nil || s != nil {
+		t.Fatalf("never activated: store %p, err %v; want none", s, err)
+	}
+	release()
+}
+
+// TestOnStoreOpen verifies that the hook sees the store already open when it
+// is registered and each store opened by a later switch.
+func TestOnStoreOpen(t *testing.T) {
+	m, _ := newTestManager(t)
+	initial := m.Snapshot()
+
+	got := make(map[string]*store.Store)
+	m.OnStoreOpen(func(key string, s *store.Store) { got[key] = s })
+	if got[initial.Key] != initial.Store || len(got) != 1 {
+		t.Fatalf("hook saw %v, want only the initial store", got)
+	}
+
+	snap, err := m.Switch([]string{t.TempDir()})
+	if err != nil {
+		t.Fatalf("Switch: %v", err)
+	}
+	if got[snap.Key] != snap.Store {
+		t.Fatalf("hook did not see the store opened by Switch")
+	}
+}
+
 // TestIncrementUnknownKeyIsNoOp verifies that incrementing an unknown key
 // does not panic or create an entry.
 func TestIncrementUnknownKeyIsNoOp(t *testing.T) {
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"latere.ai/x/wallfacer/internal/pkg/atomicfile"
+	"latere.ai/x/wallfacer/internal/pkg/atrest"
 	"latere.ai/x/wallfacer/internal/prompts"
 )
 
@@ -137,12 +138,14 @@ func dataDirHasTaskHistory(dir string) bool {
 // task history in dir: each task.json records its source folders as the keys of
 // worktree_paths. Only paths that still exist as directories are returned,
 // normalized. An empty result is valid (the workspace stays dormant with no
-// folders until the owner re-points it).
+// folders until the owner re-points it). Encrypted task.json files are read
+// with the WALLFACER_STORE_KEY key and skipped when it is unset.
 func recoverFolders(dir string) []string {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil
 	}
+	c, _ := atrest.FromEnv()
 	seen := make(map[string]bool)
 	for _, e := range entries {
 		if !e.IsDir() {
@@ -152,6 +155,9 @@ func recoverFolders(dir string) []string {
 		if err != nil {
 			continue
 		}
+		if raw, err = c.Open(raw); err != nil {
+			continue
+		}
 		var t struct {
 			WorktreePaths map[string]string `json:"worktree_paths"`
 		}
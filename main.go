@@ -39,10 +39,20 @@ func main() {
 		cli.RunServer(configDir, args, vueDist, docsFiles)
 	case "status":
 		cli.RunStatus(configDir, args)
+	case "new":
+		cli.RunNew(configDir, args)
 	case "spec":
 		cli.RunSpec(configDir, args)
 	case "auth":
 		cli.RunAuth(configDir, args)
+	case "service":
+		cli.RunService(configDir, args)
+	case "store":
+		cli.RunStore(configDir, args)
+	case "replay":
+		cli.RunReplay(configDir, args)
+	case "update":
+		cli.RunUpdate(configDir, args)
 	case "web":
 		cli.RunWeb(args, vueDist)
 	case "-help", "--help", "-h":
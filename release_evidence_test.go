@@ -45,6 +45,29 @@ func TestReleaseWorkflowWiresEvidence(t *testing.T) {
 	}
 }
 
+// TestReleaseWorkflowPublishesUpdateAttestation guards the release assets
+// `wallfacer update` depends on: the per-platform binaries named as
+// selfupdate.AssetName builds them, the SHA256SUMS manifest over them, and
+// its cosign bundle. Renaming any of them would make every installed binary
+// refuse to update.
+func TestReleaseWorkflowPublishesUpdateAttestation(t *testing.T) {
+	data, err := os.ReadFile(".github/workflows/release.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	yml := string(data)
+	for _, want := range []string{
+		"-o wallfacer-${{ matrix.goos }}-${{ matrix.goarch }}${{ matrix.ext }} .",
+		"sha256sum wallfacer-* | tee SHA256SUMS",
+		"for f in SHA256SUMS wallfacer.spdx.json; do",
+		`cosign sign-blob --bundle "${f}.cosign.bundle" "$f"`,
+	} {
+		if !strings.Contains(yml, want) {
+			t.Errorf("release.yml missing update attestation: %q", want)
+		}
+	}
+}
+
 // TestSmokeReleaseEmitsEvidence runs the real smoke script against a fake
 // production surface and asserts the evidence block it writes carries the
 // release identity and smoke result. This proves the generator the workflow